@@ -1,6 +1,7 @@
 package main
 
 import (
+	"errors"
 	"log/slog"
 	"os"
 
@@ -16,6 +17,10 @@ var (
 func main() {
 	if err := commands.Execute(version, commit, date); err != nil {
 		slog.Warn("Command failed", "error", err)
+		var exitErr *commands.ExitError
+		if errors.As(err, &exitErr) {
+			os.Exit(exitErr.Code)
+		}
 		os.Exit(1)
 	}
 }
@@ -0,0 +1,75 @@
+package analyzer
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/ppiankov/s3spectre/internal/rules"
+	"github.com/ppiankov/s3spectre/internal/s3"
+)
+
+// Risk points for exposure confirmed by IAM Access Analyzer
+const (
+	pointsConfirmedPublic   = 60
+	pointsConfirmedExternal = 40
+)
+
+// scoreExternalAccess adds the bucket's Access Analyzer findings as a risk
+// factor. Public access already scored from the bucket's own public access
+// check is named but not counted twice.
+func (d *BucketDiscovery) scoreExternalAccess(info *s3.BucketInfo, config DiscoveryConfig, weight float64) {
+	if len(info.ExternalAccess) == 0 {
+		return
+	}
+	factor, public := externalAccessFactor(info.ExternalAccess)
+	points := pointsConfirmedExternal
+	if public {
+		points = pointsConfirmedPublic
+		if config.CheckPublicAccess && info.PublicAccess != nil && info.PublicAccess.IsPublic {
+			points = 0
+		}
+	}
+	points = weightSecurity(points, weight)
+	d.RiskScore += points
+	d.RiskFactors = append(d.RiskFactors, factor)
+	d.recommend("Remove the access IAM Access Analyzer reports, or archive its findings if the access is intended",
+		points, 0, rules.EffortMedium)
+}
+
+// externalAccessFactor describes a bucket's Access Analyzer findings as one
+// risk factor naming the grantees and the actions they are allowed, and
+// reports whether any finding is public
+func externalAccessFactor(findings []s3.ExternalAccessFinding) (string, bool) {
+	public := false
+	seenGrantee := make(map[string]bool)
+	seenAction := make(map[string]bool)
+	var grantees, actions []string
+	for _, f := range findings {
+		if f.Public {
+			public = true
+		} else if g := f.Grantee(); g != "" && !seenGrantee[g] {
+			seenGrantee[g] = true
+			grantees = append(grantees, g)
+		}
+		for _, action := range f.Actions {
+			if !seenAction[action] {
+				seenAction[action] = true
+				actions = append(actions, action)
+			}
+		}
+	}
+	sort.Strings(grantees)
+	sort.Strings(actions)
+
+	factor := "External access confirmed by IAM Access Analyzer"
+	if public {
+		factor = "Public access confirmed by IAM Access Analyzer"
+	}
+	if len(grantees) > 0 {
+		factor += ": " + strings.Join(grantees, "; ")
+	}
+	if len(actions) > 0 {
+		factor += " (" + strings.Join(actions, ", ") + ")"
+	}
+	return factor, public
+}
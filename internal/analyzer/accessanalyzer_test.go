@@ -0,0 +1,45 @@
+package analyzer
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/ppiankov/s3spectre/internal/s3"
+)
+
+func TestAnalyzeDiscovery_ExternalAccess(t *testing.T) {
+	public := []s3.ExternalAccessFinding{{ID: "f-1", Public: true, Actions: []string{"s3:GetObject"}}}
+	buckets := map[string]*s3.BucketInfo{
+		// Old and unencrypted scores 60; the confirmed exposure makes it risky
+		"site": {Name: "site", Exists: true, AgeInDays: 400, Encryption: &s3.EncryptionInfo{}, ExternalAccess: public},
+		// Public access already scored is not counted twice
+		"assets": {Name: "assets", Exists: true, PublicAccess: &s3.PublicAccessInfo{IsPublic: true}, ExternalAccess: public},
+		"partner": {Name: "partner", Exists: true, ExternalAccess: []s3.ExternalAccessFinding{
+			{ID: "f-2", Principal: map[string]string{"AWS": "444455556666"}, Actions: []string{"s3:PutObject"}},
+			{ID: "f-3", Principal: map[string]string{"AWS": "444455556666"}, Actions: []string{"s3:GetObject", "s3:PutObject"}},
+		}},
+		"private": {Name: "private", Exists: true},
+	}
+	config := DiscoveryConfig{AgeThresholdDays: 365, CheckEncryption: true, CheckPublicAccess: true, RiskScoreThreshold: 100}
+
+	result := AnalyzeDiscovery(buckets, config)
+	result.Summary.Sort()
+
+	if got := strings.Join(result.Summary.ExternalAccess, ","); got != "assets,partner,site" {
+		t.Errorf("external access = %s, want assets,partner,site", got)
+	}
+	if site := result.Buckets["site"]; site.Status != StatusRisky || site.RiskScore != 120 {
+		t.Errorf("site = %s (%d), want RISKY at 120", site.Status, site.RiskScore)
+	}
+	if assets := result.Buckets["assets"]; assets.RiskScore != 60 || !strings.Contains(strings.Join(assets.RiskFactors, "|"), "Public access confirmed by IAM Access Analyzer") {
+		t.Errorf("assets = %d %v, want the heuristic's 60 points and the confirmation", assets.RiskScore, assets.RiskFactors)
+	}
+	partner := result.Buckets["partner"]
+	want := "External access confirmed by IAM Access Analyzer: AWS 444455556666 (s3:GetObject, s3:PutObject)"
+	if partner.RiskScore != 40 || len(partner.RiskFactors) != 1 || partner.RiskFactors[0] != want {
+		t.Errorf("partner = %d %v, want 40 and %q", partner.RiskScore, partner.RiskFactors, want)
+	}
+	if private := result.Buckets["private"]; private.RiskScore != 0 {
+		t.Errorf("private = %d, want 0", private.RiskScore)
+	}
+}
@@ -0,0 +1,85 @@
+package analyzer
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/ppiankov/s3spectre/internal/s3"
+)
+
+// AccountAnalysis is a finding about account configuration rather than about
+// a single bucket. Region is empty for account-wide findings; Buckets lists
+// the buckets that contributed to an aggregate finding.
+type AccountAnalysis struct {
+	Status      Status   `json:"status"`
+	Region      string   `json:"region,omitempty"`
+	Message     string   `json:"message,omitempty"`
+	Buckets     []string `json:"buckets,omitempty"`
+	Fingerprint string   `json:"fingerprint,omitempty"`
+	// Account is set in multi-account runs; empty means the caller's account
+	Account string `json:"account,omitempty"`
+	// Family is the environment family of an ENV_DRIFT finding, e.g. orders-*
+	Family string `json:"family,omitempty"`
+}
+
+// AnalyzeAccountPublicAccess adds a NO_ACCOUNT_PAB account finding when the
+// account has no public access block or one of its settings is off. A nil
+// block means the account has no configuration at all.
+func AnalyzeAccountPublicAccess(result *DiscoveryResult, block *s3.PublicAccessInfo) {
+	message := "Account has no S3 Block Public Access configuration"
+	if block != nil {
+		var off []string
+		if !block.BlockPublicAcls {
+			off = append(off, "BlockPublicAcls")
+		}
+		if !block.IgnorePublicAcls {
+			off = append(off, "IgnorePublicAcls")
+		}
+		if !block.BlockPublicPolicy {
+			off = append(off, "BlockPublicPolicy")
+		}
+		if !block.RestrictPublicBuckets {
+			off = append(off, "RestrictPublicBuckets")
+		}
+		if len(off) == 0 {
+			return
+		}
+		message = "Account Block Public Access settings are off: " + strings.Join(off, ", ")
+	}
+	result.AccountFindings = append(result.AccountFindings, AccountAnalysis{
+		Status:  StatusNoAccountPAB,
+		Message: message,
+	})
+}
+
+// AnalyzeLifecycleCoverage adds a LOW_LIFECYCLE account finding when more
+// than max inspected buckets have no lifecycle rules. Buckets that were not
+// fully inspected are not counted. A max of 0 disables the check.
+func AnalyzeLifecycleCoverage(result *DiscoveryResult, max int) {
+	if max <= 0 {
+		return
+	}
+	var uncovered []string
+	for name, discovery := range result.Buckets {
+		if discovery == nil {
+			continue
+		}
+		info := discovery.BucketInfo
+		if info == nil || !info.Exists || info.TimedOut || info.Interrupted || info.Skipped(s3.CheckLifecycle) {
+			continue
+		}
+		if info.LifecycleRules == 0 {
+			uncovered = append(uncovered, name)
+		}
+	}
+	if len(uncovered) <= max {
+		return
+	}
+	sort.Strings(uncovered)
+	result.AccountFindings = append(result.AccountFindings, AccountAnalysis{
+		Status:  StatusLowLifecycle,
+		Message: fmt.Sprintf("%d buckets have no lifecycle rules (threshold %d)", len(uncovered), max),
+		Buckets: uncovered,
+	})
+}
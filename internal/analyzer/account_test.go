@@ -0,0 +1,61 @@
+package analyzer
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/ppiankov/s3spectre/internal/s3"
+)
+
+func TestAnalyzeAccountPublicAccess(t *testing.T) {
+	full := &s3.PublicAccessInfo{BlockPublicAcls: true, IgnorePublicAcls: true, BlockPublicPolicy: true, RestrictPublicBuckets: true}
+	result := &DiscoveryResult{}
+	AnalyzeAccountPublicAccess(result, full)
+	if len(result.AccountFindings) != 0 {
+		t.Fatalf("fully enabled block should not produce a finding, got %+v", result.AccountFindings)
+	}
+
+	partial := *full
+	partial.RestrictPublicBuckets = false
+	AnalyzeAccountPublicAccess(result, &partial)
+	AnalyzeAccountPublicAccess(result, nil)
+	if len(result.AccountFindings) != 2 {
+		t.Fatalf("expected 2 account findings, got %+v", result.AccountFindings)
+	}
+	for _, f := range result.AccountFindings {
+		if f.Status != StatusNoAccountPAB || f.Region != "" {
+			t.Errorf("unexpected finding %+v", f)
+		}
+	}
+	if !strings.Contains(result.AccountFindings[0].Message, "RestrictPublicBuckets") {
+		t.Errorf("message should name the disabled setting, got %q", result.AccountFindings[0].Message)
+	}
+}
+
+func TestAnalyzeLifecycleCoverage(t *testing.T) {
+	result := &DiscoveryResult{Buckets: map[string]*BucketDiscovery{
+		"b-logs":    {BucketInfo: &s3.BucketInfo{Exists: true}},
+		"a-media":   {BucketInfo: &s3.BucketInfo{Exists: true}},
+		"covered":   {BucketInfo: &s3.BucketInfo{Exists: true, LifecycleRules: 2}},
+		"timed-out": {BucketInfo: &s3.BucketInfo{Exists: true, TimedOut: true}},
+		"gone":      {BucketInfo: &s3.BucketInfo{}},
+	}}
+
+	AnalyzeLifecycleCoverage(result, 0)
+	AnalyzeLifecycleCoverage(result, 2)
+	if len(result.AccountFindings) != 0 {
+		t.Fatalf("expected no finding at or below the threshold, got %+v", result.AccountFindings)
+	}
+
+	AnalyzeLifecycleCoverage(result, 1)
+	if len(result.AccountFindings) != 1 {
+		t.Fatalf("expected 1 account finding, got %+v", result.AccountFindings)
+	}
+	f := result.AccountFindings[0]
+	if f.Status != StatusLowLifecycle {
+		t.Errorf("expected %s, got %s", StatusLowLifecycle, f.Status)
+	}
+	if len(f.Buckets) != 2 || f.Buckets[0] != "a-media" || f.Buckets[1] != "b-logs" {
+		t.Errorf("expected sorted uncovered buckets, got %v", f.Buckets)
+	}
+}
@@ -0,0 +1,116 @@
+package analyzer
+
+import "sort"
+
+// AccountSummary totals buckets and findings for one AWS account
+type AccountSummary struct {
+	Account      string `json:"account"`
+	TotalBuckets int    `json:"total_buckets"`
+	Findings     int    `json:"findings"`
+	// Statuses counts the account's findings by status
+	Statuses  map[Status]int `json:"statuses,omitempty"`
+	TotalSize int64          `json:"total_size,omitempty"`
+}
+
+// accountTotals accumulates AccountSummary entries by account ID
+type accountTotals map[string]*AccountSummary
+
+func (t accountTotals) get(account string) *AccountSummary {
+	if account == "" {
+		account = UnknownAccount
+	}
+	summary, ok := t[account]
+	if !ok {
+		summary = &AccountSummary{Account: account, Statuses: make(map[Status]int)}
+		t[account] = summary
+	}
+	return summary
+}
+
+func (t accountTotals) finding(account string, status Status) {
+	summary := t.get(account)
+	summary.Findings++
+	summary.Statuses[status]++
+}
+
+// sorted lists the accounts with the most findings first
+func (t accountTotals) sorted() []AccountSummary {
+	out := make([]AccountSummary, 0, len(t))
+	for _, summary := range t {
+		if len(summary.Statuses) == 0 {
+			summary.Statuses = nil
+		}
+		out = append(out, *summary)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Findings != out[j].Findings {
+			return out[i].Findings > out[j].Findings
+		}
+		return out[i].Account < out[j].Account
+	})
+	return out
+}
+
+// UnknownAccount groups buckets whose owning account was not resolved
+const UnknownAccount = "unknown"
+
+// SummarizeDiscoveryAccounts groups discovery results by owning account.
+// Buckets and account findings without an account belong to defaultAccount,
+// the caller's.
+func SummarizeDiscoveryAccounts(result *DiscoveryResult, defaultAccount string) []AccountSummary {
+	totals := make(accountTotals)
+	for _, discovery := range result.Buckets {
+		if discovery == nil {
+			continue
+		}
+		account := defaultAccount
+		if discovery.BucketInfo != nil && discovery.BucketInfo.AccountID != "" {
+			account = discovery.BucketInfo.AccountID
+		}
+		summary := totals.get(account)
+		summary.TotalBuckets++
+		if discovery.BucketInfo != nil {
+			summary.TotalSize += discovery.BucketInfo.TotalSize
+		}
+		if discovery.Status != StatusOK {
+			totals.finding(account, discovery.Status)
+		}
+		for _, cf := range discovery.CustomFindings {
+			totals.finding(account, Status(cf.Rule))
+		}
+	}
+	for _, f := range result.AccountFindings {
+		account := f.Account
+		if account == "" {
+			account = defaultAccount
+		}
+		totals.finding(account, f.Status)
+	}
+	return totals.sorted()
+}
+
+// SummarizeScanAccounts groups scan results by the account owning each
+// bucket. Buckets without an account, such as missing ones, belong to
+// defaultAccount, the caller's.
+func SummarizeScanAccounts(result *Result, defaultAccount string) []AccountSummary {
+	totals := make(accountTotals)
+	for _, analysis := range result.Buckets {
+		if analysis == nil {
+			continue
+		}
+		account := analysis.Account
+		if account == "" {
+			account = defaultAccount
+		}
+		totals.get(account).TotalBuckets++
+		if analysis.Status != StatusOK {
+			totals.finding(account, analysis.Status)
+		}
+		for _, p := range analysis.Prefixes {
+			if p.Status != StatusOK {
+				totals.finding(account, p.Status)
+			}
+		}
+	}
+	return totals.sorted()
+}
@@ -0,0 +1,70 @@
+package analyzer
+
+import (
+	"testing"
+
+	"github.com/ppiankov/s3spectre/internal/s3"
+)
+
+func TestSummarizeDiscoveryAccounts(t *testing.T) {
+	result := &DiscoveryResult{
+		Buckets: map[string]*BucketDiscovery{
+			"a-logs": {Status: StatusUnusedBucket, BucketInfo: &s3.BucketInfo{AccountID: "111111111111", TotalSize: 10}},
+			"a-web": {Status: StatusRisky, BucketInfo: &s3.BucketInfo{AccountID: "111111111111"},
+				CustomFindings: []CustomFinding{{Rule: "NO_OWNER_TAG"}}},
+			"b-data": {Status: StatusOK, BucketInfo: &s3.BucketInfo{AccountID: "222222222222"}},
+			"caller": {Status: StatusOK},
+		},
+		AccountFindings: []AccountAnalysis{
+			{Status: StatusNoAccountPAB, Account: "222222222222"},
+			{Status: StatusLowLifecycle},
+		},
+	}
+
+	accounts := SummarizeDiscoveryAccounts(result, "333333333333")
+
+	if len(accounts) != 3 {
+		t.Fatalf("expected 3 accounts, got %+v", accounts)
+	}
+	a := accounts[0]
+	if a.Account != "111111111111" || a.TotalBuckets != 2 || a.Findings != 3 || a.TotalSize != 10 {
+		t.Errorf("the account with the most findings should sort first: %+v", a)
+	}
+	if a.Statuses[StatusUnusedBucket] != 1 || a.Statuses["NO_OWNER_TAG"] != 1 {
+		t.Errorf("unexpected status counts: %+v", a.Statuses)
+	}
+	if accounts[1].Account != "222222222222" || accounts[1].Findings != 1 {
+		t.Errorf("account findings should count for their account: %+v", accounts[1])
+	}
+	if accounts[2].Account != "333333333333" || accounts[2].TotalBuckets != 1 || accounts[2].Findings != 1 {
+		t.Errorf("untagged buckets and findings belong to the caller: %+v", accounts[2])
+	}
+}
+
+func TestSummarizeScanAccounts(t *testing.T) {
+	result := &Result{Buckets: map[string]*BucketAnalysis{
+		"shared": {Status: StatusOK, Account: "111111111111",
+			Prefixes: []PrefixAnalysis{{Status: StatusStalePrefix}, {Status: StatusOK}}},
+		"missing": {Status: StatusMissingBucket},
+	}}
+
+	accounts := SummarizeScanAccounts(result, "")
+
+	if len(accounts) != 2 {
+		t.Fatalf("expected 2 accounts, got %+v", accounts)
+	}
+	for _, a := range accounts {
+		switch a.Account {
+		case "111111111111":
+			if a.Findings != 1 || a.Statuses[StatusStalePrefix] != 1 {
+				t.Errorf("prefix findings should count for the bucket's account: %+v", a)
+			}
+		case UnknownAccount:
+			if a.Statuses[StatusMissingBucket] != 1 {
+				t.Errorf("missing buckets without a caller account are unknown: %+v", a)
+			}
+		default:
+			t.Errorf("unexpected account %+v", a)
+		}
+	}
+}
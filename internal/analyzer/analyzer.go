@@ -2,6 +2,7 @@ package analyzer
 
 import (
 	"fmt"
+	"strconv"
 	"strings"
 
 	"github.com/ppiankov/s3spectre/internal/s3"
@@ -21,13 +22,30 @@ func Analyze(refs []scanner.Reference, bucketInfo map[string]*s3.BucketInfo, con
 		referencedBuckets[ref.Bucket] = true
 	}
 
+	// Buckets written by replication are not unused
+	replicated := s3.ReplicationSources(bucketInfo)
+
 	// Analyze each bucket
 	for bucket, info := range bucketInfo {
 		analysis := analyzeBucket(bucket, info, refs, config, referencedBuckets)
+		if sources := replicated[bucket]; analysis.Status == StatusUnusedBucket && len(sources) > 0 {
+			markReplicatedUnused(analysis, sources)
+		}
+		assignConfidence(analysis, info, refs)
+		assignBlame(analysis, refs)
 		result.Buckets[bucket] = analysis
 
 		// Update summary
 		result.Summary.TotalBuckets++
+		if info.TimedOut {
+			result.Summary.TimedOutBuckets = append(result.Summary.TimedOutBuckets, bucket)
+		}
+		if len(info.SkippedChecks) > 0 {
+			result.Summary.DeadlineSkips = append(result.Summary.DeadlineSkips, newDeadlineSkip(bucket, info))
+		}
+		if analysis.TestOnly {
+			result.Summary.TestOnlyBuckets = append(result.Summary.TestOnlyBuckets, bucket)
+		}
 
 		switch analysis.Status {
 		case StatusOK:
@@ -40,6 +58,8 @@ func Analyze(refs []scanner.Reference, bucketInfo map[string]*s3.BucketInfo, con
 			result.Summary.VersionSprawl = append(result.Summary.VersionSprawl, bucket)
 		case StatusLifecycleMisconfig:
 			result.Summary.LifecycleMisconfig = append(result.Summary.LifecycleMisconfig, bucket)
+		case StatusReplicationDestination:
+			result.Summary.ReplicationDestinations = append(result.Summary.ReplicationDestinations, bucket)
 		}
 
 		// Check prefix statuses
@@ -50,9 +70,12 @@ func Analyze(refs []scanner.Reference, bucketInfo map[string]*s3.BucketInfo, con
 				result.Summary.MissingPrefixes = append(result.Summary.MissingPrefixes, prefixPath)
 			case StatusStalePrefix:
 				result.Summary.StalePrefixes = append(result.Summary.StalePrefixes, prefixPath)
+			case StatusPrefixNearMiss:
+				result.Summary.PrefixNearMisses = append(result.Summary.PrefixNearMisses, prefixPath)
 			}
 		}
 	}
+	addExternalBuckets(result, refs, config.External)
 
 	return result
 }
@@ -65,17 +88,55 @@ func analyzeBucket(bucket string, info *s3.BucketInfo, refs []scanner.Reference,
 		ExistsInAWS:       info.Exists,
 		VersioningEnabled: info.VersioningEnabled,
 		LifecycleRules:    info.LifecycleRules,
+		Account:           info.AccountID,
+	}
+
+	// A timed-out or interrupted inspection is a skipped check, not evidence
+	// of drift. A timed-out bucket is reported as such rather than OK,
+	// since its checks did not finish.
+	if info.TimedOut {
+		analysis.Status = StatusTimedOut
+		analysis.Message = "Inspection timed out; bucket checks were skipped"
+		return analysis
+	}
+	if info.Interrupted {
+		analysis.Status = StatusOK
+		analysis.Message = "Inspection interrupted; bucket checks were skipped"
+		return analysis
+	}
+	if !info.Exists && info.Skipped(s3.CheckLocation) {
+		analysis.Status = StatusOK
+		analysis.Message = "Location check skipped at the per-bucket deadline; existence unknown"
+		return analysis
 	}
 
 	// Check if bucket exists
 	if !info.Exists {
 		analysis.Status = StatusMissingBucket
 		analysis.Message = "Bucket referenced in code but does not exist in AWS"
+		if config.TestPaths.TestOnly(filterRefsByBucket(refs, bucket)) {
+			analysis.TestOnly = true
+			if config.TestPaths.Exclude {
+				analysis.Status = StatusOK
+				analysis.Message = TestOnlyMessage
+				return analysis
+			}
+			analysis.Message += "; referenced only from test paths"
+		}
+		if len(info.NearMisses) > 0 {
+			analysis.Suggestions = info.NearMisses
+			if sibling, siblingEnv, env := envSibling(config.Environments, bucket, info.NearMisses); sibling != "" {
+				analysis.Message += fmt.Sprintf("; %q exists for %s but not for %s", sibling, siblingEnv, env)
+			} else {
+				analysis.Message += fmt.Sprintf("; did you mean %s?", quoteList(info.NearMisses))
+			}
+		}
 		return analysis
 	}
 
-	// Check for unused bucket if enabled
-	if config.CheckUnused {
+	// Check for unused bucket if enabled; skipped checks leave too little
+	// evidence to call a bucket unused
+	if config.CheckUnused && !info.Skipped(s3.CheckActivity) && !info.Skipped(s3.CheckTagging) {
 		unusedScore := calculateUnusedScore(bucket, info, referencedBuckets, config)
 		analysis.UnusedScore = unusedScore
 
@@ -87,7 +148,7 @@ func analyzeBucket(bucket string, info *s3.BucketInfo, refs []scanner.Reference,
 	}
 
 	// Check version sprawl (versioning enabled but no lifecycle rules)
-	if info.VersioningEnabled && info.LifecycleRules == 0 {
+	if info.VersioningEnabled && info.LifecycleRules == 0 && !info.Skipped(s3.CheckLifecycle) {
 		analysis.Status = StatusVersionSprawl
 		analysis.Message = "Versioning enabled but no lifecycle rules to clean up old versions"
 		// Don't return yet, still check prefixes
@@ -102,7 +163,7 @@ func analyzeBucket(bucket string, info *s3.BucketInfo, refs []scanner.Reference,
 	// Determine overall status if not already set
 	if analysis.Status == "" {
 		// Check for lifecycle misconfig (heuristic: no lifecycle rules for large buckets)
-		if info.LifecycleRules == 0 && len(info.Prefixes) > 0 {
+		if info.LifecycleRules == 0 && len(info.Prefixes) > 0 && !info.Skipped(s3.CheckLifecycle) {
 			hasLargePrefix := false
 			for _, p := range info.Prefixes {
 				if p.ObjectCount > 100 {
@@ -176,6 +237,12 @@ func calculateUnusedScore(bucket string, info *s3.BucketInfo, referencedBuckets
 		}
 	}
 
+	// Sensitive data needs stronger evidence before it is called unused
+	if classification, weight := config.Classification.Classify(info.Tags); weight > 1 {
+		score.Total = weightDeletability(score.Total, weight)
+		score.Reasons = append(score.Reasons, fmt.Sprintf("Classified %s: score divided by %.1f", classification, weight))
+	}
+
 	// Determine if unused
 	score.IsUnused = score.Total >= threshold
 
@@ -193,7 +260,11 @@ func analyzePrefixes(prefixes []s3.PrefixInfo, refs []scanner.Reference, config
 			DaysSinceModified: prefix.DaysSinceModified,
 		}
 
-		if !prefix.Exists {
+		if !prefix.Exists && prefix.NearMiss != "" {
+			analysis.Status = StatusPrefixNearMiss
+			analysis.Suggestion = prefix.NearMiss
+			analysis.Message = fmt.Sprintf("Prefix not found; did you mean %q?", prefix.NearMiss)
+		} else if !prefix.Exists {
 			analysis.Status = StatusMissingPrefix
 			analysis.Message = "Prefix referenced in code but no objects found"
 		} else if prefix.DaysSinceModified > config.StaleThresholdDays {
@@ -210,6 +281,27 @@ func analyzePrefixes(prefixes []s3.PrefixInfo, refs []scanner.Reference, config
 	return results
 }
 
+// assignBlame sets each finding's most recent commit among its references,
+// when references carry blame
+func assignBlame(analysis *BucketAnalysis, refs []scanner.Reference) {
+	bucketRefs := filterRefsByBucket(refs, analysis.Name)
+	if analysis.Status != StatusOK {
+		analysis.Blame = scanner.LatestBlame(bucketRefs)
+	}
+	for i, p := range analysis.Prefixes {
+		if p.Status == StatusOK {
+			continue
+		}
+		var prefixRefs []scanner.Reference
+		for _, ref := range bucketRefs {
+			if ref.Prefix == p.Prefix {
+				prefixRefs = append(prefixRefs, ref)
+			}
+		}
+		analysis.Prefixes[i].Blame = scanner.LatestBlame(prefixRefs)
+	}
+}
+
 // filterRefsByBucket filters references for a specific bucket
 func filterRefsByBucket(refs []scanner.Reference, bucket string) []scanner.Reference {
 	var filtered []scanner.Reference
@@ -220,3 +312,15 @@ func filterRefsByBucket(refs []scanner.Reference, bucket string) []scanner.Refer
 	}
 	return filtered
 }
+
+// quoteList joins quoted names as "a", "b" or "c"
+func quoteList(names []string) string {
+	quoted := make([]string, len(names))
+	for i, name := range names {
+		quoted[i] = strconv.Quote(name)
+	}
+	if len(quoted) == 1 {
+		return quoted[0]
+	}
+	return strings.Join(quoted[:len(quoted)-1], ", ") + " or " + quoted[len(quoted)-1]
+}
@@ -1,7 +1,10 @@
 package analyzer
 
 import (
+	"reflect"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/ppiankov/s3spectre/internal/s3"
 	"github.com/ppiankov/s3spectre/internal/scanner"
@@ -46,6 +49,56 @@ func TestAnalyze_OKBucket(t *testing.T) {
 	}
 }
 
+func TestAnalyze_TimedOutBucketIsSkipped(t *testing.T) {
+	refs := []scanner.Reference{
+		{Bucket: "my-bucket", Prefix: "data/", File: "app.py", Line: 10},
+	}
+	bucketInfo := map[string]*s3.BucketInfo{
+		"my-bucket": {Name: "my-bucket", TimedOut: true},
+	}
+
+	result := Analyze(refs, bucketInfo, Config{})
+
+	if result.Buckets["my-bucket"].Status != StatusTimedOut {
+		t.Errorf("expected status %s, got %s", StatusTimedOut, result.Buckets["my-bucket"].Status)
+	}
+	if result.Summary.OKBuckets != 0 {
+		t.Errorf("a timed-out bucket should not count as OK, got %d", result.Summary.OKBuckets)
+	}
+	if len(result.Summary.MissingBuckets) != 0 || len(result.Summary.MissingPrefixes) != 0 {
+		t.Fatalf("expected no missing findings for a timed-out bucket, got %+v", result.Summary)
+	}
+	if len(result.Summary.TimedOutBuckets) != 1 || result.Summary.TimedOutBuckets[0] != "my-bucket" {
+		t.Fatalf("expected my-bucket listed as timed out, got %v", result.Summary.TimedOutBuckets)
+	}
+}
+
+func TestAnalyze_DeadlineSkips(t *testing.T) {
+	refs := []scanner.Reference{
+		{Bucket: "unlisted", File: "app.py", Line: 10},
+		{Bucket: "versioned", Prefix: "data/", File: "app.py", Line: 11},
+	}
+	bucketInfo := map[string]*s3.BucketInfo{
+		"unlisted": {Name: "unlisted", SkippedChecks: []string{s3.CheckLocation}},
+		"versioned": {Name: "versioned", Exists: true, Region: "eu-west-1", VersioningEnabled: true,
+			SkippedChecks: []string{s3.CheckLifecycle, s3.CheckTagging, s3.CheckActivity, s3.CheckPrefixes}},
+	}
+
+	result := Analyze(refs, bucketInfo, Config{CheckUnused: true})
+	result.Summary.Sort()
+
+	if len(result.Summary.MissingBuckets) != 0 || len(result.Summary.VersionSprawl) != 0 || len(result.Summary.MissingPrefixes) != 0 {
+		t.Fatalf("skipped checks should not produce findings, got %+v", result.Summary)
+	}
+	want := []DeadlineSkip{
+		{Bucket: "unlisted", Checks: []string{s3.CheckLocation}},
+		{Bucket: "versioned", Region: "eu-west-1", Checks: []string{s3.CheckLifecycle, s3.CheckTagging, s3.CheckActivity, s3.CheckPrefixes}},
+	}
+	if !reflect.DeepEqual(result.Summary.DeadlineSkips, want) {
+		t.Errorf("DeadlineSkips = %+v, want %+v", result.Summary.DeadlineSkips, want)
+	}
+}
+
 func TestAnalyze_VersionSprawl(t *testing.T) {
 	refs := []scanner.Reference{
 		{Bucket: "my-bucket", File: "app.py", Line: 10},
@@ -156,6 +209,42 @@ func TestAnalyze_UnusedBucket(t *testing.T) {
 	}
 }
 
+func TestAnalyze_ReplicationDestination(t *testing.T) {
+	refs := []scanner.Reference{
+		{Bucket: "orders", File: "app.py", Line: 10},
+	}
+	bucketInfo := map[string]*s3.BucketInfo{
+		"orders": {
+			Name:   "orders",
+			Exists: true,
+			Replication: &s3.ReplicationInfo{Rules: []s3.ReplicationRule{
+				{Enabled: true, Destination: "orders-dr"},
+			}},
+		},
+		"orders-dr": {
+			Name:    "orders-dr",
+			Exists:  true,
+			IsEmpty: true,
+		},
+	}
+
+	result := Analyze(refs, bucketInfo, Config{
+		CheckUnused:          true,
+		UnusedScoreThreshold: 150,
+	})
+
+	if len(result.Summary.UnusedBuckets) != 0 {
+		t.Errorf("a replication destination should not be unused, got %v", result.Summary.UnusedBuckets)
+	}
+	if len(result.Summary.ReplicationDestinations) != 1 || result.Summary.ReplicationDestinations[0] != "orders-dr" {
+		t.Fatalf("expected orders-dr as a replication destination, got %v", result.Summary.ReplicationDestinations)
+	}
+	analysis := result.Buckets["orders-dr"]
+	if analysis.Status != StatusReplicationDestination || !strings.Contains(analysis.Message, "replication destination of orders") {
+		t.Errorf("got status %s message %q", analysis.Status, analysis.Message)
+	}
+}
+
 func TestAnalyze_UnusedCheckDisabled(t *testing.T) {
 	bucketInfo := map[string]*s3.BucketInfo{
 		"unused-bucket": {
@@ -218,6 +307,53 @@ func TestAnalyze_PrefixStatuses(t *testing.T) {
 	}
 }
 
+func TestAnalyze_PrefixNearMiss(t *testing.T) {
+	refs := []scanner.Reference{
+		{Bucket: "my-bucket", Prefix: "logs/", File: "app.py", Line: 10},
+	}
+	bucketInfo := map[string]*s3.BucketInfo{
+		"my-bucket": {
+			Name:     "my-bucket",
+			Exists:   true,
+			Prefixes: []s3.PrefixInfo{{Prefix: "logs/", NearMiss: "log/"}},
+		},
+	}
+
+	result := Analyze(refs, bucketInfo, Config{StaleThresholdDays: 90})
+
+	p := result.Buckets["my-bucket"].Prefixes[0]
+	if p.Status != StatusPrefixNearMiss || p.Suggestion != "log/" {
+		t.Fatalf("expected a near miss suggesting log/, got %+v", p)
+	}
+	if p.Message != `Prefix not found; did you mean "log/"?` {
+		t.Errorf("unexpected message %q", p.Message)
+	}
+	if len(result.Summary.PrefixNearMisses) != 1 || len(result.Summary.MissingPrefixes) != 0 {
+		t.Errorf("near misses should replace missing prefixes in the summary: %+v", result.Summary)
+	}
+}
+
+func TestAnalyze_MissingBucketSuggestions(t *testing.T) {
+	refs := []scanner.Reference{{Bucket: "orders-prod", File: "app.py", Line: 3}}
+	bucketInfo := map[string]*s3.BucketInfo{
+		"orders-prod": {Name: "orders-prod", NearMisses: []string{"orders-prd", "orders-staging"}},
+	}
+
+	result := Analyze(refs, bucketInfo, Config{})
+
+	analysis := result.Buckets["orders-prod"]
+	if analysis.Status != StatusMissingBucket {
+		t.Fatalf("expected MISSING_BUCKET, got %s", analysis.Status)
+	}
+	if !reflect.DeepEqual(analysis.Suggestions, []string{"orders-prd", "orders-staging"}) {
+		t.Errorf("unexpected suggestions %v", analysis.Suggestions)
+	}
+	want := `Bucket referenced in code but does not exist in AWS; did you mean "orders-prd" or "orders-staging"?`
+	if analysis.Message != want {
+		t.Errorf("message = %q, want %q", analysis.Message, want)
+	}
+}
+
 func TestAnalyze_MultipleBuckets(t *testing.T) {
 	refs := []scanner.Reference{
 		{Bucket: "existing", File: "app.py", Line: 1},
@@ -341,3 +477,29 @@ func TestFilterRefsByBucket_NoMatch(t *testing.T) {
 		t.Fatalf("expected 0 refs, got %d", len(filtered))
 	}
 }
+
+func TestAnalyze_Blame(t *testing.T) {
+	older := &scanner.Blame{Commit: "old", Time: time.Unix(100, 0)}
+	newer := &scanner.Blame{Commit: "new", Time: time.Unix(200, 0)}
+	refs := []scanner.Reference{
+		{Bucket: "gone", File: "a.py", Line: 1, Blame: older},
+		{Bucket: "gone", File: "b.py", Line: 2, Blame: newer},
+		{Bucket: "data", Prefix: "typo/", File: "c.py", Line: 3, Blame: older},
+		{Bucket: "data", File: "d.py", Line: 4, Blame: newer},
+	}
+	info := map[string]*s3.BucketInfo{
+		"gone": {Name: "gone"},
+		"data": {Name: "data", Exists: true, Prefixes: []s3.PrefixInfo{{Prefix: "typo/"}}},
+	}
+	result := Analyze(refs, info, Config{})
+	if got := result.Buckets["gone"].Blame; got != newer {
+		t.Errorf("missing bucket blame = %+v, want the newest reference's", got)
+	}
+	data := result.Buckets["data"]
+	if data.Status == StatusOK && data.Blame != nil {
+		t.Errorf("OK buckets carry no blame: %+v", data.Blame)
+	}
+	if len(data.Prefixes) != 1 || data.Prefixes[0].Status != StatusMissingPrefix || data.Prefixes[0].Blame != older {
+		t.Errorf("prefix finding should carry its own reference's blame: %+v", data.Prefixes)
+	}
+}
@@ -0,0 +1,87 @@
+package analyzer
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+)
+
+// DefaultClassificationTag is the tag key read when none is configured
+const DefaultClassificationTag = "data-classification"
+
+// Classification weights findings by a bucket's data classification tag.
+// Security risk points are multiplied by the weight; for weights above 1,
+// the evidence that a bucket is unused is divided by it, so sensitive data
+// is slower to be suggested for deletion.
+type Classification struct {
+	// TagKeys are the tag keys holding the classification, matched
+	// case-insensitively; the first key present on a bucket wins
+	TagKeys []string
+	// Weights maps classification values (case-insensitive) to weights
+	Weights map[string]float64
+}
+
+// NewClassification validates weights and normalizes values to lower case.
+// It returns nil when no weights are configured.
+func NewClassification(tagKeys []string, weights map[string]float64) (*Classification, error) {
+	if len(weights) == 0 {
+		return nil, nil
+	}
+	c := &Classification{Weights: make(map[string]float64, len(weights))}
+	for value, weight := range weights {
+		if weight <= 0 || math.IsInf(weight, 0) || math.IsNaN(weight) {
+			return nil, fmt.Errorf("classification weight for %q must be a positive number, got %v", value, weight)
+		}
+		c.Weights[strings.ToLower(strings.TrimSpace(value))] = weight
+	}
+	for _, key := range tagKeys {
+		if key = strings.TrimSpace(key); key != "" {
+			c.TagKeys = append(c.TagKeys, key)
+		}
+	}
+	if len(c.TagKeys) == 0 {
+		c.TagKeys = []string{DefaultClassificationTag}
+	}
+	return c, nil
+}
+
+// Classify returns a bucket's classification and its weight. Unclassified
+// buckets, and values without a configured weight, weigh 1.
+func (c *Classification) Classify(tags map[string]string) (string, float64) {
+	if c == nil || len(tags) == 0 {
+		return "", 1
+	}
+	keys := make([]string, 0, len(tags))
+	for key := range tags {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, want := range c.TagKeys {
+		for _, key := range keys {
+			if !strings.EqualFold(key, want) {
+				continue
+			}
+			value := strings.ToLower(strings.TrimSpace(tags[key]))
+			if weight, ok := c.Weights[value]; ok {
+				return value, weight
+			}
+			return value, 1
+		}
+	}
+	return "", 1
+}
+
+// weightSecurity scales security risk points by the classification weight
+func weightSecurity(points int, weight float64) int {
+	return int(math.Round(float64(points) * weight))
+}
+
+// weightDeletability reduces unused evidence for sensitive data. Weights
+// of 1 or less leave it unchanged.
+func weightDeletability(points int, weight float64) int {
+	if weight <= 1 {
+		return points
+	}
+	return int(math.Round(float64(points) / weight))
+}
@@ -0,0 +1,96 @@
+package analyzer
+
+import (
+	"testing"
+
+	"github.com/ppiankov/s3spectre/internal/s3"
+)
+
+func TestNewClassification(t *testing.T) {
+	c, err := NewClassification(nil, nil)
+	if err != nil || c != nil {
+		t.Fatalf("expected nil classification without weights, got %+v, %v", c, err)
+	}
+	if _, err := NewClassification(nil, map[string]float64{"public": 0}); err == nil {
+		t.Fatalf("expected error for a zero weight")
+	}
+
+	c, err = NewClassification([]string{" "}, map[string]float64{"Confidential": 2})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(c.TagKeys) != 1 || c.TagKeys[0] != DefaultClassificationTag {
+		t.Fatalf("expected default tag key, got %v", c.TagKeys)
+	}
+	if c.Weights["confidential"] != 2 {
+		t.Fatalf("expected lower-cased weight key, got %v", c.Weights)
+	}
+}
+
+func TestClassify(t *testing.T) {
+	c, _ := NewClassification([]string{"classification", "data-classification"}, map[string]float64{"confidential": 2, "public": 0.5})
+
+	tests := []struct {
+		tags      map[string]string
+		wantLevel string
+		want      float64
+	}{
+		{nil, "", 1},
+		{map[string]string{"team": "data"}, "", 1},
+		{map[string]string{"Data-Classification": "Confidential"}, "confidential", 2},
+		{map[string]string{"classification": "public", "data-classification": "confidential"}, "public", 0.5},
+		{map[string]string{"classification": "internal"}, "internal", 1},
+	}
+	for _, tt := range tests {
+		level, weight := c.Classify(tt.tags)
+		if level != tt.wantLevel || weight != tt.want {
+			t.Errorf("Classify(%v) = %q, %v; want %q, %v", tt.tags, level, weight, tt.wantLevel, tt.want)
+		}
+	}
+
+	var disabled *Classification
+	if level, weight := disabled.Classify(map[string]string{"data-classification": "confidential"}); level != "" || weight != 1 {
+		t.Fatalf("expected nil classification to weigh 1, got %q, %v", level, weight)
+	}
+}
+
+func TestAnalyzeBucketDiscovery_Classification(t *testing.T) {
+	c, _ := NewClassification(nil, map[string]float64{"confidential": 2, "public": 0.5})
+	config := DiscoveryConfig{CheckEncryption: true, InactivityThresholdDays: 180, RiskScoreThreshold: 100, Classification: c}
+
+	info := &s3.BucketInfo{
+		Name:              "secrets",
+		DaysSinceActivity: 200,
+		Encryption:        &s3.EncryptionInfo{Enabled: false},
+		Tags:              map[string]string{"data-classification": "confidential"},
+	}
+	d := analyzeBucketDiscovery(info, config)
+	// Encryption 40*2, inactivity 50/2
+	if d.RiskScore != 105 {
+		t.Fatalf("expected risk score 105, got %d (%v)", d.RiskScore, d.RiskFactors)
+	}
+	if d.Classification != "confidential" || len(d.Recommendations) == 0 {
+		t.Fatalf("expected classification and a retention recommendation, got %+v", d)
+	}
+
+	// Low-sensitivity data lowers security risk but not deletability
+	info.Tags = map[string]string{"data-classification": "public"}
+	d = analyzeBucketDiscovery(info, config)
+	if d.RiskScore != 70 {
+		t.Fatalf("expected risk score 70, got %d (%v)", d.RiskScore, d.RiskFactors)
+	}
+}
+
+func TestCalculateUnusedScore_Classification(t *testing.T) {
+	c, _ := NewClassification(nil, map[string]float64{"restricted": 3})
+	info := &s3.BucketInfo{Name: "orphan", Exists: true, IsEmpty: true, Tags: map[string]string{"data-classification": "restricted"}}
+
+	score := calculateUnusedScore("orphan", info, map[string]bool{}, Config{UnusedScoreThreshold: 150, Classification: c})
+
+	if score.Total != 50 {
+		t.Errorf("expected Total=50 (150/3), got %d", score.Total)
+	}
+	if score.IsUnused {
+		t.Error("expected restricted bucket not to be flagged unused")
+	}
+}
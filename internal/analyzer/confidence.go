@@ -0,0 +1,159 @@
+package analyzer
+
+import (
+	"github.com/ppiankov/s3spectre/internal/s3"
+	"github.com/ppiankov/s3spectre/internal/scanner"
+)
+
+// Confidence levels, from most to least actionable
+const (
+	ConfidenceHigh   = "high"
+	ConfidenceMedium = "medium"
+	ConfidenceLow    = "low"
+)
+
+// Confidence estimates how likely a finding is to be real rather than noise.
+// Score starts at 100 and loses points for each reason the evidence is weak.
+type Confidence struct {
+	Score   int      `json:"score"`
+	Level   string   `json:"level"`
+	Reasons []string `json:"reasons,omitempty"`
+}
+
+// Penalties applied to a finding's confidence score
+const (
+	penaltyCheckFailed     = 30
+	penaltySampledActivity = 20
+	penaltySampledPrefix   = 25
+	penaltyUnknownContext  = 20
+	penaltySingleFile      = 10
+	penaltyTestOnly        = 30
+)
+
+// newConfidence returns a full-confidence estimate
+func newConfidence() *Confidence {
+	return &Confidence{Score: 100}
+}
+
+// lower subtracts points and records why
+func (c *Confidence) lower(points int, reason string) {
+	c.Score = max(c.Score-points, 0)
+	c.Reasons = append(c.Reasons, reason)
+}
+
+// finish sets the level from the score
+func (c *Confidence) finish() *Confidence {
+	switch {
+	case c.Score >= 80:
+		c.Level = ConfidenceHigh
+	case c.Score >= 50:
+		c.Level = ConfidenceMedium
+	default:
+		c.Level = ConfidenceLow
+	}
+	return c
+}
+
+// lowerForInspection applies the penalties that come from the bucket's
+// inspection: failed checks and activity read from a sample of objects
+func (c *Confidence) lowerForInspection(info *s3.BucketInfo, activityBased bool) {
+	if info.Error != "" {
+		c.lower(penaltyCheckFailed, "Some bucket checks failed: "+info.Error)
+	}
+	// A complete deep listing or an inventory report read every object, and
+	// CloudTrail access events are real use rather than a sample
+	complete := (info.SizeBreakdown != nil && !info.SizeBreakdown.Truncated) || info.Inventory != nil
+	accessed := info.Access != nil && info.Access.LastAccess != nil
+	if !activityBased || accessed {
+		return
+	}
+	// CloudWatch storage metrics count every object but only see writes;
+	// request metrics see reads too
+	if cw := info.CloudWatch; cw != nil {
+		if !cw.RequestMetrics {
+			c.lower(penaltySampledActivity, "Activity estimated from daily storage changes, which miss reads")
+		}
+		return
+	}
+	if info.ObjectCount >= s3.ActivitySampleSize && !complete {
+		c.lower(penaltySampledActivity, "Activity estimated from a sample of objects")
+	}
+}
+
+// lowerForReferences applies the penalties that come from how the bucket or
+// prefix is referenced in code
+func (c *Confidence) lowerForReferences(refs []scanner.Reference) {
+	if len(refs) == 0 {
+		return
+	}
+	files := make(map[string]bool)
+	known := false
+	for _, ref := range refs {
+		files[ref.File] = true
+		if ref.Context != "" && ref.Context != string(scanner.RefTypeUnknown) {
+			known = true
+		}
+	}
+	if !known {
+		c.lower(penaltyUnknownContext, "No reference shows an S3 operation")
+	}
+	if len(files) == 1 {
+		c.lower(penaltySingleFile, "Referenced from one file only")
+	}
+}
+
+// bucketConfidence estimates a scan bucket finding's confidence
+func bucketConfidence(analysis *BucketAnalysis, info *s3.BucketInfo, refs []scanner.Reference) *Confidence {
+	c := newConfidence()
+	c.lowerForInspection(info, false)
+	if analysis.Status == StatusMissingBucket {
+		c.lowerForReferences(refs)
+		if analysis.TestOnly {
+			c.lower(penaltyTestOnly, "Referenced only from test, fixture or example paths")
+		}
+	}
+	return c.finish()
+}
+
+// prefixConfidence estimates a scan prefix finding's confidence
+func prefixConfidence(prefix PrefixAnalysis, refs []scanner.Reference) *Confidence {
+	c := newConfidence()
+	switch prefix.Status {
+	case StatusStalePrefix:
+		if prefix.ObjectCount >= s3.PrefixListLimit {
+			c.lower(penaltySampledPrefix, "Age estimated from the first objects listed")
+		}
+	case StatusMissingPrefix, StatusPrefixNearMiss:
+		var prefixRefs []scanner.Reference
+		for _, ref := range refs {
+			if ref.Prefix == prefix.Prefix {
+				prefixRefs = append(prefixRefs, ref)
+			}
+		}
+		c.lowerForReferences(prefixRefs)
+	}
+	return c.finish()
+}
+
+// discoveryConfidence estimates a discovery bucket finding's confidence
+func discoveryConfidence(discovery *BucketDiscovery) *Confidence {
+	c := newConfidence()
+	if discovery.BucketInfo != nil {
+		activityBased := discovery.Status == StatusUnusedBucket || discovery.Status == StatusInactive || discovery.Status == StatusRisky
+		c.lowerForInspection(discovery.BucketInfo, activityBased)
+	}
+	return c.finish()
+}
+
+// assignConfidence sets the confidence of a scan bucket's findings
+func assignConfidence(analysis *BucketAnalysis, info *s3.BucketInfo, refs []scanner.Reference) {
+	bucketRefs := filterRefsByBucket(refs, analysis.Name)
+	if analysis.Status != StatusOK {
+		analysis.Confidence = bucketConfidence(analysis, info, bucketRefs)
+	}
+	for i := range analysis.Prefixes {
+		if analysis.Prefixes[i].Status != StatusOK {
+			analysis.Prefixes[i].Confidence = prefixConfidence(analysis.Prefixes[i], bucketRefs)
+		}
+	}
+}
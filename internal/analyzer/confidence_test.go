@@ -0,0 +1,79 @@
+package analyzer
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ppiankov/s3spectre/internal/s3"
+	"github.com/ppiankov/s3spectre/internal/scanner"
+)
+
+func TestAnalyze_Confidence(t *testing.T) {
+	refs := []scanner.Reference{
+		{Bucket: "gone", File: "main.go", Line: 3, Context: "unknown"},
+		{Bucket: "used", File: "a.go", Context: "read"},
+		{Bucket: "used", File: "b.go", Context: "write"},
+		{Bucket: "used", Prefix: "big/", File: "a.go", Context: "read"},
+	}
+	buckets := map[string]*s3.BucketInfo{
+		"gone": {Name: "gone"},
+		"used": {Name: "used", Exists: true, Prefixes: []s3.PrefixInfo{
+			{Prefix: "big/", Exists: true, ObjectCount: s3.PrefixListLimit, DaysSinceModified: 400},
+		}},
+		"fine": {Name: "fine", Exists: true},
+	}
+	result := Analyze(refs, buckets, Config{StaleThresholdDays: 90})
+
+	gone := result.Buckets["gone"].Confidence
+	if gone == nil || gone.Score != 70 || gone.Level != ConfidenceMedium || len(gone.Reasons) != 2 {
+		t.Errorf("unexpected confidence for missing bucket: %+v", gone)
+	}
+	if c := result.Buckets["fine"].Confidence; c != nil {
+		t.Errorf("OK bucket should have no confidence, got %+v", c)
+	}
+	stale := result.Buckets["used"].Prefixes[0].Confidence
+	if stale == nil || stale.Score != 75 || stale.Level != ConfidenceMedium {
+		t.Errorf("unexpected confidence for sampled stale prefix: %+v", stale)
+	}
+}
+
+func TestAnalyzeDiscovery_Confidence(t *testing.T) {
+	accessed := time.Now().AddDate(0, 0, -400)
+	buckets := map[string]*s3.BucketInfo{
+		"sampled": {Name: "sampled", Exists: true, ObjectCount: s3.ActivitySampleSize, DaysSinceActivity: 400, Error: "get versioning failed"},
+		"small":   {Name: "small", Exists: true, ObjectCount: 3, DaysSinceActivity: 400},
+		"deep": {Name: "deep", Exists: true, ObjectCount: 5000, DaysSinceActivity: 400,
+			SizeBreakdown: &s3.SizeBreakdown{Objects: 5000}},
+		"accessed": {Name: "accessed", Exists: true, ObjectCount: s3.ActivitySampleSize, DaysSinceActivity: 400,
+			Access: &s3.AccessInfo{LastAccess: &accessed}},
+		"storage-metrics": {Name: "storage-metrics", Exists: true, ObjectCount: 50, DaysSinceActivity: 400,
+			CloudWatch: &s3.CloudWatchActivity{}},
+		"request-metrics": {Name: "request-metrics", Exists: true, ObjectCount: 50000, DaysSinceActivity: 400,
+			CloudWatch: &s3.CloudWatchActivity{RequestMetrics: true}},
+		"inventoried": {Name: "inventoried", Exists: true, ObjectCount: 2000000, DaysSinceActivity: 400,
+			Inventory: &s3.InventoryReport{Objects: 2000000}},
+	}
+	result := AnalyzeDiscovery(buckets, DiscoveryConfig{InactivityThresholdDays: 180, RiskScoreThreshold: 50})
+
+	if c := result.Buckets["small"].Confidence; c == nil || c.Score != 100 || c.Level != ConfidenceHigh {
+		t.Errorf("unexpected confidence for fully listed bucket: %+v", c)
+	}
+	if c := result.Buckets["sampled"].Confidence; c == nil || c.Score != 50 || c.Level != ConfidenceMedium || len(c.Reasons) != 2 {
+		t.Errorf("unexpected confidence for sampled bucket with errors: %+v", c)
+	}
+	if c := result.Buckets["deep"].Confidence; c == nil || c.Score != 100 {
+		t.Errorf("unexpected confidence for completely deep-listed bucket: %+v", c)
+	}
+	if c := result.Buckets["accessed"].Confidence; c == nil || c.Score != 100 {
+		t.Errorf("unexpected confidence for bucket with CloudTrail access: %+v", c)
+	}
+	if c := result.Buckets["storage-metrics"].Confidence; c == nil || c.Score != 80 || len(c.Reasons) != 1 {
+		t.Errorf("unexpected confidence for bucket with storage metrics only: %+v", c)
+	}
+	if c := result.Buckets["request-metrics"].Confidence; c == nil || c.Score != 100 {
+		t.Errorf("unexpected confidence for bucket with request metrics: %+v", c)
+	}
+	if c := result.Buckets["inventoried"].Confidence; c == nil || c.Score != 100 {
+		t.Errorf("unexpected confidence for bucket counted from an inventory report: %+v", c)
+	}
+}
@@ -0,0 +1,161 @@
+package analyzer
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/ppiankov/s3spectre/internal/rules"
+	"github.com/ppiankov/s3spectre/internal/spectre"
+)
+
+// Correlation is a sibling Spectre tool's finding that involves a bucket
+// s3spectre flagged, such as an IAM role that can write to an unused bucket
+type Correlation struct {
+	Bucket string `json:"bucket"`
+	Status Status `json:"status"`
+	// Findings are s3spectre's own findings for the bucket
+	Findings []Status `json:"findings"`
+	// Tool, Rule, Location and SourceSeverity describe the sibling finding
+	Tool           string `json:"tool"`
+	Rule           string `json:"rule"`
+	Location       string `json:"location"`
+	SourceSeverity string `json:"source_severity,omitempty"`
+	// Actions are the S3 actions the sibling finding grants, when listed
+	Actions []string `json:"actions,omitempty"`
+	// Write is set when one of the actions can write or delete
+	Write bool `json:"write,omitempty"`
+	// Severity is high for write access or a high sibling finding, else
+	// medium
+	Severity    string `json:"severity"`
+	Message     string `json:"message"`
+	Fingerprint string `json:"fingerprint,omitempty"`
+}
+
+// Key identifies the sibling finding within the bucket, for fingerprints
+func (c Correlation) Key() string {
+	return c.Tool + ":" + c.Location + ":" + c.Rule
+}
+
+// CorrelateScan adds a CORRELATED_FINDING to result for each sibling
+// finding that involves a bucket or prefix the scan flagged
+func CorrelateScan(result *Result, envelopes []*spectre.Envelope) {
+	flagged := make(map[string][]Status)
+	for name, analysis := range result.Buckets {
+		if analysis.Status != StatusOK {
+			flagged[name] = appendStatus(flagged[name], analysis.Status)
+		}
+		for _, p := range analysis.Prefixes {
+			if p.Status != StatusOK {
+				flagged[name] = appendStatus(flagged[name], p.Status)
+			}
+		}
+	}
+	result.Correlations = correlate(flagged, envelopes)
+	result.Summary.Correlated = correlatedBuckets(result.Correlations)
+}
+
+// CorrelateDiscovery adds a CORRELATED_FINDING to result for each sibling
+// finding that involves a bucket discovery flagged or has a policy finding
+// for
+func CorrelateDiscovery(result *DiscoveryResult, envelopes []*spectre.Envelope) {
+	flagged := make(map[string][]Status)
+	for name, discovery := range result.Buckets {
+		if discovery.Status != StatusOK {
+			flagged[name] = appendStatus(flagged[name], discovery.Status)
+		}
+		for _, f := range discovery.PolicyFindings {
+			flagged[name] = appendStatus(flagged[name], f.Status)
+		}
+	}
+	result.Correlations = correlate(flagged, envelopes)
+	result.Summary.Correlated = correlatedBuckets(result.Correlations)
+}
+
+// correlate matches the findings of other tools' envelopes to flagged
+// buckets
+func correlate(flagged map[string][]Status, envelopes []*spectre.Envelope) []Correlation {
+	var correlations []Correlation
+	for _, envelope := range envelopes {
+		// Our own envelopes repeat findings already reported
+		if envelope.Tool == "s3spectre" {
+			continue
+		}
+		for _, f := range envelope.Findings {
+			for _, bucket := range f.Buckets() {
+				statuses := flagged[bucket]
+				if len(statuses) == 0 {
+					continue
+				}
+				c := Correlation{
+					Bucket:         bucket,
+					Status:         StatusCorrelated,
+					Findings:       statuses,
+					Tool:           envelope.Tool,
+					Rule:           f.ID,
+					Location:       f.Location,
+					SourceSeverity: f.Severity,
+					Severity:       rules.SeverityMedium,
+				}
+				for _, action := range f.Actions() {
+					if strings.HasPrefix(strings.ToLower(action), "s3:") || action == "*" {
+						c.Actions = append(c.Actions, action)
+						c.Write = c.Write || spectre.IsWrite(action)
+					}
+				}
+				if c.Write || strings.EqualFold(f.Severity, rules.SeverityHigh) {
+					c.Severity = rules.SeverityHigh
+				}
+				c.Message = correlationMessage(c)
+				correlations = append(correlations, c)
+			}
+		}
+	}
+
+	sort.SliceStable(correlations, func(i, j int) bool {
+		if correlations[i].Bucket != correlations[j].Bucket {
+			return correlations[i].Bucket < correlations[j].Bucket
+		}
+		return correlations[i].Key() < correlations[j].Key()
+	})
+	return correlations
+}
+
+// correlationMessage describes what the sibling finding grants on a
+// flagged bucket
+func correlationMessage(c Correlation) string {
+	statuses := make([]string, len(c.Findings))
+	for k, s := range c.Findings {
+		statuses[k] = string(s)
+	}
+	access := "involves"
+	switch {
+	case c.Write:
+		access = fmt.Sprintf("has write access (%s) to", strings.Join(c.Actions, ", "))
+	case len(c.Actions) > 0:
+		access = fmt.Sprintf("has access (%s) to", strings.Join(c.Actions, ", "))
+	}
+	return fmt.Sprintf("%s %s: %s %s %s, flagged %s",
+		c.Tool, c.Rule, c.Location, access, c.Bucket, strings.Join(statuses, ", "))
+}
+
+// correlatedBuckets lists the buckets with correlations once each
+func correlatedBuckets(correlations []Correlation) []string {
+	var buckets []string
+	for _, c := range correlations {
+		if len(buckets) == 0 || buckets[len(buckets)-1] != c.Bucket {
+			buckets = append(buckets, c.Bucket)
+		}
+	}
+	return buckets
+}
+
+// appendStatus appends a status unless the list already holds it
+func appendStatus(statuses []Status, status Status) []Status {
+	for _, s := range statuses {
+		if s == status {
+			return statuses
+		}
+	}
+	return append(statuses, status)
+}
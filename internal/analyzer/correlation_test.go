@@ -0,0 +1,85 @@
+package analyzer
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/ppiankov/s3spectre/internal/rules"
+	"github.com/ppiankov/s3spectre/internal/spectre"
+)
+
+func TestCorrelateScan(t *testing.T) {
+	result := &Result{Buckets: map[string]*BucketAnalysis{
+		"orders-archive": {Name: "orders-archive", Status: StatusUnusedBucket},
+		"orders-exports": {Name: "orders-exports", Status: StatusOK, Prefixes: []PrefixAnalysis{
+			{Prefix: "2023/", Status: StatusStalePrefix},
+		}},
+		"assets": {Name: "assets", Status: StatusOK},
+	}}
+	envelopes := []*spectre.Envelope{
+		{Tool: "iamspectre", Findings: []spectre.Finding{
+			{ID: "STALE_ROLE", Severity: "medium", Location: "arn:aws:iam::111122223333:role/etl", Metadata: map[string]any{
+				"resources": []any{"arn:aws:s3:::orders-archive/*"},
+				"actions":   []any{"s3:PutObject", "s3:GetObject", "kms:Decrypt"},
+			}},
+			// Assets is not flagged, so this finding is not correlated
+			{ID: "STALE_ROLE", Severity: "medium", Location: "arn:aws:iam::111122223333:role/web", Metadata: map[string]any{
+				"resources": []any{"arn:aws:s3:::assets/*"},
+			}},
+		}},
+		{Tool: "dynamospectre", Findings: []spectre.Finding{
+			{ID: "UNUSED_TABLE", Severity: "low", Location: "arn:aws:dynamodb:us-east-1:111122223333:table/orders",
+				Metadata: map[string]any{"export_bucket": "orders-exports"}},
+		}},
+		// Our own envelope is not correlated with itself
+		{Tool: "s3spectre", Findings: []spectre.Finding{
+			{ID: "UNUSED_BUCKET", Location: "s3://orders-archive"},
+		}},
+	}
+
+	CorrelateScan(result, envelopes)
+
+	if len(result.Correlations) != 2 {
+		t.Fatalf("correlations = %+v, want 2", result.Correlations)
+	}
+	archive := result.Correlations[0]
+	if archive.Bucket != "orders-archive" || archive.Status != StatusCorrelated || archive.Severity != rules.SeverityHigh || !archive.Write {
+		t.Errorf("archive = %+v, want a high write correlation", archive)
+	}
+	want := "iamspectre STALE_ROLE: arn:aws:iam::111122223333:role/etl has write access (s3:GetObject, s3:PutObject) to orders-archive, flagged UNUSED_BUCKET"
+	if archive.Message != want {
+		t.Errorf("message = %q, want %q", archive.Message, want)
+	}
+	exports := result.Correlations[1]
+	if exports.Bucket != "orders-exports" || exports.Severity != rules.SeverityMedium || exports.Tool != "dynamospectre" ||
+		!reflect.DeepEqual(exports.Findings, []Status{StatusStalePrefix}) {
+		t.Errorf("exports = %+v, want a medium correlation with the stale prefix", exports)
+	}
+	if !reflect.DeepEqual(result.Summary.Correlated, []string{"orders-archive", "orders-exports"}) {
+		t.Errorf("correlated = %v", result.Summary.Correlated)
+	}
+}
+
+func TestCorrelateDiscovery(t *testing.T) {
+	result := &DiscoveryResult{Buckets: map[string]*BucketDiscovery{
+		"uploads": {Name: "uploads", Status: StatusOK, PolicyFindings: []PolicyFinding{{Status: StatusWildcardPrincipal}}},
+	}}
+	envelopes := []*spectre.Envelope{{Tool: "iamspectre", Findings: []spectre.Finding{
+		{ID: "OVERPRIVILEGED_ROLE", Severity: "high", Location: "arn:aws:iam::111122223333:role/ci",
+			Message: "role can read arn:aws:s3:::uploads", Metadata: map[string]any{"actions": "s3:GetObject"}},
+	}}}
+
+	CorrelateDiscovery(result, envelopes)
+
+	if len(result.Correlations) != 1 {
+		t.Fatalf("correlations = %+v, want 1", result.Correlations)
+	}
+	c := result.Correlations[0]
+	if c.Severity != rules.SeverityHigh || c.Write {
+		t.Errorf("correlation = %+v, want high from the sibling's severity without write access", c)
+	}
+	want := "iamspectre OVERPRIVILEGED_ROLE: arn:aws:iam::111122223333:role/ci has access (s3:GetObject) to uploads, flagged WILDCARD_PRINCIPAL"
+	if c.Message != want {
+		t.Errorf("message = %q, want %q", c.Message, want)
+	}
+}
@@ -0,0 +1,39 @@
+package analyzer
+
+import "sort"
+
+// CustomFinding is an additional finding reported by an external check plugin
+type CustomFinding struct {
+	Plugin      string `json:"plugin"`
+	Rule        string `json:"rule"`
+	Severity    string `json:"severity"`
+	Message     string `json:"message,omitempty"`
+	Fingerprint string `json:"fingerprint,omitempty"`
+}
+
+// RuleID scopes the finding's rule to its plugin, so two plugins
+// reporting the same rule name are told apart
+func (f CustomFinding) RuleID() string {
+	return "plugin/" + f.Plugin + "/" + f.Rule
+}
+
+// AddCustomFindings attaches plugin findings to their discovered buckets.
+// Findings for buckets not in the result are ignored. Bucket statuses are
+// left unchanged: custom findings are reported alongside built-in ones.
+func AddCustomFindings(result *DiscoveryResult, findings map[string][]CustomFinding) {
+	for bucket, list := range findings {
+		discovery, ok := result.Buckets[bucket]
+		if !ok || discovery == nil {
+			continue
+		}
+		discovery.CustomFindings = append(discovery.CustomFindings, list...)
+		sort.SliceStable(discovery.CustomFindings, func(i, j int) bool {
+			a, b := discovery.CustomFindings[i], discovery.CustomFindings[j]
+			if a.Plugin != b.Plugin {
+				return a.Plugin < b.Plugin
+			}
+			return a.Rule < b.Rule
+		})
+		result.Summary.CustomFindings += len(list)
+	}
+}
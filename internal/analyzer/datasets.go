@@ -0,0 +1,56 @@
+package analyzer
+
+// PublicDatasets are well-known buckets of the Registry of Open Data on AWS.
+// Code that reads them is consuming someone else's data, so they are treated
+// as external unless the registry is disabled.
+var PublicDatasets = []string{
+	"1000genomes",
+	"amazon-reviews-pds",
+	"commoncrawl",
+	"copernicus-dem-30m",
+	"copernicus-dem-90m",
+	"daylight-openstreetmap",
+	"deutsche-boerse-xetra-pds",
+	"elevation-tiles-prod",
+	"era5-pds",
+	"fast-ai-imageclas",
+	"fast-ai-nlp",
+	"gdelt-open-data",
+	"gnomad-public-us-east-1",
+	"irs-form-990",
+	"landsat-pds",
+	"naip-analytic",
+	"naip-visualization",
+	"noaa-ghcn-pds",
+	"noaa-gfs-bdp-pds",
+	"noaa-goes16",
+	"noaa-goes17",
+	"noaa-goes18",
+	"noaa-nexrad-level2",
+	"nrel-pds-wtk",
+	"nyc-tlc",
+	"openaq-fetches",
+	"openneuro.org",
+	"osm-pds",
+	"overturemaps-us-west-2",
+	"sentinel-cogs",
+	"sentinel-s1-l1c",
+	"sentinel-s2-l1c",
+	"sentinel-s2-l2a",
+	"spacenet-dataset",
+	"usgs-landsat",
+}
+
+// WithPublicDatasets returns the set extended with the built-in public
+// dataset registry and extra dataset patterns
+func (e *ExternalBuckets) WithPublicDatasets(extra []string) (*ExternalBuckets, error) {
+	if err := validatePatterns(extra); err != nil {
+		return nil, err
+	}
+	out := &ExternalBuckets{}
+	if e != nil {
+		out.Patterns = e.Patterns
+	}
+	out.Datasets = append(append([]string{}, PublicDatasets...), extra...)
+	return out, nil
+}
@@ -0,0 +1,24 @@
+package analyzer
+
+import (
+	"sort"
+
+	"github.com/ppiankov/s3spectre/internal/s3"
+)
+
+// DeadlineSkip is a bucket whose remaining checks were skipped at the
+// per-bucket deadline. Findings that depend on a skipped check are not
+// reported for the bucket.
+type DeadlineSkip struct {
+	Bucket string   `json:"bucket"`
+	Region string   `json:"region,omitempty"`
+	Checks []string `json:"checks"`
+}
+
+func newDeadlineSkip(bucket string, info *s3.BucketInfo) DeadlineSkip {
+	return DeadlineSkip{Bucket: bucket, Region: info.Region, Checks: info.SkippedChecks}
+}
+
+func sortDeadlineSkips(skips []DeadlineSkip) {
+	sort.Slice(skips, func(i, j int) bool { return skips[i].Bucket < skips[j].Bucket })
+}
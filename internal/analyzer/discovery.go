@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"strings"
 
+	"github.com/ppiankov/s3spectre/internal/rules"
 	"github.com/ppiankov/s3spectre/internal/s3"
 )
 
@@ -14,23 +15,55 @@ type DiscoveryConfig struct {
 	CheckEncryption         bool
 	CheckPublicAccess       bool
 	RiskScoreThreshold      int
+	// Classification weights risk by data classification tags; nil disables it
+	Classification *Classification
+	// External buckets are reported without risk scoring
+	External *ExternalBuckets
+	// CheckPolicy reports the bucket policy findings of BucketInfo.Policy
+	CheckPolicy bool
+	// CheckLogging reports production buckets without logging, read into
+	// BucketInfo.Logging; ProductionTags select the production buckets
+	CheckLogging   bool
+	ProductionTags []s3.TagFilter
 }
 
 // DiscoveryResult contains discovery analysis results
 type DiscoveryResult struct {
 	Buckets map[string]*BucketDiscovery `json:"buckets"`
 	Summary DiscoverySummary            `json:"summary"`
+	// AccountFindings are findings about account configuration, not buckets
+	AccountFindings []AccountAnalysis `json:"account_findings,omitempty"`
+	// Correlations join other Spectre tools' findings to flagged buckets
+	Correlations []Correlation `json:"correlations,omitempty"`
 }
 
 // BucketDiscovery contains discovery analysis for a bucket
 type BucketDiscovery struct {
-	Name            string         `json:"name"`
-	Region          string         `json:"region"`
-	Status          Status         `json:"status"`
-	RiskScore       int            `json:"risk_score"`
-	RiskFactors     []string       `json:"risk_factors"`
-	Recommendations []string       `json:"recommendations"`
-	BucketInfo      *s3.BucketInfo `json:"bucket_info,omitempty"`
+	Name            string          `json:"name"`
+	Region          string          `json:"region"`
+	Status          Status          `json:"status"`
+	RiskScore       int             `json:"risk_score"`
+	RiskFactors     []string        `json:"risk_factors"`
+	Recommendations []string        `json:"recommendations"`
+	BucketInfo      *s3.BucketInfo  `json:"bucket_info,omitempty"`
+	CustomFindings  []CustomFinding `json:"custom_findings,omitempty"`
+	Fingerprint     string          `json:"fingerprint,omitempty"`
+	// Classification is the bucket's data classification tag value
+	Classification string `json:"classification,omitempty"`
+	// Confidence is set on findings; it is nil for OK buckets
+	Confidence *Confidence `json:"confidence,omitempty"`
+	// External buckets are third-party and were not scored
+	External bool `json:"external,omitempty"`
+	// ScoredRecommendations carry impact and effort, sorted by their ratio
+	ScoredRecommendations []Recommendation `json:"scored_recommendations,omitempty"`
+	// Spend is month-to-date spend from Cost Explorer, set with --with-costs
+	Spend *BucketSpend `json:"spend,omitempty"`
+	// PolicyFindings are the bucket policy's problems, set with
+	// --check-policy, missing logging, set with --check-logging, and
+	// exposed sensitive data, set with --with-macie
+	PolicyFindings []PolicyFinding `json:"policy_findings,omitempty"`
+	// DataClasses are the sensitive data categories Macie found
+	DataClasses []string `json:"data_classes,omitempty"`
 }
 
 // DiscoverySummary contains high-level summary
@@ -42,6 +75,38 @@ type DiscoverySummary struct {
 	InactiveBuckets []string `json:"inactive_buckets,omitempty"`
 	VersionSprawl   []string `json:"version_sprawl,omitempty"`
 	TotalRegions    int      `json:"total_regions"`
+	CustomFindings  int      `json:"custom_findings,omitempty"`
+	// NoGuardDutyS3 lists regions without GuardDuty S3 protection
+	NoGuardDutyS3 []string `json:"no_guardduty_s3,omitempty"`
+	// TimedOutBuckets lists buckets whose inspection hit a deadline; their
+	// remaining checks were skipped
+	TimedOutBuckets []string `json:"timed_out_buckets,omitempty"`
+	// ExternalBuckets lists third-party buckets that were not scored
+	ExternalBuckets []string `json:"external_buckets,omitempty"`
+	// DeadlineSkips lists the checks skipped at the per-bucket deadline
+	DeadlineSkips []DeadlineSkip `json:"deadline_skips,omitempty"`
+	// WildcardPrincipals, CrossAccountAccess and InsecureTransport list the
+	// buckets with each bucket policy finding
+	WildcardPrincipals []string `json:"wildcard_principals,omitempty"`
+	CrossAccountAccess []string `json:"cross_account_access,omitempty"`
+	InsecureTransport  []string `json:"insecure_transport,omitempty"`
+	// NoLogging lists the production buckets without access logging or
+	// CloudTrail data events
+	NoLogging []string `json:"no_logging,omitempty"`
+	// SensitiveDataExposed lists the public or unencrypted buckets Macie
+	// found sensitive data in
+	SensitiveDataExposed []string `json:"sensitive_data_exposed,omitempty"`
+	// ComplianceLocked lists the buckets flagged for deletion whose
+	// compliance-mode Object Lock retention keeps them from being emptied
+	ComplianceLocked []string `json:"compliance_locked,omitempty"`
+	// ExternalAccess lists the buckets with active IAM Access Analyzer
+	// findings
+	ExternalAccess []string `json:"external_access,omitempty"`
+	// ReplicationDestinations lists the buckets that would be unused or
+	// inactive but are written by replication from another bucket
+	ReplicationDestinations []string `json:"replication_destinations,omitempty"`
+	// Correlated lists the buckets with findings from other Spectre tools
+	Correlated []string `json:"correlated,omitempty"`
 }
 
 // AnalyzeDiscovery analyzes buckets discovered from AWS
@@ -52,9 +117,23 @@ func AnalyzeDiscovery(buckets map[string]*s3.BucketInfo, config DiscoveryConfig)
 	}
 
 	regions := make(map[string]bool)
+	replicated := s3.ReplicationSources(buckets)
 
 	for name, info := range buckets {
 		discovery := analyzeBucketDiscovery(info, config)
+		// Deletion candidates that replication writes to, or that hold
+		// compliance-mode retention, are not to be deleted
+		if isDeletionCandidate(discovery.Status) {
+			if sources := replicated[name]; len(sources) > 0 {
+				discovery.markReplicationDestination(sources)
+			} else if info.ObjectLock.Compliance() {
+				discovery.excludeLocked(info.ObjectLock)
+				result.Summary.ComplianceLocked = append(result.Summary.ComplianceLocked, name)
+			}
+		}
+		if discovery.Status != StatusOK {
+			discovery.Confidence = discoveryConfidence(discovery)
+		}
 		result.Buckets[name] = discovery
 
 		// Track regions
@@ -64,6 +143,34 @@ func AnalyzeDiscovery(buckets map[string]*s3.BucketInfo, config DiscoveryConfig)
 
 		// Update summary
 		result.Summary.TotalBuckets++
+		if info.TimedOut {
+			result.Summary.TimedOutBuckets = append(result.Summary.TimedOutBuckets, name)
+		}
+		if len(info.SkippedChecks) > 0 {
+			result.Summary.DeadlineSkips = append(result.Summary.DeadlineSkips, newDeadlineSkip(name, info))
+		}
+		if discovery.External {
+			result.Summary.ExternalBuckets = append(result.Summary.ExternalBuckets, name)
+		}
+
+		if len(info.ExternalAccess) > 0 && !discovery.External {
+			result.Summary.ExternalAccess = append(result.Summary.ExternalAccess, name)
+		}
+
+		for _, f := range discovery.PolicyFindings {
+			switch f.Status {
+			case StatusWildcardPrincipal:
+				result.Summary.WildcardPrincipals = append(result.Summary.WildcardPrincipals, name)
+			case StatusCrossAccount:
+				result.Summary.CrossAccountAccess = append(result.Summary.CrossAccountAccess, name)
+			case StatusInsecureTransport:
+				result.Summary.InsecureTransport = append(result.Summary.InsecureTransport, name)
+			case StatusNoLogging:
+				result.Summary.NoLogging = append(result.Summary.NoLogging, name)
+			case StatusSensitiveDataExposed:
+				result.Summary.SensitiveDataExposed = append(result.Summary.SensitiveDataExposed, name)
+			}
+		}
 
 		switch discovery.Status {
 		case StatusOK:
@@ -76,6 +183,8 @@ func AnalyzeDiscovery(buckets map[string]*s3.BucketInfo, config DiscoveryConfig)
 			result.Summary.InactiveBuckets = append(result.Summary.InactiveBuckets, name)
 		case StatusVersionSprawl:
 			result.Summary.VersionSprawl = append(result.Summary.VersionSprawl, name)
+		case StatusReplicationDestination:
+			result.Summary.ReplicationDestinations = append(result.Summary.ReplicationDestinations, name)
 		}
 	}
 
@@ -93,6 +202,19 @@ func analyzeBucketDiscovery(info *s3.BucketInfo, config DiscoveryConfig) *Bucket
 		Recommendations: make([]string, 0),
 		BucketInfo:      info,
 	}
+	if config.External.Match(info.Name) {
+		discovery.Status = StatusOK
+		discovery.External = true
+		return discovery
+	}
+	classification, weight := config.Classification.Classify(info.Tags)
+	discovery.Classification = classification
+	tagWeight := weight
+	// Sensitive data found by Macie weighs like classified data
+	if info.SensitiveData != nil && len(info.SensitiveData.Classes) > 0 {
+		discovery.DataClasses = info.SensitiveData.Classes
+		weight = max(weight, sensitiveDataWeight)
+	}
 
 	// Factor 1: Age (20 points if older than threshold)
 	if info.AgeInDays > config.AgeThresholdDays && config.AgeThresholdDays > 0 {
@@ -103,53 +225,99 @@ func analyzeBucketDiscovery(info *s3.BucketInfo, config DiscoveryConfig) *Bucket
 
 	// Factor 2: Inactivity (50 points if no activity)
 	if info.DaysSinceActivity > config.InactivityThresholdDays && config.InactivityThresholdDays > 0 {
-		discovery.RiskScore += 50
+		points := weightDeletability(50, weight)
+		discovery.RiskScore += points
 		discovery.RiskFactors = append(discovery.RiskFactors,
 			fmt.Sprintf("No activity for %d days", info.DaysSinceActivity))
-		discovery.Recommendations = append(discovery.Recommendations,
-			"Consider archiving or deleting if not needed")
+		discovery.recommend(actionArchiveOrDelete,
+			points, bucketBytes(discovery), rules.EffortMedium)
 	}
 
 	// Factor 3: Empty bucket (30 points)
 	if info.IsEmpty {
-		discovery.RiskScore += 30
+		points := weightDeletability(30, weight)
+		discovery.RiskScore += points
 		discovery.RiskFactors = append(discovery.RiskFactors, "Empty bucket")
-		discovery.Recommendations = append(discovery.Recommendations,
-			"Delete if not needed")
+		discovery.recommend(actionDelete, points, 0, rules.EffortSmall)
 	}
 
 	// Factor 4: Deprecated tags (20 points)
 	if hasDeprecatedTags(info.Tags) {
-		discovery.RiskScore += 20
+		points := weightDeletability(20, weight)
+		discovery.RiskScore += points
 		discovery.RiskFactors = append(discovery.RiskFactors, "Has deprecated tags")
-		discovery.Recommendations = append(discovery.Recommendations,
-			"Verify if bucket is still needed")
+		discovery.recommend("Verify if bucket is still needed", points, 0, rules.EffortSmall)
 	}
 
 	// Factor 5: Version sprawl (30 points)
-	if info.VersioningEnabled && info.LifecycleRules == 0 {
+	if info.VersioningEnabled && info.LifecycleRules == 0 && !info.Skipped(s3.CheckLifecycle) {
 		discovery.RiskScore += 30
 		discovery.RiskFactors = append(discovery.RiskFactors,
 			"Versioning enabled without lifecycle rules")
-		discovery.Recommendations = append(discovery.Recommendations,
-			"Add lifecycle policy to expire old versions")
+		discovery.recommend("Add lifecycle policy to expire old versions",
+			30, versionOverhead(info), rules.EffortSmall)
 	}
 
 	// Factor 6: No encryption (40 points) - if check enabled
 	if config.CheckEncryption && info.Encryption != nil && !info.Encryption.Enabled {
-		discovery.RiskScore += 40
+		points := weightSecurity(40, weight)
+		discovery.RiskScore += points
 		discovery.RiskFactors = append(discovery.RiskFactors, "No encryption enabled")
-		discovery.Recommendations = append(discovery.Recommendations,
-			"Enable default encryption (AES256 or KMS)")
+		discovery.recommend("Enable default encryption (AES256 or KMS)",
+			points, 0, rules.EffortSmall)
 	}
 
-	// Factor 7: Public access (60 points - high risk) - if check enabled
+	// Factor 7: Public access (60 points - high risk) - if check enabled.
+	// Legacy ACL grants are named, since they are fixed differently.
 	if config.CheckPublicAccess && info.PublicAccess != nil && info.PublicAccess.IsPublic {
-		discovery.RiskScore += 60
-		discovery.RiskFactors = append(discovery.RiskFactors, "Public access enabled")
-		discovery.Recommendations = append(discovery.Recommendations,
-			"Review and restrict public access if not required")
+		points := weightSecurity(60, weight)
+		discovery.RiskScore += points
+		if grants := info.PublicAccess.PublicACLGrants; len(grants) > 0 {
+			names := make([]string, len(grants))
+			for k, grant := range grants {
+				names[k] = grant.String()
+			}
+			discovery.RiskFactors = append(discovery.RiskFactors,
+				"Public ACL grants: "+strings.Join(names, ", "))
+			discovery.recommend("Remove the AllUsers and AuthenticatedUsers ACL grants and turn on IgnorePublicAcls",
+				points, 0, rules.EffortSmall)
+		} else {
+			discovery.RiskFactors = append(discovery.RiskFactors, "Public access enabled")
+			discovery.recommend("Review and restrict public access if not required",
+				points, 0, rules.EffortMedium)
+		}
+	}
+
+	// Factor 8: External access confirmed by IAM Access Analyzer
+	discovery.scoreExternalAccess(info, config, weight)
+
+	// Policy findings are reported on their own rather than scored
+	if config.CheckPolicy && info.Policy != nil {
+		discovery.PolicyFindings = analyzePolicy(info.Policy)
+	}
+	if config.CheckLogging {
+		if f := analyzeLogging(info, config.ProductionTags); f != nil {
+			discovery.PolicyFindings = append(discovery.PolicyFindings, *f)
+		}
+	}
+	if f := analyzeSensitiveData(info, config); f != nil {
+		discovery.PolicyFindings = append(discovery.PolicyFindings, *f)
+	}
+
+	// Classified data: security weighs more, deletion needs the data owner
+	if tagWeight != 1 {
+		discovery.RiskFactors = append(discovery.RiskFactors,
+			fmt.Sprintf("Classified %s (weight %.1f)", classification, tagWeight))
+	}
+	if len(discovery.DataClasses) > 0 {
+		discovery.RiskFactors = append(discovery.RiskFactors,
+			fmt.Sprintf("Macie found %s (weight %.1f)", strings.Join(discovery.DataClasses, ", "), weight))
+	}
+	if weight > 1 {
+		discovery.recommend("Confirm retention requirements with the data owner before deleting",
+			0, 0, rules.EffortMedium)
 	}
+	discovery.sortRecommendations()
 
 	// Determine status based on risk score and factors
 	threshold := config.RiskScoreThreshold
@@ -168,6 +336,9 @@ func analyzeBucketDiscovery(info *s3.BucketInfo, config DiscoveryConfig) *Bucket
 		} else {
 			discovery.Status = StatusRisky
 		}
+	} else if info.TimedOut {
+		// The checks that did not finish might have found something
+		discovery.Status = StatusTimedOut
 	} else {
 		discovery.Status = StatusOK
 	}
@@ -1,6 +1,7 @@
 package analyzer
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/ppiankov/s3spectre/internal/s3"
@@ -28,6 +29,28 @@ func TestAnalyzeDiscovery_HealthyBucket(t *testing.T) {
 	}
 }
 
+func TestAnalyzeDiscovery_TimedOutBucket(t *testing.T) {
+	buckets := map[string]*s3.BucketInfo{
+		"slow": {Name: "slow", Exists: true, Region: "us-east-1", TimedOut: true},
+		"slow-empty": {
+			Name: "slow-empty", Exists: true, Region: "us-east-1", TimedOut: true,
+			IsEmpty: true, DaysSinceActivity: 200, AgeInDays: 400,
+		},
+	}
+
+	result := AnalyzeDiscovery(buckets, DiscoveryConfig{AgeThresholdDays: 365, InactivityThresholdDays: 180, RiskScoreThreshold: 100})
+
+	if got := result.Buckets["slow"].Status; got != StatusTimedOut {
+		t.Errorf("expected status %s, got %s", StatusTimedOut, got)
+	}
+	if got := result.Buckets["slow-empty"].Status; got != StatusUnusedBucket {
+		t.Errorf("findings from finished checks should be kept, got %s", got)
+	}
+	if result.Summary.HealthyBuckets != 0 || len(result.Summary.TimedOutBuckets) != 2 {
+		t.Errorf("summary = %+v", result.Summary)
+	}
+}
+
 func TestAnalyzeDiscovery_RegionTracking(t *testing.T) {
 	buckets := map[string]*s3.BucketInfo{
 		"a": {Name: "a", Region: "us-east-1"},
@@ -66,6 +89,106 @@ func TestAnalyzeDiscovery_UnusedBucket(t *testing.T) {
 	}
 }
 
+func TestAnalyzeDiscovery_ComplianceLockedBucket(t *testing.T) {
+	compliance := &s3.ObjectLockInfo{Enabled: true, Mode: "COMPLIANCE", Years: 7}
+	buckets := map[string]*s3.BucketInfo{
+		"old-ledger": {
+			Name:              "old-ledger",
+			DaysSinceActivity: 400,
+			AgeInDays:         900,
+			ObjectLock:        compliance,
+		},
+		"governed": {
+			Name:              "governed",
+			DaysSinceActivity: 400,
+			AgeInDays:         900,
+			ObjectLock:        &s3.ObjectLockInfo{Enabled: true, Mode: "GOVERNANCE", Days: 30},
+		},
+		"active-ledger": {
+			Name:       "active-ledger",
+			ObjectLock: compliance,
+		},
+	}
+
+	result := AnalyzeDiscovery(buckets, DiscoveryConfig{
+		AgeThresholdDays:        365,
+		InactivityThresholdDays: 180,
+		RiskScoreThreshold:      50,
+	})
+
+	if len(result.Summary.ComplianceLocked) != 1 || result.Summary.ComplianceLocked[0] != "old-ledger" {
+		t.Fatalf("expected only old-ledger to be compliance locked, got %v", result.Summary.ComplianceLocked)
+	}
+	d := result.Buckets["old-ledger"]
+	if d.Status != StatusInactive {
+		t.Errorf("expected status %s, got %s", StatusInactive, d.Status)
+	}
+	if !strings.Contains(strings.Join(d.RiskFactors, "; "), "retains objects for 2555 days") {
+		t.Errorf("expected the Object Lock risk factor, got %v", d.RiskFactors)
+	}
+	if len(d.Recommendations) == 0 || !strings.HasPrefix(d.Recommendations[0], "Exclude from cleanup") {
+		t.Errorf("expected excluding from cleanup first, got %v", d.Recommendations)
+	}
+	for _, rec := range d.Recommendations {
+		if rec == actionArchiveOrDelete || rec == actionDelete {
+			t.Errorf("a compliance-locked bucket should not be recommended for deletion, got %v", d.Recommendations)
+		}
+	}
+	if recs := result.Buckets["governed"].Recommendations; len(recs) == 0 || recs[0] != actionArchiveOrDelete {
+		t.Errorf("governance retention can be bypassed and keeps its recommendations, got %v", recs)
+	}
+}
+
+func TestAnalyzeDiscovery_ReplicationDestination(t *testing.T) {
+	buckets := map[string]*s3.BucketInfo{
+		"orders": {
+			Name: "orders",
+			Replication: &s3.ReplicationInfo{Rules: []s3.ReplicationRule{
+				{Enabled: true, Destination: "orders-dr"},
+				{Enabled: false, Destination: "orders-old"},
+			}},
+		},
+		"orders-dr": {
+			Name:              "orders-dr",
+			DaysSinceActivity: 400,
+			AgeInDays:         900,
+		},
+		"orders-old": {
+			Name:              "orders-old",
+			DaysSinceActivity: 400,
+			AgeInDays:         900,
+		},
+	}
+
+	result := AnalyzeDiscovery(buckets, DiscoveryConfig{
+		AgeThresholdDays:        365,
+		InactivityThresholdDays: 180,
+		RiskScoreThreshold:      50,
+	})
+
+	if len(result.Summary.ReplicationDestinations) != 1 || result.Summary.ReplicationDestinations[0] != "orders-dr" {
+		t.Fatalf("expected only orders-dr as a replication destination, got %v", result.Summary.ReplicationDestinations)
+	}
+	d := result.Buckets["orders-dr"]
+	if d.Status != StatusReplicationDestination {
+		t.Errorf("expected status %s, got %s", StatusReplicationDestination, d.Status)
+	}
+	if !strings.Contains(strings.Join(d.RiskFactors, "; "), "Replication destination of orders") {
+		t.Errorf("expected the replication risk factor, got %v", d.RiskFactors)
+	}
+	if len(d.Recommendations) == 0 || !strings.HasPrefix(d.Recommendations[0], "Keep while replication from orders") {
+		t.Errorf("expected keeping the destination first, got %v", d.Recommendations)
+	}
+	for _, rec := range d.Recommendations {
+		if rec == actionArchiveOrDelete || rec == actionDelete {
+			t.Errorf("a replication destination should not be recommended for deletion, got %v", d.Recommendations)
+		}
+	}
+	if status := result.Buckets["orders-old"].Status; status != StatusInactive {
+		t.Errorf("a disabled rule's destination keeps its status, got %s", status)
+	}
+}
+
 func TestAnalyzeBucketDiscovery_AgeFactor(t *testing.T) {
 	info := &s3.BucketInfo{Name: "old", AgeInDays: 500}
 	config := DiscoveryConfig{AgeThresholdDays: 365, RiskScoreThreshold: 100}
@@ -139,6 +262,20 @@ func TestAnalyzeBucketDiscovery_VersionSprawlFactor(t *testing.T) {
 	}
 }
 
+func TestAnalyzeBucketDiscovery_LifecycleSkippedNoFactor(t *testing.T) {
+	info := &s3.BucketInfo{
+		Name:              "versioned",
+		VersioningEnabled: true,
+		SkippedChecks:     []string{s3.CheckLifecycle},
+	}
+
+	d := analyzeBucketDiscovery(info, DiscoveryConfig{RiskScoreThreshold: 100})
+
+	if d.RiskScore != 0 {
+		t.Errorf("a skipped lifecycle check is not evidence of version sprawl, got risk score %d", d.RiskScore)
+	}
+}
+
 func TestAnalyzeBucketDiscovery_VersioningWithLifecycleNoFactor(t *testing.T) {
 	info := &s3.BucketInfo{
 		Name:              "versioned",
@@ -196,6 +333,29 @@ func TestAnalyzeBucketDiscovery_PublicAccessFactor(t *testing.T) {
 	}
 }
 
+func TestAnalyzeBucketDiscovery_PublicACLFactor(t *testing.T) {
+	info := &s3.BucketInfo{
+		Name: "legacy-site",
+		PublicAccess: &s3.PublicAccessInfo{
+			IsPublic:        true,
+			PublicACLGrants: []s3.ACLGrant{{Grantee: "AllUsers", Permission: "READ"}},
+		},
+	}
+	config := DiscoveryConfig{CheckPublicAccess: true, RiskScoreThreshold: 100}
+
+	d := analyzeBucketDiscovery(info, config)
+
+	if d.RiskScore != 60 {
+		t.Errorf("expected risk score 60 for a public ACL, got %d", d.RiskScore)
+	}
+	if len(d.RiskFactors) != 1 || d.RiskFactors[0] != "Public ACL grants: AllUsers READ" {
+		t.Errorf("expected the ACL risk factor, got %v", d.RiskFactors)
+	}
+	if len(d.Recommendations) != 1 || !strings.Contains(d.Recommendations[0], "IgnorePublicAcls") {
+		t.Errorf("expected the ACL recommendation, got %v", d.Recommendations)
+	}
+}
+
 func TestAnalyzeBucketDiscovery_PublicAccessCheckDisabled(t *testing.T) {
 	info := &s3.BucketInfo{
 		Name:         "public",
@@ -390,3 +550,58 @@ func TestAnalyzeDiscovery_SummaryCategories(t *testing.T) {
 		t.Errorf("expected 2 regions, got %d", result.Summary.TotalRegions)
 	}
 }
+
+func TestGroupByTag(t *testing.T) {
+	buckets := map[string]*s3.BucketInfo{
+		"fin-a":   {Name: "fin-a", Tags: map[string]string{"cost-center": "finance"}, TotalSize: 100},
+		"fin-b":   {Name: "fin-b", Tags: map[string]string{"cost-center": "finance"}, IsEmpty: true, DaysSinceActivity: 400, AgeInDays: 400},
+		"eng-a":   {Name: "eng-a", Tags: map[string]string{"cost-center": "eng"}},
+		"no-tags": {Name: "no-tags"},
+	}
+	result := AnalyzeDiscovery(buckets, DiscoveryConfig{
+		AgeThresholdDays:        365,
+		InactivityThresholdDays: 180,
+		RiskScoreThreshold:      100,
+	})
+
+	groups := GroupByTag(result, "cost-center")
+	if len(groups) != 3 {
+		t.Fatalf("expected 3 groups, got %d", len(groups))
+	}
+	if groups[0].Value != "eng" || groups[1].Value != "finance" || groups[2].Value != UntaggedGroup {
+		t.Fatalf("unexpected group order: %s, %s, %s", groups[0].Value, groups[1].Value, groups[2].Value)
+	}
+	finance := groups[1]
+	if finance.TotalBuckets != 2 || finance.Healthy != 1 || finance.Unused != 1 {
+		t.Errorf("unexpected finance totals: %+v", finance)
+	}
+	if finance.TotalSize != 100 {
+		t.Errorf("expected finance size 100, got %d", finance.TotalSize)
+	}
+}
+
+func TestAddCustomFindings(t *testing.T) {
+	result := &DiscoveryResult{
+		Buckets: map[string]*BucketDiscovery{
+			"logs": {Name: "logs", Status: StatusOK},
+		},
+	}
+	AddCustomFindings(result, map[string][]CustomFinding{
+		"logs":    {{Plugin: "b", Rule: "R2", Severity: "low"}, {Plugin: "a", Rule: "R1", Severity: "high"}},
+		"missing": {{Plugin: "a", Rule: "R1", Severity: "high"}},
+	})
+
+	logs := result.Buckets["logs"]
+	if len(logs.CustomFindings) != 2 || logs.CustomFindings[0].Plugin != "a" {
+		t.Fatalf("expected sorted custom findings, got %+v", logs.CustomFindings)
+	}
+	if logs.Status != StatusOK {
+		t.Errorf("custom findings must not change status, got %s", logs.Status)
+	}
+	if result.Summary.CustomFindings != 2 {
+		t.Errorf("expected 2 custom findings in summary, got %d", result.Summary.CustomFindings)
+	}
+	if id := logs.CustomFindings[0].RuleID(); id != "plugin/a/R1" {
+		t.Errorf("RuleID() = %q, want plugin/a/R1", id)
+	}
+}
@@ -0,0 +1,133 @@
+package analyzer
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/ppiankov/s3spectre/internal/s3"
+)
+
+// envSibling finds a candidate in the same environment family as a missing
+// bucket, for a bucket that exists in one environment but not another. It
+// returns the sibling, its environment and the missing bucket's environment,
+// or empty strings when no candidate is a sibling.
+func envSibling(envs s3.Environments, bucket string, candidates []string) (sibling, siblingEnv, env string) {
+	family, env, ok := envs.Family(bucket)
+	if !ok {
+		return "", "", ""
+	}
+	for _, candidate := range candidates {
+		if candidateFamily, candidateEnv, ok := envs.Family(candidate); ok && candidateFamily == family && candidateEnv != env {
+			return candidate, candidateEnv, env
+		}
+	}
+	return "", "", ""
+}
+
+// envUnknown is the value of a setting that could not be read; a setting
+// unknown for any member of a family is not compared
+const envUnknown = "unknown"
+
+// envSetting is one bucket setting compared across an environment family
+type envSetting struct {
+	name  string
+	value func(*s3.BucketInfo) string
+}
+
+var envSettings = []envSetting{
+	{name: "encryption", value: func(info *s3.BucketInfo) string {
+		if info.Encryption == nil {
+			return envUnknown
+		}
+		if !info.Encryption.Enabled {
+			return "none"
+		}
+		if info.Encryption.Algorithm == "" {
+			return "enabled"
+		}
+		return info.Encryption.Algorithm
+	}},
+	{name: "versioning", value: func(info *s3.BucketInfo) string {
+		if info.VersioningEnabled {
+			return "enabled"
+		}
+		return "disabled"
+	}},
+	{name: "lifecycle", value: func(info *s3.BucketInfo) string {
+		if info.LifecycleRules == 0 {
+			return "none"
+		}
+		return "configured"
+	}},
+}
+
+// AnalyzeEnvironmentDrift adds an ENV_DRIFT account finding for each
+// environment family, buckets whose names differ only in an environment
+// segment, whose members disagree on encryption, versioning or lifecycle.
+// Buckets that were not fully inspected, and external buckets, are left out.
+func AnalyzeEnvironmentDrift(result *DiscoveryResult, envs s3.Environments) {
+	families := make(map[string]map[string]*s3.BucketInfo)
+	for name, discovery := range result.Buckets {
+		if discovery == nil || discovery.External {
+			continue
+		}
+		info := discovery.BucketInfo
+		if info == nil || !info.Exists || info.TimedOut || info.Interrupted || info.Error != "" {
+			continue
+		}
+		family, env, ok := envs.Family(name)
+		if !ok {
+			continue
+		}
+		if families[family] == nil {
+			families[family] = make(map[string]*s3.BucketInfo)
+		}
+		families[family][env] = info
+	}
+
+	names := make([]string, 0, len(families))
+	for family := range families {
+		names = append(names, family)
+	}
+	sort.Strings(names)
+	for _, family := range names {
+		members := families[family]
+		if len(members) < 2 {
+			continue
+		}
+		envNames := make([]string, 0, len(members))
+		for env := range members {
+			envNames = append(envNames, env)
+		}
+		sort.Strings(envNames)
+
+		var diffs []string
+		for _, setting := range envSettings {
+			values := make([]string, len(envNames))
+			differs, unknown := false, false
+			for i, env := range envNames {
+				value := setting.value(members[env])
+				values[i] = env + ": " + value
+				differs = differs || value != setting.value(members[envNames[0]])
+				unknown = unknown || value == envUnknown
+			}
+			if differs && !unknown {
+				diffs = append(diffs, fmt.Sprintf("%s (%s)", setting.name, strings.Join(values, ", ")))
+			}
+		}
+		if len(diffs) == 0 {
+			continue
+		}
+		buckets := make([]string, len(envNames))
+		for i, env := range envNames {
+			buckets[i] = members[env].Name
+		}
+		result.AccountFindings = append(result.AccountFindings, AccountAnalysis{
+			Status:  StatusEnvDrift,
+			Message: "Environments differ in " + strings.Join(diffs, "; "),
+			Buckets: buckets,
+			Family:  family,
+		})
+	}
+}
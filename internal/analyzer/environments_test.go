@@ -0,0 +1,62 @@
+package analyzer
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/ppiankov/s3spectre/internal/s3"
+	"github.com/ppiankov/s3spectre/internal/scanner"
+)
+
+func TestAnalyzeEnvironmentDrift(t *testing.T) {
+	discovered := func(info *s3.BucketInfo) *BucketDiscovery {
+		info.Exists = true
+		return &BucketDiscovery{Name: info.Name, BucketInfo: info}
+	}
+	result := &DiscoveryResult{Buckets: map[string]*BucketDiscovery{
+		"orders-prod": discovered(&s3.BucketInfo{Name: "orders-prod", VersioningEnabled: true, LifecycleRules: 2,
+			Encryption: &s3.EncryptionInfo{Enabled: true, Algorithm: "aws:kms"}}),
+		"orders-dev": discovered(&s3.BucketInfo{Name: "orders-dev", VersioningEnabled: true, LifecycleRules: 1,
+			Encryption: &s3.EncryptionInfo{}}),
+		// Unknown encryption is not compared: no finding
+		"media-prod": discovered(&s3.BucketInfo{Name: "media-prod", Encryption: &s3.EncryptionInfo{Enabled: true, Algorithm: "AES256"}}),
+		"media-dev":  discovered(&s3.BucketInfo{Name: "media-dev"}),
+		// Consistent family: no finding
+		"logs-prod": discovered(&s3.BucketInfo{Name: "logs-prod", LifecycleRules: 1}),
+		"logs-dev":  discovered(&s3.BucketInfo{Name: "logs-dev", LifecycleRules: 3}),
+		// Not fully inspected: left out
+		"images-prod": discovered(&s3.BucketInfo{Name: "images-prod", TimedOut: true}),
+		"images-dev":  discovered(&s3.BucketInfo{Name: "images-dev", VersioningEnabled: true}),
+		"standalone":  discovered(&s3.BucketInfo{Name: "standalone"}),
+	}}
+
+	AnalyzeEnvironmentDrift(result, s3.NewEnvironments(nil))
+
+	if len(result.AccountFindings) != 1 {
+		t.Fatalf("expected one ENV_DRIFT finding, got %+v", result.AccountFindings)
+	}
+	f := result.AccountFindings[0]
+	if f.Status != StatusEnvDrift || f.Family != "orders-*" {
+		t.Errorf("unexpected finding %+v", f)
+	}
+	if !reflect.DeepEqual(f.Buckets, []string{"orders-dev", "orders-prod"}) {
+		t.Errorf("unexpected buckets %v", f.Buckets)
+	}
+	if want := "Environments differ in encryption (dev: none, prod: aws:kms)"; f.Message != want {
+		t.Errorf("message = %q, want %q", f.Message, want)
+	}
+}
+
+func TestAnalyze_MissingBucketEnvSibling(t *testing.T) {
+	refs := []scanner.Reference{{Bucket: "orders-prod", File: "app.py", Line: 3}}
+	bucketInfo := map[string]*s3.BucketInfo{
+		"orders-prod": {Name: "orders-prod", NearMisses: []string{"orders-dev"}},
+	}
+
+	result := Analyze(refs, bucketInfo, Config{Environments: s3.NewEnvironments(nil)})
+
+	want := `Bucket referenced in code but does not exist in AWS; "orders-dev" exists for dev but not for prod`
+	if got := result.Buckets["orders-prod"].Message; got != want {
+		t.Errorf("message = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,112 @@
+package analyzer
+
+import (
+	"fmt"
+	"path"
+
+	"github.com/ppiankov/s3spectre/internal/scanner"
+)
+
+// Messages explaining why an external bucket was not checked
+const (
+	ExternalMessage      = "External bucket; existence and usage checks skipped"
+	PublicDatasetMessage = "Well-known public dataset; existence and usage checks skipped"
+)
+
+// ExternalBuckets matches third-party buckets, such as public datasets, that
+// the code reads but the account does not own. They are reported as external
+// instead of being checked, so they never show up as missing or unused.
+type ExternalBuckets struct {
+	Patterns []string
+	// Datasets are public dataset patterns, from the built-in registry and
+	// its configured extensions
+	Datasets []string
+}
+
+// NewExternalBuckets validates shell-style glob patterns. It returns nil when
+// none are configured.
+func NewExternalBuckets(patterns []string) (*ExternalBuckets, error) {
+	if len(patterns) == 0 {
+		return nil, nil
+	}
+	if err := validatePatterns(patterns); err != nil {
+		return nil, err
+	}
+	return &ExternalBuckets{Patterns: patterns}, nil
+}
+
+func validatePatterns(patterns []string) error {
+	for _, pattern := range patterns {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid external bucket pattern %q: %w", pattern, err)
+		}
+	}
+	return nil
+}
+
+// Match reports whether a bucket is external. A nil set matches nothing.
+func (e *ExternalBuckets) Match(bucket string) bool {
+	return e.message(bucket) != ""
+}
+
+// message explains why a bucket is external, or is empty when it is not
+func (e *ExternalBuckets) message(bucket string) string {
+	if e == nil {
+		return ""
+	}
+	if matchAny(e.Patterns, bucket) {
+		return ExternalMessage
+	}
+	if matchAny(e.Datasets, bucket) {
+		return PublicDatasetMessage
+	}
+	return ""
+}
+
+func matchAny(patterns []string, bucket string) bool {
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(pattern, bucket); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// Internal returns the references to buckets that are not external, the
+// ones worth inspecting
+func (e *ExternalBuckets) Internal(refs []scanner.Reference) []scanner.Reference {
+	if e == nil {
+		return refs
+	}
+	internal := make([]scanner.Reference, 0, len(refs))
+	for _, ref := range refs {
+		if !e.Match(ref.Bucket) {
+			internal = append(internal, ref)
+		}
+	}
+	return internal
+}
+
+// addExternalBuckets records referenced external buckets that were not
+// inspected as OK and external
+func addExternalBuckets(result *Result, refs []scanner.Reference, external *ExternalBuckets) {
+	for _, ref := range refs {
+		message := external.message(ref.Bucket)
+		if message == "" {
+			continue
+		}
+		if _, ok := result.Buckets[ref.Bucket]; ok {
+			continue
+		}
+		result.Buckets[ref.Bucket] = &BucketAnalysis{
+			Name:             ref.Bucket,
+			Status:           StatusOK,
+			Message:          message,
+			ReferencedInCode: true,
+			External:         true,
+		}
+		result.Summary.TotalBuckets++
+		result.Summary.OKBuckets++
+		result.Summary.ExternalBuckets = append(result.Summary.ExternalBuckets, ref.Bucket)
+	}
+}
@@ -0,0 +1,100 @@
+package analyzer
+
+import (
+	"testing"
+
+	"github.com/ppiankov/s3spectre/internal/s3"
+	"github.com/ppiankov/s3spectre/internal/scanner"
+)
+
+func TestNewExternalBuckets(t *testing.T) {
+	external, err := NewExternalBuckets(nil)
+	if err != nil || external != nil {
+		t.Fatalf("no patterns: got %v, %v", external, err)
+	}
+	if external.Match("landsat-pds") {
+		t.Error("nil set should match nothing")
+	}
+	if _, err := NewExternalBuckets([]string{"[bad"}); err == nil {
+		t.Error("expected error for invalid pattern")
+	}
+
+	external, err = NewExternalBuckets([]string{"landsat-pds", "noaa-*"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for bucket, want := range map[string]bool{"landsat-pds": true, "noaa-goes16": true, "prod-data": false} {
+		if got := external.Match(bucket); got != want {
+			t.Errorf("Match(%q) = %v, want %v", bucket, got, want)
+		}
+	}
+}
+
+func TestAnalyze_ExternalBucket(t *testing.T) {
+	external, _ := NewExternalBuckets([]string{"landsat-pds"})
+	refs := []scanner.Reference{
+		{Bucket: "landsat-pds", File: "etl.py", Line: 3},
+		{Bucket: "landsat-pds", Prefix: "c1/", File: "etl.py", Line: 9},
+		{Bucket: "prod-data", File: "app.py", Line: 10},
+	}
+	if got := external.Internal(refs); len(got) != 1 || got[0].Bucket != "prod-data" {
+		t.Fatalf("Internal() = %+v", got)
+	}
+	bucketInfo := map[string]*s3.BucketInfo{
+		"prod-data": {Name: "prod-data", Exists: false},
+	}
+
+	result := Analyze(refs, bucketInfo, Config{CheckUnused: true, External: external})
+
+	analysis := result.Buckets["landsat-pds"]
+	if analysis == nil || analysis.Status != StatusOK || !analysis.External {
+		t.Fatalf("expected external OK bucket, got %+v", analysis)
+	}
+	if result.Summary.TotalBuckets != 2 || len(result.Summary.ExternalBuckets) != 1 {
+		t.Errorf("summary: got %+v", result.Summary)
+	}
+	if len(result.Summary.MissingBuckets) != 1 || result.Summary.MissingBuckets[0] != "prod-data" {
+		t.Errorf("non-external buckets should still be checked: got %+v", result.Summary.MissingBuckets)
+	}
+}
+
+func TestAnalyzeDiscovery_ExternalBucket(t *testing.T) {
+	external, _ := NewExternalBuckets([]string{"public-*"})
+	buckets := map[string]*s3.BucketInfo{
+		"public-dataset": {Name: "public-dataset", IsEmpty: true, DaysSinceActivity: 900},
+	}
+
+	result := AnalyzeDiscovery(buckets, DiscoveryConfig{InactivityThresholdDays: 90, RiskScoreThreshold: 50, External: external})
+
+	discovery := result.Buckets["public-dataset"]
+	if discovery.Status != StatusOK || !discovery.External || discovery.RiskScore != 0 {
+		t.Errorf("expected unscored external bucket, got %+v", discovery)
+	}
+	if result.Summary.HealthyBuckets != 1 || len(result.Summary.ExternalBuckets) != 1 {
+		t.Errorf("summary: got %+v", result.Summary)
+	}
+}
+
+func TestAnalyze_PublicDataset(t *testing.T) {
+	var none *ExternalBuckets
+	external, err := none.WithPublicDatasets([]string{"partner-open-*"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	refs := []scanner.Reference{
+		{Bucket: "sentinel-s2-l2a", File: "etl.py", Line: 3},
+		{Bucket: "partner-open-weather", File: "etl.py", Line: 5},
+	}
+
+	result := Analyze(refs, map[string]*s3.BucketInfo{}, Config{External: external})
+
+	if got := result.Buckets["sentinel-s2-l2a"]; got == nil || !got.External || got.Message != PublicDatasetMessage {
+		t.Errorf("registry dataset: got %+v", got)
+	}
+	if got := result.Buckets["partner-open-weather"]; got == nil || !got.External {
+		t.Errorf("extra dataset: got %+v", got)
+	}
+	if _, err := none.WithPublicDatasets([]string{"[bad"}); err == nil {
+		t.Error("expected error for invalid pattern")
+	}
+}
@@ -0,0 +1,95 @@
+package analyzer
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/ppiankov/s3spectre/internal/s3"
+	"github.com/ppiankov/s3spectre/internal/scanner"
+)
+
+// VaultAnalysis contains analysis results for a Glacier vault
+type VaultAnalysis struct {
+	Name             string `json:"name"`
+	Region           string `json:"region,omitempty"`
+	Status           Status `json:"status"`
+	Message          string `json:"message,omitempty"`
+	ReferencedInCode bool   `json:"referenced_in_code"`
+	ExistsInAWS      bool   `json:"exists_in_aws"`
+	NumberOfArchives int64  `json:"number_of_archives,omitempty"`
+	SizeInBytes      int64  `json:"size_in_bytes,omitempty"`
+	Fingerprint      string `json:"fingerprint,omitempty"`
+}
+
+// AnalyzeVaults cross-references Glacier vault references in code with the
+// vaults in AWS and adds the results to result. A reference with a region
+// (a vault ARN) only matches a vault in that region. Vaults are keyed by
+// name, or by ARN when the name exists in more than one region.
+func AnalyzeVaults(result *Result, refs []scanner.VaultReference, vaults []s3.VaultInfo) {
+	result.Vaults = make(map[string]*VaultAnalysis)
+
+	referenced := func(v s3.VaultInfo) bool {
+		for _, ref := range refs {
+			if ref.Vault == v.Name && (ref.Region == "" || ref.Region == v.Region) {
+				return true
+			}
+		}
+		return false
+	}
+
+	names := make(map[string]int)
+	for _, v := range vaults {
+		names[v.Name]++
+	}
+	found := make(map[string]bool)
+	for _, v := range vaults {
+		analysis := &VaultAnalysis{
+			Name:             v.Name,
+			Region:           v.Region,
+			ExistsInAWS:      true,
+			ReferencedInCode: referenced(v),
+			NumberOfArchives: v.NumberOfArchives,
+			SizeInBytes:      v.SizeInBytes,
+		}
+		key := v.Name
+		if names[v.Name] > 1 {
+			key = v.ARN
+		}
+		if analysis.ReferencedInCode {
+			analysis.Status = StatusOK
+			analysis.Message = "Vault exists and is referenced in code"
+		} else {
+			analysis.Status = StatusOrphanedVault
+			analysis.Message = fmt.Sprintf("Vault holds %d archives but is not referenced in code", v.NumberOfArchives)
+			result.Summary.OrphanedVaults = append(result.Summary.OrphanedVaults, key)
+		}
+		result.Vaults[key] = analysis
+		found[v.Name] = true
+		found[v.Region+"|"+v.Name] = true
+	}
+
+	// A missing vault with a region is keyed region/name, so an ARN for a
+	// vault absent from one region is reported even if the name exists
+	// elsewhere
+	for _, ref := range refs {
+		lookup, key := ref.Vault, ref.Vault
+		if ref.Region != "" {
+			lookup = ref.Region + "|" + ref.Vault
+			key = ref.Region + "/" + ref.Vault
+		}
+		if found[lookup] || result.Vaults[key] != nil {
+			continue
+		}
+		result.Vaults[key] = &VaultAnalysis{
+			Name:             ref.Vault,
+			Region:           ref.Region,
+			Status:           StatusMissingVault,
+			Message:          "Vault referenced in code but does not exist in AWS",
+			ReferencedInCode: true,
+		}
+		result.Summary.MissingVaults = append(result.Summary.MissingVaults, key)
+	}
+
+	sort.Strings(result.Summary.OrphanedVaults)
+	sort.Strings(result.Summary.MissingVaults)
+}
@@ -0,0 +1,71 @@
+package analyzer
+
+import (
+	"testing"
+
+	"github.com/ppiankov/s3spectre/internal/s3"
+	"github.com/ppiankov/s3spectre/internal/scanner"
+)
+
+func TestAnalyzeVaults(t *testing.T) {
+	vaults := []s3.VaultInfo{
+		{Name: "backups", Region: "us-east-1", ARN: "arn:aws:glacier:us-east-1:123456789012:vaults/backups"},
+		{Name: "legacy", Region: "us-east-1", ARN: "arn:aws:glacier:us-east-1:123456789012:vaults/legacy", NumberOfArchives: 12},
+		{Name: "dr", Region: "us-east-1", ARN: "arn:aws:glacier:us-east-1:123456789012:vaults/dr"},
+		{Name: "dr", Region: "eu-west-1", ARN: "arn:aws:glacier:eu-west-1:123456789012:vaults/dr"},
+	}
+	refs := []scanner.VaultReference{
+		{Vault: "backups", File: "main.py", Line: 3},
+		{Vault: "dr", Region: "eu-west-1", File: "dr.yaml", Line: 7},
+		{Vault: "restore-tmp", File: "main.py", Line: 9},
+	}
+	result := &Result{Buckets: map[string]*BucketAnalysis{}}
+	AnalyzeVaults(result, refs, vaults)
+
+	if got := result.Vaults["backups"]; got == nil || got.Status != StatusOK {
+		t.Fatalf("expected backups OK, got %+v", got)
+	}
+	if got := result.Vaults["legacy"]; got == nil || got.Status != StatusOrphanedVault || got.NumberOfArchives != 12 {
+		t.Fatalf("expected legacy orphaned, got %+v", got)
+	}
+	// The ARN reference only matches the vault in its region
+	if got := result.Vaults["arn:aws:glacier:eu-west-1:123456789012:vaults/dr"]; got == nil || got.Status != StatusOK {
+		t.Fatalf("expected eu-west-1 dr OK, got %+v", got)
+	}
+	if got := result.Vaults["arn:aws:glacier:us-east-1:123456789012:vaults/dr"]; got == nil || got.Status != StatusOrphanedVault {
+		t.Fatalf("expected us-east-1 dr orphaned, got %+v", got)
+	}
+	if got := result.Vaults["restore-tmp"]; got == nil || got.Status != StatusMissingVault || got.ExistsInAWS {
+		t.Fatalf("expected restore-tmp missing, got %+v", got)
+	}
+
+	if len(result.Summary.OrphanedVaults) != 2 || result.Summary.OrphanedVaults[0] != "arn:aws:glacier:us-east-1:123456789012:vaults/dr" {
+		t.Fatalf("unexpected orphaned summary: %v", result.Summary.OrphanedVaults)
+	}
+	if len(result.Summary.MissingVaults) != 1 || result.Summary.MissingVaults[0] != "restore-tmp" {
+		t.Fatalf("unexpected missing summary: %v", result.Summary.MissingVaults)
+	}
+}
+
+func TestAnalyzeVaults_MissingInRegion(t *testing.T) {
+	vaults := []s3.VaultInfo{
+		{Name: "dr", Region: "us-east-1", ARN: "arn:aws:glacier:us-east-1:123456789012:vaults/dr"},
+	}
+	refs := []scanner.VaultReference{
+		{Vault: "dr", Region: "us-east-1", File: "main.tf", Line: 2},
+		{Vault: "dr", Region: "eu-west-1", File: "dr.yaml", Line: 7},
+	}
+
+	result := &Result{}
+	AnalyzeVaults(result, refs, vaults)
+
+	if got := result.Vaults["dr"]; got == nil || got.Status != StatusOK {
+		t.Fatalf("expected dr OK, got %+v", got)
+	}
+	if got := result.Vaults["eu-west-1/dr"]; got == nil || got.Status != StatusMissingVault || got.Region != "eu-west-1" {
+		t.Fatalf("expected dr missing in eu-west-1, got %+v", got)
+	}
+	if len(result.Summary.MissingVaults) != 1 || result.Summary.MissingVaults[0] != "eu-west-1/dr" {
+		t.Fatalf("unexpected missing summary: %v", result.Summary.MissingVaults)
+	}
+}
@@ -0,0 +1,73 @@
+package analyzer
+
+import "sort"
+
+// UntaggedGroup is the group value used for buckets missing the grouping tag
+const UntaggedGroup = "(untagged)"
+
+// TagGroup summarizes discovery results for buckets sharing one tag value
+type TagGroup struct {
+	Value         string   `json:"value"`
+	TotalBuckets  int      `json:"total_buckets"`
+	Healthy       int      `json:"healthy"`
+	Unused        int      `json:"unused"`
+	Risky         int      `json:"risky"`
+	Inactive      int      `json:"inactive"`
+	VersionSprawl int      `json:"version_sprawl"`
+	TotalSize     int64    `json:"total_size,omitempty"`
+	Buckets       []string `json:"buckets"`
+}
+
+// GroupByTag groups discovery results by the value of the given tag key.
+// Groups are sorted by value, with untagged buckets last.
+func GroupByTag(result *DiscoveryResult, key string) []TagGroup {
+	groups := make(map[string]*TagGroup)
+
+	for name, discovery := range result.Buckets {
+		value := UntaggedGroup
+		if discovery.BucketInfo != nil {
+			if v, ok := discovery.BucketInfo.Tags[key]; ok && v != "" {
+				value = v
+			}
+		}
+
+		group, ok := groups[value]
+		if !ok {
+			group = &TagGroup{Value: value}
+			groups[value] = group
+		}
+
+		group.TotalBuckets++
+		group.Buckets = append(group.Buckets, name)
+		if discovery.BucketInfo != nil {
+			group.TotalSize += discovery.BucketInfo.TotalSize
+		}
+
+		switch discovery.Status {
+		case StatusOK:
+			group.Healthy++
+		case StatusUnusedBucket:
+			group.Unused++
+		case StatusRisky:
+			group.Risky++
+		case StatusInactive:
+			group.Inactive++
+		case StatusVersionSprawl:
+			group.VersionSprawl++
+		}
+	}
+
+	out := make([]TagGroup, 0, len(groups))
+	for _, group := range groups {
+		sort.Strings(group.Buckets)
+		out = append(out, *group)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if (out[i].Value == UntaggedGroup) != (out[j].Value == UntaggedGroup) {
+			return out[j].Value == UntaggedGroup
+		}
+		return out[i].Value < out[j].Value
+	})
+
+	return out
+}
@@ -0,0 +1,25 @@
+package analyzer
+
+import (
+	"github.com/ppiankov/s3spectre/internal/s3"
+)
+
+// AnalyzeGuardDuty adds a NO_GUARDDUTY_S3 account finding for each
+// region where GuardDuty S3 protection is off
+func AnalyzeGuardDuty(result *DiscoveryResult, statuses []s3.S3ProtectionStatus) {
+	for _, status := range statuses {
+		if status.Enabled {
+			continue
+		}
+		message := "GuardDuty detector does not have S3 protection enabled"
+		if status.DetectorID == "" {
+			message = "GuardDuty is not enabled in this region"
+		}
+		result.AccountFindings = append(result.AccountFindings, AccountAnalysis{
+			Status:  StatusNoGuardDutyS3,
+			Region:  status.Region,
+			Message: message,
+		})
+		result.Summary.NoGuardDutyS3 = append(result.Summary.NoGuardDutyS3, status.Region)
+	}
+}
@@ -0,0 +1,31 @@
+package analyzer
+
+import (
+	"testing"
+
+	"github.com/ppiankov/s3spectre/internal/s3"
+)
+
+func TestAnalyzeGuardDuty(t *testing.T) {
+	result := &DiscoveryResult{}
+	AnalyzeGuardDuty(result, []s3.S3ProtectionStatus{
+		{Region: "us-east-1", DetectorID: "det-1", Enabled: true},
+		{Region: "us-west-2", DetectorID: "det-2"},
+		{Region: "eu-west-1"},
+	})
+
+	if len(result.AccountFindings) != 2 {
+		t.Fatalf("expected 2 account findings, got %+v", result.AccountFindings)
+	}
+	if got := result.Summary.NoGuardDutyS3; len(got) != 2 || got[0] != "us-west-2" || got[1] != "eu-west-1" {
+		t.Fatalf("unexpected summary regions: %v", got)
+	}
+	for _, f := range result.AccountFindings {
+		if f.Status != StatusNoGuardDutyS3 {
+			t.Fatalf("unexpected status %s", f.Status)
+		}
+	}
+	if result.AccountFindings[1].Message != "GuardDuty is not enabled in this region" {
+		t.Fatalf("expected a missing-detector message, got %q", result.AccountFindings[1].Message)
+	}
+}
@@ -0,0 +1,34 @@
+package analyzer
+
+import (
+	"strings"
+
+	"github.com/ppiankov/s3spectre/internal/s3"
+)
+
+// isProduction reports whether a bucket's tags match any of the production
+// tag filters. Values compare case-insensitively, so environment=Production
+// matches environment=production; keys must match exactly.
+func isProduction(tags map[string]string, filters []s3.TagFilter) bool {
+	for _, f := range filters {
+		value, ok := tags[f.Key]
+		if ok && (f.AnyValue || strings.EqualFold(value, f.Value)) {
+			return true
+		}
+	}
+	return false
+}
+
+// analyzeLogging returns a NO_LOGGING finding for a production bucket whose
+// requests are recorded by neither server access logging nor CloudTrail
+// data events
+func analyzeLogging(info *s3.BucketInfo, production []s3.TagFilter) *PolicyFinding {
+	if info.Logging == nil || info.Logging.Logged() || !isProduction(info.Tags, production) {
+		return nil
+	}
+	message := "Production bucket has no server access logging and no CloudTrail trail logs its data events"
+	if !info.Logging.TrailsChecked {
+		message = "Production bucket has no server access logging; CloudTrail data events could not be checked"
+	}
+	return &PolicyFinding{Status: StatusNoLogging, Message: message}
+}
@@ -0,0 +1,70 @@
+package analyzer
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/ppiankov/s3spectre/internal/s3"
+)
+
+func TestAnalyzeDiscovery_NoLogging(t *testing.T) {
+	prod := map[string]string{"environment": "Production"}
+	buckets := map[string]*s3.BucketInfo{
+		"orders":   {Name: "orders", Exists: true, Tags: prod, Logging: &s3.LoggingInfo{TrailsChecked: true}},
+		"payments": {Name: "payments", Exists: true, Tags: prod, Logging: &s3.LoggingInfo{}},
+		"ledger":   {Name: "ledger", Exists: true, Tags: prod, Logging: &s3.LoggingInfo{DataEventsTrail: "data", TrailsChecked: true}},
+		"web":      {Name: "web", Exists: true, Tags: prod, Logging: &s3.LoggingInfo{AccessLogging: true}},
+		"scratch":  {Name: "scratch", Exists: true, Tags: map[string]string{"environment": "dev"}, Logging: &s3.LoggingInfo{TrailsChecked: true}},
+		"unread":   {Name: "unread", Exists: true, Tags: prod},
+	}
+	production, err := s3.ParseTagFilters([]string{"environment=production"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result := AnalyzeDiscovery(buckets, DiscoveryConfig{RiskScoreThreshold: 100, CheckLogging: true, ProductionTags: production})
+	result.Summary.Sort()
+
+	if got := strings.Join(result.Summary.NoLogging, ","); got != "orders,payments" {
+		t.Errorf("no logging = %s, want orders,payments", got)
+	}
+	orders := result.Buckets["orders"].PolicyFindings
+	if len(orders) != 1 || orders[0].Status != StatusNoLogging || !strings.Contains(orders[0].Message, "no CloudTrail trail") {
+		t.Errorf("orders findings = %+v", orders)
+	}
+	if payments := result.Buckets["payments"].PolicyFindings; len(payments) != 1 || !strings.Contains(payments[0].Message, "could not be checked") {
+		t.Errorf("payments findings = %+v", payments)
+	}
+	if result.Buckets["orders"].Status != StatusOK {
+		t.Errorf("logging findings should not change the bucket status, got %s", result.Buckets["orders"].Status)
+	}
+
+	// Without the check, logging read into BucketInfo is not reported
+	result = AnalyzeDiscovery(buckets, DiscoveryConfig{RiskScoreThreshold: 100, ProductionTags: production})
+	if len(result.Summary.NoLogging) != 0 {
+		t.Errorf("expected no findings without CheckLogging, got %v", result.Summary.NoLogging)
+	}
+}
+
+func TestIsProduction(t *testing.T) {
+	filters, err := s3.ParseTagFilters([]string{"environment=production", "pci"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		tags map[string]string
+		want bool
+	}{
+		{map[string]string{"environment": "production"}, true},
+		{map[string]string{"environment": "PRODUCTION"}, true},
+		{map[string]string{"Environment": "production"}, false},
+		{map[string]string{"environment": "staging"}, false},
+		{map[string]string{"pci": ""}, true},
+		{nil, false},
+	}
+	for _, tt := range tests {
+		if got := isProduction(tt.tags, filters); got != tt.want {
+			t.Errorf("isProduction(%v) = %v, want %v", tt.tags, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,39 @@
+package analyzer
+
+import (
+	"strings"
+
+	"github.com/ppiankov/s3spectre/internal/s3"
+)
+
+// sensitiveDataWeight is the classification weight of a bucket Macie found
+// sensitive data in, unless its classification tag weighs more
+const sensitiveDataWeight = 2.0
+
+// analyzeSensitiveData returns a SENSITIVE_DATA_EXPOSED finding for a bucket
+// Macie found sensitive data in that is public or unencrypted. Exposure is
+// judged only by the checks that ran.
+func analyzeSensitiveData(info *s3.BucketInfo, config DiscoveryConfig) *PolicyFinding {
+	if info.SensitiveData == nil || len(info.SensitiveData.Classes) == 0 {
+		return nil
+	}
+	var exposures []string
+	public := config.CheckPublicAccess && info.PublicAccess != nil && info.PublicAccess.IsPublic
+	for _, f := range info.ExternalAccess {
+		public = public || f.Public
+	}
+	if public {
+		exposures = append(exposures, "public")
+	}
+	if config.CheckEncryption && info.Encryption != nil && !info.Encryption.Enabled {
+		exposures = append(exposures, "unencrypted")
+	}
+	if len(exposures) == 0 {
+		return nil
+	}
+	return &PolicyFinding{
+		Status: StatusSensitiveDataExposed,
+		Message: "Macie found " + strings.Join(info.SensitiveData.Classes, ", ") +
+			" in a " + strings.Join(exposures, ", ") + " bucket",
+	}
+}
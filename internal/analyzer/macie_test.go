@@ -0,0 +1,52 @@
+package analyzer
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/ppiankov/s3spectre/internal/s3"
+)
+
+func TestAnalyzeDiscovery_SensitiveData(t *testing.T) {
+	pii := &s3.SensitiveData{Classes: []string{"FINANCIAL_INFORMATION", "PERSONAL_INFORMATION"}, Findings: 2, Severity: "High"}
+	buckets := map[string]*s3.BucketInfo{
+		// Public and unencrypted: 60 and 40 points at weight 2.0
+		"exports": {Name: "exports", Exists: true, Encryption: &s3.EncryptionInfo{}, PublicAccess: &s3.PublicAccessInfo{IsPublic: true}, SensitiveData: pii},
+		"vault":   {Name: "vault", Exists: true, Encryption: &s3.EncryptionInfo{Enabled: true}, SensitiveData: pii},
+		"plain":   {Name: "plain", Exists: true, Encryption: &s3.EncryptionInfo{}},
+	}
+	config := DiscoveryConfig{CheckEncryption: true, CheckPublicAccess: true, RiskScoreThreshold: 100}
+
+	result := AnalyzeDiscovery(buckets, config)
+
+	if got := strings.Join(result.Summary.SensitiveDataExposed, ","); got != "exports" {
+		t.Errorf("sensitive data exposed = %s, want exports", got)
+	}
+	exports := result.Buckets["exports"]
+	if exports.RiskScore != 200 || exports.Status != StatusRisky {
+		t.Errorf("exports = %s (%d), want RISKY at 200", exports.Status, exports.RiskScore)
+	}
+	if len(exports.PolicyFindings) != 1 || exports.PolicyFindings[0].Status != StatusSensitiveDataExposed ||
+		exports.PolicyFindings[0].Message != "Macie found FINANCIAL_INFORMATION, PERSONAL_INFORMATION in a public, unencrypted bucket" {
+		t.Errorf("exports findings = %+v", exports.PolicyFindings)
+	}
+	if got := strings.Join(exports.DataClasses, ","); got != "FINANCIAL_INFORMATION,PERSONAL_INFORMATION" {
+		t.Errorf("exports data classes = %s", got)
+	}
+	if !strings.Contains(strings.Join(exports.RiskFactors, "|"), "Macie found FINANCIAL_INFORMATION, PERSONAL_INFORMATION (weight 2.0)") {
+		t.Errorf("exports risk factors = %v, want the Macie weight", exports.RiskFactors)
+	}
+
+	if vault := result.Buckets["vault"]; len(vault.PolicyFindings) != 0 || vault.RiskScore != 0 {
+		t.Errorf("vault = %d %+v, want no finding for a private, encrypted bucket", vault.RiskScore, vault.PolicyFindings)
+	}
+	if plain := result.Buckets["plain"]; plain.RiskScore != 40 || len(plain.DataClasses) != 0 {
+		t.Errorf("plain = %d %v, want the unweighted 40 points", plain.RiskScore, plain.DataClasses)
+	}
+
+	// Without the checks that judge exposure there is no finding
+	result = AnalyzeDiscovery(buckets, DiscoveryConfig{RiskScoreThreshold: 100})
+	if len(result.Summary.SensitiveDataExposed) != 0 {
+		t.Errorf("sensitive data exposed = %v without the checks", result.Summary.SensitiveDataExposed)
+	}
+}
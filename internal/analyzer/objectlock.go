@@ -0,0 +1,44 @@
+package analyzer
+
+import (
+	"fmt"
+
+	"github.com/ppiankov/s3spectre/internal/rules"
+	"github.com/ppiankov/s3spectre/internal/s3"
+)
+
+// Recommendations that empty or delete a bucket
+const (
+	actionArchiveOrDelete = "Consider archiving or deleting if not needed"
+	actionDelete          = "Delete if not needed"
+)
+
+// isDeletionCandidate reports whether a status flags a bucket for deletion
+func isDeletionCandidate(status Status) bool {
+	return status == StatusUnusedBucket || status == StatusInactive
+}
+
+// excludeLocked warns that a bucket flagged for deletion retains objects in
+// compliance mode, which nobody can delete before retention expires, and
+// recommends excluding it from cleanup instead of deleting it
+func (d *BucketDiscovery) excludeLocked(lock *s3.ObjectLockInfo) {
+	d.RiskFactors = append(d.RiskFactors,
+		fmt.Sprintf("Compliance-mode Object Lock retains objects for %d days; the bucket cannot be emptied", lock.RetentionDays()))
+	d.replaceDeletion("Exclude from cleanup: compliance-mode Object Lock retention cannot be shortened or removed")
+}
+
+// replaceDeletion puts action first in place of the recommendations that
+// empty or delete the bucket
+func (d *BucketDiscovery) replaceDeletion(action string) {
+	kept := d.ScoredRecommendations[:0]
+	for _, rec := range d.ScoredRecommendations {
+		if rec.Action != actionArchiveOrDelete && rec.Action != actionDelete {
+			kept = append(kept, rec)
+		}
+	}
+	d.ScoredRecommendations = append([]Recommendation{newRecommendation(action, 0, 0, rules.EffortSmall)}, kept...)
+	d.Recommendations = make([]string, 0, len(d.ScoredRecommendations))
+	for _, rec := range d.ScoredRecommendations {
+		d.Recommendations = append(d.Recommendations, rec.Action)
+	}
+}
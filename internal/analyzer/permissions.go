@@ -0,0 +1,101 @@
+package analyzer
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/ppiankov/s3spectre/internal/s3"
+)
+
+// PermissionAnalysis is a reference that one or more principals are not
+// allowed to perform
+type PermissionAnalysis struct {
+	Bucket   string `json:"bucket"`
+	Prefix   string `json:"prefix,omitempty"`
+	Action   string `json:"action"`
+	Resource string `json:"resource"`
+	Status   Status `json:"status"`
+	Message  string `json:"message,omitempty"`
+	// Denied lists each principal and its decision (implicitDeny, explicitDeny)
+	Denied      []DeniedPrincipal `json:"denied"`
+	File        string            `json:"file"`
+	Line        int               `json:"line"`
+	Fingerprint string            `json:"fingerprint,omitempty"`
+}
+
+// DeniedPrincipal is a principal the simulation did not allow
+type DeniedPrincipal struct {
+	Principal string `json:"principal"`
+	Decision  string `json:"decision"`
+}
+
+// Path returns the bucket, or bucket/prefix for prefix references
+func (p *PermissionAnalysis) Path() string {
+	if p.Prefix == "" {
+		return p.Bucket
+	}
+	return fmt.Sprintf("%s/%s", p.Bucket, p.Prefix)
+}
+
+// Key identifies the gap within its bucket; a prefix read by one principal
+// and written by another can have a gap for each action
+func (p *PermissionAnalysis) Key() string {
+	return p.Prefix + ":" + p.Action
+}
+
+// AnalyzePermissions adds a PERMISSION_GAP finding to result for each
+// reference that at least one principal is not allowed. Checks with an
+// unknown decision are not gaps; their buckets are listed in the summary.
+func AnalyzePermissions(result *Result, checks []s3.AccessCheck) {
+	byPath := make(map[string]*PermissionAnalysis)
+	unknown := make(map[string]bool)
+	for _, check := range checks {
+		if check.Decision == s3.DecisionUnknown {
+			if !unknown[check.Bucket] {
+				unknown[check.Bucket] = true
+				result.Summary.PermissionsUnknown = append(result.Summary.PermissionsUnknown, check.Bucket)
+			}
+			continue
+		}
+		if check.Allowed() {
+			continue
+		}
+		key := check.Bucket + "|" + check.Prefix + "|" + check.Action
+		analysis := byPath[key]
+		if analysis == nil {
+			analysis = &PermissionAnalysis{
+				Bucket:   check.Bucket,
+				Prefix:   check.Prefix,
+				Action:   check.Action,
+				Resource: check.Resource,
+				Status:   StatusPermissionGap,
+				File:     check.File,
+				Line:     check.Line,
+			}
+			byPath[key] = analysis
+			result.Permissions = append(result.Permissions, analysis)
+		}
+		analysis.Denied = append(analysis.Denied, DeniedPrincipal{Principal: check.Principal, Decision: check.Decision})
+	}
+
+	sort.Slice(result.Permissions, func(i, j int) bool {
+		a, b := result.Permissions[i], result.Permissions[j]
+		if a.Path() != b.Path() {
+			return a.Path() < b.Path()
+		}
+		return a.Action < b.Action
+	})
+	gaps := make(map[string]bool)
+	for _, analysis := range result.Permissions {
+		principals := make([]string, 0, len(analysis.Denied))
+		for _, d := range analysis.Denied {
+			principals = append(principals, fmt.Sprintf("%s (%s)", d.Principal, d.Decision))
+		}
+		analysis.Message = fmt.Sprintf("%s on %s is not allowed for %s", analysis.Action, analysis.Resource, strings.Join(principals, ", "))
+		if !gaps[analysis.Path()] {
+			gaps[analysis.Path()] = true
+			result.Summary.PermissionGaps = append(result.Summary.PermissionGaps, analysis.Path())
+		}
+	}
+}
@@ -0,0 +1,76 @@
+package analyzer
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/ppiankov/s3spectre/internal/s3"
+)
+
+func TestAnalyzePermissions(t *testing.T) {
+	checks := []s3.AccessCheck{
+		{Principal: "arn:aws:iam::123456789012:role/reader", Action: "s3:PutObject", Resource: "arn:aws:s3:::data/out/*", Bucket: "data", Prefix: "out/", Decision: "implicitDeny", File: "job.py", Line: 4},
+		{Principal: "arn:aws:iam::123456789012:role/writer", Action: "s3:PutObject", Resource: "arn:aws:s3:::data/out/*", Bucket: "data", Prefix: "out/", Decision: "allowed", File: "job.py", Line: 4},
+		{Principal: "arn:aws:iam::123456789012:role/writer", Action: "s3:GetObject", Resource: "arn:aws:s3:::assets/*", Bucket: "assets", Decision: "explicitDeny", File: "web.js", Line: 10},
+		{Principal: "arn:aws:iam::123456789012:role/reader", Action: "s3:GetObject", Resource: "arn:aws:s3:::assets/*", Bucket: "assets", Decision: "implicitDeny", File: "web.js", Line: 10},
+	}
+
+	result := &Result{}
+	AnalyzePermissions(result, checks)
+
+	if len(result.Permissions) != 2 {
+		t.Fatalf("expected 2 permission gaps, got %+v", result.Permissions)
+	}
+	if got := result.Summary.PermissionGaps; len(got) != 2 || got[0] != "assets" || got[1] != "data/out/" {
+		t.Fatalf("unexpected summary: %v", got)
+	}
+	assets := result.Permissions[0]
+	if assets.Status != StatusPermissionGap || len(assets.Denied) != 2 || assets.File != "web.js" {
+		t.Fatalf("unexpected assets gap: %+v", assets)
+	}
+	if !strings.Contains(assets.Message, "role/writer (explicitDeny)") {
+		t.Fatalf("expected denied principal in message, got %q", assets.Message)
+	}
+	if data := result.Permissions[1]; len(data.Denied) != 1 || data.Denied[0].Principal != "arn:aws:iam::123456789012:role/reader" {
+		t.Fatalf("expected only the reader denied on data/out/, got %+v", data.Denied)
+	}
+}
+
+func TestAnalyzePermissions_AllAllowed(t *testing.T) {
+	result := &Result{}
+	AnalyzePermissions(result, []s3.AccessCheck{{Bucket: "data", Decision: "allowed"}})
+	if len(result.Permissions) != 0 || len(result.Summary.PermissionGaps) != 0 {
+		t.Fatalf("expected no gaps, got %+v", result.Permissions)
+	}
+}
+
+func TestAnalyzePermissions_Unknown(t *testing.T) {
+	result := &Result{}
+	AnalyzePermissions(result, []s3.AccessCheck{
+		{Bucket: "locked", Action: "s3:GetObject", Decision: s3.DecisionUnknown},
+		{Bucket: "locked", Prefix: "out/", Action: "s3:PutObject", Decision: s3.DecisionUnknown},
+	})
+	if len(result.Permissions) != 0 || len(result.Summary.PermissionGaps) != 0 {
+		t.Fatalf("expected no gaps for unknown checks, got %+v", result.Permissions)
+	}
+	if got := result.Summary.PermissionsUnknown; len(got) != 1 || got[0] != "locked" {
+		t.Fatalf("PermissionsUnknown = %v, want [locked]", got)
+	}
+}
+
+func TestAnalyzePermissions_ReadAndWriteGaps(t *testing.T) {
+	result := &Result{}
+	AnalyzePermissions(result, []s3.AccessCheck{
+		{Principal: "arn:aws:iam::123456789012:role/app", Action: "s3:PutObject", Bucket: "data", Prefix: "out/", Decision: "implicitDeny", File: "job.py", Line: 4},
+		{Principal: "arn:aws:iam::123456789012:role/app", Action: "s3:GetObject", Bucket: "data", Prefix: "out/", Decision: "implicitDeny", File: "report.py", Line: 9},
+	})
+	if len(result.Permissions) != 2 {
+		t.Fatalf("expected a gap per action, got %+v", result.Permissions)
+	}
+	if read, write := result.Permissions[0], result.Permissions[1]; read.Action != "s3:GetObject" || read.File != "report.py" || write.Action != "s3:PutObject" || write.File != "job.py" {
+		t.Fatalf("unexpected gaps: %+v, %+v", read, write)
+	}
+	if got := result.Summary.PermissionGaps; len(got) != 1 || got[0] != "data/out/" {
+		t.Fatalf("expected one summary entry, got %v", got)
+	}
+}
@@ -0,0 +1,51 @@
+package analyzer
+
+import (
+	"strings"
+
+	"github.com/ppiankov/s3spectre/internal/s3"
+)
+
+// PolicyFinding is a problem with a discovered bucket's policy: a wildcard
+// principal, a cross-account grant or plain HTTP allowed; a production
+// bucket without logging; or sensitive data in a public or unencrypted
+// bucket
+type PolicyFinding struct {
+	Status      Status `json:"status"`
+	Message     string `json:"message"`
+	Fingerprint string `json:"fingerprint,omitempty"`
+}
+
+// analyzePolicy returns the findings of a bucket policy. A bucket without a
+// policy only allows plain HTTP.
+func analyzePolicy(policy *s3.PolicyInfo) []PolicyFinding {
+	var findings []PolicyFinding
+	switch {
+	case len(policy.WildcardStatements) > 0:
+		message := "Bucket policy allows any principal in statements: " + strings.Join(policy.WildcardStatements, ", ")
+		if policy.IsPublic {
+			message += " (S3 evaluates the policy as public)"
+		}
+		findings = append(findings, PolicyFinding{Status: StatusWildcardPrincipal, Message: message})
+	case policy.IsPublic:
+		// S3 also treats conditions such as a broad aws:SourceIp as public
+		findings = append(findings, PolicyFinding{
+			Status:  StatusWildcardPrincipal,
+			Message: "S3 evaluates the bucket policy as public",
+		})
+	}
+	if len(policy.CrossAccounts) > 0 {
+		findings = append(findings, PolicyFinding{
+			Status:  StatusCrossAccount,
+			Message: "Bucket policy grants access to other accounts: " + strings.Join(policy.CrossAccounts, ", "),
+		})
+	}
+	if !policy.DeniesInsecureTransport {
+		message := "Bucket policy does not deny requests without TLS (aws:SecureTransport false)"
+		if !policy.Exists {
+			message = "Bucket has no policy, so requests without TLS are allowed"
+		}
+		findings = append(findings, PolicyFinding{Status: StatusInsecureTransport, Message: message})
+	}
+	return findings
+}
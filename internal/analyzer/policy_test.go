@@ -0,0 +1,60 @@
+package analyzer
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/ppiankov/s3spectre/internal/s3"
+)
+
+func TestAnalyzeDiscovery_PolicyFindings(t *testing.T) {
+	buckets := map[string]*s3.BucketInfo{
+		"site": {Name: "site", Exists: true, Policy: &s3.PolicyInfo{
+			Exists:             true,
+			IsPublic:           true,
+			WildcardStatements: []string{"PublicRead"},
+			CrossAccounts:      []string{"222222222222"},
+		}},
+		"locked": {Name: "locked", Exists: true, Policy: &s3.PolicyInfo{Exists: true, DeniesInsecureTransport: true}},
+		"bare":   {Name: "bare", Exists: true, Policy: &s3.PolicyInfo{}},
+		"unread": {Name: "unread", Exists: true},
+	}
+
+	result := AnalyzeDiscovery(buckets, DiscoveryConfig{RiskScoreThreshold: 100, CheckPolicy: true})
+
+	site := result.Buckets["site"].PolicyFindings
+	if len(site) != 3 || site[0].Status != StatusWildcardPrincipal || site[1].Status != StatusCrossAccount || site[2].Status != StatusInsecureTransport {
+		t.Fatalf("site findings = %+v", site)
+	}
+	if !strings.Contains(site[0].Message, "PublicRead") || !strings.Contains(site[0].Message, "public") {
+		t.Errorf("wildcard message = %q", site[0].Message)
+	}
+	if len(result.Buckets["locked"].PolicyFindings) != 0 || len(result.Buckets["unread"].PolicyFindings) != 0 {
+		t.Errorf("unexpected findings for locked or unread buckets")
+	}
+	if bare := result.Buckets["bare"].PolicyFindings; len(bare) != 1 || !strings.Contains(bare[0].Message, "no policy") {
+		t.Errorf("bare findings = %+v", bare)
+	}
+	if result.Buckets["site"].Status != StatusOK {
+		t.Errorf("policy findings should not change the bucket status, got %s", result.Buckets["site"].Status)
+	}
+	result.Summary.Sort()
+	if got := strings.Join(result.Summary.InsecureTransport, ","); got != "bare,site" {
+		t.Errorf("insecure transport = %s", got)
+	}
+	if len(result.Summary.WildcardPrincipals) != 1 || len(result.Summary.CrossAccountAccess) != 1 {
+		t.Errorf("summary = %+v", result.Summary)
+	}
+
+	off := AnalyzeDiscovery(buckets, DiscoveryConfig{RiskScoreThreshold: 100})
+	if len(off.Buckets["site"].PolicyFindings) != 0 {
+		t.Errorf("policy findings reported without CheckPolicy")
+	}
+}
+
+func TestAnalyzePolicy_PublicByStatus(t *testing.T) {
+	findings := analyzePolicy(&s3.PolicyInfo{Exists: true, IsPublic: true, DeniesInsecureTransport: true})
+	if len(findings) != 1 || findings[0].Status != StatusWildcardPrincipal {
+		t.Fatalf("findings = %+v", findings)
+	}
+}
@@ -0,0 +1,93 @@
+package analyzer
+
+import "github.com/ppiankov/s3spectre/internal/s3"
+
+// StoragePosture is an account-level roll-up of discovered buckets: how much
+// is stored and how much of it is encrypted, versioned and covered by
+// lifecycle rules. Percentages are 0-100.
+type StoragePosture struct {
+	TotalBuckets int `json:"total_buckets"`
+	// EstimatedBytes sums the sampled object sizes, or all version sizes
+	// for versioned buckets
+	EstimatedBytes   int64   `json:"estimated_bytes"`
+	EncryptedBuckets int     `json:"encrypted_buckets"`
+	EncryptedPercent float64 `json:"encrypted_percent"`
+	VersionedBuckets int     `json:"versioned_buckets"`
+	VersionedPercent float64 `json:"versioned_percent"`
+	LifecycleBuckets int     `json:"lifecycle_buckets"`
+	LifecyclePercent float64 `json:"lifecycle_percent"`
+	// EncryptionUnknown counts buckets whose encryption could not be read;
+	// they are left out of EncryptedPercent
+	EncryptionUnknown int `json:"encryption_unknown,omitempty"`
+	// PartialSizeBuckets counts buckets sized from a partial listing, such
+	// as their first objects, rather than CloudWatch, an inventory report
+	// or a complete listing. EstimatedBytes is then a lower bound.
+	PartialSizeBuckets int `json:"partial_size_buckets,omitempty"`
+	// Population is the account's bucket count when discovery sampled
+	// buckets; TotalBuckets and EstimatedBytes then cover the sample
+	Population int `json:"population,omitempty"`
+	// PopulationBytes extrapolates EstimatedBytes to the population
+	PopulationBytes int64 `json:"population_bytes,omitempty"`
+}
+
+// ComputePosture summarizes the storage posture of discovered buckets.
+// population is the account's bucket count when the buckets are a sample,
+// or 0.
+func ComputePosture(result *DiscoveryResult, population int) *StoragePosture {
+	posture := &StoragePosture{}
+	for _, discovery := range result.Buckets {
+		info := discovery.BucketInfo
+		if info == nil {
+			continue
+		}
+		posture.TotalBuckets++
+		posture.EstimatedBytes += max(info.TotalSize, info.TotalVersionSize)
+		if !sizeComplete(info) {
+			posture.PartialSizeBuckets++
+		}
+		if info.VersioningEnabled {
+			posture.VersionedBuckets++
+		}
+		if info.LifecycleRules > 0 {
+			posture.LifecycleBuckets++
+		}
+		switch {
+		case info.Encryption == nil:
+			posture.EncryptionUnknown++
+		case info.Encryption.Enabled:
+			posture.EncryptedBuckets++
+		}
+	}
+
+	posture.EncryptedPercent = percent(posture.EncryptedBuckets, posture.TotalBuckets-posture.EncryptionUnknown)
+	posture.VersionedPercent = percent(posture.VersionedBuckets, posture.TotalBuckets)
+	posture.LifecyclePercent = percent(posture.LifecycleBuckets, posture.TotalBuckets)
+	if population > posture.TotalBuckets && posture.TotalBuckets > 0 {
+		posture.Population = population
+		posture.PopulationBytes = int64(float64(posture.EstimatedBytes) * float64(population) / float64(posture.TotalBuckets))
+	}
+	return posture
+}
+
+// sizeComplete reports whether a bucket's size covers all of its objects:
+// it came from CloudWatch or an inventory report, or from a listing that
+// was not cut short
+func sizeComplete(info *s3.BucketInfo) bool {
+	if info.CloudWatch != nil || info.Inventory != nil {
+		return true
+	}
+	if info.TotalVersionSize > info.TotalSize {
+		return info.VersionStats == nil || (!info.VersionStats.Truncated && !info.VersionStats.Sampled)
+	}
+	if info.SizeBreakdown != nil {
+		return !info.SizeBreakdown.Truncated
+	}
+	return info.ObjectCount < s3.ActivitySampleSize
+}
+
+func percent(n, total int) float64 {
+	if total <= 0 {
+		return 0
+	}
+	return float64(n) * 100 / float64(total)
+}
@@ -0,0 +1,69 @@
+package analyzer
+
+import (
+	"testing"
+
+	"github.com/ppiankov/s3spectre/internal/s3"
+)
+
+func TestComputePosture(t *testing.T) {
+	result := &DiscoveryResult{
+		Buckets: map[string]*BucketDiscovery{
+			"logs": {BucketInfo: &s3.BucketInfo{
+				TotalSize:         100,
+				TotalVersionSize:  300,
+				VersioningEnabled: true,
+				LifecycleRules:    1,
+				Encryption:        &s3.EncryptionInfo{Enabled: true},
+			}},
+			"assets": {BucketInfo: &s3.BucketInfo{
+				TotalSize:  50,
+				Encryption: &s3.EncryptionInfo{Enabled: false},
+			}},
+			"denied": {BucketInfo: &s3.BucketInfo{}},
+			"broken": {},
+		},
+	}
+
+	posture := ComputePosture(result, 0)
+	if posture.TotalBuckets != 3 || posture.EstimatedBytes != 350 {
+		t.Fatalf("unexpected totals: %+v", posture)
+	}
+	if posture.EncryptedBuckets != 1 || posture.EncryptionUnknown != 1 || posture.EncryptedPercent != 50 {
+		t.Fatalf("expected unknown encryption excluded from the percentage, got %+v", posture)
+	}
+	if posture.VersionedBuckets != 1 || posture.LifecycleBuckets != 1 {
+		t.Fatalf("unexpected coverage counts: %+v", posture)
+	}
+	if got := posture.LifecyclePercent; got < 33.3 || got > 33.4 {
+		t.Fatalf("expected ~33.3%% lifecycle coverage, got %.2f", got)
+	}
+}
+
+func TestComputePosture_PartialAndSampled(t *testing.T) {
+	result := &DiscoveryResult{
+		Buckets: map[string]*BucketDiscovery{
+			"big":     {BucketInfo: &s3.BucketInfo{TotalSize: 400, ObjectCount: s3.ActivitySampleSize}},
+			"metered": {BucketInfo: &s3.BucketInfo{TotalSize: 500, ObjectCount: s3.ActivitySampleSize, CloudWatch: &s3.CloudWatchActivity{}}},
+			"small":   {BucketInfo: &s3.BucketInfo{TotalSize: 100, ObjectCount: 3}},
+		},
+	}
+
+	posture := ComputePosture(result, 30)
+	if posture.PartialSizeBuckets != 1 {
+		t.Fatalf("expected only the listed-in-part bucket partial, got %+v", posture)
+	}
+	if posture.Population != 30 || posture.PopulationBytes != 10000 {
+		t.Fatalf("expected the sample extrapolated to 30 buckets, got %+v", posture)
+	}
+	if full := ComputePosture(result, 0); full.Population != 0 || full.PopulationBytes != 0 {
+		t.Fatalf("expected no extrapolation without sampling, got %+v", full)
+	}
+}
+
+func TestComputePosture_Empty(t *testing.T) {
+	posture := ComputePosture(&DiscoveryResult{}, 0)
+	if posture.TotalBuckets != 0 || posture.EncryptedPercent != 0 || posture.VersionedPercent != 0 {
+		t.Fatalf("expected zero posture, got %+v", posture)
+	}
+}
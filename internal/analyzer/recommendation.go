@@ -0,0 +1,82 @@
+package analyzer
+
+import (
+	"sort"
+
+	"github.com/ppiankov/s3spectre/internal/rules"
+	"github.com/ppiankov/s3spectre/internal/s3"
+)
+
+// StandardStorageMonthlyPerGiB is the S3 Standard list price used to turn
+// freed bytes into a rough monthly saving, in USD
+const StandardStorageMonthlyPerGiB = 0.023
+
+const gib = 1 << 30
+
+// Recommendation is a suggested action with a rough impact and effort
+type Recommendation struct {
+	Action string `json:"action"`
+	// ImpactBytes is the storage the action could free
+	ImpactBytes int64 `json:"impact_bytes,omitempty"`
+	// MonthlySavings prices ImpactBytes at S3 Standard rates, in USD
+	MonthlySavings float64 `json:"monthly_savings_usd,omitempty"`
+	// RiskReduced is the risk score the action removes
+	RiskReduced int `json:"risk_reduced,omitempty"`
+	// Effort is small, medium or large
+	Effort string `json:"effort"`
+	// Score is impact divided by effort; recommendations sort by it
+	Score float64 `json:"score"`
+}
+
+// newRecommendation scores an action. One risk point weighs as much as one
+// GiB of freed storage, and the sum is divided by the effort weight.
+func newRecommendation(action string, riskReduced int, bytes int64, effort string) Recommendation {
+	rec := Recommendation{
+		Action:      action,
+		ImpactBytes: max(bytes, 0),
+		RiskReduced: riskReduced,
+		Effort:      effort,
+	}
+	gibs := float64(rec.ImpactBytes) / gib
+	rec.MonthlySavings = gibs * StandardStorageMonthlyPerGiB
+	rec.Score = (float64(riskReduced) + gibs) / effortWeight(effort)
+	return rec
+}
+
+// effortWeight makes large work count four times as much as small work
+func effortWeight(effort string) float64 {
+	switch effort {
+	case rules.EffortMedium:
+		return 2
+	case rules.EffortLarge:
+		return 4
+	default:
+		return 1
+	}
+}
+
+// recommend records a scored recommendation on the discovery
+func (d *BucketDiscovery) recommend(action string, riskReduced int, bytes int64, effort string) {
+	d.ScoredRecommendations = append(d.ScoredRecommendations,
+		newRecommendation(action, riskReduced, bytes, effort))
+}
+
+// sortRecommendations orders recommendations by impact/effort, highest
+// first, and keeps the plain Recommendations list in the same order
+func (d *BucketDiscovery) sortRecommendations() {
+	sort.SliceStable(d.ScoredRecommendations, func(i, j int) bool {
+		return d.ScoredRecommendations[i].Score > d.ScoredRecommendations[j].Score
+	})
+	d.Recommendations = make([]string, 0, len(d.ScoredRecommendations))
+	for _, rec := range d.ScoredRecommendations {
+		d.Recommendations = append(d.Recommendations, rec.Action)
+	}
+}
+
+// versionOverhead is the storage held by noncurrent versions
+func versionOverhead(info *s3.BucketInfo) int64 {
+	if info.TotalVersionSize > info.TotalSize {
+		return info.TotalVersionSize - info.TotalSize
+	}
+	return 0
+}
@@ -0,0 +1,56 @@
+package analyzer
+
+import (
+	"testing"
+
+	"github.com/ppiankov/s3spectre/internal/rules"
+	"github.com/ppiankov/s3spectre/internal/s3"
+)
+
+func TestNewRecommendation(t *testing.T) {
+	rec := newRecommendation("Delete", 30, 10*gib, rules.EffortMedium)
+	if rec.Score != 20 {
+		t.Errorf("expected (30 risk + 10 GiB) / 2 = 20, got %v", rec.Score)
+	}
+	if rec.MonthlySavings < 0.229 || rec.MonthlySavings > 0.231 {
+		t.Errorf("expected ~$0.23/month, got %v", rec.MonthlySavings)
+	}
+	if got := newRecommendation("Check", 0, -5, rules.EffortLarge); got.ImpactBytes != 0 || got.Score != 0 {
+		t.Errorf("negative bytes should clamp to zero: %+v", got)
+	}
+}
+
+func TestAnalyzeDiscovery_RecommendationsSortedByImpactPerEffort(t *testing.T) {
+	info := &s3.BucketInfo{
+		Name:              "sprawl",
+		VersioningEnabled: true,
+		TotalSize:         1 * gib,
+		TotalVersionSize:  101 * gib,
+		DaysSinceActivity: 400,
+		PublicAccess:      &s3.PublicAccessInfo{IsPublic: true},
+	}
+	config := DiscoveryConfig{InactivityThresholdDays: 90, CheckPublicAccess: true}
+
+	result := AnalyzeDiscovery(map[string]*s3.BucketInfo{"sprawl": info}, config)
+	d := result.Buckets["sprawl"]
+
+	want := []string{
+		// 30 risk + 100 GiB of old versions, small effort
+		"Add lifecycle policy to expire old versions",
+		// 50 risk + 101 GiB, medium effort
+		"Consider archiving or deleting if not needed",
+		// 60 risk, medium effort
+		"Review and restrict public access if not required",
+	}
+	if len(d.Recommendations) != len(want) || len(d.ScoredRecommendations) != len(want) {
+		t.Fatalf("unexpected recommendations: %+v", d.ScoredRecommendations)
+	}
+	for i, action := range want {
+		if d.Recommendations[i] != action || d.ScoredRecommendations[i].Action != action {
+			t.Errorf("position %d: expected %q, got %q", i, action, d.Recommendations[i])
+		}
+	}
+	if d.ScoredRecommendations[0].ImpactBytes != 100*gib {
+		t.Errorf("lifecycle impact should be the version overhead, got %d", d.ScoredRecommendations[0].ImpactBytes)
+	}
+}
@@ -0,0 +1,85 @@
+package analyzer
+
+import "sort"
+
+// UnknownRegion is the region value for buckets whose region was not resolved
+const UnknownRegion = "(unknown)"
+
+// RegionSummary summarizes discovery results for the buckets of one region
+type RegionSummary struct {
+	Region        string `json:"region"`
+	TotalBuckets  int    `json:"total_buckets"`
+	Findings      int    `json:"findings"`
+	Unused        int    `json:"unused"`
+	Risky         int    `json:"risky"`
+	Inactive      int    `json:"inactive"`
+	VersionSprawl int    `json:"version_sprawl"`
+	TotalSize     int64  `json:"total_size,omitempty"`
+	// WasteBytes estimates reclaimable storage: all of an unused or inactive
+	// bucket, and the noncurrent versions of a bucket with version sprawl
+	WasteBytes int64 `json:"waste_bytes,omitempty"`
+}
+
+// SummarizeRegions breaks discovery results down by region. Regions with the
+// most estimated waste come first, then those with the most findings.
+func SummarizeRegions(result *DiscoveryResult) []RegionSummary {
+	regions := make(map[string]*RegionSummary)
+	for _, discovery := range result.Buckets {
+		name := discovery.Region
+		if name == "" {
+			name = UnknownRegion
+		}
+		region, ok := regions[name]
+		if !ok {
+			region = &RegionSummary{Region: name}
+			regions[name] = region
+		}
+
+		region.TotalBuckets++
+		if discovery.Status != StatusOK {
+			region.Findings++
+		}
+		info := discovery.BucketInfo
+		if info != nil {
+			region.TotalSize += info.TotalSize
+		}
+		switch discovery.Status {
+		case StatusUnusedBucket:
+			region.Unused++
+			region.WasteBytes += bucketBytes(discovery)
+		case StatusInactive:
+			region.Inactive++
+			region.WasteBytes += bucketBytes(discovery)
+		case StatusRisky:
+			region.Risky++
+		case StatusVersionSprawl:
+			region.VersionSprawl++
+			if info != nil && info.TotalVersionSize > info.TotalSize {
+				region.WasteBytes += info.TotalVersionSize - info.TotalSize
+			}
+		}
+	}
+
+	out := make([]RegionSummary, 0, len(regions))
+	for _, region := range regions {
+		out = append(out, *region)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].WasteBytes != out[j].WasteBytes {
+			return out[i].WasteBytes > out[j].WasteBytes
+		}
+		if out[i].Findings != out[j].Findings {
+			return out[i].Findings > out[j].Findings
+		}
+		return out[i].Region < out[j].Region
+	})
+	return out
+}
+
+// bucketBytes is everything a bucket stores, including noncurrent versions
+func bucketBytes(discovery *BucketDiscovery) int64 {
+	if discovery.BucketInfo == nil {
+		return 0
+	}
+	return max(discovery.BucketInfo.TotalSize, discovery.BucketInfo.TotalVersionSize)
+}
@@ -0,0 +1,39 @@
+package analyzer
+
+import (
+	"testing"
+
+	"github.com/ppiankov/s3spectre/internal/s3"
+)
+
+func TestSummarizeRegions(t *testing.T) {
+	result := &DiscoveryResult{Buckets: map[string]*BucketDiscovery{
+		"old-logs": {Region: "us-east-1", Status: StatusUnusedBucket,
+			BucketInfo: &s3.BucketInfo{TotalSize: 100, TotalVersionSize: 150}},
+		"archive": {Region: "us-east-1", Status: StatusVersionSprawl,
+			BucketInfo: &s3.BucketInfo{TotalSize: 40, TotalVersionSize: 100}},
+		"web":     {Region: "us-east-1", Status: StatusRisky, BucketInfo: &s3.BucketInfo{TotalSize: 10}},
+		"eu-data": {Region: "eu-west-1", Status: StatusOK, BucketInfo: &s3.BucketInfo{TotalSize: 500}},
+		"eu-idle": {Region: "eu-west-1", Status: StatusInactive},
+		"lost":    {Status: StatusOK},
+	}}
+
+	regions := SummarizeRegions(result)
+
+	if len(regions) != 3 {
+		t.Fatalf("expected 3 regions, got %+v", regions)
+	}
+	us := regions[0]
+	if us.Region != "us-east-1" || us.TotalBuckets != 3 || us.Findings != 3 {
+		t.Errorf("us-east-1 should sort first by waste: %+v", us)
+	}
+	if us.Unused != 1 || us.VersionSprawl != 1 || us.Risky != 1 || us.TotalSize != 150 || us.WasteBytes != 210 {
+		t.Errorf("unexpected us-east-1 totals: %+v", us)
+	}
+	if regions[1].Region != "eu-west-1" || regions[1].Findings != 1 || regions[1].Inactive != 1 || regions[1].WasteBytes != 0 {
+		t.Errorf("eu-west-1 should sort by findings next: %+v", regions[1])
+	}
+	if regions[2].Region != UnknownRegion || regions[2].Findings != 0 {
+		t.Errorf("unexpected unknown region: %+v", regions[2])
+	}
+}
@@ -0,0 +1,23 @@
+package analyzer
+
+import (
+	"fmt"
+	"strings"
+)
+
+// markReplicationDestination reports a bucket that would be unused or
+// inactive as a replication destination instead: replication, not code,
+// writes to it, and deleting it would break its sources' replication
+func (d *BucketDiscovery) markReplicationDestination(sources []string) {
+	d.Status = StatusReplicationDestination
+	d.RiskFactors = append(d.RiskFactors, "Replication destination of "+strings.Join(sources, ", "))
+	d.replaceDeletion("Keep while replication from " + strings.Join(sources, ", ") + " writes to it, or remove those replication rules first")
+}
+
+// markReplicatedUnused reports a scanned bucket that scored as unused as a
+// replication destination instead
+func markReplicatedUnused(analysis *BucketAnalysis, sources []string) {
+	analysis.Status = StatusReplicationDestination
+	analysis.Message = fmt.Sprintf("Bucket is the replication destination of %s (unused score: %d)",
+		strings.Join(sources, ", "), analysis.UnusedScore.Total)
+}
@@ -0,0 +1,93 @@
+package analyzer
+
+import "math"
+
+// sampleZ is the z-score for a 95% confidence interval
+const sampleZ = 1.96
+
+// SampleEstimate extrapolates discovery results from a random bucket sample
+// to the whole account
+type SampleEstimate struct {
+	Population int                `json:"population"`
+	SampleSize int                `json:"sample_size"`
+	Confidence float64            `json:"confidence"`
+	Estimates  []CategoryEstimate `json:"estimates"`
+}
+
+// CategoryEstimate is the extrapolated bucket count for one discovery category
+type CategoryEstimate struct {
+	Category   string  `json:"category"`
+	Observed   int     `json:"observed"`
+	Proportion float64 `json:"proportion"`
+	Estimated  int     `json:"estimated"`
+	Low        int     `json:"low"`
+	High       int     `json:"high"`
+}
+
+// EstimateFromSample extrapolates summary counts from a sampled discovery to a
+// population of buckets. Intervals are Wilson score intervals with a finite
+// population correction, so they stay sensible for small samples and for
+// categories with zero or all observations.
+func EstimateFromSample(summary DiscoverySummary, population int) *SampleEstimate {
+	n := summary.TotalBuckets
+	estimate := &SampleEstimate{
+		Population: population,
+		SampleSize: n,
+		Confidence: 0.95,
+	}
+	if n == 0 || population < n {
+		return estimate
+	}
+
+	categories := []struct {
+		name  string
+		count int
+	}{
+		{"healthy", summary.HealthyBuckets},
+		{"unused", len(summary.UnusedBuckets)},
+		{"risky", len(summary.RiskyBuckets)},
+		{"inactive", len(summary.InactiveBuckets)},
+		{"version_sprawl", len(summary.VersionSprawl)},
+	}
+
+	for _, c := range categories {
+		low, high := wilsonInterval(c.count, n, population)
+		p := float64(c.count) / float64(n)
+		// The sampled buckets are known exactly, so the population holds at
+		// least the observed count and at most everything not seen outside it
+		lowCount := int(math.Floor(low*float64(population) + 1e-9))
+		highCount := int(math.Ceil(high*float64(population) - 1e-9))
+		estimate.Estimates = append(estimate.Estimates, CategoryEstimate{
+			Category:   c.name,
+			Observed:   c.count,
+			Proportion: p,
+			Estimated:  int(math.Round(p * float64(population))),
+			Low:        max(lowCount, c.count),
+			High:       min(highCount, population-(n-c.count)),
+		})
+	}
+
+	return estimate
+}
+
+// wilsonInterval returns the confidence bounds for a proportion of k in n,
+// drawn without replacement from a population of size N. The Wilson bounds
+// are pulled toward the observed proportion by the finite population
+// correction, collapsing to it when the sample covers every bucket.
+func wilsonInterval(k, n, N int) (float64, float64) {
+	p := float64(k) / float64(n)
+	nf := float64(n)
+	z2 := sampleZ * sampleZ
+
+	denom := 1 + z2/nf
+	center := (p + z2/(2*nf)) / denom
+	half := sampleZ / denom * math.Sqrt(p*(1-p)/nf+z2/(4*nf*nf))
+	low, high := math.Max(0, center-half), math.Min(1, center+half)
+
+	fpc := 1.0
+	if N > 1 {
+		fpc = math.Sqrt(float64(N-n) / float64(N-1))
+	}
+
+	return p - (p-low)*fpc, p + (high-p)*fpc
+}
@@ -0,0 +1,51 @@
+package analyzer
+
+import "testing"
+
+func TestEstimateFromSample(t *testing.T) {
+	summary := DiscoverySummary{
+		TotalBuckets:   50,
+		HealthyBuckets: 40,
+		UnusedBuckets:  make([]string, 10),
+	}
+
+	estimate := EstimateFromSample(summary, 1000)
+	if estimate.SampleSize != 50 || estimate.Population != 1000 {
+		t.Fatalf("unexpected sizes: %+v", estimate)
+	}
+
+	var unused, risky CategoryEstimate
+	for _, e := range estimate.Estimates {
+		switch e.Category {
+		case "unused":
+			unused = e
+		case "risky":
+			risky = e
+		}
+	}
+	if unused.Estimated != 200 {
+		t.Errorf("expected ~200 unused, got %d", unused.Estimated)
+	}
+	if unused.Low >= 200 || unused.High <= 200 {
+		t.Errorf("expected interval around 200, got %d-%d", unused.Low, unused.High)
+	}
+	if risky.Estimated != 0 || risky.Low != 0 || risky.High == 0 {
+		t.Errorf("expected zero estimate with non-zero upper bound, got %+v", risky)
+	}
+}
+
+func TestEstimateFromSample_FullCensus(t *testing.T) {
+	summary := DiscoverySummary{
+		TotalBuckets:   10,
+		HealthyBuckets: 7,
+		RiskyBuckets:   make([]string, 3),
+	}
+
+	estimate := EstimateFromSample(summary, 10)
+	for _, e := range estimate.Estimates {
+		if e.Low != e.Observed || e.High != e.Observed {
+			t.Errorf("expected exact bounds for %s when sample is the population, got %d-%d (observed %d)",
+				e.Category, e.Low, e.High, e.Observed)
+		}
+	}
+}
@@ -0,0 +1,32 @@
+package analyzer
+
+import "sort"
+
+// Sort orders the summary's lists so output does not depend on map
+// iteration order
+func (s *Summary) Sort() {
+	for _, list := range [][]string{
+		s.MissingBuckets, s.UnusedBuckets, s.MissingPrefixes, s.StalePrefixes,
+		s.VersionSprawl, s.LifecycleMisconfig, s.OrphanedVaults, s.MissingVaults,
+		s.PermissionGaps, s.PermissionsUnknown, s.TimedOutBuckets, s.ExternalBuckets, s.PrefixNearMisses,
+		s.TestOnlyBuckets, s.ReplicationDestinations, s.Correlated,
+	} {
+		sort.Strings(list)
+	}
+	sortDeadlineSkips(s.DeadlineSkips)
+}
+
+// Sort orders the summary's lists so output does not depend on map
+// iteration order
+func (s *DiscoverySummary) Sort() {
+	for _, list := range [][]string{
+		s.UnusedBuckets, s.RiskyBuckets, s.InactiveBuckets, s.VersionSprawl,
+		s.NoGuardDutyS3, s.TimedOutBuckets, s.ExternalBuckets,
+		s.WildcardPrincipals, s.CrossAccountAccess, s.InsecureTransport, s.NoLogging,
+		s.SensitiveDataExposed, s.ComplianceLocked, s.ReplicationDestinations, s.ExternalAccess,
+		s.Correlated,
+	} {
+		sort.Strings(list)
+	}
+	sortDeadlineSkips(s.DeadlineSkips)
+}
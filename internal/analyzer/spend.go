@@ -0,0 +1,128 @@
+package analyzer
+
+import (
+	"sort"
+
+	"github.com/ppiankov/s3spectre/internal/s3"
+)
+
+// BucketSpend is a bucket's month-to-date S3 spend from Cost Explorer
+type BucketSpend struct {
+	MonthToDate float64 `json:"month_to_date"`
+	Currency    string  `json:"currency"`
+	// TagValue is the bucket's cost allocation tag value the spend is for
+	TagValue string `json:"tag_value"`
+	// SharedWith lists other buckets carrying the same tag value; the spend
+	// covers all of them
+	SharedWith []string `json:"shared_with,omitempty"`
+}
+
+// SpendSummary totals month-to-date S3 spend and how much of it was
+// matched to discovered buckets
+type SpendSummary struct {
+	TagKey    string  `json:"tag_key"`
+	Start     string  `json:"start"`
+	End       string  `json:"end"`
+	Currency  string  `json:"currency"`
+	Total     float64 `json:"total"`
+	Estimated bool    `json:"estimated,omitempty"`
+	// Attributed is the spend of tag values carried by discovered buckets
+	Attributed float64 `json:"attributed"`
+	// Untagged is spend without the tag, such as spend from before the tag
+	// was activated for cost allocation
+	Untagged float64 `json:"untagged"`
+	// Unmatched is tagged spend no discovered bucket carries the tag value of
+	Unmatched float64 `json:"unmatched"`
+	// Buckets counts the buckets a spend was set on
+	Buckets int `json:"buckets"`
+}
+
+// AnalyzeSpend sets each bucket's month-to-date spend from its value of the
+// cost allocation tag and returns the account-wide totals. Buckets without
+// an account belong to defaultAccount, the caller's. A tagged bucket shows
+// zero spend only when its account has tagged spend at all, since an
+// account whose spend is all untagged has not activated the tag.
+func AnalyzeSpend(result *DiscoveryResult, spend *s3.TagSpend, defaultAccount string) *SpendSummary {
+	summary := &SpendSummary{
+		TagKey:    spend.TagKey,
+		Start:     spend.Start,
+		End:       spend.End,
+		Currency:  spend.Currency,
+		Total:     spend.Total,
+		Estimated: spend.Estimated,
+	}
+
+	// Buckets sharing a tag value, by account
+	owners := make(map[string]map[string][]string)
+	for name, discovery := range result.Buckets {
+		if discovery == nil || discovery.BucketInfo == nil {
+			continue
+		}
+		value := discovery.BucketInfo.Tags[spend.TagKey]
+		if value == "" {
+			continue
+		}
+		account := spendAccount(discovery, defaultAccount)
+		if owners[account] == nil {
+			owners[account] = make(map[string][]string)
+		}
+		owners[account][value] = append(owners[account][value], name)
+	}
+
+	for account, values := range spend.Spend {
+		for value, amount := range values {
+			switch {
+			case value == "":
+				summary.Untagged += amount
+			case len(owners[account][value]) > 0:
+				summary.Attributed += amount
+			default:
+				summary.Unmatched += amount
+			}
+		}
+	}
+
+	for account, values := range owners {
+		if !hasTaggedSpend(spend.Spend[account]) {
+			continue
+		}
+		for value, names := range values {
+			sort.Strings(names)
+			amount, _ := spend.Value(account, value)
+			for _, name := range names {
+				var shared []string
+				for _, other := range names {
+					if other != name {
+						shared = append(shared, other)
+					}
+				}
+				result.Buckets[name].Spend = &BucketSpend{
+					MonthToDate: amount,
+					Currency:    spend.Currency,
+					TagValue:    value,
+					SharedWith:  shared,
+				}
+				summary.Buckets++
+			}
+		}
+	}
+	return summary
+}
+
+// spendAccount is the account a bucket is billed to
+func spendAccount(discovery *BucketDiscovery, defaultAccount string) string {
+	if discovery.BucketInfo.AccountID != "" {
+		return discovery.BucketInfo.AccountID
+	}
+	return defaultAccount
+}
+
+// hasTaggedSpend reports whether any of an account's spend carries the tag
+func hasTaggedSpend(values map[string]float64) bool {
+	for value := range values {
+		if value != "" {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,54 @@
+package analyzer
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/ppiankov/s3spectre/internal/s3"
+)
+
+func TestAnalyzeSpend(t *testing.T) {
+	bucket := func(name, account string, tags map[string]string) *BucketDiscovery {
+		return &BucketDiscovery{Name: name, BucketInfo: &s3.BucketInfo{Name: name, AccountID: account, Tags: tags}}
+	}
+	result := &DiscoveryResult{Buckets: map[string]*BucketDiscovery{
+		"logs":       bucket("logs", "", map[string]string{"Name": "logs"}),
+		"shared-a":   bucket("shared-a", "", map[string]string{"Name": "shared"}),
+		"shared-b":   bucket("shared-b", "", map[string]string{"Name": "shared"}),
+		"idle":       bucket("idle", "", map[string]string{"Name": "idle"}),
+		"untagged":   bucket("untagged", "", nil),
+		"other-acct": bucket("other-acct", "222222222222", map[string]string{"Name": "other-acct"}),
+	}}
+	spend := &s3.TagSpend{
+		TagKey:   "Name",
+		Start:    "2024-05-01",
+		End:      "2024-05-16",
+		Currency: "USD",
+		Total:    30,
+		Spend: map[string]map[string]float64{
+			"111111111111": {"logs": 10, "shared": 6, "gone": 4, "": 5},
+			// The second account has not activated the tag
+			"222222222222": {"": 5},
+		},
+	}
+
+	summary := AnalyzeSpend(result, spend, "111111111111")
+	if summary.Attributed != 16 || summary.Untagged != 10 || summary.Unmatched != 4 || summary.Buckets != 4 {
+		t.Errorf("summary = %+v", summary)
+	}
+	if got := result.Buckets["logs"].Spend; got == nil || got.MonthToDate != 10 || got.TagValue != "logs" {
+		t.Errorf("logs spend = %+v", got)
+	}
+	if got := result.Buckets["shared-a"].Spend; got == nil || got.MonthToDate != 6 || !reflect.DeepEqual(got.SharedWith, []string{"shared-b"}) {
+		t.Errorf("shared-a spend = %+v", got)
+	}
+	if got := result.Buckets["idle"].Spend; got == nil || got.MonthToDate != 0 {
+		t.Errorf("a tagged bucket without spend should show zero, got %+v", got)
+	}
+	if result.Buckets["untagged"].Spend != nil {
+		t.Errorf("an untagged bucket should have no spend, got %+v", result.Buckets["untagged"].Spend)
+	}
+	if result.Buckets["other-acct"].Spend != nil {
+		t.Errorf("a bucket in an account without tagged spend should have no spend, got %+v", result.Buckets["other-acct"].Spend)
+	}
+}
@@ -0,0 +1,97 @@
+package analyzer
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/ppiankov/s3spectre/internal/scanner"
+)
+
+// Modes for buckets referenced only from test paths
+const (
+	TestRefsMark    = "mark"
+	TestRefsExclude = "exclude"
+	TestRefsOff     = "off"
+)
+
+// TestOnlyMessage explains why an excluded test-only bucket was not checked
+const TestOnlyMessage = "Referenced only from test paths; missing-bucket check skipped"
+
+// DefaultTestPathPatterns match the directories and file names of tests,
+// fixtures and examples when none are configured
+var DefaultTestPathPatterns = []string{
+	"test", "tests", "__tests__", "spec", "specs", "testdata",
+	"fixtures", "__fixtures__", "examples", "example",
+	"*_test.go", "test_*.py", "*_test.py", "*.test.*", "*.spec.*",
+}
+
+// TestPaths classifies references found in tests, fixtures and examples.
+// A bucket referenced only from such paths is marked test-only, or, with
+// Exclude, kept out of MISSING_BUCKET findings.
+type TestPaths struct {
+	// Patterns are shell-style globs matched against each path segment, or
+	// against the whole path when they contain a slash
+	Patterns []string
+	Exclude  bool
+}
+
+// NewTestPaths validates the patterns and mode, falling back to
+// DefaultTestPathPatterns and mark. It returns nil when mode is off.
+func NewTestPaths(patterns []string, mode string) (*TestPaths, error) {
+	switch mode {
+	case "", TestRefsMark, TestRefsExclude:
+	case TestRefsOff:
+		return nil, nil
+	default:
+		return nil, fmt.Errorf("invalid test reference mode %q: must be %s, %s or %s", mode, TestRefsMark, TestRefsExclude, TestRefsOff)
+	}
+	if len(patterns) == 0 {
+		patterns = DefaultTestPathPatterns
+	}
+	for _, pattern := range patterns {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("invalid test path pattern %q: %w", pattern, err)
+		}
+	}
+	return &TestPaths{Patterns: patterns, Exclude: mode == TestRefsExclude}, nil
+}
+
+// Match reports whether a file is a test, fixture or example. A nil
+// matcher matches nothing.
+func (t *TestPaths) Match(file string) bool {
+	if t == nil || file == "" {
+		return false
+	}
+	file = strings.TrimPrefix(filepath.ToSlash(filepath.Clean(file)), "./")
+	segments := strings.Split(file, "/")
+	for _, pattern := range t.Patterns {
+		if strings.Contains(pattern, "/") {
+			if ok, _ := path.Match(pattern, file); ok {
+				return true
+			}
+			continue
+		}
+		for _, segment := range segments {
+			if ok, _ := path.Match(pattern, segment); ok {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// TestOnly reports whether there are references and all of them are in
+// test paths
+func (t *TestPaths) TestOnly(refs []scanner.Reference) bool {
+	if t == nil || len(refs) == 0 {
+		return false
+	}
+	for _, ref := range refs {
+		if !t.Match(ref.File) {
+			return false
+		}
+	}
+	return true
+}
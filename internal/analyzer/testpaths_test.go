@@ -0,0 +1,93 @@
+package analyzer
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/ppiankov/s3spectre/internal/s3"
+	"github.com/ppiankov/s3spectre/internal/scanner"
+)
+
+func TestNewTestPaths(t *testing.T) {
+	paths, err := NewTestPaths(nil, TestRefsOff)
+	if err != nil || paths != nil {
+		t.Fatalf("off: got %v, %v", paths, err)
+	}
+	if paths.Match("tests/test_etl.py") {
+		t.Error("nil matcher should match nothing")
+	}
+	if _, err := NewTestPaths(nil, "hide"); err == nil {
+		t.Error("expected error for invalid mode")
+	}
+	if _, err := NewTestPaths([]string{"[bad"}, ""); err == nil {
+		t.Error("expected error for invalid pattern")
+	}
+
+	paths, err = NewTestPaths(nil, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for file, want := range map[string]bool{
+		"tests/test_etl.py":             true,
+		"./internal/etl/etl_test.go":    true,
+		"web/src/upload.spec.ts":        true,
+		"docs/examples/quickstart.yaml": true,
+		"testdata/refs.json":            true,
+		"src/etl/latest.py":             false,
+		"contest/app.py":                false,
+		"":                              false,
+	} {
+		if got := paths.Match(file); got != want {
+			t.Errorf("Match(%q) = %v, want %v", file, got, want)
+		}
+	}
+
+	paths, _ = NewTestPaths([]string{"qa/*/data.yaml"}, TestRefsExclude)
+	if !paths.Exclude || !paths.Match("qa/smoke/data.yaml") || paths.Match("tests/a.py") {
+		t.Errorf("configured patterns replace the defaults: %+v", paths)
+	}
+}
+
+func TestAnalyze_TestOnlyBucket(t *testing.T) {
+	refs := []scanner.Reference{
+		{Bucket: "fixture-bucket", File: "tests/test_upload.py", Line: 4},
+		{Bucket: "fixture-bucket", File: "fixtures/config.yaml", Line: 2},
+		{Bucket: "prod-data", File: "app.py", Line: 10},
+		{Bucket: "prod-data", File: "tests/test_app.py", Line: 7},
+	}
+	info := map[string]*s3.BucketInfo{
+		"fixture-bucket": {Name: "fixture-bucket"},
+		"prod-data":      {Name: "prod-data"},
+	}
+
+	mark, _ := NewTestPaths(nil, TestRefsMark)
+	result := Analyze(refs, info, Config{TestPaths: mark})
+	fixture := result.Buckets["fixture-bucket"]
+	if fixture.Status != StatusMissingBucket || !fixture.TestOnly || !strings.Contains(fixture.Message, "test paths") {
+		t.Errorf("mark: unexpected analysis %+v", fixture)
+	}
+	if fixture.Confidence == nil || fixture.Confidence.Score >= result.Buckets["prod-data"].Confidence.Score {
+		t.Errorf("test-only finding should have lower confidence: %+v", fixture.Confidence)
+	}
+	if prod := result.Buckets["prod-data"]; prod.TestOnly {
+		t.Error("a bucket also referenced outside tests is not test-only")
+	}
+	if len(result.Summary.TestOnlyBuckets) != 1 || len(result.Summary.MissingBuckets) != 2 {
+		t.Errorf("mark: unexpected summary %+v", result.Summary)
+	}
+
+	exclude, _ := NewTestPaths(nil, TestRefsExclude)
+	result = Analyze(refs, info, Config{TestPaths: exclude})
+	fixture = result.Buckets["fixture-bucket"]
+	if fixture.Status != StatusOK || fixture.Message != TestOnlyMessage || !fixture.TestOnly {
+		t.Errorf("exclude: unexpected analysis %+v", fixture)
+	}
+	if len(result.Summary.MissingBuckets) != 1 || result.Summary.MissingBuckets[0] != "prod-data" {
+		t.Errorf("exclude: missing buckets = %v", result.Summary.MissingBuckets)
+	}
+
+	result = Analyze(refs, info, Config{})
+	if result.Buckets["fixture-bucket"].TestOnly {
+		t.Error("without a matcher every reference counts")
+	}
+}
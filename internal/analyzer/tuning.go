@@ -0,0 +1,127 @@
+package analyzer
+
+import "fmt"
+
+const (
+	// tuningMinSamples is the fewest observations a hint is based on
+	tuningMinSamples = 5
+	// tuningNoisyFraction is the share of observations over a threshold at
+	// which the threshold is considered too tight
+	tuningNoisyFraction = 0.5
+	// tuningTargetFraction is the share a suggested threshold may still flag
+	tuningTargetFraction = 0.2
+)
+
+// tuningSteps are the day thresholds a hint may suggest
+var tuningSteps = []int{30, 60, 90, 120, 180, 270, 365, 540, 730, 1095}
+
+// TuningHint suggests a threshold that fits the observed data better
+type TuningHint struct {
+	Flag      string `json:"flag"`
+	ConfigKey string `json:"config_key"`
+	Current   int    `json:"current"`
+	Suggested int    `json:"suggested"`
+	// Exceeding is the share of observations over the current threshold
+	Exceeding float64 `json:"exceeding"`
+	Message   string  `json:"message"`
+}
+
+// SuggestScanTuning suggests a higher --stale-days when most inspected
+// prefixes are older than it
+func SuggestScanTuning(result *Result, config Config) []TuningHint {
+	var ages []int
+	for _, bucket := range result.Buckets {
+		if bucket == nil {
+			continue
+		}
+		for _, prefix := range bucket.Prefixes {
+			if prefix.Status == StatusMissingPrefix || prefix.ObjectCount == 0 {
+				continue
+			}
+			ages = append(ages, prefix.DaysSinceModified)
+		}
+	}
+	hint := suggestThreshold("--stale-days", "stale_days", "prefixes", ages, config.StaleThresholdDays)
+	if hint == nil {
+		return nil
+	}
+	return []TuningHint{*hint}
+}
+
+// SuggestDiscoveryTuning suggests a higher --inactive-days when most
+// inspected buckets have been inactive for longer than it
+func SuggestDiscoveryTuning(result *DiscoveryResult, config DiscoveryConfig) []TuningHint {
+	var ages []int
+	for _, discovery := range result.Buckets {
+		if discovery == nil {
+			continue
+		}
+		info := discovery.BucketInfo
+		if info == nil || !info.Exists || info.TimedOut || info.Interrupted || info.DaysSinceActivity == 0 {
+			continue
+		}
+		ages = append(ages, info.DaysSinceActivity)
+	}
+	hint := suggestThreshold("--inactive-days", "inactive_days", "buckets", ages, config.InactivityThresholdDays)
+	if hint == nil {
+		return nil
+	}
+	return []TuningHint{*hint}
+}
+
+// suggestThreshold returns a hint when more than half of the ages exceed the
+// current threshold, suggesting the smallest step that flags at most a fifth
+// of them
+func suggestThreshold(flag, key, noun string, ages []int, current int) *TuningHint {
+	if current <= 0 || len(ages) < tuningMinSamples {
+		return nil
+	}
+	exceeding := fractionOver(ages, current)
+	if exceeding <= tuningNoisyFraction {
+		return nil
+	}
+
+	suggested := 0
+	for _, step := range tuningSteps {
+		if step <= current {
+			continue
+		}
+		suggested = step
+		if fractionOver(ages, step) <= tuningTargetFraction {
+			break
+		}
+	}
+	if suggested == 0 {
+		return nil
+	}
+
+	return &TuningHint{
+		Flag:      flag,
+		ConfigKey: key,
+		Current:   current,
+		Suggested: suggested,
+		Exceeding: exceeding,
+		Message: fmt.Sprintf("%.0f%% of your %s exceed %s=%d; consider %d",
+			exceeding*100, noun, flag[2:], current, suggested),
+	}
+}
+
+// fractionOver returns the share of values greater than threshold
+func fractionOver(values []int, threshold int) float64 {
+	over := 0
+	for _, v := range values {
+		if v > threshold {
+			over++
+		}
+	}
+	return float64(over) / float64(len(values))
+}
+
+// TuningPatch returns the config file settings that apply the hints
+func TuningPatch(hints []TuningHint) map[string]int {
+	patch := make(map[string]int, len(hints))
+	for _, h := range hints {
+		patch[h.ConfigKey] = h.Suggested
+	}
+	return patch
+}
@@ -0,0 +1,67 @@
+package analyzer
+
+import (
+	"testing"
+
+	"github.com/ppiankov/s3spectre/internal/s3"
+)
+
+func scanResultWithAges(ages ...int) *Result {
+	bucket := &BucketAnalysis{Name: "data"}
+	for _, age := range ages {
+		bucket.Prefixes = append(bucket.Prefixes, PrefixAnalysis{Status: StatusOK, ObjectCount: 1, DaysSinceModified: age})
+	}
+	return &Result{Buckets: map[string]*BucketAnalysis{"data": bucket}}
+}
+
+func TestSuggestScanTuning(t *testing.T) {
+	result := scanResultWithAges(100, 120, 150, 160, 170, 200, 400, 20, 30, 40)
+	hints := SuggestScanTuning(result, Config{StaleThresholdDays: 90})
+	if len(hints) != 1 {
+		t.Fatalf("expected 1 hint, got %+v", hints)
+	}
+	h := hints[0]
+	if h.ConfigKey != "stale_days" || h.Current != 90 || h.Suggested != 180 {
+		t.Errorf("unexpected hint %+v", h)
+	}
+	if h.Message != "70% of your prefixes exceed stale-days=90; consider 180" {
+		t.Errorf("unexpected message %q", h.Message)
+	}
+}
+
+func TestSuggestScanTuning_NoHint(t *testing.T) {
+	tests := []struct {
+		name   string
+		result *Result
+	}{
+		{"threshold fits", scanResultWithAges(10, 20, 30, 40, 100, 200)},
+		{"too few samples", scanResultWithAges(400, 500)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if hints := SuggestScanTuning(tt.result, Config{StaleThresholdDays: 90}); len(hints) != 0 {
+				t.Errorf("expected no hints, got %+v", hints)
+			}
+		})
+	}
+}
+
+func TestSuggestDiscoveryTuning(t *testing.T) {
+	result := &DiscoveryResult{Buckets: map[string]*BucketDiscovery{}}
+	for i, age := range []int{200, 250, 300, 320, 400, 800} {
+		name := string(rune('a' + i))
+		result.Buckets[name] = &BucketDiscovery{BucketInfo: &s3.BucketInfo{Exists: true, DaysSinceActivity: age}}
+	}
+	result.Buckets["skipped"] = &BucketDiscovery{BucketInfo: &s3.BucketInfo{Exists: true, TimedOut: true, DaysSinceActivity: 900}}
+
+	hints := SuggestDiscoveryTuning(result, DiscoveryConfig{InactivityThresholdDays: 180})
+	if len(hints) != 1 {
+		t.Fatalf("expected 1 hint, got %+v", hints)
+	}
+	if h := hints[0]; h.ConfigKey != "inactive_days" || h.Suggested != 540 {
+		t.Errorf("unexpected hint %+v", h)
+	}
+	if patch := TuningPatch(hints); patch["inactive_days"] != 540 {
+		t.Errorf("unexpected patch %v", patch)
+	}
+}
@@ -1,18 +1,41 @@
 package analyzer
 
+import (
+	"github.com/ppiankov/s3spectre/internal/rules"
+	"github.com/ppiankov/s3spectre/internal/s3"
+	"github.com/ppiankov/s3spectre/internal/scanner"
+)
+
 // Status represents the status of a bucket/prefix
 type Status string
 
+// Statuses are named in the rules registry, which maps each to its rule
 const (
-	StatusOK                 Status = "OK"
-	StatusMissingBucket      Status = "MISSING_BUCKET"
-	StatusUnusedBucket       Status = "UNUSED_BUCKET"
-	StatusMissingPrefix      Status = "MISSING_PREFIX"
-	StatusStalePrefix        Status = "STALE_PREFIX"
-	StatusVersionSprawl      Status = "VERSION_SPRAWL"
-	StatusLifecycleMisconfig Status = "LIFECYCLE_MISCONFIG"
-	StatusRisky              Status = "RISKY"
-	StatusInactive           Status = "INACTIVE"
+	StatusOK                 Status = rules.StatusOK
+	StatusMissingBucket      Status = rules.StatusMissingBucket
+	StatusUnusedBucket       Status = rules.StatusUnusedBucket
+	StatusMissingPrefix      Status = rules.StatusMissingPrefix
+	StatusStalePrefix        Status = rules.StatusStalePrefix
+	StatusVersionSprawl      Status = rules.StatusVersionSprawl
+	StatusLifecycleMisconfig Status = rules.StatusLifecycleMisconfig
+	StatusRisky              Status = rules.StatusRisky
+	StatusInactive           Status = rules.StatusInactive
+	StatusOrphanedVault      Status = rules.StatusOrphanedVault
+	StatusMissingVault       Status = rules.StatusMissingVault
+	StatusPermissionGap      Status = rules.StatusPermissionGap
+	StatusNoGuardDutyS3      Status = rules.StatusNoGuardDutyS3
+	StatusNoAccountPAB       Status = rules.StatusNoAccountPAB
+	StatusLowLifecycle       Status = rules.StatusLowLifecycle
+	StatusPrefixNearMiss     Status = rules.StatusPrefixNearMiss
+	StatusEnvDrift           Status = rules.StatusEnvDrift
+	StatusWildcardPrincipal  Status = rules.StatusWildcardPrincipal
+	StatusCrossAccount       Status = rules.StatusCrossAccount
+	StatusInsecureTransport  Status = rules.StatusInsecureTransport
+	StatusReplicationDestination Status = rules.StatusReplicationDestination
+	StatusNoLogging              Status = rules.StatusNoLogging
+	StatusSensitiveDataExposed   Status = rules.StatusSensitiveDataExposed
+	StatusCorrelated             Status = rules.StatusCorrelated
+	StatusTimedOut               Status = rules.StatusTimedOut
 )
 
 // BucketAnalysis contains analysis results for a bucket
@@ -26,6 +49,19 @@ type BucketAnalysis struct {
 	LifecycleRules    int           `json:"lifecycle_rules"`
 	Prefixes          []PrefixAnalysis `json:"prefixes,omitempty"`
 	UnusedScore       *UnusedScore  `json:"unused_score,omitempty"`
+	Fingerprint       string        `json:"fingerprint,omitempty"`
+	// Confidence is set on findings; it is nil for OK buckets
+	Confidence *Confidence `json:"confidence,omitempty"`
+	// External buckets are third-party and were not checked
+	External bool `json:"external,omitempty"`
+	// Account owns the bucket in multi-account runs; empty means the caller's
+	Account string `json:"account,omitempty"`
+	// Suggestions are similarly named existing buckets for MISSING_BUCKET
+	Suggestions []string `json:"suggestions,omitempty"`
+	// TestOnly buckets are referenced only from test, fixture or example paths
+	TestOnly bool `json:"test_only,omitempty"`
+	// Blame is the most recent commit among the finding's references
+	Blame *scanner.Blame `json:"blame,omitempty"`
 }
 
 // PrefixAnalysis contains analysis results for a prefix
@@ -35,6 +71,13 @@ type PrefixAnalysis struct {
 	Message           string `json:"message,omitempty"`
 	ObjectCount       int    `json:"object_count"`
 	DaysSinceModified int    `json:"days_since_modified,omitempty"`
+	Fingerprint       string `json:"fingerprint,omitempty"`
+	// Confidence is set on findings; it is nil for OK prefixes
+	Confidence *Confidence `json:"confidence,omitempty"`
+	// Suggestion is the closest existing prefix for PREFIX_NEAR_MISS
+	Suggestion string `json:"suggestion,omitempty"`
+	// Blame is the most recent commit among the finding's references
+	Blame *scanner.Blame `json:"blame,omitempty"`
 }
 
 // Summary contains high-level analysis summary
@@ -47,12 +90,38 @@ type Summary struct {
 	StalePrefixes        []string `json:"stale_prefixes,omitempty"`
 	VersionSprawl        []string `json:"version_sprawl,omitempty"`
 	LifecycleMisconfig   []string `json:"lifecycle_misconfig,omitempty"`
+	OrphanedVaults       []string `json:"orphaned_vaults,omitempty"`
+	MissingVaults        []string `json:"missing_vaults,omitempty"`
+	PermissionGaps       []string `json:"permission_gaps,omitempty"`
+	// PermissionsUnknown lists buckets whose access was not simulated because
+	// their bucket policy could not be read
+	PermissionsUnknown []string `json:"permissions_unknown,omitempty"`
+	// TimedOutBuckets lists buckets whose checks were skipped at a deadline
+	TimedOutBuckets []string `json:"timed_out_buckets,omitempty"`
+	// ExternalBuckets lists referenced third-party buckets left unchecked
+	ExternalBuckets []string `json:"external_buckets,omitempty"`
+	// PrefixNearMisses lists missing prefixes with a similar existing prefix
+	PrefixNearMisses []string `json:"prefix_near_misses,omitempty"`
+	// TestOnlyBuckets lists missing buckets referenced only from test paths
+	TestOnlyBuckets []string `json:"test_only_buckets,omitempty"`
+	// DeadlineSkips lists the checks skipped at the per-bucket deadline
+	DeadlineSkips []DeadlineSkip `json:"deadline_skips,omitempty"`
+	// ReplicationDestinations lists buckets that scored as unused but are
+	// written by replication from another scanned bucket
+	ReplicationDestinations []string `json:"replication_destinations,omitempty"`
+	// Correlated lists the buckets with findings from other Spectre tools
+	Correlated []string `json:"correlated,omitempty"`
 }
 
 // Result contains the complete analysis result
 type Result struct {
 	Summary Summary                    `json:"summary"`
 	Buckets map[string]*BucketAnalysis `json:"buckets"`
+	Vaults  map[string]*VaultAnalysis  `json:"vaults,omitempty"`
+	// Permissions holds the references a principal is not allowed
+	Permissions []*PermissionAnalysis `json:"permissions,omitempty"`
+	// Correlations join other Spectre tools' findings to flagged buckets
+	Correlations []Correlation `json:"correlations,omitempty"`
 }
 
 // Config contains analyzer configuration
@@ -61,6 +130,15 @@ type Config struct {
 	UnusedThresholdDays   int
 	CheckUnused           bool
 	UnusedScoreThreshold  int
+	// Classification weights unused scores by data classification tags
+	Classification *Classification
+	// External buckets are reported without being checked
+	External *ExternalBuckets
+	// Environments recognizes environment segments in bucket names
+	Environments s3.Environments
+	// TestPaths classifies references from tests, fixtures and examples;
+	// nil treats every reference alike
+	TestPaths *TestPaths
 }
 
 // UnusedScore contains scoring details for unused bucket detection
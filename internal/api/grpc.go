@@ -0,0 +1,243 @@
+package api
+
+import (
+	"context"
+	"crypto/subtle"
+	"fmt"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/ppiankov/s3spectre/internal/api/pb"
+	"github.com/ppiankov/s3spectre/internal/baseline"
+	"github.com/ppiankov/s3spectre/internal/history"
+	"github.com/ppiankov/s3spectre/internal/rules"
+)
+
+// GRPCService implements the s3spectre.v1.ReportService over a history store
+type GRPCService struct {
+	pb.UnimplementedReportServiceServer
+	store         history.Store
+	watchInterval time.Duration
+}
+
+// NewGRPCServer creates a gRPC server exposing ReportService. Calls must carry
+// "authorization: Bearer <token>" metadata; WatchReports polls the history
+// store every watchInterval.
+func NewGRPCServer(store history.Store, token string, watchInterval time.Duration) (*grpc.Server, error) {
+	if token == "" {
+		return nil, fmt.Errorf("an API token is required")
+	}
+	if watchInterval <= 0 {
+		return nil, fmt.Errorf("watch interval must be positive")
+	}
+	auth := grpcAuth{token: token}
+	server := grpc.NewServer(
+		grpc.UnaryInterceptor(auth.unary),
+		grpc.StreamInterceptor(auth.stream),
+	)
+	pb.RegisterReportServiceServer(server, &GRPCService{store: store, watchInterval: watchInterval})
+	return server, nil
+}
+
+// GetReport returns the named report, or the latest when no name is given
+func (s *GRPCService) GetReport(ctx context.Context, req *pb.GetReportRequest) (*pb.Report, error) {
+	snapshots, err := s.load(ctx)
+	if err != nil {
+		return nil, err
+	}
+	snap, ok := findSnapshot(snapshots, req.GetName(), len(snapshots)-1)
+	if !ok {
+		return nil, status.Errorf(codes.NotFound, "report %s not found", req.GetName())
+	}
+	return toPBReport(snap), nil
+}
+
+// StreamFindings streams one report's findings at or above the requested severity
+func (s *GRPCService) StreamFindings(req *pb.StreamFindingsRequest, stream pb.ReportService_StreamFindingsServer) error {
+	snapshots, err := s.load(stream.Context())
+	if err != nil {
+		return err
+	}
+	snap, ok := findSnapshot(snapshots, req.GetReport(), len(snapshots)-1)
+	if !ok {
+		return status.Errorf(codes.NotFound, "report %s not found", req.GetReport())
+	}
+	for _, f := range toPBFindings(snap.Findings) {
+		if !severityAtLeast(f.Severity, req.GetMinSeverity()) {
+			continue
+		}
+		if err := stream.Send(f); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// WatchReports streams the latest report, then each report that appears in
+// the history store until the client cancels
+func (s *GRPCService) WatchReports(_ *pb.WatchReportsRequest, stream pb.ReportService_WatchReportsServer) error {
+	snapshots, err := s.load(stream.Context())
+	if err != nil {
+		return err
+	}
+	sent := make(map[string]bool, len(snapshots))
+	for _, snap := range snapshots {
+		sent[snap.Name] = true
+	}
+	if err := stream.Send(toPBReport(snapshots[len(snapshots)-1])); err != nil {
+		return err
+	}
+
+	ticker := time.NewTicker(s.watchInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stream.Context().Done():
+			return nil
+		case <-ticker.C:
+		}
+
+		snapshots, err := s.store.Snapshots(stream.Context())
+		if err != nil {
+			// A report may be mid-write; retry on the next tick
+			continue
+		}
+		for _, snap := range snapshots {
+			if sent[snap.Name] {
+				continue
+			}
+			sent[snap.Name] = true
+			if err := stream.Send(toPBReport(snap)); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// Diff compares two reports; from defaults to the one before the latest and
+// to defaults to the latest
+func (s *GRPCService) Diff(ctx context.Context, req *pb.DiffRequest) (*pb.DiffResponse, error) {
+	snapshots, err := s.load(ctx)
+	if err != nil {
+		return nil, err
+	}
+	to, ok := findSnapshot(snapshots, req.GetTo(), len(snapshots)-1)
+	if !ok {
+		return nil, status.Errorf(codes.NotFound, "report %s not found", req.GetTo())
+	}
+	from, ok := findSnapshot(snapshots, req.GetFrom(), len(snapshots)-2)
+	if !ok {
+		if req.GetFrom() == "" {
+			return nil, status.Error(codes.FailedPrecondition, "diff needs two reports")
+		}
+		return nil, status.Errorf(codes.NotFound, "report %s not found", req.GetFrom())
+	}
+
+	diff := baseline.Diff(to.Findings, from.Findings)
+	return &pb.DiffResponse{
+		From:      from.Name,
+		To:        to.Name,
+		New:       toPBFindings(diff.New),
+		Resolved:  toPBFindings(diff.Resolved),
+		Unchanged: int32(len(diff.Unchanged)),
+	}, nil
+}
+
+func (s *GRPCService) load(ctx context.Context) ([]history.Snapshot, error) {
+	snapshots, err := s.store.Snapshots(ctx)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	if len(snapshots) == 0 {
+		return nil, status.Error(codes.NotFound, "no reports in history")
+	}
+	return snapshots, nil
+}
+
+var pbSeverities = map[string]pb.Severity{
+	"high":   pb.Severity_SEVERITY_HIGH,
+	"medium": pb.Severity_SEVERITY_MEDIUM,
+	"low":    pb.Severity_SEVERITY_LOW,
+	"info":   pb.Severity_SEVERITY_INFO,
+}
+
+// severityAtLeast reports whether severity is at or above min; an
+// unspecified min passes everything. Severity enum numbers are not in
+// severity order, so they are compared by name.
+func severityAtLeast(severity, min pb.Severity) bool {
+	if min == pb.Severity_SEVERITY_UNSPECIFIED {
+		return true
+	}
+	return rules.SeverityAtLeast(severityName(severity), severityName(min))
+}
+
+// severityName returns the severity name of a pb.Severity, "" when unspecified
+func severityName(severity pb.Severity) string {
+	for name, s := range pbSeverities {
+		if s == severity {
+			return name
+		}
+	}
+	return ""
+}
+
+func toPBReport(snap history.Snapshot) *pb.Report {
+	return &pb.Report{
+		Name:      snap.Name,
+		Kind:      snap.Kind,
+		Timestamp: timestamppb.New(snap.Timestamp),
+		Findings:  toPBFindings(snap.Findings),
+	}
+}
+
+func toPBFindings(findings []baseline.Finding) []*pb.Finding {
+	sorted := toFindings(findings, nil, time.Time{})
+	out := make([]*pb.Finding, 0, len(sorted))
+	for _, f := range sorted {
+		out = append(out, &pb.Finding{
+			Type:        f.Type,
+			Severity:    pbSeverities[f.Severity],
+			Bucket:      f.Bucket,
+			Prefix:      f.Prefix,
+			Fingerprint: f.Fingerprint,
+			Account:     f.Account,
+		})
+	}
+	return out
+}
+
+// grpcAuth checks the bearer token carried in call metadata
+type grpcAuth struct {
+	token string
+}
+
+func (a grpcAuth) check(ctx context.Context) error {
+	md, _ := metadata.FromIncomingContext(ctx)
+	for _, value := range md.Get("authorization") {
+		given, ok := strings.CutPrefix(value, "Bearer ")
+		if ok && subtle.ConstantTimeCompare([]byte(given), []byte(a.token)) == 1 {
+			return nil
+		}
+	}
+	return status.Error(codes.Unauthenticated, "unauthorized")
+}
+
+func (a grpcAuth) unary(ctx context.Context, req interface{}, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	if err := a.check(ctx); err != nil {
+		return nil, err
+	}
+	return handler(ctx, req)
+}
+
+func (a grpcAuth) stream(srv interface{}, ss grpc.ServerStream, _ *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	if err := a.check(ss.Context()); err != nil {
+		return err
+	}
+	return handler(srv, ss)
+}
@@ -0,0 +1,180 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	"github.com/ppiankov/s3spectre/internal/analyzer"
+	"github.com/ppiankov/s3spectre/internal/api/pb"
+	"github.com/ppiankov/s3spectre/internal/history"
+	"github.com/ppiankov/s3spectre/internal/report"
+)
+
+func writeDiscovery(t *testing.T, dir, name string, ts time.Time, buckets map[string]analyzer.Status) {
+	t.Helper()
+	data := report.DiscoveryData{Timestamp: ts, Buckets: make(map[string]*analyzer.BucketDiscovery)}
+	for bucket, st := range buckets {
+		data.Buckets[bucket] = &analyzer.BucketDiscovery{Status: st}
+	}
+	raw, _ := json.Marshal(data)
+	if err := os.WriteFile(filepath.Join(dir, name+".json"), raw, 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func newGRPCClient(t *testing.T, dir string) pb.ReportServiceClient {
+	t.Helper()
+	server, err := NewGRPCServer(history.NewDirStore(dir), "secret", 10*time.Millisecond)
+	if err != nil {
+		t.Fatalf("NewGRPCServer failed: %v", err)
+	}
+	lis := bufconn.Listen(1 << 20)
+	go func() { _ = server.Serve(lis) }()
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.Dial("bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+	return pb.NewReportServiceClient(conn)
+}
+
+func authed(ctx context.Context) context.Context {
+	return metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer secret")
+}
+
+func TestGRPC_Unauthenticated(t *testing.T) {
+	dir := t.TempDir()
+	writeDiscovery(t, dir, "day1", time.Now(), map[string]analyzer.Status{"logs": analyzer.StatusInactive})
+	client := newGRPCClient(t, dir)
+
+	_, err := client.GetReport(context.Background(), &pb.GetReportRequest{})
+	if status.Code(err) != codes.Unauthenticated {
+		t.Fatalf("expected Unauthenticated, got %v", err)
+	}
+}
+
+func TestGRPC_GetReportAndDiff(t *testing.T) {
+	dir := t.TempDir()
+	day := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	writeDiscovery(t, dir, "day1", day, map[string]analyzer.Status{"logs": analyzer.StatusInactive, "media": analyzer.StatusRisky})
+	writeDiscovery(t, dir, "day2", day.Add(24*time.Hour), map[string]analyzer.Status{"logs": analyzer.StatusInactive, "tmp": analyzer.StatusUnusedBucket})
+	client := newGRPCClient(t, dir)
+	ctx := authed(context.Background())
+
+	r, err := client.GetReport(ctx, &pb.GetReportRequest{})
+	if err != nil {
+		t.Fatalf("GetReport failed: %v", err)
+	}
+	if r.Name != "day2" || r.Kind != "discover" || len(r.Findings) != 2 || !r.Timestamp.AsTime().Equal(day.Add(24*time.Hour)) {
+		t.Fatalf("unexpected report: %v", r)
+	}
+
+	if _, err := client.GetReport(ctx, &pb.GetReportRequest{Name: "missing"}); status.Code(err) != codes.NotFound {
+		t.Fatalf("expected NotFound, got %v", err)
+	}
+
+	diff, err := client.Diff(ctx, &pb.DiffRequest{})
+	if err != nil {
+		t.Fatalf("Diff failed: %v", err)
+	}
+	if diff.From != "day1" || len(diff.New) != 1 || diff.New[0].Bucket != "tmp" || len(diff.Resolved) != 1 || diff.Unchanged != 1 {
+		t.Fatalf("unexpected diff: %v", diff)
+	}
+}
+
+func TestGRPC_StreamFindings(t *testing.T) {
+	dir := t.TempDir()
+	writeDiscovery(t, dir, "day1", time.Now(), map[string]analyzer.Status{
+		"logs":  analyzer.StatusInactive,
+		"media": analyzer.StatusRisky,
+		"tmp":   analyzer.StatusUnusedBucket,
+	})
+	client := newGRPCClient(t, dir)
+
+	stream, err := client.StreamFindings(authed(context.Background()), &pb.StreamFindingsRequest{MinSeverity: pb.Severity_SEVERITY_MEDIUM})
+	if err != nil {
+		t.Fatalf("StreamFindings failed: %v", err)
+	}
+	var got []*pb.Finding
+	for {
+		f, err := stream.Recv()
+		if err != nil {
+			break
+		}
+		got = append(got, f)
+	}
+	for _, f := range got {
+		if f.Severity != pb.Severity_SEVERITY_MEDIUM && f.Severity != pb.Severity_SEVERITY_HIGH {
+			t.Errorf("finding below minimum severity streamed: %v", f)
+		}
+	}
+	if len(got) == 0 || len(got) == 3 {
+		t.Fatalf("expected severity filter to drop some findings, got %d", len(got))
+	}
+}
+
+func TestGRPC_WatchReports(t *testing.T) {
+	dir := t.TempDir()
+	day := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	writeDiscovery(t, dir, "day1", day, map[string]analyzer.Status{"logs": analyzer.StatusInactive})
+	client := newGRPCClient(t, dir)
+
+	ctx, cancel := context.WithTimeout(authed(context.Background()), 5*time.Second)
+	defer cancel()
+	stream, err := client.WatchReports(ctx, &pb.WatchReportsRequest{})
+	if err != nil {
+		t.Fatalf("WatchReports failed: %v", err)
+	}
+
+	first, err := stream.Recv()
+	if err != nil || first.Name != "day1" {
+		t.Fatalf("expected latest report first, got %v (%v)", first, err)
+	}
+
+	writeDiscovery(t, dir, "day2", day.Add(24*time.Hour), map[string]analyzer.Status{})
+	next, err := stream.Recv()
+	if err != nil || next.Name != "day2" {
+		t.Fatalf("expected new report, got %v (%v)", next, err)
+	}
+}
+
+func TestSeverityNumbers(t *testing.T) {
+	// Published values must not change; INFO was added after HIGH
+	for severity, want := range map[pb.Severity]int32{
+		pb.Severity_SEVERITY_UNSPECIFIED: 0,
+		pb.Severity_SEVERITY_LOW:         1,
+		pb.Severity_SEVERITY_MEDIUM:      2,
+		pb.Severity_SEVERITY_HIGH:        3,
+		pb.Severity_SEVERITY_INFO:        4,
+	} {
+		if int32(severity) != want {
+			t.Errorf("%s = %d, want %d", severity, severity, want)
+		}
+	}
+	if severityAtLeast(pb.Severity_SEVERITY_INFO, pb.Severity_SEVERITY_LOW) {
+		t.Error("INFO should rank below LOW")
+	}
+	if !severityAtLeast(pb.Severity_SEVERITY_LOW, pb.Severity_SEVERITY_INFO) {
+		t.Error("LOW should rank at or above INFO")
+	}
+	if !severityAtLeast(pb.Severity_SEVERITY_INFO, pb.Severity_SEVERITY_UNSPECIFIED) {
+		t.Error("an unspecified minimum should pass every finding")
+	}
+}
@@ -0,0 +1,358 @@
+package api
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ppiankov/s3spectre/internal/baseline"
+	"github.com/ppiankov/s3spectre/internal/history"
+)
+
+// Server serves findings from a history store over an authenticated JSON
+// API. The store is re-read per request so reports written by scheduled runs
+// are picked up without a restart.
+type Server struct {
+	store history.Store
+	token string
+	mux   *http.ServeMux
+}
+
+// Finding is a finding as returned by the API
+type Finding struct {
+	Type        string `json:"type"`
+	Severity    string `json:"severity,omitempty"`
+	Bucket      string `json:"bucket"`
+	Prefix      string `json:"prefix,omitempty"`
+	Account     string `json:"account,omitempty"`
+	Fingerprint string `json:"fingerprint,omitempty"`
+	// State is the finding's triage status, when one is recorded
+	State string `json:"state,omitempty"`
+}
+
+// FindingsResponse is the body of GET /findings
+type FindingsResponse struct {
+	Report    string    `json:"report"`
+	Kind      string    `json:"kind"`
+	Timestamp time.Time `json:"timestamp"`
+	Findings  []Finding `json:"findings"`
+}
+
+// BucketHistoryEntry lists a bucket's findings in one report
+type BucketHistoryEntry struct {
+	Report    string    `json:"report"`
+	Timestamp time.Time `json:"timestamp"`
+	Findings  []Finding `json:"findings"`
+}
+
+// BucketResponse is the body of GET /buckets/{name}
+type BucketResponse struct {
+	Bucket  string               `json:"bucket"`
+	Current []Finding            `json:"current"`
+	History []BucketHistoryEntry `json:"history"`
+}
+
+// DiffResponse is the body of GET /diff
+type DiffResponse struct {
+	From      string    `json:"from"`
+	To        string    `json:"to"`
+	New       []Finding `json:"new"`
+	Resolved  []Finding `json:"resolved"`
+	Unchanged int       `json:"unchanged"`
+}
+
+// StatesResponse is the body of GET /states
+type StatesResponse struct {
+	States []history.State `json:"states"`
+}
+
+// StateRequest is the body of PUT /states/{fingerprint}
+type StateRequest struct {
+	Status string `json:"status"`
+	Reason string `json:"reason,omitempty"`
+	Actor  string `json:"actor,omitempty"`
+}
+
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+// NewServer creates an API server. Requests must carry
+// "Authorization: Bearer <token>"; an empty token is rejected.
+func NewServer(store history.Store, token string) (*Server, error) {
+	if token == "" {
+		return nil, fmt.Errorf("an API token is required")
+	}
+	s := &Server{store: store, token: token, mux: http.NewServeMux()}
+	s.mux.HandleFunc("/findings", s.handleFindings)
+	s.mux.HandleFunc("/buckets/", s.handleBucket)
+	s.mux.HandleFunc("/diff", s.handleDiff)
+	s.mux.HandleFunc("/states", s.handleStates)
+	s.mux.HandleFunc("/states/", s.handleState)
+	return s, nil
+}
+
+// ServeHTTP authenticates the request and dispatches it
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !s.authorized(r) {
+		w.Header().Set("WWW-Authenticate", `Bearer realm="s3spectre"`)
+		writeJSON(w, http.StatusUnauthorized, errorResponse{Error: "unauthorized"})
+		return
+	}
+	// Triage states are the only thing the API changes
+	if r.Method != http.MethodGet && !(r.Method == http.MethodPut && strings.HasPrefix(r.URL.Path, "/states/")) {
+		writeJSON(w, http.StatusMethodNotAllowed, errorResponse{Error: "method not allowed"})
+		return
+	}
+	s.mux.ServeHTTP(w, r)
+}
+
+func (s *Server) authorized(r *http.Request) bool {
+	given, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok {
+		return false
+	}
+	return subtle.ConstantTimeCompare([]byte(given), []byte(s.token)) == 1
+}
+
+// handleFindings returns the findings of the latest report, or of ?report=NAME
+func (s *Server) handleFindings(w http.ResponseWriter, r *http.Request) {
+	snapshots, states, ok := s.load(w, r)
+	if !ok {
+		return
+	}
+	snap, ok := pick(w, snapshots, r.URL.Query().Get("report"), len(snapshots)-1)
+	if !ok {
+		return
+	}
+	writeJSON(w, http.StatusOK, FindingsResponse{
+		Report:    snap.Name,
+		Kind:      snap.Kind,
+		Timestamp: snap.Timestamp,
+		Findings:  toFindings(snap.Findings, states, snap.Timestamp),
+	})
+}
+
+// handleBucket returns a bucket's current findings and its history, newest first
+func (s *Server) handleBucket(w http.ResponseWriter, r *http.Request) {
+	name := strings.TrimPrefix(r.URL.Path, "/buckets/")
+	if name == "" || strings.Contains(name, "/") {
+		writeJSON(w, http.StatusNotFound, errorResponse{Error: "bucket name required"})
+		return
+	}
+	snapshots, states, ok := s.load(w, r)
+	if !ok {
+		return
+	}
+
+	resp := BucketResponse{Bucket: name, Current: []Finding{}, History: []BucketHistoryEntry{}}
+	seen := false
+	for i := len(snapshots) - 1; i >= 0; i-- {
+		var matched []baseline.Finding
+		for _, f := range snapshots[i].Findings {
+			if f.Bucket == name {
+				matched = append(matched, f)
+			}
+		}
+		findings := toFindings(matched, states, snapshots[i].Timestamp)
+		if i == len(snapshots)-1 {
+			resp.Current = findings
+		}
+		seen = seen || len(matched) > 0
+		resp.History = append(resp.History, BucketHistoryEntry{
+			Report:    snapshots[i].Name,
+			Timestamp: snapshots[i].Timestamp,
+			Findings:  findings,
+		})
+	}
+	if !seen {
+		writeJSON(w, http.StatusNotFound, errorResponse{Error: fmt.Sprintf("no findings recorded for bucket %s", name)})
+		return
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// handleDiff compares two reports; from defaults to the one before the latest
+// and to defaults to the latest
+func (s *Server) handleDiff(w http.ResponseWriter, r *http.Request) {
+	snapshots, states, ok := s.load(w, r)
+	if !ok {
+		return
+	}
+	if len(snapshots) < 2 && (r.URL.Query().Get("from") == "" || r.URL.Query().Get("to") == "") {
+		writeJSON(w, http.StatusBadRequest, errorResponse{Error: "diff needs two reports"})
+		return
+	}
+	to, ok := pick(w, snapshots, r.URL.Query().Get("to"), len(snapshots)-1)
+	if !ok {
+		return
+	}
+	from, ok := pick(w, snapshots, r.URL.Query().Get("from"), len(snapshots)-2)
+	if !ok {
+		return
+	}
+
+	diff := baseline.Diff(to.Findings, from.Findings)
+	writeJSON(w, http.StatusOK, DiffResponse{
+		From:      from.Name,
+		To:        to.Name,
+		New:       toFindings(diff.New, states, to.Timestamp),
+		Resolved:  toFindings(diff.Resolved, states, to.Timestamp),
+		Unchanged: len(diff.Unchanged),
+	})
+}
+
+// handleStates lists the recorded triage states, by fingerprint
+func (s *Server) handleStates(w http.ResponseWriter, r *http.Request) {
+	states, err := s.store.States(r.Context())
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, errorResponse{Error: err.Error()})
+		return
+	}
+	resp := StatesResponse{States: make([]history.State, 0, len(states))}
+	for _, state := range states {
+		resp.States = append(resp.States, state)
+	}
+	sort.Slice(resp.States, func(i, j int) bool {
+		return resp.States[i].Fingerprint < resp.States[j].Fingerprint
+	})
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// handleState returns a finding's triage state, or records it on PUT
+func (s *Server) handleState(w http.ResponseWriter, r *http.Request) {
+	fingerprint := strings.TrimPrefix(r.URL.Path, "/states/")
+	if fingerprint == "" || strings.Contains(fingerprint, "/") {
+		writeJSON(w, http.StatusNotFound, errorResponse{Error: "finding fingerprint required"})
+		return
+	}
+	if r.Method == http.MethodGet {
+		states, err := s.store.States(r.Context())
+		if err != nil {
+			writeJSON(w, http.StatusInternalServerError, errorResponse{Error: err.Error()})
+			return
+		}
+		state, ok := states[fingerprint]
+		if !ok {
+			writeJSON(w, http.StatusNotFound, errorResponse{Error: fmt.Sprintf("no state recorded for %s", fingerprint)})
+			return
+		}
+		writeJSON(w, http.StatusOK, state)
+		return
+	}
+
+	var req StateRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 64*1024)).Decode(&req); err != nil {
+		writeJSON(w, http.StatusBadRequest, errorResponse{Error: "invalid state: " + err.Error()})
+		return
+	}
+	state := history.State{
+		Fingerprint: fingerprint,
+		Status:      req.Status,
+		Reason:      req.Reason,
+		Actor:       req.Actor,
+		UpdatedAt:   time.Now().UTC(),
+	}
+	if err := state.Validate(); err != nil {
+		writeJSON(w, http.StatusBadRequest, errorResponse{Error: err.Error()})
+		return
+	}
+	if err := s.store.PutState(r.Context(), state); err != nil {
+		writeJSON(w, http.StatusInternalServerError, errorResponse{Error: err.Error()})
+		return
+	}
+	writeJSON(w, http.StatusOK, state)
+}
+
+// load reads the reports and triage states, writing an error response when
+// either cannot be read or there are no reports
+func (s *Server) load(w http.ResponseWriter, r *http.Request) ([]history.Snapshot, map[string]history.State, bool) {
+	snapshots, err := s.store.Snapshots(r.Context())
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, errorResponse{Error: err.Error()})
+		return nil, nil, false
+	}
+	if len(snapshots) == 0 {
+		writeJSON(w, http.StatusNotFound, errorResponse{Error: "no reports in history"})
+		return nil, nil, false
+	}
+	states, err := s.store.States(r.Context())
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, errorResponse{Error: err.Error()})
+		return nil, nil, false
+	}
+	return snapshots, states, true
+}
+
+// pick writes a 404 when findSnapshot fails
+func pick(w http.ResponseWriter, snapshots []history.Snapshot, name string, def int) (history.Snapshot, bool) {
+	snap, ok := findSnapshot(snapshots, name, def)
+	if !ok {
+		writeJSON(w, http.StatusNotFound, errorResponse{Error: fmt.Sprintf("report %s not found", name)})
+	}
+	return snap, ok
+}
+
+// findSnapshot returns the snapshot with the given name, or the one at index
+// def when name is empty
+func findSnapshot(snapshots []history.Snapshot, name string, def int) (history.Snapshot, bool) {
+	if name == "" {
+		if def < 0 || def >= len(snapshots) {
+			return history.Snapshot{}, false
+		}
+		return snapshots[def], true
+	}
+	for _, snap := range snapshots {
+		if snap.Name == name {
+			return snap, true
+		}
+	}
+	return history.Snapshot{}, false
+}
+
+// toFindings converts a report's findings, with their triage status as of
+// the report's time
+func toFindings(findings []baseline.Finding, states map[string]history.State, at time.Time) []Finding {
+	out := make([]Finding, 0, len(findings))
+	for _, f := range findings {
+		out = append(out, Finding{
+			Type:        f.Type,
+			Severity:    f.EffectiveSeverity(),
+			Bucket:      f.Bucket,
+			Prefix:      f.Prefix,
+			Account:     f.Account,
+			Fingerprint: f.Fingerprint,
+			State:       findingState(states, f.Fingerprint, at),
+		})
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Bucket != out[j].Bucket {
+			return out[i].Bucket < out[j].Bucket
+		}
+		if out[i].Type != out[j].Type {
+			return out[i].Type < out[j].Type
+		}
+		return out[i].Prefix < out[j].Prefix
+	})
+	return out
+}
+
+func findingState(states map[string]history.State, fingerprint string, at time.Time) string {
+	state, ok := states[fingerprint]
+	if !ok {
+		return ""
+	}
+	return state.At(at)
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	_ = encoder.Encode(v)
+}
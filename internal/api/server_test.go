@@ -0,0 +1,212 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ppiankov/s3spectre/internal/analyzer"
+	"github.com/ppiankov/s3spectre/internal/history"
+	"github.com/ppiankov/s3spectre/internal/report"
+)
+
+func newTestServer(t *testing.T) *Server {
+	t.Helper()
+	dir := t.TempDir()
+	day := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	reports := map[string]report.DiscoveryData{
+		"day1": {Timestamp: day, Buckets: map[string]*analyzer.BucketDiscovery{
+			"logs":  {Status: analyzer.StatusInactive},
+			"media": {Status: analyzer.StatusRisky},
+		}},
+		"day2": {Timestamp: day.Add(24 * time.Hour), Buckets: map[string]*analyzer.BucketDiscovery{
+			"logs":  {Status: analyzer.StatusInactive},
+			"media": {Status: analyzer.StatusOK},
+			"tmp":   {Status: analyzer.StatusUnusedBucket},
+		}},
+	}
+	for name, data := range reports {
+		raw, _ := json.Marshal(data)
+		if err := os.WriteFile(filepath.Join(dir, name+".json"), raw, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	s, err := NewServer(history.NewDirStore(dir), "secret")
+	if err != nil {
+		t.Fatalf("NewServer failed: %v", err)
+	}
+	return s
+}
+
+func get(t *testing.T, s *Server, path string, out interface{}) int {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	req.Header.Set("Authorization", "Bearer secret")
+	rec := httptest.NewRecorder()
+	s.ServeHTTP(rec, req)
+	if out != nil && rec.Code == http.StatusOK {
+		if err := json.Unmarshal(rec.Body.Bytes(), out); err != nil {
+			t.Fatalf("invalid JSON from %s: %v", path, err)
+		}
+	}
+	return rec.Code
+}
+
+func TestNewServer_RequiresToken(t *testing.T) {
+	if _, err := NewServer(history.NewDirStore(t.TempDir()), ""); err == nil {
+		t.Fatal("expected error for empty token")
+	}
+}
+
+func TestServer_Unauthorized(t *testing.T) {
+	s := newTestServer(t)
+	for _, header := range []string{"", "Bearer wrong", "secret"} {
+		req := httptest.NewRequest(http.MethodGet, "/findings", nil)
+		if header != "" {
+			req.Header.Set("Authorization", header)
+		}
+		rec := httptest.NewRecorder()
+		s.ServeHTTP(rec, req)
+		if rec.Code != http.StatusUnauthorized {
+			t.Errorf("Authorization %q: expected 401, got %d", header, rec.Code)
+		}
+	}
+}
+
+func TestServer_Findings(t *testing.T) {
+	s := newTestServer(t)
+
+	var latest FindingsResponse
+	if code := get(t, s, "/findings", &latest); code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", code)
+	}
+	if latest.Report != "day2" || latest.Kind != "discover" || len(latest.Findings) != 2 {
+		t.Fatalf("unexpected latest findings: %+v", latest)
+	}
+	if latest.Findings[0].Bucket != "logs" || latest.Findings[0].Severity != "medium" {
+		t.Errorf("unexpected first finding: %+v", latest.Findings[0])
+	}
+
+	var named FindingsResponse
+	get(t, s, "/findings?report=day1", &named)
+	if named.Report != "day1" || len(named.Findings) != 2 {
+		t.Errorf("unexpected named findings: %+v", named)
+	}
+
+	if code := get(t, s, "/findings?report=missing", nil); code != http.StatusNotFound {
+		t.Errorf("expected 404 for unknown report, got %d", code)
+	}
+}
+
+func TestServer_Bucket(t *testing.T) {
+	s := newTestServer(t)
+
+	var media BucketResponse
+	if code := get(t, s, "/buckets/media", &media); code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", code)
+	}
+	if len(media.Current) != 0 || len(media.History) != 2 || len(media.History[1].Findings) != 1 {
+		t.Errorf("unexpected bucket response: %+v", media)
+	}
+
+	if code := get(t, s, "/buckets/unknown", nil); code != http.StatusNotFound {
+		t.Errorf("expected 404 for unknown bucket, got %d", code)
+	}
+}
+
+func TestServer_Diff(t *testing.T) {
+	s := newTestServer(t)
+
+	var diff DiffResponse
+	if code := get(t, s, "/diff", &diff); code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", code)
+	}
+	if diff.From != "day1" || diff.To != "day2" || diff.Unchanged != 1 {
+		t.Errorf("unexpected diff: %+v", diff)
+	}
+	if len(diff.New) != 1 || diff.New[0].Bucket != "tmp" {
+		t.Errorf("unexpected new findings: %+v", diff.New)
+	}
+	if len(diff.Resolved) != 1 || diff.Resolved[0].Bucket != "media" {
+		t.Errorf("unexpected resolved findings: %+v", diff.Resolved)
+	}
+
+	var reverse DiffResponse
+	get(t, s, "/diff?from=day2&to=day1", &reverse)
+	if len(reverse.New) != 1 || reverse.New[0].Bucket != "media" {
+		t.Errorf("unexpected reverse diff: %+v", reverse)
+	}
+}
+
+func TestServer_States(t *testing.T) {
+	s := newTestServer(t)
+
+	var latest FindingsResponse
+	get(t, s, "/findings", &latest)
+	fingerprint := latest.Findings[0].Fingerprint
+
+	put := func(path, body string) int {
+		req := httptest.NewRequest(http.MethodPut, path, strings.NewReader(body))
+		req.Header.Set("Authorization", "Bearer secret")
+		rec := httptest.NewRecorder()
+		s.ServeHTTP(rec, req)
+		return rec.Code
+	}
+	if code := put("/states/"+fingerprint, `{"status":"acknowledged","reason":"archived in Q3","actor":"ops"}`); code != http.StatusOK {
+		t.Fatalf("expected 200 recording a state, got %d", code)
+	}
+	if code := put("/states/"+fingerprint, `{"status":"ignored"}`); code != http.StatusBadRequest {
+		t.Errorf("expected 400 for an unknown status, got %d", code)
+	}
+	if code := put("/findings", `{}`); code != http.StatusMethodNotAllowed {
+		t.Errorf("expected 405 for PUT /findings, got %d", code)
+	}
+
+	var state history.State
+	if code := get(t, s, "/states/"+fingerprint, &state); code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", code)
+	}
+	if state.Status != history.StatusAcknowledged || state.Reason != "archived in Q3" || state.Actor != "ops" {
+		t.Errorf("unexpected state: %+v", state)
+	}
+	if code := get(t, s, "/states/unknown", nil); code != http.StatusNotFound {
+		t.Errorf("expected 404 for a finding without state, got %d", code)
+	}
+
+	var states StatesResponse
+	get(t, s, "/states", &states)
+	if len(states.States) != 1 {
+		t.Errorf("unexpected states: %+v", states)
+	}
+	get(t, s, "/findings", &latest)
+	if latest.Findings[0].State != history.StatusAcknowledged || latest.Findings[1].State != "" {
+		t.Errorf("findings should carry their state: %+v", latest.Findings)
+	}
+}
+
+func TestServer_ResolvedFindingReported(t *testing.T) {
+	s := newTestServer(t)
+	var latest FindingsResponse
+	get(t, s, "/findings", &latest)
+	logs := latest.Findings[0]
+
+	// Resolved between the two reports, yet still in the second one
+	resolvedAt := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	if err := s.store.PutState(context.Background(), history.State{Fingerprint: logs.Fingerprint, Status: history.StatusResolved, UpdatedAt: resolvedAt}); err != nil {
+		t.Fatal(err)
+	}
+	var day1 FindingsResponse
+	get(t, s, "/findings?report=day1", &day1)
+	get(t, s, "/findings", &latest)
+	if day1.Findings[0].State != history.StatusResolved || latest.Findings[0].State != history.StatusOpen {
+		t.Errorf("states = %q then %q, want resolved then open", day1.Findings[0].State, latest.Findings[0].State)
+	}
+}
@@ -0,0 +1,105 @@
+package badge
+
+import (
+	"encoding/json"
+	"fmt"
+	"html"
+	"io"
+
+	"github.com/ppiankov/s3spectre/internal/baseline"
+)
+
+// Badge is the label, message and color shown on a badge
+type Badge struct {
+	Label   string
+	Message string
+	Color   string
+}
+
+// levels lists severities from most to least severe with their badge color
+var levels = []struct {
+	severity string
+	color    string
+}{
+	{"high", "red"},
+	{"medium", "orange"},
+	{"low", "yellow"},
+	{"info", "lightgrey"},
+}
+
+// FromFindings summarizes findings by their most severe level, e.g. "3 high"
+func FromFindings(label string, findings []baseline.Finding) Badge {
+	counts := make(map[string]int)
+	for _, f := range findings {
+		counts[f.EffectiveSeverity()]++
+	}
+
+	for _, l := range levels {
+		if n := counts[l.severity]; n > 0 {
+			return Badge{Label: label, Message: fmt.Sprintf("%d %s", n, l.severity), Color: l.color}
+		}
+	}
+	return Badge{Label: label, Message: "clean", Color: "brightgreen"}
+}
+
+// WriteShields writes a shields.io endpoint document
+// (https://shields.io/badges/endpoint-badge)
+func WriteShields(w io.Writer, b Badge) error {
+	doc := struct {
+		SchemaVersion int    `json:"schemaVersion"`
+		Label         string `json:"label"`
+		Message       string `json:"message"`
+		Color         string `json:"color"`
+	}{1, b.Label, b.Message, b.Color}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(doc)
+}
+
+// colorHex maps named colors to the shields.io palette
+var colorHex = map[string]string{
+	"brightgreen": "#4c1",
+	"yellow":      "#dfb317",
+	"orange":      "#fe7d37",
+	"red":         "#e05d44",
+	"lightgrey":   "#9f9f9f",
+}
+
+// WriteSVG writes a flat-style SVG badge
+func WriteSVG(w io.Writer, b Badge) error {
+	labelWidth := textWidth(b.Label)
+	messageWidth := textWidth(b.Message)
+	total := labelWidth + messageWidth
+	fill, ok := colorHex[b.Color]
+	if !ok {
+		fill = "#9f9f9f"
+	}
+	label := html.EscapeString(b.Label)
+	message := html.EscapeString(b.Message)
+
+	_, err := fmt.Fprintf(w, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="20" role="img" aria-label="%s: %s">
+<title>%s: %s</title>
+<linearGradient id="s" x2="0" y2="100%%"><stop offset="0" stop-color="#bbb" stop-opacity=".1"/><stop offset="1" stop-opacity=".1"/></linearGradient>
+<clipPath id="r"><rect width="%d" height="20" rx="3" fill="#fff"/></clipPath>
+<g clip-path="url(#r)"><rect width="%d" height="20" fill="#555"/><rect x="%d" width="%d" height="20" fill="%s"/><rect width="%d" height="20" fill="url(#s)"/></g>
+<g fill="#fff" text-anchor="middle" font-family="Verdana,Geneva,DejaVu Sans,sans-serif" font-size="11">
+<text x="%d" y="15" fill="#010101" fill-opacity=".3">%s</text><text x="%d" y="14">%s</text>
+<text x="%d" y="15" fill="#010101" fill-opacity=".3">%s</text><text x="%d" y="14">%s</text>
+</g>
+</svg>
+`,
+		total, label, message,
+		label, message,
+		total,
+		labelWidth, labelWidth, messageWidth, fill, total,
+		labelWidth/2, label, labelWidth/2, label,
+		labelWidth+messageWidth/2, message, labelWidth+messageWidth/2, message,
+	)
+	return err
+}
+
+// textWidth approximates rendered width of 11px Verdana plus padding
+func textWidth(s string) int {
+	return len(s)*7 + 10
+}
@@ -0,0 +1,57 @@
+package badge
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/ppiankov/s3spectre/internal/baseline"
+)
+
+func TestFromFindings(t *testing.T) {
+	tests := []struct {
+		name     string
+		findings []baseline.Finding
+		message  string
+		color    string
+	}{
+		{"clean", nil, "clean", "brightgreen"},
+		{"low only", []baseline.Finding{{Type: "STALE_PREFIX"}, {Type: "UNUSED_BUCKET"}}, "2 low", "yellow"},
+		{"medium wins", []baseline.Finding{{Type: "STALE_PREFIX"}, {Type: "MISSING_BUCKET"}}, "1 medium", "orange"},
+	}
+	for _, tt := range tests {
+		b := FromFindings("s3 drift", tt.findings)
+		if b.Message != tt.message || b.Color != tt.color {
+			t.Errorf("%s: got %q/%q, want %q/%q", tt.name, b.Message, b.Color, tt.message, tt.color)
+		}
+	}
+}
+
+func TestWriteShields(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteShields(&buf, Badge{Label: "s3 drift", Message: "3 high", Color: "red"}); err != nil {
+		t.Fatalf("WriteShields failed: %v", err)
+	}
+	var doc map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &doc); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	if doc["schemaVersion"] != float64(1) || doc["message"] != "3 high" || doc["color"] != "red" {
+		t.Fatalf("unexpected document: %v", doc)
+	}
+}
+
+func TestWriteSVG(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteSVG(&buf, Badge{Label: "s3 <drift>", Message: "clean", Color: "brightgreen"}); err != nil {
+		t.Fatalf("WriteSVG failed: %v", err)
+	}
+	svg := buf.String()
+	if !strings.HasPrefix(svg, "<svg") || !strings.Contains(svg, "#4c1") {
+		t.Fatalf("unexpected SVG: %s", svg)
+	}
+	if strings.Contains(svg, "<drift>") || !strings.Contains(svg, "s3 &lt;drift&gt;") {
+		t.Fatalf("expected escaped label in SVG")
+	}
+}
@@ -4,9 +4,12 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"strings"
+	"time"
 
 	"github.com/ppiankov/s3spectre/internal/analyzer"
 	"github.com/ppiankov/s3spectre/internal/report"
+	"github.com/ppiankov/s3spectre/internal/rules"
 )
 
 // Finding is a flattened, identity-comparable issue from a scan or discovery.
@@ -14,13 +17,57 @@ type Finding struct {
 	Type   string `json:"type"`
 	Bucket string `json:"bucket"`
 	Prefix string `json:"prefix,omitempty"`
+	// Severity is set for plugin findings, whose rules have no built-in
+	// severity, and for correlations, whose severity varies
+	Severity string `json:"severity,omitempty"`
+	Account  string `json:"account,omitempty"`
+	// Fingerprint is the content-based identity from rules.Fingerprint
+	Fingerprint string `json:"fingerprint,omitempty"`
 }
 
-func (f Finding) key() string {
-	if f.Prefix != "" {
-		return fmt.Sprintf("%s|%s|%s", f.Type, f.Bucket, f.Prefix)
+// EffectiveSeverity returns the finding's severity after config overrides:
+// the plugin-reported one, or the built-in severity of its status or rule.
+func (f Finding) EffectiveSeverity() string {
+	if f.Severity != "" {
+		return rules.CustomSeverity(f.Type, f.Severity)
 	}
-	return fmt.Sprintf("%s|%s", f.Type, f.Bucket)
+	if severity := rules.StatusSeverity(f.Type); severity != "" {
+		return severity
+	}
+	// Rules without a status of their own, such as PUBLIC_BUCKET
+	if rule, ok := rules.Lookup(f.Type); ok {
+		return rule.Severity()
+	}
+	return ""
+}
+
+// key identifies a finding for diffing. Accounts are left out when either
+// side of a diff predates account recording.
+func (f Finding) key(withAccount bool) string {
+	account := ""
+	if withAccount {
+		account = f.Account
+	}
+	return rules.Fingerprint(f.Type, account, f.Bucket, f.Prefix)
+}
+
+func newFinding(findingType, account, bucket, prefix string) Finding {
+	return Finding{
+		Type:        findingType,
+		Bucket:      bucket,
+		Prefix:      prefix,
+		Account:     account,
+		Fingerprint: rules.Fingerprint(findingType, account, bucket, prefix),
+	}
+}
+
+func hasAccounts(findings []Finding) bool {
+	for _, f := range findings {
+		if f.Account != "" {
+			return true
+		}
+	}
+	return false
 }
 
 // DiffResult holds the outcome of comparing current findings against a baseline.
@@ -34,15 +81,29 @@ type DiffResult struct {
 func FlattenScanFindings(data report.Data) []Finding {
 	var findings []Finding
 	for name, ba := range data.Buckets {
+		account := report.AnalysisAccount(data, ba)
 		if ba.Status != analyzer.StatusOK {
-			findings = append(findings, Finding{Type: string(ba.Status), Bucket: name})
+			findings = append(findings, newFinding(string(ba.Status), account, name, ""))
 		}
 		for _, pa := range ba.Prefixes {
 			if pa.Status != analyzer.StatusOK {
-				findings = append(findings, Finding{Type: string(pa.Status), Bucket: name, Prefix: pa.Prefix})
+				findings = append(findings, newFinding(string(pa.Status), account, name, pa.Prefix))
 			}
 		}
 	}
+	for name, va := range data.Vaults {
+		if va.Status != analyzer.StatusOK {
+			findings = append(findings, newFinding(string(va.Status), data.Config.AccountID, name, ""))
+		}
+	}
+	for _, p := range data.Permissions {
+		f := newFinding(string(p.Status), data.Config.AccountID, p.Bucket, p.Prefix)
+		f.Fingerprint = rules.Fingerprint(f.Type, f.Account, p.Bucket, p.Key())
+		findings = append(findings, f)
+	}
+	for _, c := range data.Correlations {
+		findings = append(findings, correlationFinding(c, data.Config.AccountID))
+	}
 	return findings
 }
 
@@ -50,13 +111,44 @@ func FlattenScanFindings(data report.Data) []Finding {
 func FlattenDiscoveryFindings(data report.DiscoveryData) []Finding {
 	var findings []Finding
 	for name, bd := range data.Buckets {
+		account := report.BucketAccount(data, bd)
 		if bd.Status != analyzer.StatusOK {
-			findings = append(findings, Finding{Type: string(bd.Status), Bucket: name})
+			findings = append(findings, newFinding(string(bd.Status), account, name, ""))
+		}
+		if info := bd.BucketInfo; info != nil {
+			if data.Config.CheckPublicAccess && info.PublicAccess != nil && info.PublicAccess.IsPublic {
+				findings = append(findings, newFinding(rules.PublicBucket, account, name, ""))
+			}
+			if data.Config.CheckEncryption && info.Encryption != nil && !info.Encryption.Enabled {
+				findings = append(findings, newFinding(rules.NoEncryption, account, name, ""))
+			}
+		}
+		for _, f := range bd.PolicyFindings {
+			findings = append(findings, newFinding(string(f.Status), account, name, ""))
 		}
+		for _, cf := range bd.CustomFindings {
+			f := newFinding(cf.Rule, account, name, "")
+			f.Severity = cf.Severity
+			findings = append(findings, f)
+		}
+	}
+	for _, af := range data.AccountFindings {
+		findings = append(findings, newFinding(string(af.Status), report.FindingAccount(data, af), af.Region, af.Family))
+	}
+	for _, c := range data.Correlations {
+		findings = append(findings, correlationFinding(c, report.CorrelationAccount(data, c)))
 	}
 	return findings
 }
 
+// correlationFinding flattens a correlation, keyed by the other tool's
+// finding and carrying its own severity
+func correlationFinding(c analyzer.Correlation, account string) Finding {
+	f := newFinding(string(c.Status), account, c.Bucket, c.Key())
+	f.Severity = c.Severity
+	return f
+}
+
 // LoadScanBaseline reads a previous scan JSON report and extracts findings.
 func LoadScanBaseline(path string) ([]Finding, error) {
 	raw, err := os.ReadFile(path)
@@ -83,29 +175,124 @@ func LoadDiscoveryBaseline(path string) ([]Finding, error) {
 	return FlattenDiscoveryFindings(data), nil
 }
 
+// Report is a scan or discovery JSON report reduced to its findings.
+type Report struct {
+	Kind      string // "scan", "discover" or "merged"
+	Timestamp time.Time
+	Findings  []Finding
+	// Account and Region describe what the report covered, when recorded
+	Account string
+	Region  string
+	// provenance holds each finding's sources when the report is merged
+	provenance [][]Provenance
+}
+
+// ReadReport reads a scan or discovery JSON report, detecting which kind it is.
+func ReadReport(path string) (*Report, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read report: %w", err)
+	}
+	var probe struct {
+		Kind   string `json:"kind"`
+		Config struct {
+			RepoPath *string `json:"repo_path"`
+		} `json:"config"`
+	}
+	if err := json.Unmarshal(raw, &probe); err != nil {
+		return nil, fmt.Errorf("parse report: %w", err)
+	}
+	if probe.Kind == MergedKind {
+		return readMerged(raw)
+	}
+
+	// Only scan reports record the repository path
+	if probe.Config.RepoPath != nil {
+		var data report.Data
+		if err := json.Unmarshal(raw, &data); err != nil {
+			return nil, fmt.Errorf("parse report: %w", err)
+		}
+		return &Report{
+			Kind:      "scan",
+			Timestamp: data.Timestamp,
+			Findings:  FlattenScanFindings(data),
+			Account:   data.Config.AccountID,
+			Region:    data.Config.AWSRegion,
+		}, nil
+	}
+	var data report.DiscoveryData
+	if err := json.Unmarshal(raw, &data); err != nil {
+		return nil, fmt.Errorf("parse report: %w", err)
+	}
+	return &Report{
+		Kind:      "discover",
+		Timestamp: data.Timestamp,
+		Findings:  FlattenDiscoveryFindings(data),
+		Account:   data.Config.AccountID,
+		Region:    strings.Join(data.Config.Regions, ","),
+	}, nil
+}
+
+// LoadReportFindings reads a scan or discovery JSON report and extracts findings.
+func LoadReportFindings(path string) ([]Finding, error) {
+	r, err := ReadReport(path)
+	if err != nil {
+		return nil, err
+	}
+	return r.Findings, nil
+}
+
 // Diff compares current findings against a baseline.
 func Diff(current, baseline []Finding) DiffResult {
+	withAccount := hasAccounts(current) && hasAccounts(baseline)
 	baseMap := make(map[string]struct{}, len(baseline))
 	for _, f := range baseline {
-		baseMap[f.key()] = struct{}{}
+		baseMap[f.key(withAccount)] = struct{}{}
 	}
 	curMap := make(map[string]struct{}, len(current))
 	for _, f := range current {
-		curMap[f.key()] = struct{}{}
+		curMap[f.key(withAccount)] = struct{}{}
 	}
 
 	var result DiffResult
 	for _, f := range current {
-		if _, exists := baseMap[f.key()]; exists {
+		if _, exists := baseMap[f.key(withAccount)]; exists {
 			result.Unchanged = append(result.Unchanged, f)
 		} else {
 			result.New = append(result.New, f)
 		}
 	}
 	for _, f := range baseline {
-		if _, exists := curMap[f.key()]; !exists {
+		if _, exists := curMap[f.key(withAccount)]; !exists {
 			result.Resolved = append(result.Resolved, f)
 		}
 	}
 	return result
 }
+
+// Changes converts the diff into the report's baseline section, sorted by
+// severity
+func (d DiffResult) Changes(baselinePath string) *report.BaselineChanges {
+	changes := &report.BaselineChanges{
+		Baseline:  baselinePath,
+		New:       toChanges(d.New),
+		Resolved:  toChanges(d.Resolved),
+		Unchanged: len(d.Unchanged),
+	}
+	changes.Sort()
+	return changes
+}
+
+func toChanges(findings []Finding) []report.BaselineChange {
+	var changes []report.BaselineChange
+	for _, f := range findings {
+		changes = append(changes, report.BaselineChange{
+			Type:     f.Type,
+			Bucket:   f.Bucket,
+			Prefix:   f.Prefix,
+			Account:  f.Account,
+			Severity: f.EffectiveSeverity(),
+		})
+	}
+	return changes
+}
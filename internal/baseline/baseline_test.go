@@ -8,6 +8,7 @@ import (
 
 	"github.com/ppiankov/s3spectre/internal/analyzer"
 	"github.com/ppiankov/s3spectre/internal/report"
+	"github.com/ppiankov/s3spectre/internal/s3"
 )
 
 func TestFlattenScanFindings(t *testing.T) {
@@ -43,6 +44,73 @@ func TestFlattenScanFindings(t *testing.T) {
 	}
 }
 
+func TestFlattenScanFindings_Vaults(t *testing.T) {
+	data := report.Data{
+		Config: report.Config{AccountID: "123456789012"},
+		Vaults: map[string]*analyzer.VaultAnalysis{
+			"legacy":  {Name: "legacy", Status: analyzer.StatusOrphanedVault},
+			"backups": {Name: "backups", Status: analyzer.StatusOK},
+		},
+	}
+
+	findings := FlattenScanFindings(data)
+	if len(findings) != 1 || findings[0].Type != "ORPHANED_VAULT" || findings[0].Bucket != "legacy" {
+		t.Fatalf("expected one ORPHANED_VAULT finding, got %+v", findings)
+	}
+	if findings[0].EffectiveSeverity() != "low" {
+		t.Fatalf("expected low severity, got %s", findings[0].EffectiveSeverity())
+	}
+}
+
+func TestFlattenScanFindings_Accounts(t *testing.T) {
+	data := report.Data{
+		Config: report.Config{AccountID: "111111111111"},
+		Buckets: map[string]*analyzer.BucketAnalysis{
+			"member": {Status: analyzer.StatusVersionSprawl, Account: "222222222222",
+				Prefixes: []analyzer.PrefixAnalysis{{Prefix: "logs/", Status: analyzer.StatusStalePrefix}}},
+			"missing": {Status: analyzer.StatusMissingBucket},
+		},
+	}
+
+	for _, f := range FlattenScanFindings(data) {
+		want := "222222222222"
+		if f.Bucket == "missing" {
+			want = "111111111111"
+		}
+		if f.Account != want {
+			t.Errorf("%s %s: expected account %s, got %q", f.Type, f.Bucket, want, f.Account)
+		}
+	}
+}
+
+func TestFlattenScanFindings_Permissions(t *testing.T) {
+	data := report.Data{
+		Permissions: []*analyzer.PermissionAnalysis{
+			{Bucket: "data", Prefix: "out/", Status: analyzer.StatusPermissionGap},
+		},
+	}
+
+	findings := FlattenScanFindings(data)
+	if len(findings) != 1 || findings[0].Type != "PERMISSION_GAP" || findings[0].Prefix != "out/" {
+		t.Fatalf("expected one PERMISSION_GAP finding, got %+v", findings)
+	}
+	if findings[0].EffectiveSeverity() != "high" {
+		t.Fatalf("expected high severity, got %s", findings[0].EffectiveSeverity())
+	}
+}
+
+func TestFlattenDiscoveryFindings_AccountFindings(t *testing.T) {
+	data := report.DiscoveryData{
+		Config:          report.DiscoveryConfig{AccountID: "123456789012"},
+		AccountFindings: []analyzer.AccountAnalysis{{Status: analyzer.StatusNoGuardDutyS3, Region: "us-west-2"}},
+	}
+
+	findings := FlattenDiscoveryFindings(data)
+	if len(findings) != 1 || findings[0].Type != "NO_GUARDDUTY_S3" || findings[0].Bucket != "us-west-2" {
+		t.Fatalf("expected one NO_GUARDDUTY_S3 finding keyed by region, got %+v", findings)
+	}
+}
+
 func TestFlattenDiscoveryFindings(t *testing.T) {
 	data := report.DiscoveryData{
 		Buckets: map[string]*analyzer.BucketDiscovery{
@@ -58,6 +126,27 @@ func TestFlattenDiscoveryFindings(t *testing.T) {
 	}
 }
 
+func TestFlattenDiscoveryFindings_RuleFindings(t *testing.T) {
+	data := report.DiscoveryData{
+		Config: report.DiscoveryConfig{CheckPublicAccess: true},
+		Buckets: map[string]*analyzer.BucketDiscovery{
+			"exports": {Status: analyzer.StatusOK, BucketInfo: &s3.BucketInfo{
+				Name:         "exports",
+				PublicAccess: &s3.PublicAccessInfo{IsPublic: true},
+				Encryption:   &s3.EncryptionInfo{Enabled: false},
+			}},
+		},
+	}
+
+	findings := FlattenDiscoveryFindings(data)
+	if len(findings) != 1 || findings[0].Type != "PUBLIC_BUCKET" {
+		t.Fatalf("expected only PUBLIC_BUCKET (encryption was not checked), got %+v", findings)
+	}
+	if sev := findings[0].EffectiveSeverity(); sev != "high" {
+		t.Errorf("EffectiveSeverity() = %q, want high", sev)
+	}
+}
+
 func TestDiff_AllStatuses(t *testing.T) {
 	baseline := []Finding{
 		{Type: "MISSING_BUCKET", Bucket: "old-missing"},
@@ -83,6 +172,30 @@ func TestDiff_AllStatuses(t *testing.T) {
 	}
 }
 
+func TestDiffResult_Changes(t *testing.T) {
+	baseline := []Finding{
+		{Type: "VERSION_SPRAWL", Bucket: "archive"},
+		{Type: "MISSING_BUCKET", Bucket: "kept"},
+	}
+	current := []Finding{
+		{Type: "STALE_PREFIX", Bucket: "logs", Prefix: "old/"},
+		{Type: "MISSING_BUCKET", Bucket: "new-missing"},
+		{Type: "MISSING_BUCKET", Bucket: "kept"},
+	}
+
+	changes := Diff(current, baseline).Changes("base.json")
+
+	if changes.Baseline != "base.json" || changes.Unchanged != 1 {
+		t.Errorf("unexpected header: %+v", changes)
+	}
+	if len(changes.New) != 2 || changes.New[0].Bucket != "new-missing" || changes.New[0].Severity != "medium" {
+		t.Errorf("new changes should sort most severe first: %+v", changes.New)
+	}
+	if len(changes.Resolved) != 1 || changes.Resolved[0].Type != "VERSION_SPRAWL" || changes.Resolved[0].Severity == "" {
+		t.Errorf("unexpected resolved changes: %+v", changes.Resolved)
+	}
+}
+
 func TestDiff_EmptyBaseline(t *testing.T) {
 	current := []Finding{{Type: "MISSING_BUCKET", Bucket: "a"}}
 	result := Diff(current, nil)
@@ -147,3 +260,90 @@ func TestLoadScanBaseline_InvalidJSON(t *testing.T) {
 		t.Fatal("expected error for invalid JSON")
 	}
 }
+
+func TestLoadReportFindings_DetectsKind(t *testing.T) {
+	dir := t.TempDir()
+
+	scan := report.Data{
+		Config: report.Config{RepoPath: "."},
+		Buckets: map[string]*analyzer.BucketAnalysis{
+			"gone": {Status: analyzer.StatusMissingBucket},
+		},
+	}
+	discovery := report.DiscoveryData{
+		Buckets: map[string]*analyzer.BucketDiscovery{
+			"idle":  {Status: analyzer.StatusInactive},
+			"fine":  {Status: analyzer.StatusOK},
+			"empty": {Status: analyzer.StatusUnusedBucket},
+		},
+	}
+
+	for name, data := range map[string]interface{}{"scan.json": scan, "discover.json": discovery} {
+		raw, _ := json.Marshal(data)
+		if err := os.WriteFile(filepath.Join(dir, name), raw, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	findings, err := LoadReportFindings(filepath.Join(dir, "scan.json"))
+	if err != nil {
+		t.Fatalf("load scan report: %v", err)
+	}
+	if len(findings) != 1 || findings[0].Type != "MISSING_BUCKET" {
+		t.Fatalf("unexpected scan findings: %v", findings)
+	}
+
+	findings, err = LoadReportFindings(filepath.Join(dir, "discover.json"))
+	if err != nil {
+		t.Fatalf("load discovery report: %v", err)
+	}
+	if len(findings) != 2 {
+		t.Fatalf("expected 2 discovery findings, got %v", findings)
+	}
+}
+
+func TestDiff_FingerprintsIgnoreOrderAndMessages(t *testing.T) {
+	old := report.Data{
+		Config: report.Config{AccountID: "123456789012"},
+		Buckets: map[string]*analyzer.BucketAnalysis{
+			"a": {Status: analyzer.StatusLifecycleMisconfig, Message: "old wording"},
+			"b": {Status: analyzer.StatusOK, Prefixes: []analyzer.PrefixAnalysis{
+				{Prefix: "logs", Status: analyzer.StatusStalePrefix},
+			}},
+		},
+	}
+	cur := report.Data{
+		Config: report.Config{AccountID: "123456789012"},
+		Buckets: map[string]*analyzer.BucketAnalysis{
+			"b": {Status: analyzer.StatusOK, Prefixes: []analyzer.PrefixAnalysis{
+				{Prefix: "/logs/", Status: analyzer.StatusStalePrefix, Message: "new wording"},
+			}},
+			"a": {Status: analyzer.StatusLifecycleMisconfig, Message: "new wording"},
+		},
+	}
+
+	diff := Diff(FlattenScanFindings(cur), FlattenScanFindings(old))
+	if len(diff.New) != 0 || len(diff.Resolved) != 0 || len(diff.Unchanged) != 2 {
+		t.Fatalf("expected 2 unchanged, got new=%v resolved=%v", diff.New, diff.Resolved)
+	}
+	for _, f := range diff.Unchanged {
+		if f.Fingerprint == "" || f.Account != "123456789012" {
+			t.Fatalf("expected fingerprint and account on %+v", f)
+		}
+	}
+}
+
+func TestDiff_AccountChangeAndLegacyBaseline(t *testing.T) {
+	current := []Finding{newFinding("MISSING_BUCKET", "111111111111", "a", "")}
+
+	otherAccount := []Finding{newFinding("MISSING_BUCKET", "222222222222", "a", "")}
+	if diff := Diff(current, otherAccount); len(diff.New) != 1 || len(diff.Resolved) != 1 {
+		t.Fatalf("expected a different account to be a different finding, got %+v", diff)
+	}
+
+	// Baselines written before accounts were recorded still match
+	legacy := []Finding{{Type: "MISSING_BUCKET", Bucket: "a"}}
+	if diff := Diff(current, legacy); len(diff.Unchanged) != 1 {
+		t.Fatalf("expected legacy baseline to match, got %+v", diff)
+	}
+}
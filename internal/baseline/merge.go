@@ -0,0 +1,154 @@
+package baseline
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// MergedKind marks a report produced by merging other reports
+const MergedKind = "merged"
+
+// Source is one report that went into a merge
+type Source struct {
+	File      string    `json:"file"`
+	Kind      string    `json:"kind"`
+	Account   string    `json:"account,omitempty"`
+	Region    string    `json:"region,omitempty"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// Provenance records which source reported a merged finding
+type Provenance struct {
+	Source    string    `json:"source"`
+	Region    string    `json:"region,omitempty"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// MergedFinding is a finding from one or more sources. Findings with the
+// same fingerprint are reported once, with every source that saw them.
+type MergedFinding struct {
+	Finding
+	Severity   string       `json:"severity"`
+	Provenance []Provenance `json:"provenance"`
+}
+
+// MergedSummary counts merged findings
+type MergedSummary struct {
+	Sources    int            `json:"sources"`
+	Accounts   int            `json:"accounts"`
+	Findings   int            `json:"findings"`
+	ByType     map[string]int `json:"by_type,omitempty"`
+	BySeverity map[string]int `json:"by_severity,omitempty"`
+}
+
+// MergedReport consolidates scan and discovery reports from several
+// accounts, regions or runs
+type MergedReport struct {
+	Tool      string          `json:"tool"`
+	Version   string          `json:"version"`
+	Kind      string          `json:"kind"`
+	Timestamp time.Time       `json:"timestamp"`
+	Summary   MergedSummary   `json:"summary"`
+	Sources   []Source        `json:"sources"`
+	Findings  []MergedFinding `json:"findings"`
+}
+
+// Merge reads scan, discovery or merged JSON reports and combines their
+// findings. The merged timestamp is the newest source's.
+func Merge(paths []string) (*MergedReport, error) {
+	merged := &MergedReport{Tool: "s3spectre", Kind: MergedKind}
+	byFingerprint := make(map[string]*MergedFinding)
+	accounts := make(map[string]bool)
+
+	for _, path := range paths {
+		r, err := ReadReport(path)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		// The path as given, so reports named alike in different
+		// directories stay apart
+		merged.Sources = append(merged.Sources, Source{
+			File:      path,
+			Kind:      r.Kind,
+			Account:   r.Account,
+			Region:    r.Region,
+			Timestamp: r.Timestamp,
+		})
+		if r.Timestamp.After(merged.Timestamp) {
+			merged.Timestamp = r.Timestamp
+		}
+
+		for i, f := range r.Findings {
+			provenance := []Provenance{{Source: path, Region: r.Region, Timestamp: r.Timestamp}}
+			if r.provenance != nil {
+				provenance = r.provenance[i]
+			}
+			if f.Account != "" {
+				accounts[f.Account] = true
+			}
+			fingerprint := f.key(true)
+			if existing, ok := byFingerprint[fingerprint]; ok {
+				existing.Provenance = append(existing.Provenance, provenance...)
+				continue
+			}
+			byFingerprint[fingerprint] = &MergedFinding{
+				Finding:    f,
+				Severity:   f.EffectiveSeverity(),
+				Provenance: provenance,
+			}
+		}
+	}
+
+	merged.Summary = MergedSummary{
+		Sources:    len(merged.Sources),
+		Accounts:   len(accounts),
+		ByType:     make(map[string]int),
+		BySeverity: make(map[string]int),
+	}
+	merged.Findings = make([]MergedFinding, 0, len(byFingerprint))
+	for _, f := range byFingerprint {
+		merged.Findings = append(merged.Findings, *f)
+		merged.Summary.ByType[f.Type]++
+		merged.Summary.BySeverity[f.Severity]++
+	}
+	merged.Summary.Findings = len(merged.Findings)
+	sort.Slice(merged.Findings, func(i, j int) bool {
+		a, b := merged.Findings[i], merged.Findings[j]
+		if a.Account != b.Account {
+			return a.Account < b.Account
+		}
+		if a.Bucket != b.Bucket {
+			return a.Bucket < b.Bucket
+		}
+		if a.Prefix != b.Prefix {
+			return a.Prefix < b.Prefix
+		}
+		return a.Type < b.Type
+	})
+	return merged, nil
+}
+
+// readMerged reads a merged report back, keeping each finding's provenance
+// so merges can be merged again
+func readMerged(raw []byte) (*Report, error) {
+	var data MergedReport
+	if err := json.Unmarshal(raw, &data); err != nil {
+		return nil, fmt.Errorf("parse report: %w", err)
+	}
+	r := &Report{Kind: MergedKind, Timestamp: data.Timestamp}
+	var regions []string
+	for _, s := range data.Sources {
+		if s.Region != "" {
+			regions = append(regions, s.Region)
+		}
+	}
+	r.Region = strings.Join(regions, ",")
+	for _, f := range data.Findings {
+		r.Findings = append(r.Findings, f.Finding)
+		r.provenance = append(r.provenance, f.Provenance)
+	}
+	return r, nil
+}
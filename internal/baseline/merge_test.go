@@ -0,0 +1,142 @@
+package baseline
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ppiankov/s3spectre/internal/analyzer"
+	"github.com/ppiankov/s3spectre/internal/report"
+)
+
+func writeJSON(t *testing.T, path string, v any) {
+	t.Helper()
+	raw, err := json.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, raw, 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestMerge(t *testing.T) {
+	dir := t.TempDir()
+	early := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	late := early.Add(time.Hour)
+
+	writeJSON(t, filepath.Join(dir, "prod-us.json"), report.DiscoveryData{
+		Timestamp: early,
+		Config:    report.DiscoveryConfig{AccountID: "111111111111", Regions: []string{"us-east-1"}},
+		Buckets: map[string]*analyzer.BucketDiscovery{
+			"idle": {Status: analyzer.StatusInactive},
+			"fine": {Status: analyzer.StatusOK},
+		},
+	})
+	writeJSON(t, filepath.Join(dir, "prod-us-rerun.json"), report.DiscoveryData{
+		Timestamp: late,
+		Config:    report.DiscoveryConfig{AccountID: "111111111111", Regions: []string{"us-east-1"}},
+		Buckets: map[string]*analyzer.BucketDiscovery{
+			"idle": {Status: analyzer.StatusInactive},
+		},
+	})
+	writeJSON(t, filepath.Join(dir, "dev.json"), report.Data{
+		Timestamp: early,
+		Config:    report.Config{RepoPath: ".", AccountID: "222222222222", AWSRegion: "eu-west-1"},
+		Buckets: map[string]*analyzer.BucketAnalysis{
+			"idle": {Status: analyzer.StatusMissingBucket},
+		},
+	})
+
+	merged, err := Merge([]string{
+		filepath.Join(dir, "prod-us.json"),
+		filepath.Join(dir, "prod-us-rerun.json"),
+		filepath.Join(dir, "dev.json"),
+	})
+	if err != nil {
+		t.Fatalf("Merge failed: %v", err)
+	}
+	if merged.Kind != MergedKind || !merged.Timestamp.Equal(late) {
+		t.Fatalf("unexpected merged header: kind %q, timestamp %s", merged.Kind, merged.Timestamp)
+	}
+	if merged.Summary.Sources != 3 || merged.Summary.Accounts != 2 || merged.Summary.Findings != 2 {
+		t.Fatalf("unexpected summary: %+v", merged.Summary)
+	}
+
+	first, second := merged.Findings[0], merged.Findings[1]
+	if first.Account != "111111111111" || first.Type != "INACTIVE" || len(first.Provenance) != 2 {
+		t.Errorf("expected the duplicated finding once with two sources, got %+v", first)
+	}
+	if first.Provenance[0].Source != filepath.Join(dir, "prod-us.json") || first.Provenance[1].Source != filepath.Join(dir, "prod-us-rerun.json") {
+		t.Errorf("unexpected provenance: %+v", first.Provenance)
+	}
+	if second.Account != "222222222222" || second.Type != "MISSING_BUCKET" || second.Provenance[0].Region != "eu-west-1" {
+		t.Errorf("unexpected second finding: %+v", second)
+	}
+}
+
+func TestMerge_MergedReportsRoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	writeJSON(t, filepath.Join(dir, "a.json"), report.DiscoveryData{
+		Config:  report.DiscoveryConfig{AccountID: "111111111111"},
+		Buckets: map[string]*analyzer.BucketDiscovery{"idle": {Status: analyzer.StatusInactive}},
+	})
+	writeJSON(t, filepath.Join(dir, "b.json"), report.DiscoveryData{
+		Config:  report.DiscoveryConfig{AccountID: "222222222222"},
+		Buckets: map[string]*analyzer.BucketDiscovery{"idle": {Status: analyzer.StatusInactive}},
+	})
+	first, err := Merge([]string{filepath.Join(dir, "a.json"), filepath.Join(dir, "b.json")})
+	if err != nil {
+		t.Fatalf("Merge failed: %v", err)
+	}
+	writeJSON(t, filepath.Join(dir, "merged.json"), first)
+
+	findings, err := LoadReportFindings(filepath.Join(dir, "merged.json"))
+	if err != nil {
+		t.Fatalf("load merged report: %v", err)
+	}
+	if len(findings) != 2 {
+		t.Fatalf("expected merged findings to load back, got %v", findings)
+	}
+
+	again, err := Merge([]string{filepath.Join(dir, "merged.json"), filepath.Join(dir, "a.json")})
+	if err != nil {
+		t.Fatalf("Merge of merged report failed: %v", err)
+	}
+	if again.Summary.Findings != 2 || len(again.Findings[0].Provenance) != 2 {
+		t.Fatalf("expected provenance to carry over, got %+v", again.Findings)
+	}
+	if again.Findings[0].Provenance[0].Source != filepath.Join(dir, "a.json") {
+		t.Errorf("expected original source kept, got %+v", again.Findings[0].Provenance)
+	}
+}
+
+func TestMerge_SameFileNames(t *testing.T) {
+	dir := t.TempDir()
+	var paths []string
+	for _, account := range []string{"111111111111", "222222222222"} {
+		if err := os.Mkdir(filepath.Join(dir, account), 0755); err != nil {
+			t.Fatal(err)
+		}
+		path := filepath.Join(dir, account, "report.json")
+		writeJSON(t, path, report.DiscoveryData{
+			Config:  report.DiscoveryConfig{AccountID: account},
+			Buckets: map[string]*analyzer.BucketDiscovery{"idle": {Status: analyzer.StatusInactive}},
+		})
+		paths = append(paths, path)
+	}
+	merged, err := Merge(paths)
+	if err != nil {
+		t.Fatalf("Merge failed: %v", err)
+	}
+	if merged.Sources[0].File == merged.Sources[1].File {
+		t.Fatalf("expected distinct sources, got %+v", merged.Sources)
+	}
+	for i, f := range merged.Findings {
+		if f.Provenance[0].Source != paths[i] {
+			t.Errorf("finding %d: provenance %+v, want %s", i, f.Provenance, paths[i])
+		}
+	}
+}
@@ -0,0 +1,224 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/ppiankov/s3spectre/internal/analyzer"
+	"github.com/ppiankov/s3spectre/internal/s3"
+	"github.com/ppiankov/s3spectre/internal/scanner"
+)
+
+// accountClient is an S3 client for one inspected account
+type accountClient struct {
+	id     string
+	client *s3.Client
+}
+
+// validateAccounts rejects inconsistent multi-account flags before any AWS calls
+func (f awsClientFlags) validateAccounts() error {
+	if f.multiAccount() && f.assumeRole.MFASerial != "" {
+		// An MFA code is accepted once, so it cannot also be presented for
+		// each account's role
+		return fmt.Errorf("--mfa-serial cannot be combined with --assume-role-arn or --org: one MFA code cannot be reused for every account's role")
+	}
+	if f.org {
+		if len(f.accounts) > 0 {
+			return fmt.Errorf("--org and --accounts are mutually exclusive")
+		}
+		if f.accountRole == "" {
+			return nil
+		}
+		if err := s3.ValidateAccountRole(f.accountRole); err != nil {
+			return fmt.Errorf("--assume-role-arn: %w", err)
+		}
+		return nil
+	}
+	if f.accountRole == "" {
+		if len(f.accounts) > 0 {
+			return fmt.Errorf("--accounts requires --assume-role-arn")
+		}
+		return nil
+	}
+	if err := s3.ValidateAccountRole(f.accountRole); err != nil {
+		return fmt.Errorf("--assume-role-arn: %w", err)
+	}
+	if len(f.accounts) == 0 && s3.RoleAccount(f.accountRole) == "" {
+		return fmt.Errorf("--assume-role-arn with a role name requires --accounts")
+	}
+	for _, account := range f.accounts {
+		if err := s3.ValidateAccountID(account); err != nil {
+			return fmt.Errorf("--accounts: %w", err)
+		}
+	}
+	return nil
+}
+
+// targetAccounts lists the accounts to inspect with --assume-role-arn: the
+// --accounts list, or the role ARN's own account. Duplicates are dropped.
+func (f awsClientFlags) targetAccounts() []string {
+	if f.accountRole == "" {
+		return nil
+	}
+	if len(f.accounts) == 0 {
+		return []string{s3.RoleAccount(f.accountRole)}
+	}
+	seen := make(map[string]bool, len(f.accounts))
+	var accounts []string
+	for _, account := range f.accounts {
+		if !seen[account] {
+			seen[account] = true
+			accounts = append(accounts, account)
+		}
+	}
+	return accounts
+}
+
+// multiAccount reports whether the run assumes a role in other accounts
+func (f awsClientFlags) multiAccount() bool {
+	return f.accountRole != "" || f.org
+}
+
+// auditRole is the role assumed in each account: --assume-role-arn, or the
+// role Organizations creates in member accounts when --org is set alone
+func (f awsClientFlags) auditRole() string {
+	if f.accountRole == "" && f.org {
+		return s3.DefaultOrgAuditRole
+	}
+	return f.accountRole
+}
+
+// accountClients assumes the audit role in each target account with the
+// base client's credentials. Without --assume-role-arn or --org the run
+// covers the caller's account only.
+func (f awsClientFlags) accountClients(ctx context.Context, base *s3.Client, callerAccount string) ([]accountClient, error) {
+	if !f.multiAccount() {
+		return []accountClient{{id: callerAccount, client: base}}, nil
+	}
+	if f.org {
+		return f.orgAccountClients(ctx, base, callerAccount)
+	}
+	accounts := f.targetAccounts()
+	clients := make([]accountClient, 0, len(accounts))
+	for _, account := range accounts {
+		client, err := f.assumeAccountRole(ctx, base, account)
+		if err != nil {
+			return nil, fmt.Errorf("account %s: %w", account, err)
+		}
+		clients = append(clients, accountClient{id: account, client: client})
+	}
+	return clients, nil
+}
+
+// orgAccountClients assumes the audit role in every active account of the
+// caller's organization. The caller's own account uses the base client, and
+// accounts where the role cannot be assumed are skipped with a warning so
+// one unprepared account does not stop an organization-wide run.
+func (f awsClientFlags) orgAccountClients(ctx context.Context, base *s3.Client, callerAccount string) ([]accountClient, error) {
+	members, err := base.OrganizationAccounts(ctx)
+	if err != nil {
+		return nil, err
+	}
+	printStatus("Found %d active accounts in the organization", len(members))
+	clients := make([]accountClient, 0, len(members))
+	for _, member := range members {
+		if member.ID == callerAccount {
+			clients = append(clients, accountClient{id: member.ID, client: base})
+			continue
+		}
+		client, err := f.assumeAccountRole(ctx, base, member.ID)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			slog.Warn("Skipping organization account",
+				slog.String("account", member.ID), slog.String("name", member.Name), slog.String("error", err.Error()))
+			continue
+		}
+		clients = append(clients, accountClient{id: member.ID, client: client})
+	}
+	if len(clients) == 0 {
+		return nil, fmt.Errorf("could not assume %s in any organization account", f.auditRole())
+	}
+	return clients, nil
+}
+
+// assumeAccountRole returns a client for account using the audit role,
+// with the same external ID as --role-arn
+func (f awsClientFlags) assumeAccountRole(ctx context.Context, base *s3.Client, account string) (*s3.Client, error) {
+	arn, err := s3.AccountRoleARN(f.auditRole(), account)
+	if err != nil {
+		return nil, err
+	}
+	client, err := base.AssumeRole(ctx, s3.AssumeRole{
+		RoleARN:     arn,
+		ExternalID:  f.assumeRole.ExternalID,
+		SessionName: f.assumeRole.SessionName,
+		Duration:    f.assumeRole.Duration,
+	})
+	if err != nil {
+		return nil, err
+	}
+	slog.Info("Assumed role", slog.String("account", account), slog.String("role", arn))
+	return client, nil
+}
+
+// inspectAccounts inspects each referenced bucket with the client of the
+// account that owns it. Buckets no account owns are inspected with the
+// first account, which reports them missing.
+func inspectAccounts(ctx context.Context, accounts []accountClient, refs []scanner.Reference, newInspector func(*s3.Client) *s3.Inspector, envs s3.Environments) (map[string]*s3.BucketInfo, error) {
+	inspectors := make([]*s3.Inspector, len(accounts))
+	owner := make(map[string]int)
+	var existing []string
+	for i, account := range accounts {
+		inspectors[i] = newInspector(account.client)
+		owned, err := inspectors[i].OwnedBuckets(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("account %s: %w", account.id, err)
+		}
+		for bucket := range owned {
+			if _, ok := owner[bucket]; !ok {
+				owner[bucket] = i
+				existing = append(existing, bucket)
+			}
+		}
+	}
+
+	refsByAccount := make([][]scanner.Reference, len(accounts))
+	for _, ref := range refs {
+		i := owner[ref.Bucket]
+		refsByAccount[i] = append(refsByAccount[i], ref)
+	}
+
+	byAccount := make(map[string]map[string]*s3.BucketInfo)
+	for i, account := range accounts {
+		if len(refsByAccount[i]) == 0 {
+			continue
+		}
+		buckets, err := inspectors[i].InspectBuckets(ctx, refsByAccount[i])
+		if buckets != nil {
+			byAccount[account.id] = buckets
+		}
+		if err != nil {
+			return s3.MergeAccountBuckets(byAccount), fmt.Errorf("account %s: %w", account.id, err)
+		}
+	}
+	merged := s3.MergeAccountBuckets(byAccount)
+	// Missing buckets were inspected with the first account; suggest names
+	// from every account instead
+	for _, info := range merged {
+		if !info.Exists && !info.TimedOut && !info.Interrupted {
+			info.NearMisses = s3.ClosestBuckets(info.Name, existing, envs)
+		}
+	}
+	return merged, nil
+}
+
+// tagAccountFindings records the account on account findings added since
+// from, so multi-account reports tell the accounts' findings apart
+func tagAccountFindings(results *analyzer.DiscoveryResult, from int, account string) {
+	for i := from; i < len(results.AccountFindings); i++ {
+		results.AccountFindings[i].Account = account
+	}
+}
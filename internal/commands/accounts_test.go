@@ -0,0 +1,81 @@
+package commands
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/ppiankov/s3spectre/internal/analyzer"
+	"github.com/ppiankov/s3spectre/internal/s3"
+)
+
+func TestValidateAccounts(t *testing.T) {
+	tests := []struct {
+		name    string
+		flags   awsClientFlags
+		wantErr bool
+	}{
+		{name: "none", flags: awsClientFlags{}},
+		{name: "role name with accounts", flags: awsClientFlags{accountRole: "S3Audit", accounts: []string{"111111111111"}}},
+		{name: "role ARN alone", flags: awsClientFlags{accountRole: "arn:aws:iam::111111111111:role/S3Audit"}},
+		{name: "accounts without role", flags: awsClientFlags{accounts: []string{"111111111111"}}, wantErr: true},
+		{name: "role name alone", flags: awsClientFlags{accountRole: "S3Audit"}, wantErr: true},
+		{name: "bad account", flags: awsClientFlags{accountRole: "S3Audit", accounts: []string{"prod"}}, wantErr: true},
+		{name: "bad role", flags: awsClientFlags{accountRole: "arn:aws:s3:::bucket", accounts: []string{"111111111111"}}, wantErr: true},
+		{name: "org alone", flags: awsClientFlags{org: true}},
+		{name: "org with role name", flags: awsClientFlags{org: true, accountRole: "S3Audit"}},
+		{name: "org with accounts", flags: awsClientFlags{org: true, accountRole: "S3Audit", accounts: []string{"111111111111"}}, wantErr: true},
+		{name: "org with bad role", flags: awsClientFlags{org: true, accountRole: "arn:aws:s3:::bucket"}, wantErr: true},
+		{name: "accounts with external ID", flags: awsClientFlags{assumeRole: s3.AssumeRole{RoleARN: "arn:aws:iam::111111111111:role/hub", ExternalID: "audit"}, accountRole: "S3Audit", accounts: []string{"222222222222"}}},
+		{name: "accounts with MFA", flags: awsClientFlags{assumeRole: s3.AssumeRole{RoleARN: "arn:aws:iam::111111111111:role/hub", MFASerial: "arn:aws:iam::111111111111:mfa/ana"}, accountRole: "S3Audit", accounts: []string{"222222222222"}}, wantErr: true},
+		{name: "org with MFA", flags: awsClientFlags{assumeRole: s3.AssumeRole{RoleARN: "arn:aws:iam::111111111111:role/hub", MFASerial: "arn:aws:iam::111111111111:mfa/ana"}, org: true}, wantErr: true},
+		{name: "MFA without accounts", flags: awsClientFlags{assumeRole: s3.AssumeRole{RoleARN: "arn:aws:iam::111111111111:role/hub", MFASerial: "arn:aws:iam::111111111111:mfa/ana"}}},
+		{name: "endpoint URL", flags: awsClientFlags{endpointURL: "http://minio.local:9000", pathStyle: true}},
+		{name: "endpoint URL without scheme", flags: awsClientFlags{endpointURL: "minio.local:9000"}, wantErr: true},
+		{name: "endpoint URL with accounts", flags: awsClientFlags{endpointURL: "http://minio.local:9000", accountRole: "S3Audit", accounts: []string{"111111111111"}}, wantErr: true},
+		{name: "endpoint URL with org", flags: awsClientFlags{endpointURL: "http://minio.local:9000", org: true}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.flags.validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestTargetAccounts(t *testing.T) {
+	f := awsClientFlags{accountRole: "S3Audit", accounts: []string{"111111111111", "222222222222", "111111111111"}}
+	if got, want := f.targetAccounts(), []string{"111111111111", "222222222222"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("targetAccounts() = %v, want %v", got, want)
+	}
+	f = awsClientFlags{accountRole: "arn:aws:iam::333333333333:role/S3Audit"}
+	if got, want := f.targetAccounts(), []string{"333333333333"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("a role ARN alone should target its account, got %v", got)
+	}
+}
+
+func TestAuditRole(t *testing.T) {
+	f := awsClientFlags{org: true}
+	if !f.multiAccount() {
+		t.Error("--org should make the run multi-account")
+	}
+	if got := f.auditRole(); got != "OrganizationAccountAccessRole" {
+		t.Errorf("auditRole() = %q, want the Organizations default role", got)
+	}
+	f.accountRole = "S3Audit"
+	if got := f.auditRole(); got != "S3Audit" {
+		t.Errorf("auditRole() = %q, want --assume-role-arn", got)
+	}
+}
+
+func TestTagAccountFindings(t *testing.T) {
+	results := &analyzer.DiscoveryResult{AccountFindings: []analyzer.AccountAnalysis{
+		{Status: analyzer.StatusNoAccountPAB, Account: "111111111111"},
+		{Status: analyzer.StatusNoGuardDutyS3},
+	}}
+	tagAccountFindings(results, 1, "222222222222")
+	if results.AccountFindings[0].Account != "111111111111" || results.AccountFindings[1].Account != "222222222222" {
+		t.Errorf("only findings from the index on should be tagged: %+v", results.AccountFindings)
+	}
+}
@@ -0,0 +1,59 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/ppiankov/s3spectre/internal/badge"
+	"github.com/ppiankov/s3spectre/internal/baseline"
+	"github.com/spf13/cobra"
+)
+
+var badgeFlags struct {
+	format     string
+	outputFile string
+	label      string
+}
+
+var badgeCmd = &cobra.Command{
+	Use:   "badge REPORT",
+	Short: "Render a drift-health badge from a JSON report",
+	Long: `Reads a scan or discover JSON report and renders a badge summarizing its
+most severe findings (e.g. "s3 drift: 3 medium"), as an SVG image or a
+shields.io endpoint JSON document for README and dashboard embedding.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runBadge,
+}
+
+func init() {
+	badgeCmd.Flags().StringVarP(&badgeFlags.format, "format", "f", "svg", "Badge format: svg or shields")
+	badgeCmd.Flags().StringVarP(&badgeFlags.outputFile, "output", "o", "", "Output file (default: stdout)")
+	badgeCmd.Flags().StringVar(&badgeFlags.label, "label", "s3 drift", "Badge label")
+}
+
+func runBadge(cmd *cobra.Command, args []string) error {
+	findings, err := baseline.LoadReportFindings(args[0])
+	if err != nil {
+		return enhanceError("report load", err, 0)
+	}
+	b := badge.FromFindings(badgeFlags.label, findings)
+
+	writer := os.Stdout
+	if badgeFlags.outputFile != "" {
+		f, err := os.Create(badgeFlags.outputFile)
+		if err != nil {
+			return enhanceError("output file creation", err, 0)
+		}
+		defer func() { _ = f.Close() }()
+		writer = f
+	}
+
+	switch badgeFlags.format {
+	case "svg":
+		return badge.WriteSVG(writer, b)
+	case "shields":
+		return badge.WriteShields(writer, b)
+	default:
+		return fmt.Errorf("unsupported badge format: %s (supported: svg, shields)", badgeFlags.format)
+	}
+}
@@ -0,0 +1,53 @@
+package commands
+
+import (
+	"fmt"
+	"log/slog"
+
+	"github.com/ppiankov/s3spectre/internal/s3"
+)
+
+// largeAccountBuckets is the bucket count above which discover warns about
+// what inspecting them may cost
+const largeAccountBuckets = 1000
+
+// warnInspectionCost warns before discover inspects a large account, with
+// the most its S3 requests can cost
+func warnInspectionCost(buckets int, versionSampling bool, deepSize int) {
+	if buckets < largeAccountBuckets {
+		return
+	}
+	slog.Warn(fmt.Sprintf("Inspecting %d buckets; versioned buckets list up to %d version pages each", buckets, s3.MaxVersionPages),
+		slog.String("max_request_cost", formatUSD(s3.DiscoverCostCeiling(buckets, versionSampling, deepSize))),
+	)
+}
+
+// printRequestCost logs the estimated cost of the run's AWS requests
+func printRequestCost(cost s3.RequestCost) {
+	attrs := []any{
+		slog.String("cost", formatUSD(cost.USD)),
+		slog.Int("tier1_requests", cost.Tier1Requests),
+		slog.Int("tier2_requests", cost.Tier2Requests),
+		slog.Int("free_requests", cost.FreeRequests),
+	}
+	if cost.CostExplorerRequests > 0 {
+		attrs = append(attrs, slog.Int("cost_explorer_requests", cost.CostExplorerRequests))
+	}
+	if cost.CloudWatchMetrics > 0 {
+		attrs = append(attrs, slog.Int("cloudwatch_metrics", cost.CloudWatchMetrics))
+	}
+	if cost.LakeQueries > 0 {
+		// Billed by data scanned, so not in the estimate
+		attrs = append(attrs, slog.Int("unpriced_cloudtrail_lake_queries", cost.LakeQueries))
+	}
+	slog.Info("Estimated AWS request cost", attrs...)
+}
+
+// formatUSD prints a dollar amount, with sub-cent precision for the small
+// amounts most runs cost
+func formatUSD(usd float64) string {
+	if usd < 1 {
+		return fmt.Sprintf("$%.4f", usd)
+	}
+	return fmt.Sprintf("$%.2f", usd)
+}
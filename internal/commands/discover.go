@@ -9,8 +9,11 @@ import (
 	"strings"
 	"time"
 
+	"github.com/fatih/color"
 	"github.com/ppiankov/s3spectre/internal/analyzer"
 	"github.com/ppiankov/s3spectre/internal/baseline"
+	"github.com/ppiankov/s3spectre/internal/inventory"
+	"github.com/ppiankov/s3spectre/internal/plugin"
 	"github.com/ppiankov/s3spectre/internal/report"
 	"github.com/ppiankov/s3spectre/internal/s3"
 	"github.com/spf13/cobra"
@@ -20,21 +23,63 @@ import (
 var discoverFlags struct {
 	awsProfile       string
 	awsRegion        string
+	aws              awsClientFlags
+	checkpoint       checkpointFlags
 	allRegions       bool
 	regions          []string
 	ageThresholdDays int
 	inactiveDays     int
 	checkEncryption  bool
 	checkPublic      bool
+	checkPolicy      bool
+	checkLogging     bool
+	productionTags   []string
+	checkGuardDuty   bool
+	checkEnvDrift    bool
+	withCosts        bool
+	checkAccess      bool
+	checkAnalyzer    bool
+	withMacie        bool
+	correlate        []string
+	accessDataStore  string
+	costTag          string
+	maxNoLifecycle   int
 	maxConcurrency   int
 	outputFormat     string
 	outputFile       string
+	outputDir        string
 	failOnUnused     bool
 	failOnRisky      bool
+	failOnSeverity   string
 	noProgress       bool
+	deterministic    bool
+	tuningPatch      string
 	timeout          time.Duration
+	timeouts         timeoutFlags
 	baselinePath     string
 	updateBaseline   bool
+	source           string
+	configAggregator string
+	tagFilters       []string
+	explorerView     string
+	includeBuckets   []string
+	excludeBuckets   []string
+	groupByTag       string
+	sampleBuckets    int
+	versionSampling  bool
+	deepSize         int
+	metricsSource    string
+	inventoryReports []string
+	plugins          []string
+	outposts         []string
+	exportInventory  string
+	exportFormat     string
+	notify           notifyFlags
+	github           githubFlags
+	jira             jiraFlags
+	openSearch       openSearchFlags
+	warehouse        warehouseFlags
+	state            stateFlags
 }
 
 var discoverCmd = &cobra.Command{
@@ -50,24 +95,154 @@ func init() {
 	discoverCmd.Flags().StringVar(&discoverFlags.awsRegion, "aws-region", "", "AWS region (single region mode)")
 	discoverCmd.Flags().BoolVar(&discoverFlags.allRegions, "all-regions", true, "Scan all enabled AWS regions")
 	discoverCmd.Flags().StringSliceVar(&discoverFlags.regions, "regions", nil, "Specific regions to scan (comma-separated)")
+	addAWSClientFlags(discoverCmd.Flags(), &discoverFlags.aws)
+	discoverCmd.Flags().BoolVar(&discoverFlags.aws.org, "org", false, "Discover buckets in every active AWS Organizations account by assuming --assume-role-arn (default OrganizationAccountAccessRole) in each")
+	addCheckpointFlags(discoverCmd.Flags(), &discoverFlags.checkpoint)
 	discoverCmd.Flags().IntVar(&discoverFlags.ageThresholdDays, "age-threshold-days", 365, "Buckets older than X days are flagged")
 	discoverCmd.Flags().IntVar(&discoverFlags.inactiveDays, "inactive-days", 180, "No activity for X days is flagged")
 	discoverCmd.Flags().BoolVar(&discoverFlags.checkEncryption, "check-encryption", false, "Check for missing encryption")
 	discoverCmd.Flags().BoolVar(&discoverFlags.checkPublic, "check-public", false, "Check for public access")
+	discoverCmd.Flags().BoolVar(&discoverFlags.checkLogging, "check-logging", false, "Report production buckets with neither server access logging nor CloudTrail data events")
+	discoverCmd.Flags().StringSliceVar(&discoverFlags.productionTags, "production-tag", []string{"environment=production"}, "Tag marking production buckets for --check-logging, key=value or key (repeatable, any may match)")
+	discoverCmd.Flags().BoolVar(&discoverFlags.checkPolicy, "check-policy", false, "Read each bucket policy and report wildcard principals, cross-account grants and missing aws:SecureTransport denies")
+	discoverCmd.Flags().BoolVar(&discoverFlags.checkGuardDuty, "check-guardduty", false, "Report scanned regions where GuardDuty S3 protection is not enabled")
+	discoverCmd.Flags().BoolVar(&discoverFlags.checkEnvDrift, "check-env-drift", false, "Report environment families (orders-dev, orders-prod) whose encryption, versioning or lifecycle settings differ")
+	discoverCmd.Flags().BoolVar(&discoverFlags.checkAccess, "check-access", false, "Read each bucket's last GetObject/PutObject from CloudTrail data events over --inactive-days, so inactivity reflects real use")
+	discoverCmd.Flags().BoolVar(&discoverFlags.checkAnalyzer, "check-access-analyzer", false, "Attach active IAM Access Analyzer external access findings to buckets and score the confirmed exposure")
+	discoverCmd.Flags().BoolVar(&discoverFlags.withMacie, "with-macie", false, "Read Macie sensitive data findings, weight public or unencrypted buckets holding sensitive data, and report their data classes")
+	discoverCmd.Flags().StringSliceVar(&discoverFlags.correlate, "correlate", nil, "Correlate findings with this spectre/v1 report from another Spectre tool, such as iamspectre (repeatable)")
+	discoverCmd.Flags().StringVar(&discoverFlags.accessDataStore, "access-event-data-store", "", "CloudTrail Lake event data store ARN or ID holding S3 data events (required with --check-access)")
+	discoverCmd.Flags().BoolVar(&discoverFlags.withCosts, "with-costs", false, "Show each bucket's month-to-date S3 spend from Cost Explorer (needs ce:GetCostAndUsage; $0.01 per request)")
+	discoverCmd.Flags().StringVar(&discoverFlags.costTag, "cost-tag", "Name", "Cost allocation tag whose value identifies a bucket's spend (used with --with-costs)")
+	discoverCmd.Flags().IntVar(&discoverFlags.maxNoLifecycle, "max-buckets-without-lifecycle", 0, "Report an account finding when more than N buckets have no lifecycle rules (0 disables)")
 	discoverCmd.Flags().IntVar(&discoverFlags.maxConcurrency, "concurrency", 10, "Max concurrent S3 API calls")
-	discoverCmd.Flags().StringVarP(&discoverFlags.outputFormat, "format", "f", "text", "Output format: text, json, sarif, or spectrehub")
+	discoverCmd.Flags().VarP(newFormatList(&discoverFlags.outputFormat, "text"), "format", "f", "Output format: text, json, sarif, spectrehub, tasks, csv, or markdown (repeatable with --output-dir)")
 	discoverCmd.Flags().StringVarP(&discoverFlags.outputFile, "output", "o", "", "Output file (default: stdout)")
+	discoverCmd.Flags().StringVar(&discoverFlags.outputDir, "output-dir", "", "Write one report per --format to this directory, with a manifest.json")
 	discoverCmd.Flags().BoolVar(&discoverFlags.failOnUnused, "fail-on-unused", false, "Exit with error if unused buckets found")
 	discoverCmd.Flags().BoolVar(&discoverFlags.failOnRisky, "fail-on-risky", false, "Exit with error if risky buckets found")
+	discoverCmd.Flags().StringVar(&discoverFlags.failOnSeverity, "fail-on-severity", "", "Exit with error if any finding is at or above this severity: high, medium, low, or info")
 	discoverCmd.Flags().BoolVar(&discoverFlags.noProgress, "no-progress", false, "Disable progress indicators")
+	discoverCmd.Flags().BoolVar(&discoverFlags.deterministic, "deterministic", false, "Reproducible output for snapshot tests: fixed timestamp, sorted lists, no run statistics or color")
+	discoverCmd.Flags().StringVar(&discoverFlags.tuningPatch, "tuning-patch", "", "Write suggested threshold adjustments to this file as a config patch")
 	discoverCmd.Flags().DurationVar(&discoverFlags.timeout, "timeout", 0, "Total operation timeout (e.g. 5m, 30s). 0 means no timeout")
+	addTimeoutFlags(discoverCmd.Flags(), &discoverFlags.timeouts)
+	_ = discoverCmd.Flags().MarkDeprecated("timeout", "use --inspect-timeout and --per-bucket-timeout, which skip slow buckets instead of aborting the run")
+	addNotifyFlags(discoverCmd.Flags(), &discoverFlags.notify)
+	addGitHubFlags(discoverCmd.Flags(), &discoverFlags.github)
+	addJiraFlags(discoverCmd.Flags(), &discoverFlags.jira)
+	addOpenSearchFlags(discoverCmd.Flags(), &discoverFlags.openSearch)
+	addWarehouseFlags(discoverCmd.Flags(), &discoverFlags.warehouse)
+	addStateFlags(discoverCmd.Flags(), &discoverFlags.state)
 	discoverCmd.Flags().StringVar(&discoverFlags.baselinePath, "baseline", "", "Path to previous JSON report for diff comparison")
 	discoverCmd.Flags().BoolVar(&discoverFlags.updateBaseline, "update-baseline", false, "Write current results as the new baseline")
+	discoverCmd.Flags().StringVar(&discoverFlags.source, "source", "s3", "Bucket inventory source: s3, aws-config, or resource-explorer")
+	discoverCmd.Flags().StringVar(&discoverFlags.configAggregator, "config-aggregator", "", "AWS Config aggregator name (required with --source aws-config)")
+	discoverCmd.Flags().StringSliceVar(&discoverFlags.tagFilters, "tag-filter", nil, "Only include buckets with this tag, key=value or key (repeatable, all must match; part of the search with --source resource-explorer)")
+	discoverCmd.Flags().StringSliceVar(&discoverFlags.includeBuckets, "include-buckets", nil, "Only discover buckets matching these glob patterns (e.g. 'prod-*')")
+	discoverCmd.Flags().StringSliceVar(&discoverFlags.excludeBuckets, "exclude-buckets", nil, "Skip buckets matching these glob patterns (e.g. '*-logs')")
+	discoverCmd.Flags().StringVar(&discoverFlags.groupByTag, "group-by-tag", "", "Group report totals by the value of this tag key (e.g. cost-center)")
+	discoverCmd.Flags().IntVar(&discoverFlags.sampleBuckets, "sample-buckets", 0, "Inspect a random sample of N buckets and extrapolate account totals (0 inspects all)")
+	discoverCmd.Flags().BoolVar(&discoverFlags.versionSampling, "version-sampling", false, "Estimate version overhead of buckets too large to list in 100 pages from pages under random top-level prefixes, with a 95% confidence interval")
+	discoverCmd.Flags().IntVar(&discoverFlags.deepSize, "deep-size", 0, "List up to N objects per bucket for object counts, sizes and a storage class breakdown (0 lists the first 100)")
+	discoverCmd.Flags().StringVar(&discoverFlags.metricsSource, "metrics-source", s3.MetricsSourceList, "Where bucket size and activity come from: list (sample objects) or cloudwatch (daily storage and request metrics, no listing)")
+	discoverCmd.Flags().StringSliceVar(&discoverFlags.inventoryReports, "inventory-manifest", nil, "Count a bucket's objects, sizes, versions and ages from this S3 Inventory manifest.json or inventory folder (s3://...) instead of listing (repeatable, CSV inventories)")
+	discoverCmd.Flags().StringSliceVar(&discoverFlags.plugins, "plugin", nil, "External check executable to run over discovered buckets (repeatable, added to config plugins)")
+	discoverCmd.Flags().StringSliceVar(&discoverFlags.outposts, "outpost", nil, "Also discover buckets on this S3 on Outposts Outpost, by ID or ARN (repeatable, added to config outposts)")
+	discoverCmd.Flags().StringVar(&discoverFlags.exportInventory, "export-inventory", "", "Also write the raw bucket inventory to this file for warehouse analysis")
+	discoverCmd.Flags().StringVar(&discoverFlags.exportFormat, "export-format", "", "Inventory export format: csv or parquet (default: from the file extension)")
+	discoverCmd.Flags().StringVar(&discoverFlags.explorerView, "resource-explorer-view", "", "Resource Explorer view ARN (default: the region's default view)")
 }
 
 func runDiscover(cmd *cobra.Command, args []string) error {
 	// Apply config file defaults for flags not explicitly set
 	applyConfigToDiscoverFlags(cmd)
+	if err := validateSeverityGate(discoverFlags.failOnSeverity); err != nil {
+		return err
+	}
+	if err := validateOutput(discoverFlags.outputFormat, discoverFlags.outputFile, discoverFlags.outputDir); err != nil {
+		return err
+	}
+	if err := discoverFlags.aws.validate(); err != nil {
+		return err
+	}
+	if err := discoverFlags.timeouts.validate(); err != nil {
+		return err
+	}
+	if discoverFlags.deepSize < 0 {
+		return fmt.Errorf("--deep-size must not be negative")
+	}
+	switch discoverFlags.metricsSource {
+	case s3.MetricsSourceList:
+	case s3.MetricsSourceCloudWatch:
+		if discoverFlags.deepSize > 0 {
+			return fmt.Errorf("--deep-size lists objects and cannot be combined with --metrics-source cloudwatch")
+		}
+	default:
+		return fmt.Errorf("invalid --metrics-source %q: use list or cloudwatch", discoverFlags.metricsSource)
+	}
+	if checks := awsOnlyChecks(); discoverFlags.aws.endpointURL != "" && len(checks) > 0 {
+		return fmt.Errorf("--endpoint-url cannot be combined with %s, which read other AWS services", strings.Join(checks, ", "))
+	}
+	for _, manifest := range discoverFlags.inventoryReports {
+		if !strings.HasPrefix(manifest, "s3://") {
+			return fmt.Errorf("invalid --inventory-manifest %q: use an s3:// URL", manifest)
+		}
+	}
+	if discoverFlags.checkAccess && discoverFlags.accessDataStore == "" {
+		return fmt.Errorf("--check-access needs --access-event-data-store")
+	}
+	if discoverFlags.checkAccess {
+		if err := s3.ValidateEventDataStore(discoverFlags.accessDataStore); err != nil {
+			return err
+		}
+	}
+	if discoverFlags.withCosts && discoverFlags.costTag == "" {
+		return fmt.Errorf("--with-costs needs a --cost-tag")
+	}
+	if err := discoverFlags.jira.validate(); err != nil {
+		return err
+	}
+	if err := discoverFlags.openSearch.validate(); err != nil {
+		return err
+	}
+	if err := discoverFlags.warehouse.validate(); err != nil {
+		return err
+	}
+	if err := discoverFlags.state.validate(); err != nil {
+		return err
+	}
+	if err := discoverFlags.github.validate(); err != nil {
+		return err
+	}
+	if err := discoverFlags.notify.validate(); err != nil {
+		return err
+	}
+	classification, err := dataClassification()
+	if err != nil {
+		return err
+	}
+	envelopes, err := loadEnvelopes(discoverFlags.correlate)
+	if err != nil {
+		return err
+	}
+	productionTags, err := s3.ParseTagFilters(discoverFlags.productionTags)
+	if err != nil {
+		return fmt.Errorf("--production-tag: %w", err)
+	}
+	external, err := externalBuckets(false)
+	if err != nil {
+		return err
+	}
+	if discoverFlags.aws.backoff, err = retryBackoff(); err != nil {
+		return err
+	}
+	var exportFormat string
+	if discoverFlags.exportInventory != "" {
+		if exportFormat, err = inventory.ResolveFormat(discoverFlags.exportFormat, discoverFlags.exportInventory); err != nil {
+			return err
+		}
+	}
 
 	ctx := context.Background()
 	if discoverFlags.timeout > 0 {
@@ -75,7 +250,10 @@ func runDiscover(cmd *cobra.Command, args []string) error {
 		ctx, cancel = context.WithTimeout(ctx, discoverFlags.timeout)
 		defer cancel()
 	}
+	ctx, stopInterrupt := notifyInterrupt(ctx)
+	defer stopInterrupt()
 	start := time.Now()
+	stats := report.NewRunStats()
 
 	// Check if we're running in a terminal
 	isTTY := term.IsTerminal(int(os.Stderr.Fd()))
@@ -83,53 +261,161 @@ func runDiscover(cmd *cobra.Command, args []string) error {
 
 	// Initialize S3 client
 	printStatus("Initializing AWS S3 client...")
-	s3Client, err := s3.NewClient(ctx, discoverFlags.awsProfile, discoverFlags.awsRegion)
+	s3Client, err := s3.NewClient(ctx, discoverFlags.awsProfile, discoverFlags.awsRegion, discoverFlags.aws.clientOptions(discoverFlags.maxConcurrency)...)
+	if interrupted(ctx) {
+		return interruptedBefore("S3 client initialization")
+	}
 	if err != nil {
 		return enhanceError("S3 client initialization", err, discoverFlags.maxConcurrency)
 	}
 	slog.Info("Connected to AWS",
 		slog.String("region", s3Client.GetRegion()),
 		slog.String("profile", discoverFlags.awsProfile),
+		slog.String("role", discoverFlags.aws.assumeRole.RoleARN),
 	)
+	accountID := resolveAccountID(ctx, s3Client)
+	accounts, err := discoverFlags.aws.accountClients(ctx, s3Client, accountID)
+	if interrupted(ctx) {
+		return interruptedBefore("S3 client initialization")
+	}
+	if err != nil {
+		return enhanceError("S3 client initialization", err, discoverFlags.maxConcurrency)
+	}
+	stats.Phase("client_init", start)
 
-	// Configure inspector
-	inspector := s3.NewInspector(s3Client, discoverFlags.maxConcurrency)
+	ctx, stopWatchdog := watchCredentials(ctx, s3Client, discoverFlags.checkpoint)
+	defer stopWatchdog()
+	checkpoint, err := openCheckpoint("discover", discoverFlags.checkpoint)
+	if err != nil {
+		return err
+	}
 
-	// Set up regions
-	if len(discoverFlags.regions) > 0 {
-		inspector.SetRegions(discoverFlags.regions)
-		printStatus("Discovering buckets in regions: %s", strings.Join(discoverFlags.regions, ", "))
-	} else if discoverFlags.allRegions {
-		inspector.SetAllRegions(true)
-		printStatus("Discovering buckets across all enabled AWS regions")
+	phaseStart := time.Now()
+	inspectCtx, cancelInspect := phaseContext(ctx, discoverFlags.timeouts.inspect)
+	progress := s3.NewProgress()
+	stopProgress := func() {}
+	if showProgress {
+		stopProgress = startProgressDisplay(os.Stderr, progress)
+	}
+	reports := readInventoryReports(inspectCtx, s3Client)
+	buckets, population, err := discoverAccounts(inspectCtx, accounts, reports, checkpoint, progress, showProgress)
+	stopProgress()
+	cancelInspect()
+	if expired := checkpointOnExpiry(ctx, checkpoint, discoverFlags.checkpoint); expired != nil {
+		return expired
+	}
+	// An interrupted run reports what was inspected before the signal
+	var truncation *report.Truncation
+	if interrupted(ctx) {
+		if buckets == nil {
+			buckets = make(map[string]*s3.BucketInfo)
+		}
+		truncation = report.NewTruncation(errInterrupted.Error(), nil, buckets)
+	} else if err != nil {
+		return err
 	} else {
-		region := discoverFlags.awsRegion
-		if region == "" {
-			region = s3Client.GetRegion()
+		discardCheckpoint(discoverFlags.checkpoint)
+	}
+	stats.Phase("discovery", phaseStart)
+	stats.SlowestBuckets = reportSlowestBuckets(os.Stderr, progress, showProgress)
+	sampled := discoverFlags.sampleBuckets > 0 && population > len(buckets)
+	if sampled {
+		printStatus("Sampled %d of %d buckets", len(buckets), population)
+	} else {
+		printStatus("Discovered %d buckets", len(buckets))
+	}
+	if discoverFlags.exportInventory != "" {
+		if err := exportInventory(discoverFlags.exportInventory, exportFormat, buckets); err != nil {
+			return enhanceError("inventory export", err, discoverFlags.maxConcurrency)
 		}
-		printStatus("Discovering buckets in region: %s", region)
+		printStatus("Exported bucket inventory to %s", discoverFlags.exportInventory)
 	}
 
-	// Set up progress callback
-	if showProgress {
-		inspector.SetProgressCallback(func(current, total int, message string) {
-			if total > 0 {
-				slog.Debug("Discovery progress", slog.Int("current", current), slog.Int("total", total), slog.String("message", message))
+	// Access events replace sampled object ages before inactivity is judged
+	if discoverFlags.checkAccess && truncation == nil {
+		phaseStart = time.Now()
+		printStatus("Querying CloudTrail Lake for object access...")
+		inspector := s3.NewInspector(s3Client, discoverFlags.maxConcurrency)
+		now := time.Now()
+		since := now.AddDate(0, 0, -discoverFlags.inactiveDays)
+		access, err := inspector.BucketAccess(ctx, discoverFlags.accessDataStore, since)
+		switch {
+		case interrupted(ctx):
+			truncation = report.NewTruncation(errInterrupted.Error(), nil, buckets)
+		case err != nil:
+			slog.Warn("Skipping CloudTrail access check", slog.String("error", err.Error()))
+		default:
+			s3.ApplyAccess(buckets, access, since, now)
+			stats.Phase("access", phaseStart)
+		}
+	}
+
+	// Analyzer-confirmed exposure is scored along with the heuristics
+	if discoverFlags.checkAnalyzer && truncation == nil {
+		phaseStart = time.Now()
+		printStatus("Reading IAM Access Analyzer findings...")
+		for _, account := range accounts {
+			inspector := s3.NewInspector(account.client, discoverFlags.maxConcurrency)
+			if len(discoverFlags.regions) > 0 {
+				inspector.SetRegions(discoverFlags.regions)
 			} else {
-				slog.Debug("Discovery progress", slog.String("message", message))
+				inspector.SetAllRegions(discoverFlags.allRegions)
 			}
-		})
+			analysis, err := inspector.ExternalAccessFindings(ctx)
+			if interrupted(ctx) {
+				truncation = report.NewTruncation(errInterrupted.Error(), nil, buckets)
+				break
+			}
+			if err != nil {
+				slog.Warn("Skipping Access Analyzer findings",
+					slog.String("account", account.id), slog.String("error", err.Error()))
+				continue
+			}
+			if len(analysis.Unanalyzed) > 0 {
+				slog.Warn("No active external access analyzer; buckets in these regions were not checked",
+					slog.String("account", account.id), slog.String("regions", strings.Join(analysis.Unanalyzed, ",")))
+			}
+			s3.ApplyExternalAccess(buckets, account.id, analysis.Findings)
+		}
+		if truncation == nil {
+			stats.Phase("access_analyzer", phaseStart)
+		}
 	}
 
-	// Discover all buckets
-	printStatus("Discovering S3 buckets...")
-	buckets, err := inspector.DiscoverAllBuckets(ctx)
-	if err != nil {
-		return enhanceError("bucket discovery", err, discoverFlags.maxConcurrency)
+	// Sensitive data raises the weight of security findings
+	if discoverFlags.withMacie && truncation == nil {
+		phaseStart = time.Now()
+		printStatus("Reading Macie sensitive data findings...")
+		for _, account := range accounts {
+			inspector := s3.NewInspector(account.client, discoverFlags.maxConcurrency)
+			if len(discoverFlags.regions) > 0 {
+				inspector.SetRegions(discoverFlags.regions)
+			} else {
+				inspector.SetAllRegions(discoverFlags.allRegions)
+			}
+			analysis, err := inspector.MacieSensitiveData(ctx)
+			if interrupted(ctx) {
+				truncation = report.NewTruncation(errInterrupted.Error(), nil, buckets)
+				break
+			}
+			if err != nil {
+				slog.Warn("Skipping Macie findings",
+					slog.String("account", account.id), slog.String("error", err.Error()))
+				continue
+			}
+			if len(analysis.Disabled) > 0 {
+				slog.Warn("Macie is not enabled; buckets in these regions were not classified",
+					slog.String("account", account.id), slog.String("regions", strings.Join(analysis.Disabled, ",")))
+			}
+			s3.ApplySensitiveData(buckets, account.id, analysis)
+		}
+		if truncation == nil {
+			stats.Phase("macie", phaseStart)
+		}
 	}
-	printStatus("Discovered %d buckets", len(buckets))
 
 	// Analyze with discovery heuristics
+	phaseStart = time.Now()
 	printStatus("Analyzing buckets...")
 	config := analyzer.DiscoveryConfig{
 		AgeThresholdDays:        discoverFlags.ageThresholdDays,
@@ -137,8 +423,126 @@ func runDiscover(cmd *cobra.Command, args []string) error {
 		CheckEncryption:         discoverFlags.checkEncryption,
 		CheckPublicAccess:       discoverFlags.checkPublic,
 		RiskScoreThreshold:      100, // Default threshold
+		Classification:          classification,
+		External:                external,
+		CheckPolicy:             discoverFlags.checkPolicy,
+		CheckLogging:            discoverFlags.checkLogging,
+		ProductionTags:          productionTags,
 	}
 	results := analyzer.AnalyzeDiscovery(buckets, config)
+	warnTimedOut(results.Summary.TimedOutBuckets)
+	warnDeadlineSkips(results.Summary.DeadlineSkips)
+	stats.Phase("analysis", phaseStart)
+
+	plugins, err := loadPlugins()
+	if err != nil {
+		return err
+	}
+	if len(plugins) > 0 && truncation == nil {
+		phaseStart = time.Now()
+		printStatus("Running %d check plugins...", len(plugins))
+		findings, err := plugin.RunAll(ctx, plugins, buckets)
+		switch {
+		case interrupted(ctx):
+			truncation = report.NewTruncation(errInterrupted.Error(), nil, buckets)
+		case err != nil:
+			return enhanceError("plugin checks", err, discoverFlags.maxConcurrency)
+		default:
+			analyzer.AddCustomFindings(results, findings)
+			stats.Phase("plugins", phaseStart)
+		}
+	}
+
+	if discoverFlags.checkGuardDuty && truncation == nil {
+		phaseStart = time.Now()
+		printStatus("Checking GuardDuty S3 protection...")
+		for _, account := range accounts {
+			inspector := s3.NewInspector(account.client, discoverFlags.maxConcurrency)
+			if len(discoverFlags.regions) > 0 {
+				inspector.SetRegions(discoverFlags.regions)
+			} else {
+				inspector.SetAllRegions(discoverFlags.allRegions)
+			}
+			statuses, err := inspector.GuardDutyS3Protection(ctx)
+			if interrupted(ctx) {
+				truncation = report.NewTruncation(errInterrupted.Error(), nil, buckets)
+				break
+			}
+			if err != nil {
+				return enhanceError("GuardDuty check", err, discoverFlags.maxConcurrency)
+			}
+			from := len(results.AccountFindings)
+			analyzer.AnalyzeGuardDuty(results, statuses)
+			if discoverFlags.aws.multiAccount() {
+				tagAccountFindings(results, from, account.id)
+			}
+		}
+		if truncation == nil {
+			stats.Phase("guardduty", phaseStart)
+		}
+	}
+
+	// Account-level checks; a caller without s3:GetAccountPublicAccessBlock
+	// still gets the rest of the report. S3-compatible stores have no
+	// account block.
+	if discoverFlags.checkPublic && discoverFlags.aws.endpointURL == "" && truncation == nil {
+		printStatus("Checking account public access block...")
+		for _, account := range accounts {
+			inspector := s3.NewInspector(account.client, discoverFlags.maxConcurrency)
+			block, err := inspector.AccountPublicAccessBlock(ctx, account.id)
+			if interrupted(ctx) {
+				truncation = report.NewTruncation(errInterrupted.Error(), nil, buckets)
+				break
+			}
+			if err != nil {
+				slog.Warn("Skipping account public access block check",
+					slog.String("account", account.id), slog.String("error", err.Error()))
+				continue
+			}
+			from := len(results.AccountFindings)
+			analyzer.AnalyzeAccountPublicAccess(results, block)
+			if discoverFlags.aws.multiAccount() {
+				tagAccountFindings(results, from, account.id)
+			}
+		}
+	}
+	// Spend is read from the caller's account, which sees its linked
+	// accounts' spend when it is the organization's management account
+	var spend *analyzer.SpendSummary
+	if discoverFlags.withCosts && truncation == nil {
+		printStatus("Reading month-to-date S3 spend from Cost Explorer...")
+		inspector := s3.NewInspector(s3Client, discoverFlags.maxConcurrency)
+		tagSpend, err := inspector.MonthToDateS3Spend(ctx, discoverFlags.costTag, time.Now())
+		switch {
+		case interrupted(ctx):
+			truncation = report.NewTruncation(errInterrupted.Error(), nil, buckets)
+		case err != nil:
+			slog.Warn("Skipping Cost Explorer spend", slog.String("error", err.Error()))
+		default:
+			spend = analyzer.AnalyzeSpend(results, tagSpend, accountID)
+			warnUnattributedSpend(spend)
+		}
+	}
+	if truncation == nil {
+		analyzer.AnalyzeLifecycleCoverage(results, discoverFlags.maxNoLifecycle)
+		if discoverFlags.checkEnvDrift {
+			analyzer.AnalyzeEnvironmentDrift(results, environments())
+		}
+	}
+	if len(envelopes) > 0 {
+		analyzer.CorrelateDiscovery(results, envelopes)
+	}
+	stats.Finish(s3Client, checkpoint)
+	printRequestCost(stats.RequestCost)
+
+	costTag := ""
+	if discoverFlags.withCosts {
+		costTag = discoverFlags.costTag
+	}
+	var loggingTags []string
+	if discoverFlags.checkLogging {
+		loggingTags = discoverFlags.productionTags
+	}
 
 	// Generate report
 	reportData := report.DiscoveryData{
@@ -147,51 +551,90 @@ func runDiscover(cmd *cobra.Command, args []string) error {
 		Timestamp: time.Now(),
 		Config: report.DiscoveryConfig{
 			AWSProfile:              discoverFlags.awsProfile,
+			AccountID:               accountID,
 			AllRegions:              discoverFlags.allRegions,
 			Regions:                 discoverFlags.regions,
 			AgeThresholdDays:        discoverFlags.ageThresholdDays,
 			InactivityThresholdDays: discoverFlags.inactiveDays,
 			CheckEncryption:         discoverFlags.checkEncryption,
 			CheckPublicAccess:       discoverFlags.checkPublic,
+			Source:                  discoverFlags.source,
+			TagFilters:              discoverFlags.tagFilters,
+			IncludeBuckets:          discoverFlags.includeBuckets,
+			ExcludeBuckets:          discoverFlags.excludeBuckets,
+			GroupByTag:              discoverFlags.groupByTag,
+			SampleBuckets:           discoverFlags.sampleBuckets,
+			DeepSize:                discoverFlags.deepSize,
+			Plugins:                 pluginNames(plugins),
+			CheckGuardDuty:          discoverFlags.checkGuardDuty,
+			CheckAccessAnalyzer:     discoverFlags.checkAnalyzer,
+			WithMacie:               discoverFlags.withMacie,
+			MaxNoLifecycle:          discoverFlags.maxNoLifecycle,
+			CheckEnvDrift:           discoverFlags.checkEnvDrift,
+			CostTag:                 costTag,
+			CheckAccess:             discoverFlags.checkAccess,
+			MetricsSource:           discoverFlags.metricsSource,
+			InventoryManifests:      discoverFlags.inventoryReports,
+			CheckPolicy:             discoverFlags.checkPolicy,
+			CheckLogging:            discoverFlags.checkLogging,
+			ProductionTags:          loggingTags,
 		},
-		Summary: results.Summary,
-		Buckets: results.Buckets,
+		Summary:         results.Summary,
+		Buckets:         results.Buckets,
+		AccountFindings: results.AccountFindings,
+		Correlations:    results.Correlations,
+		Stats:           stats,
+		Truncated:       truncation,
+		Spend:           spend,
+	}
+	if truncation == nil {
+		reportData.TuningHints = analyzer.SuggestDiscoveryTuning(results, config)
+	}
+	postureOf := 0
+	if sampled {
+		postureOf = population
+	}
+	reportData.Posture = analyzer.ComputePosture(results, postureOf)
+	reportData.Regions = analyzer.SummarizeRegions(results)
+	if discoverFlags.aws.multiAccount() {
+		reportData.Accounts = analyzer.SummarizeDiscoveryAccounts(results, accountID)
+	}
+	if sampled {
+		reportData.Sample = analyzer.EstimateFromSample(results.Summary, population)
+	}
+	if discoverFlags.groupByTag != "" {
+		reportData.Groups = analyzer.GroupByTag(results, discoverFlags.groupByTag)
 	}
 
-	// Determine output writer
-	writer := os.Stdout
-	if discoverFlags.outputFile != "" {
-		f, err := os.Create(discoverFlags.outputFile)
+	if discoverFlags.deterministic {
+		color.NoColor = true
+		reportData.MakeDeterministic()
+	}
+
+	// Baseline comparison; a partial report is not compared
+	if discoverFlags.baselinePath != "" && truncation == nil {
+		reportData.Changes, err = compareBaseline(discoverFlags.baselinePath, discoverFlags.outputFormat,
+			baseline.FlattenDiscoveryFindings(reportData), baseline.LoadDiscoveryBaseline)
 		if err != nil {
-			return enhanceError("output file creation", err, discoverFlags.maxConcurrency)
+			return enhanceError("baseline load", err, discoverFlags.maxConcurrency)
 		}
-		defer func() { _ = f.Close() }()
-		writer = f
 	}
 
-	// Generate report
-	reporter, err := selectReporter(discoverFlags.outputFormat, writer)
+	// Generate reports
+	err = writeReports(discoverFlags.outputFormat, discoverFlags.outputFile, discoverFlags.outputDir, "discover", reportData.Timestamp, func(reporter report.Reporter) error {
+		return reporter.GenerateDiscovery(reportData)
+	})
 	if err != nil {
-		return err
-	}
-
-	if err := reporter.GenerateDiscovery(reportData); err != nil {
 		return enhanceError("report generation", err, discoverFlags.maxConcurrency)
 	}
-
-	// Baseline comparison
-	if discoverFlags.baselinePath != "" {
-		currentFindings := baseline.FlattenDiscoveryFindings(reportData)
-		baselineFindings, err := baseline.LoadDiscoveryBaseline(discoverFlags.baselinePath)
-		if err != nil {
-			return enhanceError("baseline load", err, discoverFlags.maxConcurrency)
+	// A partial report is neither compared with nor written as a baseline
+	if truncation != nil {
+		return interruptedRun(checkpoint, discoverFlags.checkpoint, truncation)
+	}
+	if discoverFlags.tuningPatch != "" {
+		if err := writeTuningPatch(discoverFlags.tuningPatch, reportData.TuningHints); err != nil {
+			return enhanceError("tuning patch write", err, discoverFlags.maxConcurrency)
 		}
-		diff := baseline.Diff(currentFindings, baselineFindings)
-		slog.Info("Baseline comparison",
-			slog.Int("new", len(diff.New)),
-			slog.Int("resolved", len(diff.Resolved)),
-			slog.Int("unchanged", len(diff.Unchanged)),
-		)
 	}
 
 	// Write updated baseline if requested
@@ -206,10 +649,42 @@ func runDiscover(cmd *cobra.Command, args []string) error {
 		slog.Info("Updated baseline", slog.String("path", discoverFlags.outputFile))
 	}
 
+	if discoverFlags.notify.enabled() {
+		sendNotifications(ctx, discoverFlags.notify, "discover", accountID, reportData.Timestamp,
+			report.DiscoveryFindings(reportData), reportData.Changes)
+	}
+	if discoverFlags.github.repo != "" {
+		complete := completeRun(reportData.Truncated, discoverFlags.sampleBuckets > 0,
+			reportData.Summary.TimedOutBuckets, reportData.Summary.DeadlineSkips)
+		fileGitHubIssues(ctx, discoverFlags.github, "discover", accountID, "", report.DiscoveryFindings(reportData), complete)
+	}
+	if discoverFlags.jira.enabled() {
+		createJiraTickets(ctx, discoverFlags.jira, "discover", report.DiscoveryFindings(reportData), reportData)
+	}
+	if discoverFlags.openSearch.enabled() {
+		indexFindings(ctx, discoverFlags.openSearch, "discover", accountID, reportData.Timestamp, report.DiscoveryFindings(reportData))
+	}
+	if discoverFlags.warehouse.enabled() {
+		exportWarehouse(ctx, discoverFlags.warehouse, s3Client, "discover", accountID, reportData.Timestamp, report.DiscoveryFindings(reportData))
+	}
+	if discoverFlags.state.enabled() {
+		recordRun(ctx, discoverFlags.state, s3Client.GetConfig(), "discover", accountID, reportData.Timestamp,
+			baseline.FlattenDiscoveryFindings(reportData), reportData.Truncated == nil)
+	}
+
 	findingCount := len(results.Summary.UnusedBuckets) +
 		len(results.Summary.RiskyBuckets) +
 		len(results.Summary.InactiveBuckets) +
-		len(results.Summary.VersionSprawl)
+		len(results.Summary.VersionSprawl) +
+		len(results.Summary.WildcardPrincipals) +
+		len(results.Summary.CrossAccountAccess) +
+		len(results.Summary.InsecureTransport) +
+		len(results.Summary.NoLogging) +
+		len(results.Summary.SensitiveDataExposed) +
+		len(results.Summary.ReplicationDestinations) +
+		results.Summary.CustomFindings +
+		len(results.AccountFindings) +
+		len(results.Correlations)
 	slog.Info("Discovery complete",
 		slog.Int("bucket_count", results.Summary.TotalBuckets),
 		slog.Int("prefix_count", 0),
@@ -227,14 +702,265 @@ func runDiscover(cmd *cobra.Command, args []string) error {
 	if discoverFlags.failOnRisky && len(results.Summary.RiskyBuckets) > 0 {
 		return fmt.Errorf("found %d risky buckets", len(results.Summary.RiskyBuckets))
 	}
+	if err := checkSeverityGate(baseline.FlattenDiscoveryFindings(reportData), discoverFlags.failOnSeverity); err != nil {
+		return err
+	}
 
 	return nil
 }
 
+// discoverAccounts discovers the buckets of each account and merges them,
+// stamping each bucket with its account in multi-account runs. When
+// sampling, the population is summed across accounts.
+func discoverAccounts(ctx context.Context, accounts []accountClient, reports map[string]*s3.InventoryReport, checkpoint *s3.Checkpoint, progress *s3.Progress, showProgress bool) (map[string]*s3.BucketInfo, int, error) {
+	if !discoverFlags.aws.multiAccount() {
+		return discoverBuckets(ctx, accounts[0], reports, checkpoint, progress, showProgress)
+	}
+	byAccount := make(map[string]map[string]*s3.BucketInfo)
+	population := 0
+	for _, account := range accounts {
+		printStatus("Discovering buckets in account %s", account.id)
+		buckets, n, err := discoverBuckets(ctx, account, reports, checkpoint, progress, showProgress)
+		if buckets != nil {
+			byAccount[account.id] = buckets
+		}
+		population += max(n, len(buckets))
+		if err != nil {
+			return s3.MergeAccountBuckets(byAccount), population, fmt.Errorf("account %s: %w", account.id, err)
+		}
+		if ctx.Err() != nil {
+			break
+		}
+	}
+	return s3.MergeAccountBuckets(byAccount), population, nil
+}
+
+// discoverBuckets lists and inspects an account's buckets from the configured inventory source.
+// When sampling, it also returns the number of buckets the sample was drawn from.
+func discoverBuckets(ctx context.Context, account accountClient, reports map[string]*s3.InventoryReport, checkpoint *s3.Checkpoint, progress *s3.Progress, showProgress bool) (map[string]*s3.BucketInfo, int, error) {
+	s3Client := account.client
+	filter, err := s3.NewBucketFilter(discoverFlags.includeBuckets, discoverFlags.excludeBuckets)
+	if err != nil {
+		return nil, 0, err
+	}
+	tagFilters, err := s3.ParseTagFilters(discoverFlags.tagFilters)
+	if err != nil {
+		return nil, 0, err
+	}
+	outposts, err := s3.ParseOutposts(append(append([]string{}, cfg.Outposts...), discoverFlags.outposts...))
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if len(outposts) > 0 && discoverFlags.source != "s3" && discoverFlags.source != "" {
+		return nil, 0, fmt.Errorf("discovering Outposts buckets requires --source s3")
+	}
+
+	switch discoverFlags.source {
+	case "aws-config":
+		if discoverFlags.configAggregator == "" {
+			return nil, 0, fmt.Errorf("--config-aggregator is required with --source aws-config")
+		}
+		source := s3.NewConfigSource(s3Client, discoverFlags.configAggregator)
+		if len(discoverFlags.regions) > 0 {
+			source.SetRegions(discoverFlags.regions)
+		}
+		printStatus("Reading bucket inventory from AWS Config aggregator: %s", discoverFlags.configAggregator)
+		buckets, err := source.DiscoverAllBuckets(ctx)
+		if err != nil {
+			return nil, 0, enhanceError("bucket discovery", err, discoverFlags.maxConcurrency)
+		}
+		filter.Apply(buckets)
+		s3.FilterByTags(buckets, tagFilters)
+		return buckets, 0, nil
+	case "s3", "", "resource-explorer":
+	default:
+		return nil, 0, fmt.Errorf("unsupported source: %s (supported: s3, aws-config, resource-explorer)", discoverFlags.source)
+	}
+	if len(outposts) > 0 && discoverFlags.sampleBuckets > 0 {
+		// Outposts buckets are not part of the sampled population
+		return nil, 0, fmt.Errorf("--sample-buckets cannot be combined with Outposts discovery")
+	}
+	if discoverFlags.sampleBuckets > 0 && discoverFlags.source != "s3" && discoverFlags.source != "" {
+		return nil, 0, fmt.Errorf("--sample-buckets requires --source s3")
+	}
+	if discoverFlags.sampleBuckets > 0 && len(tagFilters) > 0 {
+		// Tag filtering after sampling would skew the extrapolated totals
+		return nil, 0, fmt.Errorf("--sample-buckets cannot be combined with --tag-filter")
+	}
+
+	// Configure inspector
+	inspector := s3.NewInspector(s3Client, discoverFlags.maxConcurrency)
+	inspector.SetBucketFilter(filter)
+	inspector.SetTagFilters(tagFilters)
+	inspector.SetSampleSize(discoverFlags.sampleBuckets)
+	inspector.SetCheckpoint(checkpoint)
+	inspector.SetProgress(progress)
+	inspector.SetBucketTimeout(discoverFlags.timeouts.perBucket)
+	inspector.SetBucketDeadline(discoverFlags.timeouts.deadline)
+	inspector.SetVersionSampling(discoverFlags.versionSampling)
+	inspector.SetDeepSize(discoverFlags.deepSize)
+	inspector.SetMetricsSource(discoverFlags.metricsSource)
+	inspector.SetInventoryReports(reports)
+	inspector.SetCheckPublic(discoverFlags.checkPublic)
+	inspector.SetCheckPolicy(discoverFlags.checkPolicy)
+	inspector.SetCheckLogging(discoverFlags.checkLogging)
+	inspector.SetAccountID(account.id)
+	inspector.SetBeforeInspect(func(buckets int) {
+		warnInspectionCost(buckets, discoverFlags.versionSampling, discoverFlags.deepSize)
+	})
+	inspector.SetOutposts(outposts)
+	if discoverFlags.checkPublic && discoverFlags.aws.endpointURL == "" {
+		// The account's block overrides public ACLs and policies of every
+		// bucket, so buckets it protects are not reported public
+		block, err := inspector.AccountPublicAccessBlock(ctx, account.id)
+		if err != nil {
+			slog.Warn("Judging public access without the account public access block",
+				slog.String("account", account.id), slog.String("error", err.Error()))
+		} else {
+			inspector.SetAccountPublicAccess(block)
+		}
+	}
+
+	// Set up regions
+	if len(discoverFlags.regions) > 0 {
+		inspector.SetRegions(discoverFlags.regions)
+		printStatus("Discovering buckets in regions: %s", strings.Join(discoverFlags.regions, ", "))
+	} else if discoverFlags.allRegions {
+		inspector.SetAllRegions(true)
+		printStatus("Discovering buckets across all enabled AWS regions")
+	} else {
+		region := discoverFlags.awsRegion
+		if region == "" {
+			region = s3Client.GetRegion()
+		}
+		printStatus("Discovering buckets in region: %s", region)
+	}
+
+	// Set up progress callback
+	if showProgress {
+		inspector.SetProgressCallback(func(current, total int, message string) {
+			if total > 0 {
+				slog.Debug("Discovery progress", slog.Int("current", current), slog.Int("total", total), slog.String("message", message))
+			} else {
+				slog.Debug("Discovery progress", slog.String("message", message))
+			}
+		})
+	}
+
+	if discoverFlags.source == "resource-explorer" {
+		source := s3.NewResourceExplorerSource(s3Client, inspector)
+		// Filtered in the search too, so non-matching buckets are not inspected
+		source.SetTagFilters(tagFilters)
+		source.SetRegions(discoverFlags.regions)
+		if discoverFlags.explorerView != "" {
+			source.SetViewARN(discoverFlags.explorerView)
+		}
+		printStatus("Searching Resource Explorer for S3 buckets...")
+		buckets, err := source.DiscoverAllBuckets(ctx)
+		if err != nil {
+			return nil, 0, enhanceError("bucket discovery", err, discoverFlags.maxConcurrency)
+		}
+		return buckets, 0, nil
+	}
+
+	// Discover all buckets
+	printStatus("Discovering S3 buckets...")
+	buckets, err := inspector.DiscoverAllBuckets(ctx)
+	if err != nil {
+		return nil, 0, enhanceError("bucket discovery", err, discoverFlags.maxConcurrency)
+	}
+	return buckets, inspector.Population(), nil
+}
+
+// readInventoryReports reads the --inventory-manifest reports by source
+// bucket. A report that cannot be read is skipped with a warning, and its
+// bucket is listed as usual.
+func readInventoryReports(ctx context.Context, s3Client *s3.Client) map[string]*s3.InventoryReport {
+	if len(discoverFlags.inventoryReports) == 0 {
+		return nil
+	}
+	inspector := s3.NewInspector(s3Client, discoverFlags.maxConcurrency)
+	reports := make(map[string]*s3.InventoryReport)
+	for _, manifest := range discoverFlags.inventoryReports {
+		printStatus("Reading S3 Inventory report %s...", manifest)
+		report, err := inspector.ReadInventoryReport(ctx, manifest)
+		if interrupted(ctx) {
+			break
+		}
+		if err != nil {
+			slog.Warn("Skipping S3 Inventory report", slog.String("manifest", manifest), slog.String("error", err.Error()))
+			continue
+		}
+		reports[report.SourceBucket] = report
+	}
+	return reports
+}
+
+// exportInventory writes the raw bucket inventory to path
+func exportInventory(path, format string, buckets map[string]*s3.BucketInfo) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := inventory.Write(f, format, buckets); err != nil {
+		_ = f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// loadPlugins combines plugins declared in the config file with --plugin executables
+func loadPlugins() ([]plugin.Plugin, error) {
+	plugins, err := configPlugins()
+	if err != nil {
+		return nil, err
+	}
+	for _, command := range discoverFlags.plugins {
+		plugins = append(plugins, plugin.Plugin{Command: command})
+	}
+	return plugins, nil
+}
+
+// configPlugins returns the plugins declared in the config file
+func configPlugins() ([]plugin.Plugin, error) {
+	var plugins []plugin.Plugin
+	for _, pc := range cfg.Plugins {
+		if pc.Command == "" {
+			return nil, fmt.Errorf("plugin %q in config has no command", pc.Name)
+		}
+		p := plugin.Plugin{Name: pc.Name, Command: pc.Command, Args: pc.Args}
+		if pc.Timeout != "" {
+			d, err := time.ParseDuration(pc.Timeout)
+			if err != nil {
+				return nil, fmt.Errorf("plugin %q: invalid timeout %q: %w", pc.Name, pc.Timeout, err)
+			}
+			p.Timeout = d
+		}
+		plugins = append(plugins, p)
+	}
+	return plugins, nil
+}
+
+func pluginNames(plugins []plugin.Plugin) []string {
+	var names []string
+	for _, p := range plugins {
+		names = append(names, p.DisplayName())
+	}
+	return names
+}
+
 func applyConfigToDiscoverFlags(cmd *cobra.Command) {
+	if !cmd.Flags().Lookup("ca-bundle").Changed && cfg.CABundle != "" {
+		discoverFlags.aws.http.CABundle = cfg.CABundle
+	}
+	discoverFlags.aws.endpoints = cfg.Endpoints
 	if !cmd.Flags().Lookup("aws-region").Changed && cfg.Region != "" {
 		discoverFlags.awsRegion = cfg.Region
 	}
+	if !cmd.Flags().Lookup("inactive-days").Changed && cfg.InactiveDays > 0 {
+		discoverFlags.inactiveDays = cfg.InactiveDays
+	}
 	if !cmd.Flags().Lookup("format").Changed && cfg.Format != "" {
 		discoverFlags.outputFormat = cfg.Format
 	}
@@ -243,4 +969,41 @@ func applyConfigToDiscoverFlags(cmd *cobra.Command) {
 			discoverFlags.timeout = d
 		}
 	}
+	applyNotifyConfig(cmd, &discoverFlags.notify)
+}
+
+// warnUnattributedSpend warns when no discovered bucket could be given a
+// spend, which usually means the tag is not a cost allocation tag yet
+func warnUnattributedSpend(spend *analyzer.SpendSummary) {
+	if spend.Buckets > 0 || spend.Total == 0 {
+		return
+	}
+	slog.Warn(fmt.Sprintf("No S3 spend matched a bucket's %s tag; activate it as a cost allocation tag in the Billing console", spend.TagKey),
+		slog.String("untagged", fmt.Sprintf("%.2f %s", spend.Untagged, spend.Currency)),
+	)
+}
+
+// awsOnlyChecks lists the set discover flags that call AWS services other
+// than S3, which an S3-compatible store behind --endpoint-url does not have
+func awsOnlyChecks() []string {
+	var checks []string
+	if discoverFlags.metricsSource == s3.MetricsSourceCloudWatch {
+		checks = append(checks, "--metrics-source cloudwatch")
+	}
+	for _, check := range []struct {
+		flag string
+		set  bool
+	}{
+		{"--check-logging", discoverFlags.checkLogging},
+		{"--check-guardduty", discoverFlags.checkGuardDuty},
+		{"--check-access", discoverFlags.checkAccess},
+		{"--check-access-analyzer", discoverFlags.checkAnalyzer},
+		{"--with-macie", discoverFlags.withMacie},
+		{"--with-costs", discoverFlags.withCosts},
+	} {
+		if check.set {
+			checks = append(checks, check.flag)
+		}
+	}
+	return checks
 }
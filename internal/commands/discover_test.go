@@ -64,3 +64,27 @@ func TestDiscoverSelectReporter(t *testing.T) {
 		t.Fatalf("unexpected error message: %v", err)
 	}
 }
+
+func TestAWSOnlyChecks(t *testing.T) {
+	saved := discoverFlags
+	defer func() { discoverFlags = saved }()
+
+	discoverFlags.metricsSource = "list"
+	discoverFlags.checkLogging = false
+	discoverFlags.checkGuardDuty = false
+	discoverFlags.checkAccess = false
+	discoverFlags.checkAnalyzer = false
+	discoverFlags.withMacie = false
+	discoverFlags.withCosts = false
+	if checks := awsOnlyChecks(); len(checks) != 0 {
+		t.Fatalf("expected no AWS-only checks by default, got %v", checks)
+	}
+
+	discoverFlags.metricsSource = "cloudwatch"
+	discoverFlags.checkLogging = true
+	discoverFlags.withMacie = true
+	got := strings.Join(awsOnlyChecks(), ", ")
+	if want := "--metrics-source cloudwatch, --check-logging, --with-macie"; got != want {
+		t.Errorf("awsOnlyChecks() = %q, want %q", got, want)
+	}
+}
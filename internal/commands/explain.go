@@ -0,0 +1,78 @@
+package commands
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"text/tabwriter"
+
+	"github.com/ppiankov/s3spectre/internal/rules"
+	"github.com/spf13/cobra"
+)
+
+var explainCmd = &cobra.Command{
+	Use:   "explain [RULE]",
+	Short: "Describe a rule: rationale, detection, thresholds and remediation",
+	Long: `Prints a rule's rationale, detection logic, the thresholds in effect
+(defaults merged with .s3spectre.yaml), its severity after overrides,
+remediation guidance and related AWS documentation. RULE may be a rule name
+(VERSION_SPRAWL), a SARIF rule ID (s3spectre/VERSION_SPRAWL) or a status
+(LIFECYCLE_MISCONFIG). Without RULE, lists every rule.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runExplain,
+}
+
+func runExplain(cmd *cobra.Command, args []string) error {
+	w := cmd.OutOrStdout()
+	if len(args) == 0 {
+		return writeRuleList(w)
+	}
+	rule, ok := rules.Lookup(args[0])
+	if !ok {
+		return fmt.Errorf("unknown rule %q (run 's3spectre explain' to list rules)", args[0])
+	}
+	writeExplanation(w, rule)
+	return nil
+}
+
+func writeRuleList(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(tw, "RULE\tCATEGORY\tSEVERITY\tDESCRIPTION")
+	for _, rule := range rules.All() {
+		_, _ = fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", rule.ID, rule.Category, rule.Severity(), rule.Description)
+	}
+	return tw.Flush()
+}
+
+func writeExplanation(w io.Writer, rule rules.Rule) {
+	_, _ = fmt.Fprintf(w, "%s (%s)\n", rule.SARIFID(), rule.Name)
+	_, _ = fmt.Fprintf(w, "%s\n\n", rule.Description)
+	_, _ = fmt.Fprintf(w, "Category: %s\nSeverity: %s\n\n", rule.Category, rule.Severity())
+	_, _ = fmt.Fprintf(w, "Why it matters:\n  %s\n\n", rule.Rationale)
+	_, _ = fmt.Fprintf(w, "Detection:\n  %s\n\n", rule.Detection)
+	if len(rule.Thresholds) > 0 {
+		_, _ = fmt.Fprintln(w, "Thresholds in effect:")
+		for _, t := range rule.Thresholds {
+			_, _ = fmt.Fprintf(w, "  %s = %s  (%s)\n", t.Flag, thresholdValue(t), t.Description)
+		}
+		_, _ = fmt.Fprintln(w)
+	}
+	_, _ = fmt.Fprintf(w, "Remediation:\n  %s\n", rule.Remediation)
+	if len(rule.References) > 0 {
+		_, _ = fmt.Fprintln(w, "\nAWS documentation:")
+		for _, ref := range rule.References {
+			_, _ = fmt.Fprintf(w, "  %s\n", ref)
+		}
+	}
+}
+
+// thresholdValue returns a threshold's config file value, or its default
+func thresholdValue(t rules.Threshold) string {
+	switch t.ConfigKey {
+	case "stale_days":
+		if cfg.StaleDays > 0 {
+			return strconv.Itoa(cfg.StaleDays) + " (from config)"
+		}
+	}
+	return t.Default
+}
@@ -0,0 +1,42 @@
+package commands
+
+import (
+	"bytes"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/ppiankov/s3spectre/internal/config"
+)
+
+func TestRunExplain(t *testing.T) {
+	saved := cfg
+	t.Cleanup(func() { cfg = saved })
+	cfg = config.Config{StaleDays: 30}
+
+	var buf bytes.Buffer
+	explainCmd.SetOut(&buf)
+	t.Cleanup(func() { explainCmd.SetOut(nil) })
+
+	if err := runExplain(explainCmd, []string{"stale_prefix"}); err != nil {
+		t.Fatalf("runExplain: %v", err)
+	}
+	out := buf.String()
+	for _, want := range []string{"s3spectre/STALE_PREFIX", "Severity: low", "--stale-days = 30 (from config)", "Remediation:", "docs.aws.amazon.com"} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("expected output to contain %q, got:\n%s", want, out)
+		}
+	}
+
+	buf.Reset()
+	if err := runExplain(explainCmd, nil); err != nil {
+		t.Fatalf("runExplain list: %v", err)
+	}
+	if !regexp.MustCompile(`VERSION_SPRAWL +cost`).MatchString(buf.String()) {
+		t.Fatalf("expected rule list, got:\n%s", buf.String())
+	}
+
+	if err := runExplain(explainCmd, []string{"NOPE"}); err == nil {
+		t.Fatalf("expected error for unknown rule")
+	}
+}
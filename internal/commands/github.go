@@ -0,0 +1,108 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+
+	"github.com/ppiankov/s3spectre/internal/analyzer"
+	"github.com/ppiankov/s3spectre/internal/report"
+	"github.com/ppiankov/s3spectre/internal/rules"
+	"github.com/ppiankov/s3spectre/internal/tracker"
+	"github.com/spf13/pflag"
+)
+
+// githubTokenEnvs are read in order for the GitHub token; GITHUB_TOKEN is
+// what GitHub Actions provides
+var githubTokenEnvs = []string{"S3SPECTRE_GITHUB_TOKEN", "GITHUB_TOKEN", "GH_TOKEN"}
+
+// githubFlags configures filing findings as GitHub issues
+type githubFlags struct {
+	repo     string
+	severity string
+	label    string
+	apiURL   string
+}
+
+// addGitHubFlags registers the GitHub issue flags shared by scan and discover
+func addGitHubFlags(fs *pflag.FlagSet, f *githubFlags) {
+	fs.StringVar(&f.repo, "github-repo", "", "Open an issue per bucket with findings in this GitHub repository (owner/name), and close it once they are resolved; the token is read from "+githubTokenEnvs[0]+" or GITHUB_TOKEN")
+	fs.StringVar(&f.severity, "github-severity", rules.SeverityMedium, "Lowest severity filed as GitHub issues: high, medium, low, or info")
+	fs.StringVar(&f.label, "github-label", tracker.DefaultLabel, "Label of the GitHub issues s3spectre files and manages")
+	fs.StringVar(&f.apiURL, "github-api-url", tracker.DefaultGitHubAPI, "GitHub REST API URL, e.g. https://github.example.com/api/v3 for GitHub Enterprise Server")
+}
+
+func (f githubFlags) validate() error {
+	if f.repo == "" {
+		return nil
+	}
+	if !rules.ValidSeverity(f.severity) {
+		return fmt.Errorf("invalid --github-severity %q: expected high, medium, low, or info", f.severity)
+	}
+	if f.label == "" {
+		return fmt.Errorf("--github-label must not be empty")
+	}
+	_, err := f.client()
+	return err
+}
+
+// client builds the issue filer from the flags and the token in the
+// environment
+func (f githubFlags) client() (*tracker.GitHub, error) {
+	token := ""
+	for _, env := range githubTokenEnvs {
+		if token = os.Getenv(env); token != "" {
+			break
+		}
+	}
+	if token == "" {
+		return nil, fmt.Errorf("--github-repo requires a token: set %s or GITHUB_TOKEN", githubTokenEnvs[0])
+	}
+	gh, err := tracker.NewGitHub(f.repo, token)
+	if err != nil {
+		return nil, fmt.Errorf("--github-repo: %w", err)
+	}
+	gh.Label = f.label
+	gh.APIURL = f.apiURL
+	return gh, nil
+}
+
+// completeRun reports whether a run inspected everything in its scope. An
+// interrupted or sampled run, or one whose buckets timed out or skipped
+// checks at the deadline, does not show that missing findings were resolved.
+func completeRun(truncated *report.Truncation, sampled bool, timedOut []string, deadlineSkips []analyzer.DeadlineSkip) bool {
+	return truncated == nil && !sampled && len(timedOut) == 0 && len(deadlineSkips) == 0
+}
+
+// fileGitHubIssues syncs one issue per bucket with the findings at or above
+// the flags' severity. target is what the run covered, such as the scanned
+// repository, so runs over different targets keep separate issues. Issues
+// are closed only after a complete run, since a partial one does not show
+// that findings were resolved. Failures are logged rather than failing the
+// run.
+func fileGitHubIssues(ctx context.Context, f githubFlags, command, account, target string, findings []report.Finding, complete bool) {
+	gh, err := f.client()
+	if err != nil {
+		slog.Warn("GitHub issue filing skipped", "error", err)
+		return
+	}
+	var filed []report.Finding
+	for _, finding := range findings {
+		if rules.SeverityAtLeast(finding.Severity, f.severity) {
+			filed = append(filed, finding)
+		}
+	}
+	result, err := gh.Sync(context.WithoutCancel(ctx), tracker.Scope(command, account, target), tracker.GroupFindings(filed), complete)
+	if err != nil {
+		slog.Warn("GitHub issue filing failed", "repo", f.repo, "error", err)
+		return
+	}
+	slog.Info("Synced GitHub issues",
+		slog.String("repo", f.repo),
+		slog.Int("opened", result.Opened),
+		slog.Int("updated", result.Updated),
+		slog.Int("unchanged", result.Unchanged),
+		slog.Int("closed", result.Closed),
+	)
+}
@@ -0,0 +1,55 @@
+package commands
+
+import (
+	"testing"
+
+	"github.com/ppiankov/s3spectre/internal/analyzer"
+	"github.com/ppiankov/s3spectre/internal/report"
+)
+
+func TestGitHubFlags_Validate(t *testing.T) {
+	for _, env := range githubTokenEnvs {
+		t.Setenv(env, "")
+	}
+	f := githubFlags{severity: "medium", label: "s3spectre", apiURL: "https://api.github.com"}
+	if err := f.validate(); err != nil {
+		t.Errorf("disabled filing needs no token: %v", err)
+	}
+
+	f.repo = "acme/infra"
+	if err := f.validate(); err == nil {
+		t.Error("expected error without a token")
+	}
+
+	t.Setenv("GITHUB_TOKEN", "secret")
+	if err := f.validate(); err != nil {
+		t.Errorf("GITHUB_TOKEN should be used: %v", err)
+	}
+
+	bad := f
+	bad.repo = "infra"
+	if err := bad.validate(); err == nil {
+		t.Error("expected error for a repository without an owner")
+	}
+	bad = f
+	bad.severity = "urgent"
+	if err := bad.validate(); err == nil {
+		t.Error("expected error for unknown --github-severity")
+	}
+}
+
+func TestCompleteRun(t *testing.T) {
+	if !completeRun(nil, false, nil, nil) {
+		t.Error("a full run should be complete")
+	}
+	for name, complete := range map[string]bool{
+		"truncated": completeRun(&report.Truncation{}, false, nil, nil),
+		"sampled":   completeRun(nil, true, nil, nil),
+		"timed out": completeRun(nil, false, []string{"logs"}, nil),
+		"deadline":  completeRun(nil, false, nil, []analyzer.DeadlineSkip{{Bucket: "logs"}}),
+	} {
+		if complete {
+			t.Errorf("a %s run should not close issues", name)
+		}
+	}
+}
@@ -1,18 +1,58 @@
 package commands
 
 import (
+	"bufio"
+	"context"
+	"errors"
 	"fmt"
 	"io"
 	"log/slog"
+	"os"
+	"os/signal"
+	"sort"
 	"strings"
+	"syscall"
+	"time"
 
+	"github.com/ppiankov/s3spectre/internal/analyzer"
+	"github.com/ppiankov/s3spectre/internal/baseline"
 	"github.com/ppiankov/s3spectre/internal/report"
+	"github.com/ppiankov/s3spectre/internal/rules"
+	"github.com/ppiankov/s3spectre/internal/s3"
+	"github.com/ppiankov/s3spectre/internal/scanner"
+	"github.com/ppiankov/s3spectre/internal/spectre"
+	"github.com/spf13/pflag"
 )
 
 func printStatus(format string, args ...interface{}) {
 	slog.Info(fmt.Sprintf(format, args...))
 }
 
+// logSkippedFiles reports, in verbose mode, how many files the repository
+// scan skipped by reason, and each file skipped for a reason other than its
+// extension
+func logSkippedFiles(files []scanner.SkippedFile) {
+	counts := scanner.CountSkipped(files)
+	if len(counts) == 0 {
+		return
+	}
+	for _, f := range files {
+		if f.Reason != scanner.SkipUnknownExtension {
+			slog.Debug("Skipped file", "path", f.Path, "reason", f.Reason)
+		}
+	}
+	reasons := make([]string, 0, len(counts))
+	for reason := range counts {
+		reasons = append(reasons, reason)
+	}
+	sort.Strings(reasons)
+	attrs := make([]any, 0, len(reasons))
+	for _, reason := range reasons {
+		attrs = append(attrs, slog.Int(reason, counts[reason]))
+	}
+	slog.Info(fmt.Sprintf("Skipped %d files", len(files)), attrs...)
+}
+
 // enhanceError enhances an error with additional context and helpful suggestions
 func enhanceError(operation string, err error, concurrency int) error {
 	if err == nil {
@@ -68,9 +108,476 @@ func selectReporter(format string, writer io.Writer) (report.Reporter, error) {
 		return report.NewSARIFReporter(writer), nil
 	case "spectrehub":
 		return report.NewSpectreHubReporter(writer), nil
+	case "lsp-diagnostics":
+		return report.NewLSPReporter(writer), nil
+	case "tasks":
+		return report.NewTasksReporter(writer), nil
+	case "csv":
+		return report.NewCSVReporter(writer), nil
+	case "markdown":
+		return report.NewMarkdownReporter(writer), nil
 	case "text":
 		return report.NewTextReporter(writer), nil
 	default:
-		return nil, fmt.Errorf("unsupported output format: %s (supported: text, json, sarif, spectrehub)", format)
+		return nil, fmt.Errorf("unsupported output format: %s (supported: text, json, sarif, spectrehub, lsp-diagnostics, tasks, csv, markdown)", format)
+	}
+}
+
+// validateSeverityGate rejects unknown --fail-on-severity values before any AWS calls
+func validateSeverityGate(threshold string) error {
+	if threshold != "" && !rules.ValidSeverity(threshold) {
+		return fmt.Errorf("invalid --fail-on-severity %q (expected high, medium, low or info)", threshold)
+	}
+	return nil
+}
+
+// checkSeverityGate fails when any finding is at or above the threshold
+// severity, after config severity overrides
+func checkSeverityGate(findings []baseline.Finding, threshold string) error {
+	if threshold == "" {
+		return nil
+	}
+	count := 0
+	for _, f := range findings {
+		if rules.SeverityAtLeast(f.EffectiveSeverity(), threshold) {
+			count++
+		}
+	}
+	if count > 0 {
+		return fmt.Errorf("found %d findings at or above %s severity", count, threshold)
+	}
+	return nil
+}
+
+// awsClientFlags holds the credential and transport flags shared by scan and discover
+type awsClientFlags struct {
+	assumeRole s3.AssumeRole
+	ssoLogin   bool
+	http       s3.HTTPOptions
+	// accountRole is assumed in each of accounts, on top of assumeRole
+	accountRole string
+	accounts    []string
+	// org targets every account in the caller's organization (discover only)
+	org bool
+	// endpointURL points S3 at an S3-compatible store (MinIO, Ceph, R2)
+	endpointURL string
+	pathStyle   bool
+	// endpoints and backoff come from the config file only
+	endpoints map[string]string
+	backoff   *s3.Backoff
+}
+
+// addAWSClientFlags registers the AWS client flags shared by scan and discover
+func addAWSClientFlags(fs *pflag.FlagSet, f *awsClientFlags) {
+	fs.StringVar(&f.assumeRole.RoleARN, "role-arn", "", "IAM role to assume before calling AWS (e.g. a cross-account audit role)")
+	fs.StringVar(&f.assumeRole.ExternalID, "external-id", "", "External ID required by the role's trust policy")
+	fs.StringVar(&f.assumeRole.MFASerial, "mfa-serial", "", "ARN of the MFA device required by the role's trust policy")
+	fs.StringVar(&f.assumeRole.MFAToken, "mfa-token", "", "Current MFA code (prompted on stdin when --mfa-serial is set without it)")
+	fs.StringVar(&f.assumeRole.SessionName, "role-session-name", "s3spectre", "Session name recorded in CloudTrail for the assumed role")
+	fs.DurationVar(&f.assumeRole.Duration, "role-session-duration", s3.DefaultRoleSessionDuration, "Assumed-role session duration (15m to the role's maximum)")
+	fs.StringVar(&f.accountRole, "assume-role-arn", "", "Role to assume in each --accounts account: a role ARN, whose account ID is replaced, or a role name")
+	fs.StringSliceVar(&f.accounts, "accounts", nil, "AWS account IDs to inspect by assuming --assume-role-arn (comma-separated)")
+	fs.BoolVar(&f.ssoLogin, "sso-login", false, "Sign in with an SSO device code when the profile's SSO session has expired")
+	fs.StringVar(&f.http.CABundle, "ca-bundle", "", "PEM file of extra root CAs to trust, e.g. for a TLS-intercepting proxy")
+	fs.IntVar(&f.http.MaxIdleConnsPerHost, "max-idle-conns-per-host", 0, "Kept-alive connections per AWS endpoint (0 matches --concurrency)")
+	fs.IntVar(&f.http.MaxConnsPerHost, "max-conns-per-host", 0, "Maximum connections per AWS endpoint (0 means no limit)")
+	fs.StringVar(&f.endpointURL, "endpoint-url", "", "Send S3 calls to this S3-compatible endpoint (MinIO, Ceph, Cloudflare R2) instead of AWS")
+	fs.BoolVar(&f.pathStyle, "path-style", false, "Address buckets in the URL path instead of the host name, for endpoints without wildcard DNS")
+}
+
+// validate rejects inconsistent AWS client flags before any AWS calls
+func (f awsClientFlags) validate() error {
+	if err := f.assumeRole.Validate(); err != nil {
+		return err
+	}
+	if err := f.validateAccounts(); err != nil {
+		return err
+	}
+	if err := s3.ValidateEndpoints(f.endpoints); err != nil {
+		return err
+	}
+	if err := s3.ValidateEndpointURL(f.endpointURL); err != nil {
+		return err
+	}
+	if f.endpointURL != "" && (f.org || len(f.accounts) > 0) {
+		// Accounts are AWS accounts; an S3-compatible store has none
+		return fmt.Errorf("--endpoint-url cannot be combined with --accounts or --org")
+	}
+	return f.http.Validate()
+}
+
+// clientOptions builds the S3 client options for the AWS client flags
+func (f awsClientFlags) clientOptions(concurrency int) []s3.ClientOption {
+	httpOpts := f.http
+	if httpOpts.MaxIdleConnsPerHost == 0 {
+		// Keep one pooled connection per worker so bursts reuse TLS sessions
+		httpOpts.MaxIdleConnsPerHost = concurrency
+	}
+	opts := []s3.ClientOption{
+		s3.WithAssumeRole(f.assumeRole),
+		s3.WithHTTPOptions(httpOpts),
+		s3.WithEndpoints(f.endpoints),
+		s3.WithEndpointURL(f.endpointURL),
+		s3.WithPathStyle(f.pathStyle),
+	}
+	if f.backoff != nil {
+		opts = append(opts, s3.WithBackoff(*f.backoff))
+	}
+	if f.ssoLogin {
+		opts = append(opts, s3.WithSSOLogin(os.Stderr))
+	}
+	return opts
+}
+
+// checkpointFlags configures the credential watchdog and resumable checkpoints
+type checkpointFlags struct {
+	path   string
+	resume bool
+	margin time.Duration
+}
+
+// addCheckpointFlags registers the checkpoint flags shared by scan and discover
+func addCheckpointFlags(fs *pflag.FlagSet, f *checkpointFlags) {
+	fs.StringVar(&f.path, "checkpoint", ".s3spectre-checkpoint.json", "Checkpoint file written when credentials expire mid-run")
+	fs.BoolVar(&f.resume, "resume", false, "Resume from --checkpoint, skipping buckets it already holds")
+	fs.DurationVar(&f.margin, "credential-margin", s3.DefaultCredentialMargin, "Refresh credentials, or checkpoint and exit, this long before they expire (0 disables)")
+}
+
+// openCheckpoint loads the checkpoint to resume from, or starts an empty one
+func openCheckpoint(command string, f checkpointFlags) (*s3.Checkpoint, error) {
+	if !f.resume {
+		return s3.NewCheckpoint(command), nil
+	}
+	cp, err := s3.LoadCheckpoint(f.path, command)
+	if err != nil {
+		return nil, err
+	}
+	printStatus("Resuming from %s: %d buckets already inspected", f.path, cp.Len())
+	return cp, nil
+}
+
+// watchCredentials starts the credential expiry watchdog unless disabled
+func watchCredentials(ctx context.Context, client *s3.Client, f checkpointFlags) (context.Context, context.CancelFunc) {
+	if f.margin <= 0 {
+		return ctx, func() {}
+	}
+	watchdog := s3.NewCredentialWatchdog(client, f.margin)
+	watchdog.SetRefreshCallback(func(expires time.Time) {
+		slog.Info("Refreshed AWS credentials", slog.Time("expires", expires))
+	})
+	watchdog.SetRetryCallback(func(err error) {
+		slog.Warn("Could not retrieve AWS credentials; retrying at the next check", slog.String("error", err.Error()))
+	})
+	return watchdog.Watch(ctx)
+}
+
+// timeoutFlags bounds the inspection phase and each bucket within it, so a
+// hung bucket is skipped instead of running into the total --timeout
+type timeoutFlags struct {
+	inspect   time.Duration
+	perBucket time.Duration
+	// deadline skips a slow bucket's remaining checks but keeps the ones
+	// that finished
+	deadline time.Duration
+}
+
+// addTimeoutFlags registers the inspection budgets shared by scan and discover
+func addTimeoutFlags(fs *pflag.FlagSet, f *timeoutFlags) {
+	fs.DurationVar(&f.inspect, "inspect-timeout", 0, "Budget for the bucket inspection phase; buckets left when it runs out are skipped (0 means none)")
+	fs.DurationVar(&f.perBucket, "per-bucket-timeout", 0, "Budget for inspecting one bucket; its remaining checks are skipped when it runs out (0 means none)")
+	fs.DurationVar(&f.deadline, "per-bucket-deadline", 0, "Deadline for one bucket's checks; checks that have not finished are reported as skipped (deadline) while finished ones are kept (0 means none)")
+}
+
+func (f timeoutFlags) validate() error {
+	if f.inspect < 0 || f.perBucket < 0 || f.deadline < 0 {
+		return fmt.Errorf("--inspect-timeout, --per-bucket-timeout and --per-bucket-deadline must not be negative")
+	}
+	if f.perBucket > 0 && f.deadline > 0 {
+		// Both bound the same bucket: the timeout marks it timed_out, the
+		// deadline keeps its finished checks. Neither wins silently.
+		return fmt.Errorf("--per-bucket-timeout and --per-bucket-deadline cannot be combined; use --per-bucket-deadline to keep the checks that finished")
+	}
+	return nil
+}
+
+// phaseContext bounds one phase of a run; d <= 0 leaves it unbounded
+func phaseContext(ctx context.Context, d time.Duration) (context.Context, context.CancelFunc) {
+	if d > 0 {
+		return context.WithTimeout(ctx, d)
+	}
+	return context.WithCancel(ctx)
+}
+
+// warnTimedOut reports buckets whose checks were skipped for lack of time
+func warnTimedOut(buckets []string) {
+	if len(buckets) == 0 {
+		return
+	}
+	slog.Warn("Bucket inspection timed out; remaining checks were skipped",
+		slog.Int("count", len(buckets)),
+		slog.String("buckets", strings.Join(buckets, ", ")),
+	)
+}
+
+// warnPermissionsUnknown reports buckets whose access was not simulated
+func warnPermissionsUnknown(buckets []string) {
+	if len(buckets) == 0 {
+		return
+	}
+	slog.Warn("Could not read bucket policies; permission checks are unknown",
+		slog.Int("count", len(buckets)),
+		slog.String("buckets", strings.Join(buckets, ", ")),
+	)
+}
+
+// warnDeadlineSkips reports buckets whose checks were skipped at the
+// per-bucket deadline
+func warnDeadlineSkips(skips []analyzer.DeadlineSkip) {
+	if len(skips) == 0 {
+		return
+	}
+	buckets := make([]string, 0, len(skips))
+	for _, skip := range skips {
+		buckets = append(buckets, skip.Bucket)
+	}
+	slog.Warn("Bucket checks skipped at the per-bucket deadline",
+		slog.Int("count", len(skips)),
+		slog.String("buckets", strings.Join(buckets, ", ")),
+	)
+}
+
+// ExitCodeInterrupted is the exit status of a run stopped by SIGINT or
+// SIGTERM, following the shell convention of 128 + SIGINT
+const ExitCodeInterrupted = 130
+
+// ExitError is a command error that exits with a status other than 1
+type ExitError struct {
+	Code int
+	Err  error
+}
+
+func (e *ExitError) Error() string { return e.Err.Error() }
+
+func (e *ExitError) Unwrap() error { return e.Err }
+
+// errInterrupted is the cancellation cause when a signal stops the run
+var errInterrupted = errors.New("interrupted by signal")
+
+// notifyInterrupt cancels ctx on the first SIGINT or SIGTERM so the run can
+// write a partial report; a second signal terminates the process at once
+func notifyInterrupt(ctx context.Context) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancelCause(ctx)
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		select {
+		case sig := <-signals:
+			signal.Stop(signals)
+			slog.Warn("Interrupted; writing a partial report (signal again to exit now)", "signal", sig.String())
+			cancel(errInterrupted)
+		case <-ctx.Done():
+		}
+	}()
+	return ctx, func() {
+		signal.Stop(signals)
+		cancel(context.Canceled)
+	}
+}
+
+// interrupted reports whether a signal cancelled ctx
+func interrupted(ctx context.Context) bool {
+	return errors.Is(context.Cause(ctx), errInterrupted)
+}
+
+// interruptedBefore is the error of a run interrupted before it had anything
+// to report
+func interruptedBefore(stage string) error {
+	return &ExitError{Code: ExitCodeInterrupted, Err: fmt.Errorf("%w during %s; no report written", errInterrupted, stage)}
+}
+
+// interruptedRun saves the inspected buckets for --resume and returns the
+// error of a run that wrote a partial report
+func interruptedRun(cp *s3.Checkpoint, f checkpointFlags, truncation *report.Truncation) error {
+	msg := fmt.Sprintf("%v: wrote a partial report, %d buckets not inspected", errInterrupted, len(truncation.UninspectedBuckets))
+	if cp.Len() > 0 {
+		if err := cp.Save(f.path); err != nil {
+			slog.Warn("Could not save checkpoint", "path", f.path, "error", err)
+		} else {
+			msg += fmt.Sprintf(".\nSaved %d inspected buckets to %s; rerun with --resume to continue", cp.Len(), f.path)
+		}
+	}
+	return &ExitError{Code: ExitCodeInterrupted, Err: errors.New(msg)}
+}
+
+// checkpointOnExpiry saves progress when the watchdog stopped the run and
+// returns an error explaining how to resume; otherwise it returns nil
+func checkpointOnExpiry(ctx context.Context, cp *s3.Checkpoint, f checkpointFlags) error {
+	cause := context.Cause(ctx)
+	if !errors.Is(cause, s3.ErrCredentialsExpiring) {
+		return nil
+	}
+	if err := cp.Save(f.path); err != nil {
+		return fmt.Errorf("%v; %w", cause, err)
+	}
+	return fmt.Errorf("%v.\n"+
+		"Saved %d inspected buckets to %s.\n"+
+		"Refresh credentials and rerun with --resume to continue", cause, cp.Len(), f.path)
+}
+
+// discardCheckpoint removes a resumed checkpoint once the run has completed
+func discardCheckpoint(f checkpointFlags) {
+	if !f.resume {
+		return
+	}
+	if err := os.Remove(f.path); err != nil && !os.IsNotExist(err) {
+		slog.Warn("Could not remove checkpoint", "path", f.path, "error", err)
+	}
+}
+
+// resolveAccountID looks up the caller's account for finding fingerprints.
+// Failure is not fatal: fingerprints then omit the account. S3-compatible
+// stores have no AWS account, so none is looked up for them.
+func resolveAccountID(ctx context.Context, client *s3.Client) string {
+	if client.CustomEndpoint() {
+		return ""
+	}
+	accountID, err := client.AccountID(ctx)
+	if err != nil {
+		slog.Warn("Could not resolve AWS account ID; fingerprints will omit it", "error", err)
+		return ""
+	}
+	return accountID
+}
+
+// retryBackoff builds the retry schedule from the config file; nil when
+// none is configured
+func retryBackoff() (*s3.Backoff, error) {
+	if cfg.Retry.IsZero() {
+		return nil, nil
+	}
+	backoff := s3.DefaultBackoff
+	var err error
+	if cfg.Retry.BaseDelay != "" {
+		if backoff.Base, err = time.ParseDuration(cfg.Retry.BaseDelay); err != nil {
+			return nil, fmt.Errorf("invalid retry config: base_delay: %w", err)
+		}
+	}
+	if cfg.Retry.MaxDelay != "" {
+		if backoff.Max, err = time.ParseDuration(cfg.Retry.MaxDelay); err != nil {
+			return nil, fmt.Errorf("invalid retry config: max_delay: %w", err)
+		}
+	}
+	if cfg.Retry.Multiplier != 0 {
+		backoff.Multiplier = cfg.Retry.Multiplier
+	}
+	if cfg.Retry.Jitter != nil {
+		backoff.Jitter = *cfg.Retry.Jitter
+	}
+	if err := backoff.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid retry config: %w", err)
+	}
+	return &backoff, nil
+}
+
+// dataClassification builds the classification weighting from the config
+// file; nil when no weights are configured
+func dataClassification() (*analyzer.Classification, error) {
+	classification, err := analyzer.NewClassification(cfg.Classification.TagKeys, cfg.Classification.Weights)
+	if err != nil {
+		return nil, fmt.Errorf("invalid classification config: %w", err)
+	}
+	return classification, nil
+}
+
+// compareBaseline diffs the current findings with the baseline report at
+// path. Text reports show the changes; for other formats they are logged.
+func compareBaseline(path, formats string, current []baseline.Finding, load func(string) ([]baseline.Finding, error)) (*report.BaselineChanges, error) {
+	baselineFindings, err := load(path)
+	if err != nil {
+		return nil, err
+	}
+	diff := baseline.Diff(current, baselineFindings)
+	if !containsFormat(formats, "text") {
+		slog.Info("Baseline comparison",
+			slog.Int("new", len(diff.New)),
+			slog.Int("resolved", len(diff.Resolved)),
+			slog.Int("unchanged", len(diff.Unchanged)),
+		)
+	}
+	return diff.Changes(path), nil
+}
+
+// externalBuckets builds the external bucket matcher from config. The public
+// dataset registry is included for references in code, unless it is
+// disabled; buckets discovered in the account are its own, whatever their
+// name.
+func externalBuckets(datasets bool) (*analyzer.ExternalBuckets, error) {
+	external, err := analyzer.NewExternalBuckets(cfg.ExternalBuckets)
+	if err != nil {
+		return nil, fmt.Errorf("invalid external_buckets config: %w", err)
+	}
+	if !datasets || cfg.PublicDatasets.Disabled {
+		return external, nil
+	}
+	external, err = external.WithPublicDatasets(cfg.PublicDatasets.Extra)
+	if err != nil {
+		return nil, fmt.Errorf("invalid public_datasets config: %w", err)
+	}
+	return external, nil
+}
+
+// loadEnvelopes reads the --correlate envelopes of other Spectre tools
+func loadEnvelopes(paths []string) ([]*spectre.Envelope, error) {
+	envelopes := make([]*spectre.Envelope, 0, len(paths))
+	for _, path := range paths {
+		envelope, err := spectre.Load(path)
+		if err != nil {
+			return nil, fmt.Errorf("--correlate: %w", err)
+		}
+		envelopes = append(envelopes, envelope)
+	}
+	return envelopes, nil
+}
+
+// testPaths builds the test path classifier from config and the given mode;
+// nil when mode is off
+func testPaths(mode string) (*analyzer.TestPaths, error) {
+	return analyzer.NewTestPaths(cfg.TestPaths.Patterns, mode)
+}
+
+// environments returns the environment names recognized in bucket names
+func environments() s3.Environments {
+	return s3.NewEnvironments(cfg.Environments)
+}
+
+// writeTuningPatch writes the suggested thresholds as config file settings,
+// each preceded by the hint that suggested it. Nothing is written when there
+// are no hints.
+func writeTuningPatch(path string, hints []analyzer.TuningHint) error {
+	if len(hints) == 0 {
+		printStatus("No threshold adjustments suggested; %s not written", path)
+		return nil
+	}
+	var b strings.Builder
+	b.WriteString("# s3spectre tuning patch: merge into .s3spectre.yaml\n")
+	for _, h := range hints {
+		fmt.Fprintf(&b, "# %s\n%s: %d\n", h.Message, h.ConfigKey, h.Suggested)
+	}
+	if err := os.WriteFile(path, []byte(b.String()), 0644); err != nil {
+		return err
+	}
+	printStatus("Wrote %d threshold suggestions to %s", len(hints), path)
+	return nil
+}
+
+// confirm asks a yes/no question and reads the answer from answers;
+// anything but y or yes is no. answered is false when the input has ended.
+func confirm(answers *bufio.Scanner, out io.Writer, question string) (yes, answered bool) {
+	_, _ = fmt.Fprint(out, question)
+	if !answers.Scan() {
+		_, _ = fmt.Fprintln(out)
+		return false, false
 	}
+	answer := strings.ToLower(strings.TrimSpace(answers.Text()))
+	return answer == "y" || answer == "yes", true
 }
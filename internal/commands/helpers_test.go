@@ -1,11 +1,23 @@
 package commands
 
 import (
+	"bufio"
 	"bytes"
+	"context"
 	"errors"
+	"fmt"
 	"log/slog"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
+	"time"
+
+	"github.com/ppiankov/s3spectre/internal/analyzer"
+	"github.com/ppiankov/s3spectre/internal/baseline"
+	"github.com/ppiankov/s3spectre/internal/config"
+	"github.com/ppiankov/s3spectre/internal/report"
+	"github.com/ppiankov/s3spectre/internal/s3"
 )
 
 func TestEnhanceError(t *testing.T) {
@@ -58,3 +70,227 @@ func TestGetVersion(t *testing.T) {
 		t.Fatalf("expected version %q, got %q", "1.2.3", GetVersion())
 	}
 }
+
+func TestLoadAPIToken(t *testing.T) {
+	t.Setenv(apiTokenEnv, "")
+	if _, err := loadAPIToken(""); err == nil {
+		t.Fatal("expected error without a token")
+	}
+
+	t.Setenv(apiTokenEnv, "from-env")
+	if token, err := loadAPIToken(""); err != nil || token != "from-env" {
+		t.Fatalf("expected env token, got %q (%v)", token, err)
+	}
+
+	path := filepath.Join(t.TempDir(), "token")
+	if err := os.WriteFile(path, []byte("from-file\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if token, err := loadAPIToken(path); err != nil || token != "from-file" {
+		t.Fatalf("expected file token, got %q (%v)", token, err)
+	}
+}
+
+func TestCheckSeverityGate(t *testing.T) {
+	findings := []baseline.Finding{
+		{Type: "MISSING_BUCKET", Bucket: "a"},
+		{Type: "STALE_PREFIX", Bucket: "b", Prefix: "old/"},
+	}
+	if err := checkSeverityGate(findings, ""); err != nil {
+		t.Fatalf("expected no gate without threshold, got %v", err)
+	}
+	err := checkSeverityGate(findings, "low")
+	if err == nil || !strings.Contains(err.Error(), "found 2 findings") {
+		t.Fatalf("expected 2 findings at or above low, got %v", err)
+	}
+	err = checkSeverityGate(findings, "medium")
+	if err == nil || !strings.Contains(err.Error(), "found 1 findings") {
+		t.Fatalf("expected 1 finding at or above medium, got %v", err)
+	}
+	if err := checkSeverityGate(findings, "high"); err != nil {
+		t.Fatalf("expected no findings at or above high, got %v", err)
+	}
+
+	if err := validateSeverityGate("urgent"); err == nil {
+		t.Fatalf("expected error for invalid threshold")
+	}
+	if err := validateSeverityGate("info"); err != nil {
+		t.Fatalf("expected info to be valid, got %v", err)
+	}
+}
+
+func TestCheckpointOnExpiry(t *testing.T) {
+	f := checkpointFlags{path: filepath.Join(t.TempDir(), "checkpoint.json")}
+	cp := s3.NewCheckpoint("scan")
+
+	if err := checkpointOnExpiry(context.Background(), cp, f); err != nil {
+		t.Fatalf("expected no error without expiry, got %v", err)
+	}
+	if _, err := os.Stat(f.path); !os.IsNotExist(err) {
+		t.Fatalf("expected no checkpoint without expiry")
+	}
+
+	ctx, cancel := context.WithCancelCause(context.Background())
+	cancel(fmt.Errorf("%w (expires soon)", s3.ErrCredentialsExpiring))
+	err := checkpointOnExpiry(ctx, cp, f)
+	if err == nil || !strings.Contains(err.Error(), "--resume") {
+		t.Fatalf("expected resume instructions, got %v", err)
+	}
+	if _, err := s3.LoadCheckpoint(f.path, "scan"); err != nil {
+		t.Fatalf("expected saved checkpoint, got %v", err)
+	}
+}
+
+func TestPhaseContext(t *testing.T) {
+	ctx, cancel := phaseContext(context.Background(), 0)
+	if _, ok := ctx.Deadline(); ok {
+		t.Fatalf("expected no deadline for a zero budget")
+	}
+	cancel()
+
+	ctx, cancel = phaseContext(context.Background(), time.Millisecond)
+	defer cancel()
+	<-ctx.Done()
+	if !errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		t.Fatalf("expected deadline exceeded, got %v", ctx.Err())
+	}
+
+	if err := (timeoutFlags{perBucket: -time.Second}).validate(); err == nil {
+		t.Fatalf("expected error for a negative budget")
+	}
+	if err := (timeoutFlags{deadline: -time.Second}).validate(); err == nil {
+		t.Fatalf("expected error for a negative deadline")
+	}
+	if err := (timeoutFlags{perBucket: time.Minute, deadline: time.Minute}).validate(); err == nil {
+		t.Fatalf("expected error for a per-bucket timeout and deadline together")
+	}
+	if err := (timeoutFlags{inspect: time.Hour, deadline: time.Minute}).validate(); err != nil {
+		t.Fatalf("expected an inspect timeout to combine with a deadline, got %v", err)
+	}
+}
+
+func TestInterruptedRun(t *testing.T) {
+	f := checkpointFlags{path: filepath.Join(t.TempDir(), "checkpoint.json")}
+	cp := s3.NewCheckpoint("discover")
+	truncation := &report.Truncation{Reason: errInterrupted.Error(), UninspectedBuckets: []string{"logs"}}
+
+	err := interruptedRun(cp, f, truncation)
+	var exitErr *ExitError
+	if !errors.As(err, &exitErr) || exitErr.Code != ExitCodeInterrupted {
+		t.Fatalf("expected exit code %d, got %v", ExitCodeInterrupted, err)
+	}
+	if _, statErr := os.Stat(f.path); !os.IsNotExist(statErr) {
+		t.Fatalf("expected no checkpoint when nothing was inspected")
+	}
+
+	cp.Buckets["media"] = &s3.BucketInfo{Name: "media", Exists: true}
+	err = interruptedRun(cp, f, truncation)
+	if !strings.Contains(err.Error(), "--resume") {
+		t.Fatalf("expected resume instructions, got %v", err)
+	}
+	if _, err := s3.LoadCheckpoint(f.path, "discover"); err != nil {
+		t.Fatalf("expected saved checkpoint, got %v", err)
+	}
+}
+
+func TestInterrupted(t *testing.T) {
+	ctx, cancel := context.WithCancelCause(context.Background())
+	child, stop := context.WithCancel(ctx)
+	defer stop()
+	if interrupted(child) {
+		t.Fatalf("expected a live context not to be interrupted")
+	}
+	cancel(errInterrupted)
+	if !interrupted(child) {
+		t.Fatalf("expected the signal cause to reach derived contexts")
+	}
+}
+
+func TestRetryBackoff(t *testing.T) {
+	saved := cfg
+	t.Cleanup(func() { cfg = saved })
+
+	cfg = config.Config{}
+	if b, err := retryBackoff(); err != nil || b != nil {
+		t.Fatalf("expected no backoff without config, got %+v, %v", b, err)
+	}
+
+	cfg = config.Config{Retry: config.RetryConfig{BaseDelay: "200ms", MaxDelay: "5s"}}
+	b, err := retryBackoff()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if b.Base != 200*time.Millisecond || b.Max != 5*time.Second || b.Multiplier != s3.DefaultBackoff.Multiplier {
+		t.Fatalf("unexpected backoff: %+v", b)
+	}
+
+	cfg = config.Config{Retry: config.RetryConfig{Multiplier: 0.5}}
+	if _, err := retryBackoff(); err == nil {
+		t.Fatalf("expected error for a multiplier below 1")
+	}
+}
+
+func TestExternalBuckets(t *testing.T) {
+	saved := cfg
+	t.Cleanup(func() { cfg = saved })
+
+	cfg = config.Config{ExternalBuckets: []string{"partner-*"}}
+	external, err := externalBuckets(true)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !external.Match("partner-feed") || !external.Match("landsat-pds") {
+		t.Fatalf("expected configured and registry buckets to be external")
+	}
+	if external, err = externalBuckets(false); err != nil || !external.Match("partner-feed") || external.Match("landsat-pds") {
+		t.Fatalf("expected discovery to match configured buckets only, got %v", err)
+	}
+
+	cfg = config.Config{PublicDatasets: config.PublicDatasetsConfig{Extra: []string{"open-data-*"}}}
+	if external, err = externalBuckets(true); err != nil || !external.Match("open-data-weather") {
+		t.Fatalf("expected extra dataset to be external, got %v", err)
+	}
+
+	cfg = config.Config{PublicDatasets: config.PublicDatasetsConfig{Disabled: true}}
+	if external, err = externalBuckets(true); err != nil || external.Match("landsat-pds") {
+		t.Fatalf("expected disabled registry to match nothing, got %v", err)
+	}
+
+	cfg = config.Config{PublicDatasets: config.PublicDatasetsConfig{Extra: []string{"[bad"}}}
+	if _, err := externalBuckets(true); err == nil {
+		t.Fatalf("expected error for an invalid dataset pattern")
+	}
+}
+
+func TestWriteTuningPatch(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, ".s3spectre.yaml")
+	if err := writeTuningPatch(path, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Fatalf("expected no patch without hints, got %v", err)
+	}
+
+	hints := []analyzer.TuningHint{{ConfigKey: "stale_days", Suggested: 180, Message: "70% of your prefixes exceed stale-days=90; consider 180"}}
+	if err := writeTuningPatch(path, hints); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	loaded, err := config.Load(dir)
+	if err != nil {
+		t.Fatalf("patch should load as a config file: %v", err)
+	}
+	if loaded.StaleDays != 180 {
+		t.Fatalf("expected stale_days 180, got %d", loaded.StaleDays)
+	}
+}
+
+func TestConfirm(t *testing.T) {
+	for input, want := range map[string]bool{"y\n": true, " YES \n": true, "n\n": false, "\n": false, "": false} {
+		var out bytes.Buffer
+		yes, answered := confirm(bufio.NewScanner(strings.NewReader(input)), &out, "Apply? [y/N] ")
+		if yes != want || answered != (input != "") {
+			t.Errorf("confirm(%q) = %v, %v; want %v", input, yes, answered, want)
+		}
+	}
+}
@@ -0,0 +1,114 @@
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+
+	"github.com/ppiankov/s3spectre/internal/integrations"
+	"github.com/ppiankov/s3spectre/internal/report"
+	"github.com/ppiankov/s3spectre/internal/rules"
+	"github.com/spf13/pflag"
+)
+
+// Environment variables holding the Jira credentials. The user is the
+// account email of a Jira Cloud API token; without it the token is a Data
+// Center personal access token.
+const (
+	jiraUserEnv  = "S3SPECTRE_JIRA_USER"
+	jiraTokenEnv = "S3SPECTRE_JIRA_TOKEN"
+)
+
+// jiraFlags configures creating Jira tickets for findings
+type jiraFlags struct {
+	url       string
+	project   string
+	keyField  string
+	issueType string
+	severity  string
+}
+
+// addJiraFlags registers the Jira flags shared by scan and discover
+func addJiraFlags(fs *pflag.FlagSet, f *jiraFlags) {
+	fs.StringVar(&f.url, "jira-url", "", "Create Jira tickets for findings on this Jira site, e.g. https://acme.atlassian.net; credentials are read from "+jiraUserEnv+" and "+jiraTokenEnv)
+	fs.StringVar(&f.project, "jira-project", "", "Key of the Jira project tickets are created in")
+	fs.StringVar(&f.keyField, "jira-key-field", "", "Custom text field, e.g. customfield_10050, that holds the finding key so a finding gets one ticket")
+	fs.StringVar(&f.issueType, "jira-issue-type", integrations.DefaultJiraIssueType, "Issue type of created Jira tickets")
+	fs.StringVar(&f.severity, "jira-severity", rules.SeverityHigh, "Lowest severity that gets a Jira ticket: high, medium, low, or info")
+}
+
+func (f jiraFlags) enabled() bool {
+	return f.url != ""
+}
+
+func (f jiraFlags) validate() error {
+	if !f.enabled() {
+		if f.project != "" {
+			return fmt.Errorf("--jira-project requires --jira-url")
+		}
+		return nil
+	}
+	if !rules.ValidSeverity(f.severity) {
+		return fmt.Errorf("invalid --jira-severity %q: expected high, medium, low, or info", f.severity)
+	}
+	if f.issueType == "" {
+		return fmt.Errorf("--jira-issue-type must not be empty")
+	}
+	_, err := f.client()
+	return err
+}
+
+// client builds the ticket filer from the flags and the credentials in the
+// environment
+func (f jiraFlags) client() (*integrations.Jira, error) {
+	token := os.Getenv(jiraTokenEnv)
+	if token == "" {
+		return nil, fmt.Errorf("--jira-url requires an API token in %s", jiraTokenEnv)
+	}
+	jira, err := integrations.NewJira(f.url, f.project, f.keyField, os.Getenv(jiraUserEnv), token)
+	if err != nil {
+		return nil, fmt.Errorf("--jira-url: %w", err)
+	}
+	jira.IssueType = f.issueType
+	return jira, nil
+}
+
+// createJiraTickets opens a ticket, with the JSON report attached, for each
+// finding at or above the flags' severity that has no unresolved ticket.
+// Failures are logged rather than failing the run.
+func createJiraTickets(ctx context.Context, f jiraFlags, command string, findings []report.Finding, reportData any) {
+	jira, err := f.client()
+	if err != nil {
+		slog.Warn("Jira tickets skipped", "error", err)
+		return
+	}
+	var filed []report.Finding
+	for _, finding := range findings {
+		if rules.SeverityAtLeast(finding.Severity, f.severity) {
+			filed = append(filed, finding)
+		}
+	}
+	if len(filed) == 0 {
+		return
+	}
+	data, err := json.MarshalIndent(reportData, "", "  ")
+	if err != nil {
+		slog.Warn("Jira tickets skipped", "error", err)
+		return
+	}
+	attachment := &integrations.JiraAttachment{Name: "s3spectre-" + command + ".json", Data: data}
+	result, err := jira.File(context.WithoutCancel(ctx), filed, attachment)
+	if len(result.Created) > 0 {
+		slog.Info("Created Jira tickets",
+			slog.String("project", f.project),
+			slog.String("tickets", strings.Join(result.Created, ", ")),
+			slog.Int("existing", result.Existing),
+		)
+	}
+	if err != nil {
+		slog.Warn("Jira ticket creation failed", "project", f.project, "error", err)
+	}
+}
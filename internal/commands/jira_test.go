@@ -0,0 +1,37 @@
+package commands
+
+import "testing"
+
+func TestJiraFlags_Validate(t *testing.T) {
+	t.Setenv(jiraUserEnv, "")
+	t.Setenv(jiraTokenEnv, "")
+	f := jiraFlags{issueType: "Task", severity: "high"}
+	if err := f.validate(); err != nil {
+		t.Errorf("disabled tickets need no configuration: %v", err)
+	}
+	f.project = "OPS"
+	if err := f.validate(); err == nil {
+		t.Error("expected error for --jira-project without --jira-url")
+	}
+
+	f.url = "https://acme.atlassian.net"
+	f.keyField = "customfield_10050"
+	if err := f.validate(); err == nil {
+		t.Error("expected error without a token")
+	}
+	t.Setenv(jiraTokenEnv, "secret")
+	if err := f.validate(); err != nil {
+		t.Errorf("valid flags: %v", err)
+	}
+
+	bad := f
+	bad.keyField = ""
+	if err := bad.validate(); err == nil {
+		t.Error("expected error without --jira-key-field")
+	}
+	bad = f
+	bad.severity = "urgent"
+	if err := bad.validate(); err == nil {
+		t.Error("expected error for unknown --jira-severity")
+	}
+}
@@ -0,0 +1,52 @@
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/ppiankov/s3spectre/internal/baseline"
+	"github.com/spf13/cobra"
+)
+
+var mergeFlags struct {
+	outputFile string
+}
+
+var mergeCmd = &cobra.Command{
+	Use:   "merge REPORT REPORT...",
+	Short: "Combine JSON reports from sharded runs into one report",
+	Long: `Reads scan, discover or merged JSON reports, for example one per account
+or region, and writes a single consolidated JSON report. A finding seen in
+several reports is listed once, with provenance naming every report, region
+and run time it came from.`,
+	Args: cobra.MinimumNArgs(2),
+	RunE: runMerge,
+}
+
+func init() {
+	mergeCmd.Flags().StringVarP(&mergeFlags.outputFile, "output", "o", "", "Output file (default: stdout)")
+}
+
+func runMerge(cmd *cobra.Command, args []string) error {
+	merged, err := baseline.Merge(args)
+	if err != nil {
+		return enhanceError("report load", err, 0)
+	}
+	merged.Version = GetVersion()
+
+	data, err := json.MarshalIndent(merged, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode merged report: %w", err)
+	}
+	data = append(data, '\n')
+	if mergeFlags.outputFile == "" {
+		_, err = os.Stdout.Write(data)
+		return err
+	}
+	if err := os.WriteFile(mergeFlags.outputFile, data, 0644); err != nil {
+		return enhanceError("output file creation", err, 0)
+	}
+	printStatus("Merged %d reports (%d findings) into %s", len(merged.Sources), merged.Summary.Findings, mergeFlags.outputFile)
+	return nil
+}
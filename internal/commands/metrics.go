@@ -0,0 +1,191 @@
+package commands
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"slices"
+
+	"github.com/ppiankov/s3spectre/internal/remediate"
+	"github.com/ppiankov/s3spectre/internal/s3"
+	"github.com/ppiankov/s3spectre/internal/scanner"
+	"github.com/spf13/cobra"
+)
+
+var metricsFlags struct {
+	awsProfile  string
+	awsRegion   string
+	aws         awsClientFlags
+	bucketsFile string
+	dryRun      bool
+	apply       bool
+}
+
+var enableMetricsCmd = &cobra.Command{
+	Use:   "enable-metrics [BUCKET...]",
+	Short: "Turn on S3 request metrics for buckets",
+	Long: `Adds a request metrics configuration named ` + s3.MetricsConfigID + ` with no
+filter to each bucket, so CloudWatch reports request counts for the whole
+bucket. Metrics appear about 15 minutes after they are enabled and are
+billed as CloudWatch custom metrics. Remove them with disable-metrics.
+
+By default the command is a dry run that prints the AWS API calls it would
+make. With --apply it makes them after confirmation on stdin.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runMetrics(cmd, args, true)
+	},
+}
+
+var disableMetricsCmd = &cobra.Command{
+	Use:   "disable-metrics [BUCKET...]",
+	Short: "Remove the request metrics enable-metrics added",
+	Long: `Deletes the ` + s3.MetricsConfigID + ` request metrics configuration from
+each bucket. Other metrics configurations are kept.
+
+By default the command is a dry run that prints the AWS API calls it would
+make. With --apply it makes them after confirmation on stdin.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runMetrics(cmd, args, false)
+	},
+}
+
+func init() {
+	for _, cmd := range []*cobra.Command{enableMetricsCmd, disableMetricsCmd} {
+		cmd.Flags().StringVar(&metricsFlags.awsProfile, "aws-profile", "", "AWS profile to use")
+		cmd.Flags().StringVar(&metricsFlags.awsRegion, "aws-region", "", "AWS region used to look up bucket regions")
+		addAWSClientFlags(cmd.Flags(), &metricsFlags.aws)
+		// Metrics are configured in the account of the credentials only
+		_ = cmd.Flags().MarkHidden("accounts")
+		_ = cmd.Flags().MarkHidden("assume-role-arn")
+		cmd.Flags().StringVar(&metricsFlags.bucketsFile, "buckets-file", "", "Also read buckets from this file, one per line as in scan --buckets-file")
+		cmd.Flags().BoolVar(&metricsFlags.dryRun, "dry-run", true, "Print the AWS API calls without making them")
+		cmd.Flags().BoolVar(&metricsFlags.apply, "apply", false, "Make the API calls after confirming on stdin")
+	}
+}
+
+func runMetrics(cmd *cobra.Command, args []string, enable bool) error {
+	buckets, err := metricsBuckets(args, metricsFlags.bucketsFile)
+	if err != nil {
+		return err
+	}
+	if len(buckets) == 0 {
+		return fmt.Errorf("no buckets given: pass bucket names or --buckets-file")
+	}
+	if cmd.Flags().Changed("dry-run") && metricsFlags.dryRun == metricsFlags.apply {
+		return fmt.Errorf("--dry-run and --apply are mutually exclusive; --apply alone makes the API calls")
+	}
+	if err := metricsFlags.aws.validate(); err != nil {
+		return err
+	}
+	out := cmd.OutOrStdout()
+	if !metricsFlags.apply {
+		_, _ = fmt.Fprintf(out, "Dry run: %d buckets. Re-run with --apply to make these calls.\n\n", len(buckets))
+		writeMetricsCalls(out, buckets, enable)
+		return nil
+	}
+	writeMetricsCalls(out, buckets, enable)
+	if yes, _ := confirm(bufio.NewScanner(cmd.InOrStdin()), out, fmt.Sprintf("Apply to %d buckets? [y/N] ", len(buckets))); !yes {
+		_, _ = fmt.Fprintln(out, "No buckets changed.")
+		return nil
+	}
+
+	if metricsFlags.aws.backoff, err = retryBackoff(); err != nil {
+		return err
+	}
+	ctx := context.Background()
+	printStatus("Initializing AWS S3 client...")
+	s3Client, err := s3.NewClient(ctx, metricsFlags.awsProfile, metricsFlags.awsRegion, metricsFlags.aws.clientOptions(1)...)
+	if err != nil {
+		return enhanceError("S3 client initialization", err, 0)
+	}
+	clientFor := func(ctx context.Context, bucket string) (s3.MetricsAPI, error) {
+		region, err := s3Client.BucketRegion(ctx, bucket)
+		if err != nil {
+			return nil, err
+		}
+		return s3Client.ForRegion(region).GetClient(), nil
+	}
+	failed := configureMetrics(ctx, out, buckets, enable, clientFor)
+	if failed > 0 {
+		return fmt.Errorf("request metrics could not be changed on %d of %d buckets", failed, len(buckets))
+	}
+	return nil
+}
+
+// metricsBuckets joins the bucket arguments and the buckets file, in order
+// and without duplicates. Prefixes in the file are ignored, since metrics
+// cover whole buckets.
+func metricsBuckets(args []string, bucketsFile string) ([]string, error) {
+	buckets := slices.Clone(args)
+	if bucketsFile != "" {
+		refs, err := scanner.ReadBucketList(bucketsFile)
+		if err != nil {
+			return nil, enhanceError("buckets file load", err, 0)
+		}
+		for _, ref := range refs {
+			buckets = append(buckets, ref.Bucket)
+		}
+	}
+	var unique []string
+	for _, bucket := range buckets {
+		if !slices.Contains(unique, bucket) {
+			unique = append(unique, bucket)
+		}
+	}
+	return unique, nil
+}
+
+// metricsCall is the API call that enables or disables request metrics on
+// a bucket
+func metricsCall(bucket string, enable bool) remediate.Call {
+	if enable {
+		return remediate.Call{API: "PutBucketMetricsConfiguration", Params: map[string]any{
+			"Bucket":               bucket,
+			"Id":                   s3.MetricsConfigID,
+			"MetricsConfiguration": map[string]any{"Id": s3.MetricsConfigID},
+		}}
+	}
+	return remediate.Call{API: "DeleteBucketMetricsConfiguration", Params: map[string]any{
+		"Bucket": bucket,
+		"Id":     s3.MetricsConfigID,
+	}}
+}
+
+// writeMetricsCalls prints the API call planned for each bucket
+func writeMetricsCalls(w io.Writer, buckets []string, enable bool) {
+	for _, bucket := range buckets {
+		_, _ = fmt.Fprintf(w, "  %s\n", metricsCall(bucket, enable))
+	}
+}
+
+// configureMetrics enables or disables request metrics on each bucket,
+// printing a line per bucket, and returns how many failed
+func configureMetrics(ctx context.Context, out io.Writer, buckets []string, enable bool,
+	clientFor func(context.Context, string) (s3.MetricsAPI, error)) int {
+	failed := 0
+	for _, bucket := range buckets {
+		api, err := clientFor(ctx, bucket)
+		result := ""
+		if err == nil {
+			if enable {
+				err = s3.EnableRequestMetrics(ctx, api, bucket)
+				result = "request metrics enabled"
+			} else {
+				var existed bool
+				existed, err = s3.DisableRequestMetrics(ctx, api, bucket)
+				result = "request metrics disabled"
+				if !existed {
+					result = "no s3spectre request metrics to disable"
+				}
+			}
+		}
+		if err != nil {
+			_, _ = fmt.Fprintf(out, "s3://%s: failed: %v\n", bucket, err)
+			failed++
+			continue
+		}
+		_, _ = fmt.Fprintf(out, "s3://%s: %s\n", bucket, result)
+	}
+	return failed
+}
@@ -0,0 +1,77 @@
+package commands
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	s3spectre "github.com/ppiankov/s3spectre/internal/s3"
+)
+
+func TestMetricsBuckets(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "buckets.txt")
+	if err := os.WriteFile(path, []byte("# expected\nlogs/app/\ns3://media read\ndata\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	buckets, err := metricsBuckets([]string{"data", "extra"}, path)
+	if err != nil {
+		t.Fatalf("metricsBuckets: %v", err)
+	}
+	if got := strings.Join(buckets, " "); got != "data extra logs media" {
+		t.Errorf("buckets = %q", got)
+	}
+}
+
+// nopMetrics accepts every metrics call
+type nopMetrics struct{}
+
+func (nopMetrics) PutBucketMetricsConfiguration(ctx context.Context, params *s3.PutBucketMetricsConfigurationInput, optFns ...func(*s3.Options)) (*s3.PutBucketMetricsConfigurationOutput, error) {
+	return &s3.PutBucketMetricsConfigurationOutput{}, nil
+}
+
+func (nopMetrics) DeleteBucketMetricsConfiguration(ctx context.Context, params *s3.DeleteBucketMetricsConfigurationInput, optFns ...func(*s3.Options)) (*s3.DeleteBucketMetricsConfigurationOutput, error) {
+	return &s3.DeleteBucketMetricsConfigurationOutput{}, nil
+}
+
+func TestConfigureMetrics(t *testing.T) {
+	clientFor := func(ctx context.Context, bucket string) (s3spectre.MetricsAPI, error) {
+		if bucket == "gone" {
+			return nil, errors.New("NoSuchBucket")
+		}
+		return nopMetrics{}, nil
+	}
+	var out bytes.Buffer
+	failed := configureMetrics(context.Background(), &out, []string{"logs", "gone", "media"}, true, clientFor)
+	if failed != 1 {
+		t.Errorf("failed = %d, want 1", failed)
+	}
+	for _, want := range []string{"s3://logs: request metrics enabled", "s3://gone: failed: NoSuchBucket", "s3://media: request metrics enabled"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("output missing %q:\n%s", want, out.String())
+		}
+	}
+
+	out.Reset()
+	if failed := configureMetrics(context.Background(), &out, []string{"logs"}, false, clientFor); failed != 0 || !strings.Contains(out.String(), "request metrics disabled") {
+		t.Errorf("disable output = %q, failed = %d", out.String(), failed)
+	}
+}
+
+func TestWriteMetricsCalls(t *testing.T) {
+	var out bytes.Buffer
+	writeMetricsCalls(&out, []string{"logs"}, true)
+	want := `  PutBucketMetricsConfiguration {"Bucket":"logs","Id":"s3spectre-EntireBucket","MetricsConfiguration":{"Id":"s3spectre-EntireBucket"}}`
+	if strings.TrimRight(out.String(), "\n") != want {
+		t.Errorf("enable calls = %q, want %q", out.String(), want)
+	}
+	out.Reset()
+	writeMetricsCalls(&out, []string{"logs"}, false)
+	if !strings.Contains(out.String(), `DeleteBucketMetricsConfiguration {"Bucket":"logs","Id":"s3spectre-EntireBucket"}`) {
+		t.Errorf("disable calls = %q", out.String())
+	}
+}
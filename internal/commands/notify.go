@@ -0,0 +1,108 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"time"
+
+	"github.com/ppiankov/s3spectre/internal/notify"
+	"github.com/ppiankov/s3spectre/internal/report"
+	"github.com/ppiankov/s3spectre/internal/rules"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// Webhook URLs embed secrets, so they can come from the environment instead
+// of the command line
+const (
+	slackWebhookEnv  = "S3SPECTRE_SLACK_WEBHOOK"
+	notifyWebhookEnv = "S3SPECTRE_NOTIFY_WEBHOOK"
+)
+
+// notifyFlags configures the notifications sent after scan and discover
+type notifyFlags struct {
+	slackWebhook string
+	webhook      string
+	severity     string
+}
+
+// addNotifyFlags registers the notification flags shared by scan and discover
+func addNotifyFlags(fs *pflag.FlagSet, f *notifyFlags) {
+	fs.StringVar(&f.slackWebhook, "notify-slack-webhook", "", "Post a summary of findings to this Slack incoming webhook URL (or set "+slackWebhookEnv+")")
+	fs.StringVar(&f.webhook, "notify-webhook", "", "POST a JSON summary of findings to this URL (or set "+notifyWebhookEnv+")")
+	fs.StringVar(&f.severity, "notify-severity", rules.SeverityHigh, "Lowest severity that sends a notification: high, medium, low, or info")
+}
+
+// applyNotifyConfig fills unset notification flags from the environment and
+// the config file
+func applyNotifyConfig(cmd *cobra.Command, f *notifyFlags) {
+	if f.slackWebhook == "" {
+		f.slackWebhook = os.Getenv(slackWebhookEnv)
+	}
+	if f.webhook == "" {
+		f.webhook = os.Getenv(notifyWebhookEnv)
+	}
+	if !cmd.Flags().Lookup("notify-severity").Changed && cfg.Notify.Severity != "" {
+		f.severity = cfg.Notify.Severity
+	}
+}
+
+func (f notifyFlags) validate() error {
+	if !rules.ValidSeverity(f.severity) {
+		return fmt.Errorf("invalid --notify-severity %q: expected high, medium, low, or info", f.severity)
+	}
+	if f.slackWebhook != "" {
+		if err := notify.ValidateURL(f.slackWebhook); err != nil {
+			return fmt.Errorf("--notify-slack-webhook: %w", err)
+		}
+	}
+	if f.webhook != "" {
+		if err := notify.ValidateURL(f.webhook); err != nil {
+			return fmt.Errorf("--notify-webhook: %w", err)
+		}
+	}
+	return nil
+}
+
+// enabled reports whether any notification destination is set
+func (f notifyFlags) enabled() bool {
+	return f.slackWebhook != "" || f.webhook != ""
+}
+
+func (f notifyFlags) notifiers() []notify.Notifier {
+	var notifiers []notify.Notifier
+	if f.slackWebhook != "" {
+		notifiers = append(notifiers, notify.NewSlack(f.slackWebhook))
+	}
+	if f.webhook != "" {
+		notifiers = append(notifiers, notify.NewWebhook(f.webhook))
+	}
+	return notifiers
+}
+
+// sendNotifications notifies about the findings at or above the notification
+// severity; with a baseline comparison, only about new ones. Nothing is sent
+// when no finding qualifies, and a failed notification is logged rather than
+// failing the run.
+func sendNotifications(ctx context.Context, f notifyFlags, command, target string, timestamp time.Time, findings []report.Finding, changes *report.BaselineChanges) {
+	p := notify.NewPayload(findings, f.severity, changes)
+	if len(p.Findings) == 0 {
+		slog.Info("No findings to notify about", slog.String("min_severity", f.severity))
+		return
+	}
+	p.Tool = "s3spectre"
+	p.Version = GetVersion()
+	p.Command = command
+	p.Target = target
+	p.Timestamp = timestamp
+	notifiers := f.notifiers()
+	// The report is already written, so a run timeout that just expired
+	// should not stop it being announced; each request has its own timeout
+	if err := notify.Send(context.WithoutCancel(ctx), notifiers, p); err != nil {
+		slog.Warn("Notification failed", "error", err)
+		return
+	}
+	slog.Info("Sent notifications", slog.Int("findings", len(p.Findings)), slog.Int("destinations", len(notifiers)))
+}
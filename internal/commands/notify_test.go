@@ -0,0 +1,44 @@
+package commands
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ppiankov/s3spectre/internal/report"
+)
+
+func TestNotifyFlags_Validate(t *testing.T) {
+	if err := (notifyFlags{severity: "high", webhook: "https://example.com/hook"}).validate(); err != nil {
+		t.Errorf("valid flags: %v", err)
+	}
+	if err := (notifyFlags{severity: "urgent"}).validate(); err == nil {
+		t.Error("expected error for unknown --notify-severity")
+	}
+	if err := (notifyFlags{severity: "high", slackWebhook: "hooks.slack.com/x"}).validate(); err == nil {
+		t.Error("expected error for a webhook URL without a scheme")
+	}
+}
+
+func TestSendNotifications_OnlyQualifyingFindings(t *testing.T) {
+	requests := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+	}))
+	defer srv.Close()
+
+	f := notifyFlags{webhook: srv.URL, severity: "high"}
+	low := []report.Finding{{Status: "STALE_PREFIX", Severity: "low", Resource: "s3://logs/old/"}}
+	sendNotifications(context.Background(), f, "scan", ".", time.Now(), low, nil)
+	if requests != 0 {
+		t.Errorf("low findings should not notify at high severity, got %d requests", requests)
+	}
+
+	high := append(low, report.Finding{Status: "MISSING_BUCKET", Severity: "high", Resource: "s3://orders"})
+	sendNotifications(context.Background(), f, "scan", ".", time.Now(), high, nil)
+	if requests != 1 {
+		t.Errorf("expected 1 request, got %d", requests)
+	}
+}
@@ -0,0 +1,92 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"time"
+
+	"github.com/ppiankov/s3spectre/internal/integrations"
+	"github.com/ppiankov/s3spectre/internal/report"
+	"github.com/spf13/pflag"
+)
+
+// Environment variables holding the OpenSearch credentials: a user and
+// password for basic auth, or an Elasticsearch API key
+const (
+	openSearchUserEnv     = "S3SPECTRE_OPENSEARCH_USER"
+	openSearchPasswordEnv = "S3SPECTRE_OPENSEARCH_PASSWORD"
+	openSearchAPIKeyEnv   = "S3SPECTRE_OPENSEARCH_API_KEY"
+)
+
+// openSearchFlags configures indexing findings in OpenSearch or
+// Elasticsearch
+type openSearchFlags struct {
+	url      string
+	index    string
+	template bool
+}
+
+// addOpenSearchFlags registers the OpenSearch flags shared by scan and
+// discover
+func addOpenSearchFlags(fs *pflag.FlagSet, f *openSearchFlags) {
+	fs.StringVar(&f.url, "opensearch-url", "", "Bulk-index findings into this OpenSearch or Elasticsearch cluster, e.g. https://search.example.com:9200; credentials are read from "+openSearchUserEnv+" and "+openSearchPasswordEnv+", or "+openSearchAPIKeyEnv)
+	fs.StringVar(&f.index, "opensearch-index", integrations.DefaultOpenSearchIndex, "Index findings are written to")
+	fs.BoolVar(&f.template, "opensearch-template", true, "Create or update the index template for the findings index before indexing")
+}
+
+func (f openSearchFlags) enabled() bool {
+	return f.url != ""
+}
+
+func (f openSearchFlags) validate() error {
+	if !f.enabled() {
+		return nil
+	}
+	_, err := f.client()
+	return err
+}
+
+// client builds the indexer from the flags and the credentials in the
+// environment
+func (f openSearchFlags) client() (*integrations.OpenSearch, error) {
+	search, err := integrations.NewOpenSearch(f.url, f.index,
+		os.Getenv(openSearchUserEnv), os.Getenv(openSearchPasswordEnv), os.Getenv(openSearchAPIKeyEnv))
+	if err != nil {
+		return nil, fmt.Errorf("--opensearch-url: %w", err)
+	}
+	return search, nil
+}
+
+// indexFindings writes the run's findings to the cluster, after installing
+// the index template when the flags ask for it. Failures are logged rather
+// than failing the run.
+func indexFindings(ctx context.Context, f openSearchFlags, command, target string, timestamp time.Time, findings []report.Finding) {
+	search, err := f.client()
+	if err != nil {
+		slog.Warn("OpenSearch indexing skipped", "error", err)
+		return
+	}
+	ctx = context.WithoutCancel(ctx)
+	if f.template {
+		// Indexing still works without the template, with dynamic mappings
+		if err := search.PutTemplate(ctx); err != nil {
+			slog.Warn("OpenSearch index template not installed", "index", f.index, "error", err)
+		}
+	}
+	if len(findings) == 0 {
+		return
+	}
+	run := integrations.Run{Command: command, Target: target, Version: GetVersion(), Timestamp: timestamp}
+	result, err := search.IndexFindings(ctx, run, findings)
+	if result.Indexed > 0 {
+		slog.Info("Indexed findings in OpenSearch", slog.String("index", f.index), slog.Int("findings", result.Indexed))
+	}
+	if result.Failed > 0 {
+		slog.Warn("OpenSearch rejected findings", "index", f.index, "failed", result.Failed, "error", result.FirstError)
+	}
+	if err != nil {
+		slog.Warn("OpenSearch indexing failed", "index", f.index, "error", err)
+	}
+}
@@ -0,0 +1,25 @@
+package commands
+
+import "testing"
+
+func TestOpenSearchFlags_Validate(t *testing.T) {
+	f := openSearchFlags{index: "s3spectre-findings", template: true}
+	if err := f.validate(); err != nil {
+		t.Errorf("disabled indexing needs no configuration: %v", err)
+	}
+	f.url = "https://search.example.com:9200"
+	if err := f.validate(); err != nil {
+		t.Errorf("valid flags: %v", err)
+	}
+
+	bad := f
+	bad.url = "search.example.com:9200"
+	if err := bad.validate(); err == nil {
+		t.Error("expected error for a URL without a scheme")
+	}
+	bad = f
+	bad.index = "S3Spectre"
+	if err := bad.validate(); err == nil {
+		t.Error("expected error for an uppercase --opensearch-index")
+	}
+}
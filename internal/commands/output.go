@@ -0,0 +1,210 @@
+package commands
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/ppiankov/s3spectre/internal/report"
+)
+
+// formatList is a repeatable --format flag stored as a comma-separated
+// string. The first value given replaces the default; later ones are added.
+type formatList struct {
+	value *string
+	set   bool
+}
+
+func newFormatList(p *string, def string) *formatList {
+	*p = def
+	return &formatList{value: p}
+}
+
+func (f *formatList) String() string { return *f.value }
+
+func (f *formatList) Set(s string) error {
+	if !f.set {
+		*f.value = ""
+		f.set = true
+	}
+	for _, format := range strings.Split(s, ",") {
+		format = strings.TrimSpace(format)
+		if format == "" || containsFormat(*f.value, format) {
+			continue
+		}
+		if *f.value != "" {
+			*f.value += ","
+		}
+		*f.value += format
+	}
+	return nil
+}
+
+func (f *formatList) Type() string { return "strings" }
+
+// splitFormats returns the formats in a --format value
+func splitFormats(value string) []string {
+	var formats []string
+	for _, format := range strings.Split(value, ",") {
+		if format = strings.TrimSpace(format); format != "" {
+			formats = append(formats, format)
+		}
+	}
+	return formats
+}
+
+// containsFormat reports whether a --format value includes format
+func containsFormat(value, format string) bool {
+	for _, f := range splitFormats(value) {
+		if f == format {
+			return true
+		}
+	}
+	return false
+}
+
+// formatExtensions names each format's file in --output-dir
+var formatExtensions = map[string]string{
+	"text":            "txt",
+	"json":            "json",
+	"sarif":           "sarif",
+	"spectrehub":      "spectrehub.json",
+	"lsp-diagnostics": "lsp.json",
+	"tasks":           "tasks.md",
+	"csv":             "csv",
+	"markdown":        "md",
+}
+
+// validateOutput checks --format, --output and --output-dir before any AWS
+// calls. Several formats need --output-dir, which excludes --output.
+func validateOutput(value, file, dir string) error {
+	formats := splitFormats(value)
+	if len(formats) == 0 {
+		return fmt.Errorf("--format needs at least one format")
+	}
+	for _, format := range formats {
+		if _, err := selectReporter(format, io.Discard); err != nil {
+			return err
+		}
+	}
+	if file != "" && dir != "" {
+		return fmt.Errorf("--output and --output-dir cannot be used together")
+	}
+	if len(formats) > 1 && dir == "" {
+		return fmt.Errorf("%d formats requested: use --output-dir to write one file per format", len(formats))
+	}
+	return nil
+}
+
+// manifestReport is one report file listed in an --output-dir manifest
+type manifestReport struct {
+	Format string `json:"format"`
+	File   string `json:"file"`
+	Bytes  int64  `json:"bytes"`
+	SHA256 string `json:"sha256"`
+}
+
+// outputManifest describes the reports written to --output-dir in one run
+type outputManifest struct {
+	Tool      string           `json:"tool"`
+	Version   string           `json:"version"`
+	Command   string           `json:"command"`
+	Timestamp time.Time        `json:"timestamp"`
+	Reports   []manifestReport `json:"reports"`
+}
+
+// writeReports renders the same analysis in every requested format. Without
+// --output-dir the single format goes to --output or stdout; with it, each
+// format gets its own file named after the command, plus manifest.json.
+func writeReports(value, file, dir, command string, timestamp time.Time, generate func(report.Reporter) error) error {
+	formats := splitFormats(value)
+	if dir == "" {
+		writer := os.Stdout
+		if file != "" {
+			f, err := os.Create(file)
+			if err != nil {
+				return fmt.Errorf("output file creation: %w", err)
+			}
+			defer func() { _ = f.Close() }()
+			writer = f
+		}
+		reporter, err := selectReporter(formats[0], writer)
+		if err != nil {
+			return err
+		}
+		return generate(reporter)
+	}
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("output directory creation: %w", err)
+	}
+	manifest := outputManifest{
+		Tool:      "s3spectre",
+		Version:   GetVersion(),
+		Command:   command,
+		Timestamp: timestamp,
+	}
+	for _, format := range formats {
+		name := command + "." + formatExtensions[format]
+		entry, err := writeReportFile(filepath.Join(dir, name), format, generate)
+		if err != nil {
+			return err
+		}
+		entry.File = name
+		manifest.Reports = append(manifest.Reports, entry)
+	}
+
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return fmt.Errorf("manifest: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "manifest.json"), append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("manifest: %w", err)
+	}
+	printStatus("Wrote %d reports to %s", len(formats), dir)
+	return nil
+}
+
+// writeReportFile renders one format to path and records its size and digest
+func writeReportFile(path, format string, generate func(report.Reporter) error) (manifestReport, error) {
+	entry := manifestReport{Format: format}
+	f, err := os.Create(path)
+	if err != nil {
+		return entry, fmt.Errorf("output file creation: %w", err)
+	}
+	hash := sha256.New()
+	counter := &countingWriter{w: io.MultiWriter(f, hash)}
+	reporter, err := selectReporter(format, counter)
+	if err != nil {
+		_ = f.Close()
+		return entry, err
+	}
+	if err := generate(reporter); err != nil {
+		_ = f.Close()
+		return entry, fmt.Errorf("%s report: %w", format, err)
+	}
+	if err := f.Close(); err != nil {
+		return entry, err
+	}
+	entry.Bytes = counter.n
+	entry.SHA256 = hex.EncodeToString(hash.Sum(nil))
+	return entry, nil
+}
+
+// countingWriter counts the bytes written through it
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
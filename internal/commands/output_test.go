@@ -0,0 +1,95 @@
+package commands
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ppiankov/s3spectre/internal/analyzer"
+	"github.com/ppiankov/s3spectre/internal/report"
+	"github.com/spf13/pflag"
+)
+
+func TestFormatList(t *testing.T) {
+	var value string
+	flags := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	flags.VarP(newFormatList(&value, "text"), "format", "f", "")
+	if value != "text" || flags.Lookup("format").DefValue != "text" {
+		t.Fatalf("expected default text, got %q", value)
+	}
+
+	if err := flags.Parse([]string{"--format", "json", "-f", "sarif,json", "--format", "spectrehub"}); err != nil {
+		t.Fatalf("parse failed: %v", err)
+	}
+	if value != "json,sarif,spectrehub" {
+		t.Fatalf("expected formats to replace the default and accumulate, got %q", value)
+	}
+}
+
+func TestValidateOutput(t *testing.T) {
+	tests := []struct {
+		name    string
+		formats string
+		file    string
+		dir     string
+		wantErr bool
+	}{
+		{"single format to stdout", "json", "", "", false},
+		{"single format to dir", "json", "", "out", false},
+		{"several formats to dir", "json,sarif", "", "out", false},
+		{"several formats without dir", "json,sarif", "", "", true},
+		{"output and output-dir", "json", "report.json", "out", true},
+		{"unknown format", "json,yaml", "", "out", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateOutput(tt.formats, tt.file, tt.dir)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("validateOutput() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestWriteReports_OutputDir(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "out")
+	data := report.Data{Tool: "s3spectre", Buckets: map[string]*analyzer.BucketAnalysis{}}
+	calls := 0
+	err := writeReports("json,sarif,spectrehub", "", dir, "scan", time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC), func(r report.Reporter) error {
+		calls++
+		return r.Generate(data)
+	})
+	if err != nil {
+		t.Fatalf("writeReports failed: %v", err)
+	}
+	if calls != 3 {
+		t.Fatalf("expected one generation per format, got %d", calls)
+	}
+
+	raw, err := os.ReadFile(filepath.Join(dir, "manifest.json"))
+	if err != nil {
+		t.Fatalf("missing manifest: %v", err)
+	}
+	var manifest outputManifest
+	if err := json.Unmarshal(raw, &manifest); err != nil {
+		t.Fatalf("invalid manifest: %v", err)
+	}
+	if manifest.Command != "scan" || len(manifest.Reports) != 3 {
+		t.Fatalf("unexpected manifest: %+v", manifest)
+	}
+	wantFiles := []string{"scan.json", "scan.sarif", "scan.spectrehub.json"}
+	for i, entry := range manifest.Reports {
+		if entry.File != wantFiles[i] {
+			t.Errorf("expected %s, got %s", wantFiles[i], entry.File)
+		}
+		info, err := os.Stat(filepath.Join(dir, entry.File))
+		if err != nil {
+			t.Fatalf("report %s not written: %v", entry.File, err)
+		}
+		if info.Size() != entry.Bytes || len(entry.SHA256) != 64 {
+			t.Errorf("manifest entry does not match file: %+v, size %d", entry, info.Size())
+		}
+	}
+}
@@ -0,0 +1,132 @@
+package commands
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"github.com/ppiankov/s3spectre/internal/s3"
+)
+
+const (
+	// progressInterval is how often the progress display is redrawn
+	progressInterval = 500 * time.Millisecond
+	// progressActiveLines is the most in-flight buckets listed at once
+	progressActiveLines = 8
+	// slowestBucketCount is how many of the slowest buckets are reported
+	slowestBucketCount = 5
+)
+
+// startProgressDisplay redraws the inspection progress on a terminal until
+// the returned function is called, which erases it
+func startProgressDisplay(w io.Writer, p *s3.Progress) func() {
+	stop := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		ticker := time.NewTicker(progressInterval)
+		defer ticker.Stop()
+		drawn := 0
+		for {
+			select {
+			case <-stop:
+				eraseLines(w, drawn)
+				return
+			case <-ticker.C:
+				snapshot := p.Snapshot()
+				if snapshot.Total == 0 {
+					// Buckets are still being listed
+					continue
+				}
+				eraseLines(w, drawn)
+				text := formatProgress(snapshot)
+				_, _ = io.WriteString(w, text)
+				drawn = strings.Count(text, "\n")
+			}
+		}
+	}()
+	return func() {
+		close(stop)
+		wg.Wait()
+	}
+}
+
+// eraseLines moves the cursor up over the last n lines drawn and clears them
+func eraseLines(w io.Writer, n int) {
+	if n > 0 {
+		_, _ = fmt.Fprintf(w, "\x1b[%dA\x1b[J", n)
+	}
+}
+
+// formatProgress renders a progress line with throughput and ETA, then the
+// operation each in-flight bucket is waiting on, longest running first
+func formatProgress(s s3.ProgressSnapshot) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Inspecting buckets %d/%d", s.Done, s.Total)
+	if s.Total > 0 {
+		fmt.Fprintf(&b, " (%d%%)", s.Done*100/s.Total)
+	}
+	if s.Rate > 0 {
+		fmt.Fprintf(&b, " · %.1f buckets/s", s.Rate)
+	}
+	if s.ETA > 0 {
+		fmt.Fprintf(&b, " · ETA %s", s.ETA.Round(time.Second))
+	}
+	b.WriteString("\n")
+
+	active := s.Active
+	if len(active) > progressActiveLines {
+		active = active[:progressActiveLines]
+	}
+	tw := tabwriter.NewWriter(&b, 0, 0, 2, ' ', 0)
+	for _, a := range active {
+		operation := a.Operation
+		if operation == "" {
+			operation = "starting"
+		}
+		_, _ = fmt.Fprintf(tw, "  %s\t%s\t%s\n", a.Bucket, operation, a.Elapsed.Round(time.Second))
+	}
+	_ = tw.Flush()
+	if more := len(s.Active) - len(active); more > 0 {
+		fmt.Fprintf(&b, "  … and %d more\n", more)
+	}
+	return b.String()
+}
+
+// writeSlowestBuckets prints the buckets that took longest to inspect
+func writeSlowestBuckets(w io.Writer, timings []s3.BucketTiming) {
+	if len(timings) == 0 {
+		return
+	}
+	_, _ = fmt.Fprintf(w, "Slowest buckets:\n")
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintf(tw, "  BUCKET\tREGION\tDURATION\n")
+	for _, t := range timings {
+		region := t.Region
+		if region == "" {
+			region = "-"
+		}
+		duration := time.Duration(t.DurationSeconds * float64(time.Second))
+		_, _ = fmt.Fprintf(tw, "  %s\t%s\t%s\n", t.Bucket, region, duration.Round(100*time.Millisecond))
+	}
+	_ = tw.Flush()
+}
+
+// reportSlowestBuckets records the slowest buckets in the run statistics and
+// shows them: as a table on a terminal, otherwise in the debug log
+func reportSlowestBuckets(w io.Writer, p *s3.Progress, showProgress bool) []s3.BucketTiming {
+	slowest := p.Slowest(slowestBucketCount)
+	if showProgress {
+		writeSlowestBuckets(w, slowest)
+		return slowest
+	}
+	for _, t := range slowest {
+		slog.Debug("Slow bucket", slog.String("bucket", t.Bucket), slog.String("region", t.Region), slog.Float64("duration_seconds", t.DurationSeconds))
+	}
+	return slowest
+}
@@ -0,0 +1,65 @@
+package commands
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ppiankov/s3spectre/internal/s3"
+)
+
+func TestFormatProgress(t *testing.T) {
+	active := []s3.ActiveBucket{
+		{Bucket: "prod-logs", Operation: "S3.ListObjectsV2", Elapsed: 12 * time.Second},
+		{Bucket: "media", Elapsed: time.Second},
+	}
+	for i := 0; i < progressActiveLines; i++ {
+		active = append(active, s3.ActiveBucket{Bucket: "other", Operation: "S3.HeadBucket"})
+	}
+	got := formatProgress(s3.ProgressSnapshot{
+		Done:   120,
+		Total:  800,
+		Rate:   4.25,
+		ETA:    160*time.Second + 300*time.Millisecond,
+		Active: active,
+	})
+	lines := strings.Split(strings.TrimSuffix(got, "\n"), "\n")
+	if lines[0] != "Inspecting buckets 120/800 (15%) · 4.2 buckets/s · ETA 2m40s" {
+		t.Errorf("headline = %q", lines[0])
+	}
+	if !strings.Contains(lines[1], "prod-logs") || !strings.Contains(lines[1], "S3.ListObjectsV2") || !strings.HasSuffix(lines[1], "12s") {
+		t.Errorf("first active line = %q", lines[1])
+	}
+	if !strings.Contains(lines[2], "starting") {
+		t.Errorf("a bucket without an operation yet should read starting: %q", lines[2])
+	}
+	if len(lines) != progressActiveLines+2 || lines[len(lines)-1] != "  … and 2 more" {
+		t.Errorf("expected %d active lines and a count of the rest, got:\n%s", progressActiveLines, got)
+	}
+
+	if got := formatProgress(s3.ProgressSnapshot{Total: 3}); got != "Inspecting buckets 0/3 (0%)\n" {
+		t.Errorf("before any bucket finishes: %q", got)
+	}
+}
+
+func TestWriteSlowestBuckets(t *testing.T) {
+	var buf bytes.Buffer
+	writeSlowestBuckets(&buf, []s3.BucketTiming{
+		{Bucket: "prod-logs", Region: "us-east-1", DurationSeconds: 42.13},
+		{Bucket: "outpost-data", DurationSeconds: 3},
+	})
+	want := "Slowest buckets:\n" +
+		"  BUCKET        REGION     DURATION\n" +
+		"  prod-logs     us-east-1  42.1s\n" +
+		"  outpost-data  -          3s\n"
+	if buf.String() != want {
+		t.Errorf("got:\n%s\nwant:\n%s", buf.String(), want)
+	}
+
+	buf.Reset()
+	writeSlowestBuckets(&buf, nil)
+	if buf.Len() != 0 {
+		t.Errorf("nothing should be written without timings, got %q", buf.String())
+	}
+}
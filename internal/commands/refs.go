@@ -0,0 +1,106 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/ppiankov/s3spectre/internal/report"
+	"github.com/ppiankov/s3spectre/internal/scanner"
+	"github.com/spf13/cobra"
+)
+
+var refsFlags struct {
+	repoPath    string
+	format      string
+	outputFile  string
+	repoTimeout time.Duration
+	tfState     []string
+	extraFiles  []string
+	dedupe      string
+	archives    bool
+	decryptSOPS bool
+}
+
+var refsCmd = &cobra.Command{
+	Use:   "refs",
+	Short: "List S3 references found in code without contacting AWS",
+	Long: `Scans a repository for S3 bucket and prefix references and prints the full
+inventory: every occurrence with its file, line, context and confidence, plus
+per-file counts. No AWS credentials are needed, so it works offline and in
+air-gapped review.`,
+	Args: cobra.NoArgs,
+	RunE: runRefs,
+}
+
+func init() {
+	refsCmd.Flags().StringVarP(&refsFlags.repoPath, "repo", "r", ".", "Path to repository to scan")
+	refsCmd.Flags().StringVarP(&refsFlags.format, "format", "f", "text", "Output format: text, json, or csv")
+	refsCmd.Flags().StringVarP(&refsFlags.outputFile, "output", "o", "", "Output file (default: stdout)")
+	refsCmd.Flags().StringSliceVar(&refsFlags.tfState, "tf-state", nil, "Also read bucket references from this Terraform state file (repeatable)")
+	refsCmd.Flags().StringSliceVar(&refsFlags.extraFiles, "extra-file", nil, "Also read bucket references from this SQL dump or CSV/TSV table export (repeatable)")
+	refsCmd.Flags().BoolVar(&refsFlags.archives, "scan-archives", false, "Also scan config files inside .zip and .tar.gz artifacts in the repository, such as packaged Lambda bundles")
+	refsCmd.Flags().BoolVar(&refsFlags.decryptSOPS, "decrypt-sops", false, "Decrypt sops-encrypted files with the sops executable and your own KMS, age or PGP keys, and scan the plaintext for bucket references")
+	refsCmd.Flags().StringVar(&refsFlags.dedupe, "dedupe", scanner.DedupeNone, "Collapse repeated references: none, by-bucket, or by-bucket-prefix")
+	refsCmd.Flags().DurationVar(&refsFlags.repoTimeout, "repo-timeout", 0, "Budget for the repository scan (0 means none)")
+}
+
+func runRefs(cmd *cobra.Command, args []string) error {
+	switch refsFlags.format {
+	case "text", "json", "csv":
+	default:
+		return fmt.Errorf("unsupported refs format: %s (supported: text, json, csv)", refsFlags.format)
+	}
+	if refsFlags.repoTimeout < 0 {
+		return fmt.Errorf("--repo-timeout must not be negative")
+	}
+	if !cmd.Flags().Lookup("dedupe").Changed && cfg.Dedupe != "" {
+		refsFlags.dedupe = cfg.Dedupe
+	}
+	if err := scanner.ValidateDedupe(refsFlags.dedupe); err != nil {
+		return err
+	}
+
+	ctx := cmd.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	printStatus("Scanning repository: %s", refsFlags.repoPath)
+	repoScanner := scanner.NewRepoScanner(refsFlags.repoPath)
+	repoScanner.SetDedupe(refsFlags.dedupe)
+	repoScanner.SetTFStateFiles(refsFlags.tfState)
+	repoScanner.SetExtraFiles(refsFlags.extraFiles)
+	repoScanner.SetScanArchives(refsFlags.archives)
+	repoScanner.SetDecryptSOPS(refsFlags.decryptSOPS)
+	repoCtx, cancelRepo := phaseContext(ctx, refsFlags.repoTimeout)
+	references, err := repoScanner.Scan(repoCtx)
+	repoExpired := ctx.Err() == nil && errors.Is(repoCtx.Err(), context.DeadlineExceeded)
+	cancelRepo()
+	if repoExpired {
+		return fmt.Errorf("repository scan exceeded --repo-timeout of %s", refsFlags.repoTimeout)
+	}
+	if err != nil {
+		return enhanceError("repository scan", err, 0)
+	}
+	printStatus("Found %d S3 references in code", len(references))
+	logSkippedFiles(repoScanner.SkippedFiles())
+
+	inv := report.NewReferenceInventory(refsFlags.repoPath, references, repoScanner.FilesScanned())
+	inv.Summary.SkippedFiles = scanner.CountSkipped(repoScanner.SkippedFiles())
+	inv.Version = GetVersion()
+	inv.Timestamp = time.Now()
+
+	writer := os.Stdout
+	if refsFlags.outputFile != "" {
+		f, err := os.Create(refsFlags.outputFile)
+		if err != nil {
+			return enhanceError("output file creation", err, 0)
+		}
+		defer func() { _ = f.Close() }()
+		writer = f
+	}
+	return report.WriteReferences(writer, refsFlags.format, inv)
+}
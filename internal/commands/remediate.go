@@ -0,0 +1,210 @@
+package commands
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	"github.com/ppiankov/s3spectre/internal/remediate"
+	"github.com/ppiankov/s3spectre/internal/s3"
+	"github.com/spf13/cobra"
+)
+
+var remediateFlags struct {
+	awsProfile     string
+	awsRegion      string
+	aws            awsClientFlags
+	dryRun         bool
+	apply          bool
+	actions        []string
+	noncurrentDays int
+	unusedTag      string
+	kmsKeyID       string
+}
+
+var remediateCmd = &cobra.Command{
+	Use:   "remediate REPORT",
+	Short: "Fix findings from a JSON report: lifecycle rules, unused tags, default encryption",
+	Long: `Reads a scan or discover JSON report and plans a fix for each finding that has
+one: a lifecycle rule expiring noncurrent versions for VERSION_SPRAWL, a tag
+for UNUSED_BUCKET, and default encryption for NO_ENCRYPTION.
+
+By default the command is a dry run that prints the AWS API calls it would
+make. With --apply it makes them, asking for confirmation before each action.
+Existing lifecycle rules and tags are kept, and buckets that already have
+default encryption are skipped.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runRemediate,
+}
+
+func init() {
+	remediateCmd.Flags().StringVar(&remediateFlags.awsProfile, "aws-profile", "", "AWS profile to use")
+	remediateCmd.Flags().StringVar(&remediateFlags.awsRegion, "aws-region", "", "AWS region used to look up bucket regions")
+	addAWSClientFlags(remediateCmd.Flags(), &remediateFlags.aws)
+	// Remediation acts in the account of the credentials only
+	_ = remediateCmd.Flags().MarkHidden("accounts")
+	_ = remediateCmd.Flags().MarkHidden("assume-role-arn")
+	remediateCmd.Flags().BoolVar(&remediateFlags.dryRun, "dry-run", true, "Print the AWS API calls without making them")
+	remediateCmd.Flags().BoolVar(&remediateFlags.apply, "apply", false, "Make the API calls, confirming each action on stdin")
+	remediateCmd.Flags().StringSliceVar(&remediateFlags.actions, "action", nil, "Only plan these actions: lifecycle, tag, or encryption (comma-separated, default all)")
+	remediateCmd.Flags().IntVar(&remediateFlags.noncurrentDays, "noncurrent-days", remediate.DefaultNoncurrentDays, "Days noncurrent versions are kept by the added lifecycle rule")
+	remediateCmd.Flags().StringVar(&remediateFlags.unusedTag, "unused-tag", remediate.DefaultUnusedTag, "Tag set on unused buckets, as key=value")
+	remediateCmd.Flags().StringVar(&remediateFlags.kmsKeyID, "kms-key-id", "", "Enable default encryption with this KMS key instead of SSE-S3")
+}
+
+func runRemediate(cmd *cobra.Command, args []string) error {
+	opts, err := remediateOptions()
+	if err != nil {
+		return err
+	}
+	if cmd.Flags().Changed("dry-run") && remediateFlags.dryRun == remediateFlags.apply {
+		return fmt.Errorf("--dry-run and --apply are mutually exclusive; --apply alone makes the API calls")
+	}
+	if err := remediateFlags.aws.validate(); err != nil {
+		return err
+	}
+	findings, err := remediate.ReadFindings(args[0])
+	if err != nil {
+		return enhanceError("report load", err, 0)
+	}
+	actions := remediate.Plan(findings, opts)
+	out := cmd.OutOrStdout()
+	if len(actions) == 0 {
+		_, _ = fmt.Fprintln(out, "No findings to remediate.")
+		return nil
+	}
+	if !remediateFlags.apply {
+		writeRemediationPlan(out, actions)
+		return nil
+	}
+
+	if remediateFlags.aws.backoff, err = retryBackoff(); err != nil {
+		return err
+	}
+	// No interrupt handler: Ctrl-C at a confirmation prompt exits at once
+	ctx := context.Background()
+	printStatus("Initializing AWS S3 client...")
+	s3Client, err := s3.NewClient(ctx, remediateFlags.awsProfile, remediateFlags.awsRegion, remediateFlags.aws.clientOptions(1)...)
+	if err != nil {
+		return enhanceError("S3 client initialization", err, 0)
+	}
+	accountID := resolveAccountID(ctx, s3Client)
+	clientFor := func(ctx context.Context, action remediate.Action) (remediate.API, error) {
+		region := action.Region
+		if region == "" {
+			var err error
+			if region, err = s3Client.BucketRegion(ctx, action.Bucket); err != nil {
+				return nil, err
+			}
+		}
+		return s3Client.ForRegion(region).GetClient(), nil
+	}
+	result := applyRemediation(ctx, cmd.InOrStdin(), out, actions, accountID, clientFor)
+	printStatus("Remediation finished: %d applied, %d skipped, %d failed", result.applied, result.skipped, result.failed)
+	if result.failed > 0 {
+		return fmt.Errorf("%d remediation actions failed", result.failed)
+	}
+	return nil
+}
+
+// remediateOptions builds the fix options from the flags
+func remediateOptions() (remediate.Options, error) {
+	opts := remediate.DefaultOptions()
+	for _, action := range remediateFlags.actions {
+		if !slices.Contains(remediate.Kinds, action) {
+			return opts, fmt.Errorf("invalid --action %q: expected %s", action, strings.Join(remediate.Kinds, ", "))
+		}
+	}
+	opts.Kinds = remediateFlags.actions
+	if remediateFlags.noncurrentDays < 1 {
+		return opts, fmt.Errorf("--noncurrent-days must be at least 1")
+	}
+	opts.NoncurrentDays = remediateFlags.noncurrentDays
+	key, value, ok := strings.Cut(remediateFlags.unusedTag, "=")
+	if !ok || key == "" {
+		return opts, fmt.Errorf("invalid --unused-tag %q: expected key=value", remediateFlags.unusedTag)
+	}
+	opts.UnusedTagKey, opts.UnusedTagValue = key, value
+	opts.KMSKeyID = remediateFlags.kmsKeyID
+	return opts, nil
+}
+
+// writeRemediationPlan prints each action and the API calls it would make
+func writeRemediationPlan(w io.Writer, actions []remediate.Action) {
+	_, _ = fmt.Fprintf(w, "Dry run: %d actions. Re-run with --apply to make these calls.\n", len(actions))
+	for _, action := range actions {
+		_, _ = fmt.Fprintf(w, "\n%s\n", actionHeading(action))
+		for _, call := range action.Calls() {
+			_, _ = fmt.Fprintf(w, "  %s\n", call)
+		}
+	}
+}
+
+// actionHeading names the bucket, the change and the finding it fixes
+func actionHeading(action remediate.Action) string {
+	heading := fmt.Sprintf("s3://%s: %s (%s)", action.Bucket, action.Description(), action.Finding)
+	if action.Account != "" {
+		heading += " in " + action.Account
+	}
+	return heading
+}
+
+// remediationResult counts what happened to the planned actions
+type remediationResult struct {
+	applied int
+	skipped int
+	failed  int
+}
+
+// applyRemediation asks before each action and applies the confirmed ones.
+// Actions on buckets of another account are skipped, since the credentials
+// cannot reach them.
+func applyRemediation(ctx context.Context, in io.Reader, out io.Writer, actions []remediate.Action, accountID string,
+	clientFor func(context.Context, remediate.Action) (remediate.API, error)) remediationResult {
+	var result remediationResult
+	answers := bufio.NewScanner(in)
+	for i, action := range actions {
+		_, _ = fmt.Fprintf(out, "\n%s\n", actionHeading(action))
+		if action.Account != "" && accountID != "" && action.Account != accountID {
+			_, _ = fmt.Fprintf(out, "  skipped: bucket is in account %s, credentials are for %s\n", action.Account, accountID)
+			result.skipped++
+			continue
+		}
+		for _, call := range action.Calls() {
+			_, _ = fmt.Fprintf(out, "  %s\n", call)
+		}
+		yes, answered := confirm(answers, out, "Apply? [y/N] ")
+		if !answered {
+			// No more input: treat the rest as declined
+			result.skipped += len(actions) - i
+			break
+		}
+		if !yes {
+			_, _ = fmt.Fprintln(out, "  skipped")
+			result.skipped++
+			continue
+		}
+		api, err := clientFor(ctx, action)
+		if err == nil {
+			err = remediate.Apply(ctx, api, action)
+		}
+		var skipped *remediate.SkippedError
+		if errors.As(err, &skipped) {
+			_, _ = fmt.Fprintf(out, "  %v\n", err)
+			result.skipped++
+			continue
+		}
+		if err != nil {
+			_, _ = fmt.Fprintf(out, "  failed: %v\n", err)
+			result.failed++
+			continue
+		}
+		_, _ = fmt.Fprintln(out, "  applied")
+		result.applied++
+	}
+	return result
+}
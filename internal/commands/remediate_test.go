@@ -0,0 +1,117 @@
+package commands
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	s3types "github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/aws/smithy-go"
+	"github.com/ppiankov/s3spectre/internal/remediate"
+	"github.com/ppiankov/s3spectre/internal/report"
+	"github.com/ppiankov/s3spectre/internal/rules"
+)
+
+func TestRemediateOptions(t *testing.T) {
+	saved := remediateFlags
+	defer func() { remediateFlags = saved }()
+
+	remediateFlags.actions = []string{"tag"}
+	remediateFlags.noncurrentDays = 7
+	remediateFlags.unusedTag = "lifecycle=retire"
+	opts, err := remediateOptions()
+	if err != nil {
+		t.Fatalf("remediateOptions: %v", err)
+	}
+	if opts.NoncurrentDays != 7 || opts.UnusedTagKey != "lifecycle" || opts.UnusedTagValue != "retire" || len(opts.Kinds) != 1 {
+		t.Errorf("options = %+v", opts)
+	}
+
+	for name, mutate := range map[string]func(){
+		"unknown action":  func() { remediateFlags.actions = []string{"delete"} },
+		"zero days":       func() { remediateFlags.noncurrentDays = 0 },
+		"tag without key": func() { remediateFlags.unusedTag = "=unused" },
+		"tag without =":   func() { remediateFlags.unusedTag = "unused" },
+	} {
+		remediateFlags.actions, remediateFlags.noncurrentDays, remediateFlags.unusedTag = nil, 30, remediate.DefaultUnusedTag
+		mutate()
+		if _, err := remediateOptions(); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+}
+
+func TestWriteRemediationPlan(t *testing.T) {
+	actions := remediate.Plan([]report.Finding{
+		{Status: rules.StatusUnusedBucket, Bucket: "old", Account: "111111111111"},
+	}, remediate.DefaultOptions())
+	var out bytes.Buffer
+	writeRemediationPlan(&out, actions)
+	for _, want := range []string{
+		"Dry run: 1 actions",
+		"s3://old: tag s3spectre:status=unused (UNUSED_BUCKET) in 111111111111",
+		`  GetBucketTagging {"Bucket":"old"}`,
+		"  PutBucketTagging ",
+	} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("plan missing %q:\n%s", want, out.String())
+		}
+	}
+}
+
+func TestApplyRemediation(t *testing.T) {
+	actions := remediate.Plan([]report.Finding{
+		{Status: rules.NoEncryption, Bucket: "a", Account: "111111111111"},
+		{Status: rules.NoEncryption, Bucket: "b", Account: "222222222222"},
+		{Status: rules.NoEncryption, Bucket: "c", Account: "111111111111"},
+		{Status: rules.NoEncryption, Bucket: "d", Account: "111111111111"},
+		{Status: rules.NoEncryption, Bucket: "e", Account: "111111111111"},
+		{Status: rules.NoEncryption, Bucket: "f", Account: "111111111111"},
+	}, remediate.DefaultOptions())
+	var applied []string
+	clientFor := func(ctx context.Context, action remediate.Action) (remediate.API, error) {
+		if action.Bucket == "d" {
+			return nil, errors.New("bucket region: access denied")
+		}
+		applied = append(applied, action.Bucket)
+		return nopAPI{encrypted: action.Bucket == "e"}, nil
+	}
+	var out bytes.Buffer
+	// a is confirmed, b is in another account, c is declined, d fails, e
+	// is already encrypted and input ends before f
+	result := applyRemediation(context.Background(), strings.NewReader("y\nn\nyes\ny\n"), &out, actions, "111111111111", clientFor)
+	if result != (remediationResult{applied: 1, skipped: 4, failed: 1}) {
+		t.Errorf("result = %+v\n%s", result, out.String())
+	}
+	if strings.Join(applied, ",") != "a,e" {
+		t.Errorf("applied = %v", applied)
+	}
+	for _, want := range []string{"skipped: bucket is in account 222222222222", "failed: bucket region: access denied", "skipped: default encryption is already AES256", "Apply? [y/N]"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("output missing %q:\n%s", want, out.String())
+		}
+	}
+}
+
+// nopAPI accepts default encryption, reporting it already set when
+// encrypted; the other calls are not made
+type nopAPI struct {
+	remediate.API
+	encrypted bool
+}
+
+func (n nopAPI) GetBucketEncryption(ctx context.Context, params *s3.GetBucketEncryptionInput, optFns ...func(*s3.Options)) (*s3.GetBucketEncryptionOutput, error) {
+	if !n.encrypted {
+		return nil, &smithy.GenericAPIError{Code: "ServerSideEncryptionConfigurationNotFoundError"}
+	}
+	return &s3.GetBucketEncryptionOutput{ServerSideEncryptionConfiguration: &s3types.ServerSideEncryptionConfiguration{
+		Rules: []s3types.ServerSideEncryptionRule{{ApplyServerSideEncryptionByDefault: &s3types.ServerSideEncryptionByDefault{SSEAlgorithm: s3types.ServerSideEncryptionAes256}}},
+	}}, nil
+}
+
+func (nopAPI) PutBucketEncryption(ctx context.Context, params *s3.PutBucketEncryptionInput, optFns ...func(*s3.Options)) (*s3.PutBucketEncryptionOutput, error) {
+	return &s3.PutBucketEncryptionOutput{}, nil
+}
@@ -5,6 +5,7 @@ import (
 
 	"github.com/ppiankov/s3spectre/internal/config"
 	"github.com/ppiankov/s3spectre/internal/logging"
+	"github.com/ppiankov/s3spectre/internal/rules"
 	"github.com/spf13/cobra"
 )
 
@@ -24,7 +25,7 @@ validates them against your AWS S3 infrastructure, and identifies missing
 buckets, unused buckets, stale prefixes, and lifecycle misconfigurations.
 
 Part of the Spectre family of infrastructure cleanup tools.`,
-	PersistentPreRun: func(cmd *cobra.Command, args []string) {
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
 		logging.Init(verbose)
 		loaded, err := config.Load(".")
 		if err != nil {
@@ -32,6 +33,7 @@ Part of the Spectre family of infrastructure cleanup tools.`,
 		} else {
 			cfg = loaded
 		}
+		return rules.SetSeverityOverrides(cfg.SeverityOverrides)
 	},
 }
 
@@ -52,5 +54,20 @@ func init() {
 	rootCmd.PersistentFlags().BoolVar(&verbose, "verbose", false, "Enable verbose logging")
 	rootCmd.AddCommand(scanCmd)
 	rootCmd.AddCommand(discoverCmd)
+	rootCmd.AddCommand(badgeCmd)
+	rootCmd.AddCommand(mergeCmd)
+	rootCmd.AddCommand(remediateCmd)
+	rootCmd.AddCommand(suggestLifecycleCmd)
+	rootCmd.AddCommand(enableMetricsCmd)
+	rootCmd.AddCommand(disableMetricsCmd)
+	rootCmd.AddCommand(refsCmd)
+	rootCmd.AddCommand(siteCmd)
+	rootCmd.AddCommand(serveCmd)
+	rootCmd.AddCommand(ackCmd)
+	rootCmd.AddCommand(resolveCmd)
+	rootCmd.AddCommand(reopenCmd)
+	rootCmd.AddCommand(explainCmd)
+	rootCmd.AddCommand(rulesCmd)
+	rootCmd.AddCommand(warehouseSQLCmd)
 	rootCmd.AddCommand(versionCmd)
 }
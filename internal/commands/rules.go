@@ -0,0 +1,141 @@
+package commands
+
+import (
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ppiankov/s3spectre/internal/plugin"
+	"github.com/spf13/cobra"
+)
+
+var rulesCmd = &cobra.Command{
+	Use:   "rules",
+	Short: "Work with custom check rules",
+}
+
+var rulesTestFlags struct {
+	run string
+}
+
+var rulesTestCmd = &cobra.Command{
+	Use:   "test PATH...",
+	Short: "Run custom rule tests against fixture buckets",
+	Long: `Runs check plugins over fixture BucketInfo documents and asserts the
+findings they report, so policy authors can test their org rules without an
+AWS account. Each PATH is a YAML test file or a directory searched for
+*.yaml and *.yml files. A case fails when an expected finding is missing or
+the plugin reports one that was not expected.`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: runRulesTest,
+}
+
+func init() {
+	rulesTestCmd.Flags().StringVar(&rulesTestFlags.run, "run", "", "Only run cases whose name contains this text")
+	rulesCmd.AddCommand(rulesTestCmd)
+}
+
+func runRulesTest(cmd *cobra.Command, args []string) error {
+	files, err := ruleTestFiles(args)
+	if err != nil {
+		return err
+	}
+	configured, err := configPlugins()
+	if err != nil {
+		return err
+	}
+
+	w := cmd.OutOrStdout()
+	passed, failed := 0, 0
+	for _, path := range files {
+		tf, err := plugin.LoadTestFile(path)
+		if err != nil {
+			return err
+		}
+		p, err := tf.ResolvePlugin(configured)
+		if err != nil {
+			return err
+		}
+		tf.Cases = filterRuleTestCases(tf.Cases, rulesTestFlags.run)
+		for _, result := range tf.RunTests(cmd.Context(), p) {
+			writeRuleTestResult(w, path, result)
+			if result.Passed() {
+				passed++
+			} else {
+				failed++
+			}
+		}
+	}
+	_, _ = fmt.Fprintf(w, "\n%d passed, %d failed\n", passed, failed)
+	if failed > 0 {
+		return fmt.Errorf("%d of %d rule tests failed", failed, passed+failed)
+	}
+	if passed == 0 {
+		return fmt.Errorf("no rule tests matched")
+	}
+	return nil
+}
+
+// ruleTestFiles expands directories into the YAML files under them, in
+// path order
+func ruleTestFiles(paths []string) ([]string, error) {
+	var files []string
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, err
+		}
+		if !info.IsDir() {
+			files = append(files, path)
+			continue
+		}
+		var found []string
+		err = filepath.WalkDir(path, func(p string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if ext := strings.ToLower(filepath.Ext(p)); !d.IsDir() && (ext == ".yaml" || ext == ".yml") {
+				found = append(found, p)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+		if len(found) == 0 {
+			return nil, fmt.Errorf("no rule test files in %s", path)
+		}
+		sort.Strings(found)
+		files = append(files, found...)
+	}
+	return files, nil
+}
+
+func filterRuleTestCases(cases []plugin.TestCase, run string) []plugin.TestCase {
+	if run == "" {
+		return cases
+	}
+	var kept []plugin.TestCase
+	for _, c := range cases {
+		if strings.Contains(c.Name, run) {
+			kept = append(kept, c)
+		}
+	}
+	return kept
+}
+
+func writeRuleTestResult(w io.Writer, path string, result plugin.CaseResult) {
+	status := "PASS"
+	if !result.Passed() {
+		status = "FAIL"
+	}
+	_, _ = fmt.Fprintf(w, "%s  %s: %s (%s)\n", status, path, result.Name, result.Duration.Round(time.Millisecond))
+	for _, failure := range result.Failures {
+		_, _ = fmt.Fprintf(w, "      %s\n", failure)
+	}
+}
@@ -0,0 +1,71 @@
+package commands
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+)
+
+func TestRunRulesTest(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("pass.yaml", `
+command: sh
+args: [-c, "cat >/dev/null; echo '{\"findings\":[{\"bucket\":\"logs\",\"rule\":\"ACME\"}]}'"]
+cases:
+  - name: flags logs
+    buckets: [{name: logs}]
+    expect: [{bucket: logs, rule: ACME}]
+`)
+	write("notes.txt", "not a test file")
+
+	saved := rulesTestFlags
+	t.Cleanup(func() { rulesTestFlags = saved })
+	run := func(args ...string) (string, error) {
+		cmd := &cobra.Command{}
+		cmd.SetContext(context.Background())
+		var out bytes.Buffer
+		cmd.SetOut(&out)
+		err := runRulesTest(cmd, args)
+		return out.String(), err
+	}
+
+	out, err := run(dir)
+	if err != nil {
+		t.Fatalf("runRulesTest: %v\n%s", err, out)
+	}
+	if !strings.Contains(out, "PASS  "+filepath.Join(dir, "pass.yaml")+": flags logs") || !strings.Contains(out, "1 passed, 0 failed") {
+		t.Errorf("unexpected output:\n%s", out)
+	}
+
+	write("fail.yaml", `
+command: sh
+args: [-c, "cat >/dev/null; echo '{\"findings\":[]}'"]
+cases:
+  - name: expects a finding
+    buckets: [{name: logs}]
+    expect: [{bucket: logs, rule: ACME}]
+`)
+	out, err = run(dir)
+	if err == nil || !strings.Contains(err.Error(), "1 of 2 rule tests failed") {
+		t.Errorf("expected failure, got %v\n%s", err, out)
+	}
+	if !strings.Contains(out, "missing finding: logs ACME") {
+		t.Errorf("expected the failure reason in output:\n%s", out)
+	}
+
+	rulesTestFlags.run = "no such case"
+	if _, err := run(dir); err == nil || !strings.Contains(err.Error(), "no rule tests matched") {
+		t.Errorf("expected no-match error, got %v", err)
+	}
+}
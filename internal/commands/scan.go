@@ -3,12 +3,16 @@ package commands
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"log/slog"
 	"os"
+	"path/filepath"
 	"strings"
 	"time"
 
+	"github.com/fatih/color"
 	"github.com/ppiankov/s3spectre/internal/analyzer"
 	"github.com/ppiankov/s3spectre/internal/baseline"
 	"github.com/ppiankov/s3spectre/internal/report"
@@ -20,8 +24,12 @@ import (
 
 var scanFlags struct {
 	repoPath            string
+	bucketsFile         string
+	refsStdin           bool
 	awsProfile          string
 	awsRegion           string
+	aws                 awsClientFlags
+	checkpoint          checkpointFlags
 	allRegions          bool
 	regions             []string
 	staleThresholdDays  int
@@ -30,15 +38,40 @@ var scanFlags struct {
 	maxConcurrency      int
 	outputFormat        string
 	outputFile          string
+	outputDir           string
 	failOnMissing       bool
 	failOnStale         bool
 	failOnVersionSprawl bool
 	failOnUnused        bool
+	failOnSeverity      string
 	includeReferences   bool
 	noProgress          bool
+	tuningPatch         string
+	deterministic       bool
 	timeout             time.Duration
+	repoTimeout         time.Duration
+	timeouts            timeoutFlags
 	baselinePath        string
 	updateBaseline      bool
+	glacier             bool
+	principals          []string
+	terraformPrincipals bool
+	tfState             []string
+	extraFiles          []string
+	archives            bool
+	decryptSOPS         bool
+	correlate           []string
+	dedupe              string
+	testRefs            string
+	changedOnly         bool
+	baseRef             string
+	blame               bool
+	notify              notifyFlags
+	github              githubFlags
+	jira                jiraFlags
+	openSearch          openSearchFlags
+	warehouse           warehouseFlags
+	state               stateFlags
 }
 
 var scanCmd = &cobra.Command{
@@ -52,30 +85,121 @@ version sprawl, and lifecycle misconfigurations.`,
 
 func init() {
 	scanCmd.Flags().StringVarP(&scanFlags.repoPath, "repo", "r", ".", "Path to repository to scan")
+	scanCmd.Flags().BoolVar(&scanFlags.refsStdin, "refs-stdin", false, "Read references as JSON or NDJSON from stdin instead of scanning a repository (same as --repo -)")
+	scanCmd.Flags().StringVar(&scanFlags.bucketsFile, "buckets-file", "", "Validate the buckets and prefixes listed in this file instead of scanning a repository")
 	scanCmd.Flags().StringVar(&scanFlags.awsProfile, "aws-profile", "", "AWS profile to use")
 	scanCmd.Flags().StringVar(&scanFlags.awsRegion, "aws-region", "", "AWS region (defaults to profile default)")
 	scanCmd.Flags().BoolVar(&scanFlags.allRegions, "all-regions", true, "Scan all enabled AWS regions")
 	scanCmd.Flags().StringSliceVar(&scanFlags.regions, "regions", nil, "Specific regions to scan (comma-separated)")
+	addAWSClientFlags(scanCmd.Flags(), &scanFlags.aws)
+	addCheckpointFlags(scanCmd.Flags(), &scanFlags.checkpoint)
 	scanCmd.Flags().IntVar(&scanFlags.staleThresholdDays, "stale-days", 90, "Days threshold for stale prefix detection")
 	scanCmd.Flags().IntVar(&scanFlags.unusedThresholdDays, "unused-threshold-days", 180, "Days threshold for unused bucket detection")
 	scanCmd.Flags().BoolVar(&scanFlags.checkUnused, "check-unused", false, "Enable unused bucket detection")
 	scanCmd.Flags().IntVar(&scanFlags.maxConcurrency, "concurrency", 10, "Max concurrent S3 API calls")
-	scanCmd.Flags().StringVarP(&scanFlags.outputFormat, "format", "f", "text", "Output format: text, json, sarif, or spectrehub")
+	scanCmd.Flags().VarP(newFormatList(&scanFlags.outputFormat, "text"), "format", "f", "Output format: text, json, sarif, spectrehub, lsp-diagnostics, tasks, csv, or markdown (repeatable with --output-dir)")
 	scanCmd.Flags().StringVarP(&scanFlags.outputFile, "output", "o", "", "Output file (default: stdout)")
+	scanCmd.Flags().StringVar(&scanFlags.outputDir, "output-dir", "", "Write one report per --format to this directory, with a manifest.json")
 	scanCmd.Flags().BoolVar(&scanFlags.failOnMissing, "fail-on-missing", false, "Exit with error if missing buckets found")
 	scanCmd.Flags().BoolVar(&scanFlags.failOnStale, "fail-on-stale", false, "Exit with error if stale prefixes found")
 	scanCmd.Flags().BoolVar(&scanFlags.failOnVersionSprawl, "fail-on-version-sprawl", false, "Exit with error if version sprawl detected")
 	scanCmd.Flags().BoolVar(&scanFlags.failOnUnused, "fail-on-unused", false, "Exit with error if unused buckets found")
+	scanCmd.Flags().StringVar(&scanFlags.failOnSeverity, "fail-on-severity", "", "Exit with error if any finding is at or above this severity: high, medium, low, or info")
 	scanCmd.Flags().BoolVar(&scanFlags.includeReferences, "include-references", false, "Include detailed reference list in output")
 	scanCmd.Flags().BoolVar(&scanFlags.noProgress, "no-progress", false, "Disable progress indicators")
+	scanCmd.Flags().StringVar(&scanFlags.tuningPatch, "tuning-patch", "", "Write suggested threshold adjustments to this file as a config patch")
+	scanCmd.Flags().BoolVar(&scanFlags.deterministic, "deterministic", false, "Reproducible output for snapshot tests: fixed timestamp, sorted lists, no run statistics or color")
 	scanCmd.Flags().DurationVar(&scanFlags.timeout, "timeout", 0, "Total operation timeout (e.g. 5m, 30s). 0 means no timeout")
+	scanCmd.Flags().DurationVar(&scanFlags.repoTimeout, "repo-timeout", 0, "Budget for the repository scan (0 means none)")
+	addTimeoutFlags(scanCmd.Flags(), &scanFlags.timeouts)
+	_ = scanCmd.Flags().MarkDeprecated("timeout", "use --repo-timeout, --inspect-timeout and --per-bucket-timeout, which skip slow buckets instead of aborting the run")
+	addNotifyFlags(scanCmd.Flags(), &scanFlags.notify)
+	addGitHubFlags(scanCmd.Flags(), &scanFlags.github)
+	addJiraFlags(scanCmd.Flags(), &scanFlags.jira)
+	addOpenSearchFlags(scanCmd.Flags(), &scanFlags.openSearch)
+	addWarehouseFlags(scanCmd.Flags(), &scanFlags.warehouse)
+	addStateFlags(scanCmd.Flags(), &scanFlags.state)
 	scanCmd.Flags().StringVar(&scanFlags.baselinePath, "baseline", "", "Path to previous JSON report for diff comparison")
 	scanCmd.Flags().BoolVar(&scanFlags.updateBaseline, "update-baseline", false, "Write current results as the new baseline")
+	scanCmd.Flags().BoolVar(&scanFlags.glacier, "glacier", false, "Also list Glacier vaults and cross-reference glacier:// and vault ARN references")
+	scanCmd.Flags().StringSliceVar(&scanFlags.principals, "principal", nil, "Application IAM role ARN to simulate against each reference's operation (repeatable)")
+	scanCmd.Flags().StringSliceVar(&scanFlags.tfState, "tf-state", nil, "Also read bucket references from this Terraform state file, e.g. saved with terraform state pull (repeatable)")
+	scanCmd.Flags().StringSliceVar(&scanFlags.extraFiles, "extra-file", nil, "Also read bucket references from this SQL dump or CSV/TSV table export (repeatable)")
+	scanCmd.Flags().BoolVar(&scanFlags.archives, "scan-archives", false, "Also scan config files inside .zip and .tar.gz artifacts in the repository, such as packaged Lambda bundles")
+	scanCmd.Flags().BoolVar(&scanFlags.decryptSOPS, "decrypt-sops", false, "Decrypt sops-encrypted files with the sops executable and your own KMS, age or PGP keys, and scan the plaintext for bucket references")
+	scanCmd.Flags().StringSliceVar(&scanFlags.correlate, "correlate", nil, "Correlate findings with this spectre/v1 report from another Spectre tool, such as iamspectre (repeatable)")
+	scanCmd.Flags().StringVar(&scanFlags.dedupe, "dedupe", scanner.DedupeByBucketPrefix, "Collapse repeated references: none, by-bucket, or by-bucket-prefix")
+	scanCmd.Flags().BoolVar(&scanFlags.terraformPrincipals, "principals-from-terraform", false, "Also simulate the IAM role ARNs found in the repository's Terraform files")
+	scanCmd.Flags().BoolVar(&scanFlags.changedOnly, "changed-only", false, "Scan only the files changed since --base-ref and validate only the references the change added")
+	scanCmd.Flags().StringVar(&scanFlags.baseRef, "base-ref", "origin/main", "Git ref the branch is compared with for --changed-only")
+	scanCmd.Flags().BoolVar(&scanFlags.blame, "blame", false, "Run git blame on reference lines and record the last author and commit on findings")
+	scanCmd.Flags().StringVar(&scanFlags.testRefs, "test-refs", analyzer.TestRefsMark, "Missing buckets referenced only from tests, fixtures or examples: mark, exclude, or off")
 }
 
 func runScan(cmd *cobra.Command, args []string) error {
 	// Apply config file defaults for flags not explicitly set
 	applyConfigToScanFlags(cmd)
+	if err := validateSeverityGate(scanFlags.failOnSeverity); err != nil {
+		return err
+	}
+	if err := validateOutput(scanFlags.outputFormat, scanFlags.outputFile, scanFlags.outputDir); err != nil {
+		return err
+	}
+	if err := scanFlags.aws.validate(); err != nil {
+		return err
+	}
+	if err := scanFlags.timeouts.validate(); err != nil {
+		return err
+	}
+	if err := scanFlags.jira.validate(); err != nil {
+		return err
+	}
+	if err := scanFlags.openSearch.validate(); err != nil {
+		return err
+	}
+	if err := scanFlags.warehouse.validate(); err != nil {
+		return err
+	}
+	if err := scanFlags.state.validate(); err != nil {
+		return err
+	}
+	if err := scanFlags.github.validate(); err != nil {
+		return err
+	}
+	if err := scanFlags.notify.validate(); err != nil {
+		return err
+	}
+	if scanFlags.repoTimeout < 0 {
+		return fmt.Errorf("--repo-timeout must not be negative")
+	}
+	if err := validateReferenceSource(cmd); err != nil {
+		return err
+	}
+	if err := scanner.ValidateDedupe(scanFlags.dedupe); err != nil {
+		return err
+	}
+	if err := s3.ValidatePrincipals(scanFlags.principals); err != nil {
+		return err
+	}
+	classification, err := dataClassification()
+	if err != nil {
+		return err
+	}
+	external, err := externalBuckets(true)
+	if err != nil {
+		return err
+	}
+	tests, err := testPaths(scanFlags.testRefs)
+	if err != nil {
+		return err
+	}
+	envelopes, err := loadEnvelopes(scanFlags.correlate)
+	if err != nil {
+		return err
+	}
+	if scanFlags.aws.backoff, err = retryBackoff(); err != nil {
+		return err
+	}
 
 	ctx := context.Background()
 	if scanFlags.timeout > 0 {
@@ -83,43 +207,164 @@ func runScan(cmd *cobra.Command, args []string) error {
 		ctx, cancel = context.WithTimeout(ctx, scanFlags.timeout)
 		defer cancel()
 	}
+	ctx, stopInterrupt := notifyInterrupt(ctx)
+	defer stopInterrupt()
 	start := time.Now()
+	stats := report.NewRunStats()
 
 	// Check if we're running in a terminal (for progress indicators)
 	isTTY := term.IsTerminal(int(os.Stderr.Fd()))
 	showProgress := isTTY && !scanFlags.noProgress
 
-	// 1. Scan repository for S3 references
-	printStatus("Scanning repository: %s", scanFlags.repoPath)
+	// 1. Scan repository for S3 references, or read the expected list
 	repoScanner := scanner.NewRepoScanner(scanFlags.repoPath)
-	references, err := repoScanner.Scan(ctx)
-	if err != nil {
-		return enhanceError("repository scan", err, scanFlags.maxConcurrency)
+	var references []scanner.Reference
+	if readsRefsStdin() {
+		printStatus("Reading references from stdin")
+		references, err = scanner.ReadReferences(scanStdin, scanner.StdinSource)
+		if err != nil {
+			return enhanceError("reading references from stdin", err, 0)
+		}
+		stats.Phase("refs_stdin", start)
+		stats.CountReferences(references)
+		printStatus("Read %d S3 references", len(references))
+	} else if scanFlags.bucketsFile != "" {
+		printStatus("Reading expected buckets: %s", scanFlags.bucketsFile)
+		references, err = scanner.ReadBucketList(scanFlags.bucketsFile)
+		if err != nil {
+			return enhanceError("buckets file", err, 0)
+		}
+		stats.Phase("buckets_file", start)
+		stats.CountReferences(references)
+		printStatus("Found %d expected buckets and prefixes", len(references))
+	} else {
+		printStatus("Scanning repository: %s", scanFlags.repoPath)
+		repoScanner.SetScanVaults(scanFlags.glacier)
+		repoScanner.SetScanPrincipals(scanFlags.terraformPrincipals)
+		repoScanner.SetTFStateFiles(scanFlags.tfState)
+		repoScanner.SetExtraFiles(scanFlags.extraFiles)
+		repoScanner.SetScanArchives(scanFlags.archives)
+		repoScanner.SetDecryptSOPS(scanFlags.decryptSOPS)
+		repoScanner.SetDedupe(scanFlags.dedupe)
+		repoCtx, cancelRepo := phaseContext(ctx, scanFlags.repoTimeout)
+		if scanFlags.changedOnly {
+			var changes scanner.Changes
+			changes, err = scanner.GitChanges(repoCtx, scanFlags.repoPath, scanFlags.baseRef)
+			if err != nil {
+				cancelRepo()
+				return fmt.Errorf("--changed-only: %w", err)
+			}
+			printStatus("Scanning %d files changed since %s", len(changes), scanFlags.baseRef)
+			repoScanner.SetChanges(changes)
+		}
+		references, err = repoScanner.Scan(repoCtx)
+		repoExpired := ctx.Err() == nil && errors.Is(repoCtx.Err(), context.DeadlineExceeded)
+		cancelRepo()
+		if repoExpired {
+			return fmt.Errorf("repository scan exceeded --repo-timeout of %s", scanFlags.repoTimeout)
+		}
+		if interrupted(ctx) {
+			return interruptedBefore("repository scan")
+		}
+		if err != nil {
+			return enhanceError("repository scan", err, scanFlags.maxConcurrency)
+		}
+		stats.Phase("repository_scan", start)
+		stats.FilesScanned = repoScanner.FilesScanned()
+		stats.SkippedFiles = scanner.CountSkipped(repoScanner.SkippedFiles())
+		logSkippedFiles(repoScanner.SkippedFiles())
+		stats.CountReferences(references)
+		printStatus("Found %d S3 references in code", len(references))
+		if scanFlags.glacier {
+			printStatus("Found %d Glacier vault references in code", len(repoScanner.Vaults()))
+		}
 	}
-	printStatus("Found %d S3 references in code", len(references))
+	// References to Google Cloud Storage and Azure Blob Storage are listed
+	// in the report, not checked
+	references, unsupported := scanner.SplitProviders(references)
+	unsupported = append(unsupported, repoScanner.UnsupportedRefs()...)
+	if len(unsupported) > 0 {
+		printStatus("Found %d references to unsupported providers (not checked)", len(unsupported))
+	}
+	if scanFlags.blame {
+		phaseStart := time.Now()
+		printStatus("Running git blame on references...")
+		if err := scanner.BlameReferences(ctx, references); err != nil {
+			if interrupted(ctx) {
+				return interruptedBefore("git blame")
+			}
+			return fmt.Errorf("git blame: %w", err)
+		}
+		stats.Phase("blame", phaseStart)
+	}
+	principals := scanPrincipals(repoScanner.Principals())
 
 	// 2. Initialize S3 client
+	phaseStart := time.Now()
 	printStatus("Initializing AWS S3 client...")
-	s3Client, err := s3.NewClient(ctx, scanFlags.awsProfile, scanFlags.awsRegion)
+	s3Client, err := s3.NewClient(ctx, scanFlags.awsProfile, scanFlags.awsRegion, scanFlags.aws.clientOptions(scanFlags.maxConcurrency)...)
+	if interrupted(ctx) {
+		return interruptedBefore("S3 client initialization")
+	}
 	if err != nil {
 		return enhanceError("S3 client initialization", err, scanFlags.maxConcurrency)
 	}
 	slog.Info("Connected to AWS",
 		slog.String("region", s3Client.GetRegion()),
 		slog.String("profile", scanFlags.awsProfile),
+		slog.String("role", scanFlags.aws.assumeRole.RoleARN),
 	)
+	accountID := resolveAccountID(ctx, s3Client)
+	accounts, err := scanFlags.aws.accountClients(ctx, s3Client, accountID)
+	if interrupted(ctx) {
+		return interruptedBefore("S3 client initialization")
+	}
+	if err != nil {
+		return enhanceError("S3 client initialization", err, scanFlags.maxConcurrency)
+	}
+	stats.Phase("client_init", phaseStart)
+
+	ctx, stopWatchdog := watchCredentials(ctx, s3Client, scanFlags.checkpoint)
+	defer stopWatchdog()
+	checkpoint, err := openCheckpoint("scan", scanFlags.checkpoint)
+	if err != nil {
+		return err
+	}
 
 	// 3. Configure inspector
-	inspector := s3.NewInspector(s3Client, scanFlags.maxConcurrency)
+	progress := s3.NewProgress()
+	newInspector := func(client *s3.Client) *s3.Inspector {
+		inspector := s3.NewInspector(client, scanFlags.maxConcurrency)
+		inspector.SetCheckpoint(checkpoint)
+		inspector.SetProgress(progress)
+		inspector.SetBucketTimeout(scanFlags.timeouts.perBucket)
+		inspector.SetBucketDeadline(scanFlags.timeouts.deadline)
+		inspector.SetEnvironments(environments())
+		if len(scanFlags.regions) > 0 {
+			inspector.SetRegions(scanFlags.regions)
+		} else if scanFlags.allRegions {
+			inspector.SetAllRegions(true)
+		}
+		// Set up progress callback
+		if showProgress {
+			inspector.SetProgressCallback(func(current, total int, message string) {
+				if total > 0 {
+					slog.Debug("Scan progress", slog.Int("current", current), slog.Int("total", total), slog.String("message", message))
+				} else {
+					slog.Debug("Scan progress", slog.String("message", message))
+				}
+			})
+		}
+		return inspector
+	}
+	inspector := newInspector(s3Client)
 
 	// Set up regions
 	if len(scanFlags.regions) > 0 {
 		// Specific regions provided
-		inspector.SetRegions(scanFlags.regions)
 		printStatus("Scanning regions: %s", strings.Join(scanFlags.regions, ", "))
 	} else if scanFlags.allRegions {
 		// Scan all regions
-		inspector.SetAllRegions(true)
 		printStatus("Scanning all enabled AWS regions")
 	} else {
 		// Single region (default)
@@ -130,34 +375,95 @@ func runScan(cmd *cobra.Command, args []string) error {
 		printStatus("Scanning region: %s", region)
 	}
 
-	// Set up progress callback
-	if showProgress {
-		inspector.SetProgressCallback(func(current, total int, message string) {
-			if total > 0 {
-				slog.Debug("Scan progress", slog.Int("current", current), slog.Int("total", total), slog.String("message", message))
-			} else {
-				slog.Debug("Scan progress", slog.String("message", message))
-			}
-		})
-	}
-
 	// 4. Inspect AWS S3
+	phaseStart = time.Now()
 	printStatus("Inspecting AWS S3 buckets...")
-	bucketInfo, err := inspector.InspectBuckets(ctx, references)
-	if err != nil {
+	inspectCtx, cancelInspect := phaseContext(ctx, scanFlags.timeouts.inspect)
+	inspected := external.Internal(references)
+	stopProgress := func() {}
+	if showProgress {
+		stopProgress = startProgressDisplay(os.Stderr, progress)
+	}
+	var bucketInfo map[string]*s3.BucketInfo
+	if scanFlags.aws.multiAccount() {
+		bucketInfo, err = inspectAccounts(inspectCtx, accounts, inspected, newInspector, environments())
+	} else {
+		bucketInfo, err = inspector.InspectBuckets(inspectCtx, inspected)
+	}
+	stopProgress()
+	cancelInspect()
+	if expired := checkpointOnExpiry(ctx, checkpoint, scanFlags.checkpoint); expired != nil {
+		return expired
+	}
+	// An interrupted run reports what was inspected before the signal
+	var truncation *report.Truncation
+	if interrupted(ctx) {
+		if bucketInfo == nil {
+			bucketInfo = make(map[string]*s3.BucketInfo)
+		}
+		truncation = report.NewTruncation(errInterrupted.Error(), referencedBuckets(inspected), bucketInfo)
+	} else if err != nil {
 		return enhanceError("S3 inspection", err, scanFlags.maxConcurrency)
+	} else {
+		discardCheckpoint(scanFlags.checkpoint)
 	}
+	stats.Phase("inspection", phaseStart)
 	printStatus("Inspected %d buckets", len(bucketInfo))
+	stats.SlowestBuckets = reportSlowestBuckets(os.Stderr, progress, showProgress)
 
 	// 5. Analyze drift
+	phaseStart = time.Now()
 	printStatus("Analyzing drift...")
 	config := analyzer.Config{
 		StaleThresholdDays:   scanFlags.staleThresholdDays,
 		UnusedThresholdDays:  scanFlags.unusedThresholdDays,
 		CheckUnused:          scanFlags.checkUnused,
 		UnusedScoreThreshold: 150, // Default threshold
+		Classification:       classification,
+		External:             external,
+		Environments:         environments(),
+		TestPaths:            tests,
 	}
 	analysis := analyzer.Analyze(references, bucketInfo, config)
+	warnTimedOut(analysis.Summary.TimedOutBuckets)
+	warnDeadlineSkips(analysis.Summary.DeadlineSkips)
+	stats.Phase("analysis", phaseStart)
+	if scanFlags.glacier && truncation == nil {
+		phaseStart = time.Now()
+		printStatus("Listing Glacier vaults...")
+		vaults, err := inspector.ListVaults(ctx)
+		switch {
+		case interrupted(ctx):
+			truncation = report.NewTruncation(errInterrupted.Error(), nil, bucketInfo)
+		case err != nil:
+			return enhanceError("Glacier vault listing", err, scanFlags.maxConcurrency)
+		default:
+			analyzer.AnalyzeVaults(analysis, repoScanner.Vaults(), vaults)
+			stats.Phase("glacier", phaseStart)
+		}
+	}
+	if len(principals) > 0 && truncation == nil {
+		phaseStart = time.Now()
+		printStatus("Simulating access for %d principals...", len(principals))
+		checks, err := inspector.SimulateAccess(ctx, principals, references, bucketInfo)
+		switch {
+		case interrupted(ctx):
+			truncation = report.NewTruncation(errInterrupted.Error(), nil, bucketInfo)
+		case err != nil:
+			// A failed simulation leaves permissions unknown rather than
+			// discarding the rest of the scan
+			slog.Warn("Skipping IAM policy simulation; permission checks are unknown", slog.String("error", err.Error()))
+		default:
+			analyzer.AnalyzePermissions(analysis, checks)
+			warnPermissionsUnknown(analysis.Summary.PermissionsUnknown)
+			stats.Phase("permission_simulation", phaseStart)
+		}
+	}
+	if len(envelopes) > 0 {
+		analyzer.CorrelateScan(analysis, envelopes)
+	}
+	stats.Finish(s3Client, checkpoint)
+	printRequestCost(stats.RequestCost)
 
 	// 6. Generate report
 	reportData := report.Data{
@@ -165,53 +471,66 @@ func runScan(cmd *cobra.Command, args []string) error {
 		Version:   GetVersion(),
 		Timestamp: time.Now(),
 		Config: report.Config{
-			RepoPath:           scanFlags.repoPath,
+			RepoPath:           scanRepoPath(),
+			BucketsFile:        scanFlags.bucketsFile,
 			AWSProfile:         scanFlags.awsProfile,
 			AWSRegion:          s3Client.GetRegion(),
+			AccountID:          accountID,
 			StaleThresholdDays: scanFlags.staleThresholdDays,
+			ChangedSince:       changedSince(),
 		},
-		Summary: analysis.Summary,
-		Buckets: analysis.Buckets,
+		Summary:      analysis.Summary,
+		Buckets:      analysis.Buckets,
+		Vaults:       analysis.Vaults,
+		Permissions:  analysis.Permissions,
+		Correlations: analysis.Correlations,
+		Stats:        stats,
+		Truncated:    truncation,
+	}
+	reportData.UnsupportedReferences = unsupported
+	if truncation == nil {
+		reportData.TuningHints = analyzer.SuggestScanTuning(analysis, config)
+	}
+	if scanFlags.aws.multiAccount() {
+		reportData.Accounts = analyzer.SummarizeScanAccounts(analysis, accountID)
 	}
 
-	if scanFlags.includeReferences {
+	// Editor diagnostics are anchored on reference locations, and CSV rows
+	// and GitHub issues and Jira tickets list them
+	if scanFlags.includeReferences || scanFlags.github.repo != "" || scanFlags.jira.enabled() || scanFlags.openSearch.enabled() || scanFlags.warehouse.enabled() || containsFormat(scanFlags.outputFormat, "lsp-diagnostics") || containsFormat(scanFlags.outputFormat, "csv") || containsFormat(scanFlags.outputFormat, "markdown") {
 		reportData.References = references
+		reportData.VaultReferences = repoScanner.Vaults()
+	}
+
+	if scanFlags.deterministic {
+		color.NoColor = true
+		reportData.MakeDeterministic()
 	}
 
-	// Determine output writer
-	writer := os.Stdout
-	if scanFlags.outputFile != "" {
-		f, err := os.Create(scanFlags.outputFile)
+	// Baseline comparison; a partial report is not compared
+	if scanFlags.baselinePath != "" && truncation == nil {
+		reportData.Changes, err = compareBaseline(scanFlags.baselinePath, scanFlags.outputFormat,
+			baseline.FlattenScanFindings(reportData), baseline.LoadScanBaseline)
 		if err != nil {
-			return enhanceError("output file creation", err, scanFlags.maxConcurrency)
+			return enhanceError("baseline load", err, scanFlags.maxConcurrency)
 		}
-		defer func() { _ = f.Close() }()
-		writer = f
 	}
 
-	// Generate report
-	reporter, err := selectReporter(scanFlags.outputFormat, writer)
+	// Generate reports
+	err = writeReports(scanFlags.outputFormat, scanFlags.outputFile, scanFlags.outputDir, "scan", reportData.Timestamp, func(reporter report.Reporter) error {
+		return reporter.Generate(reportData)
+	})
 	if err != nil {
-		return err
-	}
-
-	if err := reporter.Generate(reportData); err != nil {
 		return enhanceError("report generation", err, scanFlags.maxConcurrency)
 	}
-
-	// Baseline comparison
-	if scanFlags.baselinePath != "" {
-		currentFindings := baseline.FlattenScanFindings(reportData)
-		baselineFindings, err := baseline.LoadScanBaseline(scanFlags.baselinePath)
-		if err != nil {
-			return enhanceError("baseline load", err, scanFlags.maxConcurrency)
+	// A partial report is neither compared with nor written as a baseline
+	if truncation != nil {
+		return interruptedRun(checkpoint, scanFlags.checkpoint, truncation)
+	}
+	if scanFlags.tuningPatch != "" {
+		if err := writeTuningPatch(scanFlags.tuningPatch, reportData.TuningHints); err != nil {
+			return enhanceError("tuning patch write", err, scanFlags.maxConcurrency)
 		}
-		diff := baseline.Diff(currentFindings, baselineFindings)
-		slog.Info("Baseline comparison",
-			slog.Int("new", len(diff.New)),
-			slog.Int("resolved", len(diff.Resolved)),
-			slog.Int("unchanged", len(diff.Unchanged)),
-		)
 	}
 
 	// Write updated baseline if requested
@@ -226,6 +545,31 @@ func runScan(cmd *cobra.Command, args []string) error {
 		slog.Info("Updated baseline", slog.String("path", scanFlags.outputFile))
 	}
 
+	if scanFlags.notify.enabled() {
+		sendNotifications(ctx, scanFlags.notify, "scan", scanRepoPath(), reportData.Timestamp,
+			report.ScanFindings(reportData), reportData.Changes)
+	}
+	// A --changed-only scan validates part of the references, so it cannot
+	// tell that the others' findings were resolved
+	if scanFlags.github.repo != "" {
+		complete := !scanFlags.changedOnly && completeRun(reportData.Truncated, false,
+			reportData.Summary.TimedOutBuckets, reportData.Summary.DeadlineSkips)
+		fileGitHubIssues(ctx, scanFlags.github, "scan", accountID, scanTarget(), report.ScanFindings(reportData), complete)
+	}
+	if scanFlags.jira.enabled() {
+		createJiraTickets(ctx, scanFlags.jira, "scan", report.ScanFindings(reportData), reportData)
+	}
+	if scanFlags.openSearch.enabled() {
+		indexFindings(ctx, scanFlags.openSearch, "scan", scanRepoPath(), reportData.Timestamp, report.ScanFindings(reportData))
+	}
+	if scanFlags.warehouse.enabled() {
+		exportWarehouse(ctx, scanFlags.warehouse, s3Client, "scan", scanRepoPath(), reportData.Timestamp, report.ScanFindings(reportData))
+	}
+	if scanFlags.state.enabled() {
+		recordRun(ctx, scanFlags.state, s3Client.GetConfig(), "scan", scanRepoPath(), reportData.Timestamp,
+			baseline.FlattenScanFindings(reportData), reportData.Truncated == nil && !scanFlags.changedOnly)
+	}
+
 	prefixCount := 0
 	prefixes := make(map[string]struct{})
 	for _, ref := range references {
@@ -241,9 +585,14 @@ func runScan(cmd *cobra.Command, args []string) error {
 	findingCount := len(analysis.Summary.MissingBuckets) +
 		len(analysis.Summary.UnusedBuckets) +
 		len(analysis.Summary.MissingPrefixes) +
+		len(analysis.Summary.PrefixNearMisses) +
 		len(analysis.Summary.StalePrefixes) +
 		len(analysis.Summary.VersionSprawl) +
-		len(analysis.Summary.LifecycleMisconfig)
+		len(analysis.Summary.LifecycleMisconfig) +
+		len(analysis.Summary.OrphanedVaults) +
+		len(analysis.Summary.MissingVaults) +
+		len(analysis.Summary.PermissionGaps) +
+		len(analysis.Summary.ReplicationDestinations)
 	slog.Info("Scan complete",
 		slog.Int("bucket_count", analysis.Summary.TotalBuckets),
 		slog.Int("prefix_count", prefixCount),
@@ -267,11 +616,121 @@ func runScan(cmd *cobra.Command, args []string) error {
 	if scanFlags.failOnUnused && len(analysis.Summary.UnusedBuckets) > 0 {
 		return fmt.Errorf("found %d unused buckets", len(analysis.Summary.UnusedBuckets))
 	}
+	if err := checkSeverityGate(baseline.FlattenScanFindings(reportData), scanFlags.failOnSeverity); err != nil {
+		return err
+	}
 
 	return nil
 }
 
+// referencedBuckets returns the distinct buckets referenced in code
+// scanStdin is where --refs-stdin reads references from
+var scanStdin io.Reader = os.Stdin
+
+// readsRefsStdin reports whether references are piped in rather than scanned
+func readsRefsStdin() bool {
+	return scanFlags.refsStdin || scanFlags.repoPath == "-"
+}
+
+// validateReferenceSource rejects conflicting reference sources and flags
+// that only make sense with a repository
+func validateReferenceSource(cmd *cobra.Command) error {
+	if cmd.Flags().Lookup("base-ref").Changed && !scanFlags.changedOnly {
+		return fmt.Errorf("--base-ref requires --changed-only")
+	}
+	source := ""
+	switch {
+	case readsRefsStdin() && scanFlags.bucketsFile != "":
+		return fmt.Errorf("--buckets-file cannot be combined with --refs-stdin or --repo -")
+	case scanFlags.refsStdin:
+		source = "--refs-stdin"
+	case scanFlags.repoPath == "-":
+		source = "--repo -"
+	case scanFlags.bucketsFile != "":
+		source = "--buckets-file"
+	default:
+		return nil
+	}
+	for _, name := range []string{"repo", "repo-timeout", "glacier", "principals-from-terraform", "tf-state", "extra-file", "scan-archives", "decrypt-sops", "dedupe", "changed-only"} {
+		if name == "repo" && scanFlags.repoPath == "-" {
+			continue
+		}
+		if cmd.Flags().Lookup(name).Changed {
+			return fmt.Errorf("%s cannot be combined with --%s", source, name)
+		}
+	}
+	return nil
+}
+
+// scanRepoPath is the repository recorded in the report; runs that read
+// references from a file or stdin scan none
+func scanRepoPath() string {
+	switch {
+	case readsRefsStdin():
+		return "-"
+	case scanFlags.bucketsFile != "":
+		return ""
+	}
+	return scanFlags.repoPath
+}
+
+// scanTarget identifies what a scan covered for tracker scopes: the
+// repository or buckets file as an absolute path, so the same checkout
+// reached through different relative paths shares its issues
+func scanTarget() string {
+	target := scanRepoPath()
+	if target == "" {
+		target = scanFlags.bucketsFile
+	}
+	if target == "" || target == "-" {
+		return target
+	}
+	if abs, err := filepath.Abs(target); err == nil {
+		return abs
+	}
+	return target
+}
+
+// changedSince is the base ref of a --changed-only scan, recorded in the
+// report
+func changedSince() string {
+	if !scanFlags.changedOnly {
+		return ""
+	}
+	return scanFlags.baseRef
+}
+
+func referencedBuckets(refs []scanner.Reference) []string {
+	seen := make(map[string]bool)
+	var buckets []string
+	for _, ref := range refs {
+		if !seen[ref.Bucket] {
+			seen[ref.Bucket] = true
+			buckets = append(buckets, ref.Bucket)
+		}
+	}
+	return buckets
+}
+
+// scanPrincipals merges the --principal flags with role ARNs found in
+// Terraform, keeping the first occurrence of each
+func scanPrincipals(fromTerraform []string) []string {
+	var principals []string
+	seen := make(map[string]bool)
+	for _, p := range append(append([]string(nil), scanFlags.principals...), fromTerraform...) {
+		if !seen[p] {
+			principals = append(principals, p)
+			seen[p] = true
+		}
+	}
+	return principals
+}
+
 func applyConfigToScanFlags(cmd *cobra.Command) {
+	if !cmd.Flags().Lookup("ca-bundle").Changed && cfg.CABundle != "" {
+		scanFlags.aws.http.CABundle = cfg.CABundle
+	}
+	scanFlags.aws.endpoints = cfg.Endpoints
 	if !cmd.Flags().Lookup("aws-region").Changed && cfg.Region != "" {
 		scanFlags.awsRegion = cfg.Region
 	}
@@ -286,4 +745,14 @@ func applyConfigToScanFlags(cmd *cobra.Command) {
 			scanFlags.timeout = d
 		}
 	}
+	applyNotifyConfig(cmd, &scanFlags.notify)
+	if !cmd.Flags().Lookup("principal").Changed && len(cfg.Principals) > 0 {
+		scanFlags.principals = cfg.Principals
+	}
+	if !cmd.Flags().Lookup("test-refs").Changed && cfg.TestPaths.Mode != "" {
+		scanFlags.testRefs = cfg.TestPaths.Mode
+	}
+	if !cmd.Flags().Lookup("dedupe").Changed && cfg.Dedupe != "" {
+		scanFlags.dedupe = cfg.Dedupe
+	}
 }
@@ -6,6 +6,7 @@ import (
 	"testing"
 
 	"github.com/ppiankov/s3spectre/internal/report"
+	"github.com/spf13/cobra"
 )
 
 func TestScanFlagDefaults(t *testing.T) {
@@ -67,3 +68,136 @@ func TestScanSelectReporter(t *testing.T) {
 		t.Fatalf("unexpected error message: %v", err)
 	}
 }
+
+func TestValidateBucketsFile(t *testing.T) {
+	saved := scanFlags.bucketsFile
+	t.Cleanup(func() { scanFlags.bucketsFile = saved })
+
+	newCmd := func() *cobra.Command {
+		cmd := &cobra.Command{}
+		cmd.Flags().String("repo", ".", "")
+		cmd.Flags().Duration("repo-timeout", 0, "")
+		cmd.Flags().Bool("glacier", false, "")
+		cmd.Flags().Bool("principals-from-terraform", false, "")
+		cmd.Flags().StringSlice("tf-state", nil, "")
+		cmd.Flags().StringSlice("extra-file", nil, "")
+		cmd.Flags().Bool("scan-archives", false, "")
+		cmd.Flags().Bool("decrypt-sops", false, "")
+		cmd.Flags().String("dedupe", "by-bucket-prefix", "")
+		cmd.Flags().Bool("changed-only", false, "")
+		cmd.Flags().String("base-ref", "origin/main", "")
+		return cmd
+	}
+
+	scanFlags.bucketsFile = ""
+	cmd := newCmd()
+	_ = cmd.Flags().Set("repo", "./app")
+	if err := validateReferenceSource(cmd); err != nil {
+		t.Errorf("--repo without --buckets-file: %v", err)
+	}
+
+	scanFlags.bucketsFile = "buckets.txt"
+	if err := validateReferenceSource(newCmd()); err != nil {
+		t.Errorf("--buckets-file alone: %v", err)
+	}
+	for _, name := range []string{"repo", "glacier"} {
+		cmd := newCmd()
+		value := "true"
+		if name == "repo" {
+			value = "./app"
+		}
+		_ = cmd.Flags().Set(name, value)
+		err := validateReferenceSource(cmd)
+		if err == nil || !strings.Contains(err.Error(), "--"+name) {
+			t.Errorf("--buckets-file with --%s: got %v", name, err)
+		}
+	}
+}
+
+func TestValidateChangedOnly(t *testing.T) {
+	saved := scanFlags
+	t.Cleanup(func() { scanFlags = saved })
+
+	newCmd := func() *cobra.Command {
+		cmd := &cobra.Command{}
+		cmd.Flags().String("repo", ".", "")
+		cmd.Flags().Duration("repo-timeout", 0, "")
+		cmd.Flags().Bool("glacier", false, "")
+		cmd.Flags().Bool("principals-from-terraform", false, "")
+		cmd.Flags().StringSlice("tf-state", nil, "")
+		cmd.Flags().StringSlice("extra-file", nil, "")
+		cmd.Flags().Bool("scan-archives", false, "")
+		cmd.Flags().Bool("decrypt-sops", false, "")
+		cmd.Flags().String("dedupe", "by-bucket-prefix", "")
+		cmd.Flags().BoolVar(&scanFlags.changedOnly, "changed-only", false, "")
+		cmd.Flags().StringVar(&scanFlags.baseRef, "base-ref", "origin/main", "")
+		return cmd
+	}
+
+	scanFlags.bucketsFile = ""
+	scanFlags.refsStdin = false
+	scanFlags.repoPath = "."
+	cmd := newCmd()
+	_ = cmd.Flags().Set("base-ref", "origin/release")
+	if err := validateReferenceSource(cmd); err == nil || !strings.Contains(err.Error(), "--base-ref requires --changed-only") {
+		t.Errorf("--base-ref alone: got %v", err)
+	}
+	_ = cmd.Flags().Set("changed-only", "true")
+	if err := validateReferenceSource(cmd); err != nil {
+		t.Errorf("--changed-only with --base-ref: %v", err)
+	}
+	if got := changedSince(); got != "origin/release" {
+		t.Errorf("changedSince() = %q, want origin/release", got)
+	}
+
+	scanFlags.bucketsFile = "buckets.txt"
+	if err := validateReferenceSource(cmd); err == nil || !strings.Contains(err.Error(), "--changed-only") {
+		t.Errorf("--buckets-file with --changed-only: got %v", err)
+	}
+}
+
+func TestValidateRefsStdin(t *testing.T) {
+	saved := scanFlags
+	t.Cleanup(func() { scanFlags = saved })
+
+	newCmd := func() *cobra.Command {
+		cmd := &cobra.Command{}
+		cmd.Flags().String("repo", ".", "")
+		cmd.Flags().Duration("repo-timeout", 0, "")
+		cmd.Flags().Bool("glacier", false, "")
+		cmd.Flags().Bool("principals-from-terraform", false, "")
+		cmd.Flags().StringSlice("tf-state", nil, "")
+		cmd.Flags().StringSlice("extra-file", nil, "")
+		cmd.Flags().Bool("scan-archives", false, "")
+		cmd.Flags().Bool("decrypt-sops", false, "")
+		cmd.Flags().String("dedupe", "by-bucket-prefix", "")
+		cmd.Flags().Bool("changed-only", false, "")
+		cmd.Flags().String("base-ref", "origin/main", "")
+		return cmd
+	}
+
+	scanFlags.bucketsFile = ""
+	scanFlags.refsStdin = false
+	scanFlags.repoPath = "-"
+	cmd := newCmd()
+	_ = cmd.Flags().Set("repo", "-")
+	if err := validateReferenceSource(cmd); err != nil {
+		t.Errorf("--repo -: %v", err)
+	}
+	if got := scanRepoPath(); got != "-" {
+		t.Errorf("scanRepoPath() = %q, want -", got)
+	}
+
+	scanFlags.repoPath = "."
+	scanFlags.refsStdin = true
+	cmd = newCmd()
+	_ = cmd.Flags().Set("glacier", "true")
+	if err := validateReferenceSource(cmd); err == nil || !strings.Contains(err.Error(), "--refs-stdin cannot be combined with --glacier") {
+		t.Errorf("--refs-stdin with --glacier: got %v", err)
+	}
+
+	scanFlags.bucketsFile = "buckets.txt"
+	if err := validateReferenceSource(newCmd()); err == nil {
+		t.Error("expected error for --refs-stdin with --buckets-file")
+	}
+}
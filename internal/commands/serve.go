@@ -0,0 +1,161 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/ppiankov/s3spectre/internal/api"
+	"github.com/ppiankov/s3spectre/internal/history"
+	"github.com/spf13/cobra"
+	"google.golang.org/grpc"
+)
+
+// apiTokenEnv names the environment variable holding the API bearer token
+const apiTokenEnv = "S3SPECTRE_API_TOKEN"
+
+var serveFlags struct {
+	historyDir    string
+	listen        string
+	grpcListen    string
+	watchInterval time.Duration
+	tokenFile     string
+	state         stateFlags
+	awsProfile    string
+	awsRegion     string
+}
+
+var serveCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "Serve report history over an authenticated REST API",
+	Long: `Runs a long-lived HTTP server exposing findings from a history directory of
+scan or discover JSON reports, or from the DynamoDB table that scan and
+discover record runs in with --state-table:
+
+  GET /findings[?report=NAME]     findings of the latest (or named) report
+  GET /buckets/{name}             a bucket's current findings and history
+  GET /diff[?from=NAME&to=NAME]   new and resolved findings between reports
+  GET /states                     triage states of findings
+  GET /states/{fingerprint}       a finding's triage state
+  PUT /states/{fingerprint}       record a triage state: {"status": ..., "reason": ..., "actor": ...}
+
+Triage statuses are open, acknowledged, suppressed, in_progress and
+resolved; the ack, resolve and reopen commands record them too. A history
+directory keeps them in ` + history.StateFileName + `; a --state-table
+shares them between servers on several hosts.
+
+With --grpc-listen it also serves the s3spectre.v1.ReportService gRPC API
+(proto/s3spectre/v1/report.proto), including streaming of findings and of
+new reports as they land in the history directory.
+
+Reports are named by file name without .json. Every request must send
+"Authorization: Bearer <token>" (gRPC: "authorization" metadata), with the
+token read from ` + apiTokenEnv + ` or --token-file.`,
+	RunE: runServe,
+}
+
+func init() {
+	serveCmd.Flags().StringVar(&serveFlags.historyDir, "history", "", "Directory of JSON reports (required unless --state-table is set)")
+	serveCmd.Flags().StringVar(&serveFlags.listen, "listen", "127.0.0.1:8080", "Address to listen on")
+	serveCmd.Flags().StringVar(&serveFlags.grpcListen, "grpc-listen", "", "Also serve the gRPC API on this address (e.g. 127.0.0.1:9090)")
+	serveCmd.Flags().DurationVar(&serveFlags.watchInterval, "watch-interval", 30*time.Second, "How often gRPC WatchReports polls the history directory")
+	serveCmd.Flags().StringVar(&serveFlags.tokenFile, "token-file", "", "File containing the API bearer token (default: $"+apiTokenEnv+")")
+	serveCmd.Flags().StringVar(&serveFlags.awsProfile, "aws-profile", "", "AWS profile for --state-table")
+	serveCmd.Flags().StringVar(&serveFlags.awsRegion, "aws-region", "", "AWS region (defaults to profile default)")
+	addStateFlags(serveCmd.Flags(), &serveFlags.state)
+}
+
+func runServe(cmd *cobra.Command, args []string) error {
+	token, err := loadAPIToken(serveFlags.tokenFile)
+	if err != nil {
+		return err
+	}
+	store, source, err := openStore(serveFlags.historyDir, serveFlags.state, serveFlags.awsProfile, serveFlags.awsRegion)
+	if err != nil {
+		return err
+	}
+
+	handler, err := api.NewServer(store, token)
+	if err != nil {
+		return err
+	}
+	server := &http.Server{
+		Addr:              serveFlags.listen,
+		Handler:           handler,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	var grpcServer *grpc.Server
+	var grpcListener net.Listener
+	if serveFlags.grpcListen != "" {
+		grpcServer, err = api.NewGRPCServer(store, token, serveFlags.watchInterval)
+		if err != nil {
+			return err
+		}
+		grpcListener, err = net.Listen("tcp", serveFlags.grpcListen)
+		if err != nil {
+			return enhanceError("gRPC listen", err, 0)
+		}
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	errCh := make(chan error, 2)
+	go func() {
+		printStatus("Serving %s on %s", source, serveFlags.listen)
+		errCh <- server.ListenAndServe()
+	}()
+	if grpcServer != nil {
+		go func() {
+			printStatus("Serving gRPC on %s", serveFlags.grpcListen)
+			errCh <- grpcServer.Serve(grpcListener)
+		}()
+	}
+
+	var serveErr error
+	select {
+	case err := <-errCh:
+		if !errors.Is(err, http.ErrServerClosed) {
+			serveErr = enhanceError("API server", err, 0)
+		}
+	case <-ctx.Done():
+		printStatus("Shutting down")
+	}
+
+	if grpcServer != nil {
+		// Stop rather than GracefulStop: WatchReports streams never finish on their own
+		grpcServer.Stop()
+	}
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := server.Shutdown(shutdownCtx); err != nil && serveErr == nil {
+		serveErr = err
+	}
+	return serveErr
+}
+
+// loadAPIToken reads the bearer token from a file, falling back to the environment
+func loadAPIToken(path string) (string, error) {
+	if path != "" {
+		raw, err := os.ReadFile(path)
+		if err != nil {
+			return "", fmt.Errorf("read token file: %w", err)
+		}
+		if token := strings.TrimSpace(string(raw)); token != "" {
+			return token, nil
+		}
+		return "", fmt.Errorf("token file %s is empty", path)
+	}
+	if token := os.Getenv(apiTokenEnv); token != "" {
+		return token, nil
+	}
+	return "", fmt.Errorf("an API token is required: set %s or use --token-file", apiTokenEnv)
+}
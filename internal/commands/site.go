@@ -0,0 +1,42 @@
+package commands
+
+import (
+	"github.com/ppiankov/s3spectre/internal/history"
+	"github.com/ppiankov/s3spectre/internal/site"
+	"github.com/spf13/cobra"
+)
+
+var siteFlags struct {
+	historyDir string
+	outDir     string
+}
+
+var siteCmd = &cobra.Command{
+	Use:   "site",
+	Short: "Render report history into a static HTML dashboard",
+	Long: `Reads a history directory of scan or discover JSON reports and renders a
+static HTML dashboard with a findings trend chart, the latest findings table
+and a page per bucket. The output directory can be published as-is to an S3
+website bucket or GitHub Pages.`,
+	RunE: runSite,
+}
+
+func init() {
+	siteCmd.Flags().StringVar(&siteFlags.historyDir, "history", "", "Directory of JSON reports (required)")
+	siteCmd.Flags().StringVar(&siteFlags.outDir, "out", "public", "Output directory")
+	_ = siteCmd.MarkFlagRequired("history")
+}
+
+func runSite(cmd *cobra.Command, args []string) error {
+	snapshots, err := history.Load(siteFlags.historyDir)
+	if err != nil {
+		return enhanceError("history load", err, 0)
+	}
+	printStatus("Loaded %d reports from %s", len(snapshots), siteFlags.historyDir)
+
+	if err := site.Generate(snapshots, siteFlags.outDir); err != nil {
+		return enhanceError("site generation", err, 0)
+	}
+	printStatus("Dashboard written to %s", siteFlags.outDir)
+	return nil
+}
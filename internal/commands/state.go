@@ -0,0 +1,105 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/ppiankov/s3spectre/internal/baseline"
+	"github.com/ppiankov/s3spectre/internal/history"
+	"github.com/ppiankov/s3spectre/internal/s3"
+	"github.com/spf13/pflag"
+)
+
+// stateFlags selects the DynamoDB table that holds run history and finding
+// triage states, shared by scanners and servers on several hosts
+type stateFlags struct {
+	table    string
+	region   string
+	endpoint string
+}
+
+// addStateFlags registers the state store flags shared by scan, discover
+// and serve
+func addStateFlags(fs *pflag.FlagSet, f *stateFlags) {
+	fs.StringVar(&f.table, "state-table", "", "DynamoDB table holding run history and finding triage states, shared across hosts")
+	fs.StringVar(&f.region, "state-region", "", "Region of --state-table (default: the AWS region)")
+	fs.StringVar(&f.endpoint, "state-endpoint", "", "DynamoDB endpoint URL for --state-table, e.g. DynamoDB Local")
+}
+
+func (f stateFlags) enabled() bool {
+	return f.table != ""
+}
+
+func (f stateFlags) validate() error {
+	if !f.enabled() && (f.region != "" || f.endpoint != "") {
+		return fmt.Errorf("--state-region and --state-endpoint require --state-table")
+	}
+	return nil
+}
+
+// open connects to --state-table with the credentials of cfg
+func (f stateFlags) open(cfg aws.Config) (*history.DynamoStore, error) {
+	if f.region != "" {
+		cfg.Region = f.region
+	}
+	return history.NewDynamoStore(cfg, f.table, f.endpoint)
+}
+
+// openStore opens a history directory or the --state-table, and describes
+// it for status messages
+func openStore(historyDir string, f stateFlags, awsProfile, awsRegion string) (history.Store, string, error) {
+	if err := f.validate(); err != nil {
+		return nil, "", err
+	}
+	switch {
+	case historyDir != "" && f.enabled():
+		return nil, "", fmt.Errorf("--history and --state-table cannot be combined")
+	case f.enabled():
+		client, err := s3.NewClient(context.Background(), awsProfile, awsRegion)
+		if err != nil {
+			return nil, "", enhanceError("AWS client initialization", err, 0)
+		}
+		store, err := f.open(client.GetConfig())
+		if err != nil {
+			return nil, "", err
+		}
+		return store, "DynamoDB table " + f.table, nil
+	case historyDir == "":
+		return nil, "", fmt.Errorf("--history or --state-table is required")
+	}
+	if info, err := os.Stat(historyDir); err != nil || !info.IsDir() {
+		return nil, "", fmt.Errorf("history directory %s is not readable", historyDir)
+	}
+	return history.NewDirStore(historyDir), historyDir, nil
+}
+
+// recordRun stores the run's findings in --state-table, where serve on any
+// host picks them up. A partial run is not recorded, since its history would
+// show the findings it did not check as resolved. Failures are logged
+// rather than failing the run.
+func recordRun(ctx context.Context, f stateFlags, cfg aws.Config, kind, target string, timestamp time.Time, findings []baseline.Finding, complete bool) {
+	if !complete {
+		slog.Info("Partial run not recorded in the state table", slog.String("table", f.table))
+		return
+	}
+	store, err := f.open(cfg)
+	if err != nil {
+		slog.Warn("Recording the run failed", "error", err)
+		return
+	}
+	snap := history.Snapshot{
+		Name:      history.SnapshotName(kind, target, timestamp),
+		Kind:      kind,
+		Timestamp: timestamp,
+		Findings:  findings,
+	}
+	if err := store.PutSnapshot(context.WithoutCancel(ctx), snap); err != nil {
+		slog.Warn("Recording the run failed", "error", err)
+		return
+	}
+	slog.Info("Recorded the run", slog.String("table", f.table), slog.String("report", snap.Name), slog.Int("findings", len(findings)))
+}
@@ -0,0 +1,61 @@
+package commands
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/ppiankov/s3spectre/internal/baseline"
+)
+
+func TestStateFlags_Validate(t *testing.T) {
+	tests := []struct {
+		flags   stateFlags
+		wantErr bool
+	}{
+		{stateFlags{}, false},
+		{stateFlags{table: "s3spectre-state"}, false},
+		{stateFlags{table: "s3spectre-state", region: "eu-west-1", endpoint: "http://localhost:8000"}, false},
+		{stateFlags{region: "eu-west-1"}, true},
+		{stateFlags{endpoint: "http://localhost:8000"}, true},
+	}
+	for _, tt := range tests {
+		if err := tt.flags.validate(); (err != nil) != tt.wantErr {
+			t.Errorf("validate(%+v) error = %v, wantErr %v", tt.flags, err, tt.wantErr)
+		}
+	}
+}
+
+func TestRecordRun(t *testing.T) {
+	var puts atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		// Count report items, not the parts holding their findings
+		if r.Header.Get("X-Amz-Target") == "DynamoDB_20120810.PutItem" && strings.Contains(string(body), `"pk":{"S":"report"}`) {
+			puts.Add(1)
+		}
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	f := stateFlags{table: "s3spectre-state", endpoint: server.URL}
+	cfg := aws.Config{Credentials: credentials.NewStaticCredentialsProvider("AKID", "SECRET", "")}
+	timestamp := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	findings := []baseline.Finding{{Type: "UNUSED_BUCKET", Bucket: "old-logs", Fingerprint: "fp-old"}}
+
+	recordRun(context.Background(), f, cfg, "discover", "111122223333", timestamp, findings, false)
+	if puts.Load() != 0 {
+		t.Error("a partial run should not be recorded")
+	}
+	recordRun(context.Background(), f, cfg, "discover", "111122223333", timestamp, findings, true)
+	if puts.Load() != 1 {
+		t.Errorf("PutItem calls = %d, want the run recorded once", puts.Load())
+	}
+}
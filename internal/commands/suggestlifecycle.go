@@ -0,0 +1,221 @@
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/ppiankov/s3spectre/internal/remediate"
+	"github.com/ppiankov/s3spectre/internal/report"
+	"github.com/ppiankov/s3spectre/internal/s3"
+	"github.com/spf13/cobra"
+)
+
+var suggestLifecycleFlags struct {
+	awsProfile     string
+	awsRegion      string
+	aws            awsClientFlags
+	format         string
+	outputFile     string
+	outputDir      string
+	samplePages    int
+	noncurrentDays int
+}
+
+var suggestLifecycleCmd = &cobra.Command{
+	Use:   "suggest-lifecycle REPORT",
+	Short: "Generate lifecycle configurations for VERSION_SPRAWL and LIFECYCLE_MISCONFIG buckets",
+	Long: `Reads a scan or discover JSON report and writes a lifecycle configuration for
+each VERSION_SPRAWL or LIFECYCLE_MISCONFIG bucket, as S3 API JSON or Terraform.
+
+Storage class transitions are tuned by sampling each bucket's object ages
+with read-only listings: when most sampled bytes are older than 90 days,
+objects move to Standard-IA at 30 days and Glacier Instant Retrieval at 90;
+when most are older than 30 days, to Standard-IA only. Version sprawl also
+gets a rule expiring noncurrent versions, and every bucket a rule aborting
+incomplete multipart uploads. Current objects are never expired.
+
+Nothing is applied. The generated configuration replaces a bucket's
+lifecycle rules, which these findings only report on buckets without any.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runSuggestLifecycle,
+}
+
+func init() {
+	suggestLifecycleCmd.Flags().StringVar(&suggestLifecycleFlags.awsProfile, "aws-profile", "", "AWS profile to use")
+	suggestLifecycleCmd.Flags().StringVar(&suggestLifecycleFlags.awsRegion, "aws-region", "", "AWS region used to look up bucket regions")
+	addAWSClientFlags(suggestLifecycleCmd.Flags(), &suggestLifecycleFlags.aws)
+	// Sampling reads buckets in the account of the credentials only
+	_ = suggestLifecycleCmd.Flags().MarkHidden("accounts")
+	_ = suggestLifecycleCmd.Flags().MarkHidden("assume-role-arn")
+	suggestLifecycleCmd.Flags().StringVar(&suggestLifecycleFlags.format, "format", "json", "Output format: json or terraform")
+	suggestLifecycleCmd.Flags().StringVarP(&suggestLifecycleFlags.outputFile, "output", "o", "", "Output file (default: stdout)")
+	suggestLifecycleCmd.Flags().StringVar(&suggestLifecycleFlags.outputDir, "output-dir", "", "Write one file per bucket to this directory instead")
+	suggestLifecycleCmd.Flags().IntVar(&suggestLifecycleFlags.samplePages, "sample-pages", remediate.DefaultSamplePages, "Object listing pages of 1000 sampled per bucket for age tuning (0 = no sampling, no AWS calls)")
+	suggestLifecycleCmd.Flags().IntVar(&suggestLifecycleFlags.noncurrentDays, "noncurrent-days", remediate.DefaultNoncurrentDays, "Days noncurrent versions are kept on VERSION_SPRAWL buckets")
+}
+
+// lifecycleSuggestion is one bucket's entry in the JSON output
+type lifecycleSuggestion struct {
+	Bucket                 string   `json:"bucket"`
+	Account                string   `json:"account,omitempty"`
+	Finding                string   `json:"finding"`
+	Notes                  []string `json:"notes"`
+	LifecycleConfiguration any      `json:"lifecycle_configuration"`
+}
+
+func runSuggestLifecycle(cmd *cobra.Command, args []string) error {
+	f := &suggestLifecycleFlags
+	if f.format != "json" && f.format != "terraform" {
+		return fmt.Errorf("invalid --format %q: expected json or terraform", f.format)
+	}
+	if f.outputFile != "" && f.outputDir != "" {
+		return fmt.Errorf("--output and --output-dir are mutually exclusive")
+	}
+	if f.samplePages < 0 {
+		return fmt.Errorf("--sample-pages must not be negative")
+	}
+	if f.noncurrentDays < 1 {
+		return fmt.Errorf("--noncurrent-days must be at least 1")
+	}
+	if err := f.aws.validate(); err != nil {
+		return err
+	}
+	findings, err := remediate.ReadFindings(args[0])
+	if err != nil {
+		return enhanceError("report load", err, 0)
+	}
+	targets := remediate.SuggestTargets(findings)
+	if len(targets) == 0 {
+		_, _ = fmt.Fprintln(cmd.OutOrStdout(), "No VERSION_SPRAWL or LIFECYCLE_MISCONFIG findings.")
+		return nil
+	}
+
+	sample := func(context.Context, report.Finding) (*remediate.AgeSample, error) { return nil, nil }
+	if f.samplePages > 0 {
+		if f.aws.backoff, err = retryBackoff(); err != nil {
+			return err
+		}
+		ctx := context.Background()
+		printStatus("Initializing AWS S3 client...")
+		s3Client, err := s3.NewClient(ctx, f.awsProfile, f.awsRegion, f.aws.clientOptions(1)...)
+		if err != nil {
+			return enhanceError("S3 client initialization", err, 0)
+		}
+		now := time.Now()
+		sample = func(ctx context.Context, target report.Finding) (*remediate.AgeSample, error) {
+			region := target.Region
+			if region == "" {
+				var err error
+				if region, err = s3Client.BucketRegion(ctx, target.Bucket); err != nil {
+					return nil, err
+				}
+			}
+			ages, err := remediate.SampleAges(ctx, s3Client.ForRegion(region).GetClient(), target.Bucket, f.samplePages, now)
+			return &ages, err
+		}
+	}
+	policies := suggestLifecycles(context.Background(), targets, f.noncurrentDays, sample)
+
+	if f.outputDir != "" {
+		return writeLifecycleFiles(f.outputDir, f.format, policies)
+	}
+	out := cmd.OutOrStdout()
+	if f.outputFile != "" {
+		file, err := os.Create(f.outputFile)
+		if err != nil {
+			return enhanceError("output file creation", err, 0)
+		}
+		defer func() { _ = file.Close() }()
+		out = file
+	}
+	return writeLifecycleSuggestions(out, f.format, targets, policies)
+}
+
+// suggestLifecycles builds a configuration per target, with Terraform
+// resource names unique among them. A bucket whose ages cannot be sampled
+// gets the untuned configuration.
+func suggestLifecycles(ctx context.Context, targets []report.Finding, noncurrentDays int,
+	sample func(context.Context, report.Finding) (*remediate.AgeSample, error)) []remediate.LifecyclePolicy {
+	policies := make([]remediate.LifecyclePolicy, 0, len(targets))
+	for _, target := range targets {
+		ages, err := sample(ctx, target)
+		if err != nil {
+			slog.Warn("Could not sample object ages; suggesting untuned rules", "bucket", target.Bucket, "error", err)
+			ages = nil
+		}
+		policy := remediate.SuggestLifecycle(target.Bucket, target.Status, ages, noncurrentDays)
+		policy.Account = target.Account
+		policies = append(policies, policy)
+	}
+	remediate.NameResources(policies)
+	return policies
+}
+
+// writeLifecycleSuggestions writes all configurations as one JSON array or
+// one Terraform file
+func writeLifecycleSuggestions(w io.Writer, format string, targets []report.Finding, policies []remediate.LifecyclePolicy) error {
+	if format == "terraform" {
+		for i, policy := range policies {
+			if i > 0 {
+				_, _ = fmt.Fprintln(w)
+			}
+			if _, err := io.WriteString(w, policy.Terraform()); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	suggestions := make([]lifecycleSuggestion, 0, len(policies))
+	for i, policy := range policies {
+		suggestions = append(suggestions, lifecycleSuggestion{
+			Bucket:                 policy.Bucket,
+			Account:                targets[i].Account,
+			Finding:                targets[i].Status,
+			Notes:                  policy.Notes,
+			LifecycleConfiguration: map[string]any{"Rules": policy.Rules},
+		})
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(suggestions)
+}
+
+// writeLifecycleFiles writes BUCKET.json, ready for
+// put-bucket-lifecycle-configuration, or BUCKET.tf per bucket. The file
+// names of buckets with a known account start with it, ACCOUNT_BUCKET.json,
+// so one bucket name in two accounts gets two files.
+func writeLifecycleFiles(dir, format string, policies []remediate.LifecyclePolicy) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return enhanceError("output file creation", err, 0)
+	}
+	for _, policy := range policies {
+		var data []byte
+		ext := ".json"
+		if format == "terraform" {
+			data, ext = []byte(policy.Terraform()), ".tf"
+		} else {
+			var err error
+			if data, err = policy.JSON(); err != nil {
+				return err
+			}
+			data = append(data, '\n')
+		}
+		// Bucket names cannot contain a slash; guard against odd report input
+		name := strings.ReplaceAll(policy.Bucket, "/", "_") + ext
+		if policy.Account != "" {
+			name = policy.Account + "_" + name
+		}
+		if err := os.WriteFile(filepath.Join(dir, name), data, 0644); err != nil {
+			return enhanceError("output file creation", err, 0)
+		}
+	}
+	printStatus("Wrote %d lifecycle configurations to %s", len(policies), dir)
+	return nil
+}
@@ -0,0 +1,98 @@
+package commands
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ppiankov/s3spectre/internal/remediate"
+	"github.com/ppiankov/s3spectre/internal/report"
+	"github.com/ppiankov/s3spectre/internal/rules"
+)
+
+func TestSuggestLifecycles(t *testing.T) {
+	targets := []report.Finding{
+		{Status: rules.StatusLifecycleMisconfig, Bucket: "data"},
+		{Status: rules.StatusVersionSprawl, Bucket: "logs", Account: "111111111111"},
+	}
+	sample := func(ctx context.Context, target report.Finding) (*remediate.AgeSample, error) {
+		if target.Bucket == "logs" {
+			return &remediate.AgeSample{}, errors.New("access denied")
+		}
+		return &remediate.AgeSample{}, nil
+	}
+	policies := suggestLifecycles(context.Background(), targets, 30, sample)
+	if len(policies) != 2 {
+		t.Fatalf("policies = %+v", policies)
+	}
+	// A failed sample falls back to the untuned transition
+	if !strings.Contains(policies[1].Notes[0], "no object ages sampled") {
+		t.Errorf("logs notes = %v, want the untuned fallback", policies[1].Notes)
+	}
+	if !strings.Contains(policies[0].Notes[0], "no objects sampled") {
+		t.Errorf("data notes = %v, want the empty sample note", policies[0].Notes)
+	}
+
+	var out bytes.Buffer
+	if err := writeLifecycleSuggestions(&out, "json", targets, policies); err != nil {
+		t.Fatalf("write json: %v", err)
+	}
+	var suggestions []lifecycleSuggestion
+	if err := json.Unmarshal(out.Bytes(), &suggestions); err != nil {
+		t.Fatalf("decode: %v\n%s", err, out.String())
+	}
+	if len(suggestions) != 2 || suggestions[1].Account != "111111111111" || suggestions[1].Finding != rules.StatusVersionSprawl {
+		t.Errorf("suggestions = %+v", suggestions)
+	}
+
+	out.Reset()
+	if err := writeLifecycleSuggestions(&out, "terraform", targets, policies); err != nil {
+		t.Fatalf("write terraform: %v", err)
+	}
+	if strings.Count(out.String(), "resource \"aws_s3_bucket_lifecycle_configuration\"") != 2 {
+		t.Errorf("terraform output:\n%s", out.String())
+	}
+}
+
+func TestWriteLifecycleFiles(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "lifecycle")
+	policies := []remediate.LifecyclePolicy{remediate.SuggestLifecycle("logs", rules.StatusVersionSprawl, nil, 30)}
+	if err := writeLifecycleFiles(dir, "json", policies); err != nil {
+		t.Fatalf("writeLifecycleFiles: %v", err)
+	}
+	data, err := os.ReadFile(filepath.Join(dir, "logs.json"))
+	if err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	// The file is the bare configuration put-bucket-lifecycle-configuration reads
+	var config map[string]json.RawMessage
+	if err := json.Unmarshal(data, &config); err != nil || len(config) != 1 || config["Rules"] == nil {
+		t.Errorf("logs.json = %s (err %v)", data, err)
+	}
+
+	if err := writeLifecycleFiles(dir, "terraform", policies); err != nil {
+		t.Fatalf("writeLifecycleFiles: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "logs.tf")); err != nil {
+		t.Errorf("logs.tf not written: %v", err)
+	}
+
+	// One bucket name in two accounts gets a file per account
+	policies = suggestLifecycles(context.Background(), []report.Finding{
+		{Status: rules.StatusLifecycleMisconfig, Bucket: "logs", Account: "111111111111"},
+		{Status: rules.StatusLifecycleMisconfig, Bucket: "logs", Account: "222222222222"},
+	}, 30, func(context.Context, report.Finding) (*remediate.AgeSample, error) { return nil, nil })
+	if err := writeLifecycleFiles(dir, "json", policies); err != nil {
+		t.Fatalf("writeLifecycleFiles: %v", err)
+	}
+	for _, name := range []string{"111111111111_logs.json", "222222222222_logs.json"} {
+		if _, err := os.Stat(filepath.Join(dir, name)); err != nil {
+			t.Errorf("%s not written: %v", name, err)
+		}
+	}
+}
@@ -0,0 +1,153 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/ppiankov/s3spectre/internal/baseline"
+	"github.com/ppiankov/s3spectre/internal/history"
+	"github.com/spf13/cobra"
+)
+
+var triageFlags struct {
+	historyDir string
+	state      stateFlags
+	awsProfile string
+	awsRegion  string
+	reason     string
+	actor      string
+}
+
+const triageUsage = `Fingerprints are the "fingerprint" of findings in JSON reports and in the
+serve API; an unambiguous prefix of at least eight characters is enough.
+States are kept in the --history directory or in the --state-table that
+scan, discover and serve share.`
+
+var ackCmd = &cobra.Command{
+	Use:   "ack FINGERPRINT...",
+	Short: "Acknowledge findings: known, and accepted for now",
+	Long: `Records that findings are known and accepted, with the --reason, so the
+serve API shows them as acknowledged instead of open.
+
+` + triageUsage,
+	Args: cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runTriage(cmd, args, history.StatusAcknowledged)
+	},
+}
+
+var resolveCmd = &cobra.Command{
+	Use:   "resolve FINGERPRINT...",
+	Short: "Mark findings as resolved",
+	Long: `Records that findings were fixed. A resolved finding that a later report
+still has is shown as open again.
+
+` + triageUsage,
+	Args: cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runTriage(cmd, args, history.StatusResolved)
+	},
+}
+
+var reopenCmd = &cobra.Command{
+	Use:   "reopen FINGERPRINT...",
+	Short: "Reopen acknowledged, suppressed or resolved findings",
+	Long: `Records that findings need attention again, undoing ack, resolve or a
+state set through the serve API.
+
+` + triageUsage,
+	Args: cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runTriage(cmd, args, history.StatusOpen)
+	},
+}
+
+func init() {
+	for _, cmd := range []*cobra.Command{ackCmd, resolveCmd, reopenCmd} {
+		cmd.Flags().StringVar(&triageFlags.historyDir, "history", "", "Directory of JSON reports whose findings to triage")
+		cmd.Flags().StringVar(&triageFlags.awsProfile, "aws-profile", "", "AWS profile for --state-table")
+		cmd.Flags().StringVar(&triageFlags.awsRegion, "aws-region", "", "AWS region (defaults to profile default)")
+		addStateFlags(cmd.Flags(), &triageFlags.state)
+		cmd.Flags().StringVar(&triageFlags.reason, "reason", "", "Why the state changed, e.g. a ticket or an accepted risk")
+		cmd.Flags().StringVar(&triageFlags.actor, "actor", defaultActor(), "Who changed the state")
+	}
+	_ = ackCmd.MarkFlagRequired("reason")
+}
+
+// defaultActor is the local user name
+func defaultActor() string {
+	if user := os.Getenv("USER"); user != "" {
+		return user
+	}
+	return os.Getenv("USERNAME")
+}
+
+// triageVerbs describe each status change in the command output
+var triageVerbs = map[string]string{
+	history.StatusAcknowledged: "Acknowledged",
+	history.StatusResolved:     "Resolved",
+	history.StatusOpen:         "Reopened",
+}
+
+func runTriage(cmd *cobra.Command, args []string, status string) error {
+	store, _, err := openStore(triageFlags.historyDir, triageFlags.state, triageFlags.awsProfile, triageFlags.awsRegion)
+	if err != nil {
+		return err
+	}
+	ctx := context.Background()
+	snapshots, err := store.Snapshots(ctx)
+	if err != nil {
+		return enhanceError("history load", err, 0)
+	}
+	states, err := store.States(ctx)
+	if err != nil {
+		return enhanceError("state load", err, 0)
+	}
+
+	// Look every fingerprint up before changing any state
+	findings := make([]baseline.Finding, 0, len(args))
+	for _, arg := range args {
+		f, err := history.FindFinding(snapshots, arg)
+		if err != nil {
+			return err
+		}
+		findings = append(findings, f)
+	}
+
+	out := cmd.OutOrStdout()
+	now := time.Now().UTC()
+	latest := snapshots[len(snapshots)-1].Timestamp
+	for _, f := range findings {
+		current := history.StatusOpen
+		if state, ok := states[f.Fingerprint]; ok {
+			current = state.At(latest)
+		}
+		if current == status {
+			_, _ = fmt.Fprintf(out, "%s %s is already %s\n", f.Fingerprint, describeFinding(f), status)
+			continue
+		}
+		state := history.State{
+			Fingerprint: f.Fingerprint,
+			Status:      status,
+			Reason:      triageFlags.reason,
+			Actor:       triageFlags.actor,
+			UpdatedAt:   now,
+		}
+		if err := store.PutState(ctx, state); err != nil {
+			return enhanceError("state update", err, 0)
+		}
+		_, _ = fmt.Fprintf(out, "%s %s %s\n", triageVerbs[status], f.Fingerprint, describeFinding(f))
+	}
+	return nil
+}
+
+// describeFinding names a finding's type and resource
+func describeFinding(f baseline.Finding) string {
+	resource := "s3://" + f.Bucket
+	if f.Prefix != "" {
+		resource += "/" + f.Prefix
+	}
+	return fmt.Sprintf("(%s %s)", f.Type, resource)
+}
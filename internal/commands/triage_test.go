@@ -0,0 +1,74 @@
+package commands
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ppiankov/s3spectre/internal/analyzer"
+	"github.com/ppiankov/s3spectre/internal/history"
+	"github.com/ppiankov/s3spectre/internal/report"
+)
+
+func TestRunTriage(t *testing.T) {
+	dir := t.TempDir()
+	raw, _ := json.Marshal(report.DiscoveryData{
+		Timestamp: time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC),
+		Buckets: map[string]*analyzer.BucketDiscovery{
+			"tmp": {Status: analyzer.StatusUnusedBucket},
+		},
+	})
+	if err := os.WriteFile(filepath.Join(dir, "day1.json"), raw, 0644); err != nil {
+		t.Fatal(err)
+	}
+	store := history.NewDirStore(dir)
+	snapshots, _ := store.Snapshots(context.Background())
+	fingerprint := snapshots[0].Findings[0].Fingerprint
+
+	prev := triageFlags
+	t.Cleanup(func() { triageFlags = prev })
+	triageFlags.historyDir = dir
+	triageFlags.reason = "scratch bucket, removed in OPS-42"
+	triageFlags.actor = "ana"
+
+	run := func(status string, args ...string) string {
+		t.Helper()
+		var out bytes.Buffer
+		ackCmd.SetOut(&out)
+		if err := runTriage(ackCmd, args, status); err != nil {
+			t.Fatalf("runTriage(%s) failed: %v", status, err)
+		}
+		return out.String()
+	}
+
+	if out := run(history.StatusAcknowledged, fingerprint[:10]); !strings.HasPrefix(out, "Acknowledged "+fingerprint+" (UNUSED_BUCKET s3://tmp)") {
+		t.Errorf("ack output = %q", out)
+	}
+	if out := run(history.StatusAcknowledged, fingerprint); !strings.Contains(out, "is already acknowledged") {
+		t.Errorf("repeated ack output = %q", out)
+	}
+	states, _ := store.States(context.Background())
+	if state := states[fingerprint]; state.Status != history.StatusAcknowledged || state.Actor != "ana" || !strings.Contains(state.Reason, "OPS-42") {
+		t.Errorf("state = %+v", state)
+	}
+
+	if out := run(history.StatusOpen, fingerprint); !strings.HasPrefix(out, "Reopened ") {
+		t.Errorf("reopen output = %q", out)
+	}
+	if out := run(history.StatusOpen, fingerprint); !strings.Contains(out, "is already open") {
+		t.Errorf("repeated reopen output = %q", out)
+	}
+
+	if err := runTriage(ackCmd, []string{"ffffffff"}, history.StatusResolved); err == nil {
+		t.Error("an unknown fingerprint should be an error")
+	}
+	triageFlags.historyDir = ""
+	if err := runTriage(ackCmd, []string{fingerprint}, history.StatusResolved); err == nil {
+		t.Error("a missing --history and --state-table should be an error")
+	}
+}
@@ -0,0 +1,123 @@
+package commands
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/ppiankov/s3spectre/internal/integrations"
+	"github.com/ppiankov/s3spectre/internal/report"
+	"github.com/ppiankov/s3spectre/internal/s3"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// warehouseFlags configures the findings export for Redshift and BigQuery
+type warehouseFlags struct {
+	uri string
+}
+
+// addWarehouseFlags registers the warehouse export flag shared by scan and
+// discover
+func addWarehouseFlags(fs *pflag.FlagSet, f *warehouseFlags) {
+	fs.StringVar(&f.uri, "warehouse-uri", "", "Export findings as JSON lines for Redshift or BigQuery to this s3://bucket/prefix or local directory (see 's3spectre warehouse-sql')")
+}
+
+func (f warehouseFlags) enabled() bool {
+	return f.uri != ""
+}
+
+func (f warehouseFlags) validate() error {
+	if !f.enabled() || !strings.HasPrefix(f.uri, "s3://") {
+		return nil
+	}
+	if bucket, _ := splitS3URI(f.uri); bucket == "" {
+		return fmt.Errorf("--warehouse-uri %q has no bucket", f.uri)
+	}
+	return nil
+}
+
+// splitS3URI splits s3://bucket/prefix into its bucket and prefix
+func splitS3URI(uri string) (bucket, prefix string) {
+	bucket, prefix, _ = strings.Cut(strings.TrimPrefix(uri, "s3://"), "/")
+	return bucket, prefix
+}
+
+// exportWarehouse writes the run's findings as one JSON lines object under
+// --warehouse-uri. Failures are logged rather than failing the run.
+func exportWarehouse(ctx context.Context, f warehouseFlags, client *s3.Client, command, target string, timestamp time.Time, findings []report.Finding) {
+	if len(findings) == 0 {
+		return
+	}
+	run := integrations.Run{Command: command, Target: target, Version: GetVersion(), Timestamp: timestamp}
+	var body bytes.Buffer
+	if err := integrations.WriteWarehouseJSONL(&body, run, findings); err != nil {
+		slog.Warn("Warehouse export failed", "error", err)
+		return
+	}
+
+	var location string
+	if strings.HasPrefix(f.uri, "s3://") {
+		bucket, prefix := splitS3URI(f.uri)
+		key := integrations.WarehouseObjectName(prefix, run)
+		if err := client.PutObject(context.WithoutCancel(ctx), bucket, key, body.Bytes(), "application/x-ndjson"); err != nil {
+			slog.Warn("Warehouse export failed", "error", err)
+			return
+		}
+		location = "s3://" + bucket + "/" + key
+	} else {
+		location = filepath.Join(f.uri, filepath.FromSlash(integrations.WarehouseObjectName("", run)))
+		if err := os.MkdirAll(filepath.Dir(location), 0755); err != nil {
+			slog.Warn("Warehouse export failed", "error", err)
+			return
+		}
+		if err := os.WriteFile(location, body.Bytes(), 0644); err != nil {
+			slog.Warn("Warehouse export failed", "error", err)
+			return
+		}
+	}
+	slog.Info("Exported findings for the warehouse", slog.String("location", location), slog.Int("findings", len(findings)))
+}
+
+var warehouseSQLFlags struct {
+	dialect    string
+	table      string
+	source     string
+	iamRole    string
+	connection string
+}
+
+var warehouseSQLCmd = &cobra.Command{
+	Use:   "warehouse-sql",
+	Short: "Print the SQL that loads --warehouse-uri exports into Redshift or BigQuery",
+	Long: `Prints a CREATE TABLE statement for the findings table and the statement
+that loads exported findings into it: COPY for Redshift, LOAD DATA for
+BigQuery. --source is the --warehouse-uri prefix, or a day under it
+(s3://bucket/prefix/dt=2025-01-31/) to load one day's runs.`,
+	Args: cobra.NoArgs,
+	RunE: runWarehouseSQL,
+}
+
+func init() {
+	warehouseSQLCmd.Flags().StringVar(&warehouseSQLFlags.dialect, "dialect", integrations.DialectRedshift, "SQL dialect: redshift or bigquery")
+	warehouseSQLCmd.Flags().StringVar(&warehouseSQLFlags.table, "table", "s3spectre_findings", "Findings table, qualified as the warehouse expects (schema.table or project.dataset.table)")
+	warehouseSQLCmd.Flags().StringVar(&warehouseSQLFlags.source, "source", "", "Exported findings to load: an s3:// prefix, or a gs:// prefix for BigQuery (required)")
+	warehouseSQLCmd.Flags().StringVar(&warehouseSQLFlags.iamRole, "iam-role", "", "IAM role ARN Redshift assumes to read --source (default: the cluster's default role)")
+	warehouseSQLCmd.Flags().StringVar(&warehouseSQLFlags.connection, "connection", "", "BigQuery Omni connection that reads s3:// sources, e.g. aws-us-east-1.s3spectre")
+	_ = warehouseSQLCmd.MarkFlagRequired("source")
+}
+
+func runWarehouseSQL(cmd *cobra.Command, args []string) error {
+	return integrations.WarehouseSQL(cmd.OutOrStdout(), integrations.WarehouseLoad{
+		Dialect:    warehouseSQLFlags.dialect,
+		Table:      warehouseSQLFlags.table,
+		Source:     warehouseSQLFlags.source,
+		IAMRole:    warehouseSQLFlags.iamRole,
+		Connection: warehouseSQLFlags.connection,
+	})
+}
@@ -0,0 +1,42 @@
+package commands
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ppiankov/s3spectre/internal/report"
+)
+
+func TestWarehouseFlags_Validate(t *testing.T) {
+	for uri, wantErr := range map[string]bool{
+		"":                       false,
+		"s3://finops/s3spectre/": false,
+		"s3://finops":            false,
+		"./exports":              false,
+		"s3:///s3spectre":        true,
+	} {
+		if err := (warehouseFlags{uri: uri}).validate(); (err != nil) != wantErr {
+			t.Errorf("validate(%q) error = %v, wantErr %v", uri, err, wantErr)
+		}
+	}
+}
+
+func TestExportWarehouse_LocalDirectory(t *testing.T) {
+	dir := t.TempDir()
+	timestamp := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	findings := []report.Finding{{Status: "UNUSED_BUCKET", Severity: "medium", Resource: "s3://old-logs", Bucket: "old-logs", Fingerprint: "fp-old"}}
+
+	exportWarehouse(context.Background(), warehouseFlags{uri: dir}, nil, "discover", "111122223333", timestamp, findings)
+
+	data, err := os.ReadFile(filepath.Join(dir, "dt=2026-03-01", "discover-20260301T120000Z.jsonl"))
+	if err != nil {
+		t.Fatalf("export not written: %v", err)
+	}
+	if !strings.HasPrefix(string(data), `{"run_at":"2026-03-01 12:00:00","tool":"s3spectre",`) || strings.Count(string(data), "\n") != 1 {
+		t.Errorf("export = %s", data)
+	}
+}
@@ -15,12 +15,102 @@ const (
 
 // Config holds persistent defaults loaded from a config file.
 type Config struct {
-	Region          string   `yaml:"region"`
-	ExcludeBuckets  []string `yaml:"exclude_buckets"`
-	ExcludePrefixes []string `yaml:"exclude_prefixes"`
-	StaleDays       int      `yaml:"stale_days"`
-	Format          string   `yaml:"format"`
-	Timeout         string   `yaml:"timeout"`
+	Region          string         `yaml:"region"`
+	ExcludeBuckets  []string       `yaml:"exclude_buckets"`
+	ExcludePrefixes []string       `yaml:"exclude_prefixes"`
+	StaleDays       int            `yaml:"stale_days"`
+	InactiveDays    int            `yaml:"inactive_days"`
+	Format          string         `yaml:"format"`
+	Timeout         string         `yaml:"timeout"`
+	Plugins         []PluginConfig `yaml:"plugins"`
+	// SeverityOverrides remaps rule severities, e.g. STALE_PREFIX: info
+	SeverityOverrides map[string]string `yaml:"severity_overrides"`
+	// CABundle is a PEM file of extra root CAs for AWS endpoints
+	CABundle string `yaml:"ca_bundle"`
+	// Endpoints maps regions to custom S3 endpoint URLs
+	Endpoints map[string]string `yaml:"endpoints"`
+	// Outposts lists Outpost IDs or ARNs whose buckets discover inspects
+	Outposts []string `yaml:"outposts"`
+	// Principals lists application IAM role ARNs whose access scan simulates
+	Principals []string `yaml:"principals"`
+	// Classification weights risk scores by data classification tags
+	Classification ClassificationConfig `yaml:"classification"`
+	// Retry tunes the backoff between retries of AWS calls
+	Retry RetryConfig `yaml:"retry"`
+	// ExternalBuckets lists glob patterns of third-party buckets, such as
+	// public datasets, that are reported as external instead of checked
+	ExternalBuckets []string `yaml:"external_buckets"`
+	// PublicDatasets controls the built-in registry of public dataset
+	// buckets treated as external
+	PublicDatasets PublicDatasetsConfig `yaml:"public_datasets"`
+	// Environments are the environment names used as bucket name segments,
+	// e.g. dev, staging, prod (default: a built-in list)
+	Environments []string `yaml:"environments"`
+	// TestPaths classifies references from tests, fixtures and examples
+	TestPaths TestPathsConfig `yaml:"test_paths"`
+	// Dedupe is the default --dedupe mode of scan and refs: none, by-bucket
+	// or by-bucket-prefix
+	Dedupe string `yaml:"dedupe"`
+	// Notify configures the notifications sent after scan and discover
+	Notify NotifyConfig `yaml:"notify"`
+}
+
+// NotifyConfig sets which findings send notifications. Webhook URLs hold
+// secrets, so they are read from flags or the environment, not the config
+// file.
+type NotifyConfig struct {
+	// Severity is the lowest severity that notifies (default: high)
+	Severity string `yaml:"severity"`
+}
+
+// TestPathsConfig sets which reference paths count as tests, fixtures or
+// examples, and how buckets referenced only from them are reported.
+type TestPathsConfig struct {
+	// Patterns are globs matched against path segments, or whole paths
+	// when they contain a slash (default: a built-in list)
+	Patterns []string `yaml:"patterns"`
+	// Mode is mark, exclude or off (default: mark)
+	Mode string `yaml:"mode"`
+}
+
+// PublicDatasetsConfig disables or extends the built-in public dataset
+// registry.
+type PublicDatasetsConfig struct {
+	Disabled bool `yaml:"disabled"`
+	// Extra lists glob patterns of additional public dataset buckets
+	Extra []string `yaml:"extra"`
+}
+
+// RetryConfig sets the retry backoff schedule. Unset fields keep their
+// defaults (1s base, multiplier 2, jitter 0.2, 30s max delay).
+type RetryConfig struct {
+	BaseDelay  string  `yaml:"base_delay"`
+	Multiplier float64 `yaml:"multiplier"`
+	// Jitter is a pointer so an explicit 0 disables it
+	Jitter   *float64 `yaml:"jitter"`
+	MaxDelay string   `yaml:"max_delay"`
+}
+
+// IsZero reports whether no retry setting is configured.
+func (r RetryConfig) IsZero() bool {
+	return r.BaseDelay == "" && r.Multiplier == 0 && r.Jitter == nil && r.MaxDelay == ""
+}
+
+// ClassificationConfig maps data classification tag values to risk weights,
+// e.g. confidential: 2.0.
+type ClassificationConfig struct {
+	// TagKeys are the bucket tag keys holding the classification
+	// (default: data-classification)
+	TagKeys []string           `yaml:"tag_keys"`
+	Weights map[string]float64 `yaml:"weights"`
+}
+
+// PluginConfig declares an external check plugin run during discovery.
+type PluginConfig struct {
+	Name    string   `yaml:"name"`
+	Command string   `yaml:"command"`
+	Args    []string `yaml:"args"`
+	Timeout string   `yaml:"timeout"`
 }
 
 // TimeoutDuration parses the Timeout field as a Go duration.
@@ -117,3 +117,70 @@ func TestTimeoutDuration(t *testing.T) {
 		t.Fatalf("expected 0 for invalid, got %v", cfg.TimeoutDuration())
 	}
 }
+
+func TestLoad_Endpoints(t *testing.T) {
+	dir := t.TempDir()
+	content := `ca_bundle: /etc/pki/corp.pem
+endpoints:
+  us-east-1: https://bucket.vpce-0abc.s3.us-east-1.vpce.amazonaws.com
+  on-prem: https://outpost.example.com
+`
+	if err := os.WriteFile(filepath.Join(dir, ".s3spectre.yaml"), []byte(content), 0644); err != nil {
+		t.Fatalf("write file: %v", err)
+	}
+
+	cfg, err := Load(dir)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.CABundle != "/etc/pki/corp.pem" {
+		t.Fatalf("expected ca_bundle, got %q", cfg.CABundle)
+	}
+	if len(cfg.Endpoints) != 2 || cfg.Endpoints["on-prem"] != "https://outpost.example.com" {
+		t.Fatalf("unexpected endpoints: %v", cfg.Endpoints)
+	}
+}
+
+func TestLoad_Classification(t *testing.T) {
+	dir := t.TempDir()
+	content := `classification:
+  tag_keys: [data-classification]
+  weights:
+    confidential: 2.5
+    public: 0.5
+`
+	if err := os.WriteFile(filepath.Join(dir, ".s3spectre.yaml"), []byte(content), 0644); err != nil {
+		t.Fatalf("write file: %v", err)
+	}
+
+	cfg, err := Load(dir)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(cfg.Classification.TagKeys) != 1 || cfg.Classification.Weights["confidential"] != 2.5 {
+		t.Fatalf("unexpected classification config: %+v", cfg.Classification)
+	}
+}
+
+func TestLoad_Retry(t *testing.T) {
+	dir := t.TempDir()
+	content := `retry:
+  base_delay: 100ms
+  multiplier: 1.5
+  jitter: 0
+`
+	if err := os.WriteFile(filepath.Join(dir, ".s3spectre.yaml"), []byte(content), 0644); err != nil {
+		t.Fatalf("write file: %v", err)
+	}
+
+	cfg, err := Load(dir)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.Retry.BaseDelay != "100ms" || cfg.Retry.Multiplier != 1.5 || cfg.Retry.Jitter == nil || *cfg.Retry.Jitter != 0 {
+		t.Fatalf("unexpected retry config: %+v", cfg.Retry)
+	}
+	if cfg.Retry.IsZero() || !(RetryConfig{}).IsZero() {
+		t.Fatalf("unexpected IsZero result")
+	}
+}
@@ -0,0 +1,320 @@
+package history
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+const (
+	// dynamoReportKey and dynamoStateKey are the partition keys of report
+	// and state items
+	dynamoReportKey = "report"
+	dynamoStateKey  = "state"
+	// dynamoPartKey prefixes the partition key of a report's body parts,
+	// followed by the report's sort key
+	dynamoPartKey = "part#"
+	// dynamoPartSize bounds the compressed findings in one part, leaving
+	// room for its keys in DynamoDB's 400 KB item limit
+	dynamoPartSize = 380 * 1024
+	// dynamoSortTime is a fixed-width timestamp, so report sort keys order
+	// by time
+	dynamoSortTime = "2006-01-02T15:04:05.000000000Z"
+)
+
+// dynamoTablePattern matches the table names DynamoDB accepts
+var dynamoTablePattern = regexp.MustCompile(`^[a-zA-Z0-9_.\-]{3,255}$`)
+
+// DynamoAPI is the subset of the DynamoDB client used by DynamoStore
+type DynamoAPI interface {
+	PutItem(ctx context.Context, params *dynamodb.PutItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.PutItemOutput, error)
+	Query(ctx context.Context, params *dynamodb.QueryInput, optFns ...func(*dynamodb.Options)) (*dynamodb.QueryOutput, error)
+}
+
+// DynamoStore is a Store in a DynamoDB table, so scanners on several hosts
+// and servers behind a load balancer share report history and triage states.
+// The table has a string partition key pk and a string sort key sk: reports
+// are items under pk "report" sorted by time, their findings are split into
+// parts under pk "part#<report sort key>", and states are items under pk
+// "state" keyed by fingerprint.
+type DynamoStore struct {
+	api      DynamoAPI
+	table    string
+	partSize int
+
+	// reports caches decoded reports by sort key; stored reports never
+	// change, so only new ones are read
+	mu      sync.Mutex
+	reports map[string]Snapshot
+}
+
+// NewDynamoStore creates a store in table, calling DynamoDB with the region,
+// credentials, HTTP client and retry settings of cfg. A non-empty endpoint
+// replaces the regional endpoint, e.g. for DynamoDB Local.
+func NewDynamoStore(cfg aws.Config, table, endpoint string) (*DynamoStore, error) {
+	if !dynamoTablePattern.MatchString(table) {
+		return nil, fmt.Errorf("invalid DynamoDB table name %q", table)
+	}
+	if cfg.Credentials == nil {
+		return nil, fmt.Errorf("no AWS credentials for DynamoDB")
+	}
+	cfg = cfg.Copy()
+	switch {
+	case endpoint != "":
+		if !strings.HasPrefix(endpoint, "http://") && !strings.HasPrefix(endpoint, "https://") {
+			return nil, fmt.Errorf("DynamoDB endpoint must be an http(s) URL, got %q", endpoint)
+		}
+		if cfg.Region == "" {
+			// DynamoDB Local accepts any signing region
+			cfg.Region = "us-east-1"
+		}
+	case cfg.Region == "":
+		return nil, fmt.Errorf("a region is required for the DynamoDB table %s", table)
+	}
+	api := dynamodb.NewFromConfig(cfg, func(o *dynamodb.Options) {
+		if endpoint != "" {
+			o.BaseEndpoint = aws.String(endpoint)
+		}
+	})
+	return newDynamoStore(api, table), nil
+}
+
+func newDynamoStore(api DynamoAPI, table string) *DynamoStore {
+	return &DynamoStore{
+		api:      api,
+		table:    table,
+		partSize: dynamoPartSize,
+		reports:  make(map[string]Snapshot),
+	}
+}
+
+// SnapshotName names a run's report in a store: its kind and time, and a
+// hash of what it covered, so runs against different targets at the same
+// second do not collide
+func SnapshotName(kind, target string, timestamp time.Time) string {
+	name := kind + "-" + timestamp.UTC().Format("20060102T150405Z")
+	if target == "" {
+		return name
+	}
+	sum := sha256.Sum256([]byte(target))
+	return name + "-" + hex.EncodeToString(sum[:4])
+}
+
+// dynamoReport is a report's item; its findings are in Parts part items
+type dynamoReport struct {
+	PK        string `dynamodbav:"pk"`
+	SK        string `dynamodbav:"sk"`
+	Name      string `dynamodbav:"name"`
+	Kind      string `dynamodbav:"kind"`
+	Timestamp string `dynamodbav:"timestamp"`
+	Parts     int    `dynamodbav:"parts"`
+}
+
+// dynamoPart holds a slice of a report's gzip-compressed findings
+type dynamoPart struct {
+	PK   string `dynamodbav:"pk"`
+	SK   string `dynamodbav:"sk"`
+	Data []byte `dynamodbav:"data"`
+}
+
+type dynamoState struct {
+	PK        string `dynamodbav:"pk"`
+	SK        string `dynamodbav:"sk"`
+	Status    string `dynamodbav:"status"`
+	Reason    string `dynamodbav:"reason,omitempty"`
+	Actor     string `dynamodbav:"actor,omitempty"`
+	UpdatedAt string `dynamodbav:"updated_at"`
+}
+
+// PutSnapshot records a report. Its findings are gzip-compressed and split
+// across part items, written before the report item so readers never see a
+// report with missing parts.
+func (d *DynamoStore) PutSnapshot(ctx context.Context, snap Snapshot) error {
+	var findings bytes.Buffer
+	zw := gzip.NewWriter(&findings)
+	if err := json.NewEncoder(zw).Encode(snap.Findings); err != nil {
+		return err
+	}
+	if err := zw.Close(); err != nil {
+		return err
+	}
+
+	report := dynamoReport{
+		PK:        dynamoReportKey,
+		SK:        snap.Timestamp.UTC().Format(dynamoSortTime) + "#" + snap.Name,
+		Name:      snap.Name,
+		Kind:      snap.Kind,
+		Timestamp: snap.Timestamp.UTC().Format(time.RFC3339Nano),
+	}
+	data := findings.Bytes()
+	for len(data) > 0 {
+		n := min(len(data), d.partSize)
+		part := dynamoPart{
+			PK:   dynamoPartKey + report.SK,
+			SK:   fmt.Sprintf("%06d", report.Parts),
+			Data: data[:n],
+		}
+		if err := d.put(ctx, part); err != nil {
+			return fmt.Errorf("report %s: %w", snap.Name, err)
+		}
+		data = data[n:]
+		report.Parts++
+	}
+	return d.put(ctx, report)
+}
+
+// Snapshots returns the table's reports, oldest first. Reports read before
+// are served from memory, so repeated calls only read new reports' parts.
+func (d *DynamoStore) Snapshots(ctx context.Context) ([]Snapshot, error) {
+	var reports []dynamoReport
+	err := d.query(ctx, dynamoReportKey, func(item map[string]types.AttributeValue) error {
+		var report dynamoReport
+		if err := attributevalue.UnmarshalMap(item, &report); err != nil {
+			return err
+		}
+		reports = append(reports, report)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	snapshots := make([]Snapshot, 0, len(reports))
+	for _, report := range reports {
+		d.mu.Lock()
+		snap, ok := d.reports[report.SK]
+		d.mu.Unlock()
+		if !ok {
+			if snap, err = d.readReport(ctx, report); err != nil {
+				return nil, fmt.Errorf("report %s: %w", report.Name, err)
+			}
+			d.mu.Lock()
+			d.reports[report.SK] = snap
+			d.mu.Unlock()
+		}
+		snapshots = append(snapshots, snap)
+	}
+	return snapshots, nil
+}
+
+// readReport joins a report's parts and decodes its findings
+func (d *DynamoStore) readReport(ctx context.Context, report dynamoReport) (Snapshot, error) {
+	snap := Snapshot{Name: report.Name, Kind: report.Kind}
+	snap.Timestamp, _ = time.Parse(time.RFC3339Nano, report.Timestamp)
+
+	var data bytes.Buffer
+	parts := 0
+	err := d.query(ctx, dynamoPartKey+report.SK, func(item map[string]types.AttributeValue) error {
+		var part dynamoPart
+		if err := attributevalue.UnmarshalMap(item, &part); err != nil {
+			return err
+		}
+		data.Write(part.Data)
+		parts++
+		return nil
+	})
+	if err != nil {
+		return snap, err
+	}
+	if parts != report.Parts {
+		return snap, fmt.Errorf("found %d of %d parts", parts, report.Parts)
+	}
+	zr, err := gzip.NewReader(&data)
+	if err != nil {
+		return snap, err
+	}
+	if err := json.NewDecoder(zr).Decode(&snap.Findings); err != nil {
+		return snap, err
+	}
+	return snap, nil
+}
+
+// States returns the triage state of each finding
+func (d *DynamoStore) States(ctx context.Context) (map[string]State, error) {
+	states := make(map[string]State)
+	err := d.query(ctx, dynamoStateKey, func(item map[string]types.AttributeValue) error {
+		var stored dynamoState
+		if err := attributevalue.UnmarshalMap(item, &stored); err != nil {
+			return err
+		}
+		state := State{
+			Fingerprint: stored.SK,
+			Status:      stored.Status,
+			Reason:      stored.Reason,
+			Actor:       stored.Actor,
+		}
+		state.UpdatedAt, _ = time.Parse(time.RFC3339Nano, stored.UpdatedAt)
+		states[state.Fingerprint] = state
+		return nil
+	})
+	return states, err
+}
+
+// PutState records a finding's triage state, replacing its previous one
+func (d *DynamoStore) PutState(ctx context.Context, state State) error {
+	if err := state.Validate(); err != nil {
+		return err
+	}
+	return d.put(ctx, dynamoState{
+		PK:        dynamoStateKey,
+		SK:        state.Fingerprint,
+		Status:    state.Status,
+		Reason:    state.Reason,
+		Actor:     state.Actor,
+		UpdatedAt: state.UpdatedAt.UTC().Format(time.RFC3339Nano),
+	})
+}
+
+// put writes an item to the table
+func (d *DynamoStore) put(ctx context.Context, item any) error {
+	av, err := attributevalue.MarshalMap(item)
+	if err != nil {
+		return err
+	}
+	_, err = d.api.PutItem(ctx, &dynamodb.PutItemInput{
+		TableName: aws.String(d.table),
+		Item:      av,
+	})
+	if err != nil {
+		return fmt.Errorf("DynamoDB PutItem on table %s: %w", d.table, err)
+	}
+	return nil
+}
+
+// query visits every item of a partition in sort key order, following
+// pagination
+func (d *DynamoStore) query(ctx context.Context, pk string, visit func(map[string]types.AttributeValue) error) error {
+	paginator := dynamodb.NewQueryPaginator(d.api, &dynamodb.QueryInput{
+		TableName:              aws.String(d.table),
+		KeyConditionExpression: aws.String("pk = :pk"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":pk": &types.AttributeValueMemberS{Value: pk},
+		},
+		ConsistentRead: aws.Bool(true),
+	})
+	for paginator.HasMorePages() {
+		out, err := paginator.NextPage(ctx)
+		if err != nil {
+			return fmt.Errorf("DynamoDB Query on table %s: %w", d.table, err)
+		}
+		for _, item := range out.Items {
+			if err := visit(item); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
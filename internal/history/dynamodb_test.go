@@ -0,0 +1,228 @@
+package history
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+
+	"github.com/ppiankov/s3spectre/internal/baseline"
+)
+
+// fakeDynamo serves PutItem and Query for one table, one item per Query page
+type fakeDynamo struct {
+	mu      sync.Mutex
+	table   string
+	items   map[string]map[string]map[string]types.AttributeValue
+	queries int
+}
+
+func keyOf(item map[string]types.AttributeValue, name string) string {
+	if s, ok := item[name].(*types.AttributeValueMemberS); ok {
+		return s.Value
+	}
+	return ""
+}
+
+func (f *fakeDynamo) PutItem(ctx context.Context, params *dynamodb.PutItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.PutItemOutput, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if aws.ToString(params.TableName) != f.table {
+		return nil, &types.ResourceNotFoundException{Message: aws.String("Requested resource not found")}
+	}
+	pk := keyOf(params.Item, "pk")
+	if f.items[pk] == nil {
+		f.items[pk] = make(map[string]map[string]types.AttributeValue)
+	}
+	f.items[pk][keyOf(params.Item, "sk")] = params.Item
+	return &dynamodb.PutItemOutput{}, nil
+}
+
+func (f *fakeDynamo) Query(ctx context.Context, params *dynamodb.QueryInput, optFns ...func(*dynamodb.Options)) (*dynamodb.QueryOutput, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if aws.ToString(params.TableName) != f.table {
+		return nil, &types.ResourceNotFoundException{Message: aws.String("Requested resource not found")}
+	}
+	f.queries++
+	pk := keyOf(params.ExpressionAttributeValues, ":pk")
+	var keys []string
+	for sk := range f.items[pk] {
+		if sk > keyOf(params.ExclusiveStartKey, "sk") {
+			keys = append(keys, sk)
+		}
+	}
+	sort.Strings(keys)
+	out := &dynamodb.QueryOutput{}
+	if len(keys) > 0 {
+		out.Items = []map[string]types.AttributeValue{f.items[pk][keys[0]]}
+		if len(keys) > 1 {
+			out.LastEvaluatedKey = map[string]types.AttributeValue{
+				"pk": &types.AttributeValueMemberS{Value: pk},
+				"sk": &types.AttributeValueMemberS{Value: keys[0]},
+			}
+		}
+	}
+	return out, nil
+}
+
+func newTestDynamoStore(table string) (*DynamoStore, *fakeDynamo) {
+	fake := &fakeDynamo{table: "s3spectre-state", items: make(map[string]map[string]map[string]types.AttributeValue)}
+	return newDynamoStore(fake, table), fake
+}
+
+func TestDynamoStore_Snapshots(t *testing.T) {
+	store, fake := newTestDynamoStore("s3spectre-state")
+	// Small parts, so reports span several items
+	store.partSize = 64
+	ctx := context.Background()
+	day := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	// Written out of order, read back oldest first across query pages
+	for _, snap := range []Snapshot{
+		{Name: SnapshotName("discover", "123456789012", day.Add(24*time.Hour)), Kind: "discover", Timestamp: day.Add(24 * time.Hour),
+			Findings: []baseline.Finding{{Type: "UNUSED_BUCKET", Bucket: "tmp", Fingerprint: "fp-tmp"}}},
+		{Name: SnapshotName("scan", "/repo", day), Kind: "scan", Timestamp: day,
+			Findings: []baseline.Finding{{Type: "MISSING_BUCKET", Bucket: "gone", Fingerprint: "fp-gone"}, {Type: "INACTIVE", Bucket: "logs"}}},
+	} {
+		if err := store.PutSnapshot(ctx, snap); err != nil {
+			t.Fatalf("PutSnapshot failed: %v", err)
+		}
+	}
+	for pk, parts := range fake.items {
+		if strings.HasPrefix(pk, dynamoPartKey) && len(parts) < 2 {
+			t.Errorf("report %s was not split into parts", pk)
+		}
+	}
+
+	snapshots, err := store.Snapshots(ctx)
+	if err != nil {
+		t.Fatalf("Snapshots failed: %v", err)
+	}
+	if len(snapshots) != 2 {
+		t.Fatalf("got %d snapshots, want 2", len(snapshots))
+	}
+	first := snapshots[0]
+	if !strings.HasPrefix(first.Name, "scan-20260301T000000Z-") || first.Kind != "scan" || !first.Timestamp.Equal(day) {
+		t.Errorf("first snapshot = %+v", first)
+	}
+	if len(first.Findings) != 2 || first.Findings[0].Fingerprint != "fp-gone" {
+		t.Errorf("first snapshot findings = %+v", first.Findings)
+	}
+	if snapshots[1].Kind != "discover" || snapshots[1].Findings[0].Bucket != "tmp" {
+		t.Errorf("second snapshot = %+v", snapshots[1])
+	}
+
+	// Reports already read are not fetched again
+	fake.queries = 0
+	if _, err := store.Snapshots(ctx); err != nil {
+		t.Fatalf("Snapshots failed: %v", err)
+	}
+	if fake.queries != 2 {
+		t.Errorf("second Snapshots made %d queries, want only the 2 report pages", fake.queries)
+	}
+}
+
+func TestDynamoStore_MissingParts(t *testing.T) {
+	store, fake := newTestDynamoStore("s3spectre-state")
+	store.partSize = 16
+	ctx := context.Background()
+	snap := Snapshot{Name: "scan-1", Kind: "scan", Timestamp: time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC),
+		Findings: []baseline.Finding{{Type: "MISSING_BUCKET", Bucket: "gone"}}}
+	if err := store.PutSnapshot(ctx, snap); err != nil {
+		t.Fatalf("PutSnapshot failed: %v", err)
+	}
+	for pk, items := range fake.items {
+		if strings.HasPrefix(pk, dynamoPartKey) {
+			delete(items, "000001")
+		}
+	}
+	if _, err := store.Snapshots(ctx); err == nil || !strings.Contains(err.Error(), "parts") {
+		t.Errorf("Snapshots error = %v, want missing parts", err)
+	}
+}
+
+func TestDynamoStore_States(t *testing.T) {
+	store, _ := newTestDynamoStore("s3spectre-state")
+	ctx := context.Background()
+	at := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	for _, state := range []State{
+		{Fingerprint: "fp-1", Status: StatusInProgress, UpdatedAt: at},
+		{Fingerprint: "fp-2", Status: StatusSuppressed, Reason: "vendor bucket", Actor: "ana", UpdatedAt: at},
+		{Fingerprint: "fp-1", Status: StatusAcknowledged, UpdatedAt: at.Add(time.Hour)},
+	} {
+		if err := store.PutState(ctx, state); err != nil {
+			t.Fatalf("PutState failed: %v", err)
+		}
+	}
+	states, err := store.States(ctx)
+	if err != nil {
+		t.Fatalf("States failed: %v", err)
+	}
+	want := map[string]State{
+		"fp-1": {Fingerprint: "fp-1", Status: StatusAcknowledged, UpdatedAt: at.Add(time.Hour)},
+		"fp-2": {Fingerprint: "fp-2", Status: StatusSuppressed, Reason: "vendor bucket", Actor: "ana", UpdatedAt: at},
+	}
+	if fmt.Sprint(states) != fmt.Sprint(want) {
+		t.Errorf("States = %+v, want %+v", states, want)
+	}
+}
+
+func TestDynamoStore_Errors(t *testing.T) {
+	store, _ := newTestDynamoStore("other-table")
+	_, err := store.States(context.Background())
+	var notFound *types.ResourceNotFoundException
+	if !errors.As(err, &notFound) || !strings.Contains(err.Error(), "other-table") {
+		t.Errorf("States error = %v, want DynamoDB's reason", err)
+	}
+
+	cfg := aws.Config{Credentials: credentials.NewStaticCredentialsProvider("AKID", "SECRET", "")}
+	if _, err := NewDynamoStore(cfg, "s3spectre-state", ""); err == nil {
+		t.Error("a store without a region or endpoint should be rejected")
+	}
+	if _, err := NewDynamoStore(cfg, "a", "http://localhost:8000"); err == nil {
+		t.Error("an invalid table name should be rejected")
+	}
+	if _, err := NewDynamoStore(cfg, "s3spectre-state", "localhost:8000"); err == nil {
+		t.Error("an endpoint without a scheme should be rejected")
+	}
+}
+
+func TestNewDynamoStore_Endpoint(t *testing.T) {
+	var target, auth string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		target = r.Header.Get("X-Amz-Target")
+		auth = r.Header.Get("Authorization")
+		w.Header().Set("Content-Type", "application/x-amz-json-1.0")
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	cfg := aws.Config{Credentials: credentials.NewStaticCredentialsProvider("AKID", "SECRET", "")}
+	store, err := NewDynamoStore(cfg, "s3spectre-state", server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	state := State{Fingerprint: "fp-1", Status: StatusAcknowledged, UpdatedAt: time.Now()}
+	if err := store.PutState(context.Background(), state); err != nil {
+		t.Fatalf("PutState failed: %v", err)
+	}
+	if target != "DynamoDB_20120810.PutItem" {
+		t.Errorf("X-Amz-Target = %q, want PutItem at the endpoint", target)
+	}
+	if !strings.HasPrefix(auth, "AWS4-HMAC-SHA256 Credential=AKID/") || !strings.Contains(auth, "/us-east-1/dynamodb/aws4_request") {
+		t.Errorf("request not signed for DynamoDB: %q", auth)
+	}
+}
@@ -0,0 +1,62 @@
+package history
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ppiankov/s3spectre/internal/baseline"
+)
+
+// Snapshot is one stored report in a history directory
+type Snapshot struct {
+	Name      string // file name without extension, used as the snapshot ID
+	Path      string
+	Kind      string
+	Timestamp time.Time
+	Findings  []baseline.Finding
+}
+
+// Load reads every JSON report in dir (e.g. written by scheduled runs with
+// --format json --output dir/$(date +%F).json), oldest first. Reports without
+// a timestamp fall back to the file modification time.
+func Load(dir string) ([]Snapshot, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("read history: %w", err)
+	}
+
+	var snapshots []Snapshot
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.EqualFold(filepath.Ext(entry.Name()), ".json") {
+			continue
+		}
+		path := filepath.Join(dir, entry.Name())
+		r, err := baseline.ReadReport(path)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", entry.Name(), err)
+		}
+
+		ts := r.Timestamp
+		if ts.IsZero() {
+			if info, err := entry.Info(); err == nil {
+				ts = info.ModTime()
+			}
+		}
+		snapshots = append(snapshots, Snapshot{
+			Name:      strings.TrimSuffix(entry.Name(), filepath.Ext(entry.Name())),
+			Path:      path,
+			Kind:      r.Kind,
+			Timestamp: ts,
+			Findings:  r.Findings,
+		})
+	}
+
+	sort.SliceStable(snapshots, func(i, j int) bool {
+		return snapshots[i].Timestamp.Before(snapshots[j].Timestamp)
+	})
+	return snapshots, nil
+}
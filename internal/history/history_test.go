@@ -0,0 +1,70 @@
+package history
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ppiankov/s3spectre/internal/analyzer"
+	"github.com/ppiankov/s3spectre/internal/report"
+)
+
+func writeReport(t *testing.T, path string, data interface{}) {
+	t.Helper()
+	raw, err := json.Marshal(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, raw, 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLoad_OrdersByTimestamp(t *testing.T) {
+	dir := t.TempDir()
+	day := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	writeReport(t, filepath.Join(dir, "a-later.json"), report.DiscoveryData{
+		Timestamp: day.Add(24 * time.Hour),
+		Buckets: map[string]*analyzer.BucketDiscovery{
+			"logs": {Status: analyzer.StatusInactive},
+		},
+	})
+	writeReport(t, filepath.Join(dir, "b-earlier.json"), report.Data{
+		Timestamp: day,
+		Config:    report.Config{RepoPath: "."},
+		Buckets: map[string]*analyzer.BucketAnalysis{
+			"gone": {Status: analyzer.StatusMissingBucket},
+			"ok":   {Status: analyzer.StatusOK},
+		},
+	})
+	if err := os.WriteFile(filepath.Join(dir, "notes.txt"), []byte("ignored"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	snapshots, err := Load(dir)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if len(snapshots) != 2 {
+		t.Fatalf("expected 2 snapshots, got %d", len(snapshots))
+	}
+	if snapshots[0].Name != "b-earlier" || snapshots[0].Kind != "scan" || len(snapshots[0].Findings) != 1 {
+		t.Errorf("unexpected first snapshot: %+v", snapshots[0])
+	}
+	if snapshots[1].Name != "a-later" || snapshots[1].Kind != "discover" {
+		t.Errorf("unexpected second snapshot: %+v", snapshots[1])
+	}
+}
+
+func TestLoad_InvalidReport(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "broken.json"), []byte("{"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Load(dir); err == nil {
+		t.Fatal("expected error for invalid report")
+	}
+}
@@ -0,0 +1,175 @@
+package history
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/ppiankov/s3spectre/internal/baseline"
+)
+
+// Triage statuses of a finding. A finding without a recorded state is open;
+// reopening records StatusOpen so the change is kept.
+const (
+	StatusOpen         = "open"
+	StatusAcknowledged = "acknowledged"
+	StatusSuppressed   = "suppressed"
+	StatusInProgress   = "in_progress"
+	StatusResolved     = "resolved"
+)
+
+// Statuses lists the triage statuses a state can record
+var Statuses = []string{StatusOpen, StatusAcknowledged, StatusSuppressed, StatusInProgress, StatusResolved}
+
+// minFingerprintPrefix is the shortest fingerprint prefix FindFinding accepts
+const minFingerprintPrefix = 8
+
+// State is the triage state of a finding, keyed by its fingerprint
+type State struct {
+	Fingerprint string    `json:"fingerprint"`
+	Status      string    `json:"status"`
+	Reason      string    `json:"reason,omitempty"`
+	Actor       string    `json:"actor,omitempty"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// Validate rejects states without a fingerprint or with an unknown status
+func (s State) Validate() error {
+	if s.Fingerprint == "" {
+		return fmt.Errorf("a finding fingerprint is required")
+	}
+	for _, status := range Statuses {
+		if s.Status == status {
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown triage status %q (expected one of %v)", s.Status, Statuses)
+}
+
+// At returns the status shown for the finding in a report taken at t. A
+// finding resolved before the report was taken has come back, so it is open
+// again.
+func (s State) At(t time.Time) string {
+	if s.Status == StatusResolved && t.After(s.UpdatedAt) {
+		return StatusOpen
+	}
+	return s.Status
+}
+
+// FindFinding looks a finding up by fingerprint, or by an unambiguous prefix
+// of at least eight characters, newest report first
+func FindFinding(snapshots []Snapshot, fingerprint string) (baseline.Finding, error) {
+	fingerprint = strings.ToLower(strings.TrimSpace(fingerprint))
+	matches := make(map[string]baseline.Finding)
+	for i := len(snapshots) - 1; i >= 0; i-- {
+		for _, f := range snapshots[i].Findings {
+			if f.Fingerprint == "" || !strings.HasPrefix(f.Fingerprint, fingerprint) {
+				continue
+			}
+			if f.Fingerprint == fingerprint {
+				return f, nil
+			}
+			if _, seen := matches[f.Fingerprint]; !seen {
+				matches[f.Fingerprint] = f
+			}
+		}
+	}
+	switch {
+	case len(matches) == 0:
+		return baseline.Finding{}, fmt.Errorf("no finding with fingerprint %s in the history", fingerprint)
+	case len(fingerprint) < minFingerprintPrefix:
+		return baseline.Finding{}, fmt.Errorf("fingerprint %s is too short: give at least %d characters", fingerprint, minFingerprintPrefix)
+	case len(matches) > 1:
+		return baseline.Finding{}, fmt.Errorf("fingerprint %s is ambiguous: it matches %d findings", fingerprint, len(matches))
+	}
+	for _, f := range matches {
+		return f, nil
+	}
+	return baseline.Finding{}, nil
+}
+
+// Store holds report history and the triage state of findings. Several
+// servers and scheduled runs can share one store.
+type Store interface {
+	// Snapshots returns the stored reports, oldest first
+	Snapshots(ctx context.Context) ([]Snapshot, error)
+	// States returns the latest triage state of each finding by fingerprint
+	States(ctx context.Context) (map[string]State, error)
+	// PutState records a finding's triage state
+	PutState(ctx context.Context, state State) error
+}
+
+// StateFileName is the file in a history directory that logs triage
+// states. It is not a .json file, so Load does not read it as a report.
+const StateFileName = ".s3spectre-state.jsonl"
+
+// DirStore is a Store over a history directory: reports are the JSON files
+// written by scheduled runs, and states are appended to StateFileName
+type DirStore struct {
+	dir string
+}
+
+// NewDirStore creates a store over a history directory
+func NewDirStore(dir string) *DirStore {
+	return &DirStore{dir: dir}
+}
+
+// Snapshots reads the directory's reports, oldest first
+func (d *DirStore) Snapshots(ctx context.Context) ([]Snapshot, error) {
+	return Load(d.dir)
+}
+
+// States reads the state log; the last entry of each fingerprint wins
+func (d *DirStore) States(ctx context.Context) (map[string]State, error) {
+	states := make(map[string]State)
+	file, err := os.Open(filepath.Join(d.dir, StateFileName))
+	if errors.Is(err, os.ErrNotExist) {
+		return states, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read states: %w", err)
+	}
+	defer func() { _ = file.Close() }()
+
+	lines := bufio.NewScanner(file)
+	lineNum := 0
+	for lines.Scan() {
+		lineNum++
+		if len(lines.Bytes()) == 0 {
+			continue
+		}
+		var state State
+		if err := json.Unmarshal(lines.Bytes(), &state); err != nil {
+			return nil, fmt.Errorf("%s line %d: %w", StateFileName, lineNum, err)
+		}
+		states[state.Fingerprint] = state
+	}
+	return states, lines.Err()
+}
+
+// PutState appends a state to the log, keeping earlier states as an audit
+// trail
+func (d *DirStore) PutState(ctx context.Context, state State) error {
+	if err := state.Validate(); err != nil {
+		return err
+	}
+	line, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+	file, err := os.OpenFile(filepath.Join(d.dir, StateFileName), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("write state: %w", err)
+	}
+	if _, err := file.Write(append(line, '\n')); err != nil {
+		_ = file.Close()
+		return fmt.Errorf("write state: %w", err)
+	}
+	return file.Close()
+}
@@ -0,0 +1,106 @@
+package history
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ppiankov/s3spectre/internal/baseline"
+)
+
+func TestDirStore_States(t *testing.T) {
+	dir := t.TempDir()
+	store := NewDirStore(dir)
+	ctx := context.Background()
+
+	states, err := store.States(ctx)
+	if err != nil || len(states) != 0 {
+		t.Fatalf("States without a log = %v, %v", states, err)
+	}
+
+	at := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	for _, state := range []State{
+		{Fingerprint: "fp-1", Status: StatusInProgress, Actor: "ana", UpdatedAt: at},
+		{Fingerprint: "fp-2", Status: StatusSuppressed, Reason: "test fixture", UpdatedAt: at},
+		{Fingerprint: "fp-1", Status: StatusAcknowledged, Reason: "owned by data team", UpdatedAt: at.Add(time.Hour)},
+	} {
+		if err := store.PutState(ctx, state); err != nil {
+			t.Fatalf("PutState failed: %v", err)
+		}
+	}
+	if err := store.PutState(ctx, State{Fingerprint: "fp-3", Status: "ignored"}); err == nil {
+		t.Error("an unknown status should be rejected")
+	}
+	if err := store.PutState(ctx, State{Status: StatusAcknowledged}); err == nil {
+		t.Error("a state without a fingerprint should be rejected")
+	}
+
+	states, err = store.States(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(states) != 2 || states["fp-1"].Status != StatusAcknowledged || states["fp-2"].Reason != "test fixture" {
+		t.Errorf("States = %+v, want the latest state of each finding", states)
+	}
+
+	// The state log is not read as a report
+	snapshots, err := store.Snapshots(ctx)
+	if err != nil || len(snapshots) != 0 {
+		t.Errorf("Snapshots = %v, %v, want none", snapshots, err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, StateFileName)); err != nil {
+		t.Errorf("state log not written: %v", err)
+	}
+}
+
+func TestFindFinding(t *testing.T) {
+	snapshots := []Snapshot{
+		{Name: "day1", Findings: []baseline.Finding{{Type: "INACTIVE", Bucket: "logs", Fingerprint: "aaaa1111bbbb2222"}}},
+		{Name: "day2", Findings: []baseline.Finding{
+			{Type: "UNUSED_BUCKET", Bucket: "tmp", Fingerprint: "aaaa1111cccc3333"},
+			{Type: "MISSING_BUCKET", Bucket: "gone", Fingerprint: "dddd4444eeee5555"},
+		}},
+	}
+	tests := []struct {
+		fingerprint string
+		wantBucket  string
+		wantErr     string
+	}{
+		{"aaaa1111bbbb2222", "logs", ""},
+		{"DDDD4444", "gone", ""},
+		{"aaaa1111c", "tmp", ""},
+		{"aaaa1111", "", "ambiguous"},
+		{"dddd", "", "too short"},
+		{"ffff0000", "", "no finding"},
+	}
+	for _, tt := range tests {
+		f, err := FindFinding(snapshots, tt.fingerprint)
+		if tt.wantErr != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("FindFinding(%q) error = %v, want %q", tt.fingerprint, err, tt.wantErr)
+			}
+			continue
+		}
+		if err != nil || f.Bucket != tt.wantBucket {
+			t.Errorf("FindFinding(%q) = %+v, %v, want bucket %s", tt.fingerprint, f, err, tt.wantBucket)
+		}
+	}
+}
+
+func TestState_At(t *testing.T) {
+	resolvedAt := time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC)
+	resolved := State{Fingerprint: "fp", Status: StatusResolved, UpdatedAt: resolvedAt}
+	if got := resolved.At(resolvedAt.Add(-time.Hour)); got != StatusResolved {
+		t.Errorf("before resolution: %s, want resolved", got)
+	}
+	if got := resolved.At(resolvedAt.Add(time.Hour)); got != StatusOpen {
+		t.Errorf("reported again after resolution: %s, want open", got)
+	}
+	acked := State{Fingerprint: "fp", Status: StatusAcknowledged, UpdatedAt: resolvedAt}
+	if got := acked.At(resolvedAt.Add(time.Hour)); got != StatusAcknowledged {
+		t.Errorf("acknowledged: %s", got)
+	}
+}
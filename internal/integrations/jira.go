@@ -0,0 +1,369 @@
+// Package integrations files findings as tickets in external issue trackers
+// and indexes them in search clusters.
+package integrations
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ppiankov/s3spectre/internal/report"
+	"github.com/ppiankov/s3spectre/internal/rules"
+)
+
+const (
+	// DefaultJiraIssueType is the issue type of created tickets
+	DefaultJiraIssueType = "Task"
+	// DefaultJiraLabel marks the tickets s3spectre creates
+	DefaultJiraLabel = "s3spectre"
+	// jiraPageSize is the most tickets read per search request
+	jiraPageSize = 100
+)
+
+// Jira creates a ticket per finding in one project. A custom text field
+// holds the finding's fingerprint, so a finding with an unresolved ticket
+// is not filed again.
+type Jira struct {
+	URL     string
+	Project string
+	// KeyField is the custom field ID, such as customfield_10050, that
+	// holds the finding key
+	KeyField  string
+	IssueType string
+	Label     string
+	// User is the account email for Jira Cloud API tokens; without it the
+	// token is sent as a Data Center personal access token
+	User   string
+	Token  string
+	Client *http.Client
+}
+
+// JiraAttachment is a file attached to every created ticket
+type JiraAttachment struct {
+	Name string
+	Data []byte
+}
+
+// JiraResult counts the findings a run created tickets for, and those that
+// already had one
+type JiraResult struct {
+	Created  []string
+	Existing int
+}
+
+// NewJira creates a Jira ticket filer
+func NewJira(baseURL, project, keyField, user, token string) (*Jira, error) {
+	u, err := url.Parse(baseURL)
+	if err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+		return nil, fmt.Errorf("Jira URL must be an http(s) URL, got %q", baseURL)
+	}
+	if project == "" {
+		return nil, errors.New("a Jira project key is required")
+	}
+	if _, err := jqlField(keyField); err != nil {
+		return nil, err
+	}
+	if token == "" {
+		return nil, errors.New("a Jira API token is required")
+	}
+	return &Jira{
+		URL:       strings.TrimSuffix(baseURL, "/"),
+		Project:   project,
+		KeyField:  keyField,
+		IssueType: DefaultJiraIssueType,
+		Label:     DefaultJiraLabel,
+		User:      user,
+		Token:     token,
+		Client:    &http.Client{Timeout: 30 * time.Second},
+	}, nil
+}
+
+// jqlField converts a custom field ID to its JQL name, customfield_10050 to
+// cf[10050]
+func jqlField(keyField string) (string, error) {
+	id, ok := strings.CutPrefix(keyField, "customfield_")
+	if _, err := strconv.Atoi(id); !ok || err != nil {
+		return "", fmt.Errorf("Jira key field must be a custom field ID such as customfield_10050, got %q", keyField)
+	}
+	return "cf[" + id + "]", nil
+}
+
+// File creates a ticket for each finding without an unresolved one and
+// attaches attachment, when set, to the new tickets
+func (j *Jira) File(ctx context.Context, findings []report.Finding, attachment *JiraAttachment) (JiraResult, error) {
+	var result JiraResult
+	existing, err := j.openKeys(ctx)
+	if err != nil {
+		return result, err
+	}
+	for _, f := range findings {
+		if existing[f.Fingerprint] {
+			result.Existing++
+			continue
+		}
+		key, err := j.create(ctx, f)
+		if err != nil {
+			return result, fmt.Errorf("creating ticket for %s %s: %w", f.Status, f.Resource, err)
+		}
+		// Findings repeated within the run share the ticket
+		existing[f.Fingerprint] = true
+		result.Created = append(result.Created, key)
+		if attachment != nil {
+			if err := j.attach(ctx, key, attachment); err != nil {
+				return result, fmt.Errorf("attaching report to %s: %w", key, err)
+			}
+		}
+	}
+	return result, nil
+}
+
+// openKeys lists the finding keys of the project's unresolved tickets.
+// Jira Cloud searches at search/jql, paged by token; Data Center has only
+// the older search endpoint, paged by offset.
+func (j *Jira) openKeys(ctx context.Context) (map[string]bool, error) {
+	field, err := jqlField(j.KeyField)
+	if err != nil {
+		return nil, err
+	}
+	jql := fmt.Sprintf("project = %s AND resolution = Unresolved AND %s is not EMPTY", jqlQuote(j.Project), field)
+	keys := make(map[string]bool)
+	err = j.searchKeys(ctx, jql, false, keys)
+	var apiErr *jiraError
+	if errors.As(err, &apiErr) && apiErr.code == http.StatusNotFound {
+		err = j.searchKeys(ctx, jql, true, keys)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("searching tickets: %w", err)
+	}
+	return keys, nil
+}
+
+// searchKeys adds the finding keys of the tickets jql matches to keys
+func (j *Jira) searchKeys(ctx context.Context, jql string, offsetPaged bool, keys map[string]bool) error {
+	path := "/rest/api/2/search/jql"
+	if offsetPaged {
+		path = "/rest/api/2/search"
+	}
+	req := map[string]any{"jql": jql, "fields": []string{j.KeyField}, "maxResults": jiraPageSize}
+	for startAt := 0; ; {
+		var page struct {
+			Issues []struct {
+				Fields map[string]any `json:"fields"`
+			} `json:"issues"`
+			Total         int    `json:"total"`
+			NextPageToken string `json:"nextPageToken"`
+		}
+		if offsetPaged {
+			req["startAt"] = startAt
+		}
+		if err := j.do(ctx, http.MethodPost, path, req, &page); err != nil {
+			return err
+		}
+		for _, issue := range page.Issues {
+			if key, ok := issue.Fields[j.KeyField].(string); ok {
+				keys[strings.TrimSpace(key)] = true
+			}
+		}
+		if offsetPaged {
+			startAt += len(page.Issues)
+			if len(page.Issues) == 0 || startAt >= page.Total {
+				return nil
+			}
+			continue
+		}
+		if page.NextPageToken == "" {
+			return nil
+		}
+		req["nextPageToken"] = page.NextPageToken
+	}
+}
+
+// jqlQuote quotes a JQL string value
+func jqlQuote(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
+
+// create opens a ticket for f and returns its key
+func (j *Jira) create(ctx context.Context, f report.Finding) (string, error) {
+	fields := map[string]any{
+		"project":     map[string]string{"key": j.Project},
+		"issuetype":   map[string]string{"name": j.IssueType},
+		"summary":     Summary(f),
+		"description": Description(f),
+		j.KeyField:    f.Fingerprint,
+	}
+	if j.Label != "" {
+		fields["labels"] = []string{j.Label}
+	}
+	var created struct {
+		Key string `json:"key"`
+	}
+	if err := j.do(ctx, http.MethodPost, "/rest/api/2/issue", map[string]any{"fields": fields}, &created); err != nil {
+		return "", err
+	}
+	return created.Key, nil
+}
+
+// attach uploads a file to the ticket
+func (j *Jira) attach(ctx context.Context, key string, a *JiraAttachment) error {
+	var body bytes.Buffer
+	w := multipart.NewWriter(&body)
+	part, err := w.CreateFormFile("file", a.Name)
+	if err != nil {
+		return err
+	}
+	if _, err := part.Write(a.Data); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	req, err := j.request(ctx, http.MethodPost, "/rest/api/2/issue/"+url.PathEscape(key)+"/attachments", &body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", w.FormDataContentType())
+	// Jira rejects uploads without this header as cross-site requests
+	req.Header.Set("X-Atlassian-Token", "no-check")
+	return j.send(req, nil)
+}
+
+// Summary is the ticket title, e.g. "S3Spectre: PUBLIC_BUCKET on s3://media"
+func Summary(f report.Finding) string {
+	summary := fmt.Sprintf("S3Spectre: %s on %s", f.Status, f.Resource)
+	if f.Account != "" {
+		summary += " in " + f.Account
+	}
+	return summary
+}
+
+// Description renders the ticket body in Jira wiki markup
+func Description(f report.Finding) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "S3Spectre reported *%s* (%s severity) on {{%s}}.\n\n", f.Status, f.Severity, f.Resource)
+	if f.Message != "" {
+		fmt.Fprintf(&b, "%s\n\n", wikiText(f.Message))
+	}
+	if f.Account != "" {
+		fmt.Fprintf(&b, "||Account|%s|\n", f.Account)
+	}
+	if f.Region != "" {
+		fmt.Fprintf(&b, "||Region|%s|\n", f.Region)
+	}
+	fmt.Fprintf(&b, "||Finding key|{{%s}}|\n", f.Fingerprint)
+	if rule, ok := rules.Lookup(f.Status); ok && rule.Remediation != "" {
+		fmt.Fprintf(&b, "\nh3. Remediation\n%s\n", wikiText(rule.Remediation))
+	}
+	if len(f.References) > 0 {
+		b.WriteString("\nh3. References\n")
+		for _, ref := range f.References {
+			location := ref.File
+			if ref.Line > 0 {
+				location += ":" + strconv.Itoa(ref.Line)
+			}
+			fmt.Fprintf(&b, "* {{%s}}\n", location)
+		}
+	}
+	if f.Blame != nil {
+		fmt.Fprintf(&b, "\nLast changed by %s in {{%s}}.\n", wikiText(f.Blame.Author), f.Blame.Commit)
+	}
+	return b.String()
+}
+
+// wikiText escapes the wiki markup characters that change how plain text
+// renders
+func wikiText(s string) string {
+	return strings.NewReplacer("{", `\{`, "}", `\}`, "[", `\[`, "]", `\]`, "|", `\|`).Replace(s)
+}
+
+// do sends a JSON REST request and decodes the response into out
+func (j *Jira) do(ctx context.Context, method, path string, in, out any) error {
+	var body io.Reader
+	if in != nil {
+		data, err := json.Marshal(in)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(data)
+	}
+	req, err := j.request(ctx, method, path, body)
+	if err != nil {
+		return err
+	}
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	return j.send(req, out)
+}
+
+func (j *Jira) request(ctx context.Context, method, path string, body io.Reader) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, method, j.URL+path, body)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+	if j.User != "" {
+		req.SetBasicAuth(j.User, j.Token)
+	} else {
+		req.Header.Set("Authorization", "Bearer "+j.Token)
+	}
+	return req, nil
+}
+
+// send performs the request and surfaces Jira's error messages
+func (j *Jira) send(req *http.Request, out any) error {
+	resp, err := j.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		apiErr := &jiraError{code: resp.StatusCode, status: resp.Status}
+		var detail struct {
+			ErrorMessages []string          `json:"errorMessages"`
+			Errors        map[string]string `json:"errors"`
+		}
+		data, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		if json.Unmarshal(data, &detail) == nil {
+			apiErr.messages = detail.ErrorMessages
+			fields := make([]string, 0, len(detail.Errors))
+			for field := range detail.Errors {
+				fields = append(fields, field)
+			}
+			sort.Strings(fields)
+			for _, field := range fields {
+				apiErr.messages = append(apiErr.messages, field+": "+detail.Errors[field])
+			}
+		}
+		return apiErr
+	}
+	if out == nil {
+		_, _ = io.Copy(io.Discard, resp.Body)
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+// jiraError is a Jira API error response
+type jiraError struct {
+	code     int
+	status   string
+	messages []string
+}
+
+func (e *jiraError) Error() string {
+	if len(e.messages) == 0 {
+		return e.status
+	}
+	return e.status + ": " + strings.Join(e.messages, "; ")
+}
@@ -0,0 +1,189 @@
+package integrations
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/ppiankov/s3spectre/internal/report"
+	"github.com/ppiankov/s3spectre/internal/rules"
+	"github.com/ppiankov/s3spectre/internal/scanner"
+)
+
+// fakeJira serves the endpoints File uses; cloud selects the token-paged
+// search of Jira Cloud over the offset-paged one of Data Center
+type fakeJira struct {
+	mu          sync.Mutex
+	cloud       bool
+	keys        []string
+	created     []map[string]any
+	attachments map[string]string
+	auth        string
+}
+
+func (f *fakeJira) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.auth = r.Header.Get("Authorization")
+	switch {
+	case r.URL.Path == "/rest/api/2/search/jql" && !f.cloud:
+		w.WriteHeader(http.StatusNotFound)
+	case r.URL.Path == "/rest/api/2/search/jql" || r.URL.Path == "/rest/api/2/search":
+		var req map[string]any
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		if !strings.Contains(req["jql"].(string), "cf[10050] is not EMPTY") {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		// One ticket per page, to exercise paging
+		page := 0
+		if start, ok := req["startAt"].(float64); ok {
+			page = int(start)
+		}
+		if token, ok := req["nextPageToken"].(string); ok {
+			page = len(token)
+		}
+		resp := map[string]any{"total": len(f.keys), "issues": []any{}}
+		if page < len(f.keys) {
+			resp["issues"] = []any{map[string]any{"fields": map[string]any{"customfield_10050": f.keys[page]}}}
+			if f.cloud && page+1 < len(f.keys) {
+				resp["nextPageToken"] = strings.Repeat("x", page+1)
+			}
+		}
+		_ = json.NewEncoder(w).Encode(resp)
+	case r.URL.Path == "/rest/api/2/issue":
+		var req struct {
+			Fields map[string]any `json:"fields"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		if req.Fields["summary"] == "" {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"errorMessages":[],"errors":{"summary":"Summary is required."}}`))
+			return
+		}
+		f.created = append(f.created, req.Fields)
+		_ = json.NewEncoder(w).Encode(map[string]string{"key": "OPS-" + string(rune('0'+len(f.created)))})
+	case strings.HasSuffix(r.URL.Path, "/attachments"):
+		if r.Header.Get("X-Atlassian-Token") != "no-check" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		file, header, err := r.FormFile("file")
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		data, _ := io.ReadAll(file)
+		key := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/rest/api/2/issue/"), "/attachments")
+		f.attachments[key] = header.Filename + ":" + string(data)
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func testFindings() []report.Finding {
+	return []report.Finding{
+		{Status: "PUBLIC_BUCKET", Severity: "high", Resource: "s3://media", Bucket: "media", Account: "111111111111",
+			Fingerprint: rules.Fingerprint("PUBLIC_BUCKET", "111111111111", "media", "")},
+		{Status: "MISSING_BUCKET", Severity: "medium", Resource: "s3://orders", Bucket: "orders", Message: "Bucket [orders] does not exist",
+			Fingerprint: rules.Fingerprint("MISSING_BUCKET", "", "orders", ""),
+			References:  []scanner.Reference{{File: "app/config.yaml", Line: 12}}},
+		{Status: "NO_ENCRYPTION", Severity: "medium", Resource: "s3://logs", Bucket: "logs",
+			Fingerprint: rules.Fingerprint("NO_ENCRYPTION", "", "logs", "")},
+	}
+}
+
+func testJira(t *testing.T, fake *fakeJira, user string) *Jira {
+	t.Helper()
+	fake.attachments = make(map[string]string)
+	srv := httptest.NewServer(fake)
+	t.Cleanup(srv.Close)
+	jira, err := NewJira(srv.URL+"/", "OPS", "customfield_10050", user, "secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return jira
+}
+
+func TestJiraFile(t *testing.T) {
+	for _, cloud := range []bool{true, false} {
+		findings := testFindings()
+		fake := &fakeJira{cloud: cloud, keys: []string{"unrelated", findings[2].Fingerprint}}
+		jira := testJira(t, fake, "ops@example.com")
+
+		attachment := &JiraAttachment{Name: "s3spectre-discover.json", Data: []byte(`{"summary":{}}`)}
+		result, err := jira.File(context.Background(), append(findings, findings[0]), attachment)
+		if err != nil {
+			t.Fatalf("cloud=%v: %v", cloud, err)
+		}
+		// The logs finding has a ticket, and the repeated media finding
+		// shares the one just created
+		if len(result.Created) != 2 || result.Existing != 2 {
+			t.Fatalf("cloud=%v: expected 2 created and 2 existing, got %+v", cloud, result)
+		}
+		if !strings.HasPrefix(fake.auth, "Basic ") {
+			t.Errorf("a user should select basic auth, got %q", fake.auth)
+		}
+		created := fake.created[0]
+		if created["customfield_10050"] != findings[0].Fingerprint || created["summary"] != "S3Spectre: PUBLIC_BUCKET on s3://media in 111111111111" {
+			t.Errorf("unexpected ticket fields: %+v", created)
+		}
+		if fake.attachments["OPS-1"] != `s3spectre-discover.json:{"summary":{}}` || len(fake.attachments) != 2 {
+			t.Errorf("every created ticket should carry the report: %v", fake.attachments)
+		}
+	}
+}
+
+func TestJiraFile_APIError(t *testing.T) {
+	fake := &fakeJira{cloud: true}
+	jira := testJira(t, fake, "")
+	_, err := jira.File(context.Background(), []report.Finding{{Status: "X"}}, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if fake.auth != "Bearer secret" {
+		t.Errorf("without a user the token should be a bearer token, got %q", fake.auth)
+	}
+
+	jira.Project = "MISSING"
+	jira.URL += "/missing"
+	_, err = jira.File(context.Background(), testFindings(), nil)
+	if err == nil || !strings.Contains(err.Error(), "404") {
+		t.Fatalf("expected a 404 error, got %v", err)
+	}
+}
+
+func TestDescription(t *testing.T) {
+	got := Description(testFindings()[1])
+	for _, want := range []string{
+		"S3Spectre reported *MISSING_BUCKET* (medium severity) on {{s3://orders}}.",
+		`Bucket \[orders\] does not exist`,
+		"h3. Remediation",
+		"* {{app/config.yaml:12}}",
+		"||Finding key|{{",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("description missing %q:\n%s", want, got)
+		}
+	}
+}
+
+func TestNewJira_Invalid(t *testing.T) {
+	cases := []struct{ url, project, field, token string }{
+		{"acme.atlassian.net", "OPS", "customfield_1", "t"},
+		{"https://acme.atlassian.net", "", "customfield_1", "t"},
+		{"https://acme.atlassian.net", "OPS", "Finding key", "t"},
+		{"https://acme.atlassian.net", "OPS", "customfield_", "t"},
+		{"https://acme.atlassian.net", "OPS", "customfield_1", ""},
+	}
+	for _, c := range cases {
+		if _, err := NewJira(c.url, c.project, c.field, "", c.token); err == nil {
+			t.Errorf("expected error for %+v", c)
+		}
+	}
+}
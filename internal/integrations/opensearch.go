@@ -0,0 +1,294 @@
+package integrations
+
+import (
+	"bytes"
+	"context"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ppiankov/s3spectre/internal/report"
+	"github.com/ppiankov/s3spectre/internal/rules"
+)
+
+const (
+	// DefaultOpenSearchIndex is the index findings are written to
+	DefaultOpenSearchIndex = "s3spectre-findings"
+	// openSearchBatch is the most findings sent per bulk request
+	openSearchBatch = 500
+)
+
+// openSearchTemplate is the index template for finding documents; its
+// index pattern is replaced to match the configured index
+//
+//go:embed opensearch_template.json
+var openSearchTemplate []byte
+
+// OpenSearch bulk-indexes findings into an OpenSearch or Elasticsearch
+// cluster, one document per finding per run
+type OpenSearch struct {
+	URL   string
+	Index string
+	// User and Password authenticate with HTTP basic auth; APIKey, when
+	// set, is sent as an Elasticsearch API key instead
+	User     string
+	Password string
+	APIKey   string
+	Client   *http.Client
+}
+
+// Run describes the run findings come from, for the sinks that record it
+type Run struct {
+	Command   string
+	Target    string
+	Version   string
+	Timestamp time.Time
+}
+
+// OpenSearchResult counts the findings indexed and those the cluster
+// rejected, with the first rejection's reason
+type OpenSearchResult struct {
+	Indexed    int
+	Failed     int
+	FirstError string
+}
+
+// openSearchDocument is the indexed form of a finding
+type openSearchDocument struct {
+	Timestamp   string   `json:"@timestamp"`
+	Tool        string   `json:"tool"`
+	Version     string   `json:"version,omitempty"`
+	Command     string   `json:"command"`
+	Target      string   `json:"target,omitempty"`
+	Status      string   `json:"status"`
+	Severity    string   `json:"severity"`
+	Resource    string   `json:"resource"`
+	Bucket      string   `json:"bucket,omitempty"`
+	Prefix      string   `json:"prefix,omitempty"`
+	Account     string   `json:"account,omitempty"`
+	Region      string   `json:"region,omitempty"`
+	Message     string   `json:"message,omitempty"`
+	Fingerprint string   `json:"fingerprint"`
+	Category    string   `json:"category,omitempty"`
+	References  []string `json:"references,omitempty"`
+	Author      string   `json:"author,omitempty"`
+	Commit      string   `json:"commit,omitempty"`
+}
+
+// NewOpenSearch creates a finding indexer
+func NewOpenSearch(baseURL, index, user, password, apiKey string) (*OpenSearch, error) {
+	u, err := url.Parse(baseURL)
+	if err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+		return nil, fmt.Errorf("OpenSearch URL must be an http(s) URL, got %q", baseURL)
+	}
+	if err := validIndexName(index); err != nil {
+		return nil, err
+	}
+	return &OpenSearch{
+		URL:      strings.TrimSuffix(baseURL, "/"),
+		Index:    index,
+		User:     user,
+		Password: password,
+		APIKey:   apiKey,
+		Client:   &http.Client{Timeout: 30 * time.Second},
+	}, nil
+}
+
+// validIndexName applies the index naming rules OpenSearch and
+// Elasticsearch share
+func validIndexName(index string) error {
+	switch {
+	case index == "" || index == "." || index == "..":
+		return fmt.Errorf("invalid OpenSearch index %q", index)
+	case index != strings.ToLower(index):
+		return fmt.Errorf("OpenSearch index %q must be lowercase", index)
+	case strings.ContainsAny(index, ` \/*?"<>|,#:`):
+		return fmt.Errorf("OpenSearch index %q contains a character indexes cannot use", index)
+	case strings.ContainsAny(index[:1], "-_+"):
+		return fmt.Errorf("OpenSearch index %q must not start with -, _ or +", index)
+	}
+	return nil
+}
+
+// IndexTemplate returns the composable index template for finding
+// documents, matching index and the indexes named after it
+func IndexTemplate(index string) ([]byte, error) {
+	var template map[string]any
+	if err := json.Unmarshal(openSearchTemplate, &template); err != nil {
+		return nil, err
+	}
+	template["index_patterns"] = []string{index + "*"}
+	return json.MarshalIndent(template, "", "  ")
+}
+
+// PutTemplate creates or updates the index template, named after the index
+func (o *OpenSearch) PutTemplate(ctx context.Context) error {
+	template, err := IndexTemplate(o.Index)
+	if err != nil {
+		return err
+	}
+	req, err := o.request(ctx, http.MethodPut, "/_index_template/"+url.PathEscape(o.Index), bytes.NewReader(template))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	return o.send(req, nil)
+}
+
+// IndexFindings writes a document per finding with the bulk API. Document
+// IDs combine the fingerprint and the run time, so a retried run replaces
+// its own documents while earlier runs are kept for trends.
+func (o *OpenSearch) IndexFindings(ctx context.Context, run Run, findings []report.Finding) (OpenSearchResult, error) {
+	var result OpenSearchResult
+	for start := 0; start < len(findings); start += openSearchBatch {
+		batch := findings[start:min(start+openSearchBatch, len(findings))]
+		var body bytes.Buffer
+		enc := json.NewEncoder(&body)
+		for _, f := range batch {
+			action := map[string]map[string]string{"index": {"_index": o.Index, "_id": DocumentID(f, run.Timestamp)}}
+			if err := enc.Encode(action); err != nil {
+				return result, err
+			}
+			if err := enc.Encode(newOpenSearchDocument(f, run)); err != nil {
+				return result, err
+			}
+		}
+		req, err := o.request(ctx, http.MethodPost, "/_bulk", &body)
+		if err != nil {
+			return result, err
+		}
+		req.Header.Set("Content-Type", "application/x-ndjson")
+		var resp struct {
+			Items []map[string]struct {
+				Status int `json:"status"`
+				Error  *struct {
+					Type   string `json:"type"`
+					Reason string `json:"reason"`
+				} `json:"error"`
+			} `json:"items"`
+		}
+		if err := o.send(req, &resp); err != nil {
+			return result, fmt.Errorf("bulk indexing: %w", err)
+		}
+		for _, item := range resp.Items {
+			for _, outcome := range item {
+				if outcome.Error == nil && outcome.Status < 300 {
+					result.Indexed++
+					continue
+				}
+				result.Failed++
+				if result.FirstError == "" && outcome.Error != nil {
+					result.FirstError = outcome.Error.Type + ": " + outcome.Error.Reason
+				}
+			}
+		}
+	}
+	return result, nil
+}
+
+// DocumentID identifies a finding's document for one run
+func DocumentID(f report.Finding, timestamp time.Time) string {
+	return f.Fingerprint + "-" + strconv.FormatInt(timestamp.Unix(), 10)
+}
+
+// newOpenSearchDocument flattens a finding and its run into a document
+func newOpenSearchDocument(f report.Finding, run Run) openSearchDocument {
+	doc := openSearchDocument{
+		Timestamp:   run.Timestamp.UTC().Format(time.RFC3339),
+		Tool:        "s3spectre",
+		Version:     run.Version,
+		Command:     run.Command,
+		Target:      run.Target,
+		Status:      f.Status,
+		Severity:    f.Severity,
+		Resource:    f.Resource,
+		Bucket:      f.Bucket,
+		Prefix:      f.Prefix,
+		Account:     f.Account,
+		Region:      f.Region,
+		Message:     f.Message,
+		Fingerprint: f.Fingerprint,
+	}
+	doc.Category = findingCategory(f.Status)
+	doc.References = referenceLocations(f)
+	if f.Blame != nil {
+		doc.Author = f.Blame.Author
+		doc.Commit = f.Blame.Commit
+	}
+	return doc
+}
+
+// findingCategory returns the category of the rule behind a status
+func findingCategory(status string) string {
+	rule, ok := rules.ForStatus(status)
+	if !ok {
+		// Rules without a status of their own, such as PUBLIC_BUCKET
+		rule, ok = rules.Lookup(status)
+	}
+	if !ok {
+		return ""
+	}
+	return rule.Category
+}
+
+// referenceLocations lists a finding's references as file:line
+func referenceLocations(f report.Finding) []string {
+	var locations []string
+	for _, ref := range f.References {
+		location := ref.File
+		if ref.Line > 0 {
+			location += ":" + strconv.Itoa(ref.Line)
+		}
+		locations = append(locations, location)
+	}
+	return locations
+}
+
+func (o *OpenSearch) request(ctx context.Context, method, path string, body io.Reader) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, method, o.URL+path, body)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+	switch {
+	case o.APIKey != "":
+		req.Header.Set("Authorization", "ApiKey "+o.APIKey)
+	case o.User != "":
+		req.SetBasicAuth(o.User, o.Password)
+	}
+	return req, nil
+}
+
+// send performs the request and surfaces the cluster's error reason
+func (o *OpenSearch) send(req *http.Request, out any) error {
+	resp, err := o.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		var detail struct {
+			Error struct {
+				Type   string `json:"type"`
+				Reason string `json:"reason"`
+			} `json:"error"`
+		}
+		data, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		if json.Unmarshal(data, &detail) == nil && detail.Error.Reason != "" {
+			return fmt.Errorf("%s: %s: %s", resp.Status, detail.Error.Type, detail.Error.Reason)
+		}
+		return errors.New(resp.Status)
+	}
+	if out == nil {
+		_, _ = io.Copy(io.Discard, resp.Body)
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
@@ -0,0 +1,184 @@
+package integrations
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ppiankov/s3spectre/internal/report"
+	"github.com/ppiankov/s3spectre/internal/scanner"
+)
+
+// fakeOpenSearch serves the index template and bulk APIs; documents with a
+// rejected status are refused
+type fakeOpenSearch struct {
+	mu       sync.Mutex
+	template map[string]any
+	actions  []map[string]map[string]string
+	docs     []map[string]any
+	bulks    int
+	auth     string
+	rejected string
+}
+
+func (f *fakeOpenSearch) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.auth = r.Header.Get("Authorization")
+	switch {
+	case r.Method == http.MethodPut && r.URL.Path == "/_index_template/s3spectre-findings":
+		_ = json.NewDecoder(r.Body).Decode(&f.template)
+		_, _ = w.Write([]byte(`{"acknowledged": true}`))
+	case r.Method == http.MethodPost && r.URL.Path == "/_bulk":
+		if r.Header.Get("Content-Type") != "application/x-ndjson" {
+			w.WriteHeader(http.StatusNotAcceptable)
+			return
+		}
+		f.bulks++
+		var items []string
+		lines := bufio.NewScanner(r.Body)
+		for lines.Scan() {
+			var action map[string]map[string]string
+			_ = json.Unmarshal(lines.Bytes(), &action)
+			lines.Scan()
+			var doc map[string]any
+			_ = json.Unmarshal(lines.Bytes(), &doc)
+			f.actions = append(f.actions, action)
+			f.docs = append(f.docs, doc)
+			if doc["status"] == f.rejected {
+				items = append(items, `{"index": {"status": 400, "error": {"type": "mapper_parsing_exception", "reason": "failed to parse field [status]"}}}`)
+			} else {
+				items = append(items, `{"index": {"status": 201}}`)
+			}
+		}
+		fmt.Fprintf(w, `{"errors": %v, "items": [%s]}`, f.rejected != "", strings.Join(items, ","))
+	default:
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = w.Write([]byte(`{"error": {"type": "index_not_found_exception", "reason": "no such index"}, "status": 404}`))
+	}
+}
+
+func TestOpenSearch_IndexFindings(t *testing.T) {
+	fake := &fakeOpenSearch{rejected: "INACTIVE"}
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	search, err := NewOpenSearch(server.URL+"/", DefaultOpenSearchIndex, "", "", "c2VjcmV0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := search.PutTemplate(context.Background()); err != nil {
+		t.Fatalf("PutTemplate failed: %v", err)
+	}
+	if patterns := fake.template["index_patterns"]; fmt.Sprint(patterns) != "[s3spectre-findings*]" {
+		t.Errorf("index_patterns = %v", patterns)
+	}
+	if fake.auth != "ApiKey c2VjcmV0" {
+		t.Errorf("Authorization = %q, want the API key", fake.auth)
+	}
+
+	findings := []report.Finding{{
+		Status: "MISSING_BUCKET", Severity: "medium", Resource: "s3://gone", Bucket: "gone", Fingerprint: "fp-gone",
+		References: []scanner.Reference{{File: "app/config.yaml", Line: 4}},
+		Blame:      &scanner.Blame{Author: "Ada", Commit: "abc123"},
+	}}
+	for k := 0; k < openSearchBatch; k++ {
+		findings = append(findings, report.Finding{Status: "INACTIVE", Severity: "low", Resource: fmt.Sprintf("s3://old-%d", k), Fingerprint: fmt.Sprintf("fp-%d", k)})
+	}
+	run := Run{Command: "scan", Target: "/repo", Version: "1.2.0", Timestamp: time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)}
+
+	result, err := search.IndexFindings(context.Background(), run, findings)
+	if err != nil {
+		t.Fatalf("IndexFindings failed: %v", err)
+	}
+	if result.Indexed != 1 || result.Failed != openSearchBatch || !strings.Contains(result.FirstError, "mapper_parsing_exception") {
+		t.Errorf("result = %+v, want 1 indexed and the rest rejected", result)
+	}
+	if fake.bulks != 2 {
+		t.Errorf("bulk requests = %d, want 2", fake.bulks)
+	}
+	if action := fake.actions[0]["index"]; action["_index"] != DefaultOpenSearchIndex || action["_id"] != "fp-gone-1772366400" {
+		t.Errorf("action = %v", action)
+	}
+	doc := fake.docs[0]
+	for field, want := range map[string]any{
+		"@timestamp": "2026-03-01T12:00:00Z", "tool": "s3spectre", "command": "scan", "target": "/repo",
+		"status": "MISSING_BUCKET", "category": "drift", "bucket": "gone", "author": "Ada",
+	} {
+		if doc[field] != want {
+			t.Errorf("document %s = %v, want %v", field, doc[field], want)
+		}
+	}
+	if fmt.Sprint(doc["references"]) != "[app/config.yaml:4]" {
+		t.Errorf("document references = %v", doc["references"])
+	}
+}
+
+func TestOpenSearch_Errors(t *testing.T) {
+	fake := &fakeOpenSearch{}
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	search, err := NewOpenSearch(server.URL, "other-index", "elastic", "changeme", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = search.PutTemplate(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "index_not_found_exception: no such index") {
+		t.Errorf("PutTemplate error = %v, want the cluster's reason", err)
+	}
+	if !strings.HasPrefix(fake.auth, "Basic ") {
+		t.Errorf("Authorization = %q, want basic auth", fake.auth)
+	}
+
+	for _, index := range []string{"", "Findings", "-findings", "find*ings", "a,b"} {
+		if _, err := NewOpenSearch(server.URL, index, "", "", ""); err == nil {
+			t.Errorf("index %q should be rejected", index)
+		}
+	}
+	if _, err := NewOpenSearch("search.example.com:9200", DefaultOpenSearchIndex, "", "", ""); err == nil {
+		t.Error("a URL without a scheme should be rejected")
+	}
+}
+
+func TestIndexTemplate(t *testing.T) {
+	data, err := IndexTemplate("s3spectre-prod")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var template struct {
+		IndexPatterns []string `json:"index_patterns"`
+		Template      struct {
+			Mappings struct {
+				Properties map[string]map[string]string `json:"properties"`
+			} `json:"mappings"`
+		} `json:"template"`
+	}
+	if err := json.Unmarshal(data, &template); err != nil {
+		t.Fatal(err)
+	}
+	if len(template.IndexPatterns) != 1 || template.IndexPatterns[0] != "s3spectre-prod*" {
+		t.Errorf("index_patterns = %v", template.IndexPatterns)
+	}
+	// Every document field is mapped
+	doc, _ := json.Marshal(openSearchDocument{})
+	var fields map[string]any
+	_ = json.Unmarshal(doc, &fields)
+	for field := range fields {
+		if _, ok := template.Template.Mappings.Properties[field]; !ok {
+			t.Errorf("field %s has no mapping", field)
+		}
+	}
+	for _, field := range []string{"version", "target", "bucket", "prefix", "account", "region", "message", "category", "references", "author", "commit"} {
+		if _, ok := template.Template.Mappings.Properties[field]; !ok {
+			t.Errorf("field %s has no mapping", field)
+		}
+	}
+}
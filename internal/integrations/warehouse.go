@@ -0,0 +1,218 @@
+package integrations
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+
+	"github.com/ppiankov/s3spectre/internal/report"
+)
+
+// Warehouse dialects WarehouseSQL writes
+const (
+	DialectRedshift = "redshift"
+	DialectBigQuery = "bigquery"
+)
+
+// warehouseTimeFormat is a timestamp layout Redshift's TIMEFORMAT 'auto' and
+// BigQuery's TIMESTAMP both parse
+const warehouseTimeFormat = "2006-01-02 15:04:05"
+
+// WarehouseRow is a finding as a row of the warehouse findings table. Every
+// column is always present, so loads do not depend on which findings a run
+// had. Account, bucket and region join with the cost and usage report.
+type WarehouseRow struct {
+	RunAt       string `json:"run_at"`
+	Tool        string `json:"tool"`
+	Version     string `json:"version"`
+	Command     string `json:"command"`
+	Target      string `json:"target"`
+	Status      string `json:"status"`
+	Severity    string `json:"severity"`
+	Category    string `json:"category"`
+	Resource    string `json:"resource"`
+	Bucket      string `json:"bucket"`
+	Prefix      string `json:"prefix"`
+	Account     string `json:"account"`
+	Region      string `json:"region"`
+	RiskScore   int    `json:"risk_score"`
+	SizeBytes   int64  `json:"size_bytes"`
+	Message     string `json:"message"`
+	Fingerprint string `json:"fingerprint"`
+	// References are the scan finding's file:line locations, comma-separated
+	References string `json:"references"`
+}
+
+// warehouseColumn is a column of the findings table and its type in each
+// dialect
+type warehouseColumn struct {
+	name     string
+	redshift string
+	bigquery string
+}
+
+// warehouseColumns lists the findings table in WarehouseRow order
+var warehouseColumns = []warehouseColumn{
+	{"run_at", "TIMESTAMP", "TIMESTAMP"},
+	{"tool", "VARCHAR(32)", "STRING"},
+	{"version", "VARCHAR(64)", "STRING"},
+	{"command", "VARCHAR(16)", "STRING"},
+	{"target", "VARCHAR(1024)", "STRING"},
+	{"status", "VARCHAR(64)", "STRING"},
+	{"severity", "VARCHAR(16)", "STRING"},
+	{"category", "VARCHAR(32)", "STRING"},
+	{"resource", "VARCHAR(2048)", "STRING"},
+	{"bucket", "VARCHAR(63)", "STRING"},
+	{"prefix", "VARCHAR(1024)", "STRING"},
+	{"account", "VARCHAR(12)", "STRING"},
+	{"region", "VARCHAR(32)", "STRING"},
+	{"risk_score", "INTEGER", "INT64"},
+	{"size_bytes", "BIGINT", "INT64"},
+	{"message", "VARCHAR(65535)", "STRING"},
+	{"fingerprint", "VARCHAR(64)", "STRING"},
+	{"references", "VARCHAR(65535)", "STRING"},
+}
+
+// NewWarehouseRow flattens a finding and its run into a row
+func NewWarehouseRow(f report.Finding, run Run) WarehouseRow {
+	return WarehouseRow{
+		RunAt:       run.Timestamp.UTC().Format(warehouseTimeFormat),
+		Tool:        "s3spectre",
+		Version:     run.Version,
+		Command:     run.Command,
+		Target:      run.Target,
+		Status:      f.Status,
+		Severity:    f.Severity,
+		Category:    findingCategory(f.Status),
+		Resource:    f.Resource,
+		Bucket:      f.Bucket,
+		Prefix:      f.Prefix,
+		Account:     f.Account,
+		Region:      f.Region,
+		RiskScore:   f.RiskScore,
+		SizeBytes:   f.SizeBytes,
+		Message:     f.Message,
+		Fingerprint: f.Fingerprint,
+		References:  strings.Join(referenceLocations(f), ","),
+	}
+}
+
+// WriteWarehouseJSONL writes one JSON row per finding, the newline-delimited
+// JSON both Redshift COPY and BigQuery load jobs read
+func WriteWarehouseJSONL(w io.Writer, run Run, findings []report.Finding) error {
+	enc := json.NewEncoder(w)
+	for _, f := range findings {
+		if err := enc.Encode(NewWarehouseRow(f, run)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// WarehouseObjectName names a run's export under a prefix, partitioned by
+// day (dt=YYYY-MM-DD) so a load can pick up a single day
+func WarehouseObjectName(prefix string, run Run) string {
+	ts := run.Timestamp.UTC()
+	name := fmt.Sprintf("dt=%s/%s-%s.jsonl", ts.Format("2006-01-02"), run.Command, ts.Format("20060102T150405Z"))
+	if prefix = strings.Trim(prefix, "/"); prefix == "" {
+		return name
+	}
+	return path.Join(prefix, name)
+}
+
+// WarehouseLoad describes the SQL that creates the findings table and loads
+// exported findings into it
+type WarehouseLoad struct {
+	Dialect string
+	// Table is the table name, qualified as the warehouse expects
+	// (schema.table or dataset.table)
+	Table string
+	// Source is the exported objects: an s3:// prefix, or for BigQuery a
+	// gs:// prefix
+	Source string
+	// IAMRole is the role Redshift assumes to read Source; empty uses the
+	// cluster's default role
+	IAMRole string
+	// Connection is the BigQuery Omni connection that reads s3:// sources,
+	// e.g. aws-us-east-1.s3spectre
+	Connection string
+}
+
+// WarehouseSQL writes the statements that create the findings table and
+// load the export into it
+func WarehouseSQL(w io.Writer, load WarehouseLoad) error {
+	if load.Table == "" {
+		return fmt.Errorf("a table name is required")
+	}
+	if !strings.HasPrefix(load.Source, "s3://") && !strings.HasPrefix(load.Source, "gs://") {
+		return fmt.Errorf("source must be an s3:// or gs:// prefix, got %q", load.Source)
+	}
+	switch load.Dialect {
+	case DialectRedshift:
+		if !strings.HasPrefix(load.Source, "s3://") {
+			return fmt.Errorf("redshift loads from s3:// only, got %q", load.Source)
+		}
+		return redshiftSQL(w, load)
+	case DialectBigQuery:
+		if strings.HasPrefix(load.Source, "s3://") && load.Connection == "" {
+			return fmt.Errorf("bigquery needs an Omni connection to load from s3://")
+		}
+		return bigQuerySQL(w, load)
+	}
+	return fmt.Errorf("unknown dialect %q (expected %s or %s)", load.Dialect, DialectRedshift, DialectBigQuery)
+}
+
+func redshiftSQL(w io.Writer, load WarehouseLoad) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "CREATE TABLE IF NOT EXISTS %s (\n", load.Table)
+	for k, c := range warehouseColumns {
+		fmt.Fprintf(&b, "  %s %s%s\n", quoteRedshift(c.name), c.redshift, separator(k))
+	}
+	b.WriteString(")\nSORTKEY (run_at);\n\n")
+	role := "default"
+	if load.IAMRole != "" {
+		role = quoteString(load.IAMRole)
+	}
+	fmt.Fprintf(&b, "COPY %s\nFROM %s\nIAM_ROLE %s\nFORMAT AS JSON 'auto'\nTIMEFORMAT 'auto'\nTRUNCATECOLUMNS;\n", load.Table, quoteString(load.Source), role)
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func bigQuerySQL(w io.Writer, load WarehouseLoad) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "CREATE TABLE IF NOT EXISTS `%s` (\n", load.Table)
+	for k, c := range warehouseColumns {
+		fmt.Fprintf(&b, "  `%s` %s%s\n", c.name, c.bigquery, separator(k))
+	}
+	b.WriteString(")\nPARTITION BY DATE(run_at);\n\n")
+	fmt.Fprintf(&b, "LOAD DATA INTO `%s`\nFROM FILES (\n  format = 'JSON',\n  uris = [%s]\n)", load.Table, quoteString(strings.TrimSuffix(load.Source, "/")+"/*"))
+	if load.Connection != "" {
+		fmt.Fprintf(&b, "\nWITH CONNECTION `%s`", load.Connection)
+	}
+	b.WriteString(";\n")
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// quoteRedshift quotes column names that are reserved words in Redshift
+func quoteRedshift(name string) string {
+	if name == "references" {
+		return `"` + name + `"`
+	}
+	return name
+}
+
+// quoteString quotes a SQL string literal
+func quoteString(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
+
+// separator returns the comma after every column but the last
+func separator(k int) string {
+	if k == len(warehouseColumns)-1 {
+		return ""
+	}
+	return ","
+}
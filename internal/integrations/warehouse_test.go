@@ -0,0 +1,128 @@
+package integrations
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ppiankov/s3spectre/internal/report"
+	"github.com/ppiankov/s3spectre/internal/scanner"
+)
+
+func TestWriteWarehouseJSONL(t *testing.T) {
+	run := Run{Command: "discover", Target: "111122223333", Version: "1.2.0", Timestamp: time.Date(2026, 3, 1, 12, 30, 0, 0, time.FixedZone("CET", 3600))}
+	findings := []report.Finding{
+		{Status: "UNUSED_BUCKET", Severity: "medium", Resource: "s3://old-logs", Bucket: "old-logs", Account: "111122223333",
+			Region: "eu-west-1", RiskScore: 40, SizeBytes: 1 << 30, Fingerprint: "fp-old"},
+		{Status: "MISSING_BUCKET", Severity: "high", Resource: "s3://gone", Bucket: "gone", Fingerprint: "fp-gone",
+			References: []scanner.Reference{{File: "app/config.yaml", Line: 4}, {File: ".env"}}},
+	}
+	var out bytes.Buffer
+	if err := WriteWarehouseJSONL(&out, run, findings); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSuffix(out.String(), "\n"), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("got %d rows, want 2:\n%s", len(lines), out.String())
+	}
+
+	var row WarehouseRow
+	if err := json.Unmarshal([]byte(lines[0]), &row); err != nil {
+		t.Fatal(err)
+	}
+	want := WarehouseRow{
+		RunAt: "2026-03-01 11:30:00", Tool: "s3spectre", Version: "1.2.0", Command: "discover", Target: "111122223333",
+		Status: "UNUSED_BUCKET", Severity: "medium", Category: "cost", Resource: "s3://old-logs", Bucket: "old-logs",
+		Account: "111122223333", Region: "eu-west-1", RiskScore: 40, SizeBytes: 1 << 30, Fingerprint: "fp-old",
+	}
+	if row != want {
+		t.Errorf("row = %+v, want %+v", row, want)
+	}
+	if err := json.Unmarshal([]byte(lines[1]), &row); err != nil {
+		t.Fatal(err)
+	}
+	if row.References != "app/config.yaml:4,.env" {
+		t.Errorf("references = %q", row.References)
+	}
+
+	// Every column is written, in table order, even when empty
+	var keys []string
+	dec := json.NewDecoder(strings.NewReader(lines[1]))
+	_, _ = dec.Token()
+	for dec.More() {
+		key, _ := dec.Token()
+		keys = append(keys, key.(string))
+		var skip any
+		_ = dec.Decode(&skip)
+	}
+	var columns []string
+	for _, c := range warehouseColumns {
+		columns = append(columns, c.name)
+	}
+	if !reflect.DeepEqual(keys, columns) {
+		t.Errorf("row keys = %v, want the table columns %v", keys, columns)
+	}
+}
+
+func TestWarehouseObjectName(t *testing.T) {
+	run := Run{Command: "scan", Timestamp: time.Date(2026, 3, 1, 23, 5, 9, 0, time.UTC)}
+	for prefix, want := range map[string]string{
+		"":                   "dt=2026-03-01/scan-20260301T230509Z.jsonl",
+		"finops/s3spectre/":  "finops/s3spectre/dt=2026-03-01/scan-20260301T230509Z.jsonl",
+		"/finops/s3spectre/": "finops/s3spectre/dt=2026-03-01/scan-20260301T230509Z.jsonl",
+	} {
+		if got := WarehouseObjectName(prefix, run); got != want {
+			t.Errorf("WarehouseObjectName(%q) = %q, want %q", prefix, got, want)
+		}
+	}
+}
+
+func TestWarehouseSQL(t *testing.T) {
+	var out bytes.Buffer
+	err := WarehouseSQL(&out, WarehouseLoad{Dialect: DialectRedshift, Table: "finops.s3spectre_findings",
+		Source: "s3://finops/s3spectre/dt=2026-03-01/", IAMRole: "arn:aws:iam::111122223333:role/RedshiftCopy"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"CREATE TABLE IF NOT EXISTS finops.s3spectre_findings (\n  run_at TIMESTAMP,\n",
+		`  "references" VARCHAR(65535)` + "\n)\nSORTKEY (run_at);",
+		"COPY finops.s3spectre_findings\nFROM 's3://finops/s3spectre/dt=2026-03-01/'\nIAM_ROLE 'arn:aws:iam::111122223333:role/RedshiftCopy'\nFORMAT AS JSON 'auto'",
+	} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("redshift SQL missing %q:\n%s", want, out.String())
+		}
+	}
+
+	out.Reset()
+	err = WarehouseSQL(&out, WarehouseLoad{Dialect: DialectBigQuery, Table: "acme.finops.s3spectre_findings",
+		Source: "s3://finops/s3spectre", Connection: "aws-us-east-1.s3spectre"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"CREATE TABLE IF NOT EXISTS `acme.finops.s3spectre_findings` (\n  `run_at` TIMESTAMP,\n",
+		"  `size_bytes` INT64,\n",
+		"PARTITION BY DATE(run_at);",
+		"uris = ['s3://finops/s3spectre/*']\n)\nWITH CONNECTION `aws-us-east-1.s3spectre`;",
+	} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("bigquery SQL missing %q:\n%s", want, out.String())
+		}
+	}
+
+	for name, load := range map[string]WarehouseLoad{
+		"unknown dialect":          {Dialect: "snowflake", Table: "t", Source: "s3://finops/"},
+		"no table":                 {Dialect: DialectRedshift, Source: "s3://finops/"},
+		"local source":             {Dialect: DialectRedshift, Table: "t", Source: "/tmp/findings"},
+		"redshift from gcs":        {Dialect: DialectRedshift, Table: "t", Source: "gs://finops/"},
+		"bigquery s3 without omni": {Dialect: DialectBigQuery, Table: "t", Source: "s3://finops/"},
+	} {
+		if err := WarehouseSQL(&out, load); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
@@ -0,0 +1,188 @@
+package inventory
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ppiankov/s3spectre/internal/s3"
+)
+
+// Supported export formats
+const (
+	FormatCSV     = "csv"
+	FormatParquet = "parquet"
+)
+
+// kind is the value type of a column
+type kind int
+
+const (
+	kindString kind = iota
+	kindBool
+	kindInt64
+	kindTimestamp
+)
+
+// column is one field of the exported inventory. value returns nil when the
+// field is unknown for a bucket, which is written as an empty CSV cell or a
+// Parquet null.
+type column struct {
+	name  string
+	kind  kind
+	value func(b *s3.BucketInfo) any
+}
+
+// columns flattens BucketInfo into one row per bucket. Tags are exported as
+// a JSON object; prefixes are scan-only and left out.
+var columns = []column{
+	{"name", kindString, func(b *s3.BucketInfo) any { return b.Name }},
+	{"exists", kindBool, func(b *s3.BucketInfo) any { return b.Exists }},
+	{"region", kindString, func(b *s3.BucketInfo) any { return optString(b.Region) }},
+	{"account_id", kindString, func(b *s3.BucketInfo) any { return optString(b.AccountID) }},
+	{"outpost_id", kindString, func(b *s3.BucketInfo) any { return optString(b.OutpostID) }},
+	{"creation_date", kindTimestamp, func(b *s3.BucketInfo) any { return optTime(b.CreationDate) }},
+	{"last_activity", kindTimestamp, func(b *s3.BucketInfo) any { return optTime(b.LastActivity) }},
+	{"days_since_activity", kindInt64, func(b *s3.BucketInfo) any { return int64(b.DaysSinceActivity) }},
+	{"age_in_days", kindInt64, func(b *s3.BucketInfo) any { return int64(b.AgeInDays) }},
+	{"versioning_enabled", kindBool, func(b *s3.BucketInfo) any { return b.VersioningEnabled }},
+	{"lifecycle_rules", kindInt64, func(b *s3.BucketInfo) any { return int64(b.LifecycleRules) }},
+	{"is_empty", kindBool, func(b *s3.BucketInfo) any { return b.IsEmpty }},
+	{"object_count", kindInt64, func(b *s3.BucketInfo) any { return int64(b.ObjectCount) }},
+	{"total_size", kindInt64, func(b *s3.BucketInfo) any { return b.TotalSize }},
+	{"total_version_size", kindInt64, func(b *s3.BucketInfo) any { return b.TotalVersionSize }},
+	{"version_count", kindInt64, func(b *s3.BucketInfo) any { return int64(b.VersionCount) }},
+	{"encryption_enabled", kindBool, func(b *s3.BucketInfo) any {
+		if b.Encryption == nil {
+			return nil
+		}
+		return b.Encryption.Enabled
+	}},
+	{"encryption_algorithm", kindString, func(b *s3.BucketInfo) any {
+		if b.Encryption == nil {
+			return nil
+		}
+		return optString(b.Encryption.Algorithm)
+	}},
+	{"kms_key_id", kindString, func(b *s3.BucketInfo) any {
+		if b.Encryption == nil {
+			return nil
+		}
+		return optString(b.Encryption.KMSMasterKeyID)
+	}},
+	{"is_public", kindBool, publicAccess(func(p *s3.PublicAccessInfo) bool { return p.IsPublic })},
+	{"block_public_acls", kindBool, publicAccess(func(p *s3.PublicAccessInfo) bool { return p.BlockPublicAcls })},
+	{"ignore_public_acls", kindBool, publicAccess(func(p *s3.PublicAccessInfo) bool { return p.IgnorePublicAcls })},
+	{"block_public_policy", kindBool, publicAccess(func(p *s3.PublicAccessInfo) bool { return p.BlockPublicPolicy })},
+	{"restrict_public_buckets", kindBool, publicAccess(func(p *s3.PublicAccessInfo) bool { return p.RestrictPublicBuckets })},
+	{"tags", kindString, func(b *s3.BucketInfo) any {
+		if len(b.Tags) == 0 {
+			return nil
+		}
+		data, _ := json.Marshal(b.Tags)
+		return string(data)
+	}},
+	{"error", kindString, func(b *s3.BucketInfo) any { return optString(b.Error) }},
+}
+
+func optString(s string) any {
+	if s == "" {
+		return nil
+	}
+	return s
+}
+
+func optTime(t *time.Time) any {
+	if t == nil {
+		return nil
+	}
+	return t.UTC()
+}
+
+func publicAccess(field func(*s3.PublicAccessInfo) bool) func(*s3.BucketInfo) any {
+	return func(b *s3.BucketInfo) any {
+		if b.PublicAccess == nil {
+			return nil
+		}
+		return field(b.PublicAccess)
+	}
+}
+
+// ResolveFormat returns the export format, inferred from the file extension
+// when format is empty (.parquet for Parquet, CSV otherwise)
+func ResolveFormat(format, path string) (string, error) {
+	if format == "" {
+		if strings.EqualFold(filepath.Ext(path), ".parquet") {
+			return FormatParquet, nil
+		}
+		return FormatCSV, nil
+	}
+	switch format {
+	case FormatCSV, FormatParquet:
+		return format, nil
+	default:
+		return "", fmt.Errorf("unsupported export format: %s (supported: csv, parquet)", format)
+	}
+}
+
+// Write exports the raw bucket inventory, one row per bucket sorted by name
+func Write(w io.Writer, format string, buckets map[string]*s3.BucketInfo) error {
+	rows := make([]*s3.BucketInfo, 0, len(buckets))
+	for _, b := range buckets {
+		rows = append(rows, b)
+	}
+	sort.Slice(rows, func(i, j int) bool { return rows[i].Name < rows[j].Name })
+
+	switch format {
+	case FormatCSV:
+		return writeCSV(w, rows)
+	case FormatParquet:
+		return writeParquet(w, rows)
+	default:
+		return fmt.Errorf("unsupported export format: %s (supported: csv, parquet)", format)
+	}
+}
+
+func writeCSV(w io.Writer, rows []*s3.BucketInfo) error {
+	cw := csv.NewWriter(w)
+	header := make([]string, len(columns))
+	for i, c := range columns {
+		header[i] = c.name
+	}
+	if err := cw.Write(header); err != nil {
+		return err
+	}
+
+	record := make([]string, len(columns))
+	for _, b := range rows {
+		for i, c := range columns {
+			record[i] = formatCSV(c.value(b))
+		}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+func formatCSV(v any) string {
+	switch v := v.(type) {
+	case string:
+		return v
+	case bool:
+		return strconv.FormatBool(v)
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case time.Time:
+		return v.Format(time.RFC3339)
+	default:
+		return ""
+	}
+}
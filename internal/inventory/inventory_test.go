@@ -0,0 +1,164 @@
+package inventory
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/parquet-go/parquet-go"
+
+	"github.com/ppiankov/s3spectre/internal/s3"
+)
+
+func testBuckets() map[string]*s3.BucketInfo {
+	created := time.Date(2023, 5, 1, 12, 0, 0, 0, time.UTC)
+	return map[string]*s3.BucketInfo{
+		"media-prod": {
+			Name:         "media-prod",
+			Exists:       true,
+			Region:       "us-east-1",
+			CreationDate: &created,
+			AgeInDays:    500,
+			ObjectCount:  42,
+			TotalSize:    1 << 20,
+			Tags:         map[string]string{"team": "media", "env": "prod"},
+			Encryption:   &s3.EncryptionInfo{Enabled: true, Algorithm: "aws:kms"},
+			PublicAccess: &s3.PublicAccessInfo{BlockPublicAcls: true},
+		},
+		"archive": {Name: "archive", Exists: true, IsEmpty: true},
+	}
+}
+
+func TestResolveFormat(t *testing.T) {
+	tests := []struct {
+		format, path, want string
+	}{
+		{"", "inventory.csv", FormatCSV},
+		{"", "inventory.PARQUET", FormatParquet},
+		{"", "inventory", FormatCSV},
+		{"parquet", "inventory.csv", FormatParquet},
+	}
+	for _, tt := range tests {
+		got, err := ResolveFormat(tt.format, tt.path)
+		if err != nil || got != tt.want {
+			t.Errorf("ResolveFormat(%q, %q) = %q, %v; want %q", tt.format, tt.path, got, err, tt.want)
+		}
+	}
+	if _, err := ResolveFormat("xlsx", "inventory.xlsx"); err == nil {
+		t.Fatalf("expected error for unsupported format")
+	}
+}
+
+func TestWrite_CSV(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Write(&buf, FormatCSV, testBuckets()); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("expected header and 2 rows, got %d lines:\n%s", len(lines), buf.String())
+	}
+	if !strings.HasPrefix(lines[0], "name,exists,region,") {
+		t.Fatalf("unexpected header: %s", lines[0])
+	}
+	// Rows are sorted by name; unknown encryption is an empty cell
+	if !strings.HasPrefix(lines[1], "archive,true,,,,,,0,0,false,0,true,") || !strings.Contains(lines[1], ",,,,,,,,,") {
+		t.Fatalf("unexpected archive row: %s", lines[1])
+	}
+	for _, want := range []string{"media-prod,true,us-east-1,", "2023-05-01T12:00:00Z", ",1048576,", "aws:kms", `"{""env"":""prod"",""team"":""media""}"`} {
+		if !strings.Contains(lines[2], want) {
+			t.Errorf("expected %q in media-prod row: %s", want, lines[2])
+		}
+	}
+}
+
+func TestWrite_Parquet(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Write(&buf, FormatParquet, testBuckets()); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	file, err := parquet.OpenFile(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("OpenFile failed: %v", err)
+	}
+	if file.NumRows() != 2 {
+		t.Fatalf("expected 2 rows, got %d", file.NumRows())
+	}
+	if fields := file.Schema().Fields(); len(fields) != len(columns) {
+		t.Fatalf("expected %d columns, got %d", len(columns), len(fields))
+	}
+	created, ok := file.Schema().Lookup("creation_date")
+	if !ok || created.Node.Type().LogicalType().Timestamp == nil {
+		t.Fatalf("creation_date is not a timestamp column: %v", created.Node)
+	}
+
+	rows := readRows(t, file)
+	if rows[0]["name"] != "archive" || rows[1]["name"] != "media-prod" {
+		t.Fatalf("unexpected names: %v, %v", rows[0]["name"], rows[1]["name"])
+	}
+	// Unknown encryption is a null, not false
+	if _, ok := rows[0]["encryption_enabled"]; ok || rows[1]["encryption_enabled"] != true {
+		t.Fatalf("unexpected encryption_enabled values: %v, %v", rows[0]["encryption_enabled"], rows[1]["encryption_enabled"])
+	}
+	if rows[1]["creation_date"] != time.Date(2023, 5, 1, 12, 0, 0, 0, time.UTC).UnixMilli() {
+		t.Fatalf("unexpected creation_date: %v", rows[1]["creation_date"])
+	}
+	if rows[1]["total_size"] != int64(1<<20) || rows[1]["tags"] != `{"env":"prod","team":"media"}` {
+		t.Fatalf("unexpected media-prod row: %v", rows[1])
+	}
+}
+
+func TestWrite_ParquetEmpty(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Write(&buf, FormatParquet, nil); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	file, err := parquet.OpenFile(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("OpenFile failed: %v", err)
+	}
+	if file.NumRows() != 0 {
+		t.Fatalf("expected no rows, got %d", file.NumRows())
+	}
+}
+
+// readRows reads a file's rows into maps of column name to value, leaving
+// nulls out
+func readRows(t *testing.T, file *parquet.File) []map[string]any {
+	t.Helper()
+	fields := file.Schema().Fields()
+	reader := parquet.NewReader(file)
+	defer func() { _ = reader.Close() }()
+
+	var out []map[string]any
+	rows := make([]parquet.Row, 1)
+	for {
+		n, err := reader.ReadRows(rows)
+		for _, row := range rows[:n] {
+			values := make(map[string]any)
+			for _, v := range row {
+				if v.IsNull() {
+					continue
+				}
+				name := fields[v.Column()].Name()
+				switch v.Kind() {
+				case parquet.Boolean:
+					values[name] = v.Boolean()
+				case parquet.Int64:
+					values[name] = v.Int64()
+				default:
+					values[name] = v.String()
+				}
+			}
+			out = append(out, values)
+		}
+		if err == io.EOF {
+			return out
+		}
+		if err != nil {
+			t.Fatalf("ReadRows failed: %v", err)
+		}
+	}
+}
@@ -0,0 +1,73 @@
+package inventory
+
+import (
+	"io"
+	"time"
+
+	"github.com/parquet-go/parquet-go"
+
+	"github.com/ppiankov/s3spectre/internal/s3"
+)
+
+// parquetSchema is a flat schema of optional fields, one per column.
+// Parquet groups order their fields by name, so leaf columns are in
+// alphabetical order rather than the CSV column order.
+var parquetSchema = newParquetSchema()
+
+func newParquetSchema() *parquet.Schema {
+	group := make(parquet.Group, len(columns))
+	for _, c := range columns {
+		group[c.name] = parquet.Optional(parquetNode(c.kind))
+	}
+	return parquet.NewSchema("bucket", group)
+}
+
+// parquetNode maps a column kind to its Parquet type
+func parquetNode(k kind) parquet.Node {
+	switch k {
+	case kindBool:
+		return parquet.Leaf(parquet.BooleanType)
+	case kindInt64:
+		return parquet.Int(64)
+	case kindTimestamp:
+		return parquet.Timestamp(parquet.Millisecond)
+	default:
+		return parquet.String()
+	}
+}
+
+func writeParquet(w io.Writer, rows []*s3.BucketInfo) error {
+	fields := parquetSchema.Fields()
+	byName := make(map[string]column, len(columns))
+	for _, c := range columns {
+		byName[c.name] = c
+	}
+
+	pw := parquet.NewWriter(w, parquetSchema)
+	batch := make([]parquet.Row, 0, len(rows))
+	for _, b := range rows {
+		row := make(parquet.Row, len(fields))
+		for i, field := range fields {
+			value := parquetValue(byName[field.Name()].value(b))
+			definition := 1
+			if value.IsNull() {
+				definition = 0
+			}
+			row[i] = value.Level(0, definition, i)
+		}
+		batch = append(batch, row)
+	}
+	if _, err := pw.WriteRows(batch); err != nil {
+		return err
+	}
+	return pw.Close()
+}
+
+// parquetValue converts a column value; timestamps are stored as Unix
+// milliseconds
+func parquetValue(v any) parquet.Value {
+	if t, ok := v.(time.Time); ok {
+		return parquet.Int64Value(t.UnixMilli())
+	}
+	return parquet.ValueOf(v)
+}
@@ -0,0 +1,146 @@
+// Package notify sends a summary of a run's findings to chat channels and
+// webhooks.
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ppiankov/s3spectre/internal/report"
+	"github.com/ppiankov/s3spectre/internal/rules"
+)
+
+// DefaultTimeout bounds each notification request
+const DefaultTimeout = 10 * time.Second
+
+// Payload summarizes the findings a notification is about
+type Payload struct {
+	Tool      string    `json:"tool"`
+	Version   string    `json:"version,omitempty"`
+	Command   string    `json:"command"`
+	Target    string    `json:"target,omitempty"`
+	Timestamp time.Time `json:"timestamp"`
+	// MinSeverity is the lowest severity the findings were filtered to
+	MinSeverity string `json:"min_severity"`
+	// Baseline is the report the run was compared with; when set, only
+	// findings new since it are listed
+	Baseline string           `json:"baseline,omitempty"`
+	Counts   map[string]int   `json:"counts"`
+	Findings []report.Finding `json:"findings"`
+}
+
+// NewPayload builds the payload for the findings at or above minSeverity,
+// most severe first, keeping only new ones when the run was compared with a
+// baseline
+func NewPayload(findings []report.Finding, minSeverity string, changes *report.BaselineChanges) Payload {
+	if changes != nil {
+		findings = report.NewSinceBaseline(findings, changes)
+	}
+	p := Payload{
+		MinSeverity: minSeverity,
+		Counts:      make(map[string]int),
+		Findings:    []report.Finding{},
+	}
+	if changes != nil {
+		p.Baseline = changes.Baseline
+	}
+	for _, f := range findings {
+		if !rules.SeverityAtLeast(f.Severity, minSeverity) {
+			continue
+		}
+		p.Findings = append(p.Findings, f)
+		p.Counts[f.Severity]++
+	}
+	sort.SliceStable(p.Findings, func(i, j int) bool {
+		return rules.Rank(p.Findings[i].Severity) > rules.Rank(p.Findings[j].Severity)
+	})
+	return p
+}
+
+// Notifier delivers a payload to one destination
+type Notifier interface {
+	// Name identifies the destination in errors, without its URL
+	Name() string
+	Notify(ctx context.Context, p Payload) error
+}
+
+// Send delivers the payload to every notifier, returning the errors of
+// those that failed
+func Send(ctx context.Context, notifiers []Notifier, p Payload) error {
+	var errs []error
+	for _, n := range notifiers {
+		if err := n.Notify(ctx, p); err != nil {
+			errs = append(errs, fmt.Errorf("%s notification: %w", n.Name(), err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Webhook posts the payload as JSON to a URL
+type Webhook struct {
+	URL    string
+	Client *http.Client
+}
+
+// NewWebhook creates a generic JSON webhook notifier
+func NewWebhook(url string) *Webhook {
+	return &Webhook{URL: url, Client: &http.Client{Timeout: DefaultTimeout}}
+}
+
+// Name identifies the notifier
+func (w *Webhook) Name() string { return "webhook" }
+
+// Notify posts the payload
+func (w *Webhook) Notify(ctx context.Context, p Payload) error {
+	body, err := json.Marshal(p)
+	if err != nil {
+		return err
+	}
+	return post(ctx, w.Client, w.URL, body)
+}
+
+// ValidateURL rejects webhook URLs that are not absolute http(s) URLs
+func ValidateURL(raw string) error {
+	u, err := url.Parse(raw)
+	if err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+		return fmt.Errorf("webhook URL must be an http or https URL")
+	}
+	return nil
+}
+
+// post sends a JSON body. Errors leave out the URL, which often embeds a
+// secret token.
+func post(ctx context.Context, client *http.Client, target string, body []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, target, bytes.NewReader(body))
+	if err != nil {
+		return errors.New("invalid webhook URL")
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := client.Do(req)
+	if err != nil {
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			return urlErr.Err
+		}
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		detail, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		if msg := strings.TrimSpace(string(detail)); msg != "" {
+			return fmt.Errorf("%s: %s", resp.Status, msg)
+		}
+		return errors.New(resp.Status)
+	}
+	_, _ = io.Copy(io.Discard, resp.Body)
+	return nil
+}
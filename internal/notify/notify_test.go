@@ -0,0 +1,168 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ppiankov/s3spectre/internal/report"
+	"github.com/ppiankov/s3spectre/internal/rules"
+)
+
+func testFindings() []report.Finding {
+	return []report.Finding{
+		{Status: "STALE_PREFIX", Severity: "low", Resource: "s3://logs/old/", Bucket: "logs", Prefix: "old/",
+			Fingerprint: rules.Fingerprint("STALE_PREFIX", "", "logs", "old/")},
+		{Status: "MISSING_BUCKET", Severity: "high", Resource: "s3://orders", Bucket: "orders", Message: "Bucket <orders> does not exist",
+			Fingerprint: rules.Fingerprint("MISSING_BUCKET", "", "orders", "")},
+		{Status: "MISSING_BUCKET", Severity: "high", Resource: "s3://payments", Bucket: "payments",
+			Fingerprint: rules.Fingerprint("MISSING_BUCKET", "", "payments", "")},
+	}
+}
+
+func TestNewPayload_FiltersBySeverity(t *testing.T) {
+	p := NewPayload(testFindings(), rules.SeverityHigh, nil)
+	if len(p.Findings) != 2 || p.Counts["high"] != 2 || p.Counts["low"] != 0 {
+		t.Fatalf("expected the 2 high findings, got %+v", p)
+	}
+
+	p = NewPayload(testFindings(), rules.SeverityLow, nil)
+	if len(p.Findings) != 3 {
+		t.Fatalf("expected all 3 findings, got %d", len(p.Findings))
+	}
+	if p.Findings[0].Severity != "high" || p.Findings[2].Severity != "low" {
+		t.Errorf("findings should be ordered most severe first: %+v", p.Findings)
+	}
+}
+
+func TestNewPayload_OnlyNewSinceBaseline(t *testing.T) {
+	changes := &report.BaselineChanges{
+		Baseline: "baseline.json",
+		New:      []report.BaselineChange{{Type: "MISSING_BUCKET", Bucket: "payments", Severity: "high"}},
+	}
+	p := NewPayload(testFindings(), rules.SeverityHigh, changes)
+	if len(p.Findings) != 1 || p.Findings[0].Bucket != "payments" {
+		t.Fatalf("expected only the new payments finding, got %+v", p.Findings)
+	}
+	if p.Baseline != "baseline.json" {
+		t.Errorf("Baseline = %q", p.Baseline)
+	}
+
+	p = NewPayload(testFindings(), rules.SeverityHigh, &report.BaselineChanges{Baseline: "baseline.json"})
+	if len(p.Findings) != 0 {
+		t.Errorf("nothing is new, got %+v", p.Findings)
+	}
+}
+
+func TestWebhook_PostsJSON(t *testing.T) {
+	var got Payload
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if ct := r.Header.Get("Content-Type"); ct != "application/json" {
+			t.Errorf("Content-Type = %q", ct)
+		}
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			t.Errorf("decode: %v", err)
+		}
+	}))
+	defer srv.Close()
+
+	p := NewPayload(testFindings(), rules.SeverityHigh, nil)
+	p.Tool, p.Command = "s3spectre", "scan"
+	if err := NewWebhook(srv.URL).Notify(context.Background(), p); err != nil {
+		t.Fatalf("Notify: %v", err)
+	}
+	if got.Command != "scan" || len(got.Findings) != 2 || got.Counts["high"] != 2 {
+		t.Errorf("unexpected payload: %+v", got)
+	}
+}
+
+func TestSlack_Message(t *testing.T) {
+	var body slackPayload
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Errorf("decode: %v", err)
+		}
+	}))
+	defer srv.Close()
+
+	p := NewPayload(testFindings(), rules.SeverityHigh, nil)
+	p.Tool, p.Version, p.Command, p.Target = "s3spectre", "1.2.3", "scan", "./repo"
+	if err := NewSlack(srv.URL).Notify(context.Background(), p); err != nil {
+		t.Fatalf("Notify: %v", err)
+	}
+	if body.Text != "S3Spectre scan: 2 findings (2 high)" {
+		t.Errorf("Text = %q", body.Text)
+	}
+	if len(body.Blocks) != 3 {
+		t.Fatalf("expected headline, findings and footer blocks, got %+v", body.Blocks)
+	}
+	list := body.Blocks[1].Text.Text
+	if !strings.Contains(list, "`MISSING_BUCKET` s3://orders — Bucket &lt;orders&gt; does not exist") {
+		t.Errorf("finding line not rendered or escaped: %q", list)
+	}
+	if footer := body.Blocks[2].Elements[0].Text; footer != "./repo · s3spectre 1.2.3" {
+		t.Errorf("footer = %q", footer)
+	}
+}
+
+func TestSlack_TruncatesLongLists(t *testing.T) {
+	var findings []report.Finding
+	for i := 0; i < maxSlackFindings+3; i++ {
+		findings = append(findings, report.Finding{Status: "MISSING_BUCKET", Severity: "high", Resource: "s3://b"})
+	}
+	msg := slackMessage(Payload{
+		Command:  "discover",
+		Baseline: "base.json",
+		Counts:   map[string]int{"high": len(findings)},
+		Findings: findings,
+	})
+	if msg.Text != "S3Spectre discover: 13 new findings (13 high)" {
+		t.Errorf("Text = %q", msg.Text)
+	}
+	if !strings.HasSuffix(msg.Blocks[1].Text.Text, "…and 3 more") {
+		t.Errorf("expected a count of unlisted findings: %q", msg.Blocks[1].Text.Text)
+	}
+}
+
+func TestSend_ReportsFailuresWithoutURL(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.Copy(io.Discard, r.Body)
+		http.Error(w, "invalid_token", http.StatusForbidden)
+	}))
+	defer srv.Close()
+
+	url := srv.URL + "/services/T000/B000/secret"
+	err := Send(context.Background(), []Notifier{NewSlack(url)}, Payload{})
+	if err == nil {
+		t.Fatal("expected an error for a rejected request")
+	}
+	if !strings.Contains(err.Error(), "slack notification: 403 Forbidden: invalid_token") {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if strings.Contains(err.Error(), "secret") {
+		t.Errorf("error leaks the webhook URL: %v", err)
+	}
+
+	srv.Close()
+	err = Send(context.Background(), []Notifier{NewWebhook(url)}, Payload{})
+	if err == nil || strings.Contains(err.Error(), "secret") {
+		t.Errorf("expected a connection error without the URL, got %v", err)
+	}
+}
+
+func TestValidateURL(t *testing.T) {
+	for _, ok := range []string{"https://hooks.slack.com/services/T/B/x", "http://localhost:8080/hook"} {
+		if err := ValidateURL(ok); err != nil {
+			t.Errorf("ValidateURL(%q): %v", ok, err)
+		}
+	}
+	for _, bad := range []string{"hooks.slack.com/services/x", "ftp://example.com", "https://"} {
+		if err := ValidateURL(bad); err == nil {
+			t.Errorf("ValidateURL(%q) should fail", bad)
+		}
+	}
+}
@@ -0,0 +1,135 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/ppiankov/s3spectre/internal/rules"
+)
+
+// maxSlackFindings is the most findings listed in a Slack message; the
+// rest are counted
+const maxSlackFindings = 10
+
+// Slack posts a summary to a Slack incoming webhook
+type Slack struct {
+	URL    string
+	Client *http.Client
+}
+
+// NewSlack creates a Slack incoming webhook notifier
+func NewSlack(url string) *Slack {
+	return &Slack{URL: url, Client: &http.Client{Timeout: DefaultTimeout}}
+}
+
+// Name identifies the notifier
+func (s *Slack) Name() string { return "slack" }
+
+// Notify posts the summary message
+func (s *Slack) Notify(ctx context.Context, p Payload) error {
+	body, err := json.Marshal(slackMessage(p))
+	if err != nil {
+		return err
+	}
+	return post(ctx, s.Client, s.URL, body)
+}
+
+type slackPayload struct {
+	Text   string       `json:"text"`
+	Blocks []slackBlock `json:"blocks"`
+}
+
+type slackBlock struct {
+	Type string     `json:"type"`
+	Text *slackText `json:"text,omitempty"`
+	// Elements holds a context block's texts
+	Elements []slackText `json:"elements,omitempty"`
+}
+
+type slackText struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+// slackMessage renders the payload as a headline, the highest-severity
+// findings and a footer naming what was scanned
+func slackMessage(p Payload) slackPayload {
+	headline := slackHeadline(p)
+	msg := slackPayload{
+		Text:   headline,
+		Blocks: []slackBlock{markdownBlock("*" + slackEscape(headline) + "*")},
+	}
+
+	findings := p.Findings
+	if len(findings) > maxSlackFindings {
+		findings = findings[:maxSlackFindings]
+	}
+	var lines []string
+	for _, f := range findings {
+		line := fmt.Sprintf("• *%s* `%s` %s", strings.ToUpper(f.Severity), f.Status, slackEscape(f.Resource))
+		if f.Message != "" {
+			line += " — " + slackEscape(f.Message)
+		}
+		lines = append(lines, line)
+	}
+	if more := len(p.Findings) - len(findings); more > 0 {
+		lines = append(lines, fmt.Sprintf("…and %d more", more))
+	}
+	if len(lines) > 0 {
+		msg.Blocks = append(msg.Blocks, markdownBlock(strings.Join(lines, "\n")))
+	}
+
+	var footer []string
+	if p.Target != "" {
+		footer = append(footer, slackEscape(p.Target))
+	}
+	if p.Baseline != "" {
+		footer = append(footer, "compared with "+slackEscape(p.Baseline))
+	}
+	if p.Version != "" {
+		footer = append(footer, p.Tool+" "+p.Version)
+	}
+	if len(footer) > 0 {
+		msg.Blocks = append(msg.Blocks, slackBlock{
+			Type:     "context",
+			Elements: []slackText{{Type: "mrkdwn", Text: strings.Join(footer, " · ")}},
+		})
+	}
+	return msg
+}
+
+// slackHeadline counts the findings by severity, e.g. "S3Spectre scan:
+// 3 new findings (2 high, 1 medium)"
+func slackHeadline(p Payload) string {
+	noun := "finding"
+	if p.Baseline != "" {
+		noun = "new finding"
+	}
+	count := fmt.Sprintf("%d %ss", len(p.Findings), noun)
+	if len(p.Findings) == 1 {
+		count = "1 " + noun
+	}
+	var parts []string
+	for _, severity := range rules.Severities() {
+		if n := p.Counts[severity]; n > 0 {
+			parts = append(parts, fmt.Sprintf("%d %s", n, severity))
+		}
+	}
+	headline := fmt.Sprintf("S3Spectre %s: %s", p.Command, count)
+	if len(parts) > 0 {
+		headline += " (" + strings.Join(parts, ", ") + ")"
+	}
+	return headline
+}
+
+func markdownBlock(text string) slackBlock {
+	return slackBlock{Type: "section", Text: &slackText{Type: "mrkdwn", Text: text}}
+}
+
+// slackEscape escapes the characters Slack treats as control sequences
+func slackEscape(s string) string {
+	return strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;").Replace(s)
+}
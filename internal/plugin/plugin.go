@@ -0,0 +1,159 @@
+package plugin
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ppiankov/s3spectre/internal/analyzer"
+	"github.com/ppiankov/s3spectre/internal/rules"
+	"github.com/ppiankov/s3spectre/internal/s3"
+)
+
+// ProtocolVersion is sent with every request so plugins can reject
+// documents they do not understand
+const ProtocolVersion = 1
+
+// DefaultTimeout bounds a plugin run when none is configured
+const DefaultTimeout = 60 * time.Second
+
+// Plugin is an external check executable. It receives a Request as JSON on
+// stdin and must print a Response as JSON on stdout; a non-zero exit fails
+// the run and its stderr is included in the error.
+type Plugin struct {
+	Name    string
+	Command string
+	Args    []string
+	Timeout time.Duration
+}
+
+// Request is the document written to a plugin's stdin
+type Request struct {
+	Version int              `json:"version"`
+	Buckets []*s3.BucketInfo `json:"buckets"`
+}
+
+// Response is the document a plugin writes to stdout
+type Response struct {
+	Findings []Finding `json:"findings"`
+}
+
+// Finding is one plugin-reported issue. Severity is high, medium, low or
+// info and defaults to medium.
+type Finding struct {
+	Bucket   string `json:"bucket"`
+	Rule     string `json:"rule"`
+	Severity string `json:"severity,omitempty"`
+	Message  string `json:"message,omitempty"`
+}
+
+// Run executes the plugin over the buckets and returns its findings by bucket
+func (p Plugin) Run(ctx context.Context, buckets map[string]*s3.BucketInfo) (map[string][]analyzer.CustomFinding, error) {
+	name := p.DisplayName()
+	timeout := p.Timeout
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	names := make([]string, 0, len(buckets))
+	for bucket := range buckets {
+		names = append(names, bucket)
+	}
+	sort.Strings(names)
+	req := Request{Version: ProtocolVersion, Buckets: make([]*s3.BucketInfo, 0, len(names))}
+	for _, bucket := range names {
+		req.Buckets = append(req.Buckets, buckets[bucket])
+	}
+	input, err := json.Marshal(req)
+	if err != nil {
+		return nil, fmt.Errorf("plugin %s: encode request: %w", name, err)
+	}
+
+	cmd := exec.CommandContext(ctx, p.Command, p.Args...)
+	cmd.Stdin = bytes.NewReader(input)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	// Don't wait on grandchildren still holding the output pipes after a kill
+	cmd.WaitDelay = time.Second
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			return nil, fmt.Errorf("plugin %s: timed out after %s", name, timeout)
+		}
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("plugin %s: %w: %s", name, err, msg)
+		}
+		return nil, fmt.Errorf("plugin %s: %w", name, err)
+	}
+
+	var resp Response
+	if err := json.Unmarshal(stdout.Bytes(), &resp); err != nil {
+		return nil, fmt.Errorf("plugin %s: invalid response: %w", name, err)
+	}
+
+	findings := make(map[string][]analyzer.CustomFinding)
+	for _, f := range resp.Findings {
+		if f.Rule == "" {
+			return nil, fmt.Errorf("plugin %s: finding for bucket %q has no rule", name, f.Bucket)
+		}
+		// A built-in name would make severity overrides and rule lookups
+		// ambiguous
+		if builtin, ok := rules.Lookup(f.Rule); ok {
+			return nil, fmt.Errorf("plugin %s: rule %s collides with built-in rule %s; rename it", name, f.Rule, builtin.ID)
+		}
+		severity := strings.ToLower(f.Severity)
+		if severity == "" {
+			severity = "medium"
+		}
+		if !rules.ValidSeverity(severity) {
+			return nil, fmt.Errorf("plugin %s: rule %s has invalid severity %q (expected high, medium, low or info)", name, f.Rule, f.Severity)
+		}
+		if _, ok := buckets[f.Bucket]; !ok {
+			slog.Warn("Plugin reported unknown bucket", "plugin", name, "bucket", f.Bucket, "rule", f.Rule)
+			continue
+		}
+		findings[f.Bucket] = append(findings[f.Bucket], analyzer.CustomFinding{
+			Plugin:   name,
+			Rule:     f.Rule,
+			Severity: severity,
+			Message:  f.Message,
+		})
+	}
+	return findings, nil
+}
+
+// RunAll runs plugins in order and merges their findings
+func RunAll(ctx context.Context, plugins []Plugin, buckets map[string]*s3.BucketInfo) (map[string][]analyzer.CustomFinding, error) {
+	merged := make(map[string][]analyzer.CustomFinding)
+	for _, p := range plugins {
+		start := time.Now()
+		findings, err := p.Run(ctx, buckets)
+		if err != nil {
+			return nil, err
+		}
+		count := 0
+		for bucket, list := range findings {
+			merged[bucket] = append(merged[bucket], list...)
+			count += len(list)
+		}
+		slog.Debug("Plugin finished", "plugin", p.DisplayName(), "findings", count, "duration", time.Since(start))
+	}
+	return merged, nil
+}
+
+// DisplayName returns the configured name, defaulting to the executable's base name
+func (p Plugin) DisplayName() string {
+	if p.Name != "" {
+		return p.Name
+	}
+	return filepath.Base(p.Command)
+}
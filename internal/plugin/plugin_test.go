@@ -0,0 +1,97 @@
+package plugin
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ppiankov/s3spectre/internal/s3"
+)
+
+func shellPlugin(script string) Plugin {
+	return Plugin{Name: "acme", Command: "sh", Args: []string{"-c", script}}
+}
+
+func testBuckets() map[string]*s3.BucketInfo {
+	return map[string]*s3.BucketInfo{
+		"logs":  {Name: "logs", Region: "us-east-1"},
+		"media": {Name: "media", Region: "eu-west-1"},
+	}
+}
+
+func TestRun_ParsesFindings(t *testing.T) {
+	// The plugin checks it was sent both buckets before answering
+	p := shellPlugin(`input=$(cat)
+case "$input" in *'"version":1'*'"logs"'*'"media"'*) ;; *) echo "bad request: $input" >&2; exit 1;; esac
+echo '{"findings":[
+  {"bucket":"logs","rule":"ACME_NO_OWNER","severity":"HIGH","message":"missing owner tag"},
+  {"bucket":"media","rule":"ACME_NAMING"},
+  {"bucket":"other","rule":"ACME_NAMING"}
+]}'`)
+
+	findings, err := p.Run(context.Background(), testBuckets())
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if len(findings) != 2 {
+		t.Fatalf("expected findings for 2 buckets (unknown bucket dropped), got %v", findings)
+	}
+	logs := findings["logs"][0]
+	if logs.Plugin != "acme" || logs.Rule != "ACME_NO_OWNER" || logs.Severity != "high" || logs.Message != "missing owner tag" {
+		t.Errorf("unexpected finding: %+v", logs)
+	}
+	if findings["media"][0].Severity != "medium" {
+		t.Errorf("expected default severity medium, got %q", findings["media"][0].Severity)
+	}
+}
+
+func TestRun_Errors(t *testing.T) {
+	cases := map[string]string{
+		"exit status":      `echo "license expired" >&2; exit 3`,
+		"invalid response": `echo not-json`,
+		"missing rule":     `echo '{"findings":[{"bucket":"logs"}]}'`,
+		"invalid severity": `echo '{"findings":[{"bucket":"logs","rule":"X","severity":"urgent"}]}'`,
+		"built-in status":  `echo '{"findings":[{"bucket":"logs","rule":"missing_bucket"}]}'`,
+		"built-in rule ID": `echo '{"findings":[{"bucket":"logs","rule":"s3spectre/NO_ENCRYPTION"}]}'`,
+	}
+	for name, script := range cases {
+		if _, err := shellPlugin(script).Run(context.Background(), testBuckets()); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+
+	_, err := shellPlugin(`echo "license expired" >&2; exit 3`).Run(context.Background(), testBuckets())
+	if err == nil || !strings.Contains(err.Error(), "license expired") {
+		t.Errorf("expected stderr in error, got %v", err)
+	}
+}
+
+func TestRun_Timeout(t *testing.T) {
+	p := shellPlugin(`sleep 5`)
+	p.Timeout = 50 * time.Millisecond
+	_, err := p.Run(context.Background(), testBuckets())
+	if err == nil || !strings.Contains(err.Error(), "timed out") {
+		t.Fatalf("expected timeout error, got %v", err)
+	}
+}
+
+func TestRunAll_Merges(t *testing.T) {
+	a := shellPlugin(`cat >/dev/null; echo '{"findings":[{"bucket":"logs","rule":"A"}]}'`)
+	b := shellPlugin(`cat >/dev/null; echo '{"findings":[{"bucket":"logs","rule":"B","severity":"low"}]}'`)
+	b.Name = "other"
+
+	findings, err := RunAll(context.Background(), []Plugin{a, b}, testBuckets())
+	if err != nil {
+		t.Fatalf("RunAll failed: %v", err)
+	}
+	if len(findings["logs"]) != 2 {
+		t.Fatalf("expected 2 merged findings, got %v", findings["logs"])
+	}
+}
+
+func TestDisplayName(t *testing.T) {
+	if got := (Plugin{Command: "/opt/checks/acme-checks"}).DisplayName(); got != "acme-checks" {
+		t.Errorf("DisplayName = %q", got)
+	}
+}
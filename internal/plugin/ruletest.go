@@ -0,0 +1,234 @@
+package plugin
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ppiankov/s3spectre/internal/analyzer"
+	"github.com/ppiankov/s3spectre/internal/s3"
+	"gopkg.in/yaml.v3"
+)
+
+// TestFile is a YAML document of test cases for one check plugin. The
+// plugin is either named from the config file's plugins, or given by its
+// command; a relative command path is resolved from the test file's
+// directory.
+type TestFile struct {
+	Path    string     `yaml:"-"`
+	Plugin  string     `yaml:"plugin"`
+	Command string     `yaml:"command"`
+	Args    []string   `yaml:"args"`
+	Timeout string     `yaml:"timeout"`
+	Cases   []TestCase `yaml:"cases"`
+}
+
+// TestCase runs the plugin over fixture buckets and lists the findings it
+// must report; any other finding fails the case
+type TestCase struct {
+	Name string `yaml:"name"`
+	// Buckets are BucketInfo documents, with the JSON field names plugins
+	// receive
+	Buckets []map[string]any  `yaml:"buckets"`
+	Expect  []ExpectedFinding `yaml:"expect"`
+}
+
+// ExpectedFinding matches a reported finding by bucket and rule, and by
+// severity and message text when they are set
+type ExpectedFinding struct {
+	Bucket          string `yaml:"bucket"`
+	Rule            string `yaml:"rule"`
+	Severity        string `yaml:"severity"`
+	MessageContains string `yaml:"message_contains"`
+}
+
+// CaseResult is the outcome of one test case; Failures is empty when it
+// passed
+type CaseResult struct {
+	Name     string
+	Failures []string
+	Duration time.Duration
+}
+
+// Passed reports whether the case met all its expectations
+func (r CaseResult) Passed() bool {
+	return len(r.Failures) == 0
+}
+
+// LoadTestFile reads and validates a test file
+func LoadTestFile(path string) (*TestFile, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var tf TestFile
+	if err := yaml.Unmarshal(data, &tf); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	tf.Path = path
+	switch {
+	case tf.Plugin == "" && tf.Command == "":
+		return nil, fmt.Errorf("%s: set plugin or command", path)
+	case tf.Plugin != "" && tf.Command != "":
+		return nil, fmt.Errorf("%s: plugin and command are mutually exclusive", path)
+	case len(tf.Cases) == 0:
+		return nil, fmt.Errorf("%s: no cases", path)
+	}
+	for i, c := range tf.Cases {
+		if c.Name == "" {
+			return nil, fmt.Errorf("%s: case %d has no name", path, i+1)
+		}
+		for _, e := range c.Expect {
+			if e.Bucket == "" || e.Rule == "" {
+				return nil, fmt.Errorf("%s: case %q: expected findings need bucket and rule", path, c.Name)
+			}
+		}
+	}
+	return &tf, nil
+}
+
+// ResolvePlugin returns the plugin under test: the named plugin from
+// configured, or the file's command
+func (tf *TestFile) ResolvePlugin(configured []Plugin) (Plugin, error) {
+	if tf.Plugin != "" {
+		for _, p := range configured {
+			if p.DisplayName() == tf.Plugin {
+				return p, nil
+			}
+		}
+		return Plugin{}, fmt.Errorf("%s: plugin %q is not configured", tf.Path, tf.Plugin)
+	}
+	command := tf.Command
+	if strings.ContainsRune(command, filepath.Separator) && !filepath.IsAbs(command) {
+		command = filepath.Join(filepath.Dir(tf.Path), command)
+	}
+	p := Plugin{Command: command, Args: tf.Args}
+	if tf.Timeout != "" {
+		timeout, err := time.ParseDuration(tf.Timeout)
+		if err != nil {
+			return Plugin{}, fmt.Errorf("%s: invalid timeout %q: %w", tf.Path, tf.Timeout, err)
+		}
+		p.Timeout = timeout
+	}
+	return p, nil
+}
+
+// RunTests runs each case of the file against the plugin
+func (tf *TestFile) RunTests(ctx context.Context, p Plugin) []CaseResult {
+	results := make([]CaseResult, 0, len(tf.Cases))
+	for _, c := range tf.Cases {
+		start := time.Now()
+		result := CaseResult{Name: c.Name}
+		buckets, err := fixtureBuckets(c.Buckets)
+		if err == nil {
+			var findings map[string][]analyzer.CustomFinding
+			findings, err = p.Run(ctx, buckets)
+			if err == nil {
+				result.Failures = compareFindings(c.Expect, findings)
+			}
+		}
+		if err != nil {
+			result.Failures = []string{err.Error()}
+		}
+		result.Duration = time.Since(start)
+		results = append(results, result)
+	}
+	return results
+}
+
+// fixtureBuckets decodes fixture documents as BucketInfo. Buckets exist
+// unless the fixture says otherwise.
+func fixtureBuckets(docs []map[string]any) (map[string]*s3.BucketInfo, error) {
+	buckets := make(map[string]*s3.BucketInfo, len(docs))
+	for i, doc := range docs {
+		if _, ok := doc["exists"]; !ok {
+			doc["exists"] = true
+		}
+		data, err := json.Marshal(doc)
+		if err != nil {
+			return nil, fmt.Errorf("bucket %d: %w", i+1, err)
+		}
+		var info s3.BucketInfo
+		if err := json.Unmarshal(data, &info); err != nil {
+			return nil, fmt.Errorf("bucket %d: %w", i+1, err)
+		}
+		if info.Name == "" {
+			return nil, fmt.Errorf("bucket %d has no name", i+1)
+		}
+		buckets[info.Name] = &info
+	}
+	return buckets, nil
+}
+
+// compareFindings lists expected findings that were not reported and
+// reported findings that were not expected
+func compareFindings(expect []ExpectedFinding, findings map[string][]analyzer.CustomFinding) []string {
+	var reported []reportedFinding
+	for bucket, list := range findings {
+		for _, f := range list {
+			reported = append(reported, reportedFinding{bucket: bucket, finding: f})
+		}
+	}
+	sort.Slice(reported, func(i, j int) bool {
+		if reported[i].bucket != reported[j].bucket {
+			return reported[i].bucket < reported[j].bucket
+		}
+		return reported[i].finding.Rule < reported[j].finding.Rule
+	})
+
+	var failures []string
+	for _, e := range expect {
+		i := matchFinding(e, reported)
+		if i < 0 {
+			failures = append(failures, "missing finding: "+e.String())
+			continue
+		}
+		reported[i].matched = true
+	}
+	for _, r := range reported {
+		if !r.matched {
+			failures = append(failures, fmt.Sprintf("unexpected finding: %s %s (%s) %q", r.bucket, r.finding.Rule, r.finding.Severity, r.finding.Message))
+		}
+	}
+	return failures
+}
+
+type reportedFinding struct {
+	bucket  string
+	finding analyzer.CustomFinding
+	matched bool
+}
+
+// matchFinding returns the first unmatched reported finding meeting the
+// expectation, or -1
+func matchFinding(e ExpectedFinding, reported []reportedFinding) int {
+	for i, r := range reported {
+		if r.matched || r.bucket != e.Bucket || r.finding.Rule != e.Rule {
+			continue
+		}
+		if e.Severity != "" && !strings.EqualFold(e.Severity, r.finding.Severity) {
+			continue
+		}
+		if e.MessageContains != "" && !strings.Contains(r.finding.Message, e.MessageContains) {
+			continue
+		}
+		return i
+	}
+	return -1
+}
+
+func (e ExpectedFinding) String() string {
+	s := e.Bucket + " " + e.Rule
+	if e.Severity != "" {
+		s += " (" + strings.ToLower(e.Severity) + ")"
+	}
+	if e.MessageContains != "" {
+		s += fmt.Sprintf(" with message containing %q", e.MessageContains)
+	}
+	return s
+}
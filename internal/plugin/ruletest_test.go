@@ -0,0 +1,110 @@
+package plugin
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// ownerTagCheck flags buckets without an owner tag
+const ownerTagCheck = `#!/bin/sh
+input=$(cat)
+case "$input" in
+  *'"name":"logs"'*'"owner"'*) echo '{"findings":[]}' ;;
+  *) echo '{"findings":[{"bucket":"logs","rule":"ACME_NO_OWNER","severity":"high","message":"logs has no owner tag"}]}' ;;
+esac
+`
+
+func writeTestFile(t *testing.T, dir, content string) string {
+	t.Helper()
+	path := filepath.Join(dir, "owner_test.yaml")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestRunTests(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "owner.sh"), []byte(ownerTagCheck), 0755); err != nil {
+		t.Fatal(err)
+	}
+	path := writeTestFile(t, dir, `
+command: ./owner.sh
+cases:
+  - name: untagged bucket is flagged
+    buckets:
+      - name: logs
+        tags: {team: data}
+    expect:
+      - bucket: logs
+        rule: ACME_NO_OWNER
+        severity: HIGH
+        message_contains: no owner
+  - name: tagged bucket passes
+    buckets:
+      - name: logs
+        tags: {owner: data}
+  - name: wrong expectation
+    buckets:
+      - name: logs
+    expect:
+      - bucket: logs
+        rule: ACME_NO_OWNER
+        severity: low
+`)
+	tf, err := LoadTestFile(path)
+	if err != nil {
+		t.Fatalf("LoadTestFile: %v", err)
+	}
+	p, err := tf.ResolvePlugin(nil)
+	if err != nil {
+		t.Fatalf("ResolvePlugin: %v", err)
+	}
+	if p.Command != filepath.Join(dir, "owner.sh") {
+		t.Errorf("command should resolve from the test file's directory, got %q", p.Command)
+	}
+
+	results := tf.RunTests(context.Background(), p)
+	if len(results) != 3 {
+		t.Fatalf("expected 3 results, got %d", len(results))
+	}
+	for _, r := range results[:2] {
+		if !r.Passed() {
+			t.Errorf("%s: unexpected failures %v", r.Name, r.Failures)
+		}
+	}
+	failures := strings.Join(results[2].Failures, "\n")
+	if !strings.Contains(failures, "missing finding: logs ACME_NO_OWNER (low)") || !strings.Contains(failures, "unexpected finding: logs ACME_NO_OWNER (high)") {
+		t.Errorf("expected a missing and an unexpected finding, got:\n%s", failures)
+	}
+}
+
+func TestResolvePlugin_Configured(t *testing.T) {
+	tf := &TestFile{Path: "t.yaml", Plugin: "acme"}
+	p, err := tf.ResolvePlugin([]Plugin{{Name: "other", Command: "x"}, {Name: "acme", Command: "acme-check"}})
+	if err != nil || p.Command != "acme-check" {
+		t.Errorf("got %+v, %v", p, err)
+	}
+	tf.Plugin = "missing"
+	if _, err := tf.ResolvePlugin(nil); err == nil {
+		t.Error("expected error for an unconfigured plugin")
+	}
+}
+
+func TestLoadTestFile_Invalid(t *testing.T) {
+	cases := map[string]string{
+		"no plugin":       "cases: [{name: a}]",
+		"both":            "plugin: a\ncommand: b\ncases: [{name: a}]",
+		"no cases":        "command: b",
+		"unnamed case":    "command: b\ncases: [{buckets: []}]",
+		"incomplete want": "command: b\ncases: [{name: a, expect: [{bucket: logs}]}]",
+	}
+	for name, content := range cases {
+		if _, err := LoadTestFile(writeTestFile(t, t.TempDir(), content)); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+}
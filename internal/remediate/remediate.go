@@ -0,0 +1,385 @@
+// Package remediate plans and applies fixes for findings: a lifecycle rule
+// for version sprawl, a tag on unused buckets and default encryption. It
+// also suggests age-tuned lifecycle configurations to apply by hand.
+package remediate
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/aws/smithy-go"
+	"github.com/ppiankov/s3spectre/internal/report"
+	"github.com/ppiankov/s3spectre/internal/rules"
+)
+
+// Action kinds
+const (
+	KindLifecycle  = "lifecycle"
+	KindTag        = "tag"
+	KindEncryption = "encryption"
+)
+
+// Kinds lists the action kinds in the order they are planned
+var Kinds = []string{KindLifecycle, KindTag, KindEncryption}
+
+const (
+	// LifecycleRuleID names the lifecycle rule remediation adds
+	LifecycleRuleID = "s3spectre-expire-noncurrent-versions"
+	// DefaultNoncurrentDays is how long noncurrent versions are kept
+	DefaultNoncurrentDays = 30
+	// DefaultUnusedTag marks unused buckets, as key=value
+	DefaultUnusedTag = "s3spectre:status=unused"
+	// abortMultipartDays cleans up incomplete uploads, which versioned
+	// buckets also accumulate
+	abortMultipartDays = 7
+)
+
+// Options configures the fixes
+type Options struct {
+	// Kinds limits the plan to these action kinds; empty means all
+	Kinds          []string
+	NoncurrentDays int
+	UnusedTagKey   string
+	UnusedTagValue string
+	// KMSKeyID selects SSE-KMS with this key instead of SSE-S3
+	KMSKeyID string
+}
+
+// DefaultOptions returns the options used without flags
+func DefaultOptions() Options {
+	key, value, _ := strings.Cut(DefaultUnusedTag, "=")
+	return Options{NoncurrentDays: DefaultNoncurrentDays, UnusedTagKey: key, UnusedTagValue: value}
+}
+
+// Action is one change to a bucket that fixes a finding
+type Action struct {
+	Kind    string
+	Finding string
+	Bucket  string
+	// Region is empty when the report did not record it
+	Region  string
+	Account string
+	opts    Options
+}
+
+// Call is an AWS API call an action makes
+type Call struct {
+	API    string
+	Params map[string]any
+	// Note explains what the printed parameters leave out
+	Note string
+}
+
+func (c Call) String() string {
+	params, _ := json.Marshal(c.Params)
+	s := c.API + " " + string(params)
+	if c.Note != "" {
+		s += "  # " + c.Note
+	}
+	return s
+}
+
+// Plan lists the actions that fix findings, one per bucket and kind, in
+// bucket order. Findings without a fix are left out.
+func Plan(findings []report.Finding, opts Options) []Action {
+	enabled := func(kind string) bool {
+		if len(opts.Kinds) == 0 {
+			return true
+		}
+		for _, k := range opts.Kinds {
+			if k == kind {
+				return true
+			}
+		}
+		return false
+	}
+	seen := make(map[string]bool)
+	var actions []Action
+	for _, f := range findings {
+		kind := kindFor(f.Status)
+		if kind == "" || f.Bucket == "" || f.Prefix != "" || !enabled(kind) {
+			continue
+		}
+		key := f.Account + "/" + f.Bucket + "/" + kind
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		actions = append(actions, Action{
+			Kind:    kind,
+			Finding: f.Status,
+			Bucket:  f.Bucket,
+			Region:  f.Region,
+			Account: f.Account,
+			opts:    opts,
+		})
+	}
+	order := make(map[string]int, len(Kinds))
+	for i, kind := range Kinds {
+		order[kind] = i
+	}
+	sort.SliceStable(actions, func(i, j int) bool {
+		if actions[i].Bucket != actions[j].Bucket {
+			return actions[i].Bucket < actions[j].Bucket
+		}
+		return order[actions[i].Kind] < order[actions[j].Kind]
+	})
+	return actions
+}
+
+// kindFor returns the action that fixes a finding status, if any
+func kindFor(status string) string {
+	switch status {
+	case rules.StatusVersionSprawl:
+		return KindLifecycle
+	case rules.StatusUnusedBucket:
+		return KindTag
+	case rules.NoEncryption:
+		return KindEncryption
+	}
+	return ""
+}
+
+// Description summarizes the change, e.g. "expire noncurrent versions
+// after 30 days"
+func (a Action) Description() string {
+	switch a.Kind {
+	case KindLifecycle:
+		return fmt.Sprintf("expire noncurrent versions after %d days", a.opts.NoncurrentDays)
+	case KindTag:
+		return fmt.Sprintf("tag %s=%s", a.opts.UnusedTagKey, a.opts.UnusedTagValue)
+	case KindEncryption:
+		if a.opts.KMSKeyID != "" {
+			return "enable default encryption with SSE-KMS"
+		}
+		return "enable default encryption with SSE-S3"
+	}
+	return a.Kind
+}
+
+// Calls lists the AWS API calls the action makes, in order
+func (a Action) Calls() []Call {
+	bucket := map[string]any{"Bucket": a.Bucket}
+	switch a.Kind {
+	case KindLifecycle:
+		return []Call{
+			{API: "GetBucketLifecycleConfiguration", Params: bucket},
+			{API: "PutBucketLifecycleConfiguration", Params: map[string]any{
+				"Bucket": a.Bucket,
+				"LifecycleConfiguration": map[string]any{"Rules": []any{map[string]any{
+					"ID":                             LifecycleRuleID,
+					"Status":                         "Enabled",
+					"Filter":                         map[string]any{"Prefix": ""},
+					"NoncurrentVersionExpiration":    map[string]any{"NoncurrentDays": a.opts.NoncurrentDays},
+					"AbortIncompleteMultipartUpload": map[string]any{"DaysAfterInitiation": abortMultipartDays},
+				}}},
+			}, Note: "existing rules are kept"},
+		}
+	case KindTag:
+		return []Call{
+			{API: "GetBucketTagging", Params: bucket},
+			{API: "PutBucketTagging", Params: map[string]any{
+				"Bucket":  a.Bucket,
+				"Tagging": map[string]any{"TagSet": []any{map[string]string{"Key": a.opts.UnusedTagKey, "Value": a.opts.UnusedTagValue}}},
+			}, Note: "existing tags are kept"},
+		}
+	case KindEncryption:
+		return []Call{
+			{API: "GetBucketEncryption", Params: bucket},
+			{API: "PutBucketEncryption", Params: map[string]any{
+				"Bucket":                            a.Bucket,
+				"ServerSideEncryptionConfiguration": map[string]any{"Rules": []any{a.encryptionParams()}},
+			}, Note: "skipped when default encryption is already set"},
+		}
+	}
+	return nil
+}
+
+func (a Action) encryptionParams() map[string]any {
+	if a.opts.KMSKeyID == "" {
+		return map[string]any{"ApplyServerSideEncryptionByDefault": map[string]string{"SSEAlgorithm": "AES256"}}
+	}
+	return map[string]any{
+		"ApplyServerSideEncryptionByDefault": map[string]string{"SSEAlgorithm": "aws:kms", "KMSMasterKeyID": a.opts.KMSKeyID},
+		"BucketKeyEnabled":                   true,
+	}
+}
+
+// API is the subset of the S3 client that remediation uses
+type API interface {
+	GetBucketLifecycleConfiguration(ctx context.Context, params *s3.GetBucketLifecycleConfigurationInput, optFns ...func(*s3.Options)) (*s3.GetBucketLifecycleConfigurationOutput, error)
+	PutBucketLifecycleConfiguration(ctx context.Context, params *s3.PutBucketLifecycleConfigurationInput, optFns ...func(*s3.Options)) (*s3.PutBucketLifecycleConfigurationOutput, error)
+	GetBucketTagging(ctx context.Context, params *s3.GetBucketTaggingInput, optFns ...func(*s3.Options)) (*s3.GetBucketTaggingOutput, error)
+	PutBucketTagging(ctx context.Context, params *s3.PutBucketTaggingInput, optFns ...func(*s3.Options)) (*s3.PutBucketTaggingOutput, error)
+	GetBucketEncryption(ctx context.Context, params *s3.GetBucketEncryptionInput, optFns ...func(*s3.Options)) (*s3.GetBucketEncryptionOutput, error)
+	PutBucketEncryption(ctx context.Context, params *s3.PutBucketEncryptionInput, optFns ...func(*s3.Options)) (*s3.PutBucketEncryptionOutput, error)
+}
+
+// SkippedError reports an action that was not applied because the bucket
+// no longer needs it
+type SkippedError struct {
+	Reason string
+}
+
+func (e *SkippedError) Error() string {
+	return "skipped: " + e.Reason
+}
+
+// Apply makes the action's calls against a client in the bucket's region
+func Apply(ctx context.Context, api API, a Action) error {
+	switch a.Kind {
+	case KindLifecycle:
+		return applyLifecycle(ctx, api, a)
+	case KindTag:
+		return applyTag(ctx, api, a)
+	case KindEncryption:
+		return applyEncryption(ctx, api, a)
+	}
+	return fmt.Errorf("unknown action %q", a.Kind)
+}
+
+// applyLifecycle adds the noncurrent version rule, replacing an earlier
+// copy of it, since a put replaces the whole configuration
+func applyLifecycle(ctx context.Context, api API, a Action) error {
+	current, err := api.GetBucketLifecycleConfiguration(ctx, &s3.GetBucketLifecycleConfigurationInput{Bucket: aws.String(a.Bucket)})
+	if err != nil && !isErrorCode(err, "NoSuchLifecycleConfiguration") {
+		return fmt.Errorf("read lifecycle configuration: %w", err)
+	}
+	var kept []types.LifecycleRule
+	if current != nil {
+		for _, rule := range current.Rules {
+			if aws.ToString(rule.ID) != LifecycleRuleID {
+				kept = append(kept, rule)
+			}
+		}
+	}
+	kept = append(kept, types.LifecycleRule{
+		ID:     aws.String(LifecycleRuleID),
+		Status: types.ExpirationStatusEnabled,
+		Filter: &types.LifecycleRuleFilterMemberPrefix{Value: ""},
+		NoncurrentVersionExpiration: &types.NoncurrentVersionExpiration{
+			NoncurrentDays: aws.Int32(int32(a.opts.NoncurrentDays)),
+		},
+		AbortIncompleteMultipartUpload: &types.AbortIncompleteMultipartUpload{
+			DaysAfterInitiation: aws.Int32(abortMultipartDays),
+		},
+	})
+	_, err = api.PutBucketLifecycleConfiguration(ctx, &s3.PutBucketLifecycleConfigurationInput{
+		Bucket:                 aws.String(a.Bucket),
+		LifecycleConfiguration: &types.BucketLifecycleConfiguration{Rules: kept},
+	})
+	if err != nil {
+		return fmt.Errorf("write lifecycle configuration: %w", err)
+	}
+	return nil
+}
+
+// applyTag sets the unused tag, keeping the bucket's other tags, since a put
+// replaces the whole tag set
+func applyTag(ctx context.Context, api API, a Action) error {
+	current, err := api.GetBucketTagging(ctx, &s3.GetBucketTaggingInput{Bucket: aws.String(a.Bucket)})
+	if err != nil && !isErrorCode(err, "NoSuchTagSet") {
+		return fmt.Errorf("read tags: %w", err)
+	}
+	var tags []types.Tag
+	if current != nil {
+		for _, tag := range current.TagSet {
+			if aws.ToString(tag.Key) != a.opts.UnusedTagKey {
+				tags = append(tags, tag)
+			}
+		}
+	}
+	tags = append(tags, types.Tag{Key: aws.String(a.opts.UnusedTagKey), Value: aws.String(a.opts.UnusedTagValue)})
+	_, err = api.PutBucketTagging(ctx, &s3.PutBucketTaggingInput{
+		Bucket:  aws.String(a.Bucket),
+		Tagging: &types.Tagging{TagSet: tags},
+	})
+	if err != nil {
+		return fmt.Errorf("write tags: %w", err)
+	}
+	return nil
+}
+
+// applyEncryption enables default encryption on a bucket without it. A
+// bucket that already has default encryption is skipped, so a stale or
+// wrong finding never replaces SSE-KMS with SSE-S3.
+func applyEncryption(ctx context.Context, api API, a Action) error {
+	current, err := api.GetBucketEncryption(ctx, &s3.GetBucketEncryptionInput{Bucket: aws.String(a.Bucket)})
+	if err != nil && !isErrorCode(err, "ServerSideEncryptionConfigurationNotFoundError") {
+		return fmt.Errorf("read default encryption: %w", err)
+	}
+	if current != nil && current.ServerSideEncryptionConfiguration != nil {
+		for _, rule := range current.ServerSideEncryptionConfiguration.Rules {
+			if def := rule.ApplyServerSideEncryptionByDefault; def != nil && def.SSEAlgorithm != "" {
+				return &SkippedError{Reason: fmt.Sprintf("default encryption is already %s", def.SSEAlgorithm)}
+			}
+		}
+	}
+
+	rule := types.ServerSideEncryptionRule{
+		ApplyServerSideEncryptionByDefault: &types.ServerSideEncryptionByDefault{SSEAlgorithm: types.ServerSideEncryptionAes256},
+	}
+	if a.opts.KMSKeyID != "" {
+		rule.ApplyServerSideEncryptionByDefault = &types.ServerSideEncryptionByDefault{
+			SSEAlgorithm:   types.ServerSideEncryptionAwsKms,
+			KMSMasterKeyID: aws.String(a.opts.KMSKeyID),
+		}
+		rule.BucketKeyEnabled = aws.Bool(true)
+	}
+	_, err = api.PutBucketEncryption(ctx, &s3.PutBucketEncryptionInput{
+		Bucket: aws.String(a.Bucket),
+		ServerSideEncryptionConfiguration: &types.ServerSideEncryptionConfiguration{
+			Rules: []types.ServerSideEncryptionRule{rule},
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("write default encryption: %w", err)
+	}
+	return nil
+}
+
+func isErrorCode(err error, code string) bool {
+	var apiErr smithy.APIError
+	return errors.As(err, &apiErr) && apiErr.ErrorCode() == code
+}
+
+// ReadFindings reads the findings of a scan or discovery JSON report
+func ReadFindings(path string) ([]report.Finding, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read report: %w", err)
+	}
+	var probe struct {
+		Kind   string `json:"kind"`
+		Config struct {
+			RepoPath *string `json:"repo_path"`
+		} `json:"config"`
+	}
+	if err := json.Unmarshal(raw, &probe); err != nil {
+		return nil, fmt.Errorf("parse report: %w", err)
+	}
+	if probe.Kind != "" {
+		return nil, fmt.Errorf("%s reports are not supported; use a scan or discover JSON report", probe.Kind)
+	}
+	// Only scan reports record the repository path
+	if probe.Config.RepoPath != nil {
+		var data report.Data
+		if err := json.Unmarshal(raw, &data); err != nil {
+			return nil, fmt.Errorf("parse report: %w", err)
+		}
+		return report.ScanFindings(data), nil
+	}
+	var data report.DiscoveryData
+	if err := json.Unmarshal(raw, &data); err != nil {
+		return nil, fmt.Errorf("parse report: %w", err)
+	}
+	return report.DiscoveryFindings(data), nil
+}
@@ -0,0 +1,249 @@
+package remediate
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/aws/smithy-go"
+	"github.com/ppiankov/s3spectre/internal/analyzer"
+	"github.com/ppiankov/s3spectre/internal/report"
+	"github.com/ppiankov/s3spectre/internal/rules"
+)
+
+func TestPlan(t *testing.T) {
+	findings := []report.Finding{
+		{Status: rules.StatusUnusedBucket, Bucket: "old", Account: "111111111111"},
+		{Status: rules.NoEncryption, Bucket: "logs", Region: "eu-west-1"},
+		{Status: rules.StatusVersionSprawl, Bucket: "logs", Region: "eu-west-1"},
+		{Status: rules.StatusVersionSprawl, Bucket: "logs", Region: "eu-west-1"},
+		{Status: rules.PublicBucket, Bucket: "media"},
+		{Status: rules.StatusStalePrefix, Bucket: "logs", Prefix: "tmp/"},
+		{Status: rules.StatusNoGuardDutyS3, Region: "eu-west-1"},
+	}
+	actions := Plan(findings, DefaultOptions())
+	var got []string
+	for _, a := range actions {
+		got = append(got, a.Bucket+":"+a.Kind)
+	}
+	want := "logs:lifecycle logs:encryption old:tag"
+	if strings.Join(got, " ") != want {
+		t.Errorf("Plan = %v, want %s", got, want)
+	}
+	if actions[2].Account != "111111111111" || actions[0].Region != "eu-west-1" {
+		t.Errorf("actions lost finding context: %+v", actions)
+	}
+
+	opts := DefaultOptions()
+	opts.Kinds = []string{KindTag}
+	if actions := Plan(findings, opts); len(actions) != 1 || actions[0].Kind != KindTag {
+		t.Errorf("Plan with kinds = %+v, want the tag action only", actions)
+	}
+}
+
+func TestActionCalls(t *testing.T) {
+	opts := DefaultOptions()
+	opts.NoncurrentDays = 14
+	actions := Plan([]report.Finding{
+		{Status: rules.StatusVersionSprawl, Bucket: "logs"},
+		{Status: rules.NoEncryption, Bucket: "logs"},
+	}, opts)
+
+	lifecycle := actions[0].Calls()
+	if len(lifecycle) != 2 || lifecycle[0].API != "GetBucketLifecycleConfiguration" {
+		t.Fatalf("lifecycle calls = %v", lifecycle)
+	}
+	put := lifecycle[1].String()
+	for _, want := range []string{"PutBucketLifecycleConfiguration", `"NoncurrentDays":14`, LifecycleRuleID, "# existing rules are kept"} {
+		if !strings.Contains(put, want) {
+			t.Errorf("lifecycle call %q missing %q", put, want)
+		}
+	}
+	if got := actions[0].Description(); got != "expire noncurrent versions after 14 days" {
+		t.Errorf("Description = %q", got)
+	}
+
+	encryption := actions[1].Calls()
+	if len(encryption) != 2 || encryption[0].API != "GetBucketEncryption" || !strings.Contains(encryption[1].String(), `"SSEAlgorithm":"AES256"`) {
+		t.Errorf("encryption calls = %v", encryption)
+	}
+	opts.KMSKeyID = "alias/s3"
+	kms := Plan([]report.Finding{{Status: rules.NoEncryption, Bucket: "logs"}}, opts)[0].Calls()[1].String()
+	for _, want := range []string{`"SSEAlgorithm":"aws:kms"`, `"KMSMasterKeyID":"alias/s3"`, `"BucketKeyEnabled":true`} {
+		if !strings.Contains(kms, want) {
+			t.Errorf("KMS call %q missing %q", kms, want)
+		}
+	}
+}
+
+// fakeAPI records the configuration written to one bucket
+type fakeAPI struct {
+	rules      []types.LifecycleRule
+	tags       []types.Tag
+	encryption *types.ServerSideEncryptionConfiguration
+	putErr     error
+}
+
+func (f *fakeAPI) GetBucketLifecycleConfiguration(ctx context.Context, params *s3.GetBucketLifecycleConfigurationInput, optFns ...func(*s3.Options)) (*s3.GetBucketLifecycleConfigurationOutput, error) {
+	if f.rules == nil {
+		return nil, &smithy.GenericAPIError{Code: "NoSuchLifecycleConfiguration"}
+	}
+	return &s3.GetBucketLifecycleConfigurationOutput{Rules: f.rules}, nil
+}
+
+func (f *fakeAPI) PutBucketLifecycleConfiguration(ctx context.Context, params *s3.PutBucketLifecycleConfigurationInput, optFns ...func(*s3.Options)) (*s3.PutBucketLifecycleConfigurationOutput, error) {
+	if f.putErr != nil {
+		return nil, f.putErr
+	}
+	f.rules = params.LifecycleConfiguration.Rules
+	return &s3.PutBucketLifecycleConfigurationOutput{}, nil
+}
+
+func (f *fakeAPI) GetBucketTagging(ctx context.Context, params *s3.GetBucketTaggingInput, optFns ...func(*s3.Options)) (*s3.GetBucketTaggingOutput, error) {
+	if f.tags == nil {
+		return nil, &smithy.GenericAPIError{Code: "NoSuchTagSet"}
+	}
+	return &s3.GetBucketTaggingOutput{TagSet: f.tags}, nil
+}
+
+func (f *fakeAPI) PutBucketTagging(ctx context.Context, params *s3.PutBucketTaggingInput, optFns ...func(*s3.Options)) (*s3.PutBucketTaggingOutput, error) {
+	f.tags = params.Tagging.TagSet
+	return &s3.PutBucketTaggingOutput{}, nil
+}
+
+func (f *fakeAPI) GetBucketEncryption(ctx context.Context, params *s3.GetBucketEncryptionInput, optFns ...func(*s3.Options)) (*s3.GetBucketEncryptionOutput, error) {
+	if f.encryption == nil {
+		return nil, &smithy.GenericAPIError{Code: "ServerSideEncryptionConfigurationNotFoundError"}
+	}
+	return &s3.GetBucketEncryptionOutput{ServerSideEncryptionConfiguration: f.encryption}, nil
+}
+
+func (f *fakeAPI) PutBucketEncryption(ctx context.Context, params *s3.PutBucketEncryptionInput, optFns ...func(*s3.Options)) (*s3.PutBucketEncryptionOutput, error) {
+	f.encryption = params.ServerSideEncryptionConfiguration
+	return &s3.PutBucketEncryptionOutput{}, nil
+}
+
+func TestApplyLifecycle(t *testing.T) {
+	ctx := context.Background()
+	action := Plan([]report.Finding{{Status: rules.StatusVersionSprawl, Bucket: "logs"}}, DefaultOptions())[0]
+
+	api := &fakeAPI{}
+	if err := Apply(ctx, api, action); err != nil {
+		t.Fatalf("Apply without a configuration: %v", err)
+	}
+	if len(api.rules) != 1 || aws.ToInt32(api.rules[0].NoncurrentVersionExpiration.NoncurrentDays) != DefaultNoncurrentDays {
+		t.Fatalf("rules = %+v", api.rules)
+	}
+
+	// Existing rules are kept, and applying again replaces the added rule
+	api.rules = append([]types.LifecycleRule{{ID: aws.String("archive"), Status: types.ExpirationStatusEnabled}}, api.rules...)
+	if err := Apply(ctx, api, action); err != nil {
+		t.Fatalf("Apply again: %v", err)
+	}
+	if len(api.rules) != 2 || aws.ToString(api.rules[0].ID) != "archive" || aws.ToString(api.rules[1].ID) != LifecycleRuleID {
+		t.Errorf("rules after reapply = %+v", api.rules)
+	}
+
+	api.putErr = &smithy.GenericAPIError{Code: "AccessDenied"}
+	if err := Apply(ctx, api, action); err == nil || !strings.Contains(err.Error(), "write lifecycle configuration") {
+		t.Errorf("Apply with a denied put = %v", err)
+	}
+}
+
+func TestApplyTag(t *testing.T) {
+	action := Plan([]report.Finding{{Status: rules.StatusUnusedBucket, Bucket: "old"}}, DefaultOptions())[0]
+	api := &fakeAPI{tags: []types.Tag{
+		{Key: aws.String("team"), Value: aws.String("data")},
+		{Key: aws.String("s3spectre:status"), Value: aws.String("review")},
+	}}
+	if err := Apply(context.Background(), api, action); err != nil {
+		t.Fatalf("Apply: %v", err)
+	}
+	got := make(map[string]string)
+	for _, tag := range api.tags {
+		got[aws.ToString(tag.Key)] = aws.ToString(tag.Value)
+	}
+	if len(got) != 2 || got["team"] != "data" || got["s3spectre:status"] != "unused" {
+		t.Errorf("tags = %v", got)
+	}
+}
+
+func TestApplyEncryption(t *testing.T) {
+	opts := DefaultOptions()
+	opts.KMSKeyID = "alias/s3"
+	action := Plan([]report.Finding{{Status: rules.NoEncryption, Bucket: "logs"}}, opts)[0]
+	api := &fakeAPI{}
+	if err := Apply(context.Background(), api, action); err != nil {
+		t.Fatalf("Apply: %v", err)
+	}
+	rule := api.encryption.Rules[0]
+	if rule.ApplyServerSideEncryptionByDefault.SSEAlgorithm != types.ServerSideEncryptionAwsKms ||
+		aws.ToString(rule.ApplyServerSideEncryptionByDefault.KMSMasterKeyID) != "alias/s3" || !aws.ToBool(rule.BucketKeyEnabled) {
+		t.Errorf("encryption rule = %+v", rule)
+	}
+
+	// Existing default encryption is kept, not replaced with SSE-S3
+	encrypted := &fakeAPI{encryption: &types.ServerSideEncryptionConfiguration{Rules: []types.ServerSideEncryptionRule{{
+		ApplyServerSideEncryptionByDefault: &types.ServerSideEncryptionByDefault{SSEAlgorithm: types.ServerSideEncryptionAwsKms, KMSMasterKeyID: aws.String("key-1")},
+	}}}}
+	err := Apply(context.Background(), encrypted, Plan([]report.Finding{{Status: rules.NoEncryption, Bucket: "logs"}}, DefaultOptions())[0])
+	var skipped *SkippedError
+	if !errors.As(err, &skipped) || err.Error() != "skipped: default encryption is already aws:kms" {
+		t.Fatalf("Apply on an encrypted bucket = %v, want skipped", err)
+	}
+	if def := encrypted.encryption.Rules[0].ApplyServerSideEncryptionByDefault; aws.ToString(def.KMSMasterKeyID) != "key-1" {
+		t.Errorf("existing encryption was replaced: %+v", def)
+	}
+}
+
+func writeReport(t *testing.T, v any) string {
+	t.Helper()
+	data, err := json.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "report.json")
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestReadFindings(t *testing.T) {
+	discovery := writeReport(t, report.DiscoveryData{
+		Buckets: map[string]*analyzer.BucketDiscovery{
+			"logs": {Name: "logs", Region: "eu-west-1", Status: analyzer.StatusVersionSprawl},
+			"app":  {Name: "app", Region: "us-east-1", Status: analyzer.StatusOK},
+		},
+	})
+	findings, err := ReadFindings(discovery)
+	if err != nil {
+		t.Fatalf("ReadFindings(discover): %v", err)
+	}
+	if len(findings) != 1 || findings[0].Bucket != "logs" || findings[0].Region != "eu-west-1" {
+		t.Errorf("discover findings = %+v", findings)
+	}
+
+	scan := writeReport(t, report.Data{
+		Config:  report.Config{RepoPath: "."},
+		Buckets: map[string]*analyzer.BucketAnalysis{"old": {Name: "old", Status: analyzer.StatusUnusedBucket}},
+	})
+	findings, err = ReadFindings(scan)
+	if err != nil {
+		t.Fatalf("ReadFindings(scan): %v", err)
+	}
+	if len(findings) != 1 || findings[0].Status != rules.StatusUnusedBucket {
+		t.Errorf("scan findings = %+v", findings)
+	}
+
+	if _, err := ReadFindings(writeReport(t, map[string]string{"kind": "merged"})); err == nil {
+		t.Error("expected error for a merged report")
+	}
+}
@@ -0,0 +1,277 @@
+package remediate
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/ppiankov/s3spectre/internal/report"
+	"github.com/ppiankov/s3spectre/internal/rules"
+)
+
+const (
+	// DefaultSamplePages is how many object listing pages of 1000 are read
+	// per bucket to measure object ages
+	DefaultSamplePages = 5
+	// coldShare is the share of sampled bytes that must be older than a
+	// transition's age for the transition to be suggested
+	coldShare = 0.5
+	// Rule IDs of suggested lifecycle rules
+	transitionRuleID = "s3spectre-transition-cold-objects"
+	abortRuleID      = "s3spectre-abort-incomplete-uploads"
+)
+
+// AgeSample is the age distribution of a bucket's sampled objects
+type AgeSample struct {
+	Objects int
+	Bytes   int64
+	// ages holds each sampled object's age in days and size
+	ages []objectAge
+}
+
+type objectAge struct {
+	days int
+	size int64
+}
+
+// ColdShare returns the share of sampled bytes in objects at least days old
+func (s AgeSample) ColdShare(days int) float64 {
+	if s.Bytes == 0 {
+		return 0
+	}
+	var cold int64
+	for _, a := range s.ages {
+		if a.days >= days {
+			cold += a.size
+		}
+	}
+	return float64(cold) / float64(s.Bytes)
+}
+
+// ObjectsAPI is the subset of the S3 client age sampling uses
+type ObjectsAPI interface {
+	ListObjectsV2(ctx context.Context, params *s3.ListObjectsV2Input, optFns ...func(*s3.Options)) (*s3.ListObjectsV2Output, error)
+}
+
+// SampleAges reads up to pages listing pages of the bucket's objects and
+// records their ages as of now. Pages follow key order, so a bucket larger
+// than the sample is measured by its first keys.
+func SampleAges(ctx context.Context, api ObjectsAPI, bucket string, pages int, now time.Time) (AgeSample, error) {
+	var sample AgeSample
+	var token *string
+	for page := 0; page < pages; page++ {
+		out, err := api.ListObjectsV2(ctx, &s3.ListObjectsV2Input{
+			Bucket:            aws.String(bucket),
+			ContinuationToken: token,
+		})
+		if err != nil {
+			return sample, fmt.Errorf("list objects: %w", err)
+		}
+		for _, obj := range out.Contents {
+			size := aws.ToInt64(obj.Size)
+			days := 0
+			if obj.LastModified != nil {
+				days = int(now.Sub(*obj.LastModified).Hours() / 24)
+			}
+			sample.Objects++
+			sample.Bytes += size
+			sample.ages = append(sample.ages, objectAge{days: days, size: size})
+		}
+		if !aws.ToBool(out.IsTruncated) {
+			break
+		}
+		token = out.NextContinuationToken
+	}
+	return sample, nil
+}
+
+// LifecyclePolicy is a suggested lifecycle configuration for one bucket
+type LifecyclePolicy struct {
+	Bucket string
+	// Account is the bucket's account, when the report names it
+	Account string
+	Rules   []LifecycleRule
+	// Notes explain how the rules were chosen
+	Notes []string
+	// resourceName is the Terraform resource name NameResources chose
+	resourceName string
+}
+
+// LifecycleRule is a lifecycle rule in the S3 API's JSON shape
+type LifecycleRule struct {
+	ID                             string                `json:"ID"`
+	Status                         string                `json:"Status"`
+	Filter                         RuleFilter            `json:"Filter"`
+	Transitions                    []Transition          `json:"Transitions,omitempty"`
+	NoncurrentVersionExpiration    *NoncurrentExpiration `json:"NoncurrentVersionExpiration,omitempty"`
+	AbortIncompleteMultipartUpload *AbortMultipartUpload `json:"AbortIncompleteMultipartUpload,omitempty"`
+}
+
+// RuleFilter selects the objects a rule applies to; an empty prefix
+// selects all
+type RuleFilter struct {
+	Prefix string `json:"Prefix"`
+}
+
+// Transition moves current objects to a storage class at an age
+type Transition struct {
+	Days         int    `json:"Days"`
+	StorageClass string `json:"StorageClass"`
+}
+
+// NoncurrentExpiration deletes versions some days after they are replaced
+type NoncurrentExpiration struct {
+	NoncurrentDays int `json:"NoncurrentDays"`
+}
+
+// AbortMultipartUpload cleans up uploads that were never completed
+type AbortMultipartUpload struct {
+	DaysAfterInitiation int `json:"DaysAfterInitiation"`
+}
+
+// SuggestTargets lists the buckets whose findings a lifecycle configuration
+// fixes, with the finding, in bucket order
+func SuggestTargets(findings []report.Finding) []report.Finding {
+	seen := make(map[string]bool)
+	var targets []report.Finding
+	for _, f := range findings {
+		if f.Status != rules.StatusVersionSprawl && f.Status != rules.StatusLifecycleMisconfig {
+			continue
+		}
+		if f.Bucket == "" || seen[f.Account+"/"+f.Bucket] {
+			continue
+		}
+		seen[f.Account+"/"+f.Bucket] = true
+		targets = append(targets, f)
+	}
+	sort.SliceStable(targets, func(i, j int) bool { return targets[i].Bucket < targets[j].Bucket })
+	return targets
+}
+
+// SuggestLifecycle builds a lifecycle configuration for a bucket with the
+// finding. Transitions follow the sampled object ages; without a sample,
+// objects move to Intelligent-Tiering. Version sprawl also gets a rule
+// expiring noncurrent versions. Current objects are never expired.
+func SuggestLifecycle(bucket, finding string, ages *AgeSample, noncurrentDays int) LifecyclePolicy {
+	policy := LifecyclePolicy{Bucket: bucket}
+	transitions, note := suggestTransitions(ages)
+	policy.Notes = append(policy.Notes, note)
+	if len(transitions) > 0 {
+		policy.Rules = append(policy.Rules, LifecycleRule{
+			ID:          transitionRuleID,
+			Status:      "Enabled",
+			Transitions: transitions,
+		})
+	}
+	if finding == rules.StatusVersionSprawl {
+		policy.Rules = append(policy.Rules, LifecycleRule{
+			ID:                          LifecycleRuleID,
+			Status:                      "Enabled",
+			NoncurrentVersionExpiration: &NoncurrentExpiration{NoncurrentDays: noncurrentDays},
+		})
+		policy.Notes = append(policy.Notes, fmt.Sprintf("noncurrent versions expire %d days after they are replaced", noncurrentDays))
+	}
+	policy.Rules = append(policy.Rules, LifecycleRule{
+		ID:                             abortRuleID,
+		Status:                         "Enabled",
+		AbortIncompleteMultipartUpload: &AbortMultipartUpload{DaysAfterInitiation: abortMultipartDays},
+	})
+	return policy
+}
+
+// suggestTransitions picks storage class transitions from the share of
+// sampled bytes old enough for each. Standard-IA and Glacier Instant
+// Retrieval bill at least 30 and 90 days, so colder classes need older data.
+func suggestTransitions(ages *AgeSample) ([]Transition, string) {
+	if ages == nil {
+		return []Transition{{Days: 30, StorageClass: "INTELLIGENT_TIERING"}},
+			"no object ages sampled; Intelligent-Tiering moves objects by access instead"
+	}
+	if ages.Objects == 0 {
+		return nil, "no objects sampled; no transitions suggested"
+	}
+	share30, share90 := ages.ColdShare(30), ages.ColdShare(90)
+	switch {
+	case share90 >= coldShare:
+		return []Transition{{Days: 30, StorageClass: "STANDARD_IA"}, {Days: 90, StorageClass: "GLACIER_IR"}},
+			fmt.Sprintf("%.0f%% of %d sampled objects' bytes are older than 90 days", share90*100, ages.Objects)
+	case share30 >= coldShare:
+		return []Transition{{Days: 30, StorageClass: "STANDARD_IA"}},
+			fmt.Sprintf("%.0f%% of %d sampled objects' bytes are older than 30 days", share30*100, ages.Objects)
+	}
+	return nil, fmt.Sprintf("only %.0f%% of %d sampled objects' bytes are older than 30 days; no transitions suggested", share30*100, ages.Objects)
+}
+
+// JSON renders the configuration for put-bucket-lifecycle-configuration
+func (p LifecyclePolicy) JSON() ([]byte, error) {
+	return json.MarshalIndent(map[string]any{"Rules": p.Rules}, "", "  ")
+}
+
+var terraformNameInvalid = regexp.MustCompile(`[^A-Za-z0-9_]`)
+
+// terraformName turns a bucket name into a Terraform identifier
+func terraformName(bucket string) string {
+	name := terraformNameInvalid.ReplaceAllString(bucket, "_")
+	if name == "" || (name[0] >= '0' && name[0] <= '9') {
+		name = "bucket_" + name
+	}
+	return name
+}
+
+// NameResources gives each policy a Terraform resource name unique among
+// them. Buckets whose names map to the same identifier, such as my-bucket
+// and my_bucket or one bucket name in two accounts, get a suffix hashed
+// from the account and bucket, so each keeps its name across runs.
+func NameResources(policies []LifecyclePolicy) {
+	counts := make(map[string]int, len(policies))
+	for _, p := range policies {
+		counts[terraformName(p.Bucket)]++
+	}
+	for i := range policies {
+		name := terraformName(policies[i].Bucket)
+		if counts[name] > 1 {
+			sum := sha256.Sum256([]byte(policies[i].Account + "/" + policies[i].Bucket))
+			name += "_" + hex.EncodeToString(sum[:4])
+		}
+		policies[i].resourceName = name
+	}
+}
+
+// Terraform renders the configuration as an
+// aws_s3_bucket_lifecycle_configuration resource, named by NameResources
+// or else after the bucket
+func (p LifecyclePolicy) Terraform() string {
+	name := p.resourceName
+	if name == "" {
+		name = terraformName(p.Bucket)
+	}
+	var b strings.Builder
+	for _, note := range p.Notes {
+		fmt.Fprintf(&b, "# %s\n", note)
+	}
+	fmt.Fprintf(&b, "resource \"aws_s3_bucket_lifecycle_configuration\" %q {\n", name)
+	fmt.Fprintf(&b, "  bucket = %q\n", p.Bucket)
+	for _, rule := range p.Rules {
+		fmt.Fprintf(&b, "\n  rule {\n    id     = %q\n    status = %q\n\n    filter {}\n", rule.ID, rule.Status)
+		for _, t := range rule.Transitions {
+			fmt.Fprintf(&b, "\n    transition {\n      days          = %d\n      storage_class = %q\n    }\n", t.Days, t.StorageClass)
+		}
+		if e := rule.NoncurrentVersionExpiration; e != nil {
+			fmt.Fprintf(&b, "\n    noncurrent_version_expiration {\n      noncurrent_days = %d\n    }\n", e.NoncurrentDays)
+		}
+		if a := rule.AbortIncompleteMultipartUpload; a != nil {
+			fmt.Fprintf(&b, "\n    abort_incomplete_multipart_upload {\n      days_after_initiation = %d\n    }\n", a.DaysAfterInitiation)
+		}
+		b.WriteString("  }\n")
+	}
+	b.WriteString("}\n")
+	return b.String()
+}
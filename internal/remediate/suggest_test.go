@@ -0,0 +1,201 @@
+package remediate
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/ppiankov/s3spectre/internal/report"
+	"github.com/ppiankov/s3spectre/internal/rules"
+)
+
+// fakeObjects serves objects in pages of two
+type fakeObjects struct {
+	objects []types.Object
+	listed  int
+}
+
+func (f *fakeObjects) ListObjectsV2(ctx context.Context, params *s3.ListObjectsV2Input, optFns ...func(*s3.Options)) (*s3.ListObjectsV2Output, error) {
+	f.listed++
+	start := 0
+	if params.ContinuationToken != nil {
+		start = len(aws.ToString(params.ContinuationToken))
+	}
+	end := min(start+2, len(f.objects))
+	out := &s3.ListObjectsV2Output{Contents: f.objects[start:end], IsTruncated: aws.Bool(end < len(f.objects))}
+	if end < len(f.objects) {
+		out.NextContinuationToken = aws.String(strings.Repeat("x", end))
+	}
+	return out, nil
+}
+
+func TestSampleAges(t *testing.T) {
+	now := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	object := func(daysOld int, size int64) types.Object {
+		return types.Object{LastModified: aws.Time(now.AddDate(0, 0, -daysOld)), Size: aws.Int64(size)}
+	}
+	api := &fakeObjects{objects: []types.Object{
+		object(200, 600), object(100, 100), object(40, 100), object(5, 200), object(1, 1000),
+	}}
+
+	sample, err := SampleAges(context.Background(), api, "logs", 2, now)
+	if err != nil {
+		t.Fatalf("SampleAges: %v", err)
+	}
+	if api.listed != 2 || sample.Objects != 4 || sample.Bytes != 1000 {
+		t.Errorf("sample = %+v after %d listings, want 4 objects from 2 pages", sample, api.listed)
+	}
+	if got := sample.ColdShare(90); got != 0.7 {
+		t.Errorf("ColdShare(90) = %v, want 0.7", got)
+	}
+	if got := sample.ColdShare(30); got != 0.8 {
+		t.Errorf("ColdShare(30) = %v, want 0.8", got)
+	}
+
+	api.listed = 0
+	all, _ := SampleAges(context.Background(), api, "logs", 10, now)
+	if api.listed != 3 || all.Objects != 5 {
+		t.Errorf("sample = %+v after %d listings, want the full listing in 3 pages", all, api.listed)
+	}
+}
+
+func TestSuggestLifecycle(t *testing.T) {
+	sampleOf := func(ages ...int) *AgeSample {
+		s := &AgeSample{}
+		for _, days := range ages {
+			s.Objects++
+			s.Bytes += 100
+			s.ages = append(s.ages, objectAge{days: days, size: 100})
+		}
+		return s
+	}
+	tests := []struct {
+		name        string
+		finding     string
+		ages        *AgeSample
+		transitions string
+		noncurrent  bool
+	}{
+		{"cold data", rules.StatusLifecycleMisconfig, sampleOf(400, 200, 10), "30:STANDARD_IA 90:GLACIER_IR", false},
+		{"warm data", rules.StatusVersionSprawl, sampleOf(60, 45, 10), "30:STANDARD_IA", true},
+		{"hot data", rules.StatusLifecycleMisconfig, sampleOf(1, 2, 40), "", false},
+		{"empty bucket", rules.StatusVersionSprawl, sampleOf(), "", true},
+		{"no sample", rules.StatusLifecycleMisconfig, nil, "30:INTELLIGENT_TIERING", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			policy := SuggestLifecycle("logs", tt.finding, tt.ages, 14)
+			var transitions []string
+			noncurrent := false
+			for _, rule := range policy.Rules {
+				for _, tr := range rule.Transitions {
+					transitions = append(transitions, strconv.Itoa(tr.Days)+":"+tr.StorageClass)
+				}
+				if rule.NoncurrentVersionExpiration != nil {
+					noncurrent = rule.NoncurrentVersionExpiration.NoncurrentDays == 14
+				}
+			}
+			if got := strings.Join(transitions, " "); got != tt.transitions {
+				t.Errorf("transitions = %q, want %q", got, tt.transitions)
+			}
+			if noncurrent != tt.noncurrent {
+				t.Errorf("noncurrent expiration = %v, want %v", noncurrent, tt.noncurrent)
+			}
+			last := policy.Rules[len(policy.Rules)-1]
+			if last.AbortIncompleteMultipartUpload == nil || last.AbortIncompleteMultipartUpload.DaysAfterInitiation != abortMultipartDays {
+				t.Errorf("last rule = %+v, want the multipart abort rule", last)
+			}
+			if len(policy.Notes) == 0 {
+				t.Error("policy has no notes explaining it")
+			}
+		})
+	}
+}
+
+func TestSuggestTargets(t *testing.T) {
+	targets := SuggestTargets([]report.Finding{
+		{Status: rules.StatusVersionSprawl, Bucket: "logs"},
+		{Status: rules.StatusVersionSprawl, Bucket: "logs"},
+		{Status: rules.StatusLifecycleMisconfig, Bucket: "data"},
+		{Status: rules.StatusUnusedBucket, Bucket: "old"},
+		{Status: rules.StatusLifecycleMisconfig, Bucket: ""},
+	})
+	if len(targets) != 2 || targets[0].Bucket != "data" || targets[1].Bucket != "logs" {
+		t.Errorf("SuggestTargets = %+v, want data and logs", targets)
+	}
+}
+
+func TestLifecyclePolicyRendering(t *testing.T) {
+	policy := SuggestLifecycle("2024.logs-eu", rules.StatusVersionSprawl, nil, 30)
+
+	data, err := policy.JSON()
+	if err != nil {
+		t.Fatalf("JSON: %v", err)
+	}
+	var config struct {
+		Rules []struct {
+			ID     string
+			Filter map[string]string
+		}
+	}
+	if err := json.Unmarshal(data, &config); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if len(config.Rules) != 3 || config.Rules[1].ID != LifecycleRuleID || config.Rules[1].Filter["Prefix"] != "" {
+		t.Errorf("rules = %s", data)
+	}
+	if !strings.Contains(string(data), `"Prefix": ""`) || !strings.Contains(string(data), `"NoncurrentDays": 30`) {
+		t.Errorf("JSON = %s", data)
+	}
+
+	hcl := policy.Terraform()
+	for _, want := range []string{
+		`resource "aws_s3_bucket_lifecycle_configuration" "bucket_2024_logs_eu" {`,
+		`bucket = "2024.logs-eu"`,
+		`storage_class = "INTELLIGENT_TIERING"`,
+		`noncurrent_days = 30`,
+		`days_after_initiation = 7`,
+		"# no object ages sampled",
+	} {
+		if !strings.Contains(hcl, want) {
+			t.Errorf("Terraform output missing %q:\n%s", want, hcl)
+		}
+	}
+	if strings.Count(hcl, "rule {") != 3 || strings.Count(hcl, "{") != strings.Count(hcl, "}") {
+		t.Errorf("unbalanced Terraform output:\n%s", hcl)
+	}
+}
+
+func TestNameResources(t *testing.T) {
+	policies := []LifecyclePolicy{
+		{Bucket: "my-bucket"},
+		{Bucket: "my_bucket"},
+		{Bucket: "logs", Account: "111111111111"},
+		{Bucket: "logs", Account: "222222222222"},
+		{Bucket: "orders"},
+	}
+	NameResources(policies)
+
+	seen := make(map[string]bool)
+	for _, p := range policies {
+		name := strings.SplitN(strings.SplitN(p.Terraform(), "\" \"", 2)[1], "\"", 2)[0]
+		if seen[name] {
+			t.Errorf("resource name %q used twice", name)
+		}
+		seen[name] = true
+	}
+	if !seen["orders"] {
+		t.Errorf("a name without collisions should be kept, got %v", seen)
+	}
+	again := []LifecyclePolicy{{Bucket: "my_bucket"}, {Bucket: "my-bucket"}}
+	NameResources(again)
+	if again[0].resourceName != policies[1].resourceName {
+		t.Errorf("names should not depend on order: %q, %q", again[0].resourceName, policies[1].resourceName)
+	}
+}
@@ -0,0 +1,49 @@
+package report
+
+import (
+	"fmt"
+
+	"github.com/ppiankov/s3spectre/internal/scanner"
+)
+
+// shortCommitLength is how much of a commit hash reports show
+const shortCommitLength = 12
+
+// blameText describes a commit as "Author <email> in abc123 (2024-01-02)"
+func blameText(b *scanner.Blame) string {
+	author := b.Author
+	if b.AuthorEmail != "" {
+		author += " <" + b.AuthorEmail + ">"
+	}
+	text := fmt.Sprintf("%s in %s", author, shortCommit(b.Commit))
+	if !b.Time.IsZero() {
+		text += " (" + b.Time.Format("2006-01-02") + ")"
+	}
+	return text
+}
+
+func shortCommit(commit string) string {
+	if len(commit) > shortCommitLength {
+		return commit[:shortCommitLength]
+	}
+	return commit
+}
+
+// withBlame adds a finding's last commit to its metadata
+func withBlame(metadata map[string]any, b *scanner.Blame) map[string]any {
+	if b == nil {
+		return metadata
+	}
+	if metadata == nil {
+		metadata = map[string]any{}
+	}
+	metadata["author"] = b.Author
+	if b.AuthorEmail != "" {
+		metadata["author_email"] = b.AuthorEmail
+	}
+	metadata["commit"] = b.Commit
+	if !b.Time.IsZero() {
+		metadata["committed_at"] = b.Time
+	}
+	return metadata
+}
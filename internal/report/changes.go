@@ -0,0 +1,92 @@
+package report
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/ppiankov/s3spectre/internal/rules"
+)
+
+// BaselineChange is a finding that appeared or went away since the baseline
+type BaselineChange struct {
+	Type     string `json:"type"`
+	Bucket   string `json:"bucket"`
+	Prefix   string `json:"prefix,omitempty"`
+	Account  string `json:"account,omitempty"`
+	Severity string `json:"severity,omitempty"`
+}
+
+// BaselineChanges compares a run's findings with a baseline report
+type BaselineChanges struct {
+	Baseline  string           `json:"baseline"`
+	New       []BaselineChange `json:"new,omitempty"`
+	Resolved  []BaselineChange `json:"resolved,omitempty"`
+	Unchanged int              `json:"unchanged"`
+}
+
+// Sort orders changes by severity, most severe first, then by type and
+// location
+func (c *BaselineChanges) Sort() {
+	for _, list := range [][]BaselineChange{c.New, c.Resolved} {
+		sort.Slice(list, func(i, j int) bool {
+			a, b := list[i], list[j]
+			if rules.Rank(a.Severity) != rules.Rank(b.Severity) {
+				return rules.Rank(a.Severity) > rules.Rank(b.Severity)
+			}
+			if a.Type != b.Type {
+				return a.Type < b.Type
+			}
+			return a.target() < b.target()
+		})
+	}
+}
+
+func (c BaselineChange) target() string {
+	target := c.Bucket
+	if c.Prefix != "" {
+		target += "/" + c.Prefix
+	}
+	if c.Account != "" {
+		target = c.Account + ":" + target
+	}
+	return target
+}
+
+// printBaselineChanges prints new (+) and resolved (-) findings grouped by
+// severity
+func (r *TextReporter) printBaselineChanges(changes *BaselineChanges) {
+	if changes == nil {
+		return
+	}
+	_, _ = fmt.Fprintf(r.writer, "%s\n", color.CyanString("Changes Since Baseline (%s)", changes.Baseline))
+	_, _ = fmt.Fprintf(r.writer, "%s\n", strings.Repeat("-", 50))
+	_, _ = fmt.Fprintf(r.writer, "New: %d  Resolved: %d  Unchanged: %d\n",
+		len(changes.New), len(changes.Resolved), changes.Unchanged)
+
+	groups := make(map[string][]string)
+	for _, c := range changes.New {
+		groups[c.Severity] = append(groups[c.Severity],
+			color.RedString("  + %s: %s", c.Type, c.target()))
+	}
+	for _, c := range changes.Resolved {
+		groups[c.Severity] = append(groups[c.Severity],
+			color.GreenString("  - %s: %s", c.Type, c.target()))
+	}
+	for _, severity := range append(rules.Severities(), "") {
+		lines := groups[severity]
+		if len(lines) == 0 {
+			continue
+		}
+		label := severity
+		if label == "" {
+			label = "unrated"
+		}
+		_, _ = fmt.Fprintf(r.writer, "\n%s\n", strings.ToUpper(label))
+		for _, line := range lines {
+			_, _ = fmt.Fprintf(r.writer, "%s\n", line)
+		}
+	}
+	_, _ = fmt.Fprintf(r.writer, "\n")
+}
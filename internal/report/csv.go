@@ -0,0 +1,337 @@
+package report
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/ppiankov/s3spectre/internal/analyzer"
+	"github.com/ppiankov/s3spectre/internal/rules"
+	"github.com/ppiankov/s3spectre/internal/scanner"
+)
+
+// csvHeader names the columns of CSV reports
+var csvHeader = []string{
+	"status", "severity", "resource", "bucket", "prefix", "account", "region",
+	"risk_score", "size_bytes", "references", "message", "fingerprint",
+}
+
+// CSVReporter writes one row per finding, for spreadsheets and BI tools
+type CSVReporter struct {
+	writer io.Writer
+}
+
+// NewCSVReporter creates a new CSV reporter
+func NewCSVReporter(w io.Writer) *CSVReporter {
+	return &CSVReporter{writer: w}
+}
+
+// findingRow is one finding of a CSV or Markdown report; zero numbers are
+// written as empty cells
+type findingRow struct {
+	status     string
+	severity   string
+	resource   string
+	bucket     string
+	prefix     string
+	account    string
+	region     string
+	riskScore  int
+	size       int64
+	references []scanner.Reference
+	message    string
+	// fingerprint matches the finding's fingerprint in the other formats
+	fingerprint string
+	// scope tells findings with the same status and resource apart
+	scope string
+	// blame is the last commit that changed the finding's references
+	blame *scanner.Blame
+}
+
+// Generate writes the scan's findings
+func (r *CSVReporter) Generate(data Data) error {
+	return r.write(scanFindingRows(data))
+}
+
+// GenerateDiscovery writes the discovery's findings
+func (r *CSVReporter) GenerateDiscovery(data DiscoveryData) error {
+	return r.write(discoveryFindingRows(data))
+}
+
+// scanFindingRows lists the scan's findings, sorted
+func scanFindingRows(data Data) []findingRow {
+	bucketRefs, prefixRefs := collectReferences(data.References)
+	vaultRefs := make(map[string][]scanner.Reference)
+	for _, ref := range data.VaultReferences {
+		vaultRefs[ref.Vault] = append(vaultRefs[ref.Vault], scanner.Reference{File: ref.File, Line: ref.Line})
+	}
+
+	var rows []findingRow
+	for name, analysis := range data.Buckets {
+		if analysis == nil {
+			continue
+		}
+		account := AnalysisAccount(data, analysis)
+		if analysis.Status != analyzer.StatusOK {
+			rows = append(rows, findingRow{
+				status:      string(analysis.Status),
+				severity:    statusSeverity(analysis.Status),
+				resource:    s3URI(name),
+				bucket:      name,
+				account:     account,
+				references:  bucketRefs[name],
+				message:     analysis.Message,
+				fingerprint: rules.Fingerprint(string(analysis.Status), account, name, ""),
+				blame:       analysis.Blame,
+			})
+		}
+		for _, p := range analysis.Prefixes {
+			if p.Status == analyzer.StatusOK {
+				continue
+			}
+			rows = append(rows, findingRow{
+				status:      string(p.Status),
+				severity:    statusSeverity(p.Status),
+				resource:    s3URI(name, p.Prefix),
+				bucket:      name,
+				prefix:      p.Prefix,
+				account:     account,
+				references:  prefixRefs[name][p.Prefix],
+				message:     p.Message,
+				fingerprint: rules.Fingerprint(string(p.Status), account, name, p.Prefix),
+				blame:       p.Blame,
+			})
+		}
+	}
+	for name, vault := range data.Vaults {
+		if vault == nil || vault.Status == analyzer.StatusOK {
+			continue
+		}
+		rows = append(rows, findingRow{
+			status:      string(vault.Status),
+			severity:    statusSeverity(vault.Status),
+			resource:    "glacier://" + name,
+			account:     data.Config.AccountID,
+			region:      vault.Region,
+			size:        vault.SizeInBytes,
+			references:  vaultRefs[vault.Name],
+			message:     vault.Message,
+			fingerprint: rules.Fingerprint(string(vault.Status), data.Config.AccountID, name, ""),
+		})
+	}
+	for _, p := range data.Permissions {
+		rows = append(rows, findingRow{
+			status:      string(p.Status),
+			severity:    statusSeverity(p.Status),
+			resource:    s3URI(p.Bucket, p.Prefix),
+			bucket:      p.Bucket,
+			prefix:      p.Prefix,
+			account:     data.Config.AccountID,
+			references:  []scanner.Reference{{File: p.File, Line: p.Line}},
+			message:     p.Message,
+			fingerprint: rules.Fingerprint(string(p.Status), data.Config.AccountID, p.Bucket, p.Key()),
+			scope:       p.Action,
+		})
+	}
+	for _, c := range data.Correlations {
+		rows = append(rows, correlationRow(c, data.Config.AccountID))
+	}
+	sortFindingRows(rows)
+	return rows
+}
+
+// discoveryFindingRows lists the discovery's findings, sorted
+func discoveryFindingRows(data DiscoveryData) []findingRow {
+	var rows []findingRow
+	for name, discovery := range data.Buckets {
+		if discovery == nil {
+			continue
+		}
+		base := findingRow{
+			resource:  s3URI(name),
+			bucket:    name,
+			account:   BucketAccount(data, discovery),
+			region:    discovery.Region,
+			riskScore: discovery.RiskScore,
+		}
+		info := discovery.BucketInfo
+		if info != nil {
+			base.size = info.TotalSize
+		}
+		if discovery.Status != analyzer.StatusOK {
+			row := base
+			row.status = string(discovery.Status)
+			row.severity = statusSeverity(discovery.Status)
+			row.message = discoveryStatusMessage(discovery, statusDescription(discovery.Status))
+			row.fingerprint = rules.Fingerprint(row.status, row.account, name, "")
+			rows = append(rows, row)
+		}
+		if info != nil {
+			if data.Config.CheckPublicAccess && info.PublicAccess != nil && info.PublicAccess.IsPublic {
+				rows = append(rows, ruleRow(base, rules.PublicBucket))
+			}
+			if data.Config.CheckEncryption && info.Encryption != nil && !info.Encryption.Enabled {
+				rows = append(rows, ruleRow(base, rules.NoEncryption))
+			}
+		}
+		for _, f := range discovery.PolicyFindings {
+			row := base
+			row.status = string(f.Status)
+			row.severity = statusSeverity(f.Status)
+			row.message = f.Message
+			row.fingerprint = rules.Fingerprint(row.status, row.account, name, "")
+			rows = append(rows, row)
+		}
+		for _, cf := range discovery.CustomFindings {
+			row := base
+			row.status = cf.Rule
+			row.severity = rules.CustomSeverity(cf.Rule, cf.Severity)
+			row.message = cf.Message
+			row.fingerprint = rules.Fingerprint(cf.RuleID(), row.account, name, "")
+			row.scope = cf.Plugin
+			rows = append(rows, row)
+		}
+	}
+	for _, f := range data.AccountFindings {
+		account := FindingAccount(data, f)
+		rows = append(rows, findingRow{
+			status:      string(f.Status),
+			severity:    statusSeverity(f.Status),
+			resource:    AccountFindingLocation(f),
+			account:     account,
+			region:      f.Region,
+			message:     f.Message,
+			fingerprint: rules.Fingerprint(string(f.Status), account, f.Region, f.Family),
+			scope:       f.Family,
+		})
+	}
+	for _, c := range data.Correlations {
+		rows = append(rows, correlationRow(c, CorrelationAccount(data, c)))
+	}
+	sortFindingRows(rows)
+	return rows
+}
+
+// correlationRow is a correlation with another tool's finding, told apart
+// from others on the bucket by that finding
+func correlationRow(c analyzer.Correlation, account string) findingRow {
+	return findingRow{
+		status:      string(c.Status),
+		severity:    rules.CustomSeverity(rules.Correlated, c.Severity),
+		resource:    s3URI(c.Bucket),
+		bucket:      c.Bucket,
+		account:     account,
+		message:     c.Message,
+		fingerprint: rules.Fingerprint(string(c.Status), account, c.Bucket, c.Key()),
+		scope:       c.Key(),
+	}
+}
+
+// ruleRow is a finding for a built-in rule that has no status of its own
+func ruleRow(base findingRow, ruleID string) findingRow {
+	base.status = ruleID
+	base.severity = ruleSeverity(ruleID)
+	base.message = fallbackMessage("", ruleID)
+	base.fingerprint = rules.Fingerprint(ruleID, base.account, base.bucket, "")
+	return base
+}
+
+// statusSeverity returns a status's severity after overrides
+func statusSeverity(status analyzer.Status) string {
+	if rule, ok := rules.ForStatus(string(status)); ok {
+		return rule.Severity()
+	}
+	return rules.SeverityMedium
+}
+
+// statusDescription is the description of a status's rule
+func statusDescription(status analyzer.Status) string {
+	if rule, ok := rules.ForStatus(string(status)); ok {
+		return rule.Description
+	}
+	return ""
+}
+
+// sortFindingRows orders rows by resource, status, scope and account
+func sortFindingRows(rows []findingRow) {
+	sort.Slice(rows, func(i, j int) bool {
+		if rows[i].resource != rows[j].resource {
+			return rows[i].resource < rows[j].resource
+		}
+		if rows[i].status != rows[j].status {
+			return rows[i].status < rows[j].status
+		}
+		if rows[i].scope != rows[j].scope {
+			return rows[i].scope < rows[j].scope
+		}
+		return rows[i].account < rows[j].account
+	})
+}
+
+// write writes the rows with a header
+func (r *CSVReporter) write(rows []findingRow) error {
+	cw := csv.NewWriter(r.writer)
+	if err := cw.Write(csvHeader); err != nil {
+		return err
+	}
+	for _, row := range rows {
+		record := []string{
+			row.status,
+			row.severity,
+			row.resource,
+			row.bucket,
+			row.prefix,
+			row.account,
+			row.region,
+			csvInt(int64(row.riskScore)),
+			csvInt(row.size),
+			csvReferences(row.references),
+			row.message,
+			row.fingerprint,
+		}
+		for i, cell := range record {
+			record[i] = csvSafe(cell)
+		}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+func csvInt(n int64) string {
+	if n == 0 {
+		return ""
+	}
+	return strconv.FormatInt(n, 10)
+}
+
+// csvReferences lists code references as file:line, separated by "; "
+func csvReferences(refs []scanner.Reference) string {
+	locations := make([]string, 0, len(refs))
+	seen := make(map[string]bool, len(refs))
+	for _, ref := range refs {
+		location := ref.File
+		if ref.Line > 0 {
+			location = fmt.Sprintf("%s:%d", ref.File, ref.Line)
+		}
+		if location != "" && !seen[location] {
+			seen[location] = true
+			locations = append(locations, location)
+		}
+	}
+	sort.Strings(locations)
+	return strings.Join(locations, "; ")
+}
+
+// csvSafe stops spreadsheets from evaluating a cell as a formula
+func csvSafe(cell string) string {
+	if cell != "" && strings.ContainsRune("=+-@\t\r", rune(cell[0])) {
+		return "'" + cell
+	}
+	return cell
+}
@@ -0,0 +1,143 @@
+package report
+
+import (
+	"bytes"
+	"encoding/csv"
+	"reflect"
+	"testing"
+
+	"github.com/ppiankov/s3spectre/internal/analyzer"
+	"github.com/ppiankov/s3spectre/internal/rules"
+	"github.com/ppiankov/s3spectre/internal/s3"
+	"github.com/ppiankov/s3spectre/internal/scanner"
+)
+
+// readCSV parses a CSV report into records keyed by column name
+func readCSV(t *testing.T, buf *bytes.Buffer) []map[string]string {
+	t.Helper()
+	records, err := csv.NewReader(buf).ReadAll()
+	if err != nil {
+		t.Fatalf("invalid CSV: %v", err)
+	}
+	if len(records) == 0 || !reflect.DeepEqual(records[0], csvHeader) {
+		t.Fatalf("missing header: %v", records)
+	}
+	rows := make([]map[string]string, 0, len(records)-1)
+	for _, record := range records[1:] {
+		row := make(map[string]string, len(record))
+		for i, cell := range record {
+			row[csvHeader[i]] = cell
+		}
+		rows = append(rows, row)
+	}
+	return rows
+}
+
+func TestCSVReporter_Generate(t *testing.T) {
+	data := Data{
+		Config: Config{AccountID: "111111111111"},
+		Buckets: map[string]*analyzer.BucketAnalysis{
+			"gone": {Name: "gone", Status: analyzer.StatusMissingBucket, Message: "Bucket referenced in code but does not exist in AWS"},
+			"data": {Name: "data", Status: analyzer.StatusOK, Prefixes: []analyzer.PrefixAnalysis{
+				{Prefix: "2019/", Status: analyzer.StatusStalePrefix},
+				{Prefix: "live/", Status: analyzer.StatusOK},
+			}},
+		},
+		References: []scanner.Reference{
+			{Bucket: "gone", File: "b.py", Line: 7},
+			{Bucket: "gone", File: "a.py", Line: 3},
+			{Bucket: "data", Prefix: "2019/", File: "etl.py", Line: 12},
+		},
+	}
+	var buf bytes.Buffer
+	if err := NewCSVReporter(&buf).Generate(data); err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+	rows := readCSV(t, &buf)
+	if len(rows) != 2 {
+		t.Fatalf("expected one row per finding, got %d: %v", len(rows), rows)
+	}
+
+	stale, missing := rows[0], rows[1]
+	if stale["status"] != "STALE_PREFIX" || stale["bucket"] != "data" || stale["prefix"] != "2019/" || stale["references"] != "etl.py:12" {
+		t.Errorf("unexpected prefix row %v", stale)
+	}
+	if missing["status"] != "MISSING_BUCKET" || missing["severity"] != "medium" || missing["resource"] != "s3://gone" {
+		t.Errorf("unexpected bucket row %v", missing)
+	}
+	if missing["references"] != "a.py:3; b.py:7" {
+		t.Errorf("references = %q, want sorted file:line list", missing["references"])
+	}
+	if want := rules.Fingerprint("MISSING_BUCKET", "111111111111", "gone", ""); missing["fingerprint"] != want {
+		t.Errorf("fingerprint = %q, want %q as in JSON reports", missing["fingerprint"], want)
+	}
+}
+
+func TestCSVReporter_GenerateDiscovery(t *testing.T) {
+	data := DiscoveryData{
+		Config: DiscoveryConfig{CheckEncryption: true},
+		Buckets: map[string]*analyzer.BucketDiscovery{
+			"archive": {
+				Name: "archive", Region: "eu-west-1", Status: analyzer.StatusRisky, RiskScore: 120,
+				BucketInfo: &s3.BucketInfo{Name: "archive", Exists: true, TotalSize: 2048, Encryption: &s3.EncryptionInfo{}},
+			},
+			"healthy": {Name: "healthy", Status: analyzer.StatusOK, BucketInfo: &s3.BucketInfo{Name: "healthy", Exists: true}},
+		},
+		AccountFindings: []analyzer.AccountAnalysis{
+			{Status: analyzer.StatusNoGuardDutyS3, Region: "us-east-1", Message: "GuardDuty S3 protection is not enabled"},
+		},
+	}
+	var buf bytes.Buffer
+	if err := NewCSVReporter(&buf).GenerateDiscovery(data); err != nil {
+		t.Fatalf("GenerateDiscovery failed: %v", err)
+	}
+	rows := readCSV(t, &buf)
+	if len(rows) != 3 {
+		t.Fatalf("expected 3 rows, got %d: %v", len(rows), rows)
+	}
+	if rows[0]["status"] != "NO_GUARDDUTY_S3" || rows[0]["resource"] != "guardduty://us-east-1" || rows[0]["region"] != "us-east-1" {
+		t.Errorf("unexpected account row %v", rows[0])
+	}
+	for _, row := range rows[1:] {
+		if row["bucket"] != "archive" || row["region"] != "eu-west-1" || row["risk_score"] != "120" || row["size_bytes"] != "2048" {
+			t.Errorf("unexpected bucket row %v", row)
+		}
+	}
+	if rows[1]["status"] != "NO_ENCRYPTION" || rows[2]["status"] != "RISKY" {
+		t.Errorf("rows should sort by status within a bucket: %v, %v", rows[1]["status"], rows[2]["status"])
+	}
+}
+
+func TestCSVSafe(t *testing.T) {
+	tests := map[string]string{
+		"=HYPERLINK(\"x\")": "'=HYPERLINK(\"x\")",
+		"-1":                "'-1",
+		"@sum":              "'@sum",
+		"plain":             "plain",
+		"":                  "",
+	}
+	for in, want := range tests {
+		if got := csvSafe(in); got != want {
+			t.Errorf("csvSafe(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestCSVReporter_PluginFingerprints(t *testing.T) {
+	data := DiscoveryData{
+		Buckets: map[string]*analyzer.BucketDiscovery{
+			"logs": {Name: "logs", Status: analyzer.StatusOK, CustomFindings: []analyzer.CustomFinding{
+				{Plugin: "owners", Rule: "NO_OWNER", Severity: "high"},
+				{Plugin: "tags", Rule: "NO_OWNER", Severity: "low"},
+			}},
+		},
+	}
+	var buf bytes.Buffer
+	if err := NewCSVReporter(&buf).GenerateDiscovery(data); err != nil {
+		t.Fatalf("GenerateDiscovery failed: %v", err)
+	}
+	rows := readCSV(t, &buf)
+	if len(rows) != 2 || rows[0]["fingerprint"] == rows[1]["fingerprint"] {
+		t.Errorf("two plugins' NO_OWNER findings should have different fingerprints: %v", rows)
+	}
+}
@@ -0,0 +1,47 @@
+package report
+
+import (
+	"sort"
+	"time"
+
+	"github.com/ppiankov/s3spectre/internal/analyzer"
+)
+
+// DeterministicTime is the timestamp of deterministic reports
+var DeterministicTime = time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// MakeDeterministic removes run-to-run variation from the report so it can
+// be snapshot-tested: the timestamp is fixed, run statistics are dropped and
+// every list is sorted
+func (d *Data) MakeDeterministic() {
+	d.Timestamp = DeterministicTime
+	d.Stats = nil
+	d.Summary.Sort()
+	for _, bucket := range d.Buckets {
+		sort.Slice(bucket.Prefixes, func(i, j int) bool {
+			return bucket.Prefixes[i].Prefix < bucket.Prefixes[j].Prefix
+		})
+	}
+}
+
+// MakeDeterministic removes run-to-run variation from the discovery report
+// so it can be snapshot-tested: the timestamp is fixed, run statistics are
+// dropped and every list is sorted
+func (d *DiscoveryData) MakeDeterministic() {
+	d.Timestamp = DeterministicTime
+	d.Stats = nil
+	d.Summary.Sort()
+	sortAccountFindings(d.AccountFindings)
+}
+
+func sortAccountFindings(findings []analyzer.AccountAnalysis) {
+	sort.Slice(findings, func(i, j int) bool {
+		if findings[i].Region != findings[j].Region {
+			return findings[i].Region < findings[j].Region
+		}
+		if findings[i].Status != findings[j].Status {
+			return findings[i].Status < findings[j].Status
+		}
+		return findings[i].Family < findings[j].Family
+	})
+}
@@ -0,0 +1,73 @@
+package report
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/ppiankov/s3spectre/internal/analyzer"
+)
+
+func deterministicScanData(timestamp time.Time, missing []string) Data {
+	return Data{
+		Tool:      "s3spectre",
+		Version:   "0.1.0",
+		Timestamp: timestamp,
+		Summary:   analyzer.Summary{TotalBuckets: 3, MissingBuckets: missing},
+		Buckets: map[string]*analyzer.BucketAnalysis{
+			"media": {Name: "media", Status: analyzer.StatusMissingBucket},
+			"logs":  {Name: "logs", Status: analyzer.StatusMissingBucket},
+			"data": {Name: "data", Status: analyzer.StatusStalePrefix, Prefixes: []analyzer.PrefixAnalysis{
+				{Prefix: "tmp/", Status: analyzer.StatusStalePrefix},
+				{Prefix: "raw/", Status: analyzer.StatusStalePrefix},
+			}},
+		},
+		Stats: NewRunStats(),
+	}
+}
+
+func TestMakeDeterministic_Scan(t *testing.T) {
+	var outputs []string
+	for _, data := range []Data{
+		deterministicScanData(time.Now(), []string{"media", "logs"}),
+		deterministicScanData(time.Now().Add(time.Hour), []string{"logs", "media"}),
+	} {
+		data.MakeDeterministic()
+		var buf bytes.Buffer
+		if err := NewJSONReporter(&buf).Generate(data); err != nil {
+			t.Fatalf("Generate failed: %v", err)
+		}
+		outputs = append(outputs, buf.String())
+	}
+	if outputs[0] != outputs[1] {
+		t.Fatalf("expected identical output, got:\n%s\n---\n%s", outputs[0], outputs[1])
+	}
+
+	data := deterministicScanData(time.Now(), []string{"media", "logs"})
+	data.MakeDeterministic()
+	if !data.Timestamp.Equal(DeterministicTime) || data.Stats != nil {
+		t.Fatalf("expected fixed timestamp and no stats, got %s, %+v", data.Timestamp, data.Stats)
+	}
+	if prefixes := data.Buckets["data"].Prefixes; prefixes[0].Prefix != "raw/" {
+		t.Fatalf("expected sorted prefixes, got %+v", prefixes)
+	}
+}
+
+func TestMakeDeterministic_Discovery(t *testing.T) {
+	data := DiscoveryData{
+		Timestamp: time.Now(),
+		Summary:   analyzer.DiscoverySummary{RiskyBuckets: []string{"b", "a"}},
+		AccountFindings: []analyzer.AccountAnalysis{
+			{Status: analyzer.StatusNoGuardDutyS3, Region: "us-west-2"},
+			{Status: analyzer.StatusNoGuardDutyS3, Region: "eu-west-1"},
+		},
+		Stats: NewRunStats(),
+	}
+	data.MakeDeterministic()
+	if data.Summary.RiskyBuckets[0] != "a" || data.AccountFindings[0].Region != "eu-west-1" {
+		t.Fatalf("expected sorted lists, got %+v and %+v", data.Summary.RiskyBuckets, data.AccountFindings)
+	}
+	if !data.Timestamp.Equal(DeterministicTime) || data.Stats != nil {
+		t.Fatalf("expected fixed timestamp and no stats")
+	}
+}
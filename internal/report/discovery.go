@@ -14,15 +14,74 @@ type DiscoveryData struct {
 	Config    DiscoveryConfig                  `json:"config"`
 	Summary   analyzer.DiscoverySummary         `json:"summary"`
 	Buckets   map[string]*analyzer.BucketDiscovery `json:"buckets"`
+	Groups    []analyzer.TagGroup              `json:"groups,omitempty"`
+	Sample    *analyzer.SampleEstimate         `json:"sample,omitempty"`
+	Posture   *analyzer.StoragePosture         `json:"posture,omitempty"`
+	// Regions breaks the results down by region
+	Regions []analyzer.RegionSummary `json:"regions,omitempty"`
+	// Accounts groups findings by owning account in multi-account runs
+	Accounts []analyzer.AccountSummary `json:"accounts,omitempty"`
+	// AccountFindings are account checks such as --check-guardduty
+	AccountFindings []analyzer.AccountAnalysis `json:"account_findings,omitempty"`
+	// Correlations are set with --correlate
+	Correlations []analyzer.Correlation `json:"correlations,omitempty"`
+	// Stats holds run statistics: API calls, checkpoint use and phases
+	Stats *RunStats `json:"stats,omitempty"`
+	// Truncated is set when the run was interrupted and the report is partial
+	Truncated *Truncation `json:"truncated,omitempty"`
+	// TuningHints suggest thresholds that fit the observed data better
+	TuningHints []analyzer.TuningHint `json:"tuning_hints,omitempty"`
+	// Spend totals month-to-date S3 spend from Cost Explorer
+	Spend *analyzer.SpendSummary `json:"spend,omitempty"`
+	// Changes compares the findings with --baseline; only text output shows it
+	Changes *BaselineChanges `json:"-"`
 }
 
 // DiscoveryConfig contains discovery scan configuration
 type DiscoveryConfig struct {
 	AWSProfile              string   `json:"aws_profile,omitempty"`
+	AccountID               string   `json:"account_id,omitempty"`
 	AllRegions              bool     `json:"all_regions"`
 	Regions                 []string `json:"regions,omitempty"`
 	AgeThresholdDays        int      `json:"age_threshold_days"`
 	InactivityThresholdDays int      `json:"inactivity_threshold_days"`
 	CheckEncryption         bool     `json:"check_encryption"`
 	CheckPublicAccess       bool     `json:"check_public_access"`
+	Source                  string   `json:"source,omitempty"`
+	TagFilters              []string `json:"tag_filters,omitempty"`
+	IncludeBuckets          []string `json:"include_buckets,omitempty"`
+	ExcludeBuckets          []string `json:"exclude_buckets,omitempty"`
+	GroupByTag              string   `json:"group_by_tag,omitempty"`
+	SampleBuckets           int      `json:"sample_buckets,omitempty"`
+	DeepSize                int      `json:"deep_size,omitempty"`
+	Plugins                 []string `json:"plugins,omitempty"`
+	CheckGuardDuty          bool     `json:"check_guardduty,omitempty"`
+	MaxNoLifecycle          int      `json:"max_buckets_without_lifecycle,omitempty"`
+	CheckEnvDrift           bool     `json:"check_env_drift,omitempty"`
+	CostTag                 string   `json:"cost_tag,omitempty"`
+	CheckAccess             bool     `json:"check_access,omitempty"`
+	MetricsSource           string   `json:"metrics_source,omitempty"`
+	InventoryManifests      []string `json:"inventory_manifests,omitempty"`
+	CheckPolicy             bool     `json:"check_policy,omitempty"`
+	CheckLogging            bool     `json:"check_logging,omitempty"`
+	ProductionTags          []string `json:"production_tags,omitempty"`
+	CheckAccessAnalyzer     bool     `json:"check_access_analyzer,omitempty"`
+	WithMacie               bool     `json:"with_macie,omitempty"`
+}
+
+// AccountFindingLocation names the service and region an account finding is
+// about, e.g. guardduty://us-east-1 or account://public-access-block
+func AccountFindingLocation(f analyzer.AccountAnalysis) string {
+	switch f.Status {
+	case analyzer.StatusNoGuardDutyS3:
+		return "guardduty://" + f.Region
+	case analyzer.StatusNoAccountPAB:
+		return "account://public-access-block"
+	case analyzer.StatusLowLifecycle:
+		return "account://lifecycle-coverage"
+	case analyzer.StatusEnvDrift:
+		return "account://env-family/" + f.Family
+	default:
+		return f.Region
+	}
 }
@@ -0,0 +1,73 @@
+package report
+
+import (
+	"github.com/ppiankov/s3spectre/internal/rules"
+	"github.com/ppiankov/s3spectre/internal/scanner"
+)
+
+// Finding is one finding as notifications and issue trackers describe it
+type Finding struct {
+	Status      string         `json:"status"`
+	Severity    string         `json:"severity"`
+	Resource    string         `json:"resource"`
+	Bucket      string         `json:"bucket,omitempty"`
+	Prefix      string         `json:"prefix,omitempty"`
+	Account     string         `json:"account,omitempty"`
+	Region      string         `json:"region,omitempty"`
+	Message     string         `json:"message,omitempty"`
+	Fingerprint string         `json:"fingerprint"`
+	RiskScore   int            `json:"risk_score,omitempty"`
+	SizeBytes   int64          `json:"size_bytes,omitempty"`
+	Blame       *scanner.Blame `json:"blame,omitempty"`
+	// References are the code locations behind a scan finding, when the
+	// report includes them
+	References []scanner.Reference `json:"references,omitempty"`
+}
+
+// ScanFindings lists the scan's findings, sorted by resource
+func ScanFindings(data Data) []Finding {
+	return exportFindingRows(scanFindingRows(data))
+}
+
+// DiscoveryFindings lists the discovery's findings, sorted by resource
+func DiscoveryFindings(data DiscoveryData) []Finding {
+	return exportFindingRows(discoveryFindingRows(data))
+}
+
+// NewSinceBaseline keeps the findings the baseline comparison reported as
+// new
+func NewSinceBaseline(findings []Finding, changes *BaselineChanges) []Finding {
+	added := make(map[string]bool, len(changes.New))
+	for _, c := range changes.New {
+		added[rules.Fingerprint(c.Type, c.Account, c.Bucket, c.Prefix)] = true
+	}
+	var kept []Finding
+	for _, f := range findings {
+		if added[f.Fingerprint] {
+			kept = append(kept, f)
+		}
+	}
+	return kept
+}
+
+func exportFindingRows(rows []findingRow) []Finding {
+	findings := make([]Finding, 0, len(rows))
+	for _, row := range rows {
+		findings = append(findings, Finding{
+			Status:      row.status,
+			Severity:    row.severity,
+			Resource:    row.resource,
+			Bucket:      row.bucket,
+			Prefix:      row.prefix,
+			Account:     row.account,
+			Region:      row.region,
+			Message:     row.message,
+			Fingerprint: row.fingerprint,
+			RiskScore:   row.riskScore,
+			SizeBytes:   row.size,
+			Blame:       row.blame,
+			References:  row.references,
+		})
+	}
+	return findings
+}
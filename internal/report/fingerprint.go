@@ -0,0 +1,112 @@
+package report
+
+import (
+	"github.com/ppiankov/s3spectre/internal/analyzer"
+	"github.com/ppiankov/s3spectre/internal/rules"
+)
+
+// AssignFingerprints stores each scan finding's fingerprint on its bucket,
+// prefix, vault, permission analysis or correlation. OK entries get none.
+func AssignFingerprints(data Data) {
+	for name, analysis := range data.Buckets {
+		if analysis == nil {
+			continue
+		}
+		account := AnalysisAccount(data, analysis)
+		analysis.Fingerprint = ""
+		if analysis.Status != analyzer.StatusOK {
+			analysis.Fingerprint = rules.Fingerprint(string(analysis.Status), account, name, "")
+		}
+		for i := range analysis.Prefixes {
+			p := &analysis.Prefixes[i]
+			p.Fingerprint = ""
+			if p.Status != analyzer.StatusOK {
+				p.Fingerprint = rules.Fingerprint(string(p.Status), account, name, p.Prefix)
+			}
+		}
+	}
+	for name, vault := range data.Vaults {
+		if vault == nil {
+			continue
+		}
+		vault.Fingerprint = ""
+		if vault.Status != analyzer.StatusOK {
+			vault.Fingerprint = rules.Fingerprint(string(vault.Status), data.Config.AccountID, name, "")
+		}
+	}
+	for _, p := range data.Permissions {
+		p.Fingerprint = rules.Fingerprint(string(p.Status), data.Config.AccountID, p.Bucket, p.Key())
+	}
+	for i := range data.Correlations {
+		c := &data.Correlations[i]
+		c.Fingerprint = rules.Fingerprint(string(c.Status), data.Config.AccountID, c.Bucket, c.Key())
+	}
+}
+
+// AssignDiscoveryFingerprints stores each discovery finding's fingerprint on
+// its bucket, custom finding, policy finding, account finding or
+// correlation. Account findings use the region in place of the bucket.
+func AssignDiscoveryFingerprints(data DiscoveryData) {
+	for name, discovery := range data.Buckets {
+		if discovery == nil {
+			continue
+		}
+		account := BucketAccount(data, discovery)
+		discovery.Fingerprint = ""
+		if discovery.Status != analyzer.StatusOK {
+			discovery.Fingerprint = rules.Fingerprint(string(discovery.Status), account, name, "")
+		}
+		for i := range discovery.CustomFindings {
+			cf := &discovery.CustomFindings[i]
+			cf.Fingerprint = rules.Fingerprint(cf.RuleID(), account, name, "")
+		}
+		for i := range discovery.PolicyFindings {
+			f := &discovery.PolicyFindings[i]
+			f.Fingerprint = rules.Fingerprint(string(f.Status), account, name, "")
+		}
+	}
+	for i := range data.AccountFindings {
+		f := &data.AccountFindings[i]
+		f.Fingerprint = rules.Fingerprint(string(f.Status), FindingAccount(data, *f), f.Region, f.Family)
+	}
+	for i := range data.Correlations {
+		c := &data.Correlations[i]
+		c.Fingerprint = rules.Fingerprint(string(c.Status), CorrelationAccount(data, *c), c.Bucket, c.Key())
+	}
+}
+
+// BucketAccount returns a discovered bucket's owning account when the source
+// reported one (AWS Config aggregators span accounts), else the caller's
+func BucketAccount(data DiscoveryData, discovery *analyzer.BucketDiscovery) string {
+	if discovery.BucketInfo != nil && discovery.BucketInfo.AccountID != "" {
+		return discovery.BucketInfo.AccountID
+	}
+	return data.Config.AccountID
+}
+
+// AnalysisAccount returns the account owning a scanned bucket in
+// multi-account runs, else the caller's
+func AnalysisAccount(data Data, analysis *analyzer.BucketAnalysis) string {
+	if analysis.Account != "" {
+		return analysis.Account
+	}
+	return data.Config.AccountID
+}
+
+// FindingAccount returns the account an account finding was checked in,
+// defaulting to the caller's
+func FindingAccount(data DiscoveryData, f analyzer.AccountAnalysis) string {
+	if f.Account != "" {
+		return f.Account
+	}
+	return data.Config.AccountID
+}
+
+// CorrelationAccount returns the account owning a correlated bucket,
+// defaulting to the caller's
+func CorrelationAccount(data DiscoveryData, c analyzer.Correlation) string {
+	if discovery := data.Buckets[c.Bucket]; discovery != nil {
+		return BucketAccount(data, discovery)
+	}
+	return data.Config.AccountID
+}
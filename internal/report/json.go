@@ -18,6 +18,7 @@ func NewJSONReporter(w io.Writer) *JSONReporter {
 // Generate generates a JSON report
 func (r *JSONReporter) Generate(data Data) error {
 	data.Timestamp = data.Timestamp.UTC()
+	AssignFingerprints(data)
 	encoder := json.NewEncoder(r.writer)
 	encoder.SetIndent("", "  ")
 	return encoder.Encode(data)
@@ -26,6 +27,7 @@ func (r *JSONReporter) Generate(data Data) error {
 // GenerateDiscovery generates a JSON discovery report
 func (r *JSONReporter) GenerateDiscovery(data DiscoveryData) error {
 	data.Timestamp = data.Timestamp.UTC()
+	AssignDiscoveryFingerprints(data)
 	encoder := json.NewEncoder(r.writer)
 	encoder.SetIndent("", "  ")
 	return encoder.Encode(data)
@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"github.com/ppiankov/s3spectre/internal/analyzer"
+	"github.com/ppiankov/s3spectre/internal/rules"
 	"github.com/ppiankov/s3spectre/internal/scanner"
 )
 
@@ -211,3 +212,38 @@ func TestJSONReporter_LargeInput(t *testing.T) {
 		t.Fatalf("expected 50 references, got %d", len(decoded.References))
 	}
 }
+
+func TestJSONReporter_Fingerprints(t *testing.T) {
+	var buf bytes.Buffer
+	data := Data{
+		Config: Config{AccountID: "123456789012"},
+		Buckets: map[string]*analyzer.BucketAnalysis{
+			"data": {
+				Name:   "data",
+				Status: analyzer.StatusOK,
+				Prefixes: []analyzer.PrefixAnalysis{
+					{Prefix: "logs/", Status: analyzer.StatusStalePrefix},
+				},
+			},
+			"gone": {Name: "gone", Status: analyzer.StatusMissingBucket},
+		},
+	}
+	if err := NewJSONReporter(&buf).Generate(data); err != nil {
+		t.Fatalf("Generate: %v", err)
+	}
+
+	var decoded Data
+	if err := json.Unmarshal(buf.Bytes(), &decoded); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	if got := decoded.Buckets["data"].Fingerprint; got != "" {
+		t.Fatalf("expected no fingerprint for OK bucket, got %q", got)
+	}
+	want := rules.Fingerprint(rules.StalePrefix, "123456789012", "data", "logs/")
+	if got := decoded.Buckets["data"].Prefixes[0].Fingerprint; got != want {
+		t.Fatalf("prefix fingerprint = %q, want %q", got, want)
+	}
+	if decoded.Buckets["gone"].Fingerprint == "" {
+		t.Fatalf("expected fingerprint for missing bucket")
+	}
+}
@@ -0,0 +1,248 @@
+package report
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/ppiankov/s3spectre/internal/analyzer"
+	"github.com/ppiankov/s3spectre/internal/rules"
+	"github.com/ppiankov/s3spectre/internal/scanner"
+)
+
+// LSP DiagnosticSeverity values
+const (
+	lspSeverityError       = 1
+	lspSeverityWarning     = 2
+	lspSeverityInformation = 3
+	lspSeverityHint        = 4
+)
+
+// LSPReporter emits diagnostics in Language Server Protocol shape: one
+// publishDiagnostics params object per file, so editor integrations can
+// forward them unchanged to underline broken s3:// references inline.
+type LSPReporter struct {
+	writer io.Writer
+}
+
+// NewLSPReporter creates a new LSP diagnostics reporter
+func NewLSPReporter(w io.Writer) *LSPReporter {
+	return &LSPReporter{writer: w}
+}
+
+type lspPublishDiagnostics struct {
+	URI         string          `json:"uri"`
+	Diagnostics []lspDiagnostic `json:"diagnostics"`
+}
+
+type lspDiagnostic struct {
+	Range    lspRange          `json:"range"`
+	Severity int               `json:"severity"`
+	Code     string            `json:"code"`
+	Source   string            `json:"source"`
+	Message  string            `json:"message"`
+	Data     lspDiagnosticData `json:"data"`
+}
+
+// lspDiagnosticData is preserved by clients between diagnostics and code actions
+type lspDiagnosticData struct {
+	Fingerprint string `json:"fingerprint"`
+}
+
+type lspRange struct {
+	Start lspPosition `json:"start"`
+	End   lspPosition `json:"end"`
+}
+
+// lspPosition is zero-based, as required by the protocol
+type lspPosition struct {
+	Line      int `json:"line"`
+	Character int `json:"character"`
+}
+
+// Generate emits diagnostics for missing buckets, missing prefixes and stale
+// prefixes at every code reference that points at them, and for permission
+// gaps at the reference whose operation is denied
+func (r *LSPReporter) Generate(data Data) error {
+	bucketRefs, prefixRefs := collectReferences(data.References)
+	files := make(map[string][]lspDiagnostic)
+	lines := newLineCache()
+
+	add := func(refs []scanner.Reference, status analyzer.Status, ruleID, message, bucket, prefix string) {
+		account := data.Config.AccountID
+		if analysis := data.Buckets[bucket]; analysis != nil {
+			account = AnalysisAccount(data, analysis)
+		}
+		fingerprint := rules.Fingerprint(ruleID, account, bucket, prefix)
+		for _, ref := range refs {
+			if ref.File == "" {
+				continue
+			}
+			files[ref.File] = append(files[ref.File], lspDiagnostic{
+				Range:    referenceRange(lines.line(ref.File, ref.Line), ref),
+				Severity: lspSeverity(ruleID),
+				Code:     string(status),
+				Source:   data.Tool,
+				Message:  fallbackMessage(message, ruleID),
+				Data:     lspDiagnosticData{Fingerprint: fingerprint},
+			})
+		}
+	}
+
+	for bucket, analysis := range data.Buckets {
+		if analysis == nil {
+			continue
+		}
+		if analysis.Status == analyzer.StatusMissingBucket {
+			add(bucketRefs[bucket], analysis.Status, sarifRuleMissingBucket, analysis.Message, bucket, "")
+		}
+		for _, prefix := range analysis.Prefixes {
+			switch prefix.Status {
+			case analyzer.StatusMissingPrefix:
+				add(prefixRefs[bucket][prefix.Prefix], prefix.Status, sarifRuleMissingPrefix, prefix.Message, bucket, prefix.Prefix)
+			case analyzer.StatusStalePrefix:
+				add(prefixRefs[bucket][prefix.Prefix], prefix.Status, sarifRuleStalePrefix, prefix.Message, bucket, prefix.Prefix)
+			case analyzer.StatusPrefixNearMiss:
+				add(prefixRefs[bucket][prefix.Prefix], prefix.Status, sarifRuleNearMiss, prefix.Message, bucket, prefix.Prefix)
+			}
+		}
+	}
+
+	for _, p := range data.Permissions {
+		ref := scanner.Reference{Bucket: p.Bucket, Prefix: p.Prefix, File: p.File, Line: p.Line}
+		add([]scanner.Reference{ref}, p.Status, sarifRulePermissionGap, p.Message, p.Bucket, p.Key())
+	}
+
+	paths := make([]string, 0, len(files))
+	for path := range files {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	out := make([]lspPublishDiagnostics, 0, len(paths))
+	for _, path := range paths {
+		diags := files[path]
+		sort.SliceStable(diags, func(i, j int) bool {
+			if diags[i].Range.Start.Line != diags[j].Range.Start.Line {
+				return diags[i].Range.Start.Line < diags[j].Range.Start.Line
+			}
+			return diags[i].Range.Start.Character < diags[j].Range.Start.Character
+		})
+		out = append(out, lspPublishDiagnostics{URI: fileURI(path), Diagnostics: diags})
+	}
+
+	encoder := json.NewEncoder(r.writer)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(out)
+}
+
+// GenerateDiscovery is not supported: discovered buckets have no source location
+func (r *LSPReporter) GenerateDiscovery(data DiscoveryData) error {
+	return fmt.Errorf("lsp-diagnostics format requires code references; use it with scan")
+}
+
+// referenceRange spans the reference text on its line, falling back to the whole line.
+// Characters are byte offsets, which match the protocol's UTF-16 offsets for
+// the ASCII text bucket names are made of.
+func referenceRange(text string, ref scanner.Reference) lspRange {
+	line := ref.Line - 1
+	if line < 0 {
+		line = 0
+	}
+
+	target := ref.Bucket
+	if ref.Prefix != "" {
+		target += "/" + ref.Prefix
+	}
+	var candidates []string
+	for _, scheme := range []string{"s3://", "s3a://", "s3n://"} {
+		candidates = append(candidates, scheme+target)
+	}
+	if ref.Prefix != "" {
+		candidates = append(candidates, target)
+	}
+	candidates = append(candidates, ref.Bucket)
+
+	for _, c := range candidates {
+		if idx := strings.Index(text, c); idx >= 0 {
+			return lspRange{
+				Start: lspPosition{Line: line, Character: idx},
+				End:   lspPosition{Line: line, Character: idx + len(c)},
+			}
+		}
+	}
+	return lspRange{
+		Start: lspPosition{Line: line},
+		End:   lspPosition{Line: line, Character: len(text)},
+	}
+}
+
+func lspSeverity(ruleID string) int {
+	switch ruleSeverity(ruleID) {
+	case rules.SeverityHigh:
+		return lspSeverityError
+	case rules.SeverityLow:
+		return lspSeverityInformation
+	case rules.SeverityInfo:
+		return lspSeverityHint
+	default:
+		return lspSeverityWarning
+	}
+}
+
+func fileURI(path string) string {
+	if abs, err := filepath.Abs(path); err == nil {
+		path = abs
+	}
+	return (&url.URL{Scheme: "file", Path: filepath.ToSlash(path)}).String()
+}
+
+// lineCache reads each referenced file once to locate references within lines
+type lineCache struct {
+	files map[string][]string
+}
+
+func newLineCache() *lineCache {
+	return &lineCache{files: make(map[string][]string)}
+}
+
+// line returns the 1-based line of a file, or "" if it cannot be read
+func (c *lineCache) line(path string, n int) string {
+	lines, ok := c.files[path]
+	if !ok {
+		lines = readLines(path)
+		c.files[path] = lines
+	}
+	if n < 1 || n > len(lines) {
+		return ""
+	}
+	return lines[n-1]
+}
+
+// maxLineCacheFile skips locating columns in very large (chunk-scanned) exports
+const maxLineCacheFile = 10 * 1024 * 1024
+
+func readLines(path string) []string {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil
+	}
+	defer func() { _ = f.Close() }()
+	if info, err := f.Stat(); err != nil || info.Size() > maxLineCacheFile {
+		return nil
+	}
+
+	var lines []string
+	s := bufio.NewScanner(f)
+	s.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for s.Scan() {
+		lines = append(lines, s.Text())
+	}
+	return lines
+}
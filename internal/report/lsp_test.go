@@ -0,0 +1,87 @@
+package report
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ppiankov/s3spectre/internal/analyzer"
+	"github.com/ppiankov/s3spectre/internal/scanner"
+)
+
+func TestLSPReporter_Generate(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "app.py")
+	content := "import boto3\nSRC = \"s3://missing-bucket/data\"\nLOGS = 's3a://logs-bucket/old/'\n"
+	if err := os.WriteFile(file, []byte(content), 0644); err != nil {
+		t.Fatalf("write fixture: %v", err)
+	}
+
+	data := Data{
+		Tool: "s3spectre",
+		Buckets: map[string]*analyzer.BucketAnalysis{
+			"missing-bucket": {Name: "missing-bucket", Status: analyzer.StatusMissingBucket},
+			"logs-bucket": {
+				Name:   "logs-bucket",
+				Status: analyzer.StatusOK,
+				Prefixes: []analyzer.PrefixAnalysis{
+					{Prefix: "old/", Status: analyzer.StatusStalePrefix, Message: "Prefix not modified for 400 days"},
+				},
+			},
+		},
+		References: []scanner.Reference{
+			{Bucket: "missing-bucket", Prefix: "data", File: file, Line: 2},
+			{Bucket: "logs-bucket", Prefix: "old/", File: file, Line: 3},
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := NewLSPReporter(&buf).Generate(data); err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+
+	var out []lspPublishDiagnostics
+	if err := json.Unmarshal(buf.Bytes(), &out); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	if len(out) != 1 {
+		t.Fatalf("expected diagnostics for 1 file, got %d", len(out))
+	}
+	if !strings.HasPrefix(out[0].URI, "file://") || !strings.HasSuffix(out[0].URI, "/app.py") {
+		t.Fatalf("unexpected URI: %s", out[0].URI)
+	}
+
+	diags := out[0].Diagnostics
+	if len(diags) != 2 {
+		t.Fatalf("expected 2 diagnostics, got %d", len(diags))
+	}
+
+	missing := diags[0]
+	if missing.Code != "MISSING_BUCKET" || missing.Severity != lspSeverityWarning {
+		t.Errorf("unexpected missing bucket diagnostic: %+v", missing)
+	}
+	if missing.Range.Start.Line != 1 || missing.Range.Start.Character != 7 || missing.Range.End.Character != 7+len("s3://missing-bucket/data") {
+		t.Errorf("unexpected range: %+v", missing.Range)
+	}
+
+	stale := diags[1]
+	if stale.Code != "STALE_PREFIX" || stale.Severity != lspSeverityInformation {
+		t.Errorf("unexpected stale prefix diagnostic: %+v", stale)
+	}
+	if stale.Range.Start.Character != 8 || stale.Range.End.Character != 8+len("s3a://logs-bucket/old/") {
+		t.Errorf("unexpected s3a range: %+v", stale.Range)
+	}
+	if stale.Message != "Prefix not modified for 400 days" {
+		t.Errorf("unexpected message: %s", stale.Message)
+	}
+}
+
+func TestLSPReporter_GenerateDiscovery(t *testing.T) {
+	var buf bytes.Buffer
+	if err := NewLSPReporter(&buf).GenerateDiscovery(DiscoveryData{}); err == nil {
+		t.Fatal("expected error for discovery data")
+	}
+}
@@ -0,0 +1,171 @@
+package report
+
+import (
+	"fmt"
+	"html"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/ppiankov/s3spectre/internal/rules"
+)
+
+// markdownTableLimit caps the findings table so the comment stays within
+// the size pull-request comments allow; details list every finding
+const markdownTableLimit = 50
+
+// MarkdownReporter writes a GitHub-flavored Markdown summary with a findings
+// table and collapsible details per status, for posting as a pull-request
+// comment from CI
+type MarkdownReporter struct {
+	writer io.Writer
+}
+
+// NewMarkdownReporter creates a new Markdown reporter
+func NewMarkdownReporter(w io.Writer) *MarkdownReporter {
+	return &MarkdownReporter{writer: w}
+}
+
+// Generate writes the scan's summary
+func (r *MarkdownReporter) Generate(data Data) error {
+	scanned := pluralize(data.Summary.TotalBuckets, "bucket") + " checked"
+	return r.write("S3Spectre scan", scanned, data.Truncated, scanFindingRows(data))
+}
+
+// GenerateDiscovery writes the discovery's summary
+func (r *MarkdownReporter) GenerateDiscovery(data DiscoveryData) error {
+	scanned := fmt.Sprintf("%s in %s discovered", pluralize(data.Summary.TotalBuckets, "bucket"), pluralize(data.Summary.TotalRegions, "region"))
+	return r.write("S3Spectre discovery", scanned, data.Truncated, discoveryFindingRows(data))
+}
+
+func (r *MarkdownReporter) write(title, scanned string, truncated *Truncation, rows []findingRow) error {
+	// Most severe first; findingRow order breaks ties
+	sort.SliceStable(rows, func(i, j int) bool {
+		return rules.Rank(rows[i].severity) > rules.Rank(rows[j].severity)
+	})
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "## %s\n\n", title)
+	if truncated != nil {
+		fmt.Fprintf(&b, "> [!WARNING]\n> Partial report: %s. %s not inspected.\n\n",
+			markdownText(truncated.Reason), pluralize(len(truncated.UninspectedBuckets), "bucket"))
+	}
+	if len(rows) == 0 {
+		fmt.Fprintf(&b, "No findings; %s.\n", scanned)
+		_, err := io.WriteString(r.writer, b.String())
+		return err
+	}
+	fmt.Fprintf(&b, "**%s**: %s; %s.\n\n", pluralize(len(rows), "finding"), severityCounts(rows), scanned)
+
+	b.WriteString("| Severity | Status | Resource | Message |\n")
+	b.WriteString("|----------|--------|----------|---------|\n")
+	for i, row := range rows {
+		if i == markdownTableLimit {
+			fmt.Fprintf(&b, "\n_%d more findings are listed in the details below._\n", len(rows)-markdownTableLimit)
+			break
+		}
+		fmt.Fprintf(&b, "| %s | %s | %s | %s |\n",
+			row.severity, markdownCode(row.status), markdownCode(row.resource), markdownText(row.message))
+	}
+
+	var statuses []string
+	byStatus := make(map[string][]findingRow)
+	for _, row := range rows {
+		if byStatus[row.status] == nil {
+			statuses = append(statuses, row.status)
+		}
+		byStatus[row.status] = append(byStatus[row.status], row)
+	}
+	for _, status := range statuses {
+		group := byStatus[status]
+		fmt.Fprintf(&b, "\n<details>\n<summary><code>%s</code> (%d)</summary>\n\n", html.EscapeString(status), len(group))
+		if rule, ok := rules.ForStatus(status); ok && rule.Description != "" {
+			fmt.Fprintf(&b, "%s\n\n", markdownText(rule.Description))
+		}
+		for _, row := range group {
+			fmt.Fprintf(&b, "- %s", markdownCode(row.resource))
+			if row.message != "" {
+				fmt.Fprintf(&b, ": %s", markdownText(row.message))
+			}
+			b.WriteString("\n")
+			if row.account != "" {
+				fmt.Fprintf(&b, "  - Account: %s\n", markdownCode(row.account))
+			}
+			if refs := csvReferences(row.references); refs != "" {
+				fmt.Fprintf(&b, "  - References: %s\n", markdownCode(refs))
+			}
+			if row.blame != nil {
+				fmt.Fprintf(&b, "  - Last changed by: %s\n", markdownText(blameText(row.blame)))
+			}
+			if row.fingerprint != "" {
+				fmt.Fprintf(&b, "  - Fingerprint: %s\n", markdownCode(row.fingerprint))
+			}
+		}
+		b.WriteString("\n</details>\n")
+	}
+	_, err := io.WriteString(r.writer, b.String())
+	return err
+}
+
+// severityCounts summarizes rows by severity, most severe first
+func severityCounts(rows []findingRow) string {
+	counts := make(map[string]int)
+	for _, row := range rows {
+		counts[row.severity]++
+	}
+	var parts []string
+	for _, severity := range rules.Severities() {
+		if counts[severity] > 0 {
+			parts = append(parts, fmt.Sprintf("%d %s", counts[severity], severity))
+			delete(counts, severity)
+		}
+	}
+	other := 0
+	for _, count := range counts {
+		other += count
+	}
+	if other > 0 {
+		parts = append(parts, fmt.Sprintf("%d other", other))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// pluralize formats a count with its noun, e.g. "1 finding", "3 findings"
+func pluralize(n int, noun string) string {
+	if n == 1 {
+		return "1 " + noun
+	}
+	return fmt.Sprintf("%d %ss", n, noun)
+}
+
+// markdownCode formats s as inline code, using a longer fence when s
+// contains backticks
+func markdownCode(s string) string {
+	fence := "`"
+	for strings.Contains(s, fence) {
+		fence += "`"
+	}
+	if strings.HasPrefix(s, "`") || strings.HasSuffix(s, "`") {
+		s = " " + s + " "
+	}
+	return fence + markdownCell(s) + fence
+}
+
+// markdownText escapes s for a table cell or list item so that it renders
+// as plain text
+func markdownText(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		if strings.ContainsRune("\\`*_[]<>#~", r) {
+			b.WriteByte('\\')
+		}
+		b.WriteRune(r)
+	}
+	return markdownCell(b.String())
+}
+
+// markdownCell keeps s on one table row: newlines become spaces and pipes
+// are escaped
+func markdownCell(s string) string {
+	return strings.NewReplacer("\r\n", " ", "\n", " ", "\r", " ", "|", "\\|").Replace(s)
+}
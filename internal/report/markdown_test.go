@@ -0,0 +1,89 @@
+package report
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/ppiankov/s3spectre/internal/analyzer"
+	"github.com/ppiankov/s3spectre/internal/s3"
+	"github.com/ppiankov/s3spectre/internal/scanner"
+)
+
+func TestMarkdownReporter_Generate(t *testing.T) {
+	data := Data{
+		Summary: analyzer.Summary{TotalBuckets: 2},
+		Buckets: map[string]*analyzer.BucketAnalysis{
+			"gone": {Name: "gone", Status: analyzer.StatusMissingBucket, Message: "Bucket referenced in code | but *missing*"},
+			"data": {Name: "data", Status: analyzer.StatusOK, Prefixes: []analyzer.PrefixAnalysis{
+				{Prefix: "2019/", Status: analyzer.StatusStalePrefix, Message: "Prefix is stale"},
+			}},
+		},
+		References: []scanner.Reference{{Bucket: "gone", File: "app.py", Line: 3}},
+	}
+	var buf bytes.Buffer
+	if err := NewMarkdownReporter(&buf).Generate(data); err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+	out := buf.String()
+
+	for _, want := range []string{
+		"## S3Spectre scan",
+		"**2 findings**",
+		"| Severity | Status | Resource | Message |",
+		"| medium | `MISSING_BUCKET` | `s3://gone` | Bucket referenced in code \\| but \\*missing\\* |",
+		"<summary><code>MISSING_BUCKET</code> (1)</summary>",
+		"  - References: `app.py:3`",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+	if strings.Count(out, "<details>") != 2 || strings.Count(out, "</details>") != 2 {
+		t.Errorf("expected one details section per status:\n%s", out)
+	}
+}
+
+func TestMarkdownReporter_TableLimit(t *testing.T) {
+	buckets := make(map[string]*analyzer.BucketAnalysis)
+	for i := 0; i < markdownTableLimit+5; i++ {
+		name := fmt.Sprintf("gone-%03d", i)
+		buckets[name] = &analyzer.BucketAnalysis{Name: name, Status: analyzer.StatusMissingBucket}
+	}
+	var buf bytes.Buffer
+	if err := NewMarkdownReporter(&buf).Generate(Data{Buckets: buckets}); err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+	out := buf.String()
+	if rows := strings.Count(out, "\n| medium |"); rows != markdownTableLimit {
+		t.Errorf("table rows = %d, want %d", rows, markdownTableLimit)
+	}
+	if !strings.Contains(out, "_5 more findings are listed in the details below._") {
+		t.Errorf("expected overflow note:\n%s", out)
+	}
+	if items := strings.Count(out, "\n- `s3://gone-"); items != markdownTableLimit+5 {
+		t.Errorf("details items = %d, want every finding", items)
+	}
+}
+
+func TestMarkdownReporter_GenerateDiscovery(t *testing.T) {
+	var buf bytes.Buffer
+	err := NewMarkdownReporter(&buf).GenerateDiscovery(DiscoveryData{
+		Summary: analyzer.DiscoverySummary{TotalBuckets: 1, TotalRegions: 1},
+		Buckets: map[string]*analyzer.BucketDiscovery{
+			"healthy": {Name: "healthy", Status: analyzer.StatusOK, BucketInfo: &s3.BucketInfo{Name: "healthy", Exists: true}},
+		},
+		Truncated: &Truncation{Reason: "interrupted", UninspectedBuckets: []string{"late"}},
+	})
+	if err != nil {
+		t.Fatalf("GenerateDiscovery failed: %v", err)
+	}
+	out := buf.String()
+	if !strings.Contains(out, "No findings; 1 bucket in 1 region discovered.") || strings.Contains(out, "<details>") {
+		t.Errorf("unexpected output for a clean discovery:\n%s", out)
+	}
+	if !strings.Contains(out, "> [!WARNING]") {
+		t.Errorf("partial report should carry a warning:\n%s", out)
+	}
+}
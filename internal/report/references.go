@@ -0,0 +1,194 @@
+package report
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ppiankov/s3spectre/internal/scanner"
+)
+
+// InventoryReference is a code reference with its estimated confidence
+type InventoryReference struct {
+	scanner.Reference
+	Confidence string `json:"confidence"`
+}
+
+// FileReferences counts the references found in one file
+type FileReferences struct {
+	File       string `json:"file"`
+	References int    `json:"references"`
+}
+
+// ReferenceSummary totals a reference inventory
+type ReferenceSummary struct {
+	FilesScanned        int            `json:"files_scanned"`
+	SkippedFiles        map[string]int `json:"skipped_files,omitempty"`
+	FilesWithReferences int            `json:"files_with_references"`
+	References          int            `json:"references"`
+	Buckets             int            `json:"buckets"`
+	ByContext           map[string]int `json:"by_context,omitempty"`
+	ByConfidence        map[string]int `json:"by_confidence,omitempty"`
+}
+
+// ReferenceInventory is every S3 reference found in a repository, without
+// any AWS state
+type ReferenceInventory struct {
+	Tool       string               `json:"tool"`
+	Version    string               `json:"version"`
+	Timestamp  time.Time            `json:"timestamp"`
+	RepoPath   string               `json:"repo_path"`
+	Summary    ReferenceSummary     `json:"summary"`
+	Files      []FileReferences     `json:"files"`
+	References []InventoryReference `json:"references"`
+}
+
+// NewReferenceInventory builds an inventory from references, sorted by file
+// and line
+func NewReferenceInventory(repoPath string, refs []scanner.Reference, filesScanned int) *ReferenceInventory {
+	inv := &ReferenceInventory{
+		Tool:       "s3spectre",
+		RepoPath:   repoPath,
+		References: make([]InventoryReference, 0, len(refs)),
+		Files:      []FileReferences{},
+		Summary: ReferenceSummary{
+			FilesScanned: filesScanned,
+			References:   len(refs),
+			ByContext:    make(map[string]int),
+			ByConfidence: make(map[string]int),
+		},
+	}
+	perFile := make(map[string]int)
+	buckets := make(map[string]bool)
+	for _, ref := range refs {
+		entry := InventoryReference{Reference: ref, Confidence: ref.Confidence()}
+		inv.References = append(inv.References, entry)
+		perFile[ref.File]++
+		buckets[ref.Bucket] = true
+		context := ref.Context
+		if context == "" {
+			context = string(scanner.RefTypeUnknown)
+		}
+		inv.Summary.ByContext[context]++
+		inv.Summary.ByConfidence[entry.Confidence]++
+	}
+	sort.Slice(inv.References, func(i, j int) bool {
+		a, b := inv.References[i], inv.References[j]
+		if a.File != b.File {
+			return a.File < b.File
+		}
+		if a.Line != b.Line {
+			return a.Line < b.Line
+		}
+		return a.Bucket+"/"+a.Prefix < b.Bucket+"/"+b.Prefix
+	})
+	for file, count := range perFile {
+		inv.Files = append(inv.Files, FileReferences{File: file, References: count})
+	}
+	sort.Slice(inv.Files, func(i, j int) bool {
+		if inv.Files[i].References != inv.Files[j].References {
+			return inv.Files[i].References > inv.Files[j].References
+		}
+		return inv.Files[i].File < inv.Files[j].File
+	})
+	inv.Summary.FilesWithReferences = len(perFile)
+	inv.Summary.Buckets = len(buckets)
+	return inv
+}
+
+// WriteReferences renders an inventory as text, json or csv
+func WriteReferences(w io.Writer, format string, inv *ReferenceInventory) error {
+	switch format {
+	case "text":
+		return writeReferencesText(w, inv)
+	case "json":
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(inv)
+	case "csv":
+		return writeReferencesCSV(w, inv)
+	default:
+		return fmt.Errorf("unsupported refs format: %s (supported: text, json, csv)", format)
+	}
+}
+
+func writeReferencesText(w io.Writer, inv *ReferenceInventory) error {
+	_, _ = fmt.Fprintf(w, "S3 References in %s\n", inv.RepoPath)
+	_, _ = fmt.Fprintf(w, "%s\n\n", strings.Repeat("=", 50))
+	_, _ = fmt.Fprintf(w, "Files Scanned: %d\n", inv.Summary.FilesScanned)
+	if len(inv.Summary.SkippedFiles) > 0 {
+		_, _ = fmt.Fprintf(w, "Files Skipped: %s\n", formatCounts(inv.Summary.SkippedFiles))
+	}
+	_, _ = fmt.Fprintf(w, "Files With References: %d\n", inv.Summary.FilesWithReferences)
+	_, _ = fmt.Fprintf(w, "References: %d (%d buckets)\n", inv.Summary.References, inv.Summary.Buckets)
+	_, _ = fmt.Fprintf(w, "By Context: %s\n", formatCounts(inv.Summary.ByContext))
+	_, _ = fmt.Fprintf(w, "By Confidence: %s\n\n", formatCounts(inv.Summary.ByConfidence))
+
+	if len(inv.Files) > 0 {
+		_, _ = fmt.Fprintf(w, "Files\n%s\n", strings.Repeat("-", 50))
+		for _, f := range inv.Files {
+			_, _ = fmt.Fprintf(w, "  %5d  %s\n", f.References, f.File)
+		}
+		_, _ = fmt.Fprintf(w, "\n")
+	}
+
+	if len(inv.References) > 0 {
+		_, _ = fmt.Fprintf(w, "References\n%s\n", strings.Repeat("-", 50))
+		for _, ref := range inv.References {
+			target := "s3://" + ref.Bucket
+			if ref.Prefix != "" {
+				target += "/" + ref.Prefix
+			}
+			context := contextOrUnknown(ref.Context)
+			if ref.Workflow != "" {
+				context += ": " + ref.Workflow
+			}
+			_, _ = fmt.Fprintf(w, "  %s:%d  %s  [%s, %s]\n", ref.File, ref.Line, target, context, ref.Confidence)
+		}
+	}
+	return nil
+}
+
+func writeReferencesCSV(w io.Writer, inv *ReferenceInventory) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write([]string{"file", "line", "bucket", "prefix", "version_id", "context", "confidence", "workflow"}); err != nil {
+		return err
+	}
+	for _, ref := range inv.References {
+		row := []string{ref.File, strconv.Itoa(ref.Line), ref.Bucket, ref.Prefix, ref.VersionID, contextOrUnknown(ref.Context), ref.Confidence, ref.Workflow}
+		if err := cw.Write(row); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+func contextOrUnknown(context string) string {
+	if context == "" {
+		return string(scanner.RefTypeUnknown)
+	}
+	return context
+}
+
+// formatCounts renders counts as "key=n" pairs in key order
+func formatCounts(counts map[string]int) string {
+	keys := make([]string, 0, len(counts))
+	for k := range counts {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	parts := make([]string, 0, len(keys))
+	for _, k := range keys {
+		parts = append(parts, fmt.Sprintf("%s=%d", k, counts[k]))
+	}
+	if len(parts) == 0 {
+		return "none"
+	}
+	return strings.Join(parts, ", ")
+}
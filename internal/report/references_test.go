@@ -0,0 +1,115 @@
+package report
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/ppiankov/s3spectre/internal/scanner"
+)
+
+func sampleInventory() *ReferenceInventory {
+	refs := []scanner.Reference{
+		{Bucket: "prod-data", Prefix: "logs/", File: "b.py", Line: 9, Context: "read"},
+		{Bucket: "prod-data", File: "a.yaml", Line: 3},
+		{Bucket: "example-bucket", File: "b.py", Line: 2, Context: "write"},
+	}
+	return NewReferenceInventory("/repo", refs, 5)
+}
+
+func TestNewReferenceInventory(t *testing.T) {
+	inv := sampleInventory()
+
+	if inv.Summary.FilesScanned != 5 || inv.Summary.FilesWithReferences != 2 {
+		t.Errorf("files: got %+v", inv.Summary)
+	}
+	if inv.Summary.References != 3 || inv.Summary.Buckets != 2 {
+		t.Errorf("references: got %+v", inv.Summary)
+	}
+	if inv.Summary.ByContext["unknown"] != 1 || inv.Summary.ByContext["read"] != 1 {
+		t.Errorf("by context: got %v", inv.Summary.ByContext)
+	}
+	if inv.Summary.ByConfidence[scanner.ConfidenceLow] != 1 || inv.Summary.ByConfidence[scanner.ConfidenceMedium] != 1 {
+		t.Errorf("by confidence: got %v", inv.Summary.ByConfidence)
+	}
+	if inv.Files[0].File != "b.py" || inv.Files[0].References != 2 {
+		t.Errorf("files should sort by count: got %+v", inv.Files)
+	}
+	if inv.References[0].File != "a.yaml" || inv.References[1].Line != 2 {
+		t.Errorf("references should sort by file and line: got %+v", inv.References)
+	}
+}
+
+func TestWriteReferences(t *testing.T) {
+	inv := sampleInventory()
+	inv.Summary.SkippedFiles = map[string]int{scanner.SkipUnknownExtension: 4, scanner.SkipTooLarge: 1}
+
+	var text bytes.Buffer
+	if err := WriteReferences(&text, "text", inv); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"References: 3 (2 buckets)", "Files Skipped: too_large=1, unknown_extension=4", "b.py:9  s3://prod-data/logs/  [read, high]", "a.yaml:3  s3://prod-data  [unknown, medium]"} {
+		if !strings.Contains(text.String(), want) {
+			t.Errorf("text output missing %q:\n%s", want, text.String())
+		}
+	}
+
+	var js bytes.Buffer
+	if err := WriteReferences(&js, "json", inv); err != nil {
+		t.Fatal(err)
+	}
+	var decoded ReferenceInventory
+	if err := json.Unmarshal(js.Bytes(), &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if decoded.Summary.SkippedFiles[scanner.SkipUnknownExtension] != 4 {
+		t.Errorf("json skipped files: got %v", decoded.Summary.SkippedFiles)
+	}
+	if len(decoded.References) != 3 || decoded.References[2].Confidence != scanner.ConfidenceHigh {
+		t.Errorf("json round trip: got %+v", decoded.References)
+	}
+
+	var out bytes.Buffer
+	if err := WriteReferences(&out, "csv", inv); err != nil {
+		t.Fatal(err)
+	}
+	rows, err := csv.NewReader(&out).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 4 || rows[0][0] != "file" || rows[2][6] != scanner.ConfidenceLow {
+		t.Errorf("csv rows: got %v", rows)
+	}
+
+	if err := WriteReferences(&out, "sarif", inv); err == nil {
+		t.Error("expected error for unsupported format")
+	}
+}
+
+func TestWriteReferences_Workflow(t *testing.T) {
+	inv := NewReferenceInventory("/repo", []scanner.Reference{
+		{Bucket: "deploy-artifacts", File: ".github/workflows/deploy.yml", Line: 12, Context: "ci", Workflow: "Deploy"},
+	}, 1)
+
+	var text bytes.Buffer
+	if err := WriteReferences(&text, "text", inv); err != nil {
+		t.Fatal(err)
+	}
+	if want := "s3://deploy-artifacts  [ci: Deploy, high]"; !strings.Contains(text.String(), want) {
+		t.Errorf("text output missing %q:\n%s", want, text.String())
+	}
+
+	var out bytes.Buffer
+	if err := WriteReferences(&out, "csv", inv); err != nil {
+		t.Fatal(err)
+	}
+	rows, err := csv.NewReader(&out).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rows[0][7] != "workflow" || rows[1][7] != "Deploy" {
+		t.Errorf("csv rows: got %v", rows)
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"strings"
 
 	"github.com/ppiankov/s3spectre/internal/analyzer"
+	"github.com/ppiankov/s3spectre/internal/rules"
 	"github.com/ppiankov/s3spectre/internal/scanner"
 )
 
@@ -15,16 +16,23 @@ const (
 	sarifSchema  = "https://json.schemastore.org/sarif-2.1.0.json"
 	sarifVersion = "2.1.0"
 
-	sarifRuleMissingBucket  = "s3spectre/MISSING_BUCKET"
-	sarifRuleMissingPrefix  = "s3spectre/MISSING_PREFIX"
-	sarifRuleStalePrefix    = "s3spectre/STALE_PREFIX"
-	sarifRuleUnusedBucket   = "s3spectre/UNUSED_BUCKET"
-	sarifRuleVersionSprawl  = "s3spectre/VERSION_SPRAWL"
-	sarifRuleLifecycleGap   = "s3spectre/LIFECYCLE_GAP"
-	sarifRulePublicBucket   = "s3spectre/PUBLIC_BUCKET"
-	sarifRuleNoEncryption   = "s3spectre/NO_ENCRYPTION"
-	sarifRuleInactiveBucket = "s3spectre/INACTIVE_BUCKET"
-	sarifRuleRiskyBucket    = "s3spectre/RISKY_BUCKET"
+	sarifRuleMissingBucket  = rules.SARIFPrefix + rules.MissingBucket
+	sarifRuleMissingPrefix  = rules.SARIFPrefix + rules.MissingPrefix
+	sarifRuleStalePrefix    = rules.SARIFPrefix + rules.StalePrefix
+	sarifRuleNearMiss       = rules.SARIFPrefix + rules.PrefixNearMiss
+	sarifRuleUnusedBucket   = rules.SARIFPrefix + rules.UnusedBucket
+	sarifRuleVersionSprawl  = rules.SARIFPrefix + rules.VersionSprawl
+	sarifRuleLifecycleGap   = rules.SARIFPrefix + rules.LifecycleGap
+	sarifRulePublicBucket   = rules.SARIFPrefix + rules.PublicBucket
+	sarifRuleNoEncryption   = rules.SARIFPrefix + rules.NoEncryption
+	sarifRuleInactiveBucket = rules.SARIFPrefix + rules.InactiveBucket
+	sarifRuleRiskyBucket    = rules.SARIFPrefix + rules.RiskyBucket
+	sarifRuleOrphanedVault  = rules.SARIFPrefix + rules.OrphanedVault
+	sarifRuleMissingVault   = rules.SARIFPrefix + rules.MissingVault
+	sarifRulePermissionGap  = rules.SARIFPrefix + rules.PermissionGap
+	sarifRuleNoGuardDutyS3  = rules.SARIFPrefix + rules.NoGuardDutyS3
+
+	sarifRuleReplicationDestination = rules.SARIFPrefix + rules.ReplicationDestination
 )
 
 type SARIFReporter struct {
@@ -57,16 +65,29 @@ type sarifDriver struct {
 }
 
 type sarifRule struct {
-	ID               string       `json:"id"`
-	Name             string       `json:"name,omitempty"`
-	ShortDescription sarifMessage `json:"shortDescription,omitempty"`
+	ID               string        `json:"id"`
+	Name             string        `json:"name,omitempty"`
+	ShortDescription sarifMessage  `json:"shortDescription,omitempty"`
+	FullDescription  *sarifMessage `json:"fullDescription,omitempty"`
+	Help             *sarifHelp    `json:"help,omitempty"`
+	HelpURI          string        `json:"helpUri,omitempty"`
+}
+
+type sarifHelp struct {
+	Text     string `json:"text"`
+	Markdown string `json:"markdown,omitempty"`
 }
 
 type sarifResult struct {
-	RuleID    string          `json:"ruleId"`
-	Level     string          `json:"level,omitempty"`
-	Message   sarifMessage    `json:"message"`
-	Locations []sarifLocation `json:"locations,omitempty"`
+	RuleID              string            `json:"ruleId"`
+	Level               string            `json:"level,omitempty"`
+	Message             sarifMessage      `json:"message"`
+	Locations           []sarifLocation   `json:"locations,omitempty"`
+	PartialFingerprints map[string]string `json:"partialFingerprints,omitempty"`
+	// Rank is the finding's confidence score, 0-100
+	Rank *float64 `json:"rank,omitempty"`
+	// Properties carry the finding's last commit when references are blamed
+	Properties map[string]any `json:"properties,omitempty"`
 }
 
 type sarifMessage struct {
@@ -90,65 +111,6 @@ type sarifRegion struct {
 	StartLine int `json:"startLine,omitempty"`
 }
 
-type sarifRuleMeta struct {
-	Name        string
-	Description string
-	Level       string
-}
-
-var sarifRules = map[string]sarifRuleMeta{
-	sarifRuleMissingBucket: {
-		Name:        "MissingBucket",
-		Description: "Bucket referenced in code but does not exist in AWS",
-		Level:       "warning",
-	},
-	sarifRuleMissingPrefix: {
-		Name:        "MissingPrefix",
-		Description: "Prefix referenced in code but no objects were found",
-		Level:       "warning",
-	},
-	sarifRuleStalePrefix: {
-		Name:        "StalePrefix",
-		Description: "Prefix has not been modified recently",
-		Level:       "note",
-	},
-	sarifRuleUnusedBucket: {
-		Name:        "UnusedBucket",
-		Description: "Bucket appears unused",
-		Level:       "note",
-	},
-	sarifRuleVersionSprawl: {
-		Name:        "VersionSprawl",
-		Description: "Versioning enabled without lifecycle rules",
-		Level:       "note",
-	},
-	sarifRuleLifecycleGap: {
-		Name:        "LifecycleGap",
-		Description: "Lifecycle rules are missing for the bucket",
-		Level:       "note",
-	},
-	sarifRulePublicBucket: {
-		Name:        "PublicBucket",
-		Description: "Bucket is publicly accessible",
-		Level:       "error",
-	},
-	sarifRuleNoEncryption: {
-		Name:        "NoEncryption",
-		Description: "Bucket does not have default encryption enabled",
-		Level:       "warning",
-	},
-	sarifRuleInactiveBucket: {
-		Name:        "InactiveBucket",
-		Description: "Bucket has been inactive for an extended period",
-		Level:       "warning",
-	},
-	sarifRuleRiskyBucket: {
-		Name:        "RiskyBucket",
-		Description: "Bucket risk score exceeds the configured threshold",
-		Level:       "warning",
-	},
-}
-
 func (r *SARIFReporter) Generate(data Data) error {
 	bucketRefs, prefixRefs := collectReferences(data.References)
 
@@ -167,24 +129,32 @@ func (r *SARIFReporter) Generate(data Data) error {
 			continue
 		}
 
+		account := AnalysisAccount(data, analysis)
+		n := len(results)
 		switch analysis.Status {
 		case analyzer.StatusMissingBucket:
 			message := fallbackMessage(analysis.Message, sarifRuleMissingBucket)
 			locations := locationsWithFallback(bucketRefs[bucket], s3URI(bucket))
-			results = appendResult(results, usedRules, sarifRuleMissingBucket, message, locations)
+			results = appendResult(results, usedRules, sarifRuleMissingBucket, message, locations, rules.Fingerprint(sarifRuleMissingBucket, account, bucket, ""))
 		case analyzer.StatusUnusedBucket:
 			message := fallbackMessage(analysis.Message, sarifRuleUnusedBucket)
 			locations := locationsWithFallback(bucketRefs[bucket], s3URI(bucket))
-			results = appendResult(results, usedRules, sarifRuleUnusedBucket, message, locations)
+			results = appendResult(results, usedRules, sarifRuleUnusedBucket, message, locations, rules.Fingerprint(sarifRuleUnusedBucket, account, bucket, ""))
+		case analyzer.StatusReplicationDestination:
+			message := fallbackMessage(analysis.Message, sarifRuleReplicationDestination)
+			locations := locationsWithFallback(bucketRefs[bucket], s3URI(bucket))
+			results = appendResult(results, usedRules, sarifRuleReplicationDestination, message, locations, rules.Fingerprint(sarifRuleReplicationDestination, account, bucket, ""))
 		case analyzer.StatusVersionSprawl:
 			message := fallbackMessage(analysis.Message, sarifRuleVersionSprawl)
 			locations := locationsWithFallback(bucketRefs[bucket], s3URI(bucket))
-			results = appendResult(results, usedRules, sarifRuleVersionSprawl, message, locations)
+			results = appendResult(results, usedRules, sarifRuleVersionSprawl, message, locations, rules.Fingerprint(sarifRuleVersionSprawl, account, bucket, ""))
 		case analyzer.StatusLifecycleMisconfig:
 			message := fallbackMessage(analysis.Message, sarifRuleLifecycleGap)
 			locations := locationsWithFallback(bucketRefs[bucket], s3URI(bucket))
-			results = appendResult(results, usedRules, sarifRuleLifecycleGap, message, locations)
+			results = appendResult(results, usedRules, sarifRuleLifecycleGap, message, locations, rules.Fingerprint(sarifRuleLifecycleGap, account, bucket, ""))
 		}
+		rankResults(results[n:], analysis.Confidence)
+		blameResults(results[n:], analysis.Blame)
 
 		if len(analysis.Prefixes) == 0 {
 			continue
@@ -197,22 +167,71 @@ func (r *SARIFReporter) Generate(data Data) error {
 		})
 
 		for _, prefix := range prefixes {
+			n := len(results)
 			switch prefix.Status {
 			case analyzer.StatusMissingPrefix:
 				message := fallbackMessage(prefix.Message, sarifRuleMissingPrefix)
 				locations := locationsWithFallback(prefixRefs[bucket][prefix.Prefix], s3URI(bucket, prefix.Prefix))
-				results = appendResult(results, usedRules, sarifRuleMissingPrefix, message, locations)
+				results = appendResult(results, usedRules, sarifRuleMissingPrefix, message, locations, rules.Fingerprint(sarifRuleMissingPrefix, account, bucket, prefix.Prefix))
 			case analyzer.StatusStalePrefix:
 				message := fallbackMessage(prefix.Message, sarifRuleStalePrefix)
 				locations := locationsWithFallback(prefixRefs[bucket][prefix.Prefix], s3URI(bucket, prefix.Prefix))
-				results = appendResult(results, usedRules, sarifRuleStalePrefix, message, locations)
+				results = appendResult(results, usedRules, sarifRuleStalePrefix, message, locations, rules.Fingerprint(sarifRuleStalePrefix, account, bucket, prefix.Prefix))
+			case analyzer.StatusPrefixNearMiss:
+				message := fallbackMessage(prefix.Message, sarifRuleNearMiss)
+				locations := locationsWithFallback(prefixRefs[bucket][prefix.Prefix], s3URI(bucket, prefix.Prefix))
+				results = appendResult(results, usedRules, sarifRuleNearMiss, message, locations, rules.Fingerprint(sarifRuleNearMiss, account, bucket, prefix.Prefix))
 			}
+			rankResults(results[n:], prefix.Confidence)
+			blameResults(results[n:], prefix.Blame)
 		}
 	}
 
+	results = appendVaultResults(results, usedRules, data)
+	for _, p := range data.Permissions {
+		locations := locationsWithFallback([]scanner.Reference{{File: p.File, Line: p.Line}}, s3URI(p.Bucket, p.Prefix))
+		fingerprint := rules.Fingerprint(string(p.Status), data.Config.AccountID, p.Bucket, p.Key())
+		results = appendResult(results, usedRules, sarifRulePermissionGap, fallbackMessage(p.Message, sarifRulePermissionGap), locations, fingerprint)
+	}
+	for _, c := range data.Correlations {
+		results = appendCorrelationResult(results, usedRules, c, data.Config.AccountID)
+	}
+
 	return r.writeSARIF(data.Tool, data.Version, results, usedRules)
 }
 
+// appendVaultResults adds Glacier vault findings. Missing vaults point at
+// their code references; orphaned vaults at a glacier:// URI.
+func appendVaultResults(results []sarifResult, usedRules map[string]sarifRule, data Data) []sarifResult {
+	vaultRefs := make(map[string][]scanner.Reference)
+	for _, ref := range data.VaultReferences {
+		vaultRefs[ref.Vault] = append(vaultRefs[ref.Vault], scanner.Reference{File: ref.File, Line: ref.Line})
+	}
+
+	names := make([]string, 0, len(data.Vaults))
+	for name := range data.Vaults {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		vault := data.Vaults[name]
+		if vault == nil {
+			continue
+		}
+		fingerprint := rules.Fingerprint(string(vault.Status), data.Config.AccountID, name, "")
+		switch vault.Status {
+		case analyzer.StatusMissingVault:
+			locations := locationsWithFallback(vaultRefs[vault.Name], "glacier://"+vault.Name)
+			results = appendResult(results, usedRules, sarifRuleMissingVault, fallbackMessage(vault.Message, sarifRuleMissingVault), locations, fingerprint)
+		case analyzer.StatusOrphanedVault:
+			locations := locationsWithFallback(nil, "glacier://"+vault.Name)
+			results = appendResult(results, usedRules, sarifRuleOrphanedVault, fallbackMessage(vault.Message, sarifRuleOrphanedVault), locations, fingerprint)
+		}
+	}
+	return results
+}
+
 func (r *SARIFReporter) GenerateDiscovery(data DiscoveryData) error {
 	var results []sarifResult
 	usedRules := make(map[string]sarifRule)
@@ -230,31 +249,64 @@ func (r *SARIFReporter) GenerateDiscovery(data DiscoveryData) error {
 		}
 
 		locations := locationsWithFallback(nil, s3URI(bucket))
+		account := BucketAccount(data, discovery)
 
+		n := len(results)
 		switch discovery.Status {
 		case analyzer.StatusUnusedBucket:
 			message := discoveryStatusMessage(discovery, "Bucket appears unused")
-			results = appendResult(results, usedRules, sarifRuleUnusedBucket, message, locations)
+			results = appendResult(results, usedRules, sarifRuleUnusedBucket, message, locations, rules.Fingerprint(sarifRuleUnusedBucket, account, bucket, ""))
 		case analyzer.StatusRisky:
 			message := discoveryStatusMessage(discovery, "Bucket risk score exceeds the threshold")
-			results = appendResult(results, usedRules, sarifRuleRiskyBucket, message, locations)
+			results = appendResult(results, usedRules, sarifRuleRiskyBucket, message, locations, rules.Fingerprint(sarifRuleRiskyBucket, account, bucket, ""))
 		case analyzer.StatusInactive:
 			message := discoveryStatusMessage(discovery, "Bucket has been inactive")
-			results = appendResult(results, usedRules, sarifRuleInactiveBucket, message, locations)
+			results = appendResult(results, usedRules, sarifRuleInactiveBucket, message, locations, rules.Fingerprint(sarifRuleInactiveBucket, account, bucket, ""))
+		case analyzer.StatusReplicationDestination:
+			message := discoveryStatusMessage(discovery, "Bucket is a replication destination")
+			results = appendResult(results, usedRules, sarifRuleReplicationDestination, message, locations, rules.Fingerprint(sarifRuleReplicationDestination, account, bucket, ""))
 		case analyzer.StatusVersionSprawl:
 			message := discoveryStatusMessage(discovery, "Versioning enabled without lifecycle rules")
-			results = appendResult(results, usedRules, sarifRuleVersionSprawl, message, locations)
+			results = appendResult(results, usedRules, sarifRuleVersionSprawl, message, locations, rules.Fingerprint(sarifRuleVersionSprawl, account, bucket, ""))
 		}
+		rankResults(results[n:], discovery.Confidence)
 
 		if data.Config.CheckPublicAccess && discovery.BucketInfo != nil && discovery.BucketInfo.PublicAccess != nil && discovery.BucketInfo.PublicAccess.IsPublic {
 			message := fallbackMessage("", sarifRulePublicBucket)
-			results = appendResult(results, usedRules, sarifRulePublicBucket, message, locations)
+			results = appendResult(results, usedRules, sarifRulePublicBucket, message, locations, rules.Fingerprint(sarifRulePublicBucket, account, bucket, ""))
 		}
 
 		if data.Config.CheckEncryption && discovery.BucketInfo != nil && discovery.BucketInfo.Encryption != nil && !discovery.BucketInfo.Encryption.Enabled {
 			message := fallbackMessage("", sarifRuleNoEncryption)
-			results = appendResult(results, usedRules, sarifRuleNoEncryption, message, locations)
+			results = appendResult(results, usedRules, sarifRuleNoEncryption, message, locations, rules.Fingerprint(sarifRuleNoEncryption, account, bucket, ""))
+		}
+
+		for _, f := range discovery.PolicyFindings {
+			rule, ok := rules.ForStatus(string(f.Status))
+			if !ok {
+				continue
+			}
+			ruleID := rule.SARIFID()
+			results = appendResult(results, usedRules, ruleID, fallbackMessage(f.Message, ruleID), locations, rules.Fingerprint(string(f.Status), account, bucket, ""))
+		}
+
+		for _, cf := range discovery.CustomFindings {
+			results = appendCustomResult(results, usedRules, cf, locations, rules.Fingerprint(cf.RuleID(), account, bucket, ""))
+		}
+	}
+
+	for _, f := range data.AccountFindings {
+		rule, ok := rules.ForStatus(string(f.Status))
+		if !ok {
+			continue
 		}
+		ruleID := rule.SARIFID()
+		locations := locationsWithFallback(nil, AccountFindingLocation(f))
+		fingerprint := rules.Fingerprint(string(f.Status), FindingAccount(data, f), f.Region, f.Family)
+		results = appendResult(results, usedRules, ruleID, fallbackMessage(f.Message, ruleID), locations, fingerprint)
+	}
+	for _, c := range data.Correlations {
+		results = appendCorrelationResult(results, usedRules, c, CorrelationAccount(data, c))
 	}
 
 	return r.writeSARIF(data.Tool, data.Version, results, usedRules)
@@ -292,14 +344,9 @@ func (r *SARIFReporter) writeSARIF(toolName, toolVersion string, results []sarif
 	return encoder.Encode(log)
 }
 
-func appendResult(results []sarifResult, usedRules map[string]sarifRule, ruleID, message string, locations []sarifLocation) []sarifResult {
-	rule := sarifRule{ID: ruleID}
-	level := "warning"
-	if meta, ok := sarifRules[ruleID]; ok {
-		rule.Name = meta.Name
-		rule.ShortDescription = sarifMessage{Text: meta.Description}
-		level = meta.Level
-	}
+func appendResult(results []sarifResult, usedRules map[string]sarifRule, ruleID, message string, locations []sarifLocation, fingerprint string) []sarifResult {
+	rule := newSARIFRule(ruleID)
+	level := rules.SARIFLevel(ruleSeverity(ruleID))
 	if message == "" {
 		message = rule.ShortDescription.Text
 	}
@@ -308,25 +355,104 @@ func appendResult(results []sarifResult, usedRules map[string]sarifRule, ruleID,
 	}
 
 	results = append(results, sarifResult{
-		RuleID:    ruleID,
-		Level:     level,
-		Message:   sarifMessage{Text: message},
-		Locations: locations,
+		RuleID:              ruleID,
+		Level:               level,
+		Message:             sarifMessage{Text: message},
+		Locations:           locations,
+		PartialFingerprints: sarifFingerprints(fingerprint),
 	})
 
 	return results
 }
 
+// appendCorrelationResult adds a correlation at the bucket's s3:// URI, at
+// the level of the correlation's own severity
+func appendCorrelationResult(results []sarifResult, usedRules map[string]sarifRule, c analyzer.Correlation, account string) []sarifResult {
+	rule, _ := rules.Lookup(rules.Correlated)
+	fingerprint := rules.Fingerprint(string(c.Status), account, c.Bucket, c.Key())
+	results = appendResult(results, usedRules, rule.SARIFID(), c.Message, locationsWithFallback(nil, s3URI(c.Bucket)), fingerprint)
+	results[len(results)-1].Level = rules.SARIFLevel(rules.CustomSeverity(rules.Correlated, c.Severity))
+	return results
+}
+
+// rankResults sets the SARIF rank of results from a finding's confidence
+func rankResults(results []sarifResult, c *analyzer.Confidence) {
+	if c == nil {
+		return
+	}
+	for i := range results {
+		rank := float64(c.Score)
+		results[i].Rank = &rank
+	}
+}
+
+// blameResults records the finding's last commit in the results' properties
+func blameResults(results []sarifResult, b *scanner.Blame) {
+	for i := range results {
+		results[i].Properties = withBlame(results[i].Properties, b)
+	}
+}
+
+// appendCustomResult adds a plugin finding under a plugin-scoped rule ID
+func appendCustomResult(results []sarifResult, usedRules map[string]sarifRule, cf analyzer.CustomFinding, locations []sarifLocation, fingerprint string) []sarifResult {
+	ruleID := cf.RuleID()
+	if _, exists := usedRules[ruleID]; !exists {
+		usedRules[ruleID] = sarifRule{
+			ID:               ruleID,
+			Name:             cf.Rule,
+			ShortDescription: sarifMessage{Text: "Reported by plugin " + cf.Plugin},
+		}
+	}
+	message := cf.Message
+	if message == "" {
+		message = cf.Rule
+	}
+	return append(results, sarifResult{
+		RuleID:              ruleID,
+		Level:               rules.SARIFLevel(rules.CustomSeverity(cf.Rule, cf.Severity)),
+		Message:             sarifMessage{Text: message},
+		Locations:           locations,
+		PartialFingerprints: sarifFingerprints(fingerprint),
+	})
+}
+
 func fallbackMessage(message, ruleID string) string {
 	if message != "" {
 		return message
 	}
-	if meta, ok := sarifRules[ruleID]; ok {
-		return meta.Description
+	if rule, ok := rules.Lookup(ruleID); ok {
+		return rule.Description
 	}
 	return message
 }
 
+// sarifFingerprintKey names s3spectre's entry in result partialFingerprints
+const sarifFingerprintKey = "s3spectreFingerprint/v1"
+
+func sarifFingerprints(fingerprint string) map[string]string {
+	if fingerprint == "" {
+		return nil
+	}
+	return map[string]string{sarifFingerprintKey: fingerprint}
+}
+
+// newSARIFRule describes a built-in rule, with help text from the rule registry
+func newSARIFRule(ruleID string) sarifRule {
+	rule := sarifRule{ID: ruleID}
+	meta, ok := rules.Lookup(ruleID)
+	if !ok {
+		return rule
+	}
+	rule.Name = meta.Name
+	rule.ShortDescription = sarifMessage{Text: meta.Description}
+	rule.FullDescription = &sarifMessage{Text: meta.Rationale}
+	rule.Help = &sarifHelp{Text: meta.HelpText(), Markdown: meta.HelpMarkdown()}
+	if len(meta.References) > 0 {
+		rule.HelpURI = meta.References[0]
+	}
+	return rule
+}
+
 func collectReferences(refs []scanner.Reference) (map[string][]scanner.Reference, map[string]map[string][]scanner.Reference) {
 	bucketRefs := make(map[string][]scanner.Reference)
 	prefixRefs := make(map[string]map[string][]scanner.Reference)
@@ -441,3 +567,12 @@ func discoveryStatusMessage(discovery *analyzer.BucketDiscovery, base string) st
 	}
 	return message
 }
+
+// ruleSeverity returns a built-in rule's severity after overrides, defaulting
+// to medium for IDs outside the registry
+func ruleSeverity(ruleID string) string {
+	if rule, ok := rules.Lookup(ruleID); ok {
+		return rule.Severity()
+	}
+	return rules.SeverityMedium
+}
@@ -3,18 +3,22 @@ package report
 import (
 	"bytes"
 	"encoding/json"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/ppiankov/s3spectre/internal/analyzer"
+	"github.com/ppiankov/s3spectre/internal/rules"
 	"github.com/ppiankov/s3spectre/internal/s3"
 	"github.com/ppiankov/s3spectre/internal/scanner"
 )
 
 type sarifResultOutput struct {
-	RuleID  string `json:"ruleId"`
-	Level   string `json:"level"`
-	Message struct {
+	RuleID              string            `json:"ruleId"`
+	Level               string            `json:"level"`
+	PartialFingerprints map[string]string `json:"partialFingerprints"`
+	Rank                *float64          `json:"rank"`
+	Message             struct {
 		Text string `json:"text"`
 	} `json:"message"`
 	Locations []struct {
@@ -125,6 +129,9 @@ func TestSARIFReporter_Generate(t *testing.T) {
 	if missing.Level != "warning" {
 		t.Fatalf("expected missing bucket level warning, got %q", missing.Level)
 	}
+	if missing.PartialFingerprints[sarifFingerprintKey] == "" {
+		t.Fatalf("expected missing bucket to carry a fingerprint, got %v", missing.PartialFingerprints)
+	}
 	if len(missing.Locations) == 0 || missing.Locations[0].PhysicalLocation == nil {
 		t.Fatalf("expected missing bucket to include a location")
 	}
@@ -215,3 +222,139 @@ func findResult(results []sarifResultOutput, ruleID string) (sarifResultOutput,
 	}
 	return sarifResultOutput{}, false
 }
+
+func TestSARIFRuleHelp(t *testing.T) {
+	var buf bytes.Buffer
+	reporter := NewSARIFReporter(&buf)
+	data := DiscoveryData{
+		Tool: "s3spectre",
+		Buckets: map[string]*analyzer.BucketDiscovery{
+			"old": {Name: "old", Status: analyzer.StatusVersionSprawl},
+		},
+	}
+	if err := reporter.GenerateDiscovery(data); err != nil {
+		t.Fatalf("GenerateDiscovery: %v", err)
+	}
+
+	var out struct {
+		Runs []struct {
+			Tool struct {
+				Driver struct {
+					Rules []struct {
+						ID   string `json:"id"`
+						Help struct {
+							Text     string `json:"text"`
+							Markdown string `json:"markdown"`
+						} `json:"help"`
+						HelpURI string `json:"helpUri"`
+					} `json:"rules"`
+				} `json:"driver"`
+			} `json:"tool"`
+		} `json:"runs"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &out); err != nil {
+		t.Fatalf("invalid SARIF: %v", err)
+	}
+	rules := out.Runs[0].Tool.Driver.Rules
+	if len(rules) != 1 || rules[0].ID != sarifRuleVersionSprawl {
+		t.Fatalf("expected one VERSION_SPRAWL rule, got %+v", rules)
+	}
+	if !strings.Contains(rules[0].Help.Text, "NoncurrentVersionExpiration") || !strings.Contains(rules[0].Help.Markdown, "**Remediation:**") {
+		t.Fatalf("expected rule help from the registry, got %+v", rules[0].Help)
+	}
+	if !strings.HasPrefix(rules[0].HelpURI, "https://docs.aws.amazon.com/") {
+		t.Fatalf("expected AWS docs help URI, got %q", rules[0].HelpURI)
+	}
+}
+
+func TestSARIFReporter_AccountFindings(t *testing.T) {
+	var buf bytes.Buffer
+	data := DiscoveryData{
+		Tool:    "s3spectre",
+		Buckets: map[string]*analyzer.BucketDiscovery{},
+		AccountFindings: []analyzer.AccountAnalysis{
+			{Status: analyzer.StatusNoGuardDutyS3, Region: "eu-west-1", Message: "GuardDuty is not enabled in this region"},
+			{Status: analyzer.StatusNoAccountPAB, Message: "Account has no S3 Block Public Access configuration"},
+		},
+	}
+	if err := NewSARIFReporter(&buf).GenerateDiscovery(data); err != nil {
+		t.Fatalf("GenerateDiscovery failed: %v", err)
+	}
+
+	var decoded sarifOutput
+	if err := json.Unmarshal(buf.Bytes(), &decoded); err != nil {
+		t.Fatalf("failed to unmarshal output: %v", err)
+	}
+	result, ok := findResult(decoded.Runs[0].Results, sarifRuleNoGuardDutyS3)
+	if !ok {
+		t.Fatalf("missing result for %s", sarifRuleNoGuardDutyS3)
+	}
+	if uri := result.Locations[0].PhysicalLocation.ArtifactLocation.URI; uri != "guardduty://eu-west-1" {
+		t.Fatalf("expected guardduty:// location, got %s", uri)
+	}
+	result, ok = findResult(decoded.Runs[0].Results, rules.SARIFPrefix+rules.NoAccountPAB)
+	if !ok {
+		t.Fatalf("missing result for %s", rules.NoAccountPAB)
+	}
+	if uri := result.Locations[0].PhysicalLocation.ArtifactLocation.URI; uri != "account://public-access-block" {
+		t.Fatalf("expected account:// location, got %s", uri)
+	}
+}
+
+func TestSARIFReporter_PolicyFindings(t *testing.T) {
+	var buf bytes.Buffer
+	data := DiscoveryData{
+		Tool: "s3spectre",
+		Buckets: map[string]*analyzer.BucketDiscovery{
+			"site": {Name: "site", Status: analyzer.StatusOK, PolicyFindings: []analyzer.PolicyFinding{
+				{Status: analyzer.StatusCrossAccount, Message: "Bucket policy grants access to other accounts: 222222222222"},
+			}},
+		},
+	}
+	if err := NewSARIFReporter(&buf).GenerateDiscovery(data); err != nil {
+		t.Fatalf("GenerateDiscovery failed: %v", err)
+	}
+
+	var decoded sarifOutput
+	if err := json.Unmarshal(buf.Bytes(), &decoded); err != nil {
+		t.Fatalf("failed to unmarshal output: %v", err)
+	}
+	result, ok := findResult(decoded.Runs[0].Results, rules.SARIFPrefix+rules.CrossAccount)
+	if !ok {
+		t.Fatalf("missing result for %s", rules.CrossAccount)
+	}
+	if uri := result.Locations[0].PhysicalLocation.ArtifactLocation.URI; uri != "s3://site" {
+		t.Fatalf("expected the bucket location, got %s", uri)
+	}
+	if !strings.Contains(result.Message.Text, "222222222222") {
+		t.Fatalf("expected the finding message, got %q", result.Message.Text)
+	}
+	if len(decoded.Runs[0].Tool.Driver.Rules) != 1 {
+		t.Fatalf("expected one rule, got %+v", decoded.Runs[0].Tool.Driver.Rules)
+	}
+}
+
+func TestSARIFReporter_Rank(t *testing.T) {
+	var buf bytes.Buffer
+	data := DiscoveryData{
+		Tool: "s3spectre",
+		Buckets: map[string]*analyzer.BucketDiscovery{
+			"old-logs": {Name: "old-logs", Status: analyzer.StatusInactive, Confidence: &analyzer.Confidence{Score: 70, Level: analyzer.ConfidenceMedium}},
+		},
+	}
+	if err := NewSARIFReporter(&buf).GenerateDiscovery(data); err != nil {
+		t.Fatalf("GenerateDiscovery failed: %v", err)
+	}
+
+	var decoded sarifOutput
+	if err := json.Unmarshal(buf.Bytes(), &decoded); err != nil {
+		t.Fatalf("failed to unmarshal output: %v", err)
+	}
+	result, ok := findResult(decoded.Runs[0].Results, sarifRuleInactiveBucket)
+	if !ok {
+		t.Fatalf("missing result for %s", sarifRuleInactiveBucket)
+	}
+	if result.Rank == nil || *result.Rank != 70 {
+		t.Fatalf("expected rank 70, got %v", result.Rank)
+	}
+}
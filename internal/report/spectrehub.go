@@ -5,8 +5,10 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"sort"
 
 	"github.com/ppiankov/s3spectre/internal/analyzer"
+	"github.com/ppiankov/s3spectre/internal/rules"
 )
 
 // spectre/v1 envelope types
@@ -27,11 +29,12 @@ type spectreTarget struct {
 }
 
 type spectreFinding struct {
-	ID       string         `json:"id"`
-	Severity string         `json:"severity"`
-	Location string         `json:"location"`
-	Message  string         `json:"message"`
-	Metadata map[string]any `json:"metadata,omitempty"`
+	ID          string         `json:"id"`
+	Severity    string         `json:"severity"`
+	Location    string         `json:"location"`
+	Message     string         `json:"message"`
+	Fingerprint string         `json:"fingerprint"`
+	Metadata    map[string]any `json:"metadata,omitempty"`
 }
 
 type spectreSummary struct {
@@ -76,12 +79,14 @@ func (r *SpectreHubReporter) Generate(data Data) error {
 		if bucket.Status == analyzer.StatusOK {
 			continue
 		}
-		severity := scanStatusSeverity(bucket.Status)
+		severity := scanHubSeverity(bucket.Status)
 		envelope.Findings = append(envelope.Findings, spectreFinding{
-			ID:       string(bucket.Status),
-			Severity: severity,
-			Location: name,
-			Message:  bucket.Message,
+			ID:          string(bucket.Status),
+			Severity:    severity,
+			Location:    name,
+			Message:     bucket.Message,
+			Fingerprint: rules.Fingerprint(string(bucket.Status), AnalysisAccount(data, bucket), name, ""),
+			Metadata:    withBlame(withConfidence(nil, bucket.Confidence), bucket.Blame),
 		})
 		countSeverity(&envelope.Summary, severity)
 
@@ -90,18 +95,66 @@ func (r *SpectreHubReporter) Generate(data Data) error {
 			if p.Status == analyzer.StatusOK {
 				continue
 			}
-			psev := scanStatusSeverity(p.Status)
+			psev := scanHubSeverity(p.Status)
 			loc := name + "/" + p.Prefix
 			envelope.Findings = append(envelope.Findings, spectreFinding{
-				ID:       string(p.Status),
-				Severity: psev,
-				Location: loc,
-				Message:  p.Message,
+				ID:          string(p.Status),
+				Severity:    psev,
+				Location:    loc,
+				Message:     p.Message,
+				Fingerprint: rules.Fingerprint(string(p.Status), AnalysisAccount(data, bucket), name, p.Prefix),
+				Metadata:    withBlame(withConfidence(nil, p.Confidence), p.Blame),
 			})
 			countSeverity(&envelope.Summary, psev)
 		}
 	}
 
+	for name, vault := range data.Vaults {
+		if vault.Status == analyzer.StatusOK {
+			continue
+		}
+		severity := scanHubSeverity(vault.Status)
+		envelope.Findings = append(envelope.Findings, spectreFinding{
+			ID:          string(vault.Status),
+			Severity:    severity,
+			Location:    "glacier://" + vault.Name,
+			Message:     vault.Message,
+			Fingerprint: rules.Fingerprint(string(vault.Status), data.Config.AccountID, name, ""),
+			Metadata: map[string]any{
+				"region":             vault.Region,
+				"number_of_archives": vault.NumberOfArchives,
+				"size_in_bytes":      vault.SizeInBytes,
+			},
+		})
+		countSeverity(&envelope.Summary, severity)
+	}
+
+	for _, p := range data.Permissions {
+		severity := scanHubSeverity(p.Status)
+		principals := make([]string, 0, len(p.Denied))
+		for _, d := range p.Denied {
+			principals = append(principals, d.Principal)
+		}
+		envelope.Findings = append(envelope.Findings, spectreFinding{
+			ID:          string(p.Status),
+			Severity:    severity,
+			Location:    p.Path(),
+			Message:     p.Message,
+			Fingerprint: rules.Fingerprint(string(p.Status), data.Config.AccountID, p.Bucket, p.Key()),
+			Metadata: map[string]any{
+				"action":     p.Action,
+				"principals": principals,
+			},
+		})
+		countSeverity(&envelope.Summary, severity)
+	}
+	for _, c := range data.Correlations {
+		finding := correlationFinding(c, data.Config.AccountID)
+		envelope.Findings = append(envelope.Findings, finding)
+		countSeverity(&envelope.Summary, finding.Severity)
+	}
+
+	sortSpectreFindings(envelope.Findings)
 	envelope.Summary.Total = len(envelope.Findings)
 	if envelope.Findings == nil {
 		envelope.Findings = []spectreFinding{}
@@ -129,21 +182,68 @@ func (r *SpectreHubReporter) GenerateDiscovery(data DiscoveryData) error {
 		if bucket.Status == analyzer.StatusOK {
 			continue
 		}
-		severity := discoveryStatusSeverity(bucket.Status, bucket.RiskScore)
+		severity := discoveryHubSeverity(bucket.Status, bucket.RiskScore)
 		envelope.Findings = append(envelope.Findings, spectreFinding{
-			ID:       string(bucket.Status),
-			Severity: severity,
-			Location: name,
-			Message:  fmt.Sprintf("risk score %d: %v", bucket.RiskScore, bucket.RiskFactors),
-			Metadata: map[string]any{
+			ID:          string(bucket.Status),
+			Severity:    severity,
+			Location:    name,
+			Message:     fmt.Sprintf("risk score %d: %v", bucket.RiskScore, bucket.RiskFactors),
+			Fingerprint: rules.Fingerprint(string(bucket.Status), BucketAccount(data, bucket), name, ""),
+			Metadata: withConfidence(map[string]any{
 				"risk_score":      bucket.RiskScore,
 				"region":          bucket.Region,
 				"recommendations": bucket.Recommendations,
-			},
+			}, bucket.Confidence),
 		})
 		countSeverity(&envelope.Summary, severity)
 	}
 
+	for name, bucket := range data.Buckets {
+		for _, f := range bucket.PolicyFindings {
+			severity := discoveryHubSeverity(f.Status, 0)
+			envelope.Findings = append(envelope.Findings, spectreFinding{
+				ID:          string(f.Status),
+				Severity:    severity,
+				Location:    name,
+				Message:     f.Message,
+				Fingerprint: rules.Fingerprint(string(f.Status), BucketAccount(data, bucket), name, ""),
+				Metadata:    map[string]any{"region": bucket.Region},
+			})
+			countSeverity(&envelope.Summary, severity)
+		}
+		for _, cf := range bucket.CustomFindings {
+			severity := rules.CustomSeverity(cf.Rule, cf.Severity)
+			envelope.Findings = append(envelope.Findings, spectreFinding{
+				ID:          cf.Rule,
+				Severity:    severity,
+				Location:    name,
+				Message:     cf.Message,
+				Fingerprint: rules.Fingerprint(cf.RuleID(), BucketAccount(data, bucket), name, ""),
+				Metadata:    map[string]any{"plugin": cf.Plugin},
+			})
+			countSeverity(&envelope.Summary, severity)
+		}
+	}
+
+	for _, f := range data.AccountFindings {
+		severity := discoveryHubSeverity(f.Status, 0)
+		envelope.Findings = append(envelope.Findings, spectreFinding{
+			ID:          string(f.Status),
+			Severity:    severity,
+			Location:    AccountFindingLocation(f),
+			Message:     f.Message,
+			Fingerprint: rules.Fingerprint(string(f.Status), FindingAccount(data, f), f.Region, f.Family),
+			Metadata:    accountMetadata(f),
+		})
+		countSeverity(&envelope.Summary, severity)
+	}
+	for _, c := range data.Correlations {
+		finding := correlationFinding(c, CorrelationAccount(data, c))
+		envelope.Findings = append(envelope.Findings, finding)
+		countSeverity(&envelope.Summary, finding.Severity)
+	}
+
+	sortSpectreFindings(envelope.Findings)
 	envelope.Summary.Total = len(envelope.Findings)
 	if envelope.Findings == nil {
 		envelope.Findings = []spectreFinding{}
@@ -154,37 +254,45 @@ func (r *SpectreHubReporter) GenerateDiscovery(data DiscoveryData) error {
 	return enc.Encode(envelope)
 }
 
-func scanStatusSeverity(status analyzer.Status) string {
-	switch status {
-	case analyzer.StatusMissingBucket:
-		return "high"
-	case analyzer.StatusUnusedBucket, analyzer.StatusLifecycleMisconfig:
-		return "medium"
-	case analyzer.StatusMissingPrefix, analyzer.StatusVersionSprawl:
-		return "medium"
-	case analyzer.StatusStalePrefix:
-		return "low"
-	default:
-		return "info"
+// sortSpectreFindings orders findings by location and rule, since they are
+// collected from maps
+func sortSpectreFindings(findings []spectreFinding) {
+	sort.SliceStable(findings, func(i, j int) bool {
+		if findings[i].Location != findings[j].Location {
+			return findings[i].Location < findings[j].Location
+		}
+		return findings[i].ID < findings[j].ID
+	})
+}
+
+// scanHubSeverity returns the SpectreHub severity of a status in a scan
+// report: its override, or the rule's scan hub severity
+func scanHubSeverity(status analyzer.Status) string {
+	rule, ok := rules.ForStatus(string(status))
+	if !ok {
+		return rules.SeverityInfo
 	}
+	if sev, ok := rules.Override(rule.ID); ok {
+		return sev
+	}
+	return rule.ScanHubSeverity
 }
 
-func discoveryStatusSeverity(status analyzer.Status, riskScore int) string {
-	switch status {
-	case analyzer.StatusRisky:
-		if riskScore >= 80 {
-			return "high"
-		}
-		return "medium"
-	case analyzer.StatusUnusedBucket:
-		return "medium"
-	case analyzer.StatusInactive:
-		return "low"
-	case analyzer.StatusVersionSprawl:
-		return "low"
-	default:
-		return "info"
+// discoveryHubSeverity returns the SpectreHub severity of a status in a
+// discover report: its override, or the rule's discovery hub severity with
+// risky buckets escalated to high at a risk score of 80
+func discoveryHubSeverity(status analyzer.Status, riskScore int) string {
+	rule, ok := rules.ForStatus(string(status))
+	if !ok {
+		return rules.SeverityInfo
 	}
+	if sev, ok := rules.Override(rule.ID); ok {
+		return sev
+	}
+	if status == analyzer.StatusRisky && riskScore >= 80 {
+		return rules.SeverityHigh
+	}
+	return rule.DiscoveryHubSeverity
 }
 
 func countSeverity(s *spectreSummary, severity string) {
@@ -199,3 +307,57 @@ func countSeverity(s *spectreSummary, severity string) {
 		s.Info++
 	}
 }
+
+// correlationFinding is the envelope finding of a correlation, whose
+// metadata names the other tool's finding
+func correlationFinding(c analyzer.Correlation, account string) spectreFinding {
+	metadata := map[string]any{
+		"tool":     c.Tool,
+		"rule":     c.Rule,
+		"location": c.Location,
+		"findings": c.Findings,
+	}
+	if len(c.Actions) > 0 {
+		metadata["actions"] = c.Actions
+	}
+	return spectreFinding{
+		ID:          string(c.Status),
+		Severity:    rules.CustomSeverity(rules.Correlated, c.Severity),
+		Location:    c.Bucket,
+		Message:     c.Message,
+		Fingerprint: rules.Fingerprint(string(c.Status), account, c.Bucket, c.Key()),
+		Metadata:    metadata,
+	}
+}
+
+// accountMetadata carries an account finding's region and contributing
+// buckets, omitting whichever is empty
+func accountMetadata(f analyzer.AccountAnalysis) map[string]any {
+	metadata := map[string]any{}
+	if f.Account != "" {
+		metadata["account"] = f.Account
+	}
+	if f.Region != "" {
+		metadata["region"] = f.Region
+	}
+	if f.Family != "" {
+		metadata["family"] = f.Family
+	}
+	if len(f.Buckets) > 0 {
+		metadata["buckets"] = f.Buckets
+	}
+	return metadata
+}
+
+// withConfidence adds a finding's confidence score and level to its metadata
+func withConfidence(metadata map[string]any, c *analyzer.Confidence) map[string]any {
+	if c == nil {
+		return metadata
+	}
+	if metadata == nil {
+		metadata = map[string]any{}
+	}
+	metadata["confidence"] = c.Score
+	metadata["confidence_level"] = c.Level
+	return metadata
+}
@@ -7,6 +7,8 @@ import (
 	"time"
 
 	"github.com/ppiankov/s3spectre/internal/analyzer"
+	"github.com/ppiankov/s3spectre/internal/rules"
+	"github.com/ppiankov/s3spectre/internal/scanner"
 )
 
 func TestSpectreHubReporter_Generate(t *testing.T) {
@@ -138,6 +140,61 @@ func TestSpectreHubReporter_GenerateDiscovery(t *testing.T) {
 	}
 }
 
+func TestSpectreHubReporter_Blame(t *testing.T) {
+	data := Data{
+		Buckets: map[string]*analyzer.BucketAnalysis{
+			"gone": {Name: "gone", Status: analyzer.StatusMissingBucket, Blame: &scanner.Blame{Author: "Ada", AuthorEmail: "ada@example.com", Commit: "abc123"}},
+		},
+	}
+	var buf bytes.Buffer
+	if err := NewSpectreHubReporter(&buf).Generate(data); err != nil {
+		t.Fatalf("Generate: %v", err)
+	}
+	var envelope spectreEnvelope
+	if err := json.Unmarshal(buf.Bytes(), &envelope); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if len(envelope.Findings) != 1 {
+		t.Fatalf("findings = %d, want 1", len(envelope.Findings))
+	}
+	metadata := envelope.Findings[0].Metadata
+	if metadata["author"] != "Ada" || metadata["author_email"] != "ada@example.com" || metadata["commit"] != "abc123" {
+		t.Errorf("unexpected blame metadata: %v", metadata)
+	}
+}
+
+func TestSpectreHubReporter_Correlations(t *testing.T) {
+	data := DiscoveryData{
+		Config: DiscoveryConfig{AccountID: "111122223333"},
+		Buckets: map[string]*analyzer.BucketDiscovery{
+			"uploads": {Name: "uploads", Status: analyzer.StatusInactive},
+		},
+		Correlations: []analyzer.Correlation{{
+			Bucket: "uploads", Status: analyzer.StatusCorrelated, Findings: []analyzer.Status{analyzer.StatusInactive},
+			Tool: "iamspectre", Rule: "STALE_ROLE", Location: "arn:aws:iam::111122223333:role/etl",
+			Actions: []string{"s3:PutObject"}, Write: true, Severity: "high", Message: "iamspectre STALE_ROLE: ...",
+		}},
+	}
+	var buf bytes.Buffer
+	if err := NewSpectreHubReporter(&buf).GenerateDiscovery(data); err != nil {
+		t.Fatalf("GenerateDiscovery: %v", err)
+	}
+	var envelope spectreEnvelope
+	if err := json.Unmarshal(buf.Bytes(), &envelope); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if len(envelope.Findings) != 2 {
+		t.Fatalf("findings = %d, want the bucket and the correlation", len(envelope.Findings))
+	}
+	f := envelope.Findings[0]
+	if f.ID != "CORRELATED_FINDING" || f.Severity != "high" || f.Location != "uploads" || f.Metadata["tool"] != "iamspectre" {
+		t.Errorf("correlation finding = %+v", f)
+	}
+	if envelope.Summary.High != 1 {
+		t.Errorf("summary = %+v, want the correlation counted as high", envelope.Summary)
+	}
+}
+
 func TestSpectreHubReporter_EmptyFindings(t *testing.T) {
 	data := Data{
 		Tool:      "s3spectre",
@@ -181,3 +238,59 @@ func TestHashRegion(t *testing.T) {
 		t.Errorf("hash should start with sha256:, got %q", h1)
 	}
 }
+
+func TestHubSeverity(t *testing.T) {
+	// Pins the envelope severity of every status, so registry changes do not
+	// silently change what SpectreHub receives
+	tests := []struct {
+		status          string
+		scan, discovery string
+	}{
+		{rules.StatusMissingBucket, "high", "info"},
+		{rules.StatusUnusedBucket, "medium", "medium"},
+		{rules.StatusMissingPrefix, "medium", "info"},
+		{rules.StatusStalePrefix, "low", "info"},
+		{rules.StatusVersionSprawl, "medium", "low"},
+		{rules.StatusLifecycleMisconfig, "medium", "info"},
+		{rules.StatusRisky, "info", "medium"},
+		{rules.StatusInactive, "info", "low"},
+		{rules.StatusOrphanedVault, "medium", "medium"},
+		{rules.StatusMissingVault, "high", "high"},
+		{rules.StatusPermissionGap, "high", "high"},
+		{rules.StatusNoGuardDutyS3, "medium", "medium"},
+		{rules.StatusNoAccountPAB, "high", "high"},
+		{rules.StatusLowLifecycle, "low", "low"},
+		{rules.StatusPrefixNearMiss, "medium", "medium"},
+		{rules.StatusEnvDrift, "medium", "medium"},
+		{rules.StatusWildcardPrincipal, "high", "high"},
+		{rules.StatusCrossAccount, "medium", "medium"},
+		{rules.StatusInsecureTransport, "low", "low"},
+		{rules.StatusReplicationDestination, "info", "info"},
+		{rules.StatusNoLogging, "medium", "medium"},
+		{rules.StatusSensitiveDataExposed, "high", "high"},
+		{rules.StatusCorrelated, "medium", "medium"},
+		{rules.StatusTimedOut, "info", "info"},
+		{rules.StatusOK, "info", "info"},
+	}
+	pinned := make(map[string]bool)
+	for _, tt := range tests {
+		pinned[tt.status] = true
+	}
+	for _, rule := range rules.All() {
+		if rule.Status != "" && !pinned[rule.Status] {
+			t.Errorf("status %s has no pinned SpectreHub severity", rule.Status)
+		}
+	}
+	for _, tt := range tests {
+		status := analyzer.Status(tt.status)
+		if got := scanHubSeverity(status); got != tt.scan {
+			t.Errorf("scan severity of %s = %q, want %q", tt.status, got, tt.scan)
+		}
+		if got := discoveryHubSeverity(status, 0); got != tt.discovery {
+			t.Errorf("discovery severity of %s = %q, want %q", tt.status, got, tt.discovery)
+		}
+	}
+	if got := discoveryHubSeverity(analyzer.StatusRisky, 80); got != "high" {
+		t.Errorf("discovery severity of RISKY at score 80 = %q, want high", got)
+	}
+}
@@ -0,0 +1,78 @@
+package report
+
+import (
+	"time"
+
+	"github.com/ppiankov/s3spectre/internal/s3"
+	"github.com/ppiankov/s3spectre/internal/scanner"
+)
+
+// RunStats are per-run statistics for capacity planning, embedded in JSON
+// reports. Report generation itself is not included.
+type RunStats struct {
+	// FilesScanned, SkippedFiles and ReferencesByContext are set by scan
+	// mode. SkippedFiles counts the files not read by reason.
+	FilesScanned        int            `json:"files_scanned,omitempty"`
+	SkippedFiles        map[string]int `json:"skipped_files,omitempty"`
+	ReferencesByContext map[string]int `json:"references_by_context,omitempty"`
+	APICalls            s3.APIStats    `json:"api_calls"`
+	// RequestCost estimates what the API calls cost
+	RequestCost s3.RequestCost `json:"request_cost"`
+	// Checkpoint counts buckets reused from a resumed checkpoint
+	Checkpoint *CacheStats `json:"checkpoint,omitempty"`
+	// SlowestBuckets are the buckets that took longest to inspect
+	SlowestBuckets  []s3.BucketTiming `json:"slowest_buckets,omitempty"`
+	Phases          []Phase           `json:"phases"`
+	DurationSeconds float64           `json:"duration_seconds"`
+
+	start time.Time
+}
+
+// CacheStats counts buckets served from a checkpoint instead of inspected
+type CacheStats struct {
+	Hits    int     `json:"hits"`
+	Misses  int     `json:"misses"`
+	HitRate float64 `json:"hit_rate"`
+}
+
+// Phase is the wall-clock duration of one step of a run
+type Phase struct {
+	Name            string  `json:"name"`
+	DurationSeconds float64 `json:"duration_seconds"`
+}
+
+// NewRunStats starts timing a run
+func NewRunStats() *RunStats {
+	return &RunStats{start: time.Now()}
+}
+
+// Phase records a step that began at start and ends now
+func (s *RunStats) Phase(name string, start time.Time) {
+	s.Phases = append(s.Phases, Phase{Name: name, DurationSeconds: time.Since(start).Seconds()})
+}
+
+// CountReferences tallies references by operation context; references
+// without one count as unknown
+func (s *RunStats) CountReferences(refs []scanner.Reference) {
+	s.ReferencesByContext = make(map[string]int)
+	for _, ref := range refs {
+		op := ref.Context
+		if op == "" {
+			op = string(scanner.RefTypeUnknown)
+		}
+		s.ReferencesByContext[op]++
+	}
+}
+
+// Finish captures the client's API calls, the checkpoint lookups and the
+// total duration
+func (s *RunStats) Finish(client *s3.Client, checkpoint *s3.Checkpoint) {
+	if client != nil {
+		s.APICalls = client.APIStats()
+		s.RequestCost = s.APICalls.Cost()
+	}
+	if hits, misses := checkpoint.Lookups(); hits+misses > 0 {
+		s.Checkpoint = &CacheStats{Hits: hits, Misses: misses, HitRate: float64(hits) / float64(hits+misses)}
+	}
+	s.DurationSeconds = time.Since(s.start).Seconds()
+}
@@ -0,0 +1,59 @@
+package report
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ppiankov/s3spectre/internal/analyzer"
+	"github.com/ppiankov/s3spectre/internal/s3"
+	"github.com/ppiankov/s3spectre/internal/scanner"
+)
+
+func TestRunStats(t *testing.T) {
+	stats := NewRunStats()
+	stats.CountReferences([]scanner.Reference{
+		{Bucket: "a", Context: "read"},
+		{Bucket: "b", Context: "read"},
+		{Bucket: "c"},
+	})
+	stats.Phase("repository_scan", time.Now().Add(-2*time.Second))
+	stats.Finish(nil, nil)
+
+	if stats.ReferencesByContext["read"] != 2 || stats.ReferencesByContext["unknown"] != 1 {
+		t.Fatalf("unexpected references by context: %v", stats.ReferencesByContext)
+	}
+	if len(stats.Phases) != 1 || stats.Phases[0].DurationSeconds < 2 {
+		t.Fatalf("unexpected phases: %+v", stats.Phases)
+	}
+	if stats.Checkpoint != nil {
+		t.Fatalf("expected no checkpoint stats without a checkpoint, got %+v", stats.Checkpoint)
+	}
+
+	stats.Finish(nil, s3.NewCheckpoint("scan"))
+	if stats.Checkpoint != nil {
+		t.Fatalf("expected no checkpoint stats without lookups, got %+v", stats.Checkpoint)
+	}
+}
+
+func TestJSONReporter_Stats(t *testing.T) {
+	var buf strings.Builder
+	stats := NewRunStats()
+	stats.FilesScanned = 12
+	data := Data{Tool: "s3spectre", Buckets: map[string]*analyzer.BucketAnalysis{}, Stats: stats}
+	if err := NewJSONReporter(&buf).Generate(data); err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+	var out struct {
+		Stats struct {
+			FilesScanned int `json:"files_scanned"`
+		} `json:"stats"`
+	}
+	if err := json.Unmarshal([]byte(buf.String()), &out); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	if out.Stats.FilesScanned != 12 {
+		t.Fatalf("expected stats in report metadata, got %s", buf.String())
+	}
+}
@@ -0,0 +1,161 @@
+package report
+
+import (
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/ppiankov/s3spectre/internal/rules"
+)
+
+// TasksReporter writes recommendations as a flat, prioritized Markdown
+// checklist, one line per action, for pasting into sprint planning tools
+type TasksReporter struct {
+	writer io.Writer
+}
+
+// NewTasksReporter creates a new task list reporter
+func NewTasksReporter(w io.Writer) *TasksReporter {
+	return &TasksReporter{writer: w}
+}
+
+// Task is one recommended action
+type Task struct {
+	// Priority runs from P1 (high impact) to P4 (informational)
+	Priority string
+	Action   string
+	Target   string
+	// Impact is the finding's severity after overrides
+	Impact string
+	Effort string
+	Rule   string
+}
+
+var effortRank = map[string]int{
+	rules.EffortSmall:  1,
+	rules.EffortMedium: 2,
+	rules.EffortLarge:  3,
+}
+
+// Generate writes the scan's tasks
+func (r *TasksReporter) Generate(data Data) error {
+	var tasks []Task
+	for name, analysis := range data.Buckets {
+		if analysis == nil {
+			continue
+		}
+		tasks = appendRuleTask(tasks, string(analysis.Status), s3URI(name))
+		for _, p := range analysis.Prefixes {
+			tasks = appendRuleTask(tasks, string(p.Status), s3URI(name, p.Prefix))
+		}
+	}
+	for name, vault := range data.Vaults {
+		tasks = appendRuleTask(tasks, string(vault.Status), "glacier://"+name)
+	}
+	for _, p := range data.Permissions {
+		tasks = appendRuleTask(tasks, string(p.Status), fmt.Sprintf("%s (%s)", s3URI(p.Bucket, p.Prefix), p.Action))
+	}
+	return r.write(tasks)
+}
+
+// GenerateDiscovery writes the discovery's tasks
+func (r *TasksReporter) GenerateDiscovery(data DiscoveryData) error {
+	var tasks []Task
+	for name, discovery := range data.Buckets {
+		if discovery == nil {
+			continue
+		}
+		target := s3URI(name)
+		if discovery.Region != "" {
+			target += " (" + discovery.Region + ")"
+		}
+		tasks = appendRuleTask(tasks, string(discovery.Status), target)
+		if info := discovery.BucketInfo; info != nil {
+			if data.Config.CheckPublicAccess && info.PublicAccess != nil && info.PublicAccess.IsPublic {
+				tasks = appendTask(tasks, rules.PublicBucket, target)
+			}
+			if data.Config.CheckEncryption && info.Encryption != nil && !info.Encryption.Enabled {
+				tasks = appendTask(tasks, rules.NoEncryption, target)
+			}
+		}
+		for _, f := range discovery.PolicyFindings {
+			tasks = appendRuleTask(tasks, string(f.Status), target)
+		}
+		for _, cf := range discovery.CustomFindings {
+			action := cf.Message
+			if action == "" {
+				action = "Resolve " + cf.Rule
+			}
+			tasks = append(tasks, newTask(action, target, rules.CustomSeverity(cf.Rule, cf.Severity), rules.EffortMedium, cf.Plugin+"/"+cf.Rule))
+		}
+	}
+	for _, f := range data.AccountFindings {
+		tasks = appendRuleTask(tasks, string(f.Status), AccountFindingLocation(f))
+	}
+	return r.write(tasks)
+}
+
+// appendRuleTask adds the task for a finding status; OK adds nothing
+func appendRuleTask(tasks []Task, status, target string) []Task {
+	rule, ok := rules.ForStatus(status)
+	if !ok {
+		return tasks
+	}
+	return appendTask(tasks, rule.ID, target)
+}
+
+func appendTask(tasks []Task, ruleID, target string) []Task {
+	rule, ok := rules.Lookup(ruleID)
+	if !ok {
+		return tasks
+	}
+	return append(tasks, newTask(rule.Action, target, rule.Severity(), rule.Effort, rule.ID))
+}
+
+func newTask(action, target, impact, effort, rule string) Task {
+	priority := 5 - rules.Rank(impact)
+	if priority > 4 {
+		priority = 4
+	}
+	return Task{
+		Priority: fmt.Sprintf("P%d", priority),
+		Action:   action,
+		Target:   target,
+		Impact:   impact,
+		Effort:   effort,
+		Rule:     rule,
+	}
+}
+
+// SortTasks orders tasks by priority, then cheapest effort first, then by
+// rule and target
+func SortTasks(tasks []Task) {
+	sort.Slice(tasks, func(i, j int) bool {
+		a, b := tasks[i], tasks[j]
+		if a.Priority != b.Priority {
+			return a.Priority < b.Priority
+		}
+		if effortRank[a.Effort] != effortRank[b.Effort] {
+			return effortRank[a.Effort] < effortRank[b.Effort]
+		}
+		if a.Rule != b.Rule {
+			return a.Rule < b.Rule
+		}
+		return a.Target < b.Target
+	})
+}
+
+func (r *TasksReporter) write(tasks []Task) error {
+	if len(tasks) == 0 {
+		_, err := fmt.Fprintln(r.writer, "No recommended actions.")
+		return err
+	}
+	SortTasks(tasks)
+	for _, t := range tasks {
+		if _, err := fmt.Fprintf(r.writer, "- [ ] %s %s: %s (impact: %s, effort: %s) [%s]\n",
+			t.Priority, t.Action, t.Target, t.Impact, t.Effort, t.Rule); err != nil {
+			return err
+		}
+	}
+	return nil
+}
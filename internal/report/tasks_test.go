@@ -0,0 +1,86 @@
+package report
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/ppiankov/s3spectre/internal/analyzer"
+	"github.com/ppiankov/s3spectre/internal/s3"
+)
+
+func TestTasksReporter_Generate(t *testing.T) {
+	data := Data{
+		Buckets: map[string]*analyzer.BucketAnalysis{
+			"old-logs": {Status: analyzer.StatusUnusedBucket},
+			"gone":     {Status: analyzer.StatusMissingBucket},
+			"data": {Status: analyzer.StatusOK, Prefixes: []analyzer.PrefixAnalysis{
+				{Prefix: "2019/", Status: analyzer.StatusStalePrefix},
+				{Prefix: "live/", Status: analyzer.StatusOK},
+			}},
+		},
+	}
+	var buf bytes.Buffer
+	if err := NewTasksReporter(&buf).Generate(data); err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	want := []string{
+		"- [ ] P2 Create the bucket or remove its code references: s3://gone (impact: medium, effort: small) [MISSING_BUCKET]",
+		"- [ ] P3 Archive or expire the stale prefix: s3://data/2019/ (impact: low, effort: small) [STALE_PREFIX]",
+	}
+	if len(lines) != 3 {
+		t.Fatalf("expected 3 tasks, got %d:\n%s", len(lines), buf.String())
+	}
+	if lines[0] != want[0] {
+		t.Errorf("first task:\n got %s\nwant %s", lines[0], want[0])
+	}
+	if !strings.Contains(buf.String(), want[1]) {
+		t.Errorf("expected %q in output:\n%s", want[1], buf.String())
+	}
+}
+
+func TestTasksReporter_GenerateDiscovery(t *testing.T) {
+	data := DiscoveryData{
+		Config: DiscoveryConfig{CheckEncryption: true},
+		Buckets: map[string]*analyzer.BucketDiscovery{
+			"archive": {
+				Region: "eu-west-1",
+				Status: analyzer.StatusVersionSprawl,
+				BucketInfo: &s3.BucketInfo{
+					Encryption: &s3.EncryptionInfo{Enabled: false},
+				},
+				CustomFindings: []analyzer.CustomFinding{{Plugin: "tags", Rule: "NO_OWNER", Severity: "high", Message: "Tag the bucket with an owner"}},
+			},
+		},
+		AccountFindings: []analyzer.AccountAnalysis{{Status: analyzer.StatusNoAccountPAB}},
+	}
+	var buf bytes.Buffer
+	if err := NewTasksReporter(&buf).GenerateDiscovery(data); err != nil {
+		t.Fatalf("GenerateDiscovery failed: %v", err)
+	}
+	output := buf.String()
+	for _, want := range []string{
+		"P1 Tag the bucket with an owner: s3://archive (eu-west-1) (impact: high, effort: medium) [tags/NO_OWNER]",
+		"Enable default encryption: s3://archive (eu-west-1)",
+		"Add a noncurrent version expiration rule: s3://archive (eu-west-1)",
+		"Turn on account-level Block Public Access: account://public-access-block",
+	} {
+		if !strings.Contains(output, want) {
+			t.Errorf("expected %q in output:\n%s", want, output)
+		}
+	}
+	if !strings.HasPrefix(output, "- [ ] P1") {
+		t.Errorf("highest priority should come first:\n%s", output)
+	}
+}
+
+func TestTasksReporter_Empty(t *testing.T) {
+	var buf bytes.Buffer
+	if err := NewTasksReporter(&buf).Generate(Data{}); err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+	if buf.String() != "No recommended actions.\n" {
+		t.Errorf("unexpected output: %q", buf.String())
+	}
+}
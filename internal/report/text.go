@@ -8,6 +8,8 @@ import (
 
 	"github.com/fatih/color"
 	"github.com/ppiankov/s3spectre/internal/analyzer"
+	"github.com/ppiankov/s3spectre/internal/s3"
+	"github.com/ppiankov/s3spectre/internal/scanner"
 )
 
 // TextReporter generates human-readable text reports
@@ -45,7 +47,11 @@ func (r *TextReporter) Generate(data Data) error {
 	}
 	_, _ = fmt.Fprintf(r.writer, "================\n\n")
 	_, _ = fmt.Fprintf(r.writer, "Scan Time: %s\n", data.Timestamp.Format("2006-01-02 15:04:05"))
-	_, _ = fmt.Fprintf(r.writer, "Repository: %s\n", data.Config.RepoPath)
+	if data.Config.BucketsFile != "" {
+		_, _ = fmt.Fprintf(r.writer, "Buckets File: %s\n", data.Config.BucketsFile)
+	} else {
+		_, _ = fmt.Fprintf(r.writer, "Repository: %s\n", data.Config.RepoPath)
+	}
 	if data.Config.AWSProfile != "" {
 		_, _ = fmt.Fprintf(r.writer, "AWS Profile: %s\n", data.Config.AWSProfile)
 	}
@@ -53,21 +59,51 @@ func (r *TextReporter) Generate(data Data) error {
 		_, _ = fmt.Fprintf(r.writer, "AWS Region: %s\n", data.Config.AWSRegion)
 	}
 	_, _ = fmt.Fprintf(r.writer, "\n")
+	r.printTruncation(data.Truncated)
 
 	// Summary
 	r.printSummary(data.Summary)
+	r.printBaselineChanges(data.Changes)
+	if len(data.Accounts) > 1 {
+		r.printAccounts(data.Accounts)
+	}
 
 	// Detailed findings
 	r.printFindings(data.Buckets, data.Summary)
+	r.printVaults(data.Vaults, data.Summary)
+	r.printPermissions(data.Permissions)
+	r.printCorrelations(data.Correlations)
+	r.printUnsupported(data.UnsupportedReferences)
+	r.printDeadlineSkips(data.Summary.DeadlineSkips)
+	r.printTuningHints(data.TuningHints)
 
 	return nil
 }
 
+// printUnsupported lists the references to object stores other than S3
+func (r *TextReporter) printUnsupported(refs []scanner.Reference) {
+	if len(refs) == 0 {
+		return
+	}
+	_, _ = fmt.Fprintf(r.writer, "%s\n", color.CyanString("Unsupported Providers (not checked)"))
+	_, _ = fmt.Fprintf(r.writer, "%s\n", strings.Repeat("-", 50))
+	for _, ref := range refs {
+		_, _ = fmt.Fprintf(r.writer, "  [%s] %s (%s:%d)\n", strings.ToUpper(ref.Provider), ref.URI(), ref.File, ref.Line)
+	}
+	_, _ = fmt.Fprintf(r.writer, "\n")
+}
+
 func (r *TextReporter) printSummary(summary analyzer.Summary) {
 	_, _ = fmt.Fprintf(r.writer, "Summary\n")
 	_, _ = fmt.Fprintf(r.writer, "-------\n")
 	_, _ = fmt.Fprintf(r.writer, "Total Buckets Scanned: %d\n", summary.TotalBuckets)
 	_, _ = fmt.Fprintf(r.writer, "OK: %d\n", summary.OKBuckets)
+	if len(summary.ExternalBuckets) > 0 {
+		_, _ = fmt.Fprintf(r.writer, "External (not checked): %d\n", len(summary.ExternalBuckets))
+	}
+	if len(summary.TestOnlyBuckets) > 0 {
+		_, _ = fmt.Fprintf(r.writer, "Referenced only from tests: %d\n", len(summary.TestOnlyBuckets))
+	}
 
 	if len(summary.MissingBuckets) > 0 {
 		_, _ = fmt.Fprintf(r.writer, "%s: %d\n",
@@ -87,6 +123,12 @@ func (r *TextReporter) printSummary(summary analyzer.Summary) {
 			len(summary.MissingPrefixes))
 	}
 
+	if len(summary.PrefixNearMisses) > 0 {
+		_, _ = fmt.Fprintf(r.writer, "%s: %d\n",
+			color.YellowString("Prefix Near Misses"),
+			len(summary.PrefixNearMisses))
+	}
+
 	if len(summary.StalePrefixes) > 0 {
 		_, _ = fmt.Fprintf(r.writer, "%s: %d\n",
 			color.YellowString("Stale Prefixes"),
@@ -105,6 +147,42 @@ func (r *TextReporter) printSummary(summary analyzer.Summary) {
 			len(summary.LifecycleMisconfig))
 	}
 
+	if len(summary.MissingVaults) > 0 {
+		_, _ = fmt.Fprintf(r.writer, "%s: %d\n",
+			color.RedString("Missing Vaults"),
+			len(summary.MissingVaults))
+	}
+
+	if len(summary.OrphanedVaults) > 0 {
+		_, _ = fmt.Fprintf(r.writer, "%s: %d\n",
+			color.YellowString("Orphaned Vaults"),
+			len(summary.OrphanedVaults))
+	}
+
+	if len(summary.PermissionGaps) > 0 {
+		_, _ = fmt.Fprintf(r.writer, "%s: %d\n",
+			color.RedString("Permission Gaps"),
+			len(summary.PermissionGaps))
+	}
+
+	if len(summary.PermissionsUnknown) > 0 {
+		_, _ = fmt.Fprintf(r.writer, "%s: %d\n",
+			color.YellowString("Permissions Unknown"),
+			len(summary.PermissionsUnknown))
+	}
+
+	if len(summary.ReplicationDestinations) > 0 {
+		_, _ = fmt.Fprintf(r.writer, "%s: %d\n",
+			color.CyanString("Replication Destinations"),
+			len(summary.ReplicationDestinations))
+	}
+
+	if len(summary.Correlated) > 0 {
+		_, _ = fmt.Fprintf(r.writer, "%s: %d\n",
+			color.RedString("Correlated With Other Tools"),
+			len(summary.Correlated))
+	}
+
 	_, _ = fmt.Fprintf(r.writer, "\n")
 }
 
@@ -119,9 +197,9 @@ func (r *TextReporter) printFindings(buckets map[string]*analyzer.BucketAnalysis
 			_, _ = fmt.Fprintf(r.writer, "  %s: %s\n",
 				color.RedString("[MISSING_BUCKET]"),
 				bucket)
-			if analysis.Message != "" {
-				_, _ = fmt.Fprintf(r.writer, "    %s\n", analysis.Message)
-			}
+			r.printSuggestion(analysis.Message, analysis.Suggestions...)
+			r.printBlame(analysis.Blame)
+			r.printConfidence(analysis.Confidence)
 		}
 		_, _ = fmt.Fprintf(r.writer, "\n")
 	}
@@ -139,6 +217,7 @@ func (r *TextReporter) printFindings(buckets map[string]*analyzer.BucketAnalysis
 			if analysis.Message != "" {
 				_, _ = fmt.Fprintf(r.writer, "    %s\n", analysis.Message)
 			}
+			r.printConfidence(analysis.Confidence)
 			if analysis.UnusedScore != nil {
 				_, _ = fmt.Fprintf(r.writer, "    Reasons:\n")
 				for _, reason := range analysis.UnusedScore.Reasons {
@@ -149,6 +228,21 @@ func (r *TextReporter) printFindings(buckets map[string]*analyzer.BucketAnalysis
 		_, _ = fmt.Fprintf(r.writer, "\n")
 	}
 
+	// Print replication destinations, which would otherwise be unused
+	if len(summary.ReplicationDestinations) > 0 {
+		_, _ = fmt.Fprintf(r.writer, "%s\n", color.CyanString("Replication Destinations"))
+		_, _ = fmt.Fprintf(r.writer, "%s\n", strings.Repeat("-", 50))
+		for _, bucket := range summary.ReplicationDestinations {
+			_, _ = fmt.Fprintf(r.writer, "  %s: %s\n",
+				color.CyanString("[REPLICATION_DESTINATION]"),
+				bucket)
+			if analysis := buckets[bucket]; analysis != nil && analysis.Message != "" {
+				_, _ = fmt.Fprintf(r.writer, "    %s\n", analysis.Message)
+			}
+		}
+		_, _ = fmt.Fprintf(r.writer, "\n")
+	}
+
 	// Print stale prefixes
 	if len(summary.StalePrefixes) > 0 {
 		_, _ = fmt.Fprintf(r.writer, "%s\n", color.YellowString("Stale Prefixes"))
@@ -171,6 +265,28 @@ func (r *TextReporter) printFindings(buckets map[string]*analyzer.BucketAnalysis
 			_, _ = fmt.Fprintf(r.writer, "  %s: %s\n",
 				color.YellowString("[MISSING_PREFIX]"),
 				prefixPath)
+			if prefix := findPrefix(buckets, prefixPath); prefix != nil {
+				r.printBlame(prefix.Blame)
+			}
+		}
+		_, _ = fmt.Fprintf(r.writer, "\n")
+	}
+
+	// Print near misses with the prefix that probably was meant
+	if len(summary.PrefixNearMisses) > 0 {
+		_, _ = fmt.Fprintf(r.writer, "%s\n", color.YellowString("Prefix Near Misses"))
+		_, _ = fmt.Fprintf(r.writer, "%s\n", strings.Repeat("-", 50))
+		sort.Strings(summary.PrefixNearMisses)
+		for _, prefixPath := range summary.PrefixNearMisses {
+			_, _ = fmt.Fprintf(r.writer, "  %s: %s\n",
+				color.YellowString("[PREFIX_NEAR_MISS]"),
+				prefixPath)
+			if prefix := findPrefix(buckets, prefixPath); prefix != nil {
+				if prefix.Suggestion != "" {
+					r.printSuggestion(prefix.Message, prefix.Suggestion)
+				}
+				r.printBlame(prefix.Blame)
+			}
 		}
 		_, _ = fmt.Fprintf(r.writer, "\n")
 	}
@@ -188,6 +304,7 @@ func (r *TextReporter) printFindings(buckets map[string]*analyzer.BucketAnalysis
 			if analysis.Message != "" {
 				_, _ = fmt.Fprintf(r.writer, "    %s\n", analysis.Message)
 			}
+			r.printConfidence(analysis.Confidence)
 		}
 		_, _ = fmt.Fprintf(r.writer, "\n")
 	}
@@ -205,6 +322,7 @@ func (r *TextReporter) printFindings(buckets map[string]*analyzer.BucketAnalysis
 			if analysis.Message != "" {
 				_, _ = fmt.Fprintf(r.writer, "    %s\n", analysis.Message)
 			}
+			r.printConfidence(analysis.Confidence)
 		}
 		_, _ = fmt.Fprintf(r.writer, "\n")
 	}
@@ -223,14 +341,118 @@ func (r *TextReporter) printFindings(buckets map[string]*analyzer.BucketAnalysis
 		sort.Strings(okBuckets)
 
 		for _, bucket := range okBuckets {
+			tag := "[OK]"
+			if buckets[bucket].External {
+				tag = "[EXTERNAL]"
+			} else if buckets[bucket].TestOnly {
+				tag = "[TEST_ONLY]"
+			}
 			_, _ = fmt.Fprintf(r.writer, "  %s: %s\n",
-				color.GreenString("[OK]"),
+				color.GreenString(tag),
 				bucket)
 		}
 		_, _ = fmt.Fprintf(r.writer, "\n")
 	}
 }
 
+// printVaults prints Glacier vault findings
+func (r *TextReporter) printVaults(vaults map[string]*analyzer.VaultAnalysis, summary analyzer.Summary) {
+	if len(summary.MissingVaults) > 0 {
+		_, _ = fmt.Fprintf(r.writer, "%s\n", color.RedString("Missing Glacier Vaults"))
+		_, _ = fmt.Fprintf(r.writer, "%s\n", strings.Repeat("-", 50))
+		for _, name := range summary.MissingVaults {
+			_, _ = fmt.Fprintf(r.writer, "  %s: %s\n",
+				color.RedString("[MISSING_VAULT]"),
+				name)
+			if analysis := vaults[name]; analysis != nil && analysis.Message != "" {
+				_, _ = fmt.Fprintf(r.writer, "    %s\n", analysis.Message)
+			}
+		}
+		_, _ = fmt.Fprintf(r.writer, "\n")
+	}
+
+	if len(summary.OrphanedVaults) > 0 {
+		_, _ = fmt.Fprintf(r.writer, "%s\n", color.YellowString("Orphaned Glacier Vaults"))
+		_, _ = fmt.Fprintf(r.writer, "%s\n", strings.Repeat("-", 50))
+		for _, name := range summary.OrphanedVaults {
+			analysis := vaults[name]
+			if analysis == nil {
+				continue
+			}
+			_, _ = fmt.Fprintf(r.writer, "  %s: %s (%s, %s)\n",
+				color.YellowString("[ORPHANED_VAULT]"),
+				analysis.Name, analysis.Region, formatBytes(analysis.SizeInBytes))
+			if analysis.Message != "" {
+				_, _ = fmt.Fprintf(r.writer, "    %s\n", analysis.Message)
+			}
+		}
+		_, _ = fmt.Fprintf(r.writer, "\n")
+	}
+}
+
+// printTruncation warns that the report is partial and lists the buckets
+// that were not inspected
+func (r *TextReporter) printTruncation(t *Truncation) {
+	if t == nil {
+		return
+	}
+	_, _ = fmt.Fprintf(r.writer, "%s: %s\n", color.RedString("PARTIAL REPORT"), t.Reason)
+	if len(t.UninspectedBuckets) > 0 {
+		_, _ = fmt.Fprintf(r.writer, "Uninspected Buckets (%d): %s\n",
+			len(t.UninspectedBuckets), strings.Join(t.UninspectedBuckets, ", "))
+	}
+	_, _ = fmt.Fprintf(r.writer, "\n")
+}
+
+// printDeadlineSkips lists the checks each bucket skipped at the per-bucket
+// deadline
+func (r *TextReporter) printDeadlineSkips(skips []analyzer.DeadlineSkip) {
+	if len(skips) == 0 {
+		return
+	}
+	_, _ = fmt.Fprintf(r.writer, "%s\n", color.YellowString("Skipped Checks (deadline)"))
+	_, _ = fmt.Fprintf(r.writer, "%s\n", strings.Repeat("-", 50))
+	for _, skip := range skips {
+		_, _ = fmt.Fprintf(r.writer, "  %s: %s\n", skip.Bucket, strings.Join(skip.Checks, ", "))
+	}
+	_, _ = fmt.Fprintf(r.writer, "  Findings that depend on these checks were not reported; raise --per-bucket-deadline to run them\n")
+	_, _ = fmt.Fprintf(r.writer, "\n")
+}
+
+// printPermissions prints references that application principals are not allowed
+func (r *TextReporter) printPermissions(permissions []*analyzer.PermissionAnalysis) {
+	if len(permissions) == 0 {
+		return
+	}
+	_, _ = fmt.Fprintf(r.writer, "%s\n", color.RedString("Permission Gaps"))
+	_, _ = fmt.Fprintf(r.writer, "%s\n", strings.Repeat("-", 50))
+	for _, p := range permissions {
+		_, _ = fmt.Fprintf(r.writer, "  %s: %s (%s, %s:%d)\n",
+			color.RedString("[PERMISSION_GAP]"),
+			p.Path(), p.Action, p.File, p.Line)
+		for _, d := range p.Denied {
+			_, _ = fmt.Fprintf(r.writer, "    %s: %s\n", d.Principal, d.Decision)
+		}
+	}
+	_, _ = fmt.Fprintf(r.writer, "\n")
+}
+
+// printCorrelations prints the findings of other Spectre tools that involve
+// flagged buckets
+func (r *TextReporter) printCorrelations(correlations []analyzer.Correlation) {
+	if len(correlations) == 0 {
+		return
+	}
+	_, _ = fmt.Fprintf(r.writer, "%s\n", color.RedString("Cross-Tool Correlations"))
+	_, _ = fmt.Fprintf(r.writer, "%s\n", strings.Repeat("-", 50))
+	for _, c := range correlations {
+		_, _ = fmt.Fprintf(r.writer, "  %s: %s (%s)\n",
+			color.RedString("[%s]", c.Status), c.Bucket, c.Severity)
+		_, _ = fmt.Fprintf(r.writer, "    %s\n", c.Message)
+	}
+	_, _ = fmt.Fprintf(r.writer, "\n")
+}
+
 // GenerateDiscovery generates a text discovery report
 func (r *TextReporter) GenerateDiscovery(data DiscoveryData) error {
 	// Header
@@ -249,14 +471,66 @@ func (r *TextReporter) GenerateDiscovery(data DiscoveryData) error {
 	} else if len(data.Config.Regions) > 0 {
 		_, _ = fmt.Fprintf(r.writer, "Regions: %s\n", strings.Join(data.Config.Regions, ", "))
 	}
+	switch data.Config.Source {
+	case "aws-config":
+		_, _ = fmt.Fprintf(r.writer, "Source: AWS Config aggregator\n")
+	case "resource-explorer":
+		_, _ = fmt.Fprintf(r.writer, "Source: AWS Resource Explorer\n")
+	}
+	if len(data.Config.TagFilters) > 0 {
+		_, _ = fmt.Fprintf(r.writer, "Tag Filters: %s\n", strings.Join(data.Config.TagFilters, ", "))
+	}
+	if len(data.Config.IncludeBuckets) > 0 {
+		_, _ = fmt.Fprintf(r.writer, "Include Buckets: %s\n", strings.Join(data.Config.IncludeBuckets, ", "))
+	}
+	if len(data.Config.ExcludeBuckets) > 0 {
+		_, _ = fmt.Fprintf(r.writer, "Exclude Buckets: %s\n", strings.Join(data.Config.ExcludeBuckets, ", "))
+	}
+	if len(data.Config.TagFilters) > 0 {
+		_, _ = fmt.Fprintf(r.writer, "Tag Filters: %s\n", strings.Join(data.Config.TagFilters, ", "))
+	}
 	_, _ = fmt.Fprintf(r.writer, "Total Regions Scanned: %d\n", data.Summary.TotalRegions)
 	_, _ = fmt.Fprintf(r.writer, "\n")
+	r.printTruncation(data.Truncated)
 
 	// Summary
 	r.printDiscoverySummary(data.Summary)
+	r.printBaselineChanges(data.Changes)
+
+	if data.Posture != nil {
+		r.printPosture(data.Posture)
+	}
+	r.printStorageClasses(data.Buckets)
+	r.printObjectAges(data.Buckets)
+	if data.Spend != nil {
+		r.printSpend(data.Spend)
+	}
+
+	if data.Sample != nil {
+		r.printSampleEstimate(data.Sample)
+	}
+
+	// A single region's breakdown would repeat the summary
+	if len(data.Regions) > 1 {
+		r.printRegions(data.Regions)
+	}
+	if len(data.Accounts) > 1 {
+		r.printAccounts(data.Accounts)
+	}
+
+	if data.Config.GroupByTag != "" {
+		r.printTagGroups(data.Config.GroupByTag, data.Groups)
+	}
+
+	r.printAccountFindings(data.AccountFindings)
+	r.printPolicyFindings(data.Buckets)
+	r.printExternalAccess(data.Buckets, data.Summary.ExternalAccess)
+	r.printCorrelations(data.Correlations)
 
 	// Detailed findings
 	r.printDiscoveryFindings(data.Buckets, data.Summary)
+	r.printDeadlineSkips(data.Summary.DeadlineSkips)
+	r.printTuningHints(data.TuningHints)
 
 	return nil
 }
@@ -266,6 +540,9 @@ func (r *TextReporter) printDiscoverySummary(summary analyzer.DiscoverySummary)
 	_, _ = fmt.Fprintf(r.writer, "-------\n")
 	_, _ = fmt.Fprintf(r.writer, "Total Buckets: %d\n", summary.TotalBuckets)
 	_, _ = fmt.Fprintf(r.writer, "Healthy: %d\n", summary.HealthyBuckets)
+	if len(summary.ExternalBuckets) > 0 {
+		_, _ = fmt.Fprintf(r.writer, "External (not scored): %d\n", len(summary.ExternalBuckets))
+	}
 
 	if len(summary.UnusedBuckets) > 0 {
 		_, _ = fmt.Fprintf(r.writer, "%s: %d\n",
@@ -291,9 +568,480 @@ func (r *TextReporter) printDiscoverySummary(summary analyzer.DiscoverySummary)
 			len(summary.VersionSprawl))
 	}
 
+	if summary.CustomFindings > 0 {
+		_, _ = fmt.Fprintf(r.writer, "%s: %d\n",
+			color.CyanString("Custom Findings"),
+			summary.CustomFindings)
+	}
+
+	if len(summary.NoGuardDutyS3) > 0 {
+		_, _ = fmt.Fprintf(r.writer, "%s: %d\n",
+			color.RedString("Regions without GuardDuty S3 Protection"),
+			len(summary.NoGuardDutyS3))
+	}
+
+	if len(summary.WildcardPrincipals) > 0 {
+		_, _ = fmt.Fprintf(r.writer, "%s: %d\n",
+			color.RedString("Wildcard Principals"),
+			len(summary.WildcardPrincipals))
+	}
+
+	if len(summary.CrossAccountAccess) > 0 {
+		_, _ = fmt.Fprintf(r.writer, "%s: %d\n",
+			color.YellowString("Cross-Account Access"),
+			len(summary.CrossAccountAccess))
+	}
+
+	if len(summary.InsecureTransport) > 0 {
+		_, _ = fmt.Fprintf(r.writer, "%s: %d\n",
+			color.YellowString("Insecure Transport Allowed"),
+			len(summary.InsecureTransport))
+	}
+
+	if len(summary.NoLogging) > 0 {
+		_, _ = fmt.Fprintf(r.writer, "%s: %d\n",
+			color.YellowString("Production Buckets Without Logging"),
+			len(summary.NoLogging))
+	}
+
+	if len(summary.SensitiveDataExposed) > 0 {
+		_, _ = fmt.Fprintf(r.writer, "%s: %d\n",
+			color.RedString("Sensitive Data Exposed"),
+			len(summary.SensitiveDataExposed))
+	}
+
+	if len(summary.ExternalAccess) > 0 {
+		_, _ = fmt.Fprintf(r.writer, "%s: %d\n",
+			color.RedString("Access Analyzer External Access"),
+			len(summary.ExternalAccess))
+	}
+
+	if len(summary.ReplicationDestinations) > 0 {
+		_, _ = fmt.Fprintf(r.writer, "%s: %d\n",
+			color.CyanString("Replication Destinations"),
+			len(summary.ReplicationDestinations))
+	}
+
+	if len(summary.ComplianceLocked) > 0 {
+		_, _ = fmt.Fprintf(r.writer, "%s: %d\n",
+			color.YellowString("Object Lock (exclude from cleanup)"),
+			len(summary.ComplianceLocked))
+	}
+
+	if len(summary.Correlated) > 0 {
+		_, _ = fmt.Fprintf(r.writer, "%s: %d\n",
+			color.RedString("Correlated With Other Tools"),
+			len(summary.Correlated))
+	}
+
 	_, _ = fmt.Fprintf(r.writer, "\n")
 }
 
+// printAccountFindings prints findings about account configuration
+func (r *TextReporter) printAccountFindings(findings []analyzer.AccountAnalysis) {
+	if len(findings) == 0 {
+		return
+	}
+	_, _ = fmt.Fprintf(r.writer, "%s\n", color.RedString("Account Findings"))
+	_, _ = fmt.Fprintf(r.writer, "%s\n", strings.Repeat("-", 50))
+	for _, f := range findings {
+		scope := f.Region
+		if f.Family != "" {
+			scope = "family " + f.Family
+		}
+		if scope == "" {
+			scope = "account"
+		}
+		if f.Account != "" {
+			scope = f.Account + " " + scope
+		}
+		_, _ = fmt.Fprintf(r.writer, "  %s: %s\n", color.RedString("[%s]", f.Status), scope)
+		if f.Message != "" {
+			_, _ = fmt.Fprintf(r.writer, "    %s\n", f.Message)
+		}
+		if len(f.Buckets) > 0 {
+			_, _ = fmt.Fprintf(r.writer, "    Buckets: %s\n", strings.Join(f.Buckets, ", "))
+		}
+	}
+	_, _ = fmt.Fprintf(r.writer, "\n")
+}
+
+// printPolicyFindings prints each bucket's bucket policy, logging and
+// sensitive data findings
+func (r *TextReporter) printPolicyFindings(buckets map[string]*analyzer.BucketDiscovery) {
+	var names []string
+	for name, discovery := range buckets {
+		if discovery != nil && len(discovery.PolicyFindings) > 0 {
+			names = append(names, name)
+		}
+	}
+	if len(names) == 0 {
+		return
+	}
+	sort.Strings(names)
+	_, _ = fmt.Fprintf(r.writer, "%s\n", color.RedString("Bucket Security Findings"))
+	_, _ = fmt.Fprintf(r.writer, "%s\n", strings.Repeat("-", 50))
+	for _, name := range names {
+		for _, f := range buckets[name].PolicyFindings {
+			_, _ = fmt.Fprintf(r.writer, "  %s: %s\n", color.RedString("[%s]", f.Status), name)
+			_, _ = fmt.Fprintf(r.writer, "    %s\n", f.Message)
+		}
+	}
+	_, _ = fmt.Fprintf(r.writer, "\n")
+}
+
+// printExternalAccess prints the IAM Access Analyzer findings of each
+// bucket, whatever its status
+func (r *TextReporter) printExternalAccess(buckets map[string]*analyzer.BucketDiscovery, names []string) {
+	if len(names) == 0 {
+		return
+	}
+	sort.Strings(names)
+	_, _ = fmt.Fprintf(r.writer, "%s\n", color.RedString("Access Analyzer Findings"))
+	_, _ = fmt.Fprintf(r.writer, "%s\n", strings.Repeat("-", 50))
+	for _, name := range names {
+		discovery := buckets[name]
+		if discovery == nil || discovery.BucketInfo == nil {
+			continue
+		}
+		_, _ = fmt.Fprintf(r.writer, "  %s: %s (%s)\n", color.RedString("[%s]", discovery.Status), name, discovery.Region)
+		for _, f := range discovery.BucketInfo.ExternalAccess {
+			_, _ = fmt.Fprintf(r.writer, "    - %s: %s\n", f.Grantee(), strings.Join(f.Actions, ", "))
+		}
+	}
+	_, _ = fmt.Fprintf(r.writer, "\n")
+}
+
+// printBlame names the last commit that changed a finding's references
+func (r *TextReporter) printBlame(b *scanner.Blame) {
+	if b == nil {
+		return
+	}
+	_, _ = fmt.Fprintf(r.writer, "    Last changed by: %s\n", blameText(b))
+}
+
+// printConfidence notes a finding whose evidence is weak, with the reasons.
+// High-confidence findings print nothing.
+func (r *TextReporter) printConfidence(c *analyzer.Confidence) {
+	if c == nil || c.Level == analyzer.ConfidenceHigh {
+		return
+	}
+	_, _ = fmt.Fprintf(r.writer, "    Confidence: %s (%d/100)\n", c.Level, c.Score)
+	for _, reason := range c.Reasons {
+		_, _ = fmt.Fprintf(r.writer, "      - %s\n", reason)
+	}
+}
+
+// printVersionOverhead prints the noncurrent version share of a bucket's
+// version bytes, marking estimates from a truncated listing
+func (r *TextReporter) printVersionOverhead(v *s3.VersionStats) {
+	switch {
+	case !v.Truncated:
+		_, _ = fmt.Fprintf(r.writer, "    Version Overhead: %s in %d noncurrent versions (%.1f%% of total)\n",
+			formatBytes(v.NoncurrentSize), v.NoncurrentVersions, v.OverheadRatio*100)
+	case v.Sampled:
+		_, _ = fmt.Fprintf(r.writer, "    Version Overhead (estimated): %.1f%% of total, 95%% CI %.1f%%-%.1f%%, sampled from %d pages\n",
+			v.OverheadRatio*100, v.OverheadLow*100, v.OverheadHigh*100, v.Pages)
+		_, _ = fmt.Fprintf(r.writer, "    Listing truncated: sizes and counts cover the sampled pages only\n")
+	default:
+		_, _ = fmt.Fprintf(r.writer, "    Version Overhead: at least %s (%.1f%% of the first %d pages)\n",
+			formatBytes(v.NoncurrentSize), v.OverheadRatio*100, v.Pages)
+		_, _ = fmt.Fprintf(r.writer, "    Listing truncated: sizes and counts are lower bounds (use --version-sampling to estimate)\n")
+	}
+}
+
+// printRecommendations prints a bucket's recommendations, highest
+// impact/effort first, with their estimated impact and effort
+func (r *TextReporter) printRecommendations(discovery *analyzer.BucketDiscovery) {
+	if len(discovery.ScoredRecommendations) == 0 {
+		if len(discovery.Recommendations) > 0 {
+			_, _ = fmt.Fprintf(r.writer, "    Recommendations:\n")
+			for _, rec := range discovery.Recommendations {
+				_, _ = fmt.Fprintf(r.writer, "      - %s\n", rec)
+			}
+		}
+		return
+	}
+	_, _ = fmt.Fprintf(r.writer, "    Recommendations:\n")
+	for _, rec := range discovery.ScoredRecommendations {
+		_, _ = fmt.Fprintf(r.writer, "      - %s (impact: %s, effort: %s)\n",
+			rec.Action, formatImpact(rec), rec.Effort)
+	}
+}
+
+// formatImpact describes what a recommendation saves and how much risk it
+// removes
+func formatImpact(rec analyzer.Recommendation) string {
+	var parts []string
+	if rec.ImpactBytes > 0 {
+		parts = append(parts, fmt.Sprintf("%s, ~$%.2f/month",
+			formatBytes(rec.ImpactBytes), rec.MonthlySavings))
+	}
+	if rec.RiskReduced > 0 {
+		parts = append(parts, fmt.Sprintf("-%d risk", rec.RiskReduced))
+	}
+	if len(parts) == 0 {
+		return "none measured"
+	}
+	return strings.Join(parts, ", ")
+}
+
+// printTuningHints prints suggested threshold adjustments
+func (r *TextReporter) printTuningHints(hints []analyzer.TuningHint) {
+	if len(hints) == 0 {
+		return
+	}
+	_, _ = fmt.Fprintf(r.writer, "%s\n", color.CyanString("Tuning Hints"))
+	_, _ = fmt.Fprintf(r.writer, "%s\n", strings.Repeat("-", 50))
+	for _, h := range hints {
+		_, _ = fmt.Fprintf(r.writer, "  %s\n", h.Message)
+	}
+	_, _ = fmt.Fprintf(r.writer, "  Apply with --tuning-patch FILE and merge it into .s3spectre.yaml\n")
+	_, _ = fmt.Fprintf(r.writer, "\n")
+}
+
+// printSuggestion prints a finding's message, which names the suggestions
+// the analyzer found, or the suggestions alone when there is no message
+func (r *TextReporter) printSuggestion(message string, suggestions ...string) {
+	switch {
+	case message != "":
+		_, _ = fmt.Fprintf(r.writer, "    %s\n", message)
+	case len(suggestions) > 0:
+		_, _ = fmt.Fprintf(r.writer, "    Did you mean: %s\n", strings.Join(suggestions, ", "))
+	}
+}
+
+func (r *TextReporter) printPosture(posture *analyzer.StoragePosture) {
+	_, _ = fmt.Fprintf(r.writer, "Storage Posture\n")
+	_, _ = fmt.Fprintf(r.writer, "---------------\n")
+	if posture.Population > 0 {
+		_, _ = fmt.Fprintf(r.writer, "Buckets: %d sampled of %d\n", posture.TotalBuckets, posture.Population)
+	} else {
+		_, _ = fmt.Fprintf(r.writer, "Buckets: %d\n", posture.TotalBuckets)
+	}
+	size := formatBytes(posture.EstimatedBytes)
+	if posture.PartialSizeBuckets > 0 {
+		size = fmt.Sprintf("at least %s (%d buckets sized from a partial listing)", size, posture.PartialSizeBuckets)
+	}
+	_, _ = fmt.Fprintf(r.writer, "Estimated Size: %s\n", size)
+	if posture.Population > 0 {
+		_, _ = fmt.Fprintf(r.writer, "Estimated Size (all buckets): ~%s\n", formatBytes(posture.PopulationBytes))
+	}
+	if posture.EncryptionUnknown == posture.TotalBuckets {
+		_, _ = fmt.Fprintf(r.writer, "Encrypted: unknown\n")
+	} else {
+		_, _ = fmt.Fprintf(r.writer, "Encrypted: %.1f%% (%d)\n", posture.EncryptedPercent, posture.EncryptedBuckets)
+	}
+	_, _ = fmt.Fprintf(r.writer, "Versioned: %.1f%% (%d)\n", posture.VersionedPercent, posture.VersionedBuckets)
+	_, _ = fmt.Fprintf(r.writer, "Lifecycle Coverage: %.1f%% (%d)\n", posture.LifecyclePercent, posture.LifecycleBuckets)
+	_, _ = fmt.Fprintf(r.writer, "\n")
+}
+
+// printStorageClasses prints the storage class breakdown of buckets
+// listed with --deep-size or read from an S3 Inventory report
+func (r *TextReporter) printStorageClasses(buckets map[string]*analyzer.BucketDiscovery) {
+	var names []string
+	for name, discovery := range buckets {
+		if discovery.BucketInfo != nil && discovery.BucketInfo.SizeBreakdown != nil {
+			names = append(names, name)
+		}
+	}
+	if len(names) == 0 {
+		return
+	}
+	sort.Strings(names)
+	_, _ = fmt.Fprintf(r.writer, "Storage Classes\n")
+	_, _ = fmt.Fprintf(r.writer, "%s\n", strings.Repeat("-", 70))
+	for _, name := range names {
+		b := buckets[name].BucketInfo.SizeBreakdown
+		// Truncated listings stopped early, so their totals are lower bounds
+		more := ""
+		if b.Truncated {
+			more = "+"
+		}
+		classes := make([]string, 0, len(b.Classes))
+		for class := range b.Classes {
+			classes = append(classes, class)
+		}
+		sort.Slice(classes, func(i, j int) bool {
+			if b.Classes[classes[i]].Size != b.Classes[classes[j]].Size {
+				return b.Classes[classes[i]].Size > b.Classes[classes[j]].Size
+			}
+			return classes[i] < classes[j]
+		})
+		_, _ = fmt.Fprintf(r.writer, "  %s: %d%s objects, %s%s\n", name, b.Objects, more, formatBytes(b.Size), more)
+		for _, class := range classes {
+			usage := b.Classes[class]
+			_, _ = fmt.Fprintf(r.writer, "    %-20s %8d objects %12s\n", class, usage.Objects, formatBytes(usage.Size))
+		}
+	}
+	_, _ = fmt.Fprintf(r.writer, "\n")
+}
+
+// printObjectAges prints the last-modified ages of the objects of buckets
+// read from an S3 Inventory report
+func (r *TextReporter) printObjectAges(buckets map[string]*analyzer.BucketDiscovery) {
+	var names []string
+	for name, discovery := range buckets {
+		if discovery.BucketInfo != nil && discovery.BucketInfo.Inventory != nil {
+			names = append(names, name)
+		}
+	}
+	if len(names) == 0 {
+		return
+	}
+	sort.Strings(names)
+	_, _ = fmt.Fprintf(r.writer, "Object Ages (S3 Inventory)\n")
+	_, _ = fmt.Fprintf(r.writer, "%s\n", strings.Repeat("-", 70))
+	for _, name := range names {
+		inv := buckets[name].BucketInfo.Inventory
+		_, _ = fmt.Fprintf(r.writer, "  %s: %d objects, %s as of %s\n", name, inv.Objects, formatBytes(inv.Size), inv.Date.Format("2006-01-02"))
+		if inv.AllVersions {
+			_, _ = fmt.Fprintf(r.writer, "    %d versions, %d noncurrent (%s), %d delete markers\n",
+				inv.Versions, inv.NoncurrentVersions, formatBytes(inv.NoncurrentSize), inv.DeleteMarkers)
+		}
+		for _, age := range inv.Ages {
+			_, _ = fmt.Fprintf(r.writer, "    %-20s %8d objects %12s\n", age.Band, age.Objects, formatBytes(age.Size))
+		}
+	}
+	_, _ = fmt.Fprintf(r.writer, "\n")
+}
+
+// printSpend prints month-to-date S3 spend and how much of it was matched
+// to buckets by their cost allocation tag
+func (r *TextReporter) printSpend(spend *analyzer.SpendSummary) {
+	estimated := ""
+	if spend.Estimated {
+		estimated = ", estimated"
+	}
+	_, _ = fmt.Fprintf(r.writer, "S3 Spend (%s to %s%s)\n", spend.Start, spend.End, estimated)
+	_, _ = fmt.Fprintf(r.writer, "%s\n", strings.Repeat("-", 70))
+	_, _ = fmt.Fprintf(r.writer, "Month to Date: %s\n", formatSpend(spend.Total, spend.Currency))
+	_, _ = fmt.Fprintf(r.writer, "Matched to Buckets by %s: %s (%d buckets)\n", spend.TagKey, formatSpend(spend.Attributed, spend.Currency), spend.Buckets)
+	_, _ = fmt.Fprintf(r.writer, "Untagged: %s\n", formatSpend(spend.Untagged, spend.Currency))
+	if spend.Unmatched > 0 {
+		_, _ = fmt.Fprintf(r.writer, "Tagged, No Discovered Bucket: %s\n", formatSpend(spend.Unmatched, spend.Currency))
+	}
+	_, _ = fmt.Fprintf(r.writer, "\n")
+}
+
+// printBucketSpend prints a bucket finding's month-to-date spend
+func (r *TextReporter) printBucketSpend(spend *analyzer.BucketSpend) {
+	if spend == nil {
+		return
+	}
+	_, _ = fmt.Fprintf(r.writer, "    Month-to-Date Spend: %s", formatSpend(spend.MonthToDate, spend.Currency))
+	if len(spend.SharedWith) > 0 {
+		_, _ = fmt.Fprintf(r.writer, " (shared with %s)", strings.Join(spend.SharedWith, ", "))
+	}
+	_, _ = fmt.Fprintf(r.writer, "\n")
+}
+
+// printAccess prints a bucket's object access from CloudTrail data events
+func (r *TextReporter) printAccess(info *s3.BucketInfo) {
+	if info == nil || info.Access == nil {
+		return
+	}
+	access := info.Access
+	if access.LastAccess == nil {
+		_, _ = fmt.Fprintf(r.writer, "    Object Access: none since %s\n", access.Since.Format("2006-01-02"))
+		return
+	}
+	_, _ = fmt.Fprintf(r.writer, "    Last Object Access: %s (%d reads, %d writes since %s)\n",
+		access.LastAccess.Format("2006-01-02"), access.Reads, access.Writes, access.Since.Format("2006-01-02"))
+}
+
+// formatSpend prints an amount in its currency, with dollars as $1.23
+func formatSpend(amount float64, currency string) string {
+	if currency == "USD" {
+		return fmt.Sprintf("$%.2f", amount)
+	}
+	return fmt.Sprintf("%.2f %s", amount, currency)
+}
+
+func (r *TextReporter) printSampleEstimate(sample *analyzer.SampleEstimate) {
+	_, _ = fmt.Fprintf(r.writer, "Sample Estimate (%d of %d buckets, %.0f%% confidence)\n",
+		sample.SampleSize, sample.Population, sample.Confidence*100)
+	_, _ = fmt.Fprintf(r.writer, "%s\n", strings.Repeat("-", 70))
+	for _, e := range sample.Estimates {
+		_, _ = fmt.Fprintf(r.writer, "  %-16s ~%d (range %d-%d, %d observed)\n",
+			e.Category, e.Estimated, e.Low, e.High, e.Observed)
+	}
+	_, _ = fmt.Fprintf(r.writer, "\n")
+}
+
+func (r *TextReporter) printTagGroups(key string, groups []analyzer.TagGroup) {
+	_, _ = fmt.Fprintf(r.writer, "By Tag: %s\n", key)
+	_, _ = fmt.Fprintf(r.writer, "%s\n", strings.Repeat("-", 70))
+	_, _ = fmt.Fprintf(r.writer, "  %-24s %7s %7s %6s %5s %8s %7s %10s\n",
+		"Value", "Buckets", "Healthy", "Unused", "Risky", "Inactive", "Sprawl", "Size")
+	for _, g := range groups {
+		_, _ = fmt.Fprintf(r.writer, "  %-24s %7d %7d %6d %5d %8d %7d %10s\n",
+			g.Value, g.TotalBuckets, g.Healthy, g.Unused, g.Risky, g.Inactive, g.VersionSprawl, formatBytes(g.TotalSize))
+	}
+	_, _ = fmt.Fprintf(r.writer, "\n")
+}
+
+func (r *TextReporter) printRegions(regions []analyzer.RegionSummary) {
+	_, _ = fmt.Fprintf(r.writer, "By Region\n")
+	_, _ = fmt.Fprintf(r.writer, "%s\n", strings.Repeat("-", 70))
+	_, _ = fmt.Fprintf(r.writer, "  %-16s %7s %8s %6s %5s %8s %6s %10s %10s\n",
+		"Region", "Buckets", "Findings", "Unused", "Risky", "Inactive", "Sprawl", "Size", "Waste")
+	for _, g := range regions {
+		_, _ = fmt.Fprintf(r.writer, "  %-16s %7d %8d %6d %5d %8d %6d %10s %10s\n",
+			g.Region, g.TotalBuckets, g.Findings, g.Unused, g.Risky, g.Inactive, g.VersionSprawl,
+			formatBytes(g.TotalSize), formatBytes(g.WasteBytes))
+	}
+	_, _ = fmt.Fprintf(r.writer, "\n")
+}
+
+// findPrefix looks up a "bucket/prefix" summary entry's prefix analysis
+func findPrefix(buckets map[string]*analyzer.BucketAnalysis, prefixPath string) *analyzer.PrefixAnalysis {
+	bucket, prefix, _ := strings.Cut(prefixPath, "/")
+	analysis := buckets[bucket]
+	if analysis == nil {
+		return nil
+	}
+	for i := range analysis.Prefixes {
+		if analysis.Prefixes[i].Prefix == prefix {
+			return &analysis.Prefixes[i]
+		}
+	}
+	return nil
+}
+
+// printAccounts prints bucket and finding totals per account, with the
+// account's most frequent findings
+func (r *TextReporter) printAccounts(accounts []analyzer.AccountSummary) {
+	_, _ = fmt.Fprintf(r.writer, "By Account\n")
+	_, _ = fmt.Fprintf(r.writer, "%s\n", strings.Repeat("-", 70))
+	_, _ = fmt.Fprintf(r.writer, "  %-14s %7s %8s  %s\n", "Account", "Buckets", "Findings", "Top Findings")
+	for _, a := range accounts {
+		_, _ = fmt.Fprintf(r.writer, "  %-14s %7d %8d  %s\n",
+			a.Account, a.TotalBuckets, a.Findings, formatStatusCounts(a.Statuses))
+	}
+	_, _ = fmt.Fprintf(r.writer, "\n")
+}
+
+// formatStatusCounts lists finding counts by status, most frequent first
+func formatStatusCounts(counts map[analyzer.Status]int) string {
+	statuses := make([]analyzer.Status, 0, len(counts))
+	for status := range counts {
+		statuses = append(statuses, status)
+	}
+	sort.Slice(statuses, func(i, j int) bool {
+		if counts[statuses[i]] != counts[statuses[j]] {
+			return counts[statuses[i]] > counts[statuses[j]]
+		}
+		return statuses[i] < statuses[j]
+	})
+	parts := make([]string, 0, len(statuses))
+	for _, status := range statuses {
+		parts = append(parts, fmt.Sprintf("%s %d", status, counts[status]))
+	}
+	return strings.Join(parts, ", ")
+}
+
 func (r *TextReporter) printDiscoveryFindings(buckets map[string]*analyzer.BucketDiscovery, summary analyzer.DiscoverySummary) {
 	// Print unused buckets
 	if len(summary.UnusedBuckets) > 0 {
@@ -307,18 +1055,16 @@ func (r *TextReporter) printDiscoveryFindings(buckets map[string]*analyzer.Bucke
 				bucket,
 				discovery.Region)
 			_, _ = fmt.Fprintf(r.writer, "    Risk Score: %d/100\n", discovery.RiskScore)
+			r.printConfidence(discovery.Confidence)
+			r.printBucketSpend(discovery.Spend)
+			r.printAccess(discovery.BucketInfo)
 			if len(discovery.RiskFactors) > 0 {
 				_, _ = fmt.Fprintf(r.writer, "    Factors:\n")
 				for _, factor := range discovery.RiskFactors {
 					_, _ = fmt.Fprintf(r.writer, "      - %s\n", factor)
 				}
 			}
-			if len(discovery.Recommendations) > 0 {
-				_, _ = fmt.Fprintf(r.writer, "    Recommendations:\n")
-				for _, rec := range discovery.Recommendations {
-					_, _ = fmt.Fprintf(r.writer, "      - %s\n", rec)
-				}
-			}
+			r.printRecommendations(discovery)
 			_, _ = fmt.Fprintf(r.writer, "\n")
 		}
 	}
@@ -335,18 +1081,15 @@ func (r *TextReporter) printDiscoveryFindings(buckets map[string]*analyzer.Bucke
 				bucket,
 				discovery.Region)
 			_, _ = fmt.Fprintf(r.writer, "    Risk Score: %d/100\n", discovery.RiskScore)
+			r.printConfidence(discovery.Confidence)
+			r.printBucketSpend(discovery.Spend)
 			if len(discovery.RiskFactors) > 0 {
 				_, _ = fmt.Fprintf(r.writer, "    Factors:\n")
 				for _, factor := range discovery.RiskFactors {
 					_, _ = fmt.Fprintf(r.writer, "      - %s\n", factor)
 				}
 			}
-			if len(discovery.Recommendations) > 0 {
-				_, _ = fmt.Fprintf(r.writer, "    Recommendations:\n")
-				for _, rec := range discovery.Recommendations {
-					_, _ = fmt.Fprintf(r.writer, "      - %s\n", rec)
-				}
-			}
+			r.printRecommendations(discovery)
 			_, _ = fmt.Fprintf(r.writer, "\n")
 		}
 	}
@@ -363,6 +1106,9 @@ func (r *TextReporter) printDiscoveryFindings(buckets map[string]*analyzer.Bucke
 				bucket,
 				discovery.Region)
 			_, _ = fmt.Fprintf(r.writer, "    Risk Score: %d/100\n", discovery.RiskScore)
+			r.printConfidence(discovery.Confidence)
+			r.printBucketSpend(discovery.Spend)
+			r.printAccess(discovery.BucketInfo)
 			if len(discovery.RiskFactors) > 0 {
 				_, _ = fmt.Fprintf(r.writer, "    Factors:\n")
 				for _, factor := range discovery.RiskFactors {
@@ -373,6 +1119,25 @@ func (r *TextReporter) printDiscoveryFindings(buckets map[string]*analyzer.Bucke
 		}
 	}
 
+	// Print replication destinations, which would otherwise be unused or
+	// inactive
+	if len(summary.ReplicationDestinations) > 0 {
+		_, _ = fmt.Fprintf(r.writer, "%s\n", color.CyanString("Replication Destinations"))
+		_, _ = fmt.Fprintf(r.writer, "%s\n", strings.Repeat("-", 70))
+		for _, bucket := range summary.ReplicationDestinations {
+			discovery := buckets[bucket]
+			_, _ = fmt.Fprintf(r.writer, "  %s: %s (%s)\n",
+				color.CyanString("[REPLICATION_DESTINATION]"),
+				bucket,
+				discovery.Region)
+			for _, factor := range discovery.RiskFactors {
+				_, _ = fmt.Fprintf(r.writer, "      - %s\n", factor)
+			}
+			r.printRecommendations(discovery)
+			_, _ = fmt.Fprintf(r.writer, "\n")
+		}
+	}
+
 	// Print version sprawl
 	if len(summary.VersionSprawl) > 0 {
 		_, _ = fmt.Fprintf(r.writer, "%s\n", color.MagentaString("Version Sprawl"))
@@ -384,6 +1149,8 @@ func (r *TextReporter) printDiscoveryFindings(buckets map[string]*analyzer.Bucke
 				color.MagentaString("[VERSION_SPRAWL]"),
 				bucket,
 				discovery.Region)
+			r.printConfidence(discovery.Confidence)
+			r.printBucketSpend(discovery.Spend)
 
 			// Show size information
 			if discovery.BucketInfo != nil {
@@ -392,7 +1159,9 @@ func (r *TextReporter) printDiscoveryFindings(buckets map[string]*analyzer.Bucke
 						formatBytes(discovery.BucketInfo.TotalVersionSize),
 						discovery.BucketInfo.VersionCount)
 				}
-				if discovery.BucketInfo.TotalSize > 0 && discovery.BucketInfo.TotalVersionSize > discovery.BucketInfo.TotalSize {
+				if versions := discovery.BucketInfo.VersionStats; versions != nil {
+					r.printVersionOverhead(versions)
+				} else if discovery.BucketInfo.TotalSize > 0 && discovery.BucketInfo.TotalVersionSize > discovery.BucketInfo.TotalSize {
 					overhead := discovery.BucketInfo.TotalVersionSize - discovery.BucketInfo.TotalSize
 					_, _ = fmt.Fprintf(r.writer, "    Version Overhead: %s (%.1f%% of total)\n",
 						formatBytes(overhead),
@@ -410,6 +1179,33 @@ func (r *TextReporter) printDiscoveryFindings(buckets map[string]*analyzer.Bucke
 		}
 	}
 
+	// Print plugin findings
+	if summary.CustomFindings > 0 {
+		_, _ = fmt.Fprintf(r.writer, "%s\n", color.CyanString("Custom Findings"))
+		_, _ = fmt.Fprintf(r.writer, "%s\n", strings.Repeat("-", 70))
+		names := make([]string, 0, len(buckets))
+		for name, discovery := range buckets {
+			if len(discovery.CustomFindings) > 0 {
+				names = append(names, name)
+			}
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			for _, cf := range buckets[name].CustomFindings {
+				_, _ = fmt.Fprintf(r.writer, "  %s: %s [%s/%s, %s]\n",
+					color.CyanString("[%s]", cf.Rule),
+					name,
+					cf.Plugin,
+					cf.Rule,
+					cf.Severity)
+				if cf.Message != "" {
+					_, _ = fmt.Fprintf(r.writer, "    %s\n", cf.Message)
+				}
+			}
+		}
+		_, _ = fmt.Fprintf(r.writer, "\n")
+	}
+
 	// Print healthy buckets summary
 	if summary.HealthyBuckets > 0 {
 		_, _ = fmt.Fprintf(r.writer, "%s\n", color.GreenString("Healthy Buckets: %d", summary.HealthyBuckets))
@@ -432,8 +1228,12 @@ func (r *TextReporter) printDiscoveryFindings(buckets map[string]*analyzer.Bucke
 		for i := 0; i < displayCount; i++ {
 			bucket := healthyBuckets[i]
 			discovery := buckets[bucket]
+			tag := "[OK]"
+			if discovery.External {
+				tag = "[EXTERNAL]"
+			}
 			_, _ = fmt.Fprintf(r.writer, "  %s: %s (%s)\n",
-				color.GreenString("[OK]"),
+				color.GreenString(tag),
 				bucket,
 				discovery.Region)
 		}
@@ -10,6 +10,7 @@ import (
 	"github.com/fatih/color"
 	"github.com/ppiankov/s3spectre/internal/analyzer"
 	"github.com/ppiankov/s3spectre/internal/s3"
+	"github.com/ppiankov/s3spectre/internal/scanner"
 )
 
 func setNoColor(t *testing.T) {
@@ -207,3 +208,529 @@ func TestTextReporter_LargeDiscoveryOutput(t *testing.T) {
 		t.Fatalf("expected version overhead details, got: %s", out)
 	}
 }
+
+func TestTextReporter_VersionOverheadEstimates(t *testing.T) {
+	setNoColor(t)
+	tests := []struct {
+		stats s3.VersionStats
+		want  []string
+	}{
+		{
+			stats: s3.VersionStats{Pages: 3, NoncurrentVersions: 7, NoncurrentSize: 2048, OverheadRatio: 0.25},
+			want:  []string{"Version Overhead: 2.00 KB in 7 noncurrent versions (25.0% of total)"},
+		},
+		{
+			stats: s3.VersionStats{Truncated: true, Pages: 100, NoncurrentSize: 2048, OverheadRatio: 0.25},
+			want:  []string{"Version Overhead: at least 2.00 KB (25.0% of the first 100 pages)", "sizes and counts are lower bounds"},
+		},
+		{
+			stats: s3.VersionStats{Truncated: true, Sampled: true, Pages: 100, OverheadRatio: 0.6, OverheadLow: 0.55, OverheadHigh: 0.65},
+			want:  []string{"Version Overhead (estimated): 60.0% of total, 95% CI 55.0%-65.0%, sampled from 100 pages", "cover the sampled pages only"},
+		},
+	}
+	for _, tt := range tests {
+		var buf bytes.Buffer
+		stats := tt.stats
+		data := DiscoveryData{
+			Summary: analyzer.DiscoverySummary{TotalBuckets: 1, VersionSprawl: []string{"logs"}},
+			Buckets: map[string]*analyzer.BucketDiscovery{"logs": {
+				Name:       "logs",
+				Region:     "us-east-1",
+				Status:     analyzer.StatusVersionSprawl,
+				BucketInfo: &s3.BucketInfo{TotalVersionSize: 8192, VersionCount: 30, VersionStats: &stats},
+			}},
+		}
+		if err := NewTextReporter(&buf).GenerateDiscovery(data); err != nil {
+			t.Fatalf("GenerateDiscovery: %v", err)
+		}
+		for _, want := range tt.want {
+			if !strings.Contains(buf.String(), want) {
+				t.Errorf("output missing %q:\n%s", want, buf.String())
+			}
+		}
+	}
+}
+
+func TestTextReporter_Posture(t *testing.T) {
+	setNoColor(t)
+	var buf bytes.Buffer
+	reporter := NewTextReporter(&buf)
+
+	data := DiscoveryData{
+		Buckets: map[string]*analyzer.BucketDiscovery{},
+		Posture: &analyzer.StoragePosture{
+			TotalBuckets:     4,
+			EstimatedBytes:   2048,
+			EncryptedBuckets: 3,
+			EncryptedPercent: 75,
+			VersionedBuckets: 2,
+			VersionedPercent: 50,
+			LifecycleBuckets: 1,
+			LifecyclePercent: 25,
+		},
+	}
+	if err := reporter.GenerateDiscovery(data); err != nil {
+		t.Fatalf("GenerateDiscovery failed: %v", err)
+	}
+
+	out := buf.String()
+	for _, want := range []string{"Storage Posture", "Estimated Size: 2.00 KB", "Encrypted: 75.0% (3)", "Versioned: 50.0% (2)", "Lifecycle Coverage: 25.0% (1)"} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("expected %q in output, got: %s", want, out)
+		}
+	}
+}
+
+func TestTextReporter_StorageClasses(t *testing.T) {
+	setNoColor(t)
+	var buf bytes.Buffer
+	reporter := NewTextReporter(&buf)
+
+	data := DiscoveryData{
+		Buckets: map[string]*analyzer.BucketDiscovery{
+			"archive": {BucketInfo: &s3.BucketInfo{Name: "archive", SizeBreakdown: &s3.SizeBreakdown{
+				Objects: 3, Size: 3072, Truncated: true,
+				Classes: map[string]*s3.StorageClassUsage{
+					"STANDARD": {Objects: 2, Size: 1024},
+					"GLACIER":  {Objects: 1, Size: 2048},
+				},
+			}}},
+			"sampled": {BucketInfo: &s3.BucketInfo{Name: "sampled"}},
+		},
+	}
+	if err := reporter.GenerateDiscovery(data); err != nil {
+		t.Fatalf("GenerateDiscovery failed: %v", err)
+	}
+
+	out := buf.String()
+	section := out[strings.Index(out, "Storage Classes"):]
+	for _, want := range []string{"archive: 3+ objects, 3.00 KB+", "GLACIER", "STANDARD"} {
+		if !strings.Contains(section, want) {
+			t.Fatalf("expected %q in output, got: %s", want, out)
+		}
+	}
+	if strings.Index(section, "GLACIER") > strings.Index(section, "STANDARD") {
+		t.Errorf("classes should be listed largest first: %s", section)
+	}
+	if strings.Contains(section, "sampled:") {
+		t.Errorf("buckets without a deep listing should be left out: %s", section)
+	}
+}
+
+func TestTextReporter_Vaults(t *testing.T) {
+	setNoColor(t)
+	var buf bytes.Buffer
+	reporter := NewTextReporter(&buf)
+
+	data := Data{
+		Summary: analyzer.Summary{
+			OrphanedVaults: []string{"legacy"},
+			MissingVaults:  []string{"restore-tmp"},
+		},
+		Buckets: map[string]*analyzer.BucketAnalysis{},
+		Vaults: map[string]*analyzer.VaultAnalysis{
+			"legacy":      {Name: "legacy", Region: "us-east-1", Status: analyzer.StatusOrphanedVault, SizeInBytes: 2048},
+			"restore-tmp": {Name: "restore-tmp", Status: analyzer.StatusMissingVault, Message: "Vault referenced in code but does not exist in AWS"},
+		},
+	}
+	if err := reporter.Generate(data); err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+
+	out := buf.String()
+	for _, want := range []string{"Orphaned Vaults: 1", "Missing Vaults: 1", "[ORPHANED_VAULT]: legacy (us-east-1, 2.00 KB)", "[MISSING_VAULT]: restore-tmp"} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("expected %q in output, got: %s", want, out)
+		}
+	}
+}
+
+func TestTextReporter_Permissions(t *testing.T) {
+	setNoColor(t)
+	var buf bytes.Buffer
+	reporter := NewTextReporter(&buf)
+
+	data := Data{
+		Summary: analyzer.Summary{PermissionGaps: []string{"data/out/"}},
+		Buckets: map[string]*analyzer.BucketAnalysis{},
+		Permissions: []*analyzer.PermissionAnalysis{{
+			Bucket: "data", Prefix: "out/", Action: "s3:PutObject", Status: analyzer.StatusPermissionGap,
+			File: "job.py", Line: 4,
+			Denied: []analyzer.DeniedPrincipal{{Principal: "arn:aws:iam::123456789012:role/reader", Decision: "implicitDeny"}},
+		}},
+	}
+	if err := reporter.Generate(data); err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+
+	out := buf.String()
+	for _, want := range []string{"Permission Gaps: 1", "[PERMISSION_GAP]: data/out/ (s3:PutObject, job.py:4)", "role/reader: implicitDeny"} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("expected %q in output, got: %s", want, out)
+		}
+	}
+}
+
+func TestTextReporter_UnsupportedReferences(t *testing.T) {
+	setNoColor(t)
+	var buf bytes.Buffer
+	data := Data{
+		Buckets: map[string]*analyzer.BucketAnalysis{},
+		UnsupportedReferences: []scanner.Reference{
+			{Provider: scanner.ProviderGCS, Bucket: "exports", Prefix: "daily", File: "etl.py", Line: 7},
+			{Provider: scanner.ProviderAzure, Bucket: "acme/backups", File: "backup.sh", Line: 2},
+		},
+	}
+	if err := NewTextReporter(&buf).Generate(data); err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+	out := buf.String()
+	for _, want := range []string{"Unsupported Providers (not checked)", "[GCS] gs://exports/daily (etl.py:7)", "[AZURE] https://acme.blob.core.windows.net/backups (backup.sh:2)"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected %q in output:\n%s", want, out)
+		}
+	}
+}
+
+func TestTextReporter_Truncated(t *testing.T) {
+	setNoColor(t)
+	var buf bytes.Buffer
+	reporter := NewTextReporter(&buf)
+
+	data := DiscoveryData{
+		Version:   "0.1.0",
+		Timestamp: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+		Buckets:   map[string]*analyzer.BucketDiscovery{},
+		Truncated: &Truncation{Reason: "interrupted by signal", UninspectedBuckets: []string{"logs", "media"}},
+	}
+	if err := reporter.GenerateDiscovery(data); err != nil {
+		t.Fatalf("GenerateDiscovery failed: %v", err)
+	}
+	output := buf.String()
+	for _, want := range []string{"PARTIAL REPORT: interrupted by signal", "Uninspected Buckets (2): logs, media"} {
+		if !strings.Contains(output, want) {
+			t.Errorf("expected %q in output:\n%s", want, output)
+		}
+	}
+}
+
+func TestTextReporter_DeadlineSkips(t *testing.T) {
+	setNoColor(t)
+	var buf bytes.Buffer
+	data := DiscoveryData{
+		Buckets: map[string]*analyzer.BucketDiscovery{},
+		Summary: analyzer.DiscoverySummary{DeadlineSkips: []analyzer.DeadlineSkip{
+			{Bucket: "archive", Checks: []string{"activity", "versions"}},
+		}},
+	}
+	if err := NewTextReporter(&buf).GenerateDiscovery(data); err != nil {
+		t.Fatalf("GenerateDiscovery failed: %v", err)
+	}
+	output := buf.String()
+	for _, want := range []string{"Skipped Checks (deadline)", "archive: activity, versions", "--per-bucket-deadline"} {
+		if !strings.Contains(output, want) {
+			t.Errorf("expected %q in output:\n%s", want, output)
+		}
+	}
+}
+
+func TestTextReporter_TuningHints(t *testing.T) {
+	setNoColor(t)
+	var buf bytes.Buffer
+	data := Data{
+		Buckets:     map[string]*analyzer.BucketAnalysis{},
+		TuningHints: []analyzer.TuningHint{{Message: "70% of your prefixes exceed stale-days=90; consider 180"}},
+	}
+	if err := NewTextReporter(&buf).Generate(data); err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+	output := buf.String()
+	for _, want := range []string{"Tuning Hints", "70% of your prefixes exceed stale-days=90; consider 180", "--tuning-patch"} {
+		if !strings.Contains(output, want) {
+			t.Errorf("expected %q in output:\n%s", want, output)
+		}
+	}
+}
+
+func TestTextReporter_BaselineChanges(t *testing.T) {
+	setNoColor(t)
+	var buf bytes.Buffer
+	data := Data{
+		Buckets: map[string]*analyzer.BucketAnalysis{},
+		Changes: &BaselineChanges{
+			Baseline:  "base.json",
+			New:       []BaselineChange{{Type: "MISSING_BUCKET", Bucket: "prod-data", Severity: "high"}},
+			Resolved:  []BaselineChange{{Type: "STALE_PREFIX", Bucket: "logs", Prefix: "old/", Severity: "low"}},
+			Unchanged: 3,
+		},
+	}
+	if err := NewTextReporter(&buf).Generate(data); err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+	output := buf.String()
+	for _, want := range []string{
+		"Changes Since Baseline (base.json)",
+		"New: 1  Resolved: 1  Unchanged: 3",
+		"HIGH\n  + MISSING_BUCKET: prod-data",
+		"LOW\n  - STALE_PREFIX: logs/old/",
+	} {
+		if !strings.Contains(output, want) {
+			t.Errorf("expected %q in output:\n%s", want, output)
+		}
+	}
+
+	buf.Reset()
+	if err := NewTextReporter(&buf).GenerateDiscovery(DiscoveryData{Changes: data.Changes}); err != nil {
+		t.Fatalf("GenerateDiscovery failed: %v", err)
+	}
+	if !strings.Contains(buf.String(), "Changes Since Baseline") {
+		t.Errorf("expected baseline changes in discovery output:\n%s", buf.String())
+	}
+}
+
+func TestTextReporter_Regions(t *testing.T) {
+	setNoColor(t)
+	data := DiscoveryData{
+		Buckets: map[string]*analyzer.BucketDiscovery{},
+		Regions: []analyzer.RegionSummary{
+			{Region: "us-east-1", TotalBuckets: 4, Findings: 2, Unused: 1, WasteBytes: 2048},
+			{Region: "eu-west-1", TotalBuckets: 1},
+		},
+	}
+	var buf bytes.Buffer
+	if err := NewTextReporter(&buf).GenerateDiscovery(data); err != nil {
+		t.Fatalf("GenerateDiscovery failed: %v", err)
+	}
+	output := buf.String()
+	for _, want := range []string{"By Region", "Waste", "us-east-1", "2.00 KB"} {
+		if !strings.Contains(output, want) {
+			t.Errorf("expected %q in output:\n%s", want, output)
+		}
+	}
+
+	buf.Reset()
+	data.Regions = data.Regions[:1]
+	if err := NewTextReporter(&buf).GenerateDiscovery(data); err != nil {
+		t.Fatalf("GenerateDiscovery failed: %v", err)
+	}
+	if strings.Contains(buf.String(), "By Region") {
+		t.Errorf("single-region reports should not print the breakdown:\n%s", buf.String())
+	}
+}
+
+func TestTextReporter_ScoredRecommendations(t *testing.T) {
+	setNoColor(t)
+	data := DiscoveryData{
+		Buckets: map[string]*analyzer.BucketDiscovery{
+			"old-logs": {
+				Name:   "old-logs",
+				Status: analyzer.StatusRisky,
+				ScoredRecommendations: []analyzer.Recommendation{
+					{Action: "Add lifecycle policy", ImpactBytes: 2 << 30, MonthlySavings: 0.046, RiskReduced: 30, Effort: "small"},
+					{Action: "Confirm retention", Effort: "medium"},
+				},
+			},
+		},
+		Summary: analyzer.DiscoverySummary{RiskyBuckets: []string{"old-logs"}},
+	}
+	var buf bytes.Buffer
+	if err := NewTextReporter(&buf).GenerateDiscovery(data); err != nil {
+		t.Fatalf("GenerateDiscovery failed: %v", err)
+	}
+	output := buf.String()
+	for _, want := range []string{
+		"Add lifecycle policy (impact: 2.00 GB, ~$0.05/month, -30 risk, effort: small)",
+		"Confirm retention (impact: none measured, effort: medium)",
+	} {
+		if !strings.Contains(output, want) {
+			t.Errorf("expected %q in output:\n%s", want, output)
+		}
+	}
+}
+
+func TestTextReporter_Accounts(t *testing.T) {
+	setNoColor(t)
+	data := Data{
+		Buckets: map[string]*analyzer.BucketAnalysis{},
+		Accounts: []analyzer.AccountSummary{
+			{Account: "111111111111", TotalBuckets: 3, Findings: 3,
+				Statuses: map[analyzer.Status]int{analyzer.StatusMissingBucket: 1, analyzer.StatusStalePrefix: 2}},
+			{Account: "222222222222", TotalBuckets: 1},
+		},
+	}
+	var buf bytes.Buffer
+	if err := NewTextReporter(&buf).Generate(data); err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+	output := buf.String()
+	for _, want := range []string{"By Account", "111111111111", "STALE_PREFIX 2, MISSING_BUCKET 1"} {
+		if !strings.Contains(output, want) {
+			t.Errorf("expected %q in output:\n%s", want, output)
+		}
+	}
+}
+
+func TestTextReporter_PrefixNearMiss(t *testing.T) {
+	setNoColor(t)
+	data := Data{
+		Buckets: map[string]*analyzer.BucketAnalysis{
+			"data": {Name: "data", Status: analyzer.StatusOK, Prefixes: []analyzer.PrefixAnalysis{
+				{Prefix: "exports/Daily/", Status: analyzer.StatusPrefixNearMiss, Suggestion: "exports/daily/"},
+			}},
+		},
+		Summary: analyzer.Summary{PrefixNearMisses: []string{"data/exports/Daily/"}},
+	}
+	var buf bytes.Buffer
+	if err := NewTextReporter(&buf).Generate(data); err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+	output := buf.String()
+	for _, want := range []string{"Prefix Near Misses: 1", "[PREFIX_NEAR_MISS]: data/exports/Daily/", "Did you mean: exports/daily/"} {
+		if !strings.Contains(output, want) {
+			t.Errorf("expected %q in output:\n%s", want, output)
+		}
+	}
+}
+
+func TestTextReporter_Blame(t *testing.T) {
+	setNoColor(t)
+	blame := &scanner.Blame{Author: "Ada", AuthorEmail: "ada@example.com", Commit: "0123456789abcdef0123", Time: time.Date(2026, 3, 4, 0, 0, 0, 0, time.UTC)}
+	data := Data{
+		Buckets: map[string]*analyzer.BucketAnalysis{
+			"gone": {Name: "gone", Status: analyzer.StatusMissingBucket, Blame: blame},
+		},
+		Summary: analyzer.Summary{MissingBuckets: []string{"gone"}},
+	}
+	var buf bytes.Buffer
+	if err := NewTextReporter(&buf).Generate(data); err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+	if want := "Last changed by: Ada <ada@example.com> in 0123456789ab (2026-03-04)"; !strings.Contains(buf.String(), want) {
+		t.Errorf("expected %q in output:\n%s", want, buf.String())
+	}
+}
+
+func TestTextReporter_MissingBucketSuggestions(t *testing.T) {
+	setNoColor(t)
+	data := Data{
+		Buckets: map[string]*analyzer.BucketAnalysis{
+			"orders-prod": {Name: "orders-prod", Status: analyzer.StatusMissingBucket, Suggestions: []string{"orders-prd", "orders-staging"}},
+		},
+		Summary: analyzer.Summary{MissingBuckets: []string{"orders-prod"}},
+	}
+	var buf bytes.Buffer
+	if err := NewTextReporter(&buf).Generate(data); err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+	if output := buf.String(); !strings.Contains(output, "Did you mean: orders-prd, orders-staging") {
+		t.Errorf("expected bucket suggestions in output:\n%s", output)
+	}
+
+	// A message naming the suggestions is printed alone
+	data.Buckets["orders-prod"].Message = `Bucket referenced in code but does not exist in AWS; did you mean "orders-prd", "orders-staging"?`
+	buf.Reset()
+	if err := NewTextReporter(&buf).Generate(data); err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+	if output := buf.String(); strings.Count(output, "orders-staging") != 1 || strings.Contains(output, "Did you mean:") {
+		t.Errorf("expected the suggestions printed once:\n%s", output)
+	}
+}
+
+func TestTextReporter_Spend(t *testing.T) {
+	setNoColor(t)
+	var buf bytes.Buffer
+	reporter := NewTextReporter(&buf)
+
+	data := DiscoveryData{
+		Summary: analyzer.DiscoverySummary{UnusedBuckets: []string{"old-logs"}},
+		Buckets: map[string]*analyzer.BucketDiscovery{
+			"old-logs": {Name: "old-logs", Region: "us-east-1", Status: analyzer.StatusUnusedBucket,
+				Spend: &analyzer.BucketSpend{MonthToDate: 12.5, Currency: "USD", TagValue: "logs", SharedWith: []string{"new-logs"}}},
+		},
+		Spend: &analyzer.SpendSummary{TagKey: "Name", Start: "2024-05-01", End: "2024-05-16", Currency: "USD",
+			Total: 20, Attributed: 12.5, Untagged: 7.5, Buckets: 2},
+	}
+	if err := reporter.GenerateDiscovery(data); err != nil {
+		t.Fatalf("GenerateDiscovery failed: %v", err)
+	}
+
+	out := buf.String()
+	for _, want := range []string{
+		"S3 Spend (2024-05-01 to 2024-05-16)",
+		"Month to Date: $20.00",
+		"Matched to Buckets by Name: $12.50 (2 buckets)",
+		"Month-to-Date Spend: $12.50 (shared with new-logs)",
+	} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("expected %q in output, got: %s", want, out)
+		}
+	}
+}
+
+func TestTextReporter_Access(t *testing.T) {
+	setNoColor(t)
+	var buf bytes.Buffer
+	reporter := NewTextReporter(&buf)
+
+	since := time.Date(2023, 12, 1, 0, 0, 0, 0, time.UTC)
+	read := time.Date(2024, 5, 2, 0, 0, 0, 0, time.UTC)
+	data := DiscoveryData{
+		Summary: analyzer.DiscoverySummary{UnusedBuckets: []string{"quiet"}, InactiveBuckets: []string{"stale"}},
+		Buckets: map[string]*analyzer.BucketDiscovery{
+			"quiet": {Name: "quiet", Status: analyzer.StatusUnusedBucket,
+				BucketInfo: &s3.BucketInfo{Name: "quiet", Access: &s3.AccessInfo{Since: since}}},
+			"stale": {Name: "stale", Status: analyzer.StatusInactive,
+				BucketInfo: &s3.BucketInfo{Name: "stale", Access: &s3.AccessInfo{Since: since, LastAccess: &read, Reads: 4}}},
+		},
+	}
+	if err := reporter.GenerateDiscovery(data); err != nil {
+		t.Fatalf("GenerateDiscovery failed: %v", err)
+	}
+
+	out := buf.String()
+	for _, want := range []string{
+		"Object Access: none since 2023-12-01",
+		"Last Object Access: 2024-05-02 (4 reads, 0 writes since 2023-12-01)",
+	} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("expected %q in output, got: %s", want, out)
+		}
+	}
+}
+
+func TestTextReporter_ObjectAges(t *testing.T) {
+	setNoColor(t)
+	var buf bytes.Buffer
+	reporter := NewTextReporter(&buf)
+
+	data := DiscoveryData{
+		Buckets: map[string]*analyzer.BucketDiscovery{
+			"media": {Name: "media", BucketInfo: &s3.BucketInfo{Name: "media", Inventory: &s3.InventoryReport{
+				Date:               time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC),
+				Objects:            3,
+				Size:               2048,
+				AllVersions:        true,
+				Versions:           5,
+				NoncurrentVersions: 2,
+				NoncurrentSize:     1024,
+				Ages:               []s3.InventoryAge{{Band: "<30d", Objects: 1, Size: 1024}, {Band: ">2y", Objects: 2, Size: 1024}},
+			}}},
+		},
+	}
+	if err := reporter.GenerateDiscovery(data); err != nil {
+		t.Fatalf("GenerateDiscovery failed: %v", err)
+	}
+
+	out := buf.String()
+	for _, want := range []string{
+		"Object Ages (S3 Inventory)",
+		"media: 3 objects, 2.00 KB as of 2024-06-01",
+		"5 versions, 2 noncurrent (1.00 KB), 0 delete markers",
+		">2y",
+	} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("expected %q in output, got: %s", want, out)
+		}
+	}
+}
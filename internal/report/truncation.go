@@ -0,0 +1,41 @@
+package report
+
+import (
+	"sort"
+
+	"github.com/ppiankov/s3spectre/internal/s3"
+)
+
+// Truncation marks a partial report, written when a run was interrupted
+// before every bucket was inspected
+type Truncation struct {
+	Reason string `json:"reason"`
+	// UninspectedBuckets lists buckets whose inspection did not finish or
+	// never started; their findings are missing from the report
+	UninspectedBuckets []string `json:"uninspected_buckets"`
+}
+
+// NewTruncation lists the uninspected buckets: those in expected with no
+// inspection result, and those whose inspection was interrupted
+func NewTruncation(reason string, expected []string, inspected map[string]*s3.BucketInfo) *Truncation {
+	seen := make(map[string]bool)
+	uninspected := make([]string, 0)
+	add := func(name string) {
+		if !seen[name] {
+			seen[name] = true
+			uninspected = append(uninspected, name)
+		}
+	}
+	for _, name := range expected {
+		if inspected[name] == nil {
+			add(name)
+		}
+	}
+	for name, info := range inspected {
+		if info.Interrupted {
+			add(name)
+		}
+	}
+	sort.Strings(uninspected)
+	return &Truncation{Reason: reason, UninspectedBuckets: uninspected}
+}
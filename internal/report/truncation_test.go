@@ -0,0 +1,24 @@
+package report
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/ppiankov/s3spectre/internal/s3"
+)
+
+func TestNewTruncation(t *testing.T) {
+	inspected := map[string]*s3.BucketInfo{
+		"done":    {Name: "done", Exists: true},
+		"halfway": {Name: "halfway", Exists: true, Interrupted: true},
+	}
+	got := NewTruncation("interrupted", []string{"done", "pending", "halfway", "pending"}, inspected)
+
+	want := []string{"halfway", "pending"}
+	if !reflect.DeepEqual(got.UninspectedBuckets, want) {
+		t.Fatalf("expected uninspected %v, got %v", want, got.UninspectedBuckets)
+	}
+	if got.Reason != "interrupted" {
+		t.Fatalf("unexpected reason %q", got.Reason)
+	}
+}
@@ -22,12 +22,36 @@ type Data struct {
 	Summary    analyzer.Summary               `json:"summary"`
 	Buckets    map[string]*analyzer.BucketAnalysis `json:"buckets"`
 	References []scanner.Reference            `json:"references,omitempty"`
+	// Vaults and VaultReferences are set when Glacier vaults are cross-referenced
+	Vaults          map[string]*analyzer.VaultAnalysis `json:"vaults,omitempty"`
+	VaultReferences []scanner.VaultReference           `json:"vault_references,omitempty"`
+	// UnsupportedReferences point at object stores other than S3, such as
+	// Google Cloud Storage and Azure Blob Storage; they are listed, not checked
+	UnsupportedReferences []scanner.Reference `json:"unsupported_references,omitempty"`
+	// Permissions is set when application principals are simulated
+	Permissions []*analyzer.PermissionAnalysis `json:"permissions,omitempty"`
+	// Correlations are set with --correlate
+	Correlations []analyzer.Correlation `json:"correlations,omitempty"`
+	// Stats holds run statistics: files, references, API calls and phases
+	Stats *RunStats `json:"stats,omitempty"`
+	// Truncated is set when the run was interrupted and the report is partial
+	Truncated *Truncation `json:"truncated,omitempty"`
+	// TuningHints suggest thresholds that fit the observed data better
+	TuningHints []analyzer.TuningHint `json:"tuning_hints,omitempty"`
+	// Changes compares the findings with --baseline; only text output shows it
+	Changes *BaselineChanges `json:"-"`
+	// Accounts groups findings by owning account in multi-account runs
+	Accounts []analyzer.AccountSummary `json:"accounts,omitempty"`
 }
 
 // Config contains scan configuration
 type Config struct {
 	RepoPath           string `json:"repo_path"`
+	BucketsFile        string `json:"buckets_file,omitempty"`
 	AWSProfile         string `json:"aws_profile,omitempty"`
 	AWSRegion          string `json:"aws_region,omitempty"`
+	AccountID          string `json:"account_id,omitempty"`
 	StaleThresholdDays int    `json:"stale_threshold_days"`
+	// ChangedSince is the base ref of a --changed-only scan
+	ChangedSince string `json:"changed_since,omitempty"`
 }
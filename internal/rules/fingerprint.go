@@ -0,0 +1,46 @@
+package rules
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+)
+
+// fingerprintVersion is hashed into every fingerprint so the scheme can change
+// without colliding with older values
+const fingerprintVersion = "v1"
+
+// Fingerprint identifies a finding by content: its rule, account, bucket and
+// normalized prefix. Messages and report order do not affect it, and a status
+// shares the fingerprint of the rule it produces (LIFECYCLE_MISCONFIG and
+// LIFECYCLE_GAP), so renames in output do not register as new findings.
+func Fingerprint(rule, account, bucket, prefix string) string {
+	id := normalize(rule)
+	if r, ok := Lookup(rule); ok {
+		id = r.ID
+	}
+	sum := sha256.Sum256([]byte(strings.Join([]string{
+		fingerprintVersion,
+		id,
+		strings.TrimSpace(account),
+		strings.ToLower(strings.TrimSpace(bucket)),
+		NormalizePrefix(prefix),
+	}, "\x00")))
+	return hex.EncodeToString(sum[:16])
+}
+
+// NormalizePrefix canonicalizes a prefix for comparison: no leading slash,
+// no repeated slashes and a single trailing slash
+func NormalizePrefix(prefix string) string {
+	parts := strings.Split(strings.TrimSpace(prefix), "/")
+	kept := parts[:0]
+	for _, part := range parts {
+		if part != "" {
+			kept = append(kept, part)
+		}
+	}
+	if len(kept) == 0 {
+		return ""
+	}
+	return strings.Join(kept, "/") + "/"
+}
@@ -0,0 +1,56 @@
+package rules
+
+import "testing"
+
+func TestFingerprint_Stable(t *testing.T) {
+	base := Fingerprint(StalePrefix, "123456789012", "data", "logs/2024/")
+	same := []struct {
+		rule, account, bucket, prefix string
+	}{
+		{"s3spectre/STALE_PREFIX", "123456789012", "data", "logs/2024/"},
+		{"stale_prefix", "123456789012", "DATA", "/logs//2024"},
+	}
+	for _, tt := range same {
+		if got := Fingerprint(tt.rule, tt.account, tt.bucket, tt.prefix); got != base {
+			t.Fatalf("Fingerprint(%q, %q, %q, %q) = %s, want %s", tt.rule, tt.account, tt.bucket, tt.prefix, got, base)
+		}
+	}
+
+	different := []struct {
+		rule, account, bucket, prefix string
+	}{
+		{MissingPrefix, "123456789012", "data", "logs/2024/"},
+		{StalePrefix, "210987654321", "data", "logs/2024/"},
+		{StalePrefix, "123456789012", "other", "logs/2024/"},
+		{StalePrefix, "123456789012", "data", "logs/2025/"},
+	}
+	for _, tt := range different {
+		if got := Fingerprint(tt.rule, tt.account, tt.bucket, tt.prefix); got == base {
+			t.Fatalf("expected %+v to have a different fingerprint", tt)
+		}
+	}
+	if len(base) != 32 {
+		t.Fatalf("expected 32 hex characters, got %q", base)
+	}
+}
+
+func TestFingerprint_StatusAlias(t *testing.T) {
+	if Fingerprint(StatusLifecycleMisconfig, "", "b", "") != Fingerprint(LifecycleGap, "", "b", "") {
+		t.Fatalf("expected a status and its rule to share a fingerprint")
+	}
+}
+
+func TestNormalizePrefix(t *testing.T) {
+	cases := map[string]string{
+		"":            "",
+		"/":           "",
+		"logs":        "logs/",
+		"/logs//a/":   "logs/a/",
+		" data/raw/ ": "data/raw/",
+	}
+	for in, want := range cases {
+		if got := NormalizePrefix(in); got != want {
+			t.Fatalf("NormalizePrefix(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
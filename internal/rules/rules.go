@@ -0,0 +1,703 @@
+package rules
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// SARIFPrefix namespaces built-in rule IDs in SARIF output
+const SARIFPrefix = "s3spectre/"
+
+// Analyzer statuses. Every status except OK produces the rule whose Status
+// field names it.
+const (
+	StatusOK                     = "OK"
+	StatusMissingBucket          = "MISSING_BUCKET"
+	StatusUnusedBucket           = "UNUSED_BUCKET"
+	StatusMissingPrefix          = "MISSING_PREFIX"
+	StatusStalePrefix            = "STALE_PREFIX"
+	StatusVersionSprawl          = "VERSION_SPRAWL"
+	StatusLifecycleMisconfig     = "LIFECYCLE_MISCONFIG"
+	StatusRisky                  = "RISKY"
+	StatusInactive               = "INACTIVE"
+	StatusOrphanedVault          = "ORPHANED_VAULT"
+	StatusMissingVault           = "MISSING_VAULT"
+	StatusPermissionGap          = "PERMISSION_GAP"
+	StatusNoGuardDutyS3          = "NO_GUARDDUTY_S3"
+	StatusNoAccountPAB           = "NO_ACCOUNT_PAB"
+	StatusLowLifecycle           = "LOW_LIFECYCLE"
+	StatusPrefixNearMiss         = "PREFIX_NEAR_MISS"
+	StatusEnvDrift               = "ENV_DRIFT"
+	StatusWildcardPrincipal      = "WILDCARD_PRINCIPAL"
+	StatusCrossAccount           = "CROSS_ACCOUNT_ACCESS"
+	StatusInsecureTransport      = "INSECURE_TRANSPORT"
+	StatusReplicationDestination = "REPLICATION_DESTINATION"
+	StatusNoLogging              = "NO_LOGGING"
+	StatusSensitiveDataExposed   = "SENSITIVE_DATA_EXPOSED"
+	StatusCorrelated             = "CORRELATED_FINDING"
+	StatusTimedOut               = "TIMED_OUT"
+)
+
+// Built-in rule IDs
+const (
+	MissingBucket  = "MISSING_BUCKET"
+	MissingPrefix  = "MISSING_PREFIX"
+	StalePrefix    = "STALE_PREFIX"
+	UnusedBucket   = "UNUSED_BUCKET"
+	VersionSprawl  = "VERSION_SPRAWL"
+	LifecycleGap   = "LIFECYCLE_GAP"
+	PublicBucket   = "PUBLIC_BUCKET"
+	NoEncryption   = "NO_ENCRYPTION"
+	InactiveBucket = "INACTIVE_BUCKET"
+	RiskyBucket    = "RISKY_BUCKET"
+	OrphanedVault  = "ORPHANED_VAULT"
+	MissingVault   = "MISSING_VAULT"
+	PermissionGap  = "PERMISSION_GAP"
+	NoGuardDutyS3  = "NO_GUARDDUTY_S3"
+	NoAccountPAB   = "NO_ACCOUNT_PAB"
+	LowLifecycle   = "LOW_LIFECYCLE"
+	PrefixNearMiss = "PREFIX_NEAR_MISS"
+	EnvDrift       = "ENV_DRIFT"
+	// Bucket policy rules
+	WildcardPrincipal = "WILDCARD_PRINCIPAL"
+	CrossAccount      = "CROSS_ACCOUNT_ACCESS"
+	InsecureTransport = "INSECURE_TRANSPORT"
+	// ReplicationDestination explains why a bucket is not unused
+	ReplicationDestination = "REPLICATION_DESTINATION"
+	// NoLogging covers production buckets whose requests are not recorded
+	NoLogging = "NO_LOGGING"
+	// SensitiveDataExposed covers public or unencrypted buckets that Macie
+	// found sensitive data in
+	SensitiveDataExposed = "SENSITIVE_DATA_EXPOSED"
+	// Correlated joins a sibling Spectre tool's finding to a flagged bucket
+	Correlated = "CORRELATED_FINDING"
+	// TimedOut marks a bucket whose checks a timeout budget cut short
+	TimedOut = "TIMED_OUT"
+)
+
+// Rule categories
+const (
+	CategoryDrift    = "drift"
+	CategoryCost     = "cost"
+	CategorySecurity = "security"
+	CategoryHygiene  = "hygiene"
+)
+
+// Remediation effort ratings
+const (
+	EffortSmall  = "small"
+	EffortMedium = "medium"
+	EffortLarge  = "large"
+)
+
+// Rule is a built-in check. It backs status severities, SARIF and LSP rule
+// metadata, the SpectreHub envelope and the explain command.
+type Rule struct {
+	ID string
+	// Status is the analyzer status that produces the rule, if any
+	Status          string
+	Name            string
+	Description     string
+	Category        string
+	DefaultSeverity string
+	// ScanHubSeverity and DiscoveryHubSeverity are the SpectreHub envelope
+	// severities in scan and discover reports: scans rank code drift above
+	// cost findings, discovery ranks bucket risk
+	ScanHubSeverity      string
+	DiscoveryHubSeverity string
+	Rationale            string
+	Detection            string
+	Thresholds           []Threshold
+	Remediation          string
+	// Action is the remediation as a short imperative task title
+	Action string
+	// Effort roughly rates the remediation work: small, medium or large
+	Effort     string
+	References []string
+}
+
+// Threshold is a tunable setting that changes when a rule fires
+type Threshold struct {
+	Flag        string
+	ConfigKey   string
+	Default     string
+	Description string
+}
+
+var registry = []Rule{
+	{
+		ID:                   MissingBucket,
+		Status:               StatusMissingBucket,
+		Name:                 "MissingBucket",
+		Description:          "Bucket referenced in code but does not exist in AWS",
+		Category:             CategoryDrift,
+		DefaultSeverity:      SeverityMedium,
+		ScanHubSeverity:      SeverityHigh,
+		DiscoveryHubSeverity: SeverityInfo,
+		Rationale:            "Code that reads or writes a bucket that does not exist fails at runtime, and a deleted bucket name can be re-registered by another account.",
+		Detection:            "scan: a bucket name found in the repository returns NotFound from HeadBucket in every scanned region. Existing buckets with similar names, or names that differ only in an environment segment, are suggested.",
+		Remediation:          "Create the bucket, fix the reference, or remove the dead code path. If the bucket was deleted on purpose, remove every reference so the name cannot be claimed and used by someone else.",
+		Action:               "Create the bucket or remove its code references",
+		Effort:               EffortSmall,
+		References: []string{
+			"https://docs.aws.amazon.com/AmazonS3/latest/userguide/create-bucket-overview.html",
+		},
+	},
+	{
+		ID:                   MissingPrefix,
+		Status:               StatusMissingPrefix,
+		Name:                 "MissingPrefix",
+		Description:          "Prefix referenced in code but no objects were found",
+		Category:             CategoryDrift,
+		DefaultSeverity:      SeverityMedium,
+		ScanHubSeverity:      SeverityMedium,
+		DiscoveryHubSeverity: SeverityInfo,
+		Rationale:            "A referenced prefix with no objects usually means a typo, a renamed path, or a producer that stopped writing.",
+		Detection:            "scan: listing the referenced prefix in an existing bucket returns no objects.",
+		Remediation:          "Check the prefix spelling against the bucket layout and confirm the producing job still runs. Remove the reference if the data is gone for good.",
+		Action:               "Fix or remove the reference to the empty prefix",
+		Effort:               EffortSmall,
+		References: []string{
+			"https://docs.aws.amazon.com/AmazonS3/latest/userguide/using-prefixes.html",
+		},
+	},
+	{
+		ID:                   PrefixNearMiss,
+		Status:               StatusPrefixNearMiss,
+		Name:                 "PrefixNearMiss",
+		Description:          "Referenced prefix is missing but a very similar prefix exists",
+		Category:             CategoryDrift,
+		DefaultSeverity:      SeverityMedium,
+		ScanHubSeverity:      SeverityMedium,
+		DiscoveryHubSeverity: SeverityMedium,
+		Rationale:            "A missing prefix next to a nearly identical one is almost always a typo, a case mismatch or a singular/plural slip, and the code is reading or writing the wrong place.",
+		Detection:            "scan: the referenced prefix has no objects, and listing its parent finds a prefix or key that differs only in case, leading or trailing slashes, or by at most one edit per five characters.",
+		Remediation:          "Compare the reference with the suggested prefix and correct whichever side is wrong. If both paths are meant to exist, the reference is a plain MISSING_PREFIX.",
+		Action:               "Point the reference at the existing near-match prefix",
+		Effort:               EffortSmall,
+		References: []string{
+			"https://docs.aws.amazon.com/AmazonS3/latest/userguide/using-prefixes.html",
+		},
+	},
+	{
+		ID:                   StalePrefix,
+		Status:               StatusStalePrefix,
+		Name:                 "StalePrefix",
+		Description:          "Prefix has not been modified recently",
+		Category:             CategoryDrift,
+		DefaultSeverity:      SeverityLow,
+		ScanHubSeverity:      SeverityLow,
+		DiscoveryHubSeverity: SeverityInfo,
+		Rationale:            "Prefixes that code still references but nothing writes to point at abandoned pipelines and data that keeps accruing storage cost.",
+		Detection:            "scan: the newest object under a referenced prefix is older than the stale threshold.",
+		Thresholds: []Threshold{
+			{Flag: "--stale-days", ConfigKey: "stale_days", Default: "90", Description: "days since the newest object was modified"},
+		},
+		Remediation: "Confirm whether the producer is retired. Archive or expire the data with a lifecycle rule, and drop the code reference if it is no longer read.",
+		Action:      "Archive or expire the stale prefix",
+		Effort:      EffortSmall,
+		References: []string{
+			"https://docs.aws.amazon.com/AmazonS3/latest/userguide/object-lifecycle-mgmt.html",
+		},
+	},
+	{
+		ID:                   UnusedBucket,
+		Status:               StatusUnusedBucket,
+		Name:                 "UnusedBucket",
+		Description:          "Bucket appears unused",
+		Category:             CategoryCost,
+		DefaultSeverity:      SeverityLow,
+		ScanHubSeverity:      SeverityMedium,
+		DiscoveryHubSeverity: SeverityMedium,
+		Rationale:            "Unused buckets cost money, widen the attack surface, and hide data nobody owns.",
+		Detection:            "scan --check-unused: a bucket scores 100 points when not referenced in code, 50 when empty and 20 for a deprecated tag, and is unused at 150 points. discover: a bucket over the risk threshold that is empty and inactive.",
+		Thresholds: []Threshold{
+			{Flag: "--unused-threshold-days", Default: "180", Description: "days without activity before a scan bucket can be unused"},
+			{Flag: "--inactive-days", ConfigKey: "inactive_days", Default: "180", Description: "days without activity before a discovered bucket is inactive"},
+		},
+		Remediation: "Confirm ownership through tags or CloudTrail, then empty and delete the bucket, or tag it with an owner if it is still needed. A bucket with compliance-mode Object Lock retention cannot be emptied until it expires; exclude it from cleanup.",
+		Action:      "Confirm the owner, then empty and delete the unused bucket",
+		Effort:      EffortMedium,
+		References: []string{
+			"https://docs.aws.amazon.com/AmazonS3/latest/userguide/delete-bucket.html",
+		},
+	},
+	{
+		ID:                   VersionSprawl,
+		Status:               StatusVersionSprawl,
+		Name:                 "VersionSprawl",
+		Description:          "Versioning enabled without lifecycle rules",
+		Category:             CategoryCost,
+		DefaultSeverity:      SeverityLow,
+		ScanHubSeverity:      SeverityMedium,
+		DiscoveryHubSeverity: SeverityLow,
+		Rationale:            "With versioning on and no lifecycle rules, every overwrite and delete keeps a noncurrent version forever, so storage grows without bound.",
+		Detection:            "The bucket has versioning enabled and no lifecycle configuration rules.",
+		Remediation:          "Add a lifecycle rule with NoncurrentVersionExpiration (for example 30 days) and expire delete markers.",
+		Action:               "Add a noncurrent version expiration rule",
+		Effort:               EffortSmall,
+		References: []string{
+			"https://docs.aws.amazon.com/AmazonS3/latest/userguide/Versioning.html",
+			"https://docs.aws.amazon.com/AmazonS3/latest/userguide/object-lifecycle-mgmt.html",
+		},
+	},
+	{
+		ID:                   LifecycleGap,
+		Status:               StatusLifecycleMisconfig,
+		Name:                 "LifecycleGap",
+		Description:          "Lifecycle rules are missing for the bucket",
+		Category:             CategoryCost,
+		DefaultSeverity:      SeverityLow,
+		ScanHubSeverity:      SeverityMedium,
+		DiscoveryHubSeverity: SeverityInfo,
+		Rationale:            "Buckets that hold many objects and have no lifecycle rules keep data in its original storage class forever.",
+		Detection:            "scan: a bucket with no lifecycle rules has a referenced prefix holding more than 100 objects.",
+		Remediation:          "Add lifecycle rules that move cold data to cheaper storage classes and expire temporary data.",
+		Action:               "Add lifecycle rules for the bucket's data",
+		Effort:               EffortMedium,
+		References: []string{
+			"https://docs.aws.amazon.com/AmazonS3/latest/userguide/object-lifecycle-mgmt.html",
+			"https://docs.aws.amazon.com/AmazonS3/latest/userguide/storage-class-intro.html",
+		},
+	},
+	{
+		ID:                   PublicBucket,
+		Name:                 "PublicBucket",
+		Description:          "Bucket is publicly accessible",
+		Category:             CategorySecurity,
+		DefaultSeverity:      SeverityHigh,
+		ScanHubSeverity:      SeverityHigh,
+		DiscoveryHubSeverity: SeverityHigh,
+		Rationale:            "Public buckets are the most common cause of S3 data exposure.",
+		Detection:            "discover --check-public: GetBucketAcl grants to AllUsers or AuthenticatedUsers make the bucket public unless IgnorePublicAcls is on for the bucket or the account, and a bucket policy GetBucketPolicyStatus judges public does unless RestrictPublicBuckets is. Buckets read from AWS Config carry only their public access block and are not judged.",
+		Remediation:          "Turn on S3 Block Public Access for the bucket (or account). Remove legacy ACL grants to AllUsers and AuthenticatedUsers, or disable ACLs with the BucketOwnerEnforced object ownership setting. Serve public content through CloudFront with origin access control instead.",
+		Action:               "Turn on Block Public Access for the bucket",
+		Effort:               EffortMedium,
+		References: []string{
+			"https://docs.aws.amazon.com/AmazonS3/latest/userguide/access-control-block-public-access.html",
+			"https://docs.aws.amazon.com/AmazonS3/latest/userguide/about-object-ownership.html",
+		},
+	},
+	{
+		ID:                   NoEncryption,
+		Name:                 "NoEncryption",
+		Description:          "Bucket does not have default encryption enabled",
+		Category:             CategorySecurity,
+		DefaultSeverity:      SeverityMedium,
+		ScanHubSeverity:      SeverityMedium,
+		DiscoveryHubSeverity: SeverityMedium,
+		Rationale:            "Without a default encryption configuration, objects depend on every writer to request encryption.",
+		Detection:            "discover --check-encryption: GetBucketEncryption returns no server-side encryption configuration.",
+		Remediation:          "Set default encryption to SSE-S3 or SSE-KMS. Use a bucket key with KMS to reduce request costs.",
+		Action:               "Enable default encryption",
+		Effort:               EffortSmall,
+		References: []string{
+			"https://docs.aws.amazon.com/AmazonS3/latest/userguide/bucket-encryption.html",
+		},
+	},
+	{
+		ID:                   WildcardPrincipal,
+		Status:               StatusWildcardPrincipal,
+		Name:                 "WildcardPrincipal",
+		Description:          "Bucket policy allows any principal",
+		Category:             CategorySecurity,
+		DefaultSeverity:      SeverityHigh,
+		ScanHubSeverity:      SeverityHigh,
+		DiscoveryHubSeverity: SeverityHigh,
+		Rationale:            "An Allow statement with Principal \"*\" grants its actions to every AWS account and to anonymous callers. Unless Block Public Access restricts the bucket, its data is public.",
+		Detection:            "discover --check-policy: an Allow statement in the bucket policy has Principal \"*\", {\"AWS\": \"*\"} or a NotPrincipal, and no condition on the caller's account, organization, ARN, source or network (aws:PrincipalOrgID, aws:SourceVpce, aws:SourceIp and similar). The finding also reports whether GetBucketPolicyStatus considers the policy public.",
+		Remediation:          "Name the principals that need access, or add an aws:PrincipalOrgID or aws:SourceVpce condition. Serve public content through CloudFront with origin access control instead.",
+		Action:               "Replace the wildcard principal in the bucket policy",
+		Effort:               EffortMedium,
+		References: []string{
+			"https://docs.aws.amazon.com/AmazonS3/latest/userguide/access-control-block-public-access.html#access-control-block-public-access-policy-status",
+			"https://docs.aws.amazon.com/IAM/latest/UserGuide/reference_policies_elements_principal.html",
+		},
+	},
+	{
+		ID:                   CrossAccount,
+		Status:               StatusCrossAccount,
+		Name:                 "CrossAccountAccess",
+		Description:          "Bucket policy grants access to other AWS accounts",
+		Category:             CategorySecurity,
+		DefaultSeverity:      SeverityMedium,
+		ScanHubSeverity:      SeverityMedium,
+		DiscoveryHubSeverity: SeverityMedium,
+		Rationale:            "Grants to other accounts are easy to forget once a partner integration or migration ends, and the other account decides which of its users and roles may use them.",
+		Detection:            "discover --check-policy: an Allow statement names an account ID, or an IAM or STS principal ARN, in an account other than the bucket owner's. Service principals and the owner's own roles are not reported.",
+		Remediation:          "Confirm each account still needs access and remove the grants that do not. Scope the remaining ones to the role ARNs and actions they use.",
+		Action:               "Review the bucket policy's cross-account grants",
+		Effort:               EffortSmall,
+		References: []string{
+			"https://docs.aws.amazon.com/AmazonS3/latest/userguide/example-walkthroughs-managing-access-example2.html",
+		},
+	},
+	{
+		ID:                   InsecureTransport,
+		Status:               StatusInsecureTransport,
+		Name:                 "InsecureTransport",
+		Description:          "Bucket policy does not deny requests over plain HTTP",
+		Category:             CategorySecurity,
+		DefaultSeverity:      SeverityLow,
+		ScanHubSeverity:      SeverityLow,
+		DiscoveryHubSeverity: SeverityLow,
+		Rationale:            "S3 accepts HTTP requests unless the bucket policy denies them, so a misconfigured client can send signed requests and object data unencrypted.",
+		Detection:            "discover --check-policy: the bucket has no policy, or no Deny statement for s3:* with the condition {\"Bool\": {\"aws:SecureTransport\": \"false\"}}.",
+		Remediation:          "Add a statement that denies s3:* to every principal when aws:SecureTransport is false.",
+		Action:               "Deny non-TLS requests in the bucket policy",
+		Effort:               EffortSmall,
+		References: []string{
+			"https://docs.aws.amazon.com/AmazonS3/latest/userguide/security-best-practices.html#transit",
+		},
+	},
+	{
+		ID:                   NoLogging,
+		Status:               StatusNoLogging,
+		Name:                 "NoLogging",
+		Description:          "Production bucket has no server access logging or CloudTrail data events",
+		Category:             CategorySecurity,
+		DefaultSeverity:      SeverityMedium,
+		ScanHubSeverity:      SeverityMedium,
+		DiscoveryHubSeverity: SeverityMedium,
+		Rationale:            "Without server access logs or CloudTrail data events there is no record of who read, wrote or deleted objects, so an incident on a production bucket cannot be investigated after the fact.",
+		Detection:            "discover --check-logging: a bucket matching a --production-tag has no server access logging, and no CloudTrail trail in its region selects AWS::S3::Object data events for it.",
+		Remediation:          "Turn on server access logging to a separate log bucket, or add the bucket to a trail's data event selectors. Data events are billed per event, so access logging is usually cheaper for busy buckets.",
+		Action:               "Enable server access logging or CloudTrail data events",
+		Effort:               EffortSmall,
+		References: []string{
+			"https://docs.aws.amazon.com/AmazonS3/latest/userguide/ServerLogs.html",
+			"https://docs.aws.amazon.com/AmazonS3/latest/userguide/cloudtrail-logging-s3-info.html",
+		},
+	},
+	{
+		ID:                   SensitiveDataExposed,
+		Status:               StatusSensitiveDataExposed,
+		Name:                 "SensitiveDataExposed",
+		Description:          "Bucket holding sensitive data is public or unencrypted",
+		Category:             CategorySecurity,
+		DefaultSeverity:      SeverityHigh,
+		ScanHubSeverity:      SeverityHigh,
+		DiscoveryHubSeverity: SeverityHigh,
+		Rationale:            "A public or unencrypted bucket is a risk on its own; when Macie has found personal, financial or credential data in it, the same misconfiguration is a likely data breach.",
+		Detection:            "discover --with-macie: Macie has unarchived classification findings for the bucket, and --check-public finds it public, Access Analyzer confirms public access, or --check-encryption finds no default encryption.",
+		Remediation:          "Block public access and turn on default encryption first, then review the Macie findings to move or delete the sensitive objects that do not belong in the bucket.",
+		Action:               "Block public access and encrypt the bucket holding sensitive data",
+		Effort:               EffortMedium,
+		References: []string{
+			"https://docs.aws.amazon.com/macie/latest/user/findings-types.html",
+			"https://docs.aws.amazon.com/AmazonS3/latest/userguide/access-control-block-public-access.html",
+		},
+	},
+	{
+		ID:                   Correlated,
+		Status:               StatusCorrelated,
+		Name:                 "CorrelatedFinding",
+		Description:          "Another Spectre tool's finding involves a bucket flagged here",
+		Category:             CategorySecurity,
+		DefaultSeverity:      SeverityMedium,
+		ScanHubSeverity:      SeverityMedium,
+		DiscoveryHubSeverity: SeverityMedium,
+		Rationale:            "Findings that are minor on their own can combine into a real risk: an IAM role that can still write to a bucket flagged unused keeps a forgotten data path open, and cleaning up only one side leaves the other behind.",
+		Detection:            "scan or discover --correlate FILE: a finding in a spectre/v1 envelope from a sibling tool names the bucket by ARN, s3:// URI or a bucket metadata key, and s3spectre flagged the bucket. The finding is high when the sibling finding is high or grants write access.",
+		Remediation:          "Resolve both findings together: remove the role's access or the table export before deleting the bucket, or keep the bucket and clear the s3spectre finding if the sibling finding shows it is still in use.",
+		Action:               "Resolve the correlated findings together",
+		Effort:               EffortMedium,
+		References: []string{
+			"https://github.com/ppiankov/s3spectre/blob/main/docs/cli-reference.md#cross-tool-correlation",
+		},
+	},
+	{
+		ID:                   InactiveBucket,
+		Status:               StatusInactive,
+		Name:                 "InactiveBucket",
+		Description:          "Bucket has been inactive for an extended period",
+		Category:             CategoryHygiene,
+		DefaultSeverity:      SeverityMedium,
+		ScanHubSeverity:      SeverityInfo,
+		DiscoveryHubSeverity: SeverityLow,
+		Rationale:            "A long-inactive bucket is often a leftover from a retired service.",
+		Detection:            "discover: the risk score reaches the threshold and the last activity is older than the inactivity threshold. Inactivity adds 50 points to the risk score.",
+		Thresholds: []Threshold{
+			{Flag: "--inactive-days", ConfigKey: "inactive_days", Default: "180", Description: "days since the last object modification"},
+			{Flag: "--age-threshold-days", Default: "365", Description: "bucket age that adds 20 risk points"},
+		},
+		Remediation: "Find the owner and archive the data to a colder storage class, or delete the bucket if no one needs it. A bucket with compliance-mode Object Lock retention cannot be emptied until it expires; exclude it from cleanup.",
+		Action:      "Find the owner and archive or delete the inactive bucket",
+		Effort:      EffortMedium,
+		References: []string{
+			"https://docs.aws.amazon.com/AmazonS3/latest/userguide/storage-class-intro.html",
+		},
+	},
+	{
+		ID:                   ReplicationDestination,
+		Status:               StatusReplicationDestination,
+		Name:                 "ReplicationDestination",
+		Description:          "Bucket is written by replication rather than by code",
+		Category:             CategoryHygiene,
+		DefaultSeverity:      SeverityInfo,
+		ScanHubSeverity:      SeverityInfo,
+		DiscoveryHubSeverity: SeverityInfo,
+		Rationale:            "A replication destination is filled by S3 from its source bucket, so it has no code references and its objects keep the source's modification times. It would otherwise look unused or inactive, and deleting it breaks replication and the copy it exists to hold.",
+		Detection:            "scan --check-unused and discover: a bucket that would be unused or inactive is the destination of an enabled replication rule on another inspected bucket, read with GetBucketReplication.",
+		Remediation:          "Keep the bucket while its sources replicate into it. If the copy is no longer needed, remove the replication rules from the sources first, then review the bucket as unused.",
+		Action:               "Confirm the replication into the bucket is still needed",
+		Effort:               EffortSmall,
+		References: []string{
+			"https://docs.aws.amazon.com/AmazonS3/latest/userguide/replication.html",
+		},
+	},
+	{
+		ID:                   RiskyBucket,
+		Status:               StatusRisky,
+		Name:                 "RiskyBucket",
+		Description:          "Bucket risk score exceeds the configured threshold",
+		Category:             CategorySecurity,
+		DefaultSeverity:      SeverityMedium,
+		ScanHubSeverity:      SeverityInfo,
+		DiscoveryHubSeverity: SeverityMedium,
+		Rationale:            "Several smaller problems on one bucket add up to a hygiene risk even when no single one would be flagged.",
+		Detection:            "discover: risk points are summed over age (20), inactivity (50), emptiness (30), deprecated tags (20), version sprawl (30), missing encryption (40) and public access (60). The bucket is flagged when the total reaches 100.",
+		Thresholds: []Threshold{
+			{Flag: "--age-threshold-days", Default: "365", Description: "bucket age that adds 20 risk points"},
+			{Flag: "--inactive-days", ConfigKey: "inactive_days", Default: "180", Description: "days without activity that add 50 risk points"},
+		},
+		Remediation: "Work through the risk factors listed on the finding, starting with public access and encryption.",
+		Action:      "Work through the bucket's risk factors",
+		Effort:      EffortLarge,
+		References: []string{
+			"https://docs.aws.amazon.com/AmazonS3/latest/userguide/security-best-practices.html",
+		},
+	},
+	{
+		ID:                   OrphanedVault,
+		Status:               StatusOrphanedVault,
+		Name:                 "OrphanedVault",
+		Description:          "Glacier vault is not referenced in code",
+		Category:             CategoryCost,
+		DefaultSeverity:      SeverityLow,
+		ScanHubSeverity:      SeverityMedium,
+		DiscoveryHubSeverity: SeverityMedium,
+		Rationale:            "Glacier vaults outlive the systems that wrote to them. A vault nothing references keeps billing for archives no one will retrieve.",
+		Detection:            "scan --glacier: a vault listed by Glacier in a scanned region has no glacier:// or vault ARN reference in the repository.",
+		Remediation:          "Confirm the retention requirement with the data owner. Delete the archives and the vault if none applies, or move long-term archives to an S3 Glacier storage class managed by lifecycle rules.",
+		Action:               "Confirm retention, then delete or migrate the orphaned vault",
+		Effort:               EffortLarge,
+		References: []string{
+			"https://docs.aws.amazon.com/amazonglacier/latest/dev/deleting-vaults.html",
+			"https://docs.aws.amazon.com/AmazonS3/latest/userguide/storage-class-intro.html",
+		},
+	},
+	{
+		ID:                   MissingVault,
+		Status:               StatusMissingVault,
+		Name:                 "MissingVault",
+		Description:          "Glacier vault referenced in code but does not exist in AWS",
+		Category:             CategoryDrift,
+		DefaultSeverity:      SeverityMedium,
+		ScanHubSeverity:      SeverityHigh,
+		DiscoveryHubSeverity: SeverityHigh,
+		Rationale:            "Archive and restore jobs that target a missing vault fail, often silently in rarely run disaster-recovery paths.",
+		Detection:            "scan --glacier: a glacier:// or vault ARN reference names a vault that Glacier does not list in any scanned region.",
+		Remediation:          "Create the vault, fix the reference, or remove the dead archive path.",
+		Action:               "Create the vault or remove its code references",
+		Effort:               EffortSmall,
+		References: []string{
+			"https://docs.aws.amazon.com/amazonglacier/latest/dev/creating-vaults.html",
+		},
+	},
+	{
+		ID:                   PermissionGap,
+		Status:               StatusPermissionGap,
+		Name:                 "PermissionGap",
+		Description:          "Application principal is not allowed the S3 operation its code performs",
+		Category:             CategoryDrift,
+		DefaultSeverity:      SeverityHigh,
+		ScanHubSeverity:      SeverityHigh,
+		DiscoveryHubSeverity: SeverityHigh,
+		Rationale:            "A reference the application role cannot read, write or list fails with AccessDenied at runtime, usually only once the code path is exercised in production.",
+		Detection:            "scan --principal: IAM policy simulation, including the bucket policy, does not allow the role s3:GetObject, s3:PutObject or s3:ListBucket for a read, write or list reference to an existing bucket.",
+		Remediation:          "Grant the action on the bucket or prefix in the role's policy, remove the explicit deny, or fix the reference if the role should not touch that bucket.",
+		Action:               "Grant the missing permission or fix the reference",
+		Effort:               EffortSmall,
+		References: []string{
+			"https://docs.aws.amazon.com/IAM/latest/UserGuide/access_policies_testing-policies.html",
+			"https://docs.aws.amazon.com/AmazonS3/latest/userguide/using-with-s3-policy-actions.html",
+		},
+	},
+	{
+		ID:                   NoGuardDutyS3,
+		Status:               StatusNoGuardDutyS3,
+		Name:                 "NoGuardDutyS3",
+		Description:          "GuardDuty S3 protection is not enabled in a scanned region",
+		Category:             CategorySecurity,
+		DefaultSeverity:      SeverityMedium,
+		ScanHubSeverity:      SeverityMedium,
+		DiscoveryHubSeverity: SeverityMedium,
+		Rationale:            "Without S3 protection GuardDuty does not analyze S3 data events, so data exfiltration and access from known malicious IPs go undetected.",
+		Detection:            "discover --check-guardduty: the region has no GuardDuty detector, the detector is suspended, or its S3_DATA_EVENTS feature is not enabled. Reported once per region, not per bucket.",
+		Remediation:          "Enable GuardDuty in the region and turn on S3 Protection, ideally for every member account through the organization's delegated administrator.",
+		Action:               "Enable GuardDuty S3 Protection",
+		Effort:               EffortSmall,
+		References: []string{
+			"https://docs.aws.amazon.com/guardduty/latest/ug/s3-protection.html",
+		},
+	},
+	{
+		ID:                   NoAccountPAB,
+		Status:               StatusNoAccountPAB,
+		Name:                 "NoAccountPublicAccessBlock",
+		Description:          "Account-level S3 Block Public Access is not fully enabled",
+		Category:             CategorySecurity,
+		DefaultSeverity:      SeverityHigh,
+		ScanHubSeverity:      SeverityHigh,
+		DiscoveryHubSeverity: SeverityHigh,
+		Rationale:            "The account-level public access block protects every bucket at once, including buckets created later. Without it, each bucket depends on its own settings to stay private.",
+		Detection:            "discover --check-public: the account has no public access block configuration, or one of its four settings is off. Reported once per account.",
+		Remediation:          "Turn on all four Block Public Access settings for the account, after moving any intentionally public content behind CloudFront or another front end.",
+		Action:               "Turn on account-level Block Public Access",
+		Effort:               EffortLarge,
+		References: []string{
+			"https://docs.aws.amazon.com/AmazonS3/latest/userguide/configuring-block-public-access-account.html",
+		},
+	},
+	{
+		ID:                   LowLifecycle,
+		Status:               StatusLowLifecycle,
+		Name:                 "LowLifecycleCoverage",
+		Description:          "Too many buckets in the account have no lifecycle rules",
+		Category:             CategoryCost,
+		DefaultSeverity:      SeverityLow,
+		ScanHubSeverity:      SeverityLow,
+		DiscoveryHubSeverity: SeverityLow,
+		Rationale:            "Buckets without lifecycle rules keep every object and noncurrent version forever. Many of them across an account point to a missing storage policy, not a one-off gap.",
+		Detection:            "discover --max-buckets-without-lifecycle N: more than N inspected buckets have no lifecycle rules. Reported once per account.",
+		Thresholds: []Threshold{
+			{Flag: "--max-buckets-without-lifecycle", Default: "0 (disabled)", Description: "buckets without lifecycle rules tolerated before the finding fires"},
+		},
+		Remediation: "Adopt a default lifecycle policy, for example expiring noncurrent versions and incomplete multipart uploads, and apply it to new buckets through infrastructure code.",
+		Action:      "Adopt a default lifecycle policy for new buckets",
+		Effort:      EffortLarge,
+		References: []string{
+			"https://docs.aws.amazon.com/AmazonS3/latest/userguide/object-lifecycle-mgmt.html",
+		},
+	},
+	{
+		ID:                   EnvDrift,
+		Status:               StatusEnvDrift,
+		Name:                 "EnvironmentDrift",
+		Description:          "Buckets for different environments of the same workload are configured differently",
+		Category:             CategoryHygiene,
+		DefaultSeverity:      SeverityLow,
+		ScanHubSeverity:      SeverityMedium,
+		DiscoveryHubSeverity: SeverityMedium,
+		Rationale:            "Dev, staging and prod buckets of one workload are meant to differ only in their data. Encryption, versioning or lifecycle set in one environment and not another means a setting was applied by hand, and what was tested is not what runs in production.",
+		Detection:            "discover --check-env-drift: buckets whose names differ only in an environment segment (orders-dev, orders-prod) form a family, and the family's members disagree on default encryption, versioning or having lifecycle rules. Environment names come from the environments config key.",
+		Remediation:          "Manage every environment's bucket from the same infrastructure module, with the environment as a parameter, and bring the outlier in line.",
+		Action:               "Align bucket settings across environments",
+		Effort:               EffortMedium,
+		References: []string{
+			"https://docs.aws.amazon.com/AmazonS3/latest/userguide/bucket-encryption.html",
+		},
+	},
+	{
+		ID:                   TimedOut,
+		Status:               StatusTimedOut,
+		Name:                 "InspectionTimedOut",
+		Description:          "Bucket inspection ran out of time and its remaining checks were skipped",
+		Category:             CategoryHygiene,
+		DefaultSeverity:      SeverityInfo,
+		ScanHubSeverity:      SeverityInfo,
+		DiscoveryHubSeverity: SeverityInfo,
+		Rationale:            "A bucket whose checks were skipped has not been shown to be healthy. Reporting it as OK would hide whatever the skipped checks would have found.",
+		Detection:            "scan and discover: inspecting the bucket took longer than --per-bucket-timeout, or --inspect-timeout ran out while it was in progress. In discover, findings from the checks that finished are reported instead.",
+		Thresholds: []Threshold{
+			{Flag: "--per-bucket-timeout", Default: "0 (none)", Description: "time one bucket's inspection may take"},
+			{Flag: "--inspect-timeout", Default: "0 (none)", Description: "time all bucket inspection may take"},
+		},
+		Remediation: "Re-run with a larger --per-bucket-timeout or --inspect-timeout. Timed-out buckets are not checkpointed, so --resume inspects only them again.",
+		Action:      "Re-run the inspection with a larger timeout budget",
+		Effort:      EffortSmall,
+		References: []string{
+			"https://docs.aws.amazon.com/AmazonS3/latest/userguide/optimizing-performance.html",
+		},
+	},
+}
+
+var (
+	byID     = make(map[string]Rule, len(registry))
+	byStatus = make(map[string]Rule, len(registry))
+)
+
+func init() {
+	for _, rule := range registry {
+		byID[rule.ID] = rule
+		if rule.Status != "" {
+			byStatus[rule.Status] = rule
+		}
+	}
+}
+
+// All returns the built-in rules sorted by ID
+func All() []Rule {
+	out := append([]Rule(nil), registry...)
+	sort.Slice(out, func(i, j int) bool { return out[i].ID < out[j].ID })
+	return out
+}
+
+// Lookup finds a rule by ID (VERSION_SPRAWL), SARIF rule ID
+// (s3spectre/VERSION_SPRAWL) or the status that produces it
+// (LIFECYCLE_MISCONFIG), ignoring case
+func Lookup(name string) (Rule, bool) {
+	key := normalize(name)
+	if rule, ok := byID[key]; ok {
+		return rule, true
+	}
+	rule, ok := byStatus[key]
+	return rule, ok
+}
+
+// ForStatus returns the rule an analyzer status produces
+func ForStatus(status string) (Rule, bool) {
+	rule, ok := byStatus[status]
+	return rule, ok
+}
+
+func normalize(name string) string {
+	return strings.ToUpper(strings.TrimPrefix(strings.TrimSpace(name), SARIFPrefix))
+}
+
+// SARIFID returns the rule ID used in SARIF and LSP output
+func (r Rule) SARIFID() string {
+	return SARIFPrefix + r.ID
+}
+
+// HelpText renders the rule documentation as plain text
+func (r Rule) HelpText() string {
+	var b strings.Builder
+	_, _ = fmt.Fprintf(&b, "%s\n\nDetection: %s\n\nRemediation: %s", r.Rationale, r.Detection, r.Remediation)
+	for _, ref := range r.References {
+		_, _ = fmt.Fprintf(&b, "\n%s", ref)
+	}
+	return b.String()
+}
+
+// HelpMarkdown renders the rule documentation as Markdown
+func (r Rule) HelpMarkdown() string {
+	var b strings.Builder
+	_, _ = fmt.Fprintf(&b, "%s\n\n**Detection:** %s\n", r.Rationale, r.Detection)
+	if len(r.Thresholds) > 0 {
+		_, _ = fmt.Fprintf(&b, "\n**Thresholds:**\n\n")
+		for _, t := range r.Thresholds {
+			_, _ = fmt.Fprintf(&b, "- `%s` (default %s): %s\n", t.Flag, t.Default, t.Description)
+		}
+	}
+	_, _ = fmt.Fprintf(&b, "\n**Remediation:** %s\n", r.Remediation)
+	if len(r.References) > 0 {
+		_, _ = fmt.Fprintf(&b, "\n**References:**\n\n")
+		for _, ref := range r.References {
+			_, _ = fmt.Fprintf(&b, "- %s\n", ref)
+		}
+	}
+	return b.String()
+}
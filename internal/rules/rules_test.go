@@ -0,0 +1,75 @@
+package rules
+
+import "testing"
+
+func TestRegistryDocumented(t *testing.T) {
+	seen := make(map[string]bool)
+	for _, rule := range All() {
+		if seen[rule.ID] {
+			t.Fatalf("duplicate rule %s", rule.ID)
+		}
+		seen[rule.ID] = true
+		if rule.Name == "" || rule.Description == "" || rule.Rationale == "" || rule.Detection == "" || rule.Remediation == "" {
+			t.Fatalf("rule %s is missing documentation: %+v", rule.ID, rule)
+		}
+		if len(rule.References) == 0 {
+			t.Fatalf("rule %s has no references", rule.ID)
+		}
+		if rule.Category == "" {
+			t.Fatalf("rule %s has no category", rule.ID)
+		}
+		if !ValidSeverity(rule.DefaultSeverity) {
+			t.Fatalf("rule %s has invalid default severity %q", rule.ID, rule.DefaultSeverity)
+		}
+		if !ValidSeverity(rule.ScanHubSeverity) || !ValidSeverity(rule.DiscoveryHubSeverity) {
+			t.Fatalf("rule %s has invalid hub severities %q, %q", rule.ID, rule.ScanHubSeverity, rule.DiscoveryHubSeverity)
+		}
+		if rule.Action == "" {
+			t.Fatalf("rule %s has no action", rule.ID)
+		}
+		switch rule.Effort {
+		case EffortSmall, EffortMedium, EffortLarge:
+		default:
+			t.Fatalf("rule %s has invalid effort %q", rule.ID, rule.Effort)
+		}
+	}
+}
+
+func TestEveryStatusHasRule(t *testing.T) {
+	statuses := []string{
+		StatusMissingBucket, StatusUnusedBucket, StatusMissingPrefix, StatusStalePrefix,
+		StatusVersionSprawl, StatusLifecycleMisconfig, StatusRisky, StatusInactive,
+		StatusOrphanedVault, StatusMissingVault, StatusPermissionGap, StatusNoGuardDutyS3,
+		StatusNoAccountPAB, StatusLowLifecycle, StatusPrefixNearMiss, StatusEnvDrift,
+		StatusWildcardPrincipal, StatusCrossAccount, StatusInsecureTransport,
+		StatusReplicationDestination,
+		StatusNoLogging, StatusSensitiveDataExposed, StatusCorrelated, StatusTimedOut,
+	}
+	for _, status := range statuses {
+		if _, ok := ForStatus(status); !ok {
+			t.Fatalf("status %s has no rule", status)
+		}
+	}
+	if _, ok := ForStatus(StatusOK); ok {
+		t.Fatalf("expected OK to have no rule")
+	}
+}
+
+func TestLookup(t *testing.T) {
+	for _, name := range []string{"VERSION_SPRAWL", "version_sprawl", "s3spectre/VERSION_SPRAWL"} {
+		rule, ok := Lookup(name)
+		if !ok || rule.ID != VersionSprawl {
+			t.Fatalf("Lookup(%q) = %q, %v", name, rule.ID, ok)
+		}
+	}
+	rule, ok := Lookup(StatusLifecycleMisconfig)
+	if !ok || rule.ID != LifecycleGap {
+		t.Fatalf("expected status lookup to find LIFECYCLE_GAP, got %q, %v", rule.ID, ok)
+	}
+	if rule.SARIFID() != "s3spectre/LIFECYCLE_GAP" {
+		t.Fatalf("unexpected SARIF ID %q", rule.SARIFID())
+	}
+	if _, ok := Lookup("NOPE"); ok {
+		t.Fatalf("expected unknown rule lookup to fail")
+	}
+}
@@ -0,0 +1,116 @@
+package rules
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Severities from most to least severe. Info findings are reported but
+// never fail a --fail-on-severity gate unless it is set to info.
+const (
+	SeverityHigh   = "high"
+	SeverityMedium = "medium"
+	SeverityLow    = "low"
+	SeverityInfo   = "info"
+)
+
+var severities = []string{SeverityHigh, SeverityMedium, SeverityLow, SeverityInfo}
+
+var severityRank = map[string]int{
+	SeverityHigh:   4,
+	SeverityMedium: 3,
+	SeverityLow:    2,
+	SeverityInfo:   1,
+}
+
+// sarifLevels maps severities to SARIF levels
+var sarifLevels = map[string]string{
+	SeverityHigh:   "error",
+	SeverityMedium: "warning",
+	SeverityLow:    "note",
+	SeverityInfo:   "none",
+}
+
+// overrides holds config remappings keyed by rule ID or plugin rule name
+var overrides map[string]string
+
+// SetSeverityOverrides installs per-rule severity remappings for every
+// reporter, baseline and severity gate. Keys are rule IDs (LIFECYCLE_GAP),
+// SARIF rule IDs (s3spectre/LIFECYCLE_GAP), statuses (LIFECYCLE_MISCONFIG)
+// or plugin rule names; values are high, medium, low or info.
+func SetSeverityOverrides(remap map[string]string) error {
+	normalized := make(map[string]string, len(remap))
+	for name, severity := range remap {
+		sev := strings.ToLower(strings.TrimSpace(severity))
+		if !ValidSeverity(sev) {
+			return fmt.Errorf("severity override for %s: invalid severity %q (expected high, medium, low or info)", name, severity)
+		}
+		key := normalize(name)
+		if rule, ok := Lookup(name); ok {
+			key = rule.ID
+		}
+		normalized[key] = sev
+	}
+	overrides = normalized
+	return nil
+}
+
+// Severities lists severities from most to least severe
+func Severities() []string {
+	return append([]string(nil), severities...)
+}
+
+// ValidSeverity reports whether s is a known severity name
+func ValidSeverity(s string) bool {
+	_, ok := severityRank[s]
+	return ok
+}
+
+// Rank orders severities: higher is more severe, unknown severities rank 0
+func Rank(severity string) int {
+	return severityRank[severity]
+}
+
+// SeverityAtLeast reports whether severity is at or above threshold
+func SeverityAtLeast(severity, threshold string) bool {
+	rank, ok := severityRank[severity]
+	return ok && rank >= severityRank[threshold]
+}
+
+// SARIFLevel maps a severity to its SARIF level (error, warning, note, none)
+func SARIFLevel(severity string) string {
+	return sarifLevels[severity]
+}
+
+// Override returns the configured severity for a rule ID or plugin rule name
+func Override(rule string) (string, bool) {
+	sev, ok := overrides[normalize(rule)]
+	return sev, ok
+}
+
+// Severity returns the rule's severity after config overrides
+func (r Rule) Severity() string {
+	if sev, ok := overrides[r.ID]; ok {
+		return sev
+	}
+	return r.DefaultSeverity
+}
+
+// StatusSeverity returns the severity of an analyzer status after config
+// overrides, or "" for statuses that are not findings
+func StatusSeverity(status string) string {
+	rule, ok := ForStatus(status)
+	if !ok {
+		return ""
+	}
+	return rule.Severity()
+}
+
+// CustomSeverity returns the severity for a plugin rule: its override, or the
+// severity the plugin reported
+func CustomSeverity(rule, reported string) string {
+	if sev, ok := Override(rule); ok {
+		return sev
+	}
+	return reported
+}
@@ -0,0 +1,89 @@
+package rules
+
+import "testing"
+
+func setOverrides(t *testing.T, remap map[string]string) {
+	t.Helper()
+	if err := SetSeverityOverrides(remap); err != nil {
+		t.Fatalf("SetSeverityOverrides: %v", err)
+	}
+	t.Cleanup(func() { _ = SetSeverityOverrides(nil) })
+}
+
+func TestStatusSeverity_Defaults(t *testing.T) {
+	cases := map[string]string{
+		StatusMissingBucket: SeverityMedium,
+		StatusStalePrefix:   SeverityLow,
+		StatusOK:            "",
+	}
+	for status, want := range cases {
+		if got := StatusSeverity(status); got != want {
+			t.Fatalf("StatusSeverity(%s) = %q, want %q", status, got, want)
+		}
+	}
+}
+
+func TestSetSeverityOverrides(t *testing.T) {
+	setOverrides(t, map[string]string{
+		"STALE_PREFIX":            "info",
+		"version_sprawl":          "HIGH",
+		"s3spectre/LIFECYCLE_GAP": "low",
+		"INACTIVE":                "high",
+		"ACME_NO_OWNER":           "low",
+	})
+
+	cases := []struct {
+		status     string
+		severity   string
+		sarifLevel string
+	}{
+		{StatusStalePrefix, SeverityInfo, "none"},
+		{StatusVersionSprawl, SeverityHigh, "error"},
+		{StatusLifecycleMisconfig, SeverityLow, "note"},
+		{StatusInactive, SeverityHigh, "error"},
+		{StatusMissingBucket, SeverityMedium, "warning"},
+	}
+	for _, tt := range cases {
+		if got := StatusSeverity(tt.status); got != tt.severity {
+			t.Fatalf("StatusSeverity(%s) = %q, want %q", tt.status, got, tt.severity)
+		}
+		if got := SARIFLevel(StatusSeverity(tt.status)); got != tt.sarifLevel {
+			t.Fatalf("SARIFLevel(%s) = %q, want %q", tt.status, got, tt.sarifLevel)
+		}
+	}
+
+	if sev, ok := Override(InactiveBucket); !ok || sev != SeverityHigh {
+		t.Fatalf("expected status key to override INACTIVE_BUCKET, got %q, %v", sev, ok)
+	}
+	if got := CustomSeverity("ACME_NO_OWNER", SeverityHigh); got != SeverityLow {
+		t.Fatalf("expected plugin rule override low, got %q", got)
+	}
+	if got := CustomSeverity("ACME_OTHER", SeverityHigh); got != SeverityHigh {
+		t.Fatalf("expected reported severity for unmapped rule, got %q", got)
+	}
+}
+
+func TestSetSeverityOverrides_Invalid(t *testing.T) {
+	t.Cleanup(func() { _ = SetSeverityOverrides(nil) })
+	if err := SetSeverityOverrides(map[string]string{"STALE_PREFIX": "urgent"}); err == nil {
+		t.Fatalf("expected error for invalid severity")
+	}
+}
+
+func TestSeverityAtLeast(t *testing.T) {
+	cases := []struct {
+		severity, threshold string
+		want                bool
+	}{
+		{SeverityHigh, SeverityMedium, true},
+		{SeverityMedium, SeverityMedium, true},
+		{SeverityLow, SeverityMedium, false},
+		{SeverityInfo, SeverityInfo, true},
+		{"", SeverityInfo, false},
+	}
+	for _, tt := range cases {
+		if got := SeverityAtLeast(tt.severity, tt.threshold); got != tt.want {
+			t.Fatalf("SeverityAtLeast(%q, %q) = %v, want %v", tt.severity, tt.threshold, got, tt.want)
+		}
+	}
+}
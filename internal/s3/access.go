@@ -0,0 +1,252 @@
+package s3
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudtrail"
+	cloudtrailtypes "github.com/aws/aws-sdk-go-v2/service/cloudtrail/types"
+)
+
+// accessQueryPollInterval is how often a CloudTrail Lake query is polled
+var accessQueryPollInterval = 2 * time.Second
+
+var (
+	eventDataStoreIDPattern  = regexp.MustCompile(`^[0-9A-Za-z]{8}-[0-9A-Za-z]{4}-[0-9A-Za-z]{4}-[0-9A-Za-z]{4}-[0-9A-Za-z]{12}$`)
+	eventDataStoreARNPattern = regexp.MustCompile(`^arn:aws[a-z-]*:cloudtrail:([a-z0-9-]+):\d{12}:eventdatastore/([^/]+)$`)
+)
+
+// CloudTrailLakeAPI is the subset of the CloudTrail client used to query an
+// event data store
+type CloudTrailLakeAPI interface {
+	StartQuery(ctx context.Context, params *cloudtrail.StartQueryInput, optFns ...func(*cloudtrail.Options)) (*cloudtrail.StartQueryOutput, error)
+	GetQueryResults(ctx context.Context, params *cloudtrail.GetQueryResultsInput, optFns ...func(*cloudtrail.Options)) (*cloudtrail.GetQueryResultsOutput, error)
+	GetEventDataStore(ctx context.Context, params *cloudtrail.GetEventDataStoreInput, optFns ...func(*cloudtrail.Options)) (*cloudtrail.GetEventDataStoreOutput, error)
+}
+
+// AccessInfo is a bucket's object reads and writes recorded by CloudTrail
+// data events
+type AccessInfo struct {
+	// Since is the start of the period that was queried
+	Since time.Time `json:"since"`
+	// LastAccess is the latest GetObject or PutObject; nil when the bucket
+	// had neither in the period
+	LastAccess *time.Time `json:"last_access,omitempty"`
+	Reads      int        `json:"reads"`
+	Writes     int        `json:"writes"`
+}
+
+// AccessEvents is what a CloudTrail Lake event data store recorded of
+// object reads and writes
+type AccessEvents struct {
+	// Buckets is each bucket's reads and writes; buckets without events
+	// are absent
+	Buckets map[string]*AccessInfo
+	// coverage is which buckets the store collects data events for
+	coverage trailCoverage
+}
+
+// Covers reports whether the store collects the bucket's data events, so
+// that no events means no reads or writes
+func (a *AccessEvents) Covers(bucket string) bool {
+	return a.coverage.covers(bucket)
+}
+
+// cloudTrailClient returns the CloudTrail client for a region
+func (i *Inspector) cloudTrailClient(region string) CloudTrailLakeAPI {
+	if i.newCloudTrailAPI != nil {
+		return i.newCloudTrailAPI(region)
+	}
+	cfg := i.client.config.Copy()
+	cfg.Region = region
+	return cloudtrail.NewFromConfig(cfg)
+}
+
+// BucketAccess queries a CloudTrail Lake event data store for the GetObject
+// and PutObject data events of each bucket since the given time. The store
+// is an ARN or ID; an ARN's region is queried, otherwise the client's. Only
+// buckets whose data events the store collects, per its event selectors,
+// have any; the query is billed by the data it scans.
+func (i *Inspector) BucketAccess(ctx context.Context, eventDataStore string, since time.Time) (*AccessEvents, error) {
+	region, storeID, err := parseEventDataStore(eventDataStore)
+	if err != nil {
+		return nil, err
+	}
+	if region == "" {
+		region = i.client.GetRegion()
+	}
+	api := i.cloudTrailClient(region)
+
+	var store *cloudtrail.GetEventDataStoreOutput
+	err = i.client.WithRetry(ctx, func() error {
+		var err error
+		store, err = api.GetEventDataStore(ctx, &cloudtrail.GetEventDataStoreInput{EventDataStore: aws.String(eventDataStore)})
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read event data store: %w", err)
+	}
+	events := &AccessEvents{
+		Buckets:  make(map[string]*AccessInfo),
+		coverage: trailCoverage{buckets: make(map[string]bool)},
+	}
+	for _, selector := range store.AdvancedEventSelectors {
+		events.coverage.addAdvanced(selector)
+	}
+
+	var started *cloudtrail.StartQueryOutput
+	err = i.client.WithRetry(ctx, func() error {
+		var err error
+		started, err = api.StartQuery(ctx, &cloudtrail.StartQueryInput{
+			QueryStatement: aws.String(accessQuery(storeID, since)),
+		})
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to start CloudTrail Lake query: %w", err)
+	}
+
+	input := &cloudtrail.GetQueryResultsInput{QueryId: started.QueryId}
+	for {
+		var out *cloudtrail.GetQueryResultsOutput
+		err := i.client.WithRetry(ctx, func() error {
+			var err error
+			out, err = api.GetQueryResults(ctx, input)
+			return err
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CloudTrail Lake query results: %w", err)
+		}
+		switch out.QueryStatus {
+		case cloudtrailtypes.QueryStatusQueued, cloudtrailtypes.QueryStatusRunning:
+			select {
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			case <-time.After(accessQueryPollInterval):
+			}
+			continue
+		case cloudtrailtypes.QueryStatusFinished:
+		default:
+			return nil, fmt.Errorf("CloudTrail Lake query %s: %s", strings.ToLower(string(out.QueryStatus)), aws.ToString(out.ErrorMessage))
+		}
+
+		for _, row := range out.QueryResultRows {
+			if err := addAccessRow(events.Buckets, row, since); err != nil {
+				return nil, err
+			}
+		}
+		if out.NextToken == nil || *out.NextToken == "" {
+			return events, nil
+		}
+		input.NextToken = out.NextToken
+	}
+}
+
+// accessQuery counts each bucket's object reads and writes since a time,
+// with the latest of each. storeID must have been checked by
+// parseEventDataStore, since it is part of the statement.
+func accessQuery(storeID string, since time.Time) string {
+	return fmt.Sprintf(`SELECT element_at(requestParameters, 'bucketName') AS bucket, eventName, max(eventTime) AS last_access, count(*) AS events
+FROM %s
+WHERE eventSource = 's3.amazonaws.com' AND eventName IN ('GetObject', 'PutObject') AND eventTime >= '%s'
+GROUP BY element_at(requestParameters, 'bucketName'), eventName`, storeID, since.UTC().Format(time.DateTime))
+}
+
+// addAccessRow adds one result row of accessQuery to access. Lake returns
+// each row as a list of single-column maps.
+func addAccessRow(access map[string]*AccessInfo, row []map[string]string, since time.Time) error {
+	columns := make(map[string]string, len(row))
+	for _, column := range row {
+		for name, value := range column {
+			columns[name] = value
+		}
+	}
+	bucket := columns["bucket"]
+	if bucket == "" {
+		return nil
+	}
+	events, err := strconv.Atoi(columns["events"])
+	if err != nil {
+		return fmt.Errorf("invalid CloudTrail Lake event count %q: %w", columns["events"], err)
+	}
+	last, err := parseLakeTime(columns["last_access"])
+	if err != nil {
+		return err
+	}
+
+	info := access[bucket]
+	if info == nil {
+		info = &AccessInfo{Since: since}
+		access[bucket] = info
+	}
+	if columns["eventName"] == "PutObject" {
+		info.Writes += events
+	} else {
+		info.Reads += events
+	}
+	if info.LastAccess == nil || last.After(*info.LastAccess) {
+		info.LastAccess = &last
+	}
+	return nil
+}
+
+// parseLakeTime parses an eventTime as CloudTrail Lake returns it
+func parseLakeTime(value string) (time.Time, error) {
+	for _, layout := range []string{"2006-01-02 15:04:05.000", time.DateTime, time.RFC3339} {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid CloudTrail Lake event time %q", value)
+}
+
+// ValidateEventDataStore checks that store is a CloudTrail Lake event data
+// store ARN or ID
+func ValidateEventDataStore(store string) error {
+	_, _, err := parseEventDataStore(store)
+	return err
+}
+
+// parseEventDataStore splits an event data store ARN into its region and
+// ID. A bare ID has no region. The ID is a UUID; anything else is rejected
+// because it is queried as a table name.
+func parseEventDataStore(store string) (region, id string, err error) {
+	id = store
+	if m := eventDataStoreARNPattern.FindStringSubmatch(store); m != nil {
+		region, id = m[1], m[2]
+	}
+	if !eventDataStoreIDPattern.MatchString(id) {
+		return "", "", fmt.Errorf("invalid event data store %q (expected an event data store ARN or ID)", store)
+	}
+	return region, id, nil
+}
+
+// ApplyAccess records each bucket's CloudTrail access and moves its last
+// activity up to the latest read or write, so inactivity reflects real
+// use rather than the newest of the sampled objects. Buckets the store
+// collects data events for but absent from its events had no reads or
+// writes since the given time; buckets it does not collect are left alone.
+func ApplyAccess(buckets map[string]*BucketInfo, access *AccessEvents, since, now time.Time) {
+	for _, info := range buckets {
+		if info == nil || !info.Exists {
+			continue
+		}
+		record := access.Buckets[info.Name]
+		if record == nil {
+			if !access.Covers(info.Name) {
+				continue
+			}
+			record = &AccessInfo{Since: since}
+		}
+		info.Access = record
+		if record.LastAccess != nil && (info.LastActivity == nil || record.LastAccess.After(*info.LastActivity)) {
+			info.LastActivity = record.LastAccess
+			info.DaysSinceActivity = int(now.Sub(*record.LastAccess).Hours() / 24)
+		}
+	}
+}
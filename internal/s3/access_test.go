@@ -0,0 +1,165 @@
+package s3
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudtrail"
+	cloudtrailtypes "github.com/aws/aws-sdk-go-v2/service/cloudtrail/types"
+)
+
+// storeID is an event data store ID in the form CloudTrail gives them
+const storeID = "0a1b2c3d-f852-4e8f-8bd1-bcf6c0a1b2c3"
+
+// fakeCloudTrailLakeAPI serves query result pages after a queued poll
+type fakeCloudTrailLakeAPI struct {
+	statement string
+	selectors []cloudtrailtypes.AdvancedEventSelector
+	pages     []*cloudtrail.GetQueryResultsOutput
+	polls     int
+}
+
+func (f *fakeCloudTrailLakeAPI) GetEventDataStore(context.Context, *cloudtrail.GetEventDataStoreInput, ...func(*cloudtrail.Options)) (*cloudtrail.GetEventDataStoreOutput, error) {
+	return &cloudtrail.GetEventDataStoreOutput{AdvancedEventSelectors: f.selectors}, nil
+}
+
+func (f *fakeCloudTrailLakeAPI) StartQuery(_ context.Context, params *cloudtrail.StartQueryInput, _ ...func(*cloudtrail.Options)) (*cloudtrail.StartQueryOutput, error) {
+	f.statement = aws.ToString(params.QueryStatement)
+	return &cloudtrail.StartQueryOutput{QueryId: aws.String("q-1")}, nil
+}
+
+func (f *fakeCloudTrailLakeAPI) GetQueryResults(context.Context, *cloudtrail.GetQueryResultsInput, ...func(*cloudtrail.Options)) (*cloudtrail.GetQueryResultsOutput, error) {
+	f.polls++
+	if f.polls == 1 {
+		return &cloudtrail.GetQueryResultsOutput{QueryStatus: cloudtrailtypes.QueryStatusQueued}, nil
+	}
+	return f.pages[f.polls-2], nil
+}
+
+func accessRow(bucket, event, last, count string) []map[string]string {
+	return []map[string]string{{"bucket": bucket}, {"eventName": event}, {"last_access": last}, {"events": count}}
+}
+
+func TestBucketAccess(t *testing.T) {
+	defer func(interval time.Duration) { accessQueryPollInterval = interval }(accessQueryPollInterval)
+	accessQueryPollInterval = time.Millisecond
+
+	api := &fakeCloudTrailLakeAPI{pages: []*cloudtrail.GetQueryResultsOutput{
+		{
+			QueryStatus: cloudtrailtypes.QueryStatusFinished,
+			QueryResultRows: [][]map[string]string{
+				accessRow("reports", "GetObject", "2024-05-10 08:00:00.000", "40"),
+				accessRow("reports", "PutObject", "2024-05-12 09:30:00.000", "2"),
+			},
+			NextToken: aws.String("next"),
+		},
+		{
+			QueryStatus:     cloudtrailtypes.QueryStatusFinished,
+			QueryResultRows: [][]map[string]string{accessRow("uploads", "PutObject", "2024-04-01 00:00:00.000", "7")},
+		},
+	}}
+	var region string
+	inspector := NewInspector(&Client{config: aws.Config{Region: "us-east-1"}}, 1)
+	inspector.newCloudTrailAPI = func(r string) CloudTrailLakeAPI {
+		region = r
+		return api
+	}
+
+	since := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	access, err := inspector.BucketAccess(context.Background(), "arn:aws:cloudtrail:eu-west-1:111111111111:eventdatastore/"+storeID, since)
+	if err != nil {
+		t.Fatalf("BucketAccess failed: %v", err)
+	}
+	if region != "eu-west-1" {
+		t.Errorf("queried region %q, want the event data store's eu-west-1", region)
+	}
+	for _, want := range []string{"FROM " + storeID, "eventTime >= '2024-01-01 00:00:00'", "'GetObject', 'PutObject'"} {
+		if !strings.Contains(api.statement, want) {
+			t.Errorf("query %q lacks %q", api.statement, want)
+		}
+	}
+
+	reports := access.Buckets["reports"]
+	if reports == nil || reports.Reads != 40 || reports.Writes != 2 {
+		t.Fatalf("reports access = %+v", reports)
+	}
+	if want := time.Date(2024, 5, 12, 9, 30, 0, 0, time.UTC); !reports.LastAccess.Equal(want) {
+		t.Errorf("reports last access = %v, want %v", reports.LastAccess, want)
+	}
+	if uploads := access.Buckets["uploads"]; uploads == nil || uploads.Writes != 7 || !uploads.Since.Equal(since) {
+		t.Errorf("uploads access = %+v", uploads)
+	}
+}
+
+func TestBucketAccess_QueryFailed(t *testing.T) {
+	api := &fakeCloudTrailLakeAPI{pages: []*cloudtrail.GetQueryResultsOutput{
+		{QueryStatus: cloudtrailtypes.QueryStatusFailed, ErrorMessage: aws.String("event data store not found")},
+	}}
+	defer func(interval time.Duration) { accessQueryPollInterval = interval }(accessQueryPollInterval)
+	accessQueryPollInterval = time.Millisecond
+	inspector := NewInspector(&Client{config: aws.Config{Region: "us-east-1"}}, 1)
+	inspector.newCloudTrailAPI = func(string) CloudTrailLakeAPI { return api }
+
+	_, err := inspector.BucketAccess(context.Background(), storeID, time.Now())
+	if err == nil || !strings.Contains(err.Error(), "event data store not found") {
+		t.Fatalf("expected the query failure, got %v", err)
+	}
+}
+
+func TestParseEventDataStore(t *testing.T) {
+	tests := []struct {
+		store   string
+		region  string
+		wantErr bool
+	}{
+		{store: storeID},
+		{store: "arn:aws:cloudtrail:eu-west-1:111111111111:eventdatastore/" + storeID, region: "eu-west-1"},
+		{store: "eds-123", wantErr: true},
+		{store: storeID + " WHERE 1=1; --", wantErr: true},
+		{store: "arn:aws:cloudtrail:eu-west-1:111111111111:eventdatastore/x UNION SELECT 1", wantErr: true},
+	}
+	for _, tt := range tests {
+		region, id, err := parseEventDataStore(tt.store)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parseEventDataStore(%q) error = %v, wantErr %v", tt.store, err, tt.wantErr)
+			continue
+		}
+		if !tt.wantErr && (region != tt.region || id != storeID) {
+			t.Errorf("parseEventDataStore(%q) = %q, %q", tt.store, region, id)
+		}
+	}
+}
+
+func TestApplyAccess(t *testing.T) {
+	now := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	since := now.AddDate(0, 0, -180)
+	sampled := now.AddDate(0, 0, -300)
+	read := now.AddDate(0, 0, -3)
+	buckets := map[string]*BucketInfo{
+		"read":    {Name: "read", Exists: true, LastActivity: &sampled, DaysSinceActivity: 300},
+		"unread":  {Name: "unread", Exists: true, LastActivity: &sampled, DaysSinceActivity: 300},
+		"missing": {Name: "missing"},
+		"other":   {Name: "other", Exists: true, LastActivity: &sampled, DaysSinceActivity: 300},
+	}
+	access := &AccessEvents{
+		Buckets:  map[string]*AccessInfo{"read": {Since: since, LastAccess: &read, Reads: 1}},
+		coverage: trailCoverage{buckets: map[string]bool{"read": true, "unread": true}},
+	}
+	ApplyAccess(buckets, access, since, now)
+
+	if info := buckets["read"]; info.DaysSinceActivity != 3 || !info.LastActivity.Equal(read) {
+		t.Errorf("read bucket activity = %d days, %v", info.DaysSinceActivity, info.LastActivity)
+	}
+	if info := buckets["unread"]; info.DaysSinceActivity != 300 || info.Access == nil || info.Access.LastAccess != nil {
+		t.Errorf("unread bucket = %+v", info)
+	}
+	if buckets["missing"].Access != nil {
+		t.Error("a missing bucket should not get access info")
+	}
+	if buckets["other"].Access != nil {
+		t.Error("a bucket the store does not collect should not get access info")
+	}
+}
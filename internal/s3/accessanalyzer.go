@@ -0,0 +1,200 @@
+package s3
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/accessanalyzer"
+	analyzertypes "github.com/aws/aws-sdk-go-v2/service/accessanalyzer/types"
+)
+
+// AccessAnalyzerAPI is the subset of the IAM Access Analyzer client used to
+// read external access findings
+type AccessAnalyzerAPI interface {
+	ListAnalyzers(ctx context.Context, params *accessanalyzer.ListAnalyzersInput, optFns ...func(*accessanalyzer.Options)) (*accessanalyzer.ListAnalyzersOutput, error)
+	ListFindings(ctx context.Context, params *accessanalyzer.ListFindingsInput, optFns ...func(*accessanalyzer.Options)) (*accessanalyzer.ListFindingsOutput, error)
+}
+
+// ExternalAccessFinding is an active IAM Access Analyzer finding that a
+// bucket can be reached from outside the analyzer's zone of trust
+type ExternalAccessFinding struct {
+	ID       string `json:"id"`
+	Analyzer string `json:"analyzer"`
+	Region   string `json:"region"`
+	Public   bool   `json:"public"`
+	// Principal is who has access, such as {"AWS": "444455556666"}
+	Principal map[string]string `json:"principal,omitempty"`
+	Actions   []string          `json:"actions,omitempty"`
+	Condition map[string]string `json:"condition,omitempty"`
+	// Sources are what grants the access: POLICY, BUCKET_ACL or an access
+	// point
+	Sources []string `json:"sources,omitempty"`
+}
+
+// Grantee describes who the finding grants access to
+func (f ExternalAccessFinding) Grantee() string {
+	if f.Public {
+		return "public"
+	}
+	keys := make([]string, 0, len(f.Principal))
+	for key := range f.Principal {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	grantees := make([]string, len(keys))
+	for k, key := range keys {
+		grantees[k] = key + " " + f.Principal[key]
+	}
+	return strings.Join(grantees, ", ")
+}
+
+// AccessAnalysis is the outcome of reading Access Analyzer findings
+type AccessAnalysis struct {
+	// Findings are the active S3 bucket findings, by bucket name
+	Findings map[string][]ExternalAccessFinding
+	// Unanalyzed lists the scanned regions without an active external
+	// access analyzer, whose buckets could not be checked
+	Unanalyzed []string
+}
+
+// analyzerClient returns the Access Analyzer client for a region
+func (i *Inspector) analyzerClient(region string) AccessAnalyzerAPI {
+	if i.newAnalyzerAPI != nil {
+		return i.newAnalyzerAPI(region)
+	}
+	cfg := i.client.config.Copy()
+	cfg.Region = region
+	return accessanalyzer.NewFromConfig(cfg)
+}
+
+// ExternalAccessFindings reads the active S3 bucket findings of the external
+// access analyzer in each scanned region. Analyzers are regional and only
+// analyze their region's buckets. An account analyzer is preferred over an
+// organization one, so findings describe access from outside the account.
+func (i *Inspector) ExternalAccessFindings(ctx context.Context) (*AccessAnalysis, error) {
+	regions, err := i.serviceRegions(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to determine regions: %w", err)
+	}
+
+	analysis := &AccessAnalysis{Findings: make(map[string][]ExternalAccessFinding)}
+	for _, region := range regions {
+		api := i.analyzerClient(region)
+		analyzer, err := i.externalAccessAnalyzer(ctx, api, region)
+		if err != nil {
+			return nil, err
+		}
+		if analyzer == nil {
+			analysis.Unanalyzed = append(analysis.Unanalyzed, region)
+			continue
+		}
+		if err := i.readExternalAccessFindings(ctx, api, region, analyzer, analysis.Findings); err != nil {
+			return nil, err
+		}
+	}
+	return analysis, nil
+}
+
+// externalAccessAnalyzer returns the region's active external access
+// analyzer, or nil when it has none
+func (i *Inspector) externalAccessAnalyzer(ctx context.Context, api AccessAnalyzerAPI, region string) (*analyzertypes.AnalyzerSummary, error) {
+	var found *analyzertypes.AnalyzerSummary
+	paginator := accessanalyzer.NewListAnalyzersPaginator(api, &accessanalyzer.ListAnalyzersInput{})
+	for paginator.HasMorePages() {
+		var page *accessanalyzer.ListAnalyzersOutput
+		err := i.client.WithRetry(ctx, func() error {
+			var err error
+			page, err = paginator.NextPage(ctx)
+			return err
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to list Access Analyzer analyzers in %s: %w", region, err)
+		}
+		for k := range page.Analyzers {
+			analyzer := &page.Analyzers[k]
+			if analyzer.Status != analyzertypes.AnalyzerStatusActive {
+				continue
+			}
+			switch analyzer.Type {
+			case analyzertypes.TypeAccount:
+				return analyzer, nil
+			case analyzertypes.TypeOrganization:
+				if found == nil {
+					found = analyzer
+				}
+			}
+		}
+	}
+	return found, nil
+}
+
+// readExternalAccessFindings adds the analyzer's active S3 bucket findings
+// to findings
+func (i *Inspector) readExternalAccessFindings(ctx context.Context, api AccessAnalyzerAPI, region string, analyzer *analyzertypes.AnalyzerSummary, findings map[string][]ExternalAccessFinding) error {
+	paginator := accessanalyzer.NewListFindingsPaginator(api, &accessanalyzer.ListFindingsInput{
+		AnalyzerArn: analyzer.Arn,
+		Filter: map[string]analyzertypes.Criterion{
+			"resourceType": {Eq: []string{string(analyzertypes.ResourceTypeAwsS3Bucket)}},
+			"status":       {Eq: []string{string(analyzertypes.FindingStatusActive)}},
+		},
+	})
+	for paginator.HasMorePages() {
+		var page *accessanalyzer.ListFindingsOutput
+		err := i.client.WithRetry(ctx, func() error {
+			var err error
+			page, err = paginator.NextPage(ctx)
+			return err
+		})
+		if err != nil {
+			return fmt.Errorf("failed to list Access Analyzer findings in %s: %w", region, err)
+		}
+		for _, summary := range page.Findings {
+			bucket := bucketFromARN(aws.ToString(summary.Resource))
+			// Findings that could not be analyzed carry an error, not access
+			if bucket == "" || summary.Error != nil {
+				continue
+			}
+			finding := ExternalAccessFinding{
+				ID:        aws.ToString(summary.Id),
+				Analyzer:  aws.ToString(analyzer.Name),
+				Region:    region,
+				Public:    aws.ToBool(summary.IsPublic),
+				Principal: summary.Principal,
+				Actions:   summary.Action,
+				Condition: summary.Condition,
+			}
+			for _, source := range summary.Sources {
+				finding.Sources = append(finding.Sources, string(source.Type))
+			}
+			findings[bucket] = append(findings[bucket], finding)
+		}
+	}
+	return nil
+}
+
+// bucketFromARN returns the bucket of arn:aws:s3:::bucket, or "" for other
+// ARNs
+func bucketFromARN(arn string) string {
+	_, bucket, found := strings.Cut(arn, ":s3:::")
+	if !found || strings.Contains(bucket, "/") {
+		return ""
+	}
+	return bucket
+}
+
+// ApplyExternalAccess records the Access Analyzer findings of an account's
+// buckets. Buckets of another account are left alone, so each account's
+// findings can be applied in turn; an empty account matches every bucket.
+func ApplyExternalAccess(buckets map[string]*BucketInfo, account string, findings map[string][]ExternalAccessFinding) {
+	for _, info := range buckets {
+		if info == nil || !info.Exists || (account != "" && info.AccountID != "" && info.AccountID != account) {
+			continue
+		}
+		if f := findings[info.Name]; len(f) > 0 {
+			info.ExternalAccess = f
+		}
+	}
+}
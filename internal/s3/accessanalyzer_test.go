@@ -0,0 +1,121 @@
+package s3
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/accessanalyzer"
+	analyzertypes "github.com/aws/aws-sdk-go-v2/service/accessanalyzer/types"
+)
+
+// fakeAccessAnalyzerAPI serves one region's analyzers and their findings by
+// analyzer ARN
+type fakeAccessAnalyzerAPI struct {
+	analyzers []analyzertypes.AnalyzerSummary
+	findings  map[string][]analyzertypes.FindingSummary
+	filters   []map[string]analyzertypes.Criterion
+}
+
+func (f *fakeAccessAnalyzerAPI) ListAnalyzers(context.Context, *accessanalyzer.ListAnalyzersInput, ...func(*accessanalyzer.Options)) (*accessanalyzer.ListAnalyzersOutput, error) {
+	return &accessanalyzer.ListAnalyzersOutput{Analyzers: f.analyzers}, nil
+}
+
+func (f *fakeAccessAnalyzerAPI) ListFindings(_ context.Context, params *accessanalyzer.ListFindingsInput, _ ...func(*accessanalyzer.Options)) (*accessanalyzer.ListFindingsOutput, error) {
+	f.filters = append(f.filters, params.Filter)
+	return &accessanalyzer.ListFindingsOutput{Findings: f.findings[aws.ToString(params.AnalyzerArn)]}, nil
+}
+
+func TestExternalAccessFindings(t *testing.T) {
+	analyzerSummary := func(name string, kind analyzertypes.Type, status analyzertypes.AnalyzerStatus) analyzertypes.AnalyzerSummary {
+		return analyzertypes.AnalyzerSummary{Arn: aws.String("arn:analyzer/" + name), Name: aws.String(name), Type: kind, Status: status}
+	}
+	east := &fakeAccessAnalyzerAPI{
+		analyzers: []analyzertypes.AnalyzerSummary{
+			analyzerSummary("org", analyzertypes.TypeOrganization, analyzertypes.AnalyzerStatusActive),
+			analyzerSummary("unused", analyzertypes.TypeAccountUnusedAccess, analyzertypes.AnalyzerStatusActive),
+			analyzerSummary("account", analyzertypes.TypeAccount, analyzertypes.AnalyzerStatusActive),
+		},
+		findings: map[string][]analyzertypes.FindingSummary{
+			"arn:analyzer/account": {
+				{
+					Id:        aws.String("f-1"),
+					Resource:  aws.String("arn:aws:s3:::orders"),
+					Principal: map[string]string{"AWS": "444455556666"},
+					Action:    []string{"s3:GetObject"},
+					Sources:   []analyzertypes.FindingSource{{Type: analyzertypes.FindingSourceTypePolicy}},
+				},
+				{
+					Id:       aws.String("f-2"),
+					Resource: aws.String("arn:aws:s3:::site"),
+					IsPublic: aws.Bool(true),
+					Action:   []string{"s3:GetObject"},
+				},
+				{Id: aws.String("f-3"), Resource: aws.String("arn:aws:s3:::broken"), Error: aws.String("ACCESS_DENIED")},
+			},
+		},
+	}
+	west := &fakeAccessAnalyzerAPI{analyzers: []analyzertypes.AnalyzerSummary{
+		analyzerSummary("creating", analyzertypes.TypeAccount, analyzertypes.AnalyzerStatusCreating),
+	}}
+	apis := map[string]*fakeAccessAnalyzerAPI{"us-east-1": east, "us-west-2": west}
+
+	inspector := NewInspector(&Client{config: aws.Config{Region: "us-east-1"}}, 1)
+	inspector.SetRegions([]string{"us-east-1", "us-west-2"})
+	inspector.newAnalyzerAPI = func(region string) AccessAnalyzerAPI { return apis[region] }
+
+	analysis, err := inspector.ExternalAccessFindings(context.Background())
+	if err != nil {
+		t.Fatalf("ExternalAccessFindings failed: %v", err)
+	}
+	if !reflect.DeepEqual(analysis.Unanalyzed, []string{"us-west-2"}) {
+		t.Errorf("Unanalyzed = %v, want us-west-2 without an active analyzer", analysis.Unanalyzed)
+	}
+	if len(analysis.Findings) != 2 {
+		t.Fatalf("findings = %+v, want orders and site", analysis.Findings)
+	}
+	orders := analysis.Findings["orders"][0]
+	if orders.Analyzer != "account" || orders.Region != "us-east-1" || orders.Public || orders.Grantee() != "AWS 444455556666" || !reflect.DeepEqual(orders.Sources, []string{"POLICY"}) {
+		t.Errorf("orders finding = %+v", orders)
+	}
+	if site := analysis.Findings["site"][0]; !site.Public || site.Grantee() != "public" {
+		t.Errorf("site finding = %+v, want public", site)
+	}
+	if len(east.filters) != 1 || east.filters[0]["resourceType"].Eq[0] != "AWS::S3::Bucket" || east.filters[0]["status"].Eq[0] != "ACTIVE" {
+		t.Errorf("filters = %+v, want active S3 bucket findings", east.filters)
+	}
+}
+
+func TestApplyExternalAccess(t *testing.T) {
+	buckets := map[string]*BucketInfo{
+		"orders": {Name: "orders", Exists: true, AccountID: "111122223333"},
+		"other":  {Name: "other", Exists: true, AccountID: "999999999999"},
+		"gone":   {Name: "gone"},
+	}
+	findings := map[string][]ExternalAccessFinding{
+		"orders": {{ID: "f-1"}},
+		"other":  {{ID: "f-2"}},
+		"gone":   {{ID: "f-3"}},
+	}
+	ApplyExternalAccess(buckets, "111122223333", findings)
+	if len(buckets["orders"].ExternalAccess) != 1 {
+		t.Errorf("orders should carry its finding, got %+v", buckets["orders"].ExternalAccess)
+	}
+	if buckets["other"].ExternalAccess != nil || buckets["gone"].ExternalAccess != nil {
+		t.Error("findings should only apply to existing buckets of the account")
+	}
+}
+
+func TestBucketFromARN(t *testing.T) {
+	for arn, want := range map[string]string{
+		"arn:aws:s3:::orders":        "orders",
+		"arn:aws-cn:s3:::orders":     "orders",
+		"arn:aws:s3:::orders/key":    "",
+		"arn:aws:iam::1:role/orders": "",
+	} {
+		if got := bucketFromARN(arn); got != want {
+			t.Errorf("bucketFromARN(%q) = %q, want %q", arn, got, want)
+		}
+	}
+}
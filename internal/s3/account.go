@@ -0,0 +1,63 @@
+package s3
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3control"
+	"github.com/aws/smithy-go"
+)
+
+// AccountPublicAccessAPI is the subset of the S3 Control client used to read
+// the account-level public access block
+type AccountPublicAccessAPI interface {
+	GetPublicAccessBlock(ctx context.Context, params *s3control.GetPublicAccessBlockInput, optFns ...func(*s3control.Options)) (*s3control.GetPublicAccessBlockOutput, error)
+}
+
+// accountPublicAccessClient returns the S3 Control client for the account
+// public access block, which is global but served from the client's region
+func (i *Inspector) accountPublicAccessClient() AccountPublicAccessAPI {
+	if i.newAccountPABAPI != nil {
+		return i.newAccountPABAPI()
+	}
+	return s3control.NewFromConfig(i.client.config.Copy())
+}
+
+// AccountPublicAccessBlock reads the account-level S3 Block Public Access
+// settings. It returns nil when the account has no configuration. An empty
+// accountID resolves the caller's account.
+func (i *Inspector) AccountPublicAccessBlock(ctx context.Context, accountID string) (*PublicAccessInfo, error) {
+	if accountID == "" {
+		id, err := i.client.AccountID(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve account: %w", err)
+		}
+		accountID = id
+	}
+
+	api := i.accountPublicAccessClient()
+	var out *s3control.GetPublicAccessBlockOutput
+	err := i.client.WithRetry(ctx, func() error {
+		var err error
+		out, err = api.GetPublicAccessBlock(ctx, &s3control.GetPublicAccessBlockInput{AccountId: aws.String(accountID)})
+		return err
+	})
+	if err != nil {
+		var apiErr smithy.APIError
+		if errors.As(err, &apiErr) && apiErr.ErrorCode() == "NoSuchPublicAccessBlockConfiguration" {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read account public access block: %w", err)
+	}
+
+	info := &PublicAccessInfo{}
+	if cfg := out.PublicAccessBlockConfiguration; cfg != nil {
+		info.BlockPublicAcls = aws.ToBool(cfg.BlockPublicAcls)
+		info.IgnorePublicAcls = aws.ToBool(cfg.IgnorePublicAcls)
+		info.BlockPublicPolicy = aws.ToBool(cfg.BlockPublicPolicy)
+		info.RestrictPublicBuckets = aws.ToBool(cfg.RestrictPublicBuckets)
+	}
+	return info, nil
+}
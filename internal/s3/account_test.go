@@ -0,0 +1,67 @@
+package s3
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3control"
+	s3controltypes "github.com/aws/aws-sdk-go-v2/service/s3control/types"
+	"github.com/aws/smithy-go"
+)
+
+// fakeAccountPABAPI serves one account public access block, or an error
+type fakeAccountPABAPI struct {
+	config  *s3controltypes.PublicAccessBlockConfiguration
+	err     error
+	account string
+}
+
+func (f *fakeAccountPABAPI) GetPublicAccessBlock(_ context.Context, params *s3control.GetPublicAccessBlockInput, _ ...func(*s3control.Options)) (*s3control.GetPublicAccessBlockOutput, error) {
+	f.account = aws.ToString(params.AccountId)
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &s3control.GetPublicAccessBlockOutput{PublicAccessBlockConfiguration: f.config}, nil
+}
+
+func TestAccountPublicAccessBlock(t *testing.T) {
+	api := &fakeAccountPABAPI{config: &s3controltypes.PublicAccessBlockConfiguration{
+		BlockPublicAcls:       aws.Bool(true),
+		IgnorePublicAcls:      aws.Bool(true),
+		BlockPublicPolicy:     aws.Bool(false),
+		RestrictPublicBuckets: aws.Bool(true),
+	}}
+	inspector := NewInspector(&Client{config: aws.Config{Region: "us-east-1"}}, 1)
+	inspector.newAccountPABAPI = func() AccountPublicAccessAPI { return api }
+
+	info, err := inspector.AccountPublicAccessBlock(context.Background(), "123456789012")
+	if err != nil {
+		t.Fatalf("AccountPublicAccessBlock failed: %v", err)
+	}
+	if api.account != "123456789012" {
+		t.Errorf("expected request for account 123456789012, got %q", api.account)
+	}
+	if info == nil || !info.BlockPublicAcls || !info.IgnorePublicAcls || info.BlockPublicPolicy || !info.RestrictPublicBuckets {
+		t.Errorf("unexpected settings: %+v", info)
+	}
+}
+
+func TestAccountPublicAccessBlock_NotConfigured(t *testing.T) {
+	api := &fakeAccountPABAPI{err: &smithy.GenericAPIError{Code: "NoSuchPublicAccessBlockConfiguration"}}
+	inspector := NewInspector(&Client{config: aws.Config{Region: "us-east-1"}}, 1)
+	inspector.newAccountPABAPI = func() AccountPublicAccessAPI { return api }
+
+	info, err := inspector.AccountPublicAccessBlock(context.Background(), "123456789012")
+	if err != nil {
+		t.Fatalf("AccountPublicAccessBlock failed: %v", err)
+	}
+	if info != nil {
+		t.Errorf("expected nil for an account without configuration, got %+v", info)
+	}
+
+	api.err = &smithy.GenericAPIError{Code: "AccessDenied"}
+	if _, err := inspector.AccountPublicAccessBlock(context.Background(), "123456789012"); err == nil {
+		t.Error("expected AccessDenied to be returned")
+	}
+}
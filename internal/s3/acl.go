@@ -0,0 +1,136 @@
+package s3
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/smithy-go"
+)
+
+// ACLAPI is the subset of the S3 client used to read a bucket's ACL, its
+// public access block and whether its policy is public
+type ACLAPI interface {
+	GetBucketAcl(ctx context.Context, params *s3.GetBucketAclInput, optFns ...func(*s3.Options)) (*s3.GetBucketAclOutput, error)
+	GetPublicAccessBlock(ctx context.Context, params *s3.GetPublicAccessBlockInput, optFns ...func(*s3.Options)) (*s3.GetPublicAccessBlockOutput, error)
+	GetBucketPolicyStatus(ctx context.Context, params *s3.GetBucketPolicyStatusInput, optFns ...func(*s3.Options)) (*s3.GetBucketPolicyStatusOutput, error)
+}
+
+// publicGroups are the ACL grantee groups that make a bucket public, by URI.
+// AuthenticatedUsers is any AWS account, not only the owner's.
+var publicGroups = map[string]string{
+	"http://acs.amazonaws.com/groups/global/AllUsers":           "AllUsers",
+	"http://acs.amazonaws.com/groups/global/AuthenticatedUsers": "AuthenticatedUsers",
+}
+
+// ACLGrant is a bucket ACL grant to a public group
+type ACLGrant struct {
+	// Grantee is AllUsers or AuthenticatedUsers
+	Grantee string `json:"grantee"`
+	// Permission is READ, WRITE, READ_ACP, WRITE_ACP or FULL_CONTROL
+	Permission string `json:"permission"`
+}
+
+// String renders the grant as "AllUsers READ"
+func (g ACLGrant) String() string {
+	return g.Grantee + " " + g.Permission
+}
+
+// SetCheckPublic makes inspection read each bucket's public access block,
+// ACL and policy status into BucketInfo.PublicAccess
+func (i *Inspector) SetCheckPublic(enabled bool) {
+	i.checkPublic = enabled
+}
+
+// SetAccountPublicAccess sets the account-level public access block, whose
+// settings apply to every bucket on top of the bucket's own. Nil means the
+// account has none.
+func (i *Inspector) SetAccountPublicAccess(block *PublicAccessInfo) {
+	i.accountPublicAccess = block
+}
+
+// aclClient returns the client that reads a bucket's ACL
+func (i *Inspector) aclClient(client *Client) ACLAPI {
+	if i.newACLAPI != nil {
+		return i.newACLAPI(client.GetRegion())
+	}
+	return client.s3Client
+}
+
+// readPublicAccess sets info.PublicAccess from the bucket's public access
+// block, its ACL grants to AllUsers and AuthenticatedUsers and its policy
+// status. Such grants make the bucket public unless IgnorePublicAcls is on
+// for the bucket or the account, and a policy S3 judges public does unless
+// RestrictPublicBuckets is. The block or ACL call failing leaves
+// info.PublicAccess nil; a failed policy status call counts the policy as
+// not public.
+func (i *Inspector) readPublicAccess(ctx context.Context, client *Client, bucket string, info *BucketInfo) {
+	api := i.aclClient(client)
+	access := &PublicAccessInfo{}
+	err := client.WithRetry(ctx, func() error {
+		out, err := api.GetPublicAccessBlock(ctx, &s3.GetPublicAccessBlockInput{Bucket: aws.String(bucket)})
+		if err == nil && out.PublicAccessBlockConfiguration != nil {
+			cfg := out.PublicAccessBlockConfiguration
+			access.BlockPublicAcls = aws.ToBool(cfg.BlockPublicAcls)
+			access.IgnorePublicAcls = aws.ToBool(cfg.IgnorePublicAcls)
+			access.BlockPublicPolicy = aws.ToBool(cfg.BlockPublicPolicy)
+			access.RestrictPublicBuckets = aws.ToBool(cfg.RestrictPublicBuckets)
+		}
+		return err
+	})
+	var apiErr smithy.APIError
+	if err != nil && !(errors.As(err, &apiErr) && apiErr.ErrorCode() == "NoSuchPublicAccessBlockConfiguration") {
+		return
+	}
+
+	err = client.WithRetry(ctx, func() error {
+		out, err := api.GetBucketAcl(ctx, &s3.GetBucketAclInput{Bucket: aws.String(bucket)})
+		if err == nil {
+			access.PublicACLGrants = publicGrants(out)
+		}
+		return err
+	})
+	if err != nil || ctx.Err() != nil {
+		return
+	}
+
+	_ = client.WithRetry(ctx, func() error {
+		out, err := api.GetBucketPolicyStatus(ctx, &s3.GetBucketPolicyStatusInput{Bucket: aws.String(bucket)})
+		if err == nil && out.PolicyStatus != nil {
+			access.PublicPolicy = aws.ToBool(out.PolicyStatus.IsPublic)
+		}
+		if isNoSuchBucketPolicy(err) {
+			return nil
+		}
+		return err
+	})
+	if ctx.Err() != nil {
+		return
+	}
+
+	ignoreACLs, restrict := access.IgnorePublicAcls, access.RestrictPublicBuckets
+	if account := i.accountPublicAccess; account != nil {
+		ignoreACLs = ignoreACLs || account.IgnorePublicAcls
+		restrict = restrict || account.RestrictPublicBuckets
+	}
+	access.IsPublic = (len(access.PublicACLGrants) > 0 && !ignoreACLs) || (access.PublicPolicy && !restrict)
+	info.PublicAccess = access
+}
+
+// publicGrants returns an ACL's grants to AllUsers and AuthenticatedUsers
+func publicGrants(acl *s3.GetBucketAclOutput) []ACLGrant {
+	var grants []ACLGrant
+	for _, grant := range acl.Grants {
+		if grant.Grantee == nil {
+			continue
+		}
+		group, ok := publicGroups[strings.TrimSuffix(aws.ToString(grant.Grantee.URI), "/")]
+		if !ok {
+			continue
+		}
+		grants = append(grants, ACLGrant{Grantee: group, Permission: string(grant.Permission)})
+	}
+	return grants
+}
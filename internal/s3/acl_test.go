@@ -0,0 +1,102 @@
+package s3
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/aws/smithy-go"
+)
+
+// fakeACLAPI returns fixed ACL grants, an optional public access block and
+// a policy status
+type fakeACLAPI struct {
+	grants       []types.Grant
+	block        *types.PublicAccessBlockConfiguration
+	publicPolicy bool
+}
+
+func (f *fakeACLAPI) GetBucketAcl(_ context.Context, _ *s3.GetBucketAclInput, _ ...func(*s3.Options)) (*s3.GetBucketAclOutput, error) {
+	return &s3.GetBucketAclOutput{Grants: f.grants}, nil
+}
+
+func (f *fakeACLAPI) GetPublicAccessBlock(_ context.Context, _ *s3.GetPublicAccessBlockInput, _ ...func(*s3.Options)) (*s3.GetPublicAccessBlockOutput, error) {
+	if f.block == nil {
+		return nil, &smithy.GenericAPIError{Code: "NoSuchPublicAccessBlockConfiguration"}
+	}
+	return &s3.GetPublicAccessBlockOutput{PublicAccessBlockConfiguration: f.block}, nil
+}
+
+func (f *fakeACLAPI) GetBucketPolicyStatus(_ context.Context, _ *s3.GetBucketPolicyStatusInput, _ ...func(*s3.Options)) (*s3.GetBucketPolicyStatusOutput, error) {
+	if !f.publicPolicy {
+		return nil, &smithy.GenericAPIError{Code: "NoSuchBucketPolicy"}
+	}
+	return &s3.GetBucketPolicyStatusOutput{PolicyStatus: &types.PolicyStatus{IsPublic: aws.Bool(true)}}, nil
+}
+
+func groupGrant(uri string, permission types.Permission) types.Grant {
+	return types.Grant{Grantee: &types.Grantee{Type: types.TypeGroup, URI: aws.String(uri)}, Permission: permission}
+}
+
+func TestReadPublicAccess(t *testing.T) {
+	client := &Client{config: aws.Config{Region: "us-east-1"}}
+	inspector := NewInspector(client, 1)
+	api := &fakeACLAPI{grants: []types.Grant{
+		{Grantee: &types.Grantee{Type: types.TypeCanonicalUser, ID: aws.String("owner")}, Permission: types.PermissionFullControl},
+		groupGrant("http://acs.amazonaws.com/groups/global/AllUsers", types.PermissionRead),
+		groupGrant("http://acs.amazonaws.com/groups/global/AuthenticatedUsers", types.PermissionWrite),
+		groupGrant("http://acs.amazonaws.com/groups/s3/LogDelivery", types.PermissionWrite),
+	}}
+	inspector.newACLAPI = func(string) ACLAPI { return api }
+
+	info := &BucketInfo{}
+	inspector.readPublicAccess(context.Background(), client, "legacy", info)
+	if info.PublicAccess == nil || !info.PublicAccess.IsPublic {
+		t.Fatalf("public access = %+v, want public without a public access block", info.PublicAccess)
+	}
+	want := []ACLGrant{{Grantee: "AllUsers", Permission: "READ"}, {Grantee: "AuthenticatedUsers", Permission: "WRITE"}}
+	if !reflect.DeepEqual(info.PublicAccess.PublicACLGrants, want) {
+		t.Errorf("grants = %+v, want %+v", info.PublicAccess.PublicACLGrants, want)
+	}
+
+	// IgnorePublicAcls makes S3 ignore the grants
+	api.block = &types.PublicAccessBlockConfiguration{IgnorePublicAcls: aws.Bool(true), BlockPublicAcls: aws.Bool(true)}
+	info = &BucketInfo{}
+	inspector.readPublicAccess(context.Background(), client, "legacy", info)
+	if info.PublicAccess == nil || info.PublicAccess.IsPublic || !info.PublicAccess.IgnorePublicAcls || len(info.PublicAccess.PublicACLGrants) != 2 {
+		t.Fatalf("public access = %+v, want ignored grants", info.PublicAccess)
+	}
+
+	// The account's IgnorePublicAcls applies to every bucket
+	api.block = nil
+	inspector.SetAccountPublicAccess(&PublicAccessInfo{IgnorePublicAcls: true})
+	info = &BucketInfo{}
+	inspector.readPublicAccess(context.Background(), client, "legacy", info)
+	if info.PublicAccess == nil || info.PublicAccess.IsPublic {
+		t.Fatalf("public access = %+v, want grants ignored by the account block", info.PublicAccess)
+	}
+}
+
+func TestReadPublicAccess_Policy(t *testing.T) {
+	client := &Client{config: aws.Config{Region: "us-east-1"}}
+	inspector := NewInspector(client, 1)
+	api := &fakeACLAPI{publicPolicy: true}
+	inspector.newACLAPI = func(string) ACLAPI { return api }
+
+	info := &BucketInfo{}
+	inspector.readPublicAccess(context.Background(), client, "site", info)
+	if info.PublicAccess == nil || !info.PublicAccess.IsPublic || !info.PublicAccess.PublicPolicy {
+		t.Fatalf("public access = %+v, want public by policy", info.PublicAccess)
+	}
+
+	// RestrictPublicBuckets limits a public policy to the owner's account
+	api.block = &types.PublicAccessBlockConfiguration{RestrictPublicBuckets: aws.Bool(true)}
+	info = &BucketInfo{}
+	inspector.readPublicAccess(context.Background(), client, "site", info)
+	if info.PublicAccess == nil || info.PublicAccess.IsPublic || !info.PublicAccess.PublicPolicy {
+		t.Fatalf("public access = %+v, want a restricted public policy", info.PublicAccess)
+	}
+}
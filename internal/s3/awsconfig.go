@@ -0,0 +1,196 @@
+package s3
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/configservice"
+	"github.com/aws/aws-sdk-go-v2/service/configservice/types"
+)
+
+// configQuerier is the subset of the AWS Config API used by ConfigSource
+type configQuerier interface {
+	SelectAggregateResourceConfig(ctx context.Context, params *configservice.SelectAggregateResourceConfigInput, optFns ...func(*configservice.Options)) (*configservice.SelectAggregateResourceConfigOutput, error)
+}
+
+// ConfigSource reads bucket inventory from an AWS Config aggregator instead of
+// calling S3 APIs bucket by bucket. One query covers every account and region
+// the aggregator collects from.
+type ConfigSource struct {
+	client     configQuerier
+	aggregator string
+	regions    []string
+}
+
+// NewConfigSource creates a bucket source backed by the named Config aggregator
+func NewConfigSource(client *Client, aggregator string) *ConfigSource {
+	return &ConfigSource{
+		client:     configservice.NewFromConfig(client.GetConfig()),
+		aggregator: aggregator,
+	}
+}
+
+// SetRegions limits results to buckets in the given regions
+func (s *ConfigSource) SetRegions(regions []string) {
+	s.regions = regions
+}
+
+// configBucketItem mirrors one row returned by the aggregator query
+type configBucketItem struct {
+	ResourceName               string                     `json:"resourceName"`
+	AWSRegion                  string                     `json:"awsRegion"`
+	AccountID                  string                     `json:"accountId"`
+	ResourceCreationTime       string                     `json:"resourceCreationTime"`
+	Configuration              json.RawMessage            `json:"configuration"`
+	SupplementaryConfiguration map[string]json.RawMessage `json:"supplementaryConfiguration"`
+	Tags                       []configTag                `json:"tags"`
+}
+
+type configTag struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+}
+
+// DiscoverAllBuckets returns every S3 bucket recorded by the aggregator.
+// Object-level data (emptiness, last activity, version sizes) is not recorded
+// by AWS Config and is left unset.
+func (s *ConfigSource) DiscoverAllBuckets(ctx context.Context) (map[string]*BucketInfo, error) {
+	buckets := make(map[string]*BucketInfo)
+
+	var nextToken *string
+	for {
+		out, err := s.client.SelectAggregateResourceConfig(ctx, &configservice.SelectAggregateResourceConfigInput{
+			ConfigurationAggregatorName: aws.String(s.aggregator),
+			Expression:                  aws.String(s.query()),
+			Limit:                       100,
+			NextToken:                   nextToken,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to query config aggregator %s: %w", s.aggregator, err)
+		}
+
+		for _, raw := range out.Results {
+			info, err := parseConfigBucket(raw)
+			if err != nil {
+				return nil, err
+			}
+			buckets[info.Name] = info
+		}
+
+		if out.NextToken == nil || *out.NextToken == "" {
+			break
+		}
+		nextToken = out.NextToken
+	}
+
+	return buckets, nil
+}
+
+// query builds the aggregator SQL expression
+func (s *ConfigSource) query() string {
+	q := "SELECT resourceName, awsRegion, accountId, resourceCreationTime, configuration, supplementaryConfiguration, tags " +
+		"WHERE resourceType = '" + string(types.ResourceTypeBucket) + "'"
+	if len(s.regions) > 0 {
+		quoted := make([]string, len(s.regions))
+		for i, r := range s.regions {
+			quoted[i] = "'" + strings.ReplaceAll(r, "'", "") + "'"
+		}
+		q += " AND awsRegion IN (" + strings.Join(quoted, ", ") + ")"
+	}
+	return q
+}
+
+// parseConfigBucket converts an aggregator result row into BucketInfo
+func parseConfigBucket(raw string) (*BucketInfo, error) {
+	var item configBucketItem
+	if err := json.Unmarshal([]byte(raw), &item); err != nil {
+		return nil, fmt.Errorf("failed to parse config item: %w", err)
+	}
+
+	info := &BucketInfo{
+		Name:      item.ResourceName,
+		Exists:    true,
+		Region:    item.AWSRegion,
+		AccountID: item.AccountID,
+	}
+
+	if created, err := time.Parse(time.RFC3339, item.ResourceCreationTime); err == nil {
+		info.CreationDate = &created
+		info.AgeInDays = int(time.Since(created).Hours() / 24)
+	}
+
+	if len(item.Tags) > 0 {
+		info.Tags = make(map[string]string, len(item.Tags))
+		for _, tag := range item.Tags {
+			info.Tags[tag.Key] = tag.Value
+		}
+	}
+
+	var versioning struct {
+		Status string `json:"status"`
+	}
+	if decodeSupplementary(item.SupplementaryConfiguration, "BucketVersioningConfiguration", &versioning) {
+		info.VersioningEnabled = versioning.Status == "Enabled"
+	}
+
+	var lifecycle struct {
+		Rules []json.RawMessage `json:"rules"`
+	}
+	if decodeSupplementary(item.SupplementaryConfiguration, "BucketLifecycleConfiguration", &lifecycle) {
+		info.LifecycleRules = len(lifecycle.Rules)
+	}
+
+	var encryption struct {
+		Rules []struct {
+			Default struct {
+				SSEAlgorithm   string `json:"sseAlgorithm"`
+				KMSMasterKeyID string `json:"kmsMasterKeyID"`
+			} `json:"applyServerSideEncryptionByDefault"`
+		} `json:"rules"`
+	}
+	// A record without the entry leaves encryption unknown: Config omits it
+	// when it could not read the configuration, not only when there is none
+	if decodeSupplementary(item.SupplementaryConfiguration, "ServerSideEncryptionConfiguration", &encryption) {
+		info.Encryption = &EncryptionInfo{}
+		if len(encryption.Rules) > 0 && encryption.Rules[0].Default.SSEAlgorithm != "" {
+			info.Encryption.Enabled = true
+			info.Encryption.Algorithm = encryption.Rules[0].Default.SSEAlgorithm
+			info.Encryption.KMSMasterKeyID = encryption.Rules[0].Default.KMSMasterKeyID
+		}
+	}
+
+	var block struct {
+		BlockPublicAcls       bool `json:"blockPublicAcls"`
+		IgnorePublicAcls      bool `json:"ignorePublicAcls"`
+		BlockPublicPolicy     bool `json:"blockPublicPolicy"`
+		RestrictPublicBuckets bool `json:"restrictPublicBuckets"`
+	}
+	if decodeSupplementary(item.SupplementaryConfiguration, "PublicAccessBlockConfiguration", &block) {
+		info.PublicAccess = &PublicAccessInfo{
+			BlockPublicAcls:       block.BlockPublicAcls,
+			IgnorePublicAcls:      block.IgnorePublicAcls,
+			BlockPublicPolicy:     block.BlockPublicPolicy,
+			RestrictPublicBuckets: block.RestrictPublicBuckets,
+		}
+	}
+
+	return info, nil
+}
+
+// decodeSupplementary decodes a supplementary configuration entry into v.
+// AWS Config returns these either as nested objects or as JSON-encoded strings.
+func decodeSupplementary(supplementary map[string]json.RawMessage, key string, v interface{}) bool {
+	raw, ok := supplementary[key]
+	if !ok || len(raw) == 0 || string(raw) == "null" {
+		return false
+	}
+	var encoded string
+	if err := json.Unmarshal(raw, &encoded); err == nil {
+		raw = json.RawMessage(encoded)
+	}
+	return json.Unmarshal(raw, v) == nil
+}
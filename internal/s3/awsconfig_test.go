@@ -0,0 +1,90 @@
+package s3
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/configservice"
+)
+
+type fakeConfigQuerier struct {
+	pages       [][]string
+	expressions []string
+}
+
+func (f *fakeConfigQuerier) SelectAggregateResourceConfig(ctx context.Context, params *configservice.SelectAggregateResourceConfigInput, optFns ...func(*configservice.Options)) (*configservice.SelectAggregateResourceConfigOutput, error) {
+	f.expressions = append(f.expressions, aws.ToString(params.Expression))
+	page := 0
+	if params.NextToken != nil {
+		page = 1
+	}
+	out := &configservice.SelectAggregateResourceConfigOutput{Results: f.pages[page]}
+	if page+1 < len(f.pages) {
+		out.NextToken = aws.String("next")
+	}
+	return out, nil
+}
+
+func TestConfigSource_DiscoverAllBuckets(t *testing.T) {
+	querier := &fakeConfigQuerier{pages: [][]string{
+		{`{"resourceName":"logs-bucket","awsRegion":"us-east-1","accountId":"111111111111","resourceCreationTime":"2020-01-01T00:00:00Z",
+		  "supplementaryConfiguration":{"BucketVersioningConfiguration":{"status":"Enabled"},
+		  "ServerSideEncryptionConfiguration":"{\"rules\":[{\"applyServerSideEncryptionByDefault\":{\"sseAlgorithm\":\"aws:kms\",\"kmsMasterKeyID\":\"key-1\"}}]}"},
+		  "tags":[{"key":"team","value":"data"}]}`},
+		{`{"resourceName":"web-bucket","awsRegion":"eu-west-1","accountId":"222222222222",
+		  "supplementaryConfiguration":{"BucketLifecycleConfiguration":{"rules":[{"id":"a"},{"id":"b"}]},
+		  "PublicAccessBlockConfiguration":{"blockPublicAcls":true,"ignorePublicAcls":true,"blockPublicPolicy":false,"restrictPublicBuckets":false}}}`},
+	}}
+	source := &ConfigSource{client: querier, aggregator: "org"}
+	source.SetRegions([]string{"us-east-1", "eu-west-1"})
+
+	buckets, err := source.DiscoverAllBuckets(context.Background())
+	if err != nil {
+		t.Fatalf("DiscoverAllBuckets failed: %v", err)
+	}
+	if len(buckets) != 2 {
+		t.Fatalf("expected 2 buckets, got %d", len(buckets))
+	}
+	if len(querier.expressions) != 2 {
+		t.Fatalf("expected 2 paged queries, got %d", len(querier.expressions))
+	}
+	if !strings.Contains(querier.expressions[0], "awsRegion IN ('us-east-1', 'eu-west-1')") {
+		t.Fatalf("expected region filter in query, got %q", querier.expressions[0])
+	}
+
+	logs := buckets["logs-bucket"]
+	if logs.Region != "us-east-1" || logs.AccountID != "111111111111" {
+		t.Fatalf("unexpected region/account: %s/%s", logs.Region, logs.AccountID)
+	}
+	if !logs.VersioningEnabled {
+		t.Fatalf("expected versioning enabled")
+	}
+	if logs.Encryption == nil || !logs.Encryption.Enabled || logs.Encryption.KMSMasterKeyID != "key-1" {
+		t.Fatalf("expected KMS encryption from string-encoded config, got %+v", logs.Encryption)
+	}
+	if logs.CreationDate == nil || logs.AgeInDays <= 0 {
+		t.Fatalf("expected creation date and age")
+	}
+	if logs.Tags["team"] != "data" {
+		t.Fatalf("expected team tag, got %v", logs.Tags)
+	}
+
+	web := buckets["web-bucket"]
+	if web.LifecycleRules != 2 {
+		t.Fatalf("expected 2 lifecycle rules, got %d", web.LifecycleRules)
+	}
+	if web.Encryption != nil {
+		t.Fatalf("expected unknown encryption without a ServerSideEncryptionConfiguration entry, got %+v", web.Encryption)
+	}
+	if web.PublicAccess == nil || !web.PublicAccess.BlockPublicAcls || web.PublicAccess.BlockPublicPolicy {
+		t.Fatalf("unexpected public access block: %+v", web.PublicAccess)
+	}
+}
+
+func TestParseConfigBucket_InvalidJSON(t *testing.T) {
+	if _, err := parseConfigBucket("not json"); err == nil {
+		t.Fatal("expected error for invalid config item")
+	}
+}
@@ -0,0 +1,89 @@
+package s3
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/aws/retry"
+)
+
+// Backoff is the delay schedule between retries of an AWS call: Base grown
+// by Multiplier per attempt, randomized by up to ±Jitter of the delay and
+// capped at Max
+type Backoff struct {
+	Base       time.Duration
+	Multiplier float64
+	// Jitter is the randomized fraction of each delay, from 0 to 1
+	Jitter float64
+	// Max caps each delay; zero means no cap
+	Max time.Duration
+}
+
+// DefaultBackoff waits about 1s, 2s and 4s between attempts
+var DefaultBackoff = Backoff{Base: time.Second, Multiplier: 2, Jitter: 0.2, Max: 30 * time.Second}
+
+// Validate rejects schedules that shrink or have an out-of-range jitter
+func (b Backoff) Validate() error {
+	if b.Base < 0 || b.Max < 0 {
+		return fmt.Errorf("retry delays must not be negative")
+	}
+	if b.Multiplier < 1 {
+		return fmt.Errorf("retry multiplier must be at least 1, got %g", b.Multiplier)
+	}
+	if b.Jitter < 0 || b.Jitter > 1 {
+		return fmt.Errorf("retry jitter must be between 0 and 1, got %g", b.Jitter)
+	}
+	return nil
+}
+
+// Delay returns the wait before retry number attempt, counted from 0
+func (b Backoff) Delay(attempt int) time.Duration {
+	return b.delay(attempt, rand.Float64())
+}
+
+// delay computes the wait for a random value r in [0, 1)
+func (b Backoff) delay(attempt int, r float64) time.Duration {
+	d := float64(b.Base) * math.Pow(b.Multiplier, float64(attempt))
+	d *= 1 - b.Jitter + 2*b.Jitter*r
+	if b.Max > 0 && d > float64(b.Max) {
+		return b.Max
+	}
+	return time.Duration(d)
+}
+
+// BackoffDelay implements the SDK's retry.BackoffDelayer, whose attempts
+// are counted from 1
+func (b Backoff) BackoffDelay(attempt int, _ error) (time.Duration, error) {
+	return b.Delay(attempt - 1), nil
+}
+
+// WithBackoff sets the delay schedule of WithRetry and of the SDK's own
+// retries. Without it WithRetry uses DefaultBackoff and the SDK its default.
+func WithBackoff(b Backoff) ClientOption {
+	return func(o *clientOptions) {
+		o.backoff = &b
+	}
+}
+
+// applyBackoff makes the SDK's standard retryer follow b
+func applyBackoff(cfg *aws.Config, b Backoff) {
+	cfg.Retryer = func() aws.Retryer {
+		return retry.NewStandard(func(o *retry.StandardOptions) {
+			o.Backoff = b
+			if b.Max > 0 {
+				o.MaxBackoff = b.Max
+			}
+		})
+	}
+}
+
+// retryBackoff is the schedule WithRetry follows
+func (c *Client) retryBackoff() Backoff {
+	if c.backoff == (Backoff{}) {
+		return DefaultBackoff
+	}
+	return c.backoff
+}
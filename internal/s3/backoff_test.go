@@ -0,0 +1,63 @@
+package s3
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestBackoff_Delay(t *testing.T) {
+	b := Backoff{Base: time.Second, Multiplier: 2, Jitter: 0.5, Max: 5 * time.Second}
+	tests := []struct {
+		attempt int
+		r       float64
+		want    time.Duration
+	}{
+		{0, 0.5, time.Second},
+		{1, 0.5, 2 * time.Second},
+		{1, 0, time.Second},
+		{1, 1, 3 * time.Second},
+		{3, 0.5, 5 * time.Second},
+	}
+	for _, tt := range tests {
+		if got := b.delay(tt.attempt, tt.r); got != tt.want {
+			t.Errorf("delay(%d, %g) = %s, want %s", tt.attempt, tt.r, got, tt.want)
+		}
+	}
+	if got, _ := b.BackoffDelay(1, nil); got < 500*time.Millisecond || got > 1500*time.Millisecond {
+		t.Errorf("expected SDK attempt 1 to use the base delay, got %s", got)
+	}
+}
+
+func TestBackoff_Validate(t *testing.T) {
+	if err := DefaultBackoff.Validate(); err != nil {
+		t.Fatalf("expected default backoff to be valid, got %v", err)
+	}
+	for _, b := range []Backoff{
+		{Base: -time.Second, Multiplier: 2},
+		{Base: time.Second, Multiplier: 0.5},
+		{Base: time.Second, Multiplier: 2, Jitter: 1.5},
+	} {
+		if err := b.Validate(); err == nil {
+			t.Errorf("expected error for %+v", b)
+		}
+	}
+}
+
+func TestWithRetry_Backoff(t *testing.T) {
+	client := &Client{backoff: Backoff{Multiplier: 1}}
+	attempts := 0
+	start := time.Now()
+	err := client.WithRetry(context.Background(), func() error {
+		attempts++
+		return errors.New("SlowDown: Please reduce your request rate")
+	})
+	if err == nil || attempts != 3 {
+		t.Fatalf("expected 3 failed attempts, got %d (err %v)", attempts, err)
+	}
+	// A zero base delay retries immediately instead of waiting 1s and 2s
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("expected configured backoff to be used, took %s", elapsed)
+	}
+}
@@ -0,0 +1,133 @@
+package s3
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// checkpointVersion is bumped when the checkpoint layout changes
+const checkpointVersion = 1
+
+// Checkpoint records fully inspected buckets so a run interrupted by
+// credential expiry can resume without inspecting them again
+type Checkpoint struct {
+	Version int                    `json:"version"`
+	Command string                 `json:"command"`
+	Saved   time.Time              `json:"saved"`
+	Buckets map[string]*BucketInfo `json:"buckets"`
+
+	mu sync.Mutex
+	// hits and misses count lookups, for run statistics
+	hits   int
+	misses int
+}
+
+// NewCheckpoint creates an empty checkpoint for a command (scan, discover)
+func NewCheckpoint(command string) *Checkpoint {
+	return &Checkpoint{
+		Version: checkpointVersion,
+		Command: command,
+		Buckets: make(map[string]*BucketInfo),
+	}
+}
+
+// LoadCheckpoint reads a checkpoint written by the same command
+func LoadCheckpoint(path, command string) (*Checkpoint, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read checkpoint: %w", err)
+	}
+	var cp Checkpoint
+	if err := json.Unmarshal(data, &cp); err != nil {
+		return nil, fmt.Errorf("failed to parse checkpoint %s: %w", path, err)
+	}
+	if cp.Version != checkpointVersion {
+		return nil, fmt.Errorf("checkpoint %s has unsupported version %d", path, cp.Version)
+	}
+	if cp.Command != command {
+		return nil, fmt.Errorf("checkpoint %s was written by %s, not %s", path, cp.Command, command)
+	}
+	if cp.Buckets == nil {
+		cp.Buckets = make(map[string]*BucketInfo)
+	}
+	return &cp, nil
+}
+
+// Len returns the number of checkpointed buckets
+func (c *Checkpoint) Len() int {
+	if c == nil {
+		return 0
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.Buckets)
+}
+
+// Save writes the checkpoint atomically
+func (c *Checkpoint) Save(path string) error {
+	c.mu.Lock()
+	c.Saved = time.Now().UTC()
+	data, err := json.MarshalIndent(c, "", "  ")
+	c.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".s3spectre-checkpoint-*")
+	if err != nil {
+		return fmt.Errorf("failed to write checkpoint: %w", err)
+	}
+	defer func() { _ = os.Remove(tmp.Name()) }()
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("failed to write checkpoint: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write checkpoint: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to write checkpoint: %w", err)
+	}
+	return nil
+}
+
+// lookup returns a checkpointed bucket; safe on a nil checkpoint
+func (c *Checkpoint) lookup(bucket string) (*BucketInfo, bool) {
+	if c == nil {
+		return nil, false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	info, ok := c.Buckets[bucket]
+	if ok {
+		c.hits++
+	} else {
+		c.misses++
+	}
+	return info, ok
+}
+
+// Lookups returns how many buckets were served from the checkpoint and how
+// many had to be inspected; safe on a nil checkpoint
+func (c *Checkpoint) Lookups() (hits, misses int) {
+	if c == nil {
+		return 0, 0
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.hits, c.misses
+}
+
+// record stores a fully inspected bucket; safe on a nil checkpoint
+func (c *Checkpoint) record(info *BucketInfo) {
+	// Buckets with skipped checks are inspected again on resume
+	if c == nil || info == nil || len(info.SkippedChecks) > 0 {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.Buckets[info.Name] = info
+}
@@ -0,0 +1,95 @@
+package s3
+
+import (
+	"context"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCheckpoint_SaveLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "checkpoint.json")
+	cp := NewCheckpoint("discover")
+	cp.record(&BucketInfo{Name: "logs", Exists: true, Region: "us-east-1", VersioningEnabled: true})
+	if err := cp.Save(path); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	loaded, err := LoadCheckpoint(path, "discover")
+	if err != nil {
+		t.Fatalf("LoadCheckpoint failed: %v", err)
+	}
+	info, ok := loaded.lookup("logs")
+	if !ok || !info.VersioningEnabled || info.Region != "us-east-1" {
+		t.Fatalf("unexpected checkpointed bucket: %+v (ok=%v)", info, ok)
+	}
+	if _, err := LoadCheckpoint(path, "scan"); err == nil {
+		t.Fatalf("expected error loading a discover checkpoint for scan")
+	}
+}
+
+func TestInspectDiscovered_SkipsCheckpointedBuckets(t *testing.T) {
+	cp := NewCheckpoint("discover")
+	cp.record(&BucketInfo{Name: "done", Exists: true, Region: "us-east-1", ObjectCount: 42})
+
+	// A nil client would panic if the checkpointed bucket were inspected again
+	inspector := NewInspector(nil, 2)
+	inspector.SetCheckpoint(cp)
+	buckets := inspector.inspectDiscovered(context.Background(), map[string]string{"done": "us-east-1"}, nil)
+
+	if buckets["done"] == nil || buckets["done"].ObjectCount != 42 {
+		t.Fatalf("expected checkpointed bucket to be reused, got %+v", buckets["done"])
+	}
+	if hits, misses := cp.Lookups(); hits != 1 || misses != 0 {
+		t.Fatalf("expected 1 checkpoint hit, got %d hits and %d misses", hits, misses)
+	}
+}
+
+func TestInspectDiscovered_BucketTimeout(t *testing.T) {
+	// Every request hangs until the bucket's deadline cancels it
+	rt := roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		<-req.Context().Done()
+		return nil, req.Context().Err()
+	})
+	cp := NewCheckpoint("discover")
+	inspector := NewInspector(newTestClient(t, rt), 2)
+	inspector.SetCheckpoint(cp)
+	inspector.SetBucketTimeout(50 * time.Millisecond)
+
+	buckets := inspector.inspectDiscovered(context.Background(), map[string]string{"hung": "us-east-1"}, nil)
+
+	info := buckets["hung"]
+	if info == nil || !info.TimedOut || !info.Exists || info.Region != "us-east-1" {
+		t.Fatalf("expected a timed-out existing bucket, got %+v", info)
+	}
+	if !strings.Contains(info.Error, "timed out after 50ms") {
+		t.Fatalf("unexpected error: %q", info.Error)
+	}
+	if cp.Len() != 0 {
+		t.Fatalf("expected timed-out bucket to stay out of the checkpoint")
+	}
+}
+
+func TestInspectDiscovered_Interrupted(t *testing.T) {
+	rt := roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		<-req.Context().Done()
+		return nil, req.Context().Err()
+	})
+	cp := NewCheckpoint("discover")
+	inspector := NewInspector(newTestClient(t, rt), 2)
+	inspector.SetCheckpoint(cp)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	buckets := inspector.inspectDiscovered(ctx, map[string]string{"logs": "us-east-1"}, nil)
+
+	info := buckets["logs"]
+	if info == nil || !info.Interrupted || info.TimedOut || !info.Exists {
+		t.Fatalf("expected an interrupted existing bucket, got %+v", info)
+	}
+	if cp.Len() != 0 {
+		t.Fatalf("expected interrupted bucket to stay out of the checkpoint")
+	}
+}
@@ -2,25 +2,113 @@ package s3
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"math"
+	"io"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/credentials/stscreds"
 	"github.com/aws/aws-sdk-go-v2/service/ec2"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
 )
 
 // Client wraps the AWS S3 client
 type Client struct {
-	s3Client *s3.Client
-	config   aws.Config
+	s3Client  *s3.Client
+	config    aws.Config
+	endpoints map[string]string
+	// endpointURL serves S3 in every region from an S3-compatible store
+	endpointURL string
+	pathStyle   bool
+	counter     *apiCounter
+	backoff     Backoff
+}
+
+// DefaultRoleSessionDuration is the assumed-role session length when none is set
+const DefaultRoleSessionDuration = time.Hour
+
+// minRoleSessionDuration is the shortest session sts:AssumeRole accepts
+const minRoleSessionDuration = 15 * time.Minute
+
+// ErrMFACodeUsed is returned when an MFA-protected role session needs renewing.
+// MFA codes are accepted once, so the session cannot outlive the first one.
+var ErrMFACodeUsed = errors.New("the MFA code was already used and the role session cannot be renewed; re-run with a longer --role-session-duration")
+
+// AssumeRole describes an IAM role assumed on top of the profile's credentials
+type AssumeRole struct {
+	RoleARN    string
+	ExternalID string
+	// MFASerial is the ARN or serial number of the caller's MFA device
+	MFASerial string
+	// MFAToken is the current MFA code. When empty and MFASerial is set, the
+	// code is prompted for on stdin. Either way the code is used for the first
+	// session only; renewing it fails with ErrMFACodeUsed.
+	MFAToken    string
+	Duration    time.Duration
+	SessionName string
+}
+
+// Validate checks that role options are only used together with a role ARN
+func (r AssumeRole) Validate() error {
+	if r.RoleARN == "" {
+		if r.ExternalID != "" || r.MFASerial != "" || r.MFAToken != "" {
+			return fmt.Errorf("--external-id, --mfa-serial and --mfa-token require --role-arn")
+		}
+		return nil
+	}
+	if r.MFAToken != "" && r.MFASerial == "" {
+		return fmt.Errorf("--mfa-token requires --mfa-serial")
+	}
+	if r.Duration != 0 && r.Duration < minRoleSessionDuration {
+		return fmt.Errorf("--role-session-duration must be at least %s", minRoleSessionDuration)
+	}
+	return nil
+}
+
+// ClientOption customizes NewClient
+type ClientOption func(*clientOptions)
+
+type clientOptions struct {
+	assumeRole  *AssumeRole
+	ssoLogin    io.Writer
+	http        HTTPOptions
+	endpoints   map[string]string
+	endpointURL string
+	pathStyle   bool
+	backoff     *Backoff
+}
+
+// WithAssumeRole makes the client assume an IAM role. A zero RoleARN leaves
+// the profile's credentials unchanged.
+func WithAssumeRole(role AssumeRole) ClientOption {
+	return func(o *clientOptions) {
+		if role.RoleARN != "" {
+			o.assumeRole = &role
+		}
+	}
+}
+
+// WithSSOLogin runs the IAM Identity Center device-code login, printing
+// instructions to prompt, when the profile's SSO session has expired.
+// Without it an expired session fails with an *SSOSessionError.
+func WithSSOLogin(prompt io.Writer) ClientOption {
+	return func(o *clientOptions) {
+		o.ssoLogin = prompt
+	}
 }
 
 // NewClient creates a new S3 client
-func NewClient(ctx context.Context, profile, region string) (*Client, error) {
+func NewClient(ctx context.Context, profile, region string, options ...ClientOption) (*Client, error) {
+	var o clientOptions
+	for _, option := range options {
+		option(&o)
+	}
+
 	// Load AWS config
 	opts := []func(*config.LoadOptions) error{}
 
@@ -32,15 +120,124 @@ func NewClient(ctx context.Context, profile, region string) (*Client, error) {
 		opts = append(opts, config.WithRegion(region))
 	}
 
+	if err := ValidateEndpoints(o.endpoints); err != nil {
+		return nil, err
+	}
+	if err := ValidateEndpointURL(o.endpointURL); err != nil {
+		return nil, err
+	}
+	backoff := DefaultBackoff
+	if o.backoff != nil {
+		if err := o.backoff.Validate(); err != nil {
+			return nil, err
+		}
+		backoff = *o.backoff
+	}
+	httpClient, err := newHTTPClient(o.http)
+	if err != nil {
+		return nil, err
+	}
+	opts = append(opts, config.WithHTTPClient(httpClient))
+
 	cfg, err := config.LoadDefaultConfig(ctx, opts...)
 	if err != nil {
 		return nil, err
 	}
+	if cfg.Region == "" && o.endpointURL != "" {
+		// S3-compatible stores still need a region to sign requests
+		cfg.Region = defaultEndpointRegion
+	}
+	counter := newAPICounter()
+	cfg.APIOptions = append(cfg.APIOptions, counter.register)
+	if o.backoff != nil {
+		applyBackoff(&cfg, backoff)
+	}
 
-	return &Client{
-		s3Client: s3.NewFromConfig(cfg),
-		config:   cfg,
-	}, nil
+	// Expired SSO sessions otherwise surface as a credentials error mid-scan
+	if sso, ok := LoadSSOProfile(ctx, profile); ok {
+		relogged, err := checkSSOSession(ctx, cfg, sso, o.ssoLogin)
+		if err != nil {
+			return nil, err
+		}
+		if relogged {
+			if cfg, err = config.LoadDefaultConfig(ctx, opts...); err != nil {
+				return nil, err
+			}
+			cfg.APIOptions = append(cfg.APIOptions, counter.register)
+			if o.backoff != nil {
+				applyBackoff(&cfg, backoff)
+			}
+		}
+	}
+
+	if o.assumeRole != nil {
+		if err := assumeRole(ctx, &cfg, *o.assumeRole); err != nil {
+			return nil, err
+		}
+	}
+
+	base := &Client{
+		endpoints:   o.endpoints,
+		endpointURL: o.endpointURL,
+		pathStyle:   o.pathStyle,
+		counter:     counter,
+		backoff:     backoff,
+	}
+	return base.withConfig(cfg), nil
+}
+
+// assumeRole swaps cfg's credentials for the role's, fetching them once so
+// bad MFA codes or trust policies fail before any S3 call
+func assumeRole(ctx context.Context, cfg *aws.Config, role AssumeRole) error {
+	if err := role.Validate(); err != nil {
+		return err
+	}
+	provider := stscreds.NewAssumeRoleProvider(sts.NewFromConfig(*cfg), role.RoleARN, func(o *stscreds.AssumeRoleOptions) {
+		o.RoleSessionName = role.SessionName
+		if o.RoleSessionName == "" {
+			o.RoleSessionName = "s3spectre"
+		}
+		o.Duration = role.Duration
+		if o.Duration == 0 {
+			o.Duration = DefaultRoleSessionDuration
+		}
+		if role.ExternalID != "" {
+			o.ExternalID = aws.String(role.ExternalID)
+		}
+		if role.MFASerial != "" {
+			o.SerialNumber = aws.String(role.MFASerial)
+			token := stscreds.StdinTokenProvider
+			if role.MFAToken != "" {
+				code := role.MFAToken
+				token = func() (string, error) { return code, nil }
+			}
+			o.TokenProvider = oneTimeToken(token)
+		}
+	})
+	cfg.Credentials = aws.NewCredentialsCache(provider)
+	if _, err := cfg.Credentials.Retrieve(ctx); err != nil {
+		return fmt.Errorf("failed to assume role %s: %w", role.RoleARN, err)
+	}
+	return nil
+}
+
+// oneTimeToken hands out the first MFA code from token and fails every later
+// request with ErrMFACodeUsed, rather than resending a spent code or prompting
+// on stdin in the middle of a scan
+func oneTimeToken(token func() (string, error)) func() (string, error) {
+	var (
+		mu   sync.Mutex
+		used bool
+	)
+	return func() (string, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		if used {
+			return "", ErrMFACodeUsed
+		}
+		used = true
+		return token()
+	}
 }
 
 // GetClient returns the underlying S3 client
@@ -58,8 +255,12 @@ func (c *Client) GetConfig() aws.Config {
 	return c.config
 }
 
-// ListRegions returns all enabled AWS regions
+// ListRegions returns all enabled AWS regions. S3-compatible stores have no
+// region list, so behind --endpoint-url it is the client's region alone.
 func (c *Client) ListRegions(ctx context.Context) ([]string, error) {
+	if c.endpointURL != "" {
+		return []string{c.config.Region}, nil
+	}
 	// Create EC2 client to list regions
 	ec2Client := ec2.NewFromConfig(c.config)
 
@@ -80,6 +281,15 @@ func (c *Client) ListRegions(ctx context.Context) ([]string, error) {
 	return regions, nil
 }
 
+// AccountID returns the AWS account ID of the caller's credentials
+func (c *Client) AccountID(ctx context.Context) (string, error) {
+	out, err := sts.NewFromConfig(c.config).GetCallerIdentity(ctx, &sts.GetCallerIdentityInput{})
+	if err != nil {
+		return "", fmt.Errorf("failed to get caller identity: %w", err)
+	}
+	return aws.ToString(out.Account), nil
+}
+
 // NewClientForRegion creates a new S3 client for a specific region
 func NewClientForRegion(baseConfig aws.Config, region string) *Client {
 	// Create a new config with the specified region
@@ -92,10 +302,61 @@ func NewClientForRegion(baseConfig aws.Config, region string) *Client {
 	}
 }
 
+// ForRegion returns a client for region that keeps c's endpoint overrides
+func (c *Client) ForRegion(region string) *Client {
+	cfg := c.config.Copy()
+	cfg.Region = region
+	return c.withConfig(cfg)
+}
+
+// withConfig returns a client for cfg that keeps c's endpoints, retry
+// schedule and API call accounting
+func (c *Client) withConfig(cfg aws.Config) *Client {
+	clone := &Client{
+		config:      cfg,
+		endpoints:   c.endpoints,
+		endpointURL: c.endpointURL,
+		pathStyle:   c.pathStyle,
+		counter:     c.counter,
+		backoff:     c.backoff,
+	}
+	clone.s3Client = clone.newS3Client(cfg)
+	return clone
+}
+
+// BucketRegion looks up the region a bucket lives in
+func (c *Client) BucketRegion(ctx context.Context, bucket string) (string, error) {
+	var locationResult *s3.GetBucketLocationOutput
+	err := c.WithRetry(ctx, func() error {
+		var err error
+		locationResult, err = c.s3Client.GetBucketLocation(ctx, &s3.GetBucketLocationInput{
+			Bucket: aws.String(bucket),
+		})
+		return err
+	})
+	if err != nil {
+		if c.endpointURL != "" && unsupportedOperation(err) {
+			// Some S3-compatible stores do not implement GetBucketLocation
+			return c.config.Region, nil
+		}
+		return "", err
+	}
+
+	// Handle the special case where us-east-1 returns empty string
+	if locationResult.LocationConstraint == "" {
+		if c.endpointURL != "" {
+			return c.config.Region, nil
+		}
+		return "us-east-1", nil
+	}
+
+	return string(locationResult.LocationConstraint), nil
+}
+
 // WithRetry wraps an S3 operation with retry logic for transient errors
 func (c *Client) WithRetry(ctx context.Context, operation func() error) error {
 	const maxRetries = 3
-	const baseDelay = 1 * time.Second
+	backoff := c.retryBackoff()
 
 	var lastErr error
 	for attempt := 0; attempt < maxRetries; attempt++ {
@@ -113,12 +374,12 @@ func (c *Client) WithRetry(ctx context.Context, operation func() error) error {
 
 		// Don't sleep on the last attempt
 		if attempt < maxRetries-1 {
+			c.counter.retried()
 			// Exponential backoff with jitter
-			delay := time.Duration(math.Pow(2, float64(attempt))) * baseDelay
 			select {
 			case <-ctx.Done():
 				return ctx.Err()
-			case <-time.After(delay):
+			case <-time.After(backoff.Delay(attempt)):
 			}
 		}
 	}
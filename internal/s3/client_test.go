@@ -3,9 +3,15 @@ package s3
 import (
 	"context"
 	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
 	"testing"
+	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/credentials"
 )
 
 func TestNewClient_RegionOverride(t *testing.T) {
@@ -96,3 +102,106 @@ func TestWithRetry_ContextCanceled(t *testing.T) {
 		t.Fatalf("expected context canceled, got %v", err)
 	}
 }
+
+func TestAssumeRoleValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		role    AssumeRole
+		wantErr bool
+	}{
+		{name: "no role", role: AssumeRole{}},
+		{name: "role only", role: AssumeRole{RoleARN: "arn:aws:iam::123456789012:role/audit"}},
+		{name: "role with mfa", role: AssumeRole{RoleARN: "arn:aws:iam::123456789012:role/audit", ExternalID: "x", MFASerial: "arn:aws:iam::123456789012:mfa/me", MFAToken: "123456"}},
+		{name: "external id without role", role: AssumeRole{ExternalID: "x"}, wantErr: true},
+		{name: "mfa serial without role", role: AssumeRole{MFASerial: "arn:aws:iam::123456789012:mfa/me"}, wantErr: true},
+		{name: "token without serial", role: AssumeRole{RoleARN: "arn:aws:iam::123456789012:role/audit", MFAToken: "123456"}, wantErr: true},
+		{name: "duration too short", role: AssumeRole{RoleARN: "arn:aws:iam::123456789012:role/audit", Duration: time.Minute}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.role.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestAssumeRole_SendsExternalIDAndMFA(t *testing.T) {
+	var form url.Values
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil {
+			t.Errorf("parse form: %v", err)
+		}
+		form = r.PostForm
+		w.Header().Set("Content-Type", "text/xml")
+		_, _ = fmt.Fprintf(w, `<AssumeRoleResponse xmlns="https://sts.amazonaws.com/doc/2011-06-15/">
+<AssumeRoleResult><Credentials>
+<AccessKeyId>AKIDROLE</AccessKeyId><SecretAccessKey>secret</SecretAccessKey><SessionToken>token</SessionToken>
+<Expiration>%s</Expiration>
+</Credentials></AssumeRoleResult></AssumeRoleResponse>`, time.Now().Add(time.Hour).UTC().Format(time.RFC3339))
+	}))
+	defer server.Close()
+
+	cfg := aws.Config{
+		Region:       "us-east-1",
+		Credentials:  credentials.NewStaticCredentialsProvider("AKIDBASE", "secret", ""),
+		BaseEndpoint: aws.String(server.URL),
+	}
+	role := AssumeRole{
+		RoleARN:    "arn:aws:iam::123456789012:role/audit",
+		ExternalID: "ext-123",
+		MFASerial:  "arn:aws:iam::111111111111:mfa/auditor",
+		MFAToken:   "654321",
+		Duration:   30 * time.Minute,
+	}
+	if err := assumeRole(context.Background(), &cfg, role); err != nil {
+		t.Fatalf("assumeRole failed: %v", err)
+	}
+
+	want := map[string]string{
+		"Action":          "AssumeRole",
+		"RoleArn":         role.RoleARN,
+		"RoleSessionName": "s3spectre",
+		"ExternalId":      "ext-123",
+		"SerialNumber":    role.MFASerial,
+		"TokenCode":       "654321",
+		"DurationSeconds": "1800",
+	}
+	for key, value := range want {
+		if got := form.Get(key); got != value {
+			t.Fatalf("expected %s=%q, got %q", key, value, got)
+		}
+	}
+
+	creds, err := cfg.Credentials.Retrieve(context.Background())
+	if err != nil {
+		t.Fatalf("Retrieve failed: %v", err)
+	}
+	if creds.AccessKeyID != "AKIDROLE" {
+		t.Fatalf("expected assumed-role credentials, got %q", creds.AccessKeyID)
+	}
+
+	// Renewing the session would resend the spent MFA code
+	cfg.Credentials.(*aws.CredentialsCache).Invalidate()
+	if _, err := cfg.Credentials.Retrieve(context.Background()); !errors.Is(err, ErrMFACodeUsed) {
+		t.Fatalf("expected ErrMFACodeUsed on renewal, got %v", err)
+	}
+}
+
+func TestOneTimeToken(t *testing.T) {
+	calls := 0
+	token := oneTimeToken(func() (string, error) {
+		calls++
+		return "654321", nil
+	})
+	if code, err := token(); err != nil || code != "654321" {
+		t.Fatalf("expected the first code, got %q, %v", code, err)
+	}
+	if _, err := token(); !errors.Is(err, ErrMFACodeUsed) {
+		t.Fatalf("expected ErrMFACodeUsed on renewal, got %v", err)
+	}
+	if calls != 1 {
+		t.Fatalf("expected the code to be read once, got %d", calls)
+	}
+}
@@ -0,0 +1,304 @@
+package s3
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatch"
+	cwtypes "github.com/aws/aws-sdk-go-v2/service/cloudwatch/types"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+// Sources of bucket size and activity
+const (
+	MetricsSourceList       = "list"
+	MetricsSourceCloudWatch = "cloudwatch"
+)
+
+// cloudWatchLookback is how far back daily metrics are read; CloudWatch
+// keeps daily storage metrics and hourly request metrics for 455 days
+const cloudWatchLookback = 455 * 24 * time.Hour
+
+// cloudWatchPeriod is the one-day period storage metrics are reported at
+const cloudWatchPeriod = 86400
+
+// CloudWatchAPI is the subset of the CloudWatch client used to read S3 metrics
+type CloudWatchAPI interface {
+	GetMetricData(ctx context.Context, params *cloudwatch.GetMetricDataInput, optFns ...func(*cloudwatch.Options)) (*cloudwatch.GetMetricDataOutput, error)
+}
+
+// MetricsConfigAPI is the subset of the S3 client used to find a bucket's
+// request metrics filters
+type MetricsConfigAPI interface {
+	ListBucketMetricsConfigurations(ctx context.Context, params *s3.ListBucketMetricsConfigurationsInput, optFns ...func(*s3.Options)) (*s3.ListBucketMetricsConfigurationsOutput, error)
+}
+
+// CloudWatchActivity is how a bucket's size and activity were read from
+// CloudWatch instead of an object listing
+type CloudWatchActivity struct {
+	// SizeDate is the day of the latest storage metrics
+	SizeDate time.Time `json:"size_date"`
+	// LastChange is the latest day the object count or size changed; nil
+	// when neither changed since Since
+	LastChange *time.Time `json:"last_change,omitempty"`
+	// LastRequest is the latest day with GET or PUT requests; nil without
+	// request metrics or requests
+	LastRequest *time.Time `json:"last_request,omitempty"`
+	// RequestMetrics is set when the bucket has request metrics
+	RequestMetrics bool `json:"request_metrics"`
+	// Since is the earliest day metrics were found for
+	Since time.Time `json:"since"`
+}
+
+// SetMetricsSource sets where the activity check reads bucket size and
+// activity: MetricsSourceList samples objects, MetricsSourceCloudWatch reads
+// the daily storage metrics and any request metrics instead, listing only
+// buckets CloudWatch has no metrics for
+func (i *Inspector) SetMetricsSource(source string) {
+	i.metricsSource = source
+}
+
+// cloudWatchClient returns the CloudWatch client for a region
+func (i *Inspector) cloudWatchClient(region string) CloudWatchAPI {
+	if i.newCloudWatchAPI != nil {
+		return i.newCloudWatchAPI(region)
+	}
+	cfg := i.client.config.Copy()
+	cfg.Region = region
+	return cloudwatch.NewFromConfig(cfg)
+}
+
+// metricsConfigClient returns the S3 client reading request metrics
+// configurations in the bucket's region
+func (i *Inspector) metricsConfigClient(client *Client) MetricsConfigAPI {
+	if i.newMetricsConfigAPI != nil {
+		return i.newMetricsConfigAPI(client.GetRegion())
+	}
+	return client.s3Client
+}
+
+// cloudWatchActivity is the activity check from CloudWatch metrics. It
+// reports false when the metrics could not be read or the bucket has none,
+// such as a bucket created today, so the caller lists objects instead.
+func (i *Inspector) cloudWatchActivity(ctx context.Context, client *Client, bucket string, info *BucketInfo) bool {
+	api := i.cloudWatchClient(client.GetRegion())
+	// Without the bucket's filters, requests are left unknown
+	filterID, _ := requestMetricsFilter(ctx, client, i.metricsConfigClient(client), bucket)
+	activity, size, objects, err := readBucketMetrics(ctx, client, api, bucket, filterID, time.Now())
+	if err != nil || activity == nil {
+		return false
+	}
+	info.CloudWatch = activity
+	info.TotalSize = size
+	info.ObjectCount = objects
+	info.IsEmpty = objects == 0
+
+	last := activity.LastChange
+	if activity.LastRequest != nil && (last == nil || activity.LastRequest.After(*last)) {
+		last = activity.LastRequest
+	}
+	if last == nil {
+		// Nothing changed in the window, so the bucket has been idle at
+		// least since its earliest metrics
+		last = &activity.Since
+	}
+	info.LastActivity = last
+	info.DaysSinceActivity = int(time.Since(*last).Hours() / 24)
+	return true
+}
+
+// requestMetricsFilter returns the ID of a request metrics filter covering
+// the whole bucket, preferring the one s3spectre adds, or "" when the
+// bucket has none. Each whole-bucket filter counts every request, so only
+// one is read; filters on a prefix or tag count a part of the bucket.
+func requestMetricsFilter(ctx context.Context, client *Client, api MetricsConfigAPI, bucket string) (string, error) {
+	var ids []string
+	input := &s3.ListBucketMetricsConfigurationsInput{Bucket: aws.String(bucket)}
+	for {
+		var out *s3.ListBucketMetricsConfigurationsOutput
+		err := client.WithRetry(ctx, func() error {
+			var err error
+			out, err = api.ListBucketMetricsConfigurations(ctx, input)
+			return err
+		})
+		if err != nil {
+			return "", err
+		}
+		for _, config := range out.MetricsConfigurationList {
+			if config.Filter == nil {
+				ids = append(ids, aws.ToString(config.Id))
+			}
+		}
+		if !aws.ToBool(out.IsTruncated) || out.NextContinuationToken == nil {
+			break
+		}
+		input.ContinuationToken = out.NextContinuationToken
+	}
+	if len(ids) == 0 {
+		return "", nil
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		if id == MetricsConfigID {
+			return id, nil
+		}
+	}
+	return ids[0], nil
+}
+
+// readBucketMetrics reads a bucket's daily size, object count and GET and
+// PUT request counts. Size is summed over storage types; requests are read
+// from the request metrics filter filterID, or not at all when it is "". It
+// returns nil activity when the bucket has no storage metrics.
+func readBucketMetrics(ctx context.Context, client *Client, api CloudWatchAPI, bucket, filterID string, now time.Time) (*CloudWatchActivity, int64, int, error) {
+	input := &cloudwatch.GetMetricDataInput{
+		StartTime: aws.Time(now.Add(-cloudWatchLookback)),
+		EndTime:   aws.Time(now),
+		ScanBy:    cwtypes.ScanByTimestampDescending,
+		MetricDataQueries: []cwtypes.MetricDataQuery{
+			{
+				Id:         aws.String("size"),
+				Expression: aws.String(fmt.Sprintf(`SUM(SEARCH('{AWS/S3,BucketName,StorageType} MetricName="BucketSizeBytes" BucketName="%s"', 'Average', %d))`, bucket, cloudWatchPeriod)),
+			},
+			{
+				Id: aws.String("objects"),
+				MetricStat: &cwtypes.MetricStat{
+					Metric: &cwtypes.Metric{
+						Namespace:  aws.String("AWS/S3"),
+						MetricName: aws.String("NumberOfObjects"),
+						Dimensions: []cwtypes.Dimension{
+							{Name: aws.String("BucketName"), Value: aws.String(bucket)},
+							{Name: aws.String("StorageType"), Value: aws.String("AllStorageTypes")},
+						},
+					},
+					Period: aws.Int32(cloudWatchPeriod),
+					Stat:   aws.String("Average"),
+				},
+			},
+		},
+	}
+	if filterID != "" {
+		input.MetricDataQueries = append(input.MetricDataQueries,
+			requestQuery("gets", "GetRequests", bucket, filterID),
+			requestQuery("puts", "PutRequests", bucket, filterID))
+	}
+
+	series := make(map[string][]metricPoint)
+	for {
+		var out *cloudwatch.GetMetricDataOutput
+		err := client.WithRetry(ctx, func() error {
+			var err error
+			out, err = api.GetMetricData(ctx, input)
+			return err
+		})
+		if err != nil {
+			return nil, 0, 0, err
+		}
+		for _, result := range out.MetricDataResults {
+			id := aws.ToString(result.Id)
+			for k, ts := range result.Timestamps {
+				if k < len(result.Values) {
+					series[id] = append(series[id], metricPoint{at: ts, value: result.Values[k]})
+				}
+			}
+		}
+		if out.NextToken == nil || *out.NextToken == "" {
+			break
+		}
+		input.NextToken = out.NextToken
+	}
+
+	size, objects := series["size"], series["objects"]
+	if len(size) == 0 && len(objects) == 0 {
+		return nil, 0, 0, nil
+	}
+	for _, points := range series {
+		sortPoints(points)
+	}
+
+	activity := &CloudWatchActivity{}
+	var newest, oldest time.Time
+	var sizeBytes int64
+	var count int
+	if len(size) > 0 {
+		sizeBytes = int64(size[len(size)-1].value)
+		newest, oldest = size[len(size)-1].at, size[0].at
+	}
+	if len(objects) > 0 {
+		count = int(objects[len(objects)-1].value)
+		if objects[len(objects)-1].at.After(newest) {
+			newest = objects[len(objects)-1].at
+		}
+		if oldest.IsZero() || objects[0].at.Before(oldest) {
+			oldest = objects[0].at
+		}
+	}
+	activity.SizeDate, activity.Since = newest, oldest
+	activity.LastChange = latestChange(size, objects)
+	for _, requests := range [][]metricPoint{series["gets"], series["puts"]} {
+		if len(requests) > 0 {
+			activity.RequestMetrics = true
+		}
+		for k := len(requests) - 1; k >= 0; k-- {
+			if requests[k].value > 0 {
+				if activity.LastRequest == nil || requests[k].at.After(*activity.LastRequest) {
+					at := requests[k].at
+					activity.LastRequest = &at
+				}
+				break
+			}
+		}
+	}
+	return activity, sizeBytes, count, nil
+}
+
+// requestQuery reads one request metric of a bucket's metrics filter
+func requestQuery(id, metric, bucket, filterID string) cwtypes.MetricDataQuery {
+	return cwtypes.MetricDataQuery{
+		Id: aws.String(id),
+		MetricStat: &cwtypes.MetricStat{
+			Metric: &cwtypes.Metric{
+				Namespace:  aws.String("AWS/S3"),
+				MetricName: aws.String(metric),
+				Dimensions: []cwtypes.Dimension{
+					{Name: aws.String("BucketName"), Value: aws.String(bucket)},
+					{Name: aws.String("FilterId"), Value: aws.String(filterID)},
+				},
+			},
+			Period: aws.Int32(cloudWatchPeriod),
+			Stat:   aws.String("Sum"),
+		},
+	}
+}
+
+// metricPoint is one datapoint of a metric
+type metricPoint struct {
+	at    time.Time
+	value float64
+}
+
+// sortPoints sorts datapoints oldest first
+func sortPoints(points []metricPoint) {
+	sort.Slice(points, func(a, b int) bool { return points[a].at.Before(points[b].at) })
+}
+
+// latestChange returns the latest day either series differs from its
+// previous day
+func latestChange(seriesList ...[]metricPoint) *time.Time {
+	var latest *time.Time
+	for _, points := range seriesList {
+		for k := len(points) - 1; k > 0; k-- {
+			if points[k].value != points[k-1].value {
+				if latest == nil || points[k].at.After(*latest) {
+					at := points[k].at
+					latest = &at
+				}
+				break
+			}
+		}
+	}
+	return latest
+}
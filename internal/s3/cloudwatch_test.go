@@ -0,0 +1,157 @@
+package s3
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatch"
+	cwtypes "github.com/aws/aws-sdk-go-v2/service/cloudwatch/types"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+// fakeCloudWatchAPI returns fixed metric results newest first, as
+// ScanByTimestampDescending does
+type fakeCloudWatchAPI struct {
+	results []cwtypes.MetricDataResult
+	err     error
+	input   *cloudwatch.GetMetricDataInput
+}
+
+func (f *fakeCloudWatchAPI) GetMetricData(_ context.Context, params *cloudwatch.GetMetricDataInput, _ ...func(*cloudwatch.Options)) (*cloudwatch.GetMetricDataOutput, error) {
+	f.input = params
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &cloudwatch.GetMetricDataOutput{MetricDataResults: f.results}, nil
+}
+
+// fakeMetricsConfigAPI returns fixed request metrics configurations
+type fakeMetricsConfigAPI struct {
+	configs []types.MetricsConfiguration
+	err     error
+}
+
+func (f *fakeMetricsConfigAPI) ListBucketMetricsConfigurations(_ context.Context, _ *s3.ListBucketMetricsConfigurationsInput, _ ...func(*s3.Options)) (*s3.ListBucketMetricsConfigurationsOutput, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &s3.ListBucketMetricsConfigurationsOutput{MetricsConfigurationList: f.configs}, nil
+}
+
+// dailySeries builds a metric result from values oldest first, one per day
+// ending at end
+func dailySeries(id string, end time.Time, values ...float64) cwtypes.MetricDataResult {
+	result := cwtypes.MetricDataResult{Id: aws.String(id)}
+	for k := len(values) - 1; k >= 0; k-- {
+		result.Timestamps = append(result.Timestamps, end.AddDate(0, 0, k-len(values)+1))
+		result.Values = append(result.Values, values[k])
+	}
+	return result
+}
+
+func TestReadBucketMetrics(t *testing.T) {
+	now := time.Date(2024, 6, 10, 12, 0, 0, 0, time.UTC)
+	day := time.Date(2024, 6, 10, 0, 0, 0, 0, time.UTC)
+	api := &fakeCloudWatchAPI{results: []cwtypes.MetricDataResult{
+		// Size grew four days ago and has not changed since
+		dailySeries("size", day, 100, 100, 250, 250, 250, 250),
+		dailySeries("objects", day, 3, 3, 5, 5, 5, 5),
+		dailySeries("gets", day, 0, 12, 0, 0, 4, 0),
+		dailySeries("puts", day, 0, 0, 3, 0, 0, 0),
+	}}
+	client := &Client{config: aws.Config{Region: "eu-west-1"}}
+
+	activity, size, objects, err := readBucketMetrics(context.Background(), client, api, "logs", "EntireBucket", now)
+	if err != nil {
+		t.Fatalf("readBucketMetrics failed: %v", err)
+	}
+	if size != 250 || objects != 5 {
+		t.Errorf("size %d, objects %d; want 250, 5", size, objects)
+	}
+	if want := day.AddDate(0, 0, -3); activity.LastChange == nil || !activity.LastChange.Equal(want) {
+		t.Errorf("last change = %v, want %v", activity.LastChange, want)
+	}
+	if want := day.AddDate(0, 0, -1); !activity.RequestMetrics || activity.LastRequest == nil || !activity.LastRequest.Equal(want) {
+		t.Errorf("requests = %+v, want last request %v", activity, want)
+	}
+	if want := day.AddDate(0, 0, -5); !activity.Since.Equal(want) || !activity.SizeDate.Equal(day) {
+		t.Errorf("since %v, size date %v", activity.Since, activity.SizeDate)
+	}
+	if expr := aws.ToString(api.input.MetricDataQueries[0].Expression); !strings.Contains(expr, `BucketName="logs"`) {
+		t.Errorf("size query %q does not name the bucket", expr)
+	}
+	if queries := api.input.MetricDataQueries; len(queries) != 4 || aws.ToString(queries[2].MetricStat.Metric.Dimensions[1].Value) != "EntireBucket" {
+		t.Errorf("request queries do not read filter EntireBucket: %+v", queries)
+	}
+
+	if _, _, _, err := readBucketMetrics(context.Background(), client, api, "logs", "", now); err != nil || len(api.input.MetricDataQueries) != 2 {
+		t.Errorf("without a filter, want only storage queries, got %d (%v)", len(api.input.MetricDataQueries), err)
+	}
+}
+
+func TestRequestMetricsFilter(t *testing.T) {
+	client := &Client{config: aws.Config{Region: "us-east-1"}}
+	prefix := &types.MetricsFilterMemberPrefix{Value: "logs/"}
+	tests := []struct {
+		name    string
+		configs []types.MetricsConfiguration
+		want    string
+	}{
+		{name: "none"},
+		{name: "prefix filter only", configs: []types.MetricsConfiguration{{Id: aws.String("Logs"), Filter: prefix}}},
+		{
+			name:    "first whole-bucket filter",
+			configs: []types.MetricsConfiguration{{Id: aws.String("Logs"), Filter: prefix}, {Id: aws.String("Whole")}, {Id: aws.String("All")}},
+			want:    "All",
+		},
+		{
+			name:    "s3spectre filter preferred",
+			configs: []types.MetricsConfiguration{{Id: aws.String("All")}, {Id: aws.String(MetricsConfigID)}},
+			want:    MetricsConfigID,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := requestMetricsFilter(context.Background(), client, &fakeMetricsConfigAPI{configs: tt.configs}, "logs")
+			if err != nil || got != tt.want {
+				t.Errorf("requestMetricsFilter() = %q, %v; want %q", got, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestCloudWatchActivity(t *testing.T) {
+	day := time.Now().UTC().Truncate(24 * time.Hour)
+	client := &Client{config: aws.Config{Region: "us-east-1"}}
+
+	// Unchanged for 30 days and no request metrics: idle since the
+	// earliest datapoint
+	values := make([]float64, 30)
+	for k := range values {
+		values[k] = 1024
+	}
+	api := &fakeCloudWatchAPI{results: []cwtypes.MetricDataResult{dailySeries("size", day, values...), dailySeries("objects", day, values...)}}
+	inspector := NewInspector(client, 1)
+	inspector.newCloudWatchAPI = func(string) CloudWatchAPI { return api }
+	inspector.newMetricsConfigAPI = func(string) MetricsConfigAPI { return &fakeMetricsConfigAPI{err: errors.New("AccessDenied")} }
+	info := &BucketInfo{Name: "idle"}
+	if !inspector.cloudWatchActivity(context.Background(), client, "idle", info) {
+		t.Fatal("expected activity from CloudWatch")
+	}
+	if info.DaysSinceActivity < 28 || info.TotalSize != 1024 || info.CloudWatch == nil || info.CloudWatch.RequestMetrics {
+		t.Errorf("info = %+v, cloudwatch %+v", info, info.CloudWatch)
+	}
+
+	// No metrics yet, or an error, falls back to listing
+	for _, api := range []*fakeCloudWatchAPI{{}, {err: errors.New("AccessDenied")}} {
+		inspector.newCloudWatchAPI = func(string) CloudWatchAPI { return api }
+		if inspector.cloudWatchActivity(context.Background(), client, "new", &BucketInfo{Name: "new"}) {
+			t.Errorf("expected fallback for %+v", api)
+		}
+	}
+}
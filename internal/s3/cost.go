@@ -0,0 +1,104 @@
+package s3
+
+import "strings"
+
+// S3 Standard request prices in us-east-1, in USD per 1000 requests. Tier 1
+// covers PUT, COPY, POST and LIST requests; tier 2 covers GET and all other
+// requests. Most other regions charge the same or slightly more.
+const (
+	Tier1PricePer1000 = 0.005
+	Tier2PricePer1000 = 0.0004
+)
+
+// CloudWatchPricePer1000Metrics is what GetMetricData charges per 1000
+// metrics requested
+const CloudWatchPricePer1000Metrics = 0.01
+
+// metricsPerCall is the most metrics one of s3spectre's GetMetricData
+// calls requests: size, object count, GET and PUT requests
+const metricsPerCall = 4
+
+// discoverTier2PerBucket counts the configuration reads discover makes per
+// bucket: location, tagging, versioning, lifecycle and encryption
+const discoverTier2PerBucket = 5
+
+// RequestCost estimates what a run's AWS requests cost
+type RequestCost struct {
+	Tier1Requests int `json:"tier1_requests"`
+	Tier2Requests int `json:"tier2_requests"`
+	// FreeRequests go to services that do not bill per request, such as
+	// STS, IAM, GuardDuty and S3 Control
+	FreeRequests int `json:"free_requests"`
+	// CostExplorerRequests are billed at CostExplorerPricePerRequest each
+	CostExplorerRequests int `json:"cost_explorer_requests,omitempty"`
+	// CloudWatchMetrics is the most metrics the GetMetricData calls
+	// requested, billed at CloudWatchPricePer1000Metrics
+	CloudWatchMetrics int `json:"cloudwatch_metrics,omitempty"`
+	// LakeQueries are CloudTrail Lake queries, billed by the data they
+	// scan, which is not known here; they are left out of USD
+	LakeQueries int     `json:"cloudtrail_lake_queries,omitempty"`
+	USD         float64 `json:"usd"`
+}
+
+// Cost estimates the price of the counted requests
+func (s APIStats) Cost() RequestCost {
+	var cost RequestCost
+	for key, n := range s.Calls {
+		service, operation, _ := strings.Cut(key, ".")
+		switch {
+		case service == "Cost Explorer":
+			cost.CostExplorerRequests += n
+		case service == "CloudWatch" && operation == "GetMetricData":
+			cost.CloudWatchMetrics += n * metricsPerCall
+		case service == "CloudTrail" && operation == "StartQuery":
+			cost.LakeQueries += n
+		case service != "S3":
+			cost.FreeRequests += n
+		case tier1Operation(operation):
+			cost.Tier1Requests += n
+		default:
+			cost.Tier2Requests += n
+		}
+	}
+	cost.USD = requestPrice(cost.Tier1Requests, cost.Tier2Requests) +
+		float64(cost.CostExplorerRequests)*CostExplorerPricePerRequest +
+		float64(cost.CloudWatchMetrics)/1000*CloudWatchPricePer1000Metrics
+	return cost
+}
+
+// tier1Operation reports whether S3 bills an operation at the tier 1 rate
+func tier1Operation(operation string) bool {
+	for _, prefix := range []string{"List", "Put", "Copy", "Post", "Create", "UploadPart", "CompleteMultipartUpload"} {
+		if strings.HasPrefix(operation, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+func requestPrice(tier1, tier2 int) float64 {
+	return float64(tier1)/1000*Tier1PricePer1000 + float64(tier2)/1000*Tier2PricePer1000
+}
+
+// DiscoverCostCeiling is the most discover's S3 requests can cost for
+// buckets buckets, when each is versioned, lists its full version page
+// budget and, with deepSize set, holds at least deepSize objects
+func DiscoverCostCeiling(buckets int, versionSampling bool, deepSize int) float64 {
+	// The activity listing plus the version pages, and the prefix listing
+	// that sampling adds
+	activityPages := 1
+	if deepSize > 0 {
+		activityPages = (deepSize + deepListPageSize - 1) / deepListPageSize
+	}
+	tier1 := activityPages + MaxVersionPages
+	if versionSampling {
+		tier1++
+	}
+	return requestPrice(buckets*tier1, buckets*discoverTier2PerBucket)
+}
+
+// SetBeforeInspect registers a callback told how many buckets discovery is
+// about to inspect, after listing and before the first inspection request
+func (i *Inspector) SetBeforeInspect(callback func(buckets int)) {
+	i.beforeInspect = callback
+}
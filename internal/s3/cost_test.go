@@ -0,0 +1,81 @@
+package s3
+
+import (
+	"context"
+	"math"
+	"testing"
+)
+
+func TestAPIStats_Cost(t *testing.T) {
+	stats := APIStats{Calls: map[string]int{
+		"S3.ListObjectsV2":               1500,
+		"S3.ListBuckets":                 500,
+		"S3.GetBucketTagging":            2000,
+		"S3.HeadBucket":                  500,
+		"S3.PutBucketTagging":            0,
+		"STS.GetCallerIdentity":          1,
+		"S3 Control.ListRegionalBuckets": 3,
+	}}
+	cost := stats.Cost()
+	if cost.Tier1Requests != 2000 || cost.Tier2Requests != 2500 || cost.FreeRequests != 4 {
+		t.Errorf("cost = %+v", cost)
+	}
+	// 2000 tier 1 requests at $0.005 and 2500 tier 2 requests at $0.0004
+	if want := 0.01 + 0.001; math.Abs(cost.USD-want) > 1e-12 {
+		t.Errorf("USD = %v, want %v", cost.USD, want)
+	}
+}
+
+func TestAPIStats_CostExplorer(t *testing.T) {
+	cost := APIStats{Calls: map[string]int{"Cost Explorer.GetCostAndUsage": 2}}.Cost()
+	if cost.CostExplorerRequests != 2 || cost.FreeRequests != 0 {
+		t.Errorf("cost = %+v", cost)
+	}
+	if want := 2 * CostExplorerPricePerRequest; math.Abs(cost.USD-want) > 1e-12 {
+		t.Errorf("USD = %v, want %v", cost.USD, want)
+	}
+}
+
+func TestAPIStats_CostOtherServices(t *testing.T) {
+	cost := APIStats{Calls: map[string]int{
+		"CloudWatch.GetMetricData":         250,
+		"CloudTrail.StartQuery":            1,
+		"CloudTrail.GetQueryResults":       3,
+		"CloudTrail.DescribeTrails":        2,
+		"Macie2.ListFindings":              1,
+		"S3.GetBucketMetricsConfiguration": 0,
+	}}.Cost()
+	if cost.CloudWatchMetrics != 1000 || cost.LakeQueries != 1 || cost.FreeRequests != 6 {
+		t.Errorf("cost = %+v", cost)
+	}
+	// 1000 metrics at $0.01; the Lake query is not priced
+	if want := CloudWatchPricePer1000Metrics; math.Abs(cost.USD-want) > 1e-12 {
+		t.Errorf("USD = %v, want %v", cost.USD, want)
+	}
+}
+
+func TestDiscoverCostCeiling(t *testing.T) {
+	// Per bucket: 101 listings and 5 configuration reads
+	want := 1000 * (101*Tier1PricePer1000 + 5*Tier2PricePer1000) / 1000
+	if got := DiscoverCostCeiling(1000, false, 0); math.Abs(got-want) > 1e-12 {
+		t.Errorf("DiscoverCostCeiling = %v, want %v", got, want)
+	}
+	if DiscoverCostCeiling(1000, true, 0) <= DiscoverCostCeiling(1000, false, 0) {
+		t.Error("sampling should add a prefix listing per bucket")
+	}
+	// A deep listing of 5000 objects takes 5 pages instead of 1
+	deep := 1000 * (105*Tier1PricePer1000 + 5*Tier2PricePer1000) / 1000
+	if got := DiscoverCostCeiling(1000, false, 5000); math.Abs(got-deep) > 1e-12 {
+		t.Errorf("DiscoverCostCeiling with deep size = %v, want %v", got, deep)
+	}
+}
+
+func TestInspector_BeforeInspect(t *testing.T) {
+	inspector := NewInspector(&Client{}, 1)
+	got := -1
+	inspector.SetBeforeInspect(func(buckets int) { got = buckets })
+	inspector.inspectDiscovered(context.Background(), map[string]string{}, nil)
+	if got != 0 {
+		t.Errorf("callback got %d buckets, want 0", got)
+	}
+}
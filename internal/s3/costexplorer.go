@@ -0,0 +1,149 @@
+package s3
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/costexplorer"
+	cetypes "github.com/aws/aws-sdk-go-v2/service/costexplorer/types"
+)
+
+// S3ServiceName is the SERVICE dimension value Cost Explorer bills S3 under
+const S3ServiceName = "Amazon Simple Storage Service"
+
+// costExplorerRegion is where the Cost Explorer endpoint lives
+const costExplorerRegion = "us-east-1"
+
+// costMetric is the Cost Explorer metric spend is read from
+const costMetric = "UnblendedCost"
+
+// CostExplorerPricePerRequest is what Cost Explorer charges per API request,
+// in USD
+const CostExplorerPricePerRequest = 0.01
+
+// CostExplorerAPI is the subset of the Cost Explorer client used to read spend
+type CostExplorerAPI interface {
+	GetCostAndUsage(ctx context.Context, params *costexplorer.GetCostAndUsageInput, optFns ...func(*costexplorer.Options)) (*costexplorer.GetCostAndUsageOutput, error)
+}
+
+// TagSpend is month-to-date S3 spend grouped by account and by the value of
+// a cost allocation tag
+type TagSpend struct {
+	TagKey string `json:"tag_key"`
+	// Start and End bound the period, End exclusive, as YYYY-MM-DD
+	Start    string  `json:"start"`
+	End      string  `json:"end"`
+	Currency string  `json:"currency"`
+	Total    float64 `json:"total"`
+	// Estimated is set while Cost Explorer still revises the period
+	Estimated bool `json:"estimated,omitempty"`
+	// Spend maps an account ID to its spend per tag value. Spend without
+	// the tag is under the empty value.
+	Spend map[string]map[string]float64 `json:"spend"`
+}
+
+// Value returns the spend of an account's resources carrying a tag value
+func (s *TagSpend) Value(account, value string) (float64, bool) {
+	amount, ok := s.Spend[account][value]
+	return amount, ok
+}
+
+// costExplorerClient returns the Cost Explorer client
+func (i *Inspector) costExplorerClient() CostExplorerAPI {
+	if i.newCostExplorerAPI != nil {
+		return i.newCostExplorerAPI()
+	}
+	cfg := i.client.config.Copy()
+	cfg.Region = costExplorerRegion
+	return costexplorer.NewFromConfig(cfg)
+}
+
+// MonthToDateS3Spend reads S3 spend since the start of the month from Cost
+// Explorer, grouped by linked account and by the value of tagKey. The tag
+// must be activated as a cost allocation tag; spend from before activation
+// is untagged. Each page costs CostExplorerPricePerRequest.
+func (i *Inspector) MonthToDateS3Spend(ctx context.Context, tagKey string, now time.Time) (*TagSpend, error) {
+	api := i.costExplorerClient()
+	now = now.UTC()
+	start := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	// End is exclusive, so tomorrow includes today's partial spend
+	end := time.Date(now.Year(), now.Month(), now.Day()+1, 0, 0, 0, 0, time.UTC)
+	spend := &TagSpend{
+		TagKey: tagKey,
+		Start:  start.Format(time.DateOnly),
+		End:    end.Format(time.DateOnly),
+		Spend:  make(map[string]map[string]float64),
+	}
+
+	input := &costexplorer.GetCostAndUsageInput{
+		Granularity: cetypes.GranularityMonthly,
+		Metrics:     []string{costMetric},
+		TimePeriod:  &cetypes.DateInterval{Start: aws.String(spend.Start), End: aws.String(spend.End)},
+		Filter: &cetypes.Expression{Dimensions: &cetypes.DimensionValues{
+			Key:    cetypes.DimensionService,
+			Values: []string{S3ServiceName},
+		}},
+		GroupBy: []cetypes.GroupDefinition{
+			{Type: cetypes.GroupDefinitionTypeDimension, Key: aws.String(string(cetypes.DimensionLinkedAccount))},
+			{Type: cetypes.GroupDefinitionTypeTag, Key: aws.String(tagKey)},
+		},
+	}
+	for {
+		var out *costexplorer.GetCostAndUsageOutput
+		err := i.client.WithRetry(ctx, func() error {
+			var err error
+			out, err = api.GetCostAndUsage(ctx, input)
+			return err
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to read S3 spend from Cost Explorer: %w", err)
+		}
+		for _, result := range out.ResultsByTime {
+			spend.Estimated = spend.Estimated || result.Estimated
+			for _, group := range result.Groups {
+				if len(group.Keys) != 2 {
+					continue
+				}
+				amount, currency, err := metricAmount(group.Metrics[costMetric])
+				if err != nil {
+					return nil, err
+				}
+				if spend.Currency == "" {
+					spend.Currency = currency
+				}
+				account := group.Keys[0]
+				// Tag group keys read "key$value"; untagged spend has no value
+				_, value, _ := strings.Cut(group.Keys[1], "$")
+				if spend.Spend[account] == nil {
+					spend.Spend[account] = make(map[string]float64)
+				}
+				spend.Spend[account][value] += amount
+				spend.Total += amount
+			}
+		}
+		if out.NextPageToken == nil || *out.NextPageToken == "" {
+			break
+		}
+		input.NextPageToken = out.NextPageToken
+	}
+	if spend.Currency == "" {
+		spend.Currency = "USD"
+	}
+	return spend, nil
+}
+
+// metricAmount parses a Cost Explorer metric value
+func metricAmount(metric cetypes.MetricValue) (float64, string, error) {
+	if metric.Amount == nil {
+		return 0, aws.ToString(metric.Unit), nil
+	}
+	amount, err := strconv.ParseFloat(*metric.Amount, 64)
+	if err != nil {
+		return 0, "", fmt.Errorf("invalid Cost Explorer amount %q: %w", *metric.Amount, err)
+	}
+	return amount, aws.ToString(metric.Unit), nil
+}
@@ -0,0 +1,91 @@
+package s3
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/costexplorer"
+	cetypes "github.com/aws/aws-sdk-go-v2/service/costexplorer/types"
+)
+
+// fakeCostExplorerAPI serves pages of grouped results and records requests
+type fakeCostExplorerAPI struct {
+	pages  []*costexplorer.GetCostAndUsageOutput
+	inputs []costexplorer.GetCostAndUsageInput
+}
+
+func (f *fakeCostExplorerAPI) GetCostAndUsage(_ context.Context, params *costexplorer.GetCostAndUsageInput, _ ...func(*costexplorer.Options)) (*costexplorer.GetCostAndUsageOutput, error) {
+	f.inputs = append(f.inputs, *params)
+	return f.pages[len(f.inputs)-1], nil
+}
+
+func costGroup(account, tag, amount string) cetypes.Group {
+	return cetypes.Group{
+		Keys:    []string{account, tag},
+		Metrics: map[string]cetypes.MetricValue{costMetric: {Amount: aws.String(amount), Unit: aws.String("USD")}},
+	}
+}
+
+func TestMonthToDateS3Spend(t *testing.T) {
+	api := &fakeCostExplorerAPI{pages: []*costexplorer.GetCostAndUsageOutput{
+		{
+			ResultsByTime: []cetypes.ResultByTime{{Estimated: true, Groups: []cetypes.Group{
+				costGroup("111111111111", "Name$logs", "12.5"),
+				costGroup("111111111111", "Name$", "3.25"),
+			}}},
+			NextPageToken: aws.String("page-2"),
+		},
+		{
+			ResultsByTime: []cetypes.ResultByTime{{Groups: []cetypes.Group{
+				costGroup("222222222222", "Name$data", "1.75"),
+			}}},
+		},
+	}}
+	inspector := NewInspector(&Client{config: aws.Config{Region: "eu-west-1"}}, 1)
+	inspector.newCostExplorerAPI = func() CostExplorerAPI { return api }
+
+	now := time.Date(2024, 5, 31, 23, 0, 0, 0, time.UTC)
+	spend, err := inspector.MonthToDateS3Spend(context.Background(), "Name", now)
+	if err != nil {
+		t.Fatalf("MonthToDateS3Spend failed: %v", err)
+	}
+	if spend.Start != "2024-05-01" || spend.End != "2024-06-01" {
+		t.Errorf("period = %s to %s, want 2024-05-01 to 2024-06-01", spend.Start, spend.End)
+	}
+	if spend.Total != 17.5 || spend.Currency != "USD" || !spend.Estimated {
+		t.Errorf("spend = %+v", spend)
+	}
+	if amount, ok := spend.Value("111111111111", "logs"); !ok || amount != 12.5 {
+		t.Errorf("logs spend = %v, %v", amount, ok)
+	}
+	if amount, _ := spend.Value("111111111111", ""); amount != 3.25 {
+		t.Errorf("untagged spend = %v, want 3.25", amount)
+	}
+	if amount, _ := spend.Value("222222222222", "data"); amount != 1.75 {
+		t.Errorf("second page spend = %v, want 1.75", amount)
+	}
+
+	if len(api.inputs) != 2 || aws.ToString(api.inputs[1].NextPageToken) != "page-2" {
+		t.Fatalf("expected the second page to be requested with its token, got %d requests", len(api.inputs))
+	}
+	filter := api.inputs[0].Filter.Dimensions
+	if filter.Key != cetypes.DimensionService || len(filter.Values) != 1 || filter.Values[0] != S3ServiceName {
+		t.Errorf("filter = %+v", filter)
+	}
+	if group := api.inputs[0].GroupBy[1]; group.Type != cetypes.GroupDefinitionTypeTag || aws.ToString(group.Key) != "Name" {
+		t.Errorf("tag grouping = %+v", group)
+	}
+}
+
+func TestMonthToDateS3Spend_InvalidAmount(t *testing.T) {
+	api := &fakeCostExplorerAPI{pages: []*costexplorer.GetCostAndUsageOutput{{
+		ResultsByTime: []cetypes.ResultByTime{{Groups: []cetypes.Group{costGroup("111111111111", "Name$logs", "n/a")}}},
+	}}}
+	inspector := NewInspector(&Client{config: aws.Config{Region: "us-east-1"}}, 1)
+	inspector.newCostExplorerAPI = func() CostExplorerAPI { return api }
+	if _, err := inspector.MonthToDateS3Spend(context.Background(), "Name", time.Now()); err == nil {
+		t.Fatal("expected an error for an unparsable amount")
+	}
+}
@@ -0,0 +1,77 @@
+package s3
+
+import (
+	"context"
+	"slices"
+	"time"
+)
+
+// Bucket checks. A bucket past its check deadline records the checks it did
+// not finish in BucketInfo.SkippedChecks.
+const (
+	CheckLocation    = "location"
+	CheckTagging     = "tagging"
+	CheckVersioning  = "versioning"
+	CheckLifecycle   = "lifecycle"
+	CheckEncryption  = "encryption"
+	CheckObjectLock  = "object_lock"
+	CheckReplication = "replication"
+	CheckACL         = "acl"
+	CheckPolicy      = "policy"
+	CheckLogging     = "logging"
+	CheckActivity    = "activity"
+	CheckVersions    = "versions"
+	CheckPrefixes    = "prefixes"
+)
+
+// SetBucketDeadline bounds the checks of each bucket. Unlike the bucket
+// timeout, a bucket past its deadline keeps the checks that finished and
+// lists the rest in SkippedChecks. Zero disables the deadline.
+func (i *Inspector) SetBucketDeadline(d time.Duration) {
+	i.bucketDeadline = d
+}
+
+// bucketChecks runs one bucket's checks until its deadline
+type bucketChecks struct {
+	// ctx is the bucket's context; its cancellation is an interruption or
+	// timeout rather than a skipped check
+	ctx      context.Context
+	checkCtx context.Context
+	info     *BucketInfo
+}
+
+// newBucketChecks starts the deadline of the checks recorded in info
+func (i *Inspector) newBucketChecks(ctx context.Context, info *BucketInfo) (*bucketChecks, context.CancelFunc) {
+	checkCtx, cancel := context.WithCancel(ctx)
+	if i.bucketDeadline > 0 {
+		checkCtx, cancel = context.WithTimeout(ctx, i.bucketDeadline)
+	}
+	return &bucketChecks{ctx: ctx, checkCtx: checkCtx, info: info}, cancel
+}
+
+// run runs the check unless the deadline has passed, and reports whether it
+// finished. A check that is not run, or that the deadline cuts short, is
+// recorded as skipped; check must not keep partial results when its
+// context ends.
+func (c *bucketChecks) run(name string, check func(ctx context.Context)) bool {
+	if !c.expired() {
+		check(c.checkCtx)
+		if !c.expired() {
+			return true
+		}
+	}
+	if c.ctx.Err() == nil {
+		c.info.SkippedChecks = append(c.info.SkippedChecks, name)
+	}
+	return false
+}
+
+// expired reports whether the checks' context has ended
+func (c *bucketChecks) expired() bool {
+	return c.checkCtx.Err() != nil
+}
+
+// Skipped reports whether check was skipped at the bucket's deadline
+func (b *BucketInfo) Skipped(check string) bool {
+	return slices.Contains(b.SkippedChecks, check)
+}
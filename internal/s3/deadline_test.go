@@ -0,0 +1,73 @@
+package s3
+
+import (
+	"context"
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestInspectDiscovered_BucketDeadline(t *testing.T) {
+	// Configuration calls answer at once; the encryption call hangs past the
+	// deadline
+	rt := roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		query := req.URL.Query()
+		switch {
+		case query.Has("tagging"):
+			return xmlResponse(`<Tagging><TagSet><Tag><Key>team</Key><Value>data</Value></Tag></TagSet></Tagging>`), nil
+		case query.Has("versioning"):
+			return xmlResponse(`<VersioningConfiguration><Status>Enabled</Status></VersioningConfiguration>`), nil
+		case query.Has("lifecycle"):
+			return xmlResponse(`<LifecycleConfiguration><Rule><ID>expire</ID><Status>Enabled</Status><Filter><Prefix></Prefix></Filter><Expiration><Days>30</Days></Expiration></Rule></LifecycleConfiguration>`), nil
+		}
+		<-req.Context().Done()
+		return nil, req.Context().Err()
+	})
+	cp := NewCheckpoint("discover")
+	inspector := NewInspector(newTestClient(t, rt), 2)
+	inspector.SetCheckpoint(cp)
+	inspector.SetBucketDeadline(50 * time.Millisecond)
+
+	buckets := inspector.inspectDiscovered(context.Background(), map[string]string{"slow": "us-east-1"}, nil)
+
+	info := buckets["slow"]
+	if info == nil || info.TimedOut || info.Interrupted {
+		t.Fatalf("a deadline should not time out the whole bucket, got %+v", info)
+	}
+	if info.Tags["team"] != "data" || !info.VersioningEnabled || info.LifecycleRules != 1 {
+		t.Errorf("finished checks should be kept, got %+v", info)
+	}
+	want := []string{CheckEncryption, CheckObjectLock, CheckReplication, CheckActivity, CheckVersions}
+	if !reflect.DeepEqual(info.SkippedChecks, want) {
+		t.Errorf("SkippedChecks = %v, want %v", info.SkippedChecks, want)
+	}
+	if !info.Skipped(CheckActivity) || info.Skipped(CheckLifecycle) {
+		t.Errorf("Skipped disagrees with SkippedChecks %v", info.SkippedChecks)
+	}
+	if info.Encryption != nil {
+		t.Errorf("a cut-short check should leave no result, got %+v", info.Encryption)
+	}
+	if cp.Len() != 0 {
+		t.Error("a bucket with skipped checks should stay out of the checkpoint")
+	}
+}
+
+func TestInspectBucket_DeadlineBeforeLocation(t *testing.T) {
+	rt := roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		<-req.Context().Done()
+		return nil, req.Context().Err()
+	})
+	inspector := NewInspector(newTestClient(t, rt), 2)
+	inspector.SetBucketDeadline(20 * time.Millisecond)
+
+	info := inspector.inspectBucket(context.Background(), "unlisted", "", nil)
+	if info.Exists || !reflect.DeepEqual(info.SkippedChecks, []string{CheckLocation}) || info.Error != "" {
+		t.Errorf("an unlisted bucket without its location is unknown, got %+v", info)
+	}
+
+	info = inspector.inspectBucket(context.Background(), "listed", "us-east-1", nil)
+	if !info.Exists || info.Region != "us-east-1" || len(info.SkippedChecks) != 6 {
+		t.Errorf("a listed bucket should exist with every check skipped, got %+v", info)
+	}
+}
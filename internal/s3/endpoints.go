@@ -0,0 +1,163 @@
+package s3
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"slices"
+	"sort"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/aws/smithy-go"
+)
+
+// defaultEndpointRegion signs requests to an S3-compatible store when no
+// region is configured; MinIO, Ceph and R2 all accept it
+const defaultEndpointRegion = "us-east-1"
+
+// ValidateEndpoints checks that every endpoint override is an absolute
+// http(s) URL
+func ValidateEndpoints(endpoints map[string]string) error {
+	for region, endpoint := range endpoints {
+		if region == "" {
+			return fmt.Errorf("endpoint %q has an empty region", endpoint)
+		}
+		u, err := url.Parse(endpoint)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("endpoint for region %s must be an http(s) URL, got %q", region, endpoint)
+		}
+	}
+	return nil
+}
+
+// ValidateEndpointURL checks that an --endpoint-url, if set, is an absolute
+// http(s) URL
+func ValidateEndpointURL(endpoint string) error {
+	if endpoint == "" {
+		return nil
+	}
+	u, err := url.Parse(endpoint)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("--endpoint-url must be an http(s) URL, got %q", endpoint)
+	}
+	return nil
+}
+
+// WithEndpointURL sends S3 calls in every region to an S3-compatible store
+// such as MinIO, Ceph or Cloudflare R2. Per-region endpoint overrides still
+// take precedence. Other AWS services are not redirected.
+func WithEndpointURL(endpoint string) ClientOption {
+	return func(o *clientOptions) {
+		o.endpointURL = endpoint
+	}
+}
+
+// WithPathStyle addresses buckets in the URL path rather than the host
+// name, for stores without wildcard DNS
+func WithPathStyle(enabled bool) ClientOption {
+	return func(o *clientOptions) {
+		o.pathStyle = enabled
+	}
+}
+
+// WithEndpoints sends S3 calls for the given regions to custom endpoints
+// (Outposts, Local Zones, S3-compatible gateways). Overridden endpoints use
+// path-style addressing, which does not need wildcard DNS.
+func WithEndpoints(endpoints map[string]string) ClientOption {
+	return func(o *clientOptions) {
+		o.endpoints = endpoints
+	}
+}
+
+// newS3Client creates an S3 client for cfg's region, pointing it at the
+// region's endpoint override if there is one, or else at the endpoint URL
+func (c *Client) newS3Client(cfg aws.Config) *s3.Client {
+	if endpoint, ok := c.endpoints[cfg.Region]; ok {
+		return s3.NewFromConfig(cfg, func(o *s3.Options) {
+			o.BaseEndpoint = aws.String(endpoint)
+			o.UsePathStyle = true
+		})
+	}
+	return s3.NewFromConfig(cfg, func(o *s3.Options) {
+		if c.endpointURL != "" {
+			o.BaseEndpoint = aws.String(c.endpointURL)
+		}
+		o.UsePathStyle = c.pathStyle
+	})
+}
+
+// CustomEndpoint reports whether S3 calls go to an S3-compatible store
+// instead of AWS
+func (c *Client) CustomEndpoint() bool {
+	return c.endpointURL != ""
+}
+
+// unsupportedOperation reports whether an S3-compatible store rejected a
+// call because it does not implement it
+func unsupportedOperation(err error) bool {
+	var apiErr smithy.APIError
+	if !errors.As(err, &apiErr) {
+		return false
+	}
+	switch apiErr.ErrorCode() {
+	case "NotImplemented", "MethodNotAllowed", "XNotImplemented":
+		return true
+	}
+	return false
+}
+
+// endpointRegions returns the overridden regions in sorted order
+func (c *Client) endpointRegions() []string {
+	regions := make([]string, 0, len(c.endpoints))
+	for region := range c.endpoints {
+		regions = append(regions, region)
+	}
+	sort.Strings(regions)
+	return regions
+}
+
+// withEndpointRegions adds overridden regions missing from regions, so
+// custom endpoints are inspected alongside standard regions
+func (c *Client) withEndpointRegions(regions []string) []string {
+	for _, region := range c.endpointRegions() {
+		if !slices.Contains(regions, region) {
+			regions = append(regions, region)
+		}
+	}
+	return regions
+}
+
+// listEndpointBuckets lists buckets served only by endpoint overrides in the
+// scanned regions. Buckets AWS already returned are skipped, so overriding a
+// standard region (e.g. with a VPC endpoint) does not relabel its buckets.
+func (i *Inspector) listEndpointBuckets(ctx context.Context, regions []string, known map[string]bool) ([]types.Bucket, map[string]string, error) {
+	var buckets []types.Bucket
+	bucketRegions := make(map[string]string)
+	for _, region := range i.client.endpointRegions() {
+		if !slices.Contains(regions, region) {
+			continue
+		}
+		client := i.client.ForRegion(region)
+		var result *s3.ListBucketsOutput
+		err := client.WithRetry(ctx, func() error {
+			var err error
+			result, err = client.s3Client.ListBuckets(ctx, &s3.ListBucketsInput{})
+			return err
+		})
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to list buckets at %s endpoint %s: %w", region, i.client.endpoints[region], err)
+		}
+		for _, bucket := range result.Buckets {
+			name := aws.ToString(bucket.Name)
+			if name == "" || known[name] || bucketRegions[name] != "" {
+				continue
+			}
+			bucketRegions[name] = region
+			buckets = append(buckets, bucket)
+		}
+	}
+	return buckets, bucketRegions, nil
+}
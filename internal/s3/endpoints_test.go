@@ -0,0 +1,154 @@
+package s3
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestValidateEndpoints(t *testing.T) {
+	valid := map[string]string{"us-east-1": "https://s3.us-east-1.amazonaws.com", "lab": "http://minio.local:9000"}
+	if err := ValidateEndpoints(valid); err != nil {
+		t.Fatalf("expected valid endpoints, got %v", err)
+	}
+	for _, endpoint := range []string{"minio.local:9000", "ftp://gateway", "https://"} {
+		if err := ValidateEndpoints(map[string]string{"lab": endpoint}); err == nil {
+			t.Fatalf("expected error for endpoint %q", endpoint)
+		}
+	}
+}
+
+func listBucketsXML(names ...string) string {
+	var b strings.Builder
+	b.WriteString(`<?xml version="1.0" encoding="UTF-8"?><ListAllMyBucketsResult><Buckets>`)
+	for _, name := range names {
+		fmt.Fprintf(&b, "<Bucket><Name>%s</Name><CreationDate>2024-01-01T00:00:00.000Z</CreationDate></Bucket>", name)
+	}
+	b.WriteString(`</Buckets></ListAllMyBucketsResult>`)
+	return b.String()
+}
+
+func TestListAllBucketsWithMetadata_EndpointOverrides(t *testing.T) {
+	var locationLookups []string
+	client := newTestClient(t, roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		if req.URL.Host == "outpost.example.com" {
+			// The custom endpoint also serves an AWS bucket; it must not be relabeled
+			return xmlResponse(listBucketsXML("shared", "edge-data")), nil
+		}
+		if req.URL.Query().Has("location") {
+			locationLookups = append(locationLookups, req.URL.Path)
+			return xmlResponse(`<?xml version="1.0" encoding="UTF-8"?><LocationConstraint>eu-west-1</LocationConstraint>`), nil
+		}
+		return xmlResponse(listBucketsXML("shared")), nil
+	}))
+	client.endpoints = map[string]string{"on-prem": "https://outpost.example.com"}
+	inspector := NewInspector(client, 1)
+
+	_, regions, metadata, err := inspector.listAllBucketsWithMetadata(context.Background(), client.withEndpointRegions([]string{"us-east-1"}))
+	if err != nil {
+		t.Fatalf("listAllBucketsWithMetadata failed: %v", err)
+	}
+	if regions["shared"] != "eu-west-1" {
+		t.Fatalf("expected AWS bucket region from GetBucketLocation, got %q", regions["shared"])
+	}
+	if regions["edge-data"] != "on-prem" {
+		t.Fatalf("expected endpoint bucket in region on-prem, got %q", regions["edge-data"])
+	}
+	if metadata["edge-data"] == nil || metadata["edge-data"].CreationDate == nil {
+		t.Fatalf("expected creation date for endpoint bucket")
+	}
+	if len(locationLookups) != 1 {
+		t.Fatalf("expected only the AWS bucket to be located, got %v", locationLookups)
+	}
+}
+
+func TestListEndpointBuckets_SkipsRegionsOutOfScope(t *testing.T) {
+	client := newTestClient(t, roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		t.Fatalf("unexpected request to %s", req.URL)
+		return nil, nil
+	}))
+	client.endpoints = map[string]string{"on-prem": "https://outpost.example.com"}
+	buckets, _, err := NewInspector(client, 1).listEndpointBuckets(context.Background(), []string{"us-east-1"}, nil)
+	if err != nil || len(buckets) != 0 {
+		t.Fatalf("expected no endpoint buckets outside the scanned regions, got %v (err=%v)", buckets, err)
+	}
+}
+
+func TestValidateEndpointURL(t *testing.T) {
+	for _, endpoint := range []string{"", "http://minio.local:9000", "https://acct.r2.cloudflarestorage.com"} {
+		if err := ValidateEndpointURL(endpoint); err != nil {
+			t.Errorf("ValidateEndpointURL(%q) = %v", endpoint, err)
+		}
+	}
+	for _, endpoint := range []string{"minio.local:9000", "s3://minio", "https://"} {
+		if err := ValidateEndpointURL(endpoint); err == nil {
+			t.Errorf("expected error for endpoint %q", endpoint)
+		}
+	}
+}
+
+func TestClient_EndpointURL(t *testing.T) {
+	var requests []string
+	base := newTestClient(t, roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		requests = append(requests, req.URL.Host+req.URL.Path)
+		switch req.URL.Path {
+		case "/legacy":
+			return &http.Response{
+				StatusCode: http.StatusNotImplemented,
+				Header:     http.Header{"Content-Type": []string{"application/xml"}},
+				Body: io.NopCloser(strings.NewReader(`<?xml version="1.0" encoding="UTF-8"?>
+<Error><Code>NotImplemented</Code><Message>A header you provided implies functionality that is not implemented</Message></Error>`)),
+			}, nil
+		case "/missing":
+			return &http.Response{
+				StatusCode: http.StatusNotFound,
+				Header:     http.Header{"Content-Type": []string{"application/xml"}},
+				Body:       io.NopCloser(strings.NewReader(`<?xml version="1.0" encoding="UTF-8"?><Error><Code>NoSuchBucket</Code></Error>`)),
+			}, nil
+		case "/zoned":
+			return xmlResponse(`<?xml version="1.0" encoding="UTF-8"?><LocationConstraint>eu-central</LocationConstraint>`), nil
+		}
+		return xmlResponse(`<?xml version="1.0" encoding="UTF-8"?><LocationConstraint></LocationConstraint>`), nil
+	}))
+	base.endpointURL = "http://minio.local:9000"
+	base.pathStyle = true
+	cfg := base.config.Copy()
+	cfg.Region = "lab"
+	client := base.withConfig(cfg)
+
+	if !client.CustomEndpoint() {
+		t.Fatal("expected a custom endpoint client")
+	}
+	regions, err := client.ListRegions(context.Background())
+	if err != nil || len(regions) != 1 || regions[0] != "lab" {
+		t.Fatalf("ListRegions() = %v, %v; want the signing region alone", regions, err)
+	}
+	for bucket, want := range map[string]string{"plain": "lab", "legacy": "lab", "zoned": "eu-central"} {
+		region, err := client.BucketRegion(context.Background(), bucket)
+		if err != nil || region != want {
+			t.Errorf("BucketRegion(%s) = %q, %v; want %q", bucket, region, err, want)
+		}
+	}
+	if _, err := client.BucketRegion(context.Background(), "missing"); err == nil {
+		t.Error("a missing bucket must still fail")
+	}
+	for _, request := range requests {
+		if !strings.HasPrefix(request, "minio.local:9000/") {
+			t.Errorf("request %s did not go to the endpoint with path-style addressing", request)
+		}
+	}
+
+	// Per-region overrides take precedence over the endpoint URL
+	base.endpoints = map[string]string{"on-prem": "https://gateway.example.com"}
+	cfg.Region = "on-prem"
+	requests = nil
+	if _, err := base.withConfig(cfg).BucketRegion(context.Background(), "plain"); err != nil {
+		t.Fatal(err)
+	}
+	if len(requests) != 1 || requests[0] != "gateway.example.com/plain" {
+		t.Errorf("requests = %v, want the region's override", requests)
+	}
+}
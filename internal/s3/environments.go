@@ -0,0 +1,71 @@
+package s3
+
+import "strings"
+
+// DefaultEnvironments are the environment names recognized as bucket name
+// segments when none are configured
+var DefaultEnvironments = []string{
+	"dev", "development", "test", "qa", "uat", "sandbox",
+	"stage", "staging", "stg", "preprod",
+	"prod", "production", "prd",
+}
+
+// Environments recognizes environment segments in bucket names, such as the
+// "prod" in orders-prod or prod.orders.exports
+type Environments map[string]bool
+
+// NewEnvironments returns the environment names to recognize, or
+// DefaultEnvironments when names is empty. Matching ignores case.
+func NewEnvironments(names []string) Environments {
+	if len(names) == 0 {
+		names = DefaultEnvironments
+	}
+	envs := make(Environments, len(names))
+	for _, name := range names {
+		if name = strings.ToLower(strings.TrimSpace(name)); name != "" {
+			envs[name] = true
+		}
+	}
+	return envs
+}
+
+// Family returns the bucket's environment family, its name with the first
+// environment segment replaced by "*", and that environment. ok is false when
+// no segment names an environment, or the name is nothing but one.
+func (e Environments) Family(bucket string) (family, env string, ok bool) {
+	lower := strings.ToLower(bucket)
+	start := 0
+	for start <= len(lower) {
+		end := strings.IndexFunc(lower[start:], isNameSeparator)
+		if end < 0 {
+			end = len(lower)
+		} else {
+			end += start
+		}
+		if segment := lower[start:end]; e[segment] && len(segment) < len(lower) {
+			return lower[:start] + "*" + lower[end:], segment, true
+		}
+		if end == len(lower) {
+			break
+		}
+		start = end + 1
+	}
+	return "", "", false
+}
+
+// stem drops environment segments from a bucket name, splitting on the
+// separators bucket names allow, and reports whether it dropped any
+func (e Environments) stem(name string) (string, bool) {
+	segments := strings.FieldsFunc(name, isNameSeparator)
+	kept := segments[:0]
+	for _, segment := range segments {
+		if !e[segment] {
+			kept = append(kept, segment)
+		}
+	}
+	return strings.Join(kept, "-"), len(kept) < len(segments)
+}
+
+func isNameSeparator(r rune) bool {
+	return r == '-' || r == '.' || r == '_'
+}
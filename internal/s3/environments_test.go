@@ -0,0 +1,39 @@
+package s3
+
+import "testing"
+
+func TestEnvironments_Family(t *testing.T) {
+	envs := NewEnvironments(nil)
+	tests := []struct {
+		bucket     string
+		wantFamily string
+		wantEnv    string
+	}{
+		{bucket: "orders-prod", wantFamily: "orders-*", wantEnv: "prod"},
+		{bucket: "Orders-Staging", wantFamily: "orders-*", wantEnv: "staging"},
+		{bucket: "dev.acme.exports", wantFamily: "*.acme.exports", wantEnv: "dev"},
+		{bucket: "acme_qa_logs", wantFamily: "acme_*_logs", wantEnv: "qa"},
+		{bucket: "product-images", wantFamily: ""},
+		{bucket: "prod", wantFamily: ""},
+	}
+	for _, tt := range tests {
+		family, env, ok := envs.Family(tt.bucket)
+		if family != tt.wantFamily || env != tt.wantEnv || ok != (tt.wantFamily != "") {
+			t.Errorf("Family(%q) = %q, %q, %v; want %q, %q", tt.bucket, family, env, ok, tt.wantFamily, tt.wantEnv)
+		}
+	}
+}
+
+func TestNewEnvironments_Custom(t *testing.T) {
+	envs := NewEnvironments([]string{"Blue", " green "})
+	if _, env, ok := envs.Family("web-green"); !ok || env != "green" {
+		t.Errorf("configured environments should be recognized, got %q, %v", env, ok)
+	}
+	if _, _, ok := envs.Family("web-prod"); ok {
+		t.Error("configured environments should replace the defaults")
+	}
+	got := ClosestBuckets("web-blue", []string{"web-green", "web-prod"}, envs)
+	if len(got) != 1 || got[0] != "web-green" {
+		t.Errorf("ClosestBuckets with custom environments = %v, want [web-green]", got)
+	}
+}
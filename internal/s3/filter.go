@@ -0,0 +1,61 @@
+package s3
+
+import (
+	"fmt"
+	"path"
+)
+
+// BucketFilter selects buckets by name using shell-style glob patterns.
+// A bucket is kept if it matches any include pattern (or no include patterns
+// are set) and matches no exclude pattern.
+type BucketFilter struct {
+	Include []string
+	Exclude []string
+}
+
+// NewBucketFilter validates the patterns and returns a filter.
+// Returns nil when no patterns are given so callers can skip filtering.
+func NewBucketFilter(include, exclude []string) (*BucketFilter, error) {
+	if len(include) == 0 && len(exclude) == 0 {
+		return nil, nil
+	}
+	for _, pattern := range append(append([]string{}, include...), exclude...) {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("invalid bucket pattern %q: %w", pattern, err)
+		}
+	}
+	return &BucketFilter{Include: include, Exclude: exclude}, nil
+}
+
+// Match reports whether the bucket passes the filter. A nil filter matches everything.
+func (f *BucketFilter) Match(bucket string) bool {
+	if f == nil {
+		return true
+	}
+	for _, pattern := range f.Exclude {
+		if ok, _ := path.Match(pattern, bucket); ok {
+			return false
+		}
+	}
+	if len(f.Include) == 0 {
+		return true
+	}
+	for _, pattern := range f.Include {
+		if ok, _ := path.Match(pattern, bucket); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// Apply removes buckets that do not pass the filter from the map
+func (f *BucketFilter) Apply(buckets map[string]*BucketInfo) {
+	if f == nil {
+		return
+	}
+	for name := range buckets {
+		if !f.Match(name) {
+			delete(buckets, name)
+		}
+	}
+}
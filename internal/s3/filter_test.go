@@ -0,0 +1,80 @@
+package s3
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestBucketFilter_Match(t *testing.T) {
+	filter, err := NewBucketFilter([]string{"prod-*"}, []string{"*-logs"})
+	if err != nil {
+		t.Fatalf("NewBucketFilter failed: %v", err)
+	}
+
+	tests := map[string]bool{
+		"prod-data":    true,
+		"prod-logs":    false,
+		"staging-data": false,
+	}
+	for bucket, want := range tests {
+		if got := filter.Match(bucket); got != want {
+			t.Fatalf("Match(%q) = %v, want %v", bucket, got, want)
+		}
+	}
+
+	var none *BucketFilter
+	if !none.Match("anything") {
+		t.Fatalf("nil filter should match everything")
+	}
+}
+
+func TestNewBucketFilter(t *testing.T) {
+	filter, err := NewBucketFilter(nil, nil)
+	if err != nil || filter != nil {
+		t.Fatalf("expected nil filter without patterns, got %v, %v", filter, err)
+	}
+	if _, err := NewBucketFilter([]string{"prod-["}, nil); err == nil {
+		t.Fatalf("expected error for malformed pattern")
+	}
+}
+
+func TestInspector_ListAllBucketsWithMetadata_Filtered(t *testing.T) {
+	listBucketsXML := `<?xml version="1.0" encoding="UTF-8"?>
+<ListAllMyBucketsResult xmlns="http://s3.amazonaws.com/doc/2006-03-01/">
+  <Buckets>
+    <Bucket><Name>prod-data</Name><CreationDate>2024-01-01T00:00:00.000Z</CreationDate></Bucket>
+    <Bucket><Name>prod-logs</Name><CreationDate>2024-01-01T00:00:00.000Z</CreationDate></Bucket>
+    <Bucket><Name>dev-data</Name><CreationDate>2024-01-01T00:00:00.000Z</CreationDate></Bucket>
+  </Buckets>
+</ListAllMyBucketsResult>`
+	locationXML := `<?xml version="1.0" encoding="UTF-8"?>
+<LocationConstraint xmlns="http://s3.amazonaws.com/doc/2006-03-01/">eu-west-1</LocationConstraint>`
+
+	var locationCalls []string
+	rt := roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		if _, ok := req.URL.Query()["location"]; ok {
+			locationCalls = append(locationCalls, strings.Trim(req.URL.Path, "/"))
+			return xmlResponse(locationXML), nil
+		}
+		return xmlResponse(listBucketsXML), nil
+	})
+	inspector := NewInspector(newTestClient(t, rt), 1)
+	filter, _ := NewBucketFilter([]string{"prod-*"}, []string{"*-logs"})
+	inspector.SetBucketFilter(filter)
+
+	buckets, regions, _, err := inspector.listAllBucketsWithMetadata(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("listAllBucketsWithMetadata failed: %v", err)
+	}
+	if len(buckets) != 1 || !buckets["prod-data"] {
+		t.Fatalf("expected only prod-data, got %v", buckets)
+	}
+	if regions["prod-data"] != "eu-west-1" {
+		t.Fatalf("expected eu-west-1, got %q", regions["prod-data"])
+	}
+	if len(locationCalls) != 1 {
+		t.Fatalf("expected location lookup only for kept bucket, got %v", locationCalls)
+	}
+}
@@ -0,0 +1,108 @@
+package s3
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/glacier"
+)
+
+// VaultInfo contains metadata about a Glacier vault
+type VaultInfo struct {
+	Name              string     `json:"name"`
+	ARN               string     `json:"arn"`
+	Region            string     `json:"region"`
+	CreationDate      *time.Time `json:"creation_date,omitempty"`
+	LastInventoryDate *time.Time `json:"last_inventory_date,omitempty"`
+	NumberOfArchives  int64      `json:"number_of_archives"`
+	SizeInBytes       int64      `json:"size_in_bytes"`
+}
+
+// GlacierAPI is the subset of the Glacier client used to list vaults
+type GlacierAPI interface {
+	ListVaults(ctx context.Context, params *glacier.ListVaultsInput, optFns ...func(*glacier.Options)) (*glacier.ListVaultsOutput, error)
+}
+
+// glacierClient returns the Glacier client for a region
+func (i *Inspector) glacierClient(region string) GlacierAPI {
+	if i.newGlacierAPI != nil {
+		return i.newGlacierAPI(region)
+	}
+	cfg := i.client.config.Copy()
+	cfg.Region = region
+	return glacier.NewFromConfig(cfg)
+}
+
+// serviceRegions returns the scanned regions for regional AWS services such
+// as Glacier and GuardDuty. Custom S3 endpoint regions are left out because
+// they do not serve them.
+func (i *Inspector) serviceRegions(ctx context.Context) ([]string, error) {
+	if len(i.regions) > 0 {
+		return i.regions, nil
+	}
+	if i.allRegions {
+		return i.client.ListRegions(ctx)
+	}
+	return []string{i.client.GetRegion()}, nil
+}
+
+// ListVaults lists the Glacier vaults in the scanned regions, sorted by
+// region and name
+func (i *Inspector) ListVaults(ctx context.Context) ([]VaultInfo, error) {
+	regions, err := i.serviceRegions(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to determine regions: %w", err)
+	}
+
+	var vaults []VaultInfo
+	for _, region := range regions {
+		api := i.glacierClient(region)
+		// "-" is the account that owns the credentials
+		paginator := glacier.NewListVaultsPaginator(api, &glacier.ListVaultsInput{AccountId: aws.String("-")})
+		for paginator.HasMorePages() {
+			var page *glacier.ListVaultsOutput
+			err := i.client.WithRetry(ctx, func() error {
+				var err error
+				page, err = paginator.NextPage(ctx)
+				return err
+			})
+			if err != nil {
+				return nil, fmt.Errorf("failed to list Glacier vaults in %s: %w", region, err)
+			}
+			for _, v := range page.VaultList {
+				vaults = append(vaults, VaultInfo{
+					Name:              aws.ToString(v.VaultName),
+					ARN:               aws.ToString(v.VaultARN),
+					Region:            region,
+					CreationDate:      parseGlacierTime(v.CreationDate),
+					LastInventoryDate: parseGlacierTime(v.LastInventoryDate),
+					NumberOfArchives:  v.NumberOfArchives,
+					SizeInBytes:       v.SizeInBytes,
+				})
+			}
+		}
+	}
+
+	sort.Slice(vaults, func(a, b int) bool {
+		if vaults[a].Region != vaults[b].Region {
+			return vaults[a].Region < vaults[b].Region
+		}
+		return vaults[a].Name < vaults[b].Name
+	})
+	return vaults, nil
+}
+
+// parseGlacierTime parses the ISO 8601 timestamps Glacier returns as strings
+func parseGlacierTime(s *string) *time.Time {
+	if s == nil {
+		return nil
+	}
+	t, err := time.Parse(time.RFC3339, *s)
+	if err != nil {
+		return nil
+	}
+	return &t
+}
@@ -0,0 +1,62 @@
+package s3
+
+import (
+	"context"
+	"strconv"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/glacier"
+	glaciertypes "github.com/aws/aws-sdk-go-v2/service/glacier/types"
+)
+
+// fakeGlacierAPI returns one vault per page
+type fakeGlacierAPI struct {
+	vaults []glaciertypes.DescribeVaultOutput
+	calls  int
+}
+
+func (f *fakeGlacierAPI) ListVaults(_ context.Context, in *glacier.ListVaultsInput, _ ...func(*glacier.Options)) (*glacier.ListVaultsOutput, error) {
+	f.calls++
+	i, _ := strconv.Atoi(aws.ToString(in.Marker))
+	out := &glacier.ListVaultsOutput{VaultList: f.vaults[i : i+1]}
+	if i+1 < len(f.vaults) {
+		out.Marker = aws.String(strconv.Itoa(i + 1))
+	}
+	return out, nil
+}
+
+func TestListVaults(t *testing.T) {
+	api := &fakeGlacierAPI{vaults: []glaciertypes.DescribeVaultOutput{
+		{VaultName: aws.String("logs"), VaultARN: aws.String("arn:aws:glacier:us-east-1:123456789012:vaults/logs"), CreationDate: aws.String("2020-03-20T17:03:43.221Z"), NumberOfArchives: 4, SizeInBytes: 2048},
+		{VaultName: aws.String("backups"), VaultARN: aws.String("arn:aws:glacier:us-east-1:123456789012:vaults/backups")},
+	}}
+	inspector := NewInspector(&Client{config: aws.Config{Region: "us-east-1"}}, 1)
+	inspector.newGlacierAPI = func(region string) GlacierAPI {
+		if region != "us-east-1" {
+			t.Fatalf("unexpected region %s", region)
+		}
+		return api
+	}
+
+	vaults, err := inspector.ListVaults(context.Background())
+	if err != nil {
+		t.Fatalf("ListVaults failed: %v", err)
+	}
+	if api.calls != 2 {
+		t.Fatalf("expected 2 pages, got %d", api.calls)
+	}
+	if len(vaults) != 2 || vaults[0].Name != "backups" || vaults[1].Name != "logs" {
+		t.Fatalf("expected vaults sorted by name, got %+v", vaults)
+	}
+	logs := vaults[1]
+	if logs.Region != "us-east-1" || logs.NumberOfArchives != 4 || logs.SizeInBytes != 2048 {
+		t.Fatalf("unexpected vault info: %+v", logs)
+	}
+	if logs.CreationDate == nil || logs.CreationDate.Year() != 2020 {
+		t.Fatalf("expected parsed creation date, got %v", logs.CreationDate)
+	}
+	if vaults[0].CreationDate != nil {
+		t.Fatalf("expected no creation date for backups")
+	}
+}
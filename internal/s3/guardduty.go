@@ -0,0 +1,87 @@
+package s3
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/guardduty"
+	guarddutytypes "github.com/aws/aws-sdk-go-v2/service/guardduty/types"
+)
+
+// GuardDutyAPI is the subset of the GuardDuty client used to read detectors
+type GuardDutyAPI interface {
+	ListDetectors(ctx context.Context, params *guardduty.ListDetectorsInput, optFns ...func(*guardduty.Options)) (*guardduty.ListDetectorsOutput, error)
+	GetDetector(ctx context.Context, params *guardduty.GetDetectorInput, optFns ...func(*guardduty.Options)) (*guardduty.GetDetectorOutput, error)
+}
+
+// S3ProtectionStatus is whether GuardDuty S3 protection is on in a region
+type S3ProtectionStatus struct {
+	Region string `json:"region"`
+	// DetectorID is empty when GuardDuty is not enabled in the region
+	DetectorID string `json:"detector_id,omitempty"`
+	Enabled    bool   `json:"enabled"`
+}
+
+// guardDutyClient returns the GuardDuty client for a region
+func (i *Inspector) guardDutyClient(region string) GuardDutyAPI {
+	if i.newGuardDutyAPI != nil {
+		return i.newGuardDutyAPI(region)
+	}
+	cfg := i.client.config.Copy()
+	cfg.Region = region
+	return guardduty.NewFromConfig(cfg)
+}
+
+// GuardDutyS3Protection reports GuardDuty S3 protection in each scanned
+// region. A region has one detector per account; S3 protection counts as on
+// only when the detector itself is enabled.
+func (i *Inspector) GuardDutyS3Protection(ctx context.Context) ([]S3ProtectionStatus, error) {
+	regions, err := i.serviceRegions(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to determine regions: %w", err)
+	}
+
+	statuses := make([]S3ProtectionStatus, 0, len(regions))
+	for _, region := range regions {
+		api := i.guardDutyClient(region)
+		status := S3ProtectionStatus{Region: region}
+
+		var detectors *guardduty.ListDetectorsOutput
+		err := i.client.WithRetry(ctx, func() error {
+			var err error
+			detectors, err = api.ListDetectors(ctx, &guardduty.ListDetectorsInput{})
+			return err
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to list GuardDuty detectors in %s: %w", region, err)
+		}
+		if len(detectors.DetectorIds) > 0 {
+			status.DetectorID = detectors.DetectorIds[0]
+			var detector *guardduty.GetDetectorOutput
+			err := i.client.WithRetry(ctx, func() error {
+				var err error
+				detector, err = api.GetDetector(ctx, &guardduty.GetDetectorInput{DetectorId: aws.String(status.DetectorID)})
+				return err
+			})
+			if err != nil {
+				return nil, fmt.Errorf("failed to read GuardDuty detector in %s: %w", region, err)
+			}
+			status.Enabled = detector.Status == guarddutytypes.DetectorStatusEnabled && s3ProtectionEnabled(detector)
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses, nil
+}
+
+// s3ProtectionEnabled reads S3 protection from the detector's features,
+// falling back to the older data sources field
+func s3ProtectionEnabled(detector *guardduty.GetDetectorOutput) bool {
+	for _, feature := range detector.Features {
+		if feature.Name == guarddutytypes.DetectorFeatureResultS3DataEvents {
+			return feature.Status == guarddutytypes.FeatureStatusEnabled
+		}
+	}
+	return detector.DataSources != nil && detector.DataSources.S3Logs != nil &&
+		detector.DataSources.S3Logs.Status == guarddutytypes.DataSourceStatusEnabled
+}
@@ -0,0 +1,68 @@
+package s3
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/guardduty"
+	guarddutytypes "github.com/aws/aws-sdk-go-v2/service/guardduty/types"
+)
+
+// fakeGuardDutyAPI serves one region's detector, if any
+type fakeGuardDutyAPI struct {
+	detector *guardduty.GetDetectorOutput
+}
+
+func (f *fakeGuardDutyAPI) ListDetectors(context.Context, *guardduty.ListDetectorsInput, ...func(*guardduty.Options)) (*guardduty.ListDetectorsOutput, error) {
+	if f.detector == nil {
+		return &guardduty.ListDetectorsOutput{}, nil
+	}
+	return &guardduty.ListDetectorsOutput{DetectorIds: []string{"det-1"}}, nil
+}
+
+func (f *fakeGuardDutyAPI) GetDetector(context.Context, *guardduty.GetDetectorInput, ...func(*guardduty.Options)) (*guardduty.GetDetectorOutput, error) {
+	return f.detector, nil
+}
+
+func TestGuardDutyS3Protection(t *testing.T) {
+	apis := map[string]*fakeGuardDutyAPI{
+		"us-east-1": {detector: &guardduty.GetDetectorOutput{
+			Status:   guarddutytypes.DetectorStatusEnabled,
+			Features: []guarddutytypes.DetectorFeatureConfigurationResult{{Name: guarddutytypes.DetectorFeatureResultS3DataEvents, Status: guarddutytypes.FeatureStatusEnabled}},
+		}},
+		"us-west-2": {detector: &guardduty.GetDetectorOutput{
+			Status:      guarddutytypes.DetectorStatusEnabled,
+			DataSources: &guarddutytypes.DataSourceConfigurationsResult{S3Logs: &guarddutytypes.S3LogsConfigurationResult{Status: guarddutytypes.DataSourceStatusEnabled}},
+		}},
+		"eu-west-1": {detector: &guardduty.GetDetectorOutput{
+			Status:   guarddutytypes.DetectorStatusDisabled,
+			Features: []guarddutytypes.DetectorFeatureConfigurationResult{{Name: guarddutytypes.DetectorFeatureResultS3DataEvents, Status: guarddutytypes.FeatureStatusEnabled}},
+		}},
+		"eu-central-1": {detector: &guardduty.GetDetectorOutput{
+			Status:   guarddutytypes.DetectorStatusEnabled,
+			Features: []guarddutytypes.DetectorFeatureConfigurationResult{{Name: guarddutytypes.DetectorFeatureResultS3DataEvents, Status: guarddutytypes.FeatureStatusDisabled}},
+		}},
+		"ap-south-1": {},
+	}
+	inspector := NewInspector(&Client{config: aws.Config{Region: "us-east-1"}}, 1)
+	inspector.SetRegions([]string{"us-east-1", "us-west-2", "eu-west-1", "eu-central-1", "ap-south-1"})
+	inspector.newGuardDutyAPI = func(region string) GuardDutyAPI { return apis[region] }
+
+	statuses, err := inspector.GuardDutyS3Protection(context.Background())
+	if err != nil {
+		t.Fatalf("GuardDutyS3Protection failed: %v", err)
+	}
+	want := map[string]bool{"us-east-1": true, "us-west-2": true, "eu-west-1": false, "eu-central-1": false, "ap-south-1": false}
+	if len(statuses) != len(want) {
+		t.Fatalf("expected one status per region, got %+v", statuses)
+	}
+	for _, s := range statuses {
+		if s.Enabled != want[s.Region] {
+			t.Fatalf("region %s: expected enabled=%v, got %+v", s.Region, want[s.Region], s)
+		}
+		if s.Region == "ap-south-1" && s.DetectorID != "" {
+			t.Fatalf("expected no detector in ap-south-1, got %+v", s)
+		}
+	}
+}
@@ -2,7 +2,9 @@ package s3
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"math/rand"
 	"strings"
 	"sync"
 	"time"
@@ -13,16 +15,70 @@ import (
 	"github.com/ppiankov/s3spectre/internal/scanner"
 )
 
+// Object listing limits. Activity and prefix age are read from at most this
+// many objects, so larger buckets and prefixes are estimates.
+const (
+	ActivitySampleSize = 100
+	PrefixListLimit    = 1000
+)
+
 // ProgressCallback is called during inspection to report progress
 type ProgressCallback func(current, total int, message string)
 
 // Inspector inspects S3 buckets and prefixes
 type Inspector struct {
-	client           *Client
-	concurrency      int
-	progressCallback ProgressCallback
-	regions          []string
-	allRegions       bool
+	client             *Client
+	concurrency        int
+	progressCallback   ProgressCallback
+	progress           *Progress
+	regions            []string
+	allRegions         bool
+	filter             *BucketFilter
+	tagFilters         []TagFilter
+	sampleSize         int
+	population         int
+	rng                *rand.Rand
+	checkpoint         *Checkpoint
+	bucketTimeout      time.Duration
+	bucketDeadline     time.Duration
+	versionSampling    bool
+	deepSize           int
+	metricsSource      string
+	inventoryReports   map[string]*InventoryReport
+	checkPolicy        bool
+	checkPublic        bool
+	checkLogging       bool
+	accountID          string
+	beforeInspect      func(buckets int)
+	outposts           []Outpost
+	environments       Environments
+	newOutpostsAPI     func(region string) OutpostsAPI
+	newGlacierAPI      func(region string) GlacierAPI
+	newIAMAPI          func() IAMAPI
+	newGuardDutyAPI    func(region string) GuardDutyAPI
+	newAccountPABAPI   func() AccountPublicAccessAPI
+	newCostExplorerAPI func() CostExplorerAPI
+	newCloudTrailAPI   func(region string) CloudTrailLakeAPI
+	newCloudWatchAPI   func(region string) CloudWatchAPI
+	newInventoryAPI    func() InventoryAPI
+	newPolicyAPI       func(region string) PolicyAPI
+	newACLAPI          func(region string) ACLAPI
+	newObjectLockAPI   func(region string) ObjectLockAPI
+	newReplicationAPI  func(region string) ReplicationAPI
+	newLoggingAPI      func(region string) LoggingAPI
+	newTrailsAPI       func(region string) TrailsAPI
+	newAnalyzerAPI     func(region string) AccessAnalyzerAPI
+	newMacieAPI        func(region string) MacieAPI
+	// trails caches the CloudTrail trails of each region for the logging
+	// check
+	trailsMu sync.Mutex
+	trails   map[string]*regionTrails
+	// accountPublicAccess is the account's public access block, applied on
+	// top of each bucket's by the public access check
+	accountPublicAccess *PublicAccessInfo
+	// newMetricsConfigAPI replaces the S3 client reading request metrics
+	// configurations in tests
+	newMetricsConfigAPI func(region string) MetricsConfigAPI
 }
 
 // NewInspector creates a new S3 inspector
@@ -31,9 +87,10 @@ func NewInspector(client *Client, concurrency int) *Inspector {
 		concurrency = 10
 	}
 	return &Inspector{
-		client:      client,
-		concurrency: concurrency,
-		allRegions:  false,
+		client:       client,
+		concurrency:  concurrency,
+		allRegions:   false,
+		environments: NewEnvironments(nil),
 	}
 }
 
@@ -42,17 +99,99 @@ func (i *Inspector) SetProgressCallback(callback ProgressCallback) {
 	i.progressCallback = callback
 }
 
+// SetProgress tracks bucket inspection timing and in-flight operations in p
+func (i *Inspector) SetProgress(p *Progress) {
+	i.progress = p
+}
+
 // SetRegions sets specific regions to scan
 func (i *Inspector) SetRegions(regions []string) {
 	i.regions = regions
 	i.allRegions = false
 }
 
+// SetEnvironments sets the environment names recognized when suggesting
+// buckets for missing ones
+func (i *Inspector) SetEnvironments(envs Environments) {
+	i.environments = envs
+}
+
 // SetAllRegions enables scanning all AWS regions
 func (i *Inspector) SetAllRegions(enabled bool) {
 	i.allRegions = enabled
 }
 
+// SetBucketFilter limits discovery to buckets whose names pass the filter.
+// Filtering happens right after ListBuckets, before any per-bucket API calls.
+func (i *Inspector) SetBucketFilter(filter *BucketFilter) {
+	i.filter = filter
+}
+
+// SetTagFilters limits discovery to buckets whose tags match every filter.
+// Tags are fetched first so non-matching buckets skip the remaining calls.
+func (i *Inspector) SetTagFilters(filters []TagFilter) {
+	i.tagFilters = filters
+}
+
+// SetSampleSize limits full inspection to a random sample of n buckets.
+// Zero disables sampling.
+func (i *Inspector) SetSampleSize(n int) {
+	i.sampleSize = n
+}
+
+// SetCheckpoint reuses buckets already in cp and records newly inspected ones
+func (i *Inspector) SetCheckpoint(cp *Checkpoint) {
+	i.checkpoint = cp
+}
+
+// SetBucketTimeout bounds the inspection of each bucket. A bucket that runs
+// out of time keeps what was collected, is marked TimedOut and is not
+// checkpointed. Zero disables the limit.
+func (i *Inspector) SetBucketTimeout(d time.Duration) {
+	i.bucketTimeout = d
+}
+
+// bucketContext returns the context one bucket is inspected with
+func (i *Inspector) bucketContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	if i.bucketTimeout > 0 {
+		return context.WithTimeout(ctx, i.bucketTimeout)
+	}
+	return context.WithCancel(ctx)
+}
+
+// markIncomplete degrades a bucket whose inspection was cut short into a
+// skipped check: TimedOut when a deadline hit, either its own or the
+// inspection phase's, and Interrupted when the run was cancelled. Prefix
+// results are dropped because unfinished prefixes would read as missing.
+func (i *Inspector) markIncomplete(ctx, bucketCtx context.Context, info *BucketInfo, bucket, region string, listed bool) *BucketInfo {
+	if info == nil {
+		info = &BucketInfo{Name: bucket, Region: region}
+	}
+	info.Exists = info.Exists || listed
+	if info.Region == "" {
+		info.Region = region
+	}
+	info.Prefixes = nil
+	switch {
+	case !errors.Is(bucketCtx.Err(), context.DeadlineExceeded):
+		info.Interrupted = true
+		info.Error = "inspection interrupted; remaining checks skipped"
+	case ctx.Err() != nil:
+		info.TimedOut = true
+		info.Error = "inspection phase timed out; remaining checks skipped"
+	default:
+		info.TimedOut = true
+		info.Error = fmt.Sprintf("bucket inspection timed out after %s; remaining checks skipped", i.bucketTimeout)
+	}
+	return info
+}
+
+// Population returns the number of buckets eligible for inspection in the
+// last discovery, before sampling
+func (i *Inspector) Population() int {
+	return i.population
+}
+
 // reportProgress calls the progress callback if set
 func (i *Inspector) reportProgress(current, total int, message string) {
 	if i.progressCallback != nil {
@@ -100,6 +239,7 @@ func (i *Inspector) InspectBuckets(ctx context.Context, refs []scanner.Reference
 
 	total := len(bucketRefs)
 	current := 0
+	i.progress.addTotal(total)
 
 	for bucket, refs := range bucketRefs {
 		wg.Add(1)
@@ -108,7 +248,26 @@ func (i *Inspector) InspectBuckets(ctx context.Context, refs []scanner.Reference
 			semaphore <- struct{}{}        // Acquire
 			defer func() { <-semaphore }() // Release
 
-			info := i.inspectBucket(ctx, bucket, refs)
+			info, ok := i.checkpoint.lookup(bucket)
+			if ok {
+				i.progress.skip()
+			} else {
+				bucketCtx, cancel := i.bucketContext(ctx)
+				bucketCtx, finish := i.progress.begin(bucketCtx, bucket, bucketRegions[bucket])
+				if arn := outpostsARN(refs); arn != "" {
+					info = i.inspectOutpostsBucketARN(bucketCtx, arn)
+				} else {
+					info = i.inspectBucket(bucketCtx, bucket, bucketRegions[bucket], refs)
+				}
+				if bucketCtx.Err() != nil {
+					// Buckets cut short are inspected again on resume
+					info = i.markIncomplete(ctx, bucketCtx, info, bucket, bucketRegions[bucket], awsBuckets[bucket])
+				} else {
+					i.checkpoint.record(info)
+				}
+				finish()
+				cancel()
+			}
 
 			mu.Lock()
 			current++
@@ -120,6 +279,16 @@ func (i *Inspector) InspectBuckets(ctx context.Context, refs []scanner.Reference
 
 	wg.Wait()
 
+	existing := make([]string, 0, len(awsBuckets))
+	for name := range awsBuckets {
+		existing = append(existing, name)
+	}
+	for _, info := range bucketInfo {
+		if !info.Exists && !info.TimedOut && !info.Interrupted {
+			info.NearMisses = ClosestBuckets(info.Name, existing, i.environments)
+		}
+	}
+
 	return bucketInfo, nil
 }
 
@@ -136,7 +305,7 @@ func (i *Inspector) determineRegions(ctx context.Context) ([]string, error) {
 		if err != nil {
 			return nil, err
 		}
-		return regions, nil
+		return i.client.withEndpointRegions(regions), nil
 	}
 
 	// Default to the client's configured region
@@ -175,43 +344,55 @@ func (i *Inspector) listAllBucketsMultiRegion(ctx context.Context, regions []str
 		}
 	}
 
+	// Buckets behind endpoint overrides are not in the AWS listing
+	_, endpointRegions, err := i.listEndpointBuckets(ctx, regions, buckets)
+	if err != nil {
+		return nil, nil, err
+	}
+	for bucketName, region := range endpointRegions {
+		buckets[bucketName] = true
+		bucketRegions[bucketName] = region
+	}
+
 	return buckets, bucketRegions, nil
 }
 
 // getBucketRegion gets the region of a specific bucket
 func (i *Inspector) getBucketRegion(ctx context.Context, bucket string) (string, error) {
-	var locationResult *s3.GetBucketLocationOutput
-	err := i.client.WithRetry(ctx, func() error {
-		var err error
-		locationResult, err = i.client.s3Client.GetBucketLocation(ctx, &s3.GetBucketLocationInput{
-			Bucket: aws.String(bucket),
-		})
-		return err
-	})
-	if err != nil {
-		return "", err
-	}
-
-	// Handle the special case where us-east-1 returns empty string
-	if locationResult.LocationConstraint == "" {
-		return "us-east-1", nil
-	}
-
-	return string(locationResult.LocationConstraint), nil
+	return i.client.BucketRegion(ctx, bucket)
 }
 
-// inspectBucket inspects a single bucket
-func (i *Inspector) inspectBucket(ctx context.Context, bucket string, refs []scanner.Reference) *BucketInfo {
+// inspectBucket inspects a single bucket. listedRegion is the region from the bucket listing; it is trusted only for
+// endpoint overrides, whose buckets AWS cannot locate.
+func (i *Inspector) inspectBucket(ctx context.Context, bucket, listedRegion string, refs []scanner.Reference) *BucketInfo {
 	info := &BucketInfo{
 		Name:   bucket,
 		Exists: false,
 	}
+	checks, cancel := i.newBucketChecks(ctx, info)
+	defer cancel()
 
 	// Get bucket region first
-	region, err := i.getBucketRegion(ctx, bucket)
-	if err != nil {
-		info.Error = formatError("get bucket location", bucket, err)
-		return info
+	region := listedRegion
+	var err error
+	if _, custom := i.client.endpoints[listedRegion]; !custom {
+		located := checks.run(CheckLocation, func(ctx context.Context) {
+			region, err = i.getBucketRegion(ctx, bucket)
+		})
+		if !located {
+			// Without its location a bucket is known only from the listing,
+			// and one missing from it is not known to be missing
+			info.Exists = listedRegion != ""
+			info.Region = listedRegion
+			if !info.Exists {
+				return info
+			}
+			region, err = listedRegion, nil
+		}
+		if err != nil {
+			info.Error = formatError("get bucket location", bucket, err)
+			return info
+		}
 	}
 
 	info.Exists = true
@@ -220,7 +401,7 @@ func (i *Inspector) inspectBucket(ctx context.Context, bucket string, refs []sca
 	// Create region-specific client if needed
 	regionClient := i.client
 	if region != i.client.GetRegion() {
-		regionClient = NewClientForRegion(i.client.GetConfig(), region)
+		regionClient = i.client.ForRegion(region)
 	}
 
 	// Get bucket creation date (from ListBuckets - we'll get it from the bucket metadata)
@@ -228,74 +409,93 @@ func (i *Inspector) inspectBucket(ctx context.Context, bucket string, refs []sca
 	// For efficiency, we'll skip this for now or get it from tags
 
 	// Get versioning status
-	err = regionClient.WithRetry(ctx, func() error {
-		versioningResult, err := regionClient.s3Client.GetBucketVersioning(ctx, &s3.GetBucketVersioningInput{
-			Bucket: aws.String(bucket),
+	checks.run(CheckVersioning, func(ctx context.Context) {
+		err := regionClient.WithRetry(ctx, func() error {
+			versioningResult, err := regionClient.s3Client.GetBucketVersioning(ctx, &s3.GetBucketVersioningInput{
+				Bucket: aws.String(bucket),
+			})
+			if err == nil {
+				info.VersioningEnabled = versioningResult.Status == types.BucketVersioningStatusEnabled
+			}
+			return err
 		})
-		if err == nil {
-			info.VersioningEnabled = versioningResult.Status == types.BucketVersioningStatusEnabled
+		if err != nil && ctx.Err() == nil {
+			// Non-fatal, continue
+			info.Error = formatError("get versioning", bucket, err)
 		}
-		return err
 	})
-	if err != nil {
-		// Non-fatal, continue
-		info.Error = formatError("get versioning", bucket, err)
-	}
 
 	// Get lifecycle configuration
-	_ = regionClient.WithRetry(ctx, func() error {
-		lifecycleResult, err := regionClient.s3Client.GetBucketLifecycleConfiguration(ctx, &s3.GetBucketLifecycleConfigurationInput{
-			Bucket: aws.String(bucket),
+	checks.run(CheckLifecycle, func(ctx context.Context) {
+		_ = regionClient.WithRetry(ctx, func() error {
+			lifecycleResult, err := regionClient.s3Client.GetBucketLifecycleConfiguration(ctx, &s3.GetBucketLifecycleConfigurationInput{
+				Bucket: aws.String(bucket),
+			})
+			if err == nil && lifecycleResult.Rules != nil {
+				info.LifecycleRules = len(lifecycleResult.Rules)
+			}
+			// Lifecycle not existing is not an error
+			if err != nil && strings.Contains(err.Error(), "NoSuchLifecycleConfiguration") {
+				return nil
+			}
+			return err
 		})
-		if err == nil && lifecycleResult.Rules != nil {
-			info.LifecycleRules = len(lifecycleResult.Rules)
-		}
-		// Lifecycle not existing is not an error
-		if err != nil && strings.Contains(err.Error(), "NoSuchLifecycleConfiguration") {
-			return nil
-		}
-		return err
+		// Non-fatal error, continue
 	})
-	// Non-fatal error, continue
 
 	// Get bucket tagging for unused detection
-	_ = regionClient.WithRetry(ctx, func() error {
-		taggingResult, err := regionClient.s3Client.GetBucketTagging(ctx, &s3.GetBucketTaggingInput{
-			Bucket: aws.String(bucket),
-		})
-		if err == nil && taggingResult.TagSet != nil {
-			info.Tags = make(map[string]string)
-			for _, tag := range taggingResult.TagSet {
-				if tag.Key != nil && tag.Value != nil {
-					info.Tags[*tag.Key] = *tag.Value
+	checks.run(CheckTagging, func(ctx context.Context) {
+		_ = regionClient.WithRetry(ctx, func() error {
+			taggingResult, err := regionClient.s3Client.GetBucketTagging(ctx, &s3.GetBucketTaggingInput{
+				Bucket: aws.String(bucket),
+			})
+			if err == nil && taggingResult.TagSet != nil {
+				info.Tags = make(map[string]string)
+				for _, tag := range taggingResult.TagSet {
+					if tag.Key != nil && tag.Value != nil {
+						info.Tags[*tag.Key] = *tag.Value
+					}
 				}
 			}
-		}
-		// No tags is not an error
-		if err != nil && strings.Contains(err.Error(), "NoSuchTagSet") {
-			return nil
-		}
-		return err
+			// No tags is not an error
+			if err != nil && strings.Contains(err.Error(), "NoSuchTagSet") {
+				return nil
+			}
+			return err
+		})
+		// Non-fatal error, continue
+	})
+
+	// Get replication rules; their destinations are written by replication
+	// rather than by code
+	checks.run(CheckReplication, func(ctx context.Context) {
+		i.readReplication(ctx, regionClient, bucket, info)
 	})
-	// Non-fatal error, continue
 
 	// Check if bucket is empty (for unused detection)
-	_ = regionClient.WithRetry(ctx, func() error {
-		listResult, err := regionClient.s3Client.ListObjectsV2(ctx, &s3.ListObjectsV2Input{
-			Bucket:  aws.String(bucket),
-			MaxKeys: aws.Int32(1),
+	checks.run(CheckActivity, func(ctx context.Context) {
+		_ = regionClient.WithRetry(ctx, func() error {
+			listResult, err := regionClient.s3Client.ListObjectsV2(ctx, &s3.ListObjectsV2Input{
+				Bucket:  aws.String(bucket),
+				MaxKeys: aws.Int32(1),
+			})
+			if err == nil {
+				info.IsEmpty = listResult.KeyCount != nil && *listResult.KeyCount == 0
+			}
+			return err
 		})
-		if err == nil {
-			info.IsEmpty = listResult.KeyCount != nil && *listResult.KeyCount == 0
-		}
-		return err
+		// Non-fatal error, continue
 	})
-	// Non-fatal error, continue
 
 	// Inspect prefixes
 	prefixes := i.extractPrefixes(refs)
 	if len(prefixes) > 0 {
-		info.Prefixes = i.inspectPrefixesWithClient(ctx, regionClient, bucket, prefixes)
+		checks.run(CheckPrefixes, func(ctx context.Context) {
+			// Unfinished prefixes would read as missing
+			if inspected := i.inspectPrefixesWithClient(ctx, regionClient, bucket, prefixes); ctx.Err() == nil {
+				info.Prefixes = inspected
+			}
+		})
 	}
 
 	return info
@@ -375,14 +575,14 @@ func (i *Inspector) inspectPrefixWithClient(ctx context.Context, client *Client,
 		Exists: false,
 	}
 
-	// List objects with this prefix (limited to 1000 for MVP)
+	// List objects with this prefix, up to PrefixListLimit
 	var listResult *s3.ListObjectsV2Output
 	err := client.WithRetry(ctx, func() error {
 		var err error
 		listResult, err = client.s3Client.ListObjectsV2(ctx, &s3.ListObjectsV2Input{
 			Bucket:  aws.String(bucket),
 			Prefix:  aws.String(prefix),
-			MaxKeys: aws.Int32(1000),
+			MaxKeys: aws.Int32(PrefixListLimit),
 		})
 		return err
 	})
@@ -391,6 +591,7 @@ func (i *Inspector) inspectPrefixWithClient(ctx context.Context, client *Client,
 	}
 
 	if listResult.KeyCount == nil || *listResult.KeyCount == 0 {
+		info.NearMiss = i.nearMissPrefix(ctx, client, bucket, prefix)
 		return info
 	}
 
@@ -427,42 +628,72 @@ func (i *Inspector) DiscoverAllBuckets(ctx context.Context) (map[string]*BucketI
 
 	// List ALL buckets and their regions
 	i.reportProgress(0, 2, "Listing all S3 buckets")
-	awsBuckets, bucketRegions, bucketMetadata, err := i.listAllBucketsWithMetadata(ctx, regions)
+	_, bucketRegions, bucketMetadata, err := i.listAllBucketsWithMetadata(ctx, regions)
 	if err != nil {
 		return nil, fmt.Errorf("failed to list AWS buckets: %w", err)
 	}
 
-	// Inspect each bucket
+	buckets := i.inspectDiscovered(ctx, bucketRegions, bucketMetadata)
+	if len(i.outposts) > 0 {
+		i.reportProgress(0, 1, fmt.Sprintf("Discovering buckets on %d Outpost(s)", len(i.outposts)))
+		if err := i.discoverOutposts(ctx, buckets); err != nil {
+			return nil, err
+		}
+	}
+	return buckets, nil
+}
+
+// inspectDiscovered fully inspects each bucket concurrently.
+// bucketRegions maps bucket name to region; metadata may be nil.
+func (i *Inspector) inspectDiscovered(ctx context.Context, bucketRegions map[string]string, metadata map[string]*bucketMetadata) map[string]*BucketInfo {
 	bucketInfo := make(map[string]*BucketInfo)
 	var wg sync.WaitGroup
 	var mu sync.Mutex
 	semaphore := make(chan struct{}, i.concurrency)
 
-	total := len(awsBuckets)
+	total := len(bucketRegions)
 	current := 0
+	i.progress.addTotal(total)
+	if i.beforeInspect != nil {
+		i.beforeInspect(total)
+	}
 
-	for bucketName := range awsBuckets {
+	for bucketName, region := range bucketRegions {
 		wg.Add(1)
-		go func(bucket string) {
+		go func(bucket, region string) {
 			defer wg.Done()
 			semaphore <- struct{}{}
 			defer func() { <-semaphore }()
 
-			region := bucketRegions[bucket]
-			metadata := bucketMetadata[bucket]
-			info := i.inspectBucketFull(ctx, bucket, region, metadata)
+			info, ok := i.checkpoint.lookup(bucket)
+			if ok {
+				i.progress.skip()
+			} else {
+				bucketCtx, cancel := i.bucketContext(ctx)
+				bucketCtx, finish := i.progress.begin(bucketCtx, bucket, region)
+				info = i.inspectBucketFull(bucketCtx, bucket, region, metadata[bucket])
+				if bucketCtx.Err() != nil {
+					info = i.markIncomplete(ctx, bucketCtx, info, bucket, region, true)
+				} else {
+					i.checkpoint.record(info)
+				}
+				finish()
+				cancel()
+			}
 
 			mu.Lock()
 			current++
 			i.reportProgress(current, total, fmt.Sprintf("Inspecting %s", bucket))
-			bucketInfo[bucket] = info
+			if info != nil {
+				bucketInfo[bucket] = info
+			}
 			mu.Unlock()
-		}(bucketName)
+		}(bucketName, region)
 	}
 
 	wg.Wait()
 
-	return bucketInfo, nil
+	return bucketInfo
 }
 
 // bucketMetadata holds bucket-level metadata from ListBuckets
@@ -487,8 +718,29 @@ func (i *Inspector) listAllBucketsWithMetadata(ctx context.Context, regions []st
 		return nil, nil, nil, fmt.Errorf("failed to list buckets: %w", err)
 	}
 
-	// For each bucket, store metadata and determine region
+	known := make(map[string]bool, len(result.Buckets))
 	for _, bucket := range result.Buckets {
+		known[aws.ToString(bucket.Name)] = true
+	}
+	endpointBuckets, endpointRegions, err := i.listEndpointBuckets(ctx, regions, known)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	all := append(result.Buckets, endpointBuckets...)
+
+	candidates := make([]types.Bucket, 0, len(all))
+	for _, bucket := range all {
+		if bucket.Name != nil && i.filter.Match(*bucket.Name) {
+			candidates = append(candidates, bucket)
+		}
+	}
+	i.population = len(candidates)
+	if i.sampleSize > 0 && i.sampleSize < len(candidates) {
+		candidates = sampleBuckets(candidates, i.sampleSize, i.rng)
+	}
+
+	// For each bucket, store metadata and determine region
+	for _, bucket := range candidates {
 		if bucket.Name != nil {
 			bucketName := *bucket.Name
 			buckets[bucketName] = true
@@ -499,9 +751,12 @@ func (i *Inspector) listAllBucketsWithMetadata(ctx context.Context, regions []st
 			}
 
 			// Get bucket region
-			region, err := i.getBucketRegion(ctx, bucketName)
-			if err != nil {
-				region = i.client.GetRegion() // Fallback to default
+			region, ok := endpointRegions[bucketName]
+			if !ok {
+				region, err = i.getBucketRegion(ctx, bucketName)
+				if err != nil {
+					region = i.client.GetRegion() // Fallback to default
+				}
 			}
 			bucketRegions[bucketName] = region
 		}
@@ -510,7 +765,8 @@ func (i *Inspector) listAllBucketsWithMetadata(ctx context.Context, regions []st
 	return buckets, bucketRegions, metadata, nil
 }
 
-// inspectBucketFull performs full inspection without needing code references
+// inspectBucketFull performs full inspection without needing code references.
+// Returns nil if the bucket does not match the tag filters.
 func (i *Inspector) inspectBucketFull(ctx context.Context, bucket, region string, metadata *bucketMetadata) *BucketInfo {
 	info := &BucketInfo{
 		Name:   bucket,
@@ -527,92 +783,178 @@ func (i *Inspector) inspectBucketFull(ctx context.Context, bucket, region string
 	// Create region-specific client if needed
 	regionClient := i.client
 	if region != i.client.GetRegion() {
-		regionClient = NewClientForRegion(i.client.GetConfig(), region)
+		regionClient = i.client.ForRegion(region)
+	}
+
+	checks, cancel := i.newBucketChecks(ctx, info)
+	defer cancel()
+
+	// Get bucket tagging first so tag-filtered buckets skip the remaining calls
+	tagged := checks.run(CheckTagging, func(ctx context.Context) {
+		_ = regionClient.WithRetry(ctx, func() error {
+			taggingResult, err := regionClient.s3Client.GetBucketTagging(ctx, &s3.GetBucketTaggingInput{
+				Bucket: aws.String(bucket),
+			})
+			if err == nil && taggingResult.TagSet != nil {
+				info.Tags = make(map[string]string)
+				for _, tag := range taggingResult.TagSet {
+					if tag.Key != nil && tag.Value != nil {
+						info.Tags[*tag.Key] = *tag.Value
+					}
+				}
+			}
+			if err != nil && strings.Contains(err.Error(), "NoSuchTagSet") {
+				return nil
+			}
+			return err
+		})
+	})
+
+	// A bucket whose tags were skipped is kept rather than silently dropped
+	if tagged && len(i.tagFilters) > 0 && !MatchTags(i.tagFilters, info.Tags) {
+		return nil
 	}
 
 	// Get versioning status
-	_ = regionClient.WithRetry(ctx, func() error {
-		versioningResult, err := regionClient.s3Client.GetBucketVersioning(ctx, &s3.GetBucketVersioningInput{
-			Bucket: aws.String(bucket),
+	checks.run(CheckVersioning, func(ctx context.Context) {
+		_ = regionClient.WithRetry(ctx, func() error {
+			versioningResult, err := regionClient.s3Client.GetBucketVersioning(ctx, &s3.GetBucketVersioningInput{
+				Bucket: aws.String(bucket),
+			})
+			if err == nil {
+				info.VersioningEnabled = versioningResult.Status == types.BucketVersioningStatusEnabled
+			}
+			return err
 		})
-		if err == nil {
-			info.VersioningEnabled = versioningResult.Status == types.BucketVersioningStatusEnabled
-		}
-		return err
 	})
 
 	// Get lifecycle configuration
-	_ = regionClient.WithRetry(ctx, func() error {
-		lifecycleResult, err := regionClient.s3Client.GetBucketLifecycleConfiguration(ctx, &s3.GetBucketLifecycleConfigurationInput{
-			Bucket: aws.String(bucket),
+	checks.run(CheckLifecycle, func(ctx context.Context) {
+		_ = regionClient.WithRetry(ctx, func() error {
+			lifecycleResult, err := regionClient.s3Client.GetBucketLifecycleConfiguration(ctx, &s3.GetBucketLifecycleConfigurationInput{
+				Bucket: aws.String(bucket),
+			})
+			if err == nil && lifecycleResult.Rules != nil {
+				info.LifecycleRules = len(lifecycleResult.Rules)
+			}
+			if err != nil && strings.Contains(err.Error(), "NoSuchLifecycleConfiguration") {
+				return nil
+			}
+			return err
 		})
-		if err == nil && lifecycleResult.Rules != nil {
-			info.LifecycleRules = len(lifecycleResult.Rules)
-		}
-		if err != nil && strings.Contains(err.Error(), "NoSuchLifecycleConfiguration") {
-			return nil
-		}
-		return err
 	})
 
-	// Get bucket tagging
-	_ = regionClient.WithRetry(ctx, func() error {
-		taggingResult, err := regionClient.s3Client.GetBucketTagging(ctx, &s3.GetBucketTaggingInput{
-			Bucket: aws.String(bucket),
-		})
-		if err == nil && taggingResult.TagSet != nil {
-			info.Tags = make(map[string]string)
-			for _, tag := range taggingResult.TagSet {
-				if tag.Key != nil && tag.Value != nil {
-					info.Tags[*tag.Key] = *tag.Value
+	// Get default encryption
+	checks.run(CheckEncryption, func(ctx context.Context) {
+		_ = regionClient.WithRetry(ctx, func() error {
+			encryptionResult, err := regionClient.s3Client.GetBucketEncryption(ctx, &s3.GetBucketEncryptionInput{
+				Bucket: aws.String(bucket),
+			})
+			if err == nil {
+				info.Encryption = &EncryptionInfo{}
+				if cfg := encryptionResult.ServerSideEncryptionConfiguration; cfg != nil && len(cfg.Rules) > 0 && cfg.Rules[0].ApplyServerSideEncryptionByDefault != nil {
+					def := cfg.Rules[0].ApplyServerSideEncryptionByDefault
+					info.Encryption.Enabled = true
+					info.Encryption.Algorithm = string(def.SSEAlgorithm)
+					info.Encryption.KMSMasterKeyID = aws.ToString(def.KMSMasterKeyID)
 				}
 			}
-		}
-		if err != nil && strings.Contains(err.Error(), "NoSuchTagSet") {
-			return nil
-		}
-		return err
+			if err != nil && strings.Contains(err.Error(), "ServerSideEncryptionConfigurationNotFoundError") {
+				info.Encryption = &EncryptionInfo{Enabled: false}
+				return nil
+			}
+			return err
+		})
 	})
 
-	// Check if empty and get last activity
-	_ = regionClient.WithRetry(ctx, func() error {
-		listResult, err := regionClient.s3Client.ListObjectsV2(ctx, &s3.ListObjectsV2Input{
-			Bucket:  aws.String(bucket),
-			MaxKeys: aws.Int32(100), // Sample first 100 objects
+	// Get the Object Lock configuration; compliance-mode retention keeps a
+	// bucket from being emptied
+	checks.run(CheckObjectLock, func(ctx context.Context) {
+		i.readObjectLock(ctx, regionClient, bucket, info)
+	})
+
+	// Get replication rules
+	checks.run(CheckReplication, func(ctx context.Context) {
+		i.readReplication(ctx, regionClient, bucket, info)
+	})
+
+	// Get the public access block and any public ACL grants
+	if i.checkPublic {
+		checks.run(CheckACL, func(ctx context.Context) {
+			i.readPublicAccess(ctx, regionClient, bucket, info)
 		})
-		if err == nil {
-			if listResult.KeyCount != nil {
-				info.IsEmpty = *listResult.KeyCount == 0
-				info.ObjectCount = int(*listResult.KeyCount)
-
-				// Calculate size and find most recent object modification
-				var latest *time.Time
-				var totalSize int64
-				for _, obj := range listResult.Contents {
-					if obj.Size != nil {
-						totalSize += *obj.Size
-					}
-					if obj.LastModified != nil {
-						if latest == nil || obj.LastModified.After(*latest) {
-							latest = obj.LastModified
+	}
+
+	// Get the bucket policy and S3's evaluation of it
+	if i.checkPolicy {
+		checks.run(CheckPolicy, func(ctx context.Context) {
+			i.readPolicy(ctx, regionClient, bucket, info)
+		})
+	}
+
+	// Get server access logging and the trails logging data events
+	if i.checkLogging {
+		checks.run(CheckLogging, func(ctx context.Context) {
+			i.readLogging(ctx, regionClient, bucket, info)
+		})
+	}
+
+	// Check if empty and get last activity
+	checks.run(CheckActivity, func(ctx context.Context) {
+		if i.inventoryActivity(bucket, info) {
+			return
+		}
+		if i.deepSize > 0 {
+			i.deepActivity(ctx, regionClient, bucket, info)
+			return
+		}
+		if i.metricsSource == MetricsSourceCloudWatch && i.cloudWatchActivity(ctx, regionClient, bucket, info) {
+			return
+		}
+		_ = regionClient.WithRetry(ctx, func() error {
+			listResult, err := regionClient.s3Client.ListObjectsV2(ctx, &s3.ListObjectsV2Input{
+				Bucket:  aws.String(bucket),
+				MaxKeys: aws.Int32(ActivitySampleSize),
+			})
+			if err == nil {
+				if listResult.KeyCount != nil {
+					info.IsEmpty = *listResult.KeyCount == 0
+					info.ObjectCount = int(*listResult.KeyCount)
+
+					// Calculate size and find most recent object modification
+					var latest *time.Time
+					var totalSize int64
+					for _, obj := range listResult.Contents {
+						if obj.Size != nil {
+							totalSize += *obj.Size
+						}
+						if obj.LastModified != nil {
+							if latest == nil || obj.LastModified.After(*latest) {
+								latest = obj.LastModified
+							}
 						}
 					}
-				}
 
-				info.TotalSize = totalSize
+					info.TotalSize = totalSize
 
-				if latest != nil {
-					info.LastActivity = latest
-					info.DaysSinceActivity = int(time.Since(*latest).Hours() / 24)
+					if latest != nil {
+						info.LastActivity = latest
+						info.DaysSinceActivity = int(time.Since(*latest).Hours() / 24)
+					}
 				}
 			}
-		}
-		return err
+			return err
+		})
 	})
 
 	// For versioned buckets, calculate total version size and count
 	if info.VersioningEnabled {
-		i.calculateVersionSizes(ctx, regionClient, bucket, info)
+		checks.run(CheckVersions, func(ctx context.Context) {
+			if i.inventoryVersions(bucket, info) {
+				return
+			}
+			i.calculateVersionSizes(ctx, regionClient, bucket, info)
+		})
 	}
 
 	return info
@@ -620,51 +962,12 @@ func (i *Inspector) inspectBucketFull(ctx context.Context, bucket, region string
 
 // calculateVersionSizes calculates total size of all versions in a bucket
 func (i *Inspector) calculateVersionSizes(ctx context.Context, client *Client, bucket string, info *BucketInfo) {
-	var totalVersionSize int64
-	var versionCount int
-	var keyMarker *string
-	var versionIDMarker *string
-
-	maxIterations := 100
-	iteration := 0
-
-	_ = client.WithRetry(ctx, func() error {
-		for iteration < maxIterations {
-			listVersionsResult, err := client.s3Client.ListObjectVersions(ctx, &s3.ListObjectVersionsInput{
-				Bucket:          aws.String(bucket),
-				MaxKeys:         aws.Int32(1000),
-				KeyMarker:       keyMarker,
-				VersionIdMarker: versionIDMarker,
-			})
-
-			if err != nil {
-				return err
-			}
-
-			if listVersionsResult.Versions != nil {
-				for _, version := range listVersionsResult.Versions {
-					if version.Size != nil {
-						totalVersionSize += *version.Size
-					}
-					versionCount++
-				}
-			}
-
-			if listVersionsResult.DeleteMarkers != nil {
-				versionCount += len(listVersionsResult.DeleteMarkers)
-			}
-
-			if listVersionsResult.IsTruncated != nil && *listVersionsResult.IsTruncated {
-				keyMarker = listVersionsResult.NextKeyMarker
-				versionIDMarker = listVersionsResult.NextVersionIdMarker
-				iteration++
-			} else {
-				break
-			}
-		}
-		return nil
-	})
-
+	totalVersionSize, versionCount, stats, err := countVersions(ctx, client, client.s3Client, bucket, MaxVersionPages, i.versionSampling)
+	// Partial totals would understate the versions
+	if err != nil || ctx.Err() != nil {
+		return
+	}
 	info.TotalVersionSize = totalVersionSize
 	info.VersionCount = versionCount
+	info.VersionStats = stats
 }
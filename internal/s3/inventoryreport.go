@@ -0,0 +1,345 @@
+package s3
+
+import (
+	"compress/gzip"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+// inventoryDatePattern matches the dated folder S3 Inventory writes each
+// report's manifest to, such as 2024-05-01T01-00Z/
+var inventoryDatePattern = regexp.MustCompile(`/\d{4}-\d{2}-\d{2}T\d{2}-\d{2}Z/$`)
+
+// inventoryAgeBands are the last-modified age bands of an inventory report,
+// by their upper bound in days; the last band is unbounded
+var inventoryAgeBands = []struct {
+	label string
+	days  int
+}{
+	{"<30d", 30}, {"30-90d", 90}, {"90-180d", 180}, {"180d-1y", 365}, {"1-2y", 730}, {">2y", 0},
+}
+
+// InventoryAPI is the subset of the S3 client used to read S3 Inventory
+// reports
+type InventoryAPI interface {
+	GetObject(ctx context.Context, params *s3.GetObjectInput, optFns ...func(*s3.Options)) (*s3.GetObjectOutput, error)
+	ListObjectsV2(ctx context.Context, params *s3.ListObjectsV2Input, optFns ...func(*s3.Options)) (*s3.ListObjectsV2Output, error)
+}
+
+// InventoryReport is a bucket's objects counted from an S3 Inventory report
+// rather than by listing them
+type InventoryReport struct {
+	// Manifest is the s3:// URL of the manifest that was read
+	Manifest string `json:"manifest"`
+	// SourceBucket is the bucket the inventory describes
+	SourceBucket string `json:"source_bucket"`
+	// Date is when the inventory was taken; objects written since are not
+	// counted
+	Date    time.Time `json:"date"`
+	Objects int       `json:"objects"`
+	Size    int64     `json:"size"`
+	// AllVersions is set when the inventory lists every object version
+	// rather than only the current ones
+	AllVersions        bool  `json:"all_versions"`
+	Versions           int   `json:"versions,omitempty"`
+	VersionSize        int64 `json:"version_size,omitempty"`
+	NoncurrentVersions int   `json:"noncurrent_versions,omitempty"`
+	NoncurrentSize     int64 `json:"noncurrent_size,omitempty"`
+	DeleteMarkers      int   `json:"delete_markers,omitempty"`
+	// LastModified is the newest LastModifiedDate of any version or delete
+	// marker; nil when the inventory does not include the field
+	LastModified *time.Time `json:"last_modified,omitempty"`
+	// Ages counts current objects by how long before Date they were last
+	// modified
+	Ages    []InventoryAge                `json:"ages,omitempty"`
+	Classes map[string]*StorageClassUsage `json:"storage_classes,omitempty"`
+}
+
+// InventoryAge is the current objects last modified within an age band
+type InventoryAge struct {
+	Band    string `json:"band"`
+	Objects int    `json:"objects"`
+	Size    int64  `json:"size"`
+}
+
+// inventoryManifest is the manifest.json S3 Inventory writes with each report
+type inventoryManifest struct {
+	SourceBucket      string `json:"sourceBucket"`
+	FileFormat        string `json:"fileFormat"`
+	FileSchema        string `json:"fileSchema"`
+	CreationTimestamp string `json:"creationTimestamp"`
+	Files             []struct {
+		Key string `json:"key"`
+	} `json:"files"`
+}
+
+// SetInventoryReports makes the activity and version checks of each
+// report's source bucket count objects from the report instead of listing
+// them
+func (i *Inspector) SetInventoryReports(reports map[string]*InventoryReport) {
+	i.inventoryReports = reports
+}
+
+// inventoryClient returns the client and API to read reports from a bucket,
+// in the bucket's region
+func (i *Inspector) inventoryClient(ctx context.Context, bucket string) (*Client, InventoryAPI, error) {
+	if i.newInventoryAPI != nil {
+		return i.client, i.newInventoryAPI(), nil
+	}
+	region, err := i.client.BucketRegion(ctx, bucket)
+	if err != nil {
+		return nil, nil, err
+	}
+	client := i.client
+	if region != client.GetRegion() {
+		client = client.ForRegion(region)
+	}
+	return client, client.s3Client, nil
+}
+
+// ReadInventoryReport reads an S3 Inventory report and counts its source
+// bucket's objects, sizes, versions and last-modified ages. The URL is a
+// manifest.json or the inventory configuration's folder, whose latest
+// report is read. Only CSV inventories can be read; the data files are
+// streamed, so reports of any size are counted in constant memory.
+func (i *Inspector) ReadInventoryReport(ctx context.Context, manifestURL string) (*InventoryReport, error) {
+	bucket, key, ok := strings.Cut(strings.TrimPrefix(manifestURL, "s3://"), "/")
+	if !strings.HasPrefix(manifestURL, "s3://") || !ok || bucket == "" {
+		return nil, fmt.Errorf("invalid inventory manifest %q: use s3://bucket/path/manifest.json", manifestURL)
+	}
+	client, api, err := i.inventoryClient(ctx, bucket)
+	if err != nil {
+		return nil, fmt.Errorf("failed to locate inventory bucket %s: %w", bucket, err)
+	}
+	if !strings.HasSuffix(key, "manifest.json") {
+		if key, err = latestInventoryManifest(ctx, client, api, bucket, key); err != nil {
+			return nil, err
+		}
+	}
+
+	var manifest inventoryManifest
+	err = readInventoryObject(ctx, client, api, bucket, key, func(body io.Reader) error {
+		return json.NewDecoder(body).Decode(&manifest)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read inventory manifest s3://%s/%s: %w", bucket, key, err)
+	}
+	if !strings.EqualFold(manifest.FileFormat, "CSV") {
+		return nil, fmt.Errorf("inventory s3://%s/%s is %s; only CSV inventories can be read", bucket, key, manifest.FileFormat)
+	}
+
+	schema := make(map[string]int)
+	for k, field := range strings.Split(manifest.FileSchema, ",") {
+		schema[strings.TrimSpace(field)] = k
+	}
+	if _, ok := schema["Size"]; !ok {
+		return nil, fmt.Errorf("inventory s3://%s/%s does not include the Size field", bucket, key)
+	}
+	report := &InventoryReport{
+		Manifest:     fmt.Sprintf("s3://%s/%s", bucket, key),
+		SourceBucket: manifest.SourceBucket,
+		Classes:      make(map[string]*StorageClassUsage),
+	}
+	if ms, err := strconv.ParseInt(manifest.CreationTimestamp, 10, 64); err == nil {
+		report.Date = time.UnixMilli(ms).UTC()
+	}
+	_, report.AllVersions = schema["VersionId"]
+	if _, ok := schema["LastModifiedDate"]; ok {
+		for _, band := range inventoryAgeBands {
+			report.Ages = append(report.Ages, InventoryAge{Band: band.label})
+		}
+	}
+
+	for _, file := range manifest.Files {
+		err := readInventoryObject(ctx, client, api, bucket, file.Key, func(body io.Reader) error {
+			gz, err := gzip.NewReader(body)
+			if err != nil {
+				return err
+			}
+			defer func() { _ = gz.Close() }()
+			return report.addRows(csv.NewReader(gz), schema)
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to read inventory file s3://%s/%s: %w", bucket, file.Key, err)
+		}
+	}
+	if len(report.Classes) == 0 {
+		report.Classes = nil
+	}
+	return report, nil
+}
+
+// addRows counts the rows of one inventory data file
+func (r *InventoryReport) addRows(reader *csv.Reader, schema map[string]int) error {
+	reader.FieldsPerRecord = -1
+	reader.ReuseRecord = true
+	field := func(record []string, name string) string {
+		if k, ok := schema[name]; ok && k < len(record) {
+			return record[k]
+		}
+		return ""
+	}
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		var modified time.Time
+		if value := field(record, "LastModifiedDate"); value != "" {
+			if modified, err = time.Parse(time.RFC3339, value); err != nil {
+				return fmt.Errorf("invalid LastModifiedDate %q: %w", value, err)
+			}
+			if r.LastModified == nil || modified.After(*r.LastModified) {
+				r.LastModified = &modified
+			}
+		}
+		if field(record, "IsDeleteMarker") == "true" {
+			r.DeleteMarkers++
+			continue
+		}
+		var size int64
+		if value := field(record, "Size"); value != "" {
+			if size, err = strconv.ParseInt(value, 10, 64); err != nil {
+				return fmt.Errorf("invalid Size %q: %w", value, err)
+			}
+		}
+		if r.AllVersions {
+			r.Versions++
+			r.VersionSize += size
+			if field(record, "IsLatest") == "false" {
+				r.NoncurrentVersions++
+				r.NoncurrentSize += size
+				continue
+			}
+		}
+
+		r.Objects++
+		r.Size += size
+		if class := field(record, "StorageClass"); class != "" {
+			usage := r.Classes[class]
+			if usage == nil {
+				usage = &StorageClassUsage{}
+				r.Classes[class] = usage
+			}
+			usage.Objects++
+			usage.Size += size
+		}
+		if len(r.Ages) > 0 && !modified.IsZero() {
+			age := r.Date.Sub(modified).Hours() / 24
+			for k, band := range inventoryAgeBands {
+				if band.days == 0 || age < float64(band.days) {
+					r.Ages[k].Objects++
+					r.Ages[k].Size += size
+					break
+				}
+			}
+		}
+	}
+}
+
+// latestInventoryManifest returns the manifest key of the newest report
+// under an inventory configuration's folder
+func latestInventoryManifest(ctx context.Context, client *Client, api InventoryAPI, bucket, folder string) (string, error) {
+	if folder != "" && !strings.HasSuffix(folder, "/") {
+		folder += "/"
+	}
+	var dates []string
+	input := &s3.ListObjectsV2Input{Bucket: aws.String(bucket), Prefix: aws.String(folder), Delimiter: aws.String("/")}
+	for {
+		var page *s3.ListObjectsV2Output
+		err := client.WithRetry(ctx, func() error {
+			var err error
+			page, err = api.ListObjectsV2(ctx, input)
+			return err
+		})
+		if err != nil {
+			return "", fmt.Errorf("failed to list inventory reports under s3://%s/%s: %w", bucket, folder, err)
+		}
+		for _, prefix := range page.CommonPrefixes {
+			if p := aws.ToString(prefix.Prefix); inventoryDatePattern.MatchString("/" + p) {
+				dates = append(dates, p)
+			}
+		}
+		if !aws.ToBool(page.IsTruncated) {
+			break
+		}
+		input.ContinuationToken = page.NextContinuationToken
+	}
+	if len(dates) == 0 {
+		return "", fmt.Errorf("no inventory reports under s3://%s/%s", bucket, folder)
+	}
+	// Dated folders sort chronologically
+	sort.Strings(dates)
+	return dates[len(dates)-1] + "manifest.json", nil
+}
+
+// readInventoryObject gets an object and passes its body to read
+func readInventoryObject(ctx context.Context, client *Client, api InventoryAPI, bucket, key string, read func(io.Reader) error) error {
+	var out *s3.GetObjectOutput
+	err := client.WithRetry(ctx, func() error {
+		var err error
+		out, err = api.GetObject(ctx, &s3.GetObjectInput{Bucket: aws.String(bucket), Key: aws.String(key)})
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	defer func() { _ = out.Body.Close() }()
+	return read(out.Body)
+}
+
+// inventoryActivity sets a bucket's size and activity from its inventory
+// report and reports whether it had one
+func (i *Inspector) inventoryActivity(bucket string, info *BucketInfo) bool {
+	report := i.inventoryReports[bucket]
+	if report == nil {
+		return false
+	}
+	info.Inventory = report
+	info.ObjectCount = report.Objects
+	info.TotalSize = report.Size
+	info.IsEmpty = report.Objects == 0
+	if report.Classes != nil {
+		info.SizeBreakdown = &SizeBreakdown{Objects: report.Objects, Size: report.Size, Classes: report.Classes}
+	}
+	if report.LastModified != nil {
+		info.LastActivity = report.LastModified
+		info.DaysSinceActivity = int(time.Since(*report.LastModified).Hours() / 24)
+	}
+	return true
+}
+
+// inventoryVersions sets a bucket's version totals from its inventory
+// report and reports whether the report lists every version
+func (i *Inspector) inventoryVersions(bucket string, info *BucketInfo) bool {
+	report := i.inventoryReports[bucket]
+	if report == nil || !report.AllVersions {
+		return false
+	}
+	info.VersionCount = report.Versions
+	info.TotalVersionSize = report.VersionSize
+	info.VersionStats = &VersionStats{
+		NoncurrentVersions: report.NoncurrentVersions,
+		NoncurrentSize:     report.NoncurrentSize,
+	}
+	if report.VersionSize > 0 {
+		info.VersionStats.OverheadRatio = float64(report.NoncurrentSize) / float64(report.VersionSize)
+	}
+	return true
+}
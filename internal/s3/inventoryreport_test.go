@@ -0,0 +1,136 @@
+package s3
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+// fakeInventoryAPI serves objects by key and lists common prefixes
+type fakeInventoryAPI struct {
+	objects  map[string][]byte
+	prefixes []string
+	gets     []string
+}
+
+func (f *fakeInventoryAPI) GetObject(_ context.Context, params *s3.GetObjectInput, _ ...func(*s3.Options)) (*s3.GetObjectOutput, error) {
+	key := aws.ToString(params.Key)
+	f.gets = append(f.gets, key)
+	data, ok := f.objects[key]
+	if !ok {
+		return nil, errors.New("NoSuchKey")
+	}
+	return &s3.GetObjectOutput{Body: io.NopCloser(bytes.NewReader(data))}, nil
+}
+
+func (f *fakeInventoryAPI) ListObjectsV2(_ context.Context, params *s3.ListObjectsV2Input, _ ...func(*s3.Options)) (*s3.ListObjectsV2Output, error) {
+	out := &s3.ListObjectsV2Output{}
+	for _, p := range f.prefixes {
+		if strings.HasPrefix(p, aws.ToString(params.Prefix)) {
+			out.CommonPrefixes = append(out.CommonPrefixes, types.CommonPrefix{Prefix: aws.String(p)})
+		}
+	}
+	return out, nil
+}
+
+func gzipped(t *testing.T, text string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	w := gzip.NewWriter(&buf)
+	if _, err := w.Write([]byte(text)); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestReadInventoryReport(t *testing.T) {
+	taken := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	manifest := `{
+  "sourceBucket": "media-prod",
+  "destinationBucket": "arn:aws:s3:::inventory-dest",
+  "fileFormat": "CSV",
+  "fileSchema": "Bucket, Key, VersionId, IsLatest, IsDeleteMarker, Size, LastModifiedDate, StorageClass",
+  "creationTimestamp": "1717200000000",
+  "files": [{"key": "media-prod/all/data/a.csv.gz"}, {"key": "media-prod/all/data/b.csv.gz"}]
+}`
+	api := &fakeInventoryAPI{
+		prefixes: []string{"media-prod/all/2024-05-31T01-00Z/", "media-prod/all/2024-06-01T01-00Z/", "media-prod/all/data/"},
+		objects: map[string][]byte{
+			"media-prod/all/2024-06-01T01-00Z/manifest.json": []byte(manifest),
+			"media-prod/all/data/a.csv.gz": gzipped(t, `"media-prod","a.jpg","v2","true","false","100","2024-05-20T10:00:00.000Z","STANDARD"
+"media-prod","a.jpg","v1","false","false","80","2023-01-01T10:00:00.000Z","STANDARD"
+`),
+			"media-prod/all/data/b.csv.gz": gzipped(t, `"media-prod","b.jpg","v3","true","false","1000","2021-03-01T00:00:00.000Z","GLACIER"
+"media-prod","c.jpg","v5","true","true","","2024-05-30T00:00:00.000Z",""
+`),
+		},
+	}
+	inspector := NewInspector(&Client{config: aws.Config{Region: "us-east-1"}}, 1)
+	inspector.newInventoryAPI = func() InventoryAPI { return api }
+
+	report, err := inspector.ReadInventoryReport(context.Background(), "s3://inventory-dest/media-prod/all")
+	if err != nil {
+		t.Fatalf("ReadInventoryReport failed: %v", err)
+	}
+	if report.Manifest != "s3://inventory-dest/media-prod/all/2024-06-01T01-00Z/manifest.json" {
+		t.Errorf("latest manifest not chosen: %s", report.Manifest)
+	}
+	if report.SourceBucket != "media-prod" || !report.Date.Equal(taken) {
+		t.Errorf("source %q, date %v", report.SourceBucket, report.Date)
+	}
+	if report.Objects != 2 || report.Size != 1100 {
+		t.Errorf("objects %d, size %d; want 2, 1100", report.Objects, report.Size)
+	}
+	if !report.AllVersions || report.Versions != 3 || report.VersionSize != 1180 ||
+		report.NoncurrentVersions != 1 || report.NoncurrentSize != 80 || report.DeleteMarkers != 1 {
+		t.Errorf("version totals = %+v", report)
+	}
+	if want := time.Date(2024, 5, 30, 0, 0, 0, 0, time.UTC); report.LastModified == nil || !report.LastModified.Equal(want) {
+		t.Errorf("last modified = %v, want the delete marker's %v", report.LastModified, want)
+	}
+	if report.Ages[0].Objects != 1 || report.Ages[0].Size != 100 || report.Ages[5].Objects != 1 {
+		t.Errorf("ages = %+v", report.Ages)
+	}
+	if report.Classes["GLACIER"] == nil || report.Classes["GLACIER"].Size != 1000 {
+		t.Errorf("classes = %+v", report.Classes)
+	}
+
+	info := &BucketInfo{Name: "media-prod"}
+	inspector.SetInventoryReports(map[string]*InventoryReport{"media-prod": report})
+	if !inspector.inventoryActivity("media-prod", info) || !inspector.inventoryVersions("media-prod", info) {
+		t.Fatalf("report not applied")
+	}
+	if info.ObjectCount != 2 || info.VersionCount != 3 || info.SizeBreakdown == nil || info.VersionStats.NoncurrentSize != 80 {
+		t.Errorf("bucket = %+v", info)
+	}
+	if inspector.inventoryActivity("other", &BucketInfo{}) {
+		t.Errorf("bucket without a report should be listed")
+	}
+}
+
+func TestReadInventoryReport_Unsupported(t *testing.T) {
+	api := &fakeInventoryAPI{objects: map[string][]byte{
+		"inv/manifest.json": []byte(`{"sourceBucket": "logs", "fileFormat": "Parquet", "fileSchema": "message x {}"}`),
+	}}
+	inspector := NewInspector(&Client{config: aws.Config{Region: "us-east-1"}}, 1)
+	inspector.newInventoryAPI = func() InventoryAPI { return api }
+
+	if _, err := inspector.ReadInventoryReport(context.Background(), "s3://dest/inv/manifest.json"); err == nil || !strings.Contains(err.Error(), "only CSV") {
+		t.Errorf("expected an unsupported format error, got %v", err)
+	}
+	if _, err := inspector.ReadInventoryReport(context.Background(), "dest/inv/manifest.json"); err == nil {
+		t.Errorf("expected an error for a URL without s3://")
+	}
+}
@@ -0,0 +1,304 @@
+package s3
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudtrail"
+	cloudtrailtypes "github.com/aws/aws-sdk-go-v2/service/cloudtrail/types"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+// s3ObjectResource is the CloudTrail resource type of S3 object data events
+const s3ObjectResource = "AWS::S3::Object"
+
+// LoggingAPI is the subset of the S3 client used to read a bucket's server
+// access logging
+type LoggingAPI interface {
+	GetBucketLogging(ctx context.Context, params *s3.GetBucketLoggingInput, optFns ...func(*s3.Options)) (*s3.GetBucketLoggingOutput, error)
+}
+
+// TrailsAPI is the subset of the CloudTrail client used to read which
+// trails log S3 data events
+type TrailsAPI interface {
+	DescribeTrails(ctx context.Context, params *cloudtrail.DescribeTrailsInput, optFns ...func(*cloudtrail.Options)) (*cloudtrail.DescribeTrailsOutput, error)
+	GetEventSelectors(ctx context.Context, params *cloudtrail.GetEventSelectorsInput, optFns ...func(*cloudtrail.Options)) (*cloudtrail.GetEventSelectorsOutput, error)
+	GetTrailStatus(ctx context.Context, params *cloudtrail.GetTrailStatusInput, optFns ...func(*cloudtrail.Options)) (*cloudtrail.GetTrailStatusOutput, error)
+}
+
+// LoggingInfo is how requests to a bucket are recorded: S3 server access
+// logs, or object-level data events of a CloudTrail trail
+type LoggingInfo struct {
+	AccessLogging bool   `json:"access_logging"`
+	TargetBucket  string `json:"target_bucket,omitempty"`
+	TargetPrefix  string `json:"target_prefix,omitempty"`
+	// DataEventsTrail names a trail logging the bucket's data events
+	DataEventsTrail string `json:"data_events_trail,omitempty"`
+	// TrailsChecked is set when the region's trails were read. They are
+	// only read for buckets without server access logging.
+	TrailsChecked bool `json:"trails_checked"`
+}
+
+// Logged reports whether the bucket's requests are recorded either way
+func (l *LoggingInfo) Logged() bool {
+	return l != nil && (l.AccessLogging || l.DataEventsTrail != "")
+}
+
+// trailCoverage is which buckets one trail logs S3 data events for: all of
+// them, named buckets, or buckets whose names start with a prefix
+type trailCoverage struct {
+	name     string
+	all      bool
+	buckets  map[string]bool
+	prefixes []string
+}
+
+// covers reports whether the trail logs the bucket's data events
+func (t trailCoverage) covers(bucket string) bool {
+	if t.all || t.buckets[bucket] {
+		return true
+	}
+	for _, prefix := range t.prefixes {
+		if strings.HasPrefix(bucket, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// regionTrails are the trails logging one region, read once per run
+type regionTrails struct {
+	mu     sync.Mutex
+	read   bool
+	trails []trailCoverage
+	err    error
+}
+
+// SetCheckLogging makes inspection read each bucket's server access logging
+// and, for buckets without it, the CloudTrail trails logging their data
+// events into BucketInfo.Logging
+func (i *Inspector) SetCheckLogging(enabled bool) {
+	i.checkLogging = enabled
+}
+
+// loggingClient returns the client that reads a bucket's access logging
+func (i *Inspector) loggingClient(client *Client) LoggingAPI {
+	if i.newLoggingAPI != nil {
+		return i.newLoggingAPI(client.GetRegion())
+	}
+	return client.s3Client
+}
+
+// trailsClient returns the CloudTrail client for a region
+func (i *Inspector) trailsClient(region string) TrailsAPI {
+	if i.newTrailsAPI != nil {
+		return i.newTrailsAPI(region)
+	}
+	cfg := i.client.config.Copy()
+	cfg.Region = region
+	return cloudtrail.NewFromConfig(cfg)
+}
+
+// readLogging sets info.Logging from the bucket's access logging and the
+// data event selectors of the trails in its region. A failed access logging
+// call leaves info.Logging nil.
+func (i *Inspector) readLogging(ctx context.Context, client *Client, bucket string, info *BucketInfo) {
+	api := i.loggingClient(client)
+	logging := &LoggingInfo{}
+	err := client.WithRetry(ctx, func() error {
+		out, err := api.GetBucketLogging(ctx, &s3.GetBucketLoggingInput{Bucket: aws.String(bucket)})
+		if err == nil && out.LoggingEnabled != nil {
+			logging.AccessLogging = true
+			logging.TargetBucket = aws.ToString(out.LoggingEnabled.TargetBucket)
+			logging.TargetPrefix = aws.ToString(out.LoggingEnabled.TargetPrefix)
+		}
+		return err
+	})
+	if err != nil || ctx.Err() != nil {
+		return
+	}
+	if !logging.AccessLogging {
+		trails, err := i.regionTrails(ctx, client.GetRegion())
+		if ctx.Err() != nil {
+			return
+		}
+		logging.TrailsChecked = err == nil
+		for _, trail := range trails {
+			if trail.covers(bucket) {
+				logging.DataEventsTrail = trail.name
+				break
+			}
+		}
+	}
+	info.Logging = logging
+}
+
+// regionTrails returns the trails logging a region's S3 data events. The
+// trails are read on first use and shared by the region's buckets.
+func (i *Inspector) regionTrails(ctx context.Context, region string) ([]trailCoverage, error) {
+	i.trailsMu.Lock()
+	if i.trails == nil {
+		i.trails = make(map[string]*regionTrails)
+	}
+	rt, ok := i.trails[region]
+	if !ok {
+		rt = &regionTrails{}
+		i.trails[region] = rt
+	}
+	i.trailsMu.Unlock()
+
+	rt.mu.Lock()
+	defer rt.mu.Unlock()
+	if !rt.read {
+		trails, err := i.readRegionTrails(ctx, region)
+		// A bucket cancelled at its deadline leaves the read to the next
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		rt.trails, rt.err, rt.read = trails, err, true
+	}
+	return rt.trails, rt.err
+}
+
+// readRegionTrails lists the trails that log a region, including
+// multi-region and organization trails created elsewhere, and reads the
+// event selectors of those with custom selectors from their home region.
+// Trails that are not logging are left out.
+func (i *Inspector) readRegionTrails(ctx context.Context, region string) ([]trailCoverage, error) {
+	var described *cloudtrail.DescribeTrailsOutput
+	err := i.client.WithRetry(ctx, func() error {
+		var err error
+		described, err = i.trailsClient(region).DescribeTrails(ctx, &cloudtrail.DescribeTrailsInput{IncludeShadowTrails: aws.Bool(true)})
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to describe CloudTrail trails in %s: %w", region, err)
+	}
+
+	var trails []trailCoverage
+	for _, trail := range described.TrailList {
+		// Trails without custom selectors log management events only
+		if !aws.ToBool(trail.HasCustomEventSelectors) {
+			continue
+		}
+		home := aws.ToString(trail.HomeRegion)
+		if home == "" {
+			home = region
+		}
+		var selectors *cloudtrail.GetEventSelectorsOutput
+		err := i.client.WithRetry(ctx, func() error {
+			var err error
+			selectors, err = i.trailsClient(home).GetEventSelectors(ctx, &cloudtrail.GetEventSelectorsInput{TrailName: trail.TrailARN})
+			return err
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to read event selectors of trail %s: %w", aws.ToString(trail.Name), err)
+		}
+		coverage := parseEventSelectors(selectors)
+		coverage.name = aws.ToString(trail.Name)
+		if !coverage.all && len(coverage.buckets) == 0 && len(coverage.prefixes) == 0 {
+			continue
+		}
+		var status *cloudtrail.GetTrailStatusOutput
+		err = i.client.WithRetry(ctx, func() error {
+			var err error
+			status, err = i.trailsClient(home).GetTrailStatus(ctx, &cloudtrail.GetTrailStatusInput{Name: trail.TrailARN})
+			return err
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to read status of trail %s: %w", aws.ToString(trail.Name), err)
+		}
+		if aws.ToBool(status.IsLogging) {
+			trails = append(trails, coverage)
+		}
+	}
+	return trails, nil
+}
+
+// parseEventSelectors reads which buckets a trail's basic or advanced event
+// selectors log S3 object data events for. Advanced selectors that exclude
+// ARNs are taken to cover every bucket, so an exclusion never produces a
+// missing-logging finding.
+func parseEventSelectors(out *cloudtrail.GetEventSelectorsOutput) trailCoverage {
+	coverage := trailCoverage{buckets: make(map[string]bool)}
+	for _, selector := range out.EventSelectors {
+		for _, resource := range selector.DataResources {
+			if aws.ToString(resource.Type) != s3ObjectResource {
+				continue
+			}
+			for _, value := range resource.Values {
+				coverage.addARN(value)
+			}
+		}
+	}
+	for _, selector := range out.AdvancedEventSelectors {
+		coverage.addAdvanced(selector)
+	}
+	return coverage
+}
+
+// addAdvanced adds the buckets of an advanced event selector that selects
+// S3 object data events
+func (t *trailCoverage) addAdvanced(selector cloudtrailtypes.AdvancedEventSelector) {
+	var data, objects bool
+	var arns []cloudtrailtypes.AdvancedFieldSelector
+	for _, field := range selector.FieldSelectors {
+		switch aws.ToString(field.Field) {
+		case "eventCategory":
+			data = slices.Contains(field.Equals, "Data")
+		case "resources.type":
+			objects = slices.Contains(field.Equals, s3ObjectResource)
+		case "resources.ARN":
+			arns = append(arns, field)
+		}
+	}
+	if !data || !objects {
+		return
+	}
+	if len(arns) == 0 {
+		t.all = true
+		return
+	}
+	for _, field := range arns {
+		if len(field.NotEquals) > 0 || len(field.NotStartsWith) > 0 || len(field.NotEndsWith) > 0 || len(field.EndsWith) > 0 {
+			t.all = true
+			return
+		}
+		for _, value := range field.Equals {
+			t.addARN(value)
+		}
+		for _, value := range field.StartsWith {
+			t.addARNPrefix(value)
+		}
+	}
+}
+
+// addARNPrefix adds the buckets an ARN prefix selects. A prefix reaching
+// past the bucket name, arn:aws:s3:::orders/, selects that bucket; a
+// shorter one, arn:aws:s3:::logs-, every bucket whose name starts with it.
+func (t *trailCoverage) addARNPrefix(prefix string) {
+	_, rest, found := strings.Cut(prefix, ":::")
+	if !found || strings.Contains(rest, "/") || rest == "" {
+		t.addARN(prefix)
+		return
+	}
+	t.prefixes = append(t.prefixes, rest)
+}
+
+// addARN adds the bucket of an S3 ARN such as arn:aws:s3:::bucket/prefix.
+// An ARN without a bucket, arn:aws:s3 or arn:aws:s3:::, selects them all.
+func (t *trailCoverage) addARN(arn string) {
+	_, rest, found := strings.Cut(arn, ":::")
+	bucket, _, _ := strings.Cut(rest, "/")
+	if !found || bucket == "" {
+		if strings.HasPrefix(arn, "arn:") && strings.Contains(arn, ":s3") {
+			t.all = true
+		}
+		return
+	}
+	t.buckets[bucket] = true
+}
@@ -0,0 +1,210 @@
+package s3
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudtrail"
+	cloudtrailtypes "github.com/aws/aws-sdk-go-v2/service/cloudtrail/types"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+// fakeLoggingAPI returns the access logging of the buckets in targets
+type fakeLoggingAPI struct {
+	targets map[string]string
+	err     error
+}
+
+func (f *fakeLoggingAPI) GetBucketLogging(_ context.Context, params *s3.GetBucketLoggingInput, _ ...func(*s3.Options)) (*s3.GetBucketLoggingOutput, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	out := &s3.GetBucketLoggingOutput{}
+	if target, ok := f.targets[aws.ToString(params.Bucket)]; ok {
+		out.LoggingEnabled = &types.LoggingEnabled{TargetBucket: aws.String(target), TargetPrefix: aws.String("logs/")}
+	}
+	return out, nil
+}
+
+// fakeTrailsAPI returns fixed trails and their event selectors by ARN.
+// Trails are logging unless listed in stopped.
+type fakeTrailsAPI struct {
+	trails    []cloudtrailtypes.Trail
+	selectors map[string]*cloudtrail.GetEventSelectorsOutput
+	stopped   map[string]bool
+	err       error
+	describes int
+}
+
+func (f *fakeTrailsAPI) DescribeTrails(_ context.Context, _ *cloudtrail.DescribeTrailsInput, _ ...func(*cloudtrail.Options)) (*cloudtrail.DescribeTrailsOutput, error) {
+	f.describes++
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &cloudtrail.DescribeTrailsOutput{TrailList: f.trails}, nil
+}
+
+func (f *fakeTrailsAPI) GetEventSelectors(_ context.Context, params *cloudtrail.GetEventSelectorsInput, _ ...func(*cloudtrail.Options)) (*cloudtrail.GetEventSelectorsOutput, error) {
+	return f.selectors[aws.ToString(params.TrailName)], nil
+}
+
+func (f *fakeTrailsAPI) GetTrailStatus(_ context.Context, params *cloudtrail.GetTrailStatusInput, _ ...func(*cloudtrail.Options)) (*cloudtrail.GetTrailStatusOutput, error) {
+	return &cloudtrail.GetTrailStatusOutput{IsLogging: aws.Bool(!f.stopped[aws.ToString(params.Name)])}, nil
+}
+
+func TestReadLogging(t *testing.T) {
+	client := &Client{config: aws.Config{Region: "us-east-1"}}
+	inspector := NewInspector(client, 1)
+	inspector.newLoggingAPI = func(string) LoggingAPI {
+		return &fakeLoggingAPI{targets: map[string]string{"orders": "access-logs"}}
+	}
+	trails := &fakeTrailsAPI{
+		trails: []cloudtrailtypes.Trail{
+			{Name: aws.String("management"), TrailARN: aws.String("arn:trail/management"), HasCustomEventSelectors: aws.Bool(false)},
+			{Name: aws.String("data"), TrailARN: aws.String("arn:trail/data"), HomeRegion: aws.String("eu-west-1"), HasCustomEventSelectors: aws.Bool(true)},
+			{Name: aws.String("stopped"), TrailARN: aws.String("arn:trail/stopped"), HasCustomEventSelectors: aws.Bool(true)},
+		},
+		selectors: map[string]*cloudtrail.GetEventSelectorsOutput{
+			"arn:trail/data": {EventSelectors: []cloudtrailtypes.EventSelector{{
+				DataResources: []cloudtrailtypes.DataResource{{Type: aws.String("AWS::S3::Object"), Values: []string{"arn:aws:s3:::payments/"}}},
+			}}},
+			"arn:trail/stopped": {EventSelectors: []cloudtrailtypes.EventSelector{{
+				DataResources: []cloudtrailtypes.DataResource{{Type: aws.String("AWS::S3::Object"), Values: []string{"arn:aws:s3:::scratch/"}}},
+			}}},
+		},
+		stopped: map[string]bool{"arn:trail/stopped": true},
+	}
+	inspector.newTrailsAPI = func(string) TrailsAPI { return trails }
+
+	info := &BucketInfo{}
+	inspector.readLogging(context.Background(), client, "orders", info)
+	if !info.Logging.Logged() || info.Logging.TargetBucket != "access-logs" || info.Logging.TrailsChecked {
+		t.Errorf("logging = %+v, want access logging without reading trails", info.Logging)
+	}
+
+	info = &BucketInfo{}
+	inspector.readLogging(context.Background(), client, "payments", info)
+	if info.Logging.DataEventsTrail != "data" || !info.Logging.TrailsChecked || !info.Logging.Logged() {
+		t.Errorf("logging = %+v, want data events from trail data", info.Logging)
+	}
+
+	info = &BucketInfo{}
+	inspector.readLogging(context.Background(), client, "scratch", info)
+	if info.Logging.Logged() || !info.Logging.TrailsChecked {
+		t.Errorf("logging = %+v, want no logging", info.Logging)
+	}
+	if trails.describes != 1 {
+		t.Errorf("DescribeTrails called %d times, want once per region", trails.describes)
+	}
+}
+
+func TestReadLogging_Errors(t *testing.T) {
+	client := &Client{config: aws.Config{Region: "us-east-1"}}
+	inspector := NewInspector(client, 1)
+	inspector.newLoggingAPI = func(string) LoggingAPI { return &fakeLoggingAPI{err: errors.New("AccessDenied")} }
+
+	info := &BucketInfo{}
+	inspector.readLogging(context.Background(), client, "denied", info)
+	if info.Logging != nil {
+		t.Errorf("a failed call should leave no result, got %+v", info.Logging)
+	}
+
+	inspector.newLoggingAPI = func(string) LoggingAPI { return &fakeLoggingAPI{} }
+	inspector.newTrailsAPI = func(string) TrailsAPI { return &fakeTrailsAPI{err: errors.New("AccessDenied")} }
+	inspector.readLogging(context.Background(), client, "unknown", info)
+	if info.Logging == nil || info.Logging.TrailsChecked || info.Logging.Logged() {
+		t.Errorf("logging = %+v, want unchecked trails", info.Logging)
+	}
+}
+
+func TestParseEventSelectors(t *testing.T) {
+	field := func(name string, equals ...string) cloudtrailtypes.AdvancedFieldSelector {
+		return cloudtrailtypes.AdvancedFieldSelector{Field: aws.String(name), Equals: equals}
+	}
+	dataEvents := []cloudtrailtypes.AdvancedFieldSelector{
+		field("eventCategory", "Data"),
+		field("resources.type", "AWS::S3::Object"),
+	}
+	tests := []struct {
+		name    string
+		out     *cloudtrail.GetEventSelectorsOutput
+		all     bool
+		buckets []string
+		// covered are buckets matched by a bucket name prefix
+		covered []string
+	}{
+		{
+			name: "basic selector for all buckets",
+			out: &cloudtrail.GetEventSelectorsOutput{EventSelectors: []cloudtrailtypes.EventSelector{{
+				DataResources: []cloudtrailtypes.DataResource{{Type: aws.String("AWS::S3::Object"), Values: []string{"arn:aws:s3"}}},
+			}}},
+			all: true,
+		},
+		{
+			name: "basic selector for Lambda only",
+			out: &cloudtrail.GetEventSelectorsOutput{EventSelectors: []cloudtrailtypes.EventSelector{{
+				DataResources: []cloudtrailtypes.DataResource{{Type: aws.String("AWS::Lambda::Function"), Values: []string{"arn:aws:lambda"}}},
+			}}},
+		},
+		{
+			name: "advanced selector without ARNs",
+			out:  &cloudtrail.GetEventSelectorsOutput{AdvancedEventSelectors: []cloudtrailtypes.AdvancedEventSelector{{FieldSelectors: dataEvents}}},
+			all:  true,
+		},
+		{
+			name: "advanced selector for named buckets",
+			out: &cloudtrail.GetEventSelectorsOutput{AdvancedEventSelectors: []cloudtrailtypes.AdvancedEventSelector{{
+				FieldSelectors: append(append([]cloudtrailtypes.AdvancedFieldSelector{}, dataEvents...), cloudtrailtypes.AdvancedFieldSelector{
+					Field:      aws.String("resources.ARN"),
+					StartsWith: []string{"arn:aws:s3:::orders/", "arn:aws:s3:::ledger/2024/"},
+				}),
+			}}},
+			buckets: []string{"orders", "ledger"},
+		},
+		{
+			name: "advanced selector for a bucket name prefix",
+			out: &cloudtrail.GetEventSelectorsOutput{AdvancedEventSelectors: []cloudtrailtypes.AdvancedEventSelector{{
+				FieldSelectors: append(append([]cloudtrailtypes.AdvancedFieldSelector{}, dataEvents...), cloudtrailtypes.AdvancedFieldSelector{
+					Field:      aws.String("resources.ARN"),
+					StartsWith: []string{"arn:aws:s3:::logs-"},
+				}),
+			}}},
+			covered: []string{"logs-app", "logs-web"},
+		},
+		{
+			name: "advanced selector excluding a bucket",
+			out: &cloudtrail.GetEventSelectorsOutput{AdvancedEventSelectors: []cloudtrailtypes.AdvancedEventSelector{{
+				FieldSelectors: append(append([]cloudtrailtypes.AdvancedFieldSelector{}, dataEvents...), cloudtrailtypes.AdvancedFieldSelector{
+					Field:         aws.String("resources.ARN"),
+					NotStartsWith: []string{"arn:aws:s3:::noisy/"},
+				}),
+			}}},
+			all: true,
+		},
+		{
+			name: "advanced selector for management events",
+			out: &cloudtrail.GetEventSelectorsOutput{AdvancedEventSelectors: []cloudtrailtypes.AdvancedEventSelector{{
+				FieldSelectors: []cloudtrailtypes.AdvancedFieldSelector{field("eventCategory", "Management")},
+			}}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := parseEventSelectors(tt.out)
+			if got.all != tt.all || len(got.buckets) != len(tt.buckets) {
+				t.Fatalf("got all=%v buckets=%v, want all=%v buckets=%v", got.all, got.buckets, tt.all, tt.buckets)
+			}
+			for _, bucket := range append(append([]string{}, tt.buckets...), tt.covered...) {
+				if !got.covers(bucket) {
+					t.Errorf("expected %s to be covered", bucket)
+				}
+			}
+			if len(tt.covered) > 0 && got.covers("app-logs") {
+				t.Errorf("expected a bucket outside the prefix not to be covered")
+			}
+		})
+	}
+}
@@ -0,0 +1,209 @@
+package s3
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/macie2"
+	macietypes "github.com/aws/aws-sdk-go-v2/service/macie2/types"
+)
+
+// macieFindingsBatch is the most findings GetFindings returns per call
+const macieFindingsBatch = 50
+
+// MacieAPI is the subset of the Macie client used to read sensitive data
+// findings
+type MacieAPI interface {
+	GetMacieSession(ctx context.Context, params *macie2.GetMacieSessionInput, optFns ...func(*macie2.Options)) (*macie2.GetMacieSessionOutput, error)
+	ListFindings(ctx context.Context, params *macie2.ListFindingsInput, optFns ...func(*macie2.Options)) (*macie2.ListFindingsOutput, error)
+	GetFindings(ctx context.Context, params *macie2.GetFindingsInput, optFns ...func(*macie2.Options)) (*macie2.GetFindingsOutput, error)
+}
+
+// SensitiveData is what Macie's classification findings found in a bucket
+type SensitiveData struct {
+	// Classes are the sensitive data categories found, such as
+	// PERSONAL_INFORMATION, FINANCIAL_INFORMATION and CREDENTIALS
+	Classes []string `json:"classes"`
+	// Types are the managed data identifiers that matched, such as
+	// CREDIT_CARD_NUMBER or AWS_CREDENTIALS
+	Types []string `json:"types,omitempty"`
+	// Findings counts the bucket's unarchived classification findings
+	Findings int `json:"findings"`
+	// Severity is the highest severity Macie gave them: Low, Medium or High
+	Severity string `json:"severity,omitempty"`
+	// Jobs are the classification jobs that produced the findings; automated
+	// sensitive data discovery has none
+	Jobs []string `json:"jobs,omitempty"`
+}
+
+// MacieAnalysis is the outcome of reading Macie findings
+type MacieAnalysis struct {
+	// Buckets maps bucket names to what Macie found in them
+	Buckets map[string]*SensitiveData
+	// accounts records the account of each bucket's findings
+	accounts map[string]string
+	// Disabled lists the scanned regions where Macie is not enabled
+	Disabled []string
+}
+
+// macieSeverityRank orders Macie severities
+var macieSeverityRank = map[string]int{"Low": 1, "Medium": 2, "High": 3}
+
+// macieClient returns the Macie client for a region
+func (i *Inspector) macieClient(region string) MacieAPI {
+	if i.newMacieAPI != nil {
+		return i.newMacieAPI(region)
+	}
+	cfg := i.client.config.Copy()
+	cfg.Region = region
+	return macie2.NewFromConfig(cfg)
+}
+
+// MacieSensitiveData reads the unarchived sensitive data findings of Macie
+// classification jobs and automated discovery in each scanned region, and
+// gathers them per bucket. Regions where Macie is not enabled, or whose
+// session cannot be read, are listed as disabled.
+func (i *Inspector) MacieSensitiveData(ctx context.Context) (*MacieAnalysis, error) {
+	regions, err := i.serviceRegions(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to determine regions: %w", err)
+	}
+
+	analysis := &MacieAnalysis{
+		Buckets:  make(map[string]*SensitiveData),
+		accounts: make(map[string]string),
+	}
+	for _, region := range regions {
+		api := i.macieClient(region)
+		var session *macie2.GetMacieSessionOutput
+		err := i.client.WithRetry(ctx, func() error {
+			var err error
+			session, err = api.GetMacieSession(ctx, &macie2.GetMacieSessionInput{})
+			return err
+		})
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		if err != nil || session.Status != macietypes.MacieStatusEnabled {
+			analysis.Disabled = append(analysis.Disabled, region)
+			continue
+		}
+		ids, err := i.macieFindingIDs(ctx, api, region)
+		if err != nil {
+			return nil, err
+		}
+		for start := 0; start < len(ids); start += macieFindingsBatch {
+			batch := ids[start:min(start+macieFindingsBatch, len(ids))]
+			var out *macie2.GetFindingsOutput
+			err := i.client.WithRetry(ctx, func() error {
+				var err error
+				out, err = api.GetFindings(ctx, &macie2.GetFindingsInput{FindingIds: batch})
+				return err
+			})
+			if err != nil {
+				return nil, fmt.Errorf("failed to read Macie findings in %s: %w", region, err)
+			}
+			for k := range out.Findings {
+				analysis.add(&out.Findings[k])
+			}
+		}
+	}
+	for _, data := range analysis.Buckets {
+		sort.Strings(data.Classes)
+		sort.Strings(data.Types)
+		sort.Strings(data.Jobs)
+	}
+	return analysis, nil
+}
+
+// macieFindingIDs lists the IDs of a region's unarchived classification
+// findings
+func (i *Inspector) macieFindingIDs(ctx context.Context, api MacieAPI, region string) ([]string, error) {
+	var ids []string
+	paginator := macie2.NewListFindingsPaginator(api, &macie2.ListFindingsInput{
+		FindingCriteria: &macietypes.FindingCriteria{Criterion: map[string]macietypes.CriterionAdditionalProperties{
+			"category": {Eq: []string{string(macietypes.FindingCategoryClassification)}},
+			"archived": {Eq: []string{"false"}},
+		}},
+	})
+	for paginator.HasMorePages() {
+		var page *macie2.ListFindingsOutput
+		err := i.client.WithRetry(ctx, func() error {
+			var err error
+			page, err = paginator.NextPage(ctx)
+			return err
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to list Macie findings in %s: %w", region, err)
+		}
+		ids = append(ids, page.FindingIds...)
+	}
+	return ids, nil
+}
+
+// add merges one classification finding into its bucket's sensitive data
+func (a *MacieAnalysis) add(finding *macietypes.Finding) {
+	if finding.ResourcesAffected == nil || finding.ResourcesAffected.S3Bucket == nil || finding.ClassificationDetails == nil {
+		return
+	}
+	bucket := aws.ToString(finding.ResourcesAffected.S3Bucket.Name)
+	if bucket == "" {
+		return
+	}
+	data := a.Buckets[bucket]
+	if data == nil {
+		data = &SensitiveData{}
+		a.Buckets[bucket] = data
+		a.accounts[bucket] = aws.ToString(finding.AccountId)
+	}
+	data.Findings++
+	if finding.Severity != nil {
+		severity := string(finding.Severity.Description)
+		if macieSeverityRank[severity] > macieSeverityRank[data.Severity] {
+			data.Severity = severity
+		}
+	}
+	if job := aws.ToString(finding.ClassificationDetails.JobId); job != "" {
+		data.Jobs = appendUnique(data.Jobs, job)
+	}
+	if result := finding.ClassificationDetails.Result; result != nil {
+		for _, item := range result.SensitiveData {
+			data.Classes = appendUnique(data.Classes, string(item.Category))
+			for _, detection := range item.Detections {
+				if t := aws.ToString(detection.Type); t != "" {
+					data.Types = appendUnique(data.Types, t)
+				}
+			}
+		}
+	}
+}
+
+// appendUnique appends value unless the list already holds it
+func appendUnique(list []string, value string) []string {
+	if slices.Contains(list, value) {
+		return list
+	}
+	return append(list, value)
+}
+
+// ApplySensitiveData records what Macie found in each bucket. With an
+// account, buckets of other accounts and findings from other accounts are
+// left alone, so each account's analysis can be applied in turn.
+func ApplySensitiveData(buckets map[string]*BucketInfo, account string, analysis *MacieAnalysis) {
+	for _, info := range buckets {
+		if info == nil || !info.Exists || (account != "" && info.AccountID != "" && info.AccountID != account) {
+			continue
+		}
+		data := analysis.Buckets[info.Name]
+		if data == nil || len(data.Classes) == 0 {
+			continue
+		}
+		if owner := analysis.accounts[info.Name]; account != "" && owner != "" && owner != account {
+			continue
+		}
+		info.SensitiveData = data
+	}
+}
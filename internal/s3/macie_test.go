@@ -0,0 +1,125 @@
+package s3
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/macie2"
+	macietypes "github.com/aws/aws-sdk-go-v2/service/macie2/types"
+)
+
+// fakeMacieAPI serves one region's Macie session and findings
+type fakeMacieAPI struct {
+	status   macietypes.MacieStatus
+	err      error
+	findings []macietypes.Finding
+	batches  []int
+}
+
+func (f *fakeMacieAPI) GetMacieSession(context.Context, *macie2.GetMacieSessionInput, ...func(*macie2.Options)) (*macie2.GetMacieSessionOutput, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &macie2.GetMacieSessionOutput{Status: f.status}, nil
+}
+
+func (f *fakeMacieAPI) ListFindings(context.Context, *macie2.ListFindingsInput, ...func(*macie2.Options)) (*macie2.ListFindingsOutput, error) {
+	ids := make([]string, len(f.findings))
+	for k, finding := range f.findings {
+		ids[k] = aws.ToString(finding.Id)
+	}
+	return &macie2.ListFindingsOutput{FindingIds: ids}, nil
+}
+
+func (f *fakeMacieAPI) GetFindings(_ context.Context, params *macie2.GetFindingsInput, _ ...func(*macie2.Options)) (*macie2.GetFindingsOutput, error) {
+	f.batches = append(f.batches, len(params.FindingIds))
+	byID := make(map[string]macietypes.Finding)
+	for _, finding := range f.findings {
+		byID[aws.ToString(finding.Id)] = finding
+	}
+	out := &macie2.GetFindingsOutput{}
+	for _, id := range params.FindingIds {
+		out.Findings = append(out.Findings, byID[id])
+	}
+	return out, nil
+}
+
+// macieFinding is a classification finding for a bucket
+func macieFinding(id, bucket, job string, severity macietypes.SeverityDescription, items ...macietypes.SensitiveDataItem) macietypes.Finding {
+	return macietypes.Finding{
+		Id:                    aws.String(id),
+		AccountId:             aws.String("111122223333"),
+		Category:              macietypes.FindingCategoryClassification,
+		Severity:              &macietypes.Severity{Description: severity},
+		ResourcesAffected:     &macietypes.ResourcesAffected{S3Bucket: &macietypes.S3Bucket{Name: aws.String(bucket)}},
+		ClassificationDetails: &macietypes.ClassificationDetails{JobId: aws.String(job), Result: &macietypes.ClassificationResult{SensitiveData: items}},
+	}
+}
+
+func TestMacieSensitiveData(t *testing.T) {
+	sensitive := func(category macietypes.SensitiveDataItemCategory, kind string) macietypes.SensitiveDataItem {
+		return macietypes.SensitiveDataItem{Category: category, Detections: []macietypes.DefaultDetection{{Type: aws.String(kind), Count: aws.Int64(3)}}}
+	}
+	east := &fakeMacieAPI{status: macietypes.MacieStatusEnabled, findings: []macietypes.Finding{
+		macieFinding("f-1", "customers", "job-1", macietypes.SeverityDescriptionMedium,
+			sensitive(macietypes.SensitiveDataItemCategoryPersonalInformation, "USA_SOCIAL_SECURITY_NUMBER")),
+		macieFinding("f-2", "customers", "", macietypes.SeverityDescriptionHigh,
+			sensitive(macietypes.SensitiveDataItemCategoryFinancialInformation, "CREDIT_CARD_NUMBER"),
+			sensitive(macietypes.SensitiveDataItemCategoryPersonalInformation, "EMAIL_ADDRESS")),
+	}}
+	// More findings than one GetFindings call returns
+	for k := 0; k < macieFindingsBatch; k++ {
+		east.findings = append(east.findings, macieFinding(fmt.Sprintf("g-%d", k), "backups", "job-2", macietypes.SeverityDescriptionLow,
+			sensitive(macietypes.SensitiveDataItemCategoryCredentials, "AWS_CREDENTIALS")))
+	}
+	apis := map[string]*fakeMacieAPI{
+		"us-east-1":    east,
+		"us-west-2":    {status: macietypes.MacieStatusPaused},
+		"eu-central-1": {err: errors.New("AccessDeniedException: Macie is not enabled")},
+	}
+
+	inspector := NewInspector(&Client{config: aws.Config{Region: "us-east-1"}}, 1)
+	inspector.SetRegions([]string{"us-east-1", "us-west-2", "eu-central-1"})
+	inspector.newMacieAPI = func(region string) MacieAPI { return apis[region] }
+
+	analysis, err := inspector.MacieSensitiveData(context.Background())
+	if err != nil {
+		t.Fatalf("MacieSensitiveData failed: %v", err)
+	}
+	if !reflect.DeepEqual(analysis.Disabled, []string{"us-west-2", "eu-central-1"}) {
+		t.Errorf("Disabled = %v", analysis.Disabled)
+	}
+	if !reflect.DeepEqual(east.batches, []int{macieFindingsBatch, 2}) {
+		t.Errorf("GetFindings batches = %v, want 50 then 2", east.batches)
+	}
+	want := &SensitiveData{
+		Classes:  []string{"FINANCIAL_INFORMATION", "PERSONAL_INFORMATION"},
+		Types:    []string{"CREDIT_CARD_NUMBER", "EMAIL_ADDRESS", "USA_SOCIAL_SECURITY_NUMBER"},
+		Findings: 2,
+		Severity: "High",
+		Jobs:     []string{"job-1"},
+	}
+	if got := analysis.Buckets["customers"]; !reflect.DeepEqual(got, want) {
+		t.Errorf("customers = %+v, want %+v", got, want)
+	}
+	if got := analysis.Buckets["backups"]; got.Findings != macieFindingsBatch || !reflect.DeepEqual(got.Classes, []string{"CREDENTIALS"}) {
+		t.Errorf("backups = %+v", got)
+	}
+
+	buckets := map[string]*BucketInfo{
+		"customers": {Name: "customers", Exists: true},
+		"backups":   {Name: "backups", Exists: true, AccountID: "444455556666"},
+		"empty":     {Name: "empty", Exists: true},
+	}
+	ApplySensitiveData(buckets, "111122223333", analysis)
+	if !reflect.DeepEqual(buckets["customers"].SensitiveData, want) {
+		t.Errorf("customers should carry its sensitive data, got %+v", buckets["customers"].SensitiveData)
+	}
+	if buckets["backups"].SensitiveData != nil || buckets["empty"].SensitiveData != nil {
+		t.Error("sensitive data should only apply to the account's buckets with findings")
+	}
+}
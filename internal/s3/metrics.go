@@ -0,0 +1,54 @@
+package s3
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/aws/smithy-go"
+)
+
+// MetricsConfigID names the request metrics configuration s3spectre adds.
+// It has no filter, so CloudWatch reports requests to the whole bucket.
+const MetricsConfigID = "s3spectre-EntireBucket"
+
+// MetricsAPI is the subset of the S3 client request metrics setup uses
+type MetricsAPI interface {
+	PutBucketMetricsConfiguration(ctx context.Context, params *s3.PutBucketMetricsConfigurationInput, optFns ...func(*s3.Options)) (*s3.PutBucketMetricsConfigurationOutput, error)
+	DeleteBucketMetricsConfiguration(ctx context.Context, params *s3.DeleteBucketMetricsConfigurationInput, optFns ...func(*s3.Options)) (*s3.DeleteBucketMetricsConfigurationOutput, error)
+}
+
+// EnableRequestMetrics turns on CloudWatch request metrics for the whole
+// bucket. Enabling it again is a no-op.
+func EnableRequestMetrics(ctx context.Context, api MetricsAPI, bucket string) error {
+	_, err := api.PutBucketMetricsConfiguration(ctx, &s3.PutBucketMetricsConfigurationInput{
+		Bucket:               aws.String(bucket),
+		Id:                   aws.String(MetricsConfigID),
+		MetricsConfiguration: &types.MetricsConfiguration{Id: aws.String(MetricsConfigID)},
+	})
+	if err != nil {
+		return fmt.Errorf("put metrics configuration: %w", err)
+	}
+	return nil
+}
+
+// DisableRequestMetrics removes the request metrics configuration
+// EnableRequestMetrics added, reporting whether there was one. Other
+// metrics configurations on the bucket are left alone.
+func DisableRequestMetrics(ctx context.Context, api MetricsAPI, bucket string) (bool, error) {
+	_, err := api.DeleteBucketMetricsConfiguration(ctx, &s3.DeleteBucketMetricsConfigurationInput{
+		Bucket: aws.String(bucket),
+		Id:     aws.String(MetricsConfigID),
+	})
+	var apiErr smithy.APIError
+	if errors.As(err, &apiErr) && apiErr.ErrorCode() == "NoSuchConfiguration" {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("delete metrics configuration: %w", err)
+	}
+	return true, nil
+}
@@ -0,0 +1,62 @@
+package s3
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/smithy-go"
+)
+
+// fakeMetrics holds metrics configuration IDs per bucket
+type fakeMetrics struct {
+	configs map[string]map[string]bool
+}
+
+func (f *fakeMetrics) PutBucketMetricsConfiguration(ctx context.Context, params *s3.PutBucketMetricsConfigurationInput, optFns ...func(*s3.Options)) (*s3.PutBucketMetricsConfigurationOutput, error) {
+	if aws.ToString(params.Id) != aws.ToString(params.MetricsConfiguration.Id) || params.MetricsConfiguration.Filter != nil {
+		return nil, errors.New("InvalidArgument")
+	}
+	bucket := aws.ToString(params.Bucket)
+	if f.configs[bucket] == nil {
+		f.configs[bucket] = make(map[string]bool)
+	}
+	f.configs[bucket][aws.ToString(params.Id)] = true
+	return &s3.PutBucketMetricsConfigurationOutput{}, nil
+}
+
+func (f *fakeMetrics) DeleteBucketMetricsConfiguration(ctx context.Context, params *s3.DeleteBucketMetricsConfigurationInput, optFns ...func(*s3.Options)) (*s3.DeleteBucketMetricsConfigurationOutput, error) {
+	configs := f.configs[aws.ToString(params.Bucket)]
+	if !configs[aws.ToString(params.Id)] {
+		return nil, &smithy.GenericAPIError{Code: "NoSuchConfiguration"}
+	}
+	delete(configs, aws.ToString(params.Id))
+	return &s3.DeleteBucketMetricsConfigurationOutput{}, nil
+}
+
+func TestRequestMetrics(t *testing.T) {
+	api := &fakeMetrics{configs: map[string]map[string]bool{"logs": {"custom": true}}}
+	ctx := context.Background()
+
+	if err := EnableRequestMetrics(ctx, api, "logs"); err != nil {
+		t.Fatalf("EnableRequestMetrics: %v", err)
+	}
+	if !api.configs["logs"][MetricsConfigID] {
+		t.Fatalf("configs = %v, want %s added", api.configs, MetricsConfigID)
+	}
+
+	existed, err := DisableRequestMetrics(ctx, api, "logs")
+	if err != nil || !existed {
+		t.Fatalf("DisableRequestMetrics = %v, %v; want true, nil", existed, err)
+	}
+	if api.configs["logs"][MetricsConfigID] || !api.configs["logs"]["custom"] {
+		t.Errorf("configs = %v, want only the other configuration left", api.configs)
+	}
+
+	existed, err = DisableRequestMetrics(ctx, api, "logs")
+	if err != nil || existed {
+		t.Errorf("second DisableRequestMetrics = %v, %v; want false, nil", existed, err)
+	}
+}
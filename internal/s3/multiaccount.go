@@ -0,0 +1,129 @@
+package s3
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+var (
+	accountIDPattern = regexp.MustCompile(`^\d{12}$`)
+	roleARNPattern   = regexp.MustCompile(`^(arn:aws[a-z-]*:iam::)(\d{12})(:role/[\w+=,.@/-]+)$`)
+	roleNamePattern  = regexp.MustCompile(`^[\w+=,.@/-]+$`)
+)
+
+// maxChainedRoleSessionDuration is the longest session AWS grants a role
+// assumed with another role's credentials
+const maxChainedRoleSessionDuration = time.Hour
+
+// ValidateAccountID checks that id is a 12-digit AWS account ID
+func ValidateAccountID(id string) error {
+	if !accountIDPattern.MatchString(id) {
+		return fmt.Errorf("invalid AWS account ID %q (expected 12 digits)", id)
+	}
+	return nil
+}
+
+// ValidateAccountRole checks that role is an IAM role ARN or a role name
+func ValidateAccountRole(role string) error {
+	if roleARNPattern.MatchString(role) {
+		return nil
+	}
+	if !strings.HasPrefix(role, "arn:") && roleNamePattern.MatchString(role) {
+		return nil
+	}
+	return fmt.Errorf("invalid role %q (expected an IAM role ARN or role name)", role)
+}
+
+// RoleAccount returns the account ID of an IAM role ARN, or "" for a role name
+func RoleAccount(role string) string {
+	m := roleARNPattern.FindStringSubmatch(role)
+	if m == nil {
+		return ""
+	}
+	return m[2]
+}
+
+// AccountRoleARN returns the role to assume in account. role is an IAM role
+// ARN, whose account ID is replaced, or a role name.
+func AccountRoleARN(role, account string) (string, error) {
+	if err := ValidateAccountID(account); err != nil {
+		return "", err
+	}
+	if m := roleARNPattern.FindStringSubmatch(role); m != nil {
+		return m[1] + account + m[3], nil
+	}
+	if err := ValidateAccountRole(role); err != nil {
+		return "", err
+	}
+	return "arn:aws:iam::" + account + ":role/" + strings.TrimPrefix(role, "/"), nil
+}
+
+// AssumeRole returns a client that assumes role with c's credentials, for
+// inspecting another account. The session is capped at one hour, the limit
+// AWS puts on role chaining. The returned client shares c's endpoint
+// overrides, retry schedule and API call accounting.
+func (c *Client) AssumeRole(ctx context.Context, role AssumeRole) (*Client, error) {
+	if role.Duration == 0 || role.Duration > maxChainedRoleSessionDuration {
+		role.Duration = maxChainedRoleSessionDuration
+	}
+	cfg := c.config.Copy()
+	if err := assumeRole(ctx, &cfg, role); err != nil {
+		return nil, err
+	}
+	return c.withConfig(cfg), nil
+}
+
+// OwnedBuckets lists the buckets the client's account owns
+func (i *Inspector) OwnedBuckets(ctx context.Context) (map[string]bool, error) {
+	var result *s3.ListBucketsOutput
+	err := i.client.WithRetry(ctx, func() error {
+		var err error
+		result, err = i.client.s3Client.ListBuckets(ctx, &s3.ListBucketsInput{})
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list buckets: %w", err)
+	}
+	owned := make(map[string]bool, len(result.Buckets))
+	for _, bucket := range result.Buckets {
+		if bucket.Name != nil {
+			owned[*bucket.Name] = true
+		}
+	}
+	return owned, nil
+}
+
+// MergeAccountBuckets combines per-account inspection results, keyed by
+// account ID, stamping each bucket with its account. When several accounts
+// report a bucket, the one where it exists wins, then the first account in
+// sorted order.
+func MergeAccountBuckets(byAccount map[string]map[string]*BucketInfo) map[string]*BucketInfo {
+	accounts := make([]string, 0, len(byAccount))
+	for account := range byAccount {
+		accounts = append(accounts, account)
+	}
+	sort.Strings(accounts)
+
+	merged := make(map[string]*BucketInfo)
+	for _, account := range accounts {
+		for name, info := range byAccount[account] {
+			if info == nil {
+				continue
+			}
+			if prev, ok := merged[name]; ok && (prev.Exists || !info.Exists) {
+				continue
+			}
+			if info.AccountID == "" && info.Exists {
+				info.AccountID = account
+			}
+			merged[name] = info
+		}
+	}
+	return merged
+}
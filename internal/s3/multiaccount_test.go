@@ -0,0 +1,66 @@
+package s3
+
+import "testing"
+
+func TestAccountRoleARN(t *testing.T) {
+	tests := []struct {
+		role, account, want string
+		wantErr             bool
+	}{
+		{role: "arn:aws:iam::111111111111:role/S3Audit", account: "222222222222", want: "arn:aws:iam::222222222222:role/S3Audit"},
+		{role: "arn:aws-us-gov:iam::111111111111:role/team/S3Audit", account: "222222222222", want: "arn:aws-us-gov:iam::222222222222:role/team/S3Audit"},
+		{role: "S3Audit", account: "333333333333", want: "arn:aws:iam::333333333333:role/S3Audit"},
+		{role: "S3Audit", account: "12345", wantErr: true},
+		{role: "arn:aws:s3:::bucket", account: "333333333333", wantErr: true},
+		{role: "bad role", account: "333333333333", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := AccountRoleARN(tt.role, tt.account)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("AccountRoleARN(%q, %q) error = %v, wantErr %v", tt.role, tt.account, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("AccountRoleARN(%q, %q) = %q, want %q", tt.role, tt.account, got, tt.want)
+		}
+	}
+}
+
+func TestRoleAccount(t *testing.T) {
+	if got := RoleAccount("arn:aws:iam::111111111111:role/S3Audit"); got != "111111111111" {
+		t.Errorf("expected the ARN's account, got %q", got)
+	}
+	if got := RoleAccount("S3Audit"); got != "" {
+		t.Errorf("role names have no account, got %q", got)
+	}
+}
+
+func TestMergeAccountBuckets(t *testing.T) {
+	merged := MergeAccountBuckets(map[string]map[string]*BucketInfo{
+		"222222222222": {
+			"shared": {Name: "shared", Exists: true},
+			"lost":   {Name: "lost"},
+		},
+		"111111111111": {
+			"shared": {Name: "shared"},
+			"own":    {Name: "own", Exists: true},
+			"agg":    {Name: "agg", Exists: true, AccountID: "999999999999"},
+		},
+	})
+
+	if len(merged) != 4 {
+		t.Fatalf("expected 4 buckets, got %d", len(merged))
+	}
+	if b := merged["shared"]; !b.Exists || b.AccountID != "222222222222" {
+		t.Errorf("the account where the bucket exists should win: %+v", b)
+	}
+	if merged["own"].AccountID != "111111111111" {
+		t.Errorf("expected own to be stamped with its account, got %q", merged["own"].AccountID)
+	}
+	if merged["agg"].AccountID != "999999999999" {
+		t.Errorf("a source-reported account should be kept, got %q", merged["agg"].AccountID)
+	}
+	if merged["lost"].AccountID != "" {
+		t.Errorf("missing buckets have no owner, got %q", merged["lost"].AccountID)
+	}
+}
@@ -0,0 +1,156 @@
+package s3
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+// nearMissListLimit bounds the sibling listing used to find a near miss
+const nearMissListLimit = 1000
+
+// maxBucketNearMisses caps the "did you mean" candidates for a missing bucket
+const maxBucketNearMisses = 3
+
+// nearMissPrefix lists the prefixes and keys next to a prefix that has no
+// objects and returns the closest one, or "" when none is close. Listing
+// failures are not reported: the prefix is still missing.
+func (i *Inspector) nearMissPrefix(ctx context.Context, client *Client, bucket, prefix string) string {
+	parent := parentPrefix(prefix)
+	var listResult *s3.ListObjectsV2Output
+	err := client.WithRetry(ctx, func() error {
+		var err error
+		listResult, err = client.s3Client.ListObjectsV2(ctx, &s3.ListObjectsV2Input{
+			Bucket:    aws.String(bucket),
+			Prefix:    aws.String(parent),
+			Delimiter: aws.String("/"),
+			MaxKeys:   aws.Int32(nearMissListLimit),
+		})
+		return err
+	})
+	if err != nil {
+		return ""
+	}
+	candidates := make([]string, 0, len(listResult.CommonPrefixes)+len(listResult.Contents))
+	for _, cp := range listResult.CommonPrefixes {
+		if cp.Prefix != nil {
+			candidates = append(candidates, *cp.Prefix)
+		}
+	}
+	for _, obj := range listResult.Contents {
+		if obj.Key != nil {
+			candidates = append(candidates, *obj.Key)
+		}
+	}
+	closest, _ := ClosestPrefix(prefix, candidates)
+	return closest
+}
+
+// parentPrefix returns the prefix's parent "directory", including its
+// trailing slash; "" for a top-level prefix
+func parentPrefix(prefix string) string {
+	trimmed := strings.TrimSuffix(prefix, "/")
+	if idx := strings.LastIndex(trimmed, "/"); idx >= 0 {
+		return trimmed[:idx+1]
+	}
+	return ""
+}
+
+// NormalizePrefix folds the differences that usually separate a reference
+// from the real layout: letter case and leading or trailing slashes
+func NormalizePrefix(prefix string) string {
+	return strings.ToLower(strings.Trim(prefix, "/"))
+}
+
+// ClosestPrefix returns the candidate nearest to prefix after
+// normalization, when it is within the near-miss distance: one edit for
+// short prefixes, one per five characters for longer ones. Ties go to the
+// alphabetically first candidate.
+func ClosestPrefix(prefix string, candidates []string) (string, bool) {
+	target := NormalizePrefix(prefix)
+	if target == "" {
+		return "", false
+	}
+	limit := max(1, len(target)/5)
+	sorted := append([]string(nil), candidates...)
+	sort.Strings(sorted)
+
+	best, bestDistance := "", limit+1
+	for _, candidate := range sorted {
+		if candidate == prefix {
+			continue
+		}
+		d := editDistance(target, NormalizePrefix(candidate))
+		if d < bestDistance {
+			best, bestDistance = candidate, d
+		}
+	}
+	return best, best != ""
+}
+
+// editDistance is the Levenshtein distance between two strings
+func editDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	cur := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		cur[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(rb)]
+}
+
+// ClosestBuckets returns up to three existing bucket names a missing bucket
+// was probably meant to be, nearest first: names within the near-miss edit
+// distance, and names that differ only in one of envs, such as -prod or
+// -staging. Ties go to the alphabetically first name.
+func ClosestBuckets(bucket string, existing []string, envs Environments) []string {
+	target := strings.ToLower(bucket)
+	limit := max(1, len(target)/5)
+	stem, targetEnv := envs.stem(target)
+
+	type candidate struct {
+		name     string
+		distance int
+	}
+	var matches []candidate
+	for _, name := range existing {
+		if name == bucket {
+			continue
+		}
+		lower := strings.ToLower(name)
+		d := editDistance(target, lower)
+		nameStem, nameEnv := envs.stem(lower)
+		envMismatch := stem != "" && nameStem == stem && (targetEnv || nameEnv)
+		if d <= limit || envMismatch {
+			matches = append(matches, candidate{name: name, distance: d})
+		}
+	}
+	sort.Slice(matches, func(a, b int) bool {
+		if matches[a].distance != matches[b].distance {
+			return matches[a].distance < matches[b].distance
+		}
+		return matches[a].name < matches[b].name
+	})
+	names := make([]string, 0, min(len(matches), maxBucketNearMisses))
+	for _, m := range matches[:min(len(matches), maxBucketNearMisses)] {
+		names = append(names, m.name)
+	}
+	if len(names) == 0 {
+		return nil
+	}
+	return names
+}
@@ -0,0 +1,116 @@
+package s3
+
+import (
+	"context"
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func TestClosestPrefix(t *testing.T) {
+	candidates := []string{"log/", "Reports/", "data/2024/", "archive/"}
+	tests := []struct {
+		prefix string
+		want   string
+	}{
+		{prefix: "logs/", want: "log/"},
+		{prefix: "logs", want: "log/"},
+		{prefix: "reports/", want: "Reports/"},
+		{prefix: "data/2023/", want: "data/2024/"},
+		{prefix: "backups/", want: ""},
+		{prefix: "archive/", want: ""},
+		{prefix: "/", want: ""},
+	}
+	for _, tt := range tests {
+		got, ok := ClosestPrefix(tt.prefix, candidates)
+		if got != tt.want || ok != (tt.want != "") {
+			t.Errorf("ClosestPrefix(%q) = %q, %v; want %q", tt.prefix, got, ok, tt.want)
+		}
+	}
+}
+
+func TestClosestBuckets(t *testing.T) {
+	existing := []string{"orders-staging", "orders-prod-archive", "acme-data-lake", "acme-logs", "billing.dev.exports", "Acme-Data-Lake-2"}
+	tests := []struct {
+		bucket string
+		want   []string
+	}{
+		{bucket: "acme-data-lak", want: []string{"acme-data-lake"}},
+		{bucket: "orders-prod", want: []string{"orders-staging"}},
+		{bucket: "billing-prod-exports", want: []string{"billing.dev.exports"}},
+		{bucket: "acme-data-lake-3", want: []string{"Acme-Data-Lake-2", "acme-data-lake"}},
+		{bucket: "acme-logs", want: nil},
+		{bucket: "unrelated", want: nil},
+	}
+	for _, tt := range tests {
+		if got := ClosestBuckets(tt.bucket, existing, NewEnvironments(nil)); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("ClosestBuckets(%q) = %v, want %v", tt.bucket, got, tt.want)
+		}
+	}
+}
+
+func TestClosestBuckets_Limit(t *testing.T) {
+	existing := []string{"app-dev", "app-qa", "app-staging", "app-test", "app-uat"}
+	got := ClosestBuckets("app-prod", existing, NewEnvironments(nil))
+	if len(got) != maxBucketNearMisses {
+		t.Fatalf("expected %d candidates, got %v", maxBucketNearMisses, got)
+	}
+	if got[0] != "app-dev" {
+		t.Errorf("the nearest name should come first, got %v", got)
+	}
+}
+
+func TestParentPrefix(t *testing.T) {
+	for prefix, want := range map[string]string{
+		"logs/":           "",
+		"logs":            "",
+		"data/2024/":      "data/",
+		"data/2024/part-": "data/2024/",
+	} {
+		if got := parentPrefix(prefix); got != want {
+			t.Errorf("parentPrefix(%q) = %q, want %q", prefix, got, want)
+		}
+	}
+}
+
+func TestInspector_InspectPrefixWithClient_NearMiss(t *testing.T) {
+	emptyXML := `<?xml version="1.0" encoding="UTF-8"?>
+<ListBucketResult xmlns="http://s3.amazonaws.com/doc/2006-03-01/">
+  <Name>test-bucket</Name>
+  <Prefix>exports/Daily/</Prefix>
+  <KeyCount>0</KeyCount>
+  <MaxKeys>1000</MaxKeys>
+  <IsTruncated>false</IsTruncated>
+</ListBucketResult>`
+	siblingsXML := `<?xml version="1.0" encoding="UTF-8"?>
+<ListBucketResult xmlns="http://s3.amazonaws.com/doc/2006-03-01/">
+  <Name>test-bucket</Name>
+  <Prefix>exports/</Prefix>
+  <Delimiter>/</Delimiter>
+  <KeyCount>2</KeyCount>
+  <MaxKeys>1000</MaxKeys>
+  <IsTruncated>false</IsTruncated>
+  <CommonPrefixes><Prefix>exports/daily/</Prefix></CommonPrefixes>
+  <CommonPrefixes><Prefix>exports/weekly/</Prefix></CommonPrefixes>
+</ListBucketResult>`
+
+	rt := roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		if req.URL.Query().Get("delimiter") == "/" {
+			if got := req.URL.Query().Get("prefix"); got != "exports/" {
+				t.Errorf("expected the parent prefix to be listed, got %q", got)
+			}
+			return xmlResponse(siblingsXML), nil
+		}
+		return xmlResponse(emptyXML), nil
+	})
+	client := newTestClient(t, rt)
+	inspector := NewInspector(client, 1)
+
+	info := inspector.inspectPrefixWithClient(context.Background(), client, "test-bucket", "exports/Daily/")
+	if info.Exists {
+		t.Fatalf("expected prefix to be missing")
+	}
+	if info.NearMiss != "exports/daily/" {
+		t.Fatalf("expected near miss exports/daily/, got %q", info.NearMiss)
+	}
+}
@@ -0,0 +1,84 @@
+package s3
+
+import (
+	"context"
+	"errors"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/aws/smithy-go"
+)
+
+// ObjectLockAPI is the subset of the S3 client used to read a bucket's
+// Object Lock configuration
+type ObjectLockAPI interface {
+	GetObjectLockConfiguration(ctx context.Context, params *s3.GetObjectLockConfigurationInput, optFns ...func(*s3.Options)) (*s3.GetObjectLockConfigurationOutput, error)
+}
+
+// ObjectLockInfo is a bucket's Object Lock configuration. A bucket without
+// Object Lock has an ObjectLockInfo with Enabled false.
+type ObjectLockInfo struct {
+	Enabled bool `json:"enabled"`
+	// Mode is the default retention mode, GOVERNANCE or COMPLIANCE; empty
+	// when new objects get no default retention
+	Mode string `json:"mode,omitempty"`
+	// Days and Years are the default retention period; only one is set
+	Days  int `json:"days,omitempty"`
+	Years int `json:"years,omitempty"`
+}
+
+// Compliance reports whether new objects are retained in compliance mode,
+// which no user, including root, can shorten or remove. A bucket holding
+// such objects cannot be emptied until their retention expires.
+func (o *ObjectLockInfo) Compliance() bool {
+	return o != nil && o.Enabled && o.Mode == string(types.ObjectLockRetentionModeCompliance)
+}
+
+// RetentionDays is the default retention period in days, counting a year
+// as 365 days
+func (o *ObjectLockInfo) RetentionDays() int {
+	if o == nil {
+		return 0
+	}
+	return o.Days + o.Years*365
+}
+
+// objectLockClient returns the client that reads a bucket's Object Lock
+// configuration
+func (i *Inspector) objectLockClient(client *Client) ObjectLockAPI {
+	if i.newObjectLockAPI != nil {
+		return i.newObjectLockAPI(client.GetRegion())
+	}
+	return client.s3Client
+}
+
+// readObjectLock sets info.ObjectLock from the bucket's Object Lock
+// configuration. A failed call leaves info.ObjectLock nil.
+func (i *Inspector) readObjectLock(ctx context.Context, client *Client, bucket string, info *BucketInfo) {
+	api := i.objectLockClient(client)
+	lock := &ObjectLockInfo{}
+	err := client.WithRetry(ctx, func() error {
+		out, err := api.GetObjectLockConfiguration(ctx, &s3.GetObjectLockConfigurationInput{Bucket: aws.String(bucket)})
+		if err != nil || out.ObjectLockConfiguration == nil {
+			return err
+		}
+		cfg := out.ObjectLockConfiguration
+		lock.Enabled = cfg.ObjectLockEnabled == types.ObjectLockEnabledEnabled
+		if cfg.Rule != nil && cfg.Rule.DefaultRetention != nil {
+			retention := cfg.Rule.DefaultRetention
+			lock.Mode = string(retention.Mode)
+			lock.Days = int(aws.ToInt32(retention.Days))
+			lock.Years = int(aws.ToInt32(retention.Years))
+		}
+		return nil
+	})
+	var apiErr smithy.APIError
+	if err != nil && !(errors.As(err, &apiErr) && apiErr.ErrorCode() == "ObjectLockConfigurationNotFoundError") {
+		return
+	}
+	if ctx.Err() != nil {
+		return
+	}
+	info.ObjectLock = lock
+}
@@ -0,0 +1,67 @@
+package s3
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/aws/smithy-go"
+)
+
+// fakeObjectLockAPI returns a fixed Object Lock configuration or error
+type fakeObjectLockAPI struct {
+	config *types.ObjectLockConfiguration
+	err    error
+}
+
+func (f *fakeObjectLockAPI) GetObjectLockConfiguration(_ context.Context, _ *s3.GetObjectLockConfigurationInput, _ ...func(*s3.Options)) (*s3.GetObjectLockConfigurationOutput, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &s3.GetObjectLockConfigurationOutput{ObjectLockConfiguration: f.config}, nil
+}
+
+func TestReadObjectLock(t *testing.T) {
+	client := &Client{config: aws.Config{Region: "us-east-1"}}
+	inspector := NewInspector(client, 1)
+	api := &fakeObjectLockAPI{config: &types.ObjectLockConfiguration{
+		ObjectLockEnabled: types.ObjectLockEnabledEnabled,
+		Rule: &types.ObjectLockRule{DefaultRetention: &types.DefaultRetention{
+			Mode:  types.ObjectLockRetentionModeCompliance,
+			Years: aws.Int32(7),
+		}},
+	}}
+	inspector.newObjectLockAPI = func(string) ObjectLockAPI { return api }
+
+	info := &BucketInfo{}
+	inspector.readObjectLock(context.Background(), client, "ledger", info)
+	if !info.ObjectLock.Compliance() || info.ObjectLock.Years != 7 || info.ObjectLock.RetentionDays() != 7*365 {
+		t.Errorf("object lock = %+v, want 7 years of compliance retention", info.ObjectLock)
+	}
+
+	// Governance retention can be bypassed, so it is not compliance
+	api.config.Rule.DefaultRetention.Mode = types.ObjectLockRetentionModeGovernance
+	info = &BucketInfo{}
+	inspector.readObjectLock(context.Background(), client, "ledger", info)
+	if info.ObjectLock == nil || !info.ObjectLock.Enabled || info.ObjectLock.Compliance() {
+		t.Errorf("object lock = %+v, want governance mode", info.ObjectLock)
+	}
+
+	// A bucket without Object Lock has no configuration
+	api.err = &smithy.GenericAPIError{Code: "ObjectLockConfigurationNotFoundError"}
+	info = &BucketInfo{}
+	inspector.readObjectLock(context.Background(), client, "plain", info)
+	if info.ObjectLock == nil || info.ObjectLock.Enabled {
+		t.Errorf("object lock = %+v, want disabled", info.ObjectLock)
+	}
+
+	api.err = errors.New("AccessDenied")
+	info = &BucketInfo{}
+	inspector.readObjectLock(context.Background(), client, "denied", info)
+	if info.ObjectLock != nil {
+		t.Errorf("a failed call should leave no result, got %+v", info.ObjectLock)
+	}
+}
@@ -0,0 +1,61 @@
+package s3
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/organizations"
+	orgtypes "github.com/aws/aws-sdk-go-v2/service/organizations/types"
+)
+
+// DefaultOrgAuditRole is the role AWS Organizations creates in accounts it
+// provisions, assumed in each member account when no other role is given
+const DefaultOrgAuditRole = "OrganizationAccountAccessRole"
+
+// OrganizationsAPI is the subset of the Organizations client used to list accounts
+type OrganizationsAPI interface {
+	ListAccounts(ctx context.Context, params *organizations.ListAccountsInput, optFns ...func(*organizations.Options)) (*organizations.ListAccountsOutput, error)
+}
+
+// OrgAccount is an active member account of an AWS organization
+type OrgAccount struct {
+	ID   string `json:"id"`
+	Name string `json:"name,omitempty"`
+}
+
+// OrganizationAccounts lists the organization's active member accounts,
+// sorted by ID. It needs management account or delegated administrator
+// credentials.
+func (c *Client) OrganizationAccounts(ctx context.Context) ([]OrgAccount, error) {
+	return c.organizationAccounts(ctx, organizations.NewFromConfig(c.config))
+}
+
+func (c *Client) organizationAccounts(ctx context.Context, api OrganizationsAPI) ([]OrgAccount, error) {
+	var accounts []OrgAccount
+	paginator := organizations.NewListAccountsPaginator(api, &organizations.ListAccountsInput{})
+	for paginator.HasMorePages() {
+		var page *organizations.ListAccountsOutput
+		err := c.WithRetry(ctx, func() error {
+			var err error
+			page, err = paginator.NextPage(ctx)
+			return err
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to list organization accounts: %w", err)
+		}
+		for _, account := range page.Accounts {
+			// Suspended and closing accounts cannot be assumed into
+			if account.Status != orgtypes.AccountStatusActive {
+				continue
+			}
+			accounts = append(accounts, OrgAccount{
+				ID:   aws.ToString(account.Id),
+				Name: aws.ToString(account.Name),
+			})
+		}
+	}
+	sort.Slice(accounts, func(a, b int) bool { return accounts[a].ID < accounts[b].ID })
+	return accounts, nil
+}
@@ -0,0 +1,65 @@
+package s3
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/organizations"
+	orgtypes "github.com/aws/aws-sdk-go-v2/service/organizations/types"
+)
+
+// fakeOrganizationsAPI serves accounts in pages keyed by NextToken
+type fakeOrganizationsAPI struct {
+	pages map[string]*organizations.ListAccountsOutput
+	err   error
+}
+
+func (f *fakeOrganizationsAPI) ListAccounts(_ context.Context, params *organizations.ListAccountsInput, _ ...func(*organizations.Options)) (*organizations.ListAccountsOutput, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	return f.pages[aws.ToString(params.NextToken)], nil
+}
+
+func TestOrganizationAccounts(t *testing.T) {
+	api := &fakeOrganizationsAPI{pages: map[string]*organizations.ListAccountsOutput{
+		"": {
+			Accounts: []orgtypes.Account{
+				{Id: aws.String("222222222222"), Name: aws.String("prod"), Status: orgtypes.AccountStatusActive},
+				{Id: aws.String("333333333333"), Name: aws.String("old"), Status: orgtypes.AccountStatusSuspended},
+			},
+			NextToken: aws.String("page-2"),
+		},
+		"page-2": {
+			Accounts: []orgtypes.Account{
+				{Id: aws.String("111111111111"), Name: aws.String("management"), Status: orgtypes.AccountStatusActive},
+				{Id: aws.String("444444444444"), Status: orgtypes.AccountStatusPendingClosure},
+			},
+		},
+	}}
+	client := &Client{config: aws.Config{Region: "us-east-1"}}
+
+	accounts, err := client.organizationAccounts(context.Background(), api)
+	if err != nil {
+		t.Fatalf("organizationAccounts: %v", err)
+	}
+	want := []OrgAccount{{ID: "111111111111", Name: "management"}, {ID: "222222222222", Name: "prod"}}
+	if len(accounts) != len(want) {
+		t.Fatalf("got %d accounts, want %d: %+v", len(accounts), len(want), accounts)
+	}
+	for i := range want {
+		if accounts[i] != want[i] {
+			t.Errorf("accounts[%d] = %+v, want %+v", i, accounts[i], want[i])
+		}
+	}
+}
+
+func TestOrganizationAccounts_Error(t *testing.T) {
+	client := &Client{config: aws.Config{Region: "us-east-1"}}
+	_, err := client.organizationAccounts(context.Background(), &fakeOrganizationsAPI{err: errors.New("AWSOrganizationsNotInUseException")})
+	if err == nil {
+		t.Fatal("expected error")
+	}
+}
@@ -0,0 +1,265 @@
+package s3
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3control"
+	s3controltypes "github.com/aws/aws-sdk-go-v2/service/s3control/types"
+	"github.com/ppiankov/s3spectre/internal/scanner"
+)
+
+var (
+	outpostsBucketARNPattern = regexp.MustCompile(`^arn:aws[a-z-]*:s3-outposts:([a-z0-9-]+):(\d{12}):outpost/(op-[0-9a-f]+)/bucket/([a-z0-9][a-z0-9\-\.]{1,61}[a-z0-9])$`)
+	outpostARNPattern        = regexp.MustCompile(`^arn:aws[a-z-]*:outposts:([a-z0-9-]+):(\d{12}):outpost/(op-[0-9a-f]+)$`)
+	outpostIDPattern         = regexp.MustCompile(`^op-[0-9a-f]+$`)
+)
+
+// OutpostsBucket identifies a bucket on S3 on Outposts
+type OutpostsBucket struct {
+	ARN       string
+	Region    string
+	AccountID string
+	OutpostID string
+	Name      string
+}
+
+// ParseOutpostsBucketARN parses an S3 on Outposts bucket ARN
+func ParseOutpostsBucketARN(arn string) (OutpostsBucket, bool) {
+	m := outpostsBucketARNPattern.FindStringSubmatch(arn)
+	if m == nil {
+		return OutpostsBucket{}, false
+	}
+	return OutpostsBucket{ARN: arn, Region: m[1], AccountID: m[2], OutpostID: m[3], Name: m[4]}, true
+}
+
+// Outpost is an Outpost whose buckets discovery inspects. Region and
+// AccountID default to the client's when empty.
+type Outpost struct {
+	ID        string
+	Region    string
+	AccountID string
+}
+
+// ParseOutpost accepts an Outpost ID (op-0123...) or Outpost ARN
+func ParseOutpost(s string) (Outpost, error) {
+	s = strings.TrimSpace(s)
+	if outpostIDPattern.MatchString(s) {
+		return Outpost{ID: s}, nil
+	}
+	if m := outpostARNPattern.FindStringSubmatch(s); m != nil {
+		return Outpost{ID: m[3], Region: m[1], AccountID: m[2]}, nil
+	}
+	return Outpost{}, fmt.Errorf("invalid Outpost %q (expected op-... or an Outpost ARN)", s)
+}
+
+// ParseOutposts parses a list of Outpost IDs or ARNs
+func ParseOutposts(values []string) ([]Outpost, error) {
+	outposts := make([]Outpost, 0, len(values))
+	for _, v := range values {
+		o, err := ParseOutpost(v)
+		if err != nil {
+			return nil, err
+		}
+		outposts = append(outposts, o)
+	}
+	return outposts, nil
+}
+
+// OutpostsAPI is the subset of the S3 Control client used for Outposts buckets
+type OutpostsAPI interface {
+	ListRegionalBuckets(ctx context.Context, params *s3control.ListRegionalBucketsInput, optFns ...func(*s3control.Options)) (*s3control.ListRegionalBucketsOutput, error)
+	GetBucket(ctx context.Context, params *s3control.GetBucketInput, optFns ...func(*s3control.Options)) (*s3control.GetBucketOutput, error)
+	GetBucketTagging(ctx context.Context, params *s3control.GetBucketTaggingInput, optFns ...func(*s3control.Options)) (*s3control.GetBucketTaggingOutput, error)
+	GetBucketVersioning(ctx context.Context, params *s3control.GetBucketVersioningInput, optFns ...func(*s3control.Options)) (*s3control.GetBucketVersioningOutput, error)
+	GetBucketLifecycleConfiguration(ctx context.Context, params *s3control.GetBucketLifecycleConfigurationInput, optFns ...func(*s3control.Options)) (*s3control.GetBucketLifecycleConfigurationOutput, error)
+}
+
+// SetOutposts makes discovery also inspect the buckets on these Outposts
+func (i *Inspector) SetOutposts(outposts []Outpost) {
+	i.outposts = outposts
+}
+
+// outpostsClient returns the S3 Control client for an Outpost's home region
+func (i *Inspector) outpostsClient(region string) OutpostsAPI {
+	if i.newOutpostsAPI != nil {
+		return i.newOutpostsAPI(region)
+	}
+	cfg := i.client.config.Copy()
+	cfg.Region = region
+	return s3control.NewFromConfig(cfg)
+}
+
+// discoverOutposts adds the buckets on each configured Outpost to buckets,
+// keyed by ARN so they never clash with a regional bucket or its checkpoint
+// entry
+func (i *Inspector) discoverOutposts(ctx context.Context, buckets map[string]*BucketInfo) error {
+	var accountID string
+	for _, outpost := range i.outposts {
+		region := outpost.Region
+		if region == "" {
+			region = i.client.GetRegion()
+		}
+		account := outpost.AccountID
+		if account == "" {
+			if accountID == "" {
+				id, err := i.client.AccountID(ctx)
+				if err != nil {
+					return fmt.Errorf("failed to resolve account for Outpost %s: %w", outpost.ID, err)
+				}
+				accountID = id
+			}
+			account = accountID
+		}
+
+		api := i.outpostsClient(region)
+		paginator := s3control.NewListRegionalBucketsPaginator(api, &s3control.ListRegionalBucketsInput{
+			AccountId: aws.String(account),
+			OutpostId: aws.String(outpost.ID),
+		})
+		for paginator.HasMorePages() {
+			page, err := paginator.NextPage(ctx)
+			if err != nil {
+				return fmt.Errorf("failed to list buckets on Outpost %s: %w", outpost.ID, err)
+			}
+			for _, rb := range page.RegionalBucketList {
+				name := aws.ToString(rb.Bucket)
+				if name == "" || !i.filter.Match(name) {
+					continue
+				}
+				bucket := OutpostsBucket{
+					ARN:       aws.ToString(rb.BucketArn),
+					Region:    region,
+					AccountID: account,
+					OutpostID: outpost.ID,
+					Name:      name,
+				}
+				key := bucket.ARN
+				info, ok := i.checkpoint.lookup(key)
+				if !ok {
+					info = i.inspectOutpostsBucket(ctx, api, bucket, rb.CreationDate)
+					if info == nil {
+						continue
+					}
+					info.Name = key
+					if ctx.Err() == nil {
+						i.checkpoint.record(info)
+					}
+				}
+				if info == nil {
+					continue
+				}
+				buckets[key] = info
+			}
+		}
+	}
+	return nil
+}
+
+// inspectOutpostsBucketARN inspects a bucket referenced by its Outposts ARN
+func (i *Inspector) inspectOutpostsBucketARN(ctx context.Context, arn string) *BucketInfo {
+	bucket, ok := ParseOutpostsBucketARN(arn)
+	if !ok {
+		return &BucketInfo{Name: arn, Error: fmt.Sprintf("invalid Outposts bucket ARN %s", arn)}
+	}
+	api := i.outpostsClient(bucket.Region)
+
+	var created *time.Time
+	err := i.client.WithRetry(ctx, func() error {
+		out, err := api.GetBucket(ctx, &s3control.GetBucketInput{
+			AccountId: aws.String(bucket.AccountID),
+			Bucket:    aws.String(bucket.ARN),
+		})
+		if err == nil {
+			created = out.CreationDate
+		}
+		return err
+	})
+	if err != nil {
+		info := &BucketInfo{Name: bucket.Name, Region: bucket.Region, AccountID: bucket.AccountID, OutpostID: bucket.OutpostID}
+		if !strings.Contains(err.Error(), "NoSuchBucket") && !strings.Contains(err.Error(), "NoSuchOutpost") {
+			info.Error = formatError("get Outposts bucket", bucket.ARN, err)
+		}
+		return info
+	}
+	return i.inspectOutpostsBucket(ctx, api, bucket, created)
+}
+
+// inspectOutpostsBucket reads an Outposts bucket's configuration through S3
+// Control. Object listing needs an access point, so activity and size are
+// not collected. Returns nil if the bucket does not match the tag filters.
+func (i *Inspector) inspectOutpostsBucket(ctx context.Context, api OutpostsAPI, bucket OutpostsBucket, created *time.Time) *BucketInfo {
+	info := &BucketInfo{
+		Name:      bucket.Name,
+		Exists:    true,
+		Region:    bucket.Region,
+		AccountID: bucket.AccountID,
+		OutpostID: bucket.OutpostID,
+		// S3 on Outposts always encrypts with SSE-S3 and blocks public access
+		Encryption: &EncryptionInfo{Enabled: true, Algorithm: "AES256"},
+		PublicAccess: &PublicAccessInfo{
+			BlockPublicAcls:       true,
+			IgnorePublicAcls:      true,
+			BlockPublicPolicy:     true,
+			RestrictPublicBuckets: true,
+		},
+	}
+	if created != nil {
+		info.CreationDate = created
+		info.AgeInDays = int(time.Since(*created).Hours() / 24)
+	}
+	account := aws.String(bucket.AccountID)
+	arn := aws.String(bucket.ARN)
+
+	_ = i.client.WithRetry(ctx, func() error {
+		out, err := api.GetBucketTagging(ctx, &s3control.GetBucketTaggingInput{AccountId: account, Bucket: arn})
+		if err == nil && len(out.TagSet) > 0 {
+			info.Tags = make(map[string]string, len(out.TagSet))
+			for _, tag := range out.TagSet {
+				info.Tags[aws.ToString(tag.Key)] = aws.ToString(tag.Value)
+			}
+		}
+		if err != nil && strings.Contains(err.Error(), "NoSuchTagSet") {
+			return nil
+		}
+		return err
+	})
+	if len(i.tagFilters) > 0 && !MatchTags(i.tagFilters, info.Tags) {
+		return nil
+	}
+
+	_ = i.client.WithRetry(ctx, func() error {
+		out, err := api.GetBucketVersioning(ctx, &s3control.GetBucketVersioningInput{AccountId: account, Bucket: arn})
+		if err == nil {
+			info.VersioningEnabled = out.Status == s3controltypes.BucketVersioningStatusEnabled
+		}
+		return err
+	})
+
+	_ = i.client.WithRetry(ctx, func() error {
+		out, err := api.GetBucketLifecycleConfiguration(ctx, &s3control.GetBucketLifecycleConfigurationInput{AccountId: account, Bucket: arn})
+		if err == nil {
+			info.LifecycleRules = len(out.Rules)
+		}
+		if err != nil && strings.Contains(err.Error(), "NoSuchLifecycleConfiguration") {
+			return nil
+		}
+		return err
+	})
+
+	return info
+}
+
+// outpostsARN returns the Outposts ARN among a bucket's references, if any
+func outpostsARN(refs []scanner.Reference) string {
+	for _, ref := range refs {
+		if ref.OutpostsARN != "" {
+			return ref.OutpostsARN
+		}
+	}
+	return ""
+}
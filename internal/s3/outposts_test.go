@@ -0,0 +1,185 @@
+package s3
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3control"
+	s3controltypes "github.com/aws/aws-sdk-go-v2/service/s3control/types"
+	"github.com/ppiankov/s3spectre/internal/scanner"
+)
+
+const testOutpostsARN = "arn:aws:s3-outposts:us-west-2:123456789012:outpost/op-01ac5d28a6a232904/bucket/edge-data"
+
+// fakeOutpostsAPI serves Outposts buckets keyed by ARN
+type fakeOutpostsAPI struct {
+	buckets map[string]s3controltypes.RegionalBucket
+	tags    map[string][]s3controltypes.S3Tag
+	rules   map[string]int
+}
+
+func (f *fakeOutpostsAPI) ListRegionalBuckets(_ context.Context, in *s3control.ListRegionalBucketsInput, _ ...func(*s3control.Options)) (*s3control.ListRegionalBucketsOutput, error) {
+	out := &s3control.ListRegionalBucketsOutput{}
+	for _, b := range f.buckets {
+		if aws.ToString(b.OutpostId) == aws.ToString(in.OutpostId) {
+			out.RegionalBucketList = append(out.RegionalBucketList, b)
+		}
+	}
+	return out, nil
+}
+
+func (f *fakeOutpostsAPI) GetBucket(_ context.Context, in *s3control.GetBucketInput, _ ...func(*s3control.Options)) (*s3control.GetBucketOutput, error) {
+	b, ok := f.buckets[aws.ToString(in.Bucket)]
+	if !ok {
+		return nil, errors.New("api error NoSuchBucket: The specified bucket does not exist")
+	}
+	return &s3control.GetBucketOutput{Bucket: b.Bucket, CreationDate: b.CreationDate}, nil
+}
+
+func (f *fakeOutpostsAPI) GetBucketTagging(_ context.Context, in *s3control.GetBucketTaggingInput, _ ...func(*s3control.Options)) (*s3control.GetBucketTaggingOutput, error) {
+	tags, ok := f.tags[aws.ToString(in.Bucket)]
+	if !ok {
+		return nil, errors.New("api error NoSuchTagSet: The TagSet does not exist")
+	}
+	return &s3control.GetBucketTaggingOutput{TagSet: tags}, nil
+}
+
+func (f *fakeOutpostsAPI) GetBucketVersioning(context.Context, *s3control.GetBucketVersioningInput, ...func(*s3control.Options)) (*s3control.GetBucketVersioningOutput, error) {
+	return &s3control.GetBucketVersioningOutput{Status: s3controltypes.BucketVersioningStatusEnabled}, nil
+}
+
+func (f *fakeOutpostsAPI) GetBucketLifecycleConfiguration(_ context.Context, in *s3control.GetBucketLifecycleConfigurationInput, _ ...func(*s3control.Options)) (*s3control.GetBucketLifecycleConfigurationOutput, error) {
+	n, ok := f.rules[aws.ToString(in.Bucket)]
+	if !ok {
+		return nil, errors.New("api error NoSuchLifecycleConfiguration: The lifecycle configuration does not exist")
+	}
+	return &s3control.GetBucketLifecycleConfigurationOutput{Rules: make([]s3controltypes.LifecycleRule, n)}, nil
+}
+
+func newFakeOutpostsAPI() *fakeOutpostsAPI {
+	created := time.Now().Add(-10 * 24 * time.Hour)
+	return &fakeOutpostsAPI{
+		buckets: map[string]s3controltypes.RegionalBucket{
+			testOutpostsARN: {
+				Bucket:       aws.String("edge-data"),
+				BucketArn:    aws.String(testOutpostsARN),
+				OutpostId:    aws.String("op-01ac5d28a6a232904"),
+				CreationDate: &created,
+			},
+		},
+		tags:  map[string][]s3controltypes.S3Tag{testOutpostsARN: {{Key: aws.String("team"), Value: aws.String("edge")}}},
+		rules: map[string]int{testOutpostsARN: 2},
+	}
+}
+
+func TestParseOutpostsBucketARN(t *testing.T) {
+	bucket, ok := ParseOutpostsBucketARN(testOutpostsARN)
+	if !ok {
+		t.Fatalf("expected ARN to parse")
+	}
+	if bucket.Region != "us-west-2" || bucket.AccountID != "123456789012" || bucket.OutpostID != "op-01ac5d28a6a232904" || bucket.Name != "edge-data" {
+		t.Fatalf("unexpected parse result: %+v", bucket)
+	}
+	if _, ok := ParseOutpostsBucketARN("arn:aws:s3:::regular-bucket"); ok {
+		t.Fatalf("expected regional bucket ARN to be rejected")
+	}
+}
+
+func TestParseOutpost(t *testing.T) {
+	o, err := ParseOutpost("op-01ac5d28a6a232904")
+	if err != nil || o.ID != "op-01ac5d28a6a232904" || o.Region != "" {
+		t.Fatalf("unexpected result for ID: %+v, %v", o, err)
+	}
+	o, err = ParseOutpost("arn:aws:outposts:us-west-2:123456789012:outpost/op-01ac5d28a6a232904")
+	if err != nil || o.Region != "us-west-2" || o.AccountID != "123456789012" {
+		t.Fatalf("unexpected result for ARN: %+v, %v", o, err)
+	}
+	if _, err := ParseOutpost("outpost-1"); err == nil {
+		t.Fatalf("expected error for invalid Outpost")
+	}
+}
+
+func TestInspectOutpostsBucketARN(t *testing.T) {
+	api := newFakeOutpostsAPI()
+	inspector := NewInspector(&Client{config: aws.Config{Region: "us-east-1"}}, 1)
+	inspector.newOutpostsAPI = func(region string) OutpostsAPI {
+		if region != "us-west-2" {
+			t.Fatalf("expected the ARN's region, got %s", region)
+		}
+		return api
+	}
+
+	info := inspector.inspectOutpostsBucketARN(context.Background(), testOutpostsARN)
+	if !info.Exists || info.OutpostID != "op-01ac5d28a6a232904" || info.AccountID != "123456789012" {
+		t.Fatalf("unexpected bucket info: %+v", info)
+	}
+	if !info.VersioningEnabled || info.LifecycleRules != 2 || info.Tags["team"] != "edge" || info.AgeInDays != 10 {
+		t.Fatalf("expected configuration from S3 Control, got %+v", info)
+	}
+	if info.Encryption == nil || !info.Encryption.Enabled || info.PublicAccess == nil || info.PublicAccess.IsPublic {
+		t.Fatalf("expected Outposts encryption and public access defaults, got %+v", info)
+	}
+
+	missing := inspector.inspectOutpostsBucketARN(context.Background(),
+		"arn:aws:s3-outposts:us-west-2:123456789012:outpost/op-01ac5d28a6a232904/bucket/gone")
+	if missing.Exists || missing.Error != "" {
+		t.Fatalf("expected missing bucket without error, got %+v", missing)
+	}
+}
+
+func TestOutpostsARN(t *testing.T) {
+	refs := []scanner.Reference{{Bucket: "edge-data"}, {Bucket: "edge-data", OutpostsARN: testOutpostsARN}}
+	if got := outpostsARN(refs); got != testOutpostsARN {
+		t.Fatalf("expected Outposts ARN, got %q", got)
+	}
+	if got := outpostsARN(refs[:1]); got != "" {
+		t.Fatalf("expected no ARN, got %q", got)
+	}
+}
+
+func TestDiscoverOutposts(t *testing.T) {
+	api := newFakeOutpostsAPI()
+	inspector := NewInspector(&Client{config: aws.Config{Region: "us-west-2"}}, 1)
+	inspector.newOutpostsAPI = func(string) OutpostsAPI { return api }
+	inspector.SetOutposts([]Outpost{{ID: "op-01ac5d28a6a232904", AccountID: "123456789012"}})
+
+	// A regional bucket with the same name is kept alongside
+	buckets := map[string]*BucketInfo{"edge-data": {Name: "edge-data", Exists: true}}
+	if err := inspector.discoverOutposts(context.Background(), buckets); err != nil {
+		t.Fatalf("discoverOutposts failed: %v", err)
+	}
+	info := buckets[testOutpostsARN]
+	if info == nil || info.Name != testOutpostsARN || info.OutpostID != "op-01ac5d28a6a232904" {
+		t.Fatalf("expected Outposts bucket keyed by ARN, got %+v", buckets)
+	}
+	if buckets["edge-data"].OutpostID != "" {
+		t.Fatalf("regional bucket must not be replaced")
+	}
+
+	inspector.SetTagFilters([]TagFilter{{Key: "team", Value: "data"}})
+	filtered := map[string]*BucketInfo{}
+	if err := inspector.discoverOutposts(context.Background(), filtered); err != nil {
+		t.Fatalf("discoverOutposts failed: %v", err)
+	}
+	if len(filtered) != 0 {
+		t.Fatalf("expected tag filter to skip the bucket, got %+v", filtered)
+	}
+
+	// A resumed run does not take the regional bucket's checkpoint entry
+	// for the Outposts bucket, nor a nil entry for a bucket
+	inspector.SetTagFilters(nil)
+	inspector.SetCheckpoint(&Checkpoint{Buckets: map[string]*BucketInfo{
+		"edge-data":     {Name: "edge-data", Exists: true},
+		testOutpostsARN: nil,
+	}})
+	resumed := map[string]*BucketInfo{}
+	if err := inspector.discoverOutposts(context.Background(), resumed); err != nil {
+		t.Fatalf("discoverOutposts failed: %v", err)
+	}
+	if len(resumed) != 0 {
+		t.Fatalf("expected the nil checkpoint entry skipped, got %+v", resumed)
+	}
+}
@@ -0,0 +1,207 @@
+package s3
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/iam"
+	iamtypes "github.com/aws/aws-sdk-go-v2/service/iam/types"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/ppiankov/s3spectre/internal/scanner"
+)
+
+// principalARNPattern matches IAM role and user ARNs, with optional paths
+var principalARNPattern = regexp.MustCompile(`^arn:(aws[a-z-]*):iam::\d{12}:(role|user)/[\w+=,.@/-]+$`)
+
+// ValidatePrincipals checks that every principal is an IAM role or user ARN
+func ValidatePrincipals(principals []string) error {
+	for _, p := range principals {
+		if !principalARNPattern.MatchString(p) {
+			return fmt.Errorf("invalid principal %q (expected an IAM role or user ARN)", p)
+		}
+	}
+	return nil
+}
+
+// IAMAPI is the subset of the IAM client used for policy simulation
+type IAMAPI interface {
+	SimulatePrincipalPolicy(ctx context.Context, params *iam.SimulatePrincipalPolicyInput, optFns ...func(*iam.Options)) (*iam.SimulatePrincipalPolicyOutput, error)
+}
+
+// AccessCheck is the simulated decision for one principal performing the
+// operation of one code reference
+type AccessCheck struct {
+	Principal string `json:"principal"`
+	Action    string `json:"action"`
+	Resource  string `json:"resource"`
+	Bucket    string `json:"bucket"`
+	Prefix    string `json:"prefix,omitempty"`
+	// Decision is allowed, implicitDeny, explicitDeny, or unknown when the
+	// bucket policy could not be read
+	Decision string `json:"decision"`
+	File     string `json:"file"`
+	Line     int    `json:"line"`
+}
+
+// DecisionUnknown is the decision of checks that were not simulated because
+// the bucket policy could not be read; without it a simulation could report
+// access the policy grants as denied
+const DecisionUnknown = "unknown"
+
+// Allowed reports whether the simulation allowed the action
+func (c AccessCheck) Allowed() bool {
+	return c.Decision == string(iamtypes.PolicyEvaluationDecisionTypeAllowed)
+}
+
+// contextActions maps a reference's operation context to the S3 action it needs
+var contextActions = map[string]string{
+	string(scanner.RefTypeRead):  "s3:GetObject",
+	string(scanner.RefTypeWrite): "s3:PutObject",
+	string(scanner.RefTypeList):  "s3:ListBucket",
+}
+
+// iamClient returns the IAM client; IAM is global so the region is irrelevant
+func (i *Inspector) iamClient() IAMAPI {
+	if i.newIAMAPI != nil {
+		return i.newIAMAPI()
+	}
+	return iam.NewFromConfig(i.client.config)
+}
+
+// SimulateAccess runs IAM policy simulation for every principal against the
+// operation of each reference to an existing bucket. References without a
+// read, write or list context are skipped, as are Outposts buckets. The
+// bucket policy is included in the simulation; checks on buckets whose
+// policy cannot be read are not simulated and have DecisionUnknown.
+func (i *Inspector) SimulateAccess(ctx context.Context, principals []string, refs []scanner.Reference, buckets map[string]*BucketInfo) ([]AccessCheck, error) {
+	api := i.iamClient()
+	policies := make(map[string]string)
+	unreadable := make(map[string]bool)
+	seen := make(map[string]bool)
+	var checks []AccessCheck
+
+	for _, ref := range refs {
+		action, ok := contextActions[ref.Context]
+		info := buckets[ref.Bucket]
+		if !ok || ref.OutpostsARN != "" || info == nil || !info.Exists || info.OutpostID != "" {
+			continue
+		}
+		policy, fetched := policies[ref.Bucket]
+		if !fetched && !unreadable[ref.Bucket] {
+			var err error
+			if policy, err = i.bucketPolicy(ctx, info); err != nil {
+				if ctx.Err() != nil {
+					return nil, ctx.Err()
+				}
+				unreadable[ref.Bucket] = true
+			} else {
+				policies[ref.Bucket] = policy
+			}
+		}
+
+		for _, principal := range principals {
+			partition := strings.SplitN(principal, ":", 3)[1]
+			resource, contextEntries := accessResource(partition, action, ref.Bucket, ref.Prefix)
+			key := principal + "|" + action + "|" + resource
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			check := AccessCheck{
+				Principal: principal,
+				Action:    action,
+				Resource:  resource,
+				Bucket:    ref.Bucket,
+				Prefix:    ref.Prefix,
+				Decision:  DecisionUnknown,
+				File:      ref.File,
+				Line:      ref.Line,
+			}
+			if unreadable[ref.Bucket] {
+				checks = append(checks, check)
+				continue
+			}
+
+			input := &iam.SimulatePrincipalPolicyInput{
+				PolicySourceArn: aws.String(principal),
+				ActionNames:     []string{action},
+				ResourceArns:    []string{resource},
+				ContextEntries:  contextEntries,
+			}
+			if policy != "" {
+				input.ResourcePolicy = aws.String(policy)
+				if info.AccountID != "" {
+					input.ResourceOwner = aws.String(fmt.Sprintf("arn:%s:iam::%s:root", partition, info.AccountID))
+				}
+			}
+
+			var out *iam.SimulatePrincipalPolicyOutput
+			err := i.client.WithRetry(ctx, func() error {
+				var err error
+				out, err = api.SimulatePrincipalPolicy(ctx, input)
+				return err
+			})
+			if err != nil {
+				return nil, fmt.Errorf("failed to simulate %s for %s: %w", action, principal, err)
+			}
+
+			check.Decision = string(iamtypes.PolicyEvaluationDecisionTypeImplicitDeny)
+			for _, result := range out.EvaluationResults {
+				if aws.ToString(result.EvalActionName) == action {
+					check.Decision = string(result.EvalDecision)
+				}
+			}
+			checks = append(checks, check)
+		}
+	}
+
+	sort.SliceStable(checks, func(a, b int) bool {
+		if checks[a].Principal != checks[b].Principal {
+			return checks[a].Principal < checks[b].Principal
+		}
+		return checks[a].Resource < checks[b].Resource
+	})
+	return checks, nil
+}
+
+// accessResource returns the resource ARN an action is simulated against.
+// Object actions target the prefix's keys; ListBucket targets the bucket with
+// the prefix as the s3:prefix condition key.
+func accessResource(partition, action, bucket, prefix string) (string, []iamtypes.ContextEntry) {
+	if action == "s3:ListBucket" {
+		arn := fmt.Sprintf("arn:%s:s3:::%s", partition, bucket)
+		if prefix == "" {
+			return arn, nil
+		}
+		return arn, []iamtypes.ContextEntry{{
+			ContextKeyName:   aws.String("s3:prefix"),
+			ContextKeyType:   iamtypes.ContextKeyTypeEnumString,
+			ContextKeyValues: []string{prefix},
+		}}
+	}
+	return fmt.Sprintf("arn:%s:s3:::%s/%s*", partition, bucket, prefix), nil
+}
+
+// bucketPolicy returns a bucket's policy document, or "" when it has none
+func (i *Inspector) bucketPolicy(ctx context.Context, info *BucketInfo) (string, error) {
+	client := i.client
+	if info.Region != "" && info.Region != client.GetRegion() {
+		client = client.ForRegion(info.Region)
+	}
+	var policy string
+	err := client.WithRetry(ctx, func() error {
+		out, err := client.s3Client.GetBucketPolicy(ctx, &s3.GetBucketPolicyInput{Bucket: aws.String(info.Name)})
+		if err == nil {
+			policy = aws.ToString(out.Policy)
+		}
+		return err
+	})
+	if isNoSuchBucketPolicy(err) {
+		return "", nil
+	}
+	return policy, err
+}
@@ -0,0 +1,167 @@
+package s3
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/iam"
+	iamtypes "github.com/aws/aws-sdk-go-v2/service/iam/types"
+	"github.com/ppiankov/s3spectre/internal/scanner"
+)
+
+const (
+	testReaderRole = "arn:aws:iam::123456789012:role/app-reader"
+	testWriterRole = "arn:aws:iam::123456789012:role/app-writer"
+)
+
+// fakeIAMAPI allows the actions listed per principal and records every input
+type fakeIAMAPI struct {
+	allowed map[string][]string
+	inputs  []*iam.SimulatePrincipalPolicyInput
+}
+
+func (f *fakeIAMAPI) SimulatePrincipalPolicy(_ context.Context, in *iam.SimulatePrincipalPolicyInput, _ ...func(*iam.Options)) (*iam.SimulatePrincipalPolicyOutput, error) {
+	f.inputs = append(f.inputs, in)
+	decision := iamtypes.PolicyEvaluationDecisionTypeImplicitDeny
+	for _, action := range f.allowed[aws.ToString(in.PolicySourceArn)] {
+		if action == in.ActionNames[0] {
+			decision = iamtypes.PolicyEvaluationDecisionTypeAllowed
+		}
+	}
+	return &iam.SimulatePrincipalPolicyOutput{EvaluationResults: []iamtypes.EvaluationResult{{
+		EvalActionName:   aws.String(in.ActionNames[0]),
+		EvalResourceName: aws.String(in.ResourceArns[0]),
+		EvalDecision:     decision,
+	}}}, nil
+}
+
+func TestValidatePrincipals(t *testing.T) {
+	valid := []string{testReaderRole, "arn:aws-us-gov:iam::123456789012:role/service/etl", "arn:aws:iam::123456789012:user/ci"}
+	if err := ValidatePrincipals(valid); err != nil {
+		t.Fatalf("expected valid principals, got %v", err)
+	}
+	if err := ValidatePrincipals([]string{"app-reader"}); err == nil {
+		t.Fatalf("expected error for a bare role name")
+	}
+}
+
+func TestSimulateAccess(t *testing.T) {
+	const policy = `{"Version":"2012-10-17","Statement":[]}`
+	rt := roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		if strings.Contains(req.URL.Path, "/no-policy") {
+			return &http.Response{
+				StatusCode: http.StatusNotFound,
+				Header:     http.Header{"Content-Type": []string{"application/xml"}},
+				Body:       io.NopCloser(strings.NewReader(`<Error><Code>NoSuchBucketPolicy</Code></Error>`)),
+			}, nil
+		}
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     http.Header{"Content-Type": []string{"application/json"}},
+			Body:       io.NopCloser(strings.NewReader(policy)),
+		}, nil
+	})
+	inspector := NewInspector(newTestClient(t, rt), 1)
+	api := &fakeIAMAPI{allowed: map[string][]string{
+		testReaderRole: {"s3:GetObject", "s3:ListBucket"},
+		testWriterRole: {"s3:PutObject"},
+	}}
+	inspector.newIAMAPI = func() IAMAPI { return api }
+
+	refs := []scanner.Reference{
+		{Bucket: "data", Prefix: "reports/", Context: "read", File: "app.py", Line: 3},
+		{Bucket: "no-policy", Context: "write", File: "app.py", Line: 7},
+		{Bucket: "data", Prefix: "exports/", Context: "list", File: "app.py", Line: 9},
+		{Bucket: "data", Context: "unknown", File: "app.py", Line: 11},
+		{Bucket: "gone", Context: "read", File: "app.py", Line: 12},
+	}
+	buckets := map[string]*BucketInfo{
+		"data":      {Name: "data", Exists: true, Region: "us-east-1", AccountID: "123456789012"},
+		"no-policy": {Name: "no-policy", Exists: true, Region: "us-east-1"},
+		"gone":      {Name: "gone"},
+	}
+
+	checks, err := inspector.SimulateAccess(context.Background(), []string{testReaderRole, testWriterRole}, refs, buckets)
+	if err != nil {
+		t.Fatalf("SimulateAccess failed: %v", err)
+	}
+	if len(checks) != 6 {
+		t.Fatalf("expected 3 references x 2 principals, got %+v", checks)
+	}
+
+	denied := map[string]bool{}
+	for _, c := range checks {
+		if !c.Allowed() {
+			denied[c.Principal+" "+c.Action+" "+c.Resource] = true
+		}
+	}
+	for _, want := range []string{
+		testWriterRole + " s3:GetObject arn:aws:s3:::data/reports/*",
+		testReaderRole + " s3:PutObject arn:aws:s3:::no-policy/*",
+		testWriterRole + " s3:ListBucket arn:aws:s3:::data",
+	} {
+		if !denied[want] {
+			t.Fatalf("expected %q to be denied, got %+v", want, denied)
+		}
+	}
+	if len(denied) != 3 {
+		t.Fatalf("expected 3 denied checks, got %+v", denied)
+	}
+
+	for _, in := range api.inputs {
+		resource := in.ResourceArns[0]
+		switch {
+		case strings.HasPrefix(resource, "arn:aws:s3:::no-policy"):
+			if in.ResourcePolicy != nil {
+				t.Fatalf("expected no resource policy for a bucket without one")
+			}
+		case in.ActionNames[0] == "s3:ListBucket":
+			if len(in.ContextEntries) != 1 || in.ContextEntries[0].ContextKeyValues[0] != "exports/" {
+				t.Fatalf("expected s3:prefix context for ListBucket, got %+v", in.ContextEntries)
+			}
+		default:
+			if aws.ToString(in.ResourcePolicy) != policy || aws.ToString(in.ResourceOwner) != "arn:aws:iam::123456789012:root" {
+				t.Fatalf("expected bucket policy and owner in simulation, got %+v", in)
+			}
+		}
+	}
+}
+
+func TestSimulateAccess_UnreadablePolicy(t *testing.T) {
+	rt := roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		return &http.Response{
+			StatusCode: http.StatusForbidden,
+			Header:     http.Header{"Content-Type": []string{"application/xml"}},
+			Body:       io.NopCloser(strings.NewReader(`<Error><Code>AccessDenied</Code></Error>`)),
+		}, nil
+	})
+	inspector := NewInspector(newTestClient(t, rt), 1)
+	api := &fakeIAMAPI{}
+	inspector.newIAMAPI = func() IAMAPI { return api }
+
+	refs := []scanner.Reference{
+		{Bucket: "locked", Context: "read", File: "app.py", Line: 3},
+		{Bucket: "locked", Prefix: "out/", Context: "write", File: "app.py", Line: 5},
+	}
+	buckets := map[string]*BucketInfo{"locked": {Name: "locked", Exists: true, Region: "us-east-1"}}
+
+	checks, err := inspector.SimulateAccess(context.Background(), []string{testReaderRole}, refs, buckets)
+	if err != nil {
+		t.Fatalf("SimulateAccess failed: %v", err)
+	}
+	if len(checks) != 2 {
+		t.Fatalf("expected a check per reference, got %+v", checks)
+	}
+	for _, c := range checks {
+		if c.Decision != DecisionUnknown {
+			t.Errorf("check on a bucket with an unreadable policy = %q, want unknown", c.Decision)
+		}
+	}
+	if len(api.inputs) != 0 {
+		t.Errorf("expected no simulation without the bucket policy, got %d calls", len(api.inputs))
+	}
+}
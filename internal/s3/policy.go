@@ -0,0 +1,299 @@
+package s3
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/smithy-go"
+)
+
+// PolicyAPI is the subset of the S3 client used to read bucket policies
+type PolicyAPI interface {
+	GetBucketPolicy(ctx context.Context, params *s3.GetBucketPolicyInput, optFns ...func(*s3.Options)) (*s3.GetBucketPolicyOutput, error)
+	GetBucketPolicyStatus(ctx context.Context, params *s3.GetBucketPolicyStatusInput, optFns ...func(*s3.Options)) (*s3.GetBucketPolicyStatusOutput, error)
+}
+
+// PolicyInfo is what a bucket policy grants. A bucket without a policy has
+// a PolicyInfo with Exists false, which still allows plain HTTP requests.
+type PolicyInfo struct {
+	Exists bool `json:"exists"`
+	// IsPublic is S3's own evaluation from GetBucketPolicyStatus
+	IsPublic bool `json:"is_public"`
+	// WildcardStatements are the Sids, or 1-based positions, of Allow
+	// statements granting any principal without a condition that limits
+	// the caller's account, organization, network or source
+	WildcardStatements []string `json:"wildcard_statements,omitempty"`
+	// CrossAccounts are the other accounts Allow statements grant access to;
+	// empty when the owning account is unknown
+	CrossAccounts []string `json:"cross_accounts,omitempty"`
+	// DeniesInsecureTransport is set when a Deny statement rejects requests
+	// with aws:SecureTransport false
+	DeniesInsecureTransport bool `json:"denies_insecure_transport"`
+}
+
+// restrictingConditionKeys limit a wildcard principal to known callers, so a
+// statement using one of them is not open to everyone
+var restrictingConditionKeys = map[string]bool{
+	"aws:principalaccount":  true,
+	"aws:principalarn":      true,
+	"aws:principalorgid":    true,
+	"aws:principalorgpaths": true,
+	"aws:sourceaccount":     true,
+	"aws:sourcearn":         true,
+	"aws:sourceorgid":       true,
+	"aws:sourceorgpaths":    true,
+	"aws:sourceowner":       true,
+	"aws:sourceip":          true,
+	"aws:sourcevpc":         true,
+	"aws:sourcevpce":        true,
+	"aws:userid":            true,
+	"aws:username":          true,
+}
+
+// principalAccountPattern finds the account of an IAM or STS principal ARN
+var principalAccountPattern = regexp.MustCompile(`^arn:aws[a-z\-]*:(?:iam|sts)::(\d{12}):`)
+
+// SetCheckPolicy makes inspection read each bucket's policy and policy
+// status into BucketInfo.Policy
+func (i *Inspector) SetCheckPolicy(enabled bool) {
+	i.checkPolicy = enabled
+}
+
+// SetAccountID sets the account that owns the inspected buckets. Bucket
+// policy grants to any other account are cross-account access.
+func (i *Inspector) SetAccountID(accountID string) {
+	i.accountID = accountID
+}
+
+// policyClient returns the client that reads a bucket's policy
+func (i *Inspector) policyClient(client *Client) PolicyAPI {
+	if i.newPolicyAPI != nil {
+		return i.newPolicyAPI(client.GetRegion())
+	}
+	return client.s3Client
+}
+
+// readPolicy sets info.Policy from the bucket policy and its policy status.
+// A policy that cannot be read or parsed leaves info.Policy nil.
+func (i *Inspector) readPolicy(ctx context.Context, client *Client, bucket string, info *BucketInfo) {
+	api := i.policyClient(client)
+	var document string
+	err := client.WithRetry(ctx, func() error {
+		out, err := api.GetBucketPolicy(ctx, &s3.GetBucketPolicyInput{Bucket: aws.String(bucket)})
+		if err == nil {
+			document = aws.ToString(out.Policy)
+		}
+		return err
+	})
+	if isNoSuchBucketPolicy(err) {
+		info.Policy = &PolicyInfo{}
+		return
+	}
+	if err != nil {
+		return
+	}
+	policy, err := ParsePolicy(document, i.accountID)
+	if err != nil {
+		return
+	}
+
+	_ = client.WithRetry(ctx, func() error {
+		out, err := api.GetBucketPolicyStatus(ctx, &s3.GetBucketPolicyStatusInput{Bucket: aws.String(bucket)})
+		if err == nil && out.PolicyStatus != nil {
+			policy.IsPublic = aws.ToBool(out.PolicyStatus.IsPublic)
+		}
+		return err
+	})
+	if ctx.Err() != nil {
+		return
+	}
+	info.Policy = policy
+}
+
+// isNoSuchBucketPolicy reports whether err says the bucket has no policy
+func isNoSuchBucketPolicy(err error) bool {
+	var apiErr smithy.APIError
+	return errors.As(err, &apiErr) && apiErr.ErrorCode() == "NoSuchBucketPolicy"
+}
+
+// policyDocument is an IAM policy document. Fields that may hold a string
+// or a list are kept raw.
+type policyDocument struct {
+	Statement json.RawMessage `json:"Statement"`
+}
+
+type policyStatement struct {
+	Sid          string                                `json:"Sid"`
+	Effect       string                                `json:"Effect"`
+	Principal    json.RawMessage                       `json:"Principal"`
+	NotPrincipal json.RawMessage                       `json:"NotPrincipal"`
+	Action       json.RawMessage                       `json:"Action"`
+	Condition    map[string]map[string]json.RawMessage `json:"Condition"`
+}
+
+// ParsePolicy reads a bucket policy document. Principals in accounts other
+// than owner are cross-account grants; an empty owner skips that check.
+func ParsePolicy(document, owner string) (*PolicyInfo, error) {
+	var doc policyDocument
+	if err := json.Unmarshal([]byte(document), &doc); err != nil {
+		return nil, fmt.Errorf("failed to parse bucket policy: %w", err)
+	}
+	var statements []policyStatement
+	if len(doc.Statement) > 0 && doc.Statement[0] == '{' {
+		var single policyStatement
+		if err := json.Unmarshal(doc.Statement, &single); err != nil {
+			return nil, fmt.Errorf("failed to parse bucket policy statement: %w", err)
+		}
+		statements = append(statements, single)
+	} else if len(doc.Statement) > 0 {
+		if err := json.Unmarshal(doc.Statement, &statements); err != nil {
+			return nil, fmt.Errorf("failed to parse bucket policy statements: %w", err)
+		}
+	}
+
+	policy := &PolicyInfo{Exists: true}
+	accounts := make(map[string]bool)
+	for n, st := range statements {
+		label := st.Sid
+		if label == "" {
+			label = "#" + strconv.Itoa(n+1)
+		}
+		switch {
+		case strings.EqualFold(st.Effect, "Deny"):
+			if deniesInsecureTransport(st) {
+				policy.DeniesInsecureTransport = true
+			}
+		case strings.EqualFold(st.Effect, "Allow"):
+			// NotPrincipal in an Allow grants everyone it does not name
+			if (len(st.NotPrincipal) > 0 || isWildcardPrincipal(st.Principal)) && !restrictsCaller(st) {
+				policy.WildcardStatements = append(policy.WildcardStatements, label)
+			}
+			if owner == "" {
+				continue
+			}
+			for _, principal := range awsPrincipals(st.Principal) {
+				if account := principalAccount(principal); account != "" && account != owner {
+					accounts[account] = true
+				}
+			}
+		}
+	}
+	for account := range accounts {
+		policy.CrossAccounts = append(policy.CrossAccounts, account)
+	}
+	sort.Strings(policy.CrossAccounts)
+	return policy, nil
+}
+
+// isWildcardPrincipal reports whether a principal is "*" or {"AWS": "*"}
+func isWildcardPrincipal(raw json.RawMessage) bool {
+	var s string
+	if json.Unmarshal(raw, &s) == nil {
+		return s == "*"
+	}
+	for _, principal := range awsPrincipals(raw) {
+		if principal == "*" {
+			return true
+		}
+	}
+	return false
+}
+
+// awsPrincipals returns the AWS principals of a Principal element; service,
+// federated and canonical user principals are not accounts
+func awsPrincipals(raw json.RawMessage) []string {
+	var principals map[string]json.RawMessage
+	if json.Unmarshal(raw, &principals) != nil {
+		return nil
+	}
+	return stringValues(principals["AWS"])
+}
+
+// principalAccount returns the account ID of an account or IAM principal
+// ARN, or "" for anything else
+func principalAccount(principal string) string {
+	if len(principal) == 12 && strings.Trim(principal, "0123456789") == "" {
+		return principal
+	}
+	if m := principalAccountPattern.FindStringSubmatch(principal); m != nil {
+		return m[1]
+	}
+	return ""
+}
+
+// restrictsCaller reports whether a statement's conditions limit who can
+// use it, such as aws:PrincipalOrgID or aws:SourceVpce
+func restrictsCaller(st policyStatement) bool {
+	for _, keys := range st.Condition {
+		for key := range keys {
+			if restrictingConditionKeys[strings.ToLower(key)] {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// deniesInsecureTransport reports whether a Deny statement rejects every
+// action, or every S3 action, when aws:SecureTransport is false
+func deniesInsecureTransport(st policyStatement) bool {
+	allActions := false
+	for _, action := range stringValues(st.Action) {
+		if action == "*" || strings.EqualFold(action, "s3:*") {
+			allActions = true
+		}
+	}
+	if !allActions {
+		return false
+	}
+	for operator, keys := range st.Condition {
+		if !strings.HasPrefix(strings.ToLower(operator), "bool") {
+			continue
+		}
+		for key, value := range keys {
+			if !strings.EqualFold(key, "aws:SecureTransport") {
+				continue
+			}
+			for _, v := range stringValues(value) {
+				if strings.EqualFold(v, "false") {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// stringValues reads a policy value that is a string, a boolean or a list
+// of them
+func stringValues(raw json.RawMessage) []string {
+	if len(raw) == 0 {
+		return nil
+	}
+	var list []any
+	if json.Unmarshal(raw, &list) != nil {
+		var single any
+		if json.Unmarshal(raw, &single) != nil {
+			return nil
+		}
+		list = []any{single}
+	}
+	values := make([]string, 0, len(list))
+	for _, v := range list {
+		switch v := v.(type) {
+		case string:
+			values = append(values, v)
+		case bool:
+			values = append(values, strconv.FormatBool(v))
+		}
+	}
+	return values
+}
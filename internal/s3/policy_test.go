@@ -0,0 +1,102 @@
+package s3
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/aws/smithy-go"
+)
+
+func TestParsePolicy(t *testing.T) {
+	document := `{
+  "Version": "2012-10-17",
+  "Statement": [
+    {"Sid": "PublicRead", "Effect": "Allow", "Principal": "*", "Action": "s3:GetObject", "Resource": "arn:aws:s3:::site/*"},
+    {"Effect": "Allow", "Principal": {"AWS": ["*"]}, "Action": "s3:GetObject", "Resource": "arn:aws:s3:::site/*",
+     "Condition": {"StringEquals": {"aws:PrincipalOrgID": "o-abc123"}}},
+    {"Sid": "Partner", "Effect": "Allow",
+     "Principal": {"AWS": ["arn:aws:iam::222222222222:role/reader", "333333333333", "arn:aws:iam::111111111111:root"]},
+     "Action": ["s3:GetObject", "s3:ListBucket"], "Resource": "*"},
+    {"Sid": "Logs", "Effect": "Allow", "Principal": {"Service": "logging.s3.amazonaws.com"}, "Action": "s3:PutObject", "Resource": "*"},
+    {"Sid": "TLS", "Effect": "Deny", "Principal": "*", "Action": "s3:*", "Resource": "*",
+     "Condition": {"Bool": {"aws:SecureTransport": false}}}
+  ]
+}`
+	policy, err := ParsePolicy(document, "111111111111")
+	if err != nil {
+		t.Fatalf("ParsePolicy failed: %v", err)
+	}
+	if !policy.Exists || !policy.DeniesInsecureTransport {
+		t.Errorf("policy = %+v", policy)
+	}
+	if want := []string{"PublicRead"}; !reflect.DeepEqual(policy.WildcardStatements, want) {
+		t.Errorf("wildcard statements = %v, want %v", policy.WildcardStatements, want)
+	}
+	if want := []string{"222222222222", "333333333333"}; !reflect.DeepEqual(policy.CrossAccounts, want) {
+		t.Errorf("cross accounts = %v, want %v", policy.CrossAccounts, want)
+	}
+
+	unknown, err := ParsePolicy(document, "")
+	if err != nil || len(unknown.CrossAccounts) != 0 {
+		t.Errorf("an unknown owner should skip cross-account grants, got %v, %v", unknown, err)
+	}
+}
+
+func TestParsePolicy_SingleStatement(t *testing.T) {
+	policy, err := ParsePolicy(`{"Statement": {"Effect": "Allow", "NotPrincipal": {"AWS": "arn:aws:iam::111111111111:root"}, "Action": "s3:*"}}`, "111111111111")
+	if err != nil {
+		t.Fatalf("ParsePolicy failed: %v", err)
+	}
+	if !reflect.DeepEqual(policy.WildcardStatements, []string{"#1"}) || policy.DeniesInsecureTransport {
+		t.Errorf("policy = %+v", policy)
+	}
+	if _, err := ParsePolicy("not json", ""); err == nil {
+		t.Errorf("expected a parse error")
+	}
+}
+
+// fakePolicyAPI returns a fixed policy and status
+type fakePolicyAPI struct {
+	policy   string
+	public   bool
+	noPolicy bool
+}
+
+func (f *fakePolicyAPI) GetBucketPolicy(_ context.Context, _ *s3.GetBucketPolicyInput, _ ...func(*s3.Options)) (*s3.GetBucketPolicyOutput, error) {
+	if f.noPolicy {
+		return nil, &smithy.GenericAPIError{Code: "NoSuchBucketPolicy", Message: "The bucket policy does not exist"}
+	}
+	return &s3.GetBucketPolicyOutput{Policy: aws.String(f.policy)}, nil
+}
+
+func (f *fakePolicyAPI) GetBucketPolicyStatus(_ context.Context, _ *s3.GetBucketPolicyStatusInput, _ ...func(*s3.Options)) (*s3.GetBucketPolicyStatusOutput, error) {
+	if f.noPolicy {
+		return nil, errors.New("unexpected policy status call")
+	}
+	return &s3.GetBucketPolicyStatusOutput{PolicyStatus: &types.PolicyStatus{IsPublic: aws.Bool(f.public)}}, nil
+}
+
+func TestReadPolicy(t *testing.T) {
+	client := &Client{config: aws.Config{Region: "us-east-1"}}
+	inspector := NewInspector(client, 1)
+	api := &fakePolicyAPI{policy: `{"Statement": [{"Effect": "Allow", "Principal": "*", "Action": "s3:GetObject"}]}`, public: true}
+	inspector.newPolicyAPI = func(string) PolicyAPI { return api }
+
+	info := &BucketInfo{}
+	inspector.readPolicy(context.Background(), client, "site", info)
+	if info.Policy == nil || !info.Policy.Exists || !info.Policy.IsPublic || len(info.Policy.WildcardStatements) != 1 {
+		t.Fatalf("policy = %+v", info.Policy)
+	}
+
+	api.noPolicy = true
+	info = &BucketInfo{}
+	inspector.readPolicy(context.Background(), client, "private", info)
+	if info.Policy == nil || info.Policy.Exists || info.Policy.DeniesInsecureTransport {
+		t.Fatalf("bucket without a policy = %+v", info.Policy)
+	}
+}
@@ -0,0 +1,184 @@
+package s3
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Progress tracks bucket inspection across workers: throughput, an estimate
+// of the time left and the AWS operation each in-flight bucket is waiting
+// on. It is safe for concurrent use, and a nil Progress tracks nothing.
+type Progress struct {
+	mu    sync.Mutex
+	now   func() time.Time
+	start time.Time
+	total int
+	// inspected counts buckets inspected since start; skipped ones came
+	// from a checkpoint and do not count towards throughput
+	inspected int
+	skipped   int
+	active    map[*bucketTask]struct{}
+	timings   []BucketTiming
+}
+
+// BucketTiming is how long one bucket took to inspect
+type BucketTiming struct {
+	Bucket          string  `json:"bucket"`
+	Region          string  `json:"region,omitempty"`
+	DurationSeconds float64 `json:"duration_seconds"`
+}
+
+// ActiveBucket is a bucket being inspected and the API operation it last
+// started, e.g. S3.ListObjectsV2
+type ActiveBucket struct {
+	Bucket    string
+	Operation string
+	Elapsed   time.Duration
+}
+
+// ProgressSnapshot is the state of an inspection at one moment
+type ProgressSnapshot struct {
+	Done    int
+	Total   int
+	Elapsed time.Duration
+	// Rate is buckets inspected per second; zero until the first finishes
+	Rate float64
+	// ETA is the estimated time left; zero when unknown
+	ETA time.Duration
+	// Active lists in-flight buckets, longest running first
+	Active []ActiveBucket
+}
+
+// bucketTask is one in-flight bucket; API calls made with its context
+// record their operation on it
+type bucketTask struct {
+	progress  *Progress
+	bucket    string
+	operation string
+	started   time.Time
+}
+
+// progressTaskKey holds the *bucketTask of a bucket's inspection context
+type progressTaskKey struct{}
+
+// NewProgress creates an empty progress tracker
+func NewProgress() *Progress {
+	return &Progress{now: time.Now, active: make(map[*bucketTask]struct{})}
+}
+
+// addTotal adds buckets to inspect; multi-account runs add one batch per
+// account
+func (p *Progress) addTotal(n int) {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.start.IsZero() {
+		p.start = p.now()
+	}
+	p.total += n
+}
+
+// skip counts a bucket reused from a checkpoint
+func (p *Progress) skip() {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	p.skipped++
+	p.mu.Unlock()
+}
+
+// begin starts timing a bucket. The returned context carries it so API
+// calls report their operation, and finish records its duration.
+func (p *Progress) begin(ctx context.Context, bucket, region string) (context.Context, func()) {
+	if p == nil {
+		return ctx, func() {}
+	}
+	p.mu.Lock()
+	task := &bucketTask{progress: p, bucket: bucket, started: p.now()}
+	p.active[task] = struct{}{}
+	p.mu.Unlock()
+	return context.WithValue(ctx, progressTaskKey{}, task), func() {
+		p.mu.Lock()
+		defer p.mu.Unlock()
+		delete(p.active, task)
+		p.inspected++
+		p.timings = append(p.timings, BucketTiming{
+			Bucket:          bucket,
+			Region:          region,
+			DurationSeconds: p.now().Sub(task.started).Seconds(),
+		})
+	}
+}
+
+// setOperation records the operation a bucket's inspection is waiting on
+func (t *bucketTask) setOperation(operation string) {
+	t.progress.mu.Lock()
+	t.operation = operation
+	t.progress.mu.Unlock()
+}
+
+// recordOperation notes an API call on the bucket task in ctx, if any
+func recordOperation(ctx context.Context, operation string) {
+	if task, ok := ctx.Value(progressTaskKey{}).(*bucketTask); ok {
+		task.setOperation(operation)
+	}
+}
+
+// Snapshot returns the progress so far
+func (p *Progress) Snapshot() ProgressSnapshot {
+	if p == nil {
+		return ProgressSnapshot{}
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	now := p.now()
+	s := ProgressSnapshot{Done: p.inspected + p.skipped, Total: p.total}
+	if !p.start.IsZero() {
+		s.Elapsed = now.Sub(p.start)
+	}
+	if p.inspected > 0 && s.Elapsed > 0 {
+		s.Rate = float64(p.inspected) / s.Elapsed.Seconds()
+		if remaining := p.total - s.Done; remaining > 0 {
+			s.ETA = time.Duration(float64(remaining) / s.Rate * float64(time.Second))
+		}
+	}
+	for task := range p.active {
+		s.Active = append(s.Active, ActiveBucket{
+			Bucket:    task.bucket,
+			Operation: task.operation,
+			Elapsed:   now.Sub(task.started),
+		})
+	}
+	sort.Slice(s.Active, func(i, j int) bool {
+		if s.Active[i].Elapsed != s.Active[j].Elapsed {
+			return s.Active[i].Elapsed > s.Active[j].Elapsed
+		}
+		return s.Active[i].Bucket < s.Active[j].Bucket
+	})
+	return s
+}
+
+// Slowest returns the n buckets that took longest to inspect, slowest first
+func (p *Progress) Slowest(n int) []BucketTiming {
+	if p == nil {
+		return nil
+	}
+	p.mu.Lock()
+	timings := append([]BucketTiming(nil), p.timings...)
+	p.mu.Unlock()
+	sort.Slice(timings, func(i, j int) bool {
+		if timings[i].DurationSeconds != timings[j].DurationSeconds {
+			return timings[i].DurationSeconds > timings[j].DurationSeconds
+		}
+		return timings[i].Bucket < timings[j].Bucket
+	})
+	if len(timings) > n {
+		timings = timings[:n]
+	}
+	return timings
+}
@@ -0,0 +1,126 @@
+package s3
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/smithy-go/middleware"
+)
+
+// fakeClock is a settable time source for progress tests
+type fakeClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	c.now = c.now.Add(d)
+	c.mu.Unlock()
+}
+
+func TestProgress_RateAndETA(t *testing.T) {
+	clock := &fakeClock{now: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)}
+	p := NewProgress()
+	p.now = clock.Now
+	p.addTotal(10)
+	p.skip() // reused from a checkpoint; not counted towards the rate
+
+	_, finishA := p.begin(context.Background(), "bucket-a", "us-east-1")
+	ctxB, finishB := p.begin(context.Background(), "bucket-b", "eu-west-1")
+	recordOperation(ctxB, "S3.ListObjectsV2")
+	clock.Advance(2 * time.Second)
+	finishA()
+
+	s := p.Snapshot()
+	if s.Done != 2 || s.Total != 10 {
+		t.Fatalf("Done/Total = %d/%d, want 2/10", s.Done, s.Total)
+	}
+	// 1 bucket inspected in 2s: 0.5/s, 8 left
+	if s.Rate != 0.5 || s.ETA != 16*time.Second {
+		t.Errorf("Rate = %v, ETA = %v; want 0.5 and 16s", s.Rate, s.ETA)
+	}
+	if len(s.Active) != 1 || s.Active[0].Bucket != "bucket-b" || s.Active[0].Operation != "S3.ListObjectsV2" || s.Active[0].Elapsed != 2*time.Second {
+		t.Errorf("unexpected active buckets: %+v", s.Active)
+	}
+
+	clock.Advance(3 * time.Second)
+	finishB()
+	slowest := p.Slowest(1)
+	if len(slowest) != 1 || slowest[0].Bucket != "bucket-b" || slowest[0].DurationSeconds != 5 || slowest[0].Region != "eu-west-1" {
+		t.Errorf("Slowest(1) = %+v", slowest)
+	}
+	if len(p.Snapshot().Active) != 0 {
+		t.Error("finished buckets should not be active")
+	}
+}
+
+func TestProgress_NilIsNoop(t *testing.T) {
+	var p *Progress
+	p.addTotal(3)
+	p.skip()
+	ctx, finish := p.begin(context.Background(), "b", "")
+	finish()
+	recordOperation(ctx, "S3.HeadBucket")
+	if s := p.Snapshot(); s.Total != 0 || p.Slowest(5) != nil {
+		t.Errorf("nil progress should track nothing: %+v", s)
+	}
+}
+
+func TestProgress_Concurrent(t *testing.T) {
+	p := NewProgress()
+	p.addTotal(50)
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ctx, finish := p.begin(context.Background(), "bucket", "")
+			recordOperation(ctx, "S3.GetBucketTagging")
+			_ = p.Snapshot()
+			finish()
+		}()
+	}
+	wg.Wait()
+	if s := p.Snapshot(); s.Done != 50 || len(s.Active) != 0 {
+		t.Errorf("expected 50 done and none active, got %+v", s)
+	}
+}
+
+func TestAPICounter_RecordsOperationOnBucket(t *testing.T) {
+	counter := newAPICounter()
+	cfg := aws.Config{
+		Region:      "us-east-1",
+		Credentials: aws.NewCredentialsCache(credentials.NewStaticCredentialsProvider("AKID", "SECRET", "")),
+		HTTPClient: &http.Client{Transport: roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			return xmlResponse(`<VersioningConfiguration xmlns="http://s3.amazonaws.com/doc/2006-03-01/"><Status>Enabled</Status></VersioningConfiguration>`), nil
+		})},
+		APIOptions: []func(*middleware.Stack) error{counter.register},
+	}
+	client := s3.NewFromConfig(cfg, func(o *s3.Options) {
+		o.UsePathStyle = true
+		o.BaseEndpoint = aws.String("https://s3.us-east-1.amazonaws.com")
+	})
+
+	p := NewProgress()
+	ctx, finish := p.begin(context.Background(), "media", "us-east-1")
+	defer finish()
+	if _, err := client.GetBucketVersioning(ctx, &s3.GetBucketVersioningInput{Bucket: aws.String("media")}); err != nil {
+		t.Fatalf("GetBucketVersioning failed: %v", err)
+	}
+	if active := p.Snapshot().Active; len(active) != 1 || active[0].Operation != "S3.GetBucketVersioning" {
+		t.Errorf("expected the bucket's last operation, got %+v", active)
+	}
+}
@@ -0,0 +1,122 @@
+package s3
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+// ReplicationAPI is the subset of the S3 client used to read a bucket's
+// replication configuration
+type ReplicationAPI interface {
+	GetBucketReplication(ctx context.Context, params *s3.GetBucketReplicationInput, optFns ...func(*s3.Options)) (*s3.GetBucketReplicationOutput, error)
+}
+
+// ReplicationInfo is a bucket's replication configuration, where the bucket
+// is the source
+type ReplicationInfo struct {
+	// Role is the IAM role S3 assumes to replicate objects
+	Role  string            `json:"role,omitempty"`
+	Rules []ReplicationRule `json:"rules"`
+}
+
+// ReplicationRule is one rule of a replication configuration
+type ReplicationRule struct {
+	ID      string `json:"id,omitempty"`
+	Enabled bool   `json:"enabled"`
+	// Destination is the destination bucket's name
+	Destination string `json:"destination"`
+	// Account is the destination account, set for cross-account replication
+	Account      string `json:"account,omitempty"`
+	StorageClass string `json:"storage_class,omitempty"`
+}
+
+// Destinations returns the buckets the enabled rules replicate to, sorted
+func (r *ReplicationInfo) Destinations() []string {
+	if r == nil {
+		return nil
+	}
+	seen := make(map[string]bool)
+	var destinations []string
+	for _, rule := range r.Rules {
+		if rule.Enabled && rule.Destination != "" && !seen[rule.Destination] {
+			seen[rule.Destination] = true
+			destinations = append(destinations, rule.Destination)
+		}
+	}
+	sort.Strings(destinations)
+	return destinations
+}
+
+// ReplicationSources maps each bucket that enabled replication rules write
+// to onto the sorted buckets replicating into it. Only the sources among
+// buckets are known; a destination need not be in buckets.
+func ReplicationSources(buckets map[string]*BucketInfo) map[string][]string {
+	sources := make(map[string][]string)
+	for name, info := range buckets {
+		if info == nil {
+			continue
+		}
+		for _, destination := range info.Replication.Destinations() {
+			if destination != name {
+				sources[destination] = append(sources[destination], name)
+			}
+		}
+	}
+	for _, list := range sources {
+		sort.Strings(list)
+	}
+	return sources
+}
+
+// replicationClient returns the client that reads a bucket's replication
+// configuration
+func (i *Inspector) replicationClient(client *Client) ReplicationAPI {
+	if i.newReplicationAPI != nil {
+		return i.newReplicationAPI(client.GetRegion())
+	}
+	return client.s3Client
+}
+
+// readReplication sets info.Replication from the bucket's replication
+// configuration. A bucket without replication, or whose configuration
+// cannot be read, keeps Replication nil.
+func (i *Inspector) readReplication(ctx context.Context, client *Client, bucket string, info *BucketInfo) {
+	api := i.replicationClient(client)
+	var replication *ReplicationInfo
+	err := client.WithRetry(ctx, func() error {
+		out, err := api.GetBucketReplication(ctx, &s3.GetBucketReplicationInput{Bucket: aws.String(bucket)})
+		if err == nil && out.ReplicationConfiguration != nil {
+			replication = parseReplication(out.ReplicationConfiguration)
+		}
+		return err
+	})
+	if err != nil || ctx.Err() != nil {
+		return
+	}
+	info.Replication = replication
+}
+
+// parseReplication reads a replication configuration. Destination buckets
+// are ARNs; their names follow the last colon.
+func parseReplication(cfg *types.ReplicationConfiguration) *ReplicationInfo {
+	replication := &ReplicationInfo{Role: aws.ToString(cfg.Role), Rules: make([]ReplicationRule, 0, len(cfg.Rules))}
+	for _, rule := range cfg.Rules {
+		r := ReplicationRule{
+			ID:      aws.ToString(rule.ID),
+			Enabled: rule.Status == types.ReplicationRuleStatusEnabled,
+		}
+		if dest := rule.Destination; dest != nil {
+			arn := aws.ToString(dest.Bucket)
+			r.Destination = arn[strings.LastIndex(arn, ":")+1:]
+			r.Account = aws.ToString(dest.Account)
+			r.StorageClass = string(dest.StorageClass)
+		}
+		replication.Rules = append(replication.Rules, r)
+	}
+	return replication
+}
@@ -0,0 +1,96 @@
+package s3
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+// fakeReplicationAPI returns a fixed replication configuration or error
+type fakeReplicationAPI struct {
+	config *types.ReplicationConfiguration
+	err    error
+}
+
+func (f *fakeReplicationAPI) GetBucketReplication(_ context.Context, _ *s3.GetBucketReplicationInput, _ ...func(*s3.Options)) (*s3.GetBucketReplicationOutput, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &s3.GetBucketReplicationOutput{ReplicationConfiguration: f.config}, nil
+}
+
+func TestReadReplication(t *testing.T) {
+	client := &Client{config: aws.Config{Region: "us-east-1"}}
+	inspector := NewInspector(client, 1)
+	api := &fakeReplicationAPI{config: &types.ReplicationConfiguration{
+		Role: aws.String("arn:aws:iam::111122223333:role/replication"),
+		Rules: []types.ReplicationRule{
+			{
+				ID:     aws.String("dr"),
+				Status: types.ReplicationRuleStatusEnabled,
+				Destination: &types.Destination{
+					Bucket:       aws.String("arn:aws:s3:::orders-dr"),
+					Account:      aws.String("444455556666"),
+					StorageClass: types.StorageClassStandardIa,
+				},
+			},
+			{
+				ID:          aws.String("old"),
+				Status:      types.ReplicationRuleStatusDisabled,
+				Destination: &types.Destination{Bucket: aws.String("arn:aws:s3:::orders-old")},
+			},
+		},
+	}}
+	inspector.newReplicationAPI = func(string) ReplicationAPI { return api }
+
+	info := &BucketInfo{}
+	inspector.readReplication(context.Background(), client, "orders", info)
+	if info.Replication == nil || len(info.Replication.Rules) != 2 {
+		t.Fatalf("replication = %+v, want two rules", info.Replication)
+	}
+	rule := info.Replication.Rules[0]
+	if rule.Destination != "orders-dr" || !rule.Enabled || rule.Account != "444455556666" || rule.StorageClass != "STANDARD_IA" {
+		t.Errorf("rule = %+v", rule)
+	}
+	if got := info.Replication.Destinations(); !reflect.DeepEqual(got, []string{"orders-dr"}) {
+		t.Errorf("Destinations() = %v, want only the enabled rule's bucket", got)
+	}
+
+	api.err = errors.New("ReplicationConfigurationNotFoundError")
+	info = &BucketInfo{}
+	inspector.readReplication(context.Background(), client, "plain", info)
+	if info.Replication != nil {
+		t.Errorf("a bucket without replication should have none, got %+v", info.Replication)
+	}
+}
+
+func TestReplicationSources(t *testing.T) {
+	replicate := func(destinations ...string) *BucketInfo {
+		info := &BucketInfo{Replication: &ReplicationInfo{}}
+		for _, d := range destinations {
+			info.Replication.Rules = append(info.Replication.Rules, ReplicationRule{Enabled: true, Destination: d})
+		}
+		return info
+	}
+	buckets := map[string]*BucketInfo{
+		"orders":   replicate("orders-dr", "audit"),
+		"payments": replicate("audit"),
+		"loop":     replicate("loop"),
+		"audit":    {},
+		"empty":    nil,
+	}
+
+	got := ReplicationSources(buckets)
+	want := map[string][]string{
+		"orders-dr": {"orders"},
+		"audit":     {"orders", "payments"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ReplicationSources() = %v, want %v", got, want)
+	}
+}
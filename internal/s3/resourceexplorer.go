@@ -0,0 +1,142 @@
+package s3
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/aws/arn"
+	"github.com/aws/aws-sdk-go-v2/service/resourceexplorer2"
+)
+
+// resourceSearcher is the subset of the Resource Explorer API used by ResourceExplorerSource
+type resourceSearcher interface {
+	Search(ctx context.Context, params *resourceexplorer2.SearchInput, optFns ...func(*resourceexplorer2.Options)) (*resourceexplorer2.SearchOutput, error)
+}
+
+// ResourceExplorerSource finds buckets through an AWS Resource Explorer index
+// instead of ListBuckets, so discovery can be scoped by tag in accounts with
+// thousands of buckets. Matching buckets are still inspected through S3.
+type ResourceExplorerSource struct {
+	client     resourceSearcher
+	inspector  *Inspector
+	tagFilters []TagFilter
+	regions    []string
+	viewARN    string
+}
+
+// NewResourceExplorerSource creates a bucket source backed by Resource Explorer.
+// The search runs in the client's region, which must host an aggregator index
+// (or the default view) to see buckets from other regions.
+func NewResourceExplorerSource(client *Client, inspector *Inspector) *ResourceExplorerSource {
+	return &ResourceExplorerSource{
+		client:    resourceexplorer2.NewFromConfig(client.GetConfig()),
+		inspector: inspector,
+	}
+}
+
+// SetTagFilters limits results to buckets matching every filter, in the
+// search query itself so non-matching buckets are never inspected
+func (s *ResourceExplorerSource) SetTagFilters(filters []TagFilter) {
+	s.tagFilters = filters
+}
+
+// SetRegions limits results to buckets in the given regions
+func (s *ResourceExplorerSource) SetRegions(regions []string) {
+	s.regions = regions
+}
+
+// SetViewARN searches a specific Resource Explorer view instead of the default one
+func (s *ResourceExplorerSource) SetViewARN(viewARN string) {
+	s.viewARN = viewARN
+}
+
+// DiscoverAllBuckets searches for matching buckets and inspects each one
+func (s *ResourceExplorerSource) DiscoverAllBuckets(ctx context.Context) (map[string]*BucketInfo, error) {
+	bucketRegions, err := s.search(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for name := range bucketRegions {
+		if !s.inspector.filter.Match(name) {
+			delete(bucketRegions, name)
+		}
+	}
+	return s.inspector.inspectDiscovered(ctx, bucketRegions, nil), nil
+}
+
+// search returns the name and region of every bucket matching the query
+func (s *ResourceExplorerSource) search(ctx context.Context) (map[string]string, error) {
+	bucketRegions := make(map[string]string)
+	input := &resourceexplorer2.SearchInput{
+		QueryString: aws.String(s.query()),
+		MaxResults:  aws.Int32(1000),
+	}
+	if s.viewARN != "" {
+		input.ViewArn = aws.String(s.viewARN)
+	}
+
+	for {
+		out, err := s.client.Search(ctx, input)
+		if err != nil {
+			return nil, fmt.Errorf("failed to search resource explorer: %w", err)
+		}
+
+		for _, res := range out.Resources {
+			name := bucketNameFromARN(aws.ToString(res.Arn))
+			if name == "" {
+				continue
+			}
+			bucketRegions[name] = aws.ToString(res.Region)
+		}
+
+		if out.NextToken == nil || *out.NextToken == "" {
+			break
+		}
+		input.NextToken = out.NextToken
+	}
+
+	return bucketRegions, nil
+}
+
+// query builds the Resource Explorer query string.
+// Space-separated terms are combined with AND; repeated region: terms with OR.
+func (s *ResourceExplorerSource) query() string {
+	terms := []string{"resourcetype:s3:bucket"}
+	for _, f := range s.tagFilters {
+		terms = append(terms, tagFilterQuery(f))
+	}
+	for _, r := range s.regions {
+		terms = append(terms, "region:"+r)
+	}
+	return strings.Join(terms, " ")
+}
+
+// tagFilterQuery converts a tag filter into a query term
+func tagFilterQuery(f TagFilter) string {
+	if f.AnyValue {
+		return "tag.key:" + quoteQueryValue(f.Key)
+	}
+	return "tag:" + quoteQueryValue(f.Key+"="+f.Value)
+}
+
+// quoteQueryValue wraps values containing spaces in double quotes
+func quoteQueryValue(v string) string {
+	if strings.ContainsAny(v, " \t") {
+		return `"` + strings.ReplaceAll(v, `"`, "") + `"`
+	}
+	return v
+}
+
+// bucketNameFromARN extracts the bucket name from arn:aws:s3:::name
+func bucketNameFromARN(resourceARN string) string {
+	parsed, err := arn.Parse(resourceARN)
+	if err != nil || parsed.Service != "s3" {
+		return ""
+	}
+	if strings.Contains(parsed.Resource, "/") {
+		return ""
+	}
+	return parsed.Resource
+}
@@ -0,0 +1,85 @@
+package s3
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/resourceexplorer2"
+	"github.com/aws/aws-sdk-go-v2/service/resourceexplorer2/types"
+)
+
+type fakeSearcher struct {
+	pages   [][]types.Resource
+	queries []string
+}
+
+func (f *fakeSearcher) Search(ctx context.Context, params *resourceexplorer2.SearchInput, optFns ...func(*resourceexplorer2.Options)) (*resourceexplorer2.SearchOutput, error) {
+	f.queries = append(f.queries, aws.ToString(params.QueryString))
+	page := 0
+	if params.NextToken != nil {
+		page = 1
+	}
+	out := &resourceexplorer2.SearchOutput{Resources: f.pages[page]}
+	if page+1 < len(f.pages) {
+		out.NextToken = aws.String("next")
+	}
+	return out, nil
+}
+
+func TestResourceExplorerSource_Search(t *testing.T) {
+	searcher := &fakeSearcher{pages: [][]types.Resource{
+		{
+			{Arn: aws.String("arn:aws:s3:::data-lake"), Region: aws.String("us-east-1")},
+			{Arn: aws.String("arn:aws:s3:::data-lake/some/object"), Region: aws.String("us-east-1")},
+		},
+		{
+			{Arn: aws.String("arn:aws:s3:::data-archive"), Region: aws.String("eu-west-1")},
+		},
+	}}
+	source := &ResourceExplorerSource{client: searcher}
+	filters, err := ParseTagFilters([]string{"team=data", "cost-center"})
+	if err != nil {
+		t.Fatalf("ParseTagFilters failed: %v", err)
+	}
+	source.SetTagFilters(filters)
+
+	bucketRegions, err := source.search(context.Background())
+	if err != nil {
+		t.Fatalf("search failed: %v", err)
+	}
+	if len(searcher.queries) != 2 {
+		t.Fatalf("expected 2 paged searches, got %d", len(searcher.queries))
+	}
+	want := "resourcetype:s3:bucket tag:team=data tag.key:cost-center"
+	if searcher.queries[0] != want {
+		t.Fatalf("expected query %q, got %q", want, searcher.queries[0])
+	}
+	if len(bucketRegions) != 2 {
+		t.Fatalf("expected 2 buckets, got %v", bucketRegions)
+	}
+	if bucketRegions["data-archive"] != "eu-west-1" {
+		t.Fatalf("expected data-archive in eu-west-1, got %q", bucketRegions["data-archive"])
+	}
+}
+
+func TestTagFilterQuery(t *testing.T) {
+	tests := []struct {
+		filter string
+		want   string
+	}{
+		{"team=data", "tag:team=data"},
+		{"env", "tag.key:env"},
+		{"owner=data platform", `tag:"owner=data platform"`},
+		{" team = data ", "tag:team=data"},
+	}
+	for _, tt := range tests {
+		filters, err := ParseTagFilters([]string{tt.filter})
+		if err != nil {
+			t.Fatalf("ParseTagFilters(%q) failed: %v", tt.filter, err)
+		}
+		if got := tagFilterQuery(filters[0]); got != tt.want {
+			t.Fatalf("tagFilterQuery(%q) = %q, want %q", tt.filter, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,24 @@
+package s3
+
+import (
+	"math/rand"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+// sampleBuckets returns n buckets chosen uniformly at random without replacement
+func sampleBuckets(buckets []types.Bucket, n int, rng *rand.Rand) []types.Bucket {
+	if n >= len(buckets) {
+		return buckets
+	}
+	if rng == nil {
+		rng = rand.New(rand.NewSource(time.Now().UnixNano()))
+	}
+
+	sample := make([]types.Bucket, n)
+	for idx, j := range rng.Perm(len(buckets))[:n] {
+		sample[idx] = buckets[j]
+	}
+	return sample
+}
@@ -0,0 +1,32 @@
+package s3
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+func TestSampleBuckets(t *testing.T) {
+	var buckets []types.Bucket
+	for _, name := range []string{"a", "b", "c", "d", "e"} {
+		buckets = append(buckets, types.Bucket{Name: aws.String(name)})
+	}
+
+	sample := sampleBuckets(buckets, 3, rand.New(rand.NewSource(1)))
+	if len(sample) != 3 {
+		t.Fatalf("expected 3 sampled buckets, got %d", len(sample))
+	}
+	seen := make(map[string]bool)
+	for _, b := range sample {
+		if seen[*b.Name] {
+			t.Fatalf("bucket %s sampled twice", *b.Name)
+		}
+		seen[*b.Name] = true
+	}
+
+	if all := sampleBuckets(buckets, 10, nil); len(all) != 5 {
+		t.Fatalf("expected all buckets when n exceeds population, got %d", len(all))
+	}
+}
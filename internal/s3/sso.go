@@ -0,0 +1,252 @@
+package s3
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/credentials/ssocreds"
+	"github.com/aws/aws-sdk-go-v2/service/ssooidc"
+	ssooidctypes "github.com/aws/aws-sdk-go-v2/service/ssooidc/types"
+	"github.com/aws/smithy-go"
+)
+
+// ssoScope is the scope sso-session tokens need to fetch role credentials
+const ssoScope = "sso:account:access"
+
+// ssoPollInterval is the shortest wait between device-code token polls
+var ssoPollInterval = time.Second
+
+// SSOProfile is the IAM Identity Center configuration behind a profile
+type SSOProfile struct {
+	Profile string
+	// Session is the sso-session name; empty for legacy SSO profiles
+	Session  string
+	StartURL string
+	Region   string
+}
+
+// LoadSSOProfile reads the SSO settings of a shared config profile. ok is
+// false when the profile does not exist or does not use SSO.
+func LoadSSOProfile(ctx context.Context, profile string) (SSOProfile, bool) {
+	env, err := config.NewEnvConfig()
+	if err != nil {
+		return SSOProfile{}, false
+	}
+	if profile == "" {
+		profile = env.SharedConfigProfile
+	}
+	if profile == "" {
+		profile = "default"
+	}
+	shared, err := config.LoadSharedConfigProfile(ctx, profile, func(o *config.LoadSharedConfigOptions) {
+		// Honor AWS_CONFIG_FILE and AWS_SHARED_CREDENTIALS_FILE like LoadDefaultConfig does
+		if env.SharedConfigFile != "" {
+			o.ConfigFiles = []string{env.SharedConfigFile}
+		}
+		if env.SharedCredentialsFile != "" {
+			o.CredentialsFiles = []string{env.SharedCredentialsFile}
+		}
+	})
+	if err != nil {
+		return SSOProfile{}, false
+	}
+	p := SSOProfile{Profile: profile, StartURL: shared.SSOStartURL, Region: shared.SSORegion}
+	if shared.SSOSession != nil {
+		p.Session = shared.SSOSession.Name
+		p.StartURL = shared.SSOSession.SSOStartURL
+		p.Region = shared.SSOSession.SSORegion
+	}
+	return p, p.StartURL != ""
+}
+
+// LoginCommand is the AWS CLI command that refreshes the profile's session
+func (p SSOProfile) LoginCommand() string {
+	if p.Profile == "default" {
+		return "aws sso login"
+	}
+	return "aws sso login --profile " + p.Profile
+}
+
+// cacheKey names the token cache file the SDK reads for this profile
+func (p SSOProfile) cacheKey() string {
+	if p.Session != "" {
+		return p.Session
+	}
+	return p.StartURL
+}
+
+// SSOSessionError reports a missing or expired IAM Identity Center session
+type SSOSessionError struct {
+	Profile SSOProfile
+	Err     error
+}
+
+func (e *SSOSessionError) Error() string {
+	return fmt.Sprintf("AWS SSO session for profile %q is expired or missing.\n"+
+		"Solutions:\n"+
+		"  - Run '%s' and retry\n"+
+		"  - Pass --sso-login to sign in with a device code\n"+
+		"Original error: %v", e.Profile.Profile, e.Profile.LoginCommand(), e.Err)
+}
+
+func (e *SSOSessionError) Unwrap() error {
+	return e.Err
+}
+
+// isSSOTokenError reports whether err comes from a missing, expired or
+// revoked SSO access token rather than from the role itself
+func isSSOTokenError(err error) bool {
+	var invalid *ssocreds.InvalidTokenError
+	if errors.As(err, &invalid) {
+		return true
+	}
+	var apiErr smithy.APIError
+	if errors.As(err, &apiErr) && apiErr.ErrorCode() == "UnauthorizedException" {
+		return true
+	}
+	return strings.Contains(err.Error(), "SSO token")
+}
+
+// checkSSOSession fetches the profile's credentials so an expired SSO session
+// surfaces at startup. With login set, it runs the device-code flow and
+// reports that cfg must be reloaded.
+func checkSSOSession(ctx context.Context, cfg aws.Config, p SSOProfile, login io.Writer) (relogged bool, err error) {
+	_, err = cfg.Credentials.Retrieve(ctx)
+	if err == nil || !isSSOTokenError(err) {
+		return false, err
+	}
+	if login == nil {
+		return false, &SSOSessionError{Profile: p, Err: err}
+	}
+	client := ssooidc.NewFromConfig(cfg, func(o *ssooidc.Options) {
+		o.Region = p.Region
+		o.Credentials = aws.AnonymousCredentials{}
+	})
+	if err := LoginSSO(ctx, client, p, login); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// SSOOIDCAPI is the subset of the SSO OIDC client used for device-code login
+type SSOOIDCAPI interface {
+	RegisterClient(ctx context.Context, params *ssooidc.RegisterClientInput, optFns ...func(*ssooidc.Options)) (*ssooidc.RegisterClientOutput, error)
+	StartDeviceAuthorization(ctx context.Context, params *ssooidc.StartDeviceAuthorizationInput, optFns ...func(*ssooidc.Options)) (*ssooidc.StartDeviceAuthorizationOutput, error)
+	CreateToken(ctx context.Context, params *ssooidc.CreateTokenInput, optFns ...func(*ssooidc.Options)) (*ssooidc.CreateTokenOutput, error)
+}
+
+// LoginSSO signs in with the OAuth device-code flow: it prints a verification
+// URL and code to prompt, waits for approval in the browser, then writes the
+// token to the SDK's cache (~/.aws/sso/cache) like 'aws sso login' does.
+func LoginSSO(ctx context.Context, client SSOOIDCAPI, p SSOProfile, prompt io.Writer) error {
+	register := &ssooidc.RegisterClientInput{
+		ClientName: aws.String("s3spectre"),
+		ClientType: aws.String("public"),
+	}
+	if p.Session != "" {
+		register.Scopes = []string{ssoScope}
+	}
+	registration, err := client.RegisterClient(ctx, register)
+	if err != nil {
+		return fmt.Errorf("failed to register SSO client: %w", err)
+	}
+	auth, err := client.StartDeviceAuthorization(ctx, &ssooidc.StartDeviceAuthorizationInput{
+		ClientId:     registration.ClientId,
+		ClientSecret: registration.ClientSecret,
+		StartUrl:     aws.String(p.StartURL),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to start SSO device authorization: %w", err)
+	}
+
+	_, _ = fmt.Fprintf(prompt, "AWS SSO session for profile %q has expired.\n", p.Profile)
+	_, _ = fmt.Fprintf(prompt, "Open %s and confirm code %s to continue.\n",
+		aws.ToString(auth.VerificationUriComplete), aws.ToString(auth.UserCode))
+
+	interval := max(time.Duration(auth.Interval)*time.Second, ssoPollInterval)
+	deadline := time.Now().Add(time.Duration(auth.ExpiresIn) * time.Second)
+	for {
+		token, err := client.CreateToken(ctx, &ssooidc.CreateTokenInput{
+			ClientId:     registration.ClientId,
+			ClientSecret: registration.ClientSecret,
+			DeviceCode:   auth.DeviceCode,
+			GrantType:    aws.String("urn:ietf:params:oauth:grant-type:device_code"),
+		})
+		if err == nil {
+			return writeSSOToken(p, registration, token)
+		}
+
+		var pending *ssooidctypes.AuthorizationPendingException
+		var slowDown *ssooidctypes.SlowDownException
+		switch {
+		case errors.As(err, &pending):
+		case errors.As(err, &slowDown):
+			interval += 5 * time.Second
+		default:
+			return fmt.Errorf("SSO login failed: %w", err)
+		}
+		if auth.ExpiresIn > 0 && time.Now().Add(interval).After(deadline) {
+			return fmt.Errorf("SSO login failed: device code expired before it was confirmed")
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(interval):
+		}
+	}
+}
+
+// ssoCachedToken is the token cache layout shared with the AWS CLI and SDKs
+type ssoCachedToken struct {
+	StartURL              string `json:"startUrl"`
+	Region                string `json:"region"`
+	AccessToken           string `json:"accessToken"`
+	ExpiresAt             string `json:"expiresAt"`
+	RefreshToken          string `json:"refreshToken,omitempty"`
+	ClientID              string `json:"clientId,omitempty"`
+	ClientSecret          string `json:"clientSecret,omitempty"`
+	RegistrationExpiresAt string `json:"registrationExpiresAt,omitempty"`
+}
+
+func writeSSOToken(p SSOProfile, registration *ssooidc.RegisterClientOutput, token *ssooidc.CreateTokenOutput) error {
+	path, err := ssocreds.StandardCachedTokenFilepath(p.cacheKey())
+	if err != nil {
+		return err
+	}
+	cached := ssoCachedToken{
+		StartURL:    p.StartURL,
+		Region:      p.Region,
+		AccessToken: aws.ToString(token.AccessToken),
+		ExpiresAt:   time.Now().Add(time.Duration(token.ExpiresIn) * time.Second).UTC().Format(time.RFC3339),
+	}
+	// Refresh details let the SDK renew sso-session tokens without a new login
+	if p.Session != "" {
+		cached.RefreshToken = aws.ToString(token.RefreshToken)
+		cached.ClientID = aws.ToString(registration.ClientId)
+		cached.ClientSecret = aws.ToString(registration.ClientSecret)
+		if registration.ClientSecretExpiresAt > 0 {
+			cached.RegistrationExpiresAt = time.Unix(registration.ClientSecretExpiresAt, 0).UTC().Format(time.RFC3339)
+		}
+	}
+	data, err := json.Marshal(cached)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return fmt.Errorf("failed to create SSO token cache: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		return fmt.Errorf("failed to write SSO token cache: %w", err)
+	}
+	return nil
+}
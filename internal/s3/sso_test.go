@@ -0,0 +1,165 @@
+package s3
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/credentials/ssocreds"
+	"github.com/aws/aws-sdk-go-v2/service/ssooidc"
+	ssooidctypes "github.com/aws/aws-sdk-go-v2/service/ssooidc/types"
+)
+
+const testAWSConfig = `[profile legacy]
+sso_start_url = https://legacy.awsapps.com/start
+sso_region = us-east-1
+sso_account_id = 123456789012
+sso_role_name = Audit
+
+[profile modern]
+sso_session = corp
+sso_account_id = 123456789012
+sso_role_name = Audit
+
+[sso-session corp]
+sso_start_url = https://corp.awsapps.com/start
+sso_region = eu-west-1
+sso_registration_scopes = sso:account:access
+
+[profile static]
+region = us-east-1
+`
+
+// useAWSConfig points the SDK at a temporary config file and home directory
+func useAWSConfig(t *testing.T) string {
+	t.Helper()
+	home := t.TempDir()
+	path := filepath.Join(home, "config")
+	if err := os.WriteFile(path, []byte(testAWSConfig), 0o600); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+	t.Setenv("HOME", home)
+	t.Setenv("AWS_CONFIG_FILE", path)
+	t.Setenv("AWS_SHARED_CREDENTIALS_FILE", filepath.Join(home, "credentials"))
+	t.Setenv("AWS_PROFILE", "")
+	t.Setenv("AWS_ACCESS_KEY_ID", "")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "")
+	t.Setenv("AWS_EC2_METADATA_DISABLED", "true")
+	return home
+}
+
+func TestLoadSSOProfile(t *testing.T) {
+	useAWSConfig(t)
+	ctx := context.Background()
+
+	legacy, ok := LoadSSOProfile(ctx, "legacy")
+	if !ok || legacy.Session != "" || legacy.StartURL != "https://legacy.awsapps.com/start" || legacy.Region != "us-east-1" {
+		t.Fatalf("unexpected legacy profile: %+v (ok=%v)", legacy, ok)
+	}
+	modern, ok := LoadSSOProfile(ctx, "modern")
+	if !ok || modern.Session != "corp" || modern.StartURL != "https://corp.awsapps.com/start" || modern.Region != "eu-west-1" {
+		t.Fatalf("unexpected sso-session profile: %+v (ok=%v)", modern, ok)
+	}
+	if modern.LoginCommand() != "aws sso login --profile modern" {
+		t.Fatalf("unexpected login command %q", modern.LoginCommand())
+	}
+	if _, ok := LoadSSOProfile(ctx, "static"); ok {
+		t.Fatalf("expected static profile not to be an SSO profile")
+	}
+	if _, ok := LoadSSOProfile(ctx, "missing"); ok {
+		t.Fatalf("expected missing profile not to be an SSO profile")
+	}
+}
+
+func TestNewClient_ExpiredSSOSession(t *testing.T) {
+	useAWSConfig(t)
+
+	_, err := NewClient(context.Background(), "legacy", "us-east-1")
+	var sessionErr *SSOSessionError
+	if !errors.As(err, &sessionErr) {
+		t.Fatalf("expected SSOSessionError, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "aws sso login --profile legacy") {
+		t.Fatalf("expected login instructions, got %q", err.Error())
+	}
+}
+
+type fakeOIDC struct {
+	pending int
+	polls   int
+}
+
+func (f *fakeOIDC) RegisterClient(_ context.Context, in *ssooidc.RegisterClientInput, _ ...func(*ssooidc.Options)) (*ssooidc.RegisterClientOutput, error) {
+	return &ssooidc.RegisterClientOutput{
+		ClientId:              aws.String("client-id"),
+		ClientSecret:          aws.String("client-secret"),
+		ClientSecretExpiresAt: time.Now().Add(90 * 24 * time.Hour).Unix(),
+	}, nil
+}
+
+func (f *fakeOIDC) StartDeviceAuthorization(_ context.Context, in *ssooidc.StartDeviceAuthorizationInput, _ ...func(*ssooidc.Options)) (*ssooidc.StartDeviceAuthorizationOutput, error) {
+	return &ssooidc.StartDeviceAuthorizationOutput{
+		DeviceCode:              aws.String("device-code"),
+		UserCode:                aws.String("ABCD-EFGH"),
+		VerificationUriComplete: aws.String(aws.ToString(in.StartUrl) + "/#/device?user_code=ABCD-EFGH"),
+		ExpiresIn:               600,
+	}, nil
+}
+
+func (f *fakeOIDC) CreateToken(_ context.Context, in *ssooidc.CreateTokenInput, _ ...func(*ssooidc.Options)) (*ssooidc.CreateTokenOutput, error) {
+	f.polls++
+	if f.polls <= f.pending {
+		return nil, &ssooidctypes.AuthorizationPendingException{}
+	}
+	return &ssooidc.CreateTokenOutput{
+		AccessToken:  aws.String("access-token"),
+		RefreshToken: aws.String("refresh-token"),
+		ExpiresIn:    3600,
+	}, nil
+}
+
+func TestLoginSSO_WritesCachedToken(t *testing.T) {
+	useAWSConfig(t)
+	defer func(d time.Duration) { ssoPollInterval = d }(ssoPollInterval)
+	ssoPollInterval = time.Millisecond
+
+	profile, _ := LoadSSOProfile(context.Background(), "modern")
+	client := &fakeOIDC{pending: 2}
+	var prompt bytes.Buffer
+	if err := LoginSSO(context.Background(), client, profile, &prompt); err != nil {
+		t.Fatalf("LoginSSO failed: %v", err)
+	}
+	if client.polls != 3 {
+		t.Fatalf("expected 3 token polls, got %d", client.polls)
+	}
+	if !strings.Contains(prompt.String(), "ABCD-EFGH") {
+		t.Fatalf("expected user code in prompt, got %q", prompt.String())
+	}
+
+	// The SDK must be able to read the token back from its standard cache
+	path, err := ssocreds.StandardCachedTokenFilepath("corp")
+	if err != nil {
+		t.Fatalf("cache path: %v", err)
+	}
+	provider := ssocreds.NewSSOTokenProvider(ssooidc.New(ssooidc.Options{Region: "eu-west-1"}), path)
+	token, err := provider.RetrieveBearerToken(context.Background())
+	if err != nil {
+		t.Fatalf("RetrieveBearerToken failed: %v", err)
+	}
+	if token.Value != "access-token" {
+		t.Fatalf("expected cached access token, got %q", token.Value)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("stat cache: %v", err)
+	}
+	if info.Mode().Perm() != 0o600 {
+		t.Fatalf("expected token cache mode 0600, got %v", info.Mode().Perm())
+	}
+}
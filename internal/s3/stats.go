@@ -0,0 +1,107 @@
+package s3
+
+import (
+	"context"
+	"sync"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsmiddleware "github.com/aws/aws-sdk-go-v2/aws/middleware"
+	"github.com/aws/aws-sdk-go-v2/aws/retry"
+	"github.com/aws/smithy-go/middleware"
+)
+
+// APIStats counts the AWS API requests of a run
+type APIStats struct {
+	// Calls maps Service.Operation (e.g. S3.ListObjectsV2) to the number of
+	// requests sent, retries included
+	Calls map[string]int `json:"calls"`
+	Total int            `json:"total"`
+	// Retries counts repeated requests, by the SDK or by WithRetry
+	Retries int `json:"retries"`
+	// Throttles counts requests rejected with a throttling error
+	Throttles int `json:"throttles"`
+}
+
+// apiCounter is an SDK middleware shared by every client derived from a
+// Client's config, so IAM, GuardDuty and other calls are counted too
+type apiCounter struct {
+	mu        sync.Mutex
+	calls     map[string]int
+	retries   int
+	throttles int
+}
+
+// attemptsKey holds the attempt count of one operation invocation
+type attemptsKey struct{}
+
+var throttleChecks = retry.IsErrorThrottles(retry.DefaultThrottles)
+
+func newAPICounter() *apiCounter {
+	return &apiCounter{calls: make(map[string]int)}
+}
+
+// register adds the counting middleware to an operation's stack. Requests
+// are counted after the SDK's retry middleware, so every attempt is seen.
+func (c *apiCounter) register(stack *middleware.Stack) error {
+	invocation := middleware.InitializeMiddlewareFunc("S3SpectreCountInvocation", func(ctx context.Context, in middleware.InitializeInput, next middleware.InitializeHandler) (middleware.InitializeOutput, middleware.Metadata, error) {
+		return next.HandleInitialize(context.WithValue(ctx, attemptsKey{}, new(int)), in)
+	})
+	if err := stack.Initialize.Add(invocation, middleware.Before); err != nil {
+		return err
+	}
+
+	attempt := middleware.FinalizeMiddlewareFunc("S3SpectreCountAttempt", func(ctx context.Context, in middleware.FinalizeInput, next middleware.FinalizeHandler) (middleware.FinalizeOutput, middleware.Metadata, error) {
+		key := awsmiddleware.GetServiceID(ctx) + "." + awsmiddleware.GetOperationName(ctx)
+		recordOperation(ctx, key)
+		out, metadata, err := next.HandleFinalize(ctx, in)
+		c.mu.Lock()
+		c.calls[key]++
+		if attempts, ok := ctx.Value(attemptsKey{}).(*int); ok {
+			if *attempts++; *attempts > 1 {
+				c.retries++
+			}
+		}
+		if err != nil && throttleChecks.IsErrorThrottle(err) == aws.TrueTernary {
+			c.throttles++
+		}
+		c.mu.Unlock()
+		return out, metadata, err
+	})
+	if _, ok := stack.Finalize.Get("Retry"); ok {
+		return stack.Finalize.Insert(attempt, "Retry", middleware.After)
+	}
+	return stack.Finalize.Add(attempt, middleware.After)
+}
+
+// retried records a WithRetry repeat; safe on a nil counter
+func (c *apiCounter) retried() {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	c.retries++
+	c.mu.Unlock()
+}
+
+// snapshot returns the counts so far; safe on a nil counter
+func (c *apiCounter) snapshot() APIStats {
+	stats := APIStats{Calls: make(map[string]int)}
+	if c == nil {
+		return stats
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for key, n := range c.calls {
+		stats.Calls[key] = n
+		stats.Total += n
+	}
+	stats.Retries = c.retries
+	stats.Throttles = c.throttles
+	return stats
+}
+
+// APIStats returns the AWS API requests made through the client and the
+// service clients created from its config
+func (c *Client) APIStats() APIStats {
+	return c.counter.snapshot()
+}
@@ -0,0 +1,71 @@
+package s3
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/aws/retry"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/smithy-go/middleware"
+)
+
+func TestAPIStats(t *testing.T) {
+	requests := 0
+	rt := roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		requests++
+		if requests == 1 {
+			return &http.Response{
+				StatusCode: http.StatusServiceUnavailable,
+				Header:     http.Header{"Content-Type": []string{"application/xml"}},
+				Body:       io.NopCloser(strings.NewReader(`<Error><Code>SlowDown</Code><Message>Please reduce your request rate.</Message></Error>`)),
+			}, nil
+		}
+		return xmlResponse(`<VersioningConfiguration xmlns="http://s3.amazonaws.com/doc/2006-03-01/"><Status>Enabled</Status></VersioningConfiguration>`), nil
+	})
+
+	counter := newAPICounter()
+	cfg := aws.Config{
+		Region:      "us-east-1",
+		Credentials: aws.NewCredentialsCache(credentials.NewStaticCredentialsProvider("AKID", "SECRET", "")),
+		HTTPClient:  &http.Client{Transport: rt},
+		APIOptions:  []func(*middleware.Stack) error{counter.register},
+		Retryer: func() aws.Retryer {
+			return retry.NewStandard(func(o *retry.StandardOptions) {
+				o.Backoff = retry.BackoffDelayerFunc(func(int, error) (time.Duration, error) { return 0, nil })
+			})
+		},
+	}
+	client := &Client{
+		s3Client: s3.NewFromConfig(cfg, func(o *s3.Options) {
+			o.UsePathStyle = true
+			o.BaseEndpoint = aws.String("https://s3.us-east-1.amazonaws.com")
+		}),
+		config:  cfg,
+		counter: counter,
+	}
+
+	_, err := client.s3Client.GetBucketVersioning(context.Background(), &s3.GetBucketVersioningInput{Bucket: aws.String("media")})
+	if err != nil {
+		t.Fatalf("GetBucketVersioning failed: %v", err)
+	}
+	// WithRetry repeats are counted alongside the SDK's own retries
+	client.counter.retried()
+
+	stats := client.APIStats()
+	if stats.Calls["S3.GetBucketVersioning"] != 2 || stats.Total != 2 {
+		t.Fatalf("expected 2 GetBucketVersioning requests, got %+v", stats)
+	}
+	if stats.Retries != 2 || stats.Throttles != 1 {
+		t.Fatalf("expected 2 retries and 1 throttle, got %+v", stats)
+	}
+
+	if got := (&Client{}).APIStats(); got.Total != 0 || got.Calls == nil {
+		t.Fatalf("expected empty stats without a counter, got %+v", got)
+	}
+}
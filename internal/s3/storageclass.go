@@ -0,0 +1,112 @@
+package s3
+
+import (
+	"context"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+// deepListPageSize is the most objects a ListObjectsV2 page returns
+const deepListPageSize = 1000
+
+// SizeBreakdown counts a bucket's objects and bytes per storage class
+type SizeBreakdown struct {
+	Objects int   `json:"objects"`
+	Size    int64 `json:"size"`
+	Pages   int   `json:"pages"`
+	// Truncated is set when listing stopped at the object cap or on an
+	// error, so the counts are lower bounds
+	Truncated bool                          `json:"truncated"`
+	Classes   map[string]*StorageClassUsage `json:"storage_classes"`
+}
+
+// StorageClassUsage is the objects and bytes in one storage class
+type StorageClassUsage struct {
+	Objects int   `json:"objects"`
+	Size    int64 `json:"size"`
+}
+
+// ObjectsAPI is the object listing call deep size listing uses
+type ObjectsAPI interface {
+	ListObjectsV2(ctx context.Context, params *s3.ListObjectsV2Input, optFns ...func(*s3.Options)) (*s3.ListObjectsV2Output, error)
+}
+
+// SetDeepSize makes the activity check list up to maxObjects objects per
+// bucket instead of the first ActivitySampleSize, recording object counts,
+// sizes and last activity from all of them plus a storage class breakdown.
+// Zero keeps the sample.
+func (i *Inspector) SetDeepSize(maxObjects int) {
+	i.deepSize = maxObjects
+}
+
+// deepActivity is the activity check with deep listing
+func (i *Inspector) deepActivity(ctx context.Context, client *Client, bucket string, info *BucketInfo) {
+	breakdown, latest, _ := listDeep(ctx, client, client.s3Client, bucket, i.deepSize)
+	if breakdown == nil {
+		return
+	}
+	info.SizeBreakdown = breakdown
+	info.IsEmpty = breakdown.Objects == 0 && !breakdown.Truncated
+	info.ObjectCount = breakdown.Objects
+	info.TotalSize = breakdown.Size
+	if latest != nil {
+		info.LastActivity = latest
+		info.DaysSinceActivity = int(time.Since(*latest).Hours() / 24)
+	}
+}
+
+// listDeep pages through up to maxObjects of the bucket's objects. An error
+// after the first page keeps the pages read, marked truncated; an error on
+// the first page returns no breakdown.
+func listDeep(ctx context.Context, client *Client, api ObjectsAPI, bucket string, maxObjects int) (*SizeBreakdown, *time.Time, error) {
+	breakdown := &SizeBreakdown{Classes: make(map[string]*StorageClassUsage)}
+	var latest *time.Time
+	var token *string
+	for breakdown.Objects < maxObjects {
+		var page *s3.ListObjectsV2Output
+		err := client.WithRetry(ctx, func() error {
+			var err error
+			page, err = api.ListObjectsV2(ctx, &s3.ListObjectsV2Input{
+				Bucket:            aws.String(bucket),
+				ContinuationToken: token,
+				MaxKeys:           aws.Int32(int32(min(deepListPageSize, maxObjects-breakdown.Objects))),
+			})
+			return err
+		})
+		if err != nil {
+			if breakdown.Pages == 0 {
+				return nil, nil, err
+			}
+			breakdown.Truncated = true
+			return breakdown, latest, err
+		}
+		breakdown.Pages++
+		for _, obj := range page.Contents {
+			class := string(obj.StorageClass)
+			if class == "" {
+				class = "STANDARD"
+			}
+			usage := breakdown.Classes[class]
+			if usage == nil {
+				usage = &StorageClassUsage{}
+				breakdown.Classes[class] = usage
+			}
+			size := aws.ToInt64(obj.Size)
+			usage.Objects++
+			usage.Size += size
+			breakdown.Objects++
+			breakdown.Size += size
+			if obj.LastModified != nil && (latest == nil || obj.LastModified.After(*latest)) {
+				latest = obj.LastModified
+			}
+		}
+		if !aws.ToBool(page.IsTruncated) {
+			return breakdown, latest, nil
+		}
+		token = page.NextContinuationToken
+	}
+	breakdown.Truncated = true
+	return breakdown, latest, nil
+}
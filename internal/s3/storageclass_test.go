@@ -0,0 +1,101 @@
+package s3
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+// fakeObjects serves objects in pages of at most pageSize, failing from
+// page failAt on when it is set
+type fakeObjects struct {
+	objects  []types.Object
+	pageSize int
+	failAt   int
+	listed   int
+}
+
+func (f *fakeObjects) ListObjectsV2(ctx context.Context, params *s3.ListObjectsV2Input, optFns ...func(*s3.Options)) (*s3.ListObjectsV2Output, error) {
+	f.listed++
+	if f.failAt > 0 && f.listed >= f.failAt {
+		return nil, errors.New("AccessDenied")
+	}
+	start := 0
+	if params.ContinuationToken != nil {
+		start, _ = strconv.Atoi(*params.ContinuationToken)
+	}
+	end := min(start+f.pageSize, start+int(aws.ToInt32(params.MaxKeys)), len(f.objects))
+	out := &s3.ListObjectsV2Output{Contents: f.objects[start:end], IsTruncated: aws.Bool(end < len(f.objects))}
+	if end < len(f.objects) {
+		out.NextContinuationToken = aws.String(strconv.Itoa(end))
+	}
+	return out, nil
+}
+
+func newFakeObjects(pageSize int, classes ...types.ObjectStorageClass) *fakeObjects {
+	f := &fakeObjects{pageSize: pageSize}
+	for i, class := range classes {
+		f.objects = append(f.objects, types.Object{
+			Key:          aws.String(strconv.Itoa(i)),
+			Size:         aws.Int64(100),
+			StorageClass: class,
+			LastModified: aws.Time(time.Date(2024, 1, 1+i, 0, 0, 0, 0, time.UTC)),
+		})
+	}
+	return f
+}
+
+func TestListDeep(t *testing.T) {
+	api := newFakeObjects(2, "", types.ObjectStorageClassStandard, types.ObjectStorageClassGlacier, types.ObjectStorageClassStandardIa, types.ObjectStorageClassGlacier)
+	breakdown, latest, err := listDeep(context.Background(), &Client{}, api, "logs", 100)
+	if err != nil {
+		t.Fatalf("listDeep: %v", err)
+	}
+	if breakdown.Truncated || breakdown.Objects != 5 || breakdown.Size != 500 || breakdown.Pages != 3 {
+		t.Errorf("breakdown = %+v, want 5 objects in 3 complete pages", breakdown)
+	}
+	want := map[string]int{"STANDARD": 2, "GLACIER": 2, "STANDARD_IA": 1}
+	for class, n := range want {
+		if usage := breakdown.Classes[class]; usage == nil || usage.Objects != n || usage.Size != int64(n)*100 {
+			t.Errorf("class %s = %+v, want %d objects", class, usage, n)
+		}
+	}
+	if len(breakdown.Classes) != len(want) {
+		t.Errorf("classes = %v", breakdown.Classes)
+	}
+	if latest == nil || !latest.Equal(time.Date(2024, 1, 5, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("latest = %v, want the newest object", latest)
+	}
+}
+
+func TestListDeep_Cap(t *testing.T) {
+	api := newFakeObjects(2, "", "", "", "", "")
+	breakdown, _, err := listDeep(context.Background(), &Client{}, api, "logs", 3)
+	if err != nil {
+		t.Fatalf("listDeep: %v", err)
+	}
+	if !breakdown.Truncated || breakdown.Objects != 3 || api.listed != 2 {
+		t.Errorf("breakdown = %+v after %d listings, want 3 objects, truncated", breakdown, api.listed)
+	}
+}
+
+func TestListDeep_Errors(t *testing.T) {
+	api := newFakeObjects(2, "", "", "", "", "")
+	api.failAt = 2
+	breakdown, _, err := listDeep(context.Background(), &Client{}, api, "logs", 100)
+	if err == nil || breakdown == nil || !breakdown.Truncated || breakdown.Objects != 2 {
+		t.Errorf("breakdown = %+v, err = %v; want the first page, truncated", breakdown, err)
+	}
+
+	api = newFakeObjects(2, "")
+	api.failAt = 1
+	if breakdown, _, err := listDeep(context.Background(), &Client{}, api, "logs", 100); err == nil || breakdown != nil {
+		t.Errorf("breakdown = %+v, err = %v; want no breakdown when the first page fails", breakdown, err)
+	}
+}
@@ -0,0 +1,54 @@
+package s3
+
+import (
+	"fmt"
+	"strings"
+)
+
+// TagFilter matches a bucket tag by key, and by value unless AnyValue is set
+type TagFilter struct {
+	Key      string
+	Value    string
+	AnyValue bool
+}
+
+// ParseTagFilters parses "key=value" or bare "key" expressions
+func ParseTagFilters(exprs []string) ([]TagFilter, error) {
+	filters := make([]TagFilter, 0, len(exprs))
+	for _, expr := range exprs {
+		key, value, hasValue := strings.Cut(expr, "=")
+		key = strings.TrimSpace(key)
+		if key == "" {
+			return nil, fmt.Errorf("invalid tag filter %q: expected key=value or key", expr)
+		}
+		filters = append(filters, TagFilter{
+			Key:      key,
+			Value:    strings.TrimSpace(value),
+			AnyValue: !hasValue,
+		})
+	}
+	return filters, nil
+}
+
+// MatchTags reports whether tags satisfy every filter
+func MatchTags(filters []TagFilter, tags map[string]string) bool {
+	for _, f := range filters {
+		value, ok := tags[f.Key]
+		if !ok || (!f.AnyValue && value != f.Value) {
+			return false
+		}
+	}
+	return true
+}
+
+// FilterByTags removes buckets whose tags do not satisfy every filter
+func FilterByTags(buckets map[string]*BucketInfo, filters []TagFilter) {
+	if len(filters) == 0 {
+		return
+	}
+	for name, info := range buckets {
+		if !MatchTags(filters, info.Tags) {
+			delete(buckets, name)
+		}
+	}
+}
@@ -0,0 +1,38 @@
+package s3
+
+import "testing"
+
+func TestParseTagFilters(t *testing.T) {
+	filters, err := ParseTagFilters([]string{"team=data", "cost-center"})
+	if err != nil {
+		t.Fatalf("ParseTagFilters failed: %v", err)
+	}
+	if len(filters) != 2 {
+		t.Fatalf("expected 2 filters, got %d", len(filters))
+	}
+	if filters[0].Key != "team" || filters[0].Value != "data" || filters[0].AnyValue {
+		t.Fatalf("unexpected first filter: %+v", filters[0])
+	}
+	if filters[1].Key != "cost-center" || !filters[1].AnyValue {
+		t.Fatalf("unexpected second filter: %+v", filters[1])
+	}
+
+	if _, err := ParseTagFilters([]string{"=data"}); err == nil {
+		t.Fatalf("expected error for empty key")
+	}
+}
+
+func TestFilterByTags(t *testing.T) {
+	filters, _ := ParseTagFilters([]string{"team=data", "cost-center"})
+	buckets := map[string]*BucketInfo{
+		"match":     {Name: "match", Tags: map[string]string{"team": "data", "cost-center": "42"}},
+		"wrong":     {Name: "wrong", Tags: map[string]string{"team": "web", "cost-center": "42"}},
+		"no-center": {Name: "no-center", Tags: map[string]string{"team": "data"}},
+		"untagged":  {Name: "untagged"},
+	}
+
+	FilterByTags(buckets, filters)
+	if len(buckets) != 1 || buckets["match"] == nil {
+		t.Fatalf("expected only matching bucket, got %v", buckets)
+	}
+}
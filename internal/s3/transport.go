@@ -0,0 +1,77 @@
+package s3
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"os"
+
+	awshttp "github.com/aws/aws-sdk-go-v2/aws/transport/http"
+)
+
+// HTTPOptions tunes the HTTP transport shared by every AWS API client
+type HTTPOptions struct {
+	// CABundle is a PEM file of extra root CAs, e.g. for a TLS-intercepting
+	// corporate proxy. They are trusted in addition to the system roots.
+	CABundle string
+	// MaxIdleConnsPerHost is how many kept-alive connections each endpoint
+	// keeps; 0 keeps the SDK default
+	MaxIdleConnsPerHost int
+	// MaxConnsPerHost caps connections per endpoint; 0 means no limit
+	MaxConnsPerHost int
+}
+
+// Validate checks the connection pool settings
+func (o HTTPOptions) Validate() error {
+	if o.MaxIdleConnsPerHost < 0 || o.MaxConnsPerHost < 0 {
+		return fmt.Errorf("connection pool sizes must not be negative")
+	}
+	return nil
+}
+
+// WithHTTPOptions configures the CA bundle and connection pool
+func WithHTTPOptions(opts HTTPOptions) ClientOption {
+	return func(o *clientOptions) {
+		o.http = opts
+	}
+}
+
+// newHTTPClient builds the SDK HTTP client. HTTPS_PROXY, HTTP_PROXY and
+// NO_PROXY are always honored; AWS_CA_BUNDLE still applies on top.
+func newHTTPClient(opts HTTPOptions) (*awshttp.BuildableClient, error) {
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+	var roots *x509.CertPool
+	if opts.CABundle != "" {
+		pem, err := os.ReadFile(opts.CABundle)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CA bundle: %w", err)
+		}
+		roots, err = x509.SystemCertPool()
+		if err != nil {
+			roots = x509.NewCertPool()
+		}
+		if !roots.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no PEM certificates found in CA bundle %s", opts.CABundle)
+		}
+	}
+
+	return awshttp.NewBuildableClient().WithTransportOptions(func(tr *http.Transport) {
+		tr.Proxy = http.ProxyFromEnvironment
+		if roots != nil {
+			if tr.TLSClientConfig == nil {
+				tr.TLSClientConfig = &tls.Config{MinVersion: tls.VersionTLS12}
+			}
+			tr.TLSClientConfig.RootCAs = roots
+		}
+		if opts.MaxIdleConnsPerHost > 0 {
+			tr.MaxIdleConnsPerHost = opts.MaxIdleConnsPerHost
+			tr.MaxIdleConns = max(tr.MaxIdleConns, opts.MaxIdleConnsPerHost)
+		}
+		if opts.MaxConnsPerHost > 0 {
+			tr.MaxConnsPerHost = opts.MaxConnsPerHost
+		}
+	}), nil
+}
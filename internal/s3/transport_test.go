@@ -0,0 +1,82 @@
+package s3
+
+import (
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestNewHTTPClient_TrustsCABundle(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	request := func(client interface {
+		Do(*http.Request) (*http.Response, error)
+	}) error {
+		req, _ := http.NewRequest(http.MethodGet, server.URL, nil)
+		resp, err := client.Do(req)
+		if err == nil {
+			_ = resp.Body.Close()
+		}
+		return err
+	}
+
+	plain, err := newHTTPClient(HTTPOptions{})
+	if err != nil {
+		t.Fatalf("newHTTPClient failed: %v", err)
+	}
+	if request(plain) == nil {
+		t.Fatalf("expected the test server's certificate to be untrusted without a bundle")
+	}
+
+	bundle := filepath.Join(t.TempDir(), "ca.pem")
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	if err := os.WriteFile(bundle, certPEM, 0o600); err != nil {
+		t.Fatalf("write bundle: %v", err)
+	}
+	trusting, err := newHTTPClient(HTTPOptions{CABundle: bundle})
+	if err != nil {
+		t.Fatalf("newHTTPClient with bundle failed: %v", err)
+	}
+	if err := request(trusting); err != nil {
+		t.Fatalf("expected CA bundle to be trusted, got %v", err)
+	}
+}
+
+func TestNewHTTPClient_InvalidCABundle(t *testing.T) {
+	bundle := filepath.Join(t.TempDir(), "ca.pem")
+	if err := os.WriteFile(bundle, []byte("not a certificate"), 0o600); err != nil {
+		t.Fatalf("write bundle: %v", err)
+	}
+	if _, err := newHTTPClient(HTTPOptions{CABundle: bundle}); err == nil {
+		t.Fatalf("expected error for a bundle without certificates")
+	}
+	if _, err := newHTTPClient(HTTPOptions{CABundle: filepath.Join(t.TempDir(), "missing.pem")}); err == nil {
+		t.Fatalf("expected error for a missing bundle")
+	}
+}
+
+func TestNewHTTPClient_ConnectionPool(t *testing.T) {
+	client, err := newHTTPClient(HTTPOptions{MaxIdleConnsPerHost: 250, MaxConnsPerHost: 300})
+	if err != nil {
+		t.Fatalf("newHTTPClient failed: %v", err)
+	}
+	tr := client.GetTransport()
+	if tr.MaxIdleConnsPerHost != 250 || tr.MaxConnsPerHost != 300 {
+		t.Fatalf("unexpected pool settings: idle/host=%d conns/host=%d", tr.MaxIdleConnsPerHost, tr.MaxConnsPerHost)
+	}
+	if tr.MaxIdleConns < 250 {
+		t.Fatalf("expected total idle connections to cover the per-host limit, got %d", tr.MaxIdleConns)
+	}
+	if tr.Proxy == nil {
+		t.Fatalf("expected proxy settings from the environment")
+	}
+	if _, err := newHTTPClient(HTTPOptions{MaxConnsPerHost: -1}); err == nil {
+		t.Fatalf("expected error for a negative pool size")
+	}
+}
@@ -7,6 +7,8 @@ type BucketInfo struct {
 	Name              string            `json:"name"`
 	Exists            bool              `json:"exists"`
 	Region            string            `json:"region,omitempty"`
+	AccountID         string            `json:"account_id,omitempty"`
+	OutpostID         string            `json:"outpost_id,omitempty"`
 	CreationDate      *time.Time        `json:"creation_date,omitempty"`
 	LastActivity      *time.Time        `json:"last_activity,omitempty"`
 	DaysSinceActivity int               `json:"days_since_activity"`
@@ -20,9 +22,39 @@ type BucketInfo struct {
 	TotalSize         int64             `json:"total_size,omitempty"`
 	TotalVersionSize  int64             `json:"total_version_size,omitempty"`
 	VersionCount      int               `json:"version_count,omitempty"`
+	// VersionStats says whether the version totals are complete
+	VersionStats *VersionStats `json:"version_stats,omitempty"`
+	// SizeBreakdown is set by deep size listing
+	SizeBreakdown *SizeBreakdown `json:"size_breakdown,omitempty"`
+	// Access is set by the CloudTrail access check
+	Access *AccessInfo `json:"access,omitempty"`
+	// CloudWatch is set when size and activity came from CloudWatch metrics
+	CloudWatch *CloudWatchActivity `json:"cloudwatch,omitempty"`
+	// Inventory is set when size and activity came from an S3 Inventory report
+	Inventory *InventoryReport `json:"inventory,omitempty"`
 	Encryption        *EncryptionInfo   `json:"encryption,omitempty"`
 	PublicAccess      *PublicAccessInfo `json:"public_access,omitempty"`
+	// Policy is set by the bucket policy check
+	Policy *PolicyInfo `json:"policy,omitempty"`
+	// ObjectLock is the bucket's Object Lock configuration
+	ObjectLock *ObjectLockInfo `json:"object_lock,omitempty"`
+	// Replication is the bucket's replication configuration as a source
+	Replication *ReplicationInfo `json:"replication,omitempty"`
+	// Logging is set by the logging check
+	Logging *LoggingInfo `json:"logging,omitempty"`
+	// ExternalAccess is set by the Access Analyzer check
+	ExternalAccess []ExternalAccessFinding `json:"external_access,omitempty"`
+	// SensitiveData is set by the Macie check
+	SensitiveData *SensitiveData `json:"sensitive_data,omitempty"`
 	Error             string            `json:"error,omitempty"`
+	// TimedOut is set when inspection hit a deadline and checks were skipped
+	TimedOut bool `json:"timed_out,omitempty"`
+	// Interrupted is set when the run was cancelled before inspection finished
+	Interrupted bool `json:"interrupted,omitempty"`
+	// SkippedChecks lists the checks skipped at the per-bucket deadline
+	SkippedChecks []string `json:"skipped_checks,omitempty"`
+	// NearMisses are existing buckets with similar names when this one does not exist
+	NearMisses []string `json:"near_misses,omitempty"`
 }
 
 // EncryptionInfo contains bucket encryption configuration
@@ -39,6 +71,12 @@ type PublicAccessInfo struct {
 	IgnorePublicAcls      bool `json:"ignore_public_acls"`
 	BlockPublicPolicy     bool `json:"block_public_policy"`
 	RestrictPublicBuckets bool `json:"restrict_public_buckets"`
+	// PublicACLGrants are the bucket ACL's grants to AllUsers and
+	// AuthenticatedUsers, read by discover --check-public
+	PublicACLGrants []ACLGrant `json:"public_acl_grants,omitempty"`
+	// PublicPolicy is set when GetBucketPolicyStatus judges the bucket
+	// policy public
+	PublicPolicy bool `json:"public_policy,omitempty"`
 }
 
 // PrefixInfo contains metadata about an S3 prefix
@@ -49,4 +87,6 @@ type PrefixInfo struct {
 	LatestModified   *time.Time `json:"latest_modified,omitempty"`
 	TotalVersions    int        `json:"total_versions,omitempty"`
 	DaysSinceModified int       `json:"days_since_modified,omitempty"`
+	// NearMiss is the closest existing prefix when this one has no objects
+	NearMiss string `json:"near_miss,omitempty"`
 }
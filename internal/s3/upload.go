@@ -0,0 +1,32 @@
+package s3
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+// PutObject writes body to bucket/key, calling the bucket's region. When
+// the region cannot be looked up, the client's region is tried.
+func (c *Client) PutObject(ctx context.Context, bucket, key string, body []byte, contentType string) error {
+	client := c
+	if region, err := c.BucketRegion(ctx, bucket); err == nil && region != c.GetRegion() {
+		client = c.ForRegion(region)
+	}
+	err := client.WithRetry(ctx, func() error {
+		_, err := client.s3Client.PutObject(ctx, &s3.PutObjectInput{
+			Bucket:      aws.String(bucket),
+			Key:         aws.String(key),
+			Body:        bytes.NewReader(body),
+			ContentType: aws.String(contentType),
+		})
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("failed to upload s3://%s/%s: %w", bucket, key, err)
+	}
+	return nil
+}
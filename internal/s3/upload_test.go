@@ -0,0 +1,38 @@
+package s3
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestClient_PutObject(t *testing.T) {
+	var uploaded, contentType string
+	client := newTestClient(t, roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		if req.URL.Query().Has("location") {
+			return &http.Response{
+				StatusCode: http.StatusForbidden,
+				Header:     http.Header{"Content-Type": []string{"application/xml"}},
+				Body:       io.NopCloser(strings.NewReader(`<?xml version="1.0" encoding="UTF-8"?><Error><Code>AccessDenied</Code></Error>`)),
+			}, nil
+		}
+		if req.Method != http.MethodPut || req.URL.Path != "/finops/s3spectre/scan.jsonl" {
+			t.Errorf("unexpected request %s %s", req.Method, req.URL.Path)
+		}
+		data, _ := io.ReadAll(req.Body)
+		uploaded = string(data)
+		contentType = req.Header.Get("Content-Type")
+		return xmlResponse(""), nil
+	}))
+
+	// A bucket whose location is denied is written in the client's region
+	err := client.PutObject(context.Background(), "finops", "s3spectre/scan.jsonl", []byte("{}\n"), "application/x-ndjson")
+	if err != nil {
+		t.Fatalf("PutObject failed: %v", err)
+	}
+	if uploaded != "{}\n" || contentType != "application/x-ndjson" {
+		t.Errorf("uploaded %q as %q", uploaded, contentType)
+	}
+}
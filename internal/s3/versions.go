@@ -0,0 +1,250 @@
+package s3
+
+import (
+	"context"
+	"hash/fnv"
+	"math"
+	"math/rand"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+const (
+	// MaxVersionPages bounds the ListObjectVersions pages read per bucket
+	MaxVersionPages = 100
+	// versionPageSize is the most versions a listing page holds
+	versionPageSize = 1000
+	// versionConfidenceZ is the normal quantile of a 95% confidence interval
+	versionConfidenceZ = 1.96
+)
+
+// VersionStats describes how a bucket's version totals were measured. When
+// Truncated is set, TotalVersionSize and VersionCount count only the listed
+// pages and are lower bounds.
+type VersionStats struct {
+	Truncated bool `json:"truncated"`
+	// Sampled is set when the pages were drawn from random top-level
+	// prefixes rather than read from the start of the bucket
+	Sampled bool `json:"sampled,omitempty"`
+	Pages   int  `json:"pages"`
+	// NoncurrentVersions and NoncurrentSize count the listed versions that
+	// are not the latest, the bytes a noncurrent version lifecycle rule
+	// would reclaim
+	NoncurrentVersions int   `json:"noncurrent_versions"`
+	NoncurrentSize     int64 `json:"noncurrent_size"`
+	// OverheadRatio is the share of version bytes held by noncurrent
+	// versions: exact for a complete listing, an estimate otherwise
+	OverheadRatio float64 `json:"overhead_ratio"`
+	// OverheadLow and OverheadHigh bound a sampled OverheadRatio at 95%
+	// confidence
+	OverheadLow  float64 `json:"overhead_low,omitempty"`
+	OverheadHigh float64 `json:"overhead_high,omitempty"`
+}
+
+// SetVersionSampling spreads the version pages of buckets too large to list
+// in MaxVersionPages over random top-level prefixes, and estimates the
+// version overhead with a confidence interval
+func (i *Inspector) SetVersionSampling(enabled bool) {
+	i.versionSampling = enabled
+}
+
+// VersionsAPI is the subset of the S3 client version metrics use
+type VersionsAPI interface {
+	ListObjectVersions(ctx context.Context, params *s3.ListObjectVersionsInput, optFns ...func(*s3.Options)) (*s3.ListObjectVersionsOutput, error)
+	ListObjectsV2(ctx context.Context, params *s3.ListObjectsV2Input, optFns ...func(*s3.Options)) (*s3.ListObjectsV2Output, error)
+}
+
+// versionPage is what one listing page contributes to the totals
+type versionPage struct {
+	versions       int
+	size           int64
+	noncurrent     int
+	noncurrentSize int64
+	nextKeyMarker  *string
+	nextVersionID  *string
+	truncated      bool
+	lastKey        string
+}
+
+// versionCounter lists a bucket's version pages within the page budget
+type versionCounter struct {
+	client   *Client
+	api      VersionsAPI
+	bucket   string
+	maxPages int
+	pages    []versionPage
+}
+
+// countVersions lists the bucket's versions, reading at most maxPages
+// pages. With sampling, a bucket that does not fit spends the rest of the
+// budget on the first page under randomly chosen top-level prefixes.
+func countVersions(ctx context.Context, client *Client, api VersionsAPI, bucket string, maxPages int, sampling bool) (total int64, count int, stats *VersionStats, err error) {
+	c := &versionCounter{client: client, api: api, bucket: bucket, maxPages: maxPages}
+	first, err := c.page(ctx, &s3.ListObjectVersionsInput{})
+	if err != nil {
+		return 0, 0, nil, err
+	}
+	stats = &VersionStats{}
+	if first.truncated {
+		var strata []string
+		if sampling {
+			if strata, err = c.strata(ctx, first.lastKey); err != nil {
+				return 0, 0, nil, err
+			}
+		}
+		// Too few prefixes to spread over: read on from the start instead
+		stats.Sampled = len(strata) >= 2
+		if stats.Sampled {
+			stats.Truncated = true
+			err = c.sample(ctx, strata)
+		} else {
+			stats.Truncated, err = c.sequential(ctx, first)
+		}
+		if err != nil {
+			return 0, 0, nil, err
+		}
+	}
+
+	var size int64
+	for _, p := range c.pages {
+		count += p.versions
+		size += p.size
+		stats.NoncurrentVersions += p.noncurrent
+		stats.NoncurrentSize += p.noncurrentSize
+	}
+	stats.Pages = len(c.pages)
+	if size > 0 {
+		stats.OverheadRatio = float64(stats.NoncurrentSize) / float64(size)
+	}
+	if stats.Sampled {
+		stats.OverheadLow, stats.OverheadHigh = ratioInterval(c.pages, stats.OverheadRatio)
+	}
+	return size, count, stats, nil
+}
+
+// sequential reads on from the first page until the listing ends or the
+// budget runs out, and reports whether versions were left unlisted
+func (c *versionCounter) sequential(ctx context.Context, last versionPage) (bool, error) {
+	for last.truncated && len(c.pages) < c.maxPages {
+		var err error
+		last, err = c.page(ctx, &s3.ListObjectVersionsInput{
+			KeyMarker:       last.nextKeyMarker,
+			VersionIdMarker: last.nextVersionID,
+		})
+		if err != nil {
+			return false, err
+		}
+	}
+	return last.truncated, nil
+}
+
+// sample reads pages under prefixes drawn from strata, shuffled by bucket
+// name so reruns sample the same prefixes. The first page of each prefix is
+// read before any second page, so the budget covers as many prefixes as it
+// can.
+func (c *versionCounter) sample(ctx context.Context, strata []string) error {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(c.bucket))
+	rng := rand.New(rand.NewSource(int64(h.Sum64())))
+	rng.Shuffle(len(strata), func(a, b int) { strata[a], strata[b] = strata[b], strata[a] })
+	queue := make([]*s3.ListObjectVersionsInput, 0, len(strata))
+	for _, prefix := range strata {
+		queue = append(queue, &s3.ListObjectVersionsInput{Prefix: aws.String(prefix)})
+	}
+	for len(queue) > 0 && len(c.pages) < c.maxPages {
+		input := queue[0]
+		queue = queue[1:]
+		p, err := c.page(ctx, input)
+		if err != nil {
+			return err
+		}
+		if p.truncated {
+			queue = append(queue, &s3.ListObjectVersionsInput{
+				Prefix:          input.Prefix,
+				KeyMarker:       p.nextKeyMarker,
+				VersionIdMarker: p.nextVersionID,
+			})
+		}
+	}
+	return nil
+}
+
+// strata lists the bucket's top-level prefixes that sort after the first
+// page, so sampled pages do not count its versions again
+func (c *versionCounter) strata(ctx context.Context, after string) ([]string, error) {
+	var out *s3.ListObjectsV2Output
+	err := c.client.WithRetry(ctx, func() error {
+		var err error
+		out, err = c.api.ListObjectsV2(ctx, &s3.ListObjectsV2Input{
+			Bucket:    aws.String(c.bucket),
+			Delimiter: aws.String("/"),
+		})
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	var strata []string
+	for _, p := range out.CommonPrefixes {
+		prefix := aws.ToString(p.Prefix)
+		if prefix > after {
+			strata = append(strata, prefix)
+		}
+	}
+	return strata, nil
+}
+
+// page reads one listing page and records its totals
+func (c *versionCounter) page(ctx context.Context, input *s3.ListObjectVersionsInput) (versionPage, error) {
+	input.Bucket = aws.String(c.bucket)
+	input.MaxKeys = aws.Int32(versionPageSize)
+	var out *s3.ListObjectVersionsOutput
+	err := c.client.WithRetry(ctx, func() error {
+		var err error
+		out, err = c.api.ListObjectVersions(ctx, input)
+		return err
+	})
+	if err != nil {
+		return versionPage{}, err
+	}
+	p := versionPage{
+		nextKeyMarker: out.NextKeyMarker,
+		nextVersionID: out.NextVersionIdMarker,
+		truncated:     aws.ToBool(out.IsTruncated),
+	}
+	for _, v := range out.Versions {
+		size := aws.ToInt64(v.Size)
+		p.versions++
+		p.size += size
+		if !aws.ToBool(v.IsLatest) {
+			p.noncurrent++
+			p.noncurrentSize += size
+		}
+		p.lastKey = max(p.lastKey, aws.ToString(v.Key))
+	}
+	p.versions += len(out.DeleteMarkers)
+	for _, m := range out.DeleteMarkers {
+		p.lastKey = max(p.lastKey, aws.ToString(m.Key))
+	}
+	c.pages = append(c.pages, p)
+	return p, nil
+}
+
+// ratioInterval is the 95% confidence interval of the ratio estimate r of
+// noncurrent bytes to version bytes, treating each page as a cluster
+func ratioInterval(pages []versionPage, r float64) (low, high float64) {
+	n := float64(len(pages))
+	var total, residuals float64
+	for _, p := range pages {
+		total += float64(p.size)
+		d := float64(p.noncurrentSize) - r*float64(p.size)
+		residuals += d * d
+	}
+	if n < 2 || total == 0 {
+		return r, r
+	}
+	mean := total / n
+	se := math.Sqrt(residuals/(n*(n-1))) / mean
+	return math.Max(0, r-versionConfidenceZ*se), math.Min(1, r+versionConfidenceZ*se)
+}
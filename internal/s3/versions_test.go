@@ -0,0 +1,158 @@
+package s3
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+// fakeVersions serves a listing of versions in key order. Markers are
+// offsets into the versions under the requested prefix.
+type fakeVersions struct {
+	versions []types.ObjectVersion
+	pageSize int
+	listed   int
+}
+
+// newFakeVersions creates versions under each prefix: every key has one
+// current version of 100 bytes and noncurrent versions of 100 bytes
+func newFakeVersions(pageSize int, keysPerPrefix map[string]int, noncurrentPerKey map[string]int) *fakeVersions {
+	f := &fakeVersions{pageSize: pageSize}
+	for prefix, keys := range keysPerPrefix {
+		for k := 0; k < keys; k++ {
+			key := fmt.Sprintf("%s%06d", prefix, k)
+			f.versions = append(f.versions, types.ObjectVersion{Key: aws.String(key), Size: aws.Int64(100), IsLatest: aws.Bool(true)})
+			for v := 0; v < noncurrentPerKey[prefix]; v++ {
+				f.versions = append(f.versions, types.ObjectVersion{Key: aws.String(key), Size: aws.Int64(100), IsLatest: aws.Bool(false)})
+			}
+		}
+	}
+	sort.SliceStable(f.versions, func(i, j int) bool { return aws.ToString(f.versions[i].Key) < aws.ToString(f.versions[j].Key) })
+	return f
+}
+
+func (f *fakeVersions) ListObjectVersions(ctx context.Context, params *s3.ListObjectVersionsInput, optFns ...func(*s3.Options)) (*s3.ListObjectVersionsOutput, error) {
+	f.listed++
+	var matching []types.ObjectVersion
+	for _, v := range f.versions {
+		if strings.HasPrefix(aws.ToString(v.Key), aws.ToString(params.Prefix)) {
+			matching = append(matching, v)
+		}
+	}
+	start := 0
+	if params.KeyMarker != nil {
+		start, _ = strconv.Atoi(*params.KeyMarker)
+	}
+	end := min(start+f.pageSize, len(matching))
+	out := &s3.ListObjectVersionsOutput{Versions: matching[start:end], IsTruncated: aws.Bool(end < len(matching))}
+	if end < len(matching) {
+		out.NextKeyMarker = aws.String(strconv.Itoa(end))
+		out.NextVersionIdMarker = aws.String("v")
+	}
+	return out, nil
+}
+
+func (f *fakeVersions) ListObjectsV2(ctx context.Context, params *s3.ListObjectsV2Input, optFns ...func(*s3.Options)) (*s3.ListObjectsV2Output, error) {
+	seen := make(map[string]bool)
+	out := &s3.ListObjectsV2Output{}
+	for _, v := range f.versions {
+		prefix, _, ok := strings.Cut(aws.ToString(v.Key), aws.ToString(params.Delimiter))
+		if ok && !seen[prefix] {
+			seen[prefix] = true
+			out.CommonPrefixes = append(out.CommonPrefixes, types.CommonPrefix{Prefix: aws.String(prefix + "/")})
+		}
+	}
+	return out, nil
+}
+
+func TestCountVersions_Complete(t *testing.T) {
+	api := newFakeVersions(10, map[string]int{"a/": 5, "b/": 5}, map[string]int{"a/": 1})
+	size, count, stats, err := countVersions(context.Background(), &Client{}, api, "logs", 100, false)
+	if err != nil {
+		t.Fatalf("countVersions: %v", err)
+	}
+	if count != 15 || size != 1500 {
+		t.Errorf("count, size = %d, %d; want 15, 1500", count, size)
+	}
+	if stats.Truncated || stats.Sampled || stats.Pages != 2 {
+		t.Errorf("stats = %+v, want a complete listing of 2 pages", stats)
+	}
+	if stats.NoncurrentVersions != 5 || stats.NoncurrentSize != 500 || stats.OverheadRatio != 500.0/1500 {
+		t.Errorf("noncurrent stats = %+v", stats)
+	}
+}
+
+func TestCountVersions_TruncatedWithoutSampling(t *testing.T) {
+	api := newFakeVersions(10, map[string]int{"a/": 100, "b/": 100}, nil)
+	_, count, stats, err := countVersions(context.Background(), &Client{}, api, "logs", 3, false)
+	if err != nil {
+		t.Fatalf("countVersions: %v", err)
+	}
+	if !stats.Truncated || stats.Sampled || stats.Pages != 3 || count != 30 {
+		t.Errorf("stats = %+v, count = %d; want 3 truncated pages", stats, count)
+	}
+	if stats.OverheadLow != 0 || stats.OverheadHigh != 0 {
+		t.Errorf("sequential pages should not claim an interval: %+v", stats)
+	}
+}
+
+func TestCountVersions_Sampling(t *testing.T) {
+	// Reading from the start sees only a/, which has no noncurrent
+	// versions; the other prefixes hold most of the overhead
+	keys := map[string]int{"a/": 5000}
+	noncurrent := map[string]int{}
+	for p := 0; p < 20; p++ {
+		prefix := fmt.Sprintf("p%02d/", p)
+		keys[prefix] = 100
+		noncurrent[prefix] = p % 4
+	}
+	api := newFakeVersions(50, keys, noncurrent)
+
+	_, _, sequential, err := countVersions(context.Background(), &Client{}, api, "logs", 30, false)
+	if err != nil {
+		t.Fatalf("countVersions: %v", err)
+	}
+	if sequential.OverheadRatio != 0 {
+		t.Fatalf("sequential overhead = %v, want 0 from the a/ prefix only", sequential.OverheadRatio)
+	}
+
+	api.listed = 0
+	_, _, stats, err := countVersions(context.Background(), &Client{}, api, "logs", 30, true)
+	if err != nil {
+		t.Fatalf("countVersions: %v", err)
+	}
+	if !stats.Truncated || !stats.Sampled || stats.Pages != 30 || api.listed != 30 {
+		t.Errorf("stats = %+v after %d listings, want 30 sampled pages", stats, api.listed)
+	}
+	// The true overhead share of the sampled prefixes is 1.5/2.5 = 0.6
+	if stats.OverheadRatio < 0.4 || stats.OverheadRatio > 0.8 {
+		t.Errorf("overhead ratio = %v, want near 0.6", stats.OverheadRatio)
+	}
+	if !(stats.OverheadLow < stats.OverheadRatio && stats.OverheadRatio < stats.OverheadHigh) || stats.OverheadLow < 0 || stats.OverheadHigh > 1 {
+		t.Errorf("interval [%v, %v] should bracket %v within [0, 1]", stats.OverheadLow, stats.OverheadHigh, stats.OverheadRatio)
+	}
+
+	// The same bucket samples the same prefixes
+	_, _, again, _ := countVersions(context.Background(), &Client{}, api, "logs", 30, true)
+	if *again != *stats {
+		t.Errorf("resampling gave %+v, want %+v", again, stats)
+	}
+}
+
+func TestCountVersions_SamplingFallsBackWithoutPrefixes(t *testing.T) {
+	api := newFakeVersions(10, map[string]int{"a/": 100}, nil)
+	_, _, stats, err := countVersions(context.Background(), &Client{}, api, "logs", 5, true)
+	if err != nil {
+		t.Fatalf("countVersions: %v", err)
+	}
+	if !stats.Truncated || stats.Sampled || stats.Pages != 5 {
+		t.Errorf("stats = %+v, want 5 sequential pages", stats)
+	}
+}
@@ -0,0 +1,149 @@
+package s3
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+)
+
+// ErrCredentialsExpiring is the cancellation cause when credentials are about
+// to expire and cannot be refreshed
+var ErrCredentialsExpiring = errors.New("AWS credentials are about to expire and cannot be refreshed")
+
+// DefaultCredentialMargin is how long before expiry the watchdog acts
+const DefaultCredentialMargin = 5 * time.Minute
+
+// watchdogInterval is how often the watchdog checks credential expiry
+const watchdogInterval = 30 * time.Second
+
+// CredentialWatchdog keeps long scans ahead of credential expiry. Within
+// margin of expiry it forces a refresh; when the credentials cannot be
+// renewed (static session tokens, expired SSO sessions, roles assumed with an
+// MFA code) it cancels the run
+// with ErrCredentialsExpiring so callers can checkpoint and exit cleanly.
+// Retrieval errors while the credentials still have time left are retried on
+// the next check instead.
+type CredentialWatchdog struct {
+	credentials aws.CredentialsProvider
+	margin      time.Duration
+	interval    time.Duration
+	now         func() time.Time
+	onRefresh   func(expires time.Time)
+	onRetry     func(err error)
+	// expires is the expiry of the last credentials retrieved, zero when
+	// they do not expire or none have been retrieved
+	expires time.Time
+}
+
+// NewCredentialWatchdog creates a watchdog for the client's credentials
+func NewCredentialWatchdog(client *Client, margin time.Duration) *CredentialWatchdog {
+	if margin <= 0 {
+		margin = DefaultCredentialMargin
+	}
+	return &CredentialWatchdog{
+		credentials: client.config.Credentials,
+		margin:      margin,
+		interval:    min(watchdogInterval, margin/2),
+		now:         time.Now,
+	}
+}
+
+// SetRefreshCallback sets a function called after each mid-run refresh
+func (w *CredentialWatchdog) SetRefreshCallback(callback func(expires time.Time)) {
+	w.onRefresh = callback
+}
+
+// SetRetryCallback sets a function called when credentials cannot be
+// retrieved but have not reached the margin, before the next check retries
+func (w *CredentialWatchdog) SetRetryCallback(callback func(err error)) {
+	w.onRetry = callback
+}
+
+// Watch returns a context that is canceled with ErrCredentialsExpiring as its
+// cause when credentials cannot outlive the margin. The returned function
+// stops the watchdog and releases the context.
+func (w *CredentialWatchdog) Watch(ctx context.Context) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancelCause(ctx)
+	if w.credentials == nil {
+		return ctx, func() { cancel(nil) }
+	}
+	go func() {
+		ticker := time.NewTicker(w.interval)
+		defer ticker.Stop()
+		for {
+			if err := w.check(ctx); err != nil {
+				cancel(err)
+				return
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+	return ctx, func() { cancel(nil) }
+}
+
+// check refreshes credentials that expire within the margin. It returns an
+// error wrapping ErrCredentialsExpiring when they cannot be renewed.
+func (w *CredentialWatchdog) check(ctx context.Context) error {
+	creds, err := w.credentials.Retrieve(ctx)
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil
+		}
+		return w.retrieveFailed(err)
+	}
+	w.remember(creds)
+	if !w.expiring(creds) {
+		return nil
+	}
+
+	cache, ok := w.credentials.(*aws.CredentialsCache)
+	if !ok {
+		return fmt.Errorf("%w (expires %s)", ErrCredentialsExpiring, creds.Expires.Format(time.RFC3339))
+	}
+	cache.Invalidate()
+	refreshed, err := cache.Retrieve(ctx)
+	if err != nil {
+		return w.retrieveFailed(err)
+	}
+	w.remember(refreshed)
+	if w.expiring(refreshed) {
+		return fmt.Errorf("%w (expires %s)", ErrCredentialsExpiring, refreshed.Expires.Format(time.RFC3339))
+	}
+	if w.onRefresh != nil {
+		w.onRefresh(refreshed.Expires)
+	}
+	return nil
+}
+
+// retrieveFailed is fatal when the last credentials retrieved expire within
+// the margin, or when an MFA-protected session can never be renewed.
+// Otherwise they remain usable and a transient error is retried at the next
+// check.
+func (w *CredentialWatchdog) retrieveFailed(err error) error {
+	if errors.Is(err, ErrMFACodeUsed) || !w.expires.IsZero() && !w.now().Add(w.margin).Before(w.expires) {
+		return fmt.Errorf("%w: %w", ErrCredentialsExpiring, err)
+	}
+	if w.onRetry != nil {
+		w.onRetry(err)
+	}
+	return nil
+}
+
+// remember records the expiry of retrieved credentials
+func (w *CredentialWatchdog) remember(creds aws.Credentials) {
+	w.expires = time.Time{}
+	if creds.CanExpire {
+		w.expires = creds.Expires
+	}
+}
+
+func (w *CredentialWatchdog) expiring(creds aws.Credentials) bool {
+	return creds.CanExpire && !w.now().Add(w.margin).Before(creds.Expires)
+}
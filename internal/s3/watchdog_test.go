@@ -0,0 +1,144 @@
+package s3
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+)
+
+// rotatingProvider issues credentials that expire lifetime after each call
+type rotatingProvider struct {
+	now      time.Time
+	lifetime []time.Duration
+	calls    int
+}
+
+func (p *rotatingProvider) Retrieve(context.Context) (aws.Credentials, error) {
+	lifetime := p.lifetime[min(p.calls, len(p.lifetime)-1)]
+	p.calls++
+	return aws.Credentials{
+		AccessKeyID:     "AKID",
+		SecretAccessKey: "secret",
+		CanExpire:       true,
+		Expires:         p.now.Add(lifetime),
+	}, nil
+}
+
+func newTestWatchdog(provider aws.CredentialsProvider, now time.Time) *CredentialWatchdog {
+	w := NewCredentialWatchdog(&Client{config: aws.Config{Credentials: provider}}, 5*time.Minute)
+	w.now = func() time.Time { return now }
+	return w
+}
+
+func TestCredentialWatchdog_IgnoresNonExpiringCredentials(t *testing.T) {
+	provider := aws.CredentialsProviderFunc(func(context.Context) (aws.Credentials, error) {
+		return aws.Credentials{AccessKeyID: "AKID", SecretAccessKey: "secret"}, nil
+	})
+	if err := newTestWatchdog(provider, time.Now()).check(context.Background()); err != nil {
+		t.Fatalf("expected static credentials to pass, got %v", err)
+	}
+}
+
+func TestCredentialWatchdog_RefreshesExpiringCredentials(t *testing.T) {
+	now := time.Now()
+	source := &rotatingProvider{now: now, lifetime: []time.Duration{2 * time.Minute, time.Hour}}
+	w := newTestWatchdog(aws.NewCredentialsCache(source), now)
+	var refreshed time.Time
+	w.SetRefreshCallback(func(expires time.Time) { refreshed = expires })
+
+	if err := w.check(context.Background()); err != nil {
+		t.Fatalf("expected refresh to succeed, got %v", err)
+	}
+	if source.calls != 2 {
+		t.Fatalf("expected a forced refresh, got %d retrievals", source.calls)
+	}
+	if !refreshed.Equal(now.Add(time.Hour)) {
+		t.Fatalf("expected refresh callback with new expiry, got %v", refreshed)
+	}
+}
+
+func TestCredentialWatchdog_FailsWhenRefreshDoesNotExtend(t *testing.T) {
+	now := time.Now()
+	source := &rotatingProvider{now: now, lifetime: []time.Duration{2 * time.Minute}}
+	err := newTestWatchdog(aws.NewCredentialsCache(source), now).check(context.Background())
+	if !errors.Is(err, ErrCredentialsExpiring) {
+		t.Fatalf("expected ErrCredentialsExpiring, got %v", err)
+	}
+}
+
+func TestCredentialWatchdog_WatchCancelsWithCause(t *testing.T) {
+	now := time.Now()
+	provider := aws.CredentialsProviderFunc(func(context.Context) (aws.Credentials, error) {
+		return aws.Credentials{CanExpire: true, Expires: now.Add(time.Minute)}, nil
+	})
+	ctx, stop := newTestWatchdog(provider, now).Watch(context.Background())
+	defer stop()
+
+	select {
+	case <-ctx.Done():
+	case <-time.After(time.Second):
+		t.Fatalf("expected watchdog to cancel the context")
+	}
+	if !errors.Is(context.Cause(ctx), ErrCredentialsExpiring) {
+		t.Fatalf("expected ErrCredentialsExpiring cause, got %v", context.Cause(ctx))
+	}
+}
+
+func TestCredentialWatchdog_RetriesRetrieveErrors(t *testing.T) {
+	now := time.Now()
+	expires := now.Add(time.Hour)
+	fail := false
+	provider := aws.CredentialsProviderFunc(func(context.Context) (aws.Credentials, error) {
+		if fail {
+			return aws.Credentials{}, errors.New("connection reset by peer")
+		}
+		return aws.Credentials{AccessKeyID: "AKID", SecretAccessKey: "secret", CanExpire: true, Expires: expires}, nil
+	})
+	w := newTestWatchdog(provider, now)
+	var retried []error
+	w.SetRetryCallback(func(err error) { retried = append(retried, err) })
+	ctx := context.Background()
+
+	// Nothing retrieved yet: no known expiry, so retry
+	fail = true
+	if err := w.check(ctx); err != nil {
+		t.Fatalf("expected a retry before any credentials were retrieved, got %v", err)
+	}
+
+	// Credentials valid for an hour stay usable across a failed retrieval
+	fail = false
+	if err := w.check(ctx); err != nil {
+		t.Fatalf("check failed: %v", err)
+	}
+	fail = true
+	if err := w.check(ctx); err != nil {
+		t.Fatalf("expected a retry while credentials have an hour left, got %v", err)
+	}
+	if len(retried) != 2 {
+		t.Fatalf("expected 2 retry callbacks, got %v", retried)
+	}
+
+	// Within the margin of the known expiry the error is fatal
+	w.now = func() time.Time { return expires.Add(-time.Minute) }
+	if err := w.check(ctx); !errors.Is(err, ErrCredentialsExpiring) {
+		t.Fatalf("expected ErrCredentialsExpiring near expiry, got %v", err)
+	}
+}
+
+func TestCredentialWatchdog_MFASessionFailsImmediately(t *testing.T) {
+	now := time.Now()
+	provider := aws.CredentialsProviderFunc(func(context.Context) (aws.Credentials, error) {
+		return aws.Credentials{}, fmt.Errorf("assume role: %w", ErrMFACodeUsed)
+	})
+	w := newTestWatchdog(provider, now)
+	w.SetRetryCallback(func(err error) { t.Fatalf("expected no retry, got %v", err) })
+
+	err := w.check(context.Background())
+	if !errors.Is(err, ErrCredentialsExpiring) || !errors.Is(err, ErrMFACodeUsed) {
+		t.Fatalf("expected ErrCredentialsExpiring citing ErrMFACodeUsed, got %v", err)
+	}
+}
@@ -0,0 +1,242 @@
+package scanner
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+)
+
+var (
+	// airflowCallPattern matches calls to the Amazon provider's S3 operators,
+	// sensors and transfers, and to S3Hook methods that take a bucket
+	airflowCallPattern = regexp.MustCompile(`\b(S3\w*|\w+ToS3\w*|load_file|load_string|load_bytes|load_file_obj|read_key|get_key|select_key|check_for_key|check_for_prefix|check_for_wildcard_key|list_keys|list_prefixes|copy_object|delete_objects|download_file)\s*\(`)
+
+	// airflowKwargPattern matches a keyword argument with a string literal value
+	airflowKwargPattern = regexp.MustCompile(`\b(\w+)\s*=\s*[rRbBuUfF]{0,2}(?:"([^"\n]*)"|'([^'\n]*)')`)
+)
+
+// airflowBucketKwargs lists the bucket keyword arguments of Airflow S3
+// calls with the key arguments paired with them and the side of a copy
+var airflowBucketKwargs = []struct {
+	name string
+	keys []string
+	role string
+}{
+	{name: "bucket_name", keys: []string{"key", "bucket_key", "prefix", "wildcard_key"}},
+	{name: "bucket", keys: []string{"key", "prefix"}},
+	{name: "s3_bucket", keys: []string{"s3_key", "s3_prefix", "prefix"}},
+	{name: "source_bucket_name", keys: []string{"source_bucket_key"}, role: "source"},
+	{name: "dest_bucket_name", keys: []string{"dest_bucket_key"}, role: "dest"},
+	{name: "dest_bucket", keys: []string{"dest_key", "dest_prefix"}, role: "dest"},
+}
+
+// airflowURLKwargs can hold a full s3:// URL instead of a key
+var airflowURLKwargs = []string{"bucket_key", "s3_key", "source_bucket_key", "dest_bucket_key", "dest_key"}
+
+// airflowKwarg is a string keyword argument and the line it is on
+type airflowKwarg struct {
+	value string
+	line  int
+}
+
+// isAirflowDAG reports whether Python source uses Airflow
+func isAirflowDAG(src string) bool {
+	return strings.Contains(src, "airflow")
+}
+
+// scanPython scans Python source, adding the bucket and key arguments of
+// Airflow S3 operators, sensors and hook calls to the regex references.
+// Airflow references replace the plain ones on the same line, since they
+// carry the key and the operation.
+func scanPython(filePath string) ([]Reference, error) {
+	refs, err := scanCode(filePath)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+	src := string(data)
+	if !isAirflowDAG(src) {
+		return refs, nil
+	}
+	airflow := airflowRefs(src, filePath)
+	if len(airflow) == 0 {
+		return refs, nil
+	}
+	seen := make(map[string]bool)
+	for _, ref := range airflow {
+		seen[fmt.Sprintf("%s|%d", ref.Bucket, ref.Line)] = true
+	}
+	merged := airflow
+	for _, ref := range refs {
+		if !seen[fmt.Sprintf("%s|%d", ref.Bucket, ref.Line)] {
+			merged = append(merged, ref)
+		}
+	}
+	return merged, nil
+}
+
+// airflowRefs finds the S3 locations named by keyword arguments of Airflow
+// calls. Positional arguments and values built at runtime are not resolved;
+// Jinja and f-string templates in a key keep their static prefix.
+func airflowRefs(src, filePath string) []Reference {
+	var refs []Reference
+	for _, loc := range airflowCallPattern.FindAllStringSubmatchIndex(src, -1) {
+		call := src[loc[2]:loc[3]]
+		args, ok := callArgs(src, loc[1]-1)
+		if !ok {
+			continue
+		}
+		base := loc[1]
+		kwargs := make(map[string]airflowKwarg)
+		for _, m := range airflowKwargPattern.FindAllStringSubmatchIndex(args, -1) {
+			name := args[m[2]:m[3]]
+			start, end := m[4], m[5]
+			if start < 0 {
+				start, end = m[6], m[7]
+			}
+			if _, dup := kwargs[name]; !dup {
+				kwargs[name] = airflowKwarg{value: args[start:end], line: lineAt(src, base+start)}
+			}
+		}
+
+		for _, spec := range airflowBucketKwargs {
+			bucket, ok := kwargs[spec.name]
+			if !ok || templated(bucket.value) || !bucketListNamePattern.MatchString(bucket.value) {
+				continue
+			}
+			ref := Reference{Bucket: bucket.value, File: filePath, Line: bucket.line, Context: airflowContext(call, spec.role)}
+			for _, keyName := range spec.keys {
+				if key, ok := kwargs[keyName]; ok {
+					ref.Prefix = staticPrefix(key.value)
+					break
+				}
+			}
+			refs = append(refs, ref)
+		}
+		for _, name := range airflowURLKwargs {
+			arg, ok := kwargs[name]
+			if !ok {
+				continue
+			}
+			match := s3URLPattern.FindStringSubmatchIndex(arg.value)
+			if match == nil || match[0] != 0 || !completeBucket(arg.value, match[3]) {
+				continue
+			}
+			role := ""
+			if strings.HasPrefix(name, "source") {
+				role = "source"
+			} else if strings.HasPrefix(name, "dest") {
+				role = "dest"
+			}
+			prefix := ""
+			if match[4] >= 0 {
+				prefix = staticPrefix(arg.value[match[4]:])
+			}
+			refs = append(refs, Reference{
+				Bucket:  arg.value[match[2]:match[3]],
+				Prefix:  prefix,
+				File:    filePath,
+				Line:    arg.line,
+				Context: airflowContext(call, role),
+			})
+		}
+	}
+	return refs
+}
+
+// airflowContext infers the operation from the called operator or method
+// and, for copies and transfers, the side of the transfer
+func airflowContext(call, role string) string {
+	switch {
+	case role == "source":
+		return string(RefTypeRead)
+	case role == "dest":
+		return string(RefTypeWrite)
+	case strings.HasPrefix(call, "load_") || strings.Contains(call, "ToS3"):
+		return string(RefTypeWrite)
+	case strings.HasPrefix(call, "list_") || strings.Contains(call, "List"):
+		return string(RefTypeList)
+	case strings.Contains(call, "Sensor") || strings.HasPrefix(call, "S3To") ||
+		strings.HasPrefix(call, "read_") || strings.HasPrefix(call, "get_") ||
+		strings.HasPrefix(call, "select_") || strings.HasPrefix(call, "check_for_") ||
+		strings.HasPrefix(call, "download_"):
+		return string(RefTypeRead)
+	case strings.Contains(call, "Create") || strings.Contains(call, "Delete") ||
+		strings.Contains(call, "Copy") || strings.Contains(call, "Put") ||
+		strings.HasPrefix(call, "delete_") || strings.HasPrefix(call, "copy_"):
+		return string(RefTypeWrite)
+	}
+	return string(RefTypeUnknown)
+}
+
+// callArgs returns the text between the parenthesis at open and its match,
+// skipping parentheses inside string literals
+func callArgs(src string, open int) (string, bool) {
+	depth := 0
+	var quote byte
+	for i := open; i < len(src); i++ {
+		c := src[i]
+		if quote != 0 {
+			if c == '\\' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+			continue
+		}
+		switch c {
+		case '"', '\'':
+			quote = c
+		case '#':
+			// Skip a comment to the end of the line
+			for i < len(src) && src[i] != '\n' {
+				i++
+			}
+		case '(':
+			depth++
+		case ')':
+			depth--
+			if depth == 0 {
+				return src[open+1 : i], true
+			}
+		}
+	}
+	return "", false
+}
+
+// templated reports whether a value is a Jinja or f-string template
+func templated(value string) bool {
+	return strings.Contains(value, "{")
+}
+
+// staticPrefix cuts a key at its first template expression, keeping the
+// part known before the DAG runs
+func staticPrefix(key string) string {
+	if i := strings.Index(key, "{"); i >= 0 {
+		return key[:i]
+	}
+	return key
+}
+
+// completeBucket reports whether the bucket name matched up to end is the
+// whole name, rather than the start of a templated one such as
+// "raw-{{ target.name }}"
+func completeBucket(text string, end int) bool {
+	if end >= len(text) {
+		return true
+	}
+	switch text[end] {
+	case '/', '?', '"', '\'', '`', ' ', '\t', '\r', ',', ')', ';':
+		return true
+	}
+	return false
+}
+
+// lineAt returns the 1-based line number of offset in src
+func lineAt(src string, offset int) int {
+	return strings.Count(src[:offset], "\n") + 1
+}
@@ -0,0 +1,112 @@
+package scanner
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestScanPython_AirflowDAG(t *testing.T) {
+	dag := `from airflow import DAG
+from airflow.providers.amazon.aws.hooks.s3 import S3Hook
+from airflow.providers.amazon.aws.sensors.s3 import S3KeySensor
+from airflow.providers.amazon.aws.operators.s3 import S3CopyObjectOperator
+
+wait = S3KeySensor(
+    task_id="wait_for_events",
+    bucket_name="raw-events",
+    bucket_key="incoming/{{ ds }}/data.json",  # templated (key)
+    mode="reschedule",
+)
+
+copy = S3CopyObjectOperator(
+    task_id="copy",
+    source_bucket_name="raw-events",
+    source_bucket_key="incoming/latest.json",
+    dest_bucket_name="curated-events",
+    dest_bucket_key="daily/latest.json",
+)
+
+done = S3KeySensor(task_id="done", bucket_key="s3://audit-log/runs/done")
+skipped = S3KeySensor(task_id="t", bucket_name="env-{{ var.value.env }}", bucket_key="x")
+
+def upload():
+    S3Hook(aws_conn_id="aws").load_file("report.csv", key="reports/daily.csv", bucket_name="analytics-out")
+`
+	path := filepath.Join(t.TempDir(), "events_dag.py")
+	if err := os.WriteFile(path, []byte(dag), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	refs, err := scanPython(path)
+	if err != nil {
+		t.Fatalf("scanPython failed: %v", err)
+	}
+	type want struct {
+		bucket, prefix, context string
+		line                    int
+	}
+	wants := []want{
+		{"raw-events", "incoming/", "read", 8},
+		{"raw-events", "incoming/latest.json", "read", 15},
+		{"curated-events", "daily/latest.json", "write", 17},
+		{"audit-log", "runs/done", "read", 21},
+		{"analytics-out", "reports/daily.csv", "write", 25},
+	}
+	for _, w := range wants {
+		found := false
+		for _, ref := range refs {
+			if ref.Bucket == w.bucket && ref.Prefix == w.prefix && ref.Context == w.context && ref.Line == w.line {
+				found = true
+			}
+		}
+		if !found {
+			t.Errorf("missing reference %+v in %+v", w, refs)
+		}
+	}
+	for _, ref := range refs {
+		if ref.Bucket == "env" || ref.Bucket == "env-" {
+			t.Errorf("templated bucket name should be skipped: %+v", ref)
+		}
+		if ref.Bucket == "audit-log" && ref.Prefix != "runs/done" {
+			t.Errorf("plain URL reference should give way to the Airflow one: %+v", ref)
+		}
+	}
+}
+
+func TestScanPython_NotAirflow(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.py")
+	content := "client.load_file(\"f\", key=\"k/x\", bucket_name=\"plain-bucket\")\n"
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+	refs, err := scanPython(path)
+	if err != nil {
+		t.Fatalf("scanPython failed: %v", err)
+	}
+	// Without Airflow imports only the regex patterns apply
+	for _, ref := range refs {
+		if ref.Prefix == "k/x" {
+			t.Errorf("unexpected Airflow reference: %+v", ref)
+		}
+	}
+}
+
+func TestAirflowContext(t *testing.T) {
+	tests := map[string]string{
+		"S3KeySensor":             "read",
+		"S3ListOperator":          "list",
+		"S3ToRedshiftOperator":    "read",
+		"SqlToS3Operator":         "write",
+		"S3CreateObjectOperator":  "write",
+		"S3DeleteObjectsOperator": "write",
+		"load_string":             "write",
+		"read_key":                "read",
+		"S3Hook":                  "unknown",
+	}
+	for call, want := range tests {
+		if got := airflowContext(call, ""); got != want {
+			t.Errorf("airflowContext(%q) = %q, want %q", call, got, want)
+		}
+	}
+}
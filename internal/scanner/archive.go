@@ -0,0 +1,194 @@
+package scanner
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	// maxArchiveFileSize is the largest archive opened
+	maxArchiveFileSize = 256 * 1024 * 1024
+
+	// maxArchiveExtractSize caps the bytes extracted from one archive, so a
+	// compression bomb cannot fill the disk
+	maxArchiveExtractSize = 100 * 1024 * 1024
+
+	// archiveSeparator joins an archive's path and an entry's path in
+	// Reference.File, as in lambda.zip!/config/app.yaml
+	archiveSeparator = "!/"
+)
+
+// isArchive reports whether scanArchive can open the file
+func isArchive(filePath string) bool {
+	name := strings.ToLower(filePath)
+	return strings.HasSuffix(name, ".zip") || strings.HasSuffix(name, ".tar.gz") || strings.HasSuffix(name, ".tgz")
+}
+
+// scanArchive extracts the scannable entries of a .zip or .tar.gz archive,
+// such as a packaged Lambda bundle, and scans them. Entries over the
+// per-file size limit, hidden entries and nested archives are skipped, and
+// extraction stops at maxArchiveExtractSize. References name the entry as
+// archive!/entry.
+func (s *RepoScanner) scanArchive(archivePath string) ([]Reference, error) {
+	dir, err := os.MkdirTemp("", "s3spectre-archive-")
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	var entries []string
+	if strings.HasSuffix(strings.ToLower(archivePath), ".zip") {
+		entries, err = extractZip(archivePath, dir)
+	} else {
+		entries, err = extractTarGz(archivePath, dir)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var refs []Reference
+	for _, entry := range entries {
+		extracted := filepath.Join(dir, filepath.FromSlash(entry))
+		entryRefs, err := s.scanFile(extracted)
+		if err != nil {
+			continue
+		}
+		for _, ref := range entryRefs {
+			ref.File = archivePath + archiveSeparator + entry
+			refs = append(refs, ref)
+		}
+	}
+	return refs, nil
+}
+
+// archiveExtractor writes archive entries under dir within the extraction
+// budget
+type archiveExtractor struct {
+	dir       string
+	remaining int64
+	entries   []string
+}
+
+// extract writes one entry if it is worth scanning. It reports false once
+// the extraction budget is spent.
+func (e *archiveExtractor) extract(name string, size int64, open func() (io.ReadCloser, error)) (bool, error) {
+	entry, ok := archiveEntryName(name)
+	if !ok || size > maxFileSize {
+		return true, nil
+	}
+	if size > e.remaining {
+		return false, nil
+	}
+	r, err := open()
+	if err != nil {
+		return true, nil
+	}
+	defer func() { _ = r.Close() }()
+
+	target := filepath.Join(e.dir, filepath.FromSlash(entry))
+	if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+		return false, err
+	}
+	f, err := os.Create(target)
+	if err != nil {
+		return false, err
+	}
+	// Headers can understate sizes, so the copy is limited too
+	written, copyErr := io.Copy(f, io.LimitReader(r, maxFileSize+1))
+	closeErr := f.Close()
+	e.remaining -= written
+	if copyErr != nil || closeErr != nil || written > maxFileSize {
+		_ = os.Remove(target)
+		return e.remaining > 0, nil
+	}
+	e.entries = append(e.entries, entry)
+	return e.remaining > 0, nil
+}
+
+// archiveEntryName cleans an entry's path and reports whether the entry is
+// a scannable file. Paths that escape the archive, hidden entries and
+// nested archives are rejected.
+func archiveEntryName(name string) (string, bool) {
+	entry := path.Clean(strings.TrimPrefix(filepath.ToSlash(name), "/"))
+	if entry == "." || entry == ".." || strings.HasPrefix(entry, "../") {
+		return "", false
+	}
+	for _, part := range strings.Split(entry, "/") {
+		if strings.HasPrefix(part, ".") && !isCIConfig(entry) {
+			return "", false
+		}
+	}
+	if isArchive(entry) || !isScannable(entry) {
+		return "", false
+	}
+	return entry, true
+}
+
+// extractZip extracts the scannable entries of a zip archive
+func extractZip(archivePath, dir string) ([]string, error) {
+	r, err := zip.OpenReader(archivePath)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = r.Close() }()
+
+	e := &archiveExtractor{dir: dir, remaining: maxArchiveExtractSize}
+	for _, f := range r.File {
+		if !f.Mode().IsRegular() {
+			continue
+		}
+		more, err := e.extract(f.Name, int64(f.UncompressedSize64), f.Open)
+		if err != nil {
+			return nil, err
+		}
+		if !more {
+			break
+		}
+	}
+	return e.entries, nil
+}
+
+// extractTarGz extracts the scannable entries of a gzipped tar archive
+func extractTarGz(archivePath, dir string) ([]string, error) {
+	file, err := os.Open(archivePath)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = file.Close() }()
+	gz, err := gzip.NewReader(file)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = gz.Close() }()
+
+	e := &archiveExtractor{dir: dir, remaining: maxArchiveExtractSize}
+	tr := tar.NewReader(gz)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+		more, err := e.extract(header.Name, header.Size, func() (io.ReadCloser, error) {
+			return io.NopCloser(tr), nil
+		})
+		if err != nil {
+			return nil, err
+		}
+		if !more {
+			break
+		}
+	}
+	return e.entries, nil
+}
@@ -0,0 +1,175 @@
+package scanner
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeZip writes a zip archive of name → content
+func writeZip(t *testing.T, path string, files map[string]string) {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for name, content := range files {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := w.Write([]byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, buf.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// writeTarGz writes a gzipped tar archive of name → content
+func writeTarGz(t *testing.T, path string, files map[string]string) {
+	t.Helper()
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	for name, content := range files {
+		header := &tar.Header{Name: name, Mode: 0644, Size: int64(len(content)), Typeflag: tar.TypeReg}
+		if err := tw.WriteHeader(header); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write([]byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, buf.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRepoScanner_Archives(t *testing.T) {
+	repo := t.TempDir()
+	bundle := filepath.Join(repo, "lambda.zip")
+	writeZip(t, bundle, map[string]string{
+		"config/app.yaml": "bucket: s3://lambda-config/settings/\n",
+		"handler.py":      "\n\nOUT = 's3://lambda-output/'\n",
+		".env":            "BUCKET=s3://hidden-bucket/\n",
+		"../escape.yaml":  "bucket: s3://escaped-bucket/\n",
+		"README.md":       "s3://readme-bucket/\n",
+	})
+	writeTarGz(t, filepath.Join(repo, "site.tar.gz"), map[string]string{
+		"site/settings.json": `{"assets": "s3://site-assets/img/"}`,
+	})
+
+	// Archives are opt-in
+	s := NewRepoScanner(repo)
+	refs, err := s.Scan(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(refs) != 0 {
+		t.Errorf("archives should not be scanned by default, got %+v", refs)
+	}
+	if counts := CountSkipped(s.SkippedFiles()); counts[SkipUnknownExtension] != 2 {
+		t.Errorf("skipped = %v, want both archives as unknown_extension", counts)
+	}
+
+	s.SetScanArchives(true)
+	refs, err = s.Scan(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]Reference{
+		"lambda-config": {File: bundle + "!/config/app.yaml", Prefix: "settings/", Line: 1},
+		"lambda-output": {File: bundle + "!/handler.py", Line: 3},
+		"site-assets":   {File: filepath.Join(repo, "site.tar.gz") + "!/site/settings.json", Prefix: "img/"},
+	}
+	if len(refs) != len(want) {
+		t.Fatalf("got %d references, want %d: %+v", len(refs), len(want), refs)
+	}
+	for _, ref := range refs {
+		w, ok := want[ref.Bucket]
+		if !ok {
+			t.Errorf("unexpected reference %+v", ref)
+			continue
+		}
+		if ref.File != w.File || ref.Prefix != w.Prefix || (w.Line != 0 && ref.Line != w.Line) {
+			t.Errorf("%s: got file %q prefix %q line %d, want %+v", ref.Bucket, ref.File, ref.Prefix, ref.Line, w)
+		}
+	}
+	if s.FilesScanned() != 2 {
+		t.Errorf("FilesScanned() = %d, want 2", s.FilesScanned())
+	}
+
+	// A corrupt archive is skipped as unreadable
+	if err := os.WriteFile(bundle, []byte("not a zip"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Scan(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if counts := CountSkipped(s.SkippedFiles()); counts[SkipUnreadable] != 1 {
+		t.Errorf("skipped = %v, want the corrupt archive as unreadable", counts)
+	}
+}
+
+func TestArchiveEntryName(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+		ok   bool
+	}{
+		{"config/app.yaml", "config/app.yaml", true},
+		{"/abs/settings.json", "abs/settings.json", true},
+		{"a/../b/app.py", "b/app.py", true},
+		{"../escape.yaml", "", false},
+		{".aws/config.json", "", false},
+		{"nested/inner.zip", "", false},
+		{"vendor/lib.so", "", false},
+		{".github/workflows/ci.yml", ".github/workflows/ci.yml", true},
+	}
+	for _, tt := range tests {
+		got, ok := archiveEntryName(tt.name)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("archiveEntryName(%q) = %q, %v; want %q, %v", tt.name, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestArchiveExtractor_Budget(t *testing.T) {
+	e := &archiveExtractor{dir: t.TempDir(), remaining: 10}
+	open := func(content string) func() (io.ReadCloser, error) {
+		return func() (io.ReadCloser, error) { return io.NopCloser(strings.NewReader(content)), nil }
+	}
+
+	// A header that understates the size does not get past the budget
+	more, err := e.extract("a.yaml", 1, open("bucket: s3://first/"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if more {
+		t.Error("extraction should stop once the budget is spent")
+	}
+	if len(e.entries) != 1 {
+		t.Errorf("entries = %v, want a.yaml", e.entries)
+	}
+
+	e = &archiveExtractor{dir: t.TempDir(), remaining: 10}
+	if more, _ := e.extract("b.yaml", 11, open("bucket: s3://second/")); more || len(e.entries) != 0 {
+		t.Errorf("an entry over the remaining budget should end extraction, got more=%v entries=%v", more, e.entries)
+	}
+}
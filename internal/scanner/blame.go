@@ -0,0 +1,152 @@
+package scanner
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"io"
+	"log/slog"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxBlameRanges is the most lines blamed one -L range at a time; files with
+// more referenced lines are blamed whole
+const maxBlameRanges = 100
+
+// uncommitted is the commit git blame reports for lines not yet committed
+const uncommitted = "0000000000000000000000000000000000000000"
+
+// Blame is the last commit that changed a reference's line
+type Blame struct {
+	Author      string    `json:"author"`
+	AuthorEmail string    `json:"author_email,omitempty"`
+	Commit      string    `json:"commit"`
+	Time        time.Time `json:"time"`
+	Summary     string    `json:"summary,omitempty"`
+}
+
+// BlameReferences runs git blame on the files of the references and sets
+// each reference's Blame. Files outside a git repository, untracked files
+// and uncommitted lines are left without one. It stops with the context's
+// error when ctx is done.
+func BlameReferences(ctx context.Context, refs []Reference) error {
+	byFile := make(map[string][]int)
+	for i, ref := range refs {
+		if ref.File != "" && ref.Line > 0 {
+			byFile[ref.File] = append(byFile[ref.File], i)
+		}
+	}
+	files := make([]string, 0, len(byFile))
+	for file := range byFile {
+		files = append(files, file)
+	}
+	sort.Strings(files)
+
+	for _, file := range files {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		indexes := byFile[file]
+		lines := make([]int, 0, len(indexes))
+		for _, i := range indexes {
+			lines = append(lines, refs[i].Line)
+		}
+		blames, err := blameLines(ctx, file, lines)
+		if err != nil {
+			slog.Debug("git blame failed", slog.String("file", file), slog.String("error", err.Error()))
+			continue
+		}
+		for _, i := range indexes {
+			refs[i].Blame = blames[refs[i].Line]
+		}
+	}
+	return ctx.Err()
+}
+
+// blameLines blames the given lines of a file, from the file's directory so
+// that any repository it belongs to is found
+func blameLines(ctx context.Context, file string, lines []int) (map[int]*Blame, error) {
+	args := []string{"blame", "--line-porcelain"}
+	if len(lines) <= maxBlameRanges {
+		seen := make(map[int]bool, len(lines))
+		for _, line := range lines {
+			if !seen[line] {
+				seen[line] = true
+				args = append(args, "-L", strconv.Itoa(line)+","+strconv.Itoa(line))
+			}
+		}
+	}
+	args = append(args, "--", filepath.Base(file))
+	out, err := git(ctx, filepath.Dir(file), args...)
+	if err != nil {
+		return nil, err
+	}
+	return parseBlame(bytes.NewReader(out))
+}
+
+// parseBlame reads git blame --line-porcelain output into the blame of each
+// final line number
+func parseBlame(r io.Reader) (map[int]*Blame, error) {
+	blames := make(map[int]*Blame)
+	var current *Blame
+	var line int
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 64*1024), maxFileSize)
+	for sc.Scan() {
+		text := sc.Text()
+		if strings.HasPrefix(text, "\t") {
+			// The line's content ends its entry
+			if current != nil && current.Commit != uncommitted {
+				blames[line] = current
+			}
+			current = nil
+			continue
+		}
+		key, value, _ := strings.Cut(text, " ")
+		if current == nil {
+			fields := strings.Fields(text)
+			if len(fields) < 3 {
+				continue
+			}
+			n, err := strconv.Atoi(fields[2])
+			if err != nil {
+				continue
+			}
+			current = &Blame{Commit: fields[0]}
+			line = n
+			continue
+		}
+		switch key {
+		case "author":
+			current.Author = value
+		case "author-mail":
+			current.AuthorEmail = strings.Trim(value, "<>")
+		case "author-time":
+			if seconds, err := strconv.ParseInt(value, 10, 64); err == nil {
+				current.Time = time.Unix(seconds, 0).UTC()
+			}
+		case "summary":
+			current.Summary = value
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	return blames, nil
+}
+
+// LatestBlame returns the most recent of the references' blames, or nil when
+// none has one
+func LatestBlame(refs []Reference) *Blame {
+	var latest *Blame
+	for _, ref := range refs {
+		if ref.Blame != nil && (latest == nil || ref.Blame.Time.After(latest.Time)) {
+			latest = ref.Blame
+		}
+	}
+	return latest
+}
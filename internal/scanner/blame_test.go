@@ -0,0 +1,99 @@
+package scanner
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseBlame(t *testing.T) {
+	out := "" +
+		"1111111111111111111111111111111111111111 2 3 1\n" +
+		"author Ada Lovelace\n" +
+		"author-mail <ada@example.com>\n" +
+		"author-time 1700000000\n" +
+		"author-tz +0000\n" +
+		"summary Add exports bucket\n" +
+		"filename app.py\n" +
+		"\tBUCKET = \"s3://exports/data\"\n" +
+		"0000000000000000000000000000000000000000 7 7 1\n" +
+		"author Not Committed Yet\n" +
+		"author-mail <not.committed.yet>\n" +
+		"author-time 1700000100\n" +
+		"summary Version of app.py from app.py\n" +
+		"filename app.py\n" +
+		"\tLOGS = \"s3://logs/\"\n"
+	blames, err := parseBlame(strings.NewReader(out))
+	if err != nil {
+		t.Fatalf("parseBlame: %v", err)
+	}
+	b := blames[3]
+	if b == nil || b.Author != "Ada Lovelace" || b.AuthorEmail != "ada@example.com" || b.Summary != "Add exports bucket" {
+		t.Fatalf("unexpected blame for line 3: %+v", b)
+	}
+	if !b.Time.Equal(time.Unix(1700000000, 0)) {
+		t.Errorf("Time = %v", b.Time)
+	}
+	if blames[7] != nil {
+		t.Errorf("uncommitted lines should have no blame: %+v", blames[7])
+	}
+}
+
+func TestBlameReferences(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	dir := t.TempDir()
+	run := func(args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", append([]string{"-C", dir}, args...)...)
+		cmd.Env = append(os.Environ(), "GIT_AUTHOR_NAME=Ada", "GIT_AUTHOR_EMAIL=ada@example.com", "GIT_COMMITTER_NAME=Ada", "GIT_COMMITTER_EMAIL=ada@example.com")
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+	app := filepath.Join(dir, "app.py")
+	if err := os.WriteFile(app, []byte("A = \"s3://committed/x\"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	run("init", "-q")
+	run("add", ".")
+	run("commit", "-q", "-m", "Add committed bucket")
+	if err := os.WriteFile(app, []byte("A = \"s3://committed/x\"\nB = \"s3://pending/y\"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	refs := []Reference{
+		{Bucket: "committed", File: app, Line: 1},
+		{Bucket: "pending", File: app, Line: 2},
+		{Bucket: "elsewhere", File: filepath.Join(t.TempDir(), "untracked.py"), Line: 1},
+		{Bucket: "stdin"},
+	}
+	if err := BlameReferences(context.Background(), refs); err != nil {
+		t.Fatalf("BlameReferences: %v", err)
+	}
+	if b := refs[0].Blame; b == nil || b.Author != "Ada" || b.Summary != "Add committed bucket" || len(b.Commit) != 40 {
+		t.Errorf("unexpected blame for committed line: %+v", b)
+	}
+	for _, ref := range refs[1:] {
+		if ref.Blame != nil {
+			t.Errorf("%s: expected no blame, got %+v", ref.Bucket, ref.Blame)
+		}
+	}
+}
+
+func TestLatestBlame(t *testing.T) {
+	older := &Blame{Commit: "a", Time: time.Unix(100, 0)}
+	newer := &Blame{Commit: "b", Time: time.Unix(200, 0)}
+	refs := []Reference{{Blame: older}, {}, {Blame: newer}}
+	if got := LatestBlame(refs); got != newer {
+		t.Errorf("LatestBlame = %+v, want the newest commit", got)
+	}
+	if LatestBlame([]Reference{{}}) != nil {
+		t.Error("expected nil without blamed references")
+	}
+}
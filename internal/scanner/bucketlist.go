@@ -0,0 +1,79 @@
+package scanner
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+)
+
+var bucketListNamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9\-\.]{1,61}[a-z0-9]$`)
+
+// ReadBucketList reads an explicit list of expected buckets and prefixes,
+// one per line, as references. Each line is "bucket", "bucket/prefix" or an
+// s3://, s3a:// or s3n:// URL, optionally followed by the operation the application performs
+// (read, write or list). Blank lines and lines starting with # are ignored.
+func ReadBucketList(path string) ([]Reference, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = file.Close() }()
+
+	var refs []Reference
+	seen := make(map[string]bool)
+	sc := bufio.NewScanner(file)
+	lineNum := 0
+	for sc.Scan() {
+		lineNum++
+		line := strings.TrimSpace(sc.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		fields := strings.Fields(line)
+		if len(fields) > 2 {
+			return nil, fmt.Errorf("%s:%d: expected \"bucket[/prefix] [read|write|list]\"", path, lineNum)
+		}
+		target := trimS3Scheme(fields[0])
+		bucket, prefix, _ := strings.Cut(target, "/")
+		if !bucketListNamePattern.MatchString(bucket) {
+			return nil, fmt.Errorf("%s:%d: invalid bucket name %q", path, lineNum, bucket)
+		}
+		context := ""
+		if len(fields) == 2 {
+			switch RefType(fields[1]) {
+			case RefTypeRead, RefTypeWrite, RefTypeList:
+				context = fields[1]
+			default:
+				return nil, fmt.Errorf("%s:%d: unknown operation %q (expected read, write or list)", path, lineNum, fields[1])
+			}
+		}
+		key := bucket + "|" + prefix
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		refs = append(refs, Reference{
+			Bucket:  bucket,
+			Prefix:  prefix,
+			File:    path,
+			Line:    lineNum,
+			Context: context,
+		})
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	return refs, nil
+}
+
+// trimS3Scheme strips an s3://, s3a:// or s3n:// scheme
+func trimS3Scheme(target string) string {
+	for _, scheme := range []string{"s3://", "s3a://", "s3n://"} {
+		if rest, ok := strings.CutPrefix(target, scheme); ok {
+			return rest
+		}
+	}
+	return target
+}
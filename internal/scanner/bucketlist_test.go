@@ -0,0 +1,67 @@
+package scanner
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestReadBucketList(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "buckets.txt")
+	content := `# expected buckets
+prod-data
+s3://prod-logs/app/ write
+
+prod-data
+analytics-raw/events read
+s3a://spark-events/2024/ list
+`
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	refs, err := ReadBucketList(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(refs) != 4 {
+		t.Fatalf("got %d references, want 4: %+v", len(refs), refs)
+	}
+	if refs[0].Bucket != "prod-data" || refs[0].Line != 2 || refs[0].File != path {
+		t.Errorf("first reference: got %+v", refs[0])
+	}
+	if refs[1].Bucket != "prod-logs" || refs[1].Prefix != "app/" || refs[1].Context != "write" {
+		t.Errorf("URL reference: got %+v", refs[1])
+	}
+	if refs[2].Prefix != "events" || refs[2].Context != "read" || refs[2].Line != 6 {
+		t.Errorf("prefix reference: got %+v", refs[2])
+	}
+	if refs[3].Bucket != "spark-events" || refs[3].Prefix != "2024/" {
+		t.Errorf("s3a reference: got %+v", refs[3])
+	}
+}
+
+func TestReadBucketList_Invalid(t *testing.T) {
+	tests := map[string]string{
+		"bad name":      "Prod_Data\n",
+		"bad operation": "prod-data delete\n",
+		"extra fields":  "prod-data read now\n",
+	}
+	for name, content := range tests {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "buckets.txt")
+			if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+				t.Fatal(err)
+			}
+			_, err := ReadBucketList(path)
+			if err == nil || !strings.Contains(err.Error(), ":1:") {
+				t.Errorf("expected line-numbered error, got %v", err)
+			}
+		})
+	}
+
+	if _, err := ReadBucketList(filepath.Join(t.TempDir(), "missing.txt")); err == nil {
+		t.Error("expected error for missing file")
+	}
+}
@@ -0,0 +1,138 @@
+package scanner
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+const (
+	// defaultChunkSize is how much of a large file is read per window
+	defaultChunkSize = 4 * 1024 * 1024
+
+	// chunkOverlap is carried from one window into the next. It must be longer
+	// than any single match so that references straddling a chunk boundary are
+	// seen whole in the following window.
+	chunkOverlap = 4096
+)
+
+// chunkPattern pairs a pattern with the submatch groups holding bucket and prefix
+type chunkPattern struct {
+	re     *regexp.Regexp
+	bucket int
+	prefix int // -1 if the pattern captures no prefix
+}
+
+var (
+	jsonChunkPatterns = []chunkPattern{
+		{re: s3URLPattern, bucket: 1, prefix: 2},
+		{re: s3HTTPPattern, bucket: 1, prefix: 3},
+		{re: bucketNamePattern, bucket: 1, prefix: -1},
+	}
+	yamlChunkPatterns = []chunkPattern{
+		{re: s3URLPattern, bucket: 1, prefix: 2},
+		{re: yamlBucketPattern, bucket: 1, prefix: -1},
+	}
+)
+
+// isChunkable reports whether a file too large for line scanning can be read in chunks.
+// Only structured exports (JSON/YAML) are worth the cost; large source files are
+// almost always generated or vendored.
+func isChunkable(filePath string) bool {
+	switch strings.ToLower(filepath.Ext(filePath)) {
+	case ".json", ".yaml", ".yml":
+		return true
+	default:
+		return false
+	}
+}
+
+// scanLargeFile scans a large JSON or YAML file in fixed-size chunks.
+// Exported AWS Config snapshots are often a single multi-hundred-MB line,
+// which a line scanner cannot handle.
+func scanLargeFile(filePath string) ([]Reference, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = file.Close() }()
+
+	if strings.ToLower(filepath.Ext(filePath)) == ".json" {
+		return scanChunked(file, filePath, "json", jsonChunkPatterns, defaultChunkSize, chunkOverlap)
+	}
+	return scanChunked(file, filePath, "yaml", yamlChunkPatterns, defaultChunkSize, chunkOverlap)
+}
+
+// scanChunked applies patterns to r one window at a time. Each window is the
+// tail of the previous one (overlap bytes) plus the next chunk. Matches that
+// start inside the trailing overlap are deferred to the next window, so every
+// match is reported exactly once with its correct line number.
+func scanChunked(r io.Reader, filePath, context string, patterns []chunkPattern, chunkSize, overlap int) ([]Reference, error) {
+	type hit struct {
+		start int
+		ref   Reference
+	}
+
+	var refs []Reference
+	window := make([]byte, 0, chunkSize+overlap)
+	chunk := make([]byte, chunkSize)
+	line := 1 // line number of window[0]
+
+	for {
+		n, err := io.ReadFull(r, chunk)
+		eof := err == io.EOF || err == io.ErrUnexpectedEOF
+		if err != nil && !eof {
+			return nil, err
+		}
+		window = append(window, chunk[:n]...)
+
+		limit := len(window)
+		if !eof {
+			limit -= overlap
+			if limit <= 0 {
+				// Not enough data yet to scan past the overlap
+				continue
+			}
+		}
+
+		var hits []hit
+		for _, p := range patterns {
+			for _, m := range p.re.FindAllSubmatchIndex(window, -1) {
+				if m[0] >= limit {
+					continue
+				}
+				ref := Reference{
+					Bucket:  string(window[m[2*p.bucket]:m[2*p.bucket+1]]),
+					File:    filePath,
+					Context: context,
+				}
+				if p.prefix >= 0 && m[2*p.prefix] >= 0 {
+					ref.Prefix = string(window[m[2*p.prefix]:m[2*p.prefix+1]])
+				}
+				hits = append(hits, hit{start: m[0], ref: ref})
+			}
+		}
+
+		sort.SliceStable(hits, func(i, j int) bool { return hits[i].start < hits[j].start })
+		pos, cur := 0, line
+		for _, h := range hits {
+			cur += bytes.Count(window[pos:h.start], []byte{'\n'})
+			pos = h.start
+			h.ref.Line = cur
+			refs = append(refs, h.ref)
+		}
+
+		if eof {
+			break
+		}
+
+		line += bytes.Count(window[:limit], []byte{'\n'})
+		window = append(window[:0], window[limit:]...)
+	}
+
+	return refs, nil
+}
@@ -0,0 +1,125 @@
+package scanner
+
+import (
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// ciContext is the context of references found in CI configuration
+const ciContext = "ci"
+
+var (
+	// githubWorkflowNamePattern matches a workflow's top-level name
+	githubWorkflowNamePattern = regexp.MustCompile(`^name:\s*['"]?([^'"#]+?)['"]?\s*(?:#.*)?$`)
+
+	// gitlabJobPattern matches a top-level key of .gitlab-ci.yml
+	gitlabJobPattern = regexp.MustCompile(`^([^\s#:][^:]*):\s*(?:#.*)?$`)
+
+	// jenkinsStagePattern matches a declarative or scripted pipeline stage
+	jenkinsStagePattern = regexp.MustCompile(`\bstage\s*\(\s*['"]([^'"]+)['"]`)
+)
+
+// gitlabKeywords are top-level .gitlab-ci.yml keys that are not jobs
+var gitlabKeywords = map[string]bool{
+	"default": true, "include": true, "stages": true, "variables": true, "workflow": true,
+	"image": true, "services": true, "cache": true, "before_script": true, "after_script": true,
+}
+
+// isCIConfig reports whether a file is CI configuration: a GitHub Actions
+// workflow, .gitlab-ci.yml or a Jenkinsfile
+func isCIConfig(filePath string) bool {
+	return isGitHubWorkflow(filePath) || isGitLabCI(filePath) || isJenkinsfile(filePath)
+}
+
+func isGitHubWorkflow(filePath string) bool {
+	ext := strings.ToLower(filepath.Ext(filePath))
+	dir := filepath.Dir(filePath)
+	return (ext == ".yml" || ext == ".yaml") &&
+		filepath.Base(dir) == "workflows" && filepath.Base(filepath.Dir(dir)) == ".github"
+}
+
+func isGitLabCI(filePath string) bool {
+	return strings.ToLower(filepath.Base(filePath)) == ".gitlab-ci.yml"
+}
+
+func isJenkinsfile(filePath string) bool {
+	base := strings.ToLower(filepath.Base(filePath))
+	return base == "jenkinsfile" || strings.HasPrefix(base, "jenkinsfile.") || strings.HasSuffix(base, ".jenkinsfile")
+}
+
+// scanCI scans CI configuration for S3 URLs, such as those of aws s3 cp
+// and sync commands, and for bucket names set in environment variables or
+// passed with --bucket. References get the "ci" context and the name of
+// the GitHub workflow, GitLab job or Jenkins stage they are in. Values
+// built from CI variables are skipped, and prefixes end at the first one.
+// The content is read from readPath; filePath decides the CI system.
+func scanCI(filePath, readPath string) ([]Reference, error) {
+	lines, err := readLines(readPath)
+	if err != nil {
+		return nil, err
+	}
+
+	workflow := filepath.Base(filePath)
+	if isGitHubWorkflow(filePath) {
+		for _, line := range lines {
+			if match := githubWorkflowNamePattern.FindStringSubmatch(line); match != nil {
+				workflow = match[1]
+				break
+			}
+		}
+	}
+
+	var refs []Reference
+	section := workflow
+	for i, line := range lines {
+		switch {
+		case isGitLabCI(filePath):
+			if match := gitlabJobPattern.FindStringSubmatch(line); match != nil {
+				section = workflow
+				if !gitlabKeywords[match[1]] && !strings.HasPrefix(match[1], ".") {
+					section = match[1]
+				}
+			}
+		case isJenkinsfile(filePath):
+			if match := jenkinsStagePattern.FindStringSubmatch(line); match != nil {
+				section = match[1]
+			}
+		}
+		if strings.HasPrefix(strings.TrimSpace(line), "#") {
+			continue
+		}
+
+		found := make(map[string]bool)
+		for _, m := range s3URLPattern.FindAllStringSubmatchIndex(line, -1) {
+			if !completeBucket(line, m[3]) {
+				continue
+			}
+			bucket := line[m[2]:m[3]]
+			prefix := ""
+			if m[4] >= 0 {
+				prefix = ciStaticPrefix(line[m[4]:m[5]])
+			}
+			found[bucket] = true
+			refs = append(refs, Reference{Bucket: bucket, Prefix: prefix, File: filePath, Line: i + 1, Context: ciContext, Workflow: section})
+		}
+		for _, m := range bucketNamePattern.FindAllStringSubmatchIndex(line, -1) {
+			bucket := line[m[2]:m[3]]
+			if found[bucket] || !completeBucket(line, m[3]) {
+				continue
+			}
+			found[bucket] = true
+			refs = append(refs, Reference{Bucket: bucket, File: filePath, Line: i + 1, Context: ciContext, Workflow: section})
+		}
+	}
+	return refs, nil
+}
+
+// ciStaticPrefix cuts a key at its first CI variable or expression, such as
+// $GITHUB_SHA or ${{ github.ref_name }}
+func ciStaticPrefix(key string) string {
+	if i := strings.IndexAny(key, "${"); i >= 0 {
+		return key[:i]
+	}
+	return key
+}
@@ -0,0 +1,107 @@
+package scanner
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRepoScanner_CIConfig(t *testing.T) {
+	tmpDir := t.TempDir()
+	files := map[string]string{
+		".github/workflows/deploy.yml": `name: Deploy site
+on: push
+env:
+  ARTIFACT_BUCKET: deploy-artifacts
+  STAGE_BUCKET: site-${{ github.ref_name }}
+jobs:
+  upload:
+    steps:
+      - run: aws s3 sync dist/ s3://static-site/releases/${{ github.sha }}/
+      # aws s3 cp old s3://retired-bucket/
+`,
+		".github/dependabot.yml": `bucket: not-a-workflow
+`,
+		".gitlab-ci.yml": `variables:
+  S3_BUCKET: gitlab-global
+backup:
+  script:
+    - aws s3 cp dump.sql s3://db-backups/nightly/
+`,
+		"Jenkinsfile": `pipeline {
+  stages {
+    stage('Publish') {
+      steps {
+        sh 'aws s3api put-object --bucket jenkins-reports --key report.html'
+      }
+    }
+  }
+}
+`,
+	}
+	for name, content := range files {
+		path := filepath.Join(tmpDir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to create test file %s: %v", name, err)
+		}
+	}
+
+	s := NewRepoScanner(tmpDir)
+	refs, err := s.Scan(context.Background())
+	if err != nil {
+		t.Fatalf("Scan failed: %v", err)
+	}
+	got := make(map[string]Reference)
+	for _, ref := range refs {
+		got[ref.Bucket] = ref
+	}
+	want := map[string]string{
+		"deploy-artifacts": "Deploy site",
+		"static-site":      "Deploy site",
+		"gitlab-global":    ".gitlab-ci.yml",
+		"db-backups":       "backup",
+		"jenkins-reports":  "Publish",
+	}
+	for bucket, workflow := range want {
+		ref, ok := got[bucket]
+		if !ok {
+			t.Errorf("missing reference to %s in %v", bucket, refs)
+			continue
+		}
+		if ref.Context != "ci" || ref.Workflow != workflow {
+			t.Errorf("%s: context %q, workflow %q; want ci, %q", bucket, ref.Context, ref.Workflow, workflow)
+		}
+	}
+	if ref := got["static-site"]; ref.Prefix != "releases/" {
+		t.Errorf("prefix should stop at the expression, got %q", ref.Prefix)
+	}
+	for _, bucket := range []string{"site", "retired-bucket", "not-a-workflow"} {
+		if _, ok := got[bucket]; ok {
+			t.Errorf("unexpected reference to %s", bucket)
+		}
+	}
+	if s.FilesScanned() != 3 {
+		t.Errorf("FilesScanned = %d, want 3", s.FilesScanned())
+	}
+}
+
+func TestIsCIConfig(t *testing.T) {
+	tests := map[string]bool{
+		".github/workflows/ci.yaml":    true,
+		".github/ISSUE_TEMPLATE/a.yml": false,
+		"ci/.gitlab-ci.yml":            true,
+		"Jenkinsfile":                  true,
+		"jenkins/Jenkinsfile.release":  true,
+		"build.jenkinsfile":            true,
+		"workflows/ci.yml":             false,
+	}
+	for path, want := range tests {
+		if got := isCIConfig(path); got != want {
+			t.Errorf("isCIConfig(%q) = %v, want %v", path, got, want)
+		}
+	}
+}
@@ -0,0 +1,38 @@
+package scanner
+
+import "strings"
+
+// Reference confidence levels
+const (
+	ConfidenceHigh   = "high"
+	ConfidenceMedium = "medium"
+	ConfidenceLow    = "low"
+)
+
+// placeholderMarkers appear in example bucket names copied from docs
+var placeholderMarkers = []string{"example", "placeholder", "changeme"}
+
+// placeholderPrefixes start example bucket names such as my-bucket
+var placeholderPrefixes = []string{"my-", "your-"}
+
+// Confidence estimates how likely a reference is to name a real bucket the
+// code uses. Structured config and lines with an S3 operation are high;
+// lines with no recognizable operation are medium; names that look like
+// documentation placeholders are low.
+func (r Reference) Confidence() string {
+	name := strings.ToLower(r.Bucket)
+	for _, marker := range placeholderMarkers {
+		if strings.Contains(name, marker) {
+			return ConfidenceLow
+		}
+	}
+	for _, prefix := range placeholderPrefixes {
+		if strings.HasPrefix(name, prefix) {
+			return ConfidenceLow
+		}
+	}
+	if r.Context == "" || r.Context == string(RefTypeUnknown) {
+		return ConfidenceMedium
+	}
+	return ConfidenceHigh
+}
@@ -0,0 +1,55 @@
+package scanner
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestReferenceConfidence(t *testing.T) {
+	tests := []struct {
+		ref  Reference
+		want string
+	}{
+		{Reference{Bucket: "prod-data", Context: "read"}, ConfidenceHigh},
+		{Reference{Bucket: "prod-data", Context: "unknown"}, ConfidenceMedium},
+		{Reference{Bucket: "prod-data"}, ConfidenceMedium},
+		{Reference{Bucket: "example-bucket", Context: "write"}, ConfidenceLow},
+		{Reference{Bucket: "my-bucket", Context: "read"}, ConfidenceLow},
+		{Reference{Bucket: "YOUR-BUCKET-NAME", Context: "read"}, ConfidenceLow},
+	}
+	for _, tt := range tests {
+		if got := tt.ref.Confidence(); got != tt.want {
+			t.Errorf("Confidence(%q, %q) = %q, want %q", tt.ref.Bucket, tt.ref.Context, got, tt.want)
+		}
+	}
+}
+
+func TestRepoScanner_AllOccurrences(t *testing.T) {
+	tmpDir := t.TempDir()
+	content := "a: s3://shared-bucket/data/\nb: s3://shared-bucket/data/\n"
+	for _, name := range []string{"one.yaml", "two.yaml"} {
+		if err := os.WriteFile(filepath.Join(tmpDir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	s := NewRepoScanner(tmpDir)
+	refs, err := s.Scan(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(refs) != 1 {
+		t.Fatalf("default scan returned %d references, want 1", len(refs))
+	}
+
+	s.SetDedupe(DedupeNone)
+	refs, err = s.Scan(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(refs) != 4 {
+		t.Fatalf("all-occurrences scan returned %d references, want 4: %+v", len(refs), refs)
+	}
+}
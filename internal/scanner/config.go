@@ -0,0 +1,128 @@
+package scanner
+
+import (
+	"regexp"
+	"strings"
+)
+
+// configContext is the context of references found in .properties, .ini,
+// .toml and .cfg files
+const configContext = "config"
+
+var (
+	// configEntryPattern matches a key and its value, separated by '=' or ':'
+	configEntryPattern = regexp.MustCompile(`^([^=:\s][^=:]*?)\s*[=:]\s*(.*)$`)
+
+	// hadoopBucketKeyPattern matches the Hadoop S3A per-bucket options, such
+	// as fs.s3a.bucket.NAME.endpoint, which name the bucket in the key
+	hadoopBucketKeyPattern = regexp.MustCompile(`^fs\.s3a\.bucket\.([a-z0-9][a-z0-9\-\.]{1,61}[a-z0-9])\.`)
+
+	// configQuotedPattern matches a double- or single-quoted string
+	configQuotedPattern = regexp.MustCompile(`"([^"]*)"|'([^']*)'`)
+
+	// configPlaceholderPattern matches a Spring-style ${NAME:default}
+	// placeholder with a default
+	configPlaceholderPattern = regexp.MustCompile(`^\$\{[^:}]+:([^}]*)\}$`)
+)
+
+// scanConfig scans .properties, .ini, .toml and .cfg files for S3 URLs and
+// for bucket names in the values of keys that name a bucket, such as
+// s3.bucket-name=assets or bucket = "assets" under an [s3] section. TOML
+// arrays and comma-separated values give one reference per name, a
+// ${NAME:default} placeholder its default, and Hadoop's
+// fs.s3a.bucket.NAME.* options the bucket in the key.
+func scanConfig(filePath string) ([]Reference, error) {
+	lines, err := readLines(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	var refs []Reference
+	for i := 0; i < len(lines); i++ {
+		lineNum := i + 1
+		line := strings.TrimSpace(lines[i])
+		// Join .properties continuation lines and multi-line TOML arrays
+		for i+1 < len(lines) && (strings.HasSuffix(line, `\`) || openArray(line)) {
+			i++
+			line = strings.TrimSuffix(line, `\`) + strings.TrimSpace(lines[i])
+		}
+		if line == "" || strings.IndexByte("#;![", line[0]) >= 0 {
+			continue
+		}
+
+		refs = append(refs, outpostsRefs(line, filePath, lineNum, configContext)...)
+
+		found := make(map[string]bool)
+		for _, m := range s3URLPattern.FindAllStringSubmatchIndex(line, -1) {
+			if !completeBucket(line, m[3]) {
+				continue
+			}
+			ref := Reference{Bucket: line[m[2]:m[3]], File: filePath, Line: lineNum, Context: configContext}
+			if m[4] >= 0 {
+				ref.Prefix = line[m[4]:m[5]]
+			}
+			found[ref.Bucket] = true
+			refs = append(refs, ref)
+		}
+
+		match := configEntryPattern.FindStringSubmatch(line)
+		if match == nil {
+			continue
+		}
+		key := strings.Trim(match[1], `"'`)
+		if m := hadoopBucketKeyPattern.FindStringSubmatch(key); m != nil && !found[m[1]] {
+			found[m[1]] = true
+			refs = append(refs, Reference{Bucket: m[1], File: filePath, Line: lineNum, Context: configContext})
+		}
+		if !namesBucket(key) {
+			continue
+		}
+		for _, value := range configValues(match[2]) {
+			if found[value] || !bucketListNamePattern.MatchString(value) {
+				continue
+			}
+			found[value] = true
+			refs = append(refs, Reference{Bucket: value, File: filePath, Line: lineNum, Context: configContext})
+		}
+	}
+	return refs, nil
+}
+
+// openArray reports whether a line starts a TOML array it does not close
+func openArray(line string) bool {
+	match := configEntryPattern.FindStringSubmatch(line)
+	return match != nil && strings.HasPrefix(match[2], "[") && !strings.Contains(match[2], "]")
+}
+
+// configValues splits a raw value into the names it holds: the strings of a
+// TOML array, a quoted string, or comma-separated words with any inline
+// comment removed
+func configValues(raw string) []string {
+	raw = strings.TrimSpace(raw)
+	var values []string
+	switch {
+	case strings.HasPrefix(raw, "["):
+		for _, m := range configQuotedPattern.FindAllStringSubmatch(raw, -1) {
+			values = append(values, m[1]+m[2])
+		}
+	case strings.HasPrefix(raw, `"`) || strings.HasPrefix(raw, "'"):
+		if m := configQuotedPattern.FindStringSubmatch(raw); m != nil {
+			values = append(values, m[1]+m[2])
+		}
+	default:
+		for _, marker := range []string{" #", " ;", "\t#", "\t;"} {
+			if i := strings.Index(raw, marker); i >= 0 {
+				raw = raw[:i]
+			}
+		}
+		for _, value := range strings.Split(raw, ",") {
+			values = append(values, strings.TrimSpace(value))
+		}
+	}
+	for k, value := range values {
+		if m := configPlaceholderPattern.FindStringSubmatch(value); m != nil {
+			values[k] = m[1]
+		}
+	}
+	return values
+}
@@ -0,0 +1,103 @@
+package scanner
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRepoScanner_ConfigFiles(t *testing.T) {
+	tmpDir := t.TempDir()
+	files := map[string]string{
+		"src/main/resources/application.properties": `# storage
+app.s3.bucket-name=${UPLOAD_BUCKET:user-uploads}
+app.export.location=s3://report-exports/monthly/
+fs.s3a.bucket.spark-landing.endpoint=s3.eu-west-1.amazonaws.com
+app.description=mentions a bucket \
+    but only in prose
+! app.s3.bucket=commented-bucket
+`,
+		"settings.ini": `[s3]
+bucket = ini-assets ; primary
+log_bucket: ini-logs
+bucket_region = eu-central-1
+bucket_policy_file = policy-json
+`,
+		"pyproject.toml": `[tool.sync]
+buckets = [
+  "toml-one",
+  "toml-two",
+]
+target = "s3://toml-target/out/"
+bucket = "env-{{ env }}"
+`,
+		"setup.cfg": `[storage]
+backup_buckets = cfg-backup-a, cfg-backup-b
+`,
+	}
+	for name, content := range files {
+		path := filepath.Join(tmpDir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to create test file %s: %v", name, err)
+		}
+	}
+
+	refs, err := NewRepoScanner(tmpDir).Scan(context.Background())
+	if err != nil {
+		t.Fatalf("Scan failed: %v", err)
+	}
+	got := make(map[string]Reference)
+	for _, ref := range refs {
+		got[ref.Bucket] = ref
+	}
+	for _, bucket := range []string{
+		"user-uploads", "report-exports", "spark-landing", "ini-assets", "ini-logs",
+		"toml-one", "toml-two", "toml-target", "cfg-backup-a", "cfg-backup-b",
+	} {
+		ref, ok := got[bucket]
+		if !ok {
+			t.Errorf("missing bucket %s in %v", bucket, refs)
+			continue
+		}
+		if ref.Context != configContext {
+			t.Errorf("bucket %s: context %q, want %q", bucket, ref.Context, configContext)
+		}
+	}
+	if ref := got["report-exports"]; ref.Prefix != "monthly/" || ref.Line != 3 {
+		t.Errorf("report-exports = %+v, want prefix monthly/ on line 3", ref)
+	}
+	if ref := got["toml-two"]; ref.Line != 2 {
+		t.Errorf("array element line = %d, want the line the array starts on", ref.Line)
+	}
+	for _, bucket := range []string{"commented-bucket", "env", "prose", "eu-central-1", "policy-json"} {
+		if _, ok := got[bucket]; ok {
+			t.Errorf("unexpected bucket %s", bucket)
+		}
+	}
+}
+
+func TestConfigValues(t *testing.T) {
+	tests := map[string][]string{
+		`"quoted" # comment`:        {"quoted"},
+		`plain ; comment`:           {"plain"},
+		`a, b`:                      {"a", "b"},
+		`['x', "y"]`:                {"x", "y"},
+		`${BUCKET:fallback-bucket}`: {"fallback-bucket"},
+	}
+	for raw, want := range tests {
+		got := configValues(raw)
+		if len(got) != len(want) {
+			t.Errorf("configValues(%q) = %v, want %v", raw, got, want)
+			continue
+		}
+		for k := range want {
+			if got[k] != want[k] {
+				t.Errorf("configValues(%q) = %v, want %v", raw, got, want)
+			}
+		}
+	}
+}
@@ -0,0 +1,187 @@
+package scanner
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+var (
+	// yamlKeyPattern captures the key of a YAML mapping line, including
+	// dbt's "+" config prefix
+	yamlKeyPattern = regexp.MustCompile(`^\s*(?:-\s+)?\+?([\w-]+)\s*:`)
+
+	// sqlConfigKeyPattern captures the config argument or DDL clause before
+	// an S3 URL in a dbt model or SQL file
+	sqlConfigKeyPattern = regexp.MustCompile(`(?i)\b(location_root|external_location|location|s3_data_dir|unload|copy\s+into|from|to)\s*(?:=\s*|\(\s*|\s+)[rb]?['"]?\s*$`)
+)
+
+// dbtWriteKeys hold locations dbt writes to: Athena staging and data
+// directories in profiles.yml, and model locations in dbt_project.yml,
+// schema files and model configs
+var dbtWriteKeys = map[string]bool{
+	"s3_staging_dir":    true,
+	"s3_data_dir":       true,
+	"s3_tmp_table_dir":  true,
+	"staging_dir":       true,
+	"external_root":     true,
+	"external_location": true,
+	"location_root":     true,
+}
+
+// isDBTProjectFile reports whether a file is dbt configuration wherever it
+// is: a project file or connection profiles
+func isDBTProjectFile(filePath string) bool {
+	base := strings.ToLower(filepath.Base(filePath))
+	return base == "dbt_project.yml" || base == "profiles.yml"
+}
+
+// inDBTProject reports whether a file is inside a dbt project: a directory
+// between it and the repository root holds dbt_project.yml
+func (s *RepoScanner) inDBTProject(filePath string) bool {
+	if s.dbtDirs == nil {
+		s.dbtDirs = make(map[string]bool)
+	}
+	root := filepath.Clean(s.repoPath)
+	var visited []string
+	dir := filepath.Dir(filePath)
+	found := false
+	for {
+		if known, ok := s.dbtDirs[dir]; ok {
+			found = known
+			break
+		}
+		visited = append(visited, dir)
+		if _, err := os.Stat(filepath.Join(dir, "dbt_project.yml")); err == nil {
+			found = true
+			break
+		}
+		parent := filepath.Dir(dir)
+		if dir == root || parent == dir || !strings.HasPrefix(dir, root) {
+			break
+		}
+		dir = parent
+	}
+	for _, d := range visited {
+		s.dbtDirs[d] = found
+	}
+	return found
+}
+
+// scanDBTYAML scans a dbt YAML file like any YAML file, then sets the
+// operation of S3 locations from their key: source locations are read and
+// profile directories and model locations are written. URLs with a Jinja
+// template in the bucket name are dropped.
+func scanDBTYAML(filePath string) ([]Reference, error) {
+	refs, err := scanYAML(filePath)
+	if err != nil {
+		return nil, err
+	}
+	lines, err := readLines(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	// The top-level section each line is in, such as sources or models
+	sections := make([]string, len(lines))
+	section := ""
+	for i, line := range lines {
+		if line != "" && line[0] != ' ' && line[0] != '-' && line[0] != '#' {
+			section, _, _ = strings.Cut(line, ":")
+		}
+		sections[i] = section
+	}
+
+	kept := refs[:0]
+	for _, ref := range refs {
+		line := lines[ref.Line-1]
+		if strings.Contains(line, "://") && !hasCompleteURL(line, ref.Bucket) {
+			continue
+		}
+		if match := yamlKeyPattern.FindStringSubmatch(line); match != nil {
+			key := match[1]
+			switch {
+			case dbtWriteKeys[key]:
+				ref.Context = string(RefTypeWrite)
+			case key == "location" && sections[ref.Line-1] == "sources":
+				ref.Context = string(RefTypeRead)
+			case key == "location":
+				ref.Context = string(RefTypeWrite)
+			}
+		}
+		kept = append(kept, ref)
+	}
+	return kept, nil
+}
+
+// scanSQL scans SQL files, such as dbt models, for S3 URLs. A URL set as a
+// model's location, or unloaded or copied into, is written; one copied or
+// read from is read.
+func scanSQL(filePath string) ([]Reference, error) {
+	lines, err := readLines(filePath)
+	if err != nil {
+		return nil, err
+	}
+	var refs []Reference
+	for i, line := range lines {
+		for _, m := range s3URLPattern.FindAllStringSubmatchIndex(line, -1) {
+			if !completeBucket(line, m[3]) {
+				continue
+			}
+			prefix := ""
+			if m[4] >= 0 {
+				prefix = staticPrefix(line[m[4]:m[5]])
+			}
+			refs = append(refs, Reference{
+				Bucket:  line[m[2]:m[3]],
+				Prefix:  prefix,
+				File:    filePath,
+				Line:    i + 1,
+				Context: sqlContext(line[:m[0]]),
+			})
+		}
+	}
+	return refs, nil
+}
+
+// sqlContext infers the operation on a URL from the text before it
+func sqlContext(before string) string {
+	match := sqlConfigKeyPattern.FindStringSubmatch(before)
+	if match == nil {
+		return string(RefTypeUnknown)
+	}
+	switch strings.ToLower(strings.Join(strings.Fields(match[1]), " ")) {
+	case "from":
+		return string(RefTypeRead)
+	default:
+		return string(RefTypeWrite)
+	}
+}
+
+// hasCompleteURL reports whether the line has an S3 URL for bucket that is
+// not cut short by a template in the bucket name
+func hasCompleteURL(line, bucket string) bool {
+	for _, m := range s3URLPattern.FindAllStringSubmatchIndex(line, -1) {
+		if line[m[2]:m[3]] == bucket && completeBucket(line, m[3]) {
+			return true
+		}
+	}
+	return false
+}
+
+// readLines reads a file's lines
+func readLines(filePath string) ([]string, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = file.Close() }()
+	var lines []string
+	sc := bufio.NewScanner(file)
+	for sc.Scan() {
+		lines = append(lines, sc.Text())
+	}
+	return lines, sc.Err()
+}
@@ -0,0 +1,91 @@
+package scanner
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRepoScanner_DBTProject(t *testing.T) {
+	tmpDir := t.TempDir()
+	files := map[string]string{
+		"warehouse/dbt_project.yml": `name: warehouse
+models:
+  warehouse:
+    marts:
+      +external_location: s3://dbt-marts/marts/
+`,
+		"warehouse/profiles.yml": `warehouse:
+  outputs:
+    prod:
+      type: athena
+      s3_staging_dir: s3://athena-results/dbt/
+      s3_data_dir: s3://dbt-tables-{{ env_var('ENV') }}/data/
+`,
+		"warehouse/models/sources.yml": `version: 2
+sources:
+  - name: raw
+    tables:
+      - name: events
+        external:
+          location: "s3://raw-events/events/"
+`,
+		"warehouse/models/daily.sql": `{{ config(materialized='external', location='s3://dbt-exports/daily/') }}
+select * from {{ source('raw', 'events') }}
+`,
+		"app/config.yaml": `location: s3://app-assets/static/
+`,
+	}
+	for name, content := range files {
+		path := filepath.Join(tmpDir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to create test file %s: %v", name, err)
+		}
+	}
+
+	refs, err := NewRepoScanner(tmpDir).Scan(context.Background())
+	if err != nil {
+		t.Fatalf("Scan failed: %v", err)
+	}
+	got := make(map[string]string)
+	for _, ref := range refs {
+		got[ref.Bucket] = ref.Context
+	}
+	want := map[string]string{
+		"dbt-marts":      "write",
+		"athena-results": "write",
+		"raw-events":     "read",
+		"dbt-exports":    "write",
+		"app-assets":     "yaml",
+	}
+	for bucket, context := range want {
+		if got[bucket] != context {
+			t.Errorf("bucket %s: context %q, want %q (all: %v)", bucket, got[bucket], context, got)
+		}
+	}
+	for bucket := range got {
+		if bucket == "dbt-tables" {
+			t.Errorf("templated bucket name should be skipped: %v", got)
+		}
+	}
+}
+
+func TestSQLContext(t *testing.T) {
+	tests := map[string]string{
+		"{{ config(location='":             "write",
+		"{{ config(external_location = \"": "write",
+		"COPY events FROM '":               "read",
+		"UNLOAD ('select 1') TO '":         "write",
+		"LOCATION '":                       "write",
+		"-- see ":                          "unknown",
+	}
+	for before, want := range tests {
+		if got := sqlContext(before); got != want {
+			t.Errorf("sqlContext(%q) = %q, want %q", before, got, want)
+		}
+	}
+}
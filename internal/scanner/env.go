@@ -32,7 +32,10 @@ func scanEnv(filePath string) ([]Reference, error) {
 			continue
 		}
 
-		// Check for s3:// URLs
+		// Check for S3 on Outposts bucket ARNs
+		refs = append(refs, outpostsRefs(line, filePath, lineNum, "env")...)
+
+		// Check for s3://, s3a:// and s3n:// URLs
 		if matches := s3URLPattern.FindAllStringSubmatch(line, -1); matches != nil {
 			for _, match := range matches {
 				refs = append(refs, Reference{
@@ -0,0 +1,440 @@
+package scanner
+
+import (
+	"bufio"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// exportContext marks references found in database dumps and table exports
+const exportContext = "database"
+
+var (
+	// sqlInsertPattern matches the start of an INSERT statement up to its
+	// VALUES keyword, capturing the column list when there is one
+	sqlInsertPattern = regexp.MustCompile(`(?is)\bINSERT\s+(?:IGNORE\s+)?INTO\s+[^\s(]+\s*(?:\(([^)]*)\))?\s*VALUES\s*`)
+	// sqlCopyPattern matches a PostgreSQL COPY ... FROM stdin header, whose
+	// rows follow as tab-separated lines ending with \.
+	sqlCopyPattern = regexp.MustCompile(`(?i)^COPY\s+\S+\s*(?:\(([^)]*)\))?\s+FROM\s+stdin;\s*$`)
+)
+
+// exportCell is a value of a dumped or exported row and the line it is on
+type exportCell struct {
+	value string
+	line  int
+}
+
+// scanExport scans a database dump or table export named with --extra-file
+// for buckets that live in data rather than code, such as an application's
+// settings table. Files ending in .csv or .tsv are read as exports with a
+// header row; anything else as a SQL dump of INSERT statements or
+// PostgreSQL COPY blocks.
+// A value is a bucket when its column name ends in "bucket" or
+// "bucket_name", or when it follows a cell naming a bucket setting in a
+// key/value row such as ('s3.bucket', 'assets'). A column whose name
+// contains "prefix" sets the prefix of the row's buckets. S3 URLs anywhere
+// in the file are references. The file is streamed twice, once for URLs and
+// once for rows, so dumps need not fit in memory.
+func scanExport(filePath string) ([]Reference, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = file.Close() }()
+
+	var refs []Reference
+	// found dedupes buckets by line
+	found := make(map[string]bool)
+	add := func(ref Reference) {
+		key := ref.Bucket + "|" + ref.Prefix + "|" + strconv.Itoa(ref.Line)
+		if found[key] {
+			return
+		}
+		found[key] = true
+		ref.File = filePath
+		ref.Context = exportContext
+		refs = append(refs, ref)
+	}
+
+	err = eachLine(file, func(line string, n int) {
+		for _, m := range s3URLPattern.FindAllStringSubmatchIndex(line, -1) {
+			if !completeBucket(line, m[3]) {
+				continue
+			}
+			ref := Reference{Bucket: line[m[2]:m[3]], Line: n}
+			if m[4] >= 0 {
+				ref.Prefix = line[m[4]:m[5]]
+			}
+			add(ref)
+		}
+		for _, m := range s3HTTPPattern.FindAllStringSubmatchIndex(line, -1) {
+			ref := Reference{Bucket: line[m[2]:m[3]], Line: n}
+			if m[6] >= 0 {
+				ref.Prefix = line[m[6]:m[7]]
+			}
+			add(ref)
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+
+	row := func(columns []string, cells []exportCell) {
+		for _, ref := range exportRowRefs(columns, cells) {
+			add(ref)
+		}
+	}
+	switch strings.ToLower(filepath.Ext(filePath)) {
+	case ".csv", ".tsv":
+		err = scanCSVExport(file, filepath.Ext(filePath), row)
+	default:
+		err = scanSQLDump(file, row)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("parsing export %s: %w", filePath, err)
+	}
+	return refs, nil
+}
+
+// eachLine calls fn with each line of r and its 1-based number. Lines are
+// read whole, however long, but the file is not.
+func eachLine(r io.Reader, fn func(line string, n int)) error {
+	br := bufio.NewReader(r)
+	for n := 1; ; n++ {
+		line, err := br.ReadString('\n')
+		if line != "" {
+			fn(strings.TrimSuffix(line, "\n"), n)
+		}
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// exportRowRefs returns the buckets a row names, by column or by key
+func exportRowRefs(columns []string, cells []exportCell) []Reference {
+	var refs []Reference
+	prefix := ""
+	for k, cell := range cells {
+		if k < len(columns) && strings.Contains(columns[k], "prefix") && !strings.Contains(cell.value, "${") {
+			prefix = strings.TrimPrefix(strings.TrimSpace(cell.value), "/")
+		}
+	}
+	names := func(cell exportCell) {
+		for _, name := range configValues(cell.value) {
+			if bucketListNamePattern.MatchString(name) {
+				refs = append(refs, Reference{Bucket: name, Prefix: prefix, Line: cell.line})
+			}
+		}
+	}
+	for k, cell := range cells {
+		if k < len(columns) && namesBucket(columns[k]) {
+			names(cell)
+			continue
+		}
+		// A key naming a bucket setting, followed by its value
+		if k+1 < len(cells) && isBucketKey(cell.value) {
+			names(cells[k+1])
+		}
+	}
+	return refs
+}
+
+// isBucketKey reports whether a cell is a setting key that names a bucket,
+// such as s3.bucket or UPLOAD_BUCKET, rather than a sentence or a bucket
+// whose own name ends in "bucket"
+func isBucketKey(value string) bool {
+	if !namesBucket(value) || strings.ContainsAny(value, " \t/:") {
+		return false
+	}
+	lower := strings.ToLower(value)
+	return strings.ContainsAny(value, "_.") || lower != value || lower == "bucket"
+}
+
+// scanCSVExport reads a CSV or TSV export whose first row names the columns
+func scanCSVExport(in io.Reader, ext string, row func([]string, []exportCell)) error {
+	r := csv.NewReader(bufio.NewReader(in))
+	r.LazyQuotes = true
+	r.FieldsPerRecord = -1
+	if strings.EqualFold(ext, ".tsv") {
+		r.Comma = '\t'
+	}
+	var columns []string
+	for {
+		record, err := r.Read()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if columns == nil {
+			columns = exportColumns(record)
+			continue
+		}
+		cells := make([]exportCell, len(record))
+		for k, value := range record {
+			line, _ := r.FieldPos(k)
+			cells[k] = exportCell{value: value, line: line}
+		}
+		row(columns, cells)
+	}
+}
+
+// exportColumns lowercases column names and strips their quotes
+func exportColumns(names []string) []string {
+	columns := make([]string, len(names))
+	for k, name := range names {
+		columns[k] = strings.ToLower(strings.Trim(strings.TrimSpace(name), "`\"'[]"))
+	}
+	return columns
+}
+
+// sqlHeaderLines is how many lines an INSERT header, such as one with a
+// column per line, may span before its VALUES keyword
+const sqlHeaderLines = 32
+
+// sqlInsertStartPattern matches a line that may start an INSERT statement
+var sqlInsertStartPattern = regexp.MustCompile(`(?i)\bINSERT\b`)
+
+// sqlStream reads a SQL dump a byte or a line at a time, counting lines
+type sqlStream struct {
+	r *bufio.Reader
+	// pending holds bytes put back in front of r
+	pending string
+	// line is the line of the next byte
+	line int
+	err  error
+}
+
+// peek returns the next byte without reading it
+func (s *sqlStream) peek() (byte, bool) {
+	if s.pending != "" {
+		return s.pending[0], true
+	}
+	b, err := s.r.Peek(1)
+	if err != nil {
+		s.fail(err)
+		return 0, false
+	}
+	return b[0], true
+}
+
+// next reads one byte
+func (s *sqlStream) next() (byte, bool) {
+	var c byte
+	if s.pending != "" {
+		c, s.pending = s.pending[0], s.pending[1:]
+	} else {
+		var err error
+		if c, err = s.r.ReadByte(); err != nil {
+			s.fail(err)
+			return 0, false
+		}
+	}
+	if c == '\n' {
+		s.line++
+	}
+	return c, true
+}
+
+// readLine reads up to the end of the line and returns it with the line
+// number it started on
+func (s *sqlStream) readLine() (string, int, bool) {
+	start := s.line
+	var b strings.Builder
+	for {
+		c, ok := s.next()
+		if !ok {
+			return b.String(), start, b.Len() > 0
+		}
+		if c == '\n' {
+			return b.String(), start, true
+		}
+		b.WriteByte(c)
+	}
+}
+
+// unread puts text back in front of the stream, starting on line
+func (s *sqlStream) unread(text string, line int) {
+	s.pending = text + s.pending
+	s.line = line
+}
+
+// fail records a read error other than the end of the dump
+func (s *sqlStream) fail(err error) {
+	if !errors.Is(err, io.EOF) && s.err == nil {
+		s.err = err
+	}
+}
+
+// scanSQLDump reads the rows of INSERT statements and COPY blocks in a SQL
+// dump. A statement that cannot be parsed ends at the error, keeping the
+// rows read before it.
+func scanSQLDump(in io.Reader, row func([]string, []exportCell)) error {
+	s := &sqlStream{r: bufio.NewReader(in), line: 1}
+	for {
+		text, n, ok := s.readLine()
+		if !ok {
+			return s.err
+		}
+		if m := sqlCopyPattern.FindStringSubmatch(strings.TrimSpace(text)); m != nil {
+			var columns []string
+			if m[1] != "" {
+				columns = exportColumns(strings.Split(m[1], ","))
+			}
+			for {
+				line, n, ok := s.readLine()
+				line = strings.TrimRight(line, "\r")
+				if !ok || line == `\.` {
+					break
+				}
+				var cells []exportCell
+				for _, value := range strings.Split(line, "\t") {
+					if value == `\N` {
+						value = ""
+					}
+					cells = append(cells, exportCell{value: value, line: n})
+				}
+				row(columns, cells)
+			}
+			continue
+		}
+		if !sqlInsertStartPattern.MatchString(text) {
+			continue
+		}
+
+		// Join the lines of a header that spans several
+		first := text
+		for k := 1; k < sqlHeaderLines && !sqlInsertPattern.MatchString(text); k++ {
+			more, _, ok := s.readLine()
+			if !ok {
+				break
+			}
+			text += "\n" + more
+		}
+		m := sqlInsertPattern.FindStringSubmatchIndex(text)
+		if m == nil {
+			if len(text) > len(first) {
+				s.unread(text[len(first)+1:]+"\n", n+1)
+			}
+			continue
+		}
+		var columns []string
+		if m[2] >= 0 {
+			columns = exportColumns(strings.Split(text[m[2]:m[3]], ","))
+		}
+		// The values follow the header; anything after them on the same
+		// line, such as another statement, is read as the next line
+		s.unread(text[m[1]:]+"\n", n+strings.Count(text[:m[1]], "\n"))
+		sqlTuples(s, func(cells []exportCell) { row(columns, cells) })
+	}
+}
+
+// sqlTuples reads the parenthesized value lists of an INSERT, calling row
+// for each, and stops at the first byte that does not continue them
+func sqlTuples(s *sqlStream, row func([]exportCell)) {
+	skipSpace := func() {
+		for {
+			c, ok := s.peek()
+			if !ok || strings.IndexByte(" \t\r\n", c) < 0 {
+				return
+			}
+			s.next()
+		}
+	}
+	// at reports whether the next byte is c
+	at := func(c byte) bool {
+		next, ok := s.peek()
+		return ok && next == c
+	}
+
+	for {
+		skipSpace()
+		if !at('(') {
+			return
+		}
+		s.next()
+		var cells []exportCell
+		for {
+			skipSpace()
+			cell := exportCell{line: s.line}
+			var value strings.Builder
+			if at('\'') {
+				s.next()
+				for {
+					c, ok := s.next()
+					if !ok {
+						break
+					}
+					if c == '\\' {
+						if c, ok = s.next(); ok {
+							value.WriteByte(c)
+						}
+						continue
+					}
+					if c == '\'' {
+						if at('\'') {
+							value.WriteByte('\'')
+							s.next()
+							continue
+						}
+						break
+					}
+					value.WriteByte(c)
+				}
+				cell.value = value.String()
+			} else {
+				// An unquoted value such as NULL, 42 or now()
+				depth := 0
+				for {
+					c, ok := s.peek()
+					if !ok || (depth == 0 && (c == ',' || c == ')')) {
+						break
+					}
+					switch c {
+					case '(':
+						depth++
+					case ')':
+						depth--
+					}
+					value.WriteByte(c)
+					s.next()
+				}
+				cell.value = strings.TrimSpace(value.String())
+				if strings.EqualFold(cell.value, "NULL") {
+					cell.value = ""
+				}
+			}
+			cells = append(cells, cell)
+			skipSpace()
+			if at(')') {
+				s.next()
+				break
+			}
+			if !at(',') {
+				return
+			}
+			s.next()
+		}
+		row(cells)
+		skipSpace()
+		if !at(',') {
+			return
+		}
+		s.next()
+	}
+}
@@ -0,0 +1,155 @@
+package scanner
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const settingsDump = `-- MySQL dump
+INSERT INTO ` + "`settings`" + ` (` + "`key`, `value`" + `) VALUES ('s3.bucket','app-uploads'),('site.title','It''s ours'),
+('EXPORT_BUCKET','nightly-exports');
+INSERT INTO tenants (id, name, bucket_name, key_prefix) VALUES
+  (1, 'acme', 'acme-data', '/tenants/acme/'),
+  (2, 'globex', NULL, NULL);
+INSERT INTO jobs VALUES (7, 'copy to s3://job-output/daily/', now());
+
+COPY public.archives (id, archive_bucket) FROM stdin;
+1	cold-archive
+2	\N
+\.
+`
+
+func TestScanExport_SQLDump(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "dump.sql")
+	if err := os.WriteFile(path, []byte(settingsDump), 0644); err != nil {
+		t.Fatal(err)
+	}
+	refs, err := scanExport(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]Reference{
+		"app-uploads":     {Line: 2},
+		"nightly-exports": {Line: 3},
+		"acme-data":       {Line: 5, Prefix: "tenants/acme/"},
+		"job-output":      {Line: 7, Prefix: "daily/"},
+		"cold-archive":    {Line: 10},
+	}
+	if len(refs) != len(want) {
+		t.Fatalf("got %d references, want %d: %+v", len(refs), len(want), refs)
+	}
+	for _, ref := range refs {
+		w, ok := want[ref.Bucket]
+		if !ok {
+			t.Errorf("unexpected reference %+v", ref)
+			continue
+		}
+		if ref.Line != w.Line || ref.Prefix != w.Prefix || ref.Context != exportContext {
+			t.Errorf("%s: got line %d prefix %q context %q, want line %d prefix %q", ref.Bucket, ref.Line, ref.Prefix, ref.Context, w.Line, w.Prefix)
+		}
+	}
+}
+
+func TestScanExport_SplitHeader(t *testing.T) {
+	dump := "INSERT INTO buckets (\n  bucket_name,\n  bucket_region\n)\nVALUES ('split-header', 'eu-central-1');INSERT INTO logs VALUES\n(1, 'LOG_BUCKET', 'same-line');\n"
+	path := filepath.Join(t.TempDir(), "dump.sql")
+	if err := os.WriteFile(path, []byte(dump), 0644); err != nil {
+		t.Fatal(err)
+	}
+	refs, err := scanExport(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(refs) != 2 {
+		t.Fatalf("got %d references, want 2: %+v", len(refs), refs)
+	}
+	if refs[0].Bucket != "split-header" || refs[0].Line != 5 {
+		t.Errorf("split header: got %+v, want split-header on line 5", refs[0])
+	}
+	if refs[1].Bucket != "same-line" || refs[1].Line != 6 {
+		t.Errorf("second statement: got %+v, want same-line on line 6", refs[1])
+	}
+}
+
+func TestScanExport_CSV(t *testing.T) {
+	dir := t.TempDir()
+	csvPath := filepath.Join(dir, "settings.csv")
+	content := "name,value,notes\n" +
+		"upload_bucket,user-files,\"primary, do not delete\"\n" +
+		"site.title,assets-bucket,\n" +
+		"\"report\",\"s3://reports-prod/monthly\",\n"
+	if err := os.WriteFile(csvPath, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	refs, err := scanExport(csvPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(refs) != 2 {
+		t.Fatalf("got %d references, want 2: %+v", len(refs), refs)
+	}
+	if refs[0].Bucket != "reports-prod" || refs[0].Prefix != "monthly" || refs[0].Line != 4 {
+		t.Errorf("URL reference: got %+v", refs[0])
+	}
+	if refs[1].Bucket != "user-files" || refs[1].Line != 2 {
+		t.Errorf("key/value reference: got %+v", refs[1])
+	}
+
+	tsvPath := filepath.Join(dir, "tenants.tsv")
+	if err := os.WriteFile(tsvPath, []byte("id\tBucket\n1\ttenant-a\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	refs, err = scanExport(tsvPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(refs) != 1 || refs[0].Bucket != "tenant-a" || refs[0].Line != 2 {
+		t.Errorf("TSV bucket column: got %+v", refs)
+	}
+}
+
+func TestIsBucketKey(t *testing.T) {
+	tests := map[string]bool{
+		"s3.bucket":          true,
+		"UPLOAD_BUCKET":      true,
+		"bucketName":         true,
+		"bucket":             true,
+		"assets-bucket":      false,
+		"the bucket to use":  false,
+		"s3://bucket-a/path": false,
+		"region":             false,
+	}
+	for value, want := range tests {
+		if got := isBucketKey(value); got != want {
+			t.Errorf("isBucketKey(%q) = %v, want %v", value, got, want)
+		}
+	}
+}
+
+func TestRepoScanner_ExtraFiles(t *testing.T) {
+	repo := t.TempDir()
+	dump := filepath.Join(t.TempDir(), "dump.sql")
+	if err := os.WriteFile(dump, []byte(settingsDump), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	s := NewRepoScanner(repo)
+	s.SetExtraFiles([]string{dump})
+	refs, err := s.Scan(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(refs) != 5 {
+		t.Errorf("got %d references, want 5: %+v", len(refs), refs)
+	}
+	if s.FilesScanned() != 1 {
+		t.Errorf("FilesScanned() = %d, want 1", s.FilesScanned())
+	}
+
+	s.SetExtraFiles([]string{filepath.Join(repo, "missing.sql")})
+	if _, err := s.Scan(context.Background()); err == nil {
+		t.Error("expected error for a missing --extra-file")
+	}
+}
@@ -0,0 +1,119 @@
+package scanner
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+// Changes maps the files changed since a base ref, relative to the scanned
+// repository path, to the line numbers the change added
+type Changes map[string]map[int]bool
+
+// Added reports whether a line of a file, relative to the repository path,
+// was added by the change
+func (c Changes) Added(file string, line int) bool {
+	return c[file][line]
+}
+
+// GitChanges lists the lines added between the merge base of baseRef and
+// HEAD and the working tree of the repository at repoPath, so a branch is
+// compared with the point it forked from. Deleted files and untracked files
+// are left out.
+func GitChanges(ctx context.Context, repoPath, baseRef string) (Changes, error) {
+	mergeBase, err := git(ctx, repoPath, "merge-base", baseRef, "HEAD")
+	if err != nil {
+		return nil, fmt.Errorf("finding merge base with %s: %w", baseRef, err)
+	}
+	diff, err := git(ctx, repoPath, "-c", "core.quotePath=false",
+		"diff", "--unified=0", "--no-color", "--no-ext-diff", "--relative", "--diff-filter=d",
+		strings.TrimSpace(string(mergeBase)), "--")
+	if err != nil {
+		return nil, fmt.Errorf("diffing against %s: %w", baseRef, err)
+	}
+	return parseDiff(bytes.NewReader(diff))
+}
+
+// git runs a git command in dir and returns its output; failures carry
+// git's own message
+func git(ctx context.Context, dir string, args ...string) ([]byte, error) {
+	cmd := exec.CommandContext(ctx, "git", append([]string{"-C", dir}, args...)...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("%w: %s", err, msg)
+		}
+		return nil, err
+	}
+	return out, nil
+}
+
+// parseDiff reads the added lines of a unified diff with zero context. File
+// names are read only from the header between "diff --git" and the first
+// hunk, since an added line such as "++ x" also starts with "+++ " inside a
+// hunk.
+func parseDiff(r io.Reader) (Changes, error) {
+	changes := make(Changes)
+	var file string
+	inHeader := false
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 64*1024), maxFileSize)
+	for sc.Scan() {
+		line := sc.Text()
+		switch {
+		case strings.HasPrefix(line, "diff --git "):
+			inHeader = true
+			file = ""
+		case inHeader && strings.HasPrefix(line, "+++ "):
+			file = strings.TrimPrefix(line, "+++ ")
+			if file == "/dev/null" {
+				file = ""
+				continue
+			}
+			file = strings.TrimPrefix(file, "b/")
+			if changes[file] == nil {
+				changes[file] = make(map[int]bool)
+			}
+		case strings.HasPrefix(line, "@@ ") && file != "":
+			inHeader = false
+			start, count, err := parseHunkHeader(line)
+			if err != nil {
+				return nil, err
+			}
+			for n := start; n < start+count; n++ {
+				changes[file][n] = true
+			}
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	return changes, nil
+}
+
+// parseHunkHeader returns the new-file range of a hunk header such as
+// "@@ -10,2 +12,3 @@"; a range without a count is one line long
+func parseHunkHeader(header string) (start, count int, err error) {
+	fields := strings.Fields(header)
+	if len(fields) < 3 || !strings.HasPrefix(fields[2], "+") {
+		return 0, 0, fmt.Errorf("malformed hunk header %q", header)
+	}
+	startText, countText, hasCount := strings.Cut(strings.TrimPrefix(fields[2], "+"), ",")
+	if start, err = strconv.Atoi(startText); err != nil {
+		return 0, 0, fmt.Errorf("malformed hunk header %q", header)
+	}
+	count = 1
+	if hasCount {
+		if count, err = strconv.Atoi(countText); err != nil {
+			return 0, 0, fmt.Errorf("malformed hunk header %q", header)
+		}
+	}
+	return start, count, nil
+}
@@ -0,0 +1,126 @@
+package scanner
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseDiff(t *testing.T) {
+	diff := `diff --git a/app.py b/app.py
+index 1111111..2222222 100644
+--- a/app.py
++++ b/app.py
+@@ -3 +3 @@ import boto3
+-BUCKET = "old-bucket"
++BUCKET = "new-bucket"
+@@ -10,0 +11,2 @@ def main():
++    s3.put_object(Bucket="logs", Key="a")
++    s3.put_object(Bucket="logs", Key="b")
+@@ -20,3 +22,0 @@
+-gone
+@@ -30,0 +30,2 @@
++++ not-a-header
++--- nor-this
+diff --git a/config/new.yaml b/config/new.yaml
+new file mode 100644
+--- /dev/null
++++ b/config/new.yaml
+@@ -0,0 +1,2 @@
++bucket: fresh-bucket
++prefix: data/
+diff --git a/logo.png b/logo.png
+Binary files a/logo.png and b/logo.png differ
+`
+	changes, err := parseDiff(strings.NewReader(diff))
+	if err != nil {
+		t.Fatalf("parseDiff: %v", err)
+	}
+	if len(changes) != 2 {
+		t.Fatalf("expected 2 changed files, got %v", changes)
+	}
+	for _, line := range []int{3, 11, 12, 30, 31} {
+		if !changes.Added("app.py", line) {
+			t.Errorf("app.py:%d should be added", line)
+		}
+	}
+	for _, line := range []int{4, 10, 13, 22, 32} {
+		if changes.Added("app.py", line) {
+			t.Errorf("app.py:%d should not be added", line)
+		}
+	}
+	if !changes.Added("config/new.yaml", 1) || !changes.Added("config/new.yaml", 2) {
+		t.Errorf("new file lines should be added: %v", changes["config/new.yaml"])
+	}
+
+	if _, err := parseDiff(strings.NewReader("diff --git a/a.py b/a.py\n--- a/a.py\n+++ b/a.py\n@@ -1 +x @@\n")); err == nil {
+		t.Error("expected error for malformed hunk header")
+	}
+}
+
+func TestGitChanges_ScanAddedReferences(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	dir := t.TempDir()
+	run := func(args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", append([]string{"-C", dir}, args...)...)
+		cmd.Env = append(os.Environ(), "GIT_AUTHOR_NAME=t", "GIT_AUTHOR_EMAIL=t@example.com", "GIT_COMMITTER_NAME=t", "GIT_COMMITTER_EMAIL=t@example.com")
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+	write := func(name, content string) {
+		t.Helper()
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	run("init", "-q", "-b", "main")
+	write("app.py", "BUCKET = \"s3://existing-bucket/data\"\n")
+	write("old.py", "OTHER = \"s3://untouched-bucket/data\"\n")
+	run("add", ".")
+	run("commit", "-q", "-m", "base")
+	run("checkout", "-q", "-b", "feature")
+	write("app.py", "BUCKET = \"s3://existing-bucket/data\"\nNEW = \"s3://added-bucket/data\"\n")
+	write("etl/job.py", "s3.get_object(Bucket=\"job-bucket\", Key=\"k\")\n")
+	run("add", ".")
+	run("commit", "-q", "-m", "feature")
+
+	changes, err := GitChanges(context.Background(), dir, "main")
+	if err != nil {
+		t.Fatalf("GitChanges: %v", err)
+	}
+	s := NewRepoScanner(dir)
+	s.SetChanges(changes)
+	refs, err := s.Scan(context.Background())
+	if err != nil {
+		t.Fatalf("Scan: %v", err)
+	}
+	got := make(map[string]bool)
+	for _, ref := range refs {
+		got[ref.Bucket] = true
+	}
+	if !got["added-bucket"] || !got["job-bucket"] {
+		t.Errorf("added references missing: %v", refs)
+	}
+	if got["existing-bucket"] || got["untouched-bucket"] {
+		t.Errorf("unchanged references should be left out: %v", refs)
+	}
+	if s.FilesScanned() != 2 {
+		t.Errorf("FilesScanned() = %d, want only the 2 changed files", s.FilesScanned())
+	}
+
+	if _, err := GitChanges(context.Background(), dir, "no-such-ref"); err == nil {
+		t.Error("expected error for unknown base ref")
+	}
+}
@@ -0,0 +1,58 @@
+package scanner
+
+import (
+	"bufio"
+	"os"
+	"regexp"
+)
+
+var (
+	// glacierURLPattern matches glacier://vault-name references
+	glacierURLPattern = regexp.MustCompile(`glacier://([A-Za-z0-9_.\-]{1,255})`)
+	// glacierARNPattern matches Glacier vault ARNs:
+	// arn:aws:glacier:us-east-1:123456789012:vaults/backups
+	glacierARNPattern = regexp.MustCompile(`arn:aws[a-z-]*:glacier:([a-z0-9-]+):\d{12}:vaults/([A-Za-z0-9_.\-]{1,255})`)
+)
+
+// VaultReference represents a Glacier vault reference found in code
+type VaultReference struct {
+	Vault string `json:"vault"`
+	// Region is set when the reference is an ARN
+	Region string `json:"region,omitempty"`
+	File   string `json:"file"`
+	Line   int    `json:"line"`
+}
+
+// SetScanVaults makes Scan also collect Glacier vault references
+func (s *RepoScanner) SetScanVaults(enabled bool) {
+	s.scanVaults = enabled
+}
+
+// Vaults returns the Glacier vault references found by the last Scan
+func (s *RepoScanner) Vaults() []VaultReference {
+	return s.vaults
+}
+
+// scanVaultFile extracts Glacier vault references from a file
+func scanVaultFile(filePath string) ([]VaultReference, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = file.Close() }()
+
+	var refs []VaultReference
+	scanner := bufio.NewScanner(file)
+	lineNum := 0
+	for scanner.Scan() {
+		lineNum++
+		line := scanner.Text()
+		for _, match := range glacierURLPattern.FindAllStringSubmatch(line, -1) {
+			refs = append(refs, VaultReference{Vault: match[1], File: filePath, Line: lineNum})
+		}
+		for _, match := range glacierARNPattern.FindAllStringSubmatch(line, -1) {
+			refs = append(refs, VaultReference{Vault: match[2], Region: match[1], File: filePath, Line: lineNum})
+		}
+	}
+	return refs, scanner.Err()
+}
@@ -0,0 +1,373 @@
+package scanner
+
+import (
+	"encoding/base64"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// helmContext is the context of references found in Helm charts
+const helmContext = "helm"
+
+// helmUnresolved stands in for template actions the scanner cannot
+// evaluate. Like an unexpanded variable, it ends a bucket name or prefix.
+const helmUnresolved = "${unresolved}"
+
+// helmActionPattern matches a template action, capturing its pipeline
+var helmActionPattern = regexp.MustCompile(`{{-?\s*(.*?)\s*-?}}`)
+
+// helmChart is a chart's name and default values
+type helmChart struct {
+	name   string
+	values map[string]any
+}
+
+// chartDir returns the directory of the Helm chart a file is in: the
+// nearest directory between it and the repository root that holds
+// Chart.yaml, or "" outside a chart
+func (s *RepoScanner) chartDir(filePath string) string {
+	if s.helmDirs == nil {
+		s.helmDirs = make(map[string]string)
+	}
+	root := filepath.Clean(s.repoPath)
+	var visited []string
+	dir := filepath.Dir(filePath)
+	found := ""
+	for {
+		if known, ok := s.helmDirs[dir]; ok {
+			found = known
+			break
+		}
+		visited = append(visited, dir)
+		if _, err := os.Stat(filepath.Join(dir, "Chart.yaml")); err == nil {
+			found = dir
+			break
+		}
+		parent := filepath.Dir(dir)
+		if dir == root || parent == dir || !strings.HasPrefix(dir, root) {
+			break
+		}
+		dir = parent
+	}
+	for _, d := range visited {
+		s.helmDirs[d] = found
+	}
+	return found
+}
+
+// loadChart reads a chart's name from Chart.yaml and its values.yaml.
+// Files that do not parse leave the name as the directory's and the values
+// empty.
+func (s *RepoScanner) loadChart(dir string) *helmChart {
+	if chart, ok := s.helmCharts[dir]; ok {
+		return chart
+	}
+	if s.helmCharts == nil {
+		s.helmCharts = make(map[string]*helmChart)
+	}
+	chart := &helmChart{name: filepath.Base(dir)}
+	var meta struct {
+		Name string `yaml:"name"`
+	}
+	if data, err := os.ReadFile(filepath.Join(dir, "Chart.yaml")); err == nil {
+		if yaml.Unmarshal(data, &meta) == nil && meta.Name != "" {
+			chart.name = meta.Name
+		}
+	}
+	if data, err := os.ReadFile(filepath.Join(dir, "values.yaml")); err == nil {
+		_ = yaml.Unmarshal(data, &chart.values)
+	}
+	s.helmCharts[dir] = chart
+	return chart
+}
+
+// scanHelm scans a YAML file of the Helm chart in dir. Values files are
+// read for bucket names, templates are rendered with the chart's
+// values.yaml before they are scanned, and other files are scanned as YAML.
+// References get the "helm" context, and the chart's name or the kind and
+// name of the rendered object as their workflow.
+func (s *RepoScanner) scanHelm(filePath, readPath, dir string) ([]Reference, error) {
+	rel, err := filepath.Rel(dir, filePath)
+	if err != nil {
+		return scanManifestYAML(readPath)
+	}
+	rel = filepath.ToSlash(rel)
+	chart := s.loadChart(dir)
+
+	var refs []Reference
+	switch {
+	case !strings.Contains(rel, "/") && strings.HasPrefix(rel, "values"):
+		refs, err = scanHelmValues(readPath)
+	case strings.HasPrefix(rel, "templates/"):
+		refs, err = scanHelmTemplate(readPath, chart.values)
+	default:
+		return scanManifestYAML(readPath)
+	}
+	if err != nil {
+		return nil, err
+	}
+	for i := range refs {
+		refs[i].Context = helmContext
+		if refs[i].Workflow == "" {
+			refs[i].Workflow = chart.name
+		}
+	}
+	return refs, nil
+}
+
+// scanHelmValues scans a values file line by line, then reads every value
+// for S3 URLs and bucket names set under keys that mention a bucket, such
+// as s3.bucketName
+func scanHelmValues(filePath string) ([]Reference, error) {
+	refs, err := scanYAML(filePath)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+	var root yaml.Node
+	if yaml.Unmarshal(data, &root) != nil {
+		return refs, nil
+	}
+	var valRefs []Reference
+	var walk func(key string, node *yaml.Node)
+	walk = func(key string, node *yaml.Node) {
+		switch node.Kind {
+		case yaml.DocumentNode, yaml.SequenceNode:
+			for _, child := range node.Content {
+				walk(key, child)
+			}
+		case yaml.MappingNode:
+			for i := 0; i+1 < len(node.Content); i += 2 {
+				walk(node.Content[i].Value, node.Content[i+1])
+			}
+		case yaml.ScalarNode:
+			valRefs = append(valRefs, valueRefs(key, node, node.Value, Reference{File: filePath})...)
+		}
+	}
+	walk("", &root)
+	return mergeObjectRefs(refs, valRefs), nil
+}
+
+// scanHelmTemplate renders a template line by line with the chart's values,
+// then scans the result like a Kubernetes manifest. Actions the scanner
+// cannot evaluate become helmUnresolved, so bucket names and prefixes built
+// from them are dropped or cut short; lines of nothing but such actions,
+// such as {{- if }} and {{- end }}, are blanked. References are reported at
+// the template line that produced them.
+func scanHelmTemplate(filePath string, values map[string]any) ([]Reference, error) {
+	lines, err := readLines(filePath)
+	if err != nil {
+		return nil, err
+	}
+	var rendered []string
+	var origin []int
+	for i, line := range lines {
+		for _, out := range strings.Split(renderHelmLine(line, values), "\n") {
+			rendered = append(rendered, out)
+			origin = append(origin, i+1)
+		}
+	}
+
+	var refs []Reference
+	for i, line := range rendered {
+		for _, ref := range yamlLineRefs(line, filePath, origin[i]) {
+			if strings.Contains(line, helmUnresolved) && !hasCompleteBucket(line, ref.Bucket) {
+				continue
+			}
+			ref.Prefix = ciStaticPrefix(ref.Prefix)
+			refs = append(refs, ref)
+		}
+	}
+	objRefs := kubernetesRefs([]byte(strings.Join(rendered, "\n")), filePath)
+	for i := range objRefs {
+		objRefs[i].Line = origin[objRefs[i].Line-1]
+	}
+	return mergeObjectRefs(refs, objRefs), nil
+}
+
+// hasCompleteBucket reports whether bucket appears on a line as a whole
+// name rather than the start of one built from a template
+func hasCompleteBucket(line, bucket string) bool {
+	for start := 0; ; {
+		i := strings.Index(line[start:], bucket)
+		if i < 0 {
+			return false
+		}
+		end := start + i + len(bucket)
+		if completeBucket(line, end) || line[end] == '#' {
+			return true
+		}
+		start = end
+	}
+}
+
+// renderHelmLine evaluates the template actions on a line
+func renderHelmLine(line string, values map[string]any) string {
+	matches := helmActionPattern.FindAllStringSubmatchIndex(line, -1)
+	if matches == nil {
+		return line
+	}
+	actionsOnly := strings.TrimSpace(helmActionPattern.ReplaceAllString(line, "")) == ""
+	var b strings.Builder
+	last := 0
+	for _, m := range matches {
+		b.WriteString(line[last:m[0]])
+		if out, ok := evalHelmPipeline(line[m[2]:m[3]], values); ok {
+			b.WriteString(out)
+		} else if !actionsOnly {
+			b.WriteString(helmUnresolved)
+		}
+		last = m[1]
+	}
+	b.WriteString(line[last:])
+	return b.String()
+}
+
+// evalHelmPipeline evaluates the pipelines charts use to insert values:
+// a .Values path or a string, passed through default, quote, squote,
+// toYaml, indent, nindent, b64enc, toString, lower, upper and trim. It
+// reports false for anything else, such as control structures, includes
+// and values the chart does not set.
+func evalHelmPipeline(pipeline string, values map[string]any) (string, bool) {
+	var value any
+	for i, cmd := range strings.Split(pipeline, "|") {
+		args := helmArgs(cmd)
+		if len(args) == 0 {
+			return "", false
+		}
+		if i == 0 {
+			if len(args) == 1 {
+				value = helmOperand(args[0], values)
+				continue
+			}
+			// The last argument of the first command is its input
+			value = helmOperand(args[len(args)-1], values)
+			args = args[:len(args)-1]
+		}
+		var ok bool
+		if value, ok = applyHelmFunc(args[0], args[1:], value); !ok {
+			return "", false
+		}
+	}
+	switch v := value.(type) {
+	case nil, map[string]any, []any:
+		return "", false
+	case string:
+		return v, true
+	default:
+		return fmt.Sprint(v), true
+	}
+}
+
+// applyHelmFunc applies a template function to a pipeline's value; a nil
+// value is one the chart does not set
+func applyHelmFunc(name string, args []string, value any) (any, bool) {
+	if name == "default" {
+		if len(args) != 1 {
+			return nil, false
+		}
+		if value == nil || value == "" {
+			return helmOperand(args[0], nil), true
+		}
+		return value, true
+	}
+	if value == nil {
+		return nil, false
+	}
+	if name == "toYaml" {
+		out, err := yaml.Marshal(value)
+		if err != nil {
+			return nil, false
+		}
+		return strings.TrimSuffix(string(out), "\n"), true
+	}
+	s, ok := value.(string)
+	switch value.(type) {
+	case map[string]any, []any:
+		return nil, false
+	}
+	if !ok {
+		s = fmt.Sprint(value)
+	}
+	switch name {
+	case "quote":
+		return strconv.Quote(s), true
+	case "squote":
+		return "'" + s + "'", true
+	case "toString":
+		return s, true
+	case "lower":
+		return strings.ToLower(s), true
+	case "upper":
+		return strings.ToUpper(s), true
+	case "trim":
+		return strings.TrimSpace(s), true
+	case "b64enc":
+		return base64.StdEncoding.EncodeToString([]byte(s)), true
+	case "indent", "nindent":
+		if len(args) != 1 {
+			return nil, false
+		}
+		n, err := strconv.Atoi(args[0])
+		if err != nil {
+			return nil, false
+		}
+		pad := strings.Repeat(" ", n)
+		s = pad + strings.ReplaceAll(s, "\n", "\n"+pad)
+		if name == "nindent" {
+			s = "\n" + s
+		}
+		return s, true
+	}
+	return nil, false
+}
+
+// helmOperand evaluates a .Values path or a quoted string, returning nil
+// for anything else
+func helmOperand(arg string, values map[string]any) any {
+	if s, err := strconv.Unquote(arg); err == nil {
+		return s
+	}
+	path, ok := strings.CutPrefix(strings.TrimPrefix(arg, "$"), ".Values.")
+	if !ok {
+		return nil
+	}
+	var value any = values
+	for _, key := range strings.Split(path, ".") {
+		m, ok := value.(map[string]any)
+		if !ok {
+			return nil
+		}
+		value = m[key]
+	}
+	return value
+}
+
+// helmArgs splits a template command into its words, keeping quoted
+// strings whole
+func helmArgs(cmd string) []string {
+	var args []string
+	for cmd = strings.TrimSpace(cmd); cmd != ""; cmd = strings.TrimSpace(cmd) {
+		end := strings.IndexAny(cmd, " \t")
+		if cmd[0] == '"' || cmd[0] == '`' {
+			if quoted, err := strconv.QuotedPrefix(cmd); err == nil {
+				end = len(quoted)
+			}
+		}
+		if end < 0 {
+			end = len(cmd)
+		}
+		args = append(args, cmd[:end])
+		cmd = cmd[end:]
+	}
+	return args
+}
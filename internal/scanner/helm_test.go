@@ -0,0 +1,159 @@
+package scanner
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRepoScanner_HelmChart(t *testing.T) {
+	tmpDir := t.TempDir()
+	files := map[string]string{
+		"charts/ingest/Chart.yaml": `apiVersion: v2
+name: ingest
+version: 1.0.0
+`,
+		"charts/ingest/values.yaml": `s3:
+  bucketName: helm-ingest
+  archive: s3://helm-archive/raw/
+env:
+  - name: REPORT_BUCKET
+    value: helm-reports
+region: us-east-1
+`,
+		"charts/ingest/templates/deployment.yaml": `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: {{ include "ingest.fullname" . }}
+spec:
+  template:
+    spec:
+      containers:
+        - name: worker
+          env:
+            - name: DATA_BUCKET
+              value: {{ .Values.s3.bucketName | quote }}
+            - name: STAGING
+              value: "s3://{{ .Values.s3.bucketName }}-staging/{{ .Release.Name }}/tmp"
+            - name: SCRATCH_BUCKET
+              value: {{ .Values.scratch | default "helm-scratch" }}
+            - name: TENANT_BUCKET
+              value: "tenant-{{ .Values.tenant }}"
+            {{- toYaml .Values.env | nindent 12 }}
+`,
+		"charts/ingest/templates/configmap.yaml": `{{- if .Values.s3.archive }}
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: ingest-config
+data:
+  ARCHIVE_URL: {{ .Values.s3.archive }}
+{{- end }}
+`,
+		"deploy/plain.yaml": `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: outside
+data:
+  LOGS_BUCKET: plain-logs
+`,
+	}
+	for name, content := range files {
+		path := filepath.Join(tmpDir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to create test file %s: %v", name, err)
+		}
+	}
+
+	s := NewRepoScanner(tmpDir)
+	s.SetDedupe(DedupeNone)
+	refs, err := s.Scan(context.Background())
+	if err != nil {
+		t.Fatalf("Scan failed: %v", err)
+	}
+	type place struct {
+		file string
+		line int
+	}
+	got := make(map[string][]Reference)
+	for _, ref := range refs {
+		got[ref.Bucket] = append(got[ref.Bucket], ref)
+	}
+	want := map[string]struct {
+		at       place
+		context  string
+		workflow string
+	}{
+		"helm-ingest":         {place{"charts/ingest/templates/deployment.yaml", 12}, helmContext, "Deployment"},
+		"helm-ingest-staging": {place{"charts/ingest/templates/deployment.yaml", 14}, helmContext, "Deployment"},
+		"helm-scratch":        {place{"charts/ingest/templates/deployment.yaml", 16}, helmContext, "Deployment"},
+		"helm-reports":        {place{"charts/ingest/templates/deployment.yaml", 19}, helmContext, "Deployment"},
+		"helm-archive":        {place{"charts/ingest/templates/configmap.yaml", 7}, helmContext, "ConfigMap/ingest-config"},
+		"plain-logs":          {place{"deploy/plain.yaml", 6}, k8sContext, "ConfigMap/outside"},
+	}
+	for bucket, w := range want {
+		found := false
+		for _, ref := range got[bucket] {
+			rel, _ := filepath.Rel(tmpDir, ref.File)
+			if (place{filepath.ToSlash(rel), ref.Line}) == w.at {
+				found = true
+				if ref.Context != w.context || ref.Workflow != w.workflow {
+					t.Errorf("%s: context %q, workflow %q; want %q, %q", bucket, ref.Context, ref.Workflow, w.context, w.workflow)
+				}
+			}
+		}
+		if !found {
+			t.Errorf("missing reference to %s at %v in %v", bucket, w.at, got[bucket])
+		}
+	}
+	for _, ref := range got["helm-ingest"] {
+		if filepath.Base(ref.File) == "values.yaml" && (ref.Line != 2 || ref.Workflow != "ingest") {
+			t.Errorf("values reference at line %d, workflow %q; want 2, ingest", ref.Line, ref.Workflow)
+		}
+	}
+	for _, ref := range got["helm-ingest-staging"] {
+		if ref.Prefix != "" {
+			t.Errorf("prefix should stop at the unresolved action, got %q", ref.Prefix)
+		}
+	}
+	if _, ok := got["tenant"]; ok {
+		t.Error("bucket names built from unset values should be dropped")
+	}
+}
+
+func TestEvalHelmPipeline(t *testing.T) {
+	values := map[string]any{
+		"s3":    map[string]any{"bucket": "data", "port": 9000},
+		"empty": "",
+		"env":   []any{map[string]any{"name": "A", "value": "b"}},
+	}
+	tests := []struct {
+		pipeline string
+		want     string
+		ok       bool
+	}{
+		{".Values.s3.bucket", "data", true},
+		{"$.Values.s3.bucket | upper", "DATA", true},
+		{".Values.s3.port", "9000", true},
+		{".Values.s3.bucket | quote", `"data"`, true},
+		{`.Values.missing | default "fallback"`, "fallback", true},
+		{`.Values.empty | default "fallback"`, "fallback", true},
+		{`default "fallback" .Values.s3.bucket`, "data", true},
+		{"toYaml .Values.env | nindent 2", "\n  - name: A\n    value: b", true},
+		{".Values.s3", "", false},
+		{".Values.missing", "", false},
+		{"if .Values.s3.bucket", "", false},
+		{`include "chart.fullname" .`, "", false},
+		{".Release.Name", "", false},
+	}
+	for _, tt := range tests {
+		got, ok := evalHelmPipeline(tt.pipeline, values)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("evalHelmPipeline(%q) = %q, %v; want %q, %v", tt.pipeline, got, ok, tt.want, tt.ok)
+		}
+	}
+}
@@ -21,7 +21,10 @@ func scanJSON(filePath string) ([]Reference, error) {
 		lineNum++
 		line := scanner.Text()
 
-		// Check for s3:// URLs
+		// Check for S3 on Outposts bucket ARNs
+		refs = append(refs, outpostsRefs(line, filePath, lineNum, "json")...)
+
+		// Check for s3://, s3a:// and s3n:// URLs
 		if matches := s3URLPattern.FindAllStringSubmatch(line, -1); matches != nil {
 			for _, match := range matches {
 				refs = append(refs, Reference{
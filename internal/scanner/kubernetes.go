@@ -0,0 +1,237 @@
+package scanner
+
+import (
+	"bytes"
+	"encoding/base64"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// k8sContext is the context of references found in Kubernetes objects
+const k8sContext = "kubernetes"
+
+// k8sPodSpecPaths locate the pod spec of each workload kind
+var k8sPodSpecPaths = map[string][]string{
+	"Pod":         {"spec"},
+	"Deployment":  {"spec", "template", "spec"},
+	"StatefulSet": {"spec", "template", "spec"},
+	"DaemonSet":   {"spec", "template", "spec"},
+	"ReplicaSet":  {"spec", "template", "spec"},
+	"Job":         {"spec", "template", "spec"},
+	"CronJob":     {"spec", "jobTemplate", "spec", "template", "spec"},
+}
+
+// scanManifestYAML scans a YAML file line by line, then reads the
+// Kubernetes objects in it: ConfigMap data, Secret stringData and
+// base64-encoded data, and the container environment of workloads. Bucket
+// names are taken from values whose key or variable name mentions a
+// bucket, which the line scan misses when the name and value of an env
+// entry are on separate lines.
+func scanManifestYAML(filePath string) ([]Reference, error) {
+	refs, err := scanYAML(filePath)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+	return mergeObjectRefs(refs, kubernetesRefs(data, filePath)), nil
+}
+
+// kubernetesRefs finds the references in the Kubernetes objects of a YAML
+// stream. Parsing stops at the first document that is not valid YAML.
+func kubernetesRefs(data []byte, filePath string) []Reference {
+	if !bytes.Contains(data, []byte("kind:")) {
+		return nil
+	}
+	var refs []Reference
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	for {
+		var doc yaml.Node
+		if err := dec.Decode(&doc); err != nil {
+			break
+		}
+		if len(doc.Content) > 0 {
+			refs = append(refs, objectRefs(doc.Content[0], filePath)...)
+		}
+	}
+	return refs
+}
+
+// objectRefs finds the references in a Kubernetes object, or in the items
+// of a List. References get the "kubernetes" context and the object's kind
+// and name as their workflow.
+func objectRefs(obj *yaml.Node, filePath string) []Reference {
+	kind := yamlScalar(yamlLookup(obj, "kind"))
+	if kind == "" || yamlScalar(yamlLookup(obj, "apiVersion")) == "" {
+		return nil
+	}
+	if kind == "List" {
+		var refs []Reference
+		if items := yamlLookup(obj, "items"); items != nil && items.Kind == yaml.SequenceNode {
+			for _, item := range items.Content {
+				refs = append(refs, objectRefs(item, filePath)...)
+			}
+		}
+		return refs
+	}
+
+	object := kind
+	// Names built from a template are left out
+	if name := yamlScalar(yamlLookup(obj, "metadata", "name")); name != "" && !strings.ContainsAny(name, "${") {
+		object += "/" + name
+	}
+	base := Reference{File: filePath, Context: k8sContext, Workflow: object}
+
+	var refs []Reference
+	switch kind {
+	case "ConfigMap":
+		yamlEachPair(yamlLookup(obj, "data"), func(key string, value *yaml.Node) {
+			refs = append(refs, valueRefs(key, value, value.Value, base)...)
+		})
+	case "Secret":
+		yamlEachPair(yamlLookup(obj, "stringData"), func(key string, value *yaml.Node) {
+			refs = append(refs, valueRefs(key, value, value.Value, base)...)
+		})
+		yamlEachPair(yamlLookup(obj, "data"), func(key string, value *yaml.Node) {
+			decoded, err := base64.StdEncoding.DecodeString(strings.Join(strings.Fields(value.Value), ""))
+			if err != nil {
+				return
+			}
+			// Decoded lines are all reported at the encoded value's line
+			flat := *value
+			flat.Style = 0
+			refs = append(refs, valueRefs(key, &flat, string(decoded), base)...)
+		})
+	default:
+		path, ok := k8sPodSpecPaths[kind]
+		if !ok {
+			return nil
+		}
+		spec := yamlLookup(obj, path...)
+		for _, list := range []string{"initContainers", "containers"} {
+			containers := yamlLookup(spec, list)
+			if containers == nil || containers.Kind != yaml.SequenceNode {
+				continue
+			}
+			for _, container := range containers.Content {
+				env := yamlLookup(container, "env")
+				if env == nil || env.Kind != yaml.SequenceNode {
+					continue
+				}
+				for _, entry := range env.Content {
+					name := yamlScalar(yamlLookup(entry, "name"))
+					if value := yamlLookup(entry, "value"); value != nil && value.Kind == yaml.ScalarNode {
+						refs = append(refs, valueRefs(name, value, value.Value, base)...)
+					}
+				}
+			}
+		}
+	}
+	return refs
+}
+
+// valueRefs finds the references in a value set under key: S3 URLs, or
+// the value itself when it is a bucket name and the key mentions a bucket.
+// The lines of a block scalar are reported at their own lines.
+func valueRefs(key string, node *yaml.Node, value string, base Reference) []Reference {
+	first := node.Line
+	block := node.Style&(yaml.LiteralStyle|yaml.FoldedStyle) != 0
+	if block {
+		first++
+	}
+	var refs []Reference
+	for i, line := range strings.Split(value, "\n") {
+		ref := base
+		ref.Line = first
+		if block {
+			ref.Line += i
+		}
+		found := false
+		for _, m := range s3URLPattern.FindAllStringSubmatchIndex(line, -1) {
+			if !completeBucket(line, m[3]) {
+				continue
+			}
+			ref.Bucket = line[m[2]:m[3]]
+			ref.Prefix = ""
+			if m[4] >= 0 {
+				// $(VAR) references to other variables end the prefix
+				ref.Prefix = ciStaticPrefix(line[m[4]:m[5]])
+			}
+			refs = append(refs, ref)
+			found = true
+		}
+		name := strings.Trim(strings.TrimSpace(line), `"'`)
+		if !found && namesBucket(key) && bucketListNamePattern.MatchString(name) {
+			ref.Bucket = name
+			refs = append(refs, ref)
+		}
+	}
+	return refs
+}
+
+// mergeObjectRefs adds the references read from objects to those found
+// line by line, replacing line references to the same bucket on the same
+// line, and orders them by line
+func mergeObjectRefs(lineRefs, objRefs []Reference) []Reference {
+	if len(objRefs) == 0 {
+		return lineRefs
+	}
+	found := make(map[string]bool)
+	for _, ref := range objRefs {
+		found[strconv.Itoa(ref.Line)+"|"+ref.Bucket] = true
+	}
+	var refs []Reference
+	for _, ref := range lineRefs {
+		if !found[strconv.Itoa(ref.Line)+"|"+ref.Bucket] {
+			refs = append(refs, ref)
+		}
+	}
+	refs = append(refs, objRefs...)
+	sort.SliceStable(refs, func(i, j int) bool { return refs[i].Line < refs[j].Line })
+	return refs
+}
+
+// yamlLookup follows a path of mapping keys from node, returning nil when
+// a key is missing
+func yamlLookup(node *yaml.Node, path ...string) *yaml.Node {
+	for _, key := range path {
+		if node == nil || node.Kind != yaml.MappingNode {
+			return nil
+		}
+		var next *yaml.Node
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			if node.Content[i].Value == key {
+				next = node.Content[i+1]
+				break
+			}
+		}
+		node = next
+	}
+	return node
+}
+
+// yamlEachPair calls fn with each key of a mapping and its scalar value
+func yamlEachPair(node *yaml.Node, fn func(key string, value *yaml.Node)) {
+	if node == nil || node.Kind != yaml.MappingNode {
+		return
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if value := node.Content[i+1]; value.Kind == yaml.ScalarNode {
+			fn(node.Content[i].Value, value)
+		}
+	}
+}
+
+// yamlScalar returns a scalar node's value, or "" for other nodes
+func yamlScalar(node *yaml.Node) string {
+	if node == nil || node.Kind != yaml.ScalarNode {
+		return ""
+	}
+	return node.Value
+}
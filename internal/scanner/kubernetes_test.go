@@ -0,0 +1,127 @@
+package scanner
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestScanManifestYAML(t *testing.T) {
+	manifest := `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: app-config
+data:
+  EXPORT_BUCKET: config-exports
+  application.properties: |
+    greeting=hello
+    archive.url=s3://config-archive/2024/
+---
+apiVersion: v1
+kind: Secret
+metadata:
+  name: backup-creds
+stringData:
+  RAW_BUCKET: "secret-raw"
+data:
+  backup.env: QkFDS1VQX1VSTD1zMzovL3NlY3JldC1iYWNrdXBzL2RiLwo=
+---
+apiVersion: batch/v1
+kind: CronJob
+metadata:
+  name: nightly
+spec:
+  jobTemplate:
+    spec:
+      template:
+        spec:
+          containers:
+            - name: export
+              env:
+                - name: TARGET_BUCKET
+                  value: cron-target
+                - name: SOURCE
+                  value: s3://cron-source/$(DAY)/in/
+                - name: LOG_LEVEL
+                  value: debug
+                - name: BUCKET_REGION
+                  value: eu-central-1
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: api
+spec:
+  template:
+    spec:
+      initContainers:
+        - name: seed
+          env:
+            - name: SEED_BUCKET
+              value: deploy-seed
+`
+	path := filepath.Join(t.TempDir(), "app.yaml")
+	if err := os.WriteFile(path, []byte(manifest), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	refs, err := scanManifestYAML(path)
+	if err != nil {
+		t.Fatalf("scanManifestYAML failed: %v", err)
+	}
+	got := make(map[string]Reference)
+	for _, ref := range refs {
+		if _, seen := got[ref.Bucket]; seen {
+			t.Errorf("duplicate reference to %s", ref.Bucket)
+		}
+		got[ref.Bucket] = ref
+	}
+	want := map[string]struct {
+		line     int
+		prefix   string
+		workflow string
+	}{
+		"config-exports": {6, "", "ConfigMap/app-config"},
+		"config-archive": {9, "2024/", "ConfigMap/app-config"},
+		"secret-raw":     {16, "", "Secret/backup-creds"},
+		"secret-backups": {18, "db/", "Secret/backup-creds"},
+		"cron-target":    {33, "", "CronJob/nightly"},
+		"cron-source":    {35, "", "CronJob/nightly"},
+		"deploy-seed":    {52, "", "Deployment/api"},
+	}
+	for bucket, w := range want {
+		ref, ok := got[bucket]
+		if !ok {
+			t.Errorf("missing reference to %s in %v", bucket, refs)
+			continue
+		}
+		if ref.Line != w.line || ref.Prefix != w.prefix || ref.Context != k8sContext || ref.Workflow != w.workflow {
+			t.Errorf("%s: line %d, prefix %q, context %q, workflow %q; want %d, %q, kubernetes, %q",
+				bucket, ref.Line, ref.Prefix, ref.Context, ref.Workflow, w.line, w.prefix, w.workflow)
+		}
+	}
+	if _, ok := got["debug"]; ok {
+		t.Error("values of variables that do not name a bucket should be ignored")
+	}
+	if _, ok := got["eu-central-1"]; ok {
+		t.Error("values of variables that do not name a bucket should be ignored")
+	}
+	if len(got) != len(want) {
+		t.Errorf("got %d buckets, want %d: %v", len(got), len(want), refs)
+	}
+}
+
+func TestScanManifestYAML_NotKubernetes(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	content := "storage:\n  bucket: plain-config\n  kind: archive\n"
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	refs, err := scanManifestYAML(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(refs) != 1 || refs[0].Bucket != "plain-config" || refs[0].Context != "yaml" {
+		t.Errorf("want the line scan's reference only, got %+v", refs)
+	}
+}
@@ -0,0 +1,28 @@
+package scanner
+
+import "regexp"
+
+// outpostsARNPattern matches S3 on Outposts bucket ARNs, optionally followed
+// by an object key:
+// arn:aws:s3-outposts:us-west-2:123456789012:outpost/op-01ac5d28a6a232904/bucket/name
+var outpostsARNPattern = regexp.MustCompile(`(arn:aws[a-z-]*:s3-outposts:[a-z0-9-]+:\d{12}:outpost/op-[0-9a-f]+/bucket/([a-z0-9][a-z0-9\-\.]{1,61}[a-z0-9]))(?:/([^?\s"']+))?`)
+
+// outpostsRefs extracts Outposts bucket references from a line. An empty
+// context is detected from the line.
+func outpostsRefs(line, filePath string, lineNum int, context string) []Reference {
+	if context == "" {
+		context = detectContext(line)
+	}
+	var refs []Reference
+	for _, match := range outpostsARNPattern.FindAllStringSubmatch(line, -1) {
+		refs = append(refs, Reference{
+			Bucket:      match[2],
+			Prefix:      match[3],
+			File:        filePath,
+			Line:        lineNum,
+			Context:     context,
+			OutpostsARN: match[1],
+		})
+	}
+	return refs
+}
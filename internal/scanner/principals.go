@@ -0,0 +1,48 @@
+package scanner
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// iamRoleARNPattern matches IAM role ARNs written out in Terraform:
+// arn:aws:iam::123456789012:role/app-worker
+var iamRoleARNPattern = regexp.MustCompile(`arn:aws[a-z-]*:iam::\d{12}:role/[\w+=,.@/-]+`)
+
+// SetScanPrincipals makes Scan also collect IAM role ARNs from Terraform files
+func (s *RepoScanner) SetScanPrincipals(enabled bool) {
+	s.scanPrincipals = enabled
+}
+
+// Principals returns the IAM role ARNs found in Terraform by the last Scan
+func (s *RepoScanner) Principals() []string {
+	return s.principals
+}
+
+// isTerraform reports whether a file holds Terraform configuration or variables
+func isTerraform(filePath string) bool {
+	switch strings.ToLower(filepath.Ext(filePath)) {
+	case ".tf", ".hcl", ".tfvars":
+		return true
+	}
+	return false
+}
+
+// scanPrincipalFile extracts IAM role ARNs from a Terraform file
+func scanPrincipalFile(filePath string) ([]string, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = file.Close() }()
+
+	var arns []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		arns = append(arns, iamRoleARNPattern.FindAllString(scanner.Text(), -1)...)
+	}
+	return arns, scanner.Err()
+}
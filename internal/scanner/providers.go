@@ -0,0 +1,140 @@
+package scanner
+
+import (
+	"bufio"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Object stores other than S3 whose references are inventoried but not
+// checked. S3 references leave Provider empty.
+const (
+	ProviderGCS   = "gcs"
+	ProviderAzure = "azure"
+)
+
+var (
+	// Google Cloud Storage: gs:// URLs and the path-style and virtual-hosted
+	// XML API endpoints
+	gcsURLPattern  = regexp.MustCompile(`gs://([a-z0-9][a-z0-9_\-\.]{1,61}[a-z0-9])(?:/([^?\s"'<>]+))?`)
+	gcsPathPattern = regexp.MustCompile(`https?://storage\.(?:googleapis|cloud\.google)\.com/([a-z0-9][a-z0-9_\-\.]{1,61}[a-z0-9])(?:/([^?\s"'<>]+))?`)
+	gcsHostPattern = regexp.MustCompile(`https?://([a-z0-9][a-z0-9_\-\.]{1,61}[a-z0-9])\.storage\.googleapis\.com(?:/([^?\s"'<>]+))?`)
+
+	// Azure Blob Storage: blob and Data Lake endpoint URLs, and the
+	// abfs:// and wasb:// Hadoop schemes
+	azureHTTPPattern   = regexp.MustCompile(`https?://([a-z0-9]{3,24})\.(?:blob|dfs)\.core\.windows\.net/(\$?[a-z0-9][a-z0-9\-]{1,62})(?:/([^?\s"'<>]+))?`)
+	azureHadoopPattern = regexp.MustCompile(`(?:abfss?|wasbs?)://(\$?[a-z0-9][a-z0-9\-]{1,62})@([a-z0-9]{3,24})\.(?:blob|dfs)\.core\.windows\.net(?:/([^?\s"'<>]+))?`)
+
+	// gcsAPIPaths are path roots of storage.googleapis.com that are JSON
+	// API endpoints rather than buckets
+	gcsAPIPaths = map[string]bool{"storage": true, "upload": true, "batch": true, "download": true}
+)
+
+// URI renders the reference as a URL of its provider. Azure references hold
+// account/container as their bucket.
+func (r Reference) URI() string {
+	var uri string
+	switch r.Provider {
+	case ProviderGCS:
+		uri = "gs://" + r.Bucket
+	case ProviderAzure:
+		account, container, _ := strings.Cut(r.Bucket, "/")
+		uri = "https://" + account + ".blob.core.windows.net/" + container
+	default:
+		uri = "s3://" + r.Bucket
+	}
+	if r.Prefix != "" {
+		uri += "/" + r.Prefix
+	}
+	return uri
+}
+
+// providerRefs finds Google Cloud Storage and Azure Blob Storage references
+// on a line
+func providerRefs(line, filePath string, lineNum int) []Reference {
+	var refs []Reference
+	add := func(provider, bucket, prefix string) {
+		refs = append(refs, Reference{
+			Provider: provider,
+			Bucket:   bucket,
+			Prefix:   prefix,
+			File:     filePath,
+			Line:     lineNum,
+			Context:  detectContext(line),
+		})
+	}
+	for _, m := range gcsURLPattern.FindAllStringSubmatch(line, -1) {
+		add(ProviderGCS, m[1], m[2])
+	}
+	for _, m := range gcsPathPattern.FindAllStringSubmatch(line, -1) {
+		if !gcsAPIPaths[m[1]] {
+			add(ProviderGCS, m[1], m[2])
+		}
+	}
+	for _, m := range gcsHostPattern.FindAllStringSubmatch(line, -1) {
+		add(ProviderGCS, m[1], m[2])
+	}
+	for _, m := range azureHTTPPattern.FindAllStringSubmatch(line, -1) {
+		add(ProviderAzure, m[1]+"/"+m[2], m[3])
+	}
+	for _, m := range azureHadoopPattern.FindAllStringSubmatch(line, -1) {
+		add(ProviderAzure, m[2]+"/"+m[1], m[3])
+	}
+	return refs
+}
+
+// scanProviderFile finds the references to other object stores in a file
+func scanProviderFile(filePath string) ([]Reference, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = file.Close() }()
+
+	var refs []Reference
+	scanner := bufio.NewScanner(file)
+	lineNum := 0
+	for scanner.Scan() {
+		lineNum++
+		line := scanner.Text()
+		// Skip the regexes on lines that cannot match any of them
+		if !strings.Contains(line, "gs://") && !strings.Contains(line, "storage.") && !strings.Contains(line, ".core.windows.net") {
+			continue
+		}
+		refs = append(refs, providerRefs(line, filePath, lineNum)...)
+	}
+	return refs, scanner.Err()
+}
+
+// SplitProviders separates S3 references from references to other object
+// stores, such as those read from --refs-stdin
+func SplitProviders(refs []Reference) (s3Refs, others []Reference) {
+	for _, ref := range refs {
+		if ref.Provider == "" {
+			s3Refs = append(s3Refs, ref)
+		} else {
+			others = append(others, ref)
+		}
+	}
+	return s3Refs, others
+}
+
+// UnsupportedRefs returns the references to object stores other than S3
+// found by the last Scan, sorted by provider, file and line. They are not
+// among the references Scan returns.
+func (s *RepoScanner) UnsupportedRefs() []Reference {
+	refs := append([]Reference(nil), s.unsupported...)
+	sort.SliceStable(refs, func(i, j int) bool {
+		a, b := refs[i], refs[j]
+		if a.Provider != b.Provider {
+			return a.Provider < b.Provider
+		}
+		if a.File != b.File {
+			return a.File < b.File
+		}
+		return a.Line < b.Line
+	})
+	return refs
+}
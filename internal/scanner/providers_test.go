@@ -0,0 +1,76 @@
+package scanner
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestProviderRefs(t *testing.T) {
+	tests := []struct {
+		line string
+		want []string
+	}{
+		{`export_uri = "gs://analytics-exports/daily/report.csv"`, []string{"gs://analytics-exports/daily/report.csv"}},
+		{`url = "https://storage.googleapis.com/public_assets/logo.png"`, []string{"gs://public_assets/logo.png"}},
+		{`url = "https://media-bucket.storage.googleapis.com/img"`, []string{"gs://media-bucket/img"}},
+		{`POST https://storage.googleapis.com/upload/storage/v1/b/x/o`, nil},
+		{`sas = "https://acmedata.blob.core.windows.net/backups/2024/db.bak?sv=2022"`, []string{"https://acmedata.blob.core.windows.net/backups/2024/db.bak"}},
+		{`path: abfss://raw@acmelake.dfs.core.windows.net/events/`, []string{"https://acmelake.blob.core.windows.net/raw/events/"}},
+		{`bucket: s3://plain-s3-bucket/data`, nil},
+	}
+	for _, tt := range tests {
+		var got []string
+		for _, ref := range providerRefs(tt.line, "f", 1) {
+			got = append(got, ref.URI())
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("providerRefs(%q) = %v, want %v", tt.line, got, tt.want)
+		}
+	}
+}
+
+func TestRepoScanner_UnsupportedRefs(t *testing.T) {
+	tmpDir := t.TempDir()
+	content := `bucket: s3://app-data/uploads
+mirror: gs://app-data-mirror/uploads
+archive: https://acmedata.blob.core.windows.net/archive
+again: gs://app-data-mirror/uploads
+`
+	if err := os.WriteFile(filepath.Join(tmpDir, "config.yaml"), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	s := NewRepoScanner(tmpDir)
+	refs, err := s.Scan(context.Background())
+	if err != nil {
+		t.Fatalf("Scan failed: %v", err)
+	}
+	for _, ref := range refs {
+		if ref.Provider != "" || strings.Contains(ref.Bucket, "mirror") {
+			t.Errorf("Scan returned a reference to another provider: %+v", ref)
+		}
+	}
+
+	others := s.UnsupportedRefs()
+	if len(others) != 2 {
+		t.Fatalf("UnsupportedRefs = %+v, want 2 deduplicated references", others)
+	}
+	if others[0].Provider != ProviderAzure || others[0].Bucket != "acmedata/archive" || others[0].Line != 3 {
+		t.Errorf("first reference = %+v, want the Azure container", others[0])
+	}
+	if others[1].Provider != ProviderGCS || others[1].Bucket != "app-data-mirror" || others[1].Prefix != "uploads" {
+		t.Errorf("second reference = %+v, want the GCS bucket", others[1])
+	}
+}
+
+func TestSplitProviders(t *testing.T) {
+	refs := []Reference{{Bucket: "a"}, {Bucket: "b", Provider: ProviderGCS}, {Bucket: "c"}}
+	s3Refs, others := SplitProviders(refs)
+	if len(s3Refs) != 2 || len(others) != 1 || others[0].Bucket != "b" {
+		t.Errorf("SplitProviders = %v, %v", s3Refs, others)
+	}
+}
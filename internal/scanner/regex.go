@@ -7,19 +7,31 @@ import (
 )
 
 var (
-	// S3 URL patterns
-	s3URLPattern  = regexp.MustCompile(`s3://([a-z0-9][a-z0-9\-\.]{1,61}[a-z0-9])(?:/([^?\s"']+))?(?:\?versionId=([^\s"']+))?`)
+	// S3 URL patterns. s3a:// and s3n:// are the Hadoop filesystem schemes
+	// Spark, Hadoop and Flink use for the same buckets.
+	s3URLPattern  = regexp.MustCompile(`s3[an]?://([a-z0-9][a-z0-9\-\.]{1,61}[a-z0-9])(?:/([^?\s"']+))?(?:\?versionId=([^\s"']+))?`)
 	s3HTTPPattern = regexp.MustCompile(`https?://([a-z0-9][a-z0-9\-\.]{1,61}[a-z0-9])\.s3(?:[.-]([a-z0-9-]+))?\.amazonaws\.com(?:/([^?\s"']+))?(?:\?versionId=([^\s"']+))?`)
 
 	// Bucket name pattern (for env vars and config)
 	bucketNamePattern = regexp.MustCompile(`(?i)(?:bucket|s3[-_]?bucket|s3[-_]?name)[\s:=]+['"]?([a-z0-9][a-z0-9\-\.]{1,61}[a-z0-9])['"]?`)
 
+	// bucketKeySuffixPattern matches the end of a key that names buckets
+	bucketKeySuffixPattern = regexp.MustCompile(`(?i)buckets?([_-]?names?)?$`)
+
 	// Context detection patterns
 	writeOpPattern = regexp.MustCompile(`(?i)(put|write|upload|store|save|create)`)
 	readOpPattern  = regexp.MustCompile(`(?i)(get|read|download|fetch|retrieve|load)`)
 	listOpPattern  = regexp.MustCompile(`(?i)(list|ls|scan|iterate)`)
 )
 
+// namesBucket reports whether a config key, element or attribute names a
+// bucket: it ends in bucket, bucket_name, bucket-name or bucketName (or
+// their plurals), as in s3.bucket, LOG_BUCKET or s3BucketName, but not
+// bucket_region or bucketPolicy
+func namesBucket(key string) bool {
+	return bucketKeySuffixPattern.MatchString(key)
+}
+
 // scanCode scans source code files using regex patterns
 func scanCode(filePath string) ([]Reference, error) {
 	file, err := os.Open(filePath)
@@ -36,7 +48,10 @@ func scanCode(filePath string) ([]Reference, error) {
 		lineNum++
 		line := scanner.Text()
 
-		// Check for s3:// URLs
+		// Check for S3 on Outposts bucket ARNs
+		refs = append(refs, outpostsRefs(line, filePath, lineNum, "")...)
+
+		// Check for s3://, s3a:// and s3n:// URLs
 		if matches := s3URLPattern.FindAllStringSubmatch(line, -1); matches != nil {
 			for _, match := range matches {
 				refs = append(refs, Reference{
@@ -2,14 +2,71 @@ package scanner
 
 import (
 	"context"
+	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 )
 
+const (
+	// maxFileSize is the largest file scanned line by line
+	maxFileSize = 10 * 1024 * 1024
+
+	// maxChunkedFileSize caps large JSON/YAML exports read through the chunked path
+	maxChunkedFileSize = 1024 * 1024 * 1024
+)
+
+// Dedupe modes for repeated references
+const (
+	DedupeNone           = "none"
+	DedupeByBucket       = "by-bucket"
+	DedupeByBucketPrefix = "by-bucket-prefix"
+)
+
+// Reasons a file was skipped
+const (
+	// SkipTooLarge is a file over the size limit for its type
+	SkipTooLarge = "too_large"
+	// SkipUnknownExtension is a file no scanner parses
+	SkipUnknownExtension = "unknown_extension"
+	// SkipUnreadable is a file that could not be opened or read
+	SkipUnreadable = "unreadable"
+	// SkipEncrypted is a sops or sealed-secrets file whose encrypted values
+	// were not read; its plaintext parts are still scanned
+	SkipEncrypted = "encrypted"
+	// SkipDecryptFailed is a sops file that --decrypt-sops could not decrypt
+	SkipDecryptFailed = "decrypt_failed"
+)
+
+// SkippedFile is a file Scan did not read, with the reason
+type SkippedFile struct {
+	Path   string `json:"path"`
+	Reason string `json:"reason"`
+}
+
 // RepoScanner scans a repository for S3 references
 type RepoScanner struct {
-	repoPath string
+	repoPath       string
+	scanVaults     bool
+	vaults         []VaultReference
+	unsupported    []Reference
+	scanPrincipals bool
+	scanArchives   bool
+	decryptSOPS    bool
+	principals     []string
+	filesScanned   int
+	skipped        []SkippedFile
+	dedupe         string
+	tfStateFiles   []string
+	extraFiles     []string
+	changes        Changes
+	// dbtDirs caches whether a directory is inside a dbt project
+	dbtDirs map[string]bool
+	// helmDirs caches the Helm chart directory each directory is in, and
+	// helmCharts the names and values of charts
+	helmDirs   map[string]string
+	helmCharts map[string]*helmChart
 }
 
 // NewRepoScanner creates a new repository scanner
@@ -19,78 +76,410 @@ func NewRepoScanner(repoPath string) *RepoScanner {
 	}
 }
 
-// Scan scans the repository and returns all S3 references found
+// Scan scans the repository and returns all S3 references found. It stops
+// with the context's error when ctx is done.
 func (s *RepoScanner) Scan(ctx context.Context) ([]Reference, error) {
 	var allRefs []Reference
 	bucketsSeen := make(map[string]bool) // Deduplicate buckets
-
-	// Walk through repository
-	err := filepath.Walk(s.repoPath, func(path string, info os.FileInfo, err error) error {
-		if err != nil {
-			return err
+	vaultsSeen := make(map[string]bool)
+	principalsSeen := make(map[string]bool)
+	s.vaults = nil
+	s.unsupported = nil
+	s.principals = nil
+	s.filesScanned = 0
+	s.skipped = nil
+	dedupe := func(refs []Reference, kept *[]Reference) {
+		for _, ref := range refs {
+			key := ref.Bucket + "|" + ref.Prefix
+			switch s.dedupe {
+			case DedupeNone:
+				key = fmt.Sprintf("%s|%s|%d|%s", ref.File, ref.Bucket, ref.Line, ref.Prefix)
+			case DedupeByBucket:
+				key = ref.Bucket
+				ref.Prefix = ""
+			}
+			key = ref.Provider + "|" + key
+			if !bucketsSeen[key] {
+				*kept = append(*kept, ref)
+				bucketsSeen[key] = true
+			}
 		}
+	}
+	addRefs := func(refs []Reference) {
+		dedupe(refs, &allRefs)
+	}
 
-		// Skip directories and hidden files
-		if info.IsDir() {
-			if strings.HasPrefix(info.Name(), ".") && info.Name() != "." {
-				return filepath.SkipDir
-			}
+	// visit scans one file. With changes set, only the references on lines
+	// the change added are kept.
+	visit := func(path string, info os.FileInfo) error {
+		// Skip hidden files, and files under .github other than workflows
+		if (strings.HasPrefix(info.Name(), ".") || s.inGitHubDir(path)) && !isCIConfig(path) {
 			return nil
 		}
 
-		// Skip hidden files
-		if strings.HasPrefix(info.Name(), ".") {
+		// Scan file based on extension. Large JSON/YAML exports are read in
+		// chunks; other large files are skipped.
+		var refs []Reference
+		var err error
+		if s.scanArchives && isArchive(path) {
+			if info.Size() > maxArchiveFileSize {
+				s.skip(path, SkipTooLarge)
+				return nil
+			}
+			// Archives are binary, so a changed archive keeps all its
+			// references
+			refs, err := s.scanArchive(path)
+			if err != nil {
+				s.skip(path, SkipUnreadable)
+				return nil
+			}
+			s.filesScanned++
+			addRefs(refs)
 			return nil
 		}
 
-		// Skip binary files and large files
-		if info.Size() > 10*1024*1024 { // Skip files > 10MB
+		// .tfvars files are read only for principals
+		if !isScannable(path) && !(s.scanPrincipals && isTerraform(path)) {
+			s.skip(path, SkipUnknownExtension)
 			return nil
 		}
-
-		// Scan file based on extension
-		refs, err := s.scanFile(path)
+		if info.Size() > maxFileSize {
+			if !isChunkable(path) || info.Size() > maxChunkedFileSize {
+				s.skip(path, SkipTooLarge)
+				return nil
+			}
+			refs, err = scanLargeFile(path)
+		} else {
+			refs, err = s.scanFile(path)
+		}
+		if err == nil {
+			refs = s.scanSecrets(ctx, path, info.Size(), refs)
+		}
 		if err != nil {
-			// Log error but continue
+			// Record the file and continue
+			s.skip(path, SkipUnreadable)
 			return nil
 		}
+		if isScannable(path) {
+			s.filesScanned++
+		}
 
-		for _, ref := range refs {
-			key := ref.Bucket + "|" + ref.Prefix
-			if !bucketsSeen[key] {
-				allRefs = append(allRefs, ref)
-				bucketsSeen[key] = true
+		if s.changes != nil {
+			refs = s.addedRefs(path, refs)
+		}
+		addRefs(refs)
+
+		// References to other object stores are kept apart, so they are
+		// inventoried without being checked against S3
+		if info.Size() <= maxFileSize && isScannable(path) {
+			if others, err := scanProviderFile(path); err == nil {
+				if s.changes != nil {
+					others = s.addedRefs(path, others)
+				}
+				dedupe(others, &s.unsupported)
+			}
+		}
+
+		if s.scanVaults && info.Size() <= maxFileSize && isScannable(path) {
+			vaults, err := scanVaultFile(path)
+			if err != nil {
+				return nil
+			}
+			for _, ref := range vaults {
+				key := ref.Region + "|" + ref.Vault
+				if !vaultsSeen[key] {
+					s.vaults = append(s.vaults, ref)
+					vaultsSeen[key] = true
+				}
+			}
+		}
+
+		if s.scanPrincipals && info.Size() <= maxFileSize && isTerraform(path) {
+			arns, err := scanPrincipalFile(path)
+			if err != nil {
+				return nil
+			}
+			for _, arn := range arns {
+				if !principalsSeen[arn] {
+					s.principals = append(s.principals, arn)
+					principalsSeen[arn] = true
+				}
 			}
 		}
 
 		return nil
-	})
+	}
+
+	var err error
+	if s.changes != nil {
+		err = s.visitChanged(ctx, visit)
+	} else {
+		// Walk through repository
+		err = filepath.Walk(s.repoPath, func(path string, info os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+
+			// Skip directories and hidden files. .github is entered for
+			// its workflows.
+			if info.IsDir() {
+				if strings.HasPrefix(info.Name(), ".") && info.Name() != "." && info.Name() != ".github" {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			return visit(path, info)
+		})
+	}
 
 	if err != nil {
 		return nil, err
 	}
 
+	// State files named with --tf-state may live outside the repository
+	for _, path := range s.tfStateFiles {
+		refs, err := scanTFState(path)
+		if err != nil {
+			return nil, err
+		}
+		s.filesScanned++
+		addRefs(refs)
+	}
+	// Database dumps and table exports named with --extra-file
+	for _, path := range s.extraFiles {
+		refs, err := scanExport(path)
+		if err != nil {
+			return nil, err
+		}
+		s.filesScanned++
+		addRefs(refs)
+	}
+
 	return allRefs, nil
 }
 
-// scanFile scans a single file for S3 references
+// SetDedupe sets how Scan collapses repeated references: DedupeNone keeps
+// every place a bucket or prefix is referenced, DedupeByBucket the first
+// reference of each bucket without its prefix, and DedupeByBucketPrefix,
+// the default, the first reference of each bucket and prefix
+func (s *RepoScanner) SetDedupe(mode string) {
+	s.dedupe = mode
+}
+
+// ValidateDedupe checks a dedupe mode; empty means DedupeByBucketPrefix
+func ValidateDedupe(mode string) error {
+	switch mode {
+	case "", DedupeNone, DedupeByBucket, DedupeByBucketPrefix:
+		return nil
+	}
+	return fmt.Errorf("invalid dedupe mode %q: must be %s, %s or %s", mode, DedupeNone, DedupeByBucket, DedupeByBucketPrefix)
+}
+
+// SetTFStateFiles adds Terraform state files to scan, such as remote state
+// saved with terraform state pull
+func (s *RepoScanner) SetTFStateFiles(paths []string) {
+	s.tfStateFiles = paths
+}
+
+// SetScanArchives makes Scan read the scannable files inside .zip and
+// .tar.gz archives, such as packaged Lambda bundles
+func (s *RepoScanner) SetScanArchives(enabled bool) {
+	s.scanArchives = enabled
+}
+
+// SetExtraFiles adds SQL dumps and CSV or TSV table exports to scan, for
+// buckets an application keeps in its database rather than its code
+func (s *RepoScanner) SetExtraFiles(paths []string) {
+	s.extraFiles = paths
+}
+
+// SetChanges limits Scan to the changed files and to the references on the
+// lines the change added. Files in hidden directories are still skipped.
+func (s *RepoScanner) SetChanges(changes Changes) {
+	s.changes = changes
+}
+
+// visitChanged visits the changed files that still exist, in path order
+func (s *RepoScanner) visitChanged(ctx context.Context, visit func(string, os.FileInfo) error) error {
+	files := make([]string, 0, len(s.changes))
+	for file := range s.changes {
+		files = append(files, file)
+	}
+	sort.Strings(files)
+	for _, file := range files {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if hiddenDir(file) && !isCIConfig(file) {
+			continue
+		}
+		path := filepath.Join(s.repoPath, filepath.FromSlash(file))
+		info, err := os.Stat(path)
+		if err != nil || info.IsDir() {
+			continue
+		}
+		if err := visit(path, info); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// addedRefs keeps the references on lines the change added to path.
+// References without a line number cannot be placed and are kept.
+func (s *RepoScanner) addedRefs(path string, refs []Reference) []Reference {
+	rel, err := filepath.Rel(s.repoPath, path)
+	if err != nil {
+		return refs
+	}
+	rel = filepath.ToSlash(rel)
+	kept := refs[:0]
+	for _, ref := range refs {
+		if ref.Line == 0 || s.changes.Added(rel, ref.Line) {
+			kept = append(kept, ref)
+		}
+	}
+	return kept
+}
+
+// inGitHubDir reports whether path is under the repository's .github
+// directory
+func (s *RepoScanner) inGitHubDir(path string) bool {
+	rel, err := filepath.Rel(s.repoPath, path)
+	if err != nil {
+		return false
+	}
+	return strings.HasPrefix(filepath.ToSlash(rel), ".github/")
+}
+
+// hiddenDir reports whether a slash-separated path lies in a directory
+// whose name starts with a dot
+func hiddenDir(file string) bool {
+	dirs := strings.Split(file, "/")
+	for _, dir := range dirs[:len(dirs)-1] {
+		if strings.HasPrefix(dir, ".") && dir != "." && dir != ".." {
+			return true
+		}
+	}
+	return false
+}
+
+// FilesScanned returns how many files of a supported type the last Scan read
+func (s *RepoScanner) FilesScanned() int {
+	return s.filesScanned
+}
+
+// SkippedFiles returns the files the last Scan skipped, in walk order.
+// Hidden files and directories are excluded by design and not listed.
+func (s *RepoScanner) SkippedFiles() []SkippedFile {
+	return s.skipped
+}
+
+// CountSkipped tallies skipped files by reason
+func CountSkipped(files []SkippedFile) map[string]int {
+	if len(files) == 0 {
+		return nil
+	}
+	counts := make(map[string]int)
+	for _, f := range files {
+		counts[f.Reason]++
+	}
+	return counts
+}
+
+// skip records a file Scan did not read
+func (s *RepoScanner) skip(path, reason string) {
+	s.skipped = append(s.skipped, SkippedFile{Path: path, Reason: reason})
+}
+
+// fileScanner reads the references of one kind of file from readPath.
+// filePath is the file being scanned and decides its context, such as the
+// Helm chart or dbt project it belongs to; readPath is filePath itself or a
+// copy of its content, such as a decrypted sops file.
+type fileScanner func(s *RepoScanner, filePath, readPath string) ([]Reference, error)
+
+// plainScanner adapts a scanner that needs no repository context
+func plainScanner(scan func(string) ([]Reference, error)) fileScanner {
+	return func(_ *RepoScanner, _, readPath string) ([]Reference, error) {
+		return scan(readPath)
+	}
+}
+
+// extensionScanners maps lower-case file extensions to their scanner. It is
+// the only list of scannable extensions: scanFile and isScannable both read
+// it. Terraform state backups (.tfstate.backup) hold superseded state and
+// are left out.
+var extensionScanners = map[string]fileScanner{
+	".tf":      plainScanner(scanTerraform),
+	".hcl":     plainScanner(scanTerraform),
+	".tfstate": plainScanner(scanTFState),
+	".yaml":    (*RepoScanner).scanYAML,
+	".yml":     (*RepoScanner).scanYAML,
+	".json":    plainScanner(scanJSON),
+	// Java and Python application configuration
+	".properties": plainScanner(scanConfig),
+	".ini":        plainScanner(scanConfig),
+	".toml":       plainScanner(scanConfig),
+	".cfg":        plainScanner(scanConfig),
+	".xml":        plainScanner(scanXML),
+	// .env, and prefixed files such as prod.env
+	".env":   plainScanner(scanEnv),
+	".py":    plainScanner(scanPython),
+	".sql":   plainScanner(scanSQL),
+	".sh":    plainScanner(scanShell),
+	".bash":  plainScanner(scanShell),
+	".js":    plainScanner(scanCode),
+	".ts":    plainScanner(scanCode),
+	".go":    plainScanner(scanCode),
+	".java":  plainScanner(scanCode),
+	".scala": plainScanner(scanCode),
+	".conf":  plainScanner(scanCode),
+}
+
+// isScannable reports whether scanFile has a parser for the file
+func isScannable(filePath string) bool {
+	_, ok := extensionScanners[strings.ToLower(filepath.Ext(filePath))]
+	return ok || isCIConfig(filePath)
+}
+
+// scanFile scans a single file for S3 references. CI configuration is
+// recognized by its path before the extension is looked up, since workflows
+// are YAML and Jenkinsfiles have no extension.
 func (s *RepoScanner) scanFile(filePath string) ([]Reference, error) {
-	ext := strings.ToLower(filepath.Ext(filePath))
-	basename := strings.ToLower(filepath.Base(filePath))
-
-	// Choose scanner based on file type
-	switch {
-	case ext == ".tf" || ext == ".hcl":
-		return scanTerraform(filePath)
-	case ext == ".yaml" || ext == ".yml":
-		return scanYAML(filePath)
-	case ext == ".json":
-		return scanJSON(filePath)
-	case basename == ".env" || strings.HasSuffix(basename, ".env"):
-		return scanEnv(filePath)
-	case ext == ".py" || ext == ".js" || ext == ".ts" || ext == ".go" || ext == ".java" || ext == ".sh":
-		return scanCode(filePath)
-	default:
-		return nil, nil
+	return s.scanFileAs(filePath, filePath)
+}
+
+// scanFileAs scans the content at readPath as the file at filePath: the
+// scanner and the file's context are chosen by filePath, and references
+// name filePath
+func (s *RepoScanner) scanFileAs(filePath, readPath string) ([]Reference, error) {
+	var refs []Reference
+	var err error
+	if isCIConfig(filePath) {
+		refs, err = scanCI(filePath, readPath)
+	} else if scan, ok := extensionScanners[strings.ToLower(filepath.Ext(filePath))]; ok {
+		refs, err = scan(s, filePath, readPath)
+	}
+	if err != nil {
+		return nil, err
+	}
+	for k := range refs {
+		refs[k].File = filePath
+	}
+	return refs, nil
+}
+
+// scanYAML reads dbt project files, Helm chart files, and other YAML as
+// Kubernetes manifests
+func (s *RepoScanner) scanYAML(filePath, readPath string) ([]Reference, error) {
+	if isDBTProjectFile(filePath) || s.inDBTProject(filePath) {
+		return scanDBTYAML(readPath)
+	}
+	if dir := s.chartDir(filePath); dir != "" {
+		return s.scanHelm(filePath, readPath, dir)
 	}
+	return scanManifestYAML(readPath)
 }
@@ -4,6 +4,8 @@ import (
 	"context"
 	"os"
 	"path/filepath"
+	"reflect"
+	"strings"
 	"testing"
 )
 
@@ -62,6 +64,103 @@ resource "aws_s3_bucket" "data" {
 			t.Errorf("Expected to find bucket %s, but it was not found", expected)
 		}
 	}
+
+	if got := scanner.FilesScanned(); got != 3 {
+		t.Errorf("Expected 3 files scanned, got %d", got)
+	}
+}
+
+func TestRepoScanner_Dedupe(t *testing.T) {
+	tmpDir := t.TempDir()
+	content := `LOGS = "s3://app-logs/raw/"
+ARCHIVE = "s3://app-logs/archive/"
+AGAIN = "s3://app-logs/raw/"
+`
+	if err := os.WriteFile(filepath.Join(tmpDir, "paths.py"), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		mode string
+		want []string
+	}{
+		{"", []string{"raw/", "archive/"}},
+		{DedupeByBucketPrefix, []string{"raw/", "archive/"}},
+		{DedupeByBucket, []string{""}},
+		{DedupeNone, []string{"raw/", "archive/", "raw/"}},
+	}
+	for _, tt := range tests {
+		s := NewRepoScanner(tmpDir)
+		s.SetDedupe(tt.mode)
+		refs, err := s.Scan(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+		var prefixes []string
+		for _, ref := range refs {
+			prefixes = append(prefixes, ref.Prefix)
+		}
+		if strings.Join(prefixes, ",") != strings.Join(tt.want, ",") {
+			t.Errorf("dedupe %q: got prefixes %q, want %q", tt.mode, prefixes, tt.want)
+		}
+	}
+
+	if err := ValidateDedupe("by-file"); err == nil {
+		t.Error("expected error for an unknown dedupe mode")
+	}
+}
+
+func TestRepoScanner_SkippedFiles(t *testing.T) {
+	tmpDir := t.TempDir()
+	files := map[string][]byte{
+		"app.py":    []byte(`BUCKET = "s3://app-data/"`),
+		"README.md": []byte("# app"),
+		"huge.py":   make([]byte, maxFileSize+1),
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(tmpDir, name), content, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.Symlink(filepath.Join(tmpDir, "missing.py"), filepath.Join(tmpDir, "dangling.py")); err != nil {
+		t.Skipf("symlinks unavailable: %v", err)
+	}
+
+	s := NewRepoScanner(tmpDir)
+	if _, err := s.Scan(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if s.FilesScanned() != 1 {
+		t.Errorf("FilesScanned() = %d, want 1", s.FilesScanned())
+	}
+	want := []SkippedFile{
+		{Path: filepath.Join(tmpDir, "README.md"), Reason: SkipUnknownExtension},
+		{Path: filepath.Join(tmpDir, "dangling.py"), Reason: SkipUnreadable},
+		{Path: filepath.Join(tmpDir, "huge.py"), Reason: SkipTooLarge},
+	}
+	if got := s.SkippedFiles(); !reflect.DeepEqual(got, want) {
+		t.Errorf("SkippedFiles() = %+v, want %+v", got, want)
+	}
+	counts := CountSkipped(s.SkippedFiles())
+	if counts[SkipTooLarge] != 1 || counts[SkipUnknownExtension] != 1 || counts[SkipUnreadable] != 1 {
+		t.Errorf("CountSkipped() = %v", counts)
+	}
+	if CountSkipped(nil) != nil {
+		t.Error("CountSkipped(nil) should be nil so reports omit it")
+	}
+}
+
+func TestRepoScanner_Cancelled(t *testing.T) {
+	tmpDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(tmpDir, "app.py"), []byte(`BUCKET = "my-bucket"`), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := NewRepoScanner(tmpDir).Scan(ctx); err != context.Canceled {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
 }
 
 func TestScanYAML(t *testing.T) {
@@ -261,6 +360,44 @@ func TestScanCode_DeduplicatesBucketName(t *testing.T) {
 	}
 }
 
+func TestScanCode_HadoopSchemes(t *testing.T) {
+	tmpDir := t.TempDir()
+	files := map[string]string{
+		"Job.scala":           `val events = spark.read.parquet("s3a://spark-events/2024/")`,
+		"spark-defaults.conf": "spark.eventLog.dir s3n://spark-history/logs\n",
+		"flink-conf.yaml":     "state.checkpoints.dir: s3a://flink-state/checkpoints\n",
+	}
+	scanner := NewRepoScanner(tmpDir)
+	got := make(map[string]string)
+	for name, content := range files {
+		path := filepath.Join(tmpDir, name)
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to create test file: %v", err)
+		}
+		refs, err := scanner.scanFile(path)
+		if err != nil {
+			t.Fatalf("scanFile(%s) failed: %v", name, err)
+		}
+		for _, ref := range refs {
+			got[ref.Bucket] = ref.Prefix
+		}
+	}
+	want := map[string]string{"spark-events": "2024/", "spark-history": "logs", "flink-state": "checkpoints"}
+	for bucket, prefix := range want {
+		if p, ok := got[bucket]; !ok || p != prefix {
+			t.Errorf("bucket %s: got prefix %q (found %v), want %q", bucket, p, ok, prefix)
+		}
+	}
+	if len(got) != len(want) {
+		t.Errorf("got references %v, want %v", got, want)
+	}
+	for name := range files {
+		if !isScannable(name) {
+			t.Errorf("%s should count as a scanned file", name)
+		}
+	}
+}
+
 func TestScanTerraform_ObjectResource(t *testing.T) {
 	tmpDir := t.TempDir()
 	tfFile := filepath.Join(tmpDir, "object.tf")
@@ -351,3 +488,192 @@ func TestScanFile_RoutesByExtension(t *testing.T) {
 		t.Fatalf("Expected no references for unknown extension, got %d", len(refs))
 	}
 }
+
+func TestScanChunked_BoundaryAndLines(t *testing.T) {
+	content := strings.Repeat("x", 50) + `"s3://boundary-bucket/data/part"` + "\n" +
+		strings.Repeat("y", 70) + "\n" +
+		`{"note": "bucket: named-bucket"}` + "\n" +
+		strings.Repeat("z", 40) + `https://http-bucket.s3.amazonaws.com/key` + "\n"
+
+	// Chunk size smaller than a single reference forces matches across windows
+	refs, err := scanChunked(strings.NewReader(content), "dump.json", "json", jsonChunkPatterns, 32, 64)
+	if err != nil {
+		t.Fatalf("scanChunked failed: %v", err)
+	}
+
+	want := map[string]Reference{
+		"boundary-bucket": {Prefix: "data/part", Line: 1},
+		"named-bucket":    {Line: 3},
+		"http-bucket":     {Prefix: "key", Line: 4},
+	}
+	if len(refs) != len(want) {
+		t.Fatalf("expected %d references, got %d: %+v", len(want), len(refs), refs)
+	}
+	for _, ref := range refs {
+		expected, ok := want[ref.Bucket]
+		if !ok {
+			t.Fatalf("unexpected bucket %q", ref.Bucket)
+		}
+		if ref.Prefix != expected.Prefix {
+			t.Errorf("bucket %s: expected prefix %q, got %q", ref.Bucket, expected.Prefix, ref.Prefix)
+		}
+		if ref.Line != expected.Line {
+			t.Errorf("bucket %s: expected line %d, got %d", ref.Bucket, expected.Line, ref.Line)
+		}
+		if ref.Context != "json" {
+			t.Errorf("bucket %s: expected context json, got %q", ref.Bucket, ref.Context)
+		}
+	}
+}
+
+func TestScanChunked_SingleLineExport(t *testing.T) {
+	var b strings.Builder
+	b.WriteString(`{"configurationItems":[`)
+	for i := 0; i < 200; i++ {
+		if i > 0 {
+			b.WriteString(",")
+		}
+		b.WriteString(`{"resourceType":"AWS::S3::Bucket","url":"s3://export-bucket-` + strings.Repeat("a", i%5+1) + `/logs"}`)
+	}
+	b.WriteString("]}")
+
+	refs, err := scanChunked(strings.NewReader(b.String()), "snapshot.json", "json", jsonChunkPatterns, 100, 128)
+	if err != nil {
+		t.Fatalf("scanChunked failed: %v", err)
+	}
+	if len(refs) != 200 {
+		t.Fatalf("expected 200 references, got %d", len(refs))
+	}
+	for _, ref := range refs {
+		if ref.Line != 1 {
+			t.Fatalf("expected all references on line 1, got %d", ref.Line)
+		}
+		if ref.Prefix != "logs" {
+			t.Fatalf("expected prefix logs, got %q", ref.Prefix)
+		}
+	}
+}
+
+func TestIsChunkable(t *testing.T) {
+	cases := map[string]bool{
+		"export.json":  true,
+		"values.YAML":  true,
+		"stack.yml":    true,
+		"bundle.js":    false,
+		"terraform.tf": false,
+	}
+	for name, expected := range cases {
+		if got := isChunkable(name); got != expected {
+			t.Errorf("isChunkable(%q) = %v, want %v", name, got, expected)
+		}
+	}
+}
+
+func TestScanYAML_OutpostsARN(t *testing.T) {
+	tmpDir := t.TempDir()
+	yamlFile := filepath.Join(tmpDir, "edge.yaml")
+
+	arn := "arn:aws:s3-outposts:us-west-2:123456789012:outpost/op-01ac5d28a6a232904/bucket/edge-data"
+	content := "storage:\n  target: " + arn + "/frames/raw\n"
+	if err := os.WriteFile(yamlFile, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	refs, err := scanYAML(yamlFile)
+	if err != nil {
+		t.Fatalf("scanYAML failed: %v", err)
+	}
+	var found *Reference
+	for i := range refs {
+		if refs[i].OutpostsARN != "" {
+			found = &refs[i]
+		}
+	}
+	if found == nil {
+		t.Fatalf("Expected an Outposts reference, got %+v", refs)
+	}
+	if found.Bucket != "edge-data" || found.Prefix != "frames/raw" || found.OutpostsARN != arn {
+		t.Fatalf("Unexpected Outposts reference: %+v", found)
+	}
+}
+
+func TestRepoScanner_Vaults(t *testing.T) {
+	tmpDir := t.TempDir()
+	content := `archive_to("glacier://nightly-archive")
+vault = "arn:aws:glacier:eu-west-1:123456789012:vaults/dr-copies"
+again = "glacier://nightly-archive"
+`
+	if err := os.WriteFile(filepath.Join(tmpDir, "archive.py"), []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	s := NewRepoScanner(tmpDir)
+	if _, err := s.Scan(context.Background()); err != nil {
+		t.Fatalf("Scan failed: %v", err)
+	}
+	if len(s.Vaults()) != 0 {
+		t.Fatalf("Expected no vault references unless enabled, got %+v", s.Vaults())
+	}
+
+	s.SetScanVaults(true)
+	if _, err := s.Scan(context.Background()); err != nil {
+		t.Fatalf("Scan failed: %v", err)
+	}
+	vaults := s.Vaults()
+	if len(vaults) != 2 {
+		t.Fatalf("Expected 2 deduplicated vault references, got %+v", vaults)
+	}
+	if vaults[0].Vault != "nightly-archive" || vaults[0].Line != 1 {
+		t.Fatalf("Unexpected glacier:// reference: %+v", vaults[0])
+	}
+	if vaults[1].Vault != "dr-copies" || vaults[1].Region != "eu-west-1" {
+		t.Fatalf("Unexpected vault ARN reference: %+v", vaults[1])
+	}
+}
+
+func TestRepoScanner_Principals(t *testing.T) {
+	tmpDir := t.TempDir()
+	tf := `data "aws_iam_role" "worker" {
+  arn = "arn:aws:iam::123456789012:role/app-worker"
+}
+resource "aws_s3_bucket_policy" "p" {
+  principals = ["arn:aws:iam::123456789012:role/app-worker", "arn:aws:iam::123456789012:role/etl/loader"]
+}
+`
+	if err := os.WriteFile(filepath.Join(tmpDir, "iam.tf"), []byte(tf), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+	code := `role = "arn:aws:iam::123456789012:role/not-terraform"`
+	if err := os.WriteFile(filepath.Join(tmpDir, "app.py"), []byte(code), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	s := NewRepoScanner(tmpDir)
+	s.SetScanPrincipals(true)
+	if _, err := s.Scan(context.Background()); err != nil {
+		t.Fatalf("Scan failed: %v", err)
+	}
+	principals := s.Principals()
+	if len(principals) != 2 || principals[0] != "arn:aws:iam::123456789012:role/app-worker" || principals[1] != "arn:aws:iam::123456789012:role/etl/loader" {
+		t.Fatalf("Expected 2 deduplicated Terraform role ARNs, got %v", principals)
+	}
+}
+
+func TestIsScannable(t *testing.T) {
+	for path, want := range map[string]bool{
+		"main.tf":                      true,
+		"prod.tfstate":                 true,
+		"prod.tfstate.backup":          false,
+		"app/.env":                     true,
+		"deploy/prod.env":              true,
+		"conf/app.PROPERTIES":          true,
+		".github/workflows/deploy.yml": true,
+		"Jenkinsfile":                  true,
+		"README.md":                    false,
+		"bin/tool":                     false,
+	} {
+		if got := isScannable(path); got != want {
+			t.Errorf("isScannable(%q) = %v, want %v", path, got, want)
+		}
+	}
+}
@@ -0,0 +1,181 @@
+package scanner
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// sopsCommand is the sops executable run by --decrypt-sops
+var sopsCommand = "sops"
+
+// Kinds of encrypted secrets files
+const (
+	secretSOPS   = "sops"
+	secretSealed = "sealed-secret"
+)
+
+var (
+	// sopsValue is a value sops encrypted
+	sopsValue = []byte("ENC[AES256_GCM,")
+	// sopsMetadata is the metadata sops adds to YAML, JSON and dotenv files
+	sopsMetadata = regexp.MustCompile(`(?m)^sops:\s*$|"sops"\s*:|^sops_mac=`)
+	// sealedSecretKind is a Bitnami SealedSecret manifest
+	sealedSecretKind = regexp.MustCompile(`(?m)^kind:\s*["']?SealedSecret["']?\s*$|"kind"\s*:\s*"SealedSecret"`)
+)
+
+// SetDecryptSOPS makes Scan decrypt sops-encrypted files with the sops
+// executable and the user's own keys (KMS, age or PGP), and scan the
+// plaintext
+func (s *RepoScanner) SetDecryptSOPS(enabled bool) {
+	s.decryptSOPS = enabled
+}
+
+// secretCarry is how much of a line read in pieces is carried into the
+// next piece, longer than any marker readSecret looks for
+const secretCarry = 256
+
+// readSecret reports whether a YAML, JSON or dotenv file is a
+// sops-encrypted file or a sealed-secrets manifest, or "" for neither, with
+// the lines holding a sops-encrypted value. The file is read a piece at a
+// time, since large files read in chunks can be a single line of hundreds
+// of megabytes.
+func readSecret(filePath string) (string, map[int]bool) {
+	ext := strings.ToLower(filepath.Ext(filePath))
+	base := strings.ToLower(filepath.Base(filePath))
+	if ext != ".yaml" && ext != ".yml" && ext != ".json" && base != ".env" && !strings.HasSuffix(base, ".env") {
+		return "", nil
+	}
+	file, err := os.Open(filePath)
+	if err != nil {
+		return "", nil
+	}
+	defer func() { _ = file.Close() }()
+
+	r := bufio.NewReaderSize(file, 64*1024)
+	encrypted := make(map[int]bool)
+	var metadata, sealed bool
+	var text, carry []byte
+	line := 1
+	for {
+		piece, err := r.ReadSlice('\n')
+		// The end of the previous piece of a long line is kept, so a marker
+		// split between two pieces is still found
+		text = append(append(text[:0], carry...), piece...)
+		if bytes.Contains(text, sopsValue) {
+			encrypted[line] = true
+		}
+		metadata = metadata || sopsMetadata.Match(text)
+		sealed = sealed || sealedSecretKind.Match(text)
+		carry = carry[:0]
+		if bytes.HasSuffix(piece, []byte("\n")) {
+			line++
+		} else {
+			carry = append(carry, text[max(0, len(text)-secretCarry):]...)
+		}
+		if err == bufio.ErrBufferFull {
+			continue
+		}
+		if err != nil {
+			if err != io.EOF {
+				return "", nil
+			}
+			break
+		}
+	}
+	switch {
+	case len(encrypted) > 0 && metadata:
+		return secretSOPS, encrypted
+	case sealed:
+		return secretSealed, nil
+	}
+	return "", nil
+}
+
+// scanSecrets filters the references refs read from an encrypted secrets
+// file of size bytes, whose ciphertext would otherwise be taken for bucket
+// names under keys such as bucket:. Sealed secrets can only be decrypted in
+// their cluster, so none of their references are kept. Sops files keep the
+// references of the values they leave in plaintext, or with SetDecryptSOPS
+// return those of the decrypted file instead; files over maxFileSize are
+// not decrypted, since their plaintext could not be line scanned. Files
+// whose encrypted values were not read are recorded as skipped, so those
+// values are not mistaken for having no references.
+func (s *RepoScanner) scanSecrets(ctx context.Context, filePath string, size int64, refs []Reference) []Reference {
+	kind, encrypted := readSecret(filePath)
+	switch kind {
+	case secretSealed:
+		s.skip(filePath, SkipEncrypted)
+		return nil
+	case secretSOPS:
+		if s.decryptSOPS && size <= maxFileSize {
+			decrypted, err := s.scanDecrypted(ctx, filePath)
+			if err == nil {
+				return decrypted
+			}
+			slog.Debug("sops decryption failed", slog.String("file", filePath), slog.String("error", err.Error()))
+			s.skip(filePath, SkipDecryptFailed)
+		} else {
+			s.skip(filePath, SkipEncrypted)
+		}
+		return plaintextRefs(encrypted, refs)
+	}
+	return refs
+}
+
+// plaintextRefs drops the references on the encrypted lines of a sops file
+func plaintextRefs(encrypted map[int]bool, refs []Reference) []Reference {
+	var kept []Reference
+	for _, ref := range refs {
+		if !encrypted[ref.Line] {
+			kept = append(kept, ref)
+		}
+	}
+	return kept
+}
+
+// scanDecrypted decrypts a sops file into a private temporary directory,
+// scans the plaintext as the file itself and removes it. The file's own path,
+// not the temporary one, decides its Helm chart, dbt project and CI context.
+func (s *RepoScanner) scanDecrypted(ctx context.Context, filePath string) ([]Reference, error) {
+	plaintext, err := decryptSOPS(ctx, filePath)
+	if err != nil {
+		return nil, err
+	}
+	dir, err := os.MkdirTemp("", "s3spectre-sops-")
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	decrypted := filepath.Join(dir, filepath.Base(filePath))
+	if err := os.WriteFile(decrypted, plaintext, 0o600); err != nil {
+		return nil, err
+	}
+	return s.scanFileAs(filePath, decrypted)
+}
+
+// decryptSOPS runs sops --decrypt on a file; failures carry sops's own
+// message. "--" ends the options, so a file named like a flag is not read
+// as one.
+func decryptSOPS(ctx context.Context, filePath string) ([]byte, error) {
+	cmd := exec.CommandContext(ctx, sopsCommand, "--decrypt", "--", filePath)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("%w: %s", err, msg)
+		}
+		return nil, err
+	}
+	return out, nil
+}
@@ -0,0 +1,205 @@
+package scanner
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+const sopsYAML = `bucket: ENC[AES256_GCM,data:q2kxJw==,iv:abc=,tag:def=,type:str]
+archive_unencrypted: s3://plain-archive/
+sops:
+    kms:
+        - arn: arn:aws:kms:us-east-1:111122223333:key/abc
+    mac: ENC[AES256_GCM,data:xyz=,iv:abc=,tag:def=,type:str]
+    version: 3.8.1
+`
+
+const sealedSecretYAML = `apiVersion: bitnami.com/v1alpha1
+kind: SealedSecret
+metadata:
+  name: uploads
+spec:
+  encryptedData:
+    BUCKET: AgBy3i4OJSWK+PiTySYZZA==
+`
+
+// fakeSOPS points sopsCommand at a script with the given body for the test
+func fakeSOPS(t *testing.T, body string) {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("fake sops is a shell script")
+	}
+	script := filepath.Join(t.TempDir(), "sops")
+	if err := os.WriteFile(script, []byte("#!/bin/sh\n"+body), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	previous := sopsCommand
+	sopsCommand = script
+	t.Cleanup(func() { sopsCommand = previous })
+}
+
+func TestRepoScanner_Secrets(t *testing.T) {
+	repo := t.TempDir()
+	secrets := filepath.Join(repo, "secrets.yaml")
+	if err := os.WriteFile(secrets, []byte(sopsYAML), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(repo, "sealed.yaml"), []byte(sealedSecretYAML), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	buckets := func(refs []Reference) map[string]string {
+		found := make(map[string]string)
+		for _, ref := range refs {
+			found[ref.Bucket] = ref.File
+		}
+		return found
+	}
+
+	// Without decryption the plaintext values are still found
+	s := NewRepoScanner(repo)
+	refs, err := s.Scan(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if found := buckets(refs); len(found) != 1 || found["plain-archive"] != secrets {
+		t.Errorf("references = %v, want plain-archive only", found)
+	}
+	if counts := CountSkipped(s.SkippedFiles()); counts[SkipEncrypted] != 2 {
+		t.Errorf("skipped = %v, want the sops file and the sealed secret as encrypted", counts)
+	}
+
+	fakeSOPS(t, "printf 'bucket: s3://secret-uploads/raw/\\narchive_unencrypted: s3://plain-archive/\\n'\n")
+	s.SetDecryptSOPS(true)
+	refs, err = s.Scan(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if found := buckets(refs); len(found) != 2 || found["secret-uploads"] != secrets {
+		t.Errorf("references = %v, want the decrypted bucket under the encrypted file's name", found)
+	}
+	if counts := CountSkipped(s.SkippedFiles()); counts[SkipEncrypted] != 1 {
+		t.Errorf("skipped = %v, want only the sealed secret as encrypted", counts)
+	}
+
+	// A failed decryption keeps the plaintext references
+	fakeSOPS(t, "echo 'Failed to get the data key' >&2\nexit 1\n")
+	refs, err = s.Scan(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if found := buckets(refs); len(found) != 1 || found["plain-archive"] == "" {
+		t.Errorf("references = %v, want plain-archive", found)
+	}
+	if counts := CountSkipped(s.SkippedFiles()); counts[SkipDecryptFailed] != 1 {
+		t.Errorf("skipped = %v, want the sops file as decrypt_failed", counts)
+	}
+}
+
+func TestRepoScanner_LargeSecrets(t *testing.T) {
+	repo := t.TempDir()
+	secrets := filepath.Join(repo, "secrets.yaml")
+	padding := strings.Repeat("# padding\n", maxFileSize/10+1)
+	if err := os.WriteFile(secrets, []byte(padding+sopsYAML), 0644); err != nil {
+		t.Fatal(err)
+	}
+	// Large files are read in chunks and never decrypted
+	fakeSOPS(t, "exit 1\n")
+
+	s := NewRepoScanner(repo)
+	s.SetDecryptSOPS(true)
+	refs, err := s.Scan(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(refs) != 1 || refs[0].Bucket != "plain-archive" {
+		t.Errorf("references = %+v, want plain-archive only", refs)
+	}
+	if counts := CountSkipped(s.SkippedFiles()); counts[SkipEncrypted] != 1 {
+		t.Errorf("skipped = %v, want the sops file as encrypted", counts)
+	}
+}
+
+func TestDecryptSOPS_EndsOptions(t *testing.T) {
+	fakeSOPS(t, "echo \"$@\"\n")
+	out, err := decryptSOPS(context.Background(), "--help.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.TrimSpace(string(out)); got != "--decrypt -- --help.yaml" {
+		t.Errorf("sops arguments = %q, want the file after --", got)
+	}
+}
+
+func TestReadSecret_LongLine(t *testing.T) {
+	// An encrypted value straddling the reader's buffer on a single line
+	path := filepath.Join(t.TempDir(), "export.json")
+	content := `{"pad": "` + strings.Repeat("x", 64*1024-29) + `", "bucket": "ENC[AES256_GCM,data:abc=,type:str]", "sops": {"version": "3.8.1"}}`
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	kind, encrypted := readSecret(path)
+	if kind != secretSOPS || !encrypted[1] || len(encrypted) != 1 {
+		t.Errorf("readSecret = %q, %v, want sops with line 1 encrypted", kind, encrypted)
+	}
+}
+
+func TestRepoScanner_DecryptedHelmValues(t *testing.T) {
+	repo := t.TempDir()
+	chart := filepath.Join(repo, "charts", "ingest")
+	if err := os.MkdirAll(chart, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(chart, "Chart.yaml"), []byte("apiVersion: v2\nname: ingest\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	values := filepath.Join(chart, "values-prod.yaml")
+	if err := os.WriteFile(values, []byte(sopsYAML), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	fakeSOPS(t, "printf 's3:\\n  bucketName: secret-uploads\\n'\n")
+	s := NewRepoScanner(repo)
+	s.SetDecryptSOPS(true)
+	refs, err := s.Scan(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(refs) != 1 {
+		t.Fatalf("references = %+v, want secret-uploads only", refs)
+	}
+	// The chart is found from the encrypted file's path, not the temporary one
+	if ref := refs[0]; ref.Bucket != "secret-uploads" || ref.File != values || ref.Context != helmContext || ref.Workflow != "ingest" {
+		t.Errorf("reference = %+v, want secret-uploads in chart ingest at %s", ref, values)
+	}
+}
+
+func TestReadSecret(t *testing.T) {
+	dir := t.TempDir()
+	tests := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{"secrets.yaml", sopsYAML, secretSOPS},
+		{"secrets.json", `{"bucket": "ENC[AES256_GCM,data:abc=,type:str]", "sops": {"version": "3.8.1"}}`, secretSOPS},
+		{"prod.env", "BUCKET=ENC[AES256_GCM,data:abc=,type:str]\nsops_mac=ENC[AES256_GCM,data:xyz=,type:str]\n", secretSOPS},
+		{"sealed.yml", sealedSecretYAML, secretSealed},
+		{"sealed.json", `{"apiVersion": "bitnami.com/v1alpha1", "kind": "SealedSecret"}`, secretSealed},
+		{"config.yaml", "bucket: s3://plain/\nsops: notes\n", ""},
+		{"handler.py", sopsYAML, ""},
+	}
+	for _, tt := range tests {
+		path := filepath.Join(dir, tt.name)
+		if err := os.WriteFile(path, []byte(tt.content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if got, _ := readSecret(path); got != tt.want {
+			t.Errorf("readSecret(%s) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,467 @@
+package scanner
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// shellLiteralDollar stands in for a quoted or escaped '$' until variables
+// are expanded, so it is not taken for a variable
+const shellLiteralDollar = "\x00"
+
+var (
+	// shellAssignmentPattern matches a variable assignment word
+	shellAssignmentPattern = regexp.MustCompile(`^([A-Za-z_][A-Za-z0-9_]*)=(.*)$`)
+
+	// shellVariablePattern matches $NAME, ${NAME} and ${NAME:-default}
+	shellVariablePattern = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)(?::?-([^}]*))?\}|\$([A-Za-z_][A-Za-z0-9_]*)`)
+)
+
+// shellPrefixes are keywords and commands that can come before the
+// command they run
+var shellPrefixes = map[string]bool{
+	"if": true, "then": true, "else": true, "elif": true, "while": true, "until": true, "do": true,
+	"!": true, "{": true,
+	"export": true, "readonly": true, "local": true, "declare": true, "typeset": true,
+	"sudo": true, "exec": true, "time": true, "command": true, "nohup": true, "env": true,
+}
+
+// awsValueOptions are aws CLI options of the global and s3 commands that
+// take a value
+var awsValueOptions = map[string]bool{
+	"--profile": true, "--region": true, "--endpoint-url": true, "--output": true, "--query": true,
+	"--color": true, "--ca-bundle": true, "--cli-read-timeout": true, "--cli-connect-timeout": true,
+	"--cli-binary-format": true, "--source-region": true, "--exclude": true, "--include": true,
+	"--acl": true, "--storage-class": true, "--sse": true, "--sse-kms-key-id": true, "--sse-c": true,
+	"--sse-c-key": true, "--sse-c-copy-source": true, "--sse-c-copy-source-key": true,
+	"--content-type": true, "--cache-control": true, "--content-disposition": true,
+	"--content-encoding": true, "--content-language": true, "--expires": true, "--metadata": true,
+	"--metadata-directive": true, "--grants": true, "--website-redirect": true, "--expected-size": true,
+	"--page-size": true, "--request-payer": true, "--copy-props": true, "--expires-in": true,
+	"--index-document": true, "--error-document": true, "--checksum-algorithm": true,
+}
+
+// shellWord is one word of a shell command after quote removal
+type shellWord struct {
+	text string
+	line int
+}
+
+// shellHeredoc is a here-document whose body starts on the next line
+type shellHeredoc struct {
+	delim     string
+	stripTabs bool
+}
+
+// scanShell scans a shell script, adding the buckets and keys of aws s3 and
+// aws s3api commands to the regex references. Commands continued over
+// several lines, inside $(...) and in here-documents are parsed, and
+// variables assigned a literal earlier in the script are expanded. Command
+// references replace the plain ones on the same line, since they carry the
+// side of a copy or sync and the operation.
+func scanShell(filePath string) ([]Reference, error) {
+	refs, err := scanCode(filePath)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+	commands := shellRefs(string(data), filePath)
+	if len(commands) == 0 {
+		return refs, nil
+	}
+	seen := make(map[string]bool)
+	for _, ref := range commands {
+		seen[fmt.Sprintf("%s|%d", ref.Bucket, ref.Line)] = true
+	}
+	merged := commands
+	for _, ref := range refs {
+		if !seen[fmt.Sprintf("%s|%d", ref.Bucket, ref.Line)] {
+			merged = append(merged, ref)
+		}
+	}
+	return merged, nil
+}
+
+// shellRefs finds the S3 locations named by aws s3 and s3api commands
+func shellRefs(src, filePath string) []Reference {
+	vars := make(map[string]string)
+	var refs []Reference
+	for _, command := range shellCommands(src) {
+		words := make([]shellWord, len(command))
+		for k, word := range command {
+			words[k] = shellWord{text: expandShell(word.text, vars), line: word.line}
+		}
+
+		k := 0
+		prefixed := false
+		for ; k < len(words); k++ {
+			text := words[k].text
+			if match := shellAssignmentPattern.FindStringSubmatch(text); match != nil {
+				if match[2] == "" || strings.Contains(match[2], "$") {
+					delete(vars, match[1])
+				} else {
+					vars[match[1]] = match[2]
+				}
+				continue
+			}
+			if shellPrefixes[text] || (prefixed && strings.HasPrefix(text, "-")) {
+				prefixed = true
+				continue
+			}
+			break
+		}
+		if k < len(words) && path.Base(words[k].text) == "aws" {
+			refs = append(refs, awsRefs(words[k+1:], filePath)...)
+		}
+	}
+	return refs
+}
+
+// awsRefs returns the references of one aws command's arguments
+func awsRefs(args []shellWord, filePath string) []Reference {
+	n := 0
+	for n < len(args) && strings.HasPrefix(args[n].text, "--") {
+		if awsValueOptions[args[n].text] {
+			n++
+		}
+		n++
+	}
+	if n+1 >= len(args) {
+		return nil
+	}
+	switch args[n].text {
+	case "s3":
+		return awsS3Refs(args[n+1].text, args[n+2:], filePath)
+	case "s3api":
+		return awsS3APIRefs(args[n+1].text, args[n+2:], filePath)
+	}
+	return nil
+}
+
+// awsS3Refs returns the s3:// paths of an aws s3 command
+func awsS3Refs(op string, args []shellWord, filePath string) []Reference {
+	var paths []shellWord
+	for k := 0; k < len(args); k++ {
+		if strings.HasPrefix(args[k].text, "-") && args[k].text != "-" {
+			if awsValueOptions[args[k].text] {
+				k++
+			}
+			continue
+		}
+		paths = append(paths, args[k])
+	}
+
+	var refs []Reference
+	for k, word := range paths {
+		match := s3URLPattern.FindStringSubmatchIndex(word.text)
+		if match == nil || match[0] != 0 || !completeBucket(word.text, match[3]) {
+			continue
+		}
+		prefix := ""
+		if match[4] >= 0 {
+			prefix = shellStaticPrefix(word.text[match[4]:match[5]])
+		}
+		refs = append(refs, Reference{
+			Bucket:  word.text[match[2]:match[3]],
+			Prefix:  prefix,
+			File:    filePath,
+			Line:    word.line,
+			Context: awsS3Context(op, k),
+		})
+	}
+	return refs
+}
+
+// awsS3Context is the operation of the path at a position of an aws s3
+// command: the source of a cp or sync is read and the destination written
+func awsS3Context(op string, position int) string {
+	switch op {
+	case "cp", "sync":
+		if position == 0 {
+			return string(RefTypeRead)
+		}
+		return string(RefTypeWrite)
+	case "mv", "rm", "mb", "rb", "website":
+		return string(RefTypeWrite)
+	case "ls":
+		return string(RefTypeList)
+	case "presign":
+		return string(RefTypeRead)
+	}
+	return string(RefTypeUnknown)
+}
+
+// awsS3APIRefs returns the --bucket and --copy-source of an aws s3api
+// command, with --key or --prefix as the prefix
+func awsS3APIRefs(op string, args []shellWord, filePath string) []Reference {
+	opts := make(map[string]shellWord)
+	for k := 0; k < len(args); k++ {
+		name, value, ok := strings.Cut(args[k].text, "=")
+		if !strings.HasPrefix(name, "--") {
+			continue
+		}
+		line := args[k].line
+		if !ok {
+			if k+1 >= len(args) || strings.HasPrefix(args[k+1].text, "--") {
+				continue
+			}
+			k++
+			value, line = args[k].text, args[k].line
+		}
+		if _, dup := opts[name]; !dup {
+			opts[name] = shellWord{text: value, line: line}
+		}
+	}
+
+	var refs []Reference
+	if bucket, ok := opts["--bucket"]; ok && bucketListNamePattern.MatchString(bucket.text) {
+		ref := Reference{Bucket: bucket.text, File: filePath, Line: bucket.line, Context: awsS3APIContext(op)}
+		if key, ok := opts["--key"]; ok {
+			ref.Prefix = shellStaticPrefix(key.text)
+		} else if prefix, ok := opts["--prefix"]; ok {
+			ref.Prefix = shellStaticPrefix(prefix.text)
+		}
+		refs = append(refs, ref)
+	}
+	if source, ok := opts["--copy-source"]; ok {
+		location, _, _ := strings.Cut(source.text, "?")
+		bucket, key, _ := strings.Cut(location, "/")
+		if bucketListNamePattern.MatchString(bucket) {
+			refs = append(refs, Reference{
+				Bucket:  bucket,
+				Prefix:  shellStaticPrefix(key),
+				File:    filePath,
+				Line:    source.line,
+				Context: string(RefTypeRead),
+			})
+		}
+	}
+	return refs
+}
+
+// awsS3APIContext infers the operation from an s3api operation name
+func awsS3APIContext(op string) string {
+	switch {
+	case strings.HasPrefix(op, "list-"):
+		return string(RefTypeList)
+	case strings.HasPrefix(op, "get-") || strings.HasPrefix(op, "head-") || strings.HasPrefix(op, "select-"):
+		return string(RefTypeRead)
+	case strings.HasPrefix(op, "put-") || strings.HasPrefix(op, "delete-") || strings.HasPrefix(op, "create-") ||
+		strings.HasPrefix(op, "copy-") || strings.HasPrefix(op, "upload-") || strings.HasPrefix(op, "complete-") ||
+		strings.HasPrefix(op, "abort-") || strings.HasPrefix(op, "restore-"):
+		return string(RefTypeWrite)
+	}
+	return string(RefTypeUnknown)
+}
+
+// shellStaticPrefix cuts a key at its first unresolved variable
+func shellStaticPrefix(key string) string {
+	if i := strings.Index(key, "$"); i >= 0 {
+		return key[:i]
+	}
+	return key
+}
+
+// expandShell expands the variables of a word that have known values, and
+// ${NAME:-default} of unknown ones to the default. Other variables are left
+// in place so the word is recognized as built at runtime.
+func expandShell(text string, vars map[string]string) string {
+	var b strings.Builder
+	last := 0
+	for _, m := range shellVariablePattern.FindAllStringSubmatchIndex(text, -1) {
+		b.WriteString(text[last:m[0]])
+		last = m[1]
+		name := ""
+		if m[2] >= 0 {
+			name = text[m[2]:m[3]]
+		} else {
+			name = text[m[6]:m[7]]
+		}
+		switch value, ok := vars[name]; {
+		case ok:
+			b.WriteString(value)
+		case m[4] >= 0:
+			b.WriteString(text[m[4]:m[5]])
+		default:
+			b.WriteString(text[m[0]:m[1]])
+		}
+	}
+	b.WriteString(text[last:])
+	return strings.ReplaceAll(b.String(), shellLiteralDollar, "$")
+}
+
+// shellCommands splits a shell script into simple commands of unquoted
+// words. Lists, pipelines, subshells and command substitutions are split
+// into their commands; comments and redirections are dropped. Each line of
+// a here-document body is split on its own, since bodies fed to a shell or
+// ssh are commands too and an apostrophe in a plain body must not open a
+// quote.
+func shellCommands(src string) [][]shellWord {
+	var (
+		commands [][]shellWord
+		command  []shellWord
+		word     strings.Builder
+		inWord   bool
+		skipWord bool
+		wordLine int
+		heredocs []shellHeredoc
+	)
+	line := 1
+	add := func(s string) {
+		if !inWord {
+			inWord, wordLine = true, line
+		}
+		word.WriteString(s)
+	}
+	endWord := func() {
+		if inWord && !skipWord {
+			command = append(command, shellWord{text: word.String(), line: wordLine})
+		}
+		if inWord {
+			skipWord = false
+		}
+		word.Reset()
+		inWord = false
+	}
+	endCommand := func() {
+		endWord()
+		skipWord = false
+		if len(command) > 0 {
+			commands = append(commands, command)
+			command = nil
+		}
+	}
+
+	for i := 0; i < len(src); i++ {
+		c := src[i]
+		switch {
+		case c == '\n':
+			endCommand()
+			line++
+			for _, doc := range heredocs {
+				for i+1 < len(src) {
+					end := strings.IndexByte(src[i+1:], '\n')
+					if end < 0 {
+						end = len(src) - i - 1
+					}
+					body := src[i+1 : i+1+end]
+					bodyLine := line
+					i += end + 1
+					line++
+					text := strings.TrimSuffix(body, "\r")
+					if doc.stripTabs {
+						text = strings.TrimLeft(text, "\t")
+					}
+					if text == doc.delim {
+						break
+					}
+					for _, bodyCommand := range shellCommands(body) {
+						for k := range bodyCommand {
+							bodyCommand[k].line += bodyLine - 1
+						}
+						commands = append(commands, bodyCommand)
+					}
+				}
+			}
+			heredocs = nil
+		case c == ' ' || c == '\t' || c == '\r':
+			endWord()
+		case c == '\\':
+			if i+1 < len(src) {
+				i++
+				switch src[i] {
+				case '\n':
+					line++
+				case '$':
+					add(shellLiteralDollar)
+				default:
+					add(src[i : i+1])
+				}
+			}
+		case c == '#' && !inWord:
+			for i+1 < len(src) && src[i+1] != '\n' {
+				i++
+			}
+		case c == '\'':
+			add("")
+			end := strings.IndexByte(src[i+1:], '\'')
+			if end < 0 {
+				end = len(src) - i - 1
+			}
+			quoted := src[i+1 : i+1+end]
+			line += strings.Count(quoted, "\n")
+			word.WriteString(strings.ReplaceAll(quoted, "$", shellLiteralDollar))
+			i += end + 1
+		case c == '"':
+			add("")
+			for i++; i < len(src) && src[i] != '"'; i++ {
+				switch {
+				case src[i] == '\\' && i+1 < len(src) && strings.IndexByte("\"\\$`\n", src[i+1]) >= 0:
+					i++
+					switch src[i] {
+					case '\n':
+						line++
+					case '$':
+						word.WriteString(shellLiteralDollar)
+					default:
+						word.WriteByte(src[i])
+					}
+				case src[i] == '\n':
+					line++
+					word.WriteByte('\n')
+				default:
+					word.WriteByte(src[i])
+				}
+			}
+		case c == '$' && i+1 < len(src) && src[i+1] == '(':
+			endCommand()
+			i++
+		case c == '<' && strings.HasPrefix(src[i:], "<<") && !strings.HasPrefix(src[i:], "<<<"):
+			endWord()
+			i += 2
+			doc := shellHeredoc{}
+			if i < len(src) && src[i] == '-' {
+				doc.stripTabs = true
+				i++
+			}
+			for i < len(src) && (src[i] == ' ' || src[i] == '\t') {
+				i++
+			}
+			var delim strings.Builder
+			for ; i < len(src) && strings.IndexByte(" \t\r\n;|&<>()", src[i]) < 0; i++ {
+				if src[i] != '\'' && src[i] != '"' && src[i] != '\\' {
+					delim.WriteByte(src[i])
+				}
+			}
+			i--
+			if delim.Len() > 0 {
+				doc.delim = delim.String()
+				heredocs = append(heredocs, doc)
+			}
+		case c == '>' || c == '<':
+			// Drop a redirection and its target, and a file descriptor
+			// number before it such as the 2 of 2>&1
+			if inWord && strings.Trim(word.String(), "0123456789") == "" {
+				word.Reset()
+				inWord = false
+			}
+			endWord()
+			for i+1 < len(src) && strings.IndexByte("<>&|", src[i+1]) >= 0 {
+				i++
+			}
+			skipWord = true
+		case c == ';' || c == '|' || c == '&' || c == '(' || c == ')' || c == '`':
+			endCommand()
+		default:
+			add(src[i : i+1])
+		}
+	}
+	endCommand()
+	return commands
+}
@@ -0,0 +1,113 @@
+package scanner
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestScanShell_AWSCommands(t *testing.T) {
+	script := `#!/usr/bin/env bash
+set -euo pipefail
+
+BUCKET=ops-backups
+ENV="${DEPLOY_ENV:-prod}"
+
+# aws s3 cp s3://commented-out/x .
+aws --profile ops s3 sync ./dist \
+    s3://web-assets-$ENV/static/ --delete
+
+if aws s3 ls "s3://$BUCKET/daily/" >/dev/null 2>&1; then
+  aws s3 cp s3://raw-events/2024/ s3://curated-events/import/ --recursive --exclude '*.tmp'
+fi
+
+aws s3api put-object --bucket "$BUCKET" --key "db/$(date +%F).sql.gz" --body dump.sql.gz
+aws s3api copy-object --copy-source=raw-events/a.json --bucket archive-events --key a.json
+KEYS=$(aws s3api list-objects-v2 --bucket ops-logs --prefix 'app/$x' --query 'Contents[].Key')
+aws s3 cp "s3://$UNKNOWN/x" .
+
+ssh bastion <<'EOF'
+echo "it's remote"
+aws s3 rm s3://tmp-scratch/old/ --recursive
+EOF
+`
+	path := filepath.Join(t.TempDir(), "backup.sh")
+	if err := os.WriteFile(path, []byte(script), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	refs, err := scanShell(path)
+	if err != nil {
+		t.Fatalf("scanShell failed: %v", err)
+	}
+	type want struct {
+		bucket, prefix, context string
+		line                    int
+	}
+	wants := []want{
+		{"web-assets-prod", "static/", "write", 9},
+		{"ops-backups", "daily/", "list", 11},
+		{"raw-events", "2024/", "read", 12},
+		{"curated-events", "import/", "write", 12},
+		{"ops-backups", "db/", "write", 15},
+		{"raw-events", "a.json", "read", 16},
+		{"archive-events", "a.json", "write", 16},
+		{"ops-logs", "app/", "list", 17},
+		{"tmp-scratch", "old/", "write", 22},
+	}
+	for _, w := range wants {
+		found := false
+		for _, ref := range refs {
+			if ref.Bucket == w.bucket && ref.Prefix == w.prefix && ref.Context == w.context && ref.Line == w.line {
+				found = true
+			}
+		}
+		if !found {
+			t.Errorf("missing reference %+v in %+v", w, refs)
+		}
+	}
+	for _, ref := range refs {
+		if ref.Bucket == "raw-events" && ref.Line == 12 && ref.Context != "read" {
+			t.Errorf("plain URL reference should give way to the command one: %+v", ref)
+		}
+		if ref.Bucket == "commented-out" && ref.Context == "read" {
+			t.Errorf("commented command should not be parsed: %+v", ref)
+		}
+	}
+}
+
+func TestShellCommands(t *testing.T) {
+	src := "A=1 aws s3 ls 's3://b/$x' | grep y; echo \"a \\\"q\\\"\" && \\\n  aws s3 rb s3://c\n"
+	commands := shellCommands(src)
+	if len(commands) != 4 {
+		t.Fatalf("expected 4 commands, got %d: %+v", len(commands), commands)
+	}
+	if got := commands[0][4].text; got != "s3://b/"+shellLiteralDollar+"x" {
+		t.Errorf("single-quoted $ should stay literal, got %q", got)
+	}
+	if got := commands[2][1].text; got != `a "q"` {
+		t.Errorf("double-quoted word = %q", got)
+	}
+	last := commands[3]
+	if last[0].text != "aws" || last[0].line != 2 || last[3].text != "s3://c" {
+		t.Errorf("continued command = %+v", last)
+	}
+}
+
+func TestAWSS3APIContext(t *testing.T) {
+	tests := map[string]string{
+		"get-object":            "read",
+		"head-object":           "read",
+		"list-objects-v2":       "list",
+		"put-bucket-policy":     "write",
+		"delete-objects":        "write",
+		"restore-object":        "write",
+		"wait":                  "unknown",
+		"select-object-content": "read",
+	}
+	for op, want := range tests {
+		if got := awsS3APIContext(op); got != want {
+			t.Errorf("awsS3APIContext(%q) = %q, want %q", op, got, want)
+		}
+	}
+}
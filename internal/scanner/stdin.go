@@ -0,0 +1,84 @@
+package scanner
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// StdinSource is the File recorded for piped references that name none
+const StdinSource = "<stdin>"
+
+// ReadReferences decodes references produced by another tool. The input is a
+// JSON array of references, an object with a "references" array (such as
+// the refs command's JSON output), or one reference object per line
+// (NDJSON). Entries without a file are attributed to source.
+func ReadReferences(r io.Reader, source string) ([]Reference, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	trimmed := bytes.TrimSpace(data)
+
+	var refs []Reference
+	switch {
+	case len(trimmed) == 0:
+	case trimmed[0] == '[':
+		if err := json.Unmarshal(trimmed, &refs); err != nil {
+			return nil, fmt.Errorf("decoding reference array: %w", err)
+		}
+	default:
+		var wrapped struct {
+			References *[]Reference `json:"references"`
+		}
+		if json.Unmarshal(trimmed, &wrapped) == nil && wrapped.References != nil {
+			refs = *wrapped.References
+			break
+		}
+		refs, err = readNDJSON(trimmed)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	seen := make(map[string]bool)
+	out := make([]Reference, 0, len(refs))
+	for i, ref := range refs {
+		// References to other object stores are passed through unchecked
+		if ref.Provider == "" && !bucketListNamePattern.MatchString(ref.Bucket) {
+			return nil, fmt.Errorf("reference %d: invalid bucket name %q", i+1, ref.Bucket)
+		}
+		if ref.File == "" {
+			ref.File = source
+		}
+		key := ref.Provider + "|" + ref.Bucket + "|" + ref.Prefix
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		out = append(out, ref)
+	}
+	return out, nil
+}
+
+func readNDJSON(data []byte) ([]Reference, error) {
+	var refs []Reference
+	sc := bufio.NewScanner(bytes.NewReader(data))
+	sc.Buffer(make([]byte, 64*1024), 10*1024*1024)
+	lineNum := 0
+	for sc.Scan() {
+		lineNum++
+		line := bytes.TrimSpace(sc.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		var ref Reference
+		if err := json.Unmarshal(line, &ref); err != nil {
+			return nil, fmt.Errorf("decoding reference on line %d: %w", lineNum, err)
+		}
+		refs = append(refs, ref)
+	}
+	return refs, sc.Err()
+}
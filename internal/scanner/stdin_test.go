@@ -0,0 +1,58 @@
+package scanner
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestReadReferences(t *testing.T) {
+	tests := map[string]string{
+		"array":   `[{"bucket":"prod-data","prefix":"logs/","context":"read"},{"bucket":"prod-data","prefix":"logs/"},{"bucket":"prod-logs","file":"etl.py","line":4}]`,
+		"wrapped": `{"tool":"s3spectre","references":[{"bucket":"prod-data","prefix":"logs/","context":"read"},{"bucket":"prod-logs","file":"etl.py","line":4}]}`,
+		"ndjson":  "{\"bucket\":\"prod-data\",\"prefix\":\"logs/\",\"context\":\"read\"}\n\n{\"bucket\":\"prod-logs\",\"file\":\"etl.py\",\"line\":4}\n",
+	}
+	for name, input := range tests {
+		t.Run(name, func(t *testing.T) {
+			refs, err := ReadReferences(strings.NewReader(input), StdinSource)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(refs) != 2 {
+				t.Fatalf("got %d references, want 2: %+v", len(refs), refs)
+			}
+			if refs[0].File != StdinSource || refs[0].Context != "read" {
+				t.Errorf("first reference: got %+v", refs[0])
+			}
+			if refs[1].File != "etl.py" || refs[1].Line != 4 {
+				t.Errorf("second reference: got %+v", refs[1])
+			}
+		})
+	}
+}
+
+func TestReadReferences_Errors(t *testing.T) {
+	refs, err := ReadReferences(strings.NewReader("  \n"), StdinSource)
+	if err != nil || len(refs) != 0 {
+		t.Errorf("empty input: got %v, %v", refs, err)
+	}
+	if _, err := ReadReferences(strings.NewReader(`{"bucket":"ok-bucket"}`+"\nnot json\n"), StdinSource); err == nil || !strings.Contains(err.Error(), "line 2") {
+		t.Errorf("expected line error, got %v", err)
+	}
+	if _, err := ReadReferences(strings.NewReader(`[{"prefix":"x/"}]`), StdinSource); err == nil {
+		t.Error("expected error for missing bucket")
+	}
+}
+
+func TestReadReferences_Providers(t *testing.T) {
+	input := `[{"bucket":"prod-data"},{"bucket":"prod-data","provider":"gcs"},{"bucket":"acme/backups","provider":"azure"}]`
+	refs, err := ReadReferences(strings.NewReader(input), StdinSource)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(refs) != 3 {
+		t.Fatalf("got %d references, want the S3 and GCS buckets kept apart: %+v", len(refs), refs)
+	}
+	if refs[2].URI() != "https://acme.blob.core.windows.net/backups" {
+		t.Errorf("Azure reference URI = %q", refs[2].URI())
+	}
+}
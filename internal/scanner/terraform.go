@@ -73,7 +73,10 @@ func scanTerraform(filePath string) ([]Reference, error) {
 			}
 		}
 
-		// Also check for s3:// URLs in any line
+		// Check for S3 on Outposts bucket ARNs
+		refs = append(refs, outpostsRefs(line, filePath, lineNum, "terraform")...)
+
+		// Also check for s3://, s3a:// and s3n:// URLs in any line
 		if matches := s3URLPattern.FindAllStringSubmatch(line, -1); matches != nil {
 			for _, match := range matches {
 				refs = append(refs, Reference{
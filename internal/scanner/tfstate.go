@@ -0,0 +1,99 @@
+package scanner
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// tfStateContext marks references found in Terraform state
+const tfStateContext = "tfstate"
+
+// tfState covers the parts of Terraform state formats 3 and 4 that name
+// buckets
+type tfState struct {
+	Version   int `json:"version"`
+	Resources []struct {
+		Mode      string `json:"mode"`
+		Type      string `json:"type"`
+		Instances []struct {
+			Attributes map[string]any `json:"attributes"`
+		} `json:"instances"`
+	} `json:"resources"`
+	Modules []struct {
+		Resources map[string]struct {
+			Type    string `json:"type"`
+			Primary struct {
+				Attributes map[string]any `json:"attributes"`
+			} `json:"primary"`
+		} `json:"resources"`
+	} `json:"modules"`
+}
+
+// scanTFState reads bucket names from S3 resources and data sources in a
+// Terraform state file, catching buckets that state still provisions after
+// their code was removed
+func scanTFState(filePath string) ([]Reference, error) {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+	return parseTFState(data, filePath)
+}
+
+func parseTFState(data []byte, filePath string) ([]Reference, error) {
+	var state tfState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, fmt.Errorf("parsing Terraform state %s: %w", filePath, err)
+	}
+
+	var refs []Reference
+	seen := make(map[string]bool)
+	add := func(resourceType string, attributes map[string]any) {
+		if !isS3ResourceType(resourceType) {
+			return
+		}
+		bucket, _ := attributes["bucket"].(string)
+		if bucket == "" || seen[bucket] || !bucketListNamePattern.MatchString(bucket) {
+			return
+		}
+		seen[bucket] = true
+		refs = append(refs, Reference{
+			Bucket:  bucket,
+			File:    filePath,
+			Line:    attributeLine(data, bucket),
+			Context: tfStateContext,
+		})
+	}
+
+	for _, resource := range state.Resources {
+		for _, instance := range resource.Instances {
+			add(resource.Type, instance.Attributes)
+		}
+	}
+	for _, module := range state.Modules {
+		for _, resource := range module.Resources {
+			add(resource.Type, resource.Primary.Attributes)
+		}
+	}
+	return refs, nil
+}
+
+// isS3ResourceType matches aws_s3_bucket and its companion resources
+// (aws_s3_bucket_policy, aws_s3_bucket_versioning, ...) and S3 objects
+func isS3ResourceType(resourceType string) bool {
+	return strings.HasPrefix(resourceType, "aws_s3_bucket") || resourceType == "aws_s3_object"
+}
+
+// attributeLine returns the line of the first "bucket" attribute naming the
+// bucket, or 1 when the state is formatted differently
+func attributeLine(data []byte, bucket string) int {
+	for _, pattern := range []string{`"bucket": "` + bucket + `"`, `"bucket":"` + bucket + `"`} {
+		if i := bytes.Index(data, []byte(pattern)); i >= 0 {
+			return bytes.Count(data[:i], []byte("\n")) + 1
+		}
+	}
+	return 1
+}
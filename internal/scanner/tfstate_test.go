@@ -0,0 +1,129 @@
+package scanner
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const tfStateV4 = `{
+  "version": 4,
+  "resources": [
+    {
+      "mode": "managed",
+      "type": "aws_s3_bucket",
+      "name": "logs",
+      "instances": [
+        {
+          "attributes": {
+            "bucket": "state-only-logs",
+            "id": "state-only-logs"
+          }
+        }
+      ]
+    },
+    {
+      "mode": "managed",
+      "type": "aws_s3_bucket_versioning",
+      "name": "logs",
+      "instances": [
+        {
+          "attributes": {
+            "bucket": "state-only-logs"
+          }
+        }
+      ]
+    },
+    {
+      "mode": "data",
+      "type": "aws_s3_bucket",
+      "name": "shared",
+      "instances": [
+        {
+          "attributes": {
+            "bucket": "shared-artifacts"
+          }
+        }
+      ]
+    },
+    {
+      "mode": "managed",
+      "type": "aws_dynamodb_table",
+      "name": "locks",
+      "instances": [
+        {
+          "attributes": {
+            "bucket": "not-a-bucket"
+          }
+        }
+      ]
+    }
+  ]
+}`
+
+func TestParseTFState_V4(t *testing.T) {
+	refs, err := parseTFState([]byte(tfStateV4), "terraform.tfstate")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(refs) != 2 {
+		t.Fatalf("got %d references, want 2: %+v", len(refs), refs)
+	}
+	if refs[0].Bucket != "state-only-logs" || refs[0].Line != 11 || refs[0].Context != tfStateContext {
+		t.Errorf("first reference: got %+v", refs[0])
+	}
+	if refs[1].Bucket != "shared-artifacts" {
+		t.Errorf("data sources should be included: got %+v", refs[1])
+	}
+}
+
+func TestParseTFState_V3(t *testing.T) {
+	state := `{"version":3,"modules":[{"resources":{"aws_s3_bucket.old":{"type":"aws_s3_bucket","primary":{"attributes":{"bucket":"legacy-bucket"}}}}}]}`
+	refs, err := parseTFState([]byte(state), "old.tfstate")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(refs) != 1 || refs[0].Bucket != "legacy-bucket" || refs[0].Line != 1 {
+		t.Errorf("got %+v", refs)
+	}
+	if _, err := parseTFState([]byte("not json"), "bad.tfstate"); err == nil {
+		t.Error("expected error for invalid state")
+	}
+}
+
+func TestRepoScanner_TFState(t *testing.T) {
+	repo := t.TempDir()
+	if err := os.WriteFile(filepath.Join(repo, "terraform.tfstate"), []byte(tfStateV4), 0644); err != nil {
+		t.Fatal(err)
+	}
+	remote := filepath.Join(t.TempDir(), "remote.json")
+	state := `{"version":4,"resources":[{"mode":"managed","type":"aws_s3_bucket","instances":[{"attributes":{"bucket":"remote-bucket"}}]}]}`
+	if err := os.WriteFile(remote, []byte(state), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	s := NewRepoScanner(repo)
+	s.SetTFStateFiles([]string{remote})
+	refs, err := s.Scan(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	buckets := make(map[string]bool)
+	for _, ref := range refs {
+		buckets[ref.Bucket] = true
+	}
+	for _, want := range []string{"state-only-logs", "shared-artifacts", "remote-bucket"} {
+		if !buckets[want] {
+			t.Errorf("missing %s in %+v", want, refs)
+		}
+	}
+	if s.FilesScanned() != 2 {
+		t.Errorf("FilesScanned() = %d, want 2", s.FilesScanned())
+	}
+
+	s.SetTFStateFiles([]string{filepath.Join(repo, "missing.tfstate")})
+	if _, err := s.Scan(context.Background()); err == nil {
+		t.Error("expected error for a missing --tf-state file")
+	}
+}
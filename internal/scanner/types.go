@@ -1,6 +1,7 @@
 package scanner
 
-// Reference represents an S3 bucket/prefix reference found in code
+// Reference represents an S3 bucket/prefix reference found in code, or a
+// bucket or container of another object store
 type Reference struct {
 	Bucket    string `json:"bucket"`
 	Prefix    string `json:"prefix,omitempty"`
@@ -8,6 +9,17 @@ type Reference struct {
 	File      string `json:"file"`
 	Line      int    `json:"line"`
 	Context   string `json:"context,omitempty"` // e.g., "read", "write", "list"
+	// OutpostsARN is set for S3 on Outposts bucket ARNs
+	OutpostsARN string `json:"outposts_arn,omitempty"`
+	// Workflow names the GitHub workflow, GitLab job or Jenkins stage of a
+	// reference found in CI configuration, the kind and name of a
+	// Kubernetes object, or the Helm chart of a values file
+	Workflow string `json:"workflow,omitempty"`
+	// Blame is set with --blame: the last commit that changed the line
+	Blame *Blame `json:"blame,omitempty"`
+	// Provider is empty for S3, or ProviderGCS or ProviderAzure for
+	// references to other object stores, which are not checked
+	Provider string `json:"provider,omitempty"`
 }
 
 // RefType represents the type of S3 operation
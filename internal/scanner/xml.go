@@ -0,0 +1,193 @@
+package scanner
+
+import (
+	"bytes"
+	"encoding/xml"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// xmlContext is the context of XML references with no known operation
+const xmlContext = "xml"
+
+// xmlKeyAttrs name the attributes that hold a Spring property or map key,
+// as in <property name="bucketName" value="assets"/>
+var xmlKeyAttrs = map[string]bool{"name": true, "key": true, "id": true, "property": true}
+
+// xmlElement is an open element while scanning an XML file
+type xmlElement struct {
+	name string
+	// bucketKey is set when the element names a bucket, by its own name
+	// (<s3BucketName>) or by a key attribute (<entry key="bucket">)
+	bucketKey bool
+	line      int
+	text      strings.Builder
+	// children are the refs found in direct children that name a bucket,
+	// which take the prefix set by a sibling such as <s3KeyPrefix>
+	children []int
+	prefix   string
+}
+
+// scanXML scans XML files such as Spring bean definitions, logback
+// configuration and Maven POMs and settings for S3 URLs and for bucket names
+// in elements or attributes that name a bucket: <s3BucketName>logs</...>,
+// bucketName="assets", or <property name="bucket" value="assets"/>. A
+// sibling element or property whose name contains "prefix" sets the
+// bucket's prefix.
+// Buckets of logback appenders and Maven distributionManagement are writes;
+// Maven repositories are reads. Comments are skipped, and a file that stops
+// parsing keeps the references found before the error.
+func scanXML(filePath string) ([]Reference, error) {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	dec := xml.NewDecoder(bytes.NewReader(data))
+	dec.Strict = false
+	dec.Entity = xml.HTMLEntity
+
+	var refs []Reference
+	var stack []*xmlElement
+	// found dedupes buckets by line
+	found := make(map[string]bool)
+	add := func(ref Reference) int {
+		key := ref.Bucket + "|" + ref.Prefix + "|" + strconv.Itoa(ref.Line)
+		if found[key] {
+			return -1
+		}
+		found[key] = true
+		ref.File = filePath
+		ref.Context = xmlRefContext(stack)
+		refs = append(refs, ref)
+		return len(refs) - 1
+	}
+	// urls adds the S3 URLs in text that starts on line
+	urls := func(text string, line int) {
+		for _, m := range s3URLPattern.FindAllStringSubmatchIndex(text, -1) {
+			if !completeBucket(text, m[3]) {
+				continue
+			}
+			ref := Reference{Bucket: text[m[2]:m[3]], Line: line + strings.Count(text[:m[0]], "\n")}
+			if m[4] >= 0 {
+				ref.Prefix = text[m[4]:m[5]]
+			}
+			add(ref)
+		}
+		for _, m := range s3HTTPPattern.FindAllStringSubmatchIndex(text, -1) {
+			ref := Reference{Bucket: text[m[2]:m[3]], Line: line + strings.Count(text[:m[0]], "\n")}
+			if m[6] >= 0 {
+				ref.Prefix = text[m[6]:m[7]]
+			}
+			add(ref)
+		}
+	}
+	// names adds the bucket names in a value that names a bucket
+	names := func(value string, line int, parent *xmlElement) {
+		for _, name := range configValues(value) {
+			if !bucketListNamePattern.MatchString(name) {
+				continue
+			}
+			if i := add(Reference{Bucket: name, Line: line}); i >= 0 && parent != nil {
+				parent.children = append(parent.children, i)
+			}
+		}
+	}
+
+	for {
+		line, _ := dec.InputPos()
+		tok, err := dec.Token()
+		if err != nil {
+			break
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			line, _ = dec.InputPos()
+			el := &xmlElement{name: strings.ToLower(t.Name.Local), line: line}
+			el.bucketKey = namesBucket(el.name)
+			var value string
+			hasValue, prefixKey := false, false
+			for _, attr := range t.Attr {
+				attrName := strings.ToLower(attr.Name.Local)
+				urls(attr.Value, line)
+				switch {
+				case attrName == "value":
+					value, hasValue = attr.Value, true
+				case xmlKeyAttrs[attrName] && namesBucket(attr.Value):
+					el.bucketKey = true
+				case xmlKeyAttrs[attrName] && strings.Contains(strings.ToLower(attr.Value), "prefix"):
+					prefixKey = true
+				case namesBucket(attrName):
+					names(attr.Value, line, nil)
+				}
+			}
+			stack = append(stack, el)
+			parent := parentOf(stack)
+			switch {
+			case el.bucketKey && hasValue:
+				names(value, line, parent)
+				// The value attribute is the bucket; any text is not
+				el.bucketKey = false
+			case prefixKey && hasValue && parent != nil && !strings.Contains(value, "${"):
+				// <property name="keyPrefix" value="logs/"/>
+				parent.prefix = strings.TrimPrefix(value, "/")
+			}
+		case xml.CharData:
+			text := string(t)
+			urls(text, line)
+			if len(stack) > 0 {
+				stack[len(stack)-1].text.WriteString(text)
+			}
+		case xml.EndElement:
+			if len(stack) == 0 {
+				continue
+			}
+			el := stack[len(stack)-1]
+			parent := parentOf(stack)
+			stack = stack[:len(stack)-1]
+			text := strings.TrimSpace(el.text.String())
+			switch {
+			case el.bucketKey:
+				names(text, el.line, parent)
+			case el.name == "value" && parent != nil && parent.bucketKey:
+				// <property name="bucket"><value>assets</value></property>
+				names(text, el.line, parentOf(stack))
+				parent.bucketKey = false
+			case strings.Contains(el.name, "prefix") && parent != nil && !strings.Contains(text, "${"):
+				parent.prefix = strings.TrimPrefix(text, "/")
+			}
+			if el.prefix != "" {
+				for _, i := range el.children {
+					if refs[i].Prefix == "" {
+						refs[i].Prefix = el.prefix
+					}
+				}
+			}
+		}
+	}
+	return refs, nil
+}
+
+// parentOf returns the parent of the innermost open element, or nil
+func parentOf(stack []*xmlElement) *xmlElement {
+	if len(stack) < 2 {
+		return nil
+	}
+	return stack[len(stack)-2]
+}
+
+// xmlRefContext infers the operation of a reference from its enclosing
+// elements: logback appenders and Maven distributionManagement write, Maven
+// repositories read
+func xmlRefContext(stack []*xmlElement) string {
+	for k := len(stack) - 1; k >= 0; k-- {
+		switch stack[k].name {
+		case "appender", "distributionmanagement":
+			return string(RefTypeWrite)
+		case "repositories", "pluginrepositories":
+			return string(RefTypeRead)
+		}
+	}
+	return xmlContext
+}
@@ -0,0 +1,116 @@
+package scanner
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRepoScanner_XMLFiles(t *testing.T) {
+	tmpDir := t.TempDir()
+	files := map[string]string{
+		"src/main/resources/applicationContext.xml": `<?xml version="1.0" encoding="UTF-8"?>
+<beans xmlns="http://www.springframework.org/schema/beans">
+  <bean id="uploader" class="com.example.Uploader">
+    <property name="bucketName" value="${UPLOAD_BUCKET:spring-uploads}"/>
+    <property name="keyPrefix" value="/incoming/"/>
+    <property name="archiveBucket">
+      <value>spring-archive</value>
+    </property>
+    <property name="source" value="s3://spring-source/daily/"/>
+  </bean>
+  <!-- <property name="bucketName" value="commented-bucket"/> -->
+</beans>
+`,
+		"src/main/resources/logback.xml": `<configuration>
+  <appender name="S3" class="ch.qos.logback.s3.S3Appender">
+    <s3BucketName>app-logs</s3BucketName>
+    <s3KeyPrefix>service/</s3KeyPrefix>
+    <bucketRegion>eu-central-1</bucketRegion>
+    <encoder bucketPolicy="policy-doc"/>
+  </appender>
+</configuration>
+`,
+		"pom.xml": `<project>
+  <distributionManagement>
+    <repository>
+      <id>releases</id>
+      <url>s3://maven-releases/release</url>
+    </repository>
+  </distributionManagement>
+  <repositories>
+    <repository>
+      <url>https://maven-mirror.s3.amazonaws.com/mirror</url>
+    </repository>
+  </repositories>
+  <properties>
+    <deploy.bucket>pom-artifacts</deploy.bucket>
+  </properties>
+</project>
+`,
+	}
+	for name, content := range files {
+		path := filepath.Join(tmpDir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to create test file %s: %v", name, err)
+		}
+	}
+
+	refs, err := NewRepoScanner(tmpDir).Scan(context.Background())
+	if err != nil {
+		t.Fatalf("Scan failed: %v", err)
+	}
+	got := make(map[string]Reference)
+	for _, ref := range refs {
+		got[ref.Bucket] = ref
+	}
+	tests := []struct {
+		bucket, prefix, context string
+		line                    int
+	}{
+		{"spring-uploads", "incoming/", xmlContext, 4},
+		{"spring-archive", "incoming/", xmlContext, 7},
+		{"spring-source", "daily/", xmlContext, 9},
+		{"app-logs", "service/", "write", 3},
+		{"maven-releases", "release", "write", 5},
+		{"maven-mirror", "mirror", "read", 10},
+		{"pom-artifacts", "", xmlContext, 14},
+	}
+	for _, tt := range tests {
+		ref, ok := got[tt.bucket]
+		if !ok {
+			t.Errorf("missing bucket %s in %v", tt.bucket, refs)
+			continue
+		}
+		if ref.Prefix != tt.prefix || ref.Context != tt.context || ref.Line != tt.line {
+			t.Errorf("%s = %+v, want prefix %q, context %q, line %d", tt.bucket, ref, tt.prefix, tt.context, tt.line)
+		}
+	}
+	if _, ok := got["commented-bucket"]; ok {
+		t.Errorf("bucket in a comment should be skipped")
+	}
+	for _, bucket := range []string{"eu-central-1", "policy-doc"} {
+		if _, ok := got[bucket]; ok {
+			t.Errorf("unexpected bucket %s from a key that does not name a bucket", bucket)
+		}
+	}
+}
+
+func TestScanXML_Malformed(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "broken.xml")
+	content := "<config>\n  <bucket>early-bucket</bucket>\n  <unclosed attr=\"\n"
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	refs, err := scanXML(path)
+	if err != nil {
+		t.Fatalf("scanXML failed: %v", err)
+	}
+	if len(refs) != 1 || refs[0].Bucket != "early-bucket" {
+		t.Errorf("refs = %+v, want the bucket before the error", refs)
+	}
+}
@@ -6,6 +6,11 @@ import (
 	"regexp"
 )
 
+var (
+	// YAML-specific bucket patterns
+	yamlBucketPattern = regexp.MustCompile(`(?i)(?:bucket|s3_bucket|s3Bucket):\s*['"]?([a-z0-9][a-z0-9\-\.]{1,61}[a-z0-9])['"]?`)
+)
+
 // scanYAML scans YAML files for S3 bucket references
 func scanYAML(filePath string) ([]Reference, error) {
 	file, err := os.Open(filePath)
@@ -18,37 +23,9 @@ func scanYAML(filePath string) ([]Reference, error) {
 	scanner := bufio.NewScanner(file)
 	lineNum := 0
 
-	// YAML-specific bucket patterns
-	yamlBucketPattern := regexp.MustCompile(`(?i)(?:bucket|s3_bucket|s3Bucket):\s*['"]?([a-z0-9][a-z0-9\-\.]{1,61}[a-z0-9])['"]?`)
-
 	for scanner.Scan() {
 		lineNum++
-		line := scanner.Text()
-
-		// Check for s3:// URLs
-		if matches := s3URLPattern.FindAllStringSubmatch(line, -1); matches != nil {
-			for _, match := range matches {
-				refs = append(refs, Reference{
-					Bucket:  match[1],
-					Prefix:  match[2],
-					File:    filePath,
-					Line:    lineNum,
-					Context: "yaml",
-				})
-			}
-		}
-
-		// Check for bucket: field
-		if matches := yamlBucketPattern.FindAllStringSubmatch(line, -1); matches != nil {
-			for _, match := range matches {
-				refs = append(refs, Reference{
-					Bucket:  match[1],
-					File:    filePath,
-					Line:    lineNum,
-					Context: "yaml",
-				})
-			}
-		}
+		refs = append(refs, yamlLineRefs(scanner.Text(), filePath, lineNum)...)
 	}
 
 	if err := scanner.Err(); err != nil {
@@ -57,3 +34,35 @@ func scanYAML(filePath string) ([]Reference, error) {
 
 	return refs, nil
 }
+
+// yamlLineRefs finds the S3 references on a line of YAML
+func yamlLineRefs(line, filePath string, lineNum int) []Reference {
+	// Check for S3 on Outposts bucket ARNs
+	refs := outpostsRefs(line, filePath, lineNum, "yaml")
+
+	// Check for s3://, s3a:// and s3n:// URLs
+	if matches := s3URLPattern.FindAllStringSubmatch(line, -1); matches != nil {
+		for _, match := range matches {
+			refs = append(refs, Reference{
+				Bucket:  match[1],
+				Prefix:  match[2],
+				File:    filePath,
+				Line:    lineNum,
+				Context: "yaml",
+			})
+		}
+	}
+
+	// Check for bucket: field
+	if matches := yamlBucketPattern.FindAllStringSubmatch(line, -1); matches != nil {
+		for _, match := range matches {
+			refs = append(refs, Reference{
+				Bucket:  match[1],
+				File:    filePath,
+				Line:    lineNum,
+				Context: "yaml",
+			})
+		}
+	}
+	return refs
+}
@@ -0,0 +1,269 @@
+package site
+
+import (
+	"fmt"
+	"html/template"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ppiankov/s3spectre/internal/baseline"
+	"github.com/ppiankov/s3spectre/internal/history"
+	"github.com/ppiankov/s3spectre/internal/rules"
+)
+
+// severities are listed most severe first, as shown in tables and the chart legend
+var severities = rules.Severities()
+
+var severityColors = map[string]string{
+	"high":   "#e05d44",
+	"medium": "#fe7d37",
+	"low":    "#dfb317",
+	"info":   "#9f9f9f",
+}
+
+const (
+	chartWidth   = 720
+	chartHeight  = 200
+	chartPadding = 30
+)
+
+type findingRow struct {
+	Severity string
+	Type     string
+	Bucket   string
+	Prefix   string
+	Page     string
+}
+
+type series struct {
+	Severity string
+	Color    string
+	Points   string
+	Latest   int
+}
+
+type snapshotRow struct {
+	Name      string
+	Kind      string
+	Timestamp string
+	Counts    map[string]int
+	Total     int
+}
+
+type bucketLink struct {
+	Name     string
+	Page     string
+	Findings int
+}
+
+type indexPage struct {
+	Generated  string
+	Latest     *snapshotRow
+	Severities []string
+	Series     []series
+	Width      int
+	Height     int
+	Findings   []findingRow
+	Snapshots  []snapshotRow
+	Buckets    []bucketLink
+}
+
+type bucketHistoryRow struct {
+	Timestamp string
+	Findings  []findingRow
+}
+
+type bucketPage struct {
+	Name    string
+	Current []findingRow
+	History []bucketHistoryRow
+}
+
+// Generate renders history snapshots into a static dashboard under outDir:
+// index.html with a findings trend chart and the latest findings, and one
+// page per bucket under buckets/ with its finding history
+func Generate(snapshots []history.Snapshot, outDir string) error {
+	if len(snapshots) == 0 {
+		return fmt.Errorf("history is empty")
+	}
+	if err := os.MkdirAll(filepath.Join(outDir, "buckets"), 0755); err != nil {
+		return fmt.Errorf("create output directory: %w", err)
+	}
+
+	latest := snapshots[len(snapshots)-1]
+	rows := make([]snapshotRow, 0, len(snapshots))
+	for _, s := range snapshots {
+		rows = append(rows, newSnapshotRow(s))
+	}
+
+	bucketNames := make(map[string]bool)
+	for _, s := range snapshots {
+		for _, f := range s.Findings {
+			if f.Bucket != "" {
+				bucketNames[f.Bucket] = true
+			}
+		}
+	}
+	current := countByBucket(latest.Findings)
+
+	index := indexPage{
+		Generated:  time.Now().UTC().Format(time.RFC3339),
+		Latest:     &rows[len(rows)-1],
+		Severities: severities,
+		Series:     trendSeries(rows),
+		Width:      chartWidth,
+		Height:     chartHeight,
+		Findings:   findingRows(latest.Findings, "buckets/"),
+	}
+	for i := len(rows) - 1; i >= 0; i-- {
+		index.Snapshots = append(index.Snapshots, rows[i])
+	}
+	for name := range bucketNames {
+		index.Buckets = append(index.Buckets, bucketLink{Name: name, Page: "buckets/" + pageName(name), Findings: current[name]})
+	}
+	sort.Slice(index.Buckets, func(i, j int) bool {
+		if index.Buckets[i].Findings != index.Buckets[j].Findings {
+			return index.Buckets[i].Findings > index.Buckets[j].Findings
+		}
+		return index.Buckets[i].Name < index.Buckets[j].Name
+	})
+
+	if err := render(filepath.Join(outDir, "index.html"), indexTemplate, index); err != nil {
+		return err
+	}
+
+	for name := range bucketNames {
+		page := bucketPage{Name: name}
+		for i := len(snapshots) - 1; i >= 0; i-- {
+			findings := findingRows(bucketFindings(snapshots[i].Findings, name), "")
+			if i == len(snapshots)-1 {
+				page.Current = findings
+			}
+			page.History = append(page.History, bucketHistoryRow{Timestamp: rows[i].Timestamp, Findings: findings})
+		}
+		if err := render(filepath.Join(outDir, "buckets", pageName(name)), bucketTemplate, page); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func newSnapshotRow(s history.Snapshot) snapshotRow {
+	row := snapshotRow{
+		Name:      s.Name,
+		Kind:      s.Kind,
+		Timestamp: s.Timestamp.UTC().Format("2006-01-02 15:04"),
+		Counts:    make(map[string]int),
+	}
+	for _, f := range s.Findings {
+		if sev := f.EffectiveSeverity(); sev != "" {
+			row.Counts[sev]++
+			row.Total++
+		}
+	}
+	return row
+}
+
+// trendSeries plots per-severity finding counts across snapshots as SVG polylines
+func trendSeries(rows []snapshotRow) []series {
+	maxCount := 1
+	for _, r := range rows {
+		for _, sev := range severities {
+			maxCount = max(maxCount, r.Counts[sev])
+		}
+	}
+
+	plotWidth := float64(chartWidth - 2*chartPadding)
+	plotHeight := float64(chartHeight - 2*chartPadding)
+	out := make([]series, 0, len(severities))
+	for _, sev := range severities {
+		points := make([]string, 0, len(rows))
+		for i, r := range rows {
+			x := float64(chartPadding) + plotWidth/2
+			if len(rows) > 1 {
+				x = float64(chartPadding) + plotWidth*float64(i)/float64(len(rows)-1)
+			}
+			y := float64(chartHeight-chartPadding) - plotHeight*float64(r.Counts[sev])/float64(maxCount)
+			points = append(points, fmt.Sprintf("%.1f,%.1f", x, y))
+		}
+		out = append(out, series{
+			Severity: sev,
+			Color:    severityColors[sev],
+			Points:   strings.Join(points, " "),
+			Latest:   rows[len(rows)-1].Counts[sev],
+		})
+	}
+	return out
+}
+
+func findingRows(findings []baseline.Finding, pagePrefix string) []findingRow {
+	rows := make([]findingRow, 0, len(findings))
+	for _, f := range findings {
+		row := findingRow{
+			Severity: f.EffectiveSeverity(),
+			Type:     f.Type,
+			Bucket:   f.Bucket,
+			Prefix:   f.Prefix,
+		}
+		// Account-level findings have no bucket page to link to
+		if f.Bucket != "" {
+			row.Page = pagePrefix + pageName(f.Bucket)
+		}
+		rows = append(rows, row)
+	}
+	sort.Slice(rows, func(i, j int) bool {
+		ri, rj := rules.Rank(rows[i].Severity), rules.Rank(rows[j].Severity)
+		if ri != rj {
+			return ri > rj
+		}
+		if rows[i].Bucket != rows[j].Bucket {
+			return rows[i].Bucket < rows[j].Bucket
+		}
+		if rows[i].Type != rows[j].Type {
+			return rows[i].Type < rows[j].Type
+		}
+		return rows[i].Prefix < rows[j].Prefix
+	})
+	return rows
+}
+
+func bucketFindings(findings []baseline.Finding, bucket string) []baseline.Finding {
+	var out []baseline.Finding
+	for _, f := range findings {
+		if f.Bucket == bucket {
+			out = append(out, f)
+		}
+	}
+	return out
+}
+
+func countByBucket(findings []baseline.Finding) map[string]int {
+	counts := make(map[string]int)
+	for _, f := range findings {
+		counts[f.Bucket]++
+	}
+	return counts
+}
+
+var unsafePageChars = regexp.MustCompile(`[^a-zA-Z0-9._-]`)
+
+// pageName maps a non-empty bucket name to its page file name
+func pageName(bucket string) string {
+	return unsafePageChars.ReplaceAllString(bucket, "_") + ".html"
+}
+
+func render(path string, tmpl *template.Template, data interface{}) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("create %s: %w", path, err)
+	}
+	if err := tmpl.Execute(f, data); err != nil {
+		_ = f.Close()
+		return fmt.Errorf("render %s: %w", path, err)
+	}
+	return f.Close()
+}
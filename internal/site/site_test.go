@@ -0,0 +1,90 @@
+package site
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ppiankov/s3spectre/internal/baseline"
+	"github.com/ppiankov/s3spectre/internal/history"
+)
+
+func TestGenerate(t *testing.T) {
+	day := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	snapshots := []history.Snapshot{
+		{Name: "day1", Kind: "scan", Timestamp: day, Findings: []baseline.Finding{
+			{Type: "MISSING_BUCKET", Bucket: "gone"},
+			{Type: "STALE_PREFIX", Bucket: "logs", Prefix: "old/"},
+		}},
+		{Name: "day2", Kind: "scan", Timestamp: day.Add(24 * time.Hour), Findings: []baseline.Finding{
+			{Type: "STALE_PREFIX", Bucket: "logs", Prefix: "old/"},
+		}},
+	}
+
+	out := t.TempDir()
+	if err := Generate(snapshots, out); err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+
+	index, err := os.ReadFile(filepath.Join(out, "index.html"))
+	if err != nil {
+		t.Fatalf("read index: %v", err)
+	}
+	html := string(index)
+	for _, want := range []string{"<polyline", `href="buckets/logs.html"`, "STALE_PREFIX", "day1", "day2"} {
+		if !strings.Contains(html, want) {
+			t.Errorf("index.html missing %q", want)
+		}
+	}
+	if strings.Contains(html, `<td>MISSING_BUCKET</td>`) {
+		t.Error("resolved finding should not be listed as current")
+	}
+
+	page, err := os.ReadFile(filepath.Join(out, "buckets", "gone.html"))
+	if err != nil {
+		t.Fatalf("read bucket page: %v", err)
+	}
+	if !strings.Contains(string(page), "No findings in the latest report") || !strings.Contains(string(page), "MISSING_BUCKET") {
+		t.Errorf("bucket page should show resolved finding in history only:\n%s", page)
+	}
+}
+
+func TestGenerate_EmptyHistory(t *testing.T) {
+	if err := Generate(nil, t.TempDir()); err == nil {
+		t.Fatal("expected error for empty history")
+	}
+}
+
+func TestPageName(t *testing.T) {
+	if got := pageName("my.bucket-1"); got != "my.bucket-1.html" {
+		t.Errorf("pageName = %q", got)
+	}
+	if got := pageName("../evil"); strings.Contains(got, "/") {
+		t.Errorf("pageName must not contain path separators: %q", got)
+	}
+}
+
+func TestGenerate_AccountFindingHasNoBucketPage(t *testing.T) {
+	snapshots := []history.Snapshot{
+		{Name: "day1", Kind: "discover", Timestamp: time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC), Findings: []baseline.Finding{
+			{Type: "NO_ACCOUNT_PAB"},
+		}},
+	}
+
+	out := t.TempDir()
+	if err := Generate(snapshots, out); err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(out, "buckets", ".html")); !os.IsNotExist(err) {
+		t.Errorf("expected no page for an empty bucket name, got %v", err)
+	}
+	index, err := os.ReadFile(filepath.Join(out, "index.html"))
+	if err != nil {
+		t.Fatalf("read index: %v", err)
+	}
+	if strings.Contains(string(index), `href="buckets/.html"`) {
+		t.Error("account-level finding should not link to a bucket page")
+	}
+}
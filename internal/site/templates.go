@@ -0,0 +1,80 @@
+package site
+
+import "html/template"
+
+const pageStyle = `<style>
+body{font-family:-apple-system,"Segoe UI",Helvetica,Arial,sans-serif;margin:2rem auto;max-width:960px;color:#24292f;padding:0 1rem}
+h1,h2{font-weight:600}
+table{border-collapse:collapse;width:100%;margin-bottom:2rem}
+th,td{text-align:left;padding:.35rem .6rem;border-bottom:1px solid #d0d7de;font-size:.9rem}
+th{background:#f6f8fa}
+.sev{display:inline-block;padding:0 .4rem;border-radius:3px;color:#fff;font-size:.8rem}
+.sev-high{background:#e05d44}.sev-medium{background:#fe7d37}.sev-low{background:#dfb317}.sev-info{background:#9f9f9f}
+.muted{color:#57606a;font-size:.85rem}
+.legend span{margin-right:1rem}
+</style>`
+
+var indexTemplate = template.Must(template.New("index").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>S3Spectre dashboard</title>
+` + pageStyle + `
+</head>
+<body>
+<h1>S3Spectre dashboard</h1>
+<p class="muted">Latest report {{.Latest.Timestamp}} UTC ({{.Latest.Kind}}) &middot; generated {{.Generated}}</p>
+
+<h2>Findings trend</h2>
+<svg xmlns="http://www.w3.org/2000/svg" width="{{.Width}}" height="{{.Height}}" role="img" aria-label="Findings by severity over time">
+<rect width="{{.Width}}" height="{{.Height}}" fill="#fff" stroke="#d0d7de"/>
+{{range .Series}}<polyline fill="none" stroke="{{.Color}}" stroke-width="2" points="{{.Points}}"/>
+{{end}}</svg>
+<p class="legend">{{range .Series}}<span style="color:{{.Color}}">&#9632; {{.Severity}}: {{.Latest}}</span>{{end}}</p>
+
+<h2>Current findings ({{.Latest.Total}})</h2>
+{{if .Findings}}<table>
+<tr><th>Severity</th><th>Type</th><th>Bucket</th><th>Prefix</th></tr>
+{{range .Findings}}<tr><td><span class="sev sev-{{.Severity}}">{{.Severity}}</span></td><td>{{.Type}}</td><td>{{if .Page}}<a href="{{.Page}}">{{.Bucket}}</a>{{end}}</td><td>{{.Prefix}}</td></tr>
+{{end}}</table>{{else}}<p>No findings.</p>{{end}}
+
+<h2>Buckets</h2>
+{{if .Buckets}}<table>
+<tr><th>Bucket</th><th>Current findings</th></tr>
+{{range .Buckets}}<tr><td><a href="{{.Page}}">{{.Name}}</a></td><td>{{.Findings}}</td></tr>
+{{end}}</table>{{else}}<p>No bucket has had findings.</p>{{end}}
+
+<h2>Reports</h2>
+<table>
+<tr><th>Report</th><th>Time (UTC)</th><th>Kind</th>{{range $.Severities}}<th>{{.}}</th>{{end}}<th>Total</th></tr>
+{{range .Snapshots}}{{$counts := .Counts}}<tr><td>{{.Name}}</td><td>{{.Timestamp}}</td><td>{{.Kind}}</td>{{range $.Severities}}<td>{{index $counts .}}</td>{{end}}<td>{{.Total}}</td></tr>
+{{end}}</table>
+</body>
+</html>
+`))
+
+var bucketTemplate = template.Must(template.New("bucket").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>{{.Name}} - S3Spectre</title>
+` + pageStyle + `
+</head>
+<body>
+<p><a href="../index.html">&larr; Dashboard</a></p>
+<h1>{{.Name}}</h1>
+
+<h2>Current findings</h2>
+{{if .Current}}<table>
+<tr><th>Severity</th><th>Type</th><th>Prefix</th></tr>
+{{range .Current}}<tr><td><span class="sev sev-{{.Severity}}">{{.Severity}}</span></td><td>{{.Type}}</td><td>{{.Prefix}}</td></tr>
+{{end}}</table>{{else}}<p>No findings in the latest report.</p>{{end}}
+
+<h2>History</h2>
+<table>
+<tr><th>Time (UTC)</th><th>Findings</th></tr>
+{{range .History}}<tr><td>{{.Timestamp}}</td><td>{{range .Findings}}<span class="sev sev-{{.Severity}}">{{.Type}}{{if .Prefix}} {{.Prefix}}{{end}}</span> {{else}}&ndash;{{end}}</td></tr>
+{{end}}</table>
+</body>
+</html>
+`))
@@ -0,0 +1,141 @@
+package spectre
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Schema is the envelope schema shared by the Spectre-family tools
+const Schema = "spectre/v1"
+
+// Envelope is a spectre/v1 report written by a Spectre-family tool such as
+// iamspectre or dynamospectre
+type Envelope struct {
+	Schema    string    `json:"schema"`
+	Tool      string    `json:"tool"`
+	Version   string    `json:"version"`
+	Timestamp string    `json:"timestamp"`
+	Findings  []Finding `json:"findings"`
+}
+
+// Finding is one finding of an envelope. Location and metadata are the
+// tool's own; buckets are recognized in them by ARN, s3:// URI or a
+// metadata key naming a bucket.
+type Finding struct {
+	ID          string         `json:"id"`
+	Severity    string         `json:"severity"`
+	Location    string         `json:"location"`
+	Message     string         `json:"message"`
+	Fingerprint string         `json:"fingerprint"`
+	Metadata    map[string]any `json:"metadata,omitempty"`
+}
+
+var (
+	// bucketARN is an S3 bucket or object ARN; the bucket may be a wildcard
+	// pattern, which is not matched
+	bucketARN = regexp.MustCompile(`arn:aws[a-z-]*:s3:::([a-z0-9][a-z0-9.-]{1,61}[a-z0-9])(?:[/"'\s,\]]|$)`)
+	// bucketURI is an s3:// URI
+	bucketURI = regexp.MustCompile(`s3://([a-z0-9][a-z0-9.-]{1,61}[a-z0-9])(?:[/"'\s,\]]|$)`)
+	// bucketName is a plain bucket name under a bucket metadata key
+	bucketName = regexp.MustCompile(`^[a-z0-9][a-z0-9.-]{1,61}[a-z0-9]$`)
+)
+
+// Load reads a spectre/v1 envelope
+func Load(path string) (*Envelope, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read envelope: %w", err)
+	}
+	var envelope Envelope
+	if err := json.Unmarshal(raw, &envelope); err != nil {
+		return nil, fmt.Errorf("parse envelope %s: %w", path, err)
+	}
+	if envelope.Schema != Schema {
+		return nil, fmt.Errorf("%s: schema %q is not %s", path, envelope.Schema, Schema)
+	}
+	if envelope.Tool == "" {
+		return nil, fmt.Errorf("%s: envelope names no tool", path)
+	}
+	return &envelope, nil
+}
+
+// Buckets returns the buckets a finding refers to, sorted
+func (f Finding) Buckets() []string {
+	found := make(map[string]bool)
+	match := func(s string) {
+		for _, re := range []*regexp.Regexp{bucketARN, bucketURI} {
+			for _, m := range re.FindAllStringSubmatch(s, -1) {
+				found[m[1]] = true
+			}
+		}
+	}
+	match(f.Location)
+	match(f.Message)
+	for key, value := range f.Metadata {
+		named := strings.Contains(strings.ToLower(key), "bucket")
+		for _, s := range metadataStrings(value) {
+			match(s)
+			if named && bucketName.MatchString(s) {
+				found[s] = true
+			}
+		}
+	}
+
+	buckets := make([]string, 0, len(found))
+	for bucket := range found {
+		buckets = append(buckets, bucket)
+	}
+	sort.Strings(buckets)
+	return buckets
+}
+
+// Actions returns the actions listed under the finding's action or actions
+// metadata key, sorted
+func (f Finding) Actions() []string {
+	var actions []string
+	for _, key := range []string{"action", "actions"} {
+		actions = append(actions, metadataStrings(f.Metadata[key])...)
+	}
+	sort.Strings(actions)
+	return actions
+}
+
+// metadataStrings flattens the strings of a metadata value, including those
+// nested in lists and objects
+func metadataStrings(value any) []string {
+	switch v := value.(type) {
+	case string:
+		return []string{v}
+	case []any:
+		var out []string
+		for _, item := range v {
+			out = append(out, metadataStrings(item)...)
+		}
+		return out
+	case map[string]any:
+		var out []string
+		for _, item := range v {
+			out = append(out, metadataStrings(item)...)
+		}
+		return out
+	}
+	return nil
+}
+
+// IsWrite reports whether an IAM action can write to or delete from S3
+func IsWrite(action string) bool {
+	action = strings.ToLower(strings.TrimSpace(action))
+	if action == "*" || action == "s3:*" {
+		return true
+	}
+	for _, prefix := range []string{"s3:put", "s3:delete", "s3:abortmultipartupload", "s3:replicate", "s3:restoreobject"} {
+		if strings.HasPrefix(action, prefix) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,108 @@
+package spectre
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestLoad(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+
+	envelope, err := Load(write("iam.json", `{"schema": "spectre/v1", "tool": "iamspectre", "version": "0.4.0",
+		"findings": [{"id": "STALE_ROLE", "severity": "medium", "location": "arn:aws:iam::111122223333:role/etl"}]}`))
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if envelope.Tool != "iamspectre" || len(envelope.Findings) != 1 || envelope.Findings[0].ID != "STALE_ROLE" {
+		t.Errorf("envelope = %+v", envelope)
+	}
+
+	for name, content := range map[string]string{
+		"sarif.json":   `{"version": "2.1.0", "runs": []}`,
+		"notool.json":  `{"schema": "spectre/v1", "findings": []}`,
+		"invalid.json": `{"schema": `,
+	} {
+		if _, err := Load(write(name, content)); err == nil {
+			t.Errorf("Load(%s) should fail", name)
+		}
+	}
+	if _, err := Load(filepath.Join(dir, "missing.json")); err == nil || !strings.Contains(err.Error(), "read envelope") {
+		t.Errorf("Load of a missing file = %v", err)
+	}
+}
+
+func TestFinding_Buckets(t *testing.T) {
+	tests := []struct {
+		name    string
+		finding Finding
+		want    []string
+	}{
+		{
+			name: "policy resources",
+			finding: Finding{Location: "arn:aws:iam::111122223333:role/etl", Metadata: map[string]any{
+				"resources": []any{"arn:aws:s3:::orders-archive", "arn:aws:s3:::orders-archive/*", "arn:aws:s3:::logs-*"},
+			}},
+			want: []string{"orders-archive"},
+		},
+		{
+			name: "export URI in the message",
+			finding: Finding{Location: "arn:aws:dynamodb:us-east-1:111122223333:table/orders",
+				Message: "table exports to s3://orders-exports/daily/ every night"},
+			want: []string{"orders-exports"},
+		},
+		{
+			name: "bucket metadata key",
+			finding: Finding{Location: "orders", Metadata: map[string]any{
+				"s3_bucket": "orders-exports", "export": map[string]any{"bucket": "Not A Bucket"},
+			}},
+			want: []string{"orders-exports"},
+		},
+		{
+			name:    "no buckets",
+			finding: Finding{Location: "arn:aws:iam::111122223333:role/etl", Metadata: map[string]any{"name": "orders-archive"}},
+			want:    []string{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.finding.Buckets(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Buckets() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFinding_Actions(t *testing.T) {
+	f := Finding{Metadata: map[string]any{"actions": []any{"s3:PutObject", "s3:GetObject"}, "action": "s3:ListBucket"}}
+	want := []string{"s3:GetObject", "s3:ListBucket", "s3:PutObject"}
+	if got := f.Actions(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Actions() = %v, want %v", got, want)
+	}
+}
+
+func TestIsWrite(t *testing.T) {
+	for action, want := range map[string]bool{
+		"s3:PutObject":     true,
+		"s3:DeleteObject":  true,
+		"S3:PUTOBJECTACL":  true,
+		"s3:*":             true,
+		"*":                true,
+		"s3:GetObject":     false,
+		"s3:ListBucket":    false,
+		"dynamodb:PutItem": false,
+	} {
+		if got := IsWrite(action); got != want {
+			t.Errorf("IsWrite(%s) = %v, want %v", action, got, want)
+		}
+	}
+}
@@ -0,0 +1,191 @@
+package tracker
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	// DefaultGitHubAPI is the REST API of github.com; GitHub Enterprise
+	// Server uses https://HOST/api/v3
+	DefaultGitHubAPI = "https://api.github.com"
+	// DefaultLabel marks the issues s3spectre files and manages
+	DefaultLabel = "s3spectre"
+	// githubPageSize is the most issues listed per request
+	githubPageSize = 100
+)
+
+// GitHub files issues in one repository through the REST API
+type GitHub struct {
+	Owner  string
+	Repo   string
+	Token  string
+	APIURL string
+	Label  string
+	Client *http.Client
+}
+
+// NewGitHub creates a GitHub issue filer for repo, given as owner/name
+func NewGitHub(repo, token string) (*GitHub, error) {
+	owner, name, ok := strings.Cut(repo, "/")
+	if !ok || owner == "" || name == "" || strings.Contains(name, "/") {
+		return nil, fmt.Errorf("GitHub repository must be owner/name, got %q", repo)
+	}
+	if token == "" {
+		return nil, errors.New("a GitHub token is required")
+	}
+	return &GitHub{
+		Owner:  owner,
+		Repo:   name,
+		Token:  token,
+		APIURL: DefaultGitHubAPI,
+		Label:  DefaultLabel,
+		Client: &http.Client{Timeout: 30 * time.Second},
+	}, nil
+}
+
+type githubIssue struct {
+	Number int    `json:"number"`
+	Title  string `json:"title"`
+	Body   string `json:"body"`
+	// PullRequest is set when the issues API returns a pull request
+	PullRequest json.RawMessage `json:"pull_request,omitempty"`
+}
+
+// Sync opens an issue for each of issues that has none, updates open ones
+// whose findings changed, and, when closeResolved is set, comments on and
+// closes the scope's open issues that no longer have findings. Runs that
+// cover only part of the scope, such as interrupted runs, must not close.
+func (g *GitHub) Sync(ctx context.Context, scope string, issues []Issue, closeResolved bool) (SyncResult, error) {
+	var result SyncResult
+	open, err := g.openIssues(ctx, scope)
+	if err != nil {
+		return result, err
+	}
+	current := make(map[string]bool, len(issues))
+	for _, issue := range issues {
+		current[issue.Key] = true
+		title, body := issue.Title(), issue.Body(scope)
+		existing, ok := open[issue.Key]
+		counter := &result.Unchanged
+		switch {
+		case !ok:
+			err = g.do(ctx, http.MethodPost, g.repoPath("issues"), map[string]any{
+				"title":  title,
+				"body":   body,
+				"labels": []string{g.Label},
+			}, nil)
+			counter = &result.Opened
+		case existing.Title != title || existing.Body != body:
+			err = g.do(ctx, http.MethodPatch, g.repoPath(fmt.Sprintf("issues/%d", existing.Number)), map[string]any{
+				"title": title,
+				"body":  body,
+			}, nil)
+			counter = &result.Updated
+		}
+		if err != nil {
+			return result, fmt.Errorf("filing issue for %s: %w", issue.Resource, err)
+		}
+		*counter++
+	}
+	if !closeResolved {
+		return result, nil
+	}
+	for key, existing := range open {
+		if current[key] {
+			continue
+		}
+		path := fmt.Sprintf("issues/%d", existing.Number)
+		comment := "S3Spectre no longer reports findings on this resource; closing as resolved."
+		if err := g.do(ctx, http.MethodPost, g.repoPath(path+"/comments"), map[string]any{"body": comment}, nil); err != nil {
+			return result, fmt.Errorf("commenting on issue #%d: %w", existing.Number, err)
+		}
+		if err := g.do(ctx, http.MethodPatch, g.repoPath(path), map[string]any{"state": "closed", "state_reason": "completed"}, nil); err != nil {
+			return result, fmt.Errorf("closing issue #%d: %w", existing.Number, err)
+		}
+		result.Closed++
+	}
+	return result, nil
+}
+
+// openIssues lists the scope's open issues by key
+func (g *GitHub) openIssues(ctx context.Context, scope string) (map[string]githubIssue, error) {
+	open := make(map[string]githubIssue)
+	for page := 1; ; page++ {
+		query := url.Values{
+			"state":    {"open"},
+			"labels":   {g.Label},
+			"per_page": {fmt.Sprint(githubPageSize)},
+			"page":     {fmt.Sprint(page)},
+		}
+		var batch []githubIssue
+		if err := g.do(ctx, http.MethodGet, g.repoPath("issues")+"?"+query.Encode(), nil, &batch); err != nil {
+			return nil, fmt.Errorf("listing issues: %w", err)
+		}
+		for _, issue := range batch {
+			if issue.PullRequest != nil {
+				continue
+			}
+			if key, issueScope, ok := parseMarker(issue.Body); ok && issueScope == scope {
+				open[key] = issue
+			}
+		}
+		if len(batch) < githubPageSize {
+			return open, nil
+		}
+	}
+}
+
+func (g *GitHub) repoPath(path string) string {
+	return fmt.Sprintf("/repos/%s/%s/%s", url.PathEscape(g.Owner), url.PathEscape(g.Repo), path)
+}
+
+// do sends a REST API request and decodes the JSON response into out
+func (g *GitHub) do(ctx context.Context, method, path string, in, out any) error {
+	var body io.Reader
+	if in != nil {
+		data, err := json.Marshal(in)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, strings.TrimSuffix(g.APIURL, "/")+path, body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("Authorization", "Bearer "+g.Token)
+	req.Header.Set("X-GitHub-Api-Version", "2022-11-28")
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	resp, err := g.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		var apiErr struct {
+			Message string `json:"message"`
+		}
+		detail, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		if json.Unmarshal(detail, &apiErr) == nil && apiErr.Message != "" {
+			return fmt.Errorf("%s: %s", resp.Status, apiErr.Message)
+		}
+		return errors.New(resp.Status)
+	}
+	if out == nil {
+		_, _ = io.Copy(io.Discard, resp.Body)
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
@@ -0,0 +1,184 @@
+package tracker
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// fakeGitHub serves the issue endpoints Sync uses from memory
+type fakeGitHub struct {
+	mu       sync.Mutex
+	issues   map[int]map[string]any
+	comments map[int][]string
+	next     int
+	token    string
+}
+
+func newFakeGitHub() *fakeGitHub {
+	return &fakeGitHub{issues: make(map[int]map[string]any), comments: make(map[int][]string), next: 1}
+}
+
+func (f *fakeGitHub) add(issue map[string]any) int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	n := f.next
+	f.next++
+	issue["number"] = n
+	if issue["state"] == nil {
+		issue["state"] = "open"
+	}
+	f.issues[n] = issue
+	return n
+}
+
+func (f *fakeGitHub) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	f.token = r.Header.Get("Authorization")
+	f.mu.Unlock()
+	path := strings.TrimPrefix(r.URL.Path, "/repos/acme/infra/issues")
+	var in map[string]any
+	if r.Body != nil {
+		_ = json.NewDecoder(r.Body).Decode(&in)
+	}
+	switch {
+	case r.Method == http.MethodGet && path == "":
+		f.mu.Lock()
+		var open []map[string]any
+		for n := 1; n < f.next; n++ {
+			issue := f.issues[n]
+			if issue["state"] == "open" && issue["label"] == r.URL.Query().Get("labels") {
+				open = append(open, issue)
+			}
+		}
+		f.mu.Unlock()
+		_ = json.NewEncoder(w).Encode(open)
+	case r.Method == http.MethodPost && path == "":
+		labels, _ := in["labels"].([]any)
+		in["label"] = labels[0]
+		f.add(in)
+		w.WriteHeader(http.StatusCreated)
+	default:
+		var n int
+		var rest string
+		if _, err := fmt.Sscanf(path, "/%d%s", &n, &rest); err != nil {
+			rest = ""
+		}
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		issue, ok := f.issues[n]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"message":"Not Found"}`))
+			return
+		}
+		if rest == "/comments" {
+			f.comments[n] = append(f.comments[n], in["body"].(string))
+			return
+		}
+		for k, v := range in {
+			issue[k] = v
+		}
+	}
+}
+
+func testGitHub(t *testing.T, fake *fakeGitHub) *GitHub {
+	t.Helper()
+	srv := httptest.NewServer(fake)
+	t.Cleanup(srv.Close)
+	gh, err := NewGitHub("acme/infra", "secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	gh.APIURL = srv.URL
+	return gh
+}
+
+func TestGitHubSync(t *testing.T) {
+	fake := newFakeGitHub()
+	gh := testGitHub(t, fake)
+	ctx := context.Background()
+	scope := "discover/111111111111"
+
+	// Issues that are not s3spectre's, or belong to another scope, or are
+	// pull requests, are left alone
+	manual := fake.add(map[string]any{"title": "manual", "body": "filed by hand", "label": DefaultLabel})
+	other := GroupFindings(testFindings()[:1])[0]
+	foreign := fake.add(map[string]any{"title": other.Title(), "body": other.Body("discover/222222222222"), "label": DefaultLabel})
+	pr := fake.add(map[string]any{"title": "pr", "body": other.Body(scope), "label": DefaultLabel, "pull_request": map[string]any{}})
+
+	issues := GroupFindings(testFindings())
+	result, err := gh.Sync(ctx, scope, issues, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result != (SyncResult{Opened: 3}) {
+		t.Fatalf("first sync = %+v", result)
+	}
+	if fake.token != "Bearer secret" {
+		t.Errorf("Authorization = %q", fake.token)
+	}
+
+	result, err = gh.Sync(ctx, scope, issues, true)
+	if err != nil || result != (SyncResult{Unchanged: 3}) {
+		t.Fatalf("repeat sync = %+v, %v", result, err)
+	}
+
+	// logs loses its stale prefix and orders is fixed
+	resolved := GroupFindings(testFindings()[2:])
+	result, err = gh.Sync(ctx, scope, resolved, false)
+	if err != nil || result != (SyncResult{Updated: 1, Unchanged: 1}) {
+		t.Fatalf("partial sync = %+v, %v", result, err)
+	}
+	result, err = gh.Sync(ctx, scope, resolved, true)
+	if err != nil || result != (SyncResult{Unchanged: 2, Closed: 1}) {
+		t.Fatalf("complete sync = %+v, %v", result, err)
+	}
+
+	var closed int
+	for n, issue := range fake.issues {
+		if issue["state"] != "closed" {
+			continue
+		}
+		closed = n
+		if issue["state_reason"] != "completed" || len(fake.comments[n]) != 1 {
+			t.Errorf("closed issue should be completed with a comment: %+v, %v", issue, fake.comments[n])
+		}
+		if !strings.Contains(issue["title"].(string), "s3://orders") {
+			t.Errorf("closed the wrong issue: %v", issue["title"])
+		}
+	}
+	if closed == 0 {
+		t.Error("the orders issue should have been closed")
+	}
+	for _, n := range []int{manual, foreign, pr} {
+		if fake.issues[n]["state"] != "open" {
+			t.Errorf("issue #%d is not s3spectre's to close in this scope", n)
+		}
+	}
+}
+
+func TestGitHubSync_APIError(t *testing.T) {
+	gh := testGitHub(t, newFakeGitHub())
+	gh.Repo = "missing"
+	_, err := gh.Sync(context.Background(), "scan/unknown", nil, true)
+	if err == nil || strings.Contains(err.Error(), "secret") {
+		t.Fatalf("expected an error without the token, got %v", err)
+	}
+}
+
+func TestNewGitHub_InvalidRepo(t *testing.T) {
+	for _, repo := range []string{"", "acme", "acme/", "/infra", "acme/infra/extra"} {
+		if _, err := NewGitHub(repo, "secret"); err == nil {
+			t.Errorf("expected error for %q", repo)
+		}
+	}
+	if _, err := NewGitHub("acme/infra", ""); err == nil {
+		t.Error("expected error without a token")
+	}
+}
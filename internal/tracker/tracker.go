@@ -0,0 +1,195 @@
+// Package tracker files findings as issues in an issue tracker, one issue
+// per bucket, and closes them when the bucket's findings are resolved.
+package tracker
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/ppiankov/s3spectre/internal/report"
+	"github.com/ppiankov/s3spectre/internal/rules"
+)
+
+// Issue is the findings of one bucket, or of one other resource such as an
+// account setting, filed as a single issue
+type Issue struct {
+	// Key is the fingerprint the issue is found by on later runs
+	Key      string
+	Resource string
+	Account  string
+	Findings []report.Finding
+}
+
+// SyncResult counts what a sync did
+type SyncResult struct {
+	Opened    int
+	Updated   int
+	Unchanged int
+	Closed    int
+}
+
+// GroupFindings groups findings into one issue per bucket, most severe
+// bucket first. Findings without a bucket get an issue per resource.
+func GroupFindings(findings []report.Finding) []Issue {
+	byKey := make(map[string]*Issue)
+	var keys []string
+	for _, f := range findings {
+		resource := f.Resource
+		if f.Bucket != "" {
+			resource = "s3://" + f.Bucket
+		}
+		key := rules.Fingerprint("ISSUE", f.Account, resource, "")
+		issue, ok := byKey[key]
+		if !ok {
+			issue = &Issue{Key: key, Resource: resource, Account: f.Account}
+			byKey[key] = issue
+			keys = append(keys, key)
+		}
+		issue.Findings = append(issue.Findings, f)
+	}
+	issues := make([]Issue, 0, len(keys))
+	for _, key := range keys {
+		issue := byKey[key]
+		sort.SliceStable(issue.Findings, func(i, j int) bool {
+			return rules.Rank(issue.Findings[i].Severity) > rules.Rank(issue.Findings[j].Severity)
+		})
+		issues = append(issues, *issue)
+	}
+	sort.SliceStable(issues, func(i, j int) bool {
+		ri, rj := issues[i].severityRank(), issues[j].severityRank()
+		if ri != rj {
+			return ri > rj
+		}
+		return issues[i].Resource < issues[j].Resource
+	})
+	return issues
+}
+
+// Severity is the highest severity among the issue's findings
+func (i Issue) Severity() string {
+	if len(i.Findings) == 0 {
+		return ""
+	}
+	return i.Findings[0].Severity
+}
+
+func (i Issue) severityRank() int {
+	return rules.Rank(i.Severity())
+}
+
+// Title names the resource and its findings, e.g. "S3Spectre: s3://logs
+// (MISSING_BUCKET)"
+func (i Issue) Title() string {
+	var statuses []string
+	seen := make(map[string]bool)
+	for _, f := range i.Findings {
+		if !seen[f.Status] {
+			seen[f.Status] = true
+			statuses = append(statuses, f.Status)
+		}
+	}
+	title := "S3Spectre: " + i.Resource
+	if i.Account != "" {
+		title += " in " + i.Account
+	}
+	return title + " (" + strings.Join(statuses, ", ") + ")"
+}
+
+// markerPattern finds the hidden marker that ties an issue to its key and
+// the scope that filed it
+var markerPattern = regexp.MustCompile(`<!-- s3spectre:issue key=([0-9a-f]+) scope=(\S+) -->`)
+
+// marker is the hidden comment identifying an issue filed for key
+func marker(key, scope string) string {
+	return fmt.Sprintf("<!-- s3spectre:issue key=%s scope=%s -->", key, scope)
+}
+
+// parseMarker returns the key and scope of an issue body's marker
+func parseMarker(body string) (key, scope string, ok bool) {
+	m := markerPattern.FindStringSubmatch(body)
+	if m == nil {
+		return "", "", false
+	}
+	return m[1], m[2], true
+}
+
+// Scope identifies which runs own an issue: only a run of the same command
+// against the same account and target closes it, so several accounts or
+// repositories can file into one tracker. The target, such as a scanned
+// repository path, is hashed to keep the scope a single marker token.
+func Scope(command, account, target string) string {
+	if account == "" {
+		account = "unknown"
+	}
+	scope := command + "/" + account
+	if target == "" {
+		return scope
+	}
+	sum := sha256.Sum256([]byte(target))
+	return scope + "/" + hex.EncodeToString(sum[:4])
+}
+
+// Body renders the issue description: a table of the findings, their code
+// references and last commits, and the hidden marker
+func (i Issue) Body(scope string) string {
+	var b strings.Builder
+	noun := "findings"
+	if len(i.Findings) == 1 {
+		noun = "finding"
+	}
+	fmt.Fprintf(&b, "S3Spectre reported %d %s on %s.\n\n", len(i.Findings), noun, code(i.Resource))
+	b.WriteString("| Severity | Status | Resource | Message |\n")
+	b.WriteString("|----------|--------|----------|---------|\n")
+	for _, f := range i.Findings {
+		fmt.Fprintf(&b, "| %s | %s | %s | %s |\n", f.Severity, code(f.Status), code(f.Resource), cell(f.Message))
+	}
+	for _, f := range i.Findings {
+		fmt.Fprintf(&b, "\n#### %s %s\n\n", code(f.Status), code(f.Resource))
+		if rule, ok := rules.Lookup(f.Status); ok && rule.Remediation != "" {
+			fmt.Fprintf(&b, "%s\n\n", rule.Remediation)
+		}
+		for _, ref := range f.References {
+			location := ref.File
+			if ref.Line > 0 {
+				location += ":" + strconv.Itoa(ref.Line)
+			}
+			fmt.Fprintf(&b, "- Referenced at %s\n", code(location))
+		}
+		if f.Blame != nil {
+			fmt.Fprintf(&b, "- Last changed by %s in %s\n", f.Blame.Author, code(shortCommit(f.Blame.Commit)))
+		}
+		fmt.Fprintf(&b, "- Fingerprint: %s\n", code(f.Fingerprint))
+	}
+	fmt.Fprintf(&b, "\n%s\n", marker(i.Key, scope))
+	return b.String()
+}
+
+// code formats s as inline code, fencing backticks it contains
+func code(s string) string {
+	fence := "`"
+	for strings.Contains(s, fence) {
+		fence += "`"
+	}
+	if strings.HasPrefix(s, "`") || strings.HasSuffix(s, "`") {
+		s = " " + s + " "
+	}
+	return fence + s + fence
+}
+
+// cell escapes text for a table cell
+func cell(s string) string {
+	s = strings.ReplaceAll(s, "|", `\|`)
+	return strings.Join(strings.Fields(s), " ")
+}
+
+func shortCommit(commit string) string {
+	if len(commit) > 12 {
+		return commit[:12]
+	}
+	return commit
+}
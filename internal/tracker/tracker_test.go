@@ -0,0 +1,88 @@
+package tracker
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/ppiankov/s3spectre/internal/report"
+	"github.com/ppiankov/s3spectre/internal/scanner"
+)
+
+func testFindings() []report.Finding {
+	return []report.Finding{
+		{Status: "STALE_PREFIX", Severity: "low", Resource: "s3://logs/old/", Bucket: "logs", Prefix: "old/", Account: "111111111111"},
+		{Status: "MISSING_BUCKET", Severity: "medium", Resource: "s3://orders", Bucket: "orders", Account: "111111111111",
+			Message:    "Bucket | orders does not exist",
+			References: []scanner.Reference{{File: "app/config.yaml", Line: 12}},
+			Blame:      &scanner.Blame{Author: "Dana", Commit: "0123456789abcdef"}},
+		{Status: "PUBLIC_BUCKET", Severity: "high", Resource: "s3://logs", Bucket: "logs", Account: "111111111111"},
+		{Status: "NO_ACCOUNT_PAB", Severity: "high", Resource: "account:111111111111", Account: "111111111111"},
+	}
+}
+
+func TestGroupFindings_OneIssuePerBucket(t *testing.T) {
+	issues := GroupFindings(testFindings())
+	if len(issues) != 3 {
+		t.Fatalf("expected 3 issues, got %d: %+v", len(issues), issues)
+	}
+	// Most severe first, then by resource
+	wantResources := []string{"account:111111111111", "s3://logs", "s3://orders"}
+	for i, want := range wantResources {
+		if issues[i].Resource != want {
+			t.Errorf("issue %d resource = %q, want %q", i, issues[i].Resource, want)
+		}
+	}
+	logs := issues[1]
+	if len(logs.Findings) != 2 || logs.Findings[0].Status != "PUBLIC_BUCKET" || logs.Severity() != "high" {
+		t.Errorf("logs issue should hold both findings, most severe first: %+v", logs.Findings)
+	}
+	if logs.Title() != "S3Spectre: s3://logs in 111111111111 (PUBLIC_BUCKET, STALE_PREFIX)" {
+		t.Errorf("title = %q", logs.Title())
+	}
+
+	again := GroupFindings(testFindings()[1:])
+	if again[1].Key != logs.Key {
+		t.Error("an issue key should not depend on which findings the bucket has")
+	}
+}
+
+func TestIssueBody(t *testing.T) {
+	issue := GroupFindings(testFindings())[2]
+	body := issue.Body("scan/111111111111")
+	for _, want := range []string{
+		"S3Spectre reported 1 finding on `s3://orders`.",
+		"| medium | `MISSING_BUCKET` | `s3://orders` | Bucket \\| orders does not exist |",
+		"- Referenced at `app/config.yaml:12`",
+		"- Last changed by Dana in `0123456789ab`",
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("body missing %q:\n%s", want, body)
+		}
+	}
+	key, scope, ok := parseMarker(body)
+	if !ok || key != issue.Key || scope != "scan/111111111111" {
+		t.Errorf("parseMarker = %q, %q, %v", key, scope, ok)
+	}
+}
+
+func TestScope(t *testing.T) {
+	if got := Scope("discover", "", ""); got != "discover/unknown" {
+		t.Errorf("Scope = %q", got)
+	}
+	repoA, repoB := Scope("scan", "111111111111", "/src/repo a"), Scope("scan", "111111111111", "/src/repo-b")
+	if repoA == repoB || !strings.HasPrefix(repoA, "scan/111111111111/") {
+		t.Errorf("scopes of two repositories = %q, %q, want distinct", repoA, repoB)
+	}
+	if _, scope, ok := parseMarker(marker("abc123", repoA)); !ok || scope != repoA {
+		t.Errorf("a repository scope should round-trip through the marker, got %q", scope)
+	}
+	if _, _, ok := parseMarker("an issue filed by hand"); ok {
+		t.Error("a body without the marker should not parse")
+	}
+}
+
+func TestCode(t *testing.T) {
+	if got := code("a`b"); got != "``a`b``" {
+		t.Errorf("code = %q", got)
+	}
+}